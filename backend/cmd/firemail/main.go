@@ -2,19 +2,53 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"firemail/internal/config"
 	"firemail/internal/database"
 	"firemail/internal/handlers"
+	"firemail/internal/metrics"
 	"firemail/internal/middleware"
+	"firemail/internal/openapi"
 	"firemail/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 )
 
+// 进程运行模式，通过第一个命令行参数指定
+const (
+	modeAll    = "all"    // 默认：同一进程提供HTTP接口并运行后台任务
+	modeServe  = "serve"  // 只提供HTTP接口，发送请求写入队列由工作进程投递
+	modeWorker = "worker" // 只运行同步、发送队列、清理等后台任务，多个工作进程通过数据库租约选出一个运行
+)
+
+// parseMode 解析运行模式，未指定时同时提供接口和运行后台任务
+func parseMode(args []string) (string, error) {
+	if len(args) == 0 {
+		return modeAll, nil
+	}
+	switch args[0] {
+	case modeAll, modeServe, modeWorker:
+		return args[0], nil
+	default:
+		return "", fmt.Errorf("unknown command %q, usage: firemail [serve|worker|all]", args[0])
+	}
+}
+
 func main() {
+	mode, err := parseMode(os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	// 加载环境变量 - 优先加载.env.local，然后是.env
 	if err := godotenv.Load(".env.local"); err != nil {
 		// 如果.env.local不存在，尝试加载.env
@@ -27,10 +61,23 @@ func main() {
 		log.Println("Loaded configuration from .env.local file")
 	}
 
-	// 初始化配置
-	cfg := config.Load()
+	// 初始化配置，存在无效配置时拒绝启动
+	cfg, err := config.LoadAndValidate()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	if configFile := cfg.ConfigFile(); configFile != "" {
+		log.Printf("Loaded configuration file %s", configFile)
+	}
+	for _, warning := range cfg.Warnings() {
+		log.Printf("Warning: %s", warning)
+	}
+	if mode != modeAll && cfg.Redis.EventBackend != config.BackendRedis {
+		log.Printf("Warning: running in %s mode without SSE_EVENT_BACKEND=redis, events from the worker will not reach clients of the HTTP process", mode)
+	}
 
 	// 初始化数据库
+	database.Configure(cfg.Database)
 	db, err := database.Initialize(cfg.Database.Path)
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
@@ -41,14 +88,6 @@ func main() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	// 创建路由器
-	router := gin.New()
-
-	// 添加中间件
-	router.Use(gin.Logger())
-	router.Use(gin.Recovery())
-	router.Use(middleware.CORS(cfg.CORS.Origins))
-
 	// 初始化处理器
 	h := handlers.New(db, cfg)
 
@@ -57,6 +96,27 @@ func main() {
 	middleware.SetGlobalAuthService(h.GetAuthService())
 	log.Println("Global auth service set successfully")
 
+	// 后台任务的上下文，在关闭流程排空任务后才取消
+	appCtx, cancelApp := context.WithCancel(context.Background())
+	defer cancelApp()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+
+	// 工作进程先取得租约，未取得时作为备用进程等待
+	var lease *services.LeaderLease
+	var leaseLost <-chan struct{}
+	if mode == modeWorker {
+		lease = services.NewLeaderLease(db, services.WorkerLeaseName, 0)
+		log.Printf("Acquiring worker lease as %s...", lease.Holder())
+		if !acquireWorkerLease(appCtx, lease, quit) {
+			log.Println("FireMail worker stopped before acquiring the lease")
+			return
+		}
+		log.Println("Worker lease acquired")
+		leaseLost = lease.Hold(appCtx)
+	}
+
 	// 启动SSE服务
 	if err := h.StartSSEService(); err != nil {
 		log.Fatalf("Failed to start SSE service: %v", err)
@@ -67,49 +127,229 @@ func main() {
 		log.Printf("Warning: Soft delete validation failed: %v", err)
 	}
 
+	if mode == modeServe {
+		h.SetSendQueueOnly()
+	}
+	if mode != modeServe {
+		startBackgroundServices(appCtx, h, mode)
+	}
+
+	var server *http.Server
+	if mode != modeWorker {
+		server = startHTTPServer(appCtx, h, cfg)
+	}
+
+	select {
+	case sig := <-quit:
+		log.Printf("Received %s, shutting down (drain timeout %s)...", sig, cfg.Server.ShutdownTimeout)
+	case <-leaseLost:
+		log.Printf("Worker lease lost, shutting down (drain timeout %s)...", cfg.Server.ShutdownTimeout)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	// 先停止接收请求并等待进行中的请求，再排空后台任务
+	if server != nil {
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Warning: HTTP server shutdown: %v", err)
+		}
+	}
+	if err := h.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Warning: Background jobs did not drain cleanly: %v", err)
+	}
+	cancelApp()
+
+	if lease != nil {
+		if err := lease.Release(shutdownCtx); err != nil {
+			log.Printf("Warning: Failed to release worker lease: %v", err)
+		}
+	}
+	if err := database.Close(db); err != nil {
+		log.Printf("Warning: Failed to close database: %v", err)
+	}
+	log.Println("FireMail server stopped")
+}
+
+// acquireWorkerLease 等待取得工作进程租约，等待期间收到退出信号时返回false
+func acquireWorkerLease(ctx context.Context, lease *services.LeaderLease, quit <-chan os.Signal) bool {
+	acquireCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-quit:
+			cancel()
+		case <-acquireCtx.Done():
+		}
+	}()
+	return lease.Acquire(acquireCtx) == nil
+}
+
+// startBackgroundServices 启动同步、发送队列、清理等后台任务
+func startBackgroundServices(appCtx context.Context, h *handlers.Handler, mode string) {
 	// 启动自动备份服务
-	if err := h.StartBackupService(context.Background()); err != nil {
+	if err := h.StartBackupService(appCtx); err != nil {
 		log.Printf("Warning: Failed to start backup service: %v", err)
 	}
 
 	// 启动软删除自动清理服务（保留30天）
-	if err := h.StartSoftDeleteCleanup(context.Background(), 30); err != nil {
+	if err := h.StartSoftDeleteCleanup(appCtx, 30); err != nil {
 		log.Printf("Warning: Failed to start soft delete cleanup service: %v", err)
 	}
 
 	// 启动临时附件自动清理服务（保留24小时）
-	if err := h.StartTemporaryAttachmentCleanup(context.Background(), 24); err != nil {
+	if err := h.StartTemporaryAttachmentCleanup(appCtx, 24); err != nil {
 		log.Printf("Warning: Failed to start temporary attachment cleanup service: %v", err)
 	}
 
+	// 定期校正文件夹和账户的邮件计数
+	if err := h.StartFolderCountReconciliation(appCtx); err != nil {
+		log.Printf("Warning: Failed to start folder count reconciliation: %v", err)
+	}
+
+	// 定期删除超过保留期的通知
+	if err := h.StartNotificationCleanup(appCtx); err != nil {
+		log.Printf("Warning: Failed to start notification cleanup: %v", err)
+	}
+
+	// 配置了向量模型时定期为邮件建立语义搜索索引
+	if err := h.StartEmbeddingIndex(appCtx); err != nil {
+		log.Printf("Warning: Failed to start embedding index: %v", err)
+	}
+
+	// 继续投递上次退出时未完成的邮件
+	if err := h.ResumeOutboundQueue(appCtx); err != nil {
+		log.Printf("Warning: Failed to resume outbound queue: %v", err)
+	}
+
+	// 工作进程补发HTTP进程写入队列但没有投递任务的邮件
+	if mode == modeWorker {
+		if err := h.DeliverQueuedSends(appCtx); err != nil {
+			log.Printf("Warning: Failed to deliver queued sends: %v", err)
+		}
+	}
+
 	// 启动定时邮件服务
-	if err := h.StartScheduledEmailService(context.Background()); err != nil {
+	if err := h.StartScheduledEmailService(appCtx); err != nil {
 		log.Printf("Warning: Failed to start scheduled email service: %v", err)
 	}
 
+	// 启动稍后回复到期提醒
+	h.StartReplyLaterReminders(appCtx)
+
+	// 启动邮件合并服务
+	if err := h.StartMailMergeService(appCtx); err != nil {
+		log.Printf("Warning: Failed to start mail merge service: %v", err)
+	}
+
+	// 启动邮箱迁移服务
+	if err := h.StartMailboxMigrationService(appCtx); err != nil {
+		log.Printf("Warning: Failed to start mailbox migration service: %v", err)
+	}
+
+	// 启动邮件保留规则定时任务
+	if err := h.StartRetentionService(appCtx); err != nil {
+		log.Printf("Warning: Failed to start retention service: %v", err)
+	}
+
+	// 启动代收外部邮箱定时任务
+	if err := h.StartMailFetcherService(appCtx); err != nil {
+		log.Printf("Warning: Failed to start mail fetcher service: %v", err)
+	}
+
+	// 启动自动转发队列
+	if err := h.StartForwardingService(appCtx); err != nil {
+		log.Printf("Warning: Failed to start forwarding service: %v", err)
+	}
+
+	// 启动法律保全服务
+	if err := h.StartLegalHoldService(appCtx); err != nil {
+		log.Printf("Warning: Failed to start legal hold service: %v", err)
+	}
+
+	// 最后启动任务队列，此时周期任务已全部注册
+	if err := h.StartJobQueue(appCtx); err != nil {
+		log.Printf("Warning: Failed to start job queue: %v", err)
+	}
+}
+
+// startHTTPServer 启动HTTP接口以及内置SMTP、IMAP服务器
+func startHTTPServer(appCtx context.Context, h *handlers.Handler, cfg *config.Config) *http.Server {
+	// 启动内置SMTP收信服务器
+	if err := h.StartSMTPServer(appCtx); err != nil {
+		log.Printf("Warning: Failed to start SMTP server: %v", err)
+	}
+
+	// 启动本地IMAP服务器
+	if err := h.StartIMAPServer(appCtx); err != nil {
+		log.Printf("Warning: Failed to start IMAP server: %v", err)
+	}
+
+	// 创建路由器，只信任配置的反向代理转发的来源IP，避免伪造 X-Forwarded-For 绕过IP限制
+	router := gin.New()
+	if err := router.SetTrustedProxies(cfg.Access.TrustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
+	// 添加中间件
+	router.Use(gin.Logger())
+	router.Use(gin.Recovery())
+	router.Use(middleware.CORS(cfg.CORS.Origins))
+	router.Use(middleware.Locale())
+
 	// 设置路由
-	setupRoutes(router, h)
+	setupRoutes(router, h, cfg)
+	if missing := undocumentedRoutes(router); len(missing) > 0 {
+		log.Printf("Warning: routes missing from OpenAPI document: %v", missing)
+	}
 
 	// 启动服务器
 	addr := cfg.Server.Host + ":" + cfg.Server.Port
-	log.Printf("FireMail server starting on %s", addr)
-
-	if err := router.Run(addr); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	server := &http.Server{
+		Addr:    addr,
+		Handler: router,
 	}
+	// SSE长连接不会自行结束，关闭开始时先通知客户端并断开
+	server.RegisterOnShutdown(h.ShutdownSSEService)
+
+	go func() {
+		log.Printf("FireMail server starting on %s", addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+	return server
 }
 
-func setupRoutes(router *gin.Engine, h *handlers.Handler) {
+func setupRoutes(router *gin.Engine, h *handlers.Handler, cfg *config.Config) {
 	// 健康检查
 	router.GET("/health", h.HealthCheck)
 
+	// Prometheus监控指标（可选）
+	if config.Env != nil && config.Env.ShouldEnableMetrics() {
+		router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	}
+
+	// OpenAPI文档
+	router.GET("/api/v1/openapi.json", h.GetOpenAPISpec)
+
+	// GraphQL查询接口（可选）
+	if cfg.GraphQL.Enabled {
+		graphql := router.Group("/api/graphql")
+		graphql.Use(h.AuthRequired())
+		{
+			graphql.POST("", h.GraphQL)
+			graphql.GET("/schema", h.GetGraphQLSchema)
+		}
+	}
+
 	// API路由组
 	api := router.Group("/api/v1")
 	{
 		// 认证路由
 		auth := api.Group("/auth")
 		{
-			auth.POST("/login", h.Login)
+			auth.POST("/login", h.LoginIPAccess(), h.Login)
 			auth.POST("/logout", h.Logout)
 			auth.GET("/me", h.AuthRequired(), h.GetCurrentUser)
 		}
@@ -141,12 +381,21 @@ func setupRoutes(router *gin.Engine, h *handlers.Handler) {
 			accounts.GET("", h.GetEmailAccounts)
 			accounts.POST("", h.CreateEmailAccount)
 			accounts.POST("/custom", h.CreateCustomEmailAccount) // 自定义邮箱创建端点
+			accounts.PUT("/reorder", h.ReorderEmailAccounts)
 			accounts.GET("/:id", h.GetEmailAccount)
 			accounts.PUT("/:id", h.UpdateEmailAccount)
 			accounts.DELETE("/:id", h.DeleteEmailAccount)
 			accounts.POST("/:id/test", h.TestEmailAccount)
 			accounts.POST("/:id/sync", h.SyncEmailAccount)
+			accounts.POST("/:id/pause", h.PauseAccountSync)
+			accounts.POST("/:id/resume", h.ResumeAccountSync)
+			accounts.GET("/:id/gmail-labels", h.GetGmailLabelMappings)
+			accounts.PUT("/:id/gmail-labels", h.UpdateGmailLabelMappings)
 			accounts.PUT("/:id/mark-read", h.MarkAccountAsRead)
+			accounts.GET("/:id/storage/top", h.GetMailboxStorageReport)
+			accounts.POST("/:id/storage/cleanup", h.StartStorageCleanup)
+			accounts.GET("/:id/storage/cleanup/:job_id", h.GetStorageCleanup)
+			accounts.POST("/:id/storage/cleanup/:job_id/cancel", h.CancelStorageCleanup)
 			accounts.POST("/batch/delete", h.BatchDeleteEmailAccounts)
 			accounts.POST("/batch/sync", h.BatchSyncEmailAccounts)
 			accounts.POST("/batch/mark-read", h.BatchMarkAccountsAsRead)
@@ -166,29 +415,112 @@ func setupRoutes(router *gin.Engine, h *handlers.Handler) {
 		{
 			emails.GET("", h.GetEmails)
 			emails.GET("/search", h.SearchEmails)
+			emails.GET("/muted-threads", h.GetMutedThreads)
+			emails.GET("/reply-later", h.GetReplyLaterEmails)
+			emails.GET("/verification-codes", h.GetVerificationCodes)
+			emails.DELETE("/muted-threads/:id", h.DeleteMutedThread)
+			emails.GET("/sync-conflicts", h.GetSyncConflicts)
+			emails.POST("/sync-conflicts/:id/resolve", h.ResolveSyncConflict)
 			emails.GET("/:id", h.GetEmail)
+			emails.GET("/:id/navigation", h.GetEmailNavigation)
+			emails.GET("/:id/pdf", h.ExportEmailPDF)
+			emails.GET("/:id/history", h.GetEmailHistory)
+			emails.GET("/:id/notes", h.GetEmailNotes)
+			emails.POST("/:id/notes", h.CreateEmailNote)
+			emails.PATCH("/:id/notes/:note_id", h.UpdateEmailNote)
+			emails.DELETE("/:id/notes/:note_id", h.DeleteEmailNote)
+			emails.POST("/:id/share", h.CreateEmailShare)
+			emails.GET("/:id/shares", h.GetEmailShares)
 			emails.PATCH("/:id", h.UpdateEmail)
 			emails.POST("/send", h.SendEmail)
 			emails.DELETE("/:id", h.DeleteEmail)
 			emails.PUT("/:id/read", h.MarkEmailAsRead)
 			emails.PUT("/:id/unread", h.MarkEmailAsUnread)
 			emails.PUT("/:id/star", h.ToggleEmailStar)
+			emails.PUT("/:id/flag", h.SetEmailFlag)
+			emails.PUT("/:id/pin", h.ToggleEmailPin)
+			emails.PUT("/:id/reply-later", h.SetReplyLater)
+			emails.DELETE("/:id/reply-later", h.ClearReplyLater)
+			emails.PUT("/:id/mute-thread", h.MuteThread)
+			emails.PUT("/:id/unmute-thread", h.UnmuteThread)
+			emails.PUT("/:id/block-sender", h.BlockEmailSender)
+			emails.PUT("/:id/priority/important", h.MarkEmailPriorityImportant)
+			emails.PUT("/:id/priority/other", h.MarkEmailPriorityOther)
 			emails.PUT("/:id/move", h.MoveEmail)
 			emails.PUT("/:id/archive", h.ArchiveEmail)
+			emails.POST("/:id/redecode", h.RedecodeEmail)
+			emails.POST("/:id/reparse", h.ReparseEmail)
+			emails.GET("/:id/source", h.GetEmailSource)
+			emails.GET("/:id/headers/analysis", h.AnalyzeEmailHeaders)
 			emails.POST("/:id/reply", h.ReplyEmail)
 			emails.POST("/:id/reply-all", h.ReplyAllEmail)
 			emails.POST("/:id/forward", h.ForwardEmail)
 			emails.POST("/batch", h.BatchEmailOperations)
+			emails.GET("/transfers/:job_id", h.GetEmailTransferJob)
+			emails.POST("/transfers/:job_id/cancel", h.CancelEmailTransferJob)
+			emails.POST("/duplicates/scans", h.StartDuplicateScan)
+			emails.GET("/duplicates/scans/:job_id", h.GetDuplicateScan)
+			emails.POST("/duplicates/scans/:job_id/cancel", h.CancelDuplicateScan)
+			emails.POST("/duplicates/scans/:job_id/resolve", h.ResolveDuplicates)
+
+			// 草稿与模板路由
+			h.GetEmailSendHandler().RegisterDraftRoutes(emails)
+		}
+
+		// 回收站路由（需要认证）
+		trash := api.Group("/trash")
+		trash.Use(h.AuthRequired())
+		{
+			trash.GET("", h.GetTrash)
+			trash.DELETE("", h.EmptyTrash)
+			trash.POST("/restore", h.RestoreTrashedEmails)
+			trash.POST("/purge", h.PurgeTrashedEmails)
 		}
 
+		// 邮件分享链接管理（需要认证）
+		shares := api.Group("/shares")
+		shares.Use(h.AuthRequired())
+		{
+			shares.PATCH("/:id", h.UpdateEmailShare)
+			shares.DELETE("/:id", h.RevokeEmailShare)
+		}
+
+		// 分享邮件的公开只读页面（凭签名链接访问，无需认证）
+		shared := api.Group("/shared")
+		{
+			shared.GET("/:token", h.ViewSharedEmail)
+			shared.GET("/:token/attachments/:attachment_id", h.DownloadSharedAttachment)
+		}
+
+		// 发信时改为下载链接的附件（凭签名链接访问，无需认证）
+		attachmentLinks := api.Group("/attachment-links")
+		{
+			attachmentLinks.GET("/:token", h.DownloadAttachmentLink)
+		}
+
+		// 活动邮件的打开跟踪像素和跳转链接（凭收件人跟踪令牌访问，无需认证）
+		tracking := api.Group("/t")
+		{
+			tracking.GET("/o/:token", h.TrackMailMergeOpen)
+			tracking.GET("/c/:token/:link_id", h.TrackMailMergeClick)
+		}
+
+		// 通知快捷操作（凭一次性令牌执行，无需认证）
+		api.POST("/notification-actions/:token", h.ExecuteNotificationAction)
+
 		// 邮件文件夹路由（需要认证）
 		folders := api.Group("/folders")
 		folders.Use(h.AuthRequired())
 		{
 			folders.GET("", h.GetFolders)
 			folders.POST("", h.CreateFolder)
+			folders.GET("/labels", h.GetLabels)
+			folders.PUT("/labels", h.UpdateLabelAppearance)
+			folders.DELETE("/labels/:id", h.DeleteLabelAppearance)
+			folders.POST("/reconcile-counts", h.ReconcileFolderCounts)
 			folders.GET("/:id", h.GetFolder)
 			folders.PUT("/:id", h.UpdateFolder)
+			folders.PUT("/:id/appearance", h.UpdateFolderAppearance)
 			folders.DELETE("/:id", h.DeleteFolder)
 			folders.PUT("/:id/mark-read", h.MarkFolderAsRead)
 			folders.PUT("/:id/sync", h.SyncFolder)
@@ -206,6 +538,218 @@ func setupRoutes(router *gin.Engine, h *handlers.Handler) {
 			groups.DELETE("/:id", h.DeleteEmailGroup)
 		}
 
+		// 邮件合并活动路由（需要认证）
+		campaigns := api.Group("/campaigns")
+		campaigns.Use(h.AuthRequired())
+		{
+			campaigns.GET("", h.GetMailMergeCampaigns)
+			campaigns.POST("", h.CreateMailMergeCampaign)
+			campaigns.GET("/opt-outs", h.GetTrackingOptOuts)
+			campaigns.POST("/opt-outs", h.AddTrackingOptOut)
+			campaigns.DELETE("/opt-outs/:id", h.DeleteTrackingOptOut)
+			campaigns.GET("/:id", h.GetMailMergeCampaign)
+			campaigns.GET("/:id/recipients", h.GetMailMergeRecipients)
+			campaigns.GET("/:id/report", h.GetMailMergeCampaignReport)
+			campaigns.POST("/:id/start", h.StartMailMergeCampaign)
+			campaigns.POST("/:id/pause", h.PauseMailMergeCampaign)
+			campaigns.POST("/:id/resume", h.ResumeMailMergeCampaign)
+			campaigns.POST("/:id/cancel", h.CancelMailMergeCampaign)
+		}
+
+		// 邮箱迁移路由（需要认证）
+		migrations := api.Group("/migrations")
+		migrations.Use(h.AuthRequired())
+		{
+			migrations.GET("", h.GetMailboxMigrations)
+			migrations.POST("", h.CreateMailboxMigration)
+			migrations.GET("/plan", h.PlanMailboxMigration)
+			migrations.GET("/:id", h.GetMailboxMigration)
+			migrations.GET("/:id/report", h.GetMailboxMigrationReport)
+			migrations.POST("/:id/pause", h.PauseMailboxMigration)
+			migrations.POST("/:id/resume", h.ResumeMailboxMigration)
+			migrations.POST("/:id/cancel", h.CancelMailboxMigration)
+		}
+
+		// VIP发件人路由（需要认证）
+		vipSenders := api.Group("/vip-senders")
+		vipSenders.Use(h.AuthRequired())
+		{
+			vipSenders.GET("", h.GetVIPSenders)
+			vipSenders.POST("", h.AddVIPSender)
+			vipSenders.DELETE("/:id", h.RemoveVIPSender)
+		}
+
+		// 屏蔽发件人路由（需要认证）
+		blockedSenders := api.Group("/blocked-senders")
+		blockedSenders.Use(h.AuthRequired())
+		{
+			blockedSenders.GET("", h.GetBlockedSenders)
+			blockedSenders.POST("", h.AddBlockedSender)
+			blockedSenders.GET("/export", h.ExportBlockedSenders)
+			blockedSenders.POST("/import", h.ImportBlockedSenders)
+			blockedSenders.PUT("/:id", h.UpdateBlockedSender)
+			blockedSenders.DELETE("/:id", h.DeleteBlockedSender)
+		}
+
+		// 邮件保留规则路由（需要认证）
+		retention := api.Group("/retention-policies")
+		retention.Use(h.AuthRequired())
+		{
+			retention.GET("", h.GetRetentionPolicies)
+			retention.POST("", h.CreateRetentionPolicy)
+			retention.GET("/runs", h.GetRetentionRuns)
+			retention.PUT("/:id", h.UpdateRetentionPolicy)
+			retention.DELETE("/:id", h.DeleteRetentionPolicy)
+			retention.GET("/:id/preview", h.PreviewRetentionPolicy)
+			retention.POST("/:id/run", h.RunRetentionPolicy)
+		}
+
+		// 代收外部邮箱路由（需要认证）
+		mailFetchers := api.Group("/mail-fetchers")
+		mailFetchers.Use(h.AuthRequired())
+		{
+			mailFetchers.GET("", h.GetMailFetchers)
+			mailFetchers.POST("", h.CreateMailFetcher)
+			mailFetchers.PUT("/:id", h.UpdateMailFetcher)
+			mailFetchers.DELETE("/:id", h.DeleteMailFetcher)
+			mailFetchers.POST("/:id/run", h.RunMailFetcher)
+		}
+
+		// 自动转发规则路由（需要认证）
+		forwarding := api.Group("/forwarding-rules")
+		forwarding.Use(h.AuthRequired())
+		{
+			forwarding.GET("", h.GetForwardingRules)
+			forwarding.POST("", h.CreateForwardingRule)
+			forwarding.PUT("/:id", h.UpdateForwardingRule)
+			forwarding.DELETE("/:id", h.DeleteForwardingRule)
+		}
+
+		// 快捷回复路由（需要认证）
+		cannedResponses := api.Group("/canned-responses")
+		cannedResponses.Use(h.AuthRequired())
+		{
+			cannedResponses.GET("", h.GetCannedResponses)
+			cannedResponses.GET("/quick", h.GetCannedQuickList)
+			cannedResponses.POST("", h.CreateCannedResponse)
+			cannedResponses.PUT("/:id", h.UpdateCannedResponse)
+			cannedResponses.DELETE("/:id", h.DeleteCannedResponse)
+			cannedResponses.POST("/:id/render", h.RenderCannedResponse)
+		}
+
+		// 法律保全相关路由
+		legalHolds := api.Group("/legal-holds")
+		legalHolds.Use(h.AuthRequired())
+		{
+			legalHolds.GET("", h.GetLegalHolds)
+			legalHolds.POST("", h.CreateLegalHold)
+			legalHolds.GET("/:id", h.GetLegalHold)
+			legalHolds.POST("/:id/release", h.ReleaseLegalHold)
+			legalHolds.POST("/:id/exports", h.CreateLegalHoldExport)
+			legalHolds.GET("/:id/exports", h.GetLegalHoldExports)
+			legalHolds.GET("/:id/exports/:export_id", h.GetLegalHoldExport)
+			legalHolds.GET("/:id/exports/:export_id/download", h.DownloadLegalHoldExport)
+			legalHolds.POST("/:id/exports/:export_id/verify", h.VerifyLegalHoldExport)
+		}
+
+		// 入站邮件端点管理（需要认证）
+		ingestEndpoints := api.Group("/ingest-endpoints")
+		ingestEndpoints.Use(h.AuthRequired())
+		{
+			ingestEndpoints.GET("", h.GetIngestEndpoints)
+			ingestEndpoints.POST("", h.CreateIngestEndpoint)
+			ingestEndpoints.PATCH("/:id", h.UpdateIngestEndpoint)
+			ingestEndpoints.DELETE("/:id", h.DeleteIngestEndpoint)
+			ingestEndpoints.POST("/:id/rotate-token", h.RotateIngestToken)
+		}
+
+		// 外部服务推送邮件（凭入站令牌访问，无需认证）
+		api.POST("/ingest", h.IngestEmail)
+
+		// 用户设置路由（需要认证）
+		settings := api.Group("/settings")
+		settings.Use(h.AuthRequired())
+		{
+			settings.GET("", h.GetSettings)
+			settings.PATCH("", h.UpdateSettings)
+		}
+
+		// 组织与共享邮箱路由（需要认证）
+		organizations := api.Group("/organizations")
+		organizations.Use(h.AuthRequired())
+		{
+			organizations.GET("", h.GetOrganizations)
+			organizations.POST("", h.CreateOrganization)
+			organizations.GET("/:id", h.GetOrganization)
+			organizations.DELETE("/:id", h.DeleteOrganization)
+			organizations.POST("/:id/invites", h.InviteOrganizationMember)
+			organizations.DELETE("/:id/invites/:invite_id", h.RevokeOrganizationInvite)
+			organizations.PATCH("/:id/members/:user_id", h.UpdateOrganizationMember)
+			organizations.DELETE("/:id/members/:user_id", h.RemoveOrganizationMember)
+			organizations.POST("/:id/mailboxes", h.ShareOrganizationMailbox)
+			organizations.DELETE("/:id/mailboxes/:mailbox_id", h.UnshareOrganizationMailbox)
+			organizations.PUT("/:id/mailboxes/:mailbox_id/grants", h.SetSharedMailboxGrant)
+			organizations.DELETE("/:id/mailboxes/:mailbox_id/grants/:user_id", h.RevokeSharedMailboxGrant)
+		}
+
+		organizationInvites := api.Group("/organization-invites")
+		organizationInvites.Use(h.AuthRequired())
+		{
+			organizationInvites.GET("", h.GetOrganizationInvites)
+			organizationInvites.POST("/:id/accept", h.AcceptOrganizationInvite)
+			organizationInvites.POST("/:id/decline", h.DeclineOrganizationInvite)
+		}
+
+		sharedMailboxes := api.Group("/shared-mailboxes")
+		sharedMailboxes.Use(h.AuthRequired())
+		{
+			sharedMailboxes.GET("", h.GetSharedMailboxes)
+			sharedMailboxes.GET("/:id/emails", h.GetSharedMailboxEmails)
+			sharedMailboxes.GET("/:id/emails/:email_id", h.GetSharedMailboxEmail)
+			sharedMailboxes.POST("/:id/emails/:email_id/claim", h.ClaimSharedMailboxEmail)
+			sharedMailboxes.POST("/:id/emails/:email_id/assign", h.AssignSharedMailboxEmail)
+			sharedMailboxes.DELETE("/:id/emails/:email_id/assignment", h.ReleaseSharedMailboxEmail)
+		}
+
+		// 统计分析路由（需要认证）
+		analytics := api.Group("/analytics")
+		analytics.Use(h.AuthRequired())
+		{
+			analytics.GET("/volume", h.GetEmailVolume)
+			analytics.GET("/busiest-hours", h.GetBusiestHours)
+			analytics.GET("/top-senders", h.GetTopSenders)
+			analytics.GET("/top-recipients", h.GetTopRecipients)
+			analytics.GET("/response-times", h.GetResponseTimes)
+			analytics.GET("/outbound/messages", h.GetOutboundMessages)
+			analytics.GET("/outbound/accounts", h.GetOutboundAccounts)
+			analytics.GET("/outbound/contacts", h.GetOutboundContacts)
+			analytics.POST("/rebuild", h.RebuildAnalytics)
+		}
+
+		// 增量变更路由（需要认证）
+		changes := api.Group("/changes")
+		changes.Use(h.AuthRequired())
+		{
+			changes.GET("", h.GetChanges)
+		}
+
+		// 管理员维护路由
+		admin := api.Group("/admin")
+		admin.Use(h.AdminIPAccess(), h.AuthRequired(), middleware.AdminRequired())
+		{
+			admin.POST("/reparse", h.StartReparseJob)
+			admin.GET("/reparse/:job_id", h.GetReparseJob)
+			admin.POST("/reparse/:job_id/cancel", h.CancelReparseJob)
+			admin.GET("/config", h.GetConfig)
+			admin.GET("/security-events", h.GetSecurityEvents)
+			admin.GET("/auth-failures", h.GetAuthFailures)
+			admin.GET("/send-queue/stuck", h.GetStuckSends)
+			admin.GET("/jobs", h.ListJobs)
+			admin.GET("/jobs/:id", h.GetJob)
+			admin.POST("/jobs/:id/retry", h.RetryJob)
+			admin.POST("/jobs/:id/cancel", h.CancelJob)
+		}
+
 		// 附件处理路由（需要认证）
 		// 创建附件存储配置
 		attachmentStorageConfig := &services.AttachmentStorageConfig{
@@ -224,10 +768,28 @@ func setupRoutes(router *gin.Engine, h *handlers.Handler) {
 
 		// 创建附件处理器
 		attachmentHandler := handlers.NewAttachmentHandler(attachmentService, h.GetDB())
+		attachmentHandler.SetTokenSigner(services.NewAttachmentTokenSigner(cfg.Auth.JWTSecret, cfg.Attachments.DownloadTokenTTL))
 
 		// 注册附件路由
 		attachmentHandler.RegisterRoutes(api)
 
+		// 通知中心路由（需要认证），SSE推送的通知同时保存在这里
+		notifications := api.Group("/notifications")
+		notifications.Use(h.AuthRequired())
+		{
+			notifications.GET("", h.GetNotifications)
+			notifications.GET("/counts", h.GetNotificationCounts)
+			notifications.PATCH("", h.MarkNotifications)
+			notifications.PATCH("/:id", h.UpdateNotification)
+		}
+
+		// 长轮询事件路由（需要认证），供无法保持SSE连接的客户端使用
+		events := api.Group("/events")
+		events.Use(h.AuthRequired())
+		{
+			events.GET("/poll", h.PollEvents)
+		}
+
 		// SSE路由（SSE端点有自己的认证逻辑）
 		sse := api.Group("/sse")
 		{
@@ -256,3 +818,14 @@ func setupRoutes(router *gin.Engine, h *handlers.Handler) {
 		})
 	})
 }
+
+// undocumentedRoutes 返回未在OpenAPI注解中登记的接口路由，静态资源和页面除外
+func undocumentedRoutes(router *gin.Engine) []string {
+	var registered []string
+	for _, route := range router.Routes() {
+		if route.Path == "/health" || strings.HasPrefix(route.Path, "/api/") {
+			registered = append(registered, route.Method+" "+route.Path)
+		}
+	}
+	return openapi.Undocumented(registered, handlers.APIRoutes())
+}
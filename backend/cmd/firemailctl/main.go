@@ -0,0 +1,7 @@
+package main
+
+import "firemail/internal/cli"
+
+func main() {
+	cli.Execute()
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"firemail/internal/handlers"
+	"firemail/internal/openapi"
+)
+
+// 根据处理器的接口注解生成OpenAPI文档以及Go、TypeScript客户端
+func main() {
+	specPath := flag.String("spec", "api/openapi.json", "OpenAPI文档输出路径")
+	goPath := flag.String("go", "pkg/client/client_gen.go", "Go客户端输出路径")
+	tsPath := flag.String("ts", "../frontend/src/lib/generated/firemail-client.ts", "TypeScript客户端输出路径")
+	flag.Parse()
+
+	doc, err := openapi.Build(handlers.APISpec())
+	if err != nil {
+		log.Fatalf("Failed to build OpenAPI document: %v", err)
+	}
+
+	spec, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to encode OpenAPI document: %v", err)
+	}
+	writeFile(*specPath, append(spec, '\n'))
+
+	goClient, err := openapi.GenerateGoClient(doc, filepath.Base(filepath.Dir(*goPath)))
+	if err != nil {
+		log.Fatalf("Failed to generate Go client: %v", err)
+	}
+	writeFile(*goPath, goClient)
+
+	writeFile(*tsPath, openapi.GenerateTypeScriptClient(doc))
+}
+
+func writeFile(path string, data []byte) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Fatalf("Failed to create directory for %s: %v", path, err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		log.Fatalf("Failed to write %s: %v", path, err)
+	}
+	log.Printf("Generated %s", path)
+}
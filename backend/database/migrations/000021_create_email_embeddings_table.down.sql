@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_email_embeddings_deleted_at;
+DROP INDEX IF EXISTS idx_email_embeddings_account_id;
+DROP INDEX IF EXISTS idx_email_embeddings_email_id;
+DROP TABLE IF EXISTS email_embeddings;
@@ -0,0 +1,18 @@
+-- 创建邮件向量索引表（语义搜索）
+CREATE TABLE IF NOT EXISTS email_embeddings (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    email_id INTEGER NOT NULL,
+    account_id INTEGER NOT NULL,
+    model VARCHAR(50) NOT NULL,
+    dimension INTEGER NOT NULL,
+    vector BLOB NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at DATETIME,
+    FOREIGN KEY (email_id) REFERENCES emails(id) ON DELETE CASCADE,
+    FOREIGN KEY (account_id) REFERENCES email_accounts(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_email_embeddings_email_id ON email_embeddings(email_id);
+CREATE INDEX IF NOT EXISTS idx_email_embeddings_account_id ON email_embeddings(account_id);
+CREATE INDEX IF NOT EXISTS idx_email_embeddings_deleted_at ON email_embeddings(deleted_at);
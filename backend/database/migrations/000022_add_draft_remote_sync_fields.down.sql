@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_drafts_remote_message_id;
+
+ALTER TABLE drafts DROP COLUMN remote_synced_at;
+ALTER TABLE drafts DROP COLUMN remote_folder;
+ALTER TABLE drafts DROP COLUMN remote_uid;
+ALTER TABLE drafts DROP COLUMN remote_message_id;
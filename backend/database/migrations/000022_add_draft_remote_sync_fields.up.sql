@@ -0,0 +1,7 @@
+-- 为草稿增加IMAP同步字段（草稿同步到服务器Drafts文件夹）
+ALTER TABLE drafts ADD COLUMN remote_message_id VARCHAR(255);
+ALTER TABLE drafts ADD COLUMN remote_uid INTEGER DEFAULT 0;
+ALTER TABLE drafts ADD COLUMN remote_folder VARCHAR(255);
+ALTER TABLE drafts ADD COLUMN remote_synced_at DATETIME;
+
+CREATE INDEX IF NOT EXISTS idx_drafts_remote_message_id ON drafts(account_id, remote_message_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_draft_revisions_deleted_at;
+DROP INDEX IF EXISTS idx_draft_revisions_created_at;
+DROP INDEX IF EXISTS idx_draft_revisions_user_id;
+DROP INDEX IF EXISTS idx_draft_revisions_draft_id;
+DROP TABLE IF EXISTS draft_revisions;
@@ -0,0 +1,33 @@
+-- 创建草稿修订历史表（自动保存与恢复）
+CREATE TABLE IF NOT EXISTS draft_revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    draft_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    revision INTEGER NOT NULL,
+    source VARCHAR(20) NOT NULL,
+
+    -- 草稿内容快照
+    subject VARCHAR(500),
+    to_addresses TEXT,
+    cc_addresses TEXT,
+    bcc_addresses TEXT,
+    text_body TEXT,
+    html_body TEXT,
+    attachment_ids TEXT,
+    priority VARCHAR(20),
+
+    -- 时间戳
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at DATETIME,
+
+    -- 外键约束
+    FOREIGN KEY (draft_id) REFERENCES drafts(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- 创建索引
+CREATE INDEX IF NOT EXISTS idx_draft_revisions_draft_id ON draft_revisions(draft_id, revision);
+CREATE INDEX IF NOT EXISTS idx_draft_revisions_user_id ON draft_revisions(user_id);
+CREATE INDEX IF NOT EXISTS idx_draft_revisions_created_at ON draft_revisions(created_at);
+CREATE INDEX IF NOT EXISTS idx_draft_revisions_deleted_at ON draft_revisions(deleted_at);
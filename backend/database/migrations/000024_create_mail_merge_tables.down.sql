@@ -0,0 +1,10 @@
+-- 删除邮件合并相关表
+DROP INDEX IF EXISTS idx_mail_merge_recipients_deleted_at;
+DROP INDEX IF EXISTS idx_mail_merge_recipients_campaign_status;
+DROP INDEX IF EXISTS idx_mail_merge_campaigns_deleted_at;
+DROP INDEX IF EXISTS idx_mail_merge_campaigns_status;
+DROP INDEX IF EXISTS idx_mail_merge_campaigns_template_id;
+DROP INDEX IF EXISTS idx_mail_merge_campaigns_account_id;
+DROP INDEX IF EXISTS idx_mail_merge_campaigns_user_id;
+DROP TABLE IF EXISTS mail_merge_recipients;
+DROP TABLE IF EXISTS mail_merge_campaigns;
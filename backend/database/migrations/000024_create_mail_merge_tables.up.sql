@@ -0,0 +1,63 @@
+-- 创建邮件合并活动表
+CREATE TABLE IF NOT EXISTS mail_merge_campaigns (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    account_id INTEGER NOT NULL,
+    template_id INTEGER NOT NULL,
+    name VARCHAR(200) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',
+
+    -- 发送节流
+    rate_per_minute INTEGER NOT NULL DEFAULT 30,
+
+    -- 统计信息
+    total_recipients INTEGER DEFAULT 0,
+    sent_count INTEGER DEFAULT 0,
+    failed_count INTEGER DEFAULT 0,
+    skipped_count INTEGER DEFAULT 0,
+
+    started_at DATETIME,
+    completed_at DATETIME,
+    last_error TEXT,
+
+    -- 时间戳
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at DATETIME,
+
+    -- 外键约束
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (account_id) REFERENCES email_accounts(id) ON DELETE CASCADE,
+    FOREIGN KEY (template_id) REFERENCES email_templates(id)
+);
+
+-- 创建邮件合并收件人表
+CREATE TABLE IF NOT EXISTS mail_merge_recipients (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    campaign_id INTEGER NOT NULL,
+    line_number INTEGER NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    name VARCHAR(255),
+    variables TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    error TEXT,
+    send_id VARCHAR(100),
+    sent_at DATETIME,
+
+    -- 时间戳
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at DATETIME,
+
+    -- 外键约束
+    FOREIGN KEY (campaign_id) REFERENCES mail_merge_campaigns(id) ON DELETE CASCADE
+);
+
+-- 创建索引
+CREATE INDEX IF NOT EXISTS idx_mail_merge_campaigns_user_id ON mail_merge_campaigns(user_id);
+CREATE INDEX IF NOT EXISTS idx_mail_merge_campaigns_account_id ON mail_merge_campaigns(account_id);
+CREATE INDEX IF NOT EXISTS idx_mail_merge_campaigns_template_id ON mail_merge_campaigns(template_id);
+CREATE INDEX IF NOT EXISTS idx_mail_merge_campaigns_status ON mail_merge_campaigns(status);
+CREATE INDEX IF NOT EXISTS idx_mail_merge_campaigns_deleted_at ON mail_merge_campaigns(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_mail_merge_recipients_campaign_status ON mail_merge_recipients(campaign_id, status);
+CREATE INDEX IF NOT EXISTS idx_mail_merge_recipients_deleted_at ON mail_merge_recipients(deleted_at);
@@ -0,0 +1 @@
+ALTER TABLE email_accounts DROP COLUMN max_part_size;
@@ -0,0 +1,2 @@
+-- 为邮件账户增加单个MIME部分内联下载的大小上限（0表示使用默认值）
+ALTER TABLE email_accounts ADD COLUMN max_part_size INTEGER DEFAULT 0;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_change_log_entries_created_at;
+DROP INDEX IF EXISTS idx_change_log_entries_user_id;
+DROP TABLE IF EXISTS change_log_entries;
@@ -0,0 +1,19 @@
+-- 创建用户变更日志表（增量同步令牌）
+CREATE TABLE IF NOT EXISTS change_log_entries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    account_id INTEGER NOT NULL DEFAULT 0,
+    entity_type VARCHAR(20) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    action VARCHAR(20) NOT NULL,
+
+    -- 时间戳
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    -- 外键约束
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- 创建索引
+CREATE INDEX IF NOT EXISTS idx_change_log_entries_user_id ON change_log_entries(user_id, id);
+CREATE INDEX IF NOT EXISTS idx_change_log_entries_created_at ON change_log_entries(created_at);
@@ -0,0 +1,8 @@
+-- 回滚：移除邮件表的user_id字段及相关索引
+DROP INDEX IF EXISTS idx_email_accounts_user_group;
+DROP INDEX IF EXISTS idx_emails_folder_unread;
+DROP INDEX IF EXISTS idx_emails_user_account_date;
+DROP INDEX IF EXISTS idx_emails_user_folder_date;
+DROP INDEX IF EXISTS idx_emails_user_deleted_date;
+
+ALTER TABLE emails DROP COLUMN user_id;
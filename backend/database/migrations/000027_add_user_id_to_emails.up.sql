@@ -0,0 +1,16 @@
+-- 为邮件冗余保存所属用户ID，列表与搜索查询不再需要关联 email_accounts 表
+
+-- 1. 添加user_id列并按账户回填
+ALTER TABLE emails ADD COLUMN user_id INTEGER NOT NULL DEFAULT 0;
+UPDATE emails SET user_id = COALESCE((SELECT user_id FROM email_accounts WHERE email_accounts.id = emails.account_id), 0);
+
+-- 2. 邮件列表的复合索引，与 ORDER BY date, id 的键集分页一致
+CREATE INDEX IF NOT EXISTS idx_emails_user_deleted_date ON emails(user_id, is_deleted, date DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_emails_user_folder_date ON emails(user_id, folder_id, is_deleted, date DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_emails_user_account_date ON emails(user_id, account_id, is_deleted, date DESC, id DESC);
+
+-- 3. 未读计数按文件夹统计
+CREATE INDEX IF NOT EXISTS idx_emails_folder_unread ON emails(folder_id, is_deleted, is_read);
+
+-- 4. 分组下的账户数量统计
+CREATE INDEX IF NOT EXISTS idx_email_accounts_user_group ON email_accounts(user_id, group_id);
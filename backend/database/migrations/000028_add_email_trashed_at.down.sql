@@ -0,0 +1,4 @@
+-- 回滚：移除邮件回收站时间字段
+DROP INDEX IF EXISTS idx_emails_user_trashed;
+
+ALTER TABLE emails DROP COLUMN trashed_at;
@@ -0,0 +1,12 @@
+-- 统一邮件删除语义：回收站 = is_deleted + trashed_at，邮件不再使用 deleted_at 软删除
+
+-- 1. 添加移入回收站的时间
+ALTER TABLE emails ADD COLUMN trashed_at DATETIME;
+
+-- 2. 已在回收站中的邮件以最后更新时间作为删除时间
+UPDATE emails SET trashed_at = updated_at WHERE is_deleted = 1 AND trashed_at IS NULL;
+
+-- 3. 通过 deleted_at 软删除的邮件并入回收站
+UPDATE emails SET is_deleted = 1, trashed_at = deleted_at, deleted_at = NULL WHERE deleted_at IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_emails_user_trashed ON emails(user_id, is_deleted, trashed_at DESC);
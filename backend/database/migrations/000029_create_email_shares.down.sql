@@ -0,0 +1,6 @@
+-- 删除邮件分享链接表
+DROP INDEX IF EXISTS idx_email_shares_deleted_at;
+DROP INDEX IF EXISTS idx_email_shares_expires_at;
+DROP INDEX IF EXISTS idx_email_shares_email_id;
+DROP INDEX IF EXISTS idx_email_shares_user_id;
+DROP TABLE IF EXISTS email_shares;
@@ -0,0 +1,28 @@
+-- 创建邮件分享链接表
+CREATE TABLE IF NOT EXISTS email_shares (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    email_id INTEGER NOT NULL,
+    allow_attachments BOOLEAN NOT NULL DEFAULT 0,
+    expires_at DATETIME NOT NULL,
+    revoked_at DATETIME,
+
+    -- 访问统计
+    access_count INTEGER NOT NULL DEFAULT 0,
+    last_accessed_at DATETIME,
+
+    -- 时间戳
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at DATETIME,
+
+    -- 外键约束
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (email_id) REFERENCES emails(id) ON DELETE CASCADE
+);
+
+-- 创建索引
+CREATE INDEX IF NOT EXISTS idx_email_shares_user_id ON email_shares(user_id);
+CREATE INDEX IF NOT EXISTS idx_email_shares_email_id ON email_shares(email_id);
+CREATE INDEX IF NOT EXISTS idx_email_shares_expires_at ON email_shares(expires_at);
+CREATE INDEX IF NOT EXISTS idx_email_shares_deleted_at ON email_shares(deleted_at);
@@ -0,0 +1,10 @@
+-- 删除邮箱迁移相关表
+DROP INDEX IF EXISTS idx_mailbox_migration_folders_deleted_at;
+DROP INDEX IF EXISTS idx_mailbox_migration_folders_migration_id;
+DROP INDEX IF EXISTS idx_mailbox_migrations_deleted_at;
+DROP INDEX IF EXISTS idx_mailbox_migrations_status;
+DROP INDEX IF EXISTS idx_mailbox_migrations_target_account_id;
+DROP INDEX IF EXISTS idx_mailbox_migrations_source_account_id;
+DROP INDEX IF EXISTS idx_mailbox_migrations_user_id;
+DROP TABLE IF EXISTS mailbox_migration_folders;
+DROP TABLE IF EXISTS mailbox_migrations;
@@ -0,0 +1,69 @@
+-- 创建邮箱迁移任务表
+CREATE TABLE IF NOT EXISTS mailbox_migrations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    source_account_id INTEGER NOT NULL,
+    target_account_id INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'running',
+
+    -- 统计信息
+    total_emails INTEGER DEFAULT 0,
+    copied_emails INTEGER DEFAULT 0,
+    failed_emails INTEGER DEFAULT 0,
+
+    started_at DATETIME,
+    completed_at DATETIME,
+    verified_at DATETIME,
+    last_error TEXT,
+
+    -- 时间戳
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at DATETIME,
+
+    -- 外键约束
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (source_account_id) REFERENCES email_accounts(id) ON DELETE CASCADE,
+    FOREIGN KEY (target_account_id) REFERENCES email_accounts(id) ON DELETE CASCADE
+);
+
+-- 创建迁移文件夹映射表（含断点）
+CREATE TABLE IF NOT EXISTS mailbox_migration_folders (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    migration_id INTEGER NOT NULL,
+    source_path VARCHAR(255) NOT NULL,
+    target_path VARCHAR(255) NOT NULL,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+
+    -- 断点
+    uid_validity INTEGER NOT NULL DEFAULT 0,
+    last_uid INTEGER NOT NULL DEFAULT 0,
+
+    -- 统计信息
+    total_emails INTEGER DEFAULT 0,
+    copied_emails INTEGER DEFAULT 0,
+    failed_emails INTEGER DEFAULT 0,
+
+    -- 校验结果
+    source_count INTEGER,
+    target_count INTEGER,
+    last_error TEXT,
+
+    -- 时间戳
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at DATETIME,
+
+    -- 外键约束
+    FOREIGN KEY (migration_id) REFERENCES mailbox_migrations(id) ON DELETE CASCADE
+);
+
+-- 创建索引
+CREATE INDEX IF NOT EXISTS idx_mailbox_migrations_user_id ON mailbox_migrations(user_id);
+CREATE INDEX IF NOT EXISTS idx_mailbox_migrations_source_account_id ON mailbox_migrations(source_account_id);
+CREATE INDEX IF NOT EXISTS idx_mailbox_migrations_target_account_id ON mailbox_migrations(target_account_id);
+CREATE INDEX IF NOT EXISTS idx_mailbox_migrations_status ON mailbox_migrations(status);
+CREATE INDEX IF NOT EXISTS idx_mailbox_migrations_deleted_at ON mailbox_migrations(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_mailbox_migration_folders_migration_id ON mailbox_migration_folders(migration_id);
+CREATE INDEX IF NOT EXISTS idx_mailbox_migration_folders_deleted_at ON mailbox_migration_folders(deleted_at);
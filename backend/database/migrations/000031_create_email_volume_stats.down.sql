@@ -0,0 +1,4 @@
+-- 删除收发邮件统计表
+DROP INDEX IF EXISTS idx_email_volume_stats_user_id;
+DROP INDEX IF EXISTS idx_email_volume_stats_account_hour;
+DROP TABLE IF EXISTS email_volume_stats;
@@ -0,0 +1,34 @@
+-- 创建按小时汇总的收发邮件统计表，同步新邮件时增量维护
+CREATE TABLE IF NOT EXISTS email_volume_stats (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    account_id INTEGER NOT NULL,
+    hour INTEGER NOT NULL, -- UTC整点的Unix小时数（Unix秒/3600）
+    received INTEGER NOT NULL DEFAULT 0,
+    sent INTEGER NOT NULL DEFAULT 0,
+
+    -- 外键约束
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (account_id) REFERENCES email_accounts(id) ON DELETE CASCADE
+);
+
+-- 创建索引
+CREATE UNIQUE INDEX IF NOT EXISTS idx_email_volume_stats_account_hour ON email_volume_stats(account_id, hour);
+CREATE INDEX IF NOT EXISTS idx_email_volume_stats_user_id ON email_volume_stats(user_id);
+
+-- 根据已有邮件回填统计，发件人为账户本身的邮件计为发出
+INSERT INTO email_volume_stats (user_id, account_id, hour, received, sent)
+SELECT
+    email_accounts.user_id,
+    emails.account_id,
+    CAST(strftime('%s', emails.date) AS INTEGER) / 3600 AS hour,
+    SUM(CASE WHEN LOWER(emails.from_address) = LOWER(email_accounts.email)
+              OR LOWER(emails.from_address) LIKE '%<' || LOWER(email_accounts.email) || '>'
+         THEN 0 ELSE 1 END),
+    SUM(CASE WHEN LOWER(emails.from_address) = LOWER(email_accounts.email)
+              OR LOWER(emails.from_address) LIKE '%<' || LOWER(email_accounts.email) || '>'
+         THEN 1 ELSE 0 END)
+FROM emails
+JOIN email_accounts ON emails.account_id = email_accounts.id
+WHERE emails.date IS NOT NULL AND strftime('%s', emails.date) IS NOT NULL
+GROUP BY emails.account_id, hour;
@@ -0,0 +1,5 @@
+-- 删除邮件操作历史表
+DROP INDEX IF EXISTS idx_email_events_created_at;
+DROP INDEX IF EXISTS idx_email_events_email_id;
+DROP INDEX IF EXISTS idx_email_events_user_id;
+DROP TABLE IF EXISTS email_events;
@@ -0,0 +1,23 @@
+-- 创建邮件操作历史表
+CREATE TABLE IF NOT EXISTS email_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    account_id INTEGER NOT NULL,
+    email_id INTEGER NOT NULL,
+    type VARCHAR(30) NOT NULL, -- synced, read, moved, replied等
+    source VARCHAR(20) NOT NULL, -- user, sync
+    from_folder_id INTEGER,
+    to_folder_id INTEGER,
+    related_email_id INTEGER,
+    details TEXT, -- JSON对象
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    -- 外键约束
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (email_id) REFERENCES emails(id) ON DELETE CASCADE
+);
+
+-- 创建索引
+CREATE INDEX IF NOT EXISTS idx_email_events_user_id ON email_events(user_id);
+CREATE INDEX IF NOT EXISTS idx_email_events_email_id ON email_events(email_id);
+CREATE INDEX IF NOT EXISTS idx_email_events_created_at ON email_events(created_at);
@@ -0,0 +1,5 @@
+-- 删除邮件私有笔记表
+DROP INDEX IF EXISTS idx_email_notes_deleted_at;
+DROP INDEX IF EXISTS idx_email_notes_email_id;
+DROP INDEX IF EXISTS idx_email_notes_user_id;
+DROP TABLE IF EXISTS email_notes;
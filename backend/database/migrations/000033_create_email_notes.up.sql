@@ -0,0 +1,25 @@
+-- 创建邮件私有笔记表
+CREATE TABLE IF NOT EXISTS email_notes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    email_id INTEGER NOT NULL,
+    content TEXT NOT NULL,
+
+    -- IMAP ANNOTATE同步
+    sync_to_server BOOLEAN NOT NULL DEFAULT 0,
+    synced_at DATETIME,
+
+    -- 时间戳
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at DATETIME,
+
+    -- 外键约束
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (email_id) REFERENCES emails(id) ON DELETE CASCADE
+);
+
+-- 创建索引
+CREATE INDEX IF NOT EXISTS idx_email_notes_user_id ON email_notes(user_id);
+CREATE INDEX IF NOT EXISTS idx_email_notes_email_id ON email_notes(email_id);
+CREATE INDEX IF NOT EXISTS idx_email_notes_deleted_at ON email_notes(deleted_at);
@@ -0,0 +1,5 @@
+-- 回滚：移除邮件置顶字段
+DROP INDEX IF EXISTS idx_emails_folder_pinned;
+
+ALTER TABLE emails DROP COLUMN pinned_at;
+ALTER TABLE emails DROP COLUMN is_pinned;
@@ -0,0 +1,5 @@
+-- 邮件置顶：与星标独立，仅保存在本地
+ALTER TABLE emails ADD COLUMN is_pinned BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE emails ADD COLUMN pinned_at DATETIME;
+
+CREATE INDEX IF NOT EXISTS idx_emails_folder_pinned ON emails(folder_id, is_pinned);
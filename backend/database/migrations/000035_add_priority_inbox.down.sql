@@ -0,0 +1,8 @@
+-- 回滚：移除优先收件箱分类
+DROP TABLE IF EXISTS sender_importance;
+
+DROP INDEX IF EXISTS idx_emails_importance_bucket;
+
+ALTER TABLE emails DROP COLUMN importance_manual;
+ALTER TABLE emails DROP COLUMN importance_score;
+ALTER TABLE emails DROP COLUMN importance_bucket;
@@ -0,0 +1,21 @@
+-- 优先收件箱：邮件重要性分类结果
+ALTER TABLE emails ADD COLUMN importance_bucket VARCHAR(20) NOT NULL DEFAULT 'other';
+ALTER TABLE emails ADD COLUMN importance_score INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE emails ADD COLUMN importance_manual BOOLEAN NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_emails_importance_bucket ON emails(importance_bucket);
+
+-- 用户对发件人的重要性反馈，用于训练分类
+CREATE TABLE IF NOT EXISTS sender_importance (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    sender VARCHAR(255) NOT NULL, -- 小写发件人地址
+    important_count INTEGER NOT NULL DEFAULT 0,
+    other_count INTEGER NOT NULL DEFAULT 0,
+    updated_at DATETIME,
+
+    -- 外键约束
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sender_importance_user_sender ON sender_importance(user_id, sender);
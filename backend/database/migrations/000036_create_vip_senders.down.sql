@@ -0,0 +1,7 @@
+-- 回滚：移除VIP发件人和账户通知静音
+ALTER TABLE email_accounts DROP COLUMN notifications_muted;
+
+DROP INDEX IF EXISTS idx_emails_is_vip;
+ALTER TABLE emails DROP COLUMN is_vip;
+
+DROP TABLE IF EXISTS vip_senders;
@@ -0,0 +1,20 @@
+-- 创建VIP发件人表
+CREATE TABLE IF NOT EXISTS vip_senders (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    address VARCHAR(255) NOT NULL, -- 小写邮箱地址
+    name VARCHAR(100),
+    created_at DATETIME,
+
+    -- 外键约束
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_vip_senders_user_address ON vip_senders(user_id, address);
+
+-- 邮件的VIP标记
+ALTER TABLE emails ADD COLUMN is_vip BOOLEAN NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_emails_is_vip ON emails(is_vip);
+
+-- 账户通知静音
+ALTER TABLE email_accounts ADD COLUMN notifications_muted BOOLEAN NOT NULL DEFAULT 0;
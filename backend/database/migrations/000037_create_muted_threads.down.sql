@@ -0,0 +1,5 @@
+-- 回滚：移除静音会话
+DROP TABLE IF EXISTS muted_threads;
+
+DROP INDEX IF EXISTS idx_emails_thread_id;
+ALTER TABLE emails DROP COLUMN thread_id;
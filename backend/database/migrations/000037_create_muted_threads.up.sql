@@ -0,0 +1,18 @@
+-- 邮件会话ID，已有邮件以自身Message-ID作为会话ID
+ALTER TABLE emails ADD COLUMN thread_id VARCHAR(255);
+UPDATE emails SET thread_id = message_id WHERE thread_id IS NULL;
+CREATE INDEX IF NOT EXISTS idx_emails_thread_id ON emails(thread_id);
+
+-- 创建静音会话表
+CREATE TABLE IF NOT EXISTS muted_threads (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    thread_id VARCHAR(255) NOT NULL,
+    subject VARCHAR(500),
+    created_at DATETIME,
+
+    -- 外键约束
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_muted_threads_user_thread ON muted_threads(user_id, thread_id);
@@ -0,0 +1,2 @@
+-- 回滚：删除屏蔽发件人表
+DROP TABLE IF EXISTS blocked_senders;
@@ -0,0 +1,13 @@
+-- 创建屏蔽发件人表
+CREATE TABLE IF NOT EXISTS blocked_senders (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    pattern VARCHAR(255) NOT NULL, -- 小写邮箱地址，或以@开头的域名
+    action VARCHAR(20) NOT NULL DEFAULT 'trash', -- trash, spam
+    created_at DATETIME,
+
+    -- 外键约束
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_blocked_senders_user_pattern ON blocked_senders(user_id, pattern);
@@ -0,0 +1,3 @@
+-- 回滚：删除邮件保留规则相关表
+DROP TABLE IF EXISTS retention_runs;
+DROP TABLE IF EXISTS retention_policies;
@@ -0,0 +1,48 @@
+-- 创建邮件保留规则表
+CREATE TABLE IF NOT EXISTS retention_policies (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    account_id INTEGER NOT NULL,
+    folder_id INTEGER, -- 为空时适用于账户的所有文件夹
+    name VARCHAR(100) NOT NULL,
+    action VARCHAR(20) NOT NULL DEFAULT 'delete', -- delete, archive
+    older_than_days INTEGER NOT NULL,
+    unread_only BOOLEAN NOT NULL DEFAULT 0,
+    newsletters BOOLEAN NOT NULL DEFAULT 0,
+    is_enabled BOOLEAN NOT NULL DEFAULT 1,
+    last_run_at DATETIME,
+    created_at DATETIME,
+    updated_at DATETIME,
+
+    -- 外键约束
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (account_id) REFERENCES email_accounts(id) ON DELETE CASCADE,
+    FOREIGN KEY (folder_id) REFERENCES folders(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_retention_policies_user_id ON retention_policies(user_id);
+CREATE INDEX IF NOT EXISTS idx_retention_policies_account_id ON retention_policies(account_id);
+CREATE INDEX IF NOT EXISTS idx_retention_policies_folder_id ON retention_policies(folder_id);
+
+-- 创建保留规则执行记录表
+CREATE TABLE IF NOT EXISTS retention_runs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    policy_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    triggered_by VARCHAR(20) NOT NULL, -- scheduled, manual
+    status VARCHAR(20) NOT NULL, -- running, completed, failed, cancelled
+    matched INTEGER NOT NULL DEFAULT 0,
+    succeeded INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    freed_bytes INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    started_at DATETIME,
+    finished_at DATETIME,
+
+    -- 外键约束
+    FOREIGN KEY (policy_id) REFERENCES retention_policies(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_retention_runs_policy_id ON retention_runs(policy_id);
+CREATE INDEX IF NOT EXISTS idx_retention_runs_user_id ON retention_runs(user_id);
@@ -0,0 +1,3 @@
+-- 回滚：删除法律保全相关表
+DROP TABLE IF EXISTS legal_hold_exports;
+DROP TABLE IF EXISTS legal_holds;
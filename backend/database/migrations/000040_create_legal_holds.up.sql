@@ -0,0 +1,46 @@
+-- 创建法律保全表
+CREATE TABLE IF NOT EXISTS legal_holds (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    senders TEXT, -- JSON数组，小写邮箱地址或以@开头的域名
+    account_ids TEXT, -- JSON数组，为空时适用于所有账户
+    since DATETIME,
+    until DATETIME,
+    released_at DATETIME,
+    created_at DATETIME,
+    updated_at DATETIME,
+
+    -- 外键约束
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_legal_holds_user_id ON legal_holds(user_id);
+CREATE INDEX IF NOT EXISTS idx_legal_holds_released_at ON legal_holds(released_at);
+
+-- 创建法律保全导出表
+CREATE TABLE IF NOT EXISTS legal_hold_exports (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    hold_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL, -- running, completed, failed
+    total_emails INTEGER NOT NULL DEFAULT 0,
+    exported_emails INTEGER NOT NULL DEFAULT 0,
+    local_emails INTEGER NOT NULL DEFAULT 0,
+    file_path VARCHAR(500),
+    file_size INTEGER NOT NULL DEFAULT 0,
+    archive_sha256 VARCHAR(64),
+    manifest_sha256 VARCHAR(64),
+    signature VARCHAR(64),
+    last_error TEXT,
+    started_at DATETIME,
+    finished_at DATETIME,
+
+    -- 外键约束
+    FOREIGN KEY (hold_id) REFERENCES legal_holds(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_legal_hold_exports_hold_id ON legal_hold_exports(hold_id);
+CREATE INDEX IF NOT EXISTS idx_legal_hold_exports_user_id ON legal_hold_exports(user_id);
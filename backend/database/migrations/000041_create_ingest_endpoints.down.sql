@@ -0,0 +1,2 @@
+-- 回滚：删除入站邮件端点表
+DROP TABLE IF EXISTS ingest_endpoints;
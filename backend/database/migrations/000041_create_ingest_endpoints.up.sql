@@ -0,0 +1,24 @@
+-- 创建入站邮件端点表
+CREATE TABLE IF NOT EXISTS ingest_endpoints (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    account_id INTEGER NOT NULL,
+    folder_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL, -- 令牌的SHA-256
+    token_prefix VARCHAR(16) NOT NULL,
+    is_enabled BOOLEAN NOT NULL DEFAULT 1,
+    received_count INTEGER NOT NULL DEFAULT 0,
+    last_used_at DATETIME,
+    created_at DATETIME,
+    updated_at DATETIME,
+
+    -- 外键约束
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (account_id) REFERENCES email_accounts(id) ON DELETE CASCADE,
+    FOREIGN KEY (folder_id) REFERENCES folders(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ingest_endpoints_token_hash ON ingest_endpoints(token_hash);
+CREATE INDEX IF NOT EXISTS idx_ingest_endpoints_user_id ON ingest_endpoints(user_id);
+CREATE INDEX IF NOT EXISTS idx_ingest_endpoints_account_id ON ingest_endpoints(account_id);
@@ -0,0 +1,4 @@
+-- 回滚：移除入站端点的SMTP收件地址
+DROP INDEX IF EXISTS idx_ingest_endpoints_address;
+
+ALTER TABLE ingest_endpoints DROP COLUMN address;
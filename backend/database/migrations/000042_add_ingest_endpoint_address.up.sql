@@ -0,0 +1,4 @@
+-- 入站端点的SMTP收件地址：内置SMTP服务器按收件人地址（或@域名）投递到对应端点
+ALTER TABLE ingest_endpoints ADD COLUMN address VARCHAR(255);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ingest_endpoints_address ON ingest_endpoints(address);
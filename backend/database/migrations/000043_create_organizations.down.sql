@@ -0,0 +1,7 @@
+-- 回滚：删除组织及共享邮箱相关表
+DROP TABLE IF EXISTS email_assignments;
+DROP TABLE IF EXISTS shared_mailbox_grants;
+DROP TABLE IF EXISTS shared_mailboxes;
+DROP TABLE IF EXISTS organization_invites;
+DROP TABLE IF EXISTS organization_members;
+DROP TABLE IF EXISTS organizations;
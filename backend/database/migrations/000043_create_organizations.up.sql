@@ -0,0 +1,94 @@
+-- 创建组织及共享邮箱相关表
+CREATE TABLE IF NOT EXISTS organizations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(100) NOT NULL,
+    created_by INTEGER NOT NULL,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+
+CREATE TABLE IF NOT EXISTS organization_members (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    organization_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    role VARCHAR(20) NOT NULL, -- owner, admin, member
+    created_at DATETIME,
+    updated_at DATETIME,
+
+    -- 外键约束
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_organization_members_org_user ON organization_members(organization_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_organization_members_user_id ON organization_members(user_id);
+
+CREATE TABLE IF NOT EXISTS organization_invites (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    organization_id INTEGER NOT NULL,
+    invitee_id INTEGER NOT NULL,
+    invited_by INTEGER NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, accepted, declined, revoked
+    expires_at DATETIME NOT NULL,
+    responded_at DATETIME,
+    created_at DATETIME,
+
+    -- 外键约束
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    FOREIGN KEY (invitee_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_invites_organization_id ON organization_invites(organization_id);
+CREATE INDEX IF NOT EXISTS idx_organization_invites_invitee_id ON organization_invites(invitee_id);
+
+CREATE TABLE IF NOT EXISTS shared_mailboxes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    organization_id INTEGER NOT NULL,
+    account_id INTEGER NOT NULL,
+    shared_by INTEGER NOT NULL,
+    created_at DATETIME,
+
+    -- 外键约束
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    FOREIGN KEY (account_id) REFERENCES email_accounts(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_shared_mailboxes_account_id ON shared_mailboxes(account_id);
+CREATE INDEX IF NOT EXISTS idx_shared_mailboxes_organization_id ON shared_mailboxes(organization_id);
+
+CREATE TABLE IF NOT EXISTS shared_mailbox_grants (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    shared_mailbox_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    permission VARCHAR(20) NOT NULL, -- read_only, send_as
+    created_at DATETIME,
+    updated_at DATETIME,
+
+    -- 外键约束
+    FOREIGN KEY (shared_mailbox_id) REFERENCES shared_mailboxes(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_shared_mailbox_grants_mailbox_user ON shared_mailbox_grants(shared_mailbox_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_shared_mailbox_grants_user_id ON shared_mailbox_grants(user_id);
+
+CREATE TABLE IF NOT EXISTS email_assignments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    shared_mailbox_id INTEGER NOT NULL,
+    email_id INTEGER NOT NULL,
+    assignee_id INTEGER NOT NULL,
+    assigned_by INTEGER NOT NULL,
+    created_at DATETIME,
+    updated_at DATETIME,
+
+    -- 外键约束
+    FOREIGN KEY (shared_mailbox_id) REFERENCES shared_mailboxes(id) ON DELETE CASCADE,
+    FOREIGN KEY (email_id) REFERENCES emails(id) ON DELETE CASCADE,
+    FOREIGN KEY (assignee_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- 唯一索引保证并发认领时只有一人成功
+CREATE UNIQUE INDEX IF NOT EXISTS idx_email_assignments_email_id ON email_assignments(email_id);
+CREATE INDEX IF NOT EXISTS idx_email_assignments_shared_mailbox_id ON email_assignments(shared_mailbox_id);
+CREATE INDEX IF NOT EXISTS idx_email_assignments_assignee_id ON email_assignments(assignee_id);
@@ -0,0 +1,2 @@
+-- 回滚：删除用户设置表
+DROP TABLE IF EXISTS user_settings;
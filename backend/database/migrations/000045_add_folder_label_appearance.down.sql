@@ -0,0 +1,8 @@
+-- 回滚：删除标签显示属性表，移除文件夹的显示属性
+DROP TABLE IF EXISTS labels;
+
+DROP INDEX IF EXISTS idx_folders_sort_order;
+
+ALTER TABLE folders DROP COLUMN sort_order;
+ALTER TABLE folders DROP COLUMN icon;
+ALTER TABLE folders DROP COLUMN color;
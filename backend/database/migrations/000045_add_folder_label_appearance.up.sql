@@ -0,0 +1,23 @@
+-- 文件夹与标签的显示属性：颜色、图标和显示顺序保存在服务端，使多个客户端显示一致
+ALTER TABLE folders ADD COLUMN color VARCHAR(20);
+ALTER TABLE folders ADD COLUMN icon VARCHAR(50);
+ALTER TABLE folders ADD COLUMN sort_order INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_folders_sort_order ON folders(sort_order);
+
+-- 标签本身保存在邮件的 labels 字段中，这里只记录显示属性
+CREATE TABLE IF NOT EXISTS labels (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    account_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    color VARCHAR(20),
+    icon VARCHAR(50),
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME,
+    updated_at DATETIME,
+
+    -- 外键约束
+    FOREIGN KEY (account_id) REFERENCES email_accounts(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_labels_account_name ON labels(account_id, name);
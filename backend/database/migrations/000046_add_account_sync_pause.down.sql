@@ -0,0 +1,3 @@
+-- 回滚：移除账户同步暂停字段
+ALTER TABLE email_accounts DROP COLUMN sync_paused_at;
+ALTER TABLE email_accounts DROP COLUMN sync_paused;
@@ -0,0 +1,3 @@
+-- 账户级同步暂停：暂停期间计划同步和手动同步都会被拒绝
+ALTER TABLE email_accounts ADD COLUMN sync_paused BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE email_accounts ADD COLUMN sync_paused_at DATETIME;
@@ -0,0 +1,5 @@
+-- 回滚：删除同步冲突表
+DROP INDEX IF EXISTS idx_sync_conflicts_status;
+DROP INDEX IF EXISTS idx_sync_conflicts_email_id;
+DROP INDEX IF EXISTS idx_sync_conflicts_user_id;
+DROP TABLE IF EXISTS sync_conflicts;
@@ -0,0 +1,26 @@
+-- 同步冲突：同一封邮件在两次同步之间被本地和其他设备改成不同状态时记录
+CREATE TABLE IF NOT EXISTS sync_conflicts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    account_id INTEGER NOT NULL,
+    email_id INTEGER NOT NULL,
+    fields VARCHAR(100) NOT NULL,
+    local_state TEXT NOT NULL,
+    remote_state TEXT NOT NULL,
+    remote_uid INTEGER NOT NULL DEFAULT 0,
+    policy VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    resolution VARCHAR(20),
+    resolved_at DATETIME,
+    created_at DATETIME,
+    updated_at DATETIME,
+
+    -- 外键约束
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (account_id) REFERENCES email_accounts(id) ON DELETE CASCADE,
+    FOREIGN KEY (email_id) REFERENCES emails(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_sync_conflicts_user_id ON sync_conflicts(user_id);
+CREATE INDEX IF NOT EXISTS idx_sync_conflicts_email_id ON sync_conflicts(email_id);
+CREATE INDEX IF NOT EXISTS idx_sync_conflicts_status ON sync_conflicts(status);
@@ -0,0 +1,5 @@
+-- 回滚：移除邮件列表摘要字段
+ALTER TABLE emails DROP COLUMN sender_avatar_hash;
+ALTER TABLE emails DROP COLUMN sender_initials;
+ALTER TABLE emails DROP COLUMN attachment_count;
+ALTER TABLE emails DROP COLUMN preview;
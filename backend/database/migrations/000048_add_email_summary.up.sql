@@ -0,0 +1,10 @@
+-- 邮件列表摘要：预览文本、附件数和发件人头像信息，保存邮件时生成，列表接口 view=summary 时无需读取正文
+-- preview 为 NULL 表示历史邮件尚未生成摘要，首次按摘要列出时补齐
+ALTER TABLE emails ADD COLUMN preview TEXT;
+ALTER TABLE emails ADD COLUMN attachment_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE emails ADD COLUMN sender_initials VARCHAR(8) NOT NULL DEFAULT '';
+ALTER TABLE emails ADD COLUMN sender_avatar_hash VARCHAR(32) NOT NULL DEFAULT '';
+
+UPDATE emails SET attachment_count = (
+    SELECT COUNT(*) FROM attachments WHERE attachments.email_id = emails.id AND attachments.deleted_at IS NULL
+);
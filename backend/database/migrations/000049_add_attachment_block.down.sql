@@ -0,0 +1,3 @@
+-- 回滚：移除附件拦截字段
+ALTER TABLE attachments DROP COLUMN block_reason;
+ALTER TABLE attachments DROP COLUMN is_blocked;
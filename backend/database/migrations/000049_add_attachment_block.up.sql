@@ -0,0 +1,3 @@
+-- 附件安全策略：扩展名在禁止列表中或内容为可执行程序的附件标记为已拦截，禁止下载和转发
+ALTER TABLE attachments ADD COLUMN is_blocked BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE attachments ADD COLUMN block_reason VARCHAR(255) NOT NULL DEFAULT '';
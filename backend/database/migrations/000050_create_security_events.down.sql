@@ -0,0 +1,4 @@
+-- 回滚：删除安全事件表
+DROP INDEX IF EXISTS idx_security_events_ip;
+DROP INDEX IF EXISTS idx_security_events_type_created;
+DROP TABLE IF EXISTS security_events;
@@ -0,0 +1,16 @@
+-- 安全事件：被IP白名单或地区限制拒绝的访问等，供管理员审计
+CREATE TABLE IF NOT EXISTS security_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_type VARCHAR(50) NOT NULL,
+    ip VARCHAR(45) NOT NULL,
+    username VARCHAR(100) NOT NULL DEFAULT '',
+    method VARCHAR(10) NOT NULL DEFAULT '',
+    path VARCHAR(500) NOT NULL DEFAULT '',
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    country_code VARCHAR(2) NOT NULL DEFAULT '',
+    user_agent VARCHAR(500) NOT NULL DEFAULT '',
+    created_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_security_events_type_created ON security_events(event_type, created_at);
+CREATE INDEX IF NOT EXISTS idx_security_events_ip ON security_events(ip);
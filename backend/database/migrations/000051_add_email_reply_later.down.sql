@@ -0,0 +1,6 @@
+-- 回滚：移除稍后回复字段
+DROP INDEX IF EXISTS idx_emails_reply_later_remind;
+DROP INDEX IF EXISTS idx_emails_user_reply_later;
+
+ALTER TABLE emails DROP COLUMN reply_later_remind_at;
+ALTER TABLE emails DROP COLUMN reply_later_at;
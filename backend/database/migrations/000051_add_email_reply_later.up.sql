@@ -0,0 +1,6 @@
+-- 稍后回复：与置顶、已读状态独立，仅保存在本地
+ALTER TABLE emails ADD COLUMN reply_later_at DATETIME;
+ALTER TABLE emails ADD COLUMN reply_later_remind_at DATETIME;
+
+CREATE INDEX IF NOT EXISTS idx_emails_user_reply_later ON emails(user_id, reply_later_at);
+CREATE INDEX IF NOT EXISTS idx_emails_reply_later_remind ON emails(reply_later_remind_at);
@@ -0,0 +1,6 @@
+-- 回滚：删除通知快捷操作令牌表
+DROP INDEX IF EXISTS idx_notification_action_tokens_expires;
+DROP INDEX IF EXISTS idx_notification_action_tokens_email;
+DROP INDEX IF EXISTS idx_notification_action_tokens_user;
+DROP INDEX IF EXISTS idx_notification_action_tokens_hash;
+DROP TABLE IF EXISTS notification_action_tokens;
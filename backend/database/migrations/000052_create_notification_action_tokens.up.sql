@@ -0,0 +1,18 @@
+-- 通知快捷操作令牌：新邮件通知附带的归档、标记已读、删除链接，每个令牌只能使用一次
+CREATE TABLE IF NOT EXISTS notification_action_tokens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    email_id INTEGER NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    expires_at DATETIME NOT NULL,
+    used_at DATETIME,
+    created_at DATETIME,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (email_id) REFERENCES emails(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notification_action_tokens_hash ON notification_action_tokens(token_hash);
+CREATE INDEX IF NOT EXISTS idx_notification_action_tokens_user ON notification_action_tokens(user_id);
+CREATE INDEX IF NOT EXISTS idx_notification_action_tokens_email ON notification_action_tokens(email_id);
+CREATE INDEX IF NOT EXISTS idx_notification_action_tokens_expires ON notification_action_tokens(expires_at);
@@ -0,0 +1,3 @@
+-- 回滚：移除Gmail标签映射字段
+ALTER TABLE folders DROP COLUMN last_merged_uid;
+ALTER TABLE folders DROP COLUMN label_mode;
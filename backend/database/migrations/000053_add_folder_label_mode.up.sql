@@ -0,0 +1,4 @@
+-- Gmail标签映射方式：folder（独立文件夹）、merge（合并为邮件标签）、skip（不同步），为空时使用默认方式
+ALTER TABLE folders ADD COLUMN label_mode VARCHAR(10) NOT NULL DEFAULT '';
+-- 合并方式的文件夹不保存邮件，记录已处理到的最大UID作为增量同步起点
+ALTER TABLE folders ADD COLUMN last_merged_uid INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+-- 回滚：移除发件别名字段
+ALTER TABLE email_accounts DROP COLUMN send_aliases;
@@ -0,0 +1,2 @@
+-- 发件别名（JSON数组格式），如iCloud自定义域名地址、隐藏邮件地址
+ALTER TABLE email_accounts ADD COLUMN send_aliases TEXT;
@@ -0,0 +1,2 @@
+-- 回滚：删除代收外部邮箱表
+DROP TABLE IF EXISTS mail_fetchers;
@@ -0,0 +1,35 @@
+-- 创建代收外部邮箱表
+CREATE TABLE IF NOT EXISTS mail_fetchers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    account_id INTEGER NOT NULL,
+    folder_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    protocol VARCHAR(10) NOT NULL, -- pop3, imap
+    host VARCHAR(255) NOT NULL,
+    port INTEGER NOT NULL,
+    security VARCHAR(20) NOT NULL DEFAULT 'SSL',
+    username VARCHAR(255) NOT NULL,
+    password VARCHAR(255),
+    source_folder VARCHAR(255) NOT NULL DEFAULT 'INBOX',
+    delete_after_fetch BOOLEAN NOT NULL DEFAULT 0,
+    label VARCHAR(100),
+    interval_minutes INTEGER NOT NULL DEFAULT 10,
+    is_enabled BOOLEAN NOT NULL DEFAULT 1,
+    uid_validity INTEGER NOT NULL DEFAULT 0,
+    last_uid INTEGER NOT NULL DEFAULT 0,
+    fetched_uidls TEXT, -- POP3保留邮件时已收取邮件的UIDL
+    fetched_count INTEGER NOT NULL DEFAULT 0,
+    last_run_at DATETIME,
+    last_error TEXT,
+    created_at DATETIME,
+    updated_at DATETIME,
+
+    -- 外键约束
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (account_id) REFERENCES email_accounts(id) ON DELETE CASCADE,
+    FOREIGN KEY (folder_id) REFERENCES folders(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_mail_fetchers_user_id ON mail_fetchers(user_id);
+CREATE INDEX IF NOT EXISTS idx_mail_fetchers_account_id ON mail_fetchers(account_id);
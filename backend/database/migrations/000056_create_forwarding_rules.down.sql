@@ -0,0 +1,2 @@
+-- 回滚：删除自动转发规则表
+DROP TABLE IF EXISTS forwarding_rules;
@@ -0,0 +1,26 @@
+-- 创建自动转发规则表
+CREATE TABLE IF NOT EXISTS forwarding_rules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    account_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    forward_to VARCHAR(255) NOT NULL,
+    mode VARCHAR(20) NOT NULL DEFAULT 'inline', -- inline, attachment
+    from_contains VARCHAR(255),
+    subject_contains VARCHAR(255),
+    is_enabled BOOLEAN NOT NULL DEFAULT 1,
+    forwarded_count INTEGER NOT NULL DEFAULT 0,
+    failed_count INTEGER NOT NULL DEFAULT 0,
+    loop_count INTEGER NOT NULL DEFAULT 0,
+    last_forwarded_at DATETIME,
+    last_error TEXT,
+    created_at DATETIME,
+    updated_at DATETIME,
+
+    -- 外键约束
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (account_id) REFERENCES email_accounts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_forwarding_rules_user_id ON forwarding_rules(user_id);
+CREATE INDEX IF NOT EXISTS idx_forwarding_rules_account_id ON forwarding_rules(account_id);
@@ -0,0 +1,5 @@
+-- 回滚：移除发送队列的MIME原文和信封字段
+ALTER TABLE send_queue DROP COLUMN subject;
+ALTER TABLE send_queue DROP COLUMN recipients;
+ALTER TABLE send_queue DROP COLUMN envelope_from;
+ALTER TABLE send_queue DROP COLUMN raw_message;
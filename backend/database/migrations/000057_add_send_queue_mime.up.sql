@@ -0,0 +1,5 @@
+-- 发送队列保存待投递的MIME原文和信封信息，进程重启后可继续发送
+ALTER TABLE send_queue ADD COLUMN raw_message BLOB;
+ALTER TABLE send_queue ADD COLUMN envelope_from VARCHAR(255);
+ALTER TABLE send_queue ADD COLUMN recipients TEXT;
+ALTER TABLE send_queue ADD COLUMN subject VARCHAR(500);
@@ -0,0 +1,4 @@
+-- 回滚：移除账户的外发邮件标识字段
+ALTER TABLE email_accounts DROP COLUMN mailer;
+ALTER TABLE email_accounts DROP COLUMN message_id_domain;
+ALTER TABLE email_accounts DROP COLUMN ehlo_name;
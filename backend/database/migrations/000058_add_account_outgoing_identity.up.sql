@@ -0,0 +1,4 @@
+-- 账户的外发邮件标识：SMTP问候名、Message-ID域名和客户端名称，为空时使用实例配置
+ALTER TABLE email_accounts ADD COLUMN ehlo_name VARCHAR(255);
+ALTER TABLE email_accounts ADD COLUMN message_id_domain VARCHAR(255);
+ALTER TABLE email_accounts ADD COLUMN mailer VARCHAR(100);
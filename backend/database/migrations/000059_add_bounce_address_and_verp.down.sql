@@ -0,0 +1,3 @@
+-- 回滚：移除退信地址和VERP设置
+ALTER TABLE mail_merge_campaigns DROP COLUMN use_verp;
+ALTER TABLE email_accounts DROP COLUMN bounce_address;
@@ -0,0 +1,3 @@
+-- 退信地址：账户的默认SMTP信封发件人，邮件合并活动可以使用VERP地址
+ALTER TABLE email_accounts ADD COLUMN bounce_address VARCHAR(255);
+ALTER TABLE mail_merge_campaigns ADD COLUMN use_verp BOOLEAN NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+-- 回滚：删除快捷回复表
+DROP TABLE IF EXISTS canned_responses;
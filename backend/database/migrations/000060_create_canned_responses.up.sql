@@ -0,0 +1,20 @@
+-- 创建快捷回复表
+CREATE TABLE IF NOT EXISTS canned_responses (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    shortcut VARCHAR(50),
+    text_body TEXT NOT NULL,
+    html_body TEXT,
+    folder_ids TEXT, -- JSON数组，在这些文件夹中回复时优先推荐
+    usage_count INTEGER NOT NULL DEFAULT 0,
+    last_used_at DATETIME,
+    created_at DATETIME,
+    updated_at DATETIME,
+
+    -- 外键约束
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_canned_responses_user_id ON canned_responses(user_id);
+CREATE INDEX IF NOT EXISTS idx_canned_responses_shortcut ON canned_responses(shortcut);
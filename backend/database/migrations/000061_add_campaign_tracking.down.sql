@@ -0,0 +1,14 @@
+-- 回滚：移除活动打开和点击跟踪
+DROP TABLE IF EXISTS tracking_opt_outs;
+DROP TABLE IF EXISTS mail_merge_links;
+DROP INDEX IF EXISTS idx_mail_merge_recipients_tracking_token;
+ALTER TABLE mail_merge_recipients DROP COLUMN first_clicked_at;
+ALTER TABLE mail_merge_recipients DROP COLUMN first_opened_at;
+ALTER TABLE mail_merge_recipients DROP COLUMN click_count;
+ALTER TABLE mail_merge_recipients DROP COLUMN open_count;
+ALTER TABLE mail_merge_recipients DROP COLUMN tracking_excluded;
+ALTER TABLE mail_merge_recipients DROP COLUMN tracking_token;
+ALTER TABLE mail_merge_campaigns DROP COLUMN clicked_count;
+ALTER TABLE mail_merge_campaigns DROP COLUMN opened_count;
+ALTER TABLE mail_merge_campaigns DROP COLUMN track_clicks;
+ALTER TABLE mail_merge_campaigns DROP COLUMN track_opens;
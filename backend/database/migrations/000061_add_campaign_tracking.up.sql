@@ -0,0 +1,41 @@
+-- 邮件合并活动的打开和点击跟踪（需显式开启）
+ALTER TABLE mail_merge_campaigns ADD COLUMN track_opens BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE mail_merge_campaigns ADD COLUMN track_clicks BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE mail_merge_campaigns ADD COLUMN opened_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE mail_merge_campaigns ADD COLUMN clicked_count INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE mail_merge_recipients ADD COLUMN tracking_token VARCHAR(64);
+ALTER TABLE mail_merge_recipients ADD COLUMN tracking_excluded BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE mail_merge_recipients ADD COLUMN open_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE mail_merge_recipients ADD COLUMN click_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE mail_merge_recipients ADD COLUMN first_opened_at DATETIME;
+ALTER TABLE mail_merge_recipients ADD COLUMN first_clicked_at DATETIME;
+
+CREATE INDEX IF NOT EXISTS idx_mail_merge_recipients_tracking_token ON mail_merge_recipients(tracking_token);
+
+-- 活动邮件中被改写的链接
+CREATE TABLE IF NOT EXISTS mail_merge_links (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    campaign_id INTEGER NOT NULL,
+    url TEXT NOT NULL,
+    click_count INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME,
+
+    -- 外键约束
+    FOREIGN KEY (campaign_id) REFERENCES mail_merge_campaigns(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_mail_merge_links_campaign_id ON mail_merge_links(campaign_id);
+
+-- 不跟踪名单：命中的收件人不插入跟踪像素，也不改写链接
+CREATE TABLE IF NOT EXISTS tracking_opt_outs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    pattern VARCHAR(255) NOT NULL, -- 小写邮箱地址，或以@开头的域名
+    created_at DATETIME,
+
+    -- 外键约束
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tracking_opt_outs_user_pattern ON tracking_opt_outs(user_id, pattern);
@@ -0,0 +1,2 @@
+-- 回滚：删除工作进程领导者租约表
+DROP TABLE IF EXISTS worker_leases;
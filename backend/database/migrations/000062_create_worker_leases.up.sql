@@ -0,0 +1,7 @@
+-- 创建工作进程领导者租约表
+CREATE TABLE IF NOT EXISTS worker_leases (
+    name VARCHAR(100) PRIMARY KEY,
+    holder VARCHAR(255) NOT NULL,
+    expires_at DATETIME NOT NULL,
+    updated_at DATETIME
+);
@@ -0,0 +1,2 @@
+-- 回滚：删除后台任务队列表
+DROP TABLE IF EXISTS jobs;
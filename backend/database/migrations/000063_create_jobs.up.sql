@@ -0,0 +1,23 @@
+-- 创建后台任务队列表
+CREATE TABLE IF NOT EXISTS jobs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    type VARCHAR(100) NOT NULL,
+    payload TEXT, -- JSON格式的任务参数
+    user_id INTEGER,
+    unique_key VARCHAR(255), -- 同一键的任务未结束时不重复入队
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, running, succeeded, dead, cancelled
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 5,
+    run_at DATETIME NOT NULL,
+    locked_by VARCHAR(255),
+    locked_at DATETIME,
+    last_error TEXT,
+    finished_at DATETIME,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_type ON jobs(type);
+CREATE INDEX IF NOT EXISTS idx_jobs_user_id ON jobs(user_id);
+CREATE INDEX IF NOT EXISTS idx_jobs_unique_key ON jobs(unique_key);
+CREATE INDEX IF NOT EXISTS idx_jobs_status_run_at ON jobs(status, run_at);
@@ -0,0 +1,6 @@
+-- 删除附件下载链接表
+DROP INDEX IF EXISTS idx_attachment_links_deleted_at;
+DROP INDEX IF EXISTS idx_attachment_links_expires_at;
+DROP INDEX IF EXISTS idx_attachment_links_attachment_id;
+DROP INDEX IF EXISTS idx_attachment_links_user_id;
+DROP TABLE IF EXISTS attachment_links;
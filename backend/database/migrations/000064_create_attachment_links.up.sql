@@ -0,0 +1,26 @@
+-- 创建附件下载链接表
+CREATE TABLE IF NOT EXISTS attachment_links (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    attachment_id INTEGER NOT NULL,
+    expires_at DATETIME NOT NULL,
+
+    -- 下载统计
+    download_count INTEGER NOT NULL DEFAULT 0,
+    last_downloaded_at DATETIME,
+
+    -- 时间戳
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at DATETIME,
+
+    -- 外键约束
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (attachment_id) REFERENCES attachments(id) ON DELETE CASCADE
+);
+
+-- 创建索引
+CREATE INDEX IF NOT EXISTS idx_attachment_links_user_id ON attachment_links(user_id);
+CREATE INDEX IF NOT EXISTS idx_attachment_links_attachment_id ON attachment_links(attachment_id);
+CREATE INDEX IF NOT EXISTS idx_attachment_links_expires_at ON attachment_links(expires_at);
+CREATE INDEX IF NOT EXISTS idx_attachment_links_deleted_at ON attachment_links(deleted_at);
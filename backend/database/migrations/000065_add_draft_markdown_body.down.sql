@@ -0,0 +1,3 @@
+-- 回滚：移除草稿的Markdown源文本
+ALTER TABLE draft_revisions DROP COLUMN markdown_body;
+ALTER TABLE drafts DROP COLUMN markdown_body;
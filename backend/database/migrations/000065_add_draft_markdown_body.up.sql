@@ -0,0 +1,3 @@
+-- Markdown撰写：保存草稿的Markdown源文本，便于再次编辑
+ALTER TABLE drafts ADD COLUMN markdown_body TEXT;
+ALTER TABLE draft_revisions ADD COLUMN markdown_body TEXT;
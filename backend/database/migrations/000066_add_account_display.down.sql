@@ -0,0 +1,7 @@
+-- 回滚：移除账户显示设置
+DROP INDEX IF EXISTS idx_email_accounts_display_order;
+ALTER TABLE email_accounts DROP COLUMN display_order;
+ALTER TABLE email_accounts DROP COLUMN avatar_initials;
+ALTER TABLE email_accounts DROP COLUMN avatar_emoji;
+ALTER TABLE email_accounts DROP COLUMN color;
+ALTER TABLE email_accounts DROP COLUMN nickname;
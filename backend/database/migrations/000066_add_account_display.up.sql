@@ -0,0 +1,7 @@
+-- 账户显示设置：昵称、颜色、头像和显示顺序，在各设备上保持一致
+ALTER TABLE email_accounts ADD COLUMN nickname VARCHAR(100);
+ALTER TABLE email_accounts ADD COLUMN color VARCHAR(7);
+ALTER TABLE email_accounts ADD COLUMN avatar_emoji VARCHAR(32);
+ALTER TABLE email_accounts ADD COLUMN avatar_initials VARCHAR(12);
+ALTER TABLE email_accounts ADD COLUMN display_order INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_email_accounts_display_order ON email_accounts(display_order);
@@ -0,0 +1,2 @@
+-- 回滚：移除文件夹类型锁定标记
+ALTER TABLE folders DROP COLUMN type_locked;
@@ -0,0 +1,2 @@
+-- 文件夹类型可由用户手动指定，锁定后同步不再覆盖
+ALTER TABLE folders ADD COLUMN type_locked BOOLEAN NOT NULL DEFAULT 0;
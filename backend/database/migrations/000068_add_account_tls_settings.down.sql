@@ -0,0 +1,5 @@
+-- 回滚：移除账户的TLS设置
+ALTER TABLE email_accounts DROP COLUMN tls_pinned_keys;
+ALTER TABLE email_accounts DROP COLUMN tls_insecure_skip_verify;
+ALTER TABLE email_accounts DROP COLUMN tls_ca_cert;
+ALTER TABLE email_accounts DROP COLUMN tls_min_version;
@@ -0,0 +1,5 @@
+-- 账户的TLS设置：最低版本、自定义CA、跳过证书校验和证书公钥固定
+ALTER TABLE email_accounts ADD COLUMN tls_min_version VARCHAR(10);
+ALTER TABLE email_accounts ADD COLUMN tls_ca_cert TEXT;
+ALTER TABLE email_accounts ADD COLUMN tls_insecure_skip_verify BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE email_accounts ADD COLUMN tls_pinned_keys TEXT;
@@ -0,0 +1,2 @@
+-- 回滚：移除账户的DNS解析器设置
+ALTER TABLE email_accounts DROP COLUMN dns_resolver;
@@ -0,0 +1,2 @@
+-- 账户的DNS解析器：DNS服务器地址或DoH地址，为空时使用全局设置
+ALTER TABLE email_accounts ADD COLUMN dns_resolver VARCHAR(255);
@@ -0,0 +1,3 @@
+-- 回滚：移除乐观锁版本号
+ALTER TABLE email_accounts DROP COLUMN version;
+ALTER TABLE emails DROP COLUMN version;
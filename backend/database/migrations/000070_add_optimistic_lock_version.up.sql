@@ -0,0 +1,3 @@
+-- 邮件和账户的乐观锁版本号，并发修改时后提交的请求返回409
+ALTER TABLE emails ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE email_accounts ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
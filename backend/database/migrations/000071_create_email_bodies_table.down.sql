@@ -0,0 +1,10 @@
+-- 回滚：恢复 emails 表中的正文列（启动时的分批迁移移完正文后会删除这两列），把正文写回并删除正文表
+ALTER TABLE emails ADD COLUMN text_body TEXT;
+ALTER TABLE emails ADD COLUMN html_body TEXT;
+
+UPDATE emails
+SET text_body = (SELECT text_body FROM email_bodies WHERE email_bodies.email_id = emails.id),
+    html_body = (SELECT html_body FROM email_bodies WHERE email_bodies.email_id = emails.id)
+WHERE id IN (SELECT email_id FROM email_bodies);
+
+DROP TABLE IF EXISTS email_bodies;
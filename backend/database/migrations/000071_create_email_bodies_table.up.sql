@@ -0,0 +1,9 @@
+-- 邮件正文分表保存，emails 表只保留元数据，列表查询和全表扫描不再读取正文。
+-- 已有邮件的正文由启动时的分批迁移写入本表（每批单独提交），全部移完后删除 emails 表中原来的正文列
+CREATE TABLE IF NOT EXISTS email_bodies (
+    email_id INTEGER PRIMARY KEY,
+    text_body TEXT,
+    html_body TEXT,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (email_id) REFERENCES emails(id) ON DELETE CASCADE
+);
@@ -0,0 +1,4 @@
+-- 删除通知中心表
+DROP INDEX IF EXISTS idx_notifications_created_at;
+DROP INDEX IF EXISTS idx_notifications_user_read;
+DROP TABLE IF EXISTS notifications;
@@ -0,0 +1,22 @@
+-- 创建通知中心表，保存需要用户关注的事件，用户不在线时也能稍后查看
+CREATE TABLE IF NOT EXISTS notifications (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    account_id INTEGER,
+    event_type VARCHAR(50) NOT NULL, -- new_email, sync_error, notification等
+    level VARCHAR(20) NOT NULL, -- info, success, warning, error
+    title VARCHAR(255) NOT NULL,
+    message TEXT,
+    data TEXT, -- JSON对象
+    is_read BOOLEAN NOT NULL DEFAULT false,
+    read_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    -- 外键约束
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (account_id) REFERENCES email_accounts(id) ON DELETE CASCADE
+);
+
+-- 创建索引
+CREATE INDEX IF NOT EXISTS idx_notifications_user_read ON notifications(user_id, is_read);
+CREATE INDEX IF NOT EXISTS idx_notifications_created_at ON notifications(created_at);
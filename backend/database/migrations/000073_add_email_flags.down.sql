@@ -0,0 +1,8 @@
+-- 回滚：移除邮件旗标字段
+DROP INDEX IF EXISTS idx_emails_user_follow_up;
+DROP INDEX IF EXISTS idx_emails_user_flag_color;
+
+ALTER TABLE emails DROP COLUMN follow_up_completed_at;
+ALTER TABLE emails DROP COLUMN follow_up_at;
+ALTER TABLE emails DROP COLUMN is_follow_up;
+ALTER TABLE emails DROP COLUMN flag_color;
@@ -0,0 +1,8 @@
+-- 邮件旗标：星标颜色和后续跟进旗标，尽量映射为IMAP关键字与服务器同步
+ALTER TABLE emails ADD COLUMN flag_color VARCHAR(20) NOT NULL DEFAULT ''; -- red, orange, yellow, green, blue, purple, gray，为空表示默认星标
+ALTER TABLE emails ADD COLUMN is_follow_up BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE emails ADD COLUMN follow_up_at DATETIME; -- 跟进截止时间，仅保存在本地
+ALTER TABLE emails ADD COLUMN follow_up_completed_at DATETIME;
+
+CREATE INDEX IF NOT EXISTS idx_emails_user_flag_color ON emails(user_id, flag_color);
+CREATE INDEX IF NOT EXISTS idx_emails_user_follow_up ON emails(user_id, is_follow_up, follow_up_at);
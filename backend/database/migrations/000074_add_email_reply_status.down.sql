@@ -0,0 +1,5 @@
+-- 回滚：移除已回复和已转发状态
+DROP INDEX IF EXISTS idx_emails_user_answered;
+
+ALTER TABLE emails DROP COLUMN is_forwarded;
+ALTER TABLE emails DROP COLUMN is_answered;
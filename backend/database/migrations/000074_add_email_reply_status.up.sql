@@ -0,0 +1,5 @@
+-- 已回复和已转发状态：同步自服务器的 \Answered 和 $Forwarded 标志，通过本应用回复或转发时也会设置
+ALTER TABLE emails ADD COLUMN is_answered BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE emails ADD COLUMN is_forwarded BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_emails_user_answered ON emails(user_id, is_answered);
@@ -45,6 +45,12 @@ type LoginResponse struct {
 	Token     string       `json:"token"`
 	ExpiresAt time.Time    `json:"expires_at"`
 	User      *models.User `json:"user"`
+	CSRFToken string       `json:"csrf_token,omitempty"` // 使用会话Cookie认证时，修改数据的请求需要在 X-CSRF-Token 请求头中携带
+}
+
+// 令牌缓存可能存放在Redis中，需要注册以便读取时还原类型
+func init() {
+	cache.RegisterType(&models.User{})
 }
 
 // Login 用户登录
@@ -125,7 +131,7 @@ func (s *Service) ValidateToken(tokenString string) (*models.User, error) {
 	user.Password = ""
 
 	// 缓存用户信息（缓存15分钟）
-	s.cacheManager.AuthCache().Set(cacheKey, &user, 15*time.Minute)
+	s.cacheManager.AuthCache().SetWithTags(cacheKey, &user, 15*time.Minute, cache.UserTag(user.ID))
 
 	return &user, nil
 }
@@ -214,6 +220,9 @@ func (s *Service) UpdateProfile(userID uint, displayName, email string) (*models
 		return nil, err
 	}
 
+	// 令牌缓存中保存的是旧资料，需要失效
+	s.cacheManager.AuthCache().InvalidateTags(cache.UserTag(userID))
+
 	// 清除密码字段
 	user.Password = ""
 
@@ -9,6 +9,12 @@ import (
 type Cache interface {
 	// Set 设置缓存项
 	Set(key string, value interface{}, ttl time.Duration)
+
+	// SetWithTags 设置缓存项并关联标签，便于按标签批量失效
+	SetWithTags(key string, value interface{}, ttl time.Duration, tags ...string)
+
+	// InvalidateTags 删除关联任一标签的缓存项，返回删除数量
+	InvalidateTags(tags ...string) int
 	
 	// Get 获取缓存项
 	Get(key string) (interface{}, bool)
@@ -30,6 +36,7 @@ type Cache interface {
 type CacheItem struct {
 	Value     interface{}
 	ExpiresAt time.Time
+	Tags      []string
 }
 
 // IsExpired 检查是否过期
@@ -39,13 +46,14 @@ func (item *CacheItem) IsExpired() bool {
 
 // MemoryCache 基于内存的缓存实现
 type MemoryCache struct {
-	items sync.Map
-	mutex sync.RWMutex
+	items    sync.Map
+	mutex    sync.RWMutex
+	tagIndex map[string]map[string]struct{} // 标签 -> 键集合
 }
 
 // NewMemoryCache 创建新的内存缓存
 func NewMemoryCache() *MemoryCache {
-	cache := &MemoryCache{}
+	cache := &MemoryCache{tagIndex: make(map[string]map[string]struct{})}
 	
 	// 启动清理协程
 	go cache.startCleanup()
@@ -55,6 +63,11 @@ func NewMemoryCache() *MemoryCache {
 
 // Set 设置缓存项
 func (c *MemoryCache) Set(key string, value interface{}, ttl time.Duration) {
+	c.SetWithTags(key, value, ttl)
+}
+
+// SetWithTags 设置缓存项并关联标签
+func (c *MemoryCache) SetWithTags(key string, value interface{}, ttl time.Duration, tags ...string) {
 	expiresAt := time.Now().Add(ttl)
 	if ttl <= 0 {
 		// 如果TTL为0或负数，设置为永不过期（100年后）
@@ -64,9 +77,56 @@ func (c *MemoryCache) Set(key string, value interface{}, ttl time.Duration) {
 	item := &CacheItem{
 		Value:     value,
 		ExpiresAt: expiresAt,
+		Tags:      tags,
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if previous, loaded := c.items.Swap(key, item); loaded {
+		if previousItem, ok := previous.(*CacheItem); ok {
+			c.untagLocked(key, previousItem.Tags)
+		}
+	}
+	for _, tag := range tags {
+		keys, ok := c.tagIndex[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			c.tagIndex[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+}
+
+// InvalidateTags 删除关联任一标签的缓存项
+func (c *MemoryCache) InvalidateTags(tags ...string) int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	removed := 0
+	for _, tag := range tags {
+		for key := range c.tagIndex[tag] {
+			if value, loaded := c.items.LoadAndDelete(key); loaded {
+				removed++
+				if item, ok := value.(*CacheItem); ok {
+					c.untagLocked(key, item.Tags)
+				}
+			}
+		}
+		delete(c.tagIndex, tag)
+	}
+	return removed
+}
+
+// untagLocked 从标签索引中移除键，调用方需持有写锁
+func (c *MemoryCache) untagLocked(key string, tags []string) {
+	for _, tag := range tags {
+		keys := c.tagIndex[tag]
+		delete(keys, key)
+		if len(keys) == 0 {
+			delete(c.tagIndex, tag)
+		}
 	}
-	
-	c.items.Store(key, item)
 }
 
 // Get 获取缓存项
@@ -77,30 +137,55 @@ func (c *MemoryCache) Get(key string) (interface{}, bool) {
 	}
 	
 	item, ok := value.(*CacheItem)
-	if !ok {
-		c.items.Delete(key)
-		return nil, false
-	}
-	
-	if item.IsExpired() {
-		c.items.Delete(key)
+	if !ok || item.IsExpired() {
+		c.deleteExpired(key)
 		return nil, false
 	}
 	
 	return item.Value, true
 }
 
+// deleteExpired 在写锁下重新检查后删除过期项，避免删掉并发 Set 刚写入的新值
+func (c *MemoryCache) deleteExpired(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	value, exists := c.items.Load(key)
+	if !exists {
+		return
+	}
+	item, ok := value.(*CacheItem)
+	if ok && !item.IsExpired() {
+		return
+	}
+	c.items.Delete(key)
+	if ok {
+		c.untagLocked(key, item.Tags)
+	}
+}
+
 // Delete 删除缓存项
 func (c *MemoryCache) Delete(key string) {
-	c.items.Delete(key)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if value, loaded := c.items.LoadAndDelete(key); loaded {
+		if item, ok := value.(*CacheItem); ok {
+			c.untagLocked(key, item.Tags)
+		}
+	}
 }
 
 // Clear 清空所有缓存
 func (c *MemoryCache) Clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
 	c.items.Range(func(key, value interface{}) bool {
 		c.items.Delete(key)
 		return true
 	})
+	c.tagIndex = make(map[string]map[string]struct{})
 }
 
 // Size 获取缓存项数量
@@ -141,17 +226,19 @@ func (c *MemoryCache) startCleanup() {
 
 // cleanup 清理过期项
 func (c *MemoryCache) cleanup() {
-	var expiredKeys []interface{}
+	var expiredKeys []string
 	
 	c.items.Range(func(key, value interface{}) bool {
 		if item, ok := value.(*CacheItem); ok && item.IsExpired() {
-			expiredKeys = append(expiredKeys, key)
+			if keyStr, ok := key.(string); ok {
+				expiredKeys = append(expiredKeys, keyStr)
+			}
 		}
 		return true
 	})
 	
 	for _, key := range expiredKeys {
-		c.items.Delete(key)
+		c.deleteExpired(key)
 	}
 }
 
@@ -193,6 +280,29 @@ func (cm *CacheManager) FolderStatusCache() Cache {
 	return cm.folderStatusCache
 }
 
+// InvalidateEmailLists 使受邮件变更影响的列表缓存失效：
+// 指定文件夹时只清理该文件夹、所属账户汇总和用户汇总列表，否则清理该用户的全部列表
+func (cm *CacheManager) InvalidateEmailLists(userID, accountID uint, folderID *uint) int {
+	if folderID == nil {
+		return cm.emailListCache.InvalidateTags(UserTag(userID))
+	}
+
+	tags := []string{FolderTag(*folderID), userListsTag(userID)}
+	if accountID != 0 {
+		tags = append(tags, accountListsTag(accountID))
+	}
+	return cm.emailListCache.InvalidateTags(tags...)
+}
+
+// InvalidateUser 清理用户在所有缓存中的条目
+func (cm *CacheManager) InvalidateUser(userID uint) {
+	tag := UserTag(userID)
+	cm.emailListCache.InvalidateTags(tag)
+	cm.authCache.InvalidateTags(tag)
+	cm.providerCache.InvalidateTags(tag)
+	cm.folderStatusCache.InvalidateTags(tag)
+}
+
 // ClearAll 清空所有缓存
 func (cm *CacheManager) ClearAll() {
 	cm.emailListCache.Clear()
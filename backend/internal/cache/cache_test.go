@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func uintPtr(v uint) *uint {
+	return &v
+}
+
+func TestMemoryCacheInvalidateTags(t *testing.T) {
+	c := NewMemoryCache()
+	c.SetWithTags("a", 1, time.Minute, "t1", "t2")
+	c.SetWithTags("b", 2, time.Minute, "t2")
+	c.Set("c", 3, time.Minute)
+
+	if removed := c.InvalidateTags("t1"); removed != 1 {
+		t.Fatalf("expected 1 entry removed, got %d", removed)
+	}
+	if _, ok := c.Get("a"); ok {
+		t.Fatalf("expected a to be invalidated")
+	}
+	if _, ok := c.Get("b"); !ok {
+		t.Fatalf("expected b to remain")
+	}
+
+	// 覆盖写入后旧标签不再关联该键
+	c.SetWithTags("b", 4, time.Minute, "t3")
+	if removed := c.InvalidateTags("t2"); removed != 0 {
+		t.Fatalf("expected stale tag to remove nothing, got %d", removed)
+	}
+	if value, ok := c.Get("b"); !ok || value != 4 {
+		t.Fatalf("expected b=4, got %v (%v)", value, ok)
+	}
+
+	c.Delete("b")
+	if removed := c.InvalidateTags("t3"); removed != 0 {
+		t.Fatalf("expected deleted key to be untagged, got %d", removed)
+	}
+	if _, ok := c.Get("c"); !ok {
+		t.Fatalf("expected untagged entry to remain")
+	}
+}
+
+func TestMemoryCacheKeepsValueSetAfterExpiry(t *testing.T) {
+	c := NewMemoryCache()
+	c.SetWithTags("a", 1, time.Nanosecond, "t1")
+	time.Sleep(time.Millisecond)
+
+	// Get 读到过期项后、删除前被 Set 覆盖，新值不应被删除
+	c.SetWithTags("a", 2, time.Minute, "t1")
+	c.deleteExpired("a")
+	if value, ok := c.Get("a"); !ok || value != 2 {
+		t.Fatalf("expected a=2, got %v (%v)", value, ok)
+	}
+
+	c.Set("b", 1, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if _, ok := c.Get("b"); ok {
+		t.Fatalf("expected b to expire")
+	}
+	if _, ok := c.items.Load("b"); ok {
+		t.Fatalf("expected expired entry to be removed")
+	}
+}
+
+func TestInvalidateEmailListsIsUserScoped(t *testing.T) {
+	cm := NewCacheManager()
+	lists := cm.EmailListCache()
+
+	set := func(userID uint, accountID, folderID *uint) string {
+		key := EmailListKey(userID, accountID, folderID, "digest")
+		lists.SetWithTags(key, true, time.Minute, EmailListTags(userID, accountID, folderID)...)
+		return key
+	}
+
+	userAll := set(1, nil, nil)
+	accountAll := set(1, uintPtr(10), nil)
+	inbox := set(1, uintPtr(10), uintPtr(100))
+	otherFolder := set(1, uintPtr(10), uintPtr(101))
+	otherAccount := set(1, uintPtr(11), nil)
+	otherUser := set(2, nil, nil)
+
+	cm.InvalidateEmailLists(1, 10, uintPtr(100))
+
+	for _, key := range []string{userAll, accountAll, inbox} {
+		if _, ok := lists.Get(key); ok {
+			t.Fatalf("expected %s to be invalidated", key)
+		}
+	}
+	for _, key := range []string{otherFolder, otherAccount, otherUser} {
+		if _, ok := lists.Get(key); !ok {
+			t.Fatalf("expected %s to remain cached", key)
+		}
+	}
+
+	// 未指定文件夹时清理该用户的全部列表
+	cm.InvalidateEmailLists(1, 10, nil)
+	for _, key := range []string{otherFolder, otherAccount} {
+		if _, ok := lists.Get(key); ok {
+			t.Fatalf("expected %s to be invalidated", key)
+		}
+	}
+	if _, ok := lists.Get(otherUser); !ok {
+		t.Fatalf("expected other user's list to remain cached")
+	}
+}
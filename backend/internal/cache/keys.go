@@ -0,0 +1,49 @@
+package cache
+
+import "fmt"
+
+// UserTag 用户范围标签，用户的所有缓存项都关联该标签
+func UserTag(userID uint) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+// FolderTag 文件夹范围标签
+func FolderTag(folderID uint) string {
+	return fmt.Sprintf("folder:%d", folderID)
+}
+
+// userListsTag 未按账户和文件夹过滤的用户汇总列表
+func userListsTag(userID uint) string {
+	return fmt.Sprintf("user:%d:lists", userID)
+}
+
+// accountListsTag 按账户过滤但未按文件夹过滤的账户汇总列表
+func accountListsTag(accountID uint) string {
+	return fmt.Sprintf("account:%d:lists", accountID)
+}
+
+// EmailListKey 生成邮件列表缓存键，前缀标明用户/账户/文件夹范围，digest区分其余查询条件
+func EmailListKey(userID uint, accountID, folderID *uint, digest string) string {
+	return fmt.Sprintf("emails:u%d:a%s:f%s:%s", userID, scopeID(accountID), scopeID(folderID), digest)
+}
+
+// EmailListTags 返回邮件列表缓存项应关联的标签
+func EmailListTags(userID uint, accountID, folderID *uint) []string {
+	tags := []string{UserTag(userID)}
+	switch {
+	case folderID != nil:
+		tags = append(tags, FolderTag(*folderID))
+	case accountID != nil:
+		tags = append(tags, accountListsTag(*accountID))
+	default:
+		tags = append(tags, userListsTag(userID))
+	}
+	return tags
+}
+
+func scopeID(id *uint) string {
+	if id == nil {
+		return "*"
+	}
+	return fmt.Sprintf("%d", *id)
+}
@@ -0,0 +1,265 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis操作超时时间，缓存不可用时不应阻塞请求
+const redisOperationTimeout = 2 * time.Second
+
+// redisValueTypes Redis缓存可以还原的值类型，键为类型名
+var redisValueTypes sync.Map
+
+// RegisterType 注册可以存入Redis缓存的值类型，未注册类型的值不会写入Redis。
+// 值以JSON序列化，读取时还原为与注册时相同的类型
+func RegisterType(prototype interface{}) {
+	valueType := reflect.TypeOf(prototype)
+	redisValueTypes.Store(valueType.String(), valueType)
+}
+
+// redisEnvelope Redis中保存的缓存值
+type redisEnvelope struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// RedisCache 基于Redis的缓存实现，多个实例共享同一份数据
+type RedisCache struct {
+	client    redis.UniversalClient
+	keyPrefix string // 数据键前缀
+	tagPrefix string // 标签集合前缀
+}
+
+// NewRedisCache 创建Redis缓存，namespace用于区分不同用途的缓存
+func NewRedisCache(client redis.UniversalClient, namespace string) *RedisCache {
+	return &RedisCache{
+		client:    client,
+		keyPrefix: namespace + "k:",
+		tagPrefix: namespace + "t:",
+	}
+}
+
+// NewRedisClient 根据连接URL创建Redis客户端并检查连通性
+func NewRedisClient(url string) (*redis.Client, error) {
+	if url == "" {
+		return nil, fmt.Errorf("redis url is empty")
+	}
+
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis url: %w", err)
+	}
+
+	client := redis.NewClient(options)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	return client, nil
+}
+
+// NewRedisCacheManager 创建使用Redis存储的缓存管理器
+func NewRedisCacheManager(client redis.UniversalClient, prefix string) *CacheManager {
+	return &CacheManager{
+		emailListCache:    NewRedisCache(client, prefix+"cache:emails:"),
+		authCache:         NewRedisCache(client, prefix+"cache:auth:"),
+		providerCache:     NewRedisCache(client, prefix+"cache:provider:"),
+		folderStatusCache: NewRedisCache(client, prefix+"cache:folder_status:"),
+	}
+}
+
+// UseBackend 将缓存管理器切换为另一个管理器的后端，需在服务开始处理请求前调用，
+// 已持有该管理器引用的服务会自动使用新后端
+func (cm *CacheManager) UseBackend(other *CacheManager) {
+	cm.emailListCache = other.emailListCache
+	cm.authCache = other.authCache
+	cm.providerCache = other.providerCache
+	cm.folderStatusCache = other.folderStatusCache
+}
+
+// Set 设置缓存项
+func (c *RedisCache) Set(key string, value interface{}, ttl time.Duration) {
+	c.SetWithTags(key, value, ttl)
+}
+
+// SetWithTags 设置缓存项并关联标签
+func (c *RedisCache) SetWithTags(key string, value interface{}, ttl time.Duration, tags ...string) {
+	typeName := reflect.TypeOf(value).String()
+	if _, ok := redisValueTypes.Load(typeName); !ok {
+		log.Printf("Warning: cache value type %s is not registered for redis, skipping", typeName)
+		return
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("Warning: failed to encode cache value for %s: %v", key, err)
+		return
+	}
+	data, err := json.Marshal(redisEnvelope{Type: typeName, Value: raw})
+	if err != nil {
+		log.Printf("Warning: failed to encode cache value for %s: %v", key, err)
+		return
+	}
+
+	if ttl < 0 {
+		ttl = 0
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisOperationTimeout)
+	defer cancel()
+
+	_, err = c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, c.keyPrefix+key, data, ttl)
+		for _, tag := range tags {
+			tagKey := c.tagPrefix + tag
+			pipe.SAdd(ctx, tagKey, key)
+			if ttl > 0 {
+				// 标签集合只需比其中的键存活更久，过期的成员在失效时删除也无副作用
+				pipe.Expire(ctx, tagKey, 2*ttl)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Warning: failed to write cache key %s: %v", key, err)
+	}
+}
+
+// Get 获取缓存项
+func (c *RedisCache) Get(key string) (interface{}, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisOperationTimeout)
+	defer cancel()
+
+	data, err := c.client.Get(ctx, c.keyPrefix+key).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			log.Printf("Warning: failed to read cache key %s: %v", key, err)
+		}
+		return nil, false
+	}
+
+	var envelope redisEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, false
+	}
+
+	registered, ok := redisValueTypes.Load(envelope.Type)
+	if !ok {
+		return nil, false
+	}
+	valueType := registered.(reflect.Type)
+
+	target := reflect.New(valueType)
+	if err := json.Unmarshal(envelope.Value, target.Interface()); err != nil {
+		log.Printf("Warning: failed to decode cache key %s: %v", key, err)
+		return nil, false
+	}
+	return target.Elem().Interface(), true
+}
+
+// Delete 删除缓存项
+func (c *RedisCache) Delete(key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisOperationTimeout)
+	defer cancel()
+
+	if err := c.client.Del(ctx, c.keyPrefix+key).Err(); err != nil {
+		log.Printf("Warning: failed to delete cache key %s: %v", key, err)
+	}
+}
+
+// InvalidateTags 删除关联任一标签的缓存项
+func (c *RedisCache) InvalidateTags(tags ...string) int {
+	ctx, cancel := context.WithTimeout(context.Background(), redisOperationTimeout)
+	defer cancel()
+
+	removed := 0
+	for _, tag := range tags {
+		tagKey := c.tagPrefix + tag
+		members, err := c.client.SMembers(ctx, tagKey).Result()
+		if err != nil {
+			log.Printf("Warning: failed to read cache tag %s: %v", tag, err)
+			continue
+		}
+
+		keys := make([]string, 0, len(members)+1)
+		for _, member := range members {
+			keys = append(keys, c.keyPrefix+member)
+		}
+
+		if len(keys) > 0 {
+			count, err := c.client.Del(ctx, keys...).Result()
+			if err != nil {
+				log.Printf("Warning: failed to invalidate cache tag %s: %v", tag, err)
+				continue
+			}
+			removed += int(count)
+		}
+		c.client.Del(ctx, tagKey)
+	}
+	return removed
+}
+
+// Clear 清空所有缓存
+func (c *RedisCache) Clear() {
+	c.deleteByPrefix(c.keyPrefix)
+	c.deleteByPrefix(c.tagPrefix)
+}
+
+// Size 获取缓存项数量
+func (c *RedisCache) Size() int {
+	return len(c.Keys())
+}
+
+// Keys 获取所有有效的键
+func (c *RedisCache) Keys() []string {
+	var keys []string
+	c.scan(c.keyPrefix, func(batch []string) {
+		for _, key := range batch {
+			keys = append(keys, strings.TrimPrefix(key, c.keyPrefix))
+		}
+	})
+	return keys
+}
+
+func (c *RedisCache) deleteByPrefix(prefix string) {
+	c.scan(prefix, func(batch []string) {
+		ctx, cancel := context.WithTimeout(context.Background(), redisOperationTimeout)
+		defer cancel()
+		if err := c.client.Del(ctx, batch...).Err(); err != nil {
+			log.Printf("Warning: failed to clear cache keys: %v", err)
+		}
+	})
+}
+
+// scan 按前缀分批遍历键
+func (c *RedisCache) scan(prefix string, handle func(keys []string)) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisOperationTimeout)
+	defer cancel()
+
+	var cursor uint64
+	for {
+		keys, next, err := c.client.Scan(ctx, cursor, prefix+"*", 500).Result()
+		if err != nil {
+			log.Printf("Warning: failed to scan cache keys: %v", err)
+			return
+		}
+		if len(keys) > 0 {
+			handle(keys)
+		}
+		if next == 0 {
+			return
+		}
+		cursor = next
+	}
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+type cachedList struct {
+	IDs   []uint `json:"ids"`
+	Total int64  `json:"total"`
+}
+
+func TestRedisCacheRoundTripsRegisteredTypes(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	RegisterType(&cachedList{})
+	c := NewRedisCache(client, "test:")
+
+	c.Set("list", &cachedList{IDs: []uint{1, 2}, Total: 2}, time.Minute)
+	value, ok := c.Get("list")
+	if !ok {
+		t.Fatalf("expected cached value")
+	}
+	list, ok := value.(*cachedList)
+	if !ok || list.Total != 2 || len(list.IDs) != 2 {
+		t.Fatalf("unexpected cached value: %#v", value)
+	}
+
+	// 未注册的类型不会写入
+	c.Set("plain", struct{ Name string }{Name: "x"}, time.Minute)
+	if _, ok := c.Get("plain"); ok {
+		t.Fatalf("expected unregistered type to be skipped")
+	}
+
+	if keys := c.Keys(); len(keys) != 1 || keys[0] != "list" {
+		t.Fatalf("unexpected keys: %v", keys)
+	}
+
+	c.Delete("list")
+	if _, ok := c.Get("list"); ok {
+		t.Fatalf("expected list to be deleted")
+	}
+}
+
+func TestRedisCacheManagerSharesInvalidationBetweenInstances(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	RegisterType(&cachedList{})
+	first := NewRedisCacheManager(client, "firemail:")
+	second := NewRedisCacheManager(client, "firemail:")
+
+	inboxKey := EmailListKey(1, uintPtr(10), uintPtr(100), "digest")
+	otherKey := EmailListKey(2, nil, nil, "digest")
+	first.EmailListCache().SetWithTags(inboxKey, &cachedList{Total: 1}, time.Minute, EmailListTags(1, uintPtr(10), uintPtr(100))...)
+	first.EmailListCache().SetWithTags(otherKey, &cachedList{Total: 2}, time.Minute, EmailListTags(2, nil, nil)...)
+
+	// 另一实例写入的缓存可以读取，也可以被另一实例按标签失效
+	if _, ok := second.EmailListCache().Get(inboxKey); !ok {
+		t.Fatalf("expected second instance to read shared cache")
+	}
+	if removed := second.InvalidateEmailLists(1, 10, uintPtr(100)); removed != 1 {
+		t.Fatalf("expected 1 entry removed, got %d", removed)
+	}
+	if _, ok := first.EmailListCache().Get(inboxKey); ok {
+		t.Fatalf("expected inbox list to be invalidated")
+	}
+	if _, ok := first.EmailListCache().Get(otherKey); !ok {
+		t.Fatalf("expected other user's list to remain cached")
+	}
+}
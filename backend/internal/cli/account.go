@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+
+	"firemail/internal/models"
+	"firemail/internal/services"
+
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+func newAccountCommand(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "account",
+		Short: "管理邮箱账户",
+	}
+	cmd.AddCommand(
+		newAccountListCommand(a),
+		newAccountAddCommand(a),
+		newAccountTestCommand(a),
+	)
+	return cmd
+}
+
+func newAccountListCommand(a *app) *cobra.Command {
+	var username string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "列出邮箱账户",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			db, err := a.database()
+			if err != nil {
+				return err
+			}
+			query := db.WithContext(cmd.Context()).Preload("User").Order("id ASC")
+			if username != "" {
+				user, err := a.findUser(cmd.Context(), username)
+				if err != nil {
+					return err
+				}
+				query = query.Where("user_id = ?", user.ID)
+			}
+			var accounts []models.EmailAccount
+			if err := query.Find(&accounts).Error; err != nil {
+				return fmt.Errorf("failed to list accounts: %w", err)
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tUSER\tEMAIL\tPROVIDER\tACTIVE\tSYNC STATUS\tLAST SYNC")
+			for _, account := range accounts {
+				lastSync := "-"
+				if account.LastSyncAt != nil {
+					lastSync = account.LastSyncAt.Local().Format("2006-01-02 15:04")
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%t\t%s\t%s\n", account.ID, account.User.Username, account.Email,
+					account.Provider, account.IsActive, account.SyncStatus, lastSync)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&username, "user", "", "只列出该用户的账户")
+	return cmd
+}
+
+func newAccountAddCommand(a *app) *cobra.Command {
+	var username string
+	req := services.CreateEmailAccountRequest{AuthMethod: "password"}
+	cmd := &cobra.Command{
+		Use:   "add <email>",
+		Short: "添加密码认证的邮箱账户，密码（或授权码）从标准输入读取",
+		Long: "添加密码认证的邮箱账户，密码（或授权码）从标准输入读取。\n" +
+			"未指定提供商时按邮箱域名自动识别，custom提供商需要指定IMAP和SMTP服务器。OAuth2账户需要在网页中授权添加",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			user, err := a.findUser(cmd.Context(), username)
+			if err != nil {
+				return err
+			}
+			emailService, _, err := a.services()
+			if err != nil {
+				return err
+			}
+			password, err := readSecret(cmd, "Password: ")
+			if err != nil {
+				return err
+			}
+
+			req.Email = args[0]
+			req.Password = password
+			if req.Name == "" {
+				req.Name = req.Email
+			}
+			account, err := emailService.CreateEmailAccount(cmd.Context(), user.ID, &req)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "已添加邮箱账户 %s (ID: %d, 提供商: %s)\n", account.Email, account.ID, account.Provider)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&username, "user", "", "账户所属用户（必填）")
+	cmd.Flags().StringVar(&req.Name, "name", "", "账户显示名称，默认为邮箱地址")
+	cmd.Flags().StringVar(&req.Provider, "provider", "", "提供商，如 gmail、outlook、qq、163、custom")
+	cmd.Flags().StringVar(&req.Username, "username", "", "登录用户名，默认为邮箱地址")
+	cmd.Flags().StringVar(&req.IMAPHost, "imap-host", "", "IMAP服务器")
+	cmd.Flags().IntVar(&req.IMAPPort, "imap-port", 0, "IMAP端口")
+	cmd.Flags().StringVar(&req.IMAPSecurity, "imap-security", "", "IMAP加密方式：SSL、STARTTLS、NONE")
+	cmd.Flags().StringVar(&req.SMTPHost, "smtp-host", "", "SMTP服务器")
+	cmd.Flags().IntVar(&req.SMTPPort, "smtp-port", 0, "SMTP端口")
+	cmd.Flags().StringVar(&req.SMTPSecurity, "smtp-security", "", "SMTP加密方式：SSL、STARTTLS、NONE")
+	_ = cmd.MarkFlagRequired("user")
+	return cmd
+}
+
+func newAccountTestCommand(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "test <account-id>",
+		Short: "测试邮箱账户的IMAP和SMTP连接",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			account, err := a.findAccount(cmd, args[0])
+			if err != nil {
+				return err
+			}
+			emailService, _, err := a.services()
+			if err != nil {
+				return err
+			}
+			if err := emailService.TestEmailAccount(cmd.Context(), account.UserID, account.ID); err != nil {
+				return fmt.Errorf("connection test failed for %s: %w", account.Email, err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "邮箱账户 %s 连接正常\n", account.Email)
+			return nil
+		},
+	}
+}
+
+// findAccount 按ID查找邮箱账户
+func (a *app) findAccount(cmd *cobra.Command, arg string) (*models.EmailAccount, error) {
+	id, err := strconv.ParseUint(arg, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account id %q", arg)
+	}
+	db, err := a.database()
+	if err != nil {
+		return nil, err
+	}
+	var account models.EmailAccount
+	if err := db.WithContext(cmd.Context()).First(&account, uint(id)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("account %d not found", id)
+		}
+		return nil, err
+	}
+	return &account, nil
+}
@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"firemail/internal/config"
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type cliTestEnv struct {
+	cfg *config.Config
+	db  *gorm.DB
+}
+
+func setupCLITestEnv(t *testing.T) *cliTestEnv {
+	t.Helper()
+
+	cfg := config.Load()
+	cfg.Database.Path = filepath.Join(t.TempDir(), "firemail.db")
+
+	db, err := gorm.Open(sqlite.Open(cfg.Database.Path), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&models.User{},
+		&models.EmailGroup{},
+		&models.EmailAccount{},
+		&models.Folder{},
+		&models.Email{},
+		&models.EmailBody{},
+		&models.Attachment{},
+		&models.LegalHoldExport{},
+	))
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return &cliTestEnv{cfg: cfg, db: db}
+}
+
+// run 使用新的命令实例执行，命令结束后会关闭自己打开的数据库连接
+func (env *cliTestEnv) run(t *testing.T, stdin string, args ...string) (string, error) {
+	t.Helper()
+
+	cmd := newRootCommand(&app{cfg: env.cfg})
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetIn(strings.NewReader(stdin))
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestUserCommands(t *testing.T) {
+	env := setupCLITestEnv(t)
+
+	_, err := env.run(t, "first-password\n", "user", "create", "alice", "--role", "user")
+	require.NoError(t, err)
+	_, err = env.run(t, "\n", "user", "create", "bob")
+	require.Error(t, err)
+
+	out, err := env.run(t, "", "user", "list")
+	require.NoError(t, err)
+	require.Contains(t, out, "alice")
+	require.NotContains(t, out, "bob")
+
+	_, err = env.run(t, "second-password\n", "user", "passwd", "alice")
+	require.NoError(t, err)
+	var user models.User
+	require.NoError(t, env.db.Where("username = ?", "alice").First(&user).Error)
+	require.True(t, user.CheckPassword("second-password"))
+	require.Equal(t, "user", user.Role)
+
+	_, err = env.run(t, "", "user", "disable", "alice")
+	require.NoError(t, err)
+	require.NoError(t, env.db.First(&user, user.ID).Error)
+	require.False(t, user.IsActive)
+
+	_, err = env.run(t, "", "user", "enable", "missing")
+	require.ErrorContains(t, err, `user "missing" not found`)
+}
+
+func TestExportMailboxWritesMbox(t *testing.T) {
+	env := setupCLITestEnv(t)
+
+	user := &models.User{Username: "alice", Password: "password123", IsActive: true}
+	require.NoError(t, env.db.Create(user).Error)
+	account := &models.EmailAccount{UserID: user.ID, Name: "Alice", Email: "alice@example.com", Provider: "custom", AuthMethod: "password", IsActive: true}
+	require.NoError(t, env.db.Create(account).Error)
+	inbox := &models.Folder{AccountID: account.ID, Name: "INBOX", Path: "INBOX", Type: models.FolderTypeInbox, IsSelectable: true}
+	require.NoError(t, env.db.Create(inbox).Error)
+	archive := &models.Folder{AccountID: account.ID, Name: "Archive", Path: "Archive", Type: models.FolderTypeCustom, IsSelectable: true}
+	require.NoError(t, env.db.Create(archive).Error)
+
+	date := time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC)
+	for i, folder := range []*models.Folder{inbox, archive} {
+		require.NoError(t, env.db.Create(&models.Email{
+			AccountID: account.ID,
+			FolderID:  &folder.ID,
+			MessageID: "<export-" + folder.Name + "@example.com>",
+			UID:       uint32(i + 1),
+			Subject:   "Report " + folder.Name,
+			From:      "Bob <bob@example.com>",
+			Date:      date,
+			TextBody:  "Hello\nFrom the team\n",
+		}).Error)
+	}
+
+	output := filepath.Join(t.TempDir(), "alice.mbox")
+	_, err := env.run(t, "", "export", "mailbox", "--user", "alice", "--account", "1", "--folder", "INBOX", "-o", output)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	content := string(data)
+	require.True(t, strings.HasPrefix(content, "From bob@example.com Thu Oct  1 09:30:00 2026\n"))
+	require.Contains(t, content, "Subject: Report INBOX\n")
+	require.NotContains(t, content, "Report Archive")
+	require.Contains(t, content, "\n>From the team")
+	require.NotContains(t, content, "\r\n")
+
+	out, err := env.run(t, "", "export", "mailbox", "--user", "alice")
+	require.NoError(t, err)
+	require.Len(t, regexp.MustCompile(`(?m)^From bob@example\.com `).FindAllString(out, -1), 2)
+
+	_, err = env.run(t, "", "export", "mailbox", "--user", "alice", "--folder", "INBOX")
+	require.Error(t, err)
+}
+
+func TestKeysRotateUpdatesEnvFile(t *testing.T) {
+	env := setupCLITestEnv(t)
+	// 命令会加载环境变量文件，预先设置避免影响其他测试
+	t.Setenv("JWT_SECRET", "old-secret")
+	t.Setenv("PORT", "8080")
+
+	envFile := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(envFile, []byte("# FireMail\nPORT=8080\nJWT_SECRET=old-secret\n"), 0640))
+
+	_, err := env.run(t, "", "keys", "rotate", "--env-file", envFile)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(envFile)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 3)
+	require.Equal(t, "# FireMail", lines[0])
+	require.Equal(t, "PORT=8080", lines[1])
+	require.Regexp(t, `^JWT_SECRET=[0-9a-f]{64}$`, lines[2])
+	info, err := os.Stat(envFile)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0640), info.Mode().Perm())
+
+	// 已签名的法律保全导出需要确认
+	require.NoError(t, env.db.Create(&models.LegalHoldExport{HoldID: 1, UserID: 1, Status: "completed", Signature: "abc", StartedAt: time.Now()}).Error)
+	_, err = env.run(t, "", "keys", "rotate", "--env-file", envFile)
+	require.ErrorContains(t, err, "--force")
+	_, err = env.run(t, "", "keys", "rotate", "--env-file", envFile, "--force")
+	require.NoError(t, err)
+
+	rotated, err := os.ReadFile(envFile)
+	require.NoError(t, err)
+	require.NotEqual(t, data, rotated)
+}
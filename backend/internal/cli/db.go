@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"fmt"
+
+	"firemail/internal/database"
+
+	"github.com/spf13/cobra"
+)
+
+func newDBCommand(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "数据库迁移与维护",
+	}
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "migrate",
+			Short: "执行未应用的数据库迁移",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				dbPath := a.config().Database.Path
+				if err := database.Migrate(dbPath); err != nil {
+					return err
+				}
+				return printMigrationVersion(cmd, dbPath)
+			},
+		},
+		&cobra.Command{
+			Use:   "status",
+			Short: "查看当前迁移版本",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return printMigrationVersion(cmd, a.config().Database.Path)
+			},
+		},
+		newDBRollbackCommand(a),
+		&cobra.Command{
+			Use:   "vacuum",
+			Short: "整理数据库文件，回收已删除数据占用的空间",
+			Long:  "整理数据库文件，回收已删除数据占用的空间并更新查询统计。整理期间数据库被独占锁定，应在服务空闲或停止时执行",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				db, err := a.database()
+				if err != nil {
+					return err
+				}
+				before, after, err := database.Vacuum(db, a.config().Database.Path)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "数据库整理完成：%s -> %s\n", formatBytes(before), formatBytes(after))
+				return nil
+			},
+		},
+	)
+	return cmd
+}
+
+func newDBRollbackCommand(a *app) *cobra.Command {
+	var steps int
+	cmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "回滚数据库迁移",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dbPath := a.config().Database.Path
+			if err := database.Rollback(dbPath, steps); err != nil {
+				return err
+			}
+			return printMigrationVersion(cmd, dbPath)
+		},
+	}
+	cmd.Flags().IntVar(&steps, "steps", 1, "回滚的迁移数")
+	return cmd
+}
+
+func printMigrationVersion(cmd *cobra.Command, dbPath string) error {
+	version, dirty, err := database.MigrationVersion(dbPath)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		fmt.Fprintf(cmd.OutOrStdout(), "迁移版本: %d（上次迁移未完成）\n", version)
+		return nil
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "迁移版本: %d\n", version)
+	return nil
+}
+
+// formatBytes 以KB/MB/GB显示文件大小
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
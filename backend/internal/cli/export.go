@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"firemail/internal/models"
+	"firemail/internal/services"
+
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+func newExportCommand(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "导出数据",
+	}
+	cmd.AddCommand(newExportMailboxCommand(a))
+	return cmd
+}
+
+func newExportMailboxCommand(a *app) *cobra.Command {
+	var username, folder, output string
+	var accountID uint
+	cmd := &cobra.Command{
+		Use:   "mailbox",
+		Short: "将本地保存的邮件导出为mbox文件",
+		Long: "将本地保存的邮件导出为mbox（mboxrd）文件，可导入Thunderbird等客户端。\n" +
+			"邮件内容由本地数据生成，只包含已下载的附件；未指定 --output 时写到标准输出",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if folder != "" && accountID == 0 {
+				return errors.New("--folder requires --account")
+			}
+			user, err := a.findUser(cmd.Context(), username)
+			if err != nil {
+				return err
+			}
+
+			opts := services.MboxExportOptions{AccountID: accountID}
+			if accountID != 0 {
+				account, err := a.findAccount(cmd, strconv.FormatUint(uint64(accountID), 10))
+				if err != nil {
+					return err
+				}
+				if account.UserID != user.ID {
+					return fmt.Errorf("account %d does not belong to %s", accountID, user.Username)
+				}
+			}
+			if folder != "" {
+				var target models.Folder
+				if err := a.db.WithContext(cmd.Context()).
+					Where("account_id = ? AND (path = ? OR name = ?)", accountID, folder, folder).
+					First(&target).Error; err != nil {
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						return fmt.Errorf("folder %q not found in account %d", folder, accountID)
+					}
+					return err
+				}
+				opts.FolderID = target.ID
+			}
+
+			var w io.Writer = cmd.OutOrStdout()
+			var file *os.File
+			if output != "" && output != "-" {
+				if file, err = os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600); err != nil {
+					return fmt.Errorf("failed to create %s: %w", output, err)
+				}
+				defer file.Close()
+				w = file
+			}
+
+			count, err := services.ExportMbox(cmd.Context(), a.db, a.attachmentStorage(), user.ID, opts, w)
+			if err != nil {
+				return err
+			}
+			if file != nil {
+				if err := file.Close(); err != nil {
+					return fmt.Errorf("failed to write %s: %w", output, err)
+				}
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "已导出 %d 封邮件\n", count)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&username, "user", "", "导出该用户的邮件（必填）")
+	cmd.Flags().UintVar(&accountID, "account", 0, "只导出该邮箱账户的邮件")
+	cmd.Flags().StringVar(&folder, "folder", "", "只导出该文件夹的邮件，需同时指定 --account")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "输出文件")
+	_ = cmd.MarkFlagRequired("user")
+	return cmd
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"firemail/internal/models"
+
+	"github.com/spf13/cobra"
+)
+
+// secretKeyBytes 生成的密钥随机字节数
+const secretKeyBytes = 32
+
+func newKeysCommand(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "keys",
+		Short: "管理密钥",
+	}
+	cmd.AddCommand(newKeysRotateCommand(a))
+	return cmd
+}
+
+func newKeysRotateCommand(a *app) *cobra.Command {
+	var force bool
+	cmd := &cobra.Command{
+		Use:   "rotate",
+		Short: "生成新的 JWT_SECRET 并写入环境变量文件",
+		Long: "生成新的 JWT_SECRET 并写入环境变量文件（--env-file，默认 .env.local 或 .env），重启服务后生效。\n" +
+			"登录令牌、邮件分享链接和法律保全导出的签名都由该密钥派生：轮换后所有用户需要重新登录，" +
+			"已有的分享链接失效，已完成的法律保全导出无法再校验签名，存在此类导出时需要 --force。\n" +
+			"新密钥不会打印到终端；如果 JWT_SECRET 通过其他环境变量或 CONFIG_FILE 设置，需要同步修改",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			envFile := a.envFile
+			if envFile == "" {
+				envFile = ".env"
+			}
+
+			if !force {
+				db, err := a.database()
+				if err != nil {
+					return err
+				}
+				var sealed int64
+				if err := db.WithContext(cmd.Context()).Model(&models.LegalHoldExport{}).
+					Where("signature <> ''").Count(&sealed).Error; err != nil {
+					return fmt.Errorf("failed to check legal hold exports: %w", err)
+				}
+				if sealed > 0 {
+					return fmt.Errorf("%d sealed legal hold exports can no longer be verified after rotation, use --force to rotate anyway", sealed)
+				}
+			}
+
+			secret := make([]byte, secretKeyBytes)
+			if _, err := rand.Read(secret); err != nil {
+				return fmt.Errorf("failed to generate secret: %w", err)
+			}
+			if err := setEnvValue(envFile, "JWT_SECRET", hex.EncodeToString(secret)); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "已在 %s 中轮换 JWT_SECRET，重启服务后生效\n", envFile)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&force, "force", false, "存在已签名的法律保全导出时仍然轮换")
+	return cmd
+}
+
+// setEnvValue 设置环境变量文件中的键值，保留其他行和注释，键不存在时追加，文件不存在时创建
+func setEnvValue(path, key, value string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	mode := os.FileMode(0600)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	var lines []string
+	if len(data) > 0 {
+		lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+	entry := key + "=" + value
+	replaced := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "export "))
+		if strings.HasPrefix(trimmed, key+"=") {
+			lines[i] = entry
+			replaced = true
+		}
+	}
+	if !replaced {
+		lines = append(lines, entry)
+	}
+
+	// 先写入临时文件再替换，避免中途失败损坏原文件
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strings.Join(lines, "\n")+"\n"), mode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
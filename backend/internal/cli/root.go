@@ -0,0 +1,196 @@
+// Package cli 实现firemailctl管理命令：用户与邮箱账户管理、触发同步、导出邮箱、数据库迁移与整理、轮换密钥。
+// 命令直接读写数据库，应在后端目录下运行，与服务使用相同的.env和数据库
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"firemail/internal/cache"
+	"firemail/internal/config"
+	"firemail/internal/database"
+	"firemail/internal/models"
+	"firemail/internal/providers"
+	"firemail/internal/services"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+// app 命令共享的状态，配置和数据库在第一次使用时加载
+type app struct {
+	envFile string
+
+	cfg *config.Config
+	db  *gorm.DB
+
+	emailService services.EmailService
+	syncService  *services.SyncService
+	storage      services.AttachmentStorage
+}
+
+// NewRootCommand 创建firemailctl根命令
+func NewRootCommand() *cobra.Command {
+	return newRootCommand(&app{})
+}
+
+func newRootCommand(a *app) *cobra.Command {
+	root := &cobra.Command{
+		Use:           "firemailctl",
+		Short:         "FireMail 管理工具",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return a.loadEnv()
+		},
+		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+			return a.close(cmd.Context())
+		},
+	}
+	root.PersistentFlags().StringVar(&a.envFile, "env-file", "", "环境变量文件，默认依次尝试 .env.local 和 .env")
+
+	root.AddCommand(
+		newUserCommand(a),
+		newAccountCommand(a),
+		newSyncCommand(a),
+		newExportCommand(a),
+		newDBCommand(a),
+		newKeysCommand(a),
+	)
+	return root
+}
+
+// Execute 运行命令，出错时打印错误并以非零状态退出
+func Execute() {
+	if err := NewRootCommand().ExecuteContext(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// loadEnv 与服务相同的顺序加载环境变量文件，已存在的环境变量优先
+func (a *app) loadEnv() error {
+	if a.envFile != "" {
+		if err := godotenv.Load(a.envFile); err != nil {
+			return fmt.Errorf("failed to load %s: %w", a.envFile, err)
+		}
+		return nil
+	}
+	for _, file := range []string{".env.local", ".env"} {
+		if err := godotenv.Load(file); err == nil {
+			a.envFile = file
+			return nil
+		}
+	}
+	return nil
+}
+
+// config 加载配置，命令行工具只报告警告，不因生产环境校验失败而拒绝运行
+func (a *app) config() *config.Config {
+	if a.cfg == nil {
+		a.cfg = config.Load()
+	}
+	return a.cfg
+}
+
+// database 打开已迁移的数据库
+func (a *app) database() (*gorm.DB, error) {
+	if a.db != nil {
+		return a.db, nil
+	}
+	database.Configure(a.config().Database)
+	db, err := database.Open(a.config().Database.Path)
+	if err != nil {
+		return nil, err
+	}
+	a.db = db
+	return db, nil
+}
+
+// services 创建邮件服务和同步服务，同步事件不推送到SSE
+func (a *app) services() (services.EmailService, *services.SyncService, error) {
+	if a.emailService != nil {
+		return a.emailService, a.syncService, nil
+	}
+	db, err := a.database()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	providerFactory := providers.NewProviderFactory()
+	providers.ConfigureRateLimiter(a.config().RateLimit)
+	providers.ConfigureOutgoing(a.config().Outgoing)
+	providers.ConfigureDNS(a.config().DNS)
+	services.ConfigureAttachmentPolicy(a.config().Attachments)
+	services.ConfigureCompose(a.config().Compose)
+	emailService := services.NewEmailService(db, providerFactory, nil)
+	syncService := services.NewSyncService(db, providerFactory, nil, services.NewDeduplicatorFactory(db), a.attachmentStorage(), cache.GlobalCacheManager)
+	syncService.SetFolderWorkers(a.config().Sync.FolderWorkers, a.config().Sync.MaxFolderWorkers)
+	if emailServiceImpl, ok := emailService.(*services.EmailServiceImpl); ok {
+		emailServiceImpl.SetSyncService(syncService)
+	}
+
+	a.emailService = emailService
+	a.syncService = syncService
+	return emailService, syncService, nil
+}
+
+// attachmentStorage 与服务相同的附件存储
+func (a *app) attachmentStorage() services.AttachmentStorage {
+	if a.storage == nil {
+		a.storage = services.NewLocalFileStorage(nil)
+	}
+	return a.storage
+}
+
+// close 等待进行中的同步结束并关闭数据库
+func (a *app) close(ctx context.Context) error {
+	if a.syncService != nil {
+		if err := a.syncService.Shutdown(ctx); err != nil {
+			return err
+		}
+	}
+	if a.db != nil {
+		return database.Close(a.db)
+	}
+	return nil
+}
+
+// findUser 按用户名查找用户
+func (a *app) findUser(ctx context.Context, username string) (*models.User, error) {
+	db, err := a.database()
+	if err != nil {
+		return nil, err
+	}
+	var user models.User
+	if err := db.WithContext(ctx).Where("username = ?", username).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("user %q not found", username)
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// readSecret 从标准输入读取一行密码，可以通过管道传入。
+// 不提供命令行参数形式，避免密码留在shell历史和进程列表中
+func readSecret(cmd *cobra.Command, prompt string) (string, error) {
+	if prompt != "" {
+		fmt.Fprint(cmd.ErrOrStderr(), prompt)
+	}
+	line, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	secret := strings.TrimRight(line, "\r\n")
+	if secret == "" {
+		return "", errors.New("password must not be empty")
+	}
+	return secret, nil
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func newSyncCommand(a *app) *cobra.Command {
+	var username, folder string
+	cmd := &cobra.Command{
+		Use:   "sync [account-id...]",
+		Short: "立即同步邮箱账户，指定 --user 时同步该用户的全部账户",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (username == "") == (len(args) == 0) {
+				return errors.New("specify either account ids or --user")
+			}
+			if folder != "" && len(args) == 0 {
+				return errors.New("--folder requires account ids")
+			}
+			_, syncService, err := a.services()
+			if err != nil {
+				return err
+			}
+
+			if username != "" {
+				user, err := a.findUser(cmd.Context(), username)
+				if err != nil {
+					return err
+				}
+				if err := syncService.SyncEmailsForUser(cmd.Context(), user.ID); err != nil {
+					return fmt.Errorf("failed to sync accounts of %s: %w", user.Username, err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "已同步用户 %s 的邮箱账户\n", user.Username)
+				return nil
+			}
+
+			for _, arg := range args {
+				account, err := a.findAccount(cmd, arg)
+				if err != nil {
+					return err
+				}
+				if folder != "" {
+					err = syncService.SyncFolder(cmd.Context(), account.ID, folder)
+				} else {
+					err = syncService.SyncEmails(cmd.Context(), account.ID)
+				}
+				if err != nil {
+					return fmt.Errorf("failed to sync %s: %w", account.Email, err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "已同步邮箱账户 %s\n", account.Email)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&username, "user", "", "同步该用户的全部账户")
+	cmd.Flags().StringVar(&folder, "folder", "", "只同步该文件夹（服务器上的路径）")
+	return cmd
+}
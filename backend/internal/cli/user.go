@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"firemail/internal/models"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newUserCommand(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "user",
+		Short: "管理FireMail用户",
+	}
+	cmd.AddCommand(
+		newUserListCommand(a),
+		newUserCreateCommand(a),
+		newUserPasswdCommand(a),
+		newUserActiveCommand(a, "enable", "启用用户", true),
+		newUserActiveCommand(a, "disable", "停用用户，停用后无法登录", false),
+	)
+	return cmd
+}
+
+func newUserListCommand(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "列出用户",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			db, err := a.database()
+			if err != nil {
+				return err
+			}
+			var users []models.User
+			if err := db.WithContext(cmd.Context()).Order("id ASC").Find(&users).Error; err != nil {
+				return fmt.Errorf("failed to list users: %w", err)
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tUSERNAME\tROLE\tACTIVE\tLAST LOGIN")
+			for _, user := range users {
+				lastLogin := "-"
+				if user.LastLoginAt != nil {
+					lastLogin = user.LastLoginAt.Local().Format("2006-01-02 15:04")
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\t%t\t%s\n", user.ID, user.Username, user.Role, user.IsActive, lastLogin)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func newUserCreateCommand(a *app) *cobra.Command {
+	var role, displayName, email string
+	cmd := &cobra.Command{
+		Use:   "create <username>",
+		Short: "创建用户，密码从标准输入读取",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			db, err := a.database()
+			if err != nil {
+				return err
+			}
+			password, err := readSecret(cmd, "Password: ")
+			if err != nil {
+				return err
+			}
+
+			user := &models.User{
+				Username:    args[0],
+				Password:    password, // 会在BeforeCreate钩子中自动加密
+				DisplayName: displayName,
+				Email:       email,
+				Role:        role,
+				IsActive:    true,
+			}
+			if err := db.WithContext(cmd.Context()).Create(user).Error; err != nil {
+				return fmt.Errorf("failed to create user: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "已创建用户 %s (ID: %d)\n", user.Username, user.ID)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&role, "role", "admin", "用户角色")
+	cmd.Flags().StringVar(&displayName, "display-name", "", "显示名称")
+	cmd.Flags().StringVar(&email, "email", "", "联系邮箱")
+	return cmd
+}
+
+func newUserPasswdCommand(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "passwd <username>",
+		Short: "重置用户密码，密码从标准输入读取",
+		Long: "重置用户密码，密码从标准输入读取。\n" +
+			"服务启动时会将 ADMIN_USERNAME 对应用户的密码同步为 ADMIN_PASSWORD，修改该用户的密码时应同时修改配置",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			user, err := a.findUser(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			password, err := readSecret(cmd, "New password: ")
+			if err != nil {
+				return err
+			}
+			hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+			if err != nil {
+				return fmt.Errorf("failed to hash password: %w", err)
+			}
+
+			// 直接写入加密后的密码，跳过更新钩子避免重复加密
+			if err := a.db.WithContext(cmd.Context()).Model(user).UpdateColumn("password", string(hashedPassword)).Error; err != nil {
+				return fmt.Errorf("failed to update password: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "已重置用户 %s 的密码\n", user.Username)
+			if user.Username == a.config().Auth.AdminUsername {
+				fmt.Fprintln(cmd.ErrOrStderr(), "Warning: the server resets this user's password to ADMIN_PASSWORD on startup")
+			}
+			return nil
+		},
+	}
+}
+
+func newUserActiveCommand(a *app, use, short string, active bool) *cobra.Command {
+	return &cobra.Command{
+		Use:   use + " <username>",
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			user, err := a.findUser(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			if err := a.db.WithContext(cmd.Context()).Model(user).UpdateColumn("is_active", active).Error; err != nil {
+				return fmt.Errorf("failed to update user: %w", err)
+			}
+			state := "停用"
+			if active {
+				state = "启用"
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "已%s用户 %s\n", state, user.Username)
+			return nil
+		},
+	}
+}
@@ -2,27 +2,51 @@ package config
 
 import (
 	"os"
-	"strconv"
 	"strings"
 	"time"
 )
 
 // Config 应用配置结构
 type Config struct {
-	Server   ServerConfig   `json:"server"`
-	Database DatabaseConfig `json:"database"`
-	Auth     AuthConfig     `json:"auth"`
-	OAuth    OAuthConfig    `json:"oauth"`
-	CORS     CORSConfig     `json:"cors"`
-	Logging  LoggingConfig  `json:"logging"`
-	SSE      SSEConfig      `json:"sse"`
+	Server       ServerConfig       `json:"server"`
+	Database     DatabaseConfig     `json:"database"`
+	Auth         AuthConfig         `json:"auth"`
+	OAuth        OAuthConfig        `json:"oauth"`
+	CORS         CORSConfig         `json:"cors"`
+	Logging      LoggingConfig      `json:"logging"`
+	SSE          SSEConfig          `json:"sse"`
+	RateLimit    RateLimitConfig    `json:"rate_limit"`
+	Sync         SyncConfig         `json:"sync"`
+	Jobs         JobsConfig         `json:"jobs"`
+	Redis        RedisConfig        `json:"redis"`
+	GraphQL      GraphQLConfig      `json:"graphql"`
+	Sharing      SharingConfig      `json:"sharing"`
+	Tracking     TrackingConfig     `json:"tracking"`
+	GeoIP        GeoIPConfig        `json:"geoip"`
+	Compliance   ComplianceConfig   `json:"compliance"`
+	Ingest       IngestConfig       `json:"ingest"`
+	Attachments  AttachmentsConfig  `json:"attachments"`
+	Access       AccessConfig       `json:"access"`
+	SMTPServer   SMTPServerConfig   `json:"smtp_server"`
+	IMAPServer   IMAPServerConfig   `json:"imap_server"`
+	UserDefaults UserDefaultsConfig `json:"user_defaults"`
+	Outgoing     OutgoingConfig     `json:"outgoing"`
+	Compose      ComposeConfig      `json:"compose"`
+	DNS          DNSConfig          `json:"dns"`
+
+	SemanticSearch SemanticSearchConfig `json:"semantic_search"`
+
+	configFile   string    // 加载的配置文件路径
+	settings     []Setting // 各配置项的取值和来源
+	loadProblems []string  // 读取阶段发现的问题，如无法解析的值
 }
 
 // ServerConfig 服务器配置
 type ServerConfig struct {
-	Host string `json:"host"`
-	Port string `json:"port"`
-	Env  string `json:"env"`
+	Host            string        `json:"host"`
+	Port            string        `json:"port"`
+	Env             string        `json:"env"`
+	ShutdownTimeout time.Duration `json:"shutdown_timeout"` // 关闭时等待请求和后台任务完成的最长时间
 }
 
 // DatabaseConfig 数据库配置
@@ -31,6 +55,14 @@ type DatabaseConfig struct {
 	BackupDir          string `json:"backup_dir"`
 	BackupMaxCount     int    `json:"backup_max_count"`
 	BackupIntervalHours int   `json:"backup_interval_hours"`
+
+	// SQLite连接参数，对连接池中的每个连接生效
+	JournalMode  string        `json:"journal_mode"`   // WAL、DELETE、TRUNCATE 等
+	BusyTimeout  time.Duration `json:"busy_timeout"`   // 数据库被其他连接锁定时等待的最长时间
+	Synchronous  string        `json:"synchronous"`    // OFF、NORMAL、FULL 或 EXTRA
+	ForeignKeys  bool          `json:"foreign_keys"`   // 启用外键约束
+	CacheSizeMB  int           `json:"cache_size_mb"`  // 每个连接的页缓存大小
+	MaxOpenConns int           `json:"max_open_conns"` // 连接池大小，WAL模式下读取可以并发
 }
 
 // AuthConfig 认证配置
@@ -39,8 +71,21 @@ type AuthConfig struct {
 	AdminPassword string        `json:"admin_password"`
 	JWTSecret     string        `json:"jwt_secret"`
 	JWTExpiry     time.Duration `json:"jwt_expiry"`
+
+	Mode           string `json:"mode"`             // header、cookie 或 both，见 AuthMode* 常量
+	CookieName     string `json:"cookie_name"`      // 会话Cookie名称，CSRF Cookie为其加 _csrf 后缀
+	CookieDomain   string `json:"cookie_domain"`    // 为空时只对当前主机有效
+	CookieSecure   bool   `json:"cookie_secure"`    // 只通过HTTPS发送Cookie
+	CookieSameSite string `json:"cookie_same_site"` // strict、lax 或 none
 }
 
+// 认证方式
+const (
+	AuthModeHeader = "header" // 只接受 Authorization 请求头中的令牌
+	AuthModeCookie = "cookie" // 只接受HttpOnly会话Cookie，修改数据的请求需要CSRF令牌
+	AuthModeBoth   = "both"   // 两者都接受，使用Cookie认证的请求同样需要CSRF令牌
+)
+
 // OAuthConfig OAuth2配置
 type OAuthConfig struct {
 	Gmail           OAuthProviderConfig `json:"gmail"`
@@ -82,77 +127,421 @@ type SSEConfig struct {
 	EnableHeartbeat       bool          `json:"enable_heartbeat"`
 }
 
+// SyncConfig 邮件同步配置
+type SyncConfig struct {
+	FolderWorkers    int `json:"folder_workers"`     // 每个账户并行同步的文件夹数，每个工作协程使用独立的IMAP连接
+	MaxFolderWorkers int `json:"max_folder_workers"` // 所有账户合计并行同步的文件夹数上限，0表示不限制
+}
+
+// JobsConfig 持久化后台任务队列配置
+type JobsConfig struct {
+	Workers      int           `json:"workers"`       // 每个进程同时执行的任务数
+	PollInterval time.Duration `json:"poll_interval"` // 检查待执行任务的间隔
+	MaxAttempts  int           `json:"max_attempts"`  // 任务默认的最大尝试次数，用尽后进入死信
+	Retention    time.Duration `json:"retention"`     // 已完成和已取消任务的保留时长
+}
+
+// 缓存与事件分发后端
+const (
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+)
+
+// RedisConfig Redis配置，多实例部署时用于共享缓存和跨实例分发SSE事件
+type RedisConfig struct {
+	URL          string `json:"url"`           // redis://[:password@]host:port/db
+	KeyPrefix    string `json:"key_prefix"`    // 多个部署共用同一Redis时区分键空间
+	CacheBackend string `json:"cache_backend"` // memory, redis
+	EventBackend string `json:"event_backend"` // memory, redis
+	JobBackend   string `json:"job_backend"`   // memory（轮询数据库）, redis（入队时通知工作进程）
+}
+
+// GraphQLConfig 可选的GraphQL查询接口配置
+type GraphQLConfig struct {
+	Enabled  bool `json:"enabled"`
+	MaxDepth int  `json:"max_depth"` // 查询字段嵌套的最大层数
+}
+
+// SharingConfig 邮件分享链接配置
+type SharingConfig struct {
+	BaseURL       string        `json:"base_url"`       // 生成分享链接使用的外部访问地址，为空时取请求的Host
+	DefaultExpiry time.Duration `json:"default_expiry"` // 未指定有效期时的默认值
+	MaxExpiry     time.Duration `json:"max_expiry"`     // 允许的最长有效期
+
+	// AttachmentLinkExpiry 发信时超过大小上限的附件改为下载链接，链接的有效期
+	AttachmentLinkExpiry time.Duration `json:"attachment_link_expiry"`
+}
+
+// TrackingConfig 邮件合并活动的打开和点击跟踪配置
+type TrackingConfig struct {
+	BaseURL string `json:"base_url"` // 跟踪像素和跳转链接使用的外部访问地址，为空时不能启用跟踪
+}
+
+// GeoIPConfig 邮件头分析中来源IP地理位置查询配置
+type GeoIPConfig struct {
+	LookupURL string        `json:"lookup_url"` // 查询地址模板，{ip}替换为IP，返回ip-api.com格式的JSON；为空时不查询
+	Timeout   time.Duration `json:"timeout"`
+}
+
+// ComplianceConfig 合规导出配置
+type ComplianceConfig struct {
+	ExportDir string `json:"export_dir"` // 法律保全导出文件的保存目录
+}
 
+// IngestConfig 入站邮件接口配置
+type IngestConfig struct {
+	MaxMessageMB int `json:"max_message_mb"` // 单封入站邮件的大小上限（MB）
+}
+
+// DefaultBlockedAttachmentExtensions 默认禁止的附件扩展名：可执行文件、脚本和快捷方式
+const DefaultBlockedAttachmentExtensions = "exe,com,scr,pif,bat,cmd,msi,msp,mst,dll,cpl,sys,ocx,vb,vbs,vbe,js,jse,ws,wsf,wsc,wsh,ps1,ps1xml,ps2,psc1,psm1,hta,jar,lnk,inf,reg,scf,msc,chm,gadget,application,appref-ms"
+
+// AccessConfig 按来源IP限制访问的配置，白名单为空时不限制
+type AccessConfig struct {
+	AdminAllowlist   []string `json:"admin_allowlist"`   // 允许访问 /api/v1/admin 的IP或CIDR
+	LoginAllowlist   []string `json:"login_allowlist"`   // 允许登录的IP或CIDR
+	AllowedCountries []string `json:"allowed_countries"` // 允许访问管理接口和登录的国家或地区代码，需要配置 GEOIP_LOOKUP_URL
+	BlockedCountries []string `json:"blocked_countries"` // 禁止访问管理接口和登录的国家或地区代码
+	TrustedProxies   []string `json:"trusted_proxies"`   // 可信反向代理，只有来自这些地址的 X-Forwarded-For 才用于确定来源IP
+
+	AuthFailureLog   string        `json:"auth_failure_log"`   // 认证失败日志文件，格式固定，供fail2ban等工具读取；为空时写入标准日志
+	AuthBanThreshold int           `json:"auth_ban_threshold"` // 统计窗口内失败次数达到该值的IP建议封禁
+	AuthBanWindow    time.Duration `json:"auth_ban_window"`    // 统计认证失败次数的时间窗口
+}
+
+// AttachmentsConfig 附件安全策略配置
+type AttachmentsConfig struct {
+	BlockedExtensions []string      `json:"blocked_extensions"` // 禁止上传、下载和转发的扩展名，不含点
+	DownloadTokenTTL  time.Duration `json:"download_token_ttl"` // 附件签名下载链接的有效期
+}
+
+// SMTPServerConfig 内置SMTP收信服务器配置（MX模式），邮件按收件地址投递到入站端点
+type SMTPServerConfig struct {
+	Enabled       bool          `json:"enabled"`
+	Addrs         []string      `json:"addrs"`          // 监听地址，如 :25,:587
+	Hostname      string        `json:"hostname"`       // 问候语和Received头中使用的主机名
+	Domains       []string      `json:"domains"`        // 接收邮件的域名，其他域名的收件人一律拒绝（不做中继）
+	TLSCertFile   string        `json:"tls_cert_file"`  // STARTTLS使用的证书，为空时不提供STARTTLS
+	TLSKeyFile    string        `json:"tls_key_file"`   // 证书私钥
+	RequireTLS    bool          `json:"require_tls"`    // 要求先执行STARTTLS再投递
+	MaxRecipients int           `json:"max_recipients"` // 单封邮件的收件人数上限
+	Timeout       time.Duration `json:"timeout"`        // 读取命令和邮件内容的空闲超时
+	DNSBLZones    []string      `json:"dnsbl_zones"`    // 连接时查询的DNS黑名单，如 zen.spamhaus.org
+}
+
+// IMAPServerConfig 本地IMAP服务器外观配置，桌面客户端使用FireMail用户名和密码登录
+type IMAPServerConfig struct {
+	Enabled           bool     `json:"enabled"`
+	Addrs             []string `json:"addrs"`               // 监听地址，默认只监听本机
+	TLSCertFile       string   `json:"tls_cert_file"`       // STARTTLS使用的证书，为空时不提供STARTTLS
+	TLSKeyFile        string   `json:"tls_key_file"`        // 证书私钥
+	AllowInsecureAuth bool     `json:"allow_insecure_auth"` // 允许在未加密的连接上登录
+}
+
+// UserDefaultsConfig 用户设置的默认值，用户未修改的设置项使用这些值
+type UserDefaultsConfig struct {
+	ReplyAll           bool   `json:"reply_all"`
+	BlockRemoteImages  bool   `json:"block_remote_images"`
+	StripTrackers      bool   `json:"strip_trackers"` // 查看邮件时去掉追踪像素
+	Timezone           string `json:"timezone"` // IANA时区名
+	EmailsPerPage      int    `json:"emails_per_page"`
+	NotificationsMuted bool   `json:"notifications_muted"`
+	Locale             string `json:"locale"` // 为空时按请求的Accept-Language选择
+	SyncConflictPolicy string `json:"sync_conflict_policy"` // server_wins、local_wins 或 ask
+}
+
+// OutgoingConfig 外发邮件的标识，账户未单独设置的项使用这些值
+type OutgoingConfig struct {
+	EHLOName        string `json:"ehlo_name"`         // SMTP问候使用的主机名，为空时使用本机主机名
+	MessageIDDomain string `json:"message_id_domain"` // Message-ID的域名部分，为空时使用发件地址的域名
+	Mailer          string `json:"mailer"`            // User-Agent和X-Mailer头的值，为空时不写入
+}
+
+// ComposeConfig 撰写邮件配置
+type ComposeConfig struct {
+	CodeHighlight bool   `json:"code_highlight"` // Markdown正文中标注了语言的代码块是否着色，请求可单独指定
+	CodeTheme     string `json:"code_theme"`     // 代码块配色：light、dark 或 solarized
+}
+
+// DNSConfig 连接邮件服务器和查询MX记录使用的DNS解析，账户可单独设置
+type DNSConfig struct {
+	Resolver string        `json:"resolver"` // DNS服务器地址（如 1.1.1.1 或 [2606:4700:4700::1111]:53）或DoH地址（https://），为空时使用系统DNS
+	Timeout  time.Duration `json:"timeout"`  // 单次查询的超时时间
+}
+
+// SemanticSearchConfig 语义搜索使用的向量模型，通过 OpenAI 兼容的 /embeddings 接口调用，可使用 Ollama 等本地模型服务
+type SemanticSearchConfig struct {
+	EmbeddingURL   string        `json:"embedding_url"`   // 向量接口地址，如 http://localhost:11434/v1/embeddings；为空时不启用语义搜索
+	EmbeddingModel string        `json:"embedding_model"` // 模型名称，如 nomic-embed-text
+	APIKey         string        `json:"-"`               // 接口需要认证时以 Bearer token 发送
+	Timeout        time.Duration `json:"timeout"`         // 单次请求的超时时间
+}
+
+// Enabled 是否配置了向量模型
+func (c SemanticSearchConfig) Enabled() bool {
+	return c.EmbeddingURL != ""
+}
 
-// Load 加载配置
+// RateLimitConfig 邮件服务器访问限速配置
+type RateLimitConfig struct {
+	Enabled   bool                         `json:"enabled"`
+	MaxWait   time.Duration                `json:"max_wait"` // 发送等待令牌的最长时间，超过则交由发送队列稍后重试
+	Default   ProviderRateLimit            `json:"default"`
+	Providers map[string]ProviderRateLimit `json:"providers"`
+}
+
+// ProviderRateLimit 单个提供商的限速参数（按账户生效）
+type ProviderRateLimit struct {
+	SendPerMinute  int           `json:"send_per_minute"`
+	MaxConnections int           `json:"max_connections"`
+	FetchInterval  time.Duration `json:"fetch_interval"` // 相邻两批邮件拉取之间的最小间隔
+	// 同步时IMAP连接每分钟可读写的字节数，同一账户的连接共享，0表示不限制
+	SyncBytesPerMinute int `json:"sync_bytes_per_minute"`
+}
+
+// ForProvider 获取指定提供商的限速参数，未配置时使用默认值
+func (c RateLimitConfig) ForProvider(name string) ProviderRateLimit {
+	if limit, ok := c.Providers[strings.ToLower(name)]; ok {
+		return limit
+	}
+	return c.Default
+}
+
+
+
+// Load 加载配置，无法解析的值回退为默认值；启动时应使用 LoadAndValidate
 func Load() *Config {
-	return &Config{
+	configFile := os.Getenv("CONFIG_FILE")
+	l := newLoader(configFile)
+
+	cfg := &Config{
 		Server: ServerConfig{
-			Host: getEnv("HOST", "localhost"),
-			Port: getEnv("PORT", "8080"),
-			Env:  getEnv("ENV", "development"),
+			Host:            l.string("HOST", "server.host", "localhost"),
+			Port:            l.string("PORT", "server.port", "8080"),
+			Env:             l.string("ENV", "server.env", "development"),
+			ShutdownTimeout: l.duration("SHUTDOWN_TIMEOUT", "server.shutdown_timeout", 30*time.Second),
 		},
 		Database: DatabaseConfig{
-			Path:                getEnv("DB_PATH", "./firemail.db"),
-			BackupDir:           getEnv("DB_BACKUP_DIR", "./backups"),
-			BackupMaxCount:      parseInt(getEnv("DB_BACKUP_MAX_COUNT", "7"), 7),
-			BackupIntervalHours: parseInt(getEnv("DB_BACKUP_INTERVAL_HOURS", "24"), 24),
+			Path:                l.string("DB_PATH", "database.path", "./firemail.db"),
+			BackupDir:           l.string("DB_BACKUP_DIR", "database.backup_dir", "./backups"),
+			BackupMaxCount:      l.int("DB_BACKUP_MAX_COUNT", "database.backup_max_count", 7),
+			BackupIntervalHours: l.int("DB_BACKUP_INTERVAL_HOURS", "database.backup_interval_hours", 24),
+			JournalMode:         l.string("DB_JOURNAL_MODE", "database.journal_mode", "WAL"),
+			BusyTimeout:         l.duration("DB_BUSY_TIMEOUT", "database.busy_timeout", 10*time.Second),
+			Synchronous:         l.string("DB_SYNCHRONOUS", "database.synchronous", "NORMAL"),
+			ForeignKeys:         l.bool("DB_FOREIGN_KEYS", "database.foreign_keys", true),
+			CacheSizeMB:         l.int("DB_CACHE_SIZE_MB", "database.cache_size_mb", 64),
+			MaxOpenConns:        l.int("DB_MAX_OPEN_CONNS", "database.max_open_conns", 5),
 		},
 		Auth: AuthConfig{
-			AdminUsername: getEnv("ADMIN_USERNAME", "admin"),
-			AdminPassword: getEnv("ADMIN_PASSWORD", "admin123"),
-			JWTSecret:     getEnv("JWT_SECRET", "your-secret-key"),
-			JWTExpiry:     parseDuration(getEnv("JWT_EXPIRY", "24h")),
+			AdminUsername: l.string("ADMIN_USERNAME", "auth.admin_username", "admin"),
+			AdminPassword: l.string("ADMIN_PASSWORD", "auth.admin_password", DefaultAdminPassword),
+			JWTSecret:     l.string("JWT_SECRET", "auth.jwt_secret", DefaultJWTSecret),
+			JWTExpiry:     l.duration("JWT_EXPIRY", "auth.jwt_expiry", 24*time.Hour),
+
+			Mode:           strings.ToLower(l.string("AUTH_MODE", "auth.mode", AuthModeHeader)),
+			CookieName:     l.string("AUTH_COOKIE_NAME", "auth.cookie_name", "firemail_session"),
+			CookieDomain:   l.string("AUTH_COOKIE_DOMAIN", "auth.cookie_domain", ""),
+			CookieSecure:   l.bool("AUTH_COOKIE_SECURE", "auth.cookie_secure", true),
+			CookieSameSite: strings.ToLower(l.string("AUTH_COOKIE_SAMESITE", "auth.cookie_same_site", "strict")),
 		},
 		OAuth: OAuthConfig{
 			Gmail: OAuthProviderConfig{
-				ClientID:     getEnv("GMAIL_CLIENT_ID", ""),
-				ClientSecret: getEnv("GMAIL_CLIENT_SECRET", ""),
-				RedirectURL:  getEnv("GMAIL_REDIRECT_URL", ""), // 已废弃：仅使用外部OAuth服务器
+				ClientID:     l.string("GMAIL_CLIENT_ID", "oauth.gmail.client_id", ""),
+				ClientSecret: l.string("GMAIL_CLIENT_SECRET", "oauth.gmail.client_secret", ""),
+				RedirectURL:  l.string("GMAIL_REDIRECT_URL", "oauth.gmail.redirect_url", ""), // 已废弃：仅使用外部OAuth服务器
 			},
 			Outlook: OAuthProviderConfig{
-				ClientID:     getEnv("OUTLOOK_CLIENT_ID", ""),
-				ClientSecret: getEnv("OUTLOOK_CLIENT_SECRET", ""),
-				RedirectURL:  getEnv("OUTLOOK_REDIRECT_URL", ""), // 已废弃：仅使用外部OAuth服务器
+				ClientID:     l.string("OUTLOOK_CLIENT_ID", "oauth.outlook.client_id", ""),
+				ClientSecret: l.string("OUTLOOK_CLIENT_SECRET", "oauth.outlook.client_secret", ""),
+				RedirectURL:  l.string("OUTLOOK_REDIRECT_URL", "oauth.outlook.redirect_url", ""), // 已废弃：仅使用外部OAuth服务器
 			},
 			ExternalServer: ExternalOAuthConfig{
-				BaseURL: getEnv("EXTERNAL_OAUTH_SERVER_URL", "http://localhost:8080"),
-				Enabled: parseBool(getEnv("EXTERNAL_OAUTH_SERVER_ENABLED", "true")),
+				BaseURL: l.string("EXTERNAL_OAUTH_SERVER_URL", "oauth.external_server.base_url", "http://localhost:8080"),
+				Enabled: l.bool("EXTERNAL_OAUTH_SERVER_ENABLED", "oauth.external_server.enabled", true),
 			},
 		},
 		CORS: CORSConfig{
-			Origins: parseStringSlice(getEnv("CORS_ORIGINS", "http://localhost:3000,http://localhost:8080")),
+			Origins: l.stringSlice("CORS_ORIGINS", "cors.origins", "http://localhost:3000,http://localhost:8080"),
 		},
 		Logging: LoggingConfig{
-			Level:  getEnv("LOG_LEVEL", "info"),
-			Format: getEnv("LOG_FORMAT", "json"),
+			Level:  l.string("LOG_LEVEL", "logging.level", "info"),
+			Format: l.string("LOG_FORMAT", "logging.format", "json"),
 		},
 		SSE: SSEConfig{
-			MaxConnectionsPerUser: parseInt(getEnv("SSE_MAX_CONNECTIONS_PER_USER", "5"), 5),
-			ConnectionTimeout:     parseDuration(getEnv("SSE_CONNECTION_TIMEOUT", "30m")),
-			HeartbeatInterval:     parseDuration(getEnv("SSE_HEARTBEAT_INTERVAL", "30s")),
-			CleanupInterval:       parseDuration(getEnv("SSE_CLEANUP_INTERVAL", "5m")),
-			BufferSize:            parseInt(getEnv("SSE_BUFFER_SIZE", "1024"), 1024),
-			EnableHeartbeat:       parseBool(getEnv("SSE_ENABLE_HEARTBEAT", "true")),
+			MaxConnectionsPerUser: l.int("SSE_MAX_CONNECTIONS_PER_USER", "sse.max_connections_per_user", 5),
+			ConnectionTimeout:     l.duration("SSE_CONNECTION_TIMEOUT", "sse.connection_timeout", 30*time.Minute),
+			HeartbeatInterval:     l.duration("SSE_HEARTBEAT_INTERVAL", "sse.heartbeat_interval", 30*time.Second),
+			CleanupInterval:       l.duration("SSE_CLEANUP_INTERVAL", "sse.cleanup_interval", 5*time.Minute),
+			BufferSize:            l.int("SSE_BUFFER_SIZE", "sse.buffer_size", 1024),
+			EnableHeartbeat:       l.bool("SSE_ENABLE_HEARTBEAT", "sse.enable_heartbeat", true),
+		},
+		RateLimit: loadRateLimitConfig(l),
+		Sync: SyncConfig{
+			FolderWorkers:    l.int("SYNC_FOLDER_WORKERS", "sync.folder_workers", 3),
+			MaxFolderWorkers: l.int("SYNC_MAX_FOLDER_WORKERS", "sync.max_folder_workers", 12),
+		},
+		Jobs: JobsConfig{
+			Workers:      l.int("JOB_WORKERS", "jobs.workers", 4),
+			PollInterval: l.duration("JOB_POLL_INTERVAL", "jobs.poll_interval", 2*time.Second),
+			MaxAttempts:  l.int("JOB_MAX_ATTEMPTS", "jobs.max_attempts", 5),
+			Retention:    l.duration("JOB_RETENTION", "jobs.retention", 7*24*time.Hour),
+		},
+		Redis: RedisConfig{
+			URL:          l.string("REDIS_URL", "redis.url", ""),
+			KeyPrefix:    l.string("REDIS_KEY_PREFIX", "redis.key_prefix", "firemail:"),
+			CacheBackend: strings.ToLower(l.string("CACHE_BACKEND", "redis.cache_backend", BackendMemory)),
+			EventBackend: strings.ToLower(l.string("SSE_EVENT_BACKEND", "redis.event_backend", BackendMemory)),
+			JobBackend:   strings.ToLower(l.string("JOB_QUEUE_BACKEND", "redis.job_backend", BackendMemory)),
+		},
+		GraphQL: GraphQLConfig{
+			Enabled:  l.bool("GRAPHQL_ENABLED", "graphql.enabled", false),
+			MaxDepth: l.int("GRAPHQL_MAX_DEPTH", "graphql.max_depth", 8),
+		},
+		Sharing: SharingConfig{
+			BaseURL:       strings.TrimRight(l.string("SHARE_BASE_URL", "sharing.base_url", ""), "/"),
+			DefaultExpiry: l.duration("SHARE_DEFAULT_EXPIRY", "sharing.default_expiry", 72*time.Hour),
+			MaxExpiry:     l.duration("SHARE_MAX_EXPIRY", "sharing.max_expiry", 30*24*time.Hour),
+
+			AttachmentLinkExpiry: l.duration("ATTACHMENT_LINK_EXPIRY", "sharing.attachment_link_expiry", 7*24*time.Hour),
+		},
+		Tracking: TrackingConfig{
+			BaseURL: strings.TrimRight(l.string("TRACKING_BASE_URL", "tracking.base_url", ""), "/"),
+		},
+		GeoIP: GeoIPConfig{
+			LookupURL: l.string("GEOIP_LOOKUP_URL", "geoip.lookup_url", ""),
+			Timeout:   l.duration("GEOIP_TIMEOUT", "geoip.timeout", 3*time.Second),
+		},
+		Compliance: ComplianceConfig{
+			ExportDir: l.string("COMPLIANCE_EXPORT_DIR", "compliance.export_dir", "./exports"),
+		},
+		Ingest: IngestConfig{
+			MaxMessageMB: l.int("INGEST_MAX_MESSAGE_MB", "ingest.max_message_mb", 25),
+		},
+		Access: AccessConfig{
+			AdminAllowlist:   l.stringSlice("ADMIN_IP_ALLOWLIST", "access.admin_allowlist", ""),
+			LoginAllowlist:   l.stringSlice("LOGIN_IP_ALLOWLIST", "access.login_allowlist", ""),
+			AllowedCountries: l.stringSlice("ACCESS_ALLOWED_COUNTRIES", "access.allowed_countries", ""),
+			BlockedCountries: l.stringSlice("ACCESS_BLOCKED_COUNTRIES", "access.blocked_countries", ""),
+			TrustedProxies:   l.stringSlice("TRUSTED_PROXIES", "access.trusted_proxies", "127.0.0.1,::1"),
+			AuthFailureLog:   l.string("AUTH_FAILURE_LOG", "access.auth_failure_log", ""),
+			AuthBanThreshold: l.int("AUTH_FAILURE_BAN_THRESHOLD", "access.auth_ban_threshold", 5),
+			AuthBanWindow:    l.duration("AUTH_FAILURE_BAN_WINDOW", "access.auth_ban_window", 10*time.Minute),
+		},
+		Attachments: AttachmentsConfig{
+			BlockedExtensions: l.stringSlice("ATTACHMENT_BLOCKED_EXTENSIONS", "attachments.blocked_extensions", DefaultBlockedAttachmentExtensions),
+			DownloadTokenTTL:  l.duration("ATTACHMENT_DOWNLOAD_TOKEN_TTL", "attachments.download_token_ttl", 5*time.Minute),
+		},
+		SMTPServer: SMTPServerConfig{
+			Enabled:       l.bool("SMTP_SERVER_ENABLED", "smtp_server.enabled", false),
+			Addrs:         l.stringSlice("SMTP_SERVER_ADDRS", "smtp_server.addrs", ":25,:587"),
+			Hostname:      l.string("SMTP_SERVER_HOSTNAME", "smtp_server.hostname", "localhost"),
+			Domains:       l.stringSlice("SMTP_SERVER_DOMAINS", "smtp_server.domains", ""),
+			TLSCertFile:   l.string("SMTP_SERVER_TLS_CERT", "smtp_server.tls_cert_file", ""),
+			TLSKeyFile:    l.string("SMTP_SERVER_TLS_KEY", "smtp_server.tls_key_file", ""),
+			RequireTLS:    l.bool("SMTP_SERVER_REQUIRE_TLS", "smtp_server.require_tls", false),
+			MaxRecipients: l.int("SMTP_SERVER_MAX_RECIPIENTS", "smtp_server.max_recipients", 100),
+			Timeout:       l.duration("SMTP_SERVER_TIMEOUT", "smtp_server.timeout", 5*time.Minute),
+			DNSBLZones:    l.stringSlice("SMTP_SERVER_DNSBL", "smtp_server.dnsbl_zones", ""),
+		},
+		IMAPServer: IMAPServerConfig{
+			Enabled:           l.bool("IMAP_SERVER_ENABLED", "imap_server.enabled", false),
+			Addrs:             l.stringSlice("IMAP_SERVER_ADDRS", "imap_server.addrs", "127.0.0.1:1143"),
+			TLSCertFile:       l.string("IMAP_SERVER_TLS_CERT", "imap_server.tls_cert_file", ""),
+			TLSKeyFile:        l.string("IMAP_SERVER_TLS_KEY", "imap_server.tls_key_file", ""),
+			AllowInsecureAuth: l.bool("IMAP_SERVER_ALLOW_INSECURE_AUTH", "imap_server.allow_insecure_auth", false),
+		},
+		UserDefaults: UserDefaultsConfig{
+			ReplyAll:           l.bool("DEFAULT_REPLY_ALL", "user_defaults.reply_all", false),
+			BlockRemoteImages:  l.bool("DEFAULT_BLOCK_REMOTE_IMAGES", "user_defaults.block_remote_images", true),
+			StripTrackers:      l.bool("DEFAULT_STRIP_TRACKERS", "user_defaults.strip_trackers", true),
+			Timezone:           l.string("DEFAULT_TIMEZONE", "user_defaults.timezone", "UTC"),
+			EmailsPerPage:      l.int("DEFAULT_EMAILS_PER_PAGE", "user_defaults.emails_per_page", 20),
+			NotificationsMuted: l.bool("DEFAULT_NOTIFICATIONS_MUTED", "user_defaults.notifications_muted", false),
+			Locale:             l.string("DEFAULT_LOCALE", "user_defaults.locale", ""),
+			SyncConflictPolicy: l.string("DEFAULT_SYNC_CONFLICT_POLICY", "user_defaults.sync_conflict_policy", "server_wins"),
+		},
+		Outgoing: OutgoingConfig{
+			EHLOName:        l.string("OUTGOING_EHLO_NAME", "outgoing.ehlo_name", ""),
+			MessageIDDomain: l.string("OUTGOING_MESSAGE_ID_DOMAIN", "outgoing.message_id_domain", ""),
+			Mailer:          l.string("OUTGOING_MAILER", "outgoing.mailer", "FireMail"),
+		},
+		Compose: ComposeConfig{
+			CodeHighlight: l.bool("MARKDOWN_CODE_HIGHLIGHT", "compose.code_highlight", true),
+			CodeTheme:     strings.ToLower(l.string("MARKDOWN_CODE_THEME", "compose.code_theme", "light")),
+		},
+		DNS: DNSConfig{
+			Resolver: strings.TrimSpace(l.string("DNS_RESOLVER", "dns.resolver", "")),
+			Timeout:  l.duration("DNS_TIMEOUT", "dns.timeout", 5*time.Second),
+		},
+		SemanticSearch: SemanticSearchConfig{
+			EmbeddingURL:   strings.TrimSpace(l.string("SEMANTIC_SEARCH_EMBEDDING_URL", "semantic_search.embedding_url", "")),
+			EmbeddingModel: strings.TrimSpace(l.string("SEMANTIC_SEARCH_EMBEDDING_MODEL", "semantic_search.embedding_model", "")),
+			APIKey:         l.string("SEMANTIC_SEARCH_API_KEY", "semantic_search.api_key", ""),
+			Timeout:        l.duration("SEMANTIC_SEARCH_TIMEOUT", "semantic_search.timeout", 30*time.Second),
 		},
 	}
+
+	cfg.configFile = configFile
+	cfg.settings = l.sortedSettings()
+	cfg.loadProblems = l.problems
+	return cfg
 }
 
-// getEnv 获取环境变量，如果不存在则返回默认值
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// LoadAndValidate 加载并校验配置。CONFIG_FILE 指定的JSON配置文件提供基础值，环境变量优先
+func LoadAndValidate() (*Config, error) {
+	cfg := Load()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
 	}
-	return defaultValue
+	return cfg, nil
+}
+
+// defaultProviderRateLimits 各提供商的默认限速，Gmail/QQ对频繁访问较为敏感
+var defaultProviderRateLimits = map[string]ProviderRateLimit{
+	"gmail":   {SendPerMinute: 20, MaxConnections: 3, FetchInterval: 200 * time.Millisecond},
+	"qq":      {SendPerMinute: 10, MaxConnections: 2, FetchInterval: 500 * time.Millisecond},
+	"outlook": {SendPerMinute: 30, MaxConnections: 4, FetchInterval: 200 * time.Millisecond},
+	"163":     {SendPerMinute: 15, MaxConnections: 2, FetchInterval: 300 * time.Millisecond},
+	"icloud":  {SendPerMinute: 20, MaxConnections: 3, FetchInterval: 200 * time.Millisecond},
 }
 
-// parseDuration 解析时间间隔
-func parseDuration(s string) time.Duration {
-	duration, err := time.ParseDuration(s)
-	if err != nil {
-		return 24 * time.Hour // 默认24小时
+// loadRateLimitConfig 加载限速配置，支持 RATE_LIMIT_<PROVIDER>_* 环境变量覆盖
+func loadRateLimitConfig(l *loader) RateLimitConfig {
+	cfg := RateLimitConfig{
+		Enabled: l.bool("RATE_LIMIT_ENABLED", "rate_limit.enabled", true),
+		MaxWait: l.duration("RATE_LIMIT_MAX_WAIT", "rate_limit.max_wait", 30*time.Second),
+		Default: loadProviderRateLimit(l, "DEFAULT", ProviderRateLimit{
+			SendPerMinute:  30,
+			MaxConnections: 5,
+			FetchInterval:  100 * time.Millisecond,
+		}),
+		Providers: make(map[string]ProviderRateLimit, len(defaultProviderRateLimits)),
+	}
+
+	for name, limit := range defaultProviderRateLimits {
+		// 同步带宽默认沿用 DEFAULT 的设置，可按提供商单独覆盖
+		limit.SyncBytesPerMinute = cfg.Default.SyncBytesPerMinute
+		cfg.Providers[name] = loadProviderRateLimit(l, strings.ToUpper(name), limit)
+	}
+
+	return cfg
+}
+
+func loadProviderRateLimit(l *loader, name string, defaults ProviderRateLimit) ProviderRateLimit {
+	prefix := "RATE_LIMIT_" + name + "_"
+	path := "rate_limit.providers." + strings.ToLower(name) + "."
+	if name == "DEFAULT" {
+		path = "rate_limit.default."
+	}
+
+	return ProviderRateLimit{
+		SendPerMinute:  l.int(prefix+"SEND_PER_MINUTE", path+"send_per_minute", defaults.SendPerMinute),
+		MaxConnections: l.int(prefix+"MAX_CONNECTIONS", path+"max_connections", defaults.MaxConnections),
+		FetchInterval:  l.duration(prefix+"FETCH_INTERVAL", path+"fetch_interval", defaults.FetchInterval),
+
+		SyncBytesPerMinute: l.int(prefix+"SYNC_BYTES_PER_MINUTE", path+"sync_bytes_per_minute", defaults.SyncBytesPerMinute),
 	}
-	return duration
 }
 
 // parseStringSlice 解析字符串切片
@@ -163,20 +552,4 @@ func parseStringSlice(s string) []string {
 	return strings.Split(s, ",")
 }
 
-// parseBool 解析布尔值
-func parseBool(s string) bool {
-	b, err := strconv.ParseBool(s)
-	if err != nil {
-		return false
-	}
-	return b
-}
 
-// parseInt 解析整数
-func parseInt(s string, defaultValue int) int {
-	i, err := strconv.Atoi(s)
-	if err != nil {
-		return defaultValue
-	}
-	return i
-}
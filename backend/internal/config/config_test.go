@@ -0,0 +1,118 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "firemail.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func findSetting(t *testing.T, cfg *Config, key string) Setting {
+	t.Helper()
+
+	for _, setting := range cfg.Settings() {
+		if setting.Key == key {
+			return setting
+		}
+	}
+	t.Fatalf("setting %s not found", key)
+	return Setting{}
+}
+
+func TestLoadMergesConfigFileAndEnvironment(t *testing.T) {
+	path := writeConfigFile(t, `{
+		"server": {"port": 9090, "shutdown_timeout": "45s"},
+		"auth": {"jwt_secret": "file-secret-with-enough-length-0123456789"},
+		"cors": {"origins": ["https://a.example.com", "https://b.example.com"]},
+		"rate_limit": {"providers": {"qq": {"send_per_minute": 3}}}
+	}`)
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("SHUTDOWN_TIMEOUT", "10s")
+
+	cfg := Load()
+	require.NoError(t, cfg.Validate())
+	require.Equal(t, path, cfg.ConfigFile())
+
+	require.Equal(t, "9090", cfg.Server.Port)
+	require.Equal(t, 10*time.Second, cfg.Server.ShutdownTimeout)
+	require.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, cfg.CORS.Origins)
+	require.Equal(t, 3, cfg.RateLimit.ForProvider("qq").SendPerMinute)
+
+	// 环境变量优先于配置文件
+	require.Equal(t, SourceEnv, findSetting(t, cfg, "SHUTDOWN_TIMEOUT").Source)
+	require.Equal(t, SourceFile, findSetting(t, cfg, "PORT").Source)
+	require.Equal(t, SourceDefault, findSetting(t, cfg, "HOST").Source)
+
+	// 敏感值脱敏
+	secret := findSetting(t, cfg, "JWT_SECRET")
+	require.True(t, secret.Secret)
+	require.Equal(t, RedactedValue, secret.Value)
+}
+
+func TestValidateReportsInvalidValues(t *testing.T) {
+	t.Setenv("PORT", "http")
+	t.Setenv("JWT_EXPIRY", "one day")
+	t.Setenv("SSE_BUFFER_SIZE", "0")
+	t.Setenv("CACHE_BACKEND", "redis")
+	t.Setenv("DNS_RESOLVER", "http://dns.example.com/dns-query")
+	t.Setenv("DB_JOURNAL_MODE", "WAL; DROP TABLE users")
+	t.Setenv("DB_MAX_OPEN_CONNS", "0")
+	t.Setenv("SEMANTIC_SEARCH_EMBEDDING_URL", "localhost:11434/v1/embeddings")
+
+	err := Load().Validate()
+	require.Error(t, err)
+	for _, key := range []string{"PORT", "JWT_EXPIRY", "SSE_BUFFER_SIZE", "REDIS_URL", "DNS_RESOLVER", "DB_JOURNAL_MODE", "DB_MAX_OPEN_CONNS",
+		"SEMANTIC_SEARCH_EMBEDDING_URL", "SEMANTIC_SEARCH_EMBEDDING_MODEL"} {
+		require.True(t, strings.Contains(err.Error(), key+":"), "expected problem for %s in %v", key, err)
+	}
+}
+
+func TestValidateRejectsDefaultJWTSecretInProduction(t *testing.T) {
+	t.Setenv("ENV", "production")
+
+	err := Load().Validate()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "JWT_SECRET")
+
+	t.Setenv("JWT_SECRET", "a-production-secret-that-is-long-enough")
+	cfg, err := LoadAndValidate()
+	require.NoError(t, err)
+	require.Len(t, cfg.Warnings(), 1) // 仍使用默认管理员密码
+}
+
+func TestRedactSecretKeepsRedisAddress(t *testing.T) {
+	require.Equal(t, "redis://:xxxxx@cache.internal:6379/0", RedactSecret("REDIS_URL", "redis://:hunter2@cache.internal:6379/0"))
+	require.Equal(t, RedactedValue, RedactSecret("JWT_SECRET", "hunter2"))
+	require.Empty(t, RedactSecret("JWT_SECRET", ""))
+}
+
+func TestParseDNSResolver(t *testing.T) {
+	_, server, err := ParseDNSResolver("1.1.1.1")
+	require.NoError(t, err)
+	require.Equal(t, "1.1.1.1:53", server)
+
+	_, server, err = ParseDNSResolver("[2606:4700:4700::1111]:5353")
+	require.NoError(t, err)
+	require.Equal(t, "[2606:4700:4700::1111]:5353", server)
+
+	dohURL, server, err := ParseDNSResolver(" https://1.1.1.1/dns-query ")
+	require.NoError(t, err)
+	require.Equal(t, "https://1.1.1.1/dns-query", dohURL)
+	require.Empty(t, server)
+
+	for _, spec := range []string{"http://1.1.1.1/dns-query", "1.1.1.1:0", "dns server", "https://"} {
+		_, _, err := ParseDNSResolver(spec)
+		require.Error(t, err, spec)
+	}
+}
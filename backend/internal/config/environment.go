@@ -164,6 +164,11 @@ func (e *Environment) ShouldEnableEnhancedDedup() bool {
 	return e.EnableEnhancedDedup && !e.IsTestMode()
 }
 
+// ShouldEnableMetrics 是否暴露Prometheus监控指标
+func (e *Environment) ShouldEnableMetrics() bool {
+	return e.EnableMetrics
+}
+
 // adjustForTestMode 调整测试模式配置
 func (e *Environment) adjustForTestMode() {
 	e.Debug = true
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 配置值来源
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+)
+
+// Setting 单个配置项的取值和来源
+type Setting struct {
+	Key    string `json:"key"`            // 环境变量名
+	Path   string `json:"path,omitempty"` // 配置文件中的路径
+	Value  string `json:"value"`
+	Source string `json:"source"` // default, file, env
+	Secret bool   `json:"secret,omitempty"`
+}
+
+// secretKeys 需要脱敏的配置项
+var secretKeys = map[string]bool{
+	"ADMIN_PASSWORD":          true,
+	"JWT_SECRET":              true,
+	"GMAIL_CLIENT_SECRET":     true,
+	"OUTLOOK_CLIENT_SECRET":   true,
+	"REDIS_URL":               true,
+	"GEOIP_LOOKUP_URL":        true,
+	"SEMANTIC_SEARCH_API_KEY": true,
+}
+
+// loader 按 环境变量 > 配置文件 > 默认值 的优先级读取配置，记录每项的来源和解析错误
+type loader struct {
+	file     map[string]string // 配置文件中的值，键为点分路径
+	settings []Setting
+	problems []string
+}
+
+// newLoader 创建配置读取器，path 为空时只读取环境变量；配置文件无法读取时记录为配置问题
+func newLoader(path string) *loader {
+	l := &loader{file: make(map[string]string)}
+	if path == "" {
+		return l
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		l.problems = append(l.problems, fmt.Sprintf("CONFIG_FILE: failed to read %s: %v", path, err))
+		return l
+	}
+
+	var document map[string]interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		l.problems = append(l.problems, fmt.Sprintf("CONFIG_FILE: failed to parse %s: %v", path, err))
+		return l
+	}
+	flattenConfigFile("", document, l.file)
+	return l
+}
+
+// flattenConfigFile 将嵌套的配置文件展开为点分路径
+func flattenConfigFile(prefix string, value interface{}, out map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			flattenConfigFile(path, child, out)
+		}
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+		out[prefix] = strings.Join(items, ",")
+	case float64:
+		out[prefix] = strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+	default:
+		out[prefix] = fmt.Sprint(v)
+	}
+}
+
+// lookup 读取原始值，返回值和来源
+func (l *loader) lookup(key, path string) (string, string, bool) {
+	if value := os.Getenv(key); value != "" {
+		return value, SourceEnv, true
+	}
+	if value, ok := l.file[path]; ok && value != "" {
+		return value, SourceFile, true
+	}
+	return "", SourceDefault, false
+}
+
+func (l *loader) record(key, path, value, source string) {
+	l.settings = append(l.settings, Setting{
+		Key:    key,
+		Path:   path,
+		Value:  value,
+		Source: source,
+		Secret: secretKeys[key],
+	})
+}
+
+func (l *loader) invalid(key, raw, expected string) {
+	l.problems = append(l.problems, fmt.Sprintf("%s: invalid value %q, expected %s", key, raw, expected))
+}
+
+func (l *loader) string(key, path, defaultValue string) string {
+	value, source, ok := l.lookup(key, path)
+	if !ok {
+		value = defaultValue
+	}
+	l.record(key, path, value, source)
+	return value
+}
+
+func (l *loader) int(key, path string, defaultValue int) int {
+	raw, source, ok := l.lookup(key, path)
+	value := defaultValue
+	if ok {
+		parsed, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			l.invalid(key, raw, "an integer")
+		} else {
+			value = parsed
+		}
+	}
+	l.record(key, path, strconv.Itoa(value), source)
+	return value
+}
+
+func (l *loader) bool(key, path string, defaultValue bool) bool {
+	raw, source, ok := l.lookup(key, path)
+	value := defaultValue
+	if ok {
+		parsed, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			l.invalid(key, raw, "true or false")
+		} else {
+			value = parsed
+		}
+	}
+	l.record(key, path, strconv.FormatBool(value), source)
+	return value
+}
+
+func (l *loader) duration(key, path string, defaultValue time.Duration) time.Duration {
+	raw, source, ok := l.lookup(key, path)
+	value := defaultValue
+	if ok {
+		parsed, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil {
+			l.invalid(key, raw, "a duration such as 30s or 5m")
+		} else {
+			value = parsed
+		}
+	}
+	l.record(key, path, value.String(), source)
+	return value
+}
+
+func (l *loader) stringSlice(key, path, defaultValue string) []string {
+	return parseStringSlice(l.string(key, path, defaultValue))
+}
+
+// sortedSettings 按键排序的配置项
+func (l *loader) sortedSettings() []Setting {
+	settings := append([]Setting(nil), l.settings...)
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i].Key < settings[j].Key
+	})
+	return settings
+}
@@ -15,11 +15,61 @@ type EmailProviderConfig struct {
 	AuthMethods  []string               `json:"auth_methods"`  // "password", "oauth2"
 	OAuth2Config *OAuth2Config          `json:"oauth2_config,omitempty"`
 	Domains      []string               `json:"domains"`               // 支持的域名
+	MXHosts      []string               `json:"mx_hosts,omitempty"`    // 托管自定义域名时MX记录指向的主机后缀
 	Features     map[string]bool        `json:"features,omitempty"`    // 功能特性
 	Limits       map[string]interface{} `json:"limits,omitempty"`      // 限制信息
 	ErrorCodes   map[string]string      `json:"error_codes,omitempty"` // 错误代码说明
 	HelpURLs     map[string]string      `json:"help_urls,omitempty"`   // 帮助链接
 	Metadata     map[string]string      `json:"metadata,omitempty"`
+	Quirks       *ProviderQuirks        `json:"quirks,omitempty"` // 提供商的特殊行为
+}
+
+// ProviderQuirks 提供商的特殊行为集中在配置中说明，同步代码按配置处理而不是按提供商名称分支
+type ProviderQuirks struct {
+	// PriorityFolders 按顺序优先同步的文件夹，其余文件夹在其后同步
+	PriorityFolders []string `json:"priority_folders,omitempty"`
+	// FolderTypes 服务器返回的文件夹名称无法识别类型时，按名称指定文件夹类型
+	FolderTypes map[string]string `json:"folder_types,omitempty"`
+	// RequiresIMAPID 登录后需要发送IMAP ID标识客户端，否则选择文件夹时被拒绝（Unsafe Login）
+	RequiresIMAPID bool `json:"requires_imap_id,omitempty"`
+	// MaxFolderWorkers 服务器严格限制并发连接时，并行同步文件夹的连接数上限，0表示不限制
+	MaxFolderWorkers int `json:"max_folder_workers,omitempty"`
+	// MaxMessagesPerSession 批量发送时同一SMTP会话最多发送的邮件数，达到后重新连接，0表示使用默认值
+	MaxMessagesPerSession int `json:"max_messages_per_session,omitempty"`
+}
+
+// MessageSizeLimit 整封邮件（附件按base64编码后）的大小上限，单位字节，未配置时返回0
+func (p *EmailProviderConfig) MessageSizeLimit() int64 {
+	switch value := p.Limits["message_size"].(type) {
+	case int:
+		return int64(value)
+	case int64:
+		return value
+	case float64:
+		return int64(value)
+	}
+	return 0
+}
+
+// PriorityRank 文件夹在优先同步列表中的位置，不在列表中时返回列表长度
+func (q *ProviderQuirks) PriorityRank(name string) int {
+	if q == nil {
+		return 0
+	}
+	for i, folder := range q.PriorityFolders {
+		if strings.EqualFold(folder, name) {
+			return i
+		}
+	}
+	return len(q.PriorityFolders)
+}
+
+// FolderType 按名称指定的文件夹类型，未指定时返回空字符串
+func (q *ProviderQuirks) FolderType(name string) string {
+	if q == nil {
+		return ""
+	}
+	return q.FolderTypes[name]
 }
 
 // OAuth2Config OAuth2配置
@@ -65,6 +115,7 @@ func GetBuiltinProviders() map[string]EmailProviderConfig {
 			},
 			Limits: map[string]interface{}{
 				"attachment_size":  25 * 1024 * 1024,
+				"message_size":     25 * 1024 * 1024,
 				"daily_send":       500,
 				"max_recipients":   500,
 				"storage_free":     15 * 1024 * 1024 * 1024,
@@ -121,6 +172,7 @@ func GetBuiltinProviders() map[string]EmailProviderConfig {
 			},
 			Limits: map[string]interface{}{
 				"attachment_size":  25 * 1024 * 1024,
+				"message_size":     25 * 1024 * 1024,
 				"daily_send":       300,
 				"max_recipients":   500,
 				"storage_free":     15 * 1024 * 1024 * 1024,
@@ -165,6 +217,7 @@ func GetBuiltinProviders() map[string]EmailProviderConfig {
 			},
 			Limits: map[string]interface{}{
 				"attachment_size":  50 * 1024 * 1024,
+				"message_size":     50 * 1024 * 1024,
 				"daily_send":       500,
 				"hourly_send":      50,
 				"max_recipients":   100,
@@ -187,6 +240,24 @@ func GetBuiltinProviders() map[string]EmailProviderConfig {
 				"help_url":           "https://service.mail.qq.com/cgi-bin/help?subtype=1&&no=1000585",
 				"requires_auth_code": "true",
 			},
+			// QQ邮箱的中文文件夹名与通用识别规则不一致（"垃圾箱"是垃圾邮件而不是已删除），
+			// 未发送IMAP ID时部分账户无法选择文件夹；同一SMTP会话连续发信过多会被断开
+			Quirks: &ProviderQuirks{
+				PriorityFolders: []string{"INBOX"},
+				FolderTypes: map[string]string{
+					"Sent Messages":    "sent",
+					"Deleted Messages": "trash",
+					"Drafts":           "drafts",
+					"Junk":             "spam",
+					"已发送":              "sent",
+					"已删除":              "trash",
+					"草稿箱":              "drafts",
+					"垃圾箱":              "spam",
+				},
+				RequiresIMAPID:        true,
+				MaxFolderWorkers:      2,
+				MaxMessagesPerSession: 20,
+			},
 		},
 		"163": {
 			Name:         "163",
@@ -210,6 +281,7 @@ func GetBuiltinProviders() map[string]EmailProviderConfig {
 			},
 			Limits: map[string]interface{}{
 				"attachment_size":  50 * 1024 * 1024,
+				"message_size":     50 * 1024 * 1024,
 				"daily_send":       200,
 				"max_recipients":   100,
 				"mailbox_size":     3 * 1024 * 1024 * 1024,
@@ -219,6 +291,17 @@ func GetBuiltinProviders() map[string]EmailProviderConfig {
 				"app_password_url": "https://mail.163.com/",
 				"help_url":         "https://help.mail.163.com/faqDetail.do?code=d7a5dc8471cd0c0e8b4b8f4f8e49998b374173cfe9171312",
 			},
+			// 网易邮箱把注册、登录类的验证码邮件归入"订阅邮件"，与收件箱一起优先同步；
+			// 对IMAP连接和SMTP会话限流严格，未发送IMAP ID的客户端无法选择文件夹
+			Quirks: &ProviderQuirks{
+				PriorityFolders: []string{"INBOX", "订阅邮件"},
+				FolderTypes: map[string]string{
+					"病毒文件夹": "spam",
+				},
+				RequiresIMAPID:        true,
+				MaxFolderWorkers:      2,
+				MaxMessagesPerSession: 10,
+			},
 		},
 		"icloud": {
 			Name:         "icloud",
@@ -231,6 +314,7 @@ func GetBuiltinProviders() map[string]EmailProviderConfig {
 			SMTPSecurity: "STARTTLS",
 			AuthMethods:  []string{"password"},
 			Domains:      []string{"icloud.com", "me.com", "mac.com"},
+			MXHosts:      []string{"mail.icloud.com"},
 			Features: map[string]bool{
 				"imap":       true,
 				"smtp":       true,
@@ -245,6 +329,7 @@ func GetBuiltinProviders() map[string]EmailProviderConfig {
 			},
 			Limits: map[string]interface{}{
 				"attachment_size":  20 * 1024 * 1024,
+				"message_size":     20 * 1024 * 1024,
 				"daily_send":       1000,
 				"max_recipients":   500,
 				"mailbox_size":     5 * 1024 * 1024 * 1024,
@@ -276,6 +361,27 @@ func GetBuiltinProviders() map[string]EmailProviderConfig {
 				"help_url": "https://help.sina.com.cn/",
 			},
 		},
+		"exchange": {
+			Name:         "exchange",
+			DisplayName:  "Exchange (EWS)",
+			IMAPPort:     443,
+			IMAPSecurity: "SSL",
+			SMTPPort:     443,
+			SMTPSecurity: "SSL",
+			AuthMethods:  []string{"password"},
+			Domains:      []string{}, // 本地部署，支持任意域名
+			Features: map[string]bool{
+				"imap":         false,
+				"smtp":         false,
+				"ews":          true,
+				"autodiscover": true,
+				"folders":      true,
+				"search":       true,
+			},
+			Metadata: map[string]string{
+				"description": "本地部署的Exchange服务器，通过EWS收发邮件，服务器地址留空时自动发现",
+			},
+		},
 		"custom": {
 			Name:        "custom",
 			DisplayName: "自定义IMAP/SMTP",
@@ -305,6 +411,24 @@ func GetProviderByDomain(domain string) *EmailProviderConfig {
 	return &custom
 }
 
+// GetProviderByMX 根据域名的MX主机查找托管该域名的提供商，用于识别自定义域名邮箱，没有匹配时返回nil
+func GetProviderByMX(mxHosts []string) *EmailProviderConfig {
+	providers := GetBuiltinProviders()
+
+	for _, host := range mxHosts {
+		host = strings.ToLower(strings.TrimSuffix(host, "."))
+		for _, provider := range providers {
+			for _, suffix := range provider.MXHosts {
+				if host == suffix || strings.HasSuffix(host, "."+suffix) {
+					return &provider
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
 // GetProviderByName 根据名称获取提供商配置
 func GetProviderByName(name string) *EmailProviderConfig {
 	providers := GetBuiltinProviders()
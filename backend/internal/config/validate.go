@@ -0,0 +1,501 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"firemail/internal/i18n"
+	"firemail/internal/markdown"
+)
+
+// 内置的默认凭据，只适合本地开发
+const (
+	DefaultAdminPassword = "admin123"
+	DefaultJWTSecret     = "your-secret-key"
+)
+
+// RedactedValue 敏感配置在日志和接口中的显示值
+const RedactedValue = "******"
+
+// minProductionJWTSecretLength 生产环境建议的JWT密钥最小长度
+const minProductionJWTSecretLength = 32
+
+var (
+	validEnvironments = map[string]bool{"development": true, "production": true, "test": true}
+	validLogLevels    = map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
+	validLogFormats   = map[string]bool{"json": true, "text": true}
+	validBackends     = map[string]bool{BackendMemory: true, BackendRedis: true}
+	validOAuthSchemes = map[string]bool{"http": true, "https": true}
+	validRedisSchemes = map[string]bool{"redis": true, "rediss": true}
+
+	sqliteJournalModes     = map[string]bool{"WAL": true, "DELETE": true, "TRUNCATE": true, "PERSIST": true, "MEMORY": true, "OFF": true}
+	sqliteSynchronousModes = map[string]bool{"OFF": true, "NORMAL": true, "FULL": true, "EXTRA": true}
+)
+
+// IsProduction 是否为生产环境
+func (c *Config) IsProduction() bool {
+	return c.Server.Env == "production"
+}
+
+// Validate 校验配置，返回所有问题的汇总；生产环境下使用内置JWT密钥也视为错误
+func (c *Config) Validate() error {
+	problems := append([]string(nil), c.loadProblems...)
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if !validEnvironments[c.Server.Env] {
+		add("ENV: unknown environment %q, expected development, production or test", c.Server.Env)
+	}
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		add("PORT: %q is not a valid port (1-65535)", c.Server.Port)
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		add("SHUTDOWN_TIMEOUT: must be positive")
+	}
+
+	if strings.TrimSpace(c.Database.Path) == "" {
+		add("DB_PATH: must not be empty")
+	}
+	if c.Database.BackupMaxCount < 1 {
+		add("DB_BACKUP_MAX_COUNT: must be at least 1")
+	}
+	if c.Database.BackupIntervalHours < 1 {
+		add("DB_BACKUP_INTERVAL_HOURS: must be at least 1")
+	}
+	if !sqliteJournalModes[strings.ToUpper(c.Database.JournalMode)] {
+		add("DB_JOURNAL_MODE: unknown journal mode %q, expected WAL, DELETE, TRUNCATE, PERSIST, MEMORY or OFF", c.Database.JournalMode)
+	}
+	if c.Database.BusyTimeout < 0 {
+		add("DB_BUSY_TIMEOUT: must not be negative")
+	}
+	if !sqliteSynchronousModes[strings.ToUpper(c.Database.Synchronous)] {
+		add("DB_SYNCHRONOUS: unknown synchronous mode %q, expected OFF, NORMAL, FULL or EXTRA", c.Database.Synchronous)
+	}
+	if c.Database.CacheSizeMB < 0 {
+		add("DB_CACHE_SIZE_MB: must not be negative")
+	}
+	if c.Database.MaxOpenConns < 1 {
+		add("DB_MAX_OPEN_CONNS: must be at least 1")
+	}
+
+	if strings.TrimSpace(c.Auth.AdminUsername) == "" {
+		add("ADMIN_USERNAME: must not be empty")
+	}
+	if c.Auth.AdminPassword == "" {
+		add("ADMIN_PASSWORD: must not be empty")
+	}
+	if strings.TrimSpace(c.Auth.JWTSecret) == "" {
+		add("JWT_SECRET: must not be empty")
+	}
+	if c.Auth.JWTExpiry <= 0 {
+		add("JWT_EXPIRY: must be positive")
+	}
+	if c.IsProduction() && c.Auth.JWTSecret == DefaultJWTSecret {
+		add("JWT_SECRET: the built-in default must not be used in production")
+	}
+	switch c.Auth.Mode {
+	case AuthModeHeader, AuthModeCookie, AuthModeBoth:
+	default:
+		add("AUTH_MODE: %q is not one of header, cookie, both", c.Auth.Mode)
+	}
+	if c.Auth.Mode != AuthModeHeader {
+		if strings.TrimSpace(c.Auth.CookieName) == "" {
+			add("AUTH_COOKIE_NAME: must not be empty")
+		}
+		switch c.Auth.CookieSameSite {
+		case "strict", "lax":
+		case "none":
+			if !c.Auth.CookieSecure {
+				add("AUTH_COOKIE_SAMESITE: none requires AUTH_COOKIE_SECURE=true")
+			}
+		default:
+			add("AUTH_COOKIE_SAMESITE: %q is not one of strict, lax, none", c.Auth.CookieSameSite)
+		}
+	}
+
+	if c.OAuth.ExternalServer.Enabled {
+		if u, err := url.Parse(c.OAuth.ExternalServer.BaseURL); err != nil || !validOAuthSchemes[u.Scheme] || u.Host == "" {
+			add("EXTERNAL_OAUTH_SERVER_URL: %q is not a valid http(s) URL", c.OAuth.ExternalServer.BaseURL)
+		}
+	}
+
+	if !validLogLevels[strings.ToLower(c.Logging.Level)] {
+		add("LOG_LEVEL: unknown level %q, expected debug, info, warn or error", c.Logging.Level)
+	}
+	if !validLogFormats[strings.ToLower(c.Logging.Format)] {
+		add("LOG_FORMAT: unknown format %q, expected json or text", c.Logging.Format)
+	}
+
+	if c.SSE.MaxConnectionsPerUser < 1 {
+		add("SSE_MAX_CONNECTIONS_PER_USER: must be at least 1")
+	}
+	if c.SSE.ConnectionTimeout <= 0 {
+		add("SSE_CONNECTION_TIMEOUT: must be positive")
+	}
+	if c.SSE.EnableHeartbeat && c.SSE.HeartbeatInterval <= 0 {
+		add("SSE_HEARTBEAT_INTERVAL: must be positive when heartbeat is enabled")
+	}
+	if c.SSE.CleanupInterval <= 0 {
+		add("SSE_CLEANUP_INTERVAL: must be positive")
+	}
+	if c.SSE.BufferSize < 1 {
+		add("SSE_BUFFER_SIZE: must be at least 1")
+	}
+
+	if c.RateLimit.MaxWait < 0 {
+		add("RATE_LIMIT_MAX_WAIT: must not be negative")
+	}
+	for name, limit := range c.RateLimit.Providers {
+		validateProviderRateLimit(strings.ToUpper(name), limit, add)
+	}
+	validateProviderRateLimit("DEFAULT", c.RateLimit.Default, add)
+
+	if c.Sync.FolderWorkers < 1 {
+		add("SYNC_FOLDER_WORKERS: must be at least 1")
+	}
+	if c.Sync.MaxFolderWorkers < 0 {
+		add("SYNC_MAX_FOLDER_WORKERS: must not be negative")
+	}
+
+	if c.Jobs.Workers < 1 {
+		add("JOB_WORKERS: must be at least 1")
+	}
+	if c.Jobs.PollInterval <= 0 {
+		add("JOB_POLL_INTERVAL: must be positive")
+	}
+	if c.Jobs.MaxAttempts < 1 {
+		add("JOB_MAX_ATTEMPTS: must be at least 1")
+	}
+	if c.Jobs.Retention <= 0 {
+		add("JOB_RETENTION: must be positive")
+	}
+
+	if !validBackends[c.Redis.CacheBackend] {
+		add("CACHE_BACKEND: unknown backend %q, expected memory or redis", c.Redis.CacheBackend)
+	}
+	if !validBackends[c.Redis.EventBackend] {
+		add("SSE_EVENT_BACKEND: unknown backend %q, expected memory or redis", c.Redis.EventBackend)
+	}
+	if !validBackends[c.Redis.JobBackend] {
+		add("JOB_QUEUE_BACKEND: unknown backend %q, expected memory or redis", c.Redis.JobBackend)
+	}
+	if c.Redis.CacheBackend == BackendRedis || c.Redis.EventBackend == BackendRedis || c.Redis.JobBackend == BackendRedis {
+		if c.Redis.URL == "" {
+			add("REDIS_URL: required when a redis backend is selected")
+		} else if u, err := url.Parse(c.Redis.URL); err != nil || !validRedisSchemes[u.Scheme] {
+			add("REDIS_URL: not a valid redis:// or rediss:// URL")
+		}
+	}
+
+	if c.GraphQL.Enabled && c.GraphQL.MaxDepth < 1 {
+		add("GRAPHQL_MAX_DEPTH: must be at least 1")
+	}
+
+	if c.Sharing.BaseURL != "" {
+		if u, err := url.Parse(c.Sharing.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("SHARE_BASE_URL: not a valid http:// or https:// URL")
+		}
+	}
+	if c.Tracking.BaseURL != "" {
+		if u, err := url.Parse(c.Tracking.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("TRACKING_BASE_URL: not a valid http:// or https:// URL")
+		}
+	}
+	if c.Sharing.DefaultExpiry <= 0 {
+		add("SHARE_DEFAULT_EXPIRY: must be positive")
+	}
+	if c.Sharing.MaxExpiry < c.Sharing.DefaultExpiry {
+		add("SHARE_MAX_EXPIRY: must not be shorter than SHARE_DEFAULT_EXPIRY")
+	}
+	if c.Sharing.AttachmentLinkExpiry <= 0 {
+		add("ATTACHMENT_LINK_EXPIRY: must be positive")
+	}
+
+	if c.GeoIP.LookupURL != "" {
+		if u, err := url.Parse(strings.ReplaceAll(c.GeoIP.LookupURL, "{ip}", "127.0.0.1")); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("GEOIP_LOOKUP_URL: not a valid http:// or https:// URL")
+		} else if !strings.Contains(c.GeoIP.LookupURL, "{ip}") {
+			add("GEOIP_LOOKUP_URL: must contain the {ip} placeholder")
+		}
+	}
+	if c.GeoIP.Timeout <= 0 {
+		add("GEOIP_TIMEOUT: must be positive")
+	}
+
+	if strings.TrimSpace(c.Compliance.ExportDir) == "" {
+		add("COMPLIANCE_EXPORT_DIR: must not be empty")
+	}
+
+	if c.Ingest.MaxMessageMB <= 0 {
+		add("INGEST_MAX_MESSAGE_MB: must be positive")
+	}
+
+	ipLists := []struct {
+		name    string
+		entries []string
+	}{
+		{"ADMIN_IP_ALLOWLIST", c.Access.AdminAllowlist},
+		{"LOGIN_IP_ALLOWLIST", c.Access.LoginAllowlist},
+		{"TRUSTED_PROXIES", c.Access.TrustedProxies},
+	}
+	for _, list := range ipLists {
+		for _, entry := range list.entries {
+			if !validIPOrCIDR(entry) {
+				add("%s: %q is not an IP address or CIDR", list.name, entry)
+			}
+		}
+	}
+	if c.Access.AuthBanThreshold <= 0 {
+		add("AUTH_FAILURE_BAN_THRESHOLD: must be positive")
+	}
+	if c.Access.AuthBanWindow <= 0 {
+		add("AUTH_FAILURE_BAN_WINDOW: must be positive")
+	}
+	countryLists := []struct {
+		name  string
+		codes []string
+	}{
+		{"ACCESS_ALLOWED_COUNTRIES", c.Access.AllowedCountries},
+		{"ACCESS_BLOCKED_COUNTRIES", c.Access.BlockedCountries},
+	}
+	for _, list := range countryLists {
+		for _, code := range list.codes {
+			if len(strings.TrimSpace(code)) != 2 {
+				add("%s: %q is not a two-letter country code", list.name, code)
+			}
+		}
+		if len(list.codes) > 0 && c.GeoIP.LookupURL == "" {
+			add("%s: requires GEOIP_LOOKUP_URL", list.name)
+		}
+	}
+
+	for _, ext := range c.Attachments.BlockedExtensions {
+		if strings.ContainsAny(strings.TrimSpace(ext), "./\\ ") {
+			add("ATTACHMENT_BLOCKED_EXTENSIONS: invalid extension %q, use names without dots such as exe", ext)
+		}
+	}
+	if c.Attachments.DownloadTokenTTL <= 0 || c.Attachments.DownloadTokenTTL > 24*time.Hour {
+		add("ATTACHMENT_DOWNLOAD_TOKEN_TTL: must be between 1s and 24h")
+	}
+
+	if c.SMTPServer.Enabled {
+		if len(c.SMTPServer.Addrs) == 0 {
+			add("SMTP_SERVER_ADDRS: at least one listen address is required")
+		}
+		if len(c.SMTPServer.Domains) == 0 {
+			add("SMTP_SERVER_DOMAINS: at least one domain is required")
+		}
+		if strings.TrimSpace(c.SMTPServer.Hostname) == "" {
+			add("SMTP_SERVER_HOSTNAME: must not be empty")
+		}
+		if (c.SMTPServer.TLSCertFile == "") != (c.SMTPServer.TLSKeyFile == "") {
+			add("SMTP_SERVER_TLS_CERT/SMTP_SERVER_TLS_KEY: both must be set to enable STARTTLS")
+		}
+		if c.SMTPServer.RequireTLS && c.SMTPServer.TLSCertFile == "" {
+			add("SMTP_SERVER_REQUIRE_TLS: requires SMTP_SERVER_TLS_CERT and SMTP_SERVER_TLS_KEY")
+		}
+		if c.SMTPServer.MaxRecipients < 1 {
+			add("SMTP_SERVER_MAX_RECIPIENTS: must be at least 1")
+		}
+		if c.SMTPServer.Timeout <= 0 {
+			add("SMTP_SERVER_TIMEOUT: must be positive")
+		}
+	}
+
+	if c.IMAPServer.Enabled {
+		if len(c.IMAPServer.Addrs) == 0 {
+			add("IMAP_SERVER_ADDRS: at least one listen address is required")
+		}
+		if (c.IMAPServer.TLSCertFile == "") != (c.IMAPServer.TLSKeyFile == "") {
+			add("IMAP_SERVER_TLS_CERT/IMAP_SERVER_TLS_KEY: both must be set to enable STARTTLS")
+		}
+		if c.IMAPServer.TLSCertFile == "" && !c.IMAPServer.AllowInsecureAuth {
+			add("IMAP_SERVER_TLS_CERT: required unless IMAP_SERVER_ALLOW_INSECURE_AUTH is true, clients could not log in")
+		}
+	}
+
+	if _, err := time.LoadLocation(c.UserDefaults.Timezone); err != nil || c.UserDefaults.Timezone == "" {
+		add("DEFAULT_TIMEZONE: unknown time zone %q", c.UserDefaults.Timezone)
+	}
+	if c.UserDefaults.EmailsPerPage < 1 || c.UserDefaults.EmailsPerPage > 100 {
+		add("DEFAULT_EMAILS_PER_PAGE: must be between 1 and 100")
+	}
+	if c.UserDefaults.Locale != "" {
+		if _, ok := i18n.Normalize(c.UserDefaults.Locale); !ok {
+			add("DEFAULT_LOCALE: unsupported locale %q, expected one of %s", c.UserDefaults.Locale, strings.Join(i18n.Supported(), ", "))
+		}
+	}
+	switch c.UserDefaults.SyncConflictPolicy {
+	case "server_wins", "local_wins", "ask":
+	default:
+		add("DEFAULT_SYNC_CONFLICT_POLICY: unknown policy %q, expected server_wins, local_wins or ask", c.UserDefaults.SyncConflictPolicy)
+	}
+
+	if c.Outgoing.EHLOName != "" && !ValidEHLOName(c.Outgoing.EHLOName) {
+		add("OUTGOING_EHLO_NAME: %q is not a valid host name or address literal", c.Outgoing.EHLOName)
+	}
+	if c.Outgoing.MessageIDDomain != "" && !ValidHostname(c.Outgoing.MessageIDDomain) {
+		add("OUTGOING_MESSAGE_ID_DOMAIN: %q is not a valid domain", c.Outgoing.MessageIDDomain)
+	}
+	if strings.ContainsAny(c.Outgoing.Mailer, "\r\n") {
+		add("OUTGOING_MAILER: must not contain line breaks")
+	}
+	if !markdown.IsTheme(c.Compose.CodeTheme) {
+		add("MARKDOWN_CODE_THEME: must be one of %s", strings.Join(markdown.Themes(), ", "))
+	}
+	if _, _, err := ParseDNSResolver(c.DNS.Resolver); err != nil {
+		add("DNS_RESOLVER: %v", err)
+	}
+	if c.DNS.Timeout <= 0 {
+		add("DNS_TIMEOUT: must be positive")
+	}
+	if c.SemanticSearch.Enabled() {
+		if u, err := url.Parse(c.SemanticSearch.EmbeddingURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("SEMANTIC_SEARCH_EMBEDDING_URL: %q is not an http(s) URL", c.SemanticSearch.EmbeddingURL)
+		}
+		if c.SemanticSearch.EmbeddingModel == "" {
+			add("SEMANTIC_SEARCH_EMBEDDING_MODEL: required when SEMANTIC_SEARCH_EMBEDDING_URL is set")
+		} else if len(c.SemanticSearch.EmbeddingModel) > 50 {
+			add("SEMANTIC_SEARCH_EMBEDDING_MODEL: must be at most 50 characters")
+		}
+	}
+	if c.SemanticSearch.Timeout <= 0 {
+		add("SEMANTIC_SEARCH_TIMEOUT: must be positive")
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid configuration:\n  - %s", strings.Join(problems, "\n  - "))
+}
+
+func validateProviderRateLimit(name string, limit ProviderRateLimit, add func(string, ...interface{})) {
+	prefix := "RATE_LIMIT_" + name + "_"
+	if limit.SendPerMinute < 0 {
+		add("%sSEND_PER_MINUTE: must not be negative", prefix)
+	}
+	if limit.MaxConnections < 0 {
+		add("%sMAX_CONNECTIONS: must not be negative", prefix)
+	}
+	if limit.FetchInterval < 0 {
+		add("%sFETCH_INTERVAL: must not be negative", prefix)
+	}
+	if limit.SyncBytesPerMinute < 0 {
+		add("%sSYNC_BYTES_PER_MINUTE: must not be negative", prefix)
+	}
+}
+
+// Warnings 返回不影响启动但应当修正的配置问题，如使用默认凭据或过短的密钥
+func (c *Config) Warnings() []string {
+	var warnings []string
+	if c.Auth.JWTSecret == DefaultJWTSecret && !c.IsProduction() {
+		warnings = append(warnings, "JWT_SECRET is using the built-in default, set a random secret before deploying")
+	}
+	if c.IsProduction() && c.Auth.JWTSecret != DefaultJWTSecret && len(c.Auth.JWTSecret) < minProductionJWTSecretLength {
+		warnings = append(warnings, fmt.Sprintf("JWT_SECRET is shorter than %d characters", minProductionJWTSecretLength))
+	}
+	if c.Auth.AdminPassword == DefaultAdminPassword {
+		warnings = append(warnings, "ADMIN_PASSWORD is using the built-in default, change it after first login")
+	}
+	return warnings
+}
+
+// ConfigFile 加载的配置文件路径，未使用配置文件时为空
+func (c *Config) ConfigFile() string {
+	return c.configFile
+}
+
+// Settings 返回各配置项的取值和来源，敏感值已脱敏
+func (c *Config) Settings() []Setting {
+	settings := make([]Setting, len(c.settings))
+	for i, setting := range c.settings {
+		if setting.Secret {
+			setting.Value = RedactSecret(setting.Key, setting.Value)
+		}
+		settings[i] = setting
+	}
+	return settings
+}
+
+// RedactSecret 返回敏感配置的脱敏值；连接URL只隐藏其中的密码，保留地址便于排查
+func RedactSecret(key, value string) string {
+	if value == "" {
+		return ""
+	}
+	if key == "REDIS_URL" {
+		if u, err := url.Parse(value); err == nil && u.Host != "" {
+			return u.Redacted()
+		}
+	}
+	return RedactedValue
+}
+
+// validIPOrCIDR 是否为IP地址或CIDR网段
+func validIPOrCIDR(entry string) bool {
+	entry = strings.TrimSpace(entry)
+	if net.ParseIP(entry) != nil {
+		return true
+	}
+	_, _, err := net.ParseCIDR(entry)
+	return err == nil
+}
+
+// ValidHostname 检查是否为合法的主机名或域名，如 mail.example.com
+func ValidHostname(name string) bool {
+	if name == "" || len(name) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ValidEHLOName 检查SMTP问候名，可以是主机名或 [192.0.2.1] 形式的地址字面量
+func ValidEHLOName(name string) bool {
+	if strings.HasPrefix(name, "[") && strings.HasSuffix(name, "]") {
+		literal := strings.TrimPrefix(name[1:len(name)-1], "IPv6:")
+		return net.ParseIP(literal) != nil
+	}
+	return ValidHostname(name)
+}
+
+// ParseDNSResolver 解析DNS解析器设置：https:// 开头为DoH地址，否则为DNS服务器地址，未写端口时使用53。
+// 设置为空时两个返回值都为空，表示使用系统DNS
+func ParseDNSResolver(spec string) (dohURL, server string, err error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return "", "", nil
+	}
+	if strings.Contains(spec, "://") {
+		u, err := url.Parse(spec)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return "", "", fmt.Errorf("invalid DNS-over-HTTPS URL %q, expected https://host/path", spec)
+		}
+		return u.String(), "", nil
+	}
+
+	host, port, err := net.SplitHostPort(spec)
+	if err != nil {
+		host, port = strings.Trim(spec, "[]"), "53"
+	}
+	if net.ParseIP(host) == nil && !ValidHostname(host) {
+		return "", "", fmt.Errorf("invalid DNS server %q, expected an address such as 1.1.1.1 or [2606:4700:4700::1111]:53", spec)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return "", "", fmt.Errorf("invalid DNS server port in %q", spec)
+	}
+	return "", net.JoinHostPort(host, port), nil
+}
@@ -53,13 +53,16 @@ func InitializeWithDriver(dbPath string, usePureGo bool) (*gorm.DB, error) {
 		// 使用纯Go SQLite驱动（用于测试）
 		db, err = gorm.Open(sqlite.Dialector{
 			DriverName: "sqlite",
-			DSN:        dbPath,
+			DSN:        pureGoDSN(dbPath),
 		}, &gorm.Config{
 			Logger: gormLogger,
 		})
 	} else {
-		// 使用标准CGO SQLite驱动
-		db, err = gorm.Open(sqlite.Open(dbPath), &gorm.Config{
+		// 使用标准CGO SQLite驱动，每个连接打开时执行调优PRAGMA
+		db, err = gorm.Open(sqlite.Dialector{
+			DriverName: sqliteTunedDriver,
+			DSN:        tunedDSN(dbPath),
+		}, &gorm.Config{
 			Logger: gormLogger,
 		})
 	}
@@ -97,12 +100,15 @@ func InitializeWithDriver(dbPath string, usePureGo bool) (*gorm.DB, error) {
 	if usePureGo {
 		db, err = gorm.Open(sqlite.Dialector{
 			DriverName: "sqlite",
-			DSN:        dbPath,
+			DSN:        pureGoDSN(dbPath),
 		}, &gorm.Config{
 			Logger: gormLogger,
 		})
 	} else {
-		db, err = gorm.Open(sqlite.Open(dbPath), &gorm.Config{
+		db, err = gorm.Open(sqlite.Dialector{
+			DriverName: sqliteTunedDriver,
+			DSN:        tunedDSN(dbPath),
+		}, &gorm.Config{
 			Logger: gormLogger,
 		})
 	}
@@ -141,17 +147,33 @@ func InitializeWithDriver(dbPath string, usePureGo bool) (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to repair email group invariants: %w", err)
 	}
 
+	// 历史邮件的正文分批移到 email_bodies 表，移完后删除原来的正文列
+	if err := services.MoveLegacyEmailBodies(context.Background(), db); err != nil {
+		return nil, fmt.Errorf("failed to move legacy email bodies: %w", err)
+	}
+
 	log.Println("Database initialized successfully")
 	return db, nil
 }
 
 // runMigrations 执行数据库迁移
 // 使用golang-migrate进行版本化迁移，遵循最佳实践
-// 为迁移创建单独的数据库连接，避免连接被关闭的问题
 func runMigrations(dbPath string) error {
+	err := withMigrationService(dbPath, func(ctx context.Context, migrationService *migration.MigrationService) error {
+		return migrationService.RunMigrations(ctx)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	log.Println("Database migration completed successfully")
+	return nil
+}
 
-	// 为迁移创建单独的数据库连接
-	migrationDB, err := sql.Open("sqlite3", dbPath)
+// withMigrationService 为迁移创建单独的数据库连接，避免连接被关闭的问题
+func withMigrationService(dbPath string, fn func(ctx context.Context, migrationService *migration.MigrationService) error) error {
+	// 为迁移创建单独的数据库连接。迁移需要重建表，不启用外键约束，只设置 busy_timeout 等待其他连接的写锁
+	migrationDB, err := sql.Open("sqlite3", fmt.Sprintf("%s%s_busy_timeout=%d", dbPath, dsnSeparator(dbPath), currentTuning().BusyTimeout.Milliseconds()))
 	if err != nil {
 		return fmt.Errorf("failed to open migration database connection: %w", err)
 	}
@@ -174,14 +196,7 @@ func runMigrations(dbPath string) error {
 	}
 	defer migrationService.Close()
 
-	// 运行迁移
-	ctx := context.Background()
-	if err := migrationService.RunMigrations(ctx); err != nil {
-		return fmt.Errorf("failed to run migrations: %w", err)
-	}
-
-	log.Println("Database migration completed successfully")
-	return nil
+	return fn(context.Background(), migrationService)
 }
 
 // 注意：索引和约束创建逻辑已移至迁移文件中
@@ -323,48 +338,24 @@ func syncAdminPassword(db *gorm.DB) error {
 
 // optimizeConnectionPool 优化连接池配置
 func optimizeConnectionPool(sqlDB *sql.DB) error {
-	// SQLite在WAL模式下支持并发读取，但写入仍然是串行的
-	// 对于个人项目，适度增加连接数以支持并发读取
-	sqlDB.SetMaxOpenConns(5)                   // 允许最多5个并发连接
-	sqlDB.SetMaxIdleConns(2)                   // 保持2个空闲连接
+	// SQLite在WAL模式下支持并发读取，但写入仍然是串行的，写事务按 busy_timeout 排队等待
+	maxOpen := currentTuning().MaxOpenConns
+	sqlDB.SetMaxOpenConns(maxOpen)             // 最大并发连接数，由 DB_MAX_OPEN_CONNS 配置
+	sqlDB.SetMaxIdleConns(min(2, maxOpen))     // 保持2个空闲连接
 	sqlDB.SetConnMaxLifetime(time.Hour)        // 连接最大生命周期1小时
 	sqlDB.SetConnMaxIdleTime(15 * time.Minute) // 空闲连接最大时间15分钟
 
 	return nil
 }
 
-// applySQLiteOptimizations 应用SQLite性能优化
+// applySQLiteOptimizations 应用只需执行一次的SQLite优化，连接级别的PRAGMA在每个连接打开时执行
 func applySQLiteOptimizations(db *gorm.DB) error {
 	optimizations := []string{
-		// 启用外键约束
-		"PRAGMA foreign_keys = ON",
-
-		// 启用WAL模式以提高并发性能
-		"PRAGMA journal_mode = WAL",
-
-		// 设置同步模式为NORMAL，平衡性能和安全性
-		"PRAGMA synchronous = NORMAL",
-
-		// 增加缓存大小到64MB
-		"PRAGMA cache_size = -65536",
-
-		// 设置临时存储为内存
-		"PRAGMA temp_store = MEMORY",
-
-		// 启用内存映射I/O，提高读取性能
-		"PRAGMA mmap_size = 268435456", // 256MB
-
-		// 优化页面大小
+		// 优化页面大小，只对新建的数据库生效
 		"PRAGMA page_size = 4096",
 
 		// 启用查询优化器
 		"PRAGMA optimize",
-
-		// 设置WAL自动检查点
-		"PRAGMA wal_autocheckpoint = 1000",
-
-		// 禁用递归触发器，避免触发器递归问题
-		"PRAGMA recursive_triggers = OFF",
 	}
 
 	for _, pragma := range optimizations {
@@ -374,7 +365,13 @@ func applySQLiteOptimizations(db *gorm.DB) error {
 		}
 	}
 
-	log.Println("SQLite performance optimizations applied")
+	var journalMode string
+	if err := db.Raw("PRAGMA journal_mode").Scan(&journalMode).Error; err != nil {
+		log.Printf("Warning: failed to read journal mode: %v", err)
+	}
+	t := currentTuning()
+	log.Printf("SQLite performance optimizations applied (journal_mode=%s, synchronous=%s, busy_timeout=%s, foreign_keys=%t, cache_size=%dMB, max_open_conns=%d)",
+		journalMode, t.Synchronous, t.BusyTimeout, t.ForeignKeys, t.CacheSizeMB, t.MaxOpenConns)
 	return nil
 }
 
@@ -0,0 +1,102 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"firemail/internal/database/migration"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Open 打开已存在的数据库，不执行迁移，也不创建或同步管理员用户，供命令行工具使用
+func Open(dbPath string) (*gorm.DB, error) {
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, fmt.Errorf("database %s not found, run migrations first: %w", dbPath, err)
+	}
+
+	db, err := gorm.Open(sqlite.Dialector{DriverName: sqliteTunedDriver, DSN: tunedDSN(dbPath)}, &gorm.Config{
+		Logger: logger.New(log.New(os.Stderr, "\r\n", log.LstdFlags), logger.Config{
+			LogLevel:                  logger.Warn,
+			IgnoreRecordNotFoundError: true,
+		}),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get underlying sql.DB: %w", err)
+	}
+	if err := optimizeConnectionPool(sqlDB); err != nil {
+		return nil, fmt.Errorf("failed to optimize connection pool: %w", err)
+	}
+	if err := applySQLiteOptimizations(db); err != nil {
+		return nil, fmt.Errorf("failed to apply SQLite optimizations: %w", err)
+	}
+	return db, nil
+}
+
+// Migrate 执行全部未应用的迁移
+func Migrate(dbPath string) error {
+	return runMigrations(dbPath)
+}
+
+// MigrationVersion 返回当前迁移版本，dirty表示上次迁移未完成
+func MigrationVersion(dbPath string) (version int, dirty bool, err error) {
+	err = withMigrationService(dbPath, func(ctx context.Context, migrationService *migration.MigrationService) error {
+		info, err := migrationService.GetMigrationInfo(ctx)
+		if err != nil {
+			return err
+		}
+		if len(info) > 0 {
+			version = info[0].Version
+			dirty = !info[0].Applied
+		}
+		return nil
+	})
+	return version, dirty, err
+}
+
+// Rollback 回滚指定步数的迁移
+func Rollback(dbPath string, steps int) error {
+	return withMigrationService(dbPath, func(ctx context.Context, migrationService *migration.MigrationService) error {
+		return migrationService.Rollback(ctx, steps)
+	})
+}
+
+// Vacuum 重建数据库文件回收已删除数据占用的空间并更新查询统计，返回整理前后的文件大小。
+// 整理期间数据库被独占锁定，应在服务空闲时执行
+func Vacuum(db *gorm.DB, dbPath string) (before, after int64, err error) {
+	// WAL模式下先将日志写回主文件，文件大小才能反映实际占用
+	if err := db.Exec("PRAGMA wal_checkpoint(TRUNCATE)").Error; err != nil {
+		return 0, 0, fmt.Errorf("failed to checkpoint WAL: %w", err)
+	}
+	if before, err = fileSize(dbPath); err != nil {
+		return 0, 0, err
+	}
+
+	for _, statement := range []string{"VACUUM", "ANALYZE", "PRAGMA wal_checkpoint(TRUNCATE)"} {
+		if err := db.Exec(statement).Error; err != nil {
+			return 0, 0, fmt.Errorf("failed to execute %s: %w", statement, err)
+		}
+	}
+
+	if after, err = fileSize(dbPath); err != nil {
+		return 0, 0, err
+	}
+	return before, after, nil
+}
+
+func fileSize(path string) (int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat database file: %w", err)
+	}
+	return info.Size(), nil
+}
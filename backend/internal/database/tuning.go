@@ -0,0 +1,143 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"firemail/internal/config"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// sqliteTunedDriver 注册的CGO SQLite驱动，每个新连接都会执行调优PRAGMA。
+// PRAGMA 只对执行它的连接生效，只在一个连接上执行时连接池中的其他连接仍是默认设置，
+// 没有 busy_timeout 的连接遇到写锁会立即返回 "database is locked"
+const sqliteTunedDriver = "sqlite3_firemail"
+
+// sqliteTuning SQLite连接参数
+type sqliteTuning struct {
+	JournalMode  string
+	BusyTimeout  time.Duration
+	Synchronous  string
+	ForeignKeys  bool
+	CacheSizeMB  int
+	MaxOpenConns int
+}
+
+var (
+	tuningMu sync.RWMutex
+	tuning   = sqliteTuning{
+		JournalMode:  "WAL",
+		BusyTimeout:  10 * time.Second,
+		Synchronous:  "NORMAL",
+		ForeignKeys:  true,
+		CacheSizeMB:  64,
+		MaxOpenConns: 5,
+	}
+)
+
+func init() {
+	sql.Register(sqliteTunedDriver, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			for _, pragma := range currentTuning().pragmas() {
+				if _, err := conn.Exec("PRAGMA "+pragma, nil); err != nil {
+					return fmt.Errorf("failed to execute PRAGMA %s: %w", pragma, err)
+				}
+			}
+			return nil
+		},
+	})
+}
+
+// Configure 设置SQLite连接参数，在打开数据库之前调用；无效的取值保留默认设置
+func Configure(cfg config.DatabaseConfig) {
+	tuningMu.Lock()
+	defer tuningMu.Unlock()
+
+	if mode := pragmaWord(cfg.JournalMode); mode != "" {
+		tuning.JournalMode = mode
+	}
+	if cfg.BusyTimeout >= 0 {
+		tuning.BusyTimeout = cfg.BusyTimeout
+	}
+	if mode := pragmaWord(cfg.Synchronous); mode != "" {
+		tuning.Synchronous = mode
+	}
+	tuning.ForeignKeys = cfg.ForeignKeys
+	if cfg.CacheSizeMB >= 0 {
+		tuning.CacheSizeMB = cfg.CacheSizeMB
+	}
+	if cfg.MaxOpenConns > 0 {
+		tuning.MaxOpenConns = cfg.MaxOpenConns
+	}
+}
+
+// currentTuning 当前的SQLite连接参数
+func currentTuning() sqliteTuning {
+	tuningMu.RLock()
+	defer tuningMu.RUnlock()
+	return tuning
+}
+
+// pragmas 每个连接打开时执行的PRAGMA，busy_timeout 最先设置，切换日志模式等待写锁时也会重试
+func (t sqliteTuning) pragmas() []string {
+	foreignKeys := "OFF"
+	if t.ForeignKeys {
+		foreignKeys = "ON"
+	}
+	return []string{
+		fmt.Sprintf("busy_timeout = %d", t.BusyTimeout.Milliseconds()),
+		"journal_mode = " + t.JournalMode,
+		"synchronous = " + t.Synchronous,
+		"foreign_keys = " + foreignKeys,
+		// 负数表示以KB为单位
+		fmt.Sprintf("cache_size = %d", -t.CacheSizeMB*1024),
+		"temp_store = MEMORY",
+		// 启用内存映射I/O，提高读取性能
+		"mmap_size = 268435456",
+		"wal_autocheckpoint = 1000",
+		// 禁用递归触发器，避免触发器递归问题
+		"recursive_triggers = OFF",
+	}
+}
+
+// tunedDSN CGO驱动的连接字符串。事务以 BEGIN IMMEDIATE 开始，在事务开始时获取写锁并按
+// busy_timeout 等待；延迟获取写锁的事务在读取后升级为写事务时不会等待，直接返回 "database is locked"
+func tunedDSN(dbPath string) string {
+	return dbPath + dsnSeparator(dbPath) + "_txlock=immediate"
+}
+
+// pureGoDSN 纯Go驱动的连接字符串，该驱动不支持连接回调，通过 _pragma 参数在每个连接上执行PRAGMA
+func pureGoDSN(dbPath string) string {
+	params := make([]string, 0, 10)
+	for _, pragma := range currentTuning().pragmas() {
+		name, value, _ := strings.Cut(pragma, " = ")
+		params = append(params, fmt.Sprintf("_pragma=%s(%s)", name, value))
+	}
+	params = append(params, "_txlock=immediate")
+	return dbPath + dsnSeparator(dbPath) + strings.Join(params, "&")
+}
+
+func dsnSeparator(dbPath string) string {
+	if strings.Contains(dbPath, "?") {
+		return "&"
+	}
+	return "?"
+}
+
+// pragmaWord 只保留由字母组成的取值，PRAGMA 不支持参数绑定，避免拼接任意内容
+func pragmaWord(value string) string {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if value == "" {
+		return ""
+	}
+	for _, r := range value {
+		if r < 'A' || r > 'Z' {
+			return ""
+		}
+	}
+	return value
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"firemail/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTuningAppliedToEveryConnection(t *testing.T) {
+	previous := currentTuning()
+	t.Cleanup(func() {
+		tuningMu.Lock()
+		tuning = previous
+		tuningMu.Unlock()
+	})
+	Configure(config.DatabaseConfig{
+		JournalMode:  "wal",
+		BusyTimeout:  3 * time.Second,
+		Synchronous:  "normal; PRAGMA foreign_keys = OFF",
+		ForeignKeys:  true,
+		CacheSizeMB:  16,
+		MaxOpenConns: 3,
+	})
+	require.Equal(t, "NORMAL", currentTuning().Synchronous) // 无效取值保留原设置
+
+	db, err := sql.Open(sqliteTunedDriver, tunedDSN(filepath.Join(t.TempDir(), "tuning.db")))
+	require.NoError(t, err)
+	defer db.Close()
+
+	// 同时占用多个连接，确认每个连接都执行了PRAGMA
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		conn, err := db.Conn(ctx)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		var busyTimeout, foreignKeys, cacheSize int
+		var journalMode string
+		require.NoError(t, conn.QueryRowContext(ctx, "PRAGMA busy_timeout").Scan(&busyTimeout))
+		require.NoError(t, conn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&foreignKeys))
+		require.NoError(t, conn.QueryRowContext(ctx, "PRAGMA cache_size").Scan(&cacheSize))
+		require.NoError(t, conn.QueryRowContext(ctx, "PRAGMA journal_mode").Scan(&journalMode))
+		require.Equal(t, 3000, busyTimeout)
+		require.Equal(t, 1, foreignKeys)
+		require.Equal(t, -16*1024, cacheSize)
+		require.Equal(t, "wal", journalMode)
+	}
+}
+
+func TestPureGoDSNIncludesPragmas(t *testing.T) {
+	dsn := pureGoDSN("firemail.db")
+	require.Contains(t, dsn, "firemail.db?_pragma=busy_timeout(")
+	require.Contains(t, dsn, "&_pragma=journal_mode(")
+	require.Contains(t, dsn, "&_txlock=immediate")
+	require.Equal(t, "file:test.db?mode=rwc&_txlock=immediate", tunedDSN("file:test.db?mode=rwc"))
+}
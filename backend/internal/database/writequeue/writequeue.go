@@ -0,0 +1,26 @@
+// Package writequeue 让同步入库、清空回收站等大批量写入依次执行。
+// SQLite 同一时间只允许一个写事务，多个大事务同时等待写锁时容易超过 busy_timeout，
+// 在进程内排队后只有界面上的小写入需要与当前的大事务竞争写锁
+package writequeue
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// slot 大批量写入的执行权，同一时间只有一个持有者
+var slot = make(chan struct{}, 1)
+
+// Transaction 排队取得执行权后在事务中执行 fn。等待期间 ctx 取消时返回 ctx.Err()。
+// fn 中不能再调用 Transaction，否则会一直等待自己释放执行权
+func Transaction(ctx context.Context, db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	select {
+	case slot <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-slot }()
+
+	return db.Transaction(fn)
+}
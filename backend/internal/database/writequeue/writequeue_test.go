@@ -0,0 +1,64 @@
+package writequeue
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestTransactionRunsOneAtATime(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+
+	var running, maxRunning int32
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, Transaction(context.Background(), db, func(tx *gorm.DB) error {
+				current := atomic.AddInt32(&running, 1)
+				for {
+					seen := atomic.LoadInt32(&maxRunning)
+					if current <= seen || atomic.CompareAndSwapInt32(&maxRunning, seen, current) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				return nil
+			}))
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(1), maxRunning)
+}
+
+func TestTransactionStopsWaitingWhenCanceled(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go Transaction(context.Background(), db, func(tx *gorm.DB) error {
+		close(started)
+		<-release
+		return nil
+	})
+	<-started
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = Transaction(ctx, db, func(tx *gorm.DB) error {
+		t.Fatal("should not run while another write holds the queue")
+		return nil
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,269 @@
+package encoding
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/transform"
+)
+
+// MinCharsetConfidence 检测结果可被采用的最低置信度，低于该值时按UTF-8处理
+const MinCharsetConfidence = 0.5
+
+// 解码结果中替换字符的容忍比例，超过则认为字符集不匹配
+const maxInvalidRuneRatio = 0.02
+
+// CharsetMatch 字符集检测结果
+type CharsetMatch struct {
+	Charset    string  `json:"charset"`
+	Confidence float64 `json:"confidence"`
+}
+
+// 常用简体汉字，用于区分GB18030与Big5
+const commonSimplifiedHan = "的一是不了在人有我他这个们中来上大为和国地到以说时要就出会可也你对生能而子那得于着下自之年过发后作里用道行所然家种事成方多经么去法学如都同现当没动面起看定天分还进好小部其些主样理心她本前开但因只从想实日者意无力它与长把机十民第公此已工使情明性知全三又关点正业外将两高间由问很最重并物手应向头文体政美相见被利什二等产或新己制身果加请您邮件附收发送"
+
+// 常用繁体汉字
+const commonTraditionalHan = "的一是不了在人有我他這個們中來上大為和國地到以說時要就出會可也你對生能而子那得於著下自之年過發後作裡用道行所然家種事成方多經麼去法學如都同現當沒動面起看定天分還進好小部其些主樣理心她本前開但因只從想實日者意無力它與長把機十民第公此已工使情明性知全三又關點正業外將兩高間由問很最重並物手應向頭文體政美相見被利什二等產或新己制身果加請您郵件附收發送"
+
+// charsetCandidate 参与检测的多字节字符集
+type charsetCandidate struct {
+	name     string
+	encoding encoding.Encoding
+	score    func(stats *runeStats) float64
+}
+
+var charsetCandidates = []charsetCandidate{
+	{name: "gb18030", encoding: simplifiedchinese.GB18030, score: scoreChinese(true)},
+	{name: "big5", encoding: traditionalchinese.Big5, score: scoreChinese(false)},
+	{name: "euc-jp", encoding: japanese.EUCJP, score: scoreJapanese},
+	{name: "shift_jis", encoding: japanese.ShiftJIS, score: scoreJapanese},
+	{name: "euc-kr", encoding: korean.EUCKR, score: scoreKorean},
+}
+
+// runeStats 解码结果中非ASCII字符的分布
+type runeStats struct {
+	nonASCII          int
+	invalid           int
+	han               int
+	kana              int
+	hangul            int
+	punct             int
+	commonSimplified  int
+	commonTraditional int
+}
+
+func collectRuneStats(text []byte) *runeStats {
+	stats := &runeStats{}
+	for len(text) > 0 {
+		r, size := utf8.DecodeRune(text)
+		text = text[size:]
+		if r < utf8.RuneSelf {
+			continue
+		}
+
+		stats.nonASCII++
+		switch {
+		case r == utf8.RuneError:
+			stats.invalid++
+		case unicode.Is(unicode.Han, r):
+			stats.han++
+			if strings.ContainsRune(commonSimplifiedHan, r) {
+				stats.commonSimplified++
+			}
+			if strings.ContainsRune(commonTraditionalHan, r) {
+				stats.commonTraditional++
+			}
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			stats.kana++
+		case unicode.Is(unicode.Hangul, r):
+			stats.hangul++
+		case (r >= 0x3000 && r <= 0x303F) || (r >= 0xFF00 && r <= 0xFFEF) || (r >= 0x2000 && r <= 0x206F) || r == 0x00B7:
+			// CJK符号、全角字符与常用标点
+			stats.punct++
+		}
+	}
+	return stats
+}
+
+func (s *runeStats) ratio(n int) float64 {
+	if s.nonASCII == 0 {
+		return 0
+	}
+	return float64(n) / float64(s.nonASCII)
+}
+
+func scoreChinese(simplified bool) func(stats *runeStats) float64 {
+	return func(stats *runeStats) float64 {
+		plausible := stats.ratio(stats.han + stats.punct)
+		common := stats.commonTraditional
+		if simplified {
+			common = stats.commonSimplified
+		}
+		commonRatio := 0.0
+		if stats.han > 0 {
+			commonRatio = float64(common) / float64(stats.han)
+		}
+		// 中文正文中几乎不会出现假名，假名较多时更可能是日文
+		return plausible * (0.6 + 0.4*commonRatio) * (1 - stats.ratio(stats.kana))
+	}
+}
+
+func scoreJapanese(stats *runeStats) float64 {
+	plausible := stats.ratio(stats.han + stats.kana + stats.punct)
+	kana := stats.ratio(stats.kana) * 3
+	if kana > 1 {
+		kana = 1
+	}
+	return plausible * (0.5 + 0.5*kana)
+}
+
+func scoreKorean(stats *runeStats) float64 {
+	plausible := stats.ratio(stats.hangul + stats.han + stats.punct)
+	return plausible * (0.5 + 0.5*stats.ratio(stats.hangul))
+}
+
+// DetectCharset 对未声明或声明错误的内容进行字符集检测，按置信度从高到低返回候选结果
+func DetectCharset(data []byte) []CharsetMatch {
+	if len(data) == 0 {
+		return nil
+	}
+
+	// ISO-2022-JP是7位编码，需要在UTF-8之前根据转义序列识别
+	if isISO2022JP(data) {
+		if decoded, err := decodeBytes(japanese.ISO2022JP, data); err == nil && !bytes.ContainsRune(decoded, utf8.RuneError) {
+			return []CharsetMatch{{Charset: "iso-2022-jp", Confidence: 0.99}}
+		}
+	}
+
+	if utf8.Valid(data) {
+		return []CharsetMatch{{Charset: "utf-8", Confidence: 0.99}}
+	}
+
+	var matches []CharsetMatch
+	for _, candidate := range charsetCandidates {
+		decoded, err := decodeBytes(candidate.encoding, data)
+		if err != nil {
+			continue
+		}
+
+		stats := collectRuneStats(decoded)
+		if stats.nonASCII == 0 {
+			continue
+		}
+		invalidRatio := stats.ratio(stats.invalid)
+		if invalidRatio > maxInvalidRuneRatio {
+			continue
+		}
+
+		confidence := candidate.score(stats) * (1 - invalidRatio*10)
+		if confidence > 0 {
+			matches = append(matches, CharsetMatch{Charset: candidate.name, Confidence: confidence})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Confidence > matches[j].Confidence
+	})
+	return matches
+}
+
+// DecodeWithDetection 将内容转换为UTF-8：
+// 声明的字符集能干净解码时直接采用，否则进行字符集检测，检测失败时按UTF-8处理并替换非法字节。
+// 返回实际使用的字符集
+func DecodeWithDetection(data []byte, declaredCharset string) ([]byte, string) {
+	if len(data) == 0 {
+		return data, "utf-8"
+	}
+
+	declared := normalizeCharsetName(declaredCharset)
+	switch declared {
+	case "", "utf-8", "us-ascii", "ascii":
+		if utf8.Valid(data) && !isISO2022JP(data) {
+			return data, "utf-8"
+		}
+	default:
+		if decoded, err := DecodeCharset(data, declared); err == nil && !bytes.ContainsRune(decoded, utf8.RuneError) {
+			return decoded, declared
+		}
+	}
+
+	if matches := DetectCharset(data); len(matches) > 0 && matches[0].Confidence >= MinCharsetConfidence {
+		if decoded, err := DecodeCharset(data, matches[0].Charset); err == nil {
+			return decoded, matches[0].Charset
+		}
+	}
+
+	return bytes.ToValidUTF8(data, []byte(string(utf8.RuneError))), "utf-8"
+}
+
+// DecodeCharset 使用指定的字符集将内容转换为UTF-8，无法解码的字节替换为U+FFFD
+func DecodeCharset(data []byte, charset string) ([]byte, error) {
+	name := normalizeCharsetName(charset)
+	if name == "utf-8" || name == "us-ascii" || name == "ascii" {
+		return bytes.ToValidUTF8(data, []byte(string(utf8.RuneError))), nil
+	}
+
+	enc, ok := lookupCharset(name)
+	if !ok {
+		return nil, fmt.Errorf("unsupported charset: %s", charset)
+	}
+	return decodeBytes(enc, data)
+}
+
+// IsSupportedCharset 检查字符集是否可用于解码
+func IsSupportedCharset(charset string) bool {
+	name := normalizeCharsetName(charset)
+	if name == "utf-8" || name == "us-ascii" || name == "ascii" {
+		return true
+	}
+	_, ok := lookupCharset(name)
+	return ok
+}
+
+// 复用标准检测器的编码映射和别名
+var charsetConverter = NewStandardEncodingConverter(NewStandardEncodingDetector()).(*StandardEncodingConverter)
+
+func lookupCharset(name string) (encoding.Encoding, bool) {
+	enc, err := charsetConverter.getEncoding(name)
+	return enc, err == nil
+}
+
+func normalizeCharsetName(charset string) string {
+	name := strings.ToLower(strings.Trim(strings.TrimSpace(charset), `"'`))
+	switch name {
+	case "utf8":
+		return "utf-8"
+	case "shift-jis", "sjis", "x-sjis":
+		return "shift_jis"
+	case "x-gbk", "cp936", "ms936", "windows-936":
+		return "gbk"
+	case "big5-hkscs", "x-big5", "cp950":
+		return "big5"
+	case "x-euc-jp":
+		return "euc-jp"
+	case "csiso2022jp":
+		return "iso-2022-jp"
+	}
+	return name
+}
+
+func isISO2022JP(data []byte) bool {
+	return bytes.Contains(data, []byte("\x1b$B")) || bytes.Contains(data, []byte("\x1b$@")) || bytes.Contains(data, []byte("\x1b(J"))
+}
+
+func decodeBytes(enc encoding.Encoding, data []byte) ([]byte, error) {
+	decoded, _, err := transform.Bytes(enc.NewDecoder(), data)
+	if err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
@@ -0,0 +1,98 @@
+package encoding
+
+import (
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+func mustEncode(t *testing.T, enc encoding.Encoding, text string) []byte {
+	t.Helper()
+	data, err := enc.NewEncoder().Bytes([]byte(text))
+	if err != nil {
+		t.Fatalf("failed to encode test data: %v", err)
+	}
+	return data
+}
+
+func TestDetectCharset(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding encoding.Encoding
+		text     string
+		expected string
+	}{
+		{"GB18030", simplifiedchinese.GB18030, "您好，这是一封来自QQ邮箱的测试邮件，请查收附件中的文件。", "gb18030"},
+		{"GBK", simplifiedchinese.GBK, "我们已经收到你的申请，会在三个工作日内处理。", "gb18030"},
+		{"Big5", traditionalchinese.Big5, "您好，這是一封測試郵件，請查收附件中的文件。我們會盡快處理。", "big5"},
+		{"EUC-JP", japanese.EUCJP, "こんにちは、これはテストメールです。添付ファイルをご確認ください。", "euc-jp"},
+		{"ISO-2022-JP", japanese.ISO2022JP, "お世話になっております。会議の資料をお送りします。", "iso-2022-jp"},
+		{"EUC-KR", korean.EUCKR, "안녕하세요, 테스트 메일입니다. 첨부 파일을 확인해 주세요.", "euc-kr"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := mustEncode(t, tt.encoding, tt.text)
+			matches := DetectCharset(data)
+			if len(matches) == 0 {
+				t.Fatal("expected at least one match")
+			}
+			if matches[0].Charset != tt.expected {
+				t.Fatalf("expected %s, got %+v", tt.expected, matches)
+			}
+			if matches[0].Confidence < MinCharsetConfidence {
+				t.Errorf("expected confidence >= %.2f, got %.2f", MinCharsetConfidence, matches[0].Confidence)
+			}
+
+			decoded, charset := DecodeWithDetection(data, "")
+			if string(decoded) != tt.text || charset != tt.expected {
+				t.Errorf("expected %q (%s), got %q (%s)", tt.text, tt.expected, decoded, charset)
+			}
+		})
+	}
+}
+
+func TestDecodeWithDetectionWrongDeclaredCharset(t *testing.T) {
+	text := "会议纪要已上传，请各位同事查阅。"
+	data := mustEncode(t, simplifiedchinese.GBK, text)
+
+	// 声明为UTF-8但实际是GBK
+	decoded, charset := DecodeWithDetection(data, "utf-8")
+	if string(decoded) != text || charset != "gb18030" {
+		t.Errorf("expected GBK content to be detected, got %q (%s)", decoded, charset)
+	}
+
+	// 声明为gb2312但包含GBK扩展字符
+	extended := "镕基先生的邮件"
+	decoded, _ = DecodeWithDetection(mustEncode(t, simplifiedchinese.GBK, extended), "gb2312")
+	if string(decoded) != extended {
+		t.Errorf("expected %q, got %q", extended, decoded)
+	}
+}
+
+func TestDecodeWithDetectionFallsBackToUTF8(t *testing.T) {
+	data := []byte{'a', 'b', 0xff, 'c'}
+	decoded, charset := DecodeWithDetection(data, "")
+	if charset != "utf-8" || string(decoded) != "ab�c" {
+		t.Errorf("expected UTF-8 fallback, got %q (%s)", decoded, charset)
+	}
+}
+
+func TestDecodeCharset(t *testing.T) {
+	data := mustEncode(t, traditionalchinese.Big5, "測試")
+	decoded, err := DecodeCharset(data, "BIG5")
+	if err != nil || string(decoded) != "測試" {
+		t.Errorf("expected Big5 content to decode, got %q (%v)", decoded, err)
+	}
+
+	if _, err := DecodeCharset(data, "x-unknown"); err == nil {
+		t.Error("expected unsupported charset error")
+	}
+	if !IsSupportedCharset("GB2312") || IsSupportedCharset("x-unknown") {
+		t.Error("unexpected IsSupportedCharset result")
+	}
+}
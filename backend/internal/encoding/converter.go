@@ -275,30 +275,8 @@ func (s *StandardEncodingService) AutoConvertToUTF8(data []byte) ([]byte, string
 		return data, "utf-8", nil
 	}
 	
-	// 检测编码
-	detectedEncoding, confidence, err := s.detector.DetectEncoding(data)
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to detect encoding: %w", err)
-	}
-	
-	// 如果置信度太低，尝试常见的中文编码
-	if confidence < 0.6 {
-		commonEncodings := []string{"gbk", "gb2312", "big5", "gb18030"}
-		for _, encoding := range commonEncodings {
-			if converted, err := s.converter.ConvertToUTF8(data, encoding); err == nil {
-				if s.converter.IsValidUTF8(converted) {
-					return converted, encoding, nil
-				}
-			}
-		}
-	}
-	
-	// 使用检测到的编码进行转换
-	converted, err := s.converter.ConvertToUTF8(data, detectedEncoding)
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to convert from %s to UTF-8: %w", detectedEncoding, err)
-	}
-	
+	// 按置信度检测字符集，检测失败时按UTF-8处理
+	converted, detectedEncoding := DecodeWithDetection(data, "")
 	return converted, detectedEncoding, nil
 }
 
@@ -363,14 +341,9 @@ func (h *EmailEncodingHelper) DecodeEmailContent(content []byte, charset string)
 		return content, nil
 	}
 	
-	// 如果指定了字符集，使用指定的字符集转换
-	if charset != "" && strings.ToLower(charset) != "utf-8" {
-		return h.service.ConvertToUTF8(content, charset)
-	}
-	
-	// 自动检测并转换
-	converted, _, err := h.service.AutoConvertToUTF8(content)
-	return converted, err
+	// 声明的字符集缺失或与内容不符时自动检测
+	converted, _ := DecodeWithDetection(content, charset)
+	return converted, nil
 }
 
 // DecodeEmailFrom 解码发件人信息
@@ -405,22 +378,8 @@ func (h *EmailEncodingHelper) DecodeEmailContentWithTransferEncoding(content []b
 		return nil, fmt.Errorf("transfer encoding decode failed: %w", err)
 	}
 
-	// 第二步：处理字符编码转换
-	if charset != "" && strings.ToLower(charset) != "utf-8" {
-		// 使用指定的字符集转换
-		converted, err := h.service.ConvertToUTF8(decoded, charset)
-		if err != nil {
-			return nil, fmt.Errorf("charset conversion failed: %w", err)
-		}
-		return converted, nil
-	}
-
-	// 自动检测并转换字符编码
-	converted, _, err := h.service.AutoConvertToUTF8(decoded)
-	if err != nil {
-		return nil, fmt.Errorf("auto charset conversion failed: %w", err)
-	}
-
+	// 第二步：处理字符编码转换，声明的字符集缺失或与内容不符时自动检测
+	converted, _ := DecodeWithDetection(decoded, charset)
 	return converted, nil
 }
 
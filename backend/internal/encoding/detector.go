@@ -73,7 +73,9 @@ func (d *StandardEncodingDetector) initializeEncodings() {
 	d.encodings["utf-16be"] = unicode.UTF16(unicode.BigEndian, unicode.UseBOM)
 	
 	// 中文编码
-	d.encodings["gb2312"] = simplifiedchinese.HZGB2312
+	// 邮件中声明为gb2312的内容实际多为GBK编码，按GBK解码以兼容扩展字符
+	d.encodings["gb2312"] = simplifiedchinese.GBK
+	d.encodings["hz-gb-2312"] = simplifiedchinese.HZGB2312
 	d.encodings["gbk"] = simplifiedchinese.GBK
 	d.encodings["gb18030"] = simplifiedchinese.GB18030
 	d.encodings["big5"] = traditionalchinese.Big5
@@ -109,26 +111,6 @@ func (d *StandardEncodingDetector) initializeDetectionRules() {
 			Encoding: "utf-8",
 			Detector: d.detectUTF8,
 		},
-		{
-			Name:     "GBK/GB2312",
-			Encoding: "gbk",
-			Detector: d.detectGBK,
-		},
-		{
-			Name:     "Big5",
-			Encoding: "big5",
-			Detector: d.detectBig5,
-		},
-		{
-			Name:     "Shift_JIS",
-			Encoding: "shift_jis",
-			Detector: d.detectShiftJIS,
-		},
-		{
-			Name:     "EUC-KR",
-			Encoding: "euc-kr",
-			Detector: d.detectEUCKR,
-		},
 		{
 			Name:     "ISO-8859-1",
 			Encoding: "iso-8859-1",
@@ -145,6 +127,12 @@ func (d *StandardEncodingDetector) DetectEncoding(data []byte) (string, float64,
 	
 	bestEncoding := "utf-8"
 	bestConfidence := 0.0
+
+	// GB18030、Big5、EUC-JP等多字节编码通过解码打分确定
+	if matches := DetectCharset(data); len(matches) > 0 && matches[0].Confidence >= MinCharsetConfidence {
+		bestEncoding = matches[0].Charset
+		bestConfidence = matches[0].Confidence
+	}
 	
 	// 按优先级检测编码
 	for _, rule := range d.detectionRules {
@@ -220,113 +208,6 @@ func (d *StandardEncodingDetector) detectUTF8(data []byte) (bool, float64) {
 	return false, 0.0
 }
 
-// detectGBK 检测GBK编码
-func (d *StandardEncodingDetector) detectGBK(data []byte) (bool, float64) {
-	gbkCount := 0
-	totalBytes := len(data)
-	
-	for i := 0; i < totalBytes-1; i++ {
-		b1, b2 := data[i], data[i+1]
-		
-		// GBK第一字节范围：0x81-0xFE
-		// GBK第二字节范围：0x40-0x7E, 0x80-0xFE
-		if (b1 >= 0x81 && b1 <= 0xFE) &&
-			((b2 >= 0x40 && b2 <= 0x7E) || (b2 >= 0x80 && b2 <= 0xFE)) {
-			gbkCount++
-			i++ // 跳过下一个字节
-		}
-	}
-	
-	if gbkCount > 0 {
-		confidence := float64(gbkCount*2) / float64(totalBytes)
-		if confidence > 0.3 {
-			return true, confidence
-		}
-	}
-	
-	return false, 0.0
-}
-
-// detectBig5 检测Big5编码
-func (d *StandardEncodingDetector) detectBig5(data []byte) (bool, float64) {
-	big5Count := 0
-	totalBytes := len(data)
-	
-	for i := 0; i < totalBytes-1; i++ {
-		b1, b2 := data[i], data[i+1]
-		
-		// Big5第一字节范围：0xA1-0xFE
-		// Big5第二字节范围：0x40-0x7E, 0xA1-0xFE
-		if (b1 >= 0xA1 && b1 <= 0xFE) &&
-			((b2 >= 0x40 && b2 <= 0x7E) || (b2 >= 0xA1 && b2 <= 0xFE)) {
-			big5Count++
-			i++ // 跳过下一个字节
-		}
-	}
-	
-	if big5Count > 0 {
-		confidence := float64(big5Count*2) / float64(totalBytes)
-		if confidence > 0.3 {
-			return true, confidence
-		}
-	}
-	
-	return false, 0.0
-}
-
-// detectShiftJIS 检测Shift_JIS编码
-func (d *StandardEncodingDetector) detectShiftJIS(data []byte) (bool, float64) {
-	sjisCount := 0
-	totalBytes := len(data)
-	
-	for i := 0; i < totalBytes-1; i++ {
-		b1, b2 := data[i], data[i+1]
-		
-		// Shift_JIS第一字节范围：0x81-0x9F, 0xE0-0xFC
-		// Shift_JIS第二字节范围：0x40-0x7E, 0x80-0xFC
-		if ((b1 >= 0x81 && b1 <= 0x9F) || (b1 >= 0xE0 && b1 <= 0xFC)) &&
-			((b2 >= 0x40 && b2 <= 0x7E) || (b2 >= 0x80 && b2 <= 0xFC)) {
-			sjisCount++
-			i++ // 跳过下一个字节
-		}
-	}
-	
-	if sjisCount > 0 {
-		confidence := float64(sjisCount*2) / float64(totalBytes)
-		if confidence > 0.3 {
-			return true, confidence
-		}
-	}
-	
-	return false, 0.0
-}
-
-// detectEUCKR 检测EUC-KR编码
-func (d *StandardEncodingDetector) detectEUCKR(data []byte) (bool, float64) {
-	euckrCount := 0
-	totalBytes := len(data)
-	
-	for i := 0; i < totalBytes-1; i++ {
-		b1, b2 := data[i], data[i+1]
-		
-		// EUC-KR第一字节范围：0xA1-0xFE
-		// EUC-KR第二字节范围：0xA1-0xFE
-		if (b1 >= 0xA1 && b1 <= 0xFE) && (b2 >= 0xA1 && b2 <= 0xFE) {
-			euckrCount++
-			i++ // 跳过下一个字节
-		}
-	}
-	
-	if euckrCount > 0 {
-		confidence := float64(euckrCount*2) / float64(totalBytes)
-		if confidence > 0.3 {
-			return true, confidence
-		}
-	}
-	
-	return false, 0.0
-}
-
 // detectISO88591 检测ISO-8859-1编码
 func (d *StandardEncodingDetector) detectISO88591(data []byte) (bool, float64) {
 	// ISO-8859-1可以表示任何字节值，所以置信度较低
@@ -0,0 +1,21 @@
+package transfer
+
+import (
+	"encoding/base64"
+	"io"
+	"mime/quotedprintable"
+)
+
+// NewDecodingReader 返回边读边解码的Reader，用于大附件的流式处理
+// 未知编码按原样返回数据
+func NewDecodingReader(r io.Reader, encoding string) io.Reader {
+	switch normalizeEncoding(encoding) {
+	case "base64":
+		// base64.NewDecoder 会忽略换行符
+		return base64.NewDecoder(base64.StdEncoding, r)
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	default:
+		return r
+	}
+}
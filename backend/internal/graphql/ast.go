@@ -0,0 +1,117 @@
+package graphql
+
+// Document 解析后的查询文档
+type Document struct {
+	Operations []*OperationDefinition
+	Fragments  map[string]*FragmentDefinition
+}
+
+// OperationDefinition 操作定义（query/mutation/subscription）
+type OperationDefinition struct {
+	Type         string
+	Name         string
+	Variables    []*VariableDefinition
+	SelectionSet []Selection
+	Location     Location
+}
+
+// VariableDefinition 变量声明
+type VariableDefinition struct {
+	Name     string
+	Type     *TypeRef
+	Default  Value
+	Location Location
+}
+
+// TypeRef 查询中引用的类型，Elem非空表示列表
+type TypeRef struct {
+	Name    string
+	Elem    *TypeRef
+	NonNull bool
+}
+
+func (t *TypeRef) String() string {
+	var s string
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	} else {
+		s = t.Name
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+// Selection 选择集中的字段、片段展开或内联片段
+type Selection interface {
+	directives() []*Directive
+}
+
+// Field 字段选择
+type Field struct {
+	Alias        string
+	Name         string
+	Arguments    []*Argument
+	Directives   []*Directive
+	SelectionSet []Selection
+	Location     Location
+}
+
+// ResponseKey 响应中使用的键名，有别名时为别名
+func (f *Field) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+// FragmentSpread 命名片段展开 ...Name
+type FragmentSpread struct {
+	Name       string
+	Directives []*Directive
+	Location   Location
+}
+
+// InlineFragment 内联片段 ... on Type { }
+type InlineFragment struct {
+	TypeCondition string
+	Directives    []*Directive
+	SelectionSet  []Selection
+	Location      Location
+}
+
+// FragmentDefinition 命名片段定义
+type FragmentDefinition struct {
+	Name          string
+	TypeCondition string
+	SelectionSet  []Selection
+	Location      Location
+}
+
+// Argument 字段或指令参数
+type Argument struct {
+	Name  string
+	Value Value
+}
+
+// Directive 指令，如 @include(if: $flag)
+type Directive struct {
+	Name      string
+	Arguments []*Argument
+}
+
+func (f *Field) directives() []*Directive          { return f.Directives }
+func (f *FragmentSpread) directives() []*Directive { return f.Directives }
+func (f *InlineFragment) directives() []*Directive { return f.Directives }
+
+// Value 字面量：nil、bool、int64、float64、string、EnumValue、Variable、[]Value、map[string]Value
+type Value interface{}
+
+// Variable 变量引用 $name
+type Variable struct {
+	Name string
+}
+
+// EnumValue 枚举字面量
+type EnumValue string
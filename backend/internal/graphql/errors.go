@@ -0,0 +1,30 @@
+package graphql
+
+import (
+	"fmt"
+)
+
+// Location 错误在查询文档中的位置
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Error GraphQL响应中的错误，Path指向出错的字段
+type Error struct {
+	Message   string        `json:"message"`
+	Locations []Location    `json:"locations,omitempty"`
+	Path      []interface{} `json:"path,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func locatedError(loc Location, format string, args ...interface{}) *Error {
+	err := &Error{Message: fmt.Sprintf(format, args...)}
+	if loc.Line > 0 {
+		err.Locations = []Location{loc}
+	}
+	return err
+}
@@ -0,0 +1,422 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// Request GraphQL请求体
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Response GraphQL响应体，请求错误时没有data字段
+type Response struct {
+	Data   interface{} `json:"data,omitempty"`
+	Errors []*Error    `json:"errors,omitempty"`
+}
+
+// ExecuteOptions 执行限制
+type ExecuteOptions struct {
+	MaxDepth int // 字段嵌套的最大层数，0表示不限制
+}
+
+// Execute 解析、校验并执行查询；字段解析错误记录在errors中，其余字段照常返回
+func (s *Schema) Execute(ctx context.Context, req Request, opts ExecuteOptions) *Response {
+	doc, err := Parse(req.Query)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	op, err := selectOperation(doc, req.OperationName)
+	if err != nil {
+		return errorResponse(err)
+	}
+	if op.Type != "query" {
+		return errorResponse(locatedError(op.Location, "Only query operations are supported, got %s.", op.Type))
+	}
+
+	v := &validator{
+		schema:    s,
+		doc:       doc,
+		maxDepth:  opts.MaxDepth,
+		args:      make(map[*Field]map[string]interface{}),
+		spreading: make(map[string]bool),
+	}
+	v.coerceVariables(op, req.Variables)
+	if len(v.errors) == 0 {
+		v.selectionSet(s.query, op.SelectionSet, 1)
+	}
+	if len(v.errors) > 0 {
+		return &Response{Errors: v.errors}
+	}
+
+	e := &executor{doc: doc, variables: v.variables, declared: v.declared, args: v.args}
+	data, ok := e.selectionSet(ctx, s.query, nil, op.SelectionSet, nil)
+	resp := &Response{Errors: e.errors}
+	if ok {
+		resp.Data = data
+	} else {
+		resp.Data = json.RawMessage("null")
+	}
+	return resp
+}
+
+func errorResponse(err error) *Response {
+	if gqlErr, ok := err.(*Error); ok {
+		return &Response{Errors: []*Error{gqlErr}}
+	}
+	return &Response{Errors: []*Error{{Message: err.Error()}}}
+}
+
+func selectOperation(doc *Document, name string) (*OperationDefinition, error) {
+	if name == "" {
+		if len(doc.Operations) > 1 {
+			return nil, &Error{Message: "Must provide operation name if query contains multiple operations."}
+		}
+		return doc.Operations[0], nil
+	}
+	for _, op := range doc.Operations {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+	return nil, &Error{Message: fmt.Sprintf("Unknown operation named \"%s\".", name)}
+}
+
+type executor struct {
+	doc       *Document
+	variables map[string]interface{}
+	declared  map[string]bool
+	args      map[*Field]map[string]interface{}
+	errors    []*Error
+}
+
+func (e *executor) addError(field *Field, path []interface{}, message string) {
+	err := &Error{Message: message, Path: append([]interface{}(nil), path...)}
+	if field.Location.Line > 0 {
+		err.Locations = []Location{field.Location}
+	}
+	e.errors = append(e.errors, err)
+}
+
+// selectionSet 按查询顺序执行字段；返回false表示某个非空字段为null，整个对象需置为null
+func (e *executor) selectionSet(ctx context.Context, object *Object, source interface{}, selections []Selection, path []interface{}) (*orderedMap, bool) {
+	result := &orderedMap{}
+	for _, group := range e.collectFields(object, selections) {
+		field := group[0]
+		key := field.ResponseKey()
+		if field.Name == "__typename" {
+			result.set(key, object.Name)
+			continue
+		}
+
+		def := object.Fields[field.Name]
+		value, ok := e.field(ctx, def, source, group, appendPath(path, key))
+		if !ok {
+			if isNonNull(def.Type) {
+				return nil, false
+			}
+			value = nil
+		}
+		result.set(key, value)
+	}
+	return result, true
+}
+
+// collectFields 展开片段并按响应键合并同名字段
+func (e *executor) collectFields(object *Object, selections []Selection) [][]*Field {
+	var groups [][]*Field
+	index := make(map[string]int)
+	var collect func(selections []Selection, visited map[string]bool)
+	collect = func(selections []Selection, visited map[string]bool) {
+		for _, selection := range selections {
+			if !e.include(selection.directives()) {
+				continue
+			}
+			switch sel := selection.(type) {
+			case *Field:
+				key := sel.ResponseKey()
+				if i, ok := index[key]; ok {
+					groups[i] = append(groups[i], sel)
+				} else {
+					index[key] = len(groups)
+					groups = append(groups, []*Field{sel})
+				}
+			case *InlineFragment:
+				collect(sel.SelectionSet, visited)
+			case *FragmentSpread:
+				if visited[sel.Name] {
+					continue
+				}
+				visited[sel.Name] = true
+				collect(e.doc.Fragments[sel.Name].SelectionSet, visited)
+			}
+		}
+	}
+	collect(selections, make(map[string]bool))
+	return groups
+}
+
+func (e *executor) include(directives []*Directive) bool {
+	for _, directive := range directives {
+		condition, _ := directiveCondition(directive, e.variables, e.declared)
+		if (directive.Name == "skip" && condition) || (directive.Name == "include" && !condition) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *executor) field(ctx context.Context, def *FieldDefinition, source interface{}, fields []*Field, path []interface{}) (interface{}, bool) {
+	field := fields[0]
+	resolve := def.Resolve
+	if resolve == nil {
+		resolve = defaultResolve(field.Name)
+	}
+
+	value, err := safeResolve(resolve, ResolveParams{
+		Context: ctx,
+		Source:  source,
+		Args:    e.args[field],
+		Path:    path,
+	})
+	if err != nil {
+		e.addError(field, path, err.Error())
+		return nil, false
+	}
+	return e.complete(ctx, def.Type, fields, value, path)
+}
+
+func safeResolve(resolve ResolveFunc, p ResolveParams) (value interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("GraphQL resolver panic at %v: %v", p.Path, r)
+			value, err = nil, fmt.Errorf("internal error")
+		}
+	}()
+	return resolve(p)
+}
+
+// complete 按字段类型转换解析结果；返回false表示值因错误为null（错误已记录）
+func (e *executor) complete(ctx context.Context, t Type, fields []*Field, value interface{}, path []interface{}) (interface{}, bool) {
+	if nonNull, ok := t.(*NonNull); ok {
+		completed, ok := e.complete(ctx, nonNull.OfType, fields, value, path)
+		if !ok {
+			return nil, false
+		}
+		if completed == nil {
+			e.addError(fields[0], path, fmt.Sprintf("Cannot return null for non-nullable field %s.", fields[0].Name))
+			return nil, false
+		}
+		return completed, true
+	}
+
+	if isNil(value) {
+		// 空切片按空列表返回，避免把查询结果为空误当作null
+		if _, isList := t.(*List); isList && reflect.ValueOf(value).Kind() == reflect.Slice {
+			return []interface{}{}, true
+		}
+		return nil, true
+	}
+
+	switch t := t.(type) {
+	case *List:
+		rv := reflect.ValueOf(value)
+		for rv.Kind() == reflect.Ptr {
+			rv = rv.Elem()
+		}
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			e.addError(fields[0], path, fmt.Sprintf("Expected a list for field %s.", fields[0].Name))
+			return nil, false
+		}
+		items := make([]interface{}, rv.Len())
+		for i := range items {
+			item, ok := e.complete(ctx, t.OfType, fields, listItem(rv.Index(i)), appendPath(path, i))
+			if !ok {
+				if isNonNull(t.OfType) {
+					return nil, false
+				}
+				item = nil
+			}
+			items[i] = item
+		}
+		return items, true
+
+	case *Object:
+		var selections []Selection
+		for _, field := range fields {
+			selections = append(selections, field.SelectionSet...)
+		}
+		result, ok := e.selectionSet(ctx, t, value, selections, path)
+		if !ok {
+			return nil, false
+		}
+		return result, true
+
+	case *Enum:
+		s, err := serializeString(deref(value))
+		if err != nil || !t.has(s.(string)) {
+			e.addError(fields[0], path, fmt.Sprintf("Enum %s cannot represent value: %v", t.Name, deref(value)))
+			return nil, false
+		}
+		return s, true
+
+	case *Scalar:
+		serialized, err := t.Serialize(deref(value))
+		if err != nil {
+			e.addError(fields[0], path, err.Error())
+			return nil, false
+		}
+		return serialized, true
+	}
+
+	e.addError(fields[0], path, fmt.Sprintf("Unsupported type %s.", t))
+	return nil, false
+}
+
+// appendPath 复制后追加，避免同级字段共用底层数组
+func appendPath(path []interface{}, segment interface{}) []interface{} {
+	return append(path[:len(path):len(path)], segment)
+}
+
+// listItem 结构体元素取地址，使解析函数总能拿到指针
+func listItem(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Struct && v.CanAddr() {
+		return v.Addr().Interface()
+	}
+	return v.Interface()
+}
+
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func:
+		return rv.IsNil()
+	}
+	return false
+}
+
+func deref(value interface{}) interface{} {
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	return rv.Interface()
+}
+
+// defaultResolve 从map或结构体读取字段，结构体按json标签匹配（accountId 对应 account_id）
+func defaultResolve(name string) ResolveFunc {
+	jsonName := snakeCase(name)
+	return func(p ResolveParams) (interface{}, error) {
+		if m, ok := p.Source.(map[string]interface{}); ok {
+			return m[name], nil
+		}
+
+		rv := reflect.ValueOf(p.Source)
+		for rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				return nil, nil
+			}
+			rv = rv.Elem()
+		}
+		if rv.Kind() != reflect.Struct {
+			return nil, nil
+		}
+		if field, ok := structField(rv, name, jsonName); ok {
+			if field.Kind() == reflect.Struct && field.CanAddr() {
+				return field.Addr().Interface(), nil
+			}
+			return field.Interface(), nil
+		}
+		return nil, nil
+	}
+}
+
+func structField(rv reflect.Value, name, jsonName string) (reflect.Value, bool) {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct && sf.Tag.Get("json") == "" {
+			if field, ok := structField(rv.Field(i), name, jsonName); ok {
+				return field, true
+			}
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		tag := strings.Split(sf.Tag.Get("json"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+		if tag == jsonName || tag == name || (tag == "" && strings.EqualFold(sf.Name, name)) {
+			return rv.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// orderedMap 按查询中字段的顺序输出JSON
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (m *orderedMap) set(key string, value interface{}) {
+	if m.values == nil {
+		m.values = make(map[string]interface{})
+	}
+	if _, exists := m.values[key]; !exists {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,188 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testItem struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	AccountID uint   `json:"account_id"`
+}
+
+func newTestSchema(t *testing.T) *Schema {
+	t.Helper()
+
+	item := &Object{
+		Name: "Item",
+		Fields: map[string]*FieldDefinition{
+			"id":        {Type: NewNonNull(ID)},
+			"name":      {Type: String},
+			"accountId": {Type: ID},
+			"broken": {
+				Type: NewNonNull(String),
+				Resolve: func(p ResolveParams) (interface{}, error) {
+					return nil, errors.New("boom")
+				},
+			},
+		},
+	}
+	items := []testItem{{ID: 1, Name: "first", AccountID: 7}, {ID: 2, Name: "second", AccountID: 8}}
+
+	query := &Object{
+		Name: "Query",
+		Fields: map[string]*FieldDefinition{
+			"items": {
+				Type: NewNonNull(NewList(NewNonNull(item))),
+				Args: map[string]*ArgumentDefinition{
+					"first": {Type: Int, Default: int32(10)},
+				},
+				Resolve: func(p ResolveParams) (interface{}, error) {
+					first := int(p.Args["first"].(int32))
+					if first > len(items) {
+						first = len(items)
+					}
+					return items[:first], nil
+				},
+			},
+			"item": {
+				Type: item,
+				Args: map[string]*ArgumentDefinition{
+					"id": {Type: NewNonNull(ID)},
+				},
+				Resolve: func(p ResolveParams) (interface{}, error) {
+					for i := range items {
+						if strconv.FormatUint(uint64(items[i].ID), 10) == p.Args["id"] {
+							return &items[i], nil
+						}
+					}
+					return nil, nil
+				},
+			},
+		},
+	}
+
+	schema, err := NewSchema(query)
+	require.NoError(t, err)
+	return schema
+}
+
+func executeJSON(t *testing.T, schema *Schema, req Request, opts ExecuteOptions) (string, *Response) {
+	t.Helper()
+
+	resp := schema.Execute(context.Background(), req, opts)
+	data, err := json.Marshal(resp.Data)
+	require.NoError(t, err)
+	return string(data), resp
+}
+
+func TestParseDocument(t *testing.T) {
+	doc, err := Parse(`
+		query List($first: Int = 1) {
+			items(first: $first) { ...ItemFields }
+		}
+		fragment ItemFields on Item { id name }
+	`)
+	require.NoError(t, err)
+	require.Len(t, doc.Operations, 1)
+	require.Equal(t, "List", doc.Operations[0].Name)
+	require.Len(t, doc.Operations[0].Variables, 1)
+	require.Contains(t, doc.Fragments, "ItemFields")
+
+	_, err = Parse(`{ items(first: ) { id } }`)
+	require.Error(t, err)
+	require.True(t, strings.HasPrefix(err.Error(), "Syntax Error:"))
+}
+
+func TestExecuteFieldSelection(t *testing.T) {
+	schema := newTestSchema(t)
+
+	data, resp := executeJSON(t, schema, Request{
+		Query: `query($n: Int) {
+			items(first: $n) { ...F }
+			one: item(id: "2") { name accountId __typename }
+		}
+		fragment F on Item { id }`,
+		Variables: map[string]interface{}{"n": float64(1)},
+	}, ExecuteOptions{})
+	require.Empty(t, resp.Errors)
+	require.JSONEq(t, `{"items":[{"id":"1"}],"one":{"name":"second","accountId":"8","__typename":"Item"}}`, data)
+
+	// 响应字段保持查询中的顺序
+	require.True(t, strings.Index(data, `"name"`) < strings.Index(data, `"accountId"`))
+}
+
+func TestExecuteDirectives(t *testing.T) {
+	schema := newTestSchema(t)
+
+	data, resp := executeJSON(t, schema, Request{
+		Query:     `query($skip: Boolean!) { item(id: "1") { id name @skip(if: $skip) } }`,
+		Variables: map[string]interface{}{"skip": true},
+	}, ExecuteOptions{})
+	require.Empty(t, resp.Errors)
+	require.JSONEq(t, `{"item":{"id":"1"}}`, data)
+}
+
+func TestExecuteNullPropagation(t *testing.T) {
+	schema := newTestSchema(t)
+
+	data, resp := executeJSON(t, schema, Request{Query: `{ item(id: "1") { id broken } }`}, ExecuteOptions{})
+	require.JSONEq(t, `{"item":null}`, data)
+	require.Len(t, resp.Errors, 1)
+	require.Equal(t, "boom", resp.Errors[0].Message)
+	require.Equal(t, []interface{}{"item", "broken"}, resp.Errors[0].Path)
+
+	// 非空列表中的元素失败时向上传播到可空的根字段之外，data整体为null
+	data, resp = executeJSON(t, schema, Request{Query: `{ items { broken } }`}, ExecuteOptions{})
+	require.Equal(t, "null", data)
+	require.NotEmpty(t, resp.Errors)
+}
+
+func TestExecuteValidationErrors(t *testing.T) {
+	schema := newTestSchema(t)
+
+	cases := []struct {
+		name    string
+		req     Request
+		message string
+	}{
+		{"unknown field", Request{Query: `{ item(id: "1") { email } }`}, `Cannot query field "email" on type "Item".`},
+		{"missing argument", Request{Query: `{ item { id } }`}, `argument "id" of type "ID!" is required`},
+		{"missing selection", Request{Query: `{ items }`}, `must have a selection of subfields`},
+		{"bad variable", Request{Query: `query($n: Int) { items(first: $n) { id } }`, Variables: map[string]interface{}{"n": "x"}}, `Variable "$n" got invalid value`},
+		{"mutation", Request{Query: `mutation { items { id } }`}, `Only query operations are supported`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := schema.Execute(context.Background(), tc.req, ExecuteOptions{})
+			require.Nil(t, resp.Data)
+			require.NotEmpty(t, resp.Errors)
+			require.Contains(t, resp.Errors[0].Message, tc.message)
+		})
+	}
+}
+
+func TestExecuteMaxDepth(t *testing.T) {
+	schema := newTestSchema(t)
+
+	resp := schema.Execute(context.Background(), Request{Query: `{ item(id: "1") { id } }`}, ExecuteOptions{MaxDepth: 1})
+	require.Nil(t, resp.Data)
+	require.Contains(t, resp.Errors[0].Message, "Query depth exceeds the limit of 1.")
+
+	resp = schema.Execute(context.Background(), Request{Query: `{ item(id: "1") { id } }`}, ExecuteOptions{MaxDepth: 2})
+	require.Empty(t, resp.Errors)
+}
+
+func TestSchemaSDL(t *testing.T) {
+	sdl := newTestSchema(t).SDL()
+	require.Contains(t, sdl, "type Query {")
+	require.Contains(t, sdl, "items(first: Int = 10): [Item!]!")
+	require.Contains(t, sdl, "type Item {")
+}
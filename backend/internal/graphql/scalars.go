@@ -0,0 +1,187 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// 内置标量以及DateTime扩展标量
+var (
+	Int = &Scalar{
+		Name:        "Int",
+		Description: "32位有符号整数",
+		Serialize:   serializeInt,
+		ParseValue:  parseInt,
+	}
+	Float = &Scalar{
+		Name:        "Float",
+		Description: "双精度浮点数",
+		Serialize:   serializeFloat,
+		ParseValue:  serializeFloat,
+	}
+	String = &Scalar{
+		Name:        "String",
+		Description: "UTF-8字符串",
+		Serialize:   serializeString,
+		ParseValue:  parseString,
+	}
+	Boolean = &Scalar{
+		Name:        "Boolean",
+		Description: "布尔值",
+		Serialize:   parseBoolean,
+		ParseValue:  parseBoolean,
+	}
+	ID = &Scalar{
+		Name:        "ID",
+		Description: "唯一标识，按字符串序列化",
+		Serialize:   serializeID,
+		ParseValue:  serializeID,
+	}
+	DateTime = &Scalar{
+		Name:        "DateTime",
+		Description: "RFC 3339 格式的时间",
+		Serialize:   serializeDateTime,
+		ParseValue:  parseDateTime,
+	}
+)
+
+func isBuiltinScalar(s *Scalar) bool {
+	return s == Int || s == Float || s == String || s == Boolean || s == ID
+}
+
+// toInt64 接受Go整数、整数值的浮点数以及json.Number
+func toInt64(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		n, err := v.Int64()
+		return n, err == nil
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, false
+		}
+		return int64(v), true
+	case float32:
+		return toInt64(float64(v))
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(rv.Uint()), true
+	}
+	return 0, false
+}
+
+func serializeInt(value interface{}) (interface{}, error) {
+	if b, ok := value.(bool); ok {
+		if b {
+			return int32(1), nil
+		}
+		return int32(0), nil
+	}
+	return parseInt(value)
+}
+
+func parseInt(value interface{}) (interface{}, error) {
+	n, ok := toInt64(value)
+	if !ok {
+		return nil, fmt.Errorf("Int cannot represent non-integer value: %v", value)
+	}
+	if n < math.MinInt32 || n > math.MaxInt32 {
+		return nil, fmt.Errorf("Int cannot represent non 32-bit signed integer value: %d", n)
+	}
+	return int32(n), nil
+}
+
+func serializeFloat(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case json.Number:
+		return v.Float64()
+	}
+	if n, ok := toInt64(value); ok {
+		return float64(n), nil
+	}
+	return nil, fmt.Errorf("Float cannot represent non numeric value: %v", value)
+}
+
+func serializeString(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case fmt.Stringer:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	if rv := reflect.ValueOf(value); rv.Kind() == reflect.String {
+		return rv.String(), nil
+	}
+	if n, ok := toInt64(value); ok {
+		return strconv.FormatInt(n, 10), nil
+	}
+	return nil, fmt.Errorf("String cannot represent value: %v", value)
+}
+
+func parseString(value interface{}) (interface{}, error) {
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("String cannot represent a non string value: %v", value)
+}
+
+func parseBoolean(value interface{}) (interface{}, error) {
+	if b, ok := value.(bool); ok {
+		return b, nil
+	}
+	return nil, fmt.Errorf("Boolean cannot represent a non boolean value: %v", value)
+}
+
+func serializeID(value interface{}) (interface{}, error) {
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	if n, ok := toInt64(value); ok {
+		return strconv.FormatInt(n, 10), nil
+	}
+	return nil, fmt.Errorf("ID cannot represent value: %v", value)
+}
+
+func serializeDateTime(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339), nil
+	case string:
+		return v, nil
+	}
+	return nil, fmt.Errorf("DateTime cannot represent value: %v", value)
+}
+
+func parseDateTime(value interface{}) (interface{}, error) {
+	if t, ok := value.(time.Time); ok {
+		return t, nil
+	}
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("DateTime must be an RFC 3339 string")
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, fmt.Errorf("DateTime must be an RFC 3339 string: %q", s)
+	}
+	return t, nil
+}
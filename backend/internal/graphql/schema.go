@@ -0,0 +1,283 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Type 类型系统中的类型
+type Type interface {
+	String() string
+}
+
+// Scalar 标量类型；Serialize把解析器返回值转换为JSON值，ParseValue把参数值转换为Go值
+type Scalar struct {
+	Name        string
+	Description string
+	Serialize   func(value interface{}) (interface{}, error)
+	ParseValue  func(value interface{}) (interface{}, error)
+}
+
+func (s *Scalar) String() string { return s.Name }
+
+// Enum 枚举类型，值按字符串传递
+type Enum struct {
+	Name        string
+	Description string
+	Values      []string
+}
+
+func (e *Enum) String() string { return e.Name }
+
+func (e *Enum) has(value string) bool {
+	for _, v := range e.Values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Object 对象类型
+type Object struct {
+	Name        string
+	Description string
+	Fields      Fields
+}
+
+func (o *Object) String() string { return o.Name }
+
+// Fields 对象字段，键为字段名
+type Fields map[string]*FieldDefinition
+
+// List 列表类型
+type List struct {
+	OfType Type
+}
+
+func (l *List) String() string { return "[" + l.OfType.String() + "]" }
+
+// NonNull 非空类型
+type NonNull struct {
+	OfType Type
+}
+
+func (n *NonNull) String() string { return n.OfType.String() + "!" }
+
+// NewList 创建列表类型
+func NewList(of Type) *List { return &List{OfType: of} }
+
+// NewNonNull 创建非空类型
+func NewNonNull(of Type) *NonNull { return &NonNull{OfType: of} }
+
+// FieldDefinition 字段定义，Resolve为空时按字段名从来源对象读取
+type FieldDefinition struct {
+	Type        Type
+	Description string
+	Args        map[string]*ArgumentDefinition
+	Resolve     ResolveFunc
+}
+
+// ArgumentDefinition 参数定义
+type ArgumentDefinition struct {
+	Type        Type
+	Default     interface{}
+	Description string
+}
+
+// ResolveFunc 字段解析函数
+type ResolveFunc func(p ResolveParams) (interface{}, error)
+
+// ResolveParams 解析函数的输入
+type ResolveParams struct {
+	Context context.Context
+	Source  interface{}
+	Args    map[string]interface{}
+	Path    []interface{}
+}
+
+// Schema 只读查询的类型系统
+type Schema struct {
+	query *Object
+	types map[string]Type
+}
+
+// NewSchema 创建Schema并检查类型引用，同名类型必须是同一个定义
+func NewSchema(query *Object) (*Schema, error) {
+	s := &Schema{query: query, types: make(map[string]Type)}
+	for _, scalar := range []*Scalar{Int, Float, String, Boolean, ID} {
+		s.types[scalar.Name] = scalar
+	}
+	if err := s.collect(query); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Query 根查询类型
+func (s *Schema) Query() *Object {
+	return s.query
+}
+
+func (s *Schema) collect(t Type) error {
+	switch t := t.(type) {
+	case *List:
+		return s.collect(t.OfType)
+	case *NonNull:
+		if _, nested := t.OfType.(*NonNull); nested {
+			return fmt.Errorf("graphql: %s wraps a non-null type", t)
+		}
+		return s.collect(t.OfType)
+	}
+
+	name := t.String()
+	if existing, ok := s.types[name]; ok {
+		if existing != t {
+			return fmt.Errorf("graphql: type %s defined more than once", name)
+		}
+		return nil
+	}
+	s.types[name] = t
+
+	object, ok := t.(*Object)
+	if !ok {
+		return nil
+	}
+	if len(object.Fields) == 0 {
+		return fmt.Errorf("graphql: object %s has no fields", name)
+	}
+	for fieldName, field := range object.Fields {
+		if field.Type == nil {
+			return fmt.Errorf("graphql: field %s.%s has no type", name, fieldName)
+		}
+		if err := s.collect(field.Type); err != nil {
+			return err
+		}
+		for argName, arg := range field.Args {
+			if !isInputType(arg.Type) {
+				return fmt.Errorf("graphql: argument %s.%s(%s) must be a scalar or enum type", name, fieldName, argName)
+			}
+			if err := s.collect(arg.Type); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func isInputType(t Type) bool {
+	switch t := t.(type) {
+	case *List:
+		return isInputType(t.OfType)
+	case *NonNull:
+		return isInputType(t.OfType)
+	case *Scalar, *Enum:
+		return true
+	}
+	return false
+}
+
+func namedType(t Type) Type {
+	for {
+		switch wrapped := t.(type) {
+		case *List:
+			t = wrapped.OfType
+		case *NonNull:
+			t = wrapped.OfType
+		default:
+			return t
+		}
+	}
+}
+
+// SDL 以Schema定义语言输出类型系统，供客户端生成类型
+func (s *Schema) SDL() string {
+	var b strings.Builder
+	b.WriteString("schema {\n  query: " + s.query.Name + "\n}\n")
+
+	names := make([]string, 0, len(s.types))
+	for name := range s.types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		switch t := s.types[name].(type) {
+		case *Scalar:
+			if isBuiltinScalar(t) {
+				continue
+			}
+			b.WriteString("\n")
+			writeDescription(&b, t.Description, "")
+			b.WriteString("scalar " + t.Name + "\n")
+		case *Enum:
+			b.WriteString("\n")
+			writeDescription(&b, t.Description, "")
+			b.WriteString("enum " + t.Name + " {\n")
+			for _, value := range t.Values {
+				b.WriteString("  " + value + "\n")
+			}
+			b.WriteString("}\n")
+		case *Object:
+			b.WriteString("\n")
+			writeDescription(&b, t.Description, "")
+			b.WriteString("type " + t.Name + " {\n")
+			for _, fieldName := range sortedFieldNames(t.Fields) {
+				field := t.Fields[fieldName]
+				writeDescription(&b, field.Description, "  ")
+				b.WriteString("  " + fieldName + writeArgs(field.Args) + ": " + field.Type.String() + "\n")
+			}
+			b.WriteString("}\n")
+		}
+	}
+	return b.String()
+}
+
+func writeDescription(b *strings.Builder, description, indent string) {
+	if description == "" {
+		return
+	}
+	fmt.Fprintf(b, "%s%q\n", indent, description)
+}
+
+func writeArgs(args map[string]*ArgumentDefinition) string {
+	if len(args) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		arg := args[name]
+		parts[i] = name + ": " + arg.Type.String()
+		if arg.Default != nil {
+			parts[i] += " = " + formatDefault(arg.Default, arg.Type)
+		}
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+func formatDefault(value interface{}, t Type) string {
+	if _, isEnum := namedType(t).(*Enum); isEnum {
+		return fmt.Sprint(value)
+	}
+	if s, ok := value.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprint(value)
+}
+
+func sortedFieldNames(fields Fields) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,343 @@
+package graphql
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// validator 执行前校验选择集并转换参数，参数结果按字段缓存供执行阶段使用
+type validator struct {
+	schema    *Schema
+	doc       *Document
+	variables map[string]interface{}
+	declared  map[string]bool
+	maxDepth  int
+	args      map[*Field]map[string]interface{}
+	errors    []*Error
+	spreading map[string]bool
+}
+
+func (v *validator) addError(err *Error) {
+	v.errors = append(v.errors, err)
+}
+
+// coerceVariables 按声明类型转换请求中的变量，缺省时使用默认值
+func (v *validator) coerceVariables(op *OperationDefinition, input map[string]interface{}) {
+	v.variables = make(map[string]interface{})
+	v.declared = make(map[string]bool)
+	for _, def := range op.Variables {
+		if v.declared[def.Name] {
+			v.addError(locatedError(def.Location, "There can be only one variable named \"$%s\".", def.Name))
+			continue
+		}
+		v.declared[def.Name] = true
+
+		t, err := v.resolveTypeRef(def.Type)
+		if err != nil {
+			v.addError(locatedError(def.Location, "Variable \"$%s\": %s", def.Name, err))
+			continue
+		}
+
+		raw, provided := input[def.Name]
+		switch {
+		case provided:
+			value, err := coerceInput(t, raw)
+			if err != nil {
+				v.addError(locatedError(def.Location, "Variable \"$%s\" got invalid value: %s", def.Name, err))
+				continue
+			}
+			v.variables[def.Name] = value
+		case def.Default != nil:
+			value, err := coerceLiteral(t, def.Default, nil)
+			if err != nil {
+				v.addError(locatedError(def.Location, "Variable \"$%s\" has invalid default value: %s", def.Name, err))
+				continue
+			}
+			v.variables[def.Name] = value
+		case isNonNull(t):
+			v.addError(locatedError(def.Location, "Variable \"$%s\" of required type \"%s\" was not provided.", def.Name, t))
+		}
+	}
+}
+
+func (v *validator) resolveTypeRef(ref *TypeRef) (Type, error) {
+	var t Type
+	if ref.Elem != nil {
+		elem, err := v.resolveTypeRef(ref.Elem)
+		if err != nil {
+			return nil, err
+		}
+		t = NewList(elem)
+	} else {
+		named, ok := v.schema.types[ref.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown type \"%s\"", ref.Name)
+		}
+		if !isInputType(named) {
+			return nil, fmt.Errorf("type \"%s\" is not an input type", ref.Name)
+		}
+		t = named
+	}
+	if ref.NonNull {
+		t = NewNonNull(t)
+	}
+	return t, nil
+}
+
+func (v *validator) selectionSet(parent *Object, selections []Selection, depth int) {
+	for _, selection := range selections {
+		if !v.checkDirectives(selection.directives()) {
+			continue
+		}
+
+		switch sel := selection.(type) {
+		case *Field:
+			v.field(parent, sel, depth)
+		case *InlineFragment:
+			if sel.TypeCondition != "" && !v.typeCondition(parent, sel.TypeCondition, sel.Location) {
+				continue
+			}
+			v.selectionSet(parent, sel.SelectionSet, depth)
+		case *FragmentSpread:
+			fragment, ok := v.doc.Fragments[sel.Name]
+			if !ok {
+				v.addError(locatedError(sel.Location, "Unknown fragment \"%s\".", sel.Name))
+				continue
+			}
+			if v.spreading[sel.Name] {
+				v.addError(locatedError(sel.Location, "Cannot spread fragment \"%s\" within itself.", sel.Name))
+				continue
+			}
+			if !v.typeCondition(parent, fragment.TypeCondition, sel.Location) {
+				continue
+			}
+			v.spreading[sel.Name] = true
+			v.selectionSet(parent, fragment.SelectionSet, depth)
+			delete(v.spreading, sel.Name)
+		}
+	}
+}
+
+// typeCondition 没有接口和联合类型，片段的类型条件必须与所在对象一致
+func (v *validator) typeCondition(parent *Object, condition string, loc Location) bool {
+	if _, ok := v.schema.types[condition]; !ok {
+		v.addError(locatedError(loc, "Unknown type \"%s\".", condition))
+		return false
+	}
+	if condition != parent.Name {
+		v.addError(locatedError(loc, "Fragment cannot be spread here as objects of type \"%s\" can never be of type \"%s\".", parent.Name, condition))
+		return false
+	}
+	return true
+}
+
+func (v *validator) field(parent *Object, field *Field, depth int) {
+	if v.maxDepth > 0 && depth > v.maxDepth {
+		v.addError(locatedError(field.Location, "Query depth exceeds the limit of %d.", v.maxDepth))
+		return
+	}
+
+	if field.Name == "__typename" {
+		if len(field.SelectionSet) > 0 {
+			v.addError(locatedError(field.Location, "Field \"__typename\" must not have a selection since type \"String!\" has no subfields."))
+		}
+		return
+	}
+
+	def, ok := parent.Fields[field.Name]
+	if !ok {
+		v.addError(locatedError(field.Location, "Cannot query field \"%s\" on type \"%s\".", field.Name, parent.Name))
+		return
+	}
+
+	args, err := coerceArguments(def.Args, field.Arguments, v.variables, v.declared)
+	if err != nil {
+		v.addError(locatedError(field.Location, "Field \"%s\": %s", field.Name, err))
+	} else {
+		v.args[field] = args
+	}
+
+	object, isObject := namedType(def.Type).(*Object)
+	switch {
+	case isObject && len(field.SelectionSet) == 0:
+		v.addError(locatedError(field.Location, "Field \"%s\" of type \"%s\" must have a selection of subfields.", field.Name, def.Type))
+	case !isObject && len(field.SelectionSet) > 0:
+		v.addError(locatedError(field.Location, "Field \"%s\" must not have a selection since type \"%s\" has no subfields.", field.Name, def.Type))
+	case isObject:
+		v.selectionSet(object, field.SelectionSet, depth+1)
+	}
+}
+
+// checkDirectives 只支持 @skip 和 @include，返回选择是否参与后续校验
+func (v *validator) checkDirectives(directives []*Directive) bool {
+	ok := true
+	for _, directive := range directives {
+		if directive.Name != "skip" && directive.Name != "include" {
+			v.addError(&Error{Message: fmt.Sprintf("Unknown directive \"@%s\".", directive.Name)})
+			ok = false
+			continue
+		}
+		if _, err := directiveCondition(directive, v.variables, v.declared); err != nil {
+			v.addError(&Error{Message: fmt.Sprintf("Directive \"@%s\": %s", directive.Name, err)})
+			ok = false
+		}
+	}
+	return ok
+}
+
+var directiveArgs = map[string]*ArgumentDefinition{"if": {Type: NewNonNull(Boolean)}}
+
+func directiveCondition(directive *Directive, variables map[string]interface{}, declared map[string]bool) (bool, error) {
+	args, err := coerceArguments(directiveArgs, directive.Arguments, variables, declared)
+	if err != nil {
+		return false, err
+	}
+	return args["if"].(bool), nil
+}
+
+// coerceArguments 把字段参数字面量转换为Go值，未提供的可选参数不出现在结果中
+func coerceArguments(defs map[string]*ArgumentDefinition, args []*Argument, variables map[string]interface{}, declared map[string]bool) (map[string]interface{}, error) {
+	provided := make(map[string]Value, len(args))
+	for _, arg := range args {
+		if _, ok := defs[arg.Name]; !ok {
+			return nil, fmt.Errorf("unknown argument \"%s\"", arg.Name)
+		}
+		if ref, ok := arg.Value.(Variable); ok {
+			if !declared[ref.Name] {
+				return nil, fmt.Errorf("variable \"$%s\" is not defined", ref.Name)
+			}
+			if _, set := variables[ref.Name]; !set {
+				continue
+			}
+		}
+		provided[arg.Name] = arg.Value
+	}
+
+	result := make(map[string]interface{}, len(defs))
+	for name, def := range defs {
+		literal, ok := provided[name]
+		if !ok {
+			switch {
+			case def.Default != nil:
+				result[name] = def.Default
+			case isNonNull(def.Type):
+				return nil, fmt.Errorf("argument \"%s\" of type \"%s\" is required", name, def.Type)
+			}
+			continue
+		}
+		value, err := coerceLiteral(def.Type, literal, variables)
+		if err != nil {
+			return nil, fmt.Errorf("argument \"%s\" has invalid value: %s", name, err)
+		}
+		result[name] = value
+	}
+	return result, nil
+}
+
+// coerceLiteral 按类型转换查询中的字面量，变量取已转换的值
+func coerceLiteral(t Type, literal Value, variables map[string]interface{}) (interface{}, error) {
+	if ref, ok := literal.(Variable); ok {
+		// 变量已按声明类型转换，再按使用位置的类型转换一次以发现类型不匹配
+		return coerceInput(t, variables[ref.Name])
+	}
+
+	switch t := t.(type) {
+	case *NonNull:
+		if literal == nil {
+			return nil, fmt.Errorf("expected non-null value of type %s", t)
+		}
+		return coerceLiteral(t.OfType, literal, variables)
+	case *List:
+		if literal == nil {
+			return nil, nil
+		}
+		items, ok := literal.([]Value)
+		if !ok {
+			item, err := coerceLiteral(t.OfType, literal, variables)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{item}, nil
+		}
+		result := make([]interface{}, len(items))
+		for i, item := range items {
+			value, err := coerceLiteral(t.OfType, item, variables)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = value
+		}
+		return result, nil
+	}
+
+	if literal == nil {
+		return nil, nil
+	}
+	switch t := t.(type) {
+	case *Enum:
+		value, ok := literal.(EnumValue)
+		if !ok || !t.has(string(value)) {
+			return nil, fmt.Errorf("expected a value of enum %s", t.Name)
+		}
+		return string(value), nil
+	case *Scalar:
+		switch literal.(type) {
+		case EnumValue, []Value, map[string]Value:
+			return nil, fmt.Errorf("expected a value of type %s", t.Name)
+		}
+		return t.ParseValue(literal)
+	}
+	return nil, fmt.Errorf("type %s cannot be used as input", t)
+}
+
+// coerceInput 按类型转换JSON解码得到的变量值
+func coerceInput(t Type, value interface{}) (interface{}, error) {
+	switch t := t.(type) {
+	case *NonNull:
+		if value == nil {
+			return nil, fmt.Errorf("expected non-null value of type %s", t)
+		}
+		return coerceInput(t.OfType, value)
+	case *List:
+		if value == nil {
+			return nil, nil
+		}
+		rv := reflect.ValueOf(value)
+		if rv.Kind() != reflect.Slice {
+			item, err := coerceInput(t.OfType, value)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{item}, nil
+		}
+		result := make([]interface{}, rv.Len())
+		for i := range result {
+			item, err := coerceInput(t.OfType, rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			result[i] = item
+		}
+		return result, nil
+	}
+
+	if value == nil {
+		return nil, nil
+	}
+	switch t := t.(type) {
+	case *Enum:
+		s, ok := value.(string)
+		if !ok || !t.has(s) {
+			return nil, fmt.Errorf("expected a value of enum %s", t.Name)
+		}
+		return s, nil
+	case *Scalar:
+		return t.ParseValue(value)
+	}
+	return nil, fmt.Errorf("type %s cannot be used as input", t)
+}
+
+func isNonNull(t Type) bool {
+	_, ok := t.(*NonNull)
+	return ok
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bindAnalyticsQuery 解析统计分析查询参数
+func (h *Handler) bindAnalyticsQuery(c *gin.Context) (uint, *services.AnalyticsQuery, bool) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return 0, nil, false
+	}
+
+	var query services.AnalyticsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid query parameters: "+err.Error())
+		return 0, nil, false
+	}
+	return userID, &query, true
+}
+
+// GetEmailVolume 按时间段统计收发邮件数
+func (h *Handler) GetEmailVolume(c *gin.Context) {
+	userID, query, ok := h.bindAnalyticsQuery(c)
+	if !ok {
+		return
+	}
+
+	points, err := h.analyticsService.GetVolume(c.Request.Context(), userID, query)
+	if err != nil {
+		h.respondWithAnalyticsError(c, err, "Failed to get email volume")
+		return
+	}
+
+	h.respondWithSuccess(c, points)
+}
+
+// GetBusiestHours 按小时和星期统计收发邮件数
+func (h *Handler) GetBusiestHours(c *gin.Context) {
+	userID, query, ok := h.bindAnalyticsQuery(c)
+	if !ok {
+		return
+	}
+
+	activity, err := h.analyticsService.GetBusiestHours(c.Request.Context(), userID, query)
+	if err != nil {
+		h.respondWithAnalyticsError(c, err, "Failed to get busiest hours")
+		return
+	}
+
+	h.respondWithSuccess(c, activity)
+}
+
+// GetTopSenders 获取来信最多的发件人
+func (h *Handler) GetTopSenders(c *gin.Context) {
+	userID, query, ok := h.bindAnalyticsQuery(c)
+	if !ok {
+		return
+	}
+
+	senders, err := h.analyticsService.GetTopSenders(c.Request.Context(), userID, query)
+	if err != nil {
+		h.respondWithAnalyticsError(c, err, "Failed to get top senders")
+		return
+	}
+
+	h.respondWithSuccess(c, senders)
+}
+
+// GetTopRecipients 获取发信最多的收件人
+func (h *Handler) GetTopRecipients(c *gin.Context) {
+	userID, query, ok := h.bindAnalyticsQuery(c)
+	if !ok {
+		return
+	}
+
+	recipients, err := h.analyticsService.GetTopRecipients(c.Request.Context(), userID, query)
+	if err != nil {
+		h.respondWithAnalyticsError(c, err, "Failed to get top recipients")
+		return
+	}
+
+	h.respondWithSuccess(c, recipients)
+}
+
+// GetResponseTimes 获取回复邮件所用时间的统计
+func (h *Handler) GetResponseTimes(c *gin.Context) {
+	userID, query, ok := h.bindAnalyticsQuery(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.analyticsService.GetResponseTimes(c.Request.Context(), userID, query)
+	if err != nil {
+		h.respondWithAnalyticsError(c, err, "Failed to get response times")
+		return
+	}
+
+	h.respondWithSuccess(c, stats)
+}
+
+// GetOutboundMessages 获取发出的邮件及其回复情况，unreplied=true 时只返回未收到回复的邮件
+func (h *Handler) GetOutboundMessages(c *gin.Context) {
+	userID, query, ok := h.bindAnalyticsQuery(c)
+	if !ok {
+		return
+	}
+
+	unreplied := false
+	if value := h.parseOptionalBoolQuery(c, "unreplied"); value != nil {
+		unreplied = *value
+	}
+
+	messages, err := h.analyticsService.GetOutboundMessages(c.Request.Context(), userID, query, unreplied)
+	if err != nil {
+		h.respondWithAnalyticsError(c, err, "Failed to get outbound messages")
+		return
+	}
+
+	h.respondWithSuccess(c, messages)
+}
+
+// GetOutboundAccounts 按账户统计发出邮件的回复率和回复用时
+func (h *Handler) GetOutboundAccounts(c *gin.Context) {
+	userID, query, ok := h.bindAnalyticsQuery(c)
+	if !ok {
+		return
+	}
+
+	accounts, err := h.analyticsService.GetOutboundAccounts(c.Request.Context(), userID, query)
+	if err != nil {
+		h.respondWithAnalyticsError(c, err, "Failed to get outbound account stats")
+		return
+	}
+
+	h.respondWithSuccess(c, accounts)
+}
+
+// GetOutboundContacts 按联系人统计发出邮件的回复率和回复用时
+func (h *Handler) GetOutboundContacts(c *gin.Context) {
+	userID, query, ok := h.bindAnalyticsQuery(c)
+	if !ok {
+		return
+	}
+
+	contacts, err := h.analyticsService.GetOutboundContacts(c.Request.Context(), userID, query)
+	if err != nil {
+		h.respondWithAnalyticsError(c, err, "Failed to get outbound contact stats")
+		return
+	}
+
+	h.respondWithSuccess(c, contacts)
+}
+
+// RebuildAnalytics 根据现有邮件重建收发统计
+func (h *Handler) RebuildAnalytics(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	// 请求体可省略，此时重建全部账户
+	var req services.RebuildVolumeStatsRequest
+	if c.Request.ContentLength != 0 && !h.bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.analyticsService.RebuildVolumeStats(c.Request.Context(), userID, req.AccountID); err != nil {
+		h.respondWithAnalyticsError(c, err, "Failed to rebuild analytics")
+		return
+	}
+
+	h.respondWithSuccess(c, nil, "Analytics rebuilt")
+}
+
+// respondWithAnalyticsError 将统计分析错误映射为HTTP状态码
+func (h *Handler) respondWithAnalyticsError(c *gin.Context, err error, message string) {
+	switch {
+	case err.Error() == "account not found":
+		h.respondWithError(c, http.StatusNotFound, err.Error())
+	case strings.HasPrefix(err.Error(), "from must be"),
+		strings.HasPrefix(err.Error(), "date range"),
+		strings.HasPrefix(err.Error(), "interval must"),
+		strings.HasPrefix(err.Error(), "invalid time zone"):
+		h.respondWithError(c, http.StatusBadRequest, err.Error())
+	default:
+		h.respondWithError(c, http.StatusInternalServerError, message+": "+err.Error())
+	}
+}
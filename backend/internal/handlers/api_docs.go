@@ -0,0 +1,716 @@
+package handlers
+
+import (
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"firemail/internal/auth"
+	"firemail/internal/config"
+	"firemail/internal/graphql"
+	"firemail/internal/models"
+	"firemail/internal/openapi"
+	"firemail/internal/services"
+	"firemail/internal/sse"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 以下类型仅描述处理器中以map构造的响应结构，供接口文档引用
+
+// HealthResponse 健康检查响应
+type HealthResponse struct {
+	Status  string `json:"status"`
+	Service string `json:"service"`
+	Version string `json:"version"`
+}
+
+// ProviderServer 提供商服务器配置
+type ProviderServer struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Security string `json:"security"`
+}
+
+// ProviderInfo 邮件提供商信息
+type ProviderInfo struct {
+	Name        string          `json:"name"`
+	DisplayName string          `json:"display_name"`
+	AuthMethods []string        `json:"auth_methods"`
+	Domains     []string        `json:"domains,omitempty"`
+	IMAP        *ProviderServer `json:"imap,omitempty"`
+	SMTP        *ProviderServer `json:"smtp,omitempty"`
+}
+
+// OAuthURLResponse OAuth2授权地址
+type OAuthURLResponse struct {
+	AuthURL string `json:"auth_url"`
+	State   string `json:"state"`
+}
+
+// OAuthTokenResponse OAuth2回调换取的令牌
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	Scope        string `json:"scope"`
+	ClientID     string `json:"client_id"`
+}
+
+// BatchEmailResult 批量邮件操作结果
+type BatchEmailResult struct {
+	SuccessCount int      `json:"success_count"`
+	TotalCount   int      `json:"total_count"`
+	Errors       []string `json:"errors"`
+
+	Job *services.EmailTransferJob `json:"job,omitempty"` // 跨账户移动/复制任务
+}
+
+// DraftAutosaveResult 草稿自动保存结果
+type DraftAutosaveResult struct {
+	ID           uint       `json:"id"`
+	LastEditedAt *time.Time `json:"last_edited_at"`
+}
+
+// ImportDraftsResult 导入服务器草稿结果
+type ImportDraftsResult struct {
+	Imported int `json:"imported"`
+}
+
+// AttachmentUploadResult 附件上传结果
+type AttachmentUploadResult struct {
+	AttachmentID uint   `json:"attachment_id"`
+	Filename     string `json:"filename"`
+	Size         int64  `json:"size"`
+	ContentType  string `json:"content_type"`
+}
+
+// TestEventRequest 发送测试事件请求
+type TestEventRequest struct {
+	Type    string      `json:"type" binding:"required"`
+	Message string      `json:"message" binding:"required"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// TestEventResult 测试事件发布结果
+type TestEventResult struct {
+	EventID string        `json:"event_id"`
+	Type    sse.EventType `json:"type"`
+	Message string        `json:"message"`
+}
+
+// ConfigView 脱敏后的生效配置
+type ConfigView struct {
+	ConfigFile string           `json:"config_file"`
+	Settings   []config.Setting `json:"settings"`
+	Warnings   []string         `json:"warnings"`
+}
+
+const apiPrefix = "/api/v1"
+
+var (
+	pageParam     = openapi.QueryParam("page", "integer", "页码，从1开始")
+	pageSizeParam = openapi.QueryParam("page_size", "integer", "每页数量，1-100")
+	cursorParam   = openapi.QueryParam("cursor", "string", "上一页返回的next_cursor，提供时忽略page")
+)
+
+// APIRoutes 全部HTTP接口的文档注解，新增路由时需同步登记
+func APIRoutes() []openapi.Route {
+	return []openapi.Route{
+		{Method: "GET", Path: "/health", ID: "HealthCheck", Tag: "System", Summary: "健康检查", Raw: true, Data: HealthResponse{}, Public: true},
+		{Method: "GET", Path: apiPrefix + "/openapi.json", ID: "GetOpenAPISpec", Tag: "System", Summary: "获取OpenAPI文档", Raw: true, Public: true},
+
+		// 认证
+		{Method: "POST", Path: apiPrefix + "/auth/login", ID: "Login", Tag: "Auth", Summary: "用户登录", Body: auth.LoginRequest{}, Data: auth.LoginResponse{}, Public: true},
+		{Method: "POST", Path: apiPrefix + "/auth/logout", ID: "Logout", Tag: "Auth", Summary: "用户登出", Public: true},
+		{Method: "GET", Path: apiPrefix + "/auth/me", ID: "GetCurrentUser", Tag: "Auth", Summary: "获取当前用户信息", Data: models.User{}},
+
+		// OAuth2
+		{Method: "GET", Path: apiPrefix + "/oauth/gmail", ID: "InitGmailOAuth", Tag: "OAuth", Summary: "获取Gmail授权地址",
+			Params: []*openapi.Parameter{openapi.QueryParam("callback_url", "string", "授权完成后的前端回调地址")}, Data: OAuthURLResponse{}, Public: true},
+		{Method: "GET", Path: apiPrefix + "/oauth/outlook", ID: "InitOutlookOAuth", Tag: "OAuth", Summary: "获取Outlook授权地址",
+			Params: []*openapi.Parameter{openapi.QueryParam("callback_url", "string", "授权完成后的前端回调地址")}, Data: OAuthURLResponse{}, Public: true},
+		{Method: "GET", Path: apiPrefix + "/oauth/:provider/callback", ID: "HandleOAuth2Callback", Tag: "OAuth", Summary: "处理OAuth2回调",
+			Params: []*openapi.Parameter{
+				openapi.PathParam("provider", "string", "gmail 或 outlook"),
+				openapi.QueryParam("code", "string", "授权码"),
+				openapi.QueryParam("state", "string", "授权状态"),
+				openapi.QueryParam("error", "string", "提供商返回的错误"),
+				openapi.QueryParam("error_description", "string", "错误描述"),
+			}, Data: OAuthTokenResponse{}, Public: true},
+		{Method: "POST", Path: apiPrefix + "/oauth/create-account", ID: "CreateOAuth2Account", Tag: "OAuth", Summary: "使用OAuth2令牌创建邮件账户",
+			Body: CreateOAuth2AccountRequest{}, Status: http.StatusCreated, Data: models.EmailAccount{}},
+		{Method: "POST", Path: apiPrefix + "/oauth/manual-config", ID: "CreateManualOAuth2Account", Tag: "OAuth", Summary: "手动配置OAuth2邮件账户",
+			Body: CreateManualOAuth2AccountRequest{}, Status: http.StatusCreated, Data: models.EmailAccount{}},
+
+		// 邮件账户
+		{Method: "GET", Path: apiPrefix + "/accounts", ID: "GetEmailAccounts", Tag: "Accounts", Summary: "获取邮件账户列表", Data: []*models.EmailAccount{}},
+		{Method: "POST", Path: apiPrefix + "/accounts", ID: "CreateEmailAccount", Tag: "Accounts", Summary: "创建邮件账户；连接失败且需要先在邮箱网页版完成设置（如QQ邮箱授权码）时返回setup_guide分步说明",
+			Body: services.CreateEmailAccountRequest{}, Status: http.StatusCreated, Data: models.EmailAccount{}},
+		{Method: "POST", Path: apiPrefix + "/accounts/custom", ID: "CreateCustomEmailAccount", Tag: "Accounts", Summary: "创建自定义服务器邮件账户",
+			Body: services.CreateEmailAccountRequest{}, Status: http.StatusCreated, Data: models.EmailAccount{}},
+		{Method: "PUT", Path: apiPrefix + "/accounts/reorder", ID: "ReorderEmailAccounts", Tag: "Accounts", Summary: "调整账户显示顺序，未列出的账户排在后面",
+			Body: ReorderEmailAccountsRequest{}, Data: []*models.EmailAccount{}},
+		{Method: "GET", Path: apiPrefix + "/accounts/:id", ID: "GetEmailAccount", Tag: "Accounts", Summary: "获取邮件账户", Data: models.EmailAccount{}},
+		{Method: "PUT", Path: apiPrefix + "/accounts/:id", ID: "UpdateEmailAccount", Tag: "Accounts", Summary: "更新邮件账户",
+			Body: services.UpdateEmailAccountRequest{}, Data: models.EmailAccount{}},
+		{Method: "DELETE", Path: apiPrefix + "/accounts/:id", ID: "DeleteEmailAccount", Tag: "Accounts", Summary: "删除邮件账户"},
+		{Method: "POST", Path: apiPrefix + "/accounts/:id/test", ID: "TestEmailAccount", Tag: "Accounts", Summary: "测试账户连接，需要先完成邮箱设置时错误响应附带setup_guide"},
+		{Method: "POST", Path: apiPrefix + "/accounts/:id/sync", ID: "SyncEmailAccount", Tag: "Accounts", Summary: "同步账户邮件"},
+		{Method: "POST", Path: apiPrefix + "/accounts/:id/pause", ID: "PauseAccountSync", Tag: "Accounts", Summary: "暂停账户同步，等待进行中的同步退出", Data: models.EmailAccount{}},
+		{Method: "POST", Path: apiPrefix + "/accounts/:id/resume", ID: "ResumeAccountSync", Tag: "Accounts", Summary: "恢复账户同步", Data: models.EmailAccount{}},
+		{Method: "GET", Path: apiPrefix + "/accounts/:id/gmail-labels", ID: "GetGmailLabelMappings", Tag: "Accounts", Summary: "获取Gmail标签映射方式", Data: []services.GmailLabelMapping{}},
+		{Method: "PUT", Path: apiPrefix + "/accounts/:id/gmail-labels", ID: "UpdateGmailLabelMappings", Tag: "Accounts", Summary: "修改Gmail标签映射方式（作为文件夹、合并或跳过）",
+			Body: services.UpdateGmailLabelMappingsRequest{}, Data: []services.GmailLabelMapping{}},
+		{Method: "PUT", Path: apiPrefix + "/accounts/:id/mark-read", ID: "MarkAccountAsRead", Tag: "Accounts", Summary: "将账户邮件标记为已读"},
+		{Method: "GET", Path: apiPrefix + "/accounts/:id/storage/top", ID: "GetMailboxStorageReport", Tag: "Accounts", Summary: "获取最大邮件和附件、最早未读订阅邮件及发件人占用统计",
+			Query: services.StorageTopRequest{}, Data: services.MailboxStorageReport{}},
+		{Method: "POST", Path: apiPrefix + "/accounts/:id/storage/cleanup", ID: "StartStorageCleanup", Tag: "Accounts", Summary: "按条件批量清理邮件，返回202和后台任务",
+			Body: services.StartStorageCleanupRequest{}, Status: http.StatusAccepted, Data: services.StorageCleanupJob{}},
+		{Method: "GET", Path: apiPrefix + "/accounts/:id/storage/cleanup/:job_id", ID: "GetStorageCleanup", Tag: "Accounts", Summary: "获取邮箱清理任务状态",
+			Params: []*openapi.Parameter{openapi.PathParam("job_id", "string", "任务ID")}, Data: services.StorageCleanupJob{}},
+		{Method: "POST", Path: apiPrefix + "/accounts/:id/storage/cleanup/:job_id/cancel", ID: "CancelStorageCleanup", Tag: "Accounts", Summary: "取消邮箱清理任务",
+			Params: []*openapi.Parameter{openapi.PathParam("job_id", "string", "任务ID")}, Data: services.StorageCleanupJob{}},
+		{Method: "POST", Path: apiPrefix + "/accounts/batch/delete", ID: "BatchDeleteEmailAccounts", Tag: "Accounts", Summary: "批量删除账户", Body: BatchAccountRequest{}},
+		{Method: "POST", Path: apiPrefix + "/accounts/batch/sync", ID: "BatchSyncEmailAccounts", Tag: "Accounts", Summary: "批量同步账户", Body: BatchAccountRequest{}},
+		{Method: "POST", Path: apiPrefix + "/accounts/batch/mark-read", ID: "BatchMarkAccountsAsRead", Tag: "Accounts", Summary: "批量标记账户为已读", Body: BatchAccountRequest{}},
+
+		// 提供商
+		{Method: "GET", Path: apiPrefix + "/providers", ID: "GetProviders", Tag: "Providers", Summary: "获取支持的邮件提供商", Data: []ProviderInfo{}},
+		{Method: "GET", Path: apiPrefix + "/providers/detect", ID: "DetectProvider", Tag: "Providers", Summary: "根据邮箱地址检测提供商",
+			Params: []*openapi.Parameter{openapi.RequiredQueryParam("email", "string", "邮箱地址")}, Data: ProviderInfo{}},
+
+		// 邮件
+		{Method: "GET", Path: apiPrefix + "/emails", ID: "GetEmails", Tag: "Emails", Summary: "获取邮件列表",
+			Params: []*openapi.Parameter{
+				openapi.QueryParam("account_id", "integer", "按账户过滤"),
+				openapi.QueryParam("folder_id", "integer", "按文件夹过滤"),
+				openapi.QueryParam("is_read", "boolean", "按已读状态过滤"),
+				openapi.QueryParam("is_starred", "boolean", "按星标过滤"),
+				openapi.QueryParam("is_important", "boolean", "按重要标记过滤"),
+				openapi.QueryParam("importance_bucket", "string", "按优先收件箱分类过滤：important 或 other"),
+				openapi.QueryParam("is_vip", "boolean", "只看或排除VIP发件人的邮件"),
+				openapi.QueryParam("flag_color", "string", "按旗标颜色过滤：red、orange、yellow、green、blue、purple 或 gray"),
+				openapi.QueryParam("is_follow_up", "boolean", "按后续跟进旗标过滤"),
+				openapi.QueryParam("follow_up_due", "date-time", "只返回跟进截止时间不晚于该时间的邮件（RFC3339）"),
+				openapi.QueryParam("is_answered", "boolean", "按已回复状态过滤"),
+				openapi.QueryParam("awaiting_reply", "boolean", "只返回等待我回复的邮件：尚未回复，且会话中之后没有已发送的邮件"),
+				pageParam, pageSizeParam,
+				openapi.QueryParam("sort_by", "string", "排序字段，默认date"),
+				openapi.QueryParam("sort_order", "string", "asc 或 desc，默认desc"),
+				openapi.QueryParam("search", "string", "关键词过滤"),
+				cursorParam,
+				openapi.QueryParam("pinned_first", "boolean", "置顶邮件排在最前，不能与cursor同时使用"),
+				openapi.QueryParam("view", "string", "full（默认）返回不含正文的完整邮件；summary 只在 summaries 中返回预览、标记和头像等列表摘要"),
+				openapi.QueryParam("group_by", "string", "date 按用户时区的今天、昨天、近一周、近一月和更早分组，要求按日期排序；sender 按发件人分组，不能与cursor同时使用"),
+			}, Data: services.GetEmailsResponse{}},
+		{Method: "GET", Path: apiPrefix + "/emails/search", ID: "SearchEmails", Tag: "Emails", Summary: "搜索邮件",
+			Params: []*openapi.Parameter{
+				openapi.QueryParam("q", "string", "搜索关键词"),
+				openapi.QueryParam("subject", "string", "主题包含"),
+				openapi.QueryParam("from", "string", "发件人包含"),
+				openapi.QueryParam("to", "string", "收件人包含"),
+				openapi.QueryParam("body", "string", "正文包含"),
+				openapi.QueryParam("note", "string", "私有笔记包含"),
+				openapi.QueryParam("account_id", "integer", "按账户过滤"),
+				openapi.QueryParam("folder_id", "integer", "按文件夹过滤"),
+				openapi.QueryParam("has_attachment", "boolean", "是否有附件"),
+				openapi.QueryParam("is_read", "boolean", "按已读状态过滤"),
+				openapi.QueryParam("is_starred", "boolean", "按星标过滤"),
+				openapi.QueryParam("since", "date-time", "起始时间（RFC3339）"),
+				openapi.QueryParam("before", "date-time", "截止时间（RFC3339）"),
+				openapi.QueryParam("mode", "string", "keyword（默认）或 semantic（按含义匹配，需配置向量模型，未配置时返回503，不支持游标）"),
+				pageParam, pageSizeParam, cursorParam,
+			}, Data: services.GetEmailsResponse{}},
+		{Method: "GET", Path: apiPrefix + "/emails/:id", ID: "GetEmail", Tag: "Emails", Summary: "获取邮件详情", Data: models.Email{}},
+		{Method: "GET", Path: apiPrefix + "/emails/:id/navigation", ID: "GetEmailNavigation", Tag: "Emails", Summary: "获取邮件在列表中的上一封和下一封",
+			Params: []*openapi.Parameter{
+				openapi.QueryParam("account_id", "integer", "按账户过滤"),
+				openapi.QueryParam("folder_id", "integer", "按文件夹过滤"),
+				openapi.QueryParam("is_read", "boolean", "按已读状态过滤"),
+				openapi.QueryParam("is_starred", "boolean", "按星标过滤"),
+				openapi.QueryParam("is_important", "boolean", "按重要标记过滤"),
+				openapi.QueryParam("importance_bucket", "string", "按优先收件箱分类过滤：important 或 other"),
+				openapi.QueryParam("is_vip", "boolean", "只看或排除VIP发件人的邮件"),
+				openapi.QueryParam("flag_color", "string", "按旗标颜色过滤：red、orange、yellow、green、blue、purple 或 gray"),
+				openapi.QueryParam("is_follow_up", "boolean", "按后续跟进旗标过滤"),
+				openapi.QueryParam("follow_up_due", "date-time", "只返回跟进截止时间不晚于该时间的邮件（RFC3339）"),
+				openapi.QueryParam("is_answered", "boolean", "按已回复状态过滤"),
+				openapi.QueryParam("awaiting_reply", "boolean", "只返回等待我回复的邮件：尚未回复，且会话中之后没有已发送的邮件"),
+				openapi.QueryParam("sort_by", "string", "排序字段，默认date"),
+				openapi.QueryParam("sort_order", "string", "asc 或 desc，默认desc"),
+				openapi.QueryParam("search", "string", "关键词过滤"),
+				openapi.QueryParam("pinned_first", "boolean", "置顶邮件排在最前"),
+				openapi.QueryParam("group_by", "string", "与列表的分组方式一致，sender 时先按发件人排序"),
+			}, Data: services.EmailNavigation{}},
+		{Method: "GET", Path: apiPrefix + "/emails/:id/pdf", ID: "ExportEmailPDF", Tag: "Emails", Summary: "导出邮件PDF，可连同附件打包为zip",
+			Query: services.EmailPDFOptions{}, Raw: true, ContentType: "application/pdf"},
+		{Method: "GET", Path: apiPrefix + "/emails/:id/history", ID: "GetEmailHistory", Tag: "Emails", Summary: "获取邮件操作历史（同步、已读、移动、回复等）", Data: []*services.EmailHistoryEntry{}},
+		{Method: "GET", Path: apiPrefix + "/emails/:id/notes", ID: "GetEmailNotes", Tag: "Notes", Summary: "获取邮件的私有笔记", Data: []models.EmailNote{}},
+		{Method: "POST", Path: apiPrefix + "/emails/:id/notes", ID: "CreateEmailNote", Tag: "Notes", Summary: "添加私有笔记，可选通过IMAP ANNOTATE同步到服务器",
+			Body: services.CreateEmailNoteRequest{}, Status: http.StatusCreated, Data: models.EmailNote{}},
+		{Method: "PATCH", Path: apiPrefix + "/emails/:id/notes/:note_id", ID: "UpdateEmailNote", Tag: "Notes", Summary: "更新私有笔记",
+			Params: []*openapi.Parameter{openapi.PathParam("note_id", "integer", "笔记ID")}, Body: services.UpdateEmailNoteRequest{}, Data: models.EmailNote{}},
+		{Method: "DELETE", Path: apiPrefix + "/emails/:id/notes/:note_id", ID: "DeleteEmailNote", Tag: "Notes", Summary: "删除私有笔记",
+			Params: []*openapi.Parameter{openapi.PathParam("note_id", "integer", "笔记ID")}},
+		{Method: "PATCH", Path: apiPrefix + "/emails/:id", ID: "UpdateEmail", Tag: "Emails", Summary: "更新邮件状态", Body: UpdateEmailRequest{}, Data: models.Email{}},
+		{Method: "POST", Path: apiPrefix + "/emails/send", ID: "SendEmail", Tag: "Emails", Summary: "发送邮件；超过邮箱服务商大小上限时返回413，size_limit中给出邮件大小和上限，offload_large_attachments为true时把最大的附件改为正文中的下载链接", Body: services.SendEmailRequest{}},
+		{Method: "DELETE", Path: apiPrefix + "/emails/:id", ID: "DeleteEmail", Tag: "Emails", Summary: "删除邮件"},
+		{Method: "PUT", Path: apiPrefix + "/emails/:id/read", ID: "MarkEmailAsRead", Tag: "Emails", Summary: "标记为已读"},
+		{Method: "PUT", Path: apiPrefix + "/emails/:id/unread", ID: "MarkEmailAsUnread", Tag: "Emails", Summary: "标记为未读"},
+		{Method: "PUT", Path: apiPrefix + "/emails/:id/star", ID: "ToggleEmailStar", Tag: "Emails", Summary: "切换星标"},
+		{Method: "PUT", Path: apiPrefix + "/emails/:id/flag", ID: "SetEmailFlag", Tag: "Emails", Summary: "设置星标颜色和后续跟进旗标，尽量以IMAP关键字同步到服务器",
+			Body: services.SetEmailFlagRequest{}, Data: models.Email{}},
+		{Method: "PUT", Path: apiPrefix + "/emails/:id/pin", ID: "ToggleEmailPin", Tag: "Emails", Summary: "切换置顶，文件夹内置顶数量已满时返回409", Data: models.Email{}},
+		{Method: "PUT", Path: apiPrefix + "/emails/:id/reply-later", ID: "SetReplyLater", Tag: "Emails", Summary: "加入稍后回复，设置remind_at时到期后标记为未读并置顶，回复后自动移出",
+			Body: services.ReplyLaterRequest{}, Data: models.Email{}},
+		{Method: "DELETE", Path: apiPrefix + "/emails/:id/reply-later", ID: "ClearReplyLater", Tag: "Emails", Summary: "移出稍后回复", Data: models.Email{}},
+		{Method: "GET", Path: apiPrefix + "/emails/reply-later", ID: "GetReplyLaterEmails", Tag: "Emails", Summary: "获取稍后回复列表，先加入的在前",
+			Params: []*openapi.Parameter{
+				openapi.QueryParam("account_id", "integer", "按账户过滤"),
+				pageParam, pageSizeParam,
+			}, Data: services.GetEmailsResponse{}},
+		{Method: "GET", Path: apiPrefix + "/emails/verification-codes", ID: "GetVerificationCodes", Tag: "Emails", Summary: "获取最近收到的邮件中识别出的验证码，新邮件在前；新验证码同时通过verification_code事件推送",
+			Params: []*openapi.Parameter{
+				openapi.QueryParam("account_id", "integer", "按账户过滤"),
+				openapi.QueryParam("minutes", "integer", "查询最近多少分钟的邮件，默认30，最大1440"),
+			}, Data: []services.VerificationCode{}},
+		{Method: "PUT", Path: apiPrefix + "/emails/:id/mute-thread", ID: "MuteThread", Tag: "Emails", Summary: "静音邮件所在会话，后续会话邮件自动已读且不通知", Data: models.MutedThread{}},
+		{Method: "PUT", Path: apiPrefix + "/emails/:id/unmute-thread", ID: "UnmuteThread", Tag: "Emails", Summary: "取消静音邮件所在会话"},
+		{Method: "PUT", Path: apiPrefix + "/emails/:id/block-sender", ID: "BlockEmailSender", Tag: "Emails", Summary: "屏蔽邮件的发件人或其域名，并将邮件移入垃圾邮件或回收站",
+			Body: services.BlockEmailSenderRequest{}, Status: http.StatusCreated, Data: models.BlockedSender{}},
+		{Method: "GET", Path: apiPrefix + "/emails/muted-threads", ID: "GetMutedThreads", Tag: "Emails", Summary: "获取已静音的会话", Data: []models.MutedThread{}},
+		{Method: "DELETE", Path: apiPrefix + "/emails/muted-threads/:id", ID: "DeleteMutedThread", Tag: "Emails", Summary: "从静音列表中移除会话"},
+		{Method: "GET", Path: apiPrefix + "/emails/sync-conflicts", ID: "GetSyncConflicts", Tag: "Emails", Summary: "获取本地与服务器同时修改同一封邮件产生的同步冲突",
+			Params: []*openapi.Parameter{openapi.QueryParam("status", "string", "pending 或 resolved，为空时返回全部")}, Data: []models.SyncConflict{}},
+		{Method: "POST", Path: apiPrefix + "/emails/sync-conflicts/:id/resolve", ID: "ResolveSyncConflict", Tag: "Emails", Summary: "选择以服务器或本地状态处理同步冲突",
+			Body: services.ResolveSyncConflictRequest{}, Data: models.SyncConflict{}},
+		{Method: "PUT", Path: apiPrefix + "/emails/:id/priority/important", ID: "MarkEmailPriorityImportant", Tag: "Emails", Summary: "反馈为重要邮件，用于训练优先收件箱分类", Data: models.Email{}},
+		{Method: "PUT", Path: apiPrefix + "/emails/:id/priority/other", ID: "MarkEmailPriorityOther", Tag: "Emails", Summary: "反馈为非重要邮件，用于训练优先收件箱分类", Data: models.Email{}},
+		{Method: "PUT", Path: apiPrefix + "/emails/:id/move", ID: "MoveEmail", Tag: "Emails", Summary: "移动邮件，指定target_account_id或copy时跨账户移动/复制", Body: MoveEmailRequest{}, Data: services.EmailTransferJob{}},
+		{Method: "PUT", Path: apiPrefix + "/emails/:id/archive", ID: "ArchiveEmail", Tag: "Emails", Summary: "归档邮件"},
+		{Method: "POST", Path: apiPrefix + "/emails/:id/redecode", ID: "RedecodeEmail", Tag: "Emails", Summary: "使用指定字符集重新解码", Body: RedecodeEmailRequest{}, Data: models.Email{}},
+		{Method: "POST", Path: apiPrefix + "/emails/:id/reparse", ID: "ReparseEmail", Tag: "Emails", Summary: "从服务器源码重新解析", Data: models.Email{}},
+		{Method: "GET", Path: apiPrefix + "/emails/:id/source", ID: "GetEmailSource", Tag: "Emails", Summary: "查看邮件原始邮件头和MIME结构", Data: services.EmailSource{}},
+		{Method: "GET", Path: apiPrefix + "/emails/:id/headers/analysis", ID: "AnalyzeEmailHeaders", Tag: "Emails", Summary: "分析邮件投递链延迟、SPF/DKIM结果和来源IP", Data: services.HeaderAnalysis{}},
+		{Method: "POST", Path: apiPrefix + "/emails/:id/reply", ID: "ReplyEmail", Tag: "Emails", Summary: "回复邮件", Body: services.ReplyEmailRequest{}},
+		{Method: "POST", Path: apiPrefix + "/emails/:id/reply-all", ID: "ReplyAllEmail", Tag: "Emails", Summary: "回复全部", Body: services.ReplyEmailRequest{}},
+		{Method: "POST", Path: apiPrefix + "/emails/:id/forward", ID: "ForwardEmail", Tag: "Emails", Summary: "转发邮件", Body: services.ForwardEmailRequest{}},
+		{Method: "POST", Path: apiPrefix + "/emails/batch", ID: "BatchEmailOperations", Tag: "Emails", Summary: "批量邮件操作，跨账户批量移动/复制较多邮件时返回202和后台任务", Body: BatchEmailOperation{}, Data: BatchEmailResult{}},
+		{Method: "GET", Path: apiPrefix + "/emails/transfers/:job_id", ID: "GetEmailTransferJob", Tag: "Emails", Summary: "获取跨账户移动/复制任务状态",
+			Params: []*openapi.Parameter{openapi.PathParam("job_id", "string", "任务ID")}, Data: services.EmailTransferJob{}},
+		{Method: "POST", Path: apiPrefix + "/emails/transfers/:job_id/cancel", ID: "CancelEmailTransferJob", Tag: "Emails", Summary: "取消跨账户移动/复制任务",
+			Params: []*openapi.Parameter{openapi.PathParam("job_id", "string", "任务ID")}, Data: services.EmailTransferJob{}},
+		{Method: "POST", Path: apiPrefix + "/emails/duplicates/scans", ID: "StartDuplicateScan", Tag: "Emails", Summary: "启动重复邮件扫描任务",
+			Body: services.StartDuplicateScanRequest{}, Status: http.StatusAccepted, Data: services.DuplicateScanJob{}},
+		{Method: "GET", Path: apiPrefix + "/emails/duplicates/scans/:job_id", ID: "GetDuplicateScan", Tag: "Emails", Summary: "获取重复邮件扫描状态及分组结果",
+			Params: []*openapi.Parameter{openapi.PathParam("job_id", "string", "任务ID")}, Data: services.DuplicateScanJob{}},
+		{Method: "POST", Path: apiPrefix + "/emails/duplicates/scans/:job_id/cancel", ID: "CancelDuplicateScan", Tag: "Emails", Summary: "取消重复邮件扫描任务",
+			Params: []*openapi.Parameter{openapi.PathParam("job_id", "string", "任务ID")}, Data: services.DuplicateScanJob{}},
+		{Method: "POST", Path: apiPrefix + "/emails/duplicates/scans/:job_id/resolve", ID: "ResolveDuplicates", Tag: "Emails", Summary: "删除或移动重复邮件，每组保留一封",
+			Params: []*openapi.Parameter{openapi.PathParam("job_id", "string", "任务ID")}, Body: services.ResolveDuplicatesRequest{}, Data: services.ResolveDuplicatesResult{}},
+
+		// 草稿
+		{Method: "POST", Path: apiPrefix + "/emails/draft", ID: "SaveDraft", Tag: "Drafts", Summary: "保存草稿", Body: SaveDraftRequest{}, Status: http.StatusCreated, Data: models.Draft{}},
+		{Method: "PUT", Path: apiPrefix + "/emails/draft/:id", ID: "UpdateDraft", Tag: "Drafts", Summary: "更新草稿", Body: services.UpdateDraftRequest{}, Data: models.Draft{}},
+		{Method: "GET", Path: apiPrefix + "/emails/draft/:id", ID: "GetDraft", Tag: "Drafts", Summary: "获取草稿", Data: models.Draft{}},
+		{Method: "GET", Path: apiPrefix + "/emails/drafts", ID: "ListDrafts", Tag: "Drafts", Summary: "获取草稿列表", Query: services.ListDraftsRequest{}, Data: services.ListDraftsResponse{}},
+		{Method: "POST", Path: apiPrefix + "/emails/drafts/import", ID: "ImportDrafts", Tag: "Drafts", Summary: "导入服务器Drafts文件夹中的草稿", Body: ImportDraftsRequest{}, Data: ImportDraftsResult{}},
+		{Method: "DELETE", Path: apiPrefix + "/emails/draft/:id", ID: "DeleteDraft", Tag: "Drafts", Summary: "删除草稿"},
+		{Method: "PATCH", Path: apiPrefix + "/emails/draft/:id/autosave", ID: "AutosaveDraft", Tag: "Drafts", Summary: "自动保存草稿", Body: services.UpdateDraftRequest{}, Data: DraftAutosaveResult{}},
+		{Method: "GET", Path: apiPrefix + "/emails/draft/:id/revisions", ID: "ListDraftRevisions", Tag: "Drafts", Summary: "获取草稿修订历史", Data: []*models.DraftRevision{}},
+		{Method: "POST", Path: apiPrefix + "/emails/draft/:id/revisions/:revision_id/restore", ID: "RestoreDraftRevision", Tag: "Drafts", Summary: "恢复草稿修订", Data: models.Draft{}},
+		{Method: "POST", Path: apiPrefix + "/emails/markdown/preview", ID: "PreviewMarkdown", Tag: "Drafts", Summary: "预览Markdown正文渲染出的HTML和纯文本",
+			Body: services.MarkdownPreviewRequest{}, Data: services.MarkdownPreview{}},
+
+		// 模板
+		{Method: "POST", Path: apiPrefix + "/emails/template", ID: "CreateTemplate", Tag: "Templates", Summary: "创建邮件模板",
+			Body: services.CreateEmailTemplateRequest{}, Status: http.StatusCreated, Data: models.EmailTemplate{}},
+		{Method: "PUT", Path: apiPrefix + "/emails/template/:id", ID: "UpdateTemplate", Tag: "Templates", Summary: "更新邮件模板",
+			Body: services.UpdateEmailTemplateRequest{}, Data: models.EmailTemplate{}},
+		{Method: "GET", Path: apiPrefix + "/emails/template/:id", ID: "GetTemplate", Tag: "Templates", Summary: "获取邮件模板", Data: models.EmailTemplate{}},
+		{Method: "POST", Path: apiPrefix + "/emails/template/:id/preview", ID: "PreviewTemplate", Tag: "Templates", Summary: "预览模板渲染结果",
+			Body: services.TemplatePreviewRequest{}, Data: services.TemplatePreview{}},
+		{Method: "GET", Path: apiPrefix + "/emails/templates", ID: "ListTemplates", Tag: "Templates", Summary: "获取邮件模板列表",
+			Query: services.ListEmailTemplatesRequest{}, Data: services.ListEmailTemplatesResponse{}},
+		{Method: "DELETE", Path: apiPrefix + "/emails/template/:id", ID: "DeleteTemplate", Tag: "Templates", Summary: "删除邮件模板"},
+
+		// 回收站
+		{Method: "GET", Path: apiPrefix + "/trash", ID: "GetTrash", Tag: "Trash", Summary: "获取回收站中的邮件",
+			Params: []*openapi.Parameter{
+				openapi.QueryParam("account_id", "integer", "按账户过滤"),
+				pageParam, pageSizeParam,
+			}, Data: services.GetEmailsResponse{}},
+		{Method: "POST", Path: apiPrefix + "/trash/restore", ID: "RestoreTrashedEmails", Tag: "Trash", Summary: "从回收站恢复邮件",
+			Body: services.TrashEmailsRequest{}, Data: services.TrashOperationResponse{}},
+		{Method: "POST", Path: apiPrefix + "/trash/purge", ID: "PurgeTrashedEmails", Tag: "Trash", Summary: "彻底删除回收站中的邮件",
+			Body: services.TrashEmailsRequest{}, Data: services.TrashOperationResponse{}},
+		{Method: "DELETE", Path: apiPrefix + "/trash", ID: "EmptyTrash", Tag: "Trash", Summary: "清空回收站",
+			Params: []*openapi.Parameter{openapi.QueryParam("account_id", "integer", "只清空该账户")},
+			Data:   services.TrashOperationResponse{}},
+
+		// 邮件分享链接
+		{Method: "POST", Path: apiPrefix + "/emails/:id/share", ID: "CreateEmailShare", Tag: "Shares", Summary: "创建带有效期的公开分享链接",
+			Body: services.CreateEmailShareRequest{}, Status: http.StatusCreated, Data: services.EmailShareLink{}},
+		{Method: "GET", Path: apiPrefix + "/emails/:id/shares", ID: "GetEmailShares", Tag: "Shares", Summary: "获取邮件的分享链接", Data: []*services.EmailShareLink{}},
+		{Method: "PATCH", Path: apiPrefix + "/shares/:id", ID: "UpdateEmailShare", Tag: "Shares", Summary: "修改分享链接的附件访问权限",
+			Body: services.UpdateEmailShareRequest{}, Data: services.EmailShareLink{}},
+		{Method: "DELETE", Path: apiPrefix + "/shares/:id", ID: "RevokeEmailShare", Tag: "Shares", Summary: "撤销分享链接"},
+		{Method: "GET", Path: apiPrefix + "/shared/:token", ID: "ViewSharedEmail", Tag: "Shares", Summary: "查看分享的邮件（只读页面）",
+			Raw: true, ContentType: "text/html", Public: true},
+		{Method: "GET", Path: apiPrefix + "/shared/:token/attachments/:attachment_id", ID: "DownloadSharedAttachment", Tag: "Shares", Summary: "下载分享邮件的附件",
+			Raw: true, ContentType: openapi.ContentTypeBinary, Public: true},
+
+		// 文件夹
+		{Method: "GET", Path: apiPrefix + "/folders", ID: "GetFolders", Tag: "Folders", Summary: "获取文件夹列表",
+			Params: []*openapi.Parameter{openapi.RequiredQueryParam("account_id", "integer", "账户ID")}, Data: []*models.Folder{}},
+		{Method: "POST", Path: apiPrefix + "/folders", ID: "CreateFolder", Tag: "Folders", Summary: "创建文件夹",
+			Params: []*openapi.Parameter{openapi.RequiredQueryParam("account_id", "integer", "账户ID")},
+			Body:   services.CreateFolderRequest{}, Status: http.StatusCreated, Data: models.Folder{}},
+		{Method: "GET", Path: apiPrefix + "/folders/labels", ID: "GetLabels", Tag: "Folders", Summary: "获取标签及显示属性",
+			Params: []*openapi.Parameter{openapi.RequiredQueryParam("account_id", "integer", "账户ID")}, Data: []models.Label{}},
+		{Method: "PUT", Path: apiPrefix + "/folders/labels", ID: "UpdateLabelAppearance", Tag: "Folders", Summary: "设置标签显示属性",
+			Params: []*openapi.Parameter{openapi.RequiredQueryParam("account_id", "integer", "账户ID")},
+			Body:   services.UpdateLabelAppearanceRequest{}, Data: models.Label{}},
+		{Method: "DELETE", Path: apiPrefix + "/folders/labels/:id", ID: "DeleteLabelAppearance", Tag: "Folders", Summary: "删除标签显示属性"},
+		{Method: "POST", Path: apiPrefix + "/folders/reconcile-counts", ID: "ReconcileFolderCounts", Tag: "Folders", Summary: "重新统计文件夹邮件数和未读数",
+			Body: services.ReconcileFolderCountsRequest{}, Data: services.FolderCountReconciliation{}},
+		{Method: "GET", Path: apiPrefix + "/folders/:id", ID: "GetFolder", Tag: "Folders", Summary: "获取文件夹", Data: models.Folder{}},
+		{Method: "PUT", Path: apiPrefix + "/folders/:id", ID: "UpdateFolder", Tag: "Folders", Summary: "更新文件夹", Body: services.UpdateFolderRequest{}, Data: models.Folder{}},
+		{Method: "PUT", Path: apiPrefix + "/folders/:id/appearance", ID: "UpdateFolderAppearance", Tag: "Folders", Summary: "更新文件夹显示属性", Body: services.AppearanceRequest{}, Data: models.Folder{}},
+		{Method: "DELETE", Path: apiPrefix + "/folders/:id", ID: "DeleteFolder", Tag: "Folders", Summary: "删除文件夹"},
+		{Method: "PUT", Path: apiPrefix + "/folders/:id/mark-read", ID: "MarkFolderAsRead", Tag: "Folders", Summary: "将文件夹标记为已读"},
+		{Method: "PUT", Path: apiPrefix + "/folders/:id/sync", ID: "SyncFolder", Tag: "Folders", Summary: "同步文件夹"},
+
+		// 邮箱分组
+		{Method: "GET", Path: apiPrefix + "/groups", ID: "GetEmailGroups", Tag: "Groups", Summary: "获取邮箱分组", Data: []*models.EmailGroup{}},
+		{Method: "POST", Path: apiPrefix + "/groups", ID: "CreateEmailGroup", Tag: "Groups", Summary: "创建邮箱分组",
+			Body: services.CreateEmailGroupRequest{}, Status: http.StatusCreated, Data: models.EmailGroup{}},
+		{Method: "PUT", Path: apiPrefix + "/groups/reorder", ID: "ReorderEmailGroups", Tag: "Groups", Summary: "调整分组顺序", Body: ReorderEmailGroupsRequest{}, Data: []*models.EmailGroup{}},
+		{Method: "PUT", Path: apiPrefix + "/groups/:id", ID: "UpdateEmailGroup", Tag: "Groups", Summary: "更新邮箱分组", Body: services.UpdateEmailGroupRequest{}, Data: models.EmailGroup{}},
+		{Method: "PUT", Path: apiPrefix + "/groups/:id/default", ID: "SetDefaultEmailGroup", Tag: "Groups", Summary: "设为默认分组", Data: models.EmailGroup{}},
+		{Method: "DELETE", Path: apiPrefix + "/groups/:id", ID: "DeleteEmailGroup", Tag: "Groups", Summary: "删除邮箱分组"},
+
+		// 邮件合并
+		{Method: "GET", Path: apiPrefix + "/campaigns", ID: "GetMailMergeCampaigns", Tag: "Campaigns", Summary: "获取邮件合并活动列表", Data: []*models.MailMergeCampaign{}},
+		{Method: "POST", Path: apiPrefix + "/campaigns", ID: "CreateMailMergeCampaign", Tag: "Campaigns", Summary: "上传CSV收件人创建活动",
+			Form: services.CreateMailMergeCampaignRequest{}, Status: http.StatusCreated, Data: models.MailMergeCampaign{}},
+		{Method: "GET", Path: apiPrefix + "/campaigns/opt-outs", ID: "GetTrackingOptOuts", Tag: "Campaigns", Summary: "获取不跟踪名单", Data: []models.TrackingOptOut{}},
+		{Method: "POST", Path: apiPrefix + "/campaigns/opt-outs", ID: "AddTrackingOptOut", Tag: "Campaigns", Summary: "添加不跟踪的地址或域名",
+			Body: services.TrackingOptOutRequest{}, Status: http.StatusCreated, Data: models.TrackingOptOut{}},
+		{Method: "DELETE", Path: apiPrefix + "/campaigns/opt-outs/:id", ID: "DeleteTrackingOptOut", Tag: "Campaigns", Summary: "从不跟踪名单中移除"},
+		{Method: "GET", Path: apiPrefix + "/campaigns/:id", ID: "GetMailMergeCampaign", Tag: "Campaigns", Summary: "获取邮件合并活动", Data: models.MailMergeCampaign{}},
+		{Method: "GET", Path: apiPrefix + "/campaigns/:id/recipients", ID: "GetMailMergeRecipients", Tag: "Campaigns", Summary: "获取活动收件人",
+			Query: services.ListMailMergeRecipientsRequest{}, Data: services.ListMailMergeRecipientsResponse{}},
+		{Method: "GET", Path: apiPrefix + "/campaigns/:id/report", ID: "GetMailMergeCampaignReport", Tag: "Campaigns", Summary: "获取活动的打开和点击统计", Data: services.MailMergeCampaignReport{}},
+		{Method: "POST", Path: apiPrefix + "/campaigns/:id/start", ID: "StartMailMergeCampaign", Tag: "Campaigns", Summary: "开始发送", Data: models.MailMergeCampaign{}},
+		{Method: "POST", Path: apiPrefix + "/campaigns/:id/pause", ID: "PauseMailMergeCampaign", Tag: "Campaigns", Summary: "暂停发送", Data: models.MailMergeCampaign{}},
+		{Method: "POST", Path: apiPrefix + "/campaigns/:id/resume", ID: "ResumeMailMergeCampaign", Tag: "Campaigns", Summary: "恢复发送", Data: models.MailMergeCampaign{}},
+		{Method: "POST", Path: apiPrefix + "/campaigns/:id/cancel", ID: "CancelMailMergeCampaign", Tag: "Campaigns", Summary: "取消活动", Data: models.MailMergeCampaign{}},
+		{Method: "GET", Path: apiPrefix + "/t/o/:token", ID: "TrackMailMergeOpen", Tag: "Campaigns", Summary: "活动邮件的打开跟踪像素",
+			Raw: true, ContentType: "image/gif", Public: true},
+		{Method: "GET", Path: apiPrefix + "/t/c/:token/:link_id", ID: "TrackMailMergeClick", Tag: "Campaigns", Summary: "记录点击并跳转到原始链接",
+			Raw: true, Status: http.StatusFound, ContentType: "text/html", Public: true},
+
+		// 邮箱迁移
+		{Method: "GET", Path: apiPrefix + "/migrations", ID: "GetMailboxMigrations", Tag: "Migrations", Summary: "获取邮箱迁移列表", Data: []*models.MailboxMigration{}},
+		{Method: "POST", Path: apiPrefix + "/migrations", ID: "CreateMailboxMigration", Tag: "Migrations", Summary: "创建邮箱迁移并开始复制",
+			Body: services.CreateMailboxMigrationRequest{}, Status: http.StatusCreated, Data: models.MailboxMigration{}},
+		{Method: "GET", Path: apiPrefix + "/migrations/plan", ID: "PlanMailboxMigration", Tag: "Migrations", Summary: "生成默认文件夹映射",
+			Query: MailboxMigrationPlanQuery{}, Data: []services.MailboxMigrationFolderMapping{}},
+		{Method: "GET", Path: apiPrefix + "/migrations/:id", ID: "GetMailboxMigration", Tag: "Migrations", Summary: "获取邮箱迁移进度", Data: models.MailboxMigration{}},
+		{Method: "GET", Path: apiPrefix + "/migrations/:id/report", ID: "GetMailboxMigrationReport", Tag: "Migrations", Summary: "获取迁移核对报告", Data: services.MailboxMigrationReport{}},
+		{Method: "POST", Path: apiPrefix + "/migrations/:id/pause", ID: "PauseMailboxMigration", Tag: "Migrations", Summary: "暂停迁移", Data: models.MailboxMigration{}},
+		{Method: "POST", Path: apiPrefix + "/migrations/:id/resume", ID: "ResumeMailboxMigration", Tag: "Migrations", Summary: "从断点继续迁移", Data: models.MailboxMigration{}},
+		{Method: "POST", Path: apiPrefix + "/migrations/:id/cancel", ID: "CancelMailboxMigration", Tag: "Migrations", Summary: "取消迁移", Data: models.MailboxMigration{}},
+
+		// VIP发件人
+		{Method: "GET", Path: apiPrefix + "/vip-senders", ID: "GetVIPSenders", Tag: "VIP", Summary: "获取VIP发件人列表", Data: []models.VIPSender{}},
+		{Method: "POST", Path: apiPrefix + "/vip-senders", ID: "AddVIPSender", Tag: "VIP", Summary: "添加VIP发件人，并标记其已有邮件",
+			Body: services.AddVIPSenderRequest{}, Status: http.StatusCreated, Data: models.VIPSender{}},
+		{Method: "DELETE", Path: apiPrefix + "/vip-senders/:id", ID: "RemoveVIPSender", Tag: "VIP", Summary: "移除VIP发件人"},
+
+		// 屏蔽发件人
+		{Method: "GET", Path: apiPrefix + "/blocked-senders", ID: "GetBlockedSenders", Tag: "Blocked", Summary: "获取屏蔽发件人列表", Data: []models.BlockedSender{}},
+		{Method: "POST", Path: apiPrefix + "/blocked-senders", ID: "AddBlockedSender", Tag: "Blocked", Summary: "屏蔽邮箱地址或域名，之后同步到的邮件自动移入回收站或垃圾邮件",
+			Body: services.BlockedSenderRequest{}, Status: http.StatusCreated, Data: models.BlockedSender{}},
+		{Method: "GET", Path: apiPrefix + "/blocked-senders/export", ID: "ExportBlockedSenders", Tag: "Blocked", Summary: "导出屏蔽发件人列表", Data: services.BlockedSenderExport{}},
+		{Method: "POST", Path: apiPrefix + "/blocked-senders/import", ID: "ImportBlockedSenders", Tag: "Blocked", Summary: "导入屏蔽发件人列表",
+			Body: services.ImportBlockedSendersRequest{}, Data: services.ImportBlockedSendersResult{}},
+		{Method: "PUT", Path: apiPrefix + "/blocked-senders/:id", ID: "UpdateBlockedSender", Tag: "Blocked", Summary: "修改屏蔽发件人的处理方式",
+			Body: services.UpdateBlockedSenderRequest{}, Data: models.BlockedSender{}},
+		{Method: "DELETE", Path: apiPrefix + "/blocked-senders/:id", ID: "DeleteBlockedSender", Tag: "Blocked", Summary: "取消屏蔽发件人"},
+
+		// 邮件保留规则
+		{Method: "GET", Path: apiPrefix + "/retention-policies", ID: "GetRetentionPolicies", Tag: "Retention", Summary: "获取邮件保留规则列表", Data: []models.RetentionPolicy{}},
+		{Method: "POST", Path: apiPrefix + "/retention-policies", ID: "CreateRetentionPolicy", Tag: "Retention", Summary: "创建保留规则，定时删除或归档超过指定天数的邮件",
+			Body: services.RetentionPolicyRequest{}, Status: http.StatusCreated, Data: models.RetentionPolicy{}},
+		{Method: "GET", Path: apiPrefix + "/retention-policies/runs", ID: "GetRetentionRuns", Tag: "Retention", Summary: "获取保留规则的执行记录",
+			Query: services.ListRetentionRunsRequest{}, Data: []models.RetentionRun{}},
+		{Method: "PUT", Path: apiPrefix + "/retention-policies/:id", ID: "UpdateRetentionPolicy", Tag: "Retention", Summary: "修改保留规则",
+			Body: services.RetentionPolicyRequest{}, Data: models.RetentionPolicy{}},
+		{Method: "DELETE", Path: apiPrefix + "/retention-policies/:id", ID: "DeleteRetentionPolicy", Tag: "Retention", Summary: "删除保留规则及其执行记录"},
+		{Method: "GET", Path: apiPrefix + "/retention-policies/:id/preview", ID: "PreviewRetentionPolicy", Tag: "Retention", Summary: "试运行保留规则，返回会被处理的邮件数量和样例", Data: services.RetentionPreview{}},
+		{Method: "POST", Path: apiPrefix + "/retention-policies/:id/run", ID: "RunRetentionPolicy", Tag: "Retention", Summary: "立即执行保留规则，返回202和执行记录",
+			Status: http.StatusAccepted, Data: models.RetentionRun{}},
+
+		// 代收外部邮箱
+		{Method: "GET", Path: apiPrefix + "/mail-fetchers", ID: "GetMailFetchers", Tag: "MailFetcher", Summary: "获取代收外部邮箱列表", Data: []models.MailFetcher{}},
+		{Method: "POST", Path: apiPrefix + "/mail-fetchers", ID: "CreateMailFetcher", Tag: "MailFetcher", Summary: "创建代收，定时从外部POP3/IMAP邮箱收取邮件到账户的文件夹",
+			Body: services.MailFetcherRequest{}, Status: http.StatusCreated, Data: models.MailFetcher{}},
+		{Method: "PUT", Path: apiPrefix + "/mail-fetchers/:id", ID: "UpdateMailFetcher", Tag: "MailFetcher", Summary: "修改代收，密码为空时保留原密码",
+			Body: services.MailFetcherRequest{}, Data: models.MailFetcher{}},
+		{Method: "DELETE", Path: apiPrefix + "/mail-fetchers/:id", ID: "DeleteMailFetcher", Tag: "MailFetcher", Summary: "删除代收，已收取的邮件保留"},
+		{Method: "POST", Path: apiPrefix + "/mail-fetchers/:id/run", ID: "RunMailFetcher", Tag: "MailFetcher", Summary: "立即执行一次代收并返回收取结果", Data: services.MailFetchResult{}},
+
+		// 自动转发规则
+		{Method: "GET", Path: apiPrefix + "/forwarding-rules", ID: "GetForwardingRules", Tag: "Forwarding", Summary: "获取自动转发规则列表及转发统计", Data: []models.ForwardingRule{}},
+		{Method: "POST", Path: apiPrefix + "/forwarding-rules", ID: "CreateForwardingRule", Tag: "Forwarding", Summary: "创建自动转发规则，收件箱中匹配的新邮件通过账户的SMTP转发到外部地址",
+			Body: services.ForwardingRuleRequest{}, Status: http.StatusCreated, Data: models.ForwardingRule{}},
+		{Method: "PUT", Path: apiPrefix + "/forwarding-rules/:id", ID: "UpdateForwardingRule", Tag: "Forwarding", Summary: "修改自动转发规则，包括启用和停用",
+			Body: services.ForwardingRuleRequest{}, Data: models.ForwardingRule{}},
+		{Method: "DELETE", Path: apiPrefix + "/forwarding-rules/:id", ID: "DeleteForwardingRule", Tag: "Forwarding", Summary: "删除自动转发规则"},
+
+		{Method: "GET", Path: apiPrefix + "/canned-responses", ID: "GetCannedResponses", Tag: "CannedResponses", Summary: "获取快捷回复列表", Data: []models.CannedResponse{}},
+		{Method: "GET", Path: apiPrefix + "/canned-responses/quick", ID: "GetCannedQuickList", Tag: "CannedResponses", Summary: "撰写邮件时使用的快捷回复精简列表，关联当前文件夹的排在前面，其余按使用次数排序",
+			Query: services.CannedQuickListRequest{}, Data: []services.CannedQuickItem{}},
+		{Method: "POST", Path: apiPrefix + "/canned-responses", ID: "CreateCannedResponse", Tag: "CannedResponses", Summary: "创建快捷回复",
+			Body: services.CannedResponseRequest{}, Status: http.StatusCreated, Data: models.CannedResponse{}},
+		{Method: "PUT", Path: apiPrefix + "/canned-responses/:id", ID: "UpdateCannedResponse", Tag: "CannedResponses", Summary: "修改快捷回复",
+			Body: services.CannedResponseRequest{}, Data: models.CannedResponse{}},
+		{Method: "DELETE", Path: apiPrefix + "/canned-responses/:id", ID: "DeleteCannedResponse", Tag: "CannedResponses", Summary: "删除快捷回复"},
+		{Method: "POST", Path: apiPrefix + "/canned-responses/:id/render", ID: "RenderCannedResponse", Tag: "CannedResponses", Summary: "代入变量渲染快捷回复并计入使用次数，提供email_id时可以使用发件人和主题变量",
+			Body: services.RenderCannedResponseRequest{}, Data: services.RenderedCannedResponse{}},
+
+		{Method: "GET", Path: apiPrefix + "/legal-holds", ID: "GetLegalHolds", Tag: "LegalHold", Summary: "获取法律保全列表", Data: []models.LegalHold{}},
+		{Method: "POST", Path: apiPrefix + "/legal-holds", ID: "CreateLegalHold", Tag: "LegalHold", Summary: "创建法律保全，符合发件人和日期条件的邮件在解除前不能删除",
+			Body: services.CreateLegalHoldRequest{}, Status: http.StatusCreated, Data: models.LegalHold{}},
+		{Method: "GET", Path: apiPrefix + "/legal-holds/:id", ID: "GetLegalHold", Tag: "LegalHold", Summary: "获取法律保全及覆盖的邮件数", Data: services.LegalHoldDetail{}},
+		{Method: "POST", Path: apiPrefix + "/legal-holds/:id/release", ID: "ReleaseLegalHold", Tag: "LegalHold", Summary: "解除法律保全", Data: models.LegalHold{}},
+		{Method: "POST", Path: apiPrefix + "/legal-holds/:id/exports", ID: "CreateLegalHoldExport", Tag: "LegalHold", Summary: "在后台导出保全覆盖的邮件原文和带哈希的签名清单，返回202",
+			Status: http.StatusAccepted, Data: models.LegalHoldExport{}},
+		{Method: "GET", Path: apiPrefix + "/legal-holds/:id/exports", ID: "GetLegalHoldExports", Tag: "LegalHold", Summary: "获取法律保全的导出列表", Data: []models.LegalHoldExport{}},
+		{Method: "GET", Path: apiPrefix + "/legal-holds/:id/exports/:export_id", ID: "GetLegalHoldExport", Tag: "LegalHold", Summary: "获取导出进度和结果",
+			Params: []*openapi.Parameter{openapi.PathParam("export_id", "integer", "导出ID")}, Data: models.LegalHoldExport{}},
+		{Method: "GET", Path: apiPrefix + "/legal-holds/:id/exports/:export_id/download", ID: "DownloadLegalHoldExport", Tag: "LegalHold", Summary: "下载已完成的导出压缩包",
+			Params: []*openapi.Parameter{openapi.PathParam("export_id", "integer", "导出ID")}, Raw: true, ContentType: "application/zip"},
+		{Method: "POST", Path: apiPrefix + "/legal-holds/:id/exports/:export_id/verify", ID: "VerifyLegalHoldExport", Tag: "LegalHold", Summary: "重新计算导出文件的哈希并校验清单签名",
+			Params: []*openapi.Parameter{openapi.PathParam("export_id", "integer", "导出ID")}, Data: services.LegalHoldExportVerification{}},
+
+		// 入站邮件
+		{Method: "GET", Path: apiPrefix + "/ingest-endpoints", ID: "GetIngestEndpoints", Tag: "Ingest", Summary: "获取入站邮件端点列表", Data: []models.IngestEndpoint{}},
+		{Method: "POST", Path: apiPrefix + "/ingest-endpoints", ID: "CreateIngestEndpoint", Tag: "Ingest", Summary: "创建入站邮件端点，未指定账户时创建虚拟账户，令牌只返回一次",
+			Body: services.CreateIngestEndpointRequest{}, Status: http.StatusCreated, Data: services.IngestEndpointWithToken{}},
+		{Method: "PATCH", Path: apiPrefix + "/ingest-endpoints/:id", ID: "UpdateIngestEndpoint", Tag: "Ingest", Summary: "修改入站端点的名称、SMTP收件地址或启用状态",
+			Body: services.UpdateIngestEndpointRequest{}, Data: models.IngestEndpoint{}},
+		{Method: "DELETE", Path: apiPrefix + "/ingest-endpoints/:id", ID: "DeleteIngestEndpoint", Tag: "Ingest", Summary: "删除入站端点，已接收的邮件保留"},
+		{Method: "POST", Path: apiPrefix + "/ingest-endpoints/:id/rotate-token", ID: "RotateIngestToken", Tag: "Ingest", Summary: "重新生成入站令牌，旧令牌立即失效",
+			Data: services.IngestEndpointWithToken{}},
+		{Method: "POST", Path: apiPrefix + "/ingest", ID: "IngestEmail", Tag: "Ingest", Summary: "外部服务推送邮件，凭Bearer或X-Ingest-Token令牌认证；也接受原始MIME和带body-mime字段的表单，重复投递返回200",
+			Params: []*openapi.Parameter{openapi.QueryParam("token", "string", "入站令牌，无法设置请求头时使用")},
+			Body:   services.IngestMessageRequest{}, Status: http.StatusCreated, Data: services.IngestResult{}, Public: true},
+
+		// 用户设置
+		{Method: "GET", Path: apiPrefix + "/settings", ID: "GetSettings", Tag: "Settings", Summary: "获取当前用户的设置，未设置的项为服务器配置的默认值", Data: services.UserSettings{}},
+		{Method: "PATCH", Path: apiPrefix + "/settings", ID: "UpdateSettings", Tag: "Settings", Summary: "修改当前用户的设置，只修改提供的字段，返回全部设置",
+			Body: services.UpdateUserSettingsRequest{}, Data: services.UserSettings{}},
+
+		// 组织与共享邮箱
+		{Method: "GET", Path: apiPrefix + "/organizations", ID: "GetOrganizations", Tag: "Organization", Summary: "获取当前用户加入的组织", Data: []services.OrganizationSummary{}},
+		{Method: "POST", Path: apiPrefix + "/organizations", ID: "CreateOrganization", Tag: "Organization", Summary: "创建组织，创建者成为所有者",
+			Body: services.CreateOrganizationRequest{}, Status: http.StatusCreated, Data: models.Organization{}},
+		{Method: "GET", Path: apiPrefix + "/organizations/:id", ID: "GetOrganization", Tag: "Organization", Summary: "获取组织成员和共享邮箱，管理员还返回待处理的邀请和授权",
+			Data: services.OrganizationDetail{}},
+		{Method: "DELETE", Path: apiPrefix + "/organizations/:id", ID: "DeleteOrganization", Tag: "Organization", Summary: "删除组织及其共享、授权和认领，仅所有者可以操作"},
+		{Method: "POST", Path: apiPrefix + "/organizations/:id/invites", ID: "InviteOrganizationMember", Tag: "Organization", Summary: "按用户名邀请成员，邀请7天内有效",
+			Body: services.InviteOrganizationMemberRequest{}, Status: http.StatusCreated, Data: models.OrganizationInvite{}},
+		{Method: "DELETE", Path: apiPrefix + "/organizations/:id/invites/:invite_id", ID: "RevokeOrganizationInvite", Tag: "Organization", Summary: "撤销待处理的邀请",
+			Params: []*openapi.Parameter{openapi.PathParam("invite_id", "integer", "邀请ID")}},
+		{Method: "PATCH", Path: apiPrefix + "/organizations/:id/members/:user_id", ID: "UpdateOrganizationMember", Tag: "Organization", Summary: "修改成员角色，管理员的任免只能由所有者进行",
+			Params: []*openapi.Parameter{openapi.PathParam("user_id", "integer", "成员用户ID")}, Body: services.UpdateOrganizationMemberRequest{}, Data: models.OrganizationMember{}},
+		{Method: "DELETE", Path: apiPrefix + "/organizations/:id/members/:user_id", ID: "RemoveOrganizationMember", Tag: "Organization", Summary: "移除成员或退出组织，同时撤销授权并释放认领的邮件",
+			Params: []*openapi.Parameter{openapi.PathParam("user_id", "integer", "成员用户ID")}},
+		{Method: "POST", Path: apiPrefix + "/organizations/:id/mailboxes", ID: "ShareOrganizationMailbox", Tag: "Organization", Summary: "将自己的邮箱账户共享到组织，需要是组织管理员",
+			Body: services.ShareMailboxRequest{}, Status: http.StatusCreated, Data: models.SharedMailbox{}},
+		{Method: "DELETE", Path: apiPrefix + "/organizations/:id/mailboxes/:mailbox_id", ID: "UnshareOrganizationMailbox", Tag: "Organization", Summary: "取消共享邮箱",
+			Params: []*openapi.Parameter{openapi.PathParam("mailbox_id", "integer", "共享邮箱ID")}},
+		{Method: "PUT", Path: apiPrefix + "/organizations/:id/mailboxes/:mailbox_id/grants", ID: "SetSharedMailboxGrant", Tag: "Organization", Summary: "授予或修改成员的共享邮箱权限（read_only、send_as）",
+			Params: []*openapi.Parameter{openapi.PathParam("mailbox_id", "integer", "共享邮箱ID")}, Body: services.SharedMailboxGrantRequest{}, Data: models.SharedMailboxGrant{}},
+		{Method: "DELETE", Path: apiPrefix + "/organizations/:id/mailboxes/:mailbox_id/grants/:user_id", ID: "RevokeSharedMailboxGrant", Tag: "Organization", Summary: "撤销成员的共享邮箱权限并释放其认领的邮件",
+			Params: []*openapi.Parameter{openapi.PathParam("mailbox_id", "integer", "共享邮箱ID"), openapi.PathParam("user_id", "integer", "成员用户ID")}},
+		{Method: "GET", Path: apiPrefix + "/organization-invites", ID: "GetOrganizationInvites", Tag: "Organization", Summary: "获取当前用户收到的待处理邀请", Data: []models.OrganizationInvite{}},
+		{Method: "POST", Path: apiPrefix + "/organization-invites/:id/accept", ID: "AcceptOrganizationInvite", Tag: "Organization", Summary: "接受组织邀请", Data: models.OrganizationInvite{}},
+		{Method: "POST", Path: apiPrefix + "/organization-invites/:id/decline", ID: "DeclineOrganizationInvite", Tag: "Organization", Summary: "拒绝组织邀请", Data: models.OrganizationInvite{}},
+		{Method: "GET", Path: apiPrefix + "/shared-mailboxes", ID: "GetSharedMailboxes", Tag: "Organization", Summary: "获取当前用户可以访问的共享邮箱及权限", Data: []services.SharedMailboxInfo{}},
+		{Method: "GET", Path: apiPrefix + "/shared-mailboxes/:id/emails", ID: "GetSharedMailboxEmails", Tag: "Organization", Summary: "分页获取共享邮箱中的邮件及认领状态",
+			Query: services.ListSharedEmailsRequest{}, Data: services.ListSharedEmailsResponse{}},
+		{Method: "GET", Path: apiPrefix + "/shared-mailboxes/:id/emails/:email_id", ID: "GetSharedMailboxEmail", Tag: "Organization", Summary: "获取共享邮箱中的邮件，不改变已读状态",
+			Params: []*openapi.Parameter{openapi.PathParam("email_id", "integer", "邮件ID")}, Data: services.SharedMailboxEmail{}},
+		{Method: "POST", Path: apiPrefix + "/shared-mailboxes/:id/emails/:email_id/claim", ID: "ClaimSharedMailboxEmail", Tag: "Organization", Summary: "认领邮件，已被其他成员认领时返回409",
+			Params: []*openapi.Parameter{openapi.PathParam("email_id", "integer", "邮件ID")}, Data: models.EmailAssignment{}},
+		{Method: "POST", Path: apiPrefix + "/shared-mailboxes/:id/emails/:email_id/assign", ID: "AssignSharedMailboxEmail", Tag: "Organization", Summary: "将邮件分配给有权限的成员，需要是组织管理员",
+			Params: []*openapi.Parameter{openapi.PathParam("email_id", "integer", "邮件ID")}, Body: services.AssignEmailRequest{}, Data: models.EmailAssignment{}},
+		{Method: "DELETE", Path: apiPrefix + "/shared-mailboxes/:id/emails/:email_id/assignment", ID: "ReleaseSharedMailboxEmail", Tag: "Organization", Summary: "释放认领，负责人或组织管理员可以操作",
+			Params: []*openapi.Parameter{openapi.PathParam("email_id", "integer", "邮件ID")}},
+
+		{Method: "GET", Path: apiPrefix + "/analytics/volume", ID: "GetEmailVolume", Tag: "Analytics", Summary: "按时间段统计收发邮件数",
+			Query: services.AnalyticsQuery{}, Data: []services.AnalyticsVolumePoint{}},
+		{Method: "GET", Path: apiPrefix + "/analytics/busiest-hours", ID: "GetBusiestHours", Tag: "Analytics", Summary: "按小时和星期统计收发邮件数",
+			Query: services.AnalyticsQuery{}, Data: services.AnalyticsBusiestHours{}},
+		{Method: "GET", Path: apiPrefix + "/analytics/top-senders", ID: "GetTopSenders", Tag: "Analytics", Summary: "来信最多的发件人",
+			Query: services.AnalyticsQuery{}, Data: []services.SenderVolume{}},
+		{Method: "GET", Path: apiPrefix + "/analytics/top-recipients", ID: "GetTopRecipients", Tag: "Analytics", Summary: "发信最多的收件人",
+			Query: services.AnalyticsQuery{}, Data: []services.AnalyticsRecipient{}},
+		{Method: "GET", Path: apiPrefix + "/analytics/response-times", ID: "GetResponseTimes", Tag: "Analytics", Summary: "回复邮件所用时间统计",
+			Query: services.AnalyticsQuery{}, Data: services.AnalyticsResponseTimes{}},
+		{Method: "GET", Path: apiPrefix + "/analytics/outbound/messages", ID: "GetOutboundMessages", Tag: "Analytics", Summary: "发出的邮件及其回复情况",
+			Params: []*openapi.Parameter{openapi.QueryParam("unreplied", "boolean", "只返回未收到回复的邮件")},
+			Query:  services.AnalyticsQuery{}, Data: []services.AnalyticsOutboundMessage{}},
+		{Method: "GET", Path: apiPrefix + "/analytics/outbound/accounts", ID: "GetOutboundAccounts", Tag: "Analytics", Summary: "按账户统计发出邮件的回复率和回复用时",
+			Query: services.AnalyticsQuery{}, Data: []services.AnalyticsOutboundAccount{}},
+		{Method: "GET", Path: apiPrefix + "/analytics/outbound/contacts", ID: "GetOutboundContacts", Tag: "Analytics", Summary: "按联系人统计发出邮件的回复率和回复用时",
+			Query: services.AnalyticsQuery{}, Data: []services.AnalyticsOutboundContact{}},
+		{Method: "POST", Path: apiPrefix + "/analytics/rebuild", ID: "RebuildAnalytics", Tag: "Analytics", Summary: "根据现有邮件重建收发统计",
+			Body: services.RebuildVolumeStatsRequest{}},
+
+		// 增量变更
+		{Method: "GET", Path: apiPrefix + "/changes", ID: "GetChanges", Tag: "Changes", Summary: "获取令牌之后的增量变更",
+			Params: []*openapi.Parameter{
+				openapi.QueryParam("since", "integer", "上次获取的变更令牌"),
+				openapi.QueryParam("limit", "integer", "最多返回的变更数"),
+			}, Data: services.ChangesResponse{}},
+
+		// 管理
+		{Method: "POST", Path: apiPrefix + "/admin/reparse", ID: "StartReparseJob", Tag: "Admin", Summary: "启动批量重新解析任务",
+			Body: services.StartReparseJobRequest{}, Status: http.StatusAccepted, Data: services.ReparseJob{}},
+		{Method: "GET", Path: apiPrefix + "/admin/reparse/:job_id", ID: "GetReparseJob", Tag: "Admin", Summary: "获取重新解析任务状态",
+			Params: []*openapi.Parameter{openapi.PathParam("job_id", "string", "任务ID")}, Data: services.ReparseJob{}},
+		{Method: "POST", Path: apiPrefix + "/admin/reparse/:job_id/cancel", ID: "CancelReparseJob", Tag: "Admin", Summary: "取消重新解析任务",
+			Params: []*openapi.Parameter{openapi.PathParam("job_id", "string", "任务ID")}, Data: services.ReparseJob{}},
+		{Method: "GET", Path: apiPrefix + "/admin/config", ID: "GetConfig", Tag: "Admin", Summary: "查看生效配置（已脱敏）", Data: ConfigView{}},
+		{Method: "GET", Path: apiPrefix + "/admin/security-events", ID: "GetSecurityEvents", Tag: "Admin", Summary: "查看安全事件审计日志",
+			Params: []*openapi.Parameter{
+				openapi.QueryParam("type", "string", "事件类型，如 ip_denied、auth_failed，为空时返回全部"),
+				openapi.QueryParam("ip", "string", "来源IP"),
+				openapi.QueryParam("since", "string", "起始时间，RFC3339格式"),
+				openapi.QueryParam("limit", "integer", "返回条数，默认100，最多1000"),
+			}, Data: []models.SecurityEvent{}},
+		{Method: "GET", Path: apiPrefix + "/admin/auth-failures", ID: "GetAuthFailures", Tag: "Admin", Summary: "按IP汇总最近的登录失败并给出封禁建议",
+			Params: []*openapi.Parameter{
+				openapi.QueryParam("window", "string", "统计窗口，如 30m、24h，默认使用 AUTH_FAILURE_BAN_WINDOW"),
+			}, Data: AuthFailuresResponse{}},
+		{Method: "GET", Path: apiPrefix + "/admin/send-queue/stuck", ID: "GetStuckSends", Tag: "Admin", Summary: "查看发送队列中超过阈值仍未发出的邮件",
+			Params: []*openapi.Parameter{
+				openapi.QueryParam("older_than", "string", "卡住判定时长，如 15m、2h，默认15m"),
+			}, Data: []services.StuckSend{}},
+		{Method: "GET", Path: apiPrefix + "/admin/jobs", ID: "ListJobs", Tag: "Admin", Summary: "列出后台任务及各状态的任务数",
+			Params: []*openapi.Parameter{
+				openapi.QueryParam("status", "string", "任务状态 pending/running/succeeded/dead/cancelled，为空时返回全部"),
+				openapi.QueryParam("type", "string", "任务类型，如 sync.account、send.deliver"),
+				openapi.QueryParam("limit", "integer", "返回条数，默认100，最多1000"),
+			}, Data: services.JobList{}},
+		{Method: "GET", Path: apiPrefix + "/admin/jobs/:id", ID: "GetJob", Tag: "Admin", Summary: "获取后台任务详情", Data: models.Job{}},
+		{Method: "POST", Path: apiPrefix + "/admin/jobs/:id/retry", ID: "RetryJob", Tag: "Admin", Summary: "重新执行死信或已取消的任务", Data: models.Job{}},
+		{Method: "POST", Path: apiPrefix + "/admin/jobs/:id/cancel", ID: "CancelJob", Tag: "Admin", Summary: "取消等待执行的任务", Data: models.Job{}},
+
+		// 附件
+		{Method: "GET", Path: apiPrefix + "/attachments", ID: "ListAttachments", Tag: "Attachments", Summary: "跨邮件列出附件，可按账户、类型、时间和大小筛选",
+			Query: services.ListAttachmentsRequest{}, Data: services.AttachmentListResponse{}},
+		{Method: "POST", Path: apiPrefix + "/attachments/download-zip", ID: "DownloadAttachmentsZip", Tag: "Attachments", Summary: "将选中的附件打包为ZIP下载",
+			Body: services.DownloadAttachmentsZipRequest{}, Raw: true, ContentType: "application/zip"},
+		{Method: "POST", Path: apiPrefix + "/attachments/upload", ID: "UploadAttachment", Tag: "Attachments", Summary: "上传附件",
+			Form: struct{}{}, Status: http.StatusCreated, Data: AttachmentUploadResult{}},
+		{Method: "GET", Path: apiPrefix + "/attachments/:id/download", ID: "DownloadAttachment", Tag: "Attachments", Summary: "下载附件",
+			Raw: true, ContentType: openapi.ContentTypeBinary},
+		{Method: "GET", Path: apiPrefix + "/attachments/:id/preview", ID: "PreviewAttachment", Tag: "Attachments", Summary: "预览附件", Data: services.AttachmentPreview{}},
+		{Method: "POST", Path: apiPrefix + "/attachments/:id/token", ID: "CreateAttachmentToken", Tag: "Attachments", Summary: "签发单个附件的短期下载链接", Data: services.AttachmentToken{}},
+		{Method: "GET", Path: apiPrefix + "/attachments/:id/file", ID: "DownloadAttachmentWithToken", Tag: "Attachments", Summary: "通过签名链接下载附件，无需登录令牌",
+			Params: []*openapi.Parameter{openapi.QueryParam("token", "string", "附件下载令牌")}, Public: true, Raw: true, ContentType: openapi.ContentTypeBinary},
+		{Method: "GET", Path: apiPrefix + "/attachments/:id/progress", ID: "GetDownloadProgress", Tag: "Attachments", Summary: "获取附件下载进度", Data: services.DownloadProgress{}},
+		{Method: "POST", Path: apiPrefix + "/attachments/:id/download", ID: "ForceDownloadAttachment", Tag: "Attachments", Summary: "从服务器重新下载附件", Status: http.StatusAccepted},
+		{Method: "GET", Path: apiPrefix + "/emails/:id/attachments", ID: "GetEmailAttachments", Tag: "Attachments", Summary: "获取邮件附件列表", Data: []AttachmentInfo{}},
+		{Method: "POST", Path: apiPrefix + "/emails/:id/attachments/download", ID: "DownloadEmailAttachments", Tag: "Attachments", Summary: "下载邮件的全部附件", Status: http.StatusAccepted},
+		{Method: "GET", Path: apiPrefix + "/attachment-links/:token", ID: "DownloadAttachmentLink", Tag: "Attachments", Summary: "通过邮件正文中的下载链接下载附件",
+			Raw: true, ContentType: openapi.ContentTypeBinary, Public: true},
+
+		// 实时事件
+		{Method: "GET", Path: apiPrefix + "/sse", ID: "HandleSSE", Tag: "SSE", Summary: "订阅实时事件流",
+			Params: []*openapi.Parameter{
+				openapi.QueryParam("token", "string", "访问令牌，EventSource无法设置请求头时使用"),
+				openapi.QueryParam("client_id", "string", "客户端标识"),
+				openapi.QueryParam("events", "string", "逗号分隔的事件类别（new_email、email、sync、send、account、migration、notification）或事件类型，为空时接收全部事件"),
+				openapi.QueryParam("account_ids", "string", "逗号分隔的账户ID，只接收这些账户的事件；不属于具体账户的事件不受影响"),
+				openapi.QueryParam("since", "integer", "重连时传入最后收到事件的 seq，补发缓冲区中之后的事件"),
+			}, Raw: true, ContentType: openapi.ContentTypeEventStream, Public: true},
+		{Method: "GET", Path: apiPrefix + "/sse/events", ID: "HandleSSEEvents", Tag: "SSE", Summary: "订阅实时事件流（兼容旧路径）",
+			Params: []*openapi.Parameter{
+				openapi.QueryParam("token", "string", "访问令牌，EventSource无法设置请求头时使用"),
+				openapi.QueryParam("client_id", "string", "客户端标识"),
+				openapi.QueryParam("events", "string", "逗号分隔的事件类别（new_email、email、sync、send、account、migration、notification）或事件类型，为空时接收全部事件"),
+				openapi.QueryParam("account_ids", "string", "逗号分隔的账户ID，只接收这些账户的事件；不属于具体账户的事件不受影响"),
+				openapi.QueryParam("since", "integer", "重连时传入最后收到事件的 seq，补发缓冲区中之后的事件"),
+			}, Raw: true, ContentType: openapi.ContentTypeEventStream, Public: true},
+		{Method: "GET", Path: apiPrefix + "/events/poll", ID: "PollEvents", Tag: "SSE", Summary: "长轮询获取事件（无法保持SSE连接时使用）",
+			Params: []*openapi.Parameter{
+				openapi.QueryParam("since", "integer", "上次返回的游标，为空时从当前位置开始等待"),
+				openapi.QueryParam("timeout", "integer", "没有事件时最多等待的秒数，默认25，最大60"),
+				openapi.QueryParam("events", "string", "逗号分隔的事件类别或事件类型，为空时接收全部事件"),
+				openapi.QueryParam("account_ids", "string", "逗号分隔的账户ID，只接收这些账户的事件"),
+			}, Data: sse.EventBatch{}},
+		{Method: "GET", Path: apiPrefix + "/sse/stats", ID: "GetSSEStats", Tag: "SSE", Summary: "获取SSE统计", Data: sse.ServiceStats{}},
+		{Method: "POST", Path: apiPrefix + "/sse/test", ID: "SendTestEvent", Tag: "SSE", Summary: "发送测试事件", Body: TestEventRequest{}, Data: TestEventResult{}},
+		{Method: "POST", Path: apiPrefix + "/notification-actions/:token", ID: "ExecuteNotificationAction", Tag: "SSE", Summary: "执行通知中的快捷操作（一次性令牌）",
+			Public: true, Data: services.NotificationActionResult{}},
+
+		// 通知中心
+		{Method: "GET", Path: apiPrefix + "/notifications", ID: "GetNotifications", Tag: "Notifications", Summary: "分页获取通知中心的通知，按时间倒序，同时返回全部未读数",
+			Query: services.ListNotificationsRequest{}, Data: services.ListNotificationsResponse{}},
+		{Method: "GET", Path: apiPrefix + "/notifications/counts", ID: "GetNotificationCounts", Tag: "Notifications", Summary: "获取未读通知的角标计数，按级别和事件类型分组",
+			Data: services.NotificationCounts{}},
+		{Method: "PATCH", Path: apiPrefix + "/notifications", ID: "MarkNotifications", Tag: "Notifications", Summary: "批量标记通知为已读或未读，未指定ids时修改全部通知",
+			Body: services.MarkNotificationsRequest{}, Data: services.MarkNotificationsResult{}},
+		{Method: "PATCH", Path: apiPrefix + "/notifications/:id", ID: "UpdateNotification", Tag: "Notifications", Summary: "标记单条通知为已读或未读",
+			Body: services.UpdateNotificationRequest{}, Data: models.Notification{}},
+
+		// GraphQL（GRAPHQL_ENABLED开启时注册），响应为标准GraphQL结构
+		{Method: "POST", Path: "/api/graphql", ID: "GraphQL", Tag: "GraphQL", Summary: "执行GraphQL查询",
+			Body: graphql.Request{}, Data: graphql.Response{}, Raw: true},
+		{Method: "GET", Path: "/api/graphql/schema", ID: "GetGraphQLSchema", Tag: "GraphQL", Summary: "获取GraphQL类型定义（SDL）",
+			Raw: true, ContentType: "text/plain"},
+	}
+}
+
+// APISpec 接口文档的元信息与路由
+func APISpec() openapi.Spec {
+	return openapi.Spec{
+		Info: openapi.Info{
+			Title:       "FireMail API",
+			Description: "FireMail 邮件客户端后端接口。除特别说明外，响应均包装为 {success, data, message}。",
+			Version:     "1.0.0",
+		},
+		Servers: []openapi.Server{{URL: "/"}},
+		Routes:  APIRoutes(),
+		Error:   ErrorResponse{},
+		Overrides: map[reflect.Type]*openapi.Schema{
+			// 省略表示不修改，null表示移出分组
+			reflect.TypeOf(services.OptionalGroupID{}): {Type: "integer", Format: "int64", Nullable: true},
+		},
+	}
+}
+
+// 文档只依赖静态注解，首次请求时生成后复用
+var buildAPIDocument = sync.OnceValues(func() (*openapi.Document, error) {
+	return openapi.Build(APISpec())
+})
+
+// GetOpenAPISpec 返回OpenAPI文档
+func (h *Handler) GetOpenAPISpec(c *gin.Context) {
+	doc, err := buildAPIDocument()
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to build OpenAPI document: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, doc)
+}
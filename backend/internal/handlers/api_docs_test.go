@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"firemail/internal/openapi"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPISpecBuilds(t *testing.T) {
+	doc, err := openapi.Build(APISpec())
+	require.NoError(t, err)
+
+	for path, item := range doc.Paths {
+		for _, op := range item.Operations() {
+			require.NotEmpty(t, op.Operation.Summary, "%s %s has no summary", op.Method, path)
+			require.NotEmpty(t, op.Operation.Tags, "%s %s has no tag", op.Method, path)
+		}
+	}
+
+	_, err = openapi.GenerateGoClient(doc, "client")
+	require.NoError(t, err)
+}
+
+// 提交的文档与注解不一致时提示重新运行生成命令
+func TestCommittedOpenAPIDocumentUpToDate(t *testing.T) {
+	committed, err := os.ReadFile("../../api/openapi.json")
+	require.NoError(t, err)
+
+	doc, err := openapi.Build(APISpec())
+	require.NoError(t, err)
+	generated, err := json.MarshalIndent(doc, "", "  ")
+	require.NoError(t, err)
+
+	require.Equal(t, string(committed), string(generated)+"\n", "api/openapi.json is stale, run `make openapi`")
+}
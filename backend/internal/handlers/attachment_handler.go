@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -21,6 +23,7 @@ import (
 type AttachmentHandler struct {
 	attachmentService services.AttachmentDownloader
 	db                *gorm.DB
+	tokenSigner       *services.AttachmentTokenSigner
 }
 
 // NewAttachmentHandler 创建附件处理器
@@ -31,11 +34,25 @@ func NewAttachmentHandler(attachmentService services.AttachmentDownloader, db *g
 	}
 }
 
+// SetTokenSigner 设置附件签名下载链接的签发器，未设置时不提供签名链接
+func (h *AttachmentHandler) SetTokenSigner(signer *services.AttachmentTokenSigner) {
+	h.tokenSigner = signer
+}
+
 // RegisterRoutes 注册路由
 func (h *AttachmentHandler) RegisterRoutes(router *gin.RouterGroup) {
+	// 签名下载链接，令牌代替登录认证，供 <img> 标签和直接下载链接使用
+	router.GET("/attachments/:id/file", h.DownloadAttachmentWithToken)
+
 	attachments := router.Group("/attachments")
 	attachments.Use(middleware.AuthRequired())
 	{
+		// 跨邮件列出附件
+		attachments.GET("", h.ListAttachments)
+
+		// 打包下载选中的附件
+		attachments.POST("/download-zip", h.DownloadAttachmentsZip)
+
 		// 上传附件（用于邮件发送）
 		attachments.POST("/upload", h.UploadAttachment)
 
@@ -48,6 +65,9 @@ func (h *AttachmentHandler) RegisterRoutes(router *gin.RouterGroup) {
 		// 获取下载进度
 		attachments.GET("/:id/progress", h.GetDownloadProgress)
 
+		// 签发附件下载链接
+		attachments.POST("/:id/token", h.CreateAttachmentToken)
+
 		// 强制重新下载
 		attachments.POST("/:id/download", h.ForceDownloadAttachment)
 	}
@@ -75,8 +95,86 @@ func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
 		return
 	}
 
+	h.serveAttachment(c, uint(attachmentID), userID)
+}
+
+// DownloadAttachmentWithToken 通过签名链接下载附件，令牌只对签发时指定的附件有效
+func (h *AttachmentHandler) DownloadAttachmentWithToken(c *gin.Context) {
+	attachmentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID"})
+		return
+	}
+	if h.tokenSigner == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "Attachment download links are not enabled")})
+		return
+	}
+
+	userID, err := h.tokenSigner.Verify(c.Query("token"), uint(attachmentID))
+	if errors.Is(err, services.ErrAttachmentTokenExpired) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "Attachment download link has expired")})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "Invalid attachment download link")})
+		return
+	}
+
+	// 链接可能出现在页面中，避免通过Referer泄露给第三方
+	c.Header("Referrer-Policy", "no-referrer")
+	h.serveAttachment(c, uint(attachmentID), userID)
+}
+
+// CreateAttachmentToken 为当前用户签发单个附件的短期下载链接
+func (h *AttachmentHandler) CreateAttachmentToken(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	attachmentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID"})
+		return
+	}
+	if h.tokenSigner == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "Attachment download links are not enabled")})
+		return
+	}
+
+	// 只为有权访问的附件签发
+	if _, err := h.getAttachmentInfo(c, uint(attachmentID), userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "Attachment not found")})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    h.signAttachment(c, uint(attachmentID), userID),
+	})
+}
+
+// signAttachment 签发附件下载链接，链接使用与当前请求相同的接口前缀
+func (h *AttachmentHandler) signAttachment(c *gin.Context, attachmentID, userID uint) services.AttachmentToken {
+	token, expiresAt := h.tokenSigner.Sign(attachmentID, userID)
+	prefix := c.Request.URL.Path
+	if index := strings.Index(prefix, "/attachments/"); index >= 0 {
+		prefix = prefix[:index]
+	} else if index := strings.Index(prefix, "/emails/"); index >= 0 {
+		prefix = prefix[:index]
+	}
+	return services.AttachmentToken{
+		Token:     token,
+		URL:       fmt.Sprintf("%s/attachments/%d/file?token=%s", prefix, attachmentID, url.QueryEscape(token)),
+		ExpiresAt: expiresAt,
+	}
+}
+
+// serveAttachment 检查权限后流式输出附件内容
+func (h *AttachmentHandler) serveAttachment(c *gin.Context, attachmentID, userID uint) {
 	// 获取附件内容
-	content, err := h.attachmentService.GetAttachmentContent(c.Request.Context(), uint(attachmentID), userID)
+	content, err := h.attachmentService.GetAttachmentContent(c.Request.Context(), attachmentID, userID)
+	if errors.Is(err, services.ErrAttachmentBlocked) {
+		c.JSON(http.StatusForbidden, gin.H{"error": localize(c, "Attachment is blocked by the security policy")})
+		return
+	}
 	if err != nil {
 		log.Printf("Failed to get attachment content: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get attachment"})
@@ -85,7 +183,7 @@ func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
 	defer content.Close()
 
 	// 获取附件信息（用于设置响应头）
-	attachmentInfo, err := h.getAttachmentInfo(c, uint(attachmentID), userID)
+	attachmentInfo, err := h.getAttachmentInfo(c, attachmentID, userID)
 	if err != nil {
 		log.Printf("Failed to get attachment info: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get attachment info"})
@@ -93,7 +191,7 @@ func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
 	}
 
 	// 获取实际文件大小以修复Content-Length不匹配问题
-	attachment, err := h.attachmentService.(*services.AttachmentService).GetAttachmentWithPermissionCheck(c.Request.Context(), uint(attachmentID), userID)
+	attachment, err := h.attachmentService.(*services.AttachmentService).GetAttachmentWithPermissionCheck(c.Request.Context(), attachmentID, userID)
 	if err == nil {
 		storage := h.attachmentService.(*services.AttachmentService).GetStorage()
 		if storageInfo, err := storage.GetStorageInfo(c.Request.Context(), attachment); err == nil {
@@ -180,7 +278,7 @@ func (h *AttachmentHandler) ForceDownloadAttachment(c *gin.Context) {
 
 	c.JSON(http.StatusAccepted, SuccessResponse{
 		Success: true,
-		Message: "Download started",
+		Message: localize(c, "Download started"),
 	})
 }
 
@@ -203,6 +301,15 @@ func (h *AttachmentHandler) GetEmailAttachments(c *gin.Context) {
 		return
 	}
 
+	// 附带签名下载链接，内嵌图片可直接用作 <img> 的地址
+	if h.tokenSigner != nil {
+		for i := range attachments {
+			if !attachments[i].IsBlocked {
+				attachments[i].DownloadURL = h.signAttachment(c, attachments[i].ID, userID).URL
+			}
+		}
+	}
+
 	c.JSON(http.StatusOK, SuccessResponse{
 		Success: true,
 		Data:    attachments,
@@ -229,7 +336,7 @@ func (h *AttachmentHandler) DownloadEmailAttachments(c *gin.Context) {
 
 	c.JSON(http.StatusAccepted, SuccessResponse{
 		Success: true,
-		Message: "Download started for all attachments",
+		Message: localize(c, "Download started for all attachments"),
 	})
 }
 
@@ -242,6 +349,9 @@ type AttachmentInfo struct {
 	Disposition string `json:"disposition"`
 	IsDownloaded bool  `json:"is_downloaded"`
 	IsInline    bool   `json:"is_inline"`
+	IsBlocked   bool   `json:"is_blocked"`             // 被附件安全策略拦截，禁止下载和转发
+	BlockReason string `json:"block_reason,omitempty"` // 拦截原因
+	DownloadURL string `json:"download_url,omitempty"` // 短期有效的签名下载链接，无需携带登录令牌
 }
 
 // getAttachmentInfo 获取附件信息
@@ -291,6 +401,8 @@ func (h *AttachmentHandler) getAttachmentInfo(c *gin.Context, attachmentID uint,
 		Disposition: attachment.Disposition,
 		IsDownloaded: attachment.IsDownloaded,
 		IsInline:    attachment.IsInline,
+		IsBlocked:   attachment.IsBlocked,
+		BlockReason: attachment.BlockReason,
 	}, nil
 }
 
@@ -317,6 +429,8 @@ func (h *AttachmentHandler) getEmailAttachmentsList(c *gin.Context, emailID uint
 			Disposition: attachment.Disposition,
 			IsDownloaded: attachment.IsDownloaded,
 			IsInline:    attachment.IsInline,
+			IsBlocked:   attachment.IsBlocked,
+			BlockReason: attachment.BlockReason,
 		})
 	}
 
@@ -408,22 +522,39 @@ func (h *AttachmentHandler) UploadAttachment(c *gin.Context) {
 		return
 	}
 
+	// 按文件头识别类型并检查安全策略，不信任客户端声明的Content-Type
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read uploaded file"})
+		return
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read uploaded file"})
+		return
+	}
+	declaredType := header.Header.Get("Content-Type")
+	if declaredType == "" {
+		declaredType = h.getContentTypeByExtension(header.Filename)
+	}
+	verdict := services.CurrentAttachmentPolicy().Check(header.Filename, declaredType, head[:n])
+	if verdict.Blocked {
+		log.Printf("Rejected attachment upload %q from user %d: %s", header.Filename, userID, verdict.Reason)
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Attachment type is not allowed"), "reason": verdict.Reason})
+		return
+	}
+
 	// 创建临时附件记录（用于邮件发送）
 	attachment := &models.Attachment{
 		EmailID:      nil,    // 临时上传的附件，暂不关联邮件
 		UserID:       &userID, // 设置用户ID用于权限检查
 		Filename:     header.Filename,
-		ContentType:  header.Header.Get("Content-Type"),
+		ContentType:  verdict.ContentType,
 		Size:         header.Size,
 		Disposition:  "attachment",
 		IsDownloaded: true, // 上传的文件直接标记为已下载
 	}
 
-	// 如果没有Content-Type，根据文件扩展名推断
-	if attachment.ContentType == "" {
-		attachment.ContentType = h.getContentTypeByExtension(header.Filename)
-	}
-
 	// 保存到数据库
 	if err := h.db.WithContext(c.Request.Context()).Create(attachment).Error; err != nil {
 		log.Printf("Failed to create attachment record: %v", err)
@@ -452,7 +583,7 @@ func (h *AttachmentHandler) UploadAttachment(c *gin.Context) {
 
 	c.JSON(http.StatusCreated, SuccessResponse{
 		Success: true,
-		Message: "Attachment uploaded successfully",
+		Message: localize(c, "Attachment uploaded successfully"),
 		Data: gin.H{
 			"attachment_id": attachment.ID,
 			"filename":      attachment.Filename,
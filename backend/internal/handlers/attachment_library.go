@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"firemail/internal/middleware"
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListAttachments 跨邮件列出附件，支持按账户、类型、时间和大小筛选
+func (h *AttachmentHandler) ListAttachments(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req services.ListAttachmentsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters: " + err.Error()})
+		return
+	}
+
+	result, err := h.attachmentService.ListAttachments(c.Request.Context(), userID, &req)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid attachment type") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Failed to list attachments: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list attachments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    result,
+	})
+}
+
+// DownloadAttachmentsZip 将选中的附件打包为ZIP流式下载
+func (h *AttachmentHandler) DownloadAttachmentsZip(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req services.DownloadAttachmentsZipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	// 开始写入前完成权限检查，之后的错误只能记录日志
+	attachments, err := h.attachmentService.GetAttachmentsForUser(c.Request.Context(), userID, req.AttachmentIDs)
+	if err != nil {
+		if err.Error() == "attachment not found or access denied" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Failed to get attachments for zip: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get attachments"})
+		return
+	}
+
+	filename := fmt.Sprintf("attachments_%s.zip", time.Now().Format("20060102_150405"))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Status(http.StatusOK)
+
+	if err := h.attachmentService.WriteAttachmentsZip(c.Request.Context(), userID, attachments, c.Writer); err != nil {
+		log.Printf("Failed to stream attachments zip: %v", err)
+	}
+}
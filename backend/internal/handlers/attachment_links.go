@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DownloadAttachmentLink 通过邮件正文中的下载链接下载附件（凭签名链接访问，无需认证）
+func (h *Handler) DownloadAttachmentLink(c *gin.Context) {
+	attachment, content, err := h.attachmentLinkService.Open(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		message := "无法下载附件，请稍后重试。"
+		switch {
+		case errors.Is(err, services.ErrAttachmentLinkExpired):
+			status = http.StatusGone
+			message = "该下载链接已过期。"
+		case errors.Is(err, services.ErrAttachmentLinkNotFound):
+			status = http.StatusNotFound
+			message = "该下载链接无效。"
+		default:
+			log.Printf("Failed to open attachment link: %v", err)
+		}
+		setSharedEmailHeaders(c)
+		c.Data(status, "text/plain; charset=utf-8", []byte(message))
+		return
+	}
+	defer content.Close()
+
+	contentType := attachment.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	setSharedEmailHeaders(c)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, content); err != nil {
+		log.Printf("Failed to stream linked attachment %d: %v", attachment.ID, err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"firemail/internal/auth"
+	"firemail/internal/middleware"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,8 +21,10 @@ func (h *Handler) Login(c *gin.Context) {
 	if err != nil {
 		switch err {
 		case auth.ErrInvalidCredentials:
+			h.recordAuthFailure(c, req.Username, "invalid_credentials")
 			h.respondWithError(c, http.StatusUnauthorized, "Invalid username or password")
 		case auth.ErrUserInactive:
+			h.recordAuthFailure(c, req.Username, "user_inactive")
 			h.respondWithError(c, http.StatusForbidden, "User account is inactive")
 		default:
 			h.respondWithError(c, http.StatusInternalServerError, "Login failed")
@@ -29,6 +32,14 @@ func (h *Handler) Login(c *gin.Context) {
 		return
 	}
 
+	// 会话Cookie认证：令牌写入HttpOnly Cookie，只接受Cookie时不在响应中返回，避免前端脚本读取
+	if middleware.CookieAuthEnabled() {
+		response.CSRFToken = middleware.SetSessionCookies(c, response.Token, response.ExpiresAt)
+		if !middleware.HeaderAuthEnabled() {
+			response.Token = ""
+		}
+	}
+
 	h.respondWithSuccess(c, response, "Login successful")
 }
 
@@ -36,6 +47,9 @@ func (h *Handler) Login(c *gin.Context) {
 func (h *Handler) Logout(c *gin.Context) {
 	// 对于JWT，登出通常在客户端处理（删除token）
 	// 这里可以实现token黑名单机制
+	if middleware.CookieAuthEnabled() {
+		middleware.ClearSessionCookies(c)
+	}
 	h.respondWithSuccess(c, nil, "Logout successful")
 }
 
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetBlockedSenders 获取屏蔽发件人列表
+func (h *Handler) GetBlockedSenders(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	senders, err := h.emailService.ListBlockedSenders(c.Request.Context(), userID)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get blocked senders")
+		return
+	}
+
+	h.respondWithSuccess(c, senders)
+}
+
+// AddBlockedSender 屏蔽发件人地址或域名
+func (h *Handler) AddBlockedSender(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	var req services.BlockedSenderRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	sender, err := h.emailService.AddBlockedSender(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondWithBlockedSenderError(c, err, "Failed to block sender")
+		return
+	}
+
+	h.respondWithCreated(c, sender, "Sender blocked")
+}
+
+// UpdateBlockedSender 修改屏蔽发件人的处理方式
+func (h *Handler) UpdateBlockedSender(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	senderID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	var req services.UpdateBlockedSenderRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	sender, err := h.emailService.UpdateBlockedSender(c.Request.Context(), userID, senderID, &req)
+	if err != nil {
+		h.respondWithBlockedSenderError(c, err, "Failed to update blocked sender")
+		return
+	}
+
+	h.respondWithSuccess(c, sender, "Blocked sender updated")
+}
+
+// DeleteBlockedSender 取消屏蔽发件人
+func (h *Handler) DeleteBlockedSender(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	senderID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	if err := h.emailService.DeleteBlockedSender(c.Request.Context(), userID, senderID); err != nil {
+		h.respondWithBlockedSenderError(c, err, "Failed to unblock sender")
+		return
+	}
+
+	h.respondWithSuccess(c, nil, "Sender unblocked")
+}
+
+// BlockEmailSender 屏蔽邮件的发件人，并将邮件移入垃圾邮件或回收站
+func (h *Handler) BlockEmailSender(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	emailID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	// 请求体可选，默认屏蔽发件人地址并移入回收站
+	var req services.BlockEmailSenderRequest
+	if c.Request.ContentLength > 0 && !h.bindJSON(c, &req) {
+		return
+	}
+
+	sender, err := h.emailService.BlockEmailSender(c.Request.Context(), userID, emailID, &req)
+	if err != nil {
+		h.respondWithBlockedSenderError(c, err, "Failed to block sender")
+		return
+	}
+
+	h.respondWithCreated(c, sender, "Sender blocked")
+}
+
+// ExportBlockedSenders 导出屏蔽发件人列表
+func (h *Handler) ExportBlockedSenders(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	export, err := h.emailService.ExportBlockedSenders(c.Request.Context(), userID)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to export blocked senders")
+		return
+	}
+
+	h.respondWithSuccess(c, export)
+}
+
+// ImportBlockedSenders 导入屏蔽发件人列表
+func (h *Handler) ImportBlockedSenders(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	var req services.ImportBlockedSendersRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.emailService.ImportBlockedSenders(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to import blocked senders: "+err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, result, "Blocked senders imported")
+}
+
+// respondWithBlockedSenderError 将屏蔽发件人错误映射为HTTP状态码
+func (h *Handler) respondWithBlockedSenderError(c *gin.Context, err error, message string) {
+	switch {
+	case err.Error() == "blocked sender not found", err.Error() == "email not found":
+		h.respondWithError(c, http.StatusNotFound, err.Error())
+	case err.Error() == "sender already blocked":
+		h.respondWithError(c, http.StatusConflict, err.Error())
+	case err.Error() == "invalid blocked sender pattern", err.Error() == "email has no sender address",
+		strings.HasPrefix(err.Error(), "invalid block action"):
+		h.respondWithError(c, http.StatusBadRequest, err.Error())
+	default:
+		h.respondWithError(c, http.StatusInternalServerError, message+": "+err.Error())
+	}
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCannedResponses 获取快捷回复列表
+func (h *Handler) GetCannedResponses(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	responses, err := h.cannedResponseService.ListResponses(c.Request.Context(), userID)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get canned responses: "+err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, responses)
+}
+
+// GetCannedQuickList 获取撰写邮件时使用的快捷回复精简列表
+func (h *Handler) GetCannedQuickList(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	var req services.CannedQuickListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid query parameters: "+err.Error())
+		return
+	}
+
+	items, err := h.cannedResponseService.QuickList(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get canned responses: "+err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, items)
+}
+
+// CreateCannedResponse 创建快捷回复
+func (h *Handler) CreateCannedResponse(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	var req services.CannedResponseRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.cannedResponseService.CreateResponse(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondWithCannedResponseError(c, err, "Failed to create canned response")
+		return
+	}
+
+	h.respondWithCreated(c, response, "Canned response created")
+}
+
+// UpdateCannedResponse 修改快捷回复
+func (h *Handler) UpdateCannedResponse(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	responseID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	var req services.CannedResponseRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.cannedResponseService.UpdateResponse(c.Request.Context(), userID, responseID, &req)
+	if err != nil {
+		h.respondWithCannedResponseError(c, err, "Failed to update canned response")
+		return
+	}
+
+	h.respondWithSuccess(c, response, "Canned response updated")
+}
+
+// DeleteCannedResponse 删除快捷回复
+func (h *Handler) DeleteCannedResponse(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	responseID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	if err := h.cannedResponseService.DeleteResponse(c.Request.Context(), userID, responseID); err != nil {
+		h.respondWithCannedResponseError(c, err, "Failed to delete canned response")
+		return
+	}
+
+	h.respondWithSuccess(c, nil, "Canned response deleted")
+}
+
+// RenderCannedResponse 代入变量渲染快捷回复，并计入使用次数
+func (h *Handler) RenderCannedResponse(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	responseID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	var req services.RenderCannedResponseRequest
+	if c.Request.ContentLength != 0 && !h.bindJSON(c, &req) {
+		return
+	}
+
+	rendered, err := h.cannedResponseService.RenderResponse(c.Request.Context(), userID, responseID, &req)
+	if err != nil {
+		h.respondWithCannedResponseError(c, err, "Failed to render canned response")
+		return
+	}
+
+	h.respondWithSuccess(c, rendered)
+}
+
+// respondWithCannedResponseError 将快捷回复服务错误映射为HTTP状态码
+func (h *Handler) respondWithCannedResponseError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrCannedResponseNotFound):
+		h.respondWithError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrCannedShortcutInUse):
+		h.respondWithError(c, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrInvalidCannedResponse):
+		h.respondWithError(c, http.StatusBadRequest, err.Error())
+	default:
+		h.respondWithError(c, http.StatusInternalServerError, message+": "+err.Error())
+	}
+}
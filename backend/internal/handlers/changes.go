@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetChanges 获取令牌之后的增量变更，供前端重连后对账本地缓存
+func (h *Handler) GetChanges(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	var since uint64
+	if token := c.Query("since"); token != "" {
+		parsed, err := strconv.ParseUint(token, 10, 64)
+		if err != nil {
+			h.respondWithError(c, http.StatusBadRequest, "Invalid since token")
+			return
+		}
+		since = parsed
+	}
+
+	changes, err := h.changeLogService.GetChanges(c.Request.Context(), userID, uint(since), h.parseIntQuery(c, "limit", 0))
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get changes: "+err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, changes)
+}
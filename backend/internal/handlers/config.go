@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// GetConfig 查看当前生效的配置及来源，敏感值已脱敏
+func (h *Handler) GetConfig(c *gin.Context) {
+	warnings := h.config.Warnings()
+	if warnings == nil {
+		warnings = []string{}
+	}
+
+	h.respondWithSuccess(c, gin.H{
+		"config_file": h.config.ConfigFile(),
+		"settings":    h.config.Settings(),
+		"warnings":    warnings,
+	})
+}
@@ -66,8 +66,8 @@ func (h *DeduplicationHandler) DeduplicateAccount(c *gin.Context) {
 	accountID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid account ID",
-			Message: err.Error(),
+			Error:   localize(c, "Invalid account ID"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
@@ -75,8 +75,8 @@ func (h *DeduplicationHandler) DeduplicateAccount(c *gin.Context) {
 	var req DeduplicateAccountRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid request",
-			Message: err.Error(),
+			Error:   localize(c, "Invalid request"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
@@ -84,8 +84,8 @@ func (h *DeduplicationHandler) DeduplicateAccount(c *gin.Context) {
 	// 验证账户权限
 	if err := h.validateAccountAccess(c, uint(accountID), userID); err != nil {
 		c.JSON(http.StatusForbidden, ErrorResponse{
-			Error:   "Access denied",
-			Message: "You don't have access to this email account",
+			Error:   localize(c, "Access denied"),
+			Message: localize(c, "You don't have access to this email account"),
 		})
 		return
 	}
@@ -111,15 +111,15 @@ func (h *DeduplicationHandler) DeduplicateAccount(c *gin.Context) {
 	result, err := h.deduplicationManager.DeduplicateAccount(c.Request.Context(), uint(accountID), options)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "Failed to deduplicate account",
-			Message: err.Error(),
+			Error:   localize(c, "Failed to deduplicate account"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, SuccessResponse{
 		Success: true,
-		Message: "Account deduplication completed",
+		Message: localize(c, "Account deduplication completed"),
 		Data:    result,
 	})
 }
@@ -143,8 +143,8 @@ func (h *DeduplicationHandler) DeduplicateUser(c *gin.Context) {
 	var req DeduplicateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid request",
-			Message: err.Error(),
+			Error:   localize(c, "Invalid request"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
@@ -170,15 +170,15 @@ func (h *DeduplicationHandler) DeduplicateUser(c *gin.Context) {
 	result, err := h.deduplicationManager.DeduplicateUser(c.Request.Context(), userID, options)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "Failed to deduplicate user accounts",
-			Message: err.Error(),
+			Error:   localize(c, "Failed to deduplicate user accounts"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, SuccessResponse{
 		Success: true,
-		Message: "User accounts deduplication completed",
+		Message: localize(c, "User accounts deduplication completed"),
 		Data:    result,
 	})
 }
@@ -190,8 +190,8 @@ func (h *DeduplicationHandler) GetDeduplicationReport(c *gin.Context) {
 	accountID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid account ID",
-			Message: err.Error(),
+			Error:   localize(c, "Invalid account ID"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
@@ -199,8 +199,8 @@ func (h *DeduplicationHandler) GetDeduplicationReport(c *gin.Context) {
 	// 验证账户权限
 	if err := h.validateAccountAccess(c, uint(accountID), userID); err != nil {
 		c.JSON(http.StatusForbidden, ErrorResponse{
-			Error:   "Access denied",
-			Message: "You don't have access to this email account",
+			Error:   localize(c, "Access denied"),
+			Message: localize(c, "You don't have access to this email account"),
 		})
 		return
 	}
@@ -209,8 +209,8 @@ func (h *DeduplicationHandler) GetDeduplicationReport(c *gin.Context) {
 	report, err := h.deduplicationManager.GetDeduplicationReport(c.Request.Context(), uint(accountID))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "Failed to get deduplication report",
-			Message: err.Error(),
+			Error:   localize(c, "Failed to get deduplication report"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
@@ -236,8 +236,8 @@ func (h *DeduplicationHandler) ScheduleDeduplication(c *gin.Context) {
 	accountID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid account ID",
-			Message: err.Error(),
+			Error:   localize(c, "Invalid account ID"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
@@ -245,8 +245,8 @@ func (h *DeduplicationHandler) ScheduleDeduplication(c *gin.Context) {
 	var req ScheduleDeduplicationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid request",
-			Message: err.Error(),
+			Error:   localize(c, "Invalid request"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
@@ -254,8 +254,8 @@ func (h *DeduplicationHandler) ScheduleDeduplication(c *gin.Context) {
 	// 验证账户权限
 	if err := h.validateAccountAccess(c, uint(accountID), userID); err != nil {
 		c.JSON(http.StatusForbidden, ErrorResponse{
-			Error:   "Access denied",
-			Message: "You don't have access to this email account",
+			Error:   localize(c, "Access denied"),
+			Message: localize(c, "You don't have access to this email account"),
 		})
 		return
 	}
@@ -272,15 +272,15 @@ func (h *DeduplicationHandler) ScheduleDeduplication(c *gin.Context) {
 	err = h.deduplicationManager.ScheduleDeduplication(c.Request.Context(), uint(accountID), schedule)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "Failed to schedule deduplication",
-			Message: err.Error(),
+			Error:   localize(c, "Failed to schedule deduplication"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, SuccessResponse{
 		Success: true,
-		Message: "Deduplication scheduled successfully",
+		Message: localize(c, "Deduplication scheduled successfully"),
 		Data:    schedule,
 	})
 }
@@ -292,8 +292,8 @@ func (h *DeduplicationHandler) CancelScheduledDeduplication(c *gin.Context) {
 	accountID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid account ID",
-			Message: err.Error(),
+			Error:   localize(c, "Invalid account ID"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
@@ -301,8 +301,8 @@ func (h *DeduplicationHandler) CancelScheduledDeduplication(c *gin.Context) {
 	// 验证账户权限
 	if err := h.validateAccountAccess(c, uint(accountID), userID); err != nil {
 		c.JSON(http.StatusForbidden, ErrorResponse{
-			Error:   "Access denied",
-			Message: "You don't have access to this email account",
+			Error:   localize(c, "Access denied"),
+			Message: localize(c, "You don't have access to this email account"),
 		})
 		return
 	}
@@ -311,15 +311,15 @@ func (h *DeduplicationHandler) CancelScheduledDeduplication(c *gin.Context) {
 	err = h.deduplicationManager.CancelScheduledDeduplication(c.Request.Context(), uint(accountID))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "Failed to cancel scheduled deduplication",
-			Message: err.Error(),
+			Error:   localize(c, "Failed to cancel scheduled deduplication"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, SuccessResponse{
 		Success: true,
-		Message: "Scheduled deduplication cancelled successfully",
+		Message: localize(c, "Scheduled deduplication cancelled successfully"),
 	})
 }
 
@@ -330,8 +330,8 @@ func (h *DeduplicationHandler) GetDeduplicationStats(c *gin.Context) {
 	accountID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid account ID",
-			Message: err.Error(),
+			Error:   localize(c, "Invalid account ID"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
@@ -339,8 +339,8 @@ func (h *DeduplicationHandler) GetDeduplicationStats(c *gin.Context) {
 	// 验证账户权限
 	if err := h.validateAccountAccess(c, uint(accountID), userID); err != nil {
 		c.JSON(http.StatusForbidden, ErrorResponse{
-			Error:   "Access denied",
-			Message: "You don't have access to this email account",
+			Error:   localize(c, "Access denied"),
+			Message: localize(c, "You don't have access to this email account"),
 		})
 		return
 	}
@@ -349,8 +349,8 @@ func (h *DeduplicationHandler) GetDeduplicationStats(c *gin.Context) {
 	report, err := h.deduplicationManager.GetDeduplicationReport(c.Request.Context(), uint(accountID))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "Failed to get deduplication stats",
-			Message: err.Error(),
+			Error:   localize(c, "Failed to get deduplication stats"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
@@ -1,16 +1,23 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"firemail/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
 
+// ReorderEmailAccountsRequest 账户排序请求
+type ReorderEmailAccountsRequest struct {
+	AccountIDs []uint `json:"account_ids" binding:"required"`
+}
+
 // GetEmailAccounts 获取邮件账户列表
 func (h *Handler) GetEmailAccounts(c *gin.Context) {
 	userID, exists := h.getCurrentUserID(c)
@@ -49,6 +56,7 @@ func (h *Handler) CreateEmailAccount(c *gin.Context) {
 		return
 	}
 
+	account.SetupGuide = localizeSetupGuide(c, account.SetupGuide)
 	h.respondWithCreated(c, account, "Email account created successfully")
 }
 
@@ -70,6 +78,7 @@ func (h *Handler) GetEmailAccount(c *gin.Context) {
 		return
 	}
 
+	setVersionETag(c, account.Version)
 	h.respondWithSuccess(c, account)
 }
 
@@ -90,15 +99,50 @@ func (h *Handler) UpdateEmailAccount(c *gin.Context) {
 		return
 	}
 
-	account, err := h.emailService.UpdateEmailAccount(c.Request.Context(), userID, accountID, &req)
+	ctx, ok := h.versionedContext(c)
+	if !ok {
+		return
+	}
+
+	account, err := h.emailService.UpdateEmailAccount(ctx, userID, accountID, &req)
 	if err != nil {
+		if respondWithVersionConflict(c, err) {
+			return
+		}
 		h.respondWithEmailGroupError(c, http.StatusBadRequest, "Failed to update email account: ", err)
 		return
 	}
 
+	setVersionETag(c, account.Version)
 	h.respondWithSuccess(c, account, "Email account updated successfully")
 }
 
+// ReorderEmailAccounts 调整账户在列表中的显示顺序
+func (h *Handler) ReorderEmailAccounts(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	var req ReorderEmailAccountsRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	if len(req.AccountIDs) == 0 {
+		h.respondWithError(c, http.StatusBadRequest, "account_ids cannot be empty")
+		return
+	}
+
+	accounts, err := h.emailService.ReorderEmailAccounts(c.Request.Context(), userID, req.AccountIDs)
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Failed to reorder email accounts: "+err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, accounts, "Email accounts reordered successfully")
+}
+
 // DeleteEmailAccount 删除邮件账户
 func (h *Handler) DeleteEmailAccount(c *gin.Context) {
 	userID, exists := h.getCurrentUserID(c)
@@ -113,6 +157,10 @@ func (h *Handler) DeleteEmailAccount(c *gin.Context) {
 
 	err := h.emailService.DeleteEmailAccount(c.Request.Context(), userID, accountID)
 	if err != nil {
+		if errors.Is(err, services.ErrEmailUnderLegalHold) {
+			h.respondWithError(c, http.StatusConflict, err.Error())
+			return
+		}
 		h.respondWithError(c, http.StatusBadRequest, "Failed to delete email account: "+err.Error())
 		return
 	}
@@ -134,7 +182,7 @@ func (h *Handler) TestEmailAccount(c *gin.Context) {
 
 	err := h.emailService.TestEmailAccount(c.Request.Context(), userID, accountID)
 	if err != nil {
-		h.respondWithError(c, http.StatusBadRequest, "Connection test failed: "+err.Error())
+		h.respondWithProviderError(c, http.StatusBadRequest, "Connection test failed: ", err)
 		return
 	}
 
@@ -154,21 +202,71 @@ func (h *Handler) SyncEmailAccount(c *gin.Context) {
 	}
 
 	// 验证账户属于当前用户
-	_, err := h.emailService.GetEmailAccount(c.Request.Context(), userID, accountID)
+	account, err := h.emailService.GetEmailAccount(c.Request.Context(), userID, accountID)
 	if err != nil {
 		h.respondWithError(c, http.StatusNotFound, "Email account not found")
 		return
 	}
+	if account.SyncPaused {
+		h.respondWithError(c, http.StatusConflict, "Account sync is paused")
+		return
+	}
+
+	// 入队同步任务，由后台任务队列执行
+	if _, err := services.EnqueueAccountSync(c.Request.Context(), h.jobQueue, userID, accountID); err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to start email sync")
+		return
+	}
+
+	h.respondWithSuccess(c, nil, "Email sync started")
+}
+
+// PauseAccountSync 暂停账户同步，等待进行中的同步退出后返回
+func (h *Handler) PauseAccountSync(c *gin.Context) {
+	h.setAccountSyncPaused(c, true)
+}
+
+// ResumeAccountSync 恢复账户同步
+func (h *Handler) ResumeAccountSync(c *gin.Context) {
+	h.setAccountSyncPaused(c, false)
+}
+
+// setAccountSyncPaused 暂停或恢复账户同步
+func (h *Handler) setAccountSyncPaused(c *gin.Context, paused bool) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	accountID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	if _, err := h.emailService.GetEmailAccount(c.Request.Context(), userID, accountID); err != nil {
+		h.respondWithError(c, http.StatusNotFound, "Email account not found")
+		return
+	}
 
-	// 启动异步同步
-	go func() {
-		if err := h.syncService.SyncEmails(c.Request.Context(), accountID); err != nil {
-			// 记录错误，但不影响响应
-			// 可以通过WebSocket或其他方式通知前端
+	if !paused {
+		account, err := h.syncService.ResumeAccountSync(c.Request.Context(), accountID)
+		if err != nil {
+			h.respondWithError(c, http.StatusInternalServerError, "Failed to resume account sync: "+err.Error())
+			return
 		}
-	}()
+		h.respondWithSuccess(c, account, "Account sync resumed")
+		return
+	}
 
-	h.respondWithSuccess(c, nil, "Email sync started")
+	// 最多等待进行中的同步退出30秒
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+	account, err := h.syncService.PauseAccountSync(ctx, accountID)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to pause account sync: "+err.Error())
+		return
+	}
+	h.respondWithSuccess(c, account, "Account sync paused")
 }
 
 // BatchAccountRequest 批量账户操作请求
@@ -220,13 +318,19 @@ func (h *Handler) BatchSyncEmailAccounts(c *gin.Context) {
 
 	for _, id := range req.AccountIDs {
 		// 验证账户归属
-		if _, err := h.emailService.GetEmailAccount(c.Request.Context(), userID, id); err != nil {
+		account, err := h.emailService.GetEmailAccount(c.Request.Context(), userID, id)
+		if err != nil {
 			h.respondWithError(c, http.StatusNotFound, "Email account not found")
 			return
 		}
-		go func(accountID uint) {
-			_ = h.syncService.SyncEmails(c.Request.Context(), accountID)
-		}(id)
+		// 已暂停同步的账户跳过
+		if account.SyncPaused {
+			continue
+		}
+		if _, err := services.EnqueueAccountSync(c.Request.Context(), h.jobQueue, userID, id); err != nil {
+			h.respondWithError(c, http.StatusInternalServerError, "Failed to start email sync")
+			return
+		}
 	}
 
 	h.respondWithSuccess(c, nil, "Batch email sync started")
@@ -379,6 +483,7 @@ func (h *Handler) CreateCustomEmailAccount(c *gin.Context) {
 		return
 	}
 
+	account.SetupGuide = localizeSetupGuide(c, account.SetupGuide)
 	h.respondWithCreated(c, account, "Custom email account created successfully")
 }
 
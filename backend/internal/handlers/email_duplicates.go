@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StartDuplicateScan 启动重复邮件扫描任务
+func (h *Handler) StartDuplicateScan(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	var req services.StartDuplicateScanRequest
+	if c.Request.ContentLength > 0 && !h.bindJSON(c, &req) {
+		return
+	}
+
+	job, err := h.emailService.StartDuplicateScan(c.Request.Context(), userID, &req)
+	if err != nil {
+		if strings.HasSuffix(err.Error(), "not found") {
+			h.respondWithError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to start duplicate scan: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusAccepted, SuccessResponse{
+		Success: true,
+		Data:    job,
+		Message: localize(c, "Duplicate scan started"),
+	})
+}
+
+// GetDuplicateScan 获取重复邮件扫描任务状态及结果
+func (h *Handler) GetDuplicateScan(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	job, err := h.emailService.GetDuplicateScan(c.Request.Context(), userID, c.Param("job_id"))
+	if err != nil {
+		h.respondWithError(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, job)
+}
+
+// CancelDuplicateScan 取消重复邮件扫描任务
+func (h *Handler) CancelDuplicateScan(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	job, err := h.emailService.CancelDuplicateScan(c.Request.Context(), userID, c.Param("job_id"))
+	if err != nil {
+		h.respondWithError(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, job, "Duplicate scan cancelled")
+}
+
+// ResolveDuplicates 按扫描结果批量删除或移动重复邮件，每组保留一封
+func (h *Handler) ResolveDuplicates(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	var req services.ResolveDuplicatesRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.emailService.ResolveDuplicates(c.Request.Context(), userID, c.Param("job_id"), &req)
+	if err != nil {
+		if err.Error() == "duplicate scan not found" {
+			h.respondWithError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		h.respondWithProviderError(c, http.StatusBadRequest, "Failed to resolve duplicates: ", err)
+		return
+	}
+
+	if result.Transfer != nil && !result.Transfer.Finished() {
+		c.JSON(http.StatusAccepted, SuccessResponse{
+			Success: true,
+			Data:    result,
+			Message: localize(c, "Transfer job started"),
+		})
+		return
+	}
+	h.respondWithSuccess(c, result, "Duplicates resolved")
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetEmailHistory 获取邮件的操作历史
+func (h *Handler) GetEmailHistory(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	emailID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	history, err := h.emailService.GetEmailHistory(c.Request.Context(), userID, emailID)
+	if err != nil {
+		if err.Error() == "email not found" {
+			h.respondWithError(c, http.StatusNotFound, "Email not found")
+			return
+		}
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get email history: "+err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, history)
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"net/http"
+
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetEmailNotes 获取邮件的私有笔记
+func (h *Handler) GetEmailNotes(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	emailID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	notes, err := h.emailService.ListEmailNotes(c.Request.Context(), userID, emailID)
+	if err != nil {
+		h.respondWithEmailNoteError(c, err, "Failed to get notes")
+		return
+	}
+
+	h.respondWithSuccess(c, notes)
+}
+
+// CreateEmailNote 为邮件添加私有笔记
+func (h *Handler) CreateEmailNote(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	emailID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	var req services.CreateEmailNoteRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	note, err := h.emailService.CreateEmailNote(c.Request.Context(), userID, emailID, &req)
+	if err != nil {
+		h.respondWithEmailNoteError(c, err, "Failed to create note")
+		return
+	}
+
+	h.respondWithCreated(c, note, "Note created")
+}
+
+// UpdateEmailNote 更新邮件笔记
+func (h *Handler) UpdateEmailNote(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	emailID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	noteID, exists := h.parseUintParam(c, "note_id")
+	if !exists {
+		return
+	}
+
+	var req services.UpdateEmailNoteRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	note, err := h.emailService.UpdateEmailNote(c.Request.Context(), userID, emailID, noteID, &req)
+	if err != nil {
+		h.respondWithEmailNoteError(c, err, "Failed to update note")
+		return
+	}
+
+	h.respondWithSuccess(c, note, "Note updated")
+}
+
+// DeleteEmailNote 删除邮件笔记
+func (h *Handler) DeleteEmailNote(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	emailID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	noteID, exists := h.parseUintParam(c, "note_id")
+	if !exists {
+		return
+	}
+
+	if err := h.emailService.DeleteEmailNote(c.Request.Context(), userID, emailID, noteID); err != nil {
+		h.respondWithEmailNoteError(c, err, "Failed to delete note")
+		return
+	}
+
+	h.respondWithSuccess(c, nil, "Note deleted")
+}
+
+// respondWithEmailNoteError 将笔记错误映射为HTTP状态码
+func (h *Handler) respondWithEmailNoteError(c *gin.Context, err error, message string) {
+	switch err.Error() {
+	case "email not found", "note not found":
+		h.respondWithError(c, http.StatusNotFound, err.Error())
+	case "note content cannot be empty":
+		h.respondWithError(c, http.StatusBadRequest, err.Error())
+	default:
+		h.respondWithError(c, http.StatusInternalServerError, message+": "+err.Error())
+	}
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"mime"
+	"net/http"
+
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportEmailPDF 把邮件渲染为PDF下载，bundle_attachments=true时连同附件打包为zip
+func (h *Handler) ExportEmailPDF(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	emailID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	var opts services.EmailPDFOptions
+	if err := c.ShouldBindQuery(&opts); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid query parameters: "+err.Error())
+		return
+	}
+
+	export, err := h.emailService.ExportEmailPDF(c.Request.Context(), userID, emailID, &opts)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidPaperSize):
+			h.respondWithError(c, http.StatusBadRequest, "Invalid paper size, expected A4, Letter or Legal")
+		case err.Error() == "email not found":
+			h.respondWithError(c, http.StatusNotFound, "Email not found")
+		default:
+			h.respondWithError(c, http.StatusInternalServerError, "Failed to export email")
+		}
+		return
+	}
+
+	c.Header("Content-Type", export.ContentType)
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": export.Filename}))
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Status(http.StatusOK)
+
+	// 附件在写入过程中读取，出错时响应已经开始，只能记录日志并中断
+	if _, err := export.WriteTo(c.Writer); err != nil {
+		log.Printf("Failed to write email export %d: %v", emailID, err)
+	}
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -24,6 +25,9 @@ type EmailSendHandler struct {
 	draftService    services.DraftService
 	templateService services.EmailTemplateService
 	db              *gorm.DB
+
+	organizationService services.OrganizationService
+	settingsService     services.SettingsService
 }
 
 // NewEmailSendHandler 创建邮件发送处理器
@@ -37,6 +41,16 @@ func NewEmailSendHandler(emailComposer services.EmailComposer, emailSender servi
 	}
 }
 
+// SetOrganizationService 设置组织服务，共享邮箱的 send_as 成员可以使用该账户发信
+func (h *EmailSendHandler) SetOrganizationService(organizationService services.OrganizationService) {
+	h.organizationService = organizationService
+}
+
+// SetSettingsService 设置用户设置服务，不带时区的定时发送时间按用户时区解析
+func (h *EmailSendHandler) SetSettingsService(settingsService services.SettingsService) {
+	h.settingsService = settingsService
+}
+
 // RegisterRoutes 注册路由
 func (h *EmailSendHandler) RegisterRoutes(router *gin.RouterGroup) {
 	emails := router.Group("/emails")
@@ -53,21 +67,34 @@ func (h *EmailSendHandler) RegisterRoutes(router *gin.RouterGroup) {
 		
 		// 重新发送邮件
 		emails.POST("/send/:send_id/resend", h.ResendEmail)
-		
-		// 草稿相关
-		emails.POST("/draft", h.SaveDraft)
-		emails.PUT("/draft/:id", h.UpdateDraft)
-		emails.GET("/draft/:id", h.GetDraft)
-		emails.GET("/drafts", h.ListDrafts)
-		emails.DELETE("/draft/:id", h.DeleteDraft)
-		
-		// 模板相关
-		emails.POST("/template", h.CreateTemplate)
-		emails.PUT("/template/:id", h.UpdateTemplate)
-		emails.GET("/template/:id", h.GetTemplate)
-		emails.GET("/templates", h.ListTemplates)
-		emails.DELETE("/template/:id", h.DeleteTemplate)
 	}
+
+	h.RegisterDraftRoutes(emails)
+}
+
+// RegisterDraftRoutes 注册草稿与模板路由（路由组需已挂载认证中间件）
+func (h *EmailSendHandler) RegisterDraftRoutes(emails *gin.RouterGroup) {
+	// 草稿相关
+	emails.POST("/draft", h.SaveDraft)
+	emails.PUT("/draft/:id", h.UpdateDraft)
+	emails.GET("/draft/:id", h.GetDraft)
+	emails.GET("/drafts", h.ListDrafts)
+	emails.POST("/drafts/import", h.ImportDrafts)
+	emails.DELETE("/draft/:id", h.DeleteDraft)
+	emails.PATCH("/draft/:id/autosave", h.AutosaveDraft)
+	emails.GET("/draft/:id/revisions", h.ListDraftRevisions)
+	emails.POST("/draft/:id/revisions/:revision_id/restore", h.RestoreDraftRevision)
+
+	// Markdown撰写
+	emails.POST("/markdown/preview", h.PreviewMarkdown)
+
+	// 模板相关
+	emails.POST("/template", h.CreateTemplate)
+	emails.PUT("/template/:id", h.UpdateTemplate)
+	emails.GET("/template/:id", h.GetTemplate)
+	emails.POST("/template/:id/preview", h.PreviewTemplate)
+	emails.GET("/templates", h.ListTemplates)
+	emails.DELETE("/template/:id", h.DeleteTemplate)
 }
 
 // SendEmailRequest 发送邮件请求
@@ -83,8 +110,8 @@ func (h *EmailSendHandler) SendEmail(c *gin.Context) {
 	var req SendEmailRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid request",
-			Message: err.Error(),
+			Error:   localize(c, "Invalid request"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
@@ -92,8 +119,8 @@ func (h *EmailSendHandler) SendEmail(c *gin.Context) {
 	// 验证账户权限
 	if err := h.validateAccountAccess(c, req.AccountID, userID); err != nil {
 		c.JSON(http.StatusForbidden, ErrorResponse{
-			Error:   "Access denied",
-			Message: "You don't have access to this email account",
+			Error:   localize(c, "Access denied"),
+			Message: localize(c, "You don't have access to this email account"),
 		})
 		return
 	}
@@ -104,15 +131,15 @@ func (h *EmailSendHandler) SendEmail(c *gin.Context) {
 		err := h.scheduleEmail(c.Request.Context(), userID, &req)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, ErrorResponse{
-				Error:   "Failed to schedule email",
-				Message: err.Error(),
+				Error:   localize(c, "Failed to schedule email"),
+				Message: localize(c, err.Error()),
 			})
 			return
 		}
 
 		c.JSON(http.StatusAccepted, SuccessResponse{
 			Success: true,
-			Message: "Email scheduled successfully",
+			Message: localize(c, "Email scheduled successfully"),
 			Data:    map[string]interface{}{
 				"scheduled_time": *req.ScheduledTime,
 			},
@@ -121,28 +148,35 @@ func (h *EmailSendHandler) SendEmail(c *gin.Context) {
 	}
 
 	// 立即发送
-	composedEmail, err := h.emailComposer.ComposeEmail(c.Request.Context(), &req.ComposeEmailRequest)
+	req.ComposeEmailRequest.UserID = userID
+	sizeLimit, err := services.AccountMessageSizeLimit(c.Request.Context(), h.db, req.AccountID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Failed to compose email",
-			Message: err.Error(),
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   localize(c, "Failed to compose email"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
+	req.ComposeEmailRequest.SizeLimit = sizeLimit
+	composedEmail, err := h.emailComposer.ComposeEmail(c.Request.Context(), &req.ComposeEmailRequest)
+	if err != nil {
+		h.respondWithComposeError(c, err.Error(), err)
+		return
+	}
 
 	// 发送邮件
 	result, err := h.emailSender.SendEmail(c.Request.Context(), composedEmail, req.AccountID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "Failed to send email",
-			Message: err.Error(),
+			Error:   localize(c, "Failed to send email"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
 
 	c.JSON(http.StatusAccepted, SuccessResponse{
 		Success: true,
-		Message: "Email queued for sending",
+		Message: localize(c, "Email queued for sending"),
 		Data:    result,
 	})
 }
@@ -160,8 +194,8 @@ func (h *EmailSendHandler) SendBulkEmails(c *gin.Context) {
 	var req SendBulkEmailsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid request",
-			Message: err.Error(),
+			Error:   localize(c, "Invalid request"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
@@ -169,8 +203,17 @@ func (h *EmailSendHandler) SendBulkEmails(c *gin.Context) {
 	// 验证账户权限
 	if err := h.validateAccountAccess(c, req.AccountID, userID); err != nil {
 		c.JSON(http.StatusForbidden, ErrorResponse{
-			Error:   "Access denied",
-			Message: "You don't have access to this email account",
+			Error:   localize(c, "Access denied"),
+			Message: localize(c, "You don't have access to this email account"),
+		})
+		return
+	}
+
+	sizeLimit, err := services.AccountMessageSizeLimit(c.Request.Context(), h.db, req.AccountID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   localize(c, "Failed to compose email"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
@@ -178,12 +221,11 @@ func (h *EmailSendHandler) SendBulkEmails(c *gin.Context) {
 	// 组装所有邮件
 	var composedEmails []*services.ComposedEmail
 	for i, emailReq := range req.Emails {
+		emailReq.UserID = userID
+		emailReq.SizeLimit = sizeLimit
 		composedEmail, err := h.emailComposer.ComposeEmail(c.Request.Context(), &emailReq)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, ErrorResponse{
-				Error:   "Failed to compose email",
-				Message: fmt.Sprintf("Error in email %d: %v", i+1, err),
-			})
+			h.respondWithComposeError(c, fmt.Sprintf("Error in email %d: %v", i+1, err), err)
 			return
 		}
 		composedEmails = append(composedEmails, composedEmail)
@@ -193,15 +235,15 @@ func (h *EmailSendHandler) SendBulkEmails(c *gin.Context) {
 	results, err := h.emailSender.SendBulkEmails(c.Request.Context(), composedEmails, req.AccountID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "Failed to send bulk emails",
-			Message: err.Error(),
+			Error:   localize(c, "Failed to send bulk emails"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
 
 	c.JSON(http.StatusAccepted, SuccessResponse{
 		Success: true,
-		Message: "Emails queued for sending",
+		Message: localize(c, "Emails queued for sending"),
 		Data:    results,
 	})
 }
@@ -213,8 +255,8 @@ func (h *EmailSendHandler) GetSendStatus(c *gin.Context) {
 	status, err := h.emailSender.GetSendStatus(c.Request.Context(), sendID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, ErrorResponse{
-			Error:   "Send status not found",
-			Message: err.Error(),
+			Error:   localize(c, "Send status not found"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
@@ -232,15 +274,15 @@ func (h *EmailSendHandler) ResendEmail(c *gin.Context) {
 	result, err := h.emailSender.ResendEmail(c.Request.Context(), sendID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "Failed to resend email",
-			Message: err.Error(),
+			Error:   localize(c, "Failed to resend email"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
 
 	c.JSON(http.StatusAccepted, SuccessResponse{
 		Success: true,
-		Message: "Email queued for resending",
+		Message: localize(c, "Email queued for resending"),
 		Data:    result,
 	})
 }
@@ -258,8 +300,8 @@ func (h *EmailSendHandler) SaveDraft(c *gin.Context) {
 	var req SaveDraftRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid request",
-			Message: err.Error(),
+			Error:   localize(c, "Invalid request"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
@@ -267,8 +309,8 @@ func (h *EmailSendHandler) SaveDraft(c *gin.Context) {
 	// 验证账户权限
 	if err := h.validateAccountAccess(c, req.AccountID, userID); err != nil {
 		c.JSON(http.StatusForbidden, ErrorResponse{
-			Error:   "Access denied",
-			Message: "You don't have access to this email account",
+			Error:   localize(c, "Access denied"),
+			Message: localize(c, "You don't have access to this email account"),
 		})
 		return
 	}
@@ -310,6 +352,7 @@ func (h *EmailSendHandler) SaveDraft(c *gin.Context) {
 		BCC:           bccAddresses,
 		TextBody:      req.TextBody,
 		HTMLBody:      req.HTMLBody,
+		MarkdownBody:  req.MarkdownBody,
 		AttachmentIDs: attachmentIDs,
 		Priority:      req.Priority,
 	}
@@ -318,15 +361,15 @@ func (h *EmailSendHandler) SaveDraft(c *gin.Context) {
 	draft, err := h.draftService.CreateDraft(c.Request.Context(), userID, draftReq)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "Failed to save draft",
-			Message: err.Error(),
+			Error:   localize(c, "Failed to save draft"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
 
 	c.JSON(http.StatusCreated, SuccessResponse{
 		Success: true,
-		Message: "Draft saved successfully",
+		Message: localize(c, "Draft saved successfully"),
 		Data:    draft,
 	})
 }
@@ -338,8 +381,8 @@ func (h *EmailSendHandler) UpdateDraft(c *gin.Context) {
 	draftID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid draft ID",
-			Message: err.Error(),
+			Error:   localize(c, "Invalid draft ID"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
@@ -347,8 +390,8 @@ func (h *EmailSendHandler) UpdateDraft(c *gin.Context) {
 	var req services.UpdateDraftRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid request",
-			Message: err.Error(),
+			Error:   localize(c, "Invalid request"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
@@ -358,13 +401,13 @@ func (h *EmailSendHandler) UpdateDraft(c *gin.Context) {
 	if err != nil {
 		if err.Error() == "draft not found or access denied" {
 			c.JSON(http.StatusNotFound, ErrorResponse{
-				Error:   "Draft not found",
-				Message: "Draft not found or access denied",
+				Error:   localize(c, "Draft not found"),
+				Message: localize(c, "Draft not found or access denied"),
 			})
 		} else {
 			c.JSON(http.StatusInternalServerError, ErrorResponse{
-				Error:   "Failed to update draft",
-				Message: err.Error(),
+				Error:   localize(c, "Failed to update draft"),
+				Message: localize(c, err.Error()),
 			})
 		}
 		return
@@ -372,7 +415,7 @@ func (h *EmailSendHandler) UpdateDraft(c *gin.Context) {
 
 	c.JSON(http.StatusOK, SuccessResponse{
 		Success: true,
-		Message: "Draft updated successfully",
+		Message: localize(c, "Draft updated successfully"),
 		Data:    draft,
 	})
 }
@@ -384,8 +427,8 @@ func (h *EmailSendHandler) GetDraft(c *gin.Context) {
 	draftID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid draft ID",
-			Message: err.Error(),
+			Error:   localize(c, "Invalid draft ID"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
@@ -395,13 +438,145 @@ func (h *EmailSendHandler) GetDraft(c *gin.Context) {
 	if err != nil {
 		if err.Error() == "draft not found or access denied" {
 			c.JSON(http.StatusNotFound, ErrorResponse{
-				Error:   "Draft not found",
-				Message: "Draft not found or access denied",
+				Error:   localize(c, "Draft not found"),
+				Message: localize(c, "Draft not found or access denied"),
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   localize(c, "Failed to get draft"),
+				Message: localize(c, err.Error()),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    draft,
+	})
+}
+
+// AutosaveDraft 自动保存草稿（部分更新，不做完整校验）
+func (h *EmailSendHandler) AutosaveDraft(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	draftID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   localize(c, "Invalid draft ID"),
+			Message: localize(c, err.Error()),
+		})
+		return
+	}
+
+	var req services.UpdateDraftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   localize(c, "Invalid request"),
+			Message: localize(c, err.Error()),
+		})
+		return
+	}
+
+	draft, err := h.draftService.AutosaveDraft(c.Request.Context(), userID, uint(draftID), &req)
+	if err != nil {
+		if err.Error() == "draft not found or access denied" {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   localize(c, "Draft not found"),
+				Message: localize(c, "Draft not found or access denied"),
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   localize(c, "Failed to autosave draft"),
+				Message: localize(c, err.Error()),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"id":             draft.ID,
+			"last_edited_at": draft.LastEditedAt,
+		},
+	})
+}
+
+// ListDraftRevisions 获取草稿修订历史
+func (h *EmailSendHandler) ListDraftRevisions(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	draftID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   localize(c, "Invalid draft ID"),
+			Message: localize(c, err.Error()),
+		})
+		return
+	}
+
+	revisions, err := h.draftService.ListDraftRevisions(c.Request.Context(), userID, uint(draftID))
+	if err != nil {
+		if err.Error() == "draft not found or access denied" {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   localize(c, "Draft not found"),
+				Message: localize(c, "Draft not found or access denied"),
 			})
 		} else {
 			c.JSON(http.StatusInternalServerError, ErrorResponse{
-				Error:   "Failed to get draft",
-				Message: err.Error(),
+				Error:   localize(c, "Failed to list draft revisions"),
+				Message: localize(c, err.Error()),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    revisions,
+	})
+}
+
+// RestoreDraftRevision 将草稿恢复到指定修订
+func (h *EmailSendHandler) RestoreDraftRevision(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	draftID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   localize(c, "Invalid draft ID"),
+			Message: localize(c, err.Error()),
+		})
+		return
+	}
+
+	revisionID, err := strconv.ParseUint(c.Param("revision_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   localize(c, "Invalid revision ID"),
+			Message: localize(c, err.Error()),
+		})
+		return
+	}
+
+	draft, err := h.draftService.RestoreDraftRevision(c.Request.Context(), userID, uint(draftID), uint(revisionID))
+	if err != nil {
+		switch err.Error() {
+		case "draft not found or access denied":
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   localize(c, "Draft not found"),
+				Message: localize(c, "Draft not found or access denied"),
+			})
+		case "draft revision not found":
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   localize(c, "Revision not found"),
+				Message: localize(c, err.Error()),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   localize(c, "Failed to restore draft revision"),
+				Message: localize(c, err.Error()),
 			})
 		}
 		return
@@ -409,6 +584,7 @@ func (h *EmailSendHandler) GetDraft(c *gin.Context) {
 
 	c.JSON(http.StatusOK, SuccessResponse{
 		Success: true,
+		Message: localize(c, "Draft restored successfully"),
 		Data:    draft,
 	})
 }
@@ -421,8 +597,8 @@ func (h *EmailSendHandler) ListDrafts(c *gin.Context) {
 	var req services.ListDraftsRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid query parameters",
-			Message: err.Error(),
+			Error:   localize(c, "Invalid query parameters"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
@@ -439,8 +615,8 @@ func (h *EmailSendHandler) ListDrafts(c *gin.Context) {
 	response, err := h.draftService.ListDrafts(c.Request.Context(), userID, &req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "Failed to list drafts",
-			Message: err.Error(),
+			Error:   localize(c, "Failed to list drafts"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
@@ -451,12 +627,65 @@ func (h *EmailSendHandler) ListDrafts(c *gin.Context) {
 	})
 }
 
+// ImportDraftsRequest 导入服务器草稿请求
+type ImportDraftsRequest struct {
+	AccountID uint `json:"account_id" binding:"required"`
+}
+
+// ImportDrafts 导入服务器Drafts文件夹中的草稿
+func (h *EmailSendHandler) ImportDrafts(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req ImportDraftsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   localize(c, "Invalid request"),
+			Message: localize(c, err.Error()),
+		})
+		return
+	}
+
+	imported, err := h.draftService.ImportRemoteDrafts(c.Request.Context(), userID, req.AccountID)
+	if err != nil {
+		if err.Error() == "account not found or access denied" {
+			c.JSON(http.StatusForbidden, ErrorResponse{
+				Error:   localize(c, "Access denied"),
+				Message: localize(c, "You don't have access to this email account"),
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   localize(c, "Failed to import drafts"),
+				Message: localize(c, err.Error()),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Message: localize(c, "Drafts imported successfully"),
+		Data: map[string]interface{}{
+			"imported": imported,
+		},
+	})
+}
+
 // scheduleEmail 安排定时发送邮件
 func (h *EmailSendHandler) scheduleEmail(ctx context.Context, userID uint, req *SendEmailRequest) error {
-	// 解析定时发送时间
+	// 解析定时发送时间，不带时区时按用户设置的时区解析
 	scheduledTime, err := time.Parse(time.RFC3339, *req.ScheduledTime)
 	if err != nil {
-		return fmt.Errorf("invalid scheduled time format: %w", err)
+		loc := time.UTC
+		if h.settingsService != nil {
+			if settings, settingsErr := h.settingsService.GetSettings(ctx, userID); settingsErr == nil {
+				loc = settings.Location()
+			}
+		}
+		var localErr error
+		scheduledTime, localErr = time.ParseInLocation("2006-01-02T15:04:05", *req.ScheduledTime, loc)
+		if localErr != nil {
+			return fmt.Errorf("invalid scheduled time format: %w", err)
+		}
 	}
 
 	// 检查时间是否在未来
@@ -493,8 +722,8 @@ func (h *EmailSendHandler) DeleteDraft(c *gin.Context) {
 	draftID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid draft ID",
-			Message: err.Error(),
+			Error:   localize(c, "Invalid draft ID"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
@@ -504,13 +733,13 @@ func (h *EmailSendHandler) DeleteDraft(c *gin.Context) {
 	if err != nil {
 		if err.Error() == "draft not found or access denied" {
 			c.JSON(http.StatusNotFound, ErrorResponse{
-				Error:   "Draft not found",
-				Message: "Draft not found or access denied",
+				Error:   localize(c, "Draft not found"),
+				Message: localize(c, "Draft not found or access denied"),
 			})
 		} else {
 			c.JSON(http.StatusInternalServerError, ErrorResponse{
-				Error:   "Failed to delete draft",
-				Message: err.Error(),
+				Error:   localize(c, "Failed to delete draft"),
+				Message: localize(c, err.Error()),
 			})
 		}
 		return
@@ -518,7 +747,7 @@ func (h *EmailSendHandler) DeleteDraft(c *gin.Context) {
 
 	c.JSON(http.StatusOK, SuccessResponse{
 		Success: true,
-		Message: "Draft deleted successfully",
+		Message: localize(c, "Draft deleted successfully"),
 	})
 }
 
@@ -529,8 +758,8 @@ func (h *EmailSendHandler) CreateTemplate(c *gin.Context) {
 	var req services.CreateEmailTemplateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid request",
-			Message: err.Error(),
+			Error:   localize(c, "Invalid request"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
@@ -538,15 +767,15 @@ func (h *EmailSendHandler) CreateTemplate(c *gin.Context) {
 	template, err := h.templateService.CreateTemplate(c.Request.Context(), userID, &req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "Failed to create template",
-			Message: err.Error(),
+			Error:   localize(c, "Failed to create template"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
 
 	c.JSON(http.StatusCreated, SuccessResponse{
 		Success: true,
-		Message: "Template created successfully",
+		Message: localize(c, "Template created successfully"),
 		Data:    template,
 	})
 }
@@ -558,8 +787,8 @@ func (h *EmailSendHandler) UpdateTemplate(c *gin.Context) {
 	templateID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid template ID",
-			Message: err.Error(),
+			Error:   localize(c, "Invalid template ID"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
@@ -567,8 +796,8 @@ func (h *EmailSendHandler) UpdateTemplate(c *gin.Context) {
 	var req services.UpdateEmailTemplateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid request",
-			Message: err.Error(),
+			Error:   localize(c, "Invalid request"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
@@ -577,18 +806,18 @@ func (h *EmailSendHandler) UpdateTemplate(c *gin.Context) {
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			c.JSON(http.StatusNotFound, ErrorResponse{
-				Error:   "Template not found",
-				Message: err.Error(),
+				Error:   localize(c, "Template not found"),
+				Message: localize(c, err.Error()),
 			})
 		} else if strings.Contains(err.Error(), "permission denied") {
 			c.JSON(http.StatusForbidden, ErrorResponse{
-				Error:   "Permission denied",
-				Message: err.Error(),
+				Error:   localize(c, "Permission denied"),
+				Message: localize(c, err.Error()),
 			})
 		} else {
 			c.JSON(http.StatusInternalServerError, ErrorResponse{
-				Error:   "Failed to update template",
-				Message: err.Error(),
+				Error:   localize(c, "Failed to update template"),
+				Message: localize(c, err.Error()),
 			})
 		}
 		return
@@ -596,7 +825,7 @@ func (h *EmailSendHandler) UpdateTemplate(c *gin.Context) {
 
 	c.JSON(http.StatusOK, SuccessResponse{
 		Success: true,
-		Message: "Template updated successfully",
+		Message: localize(c, "Template updated successfully"),
 		Data:    template,
 	})
 }
@@ -608,8 +837,8 @@ func (h *EmailSendHandler) GetTemplate(c *gin.Context) {
 	templateID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid template ID",
-			Message: err.Error(),
+			Error:   localize(c, "Invalid template ID"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
@@ -618,18 +847,18 @@ func (h *EmailSendHandler) GetTemplate(c *gin.Context) {
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			c.JSON(http.StatusNotFound, ErrorResponse{
-				Error:   "Template not found",
-				Message: err.Error(),
+				Error:   localize(c, "Template not found"),
+				Message: localize(c, err.Error()),
 			})
 		} else if strings.Contains(err.Error(), "permission denied") {
 			c.JSON(http.StatusForbidden, ErrorResponse{
-				Error:   "Permission denied",
-				Message: err.Error(),
+				Error:   localize(c, "Permission denied"),
+				Message: localize(c, err.Error()),
 			})
 		} else {
 			c.JSON(http.StatusInternalServerError, ErrorResponse{
-				Error:   "Failed to get template",
-				Message: err.Error(),
+				Error:   localize(c, "Failed to get template"),
+				Message: localize(c, err.Error()),
 			})
 		}
 		return
@@ -641,6 +870,87 @@ func (h *EmailSendHandler) GetTemplate(c *gin.Context) {
 	})
 }
 
+// TemplateValidationErrorResponse 模板变量校验失败的响应，列出每个变量的问题
+type TemplateValidationErrorResponse struct {
+	ErrorResponse
+	Issues []services.TemplateVariableIssue `json:"issues"`
+}
+
+// PreviewTemplate 使用给定变量预览模板渲染的最终内容，包括签名和内联图片，不发送
+func (h *EmailSendHandler) PreviewTemplate(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	templateID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   localize(c, "Invalid template ID"),
+			Message: localize(c, err.Error()),
+		})
+		return
+	}
+
+	var req services.TemplatePreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   localize(c, "Invalid request"),
+			Message: localize(c, err.Error()),
+		})
+		return
+	}
+
+	preview, err := h.templateService.PreviewTemplate(c.Request.Context(), userID, uint(templateID), &req)
+	if err != nil {
+		var validationErr *services.TemplateValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusUnprocessableEntity, TemplateValidationErrorResponse{
+				ErrorResponse: ErrorResponse{
+					Error:   localize(c, "Template variables are invalid"),
+					Message: localize(c, err.Error()),
+				},
+				Issues: validationErr.Issues,
+			})
+		} else if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   localize(c, "Template not found"),
+				Message: localize(c, err.Error()),
+			})
+		} else if strings.Contains(err.Error(), "permission denied") {
+			c.JSON(http.StatusForbidden, ErrorResponse{
+				Error:   localize(c, "Permission denied"),
+				Message: localize(c, err.Error()),
+			})
+		} else {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   localize(c, "Failed to render template"),
+				Message: localize(c, err.Error()),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    preview,
+	})
+}
+
+// PreviewMarkdown 预览Markdown正文渲染出的HTML和纯文本
+func (h *EmailSendHandler) PreviewMarkdown(c *gin.Context) {
+	var req services.MarkdownPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   localize(c, "Invalid request"),
+			Message: localize(c, err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    services.RenderMarkdown(req.Markdown, req.CodeHighlight),
+	})
+}
+
 // ListTemplates 列出模板
 func (h *EmailSendHandler) ListTemplates(c *gin.Context) {
 	userID := middleware.GetUserID(c)
@@ -648,8 +958,8 @@ func (h *EmailSendHandler) ListTemplates(c *gin.Context) {
 	var req services.ListEmailTemplatesRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid query parameters",
-			Message: err.Error(),
+			Error:   localize(c, "Invalid query parameters"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
@@ -662,11 +972,21 @@ func (h *EmailSendHandler) ListTemplates(c *gin.Context) {
 		req.PageSize = 20
 	}
 
+	switch req.Scope {
+	case "", services.TemplateScopeAll, services.TemplateScopePrivate, services.TemplateScopeShared:
+	default:
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   localize(c, "Invalid template scope"),
+			Message: localize(c, "scope must be one of: all, private, shared"),
+		})
+		return
+	}
+
 	response, err := h.templateService.ListTemplates(c.Request.Context(), userID, &req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "Failed to list templates",
-			Message: err.Error(),
+			Error:   localize(c, "Failed to list templates"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
@@ -684,8 +1004,8 @@ func (h *EmailSendHandler) DeleteTemplate(c *gin.Context) {
 	templateID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid template ID",
-			Message: err.Error(),
+			Error:   localize(c, "Invalid template ID"),
+			Message: localize(c, err.Error()),
 		})
 		return
 	}
@@ -694,18 +1014,18 @@ func (h *EmailSendHandler) DeleteTemplate(c *gin.Context) {
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			c.JSON(http.StatusNotFound, ErrorResponse{
-				Error:   "Template not found",
-				Message: err.Error(),
+				Error:   localize(c, "Template not found"),
+				Message: localize(c, err.Error()),
 			})
 		} else if strings.Contains(err.Error(), "permission denied") {
 			c.JSON(http.StatusForbidden, ErrorResponse{
-				Error:   "Permission denied",
-				Message: err.Error(),
+				Error:   localize(c, "Permission denied"),
+				Message: localize(c, err.Error()),
 			})
 		} else {
 			c.JSON(http.StatusInternalServerError, ErrorResponse{
-				Error:   "Failed to delete template",
-				Message: err.Error(),
+				Error:   localize(c, "Failed to delete template"),
+				Message: localize(c, err.Error()),
 			})
 		}
 		return
@@ -713,7 +1033,18 @@ func (h *EmailSendHandler) DeleteTemplate(c *gin.Context) {
 
 	c.JSON(http.StatusOK, SuccessResponse{
 		Success: true,
-		Message: "Template deleted successfully",
+		Message: localize(c, "Template deleted successfully"),
+	})
+}
+
+// respondWithComposeError 返回组装邮件失败的响应
+func (h *EmailSendHandler) respondWithComposeError(c *gin.Context, message string, err error) {
+	if respondWithMessageTooLarge(c, message, err) {
+		return
+	}
+	c.JSON(http.StatusBadRequest, ErrorResponse{
+		Error:   localize(c, "Failed to compose email"),
+		Message: localize(c, message),
 	})
 }
 
@@ -730,6 +1061,15 @@ func (h *EmailSendHandler) validateAccountAccess(c *gin.Context, accountID, user
 	}
 	
 	if count == 0 {
+		if h.organizationService != nil {
+			canSend, err := h.organizationService.CanSendAs(c.Request.Context(), userID, accountID)
+			if err != nil {
+				return err
+			}
+			if canSend {
+				return nil
+			}
+		}
 		return fmt.Errorf("account not found or access denied")
 	}
 	
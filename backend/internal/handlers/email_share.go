@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"errors"
+	"html/template"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sharedEmailPolicy 公开页面的内容安全策略：禁止脚本和外部资源，只加载本站的内联附件
+const sharedEmailPolicy = "default-src 'none'; img-src 'self' data:; style-src 'unsafe-inline'; base-uri 'none'; form-action 'none'; frame-ancestors 'none'"
+
+// sharedEmailPage 分享邮件的只读页面
+var sharedEmailPage = template.Must(template.New("shared-email").Funcs(template.FuncMap{
+	"size": services.FormatByteSize,
+}).Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex, nofollow">
+<meta name="referrer" content="no-referrer">
+<title>{{if .Email.Subject}}{{.Email.Subject}}{{else}}(无主题){{end}}</title>
+<style>
+body{margin:0;background:#f5f5f5;color:#222;font-family:-apple-system,"Segoe UI","PingFang SC","Microsoft YaHei",sans-serif}
+main{max-width:860px;margin:24px auto;background:#fff;border-radius:8px;padding:24px 32px;box-shadow:0 1px 3px rgba(0,0,0,.1)}
+h1{font-size:20px;margin:0 0 16px}
+dl{display:grid;grid-template-columns:max-content 1fr;gap:4px 12px;margin:0;font-size:14px;color:#555}
+dt{font-weight:600}dd{margin:0;word-break:break-all}
+article{border-top:1px solid #eee;margin-top:16px;padding-top:16px;overflow-x:auto}
+article img{max-width:100%;height:auto}
+pre{white-space:pre-wrap;word-wrap:break-word;font-family:inherit}
+section{border-top:1px solid #eee;margin-top:16px;padding-top:8px;font-size:14px}
+footer{margin-top:24px;font-size:12px;color:#999}
+</style>
+</head>
+<body>
+<main>
+<h1>{{if .Email.Subject}}{{.Email.Subject}}{{else}}(无主题){{end}}</h1>
+<dl>
+<dt>发件人</dt><dd>{{.Email.From}}</dd>
+{{if .Email.To}}<dt>收件人</dt><dd>{{.Email.To}}</dd>{{end}}
+{{if .Email.CC}}<dt>抄送</dt><dd>{{.Email.CC}}</dd>{{end}}
+{{if not .Email.Date.IsZero}}<dt>日期</dt><dd>{{.Email.Date.Format "2006-01-02 15:04:05 -0700"}}</dd>{{end}}
+</dl>
+<article>{{if .Body}}{{.Body}}{{else}}<pre>{{.Email.TextBody}}</pre>{{end}}</article>
+{{if .Email.Attachments}}<section>
+<h2>附件</h2>
+<ul>{{range .Email.Attachments}}<li><a href="{{.URL}}">{{.Filename}}</a> ({{size .Size}})</li>{{end}}</ul>
+</section>{{end}}
+<footer>此链接为只读分享，有效期至 {{.Email.ExpiresAt.Format "2006-01-02 15:04 MST"}}</footer>
+</main>
+</body>
+</html>
+`))
+
+// CreateEmailShare 为邮件创建带有效期的公开分享链接
+func (h *Handler) CreateEmailShare(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	emailID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	var req services.CreateEmailShareRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	link, err := h.emailShareService.CreateShare(c.Request.Context(), userID, emailID, &req)
+	if err != nil {
+		h.respondWithEmailShareError(c, err, "Failed to create share link")
+		return
+	}
+
+	h.absoluteShareURL(c, link)
+	h.respondWithCreated(c, link, "Share link created")
+}
+
+// GetEmailShares 获取邮件的分享链接列表
+func (h *Handler) GetEmailShares(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	emailID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	links, err := h.emailShareService.ListShares(c.Request.Context(), userID, emailID)
+	if err != nil {
+		h.respondWithEmailShareError(c, err, "Failed to get share links")
+		return
+	}
+
+	for _, link := range links {
+		h.absoluteShareURL(c, link)
+	}
+	h.respondWithSuccess(c, links)
+}
+
+// UpdateEmailShare 修改分享链接的附件访问权限
+func (h *Handler) UpdateEmailShare(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	shareID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	var req services.UpdateEmailShareRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	link, err := h.emailShareService.UpdateShare(c.Request.Context(), userID, shareID, &req)
+	if err != nil {
+		h.respondWithEmailShareError(c, err, "Failed to update share link")
+		return
+	}
+
+	h.absoluteShareURL(c, link)
+	h.respondWithSuccess(c, link, "Share link updated")
+}
+
+// RevokeEmailShare 撤销分享链接
+func (h *Handler) RevokeEmailShare(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	shareID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	if err := h.emailShareService.RevokeShare(c.Request.Context(), userID, shareID); err != nil {
+		h.respondWithEmailShareError(c, err, "Failed to revoke share link")
+		return
+	}
+
+	h.respondWithSuccess(c, nil, "Share link revoked")
+}
+
+// ViewSharedEmail 公开访问分享的邮件，返回只读HTML页面
+func (h *Handler) ViewSharedEmail(c *gin.Context) {
+	email, err := h.emailShareService.OpenShare(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		h.respondWithSharedPageError(c, err)
+		return
+	}
+
+	setSharedEmailHeaders(c)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := sharedEmailPage.Execute(c.Writer, struct {
+		Email *services.SharedEmail
+		Body  template.HTML
+	}{
+		Email: email,
+		// 正文已按白名单清理
+		Body: template.HTML(email.HTMLBody),
+	}); err != nil {
+		log.Printf("Failed to render shared email: %v", err)
+	}
+}
+
+// DownloadSharedAttachment 通过分享链接下载附件或加载内联图片
+func (h *Handler) DownloadSharedAttachment(c *gin.Context) {
+	attachmentID, exists := h.parseUintParam(c, "attachment_id")
+	if !exists {
+		return
+	}
+
+	attachment, content, err := h.emailShareService.OpenSharedAttachment(c.Request.Context(), c.Param("token"), attachmentID)
+	if err != nil {
+		h.respondWithSharedPageError(c, err)
+		return
+	}
+	defer content.Close()
+
+	contentType := attachment.ContentType
+	disposition := "attachment"
+	if attachment.IsInlineAttachment() && strings.HasPrefix(strings.ToLower(contentType), "image/") {
+		disposition = "inline"
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	setSharedEmailHeaders(c)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": attachment.Filename}))
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, content); err != nil {
+		log.Printf("Failed to stream shared attachment %d: %v", attachmentID, err)
+	}
+}
+
+// setSharedEmailHeaders 公开内容不缓存、不被索引，也不允许被嵌入其他页面
+func setSharedEmailHeaders(c *gin.Context) {
+	c.Header("Content-Security-Policy", sharedEmailPolicy)
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("X-Robots-Tag", "noindex, nofollow")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Header("Cache-Control", "private, no-store")
+}
+
+// absoluteShareURL 未配置SHARE_BASE_URL时按当前请求补全分享地址
+func (h *Handler) absoluteShareURL(c *gin.Context, link *services.EmailShareLink) {
+	if !strings.HasPrefix(link.URL, "/") {
+		return
+	}
+	link.URL = requestBaseURL(c) + link.URL
+}
+
+// publicBaseURL 公开链接使用的外部访问地址，优先使用SHARE_BASE_URL
+func (h *Handler) publicBaseURL(c *gin.Context) string {
+	if h.config != nil && h.config.Sharing.BaseURL != "" {
+		return h.config.Sharing.BaseURL
+	}
+	return requestBaseURL(c)
+}
+
+// requestBaseURL 按当前请求的协议和Host拼出访问地址
+func requestBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil || strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https") {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+// respondWithEmailShareError 分享管理接口的错误响应
+func (h *Handler) respondWithEmailShareError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidShareExpiry):
+		h.respondWithError(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrEmailShareNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Share link not found")
+	case err.Error() == "email not found":
+		h.respondWithError(c, http.StatusNotFound, "Email not found")
+	default:
+		h.respondWithError(c, http.StatusInternalServerError, fallback)
+	}
+}
+
+// respondWithSharedPageError 公开链接的错误页面，不区分链接不存在与签名错误
+func (h *Handler) respondWithSharedPageError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	message := "无法打开分享的邮件，请稍后重试。"
+	switch {
+	case errors.Is(err, services.ErrEmailShareExpired):
+		status = http.StatusGone
+		message = "该分享链接已过期。"
+	case errors.Is(err, services.ErrEmailShareNotFound):
+		status = http.StatusNotFound
+		message = "该分享链接无效或已被撤销。"
+	default:
+		log.Printf("Failed to open shared email: %v", err)
+	}
+
+	setSharedEmailHeaders(c)
+	c.Data(status, "text/plain; charset=utf-8", []byte(message))
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// transferBatchEmails 批量跨账户移动或复制，数量较多时返回202和后台任务
+func (h *Handler) transferBatchEmails(c *gin.Context, userID uint, req *BatchEmailOperation) {
+	targetFolderID := req.TargetFolderID
+	if targetFolderID == nil {
+		targetFolderID = req.FolderID
+	}
+	if targetFolderID == nil {
+		h.respondWithError(c, http.StatusBadRequest, "target_folder_id is required for "+req.Operation+" operation")
+		return
+	}
+
+	job, err := h.emailService.TransferEmails(c.Request.Context(), userID, &services.TransferEmailsRequest{
+		EmailIDs:        req.EmailIDs,
+		TargetAccountID: req.TargetAccountID,
+		TargetFolderID:  *targetFolderID,
+		Copy:            req.Operation == "copy",
+	})
+	if err != nil {
+		h.respondWithProviderError(c, http.StatusBadRequest, "Failed to transfer emails: ", err)
+		return
+	}
+
+	result := BatchEmailResult{
+		SuccessCount: job.Succeeded,
+		TotalCount:   job.Total,
+		Errors:       []string{},
+		Job:          job,
+	}
+	if job.LastError != "" {
+		result.Errors = append(result.Errors, job.LastError)
+	}
+
+	if !job.Finished() {
+		c.JSON(http.StatusAccepted, SuccessResponse{
+			Success: true,
+			Data:    result,
+			Message: localize(c, "Transfer job started"),
+		})
+		return
+	}
+	h.respondWithSuccess(c, result, "Batch operation completed")
+}
+
+// GetEmailTransferJob 获取跨账户移动/复制任务状态
+func (h *Handler) GetEmailTransferJob(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	job, err := h.emailService.GetEmailTransferJob(c.Request.Context(), userID, c.Param("job_id"))
+	if err != nil {
+		h.respondWithError(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, job)
+}
+
+// CancelEmailTransferJob 取消跨账户移动/复制任务
+func (h *Handler) CancelEmailTransferJob(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	job, err := h.emailService.CancelEmailTransferJob(c.Request.Context(), userID, c.Param("job_id"))
+	if err != nil {
+		h.respondWithError(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, job, "Transfer job cancelled")
+}
@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
+	"firemail/internal/models"
 	"firemail/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -17,18 +19,74 @@ func (h *Handler) GetEmails(c *gin.Context) {
 		return
 	}
 
-	// 解析查询参数
+	req, ok := h.parseEmailListRequest(c, userID)
+	if !ok {
+		return
+	}
+
+	response, err := h.emailService.GetEmails(c.Request.Context(), userID, req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidEmailCursor) || errors.Is(err, services.ErrInvalidEmailGrouping) {
+			h.respondWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get emails")
+		return
+	}
+
+	h.respondWithSuccess(c, response)
+}
+
+// parseEmailListRequest 解析邮件列表的过滤、排序和分页参数，参数无效时已写入错误响应
+func (h *Handler) parseEmailListRequest(c *gin.Context, userID uint) (*services.GetEmailsRequest, bool) {
+	pinnedFirst := h.parseOptionalBoolQuery(c, "pinned_first")
+	awaitingReply := h.parseOptionalBoolQuery(c, "awaiting_reply")
 	req := &services.GetEmailsRequest{
-		AccountID:   h.parseOptionalUintQuery(c, "account_id"),
-		FolderID:    h.parseOptionalUintQuery(c, "folder_id"),
-		IsRead:      h.parseOptionalBoolQuery(c, "is_read"),
-		IsStarred:   h.parseOptionalBoolQuery(c, "is_starred"),
-		IsImportant: h.parseOptionalBoolQuery(c, "is_important"),
-		Page:        h.parseIntQuery(c, "page", 1),
-		PageSize:    h.parseIntQuery(c, "page_size", 20),
-		SortBy:      c.DefaultQuery("sort_by", "date"),
-		SortOrder:   c.DefaultQuery("sort_order", "desc"),
-		SearchQuery: c.Query("search"),
+		AccountID:        h.parseOptionalUintQuery(c, "account_id"),
+		FolderID:         h.parseOptionalUintQuery(c, "folder_id"),
+		IsRead:           h.parseOptionalBoolQuery(c, "is_read"),
+		IsStarred:        h.parseOptionalBoolQuery(c, "is_starred"),
+		IsImportant:      h.parseOptionalBoolQuery(c, "is_important"),
+		ImportanceBucket: c.Query("importance_bucket"),
+		IsVIP:            h.parseOptionalBoolQuery(c, "is_vip"),
+		FlagColor:        c.Query("flag_color"),
+		IsFollowUp:       h.parseOptionalBoolQuery(c, "is_follow_up"),
+		IsAnswered:       h.parseOptionalBoolQuery(c, "is_answered"),
+		Page:             h.parseIntQuery(c, "page", 1),
+		PageSize:         h.parseIntQuery(c, "page_size", h.emailsPerPage(c, userID)),
+		SortBy:           c.DefaultQuery("sort_by", "date"),
+		SortOrder:        c.DefaultQuery("sort_order", "desc"),
+		SearchQuery:      c.Query("search"),
+		Cursor:           c.Query("cursor"),
+		PinnedFirst:      pinnedFirst != nil && *pinnedFirst,
+		AwaitingReply:    awaitingReply != nil && *awaitingReply,
+		View:             c.DefaultQuery("view", services.EmailListViewFull),
+		GroupBy:          c.Query("group_by"),
+	}
+
+	if req.View != services.EmailListViewFull && req.View != services.EmailListViewSummary {
+		h.respondWithError(c, http.StatusBadRequest, "view must be full or summary")
+		return nil, false
+	}
+	if req.ImportanceBucket != "" && req.ImportanceBucket != models.ImportanceBucketImportant && req.ImportanceBucket != models.ImportanceBucketOther {
+		h.respondWithError(c, http.StatusBadRequest, "importance_bucket must be important or other")
+		return nil, false
+	}
+	if !services.ValidEmailFlagColor(req.FlagColor) {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid flag_color")
+		return nil, false
+	}
+	if dueStr := c.Query("follow_up_due"); dueStr != "" {
+		due, err := time.Parse(time.RFC3339, dueStr)
+		if err != nil {
+			h.respondWithError(c, http.StatusBadRequest, "follow_up_due must be an RFC3339 time")
+			return nil, false
+		}
+		req.FollowUpDue = &due
+	}
+	if !services.ValidEmailGroupBy(req.GroupBy) {
+		h.respondWithError(c, http.StatusBadRequest, "group_by must be date or sender")
+		return nil, false
 	}
 
 	// 验证分页参数
@@ -37,13 +95,40 @@ func (h *Handler) GetEmails(c *gin.Context) {
 	// 验证排序参数
 	req.SortBy, req.SortOrder = h.validateSortParams(req.SortBy, req.SortOrder)
 
-	response, err := h.emailService.GetEmails(c.Request.Context(), userID, req)
+	return req, true
+}
+
+// GetEmailNavigation 按列表的过滤条件和排序获取邮件的上一封和下一封，跨越分页边界
+func (h *Handler) GetEmailNavigation(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	emailID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	req, ok := h.parseEmailListRequest(c, userID)
+	if !ok {
+		return
+	}
+
+	navigation, err := h.emailService.GetEmailNavigation(c.Request.Context(), userID, emailID, req)
 	if err != nil {
-		h.respondWithError(c, http.StatusInternalServerError, "Failed to get emails")
+		switch {
+		case errors.Is(err, services.ErrNavigationEmailNotFound):
+			h.respondWithError(c, http.StatusNotFound, "Email not found")
+		case errors.Is(err, services.ErrInvalidEmailGrouping):
+			h.respondWithError(c, http.StatusBadRequest, err.Error())
+		default:
+			h.respondWithError(c, http.StatusInternalServerError, "Failed to get email navigation")
+		}
 		return
 	}
 
-	h.respondWithSuccess(c, response)
+	h.respondWithSuccess(c, navigation)
 }
 
 // GetEmail 获取指定邮件
@@ -64,6 +149,7 @@ func (h *Handler) GetEmail(c *gin.Context) {
 		return
 	}
 
+	setVersionETag(c, email.Version)
 	h.respondWithSuccess(c, email)
 }
 
@@ -73,6 +159,7 @@ type UpdateEmailRequest struct {
 	IsStarred   *bool `json:"is_starred,omitempty"`
 	IsImportant *bool `json:"is_important,omitempty"`
 	FolderID    *uint `json:"folder_id,omitempty"`
+	Version     *uint `json:"version,omitempty"` // 读取到的邮件版本号，也可通过 If-Match 提交
 }
 
 // UpdateEmail 通用邮件更新处理器
@@ -98,20 +185,37 @@ func (h *Handler) UpdateEmail(c *gin.Context) {
 		return
 	}
 
+	expected, ok := h.ifMatchVersion(c)
+	if !ok {
+		return
+	}
+	if req.Version != nil {
+		expected = req.Version
+	}
+	// 版本号只在第一次修改时检查，之后的修改基于本次请求递增后的版本
+	ctx := c.Request.Context()
+	if expected != nil {
+		ctx = services.WithExpectedVersion(ctx, *expected)
+	}
+
 	// 执行更新操作
 	var err error
 
 	// 处理已读状态更新
 	if req.IsRead != nil {
 		if *req.IsRead {
-			err = h.emailService.MarkEmailAsRead(c.Request.Context(), userID, emailID)
+			err = h.emailService.MarkEmailAsRead(ctx, userID, emailID)
 		} else {
-			err = h.emailService.MarkEmailAsUnread(c.Request.Context(), userID, emailID)
+			err = h.emailService.MarkEmailAsUnread(ctx, userID, emailID)
 		}
 		if err != nil {
+			if respondWithVersionConflict(c, err) {
+				return
+			}
 			h.respondWithError(c, http.StatusBadRequest, "Failed to update read status: "+err.Error())
 			return
 		}
+		ctx = c.Request.Context()
 	}
 
 	// 处理星标状态更新
@@ -125,11 +229,15 @@ func (h *Handler) UpdateEmail(c *gin.Context) {
 
 		// 只有当目标状态与当前状态不同时才切换
 		if email.IsStarred != *req.IsStarred {
-			err = h.emailService.ToggleEmailStar(c.Request.Context(), userID, emailID)
+			err = h.emailService.ToggleEmailStar(ctx, userID, emailID)
 			if err != nil {
+				if respondWithVersionConflict(c, err) {
+					return
+				}
 				h.respondWithError(c, http.StatusBadRequest, "Failed to update star status: "+err.Error())
 				return
 			}
+			ctx = c.Request.Context()
 		}
 	}
 
@@ -144,19 +252,26 @@ func (h *Handler) UpdateEmail(c *gin.Context) {
 
 		// 只有当目标状态与当前状态不同时才切换
 		if email.IsImportant != *req.IsImportant {
-			err = h.emailService.ToggleEmailImportant(c.Request.Context(), userID, emailID)
+			err = h.emailService.ToggleEmailImportant(ctx, userID, emailID)
 			if err != nil {
+				if respondWithVersionConflict(c, err) {
+					return
+				}
 				h.respondWithError(c, http.StatusBadRequest, "Failed to update important status: "+err.Error())
 				return
 			}
+			ctx = c.Request.Context()
 		}
 	}
 
 	// 处理文件夹移动
 	if req.FolderID != nil {
-		err = h.emailService.MoveEmail(c.Request.Context(), userID, emailID, *req.FolderID)
+		err = h.emailService.MoveEmail(ctx, userID, emailID, *req.FolderID)
 		if err != nil {
-			h.respondWithError(c, http.StatusBadRequest, "Failed to move email: "+err.Error())
+			if respondWithVersionConflict(c, err) {
+				return
+			}
+			h.respondWithProviderError(c, http.StatusBadRequest, "Failed to move email: ", err)
 			return
 		}
 	}
@@ -168,6 +283,7 @@ func (h *Handler) UpdateEmail(c *gin.Context) {
 		return
 	}
 
+	setVersionETag(c, updatedEmail.Version)
 	h.respondWithSuccess(c, updatedEmail, "Email updated successfully")
 }
 
@@ -182,9 +298,15 @@ func (h *Handler) SendEmail(c *gin.Context) {
 	if !h.bindJSON(c, &req) {
 		return
 	}
+	if req.OffloadLargeAttachments {
+		req.LinkBaseURL = h.publicBaseURL(c)
+	}
 
 	err := h.emailService.SendEmail(c.Request.Context(), userID, &req)
 	if err != nil {
+		if respondWithMessageTooLarge(c, "Failed to send email: "+err.Error(), err) {
+			return
+		}
 		h.respondWithError(c, http.StatusBadRequest, "Failed to send email: "+err.Error())
 		return
 	}
@@ -204,9 +326,21 @@ func (h *Handler) DeleteEmail(c *gin.Context) {
 		return
 	}
 
-	err := h.emailService.DeleteEmail(c.Request.Context(), userID, emailID)
+	ctx, ok := h.versionedContext(c)
+	if !ok {
+		return
+	}
+
+	err := h.emailService.DeleteEmail(ctx, userID, emailID)
 	if err != nil {
-		h.respondWithError(c, http.StatusBadRequest, "Failed to delete email: "+err.Error())
+		if respondWithVersionConflict(c, err) {
+			return
+		}
+		if errors.Is(err, services.ErrEmailUnderLegalHold) {
+			h.respondWithError(c, http.StatusConflict, err.Error())
+			return
+		}
+		h.respondWithProviderError(c, http.StatusBadRequest, "Failed to delete email: ", err)
 		return
 	}
 
@@ -225,9 +359,17 @@ func (h *Handler) MarkEmailAsRead(c *gin.Context) {
 		return
 	}
 
-	err := h.emailService.MarkEmailAsRead(c.Request.Context(), userID, emailID)
+	ctx, ok := h.versionedContext(c)
+	if !ok {
+		return
+	}
+
+	err := h.emailService.MarkEmailAsRead(ctx, userID, emailID)
 	if err != nil {
-		h.respondWithError(c, http.StatusBadRequest, "Failed to mark email as read: "+err.Error())
+		if respondWithVersionConflict(c, err) {
+			return
+		}
+		h.respondWithProviderError(c, http.StatusBadRequest, "Failed to mark email as read: ", err)
 		return
 	}
 
@@ -246,9 +388,17 @@ func (h *Handler) MarkEmailAsUnread(c *gin.Context) {
 		return
 	}
 
-	err := h.emailService.MarkEmailAsUnread(c.Request.Context(), userID, emailID)
+	ctx, ok := h.versionedContext(c)
+	if !ok {
+		return
+	}
+
+	err := h.emailService.MarkEmailAsUnread(ctx, userID, emailID)
 	if err != nil {
-		h.respondWithError(c, http.StatusBadRequest, "Failed to mark email as unread: "+err.Error())
+		if respondWithVersionConflict(c, err) {
+			return
+		}
+		h.respondWithProviderError(c, http.StatusBadRequest, "Failed to mark email as unread: ", err)
 		return
 	}
 
@@ -267,8 +417,16 @@ func (h *Handler) ToggleEmailStar(c *gin.Context) {
 		return
 	}
 
-	err := h.emailService.ToggleEmailStar(c.Request.Context(), userID, emailID)
+	ctx, ok := h.versionedContext(c)
+	if !ok {
+		return
+	}
+
+	err := h.emailService.ToggleEmailStar(ctx, userID, emailID)
 	if err != nil {
+		if respondWithVersionConflict(c, err) {
+			return
+		}
 		h.respondWithError(c, http.StatusBadRequest, "Failed to toggle email star: "+err.Error())
 		return
 	}
@@ -276,9 +434,134 @@ func (h *Handler) ToggleEmailStar(c *gin.Context) {
 	h.respondWithSuccess(c, nil, "Email star toggled")
 }
 
+// SetEmailFlag 设置邮件的星标颜色和后续跟进旗标
+func (h *Handler) SetEmailFlag(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	emailID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	var req services.SetEmailFlagRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	ctx, ok := h.versionedContext(c)
+	if !ok {
+		return
+	}
+
+	email, err := h.emailService.SetEmailFlag(ctx, userID, emailID, &req)
+	if err != nil {
+		if respondWithVersionConflict(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, services.ErrInvalidEmailFlag):
+			h.respondWithError(c, http.StatusBadRequest, err.Error())
+		case err.Error() == "email not found":
+			h.respondWithError(c, http.StatusNotFound, "Email not found")
+		default:
+			h.respondWithError(c, http.StatusInternalServerError, "Failed to update email flag")
+		}
+		return
+	}
+
+	h.respondWithSuccess(c, email, "Email flag updated")
+}
+
+// ToggleEmailPin 切换邮件置顶状态
+func (h *Handler) ToggleEmailPin(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	emailID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	ctx, ok := h.versionedContext(c)
+	if !ok {
+		return
+	}
+
+	email, err := h.emailService.ToggleEmailPin(ctx, userID, emailID)
+	if err != nil {
+		if respondWithVersionConflict(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, services.ErrTooManyPinnedEmails):
+			h.respondWithError(c, http.StatusConflict, err.Error())
+		case err.Error() == "email not found":
+			h.respondWithError(c, http.StatusNotFound, "Email not found")
+		default:
+			h.respondWithError(c, http.StatusInternalServerError, "Failed to toggle email pin")
+		}
+		return
+	}
+
+	if email.IsPinned {
+		h.respondWithSuccess(c, email, "Email pinned")
+		return
+	}
+	h.respondWithSuccess(c, email, "Email unpinned")
+}
+
+// MarkEmailPriorityImportant 反馈邮件应归入优先收件箱的重要分类
+func (h *Handler) MarkEmailPriorityImportant(c *gin.Context) {
+	h.setEmailImportance(c, models.ImportanceBucketImportant)
+}
+
+// MarkEmailPriorityOther 反馈邮件不重要
+func (h *Handler) MarkEmailPriorityOther(c *gin.Context) {
+	h.setEmailImportance(c, models.ImportanceBucketOther)
+}
+
+func (h *Handler) setEmailImportance(c *gin.Context, bucket string) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	emailID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	ctx, ok := h.versionedContext(c)
+	if !ok {
+		return
+	}
+
+	email, err := h.emailService.SetEmailImportance(ctx, userID, emailID, bucket)
+	if err != nil {
+		if respondWithVersionConflict(c, err) {
+			return
+		}
+		if err.Error() == "email not found" {
+			h.respondWithError(c, http.StatusNotFound, "Email not found")
+			return
+		}
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to update email importance")
+		return
+	}
+
+	h.respondWithSuccess(c, email, "Email importance updated")
+}
+
 // MoveEmailRequest 移动邮件请求
 type MoveEmailRequest struct {
-	TargetFolderID uint `json:"target_folder_id" binding:"required"`
+	TargetFolderID  uint  `json:"target_folder_id" binding:"required"`
+	TargetAccountID *uint `json:"target_account_id"` // 指定时可跨账户移动
+	Copy            bool  `json:"copy"`              // 复制到目标文件夹并保留源邮件
 }
 
 // MoveEmail 移动邮件
@@ -298,15 +581,143 @@ func (h *Handler) MoveEmail(c *gin.Context) {
 		return
 	}
 
-	err := h.emailService.MoveEmail(c.Request.Context(), userID, emailID, req.TargetFolderID)
+	if req.TargetAccountID != nil || req.Copy {
+		job, err := h.emailService.TransferEmails(c.Request.Context(), userID, &services.TransferEmailsRequest{
+			EmailIDs:        []uint{emailID},
+			TargetAccountID: req.TargetAccountID,
+			TargetFolderID:  req.TargetFolderID,
+			Copy:            req.Copy,
+		})
+		if err == nil && job.Failed > 0 {
+			err = errors.New(job.LastError)
+		}
+		if err != nil {
+			h.respondWithProviderError(c, http.StatusBadRequest, "Failed to transfer email: ", err)
+			return
+		}
+		h.respondWithSuccess(c, job, "Email transferred successfully")
+		return
+	}
+
+	ctx, ok := h.versionedContext(c)
+	if !ok {
+		return
+	}
+
+	err := h.emailService.MoveEmail(ctx, userID, emailID, req.TargetFolderID)
 	if err != nil {
-		h.respondWithError(c, http.StatusBadRequest, "Failed to move email: "+err.Error())
+		if respondWithVersionConflict(c, err) {
+			return
+		}
+		h.respondWithProviderError(c, http.StatusBadRequest, "Failed to move email: ", err)
 		return
 	}
 
 	h.respondWithSuccess(c, nil, "Email moved successfully")
 }
 
+// RedecodeEmailRequest 重新解码邮件请求
+type RedecodeEmailRequest struct {
+	Charset string `json:"charset" binding:"required"`
+}
+
+// RedecodeEmail 使用指定字符集重新解码邮件
+func (h *Handler) RedecodeEmail(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	emailID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	var req RedecodeEmailRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	email, err := h.emailService.RedecodeEmail(c.Request.Context(), userID, emailID, req.Charset)
+	if err != nil {
+		h.respondWithProviderError(c, http.StatusBadRequest, "Failed to re-decode email: ", err)
+		return
+	}
+
+	h.respondWithSuccess(c, email, "Email re-decoded successfully")
+}
+
+// ReparseEmail 使用当前解析器重新解析邮件
+func (h *Handler) ReparseEmail(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	emailID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	email, err := h.emailService.ReparseEmail(c.Request.Context(), userID, emailID)
+	if err != nil {
+		h.respondWithProviderError(c, http.StatusBadRequest, "Failed to reparse email: ", err)
+		return
+	}
+
+	h.respondWithSuccess(c, email, "Email reparsed successfully")
+}
+
+// GetEmailSource 查看邮件原始邮件头和MIME结构
+func (h *Handler) GetEmailSource(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	emailID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	source, err := h.emailService.GetEmailSource(c.Request.Context(), userID, emailID)
+	if err != nil {
+		if err.Error() == "email not found" {
+			h.respondWithError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		h.respondWithProviderError(c, http.StatusBadRequest, "Failed to get email source: ", err)
+		return
+	}
+
+	h.respondWithSuccess(c, source)
+}
+
+// AnalyzeEmailHeaders 分析邮件投递链延迟、认证结果和来源IP
+func (h *Handler) AnalyzeEmailHeaders(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	emailID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	analysis, err := h.emailService.AnalyzeEmailHeaders(c.Request.Context(), userID, emailID)
+	if err != nil {
+		if err.Error() == "email not found" {
+			h.respondWithError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		h.respondWithProviderError(c, http.StatusBadRequest, "Failed to analyze email headers: ", err)
+		return
+	}
+
+	h.respondWithSuccess(c, analysis)
+}
+
 // SearchEmails 搜索邮件
 func (h *Handler) SearchEmails(c *gin.Context) {
 	userID, exists := h.getCurrentUserID(c)
@@ -323,11 +734,19 @@ func (h *Handler) SearchEmails(c *gin.Context) {
 		From:          c.Query("from"),
 		To:            c.Query("to"),
 		Body:          c.Query("body"),
+		Note:          c.Query("note"),
 		HasAttachment: h.parseOptionalBoolQuery(c, "has_attachment"),
 		IsRead:        h.parseOptionalBoolQuery(c, "is_read"),
 		IsStarred:     h.parseOptionalBoolQuery(c, "is_starred"),
 		Page:          h.parseIntQuery(c, "page", 1),
-		PageSize:      h.parseIntQuery(c, "page_size", 20),
+		PageSize:      h.parseIntQuery(c, "page_size", h.emailsPerPage(c, userID)),
+		Cursor:        c.Query("cursor"),
+		Mode:          c.DefaultQuery("mode", services.SearchModeKeyword),
+	}
+
+	if req.Mode != services.SearchModeKeyword && req.Mode != services.SearchModeSemantic {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid search mode")
+		return
 	}
 
 	// 解析时间参数
@@ -354,6 +773,14 @@ func (h *Handler) SearchEmails(c *gin.Context) {
 
 	response, err := h.emailService.SearchEmails(c.Request.Context(), userID, req)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidEmailCursor) {
+			h.respondWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrSemanticSearchUnavailable) {
+			h.respondWithError(c, http.StatusServiceUnavailable, "Semantic search is not configured")
+			return
+		}
 		h.respondWithError(c, http.StatusInternalServerError, "Failed to search emails")
 		return
 	}
@@ -363,10 +790,11 @@ func (h *Handler) SearchEmails(c *gin.Context) {
 
 // BatchEmailOperation 批量邮件操作请求
 type BatchEmailOperation struct {
-	EmailIDs       []uint `json:"email_ids" binding:"required"`
-	Operation      string `json:"operation" binding:"required,oneof=read unread delete star unstar move"`
-	FolderID       *uint  `json:"folder_id"`        // 兼容旧字段
-	TargetFolderID *uint  `json:"target_folder_id"` // 用于move操作
+	EmailIDs        []uint `json:"email_ids" binding:"required"`
+	Operation       string `json:"operation" binding:"required,oneof=read unread delete star unstar move copy"`
+	FolderID        *uint  `json:"folder_id"`         // 兼容旧字段
+	TargetFolderID  *uint  `json:"target_folder_id"`  // 用于move和copy操作
+	TargetAccountID *uint  `json:"target_account_id"` // 指定时可跨账户移动
 }
 
 // ReplyEmail 回复邮件
@@ -461,7 +889,7 @@ func (h *Handler) ArchiveEmail(c *gin.Context) {
 
 	err := h.emailService.ArchiveEmail(c.Request.Context(), userID, emailID)
 	if err != nil {
-		h.respondWithError(c, http.StatusBadRequest, "Failed to archive email: "+err.Error())
+		h.respondWithProviderError(c, http.StatusBadRequest, "Failed to archive email: ", err)
 		return
 	}
 
@@ -490,6 +918,11 @@ func (h *Handler) BatchEmailOperations(c *gin.Context) {
 		return
 	}
 
+	if req.Operation == "copy" || (req.Operation == "move" && req.TargetAccountID != nil) {
+		h.transferBatchEmails(c, userID, &req)
+		return
+	}
+
 	var errors []string
 	successCount := 0
 
@@ -535,7 +968,7 @@ func (h *Handler) BatchEmailOperations(c *gin.Context) {
 	}
 
 	if len(errors) > 0 {
-		h.respondWithSuccess(c, result, fmt.Sprintf("Batch operation completed with %d errors", len(errors)))
+		h.respondWithSuccess(c, result, localizef(c, "Batch operation completed with %d errors", len(errors)))
 	} else {
 		h.respondWithSuccess(c, result, "Batch operation completed successfully")
 	}
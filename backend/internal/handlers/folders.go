@@ -1,10 +1,9 @@
 package handlers
 
 import (
-	"context"
-	"log"
+	"errors"
 	"net/http"
-	"time"
+	"strings"
 
 	"firemail/internal/services"
 
@@ -170,19 +169,148 @@ func (h *Handler) SyncFolder(c *gin.Context) {
 		return
 	}
 
-	// 启动异步同步
-	go func() {
-		// 为异步操作创建独立的context，避免使用HTTP请求的context
-		// HTTP请求的context在响应返回后会被取消，导致异步操作失败
-		syncCtx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
-		defer cancel()
+	// 验证文件夹属于当前用户，再入队同步任务
+	if _, err := h.emailService.GetFolder(c.Request.Context(), userID, folderID); err != nil {
+		h.respondWithError(c, http.StatusNotFound, "Folder not found")
+		return
+	}
+	if _, err := services.EnqueueFolderSync(c.Request.Context(), h.jobQueue, userID, folderID); err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to start folder sync")
+		return
+	}
+
+	h.respondWithSuccess(c, nil, "Folder sync started")
+}
+
+// ReconcileFolderCounts 按数据库中的邮件重新统计文件夹和账户的邮件数、未读数，报告并修正偏差
+func (h *Handler) ReconcileFolderCounts(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
 
-		if err := h.emailService.SyncSpecificFolder(syncCtx, userID, folderID); err != nil {
-			// 记录错误，但不影响响应
-			// 可以通过SSE通知前端同步失败
-			log.Printf("Failed to sync folder %d for user %d: %v", folderID, userID, err)
+	var req services.ReconcileFolderCountsRequest
+	if c.Request.ContentLength > 0 && !h.bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.emailService.ReconcileFolderCounts(c.Request.Context(), userID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrFolderCountScopeNotFound) {
+			h.respondWithError(c, http.StatusNotFound, err.Error())
+			return
 		}
-	}()
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to reconcile folder counts: "+err.Error())
+		return
+	}
 
-	h.respondWithSuccess(c, nil, "Folder sync started")
+	h.respondWithSuccess(c, result, "Folder counts reconciled")
+}
+
+// UpdateFolderAppearance 更新文件夹的颜色、图标和显示顺序
+func (h *Handler) UpdateFolderAppearance(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	folderID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	var req services.AppearanceRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	folder, err := h.emailService.UpdateFolderAppearance(c.Request.Context(), userID, folderID, &req)
+	if err != nil {
+		h.respondWithAppearanceError(c, err, "Failed to update folder appearance")
+		return
+	}
+
+	h.respondWithSuccess(c, folder, "Folder appearance updated")
+}
+
+// GetLabels 获取账户的标签及其显示属性
+func (h *Handler) GetLabels(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	accountID := h.parseUintQuery(c, "account_id", 0)
+	if accountID == 0 {
+		h.respondWithError(c, http.StatusBadRequest, "account_id parameter is required")
+		return
+	}
+
+	labels, err := h.emailService.GetLabels(c.Request.Context(), userID, accountID)
+	if err != nil {
+		h.respondWithAppearanceError(c, err, "Failed to get labels")
+		return
+	}
+
+	h.respondWithSuccess(c, labels)
+}
+
+// UpdateLabelAppearance 设置标签的颜色、图标和显示顺序
+func (h *Handler) UpdateLabelAppearance(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	accountID := h.parseUintQuery(c, "account_id", 0)
+	if accountID == 0 {
+		h.respondWithError(c, http.StatusBadRequest, "account_id parameter is required")
+		return
+	}
+
+	var req services.UpdateLabelAppearanceRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	label, err := h.emailService.UpdateLabelAppearance(c.Request.Context(), userID, accountID, &req)
+	if err != nil {
+		h.respondWithAppearanceError(c, err, "Failed to update label appearance")
+		return
+	}
+
+	h.respondWithSuccess(c, label, "Label appearance updated")
+}
+
+// DeleteLabelAppearance 删除标签的显示属性
+func (h *Handler) DeleteLabelAppearance(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	labelID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	if err := h.emailService.DeleteLabelAppearance(c.Request.Context(), userID, labelID); err != nil {
+		h.respondWithAppearanceError(c, err, "Failed to delete label appearance")
+		return
+	}
+
+	h.respondWithSuccess(c, nil, "Label appearance deleted")
+}
+
+// respondWithAppearanceError 将显示属性错误映射为HTTP状态码
+func (h *Handler) respondWithAppearanceError(c *gin.Context, err error, message string) {
+	msg := err.Error()
+	switch {
+	case msg == "folder not found" || msg == "label not found" || strings.HasPrefix(msg, "email account not found"):
+		h.respondWithError(c, http.StatusNotFound, message+": "+msg)
+	case strings.HasPrefix(msg, "invalid ") || strings.HasPrefix(msg, "label name"):
+		h.respondWithError(c, http.StatusBadRequest, message+": "+msg)
+	default:
+		h.respondWithError(c, http.StatusInternalServerError, message+": "+msg)
+	}
 }
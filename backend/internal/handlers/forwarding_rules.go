@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetForwardingRules 获取自动转发规则列表
+func (h *Handler) GetForwardingRules(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	rules, err := h.forwardingService.ListRules(c.Request.Context(), userID)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get forwarding rules: "+err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, rules)
+}
+
+// CreateForwardingRule 创建自动转发规则
+func (h *Handler) CreateForwardingRule(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	var req services.ForwardingRuleRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	rule, err := h.forwardingService.CreateRule(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondWithForwardingError(c, err, "Failed to create forwarding rule")
+		return
+	}
+
+	h.respondWithCreated(c, rule, "Forwarding rule created")
+}
+
+// UpdateForwardingRule 修改自动转发规则，包括启用和停用
+func (h *Handler) UpdateForwardingRule(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	ruleID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	var req services.ForwardingRuleRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	rule, err := h.forwardingService.UpdateRule(c.Request.Context(), userID, ruleID, &req)
+	if err != nil {
+		h.respondWithForwardingError(c, err, "Failed to update forwarding rule")
+		return
+	}
+
+	h.respondWithSuccess(c, rule, "Forwarding rule updated")
+}
+
+// DeleteForwardingRule 删除自动转发规则
+func (h *Handler) DeleteForwardingRule(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	ruleID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	if err := h.forwardingService.DeleteRule(c.Request.Context(), userID, ruleID); err != nil {
+		h.respondWithForwardingError(c, err, "Failed to delete forwarding rule")
+		return
+	}
+
+	h.respondWithSuccess(c, nil, "Forwarding rule deleted")
+}
+
+// respondWithForwardingError 将转发规则服务错误映射为HTTP状态码
+func (h *Handler) respondWithForwardingError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrForwardingRuleNotFound):
+		h.respondWithError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrInvalidForwardingRule):
+		h.respondWithError(c, http.StatusBadRequest, err.Error())
+	default:
+		h.respondWithError(c, http.StatusInternalServerError, message+": "+err.Error())
+	}
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetGmailLabelMappings 获取Gmail账户各标签作为文件夹、合并或跳过的映射方式
+func (h *Handler) GetGmailLabelMappings(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	accountID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	mappings, err := h.emailService.GetGmailLabelMappings(c.Request.Context(), userID, accountID)
+	if err != nil {
+		h.respondWithGmailLabelError(c, err, "Failed to get Gmail label mappings")
+		return
+	}
+
+	h.respondWithSuccess(c, mappings)
+}
+
+// UpdateGmailLabelMappings 修改Gmail账户的标签映射方式，下次同步起生效
+func (h *Handler) UpdateGmailLabelMappings(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	accountID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	var req services.UpdateGmailLabelMappingsRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	mappings, err := h.emailService.UpdateGmailLabelMappings(c.Request.Context(), userID, accountID, &req)
+	if err != nil {
+		h.respondWithGmailLabelError(c, err, "Failed to update Gmail label mappings")
+		return
+	}
+
+	h.respondWithSuccess(c, mappings, "Gmail label mappings updated")
+}
+
+// respondWithGmailLabelError 把标签映射错误转换为HTTP响应
+func (h *Handler) respondWithGmailLabelError(c *gin.Context, err error, message string) {
+	switch {
+	case strings.HasPrefix(err.Error(), "email account not found"):
+		h.respondWithError(c, http.StatusNotFound, "Email account not found")
+	case err.Error() == "folder not found":
+		h.respondWithError(c, http.StatusNotFound, "Folder not found")
+	case errors.Is(err, services.ErrNotGmailAccount), errors.Is(err, services.ErrInvalidLabelMode):
+		h.respondWithError(c, http.StatusBadRequest, err.Error())
+	default:
+		h.respondWithError(c, http.StatusInternalServerError, message)
+	}
+}
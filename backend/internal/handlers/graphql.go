@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"firemail/internal/graphql"
+	"firemail/internal/models"
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Schema只依赖类型定义，解析函数通过上下文中的加载器访问服务
+var buildGraphQLSchema = sync.OnceValues(newGraphQLSchema)
+
+// GraphQL 执行只读的GraphQL查询
+func (h *Handler) GraphQL(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	schema, err := buildGraphQLSchema()
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to build GraphQL schema: "+err.Error())
+		return
+	}
+
+	var req graphql.Request
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	ctx := withGraphQLLoader(c.Request.Context(), newGraphQLLoader(h.emailService, userID))
+	resp := schema.Execute(ctx, req, graphql.ExecuteOptions{MaxDepth: h.config.GraphQL.MaxDepth})
+
+	// 语法或校验错误时没有data，按请求错误返回
+	status := http.StatusOK
+	if resp.Data == nil {
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, resp)
+}
+
+// GetGraphQLSchema 以SDL格式返回GraphQL类型定义
+func (h *Handler) GetGraphQLSchema(c *gin.Context) {
+	schema, err := buildGraphQLSchema()
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to build GraphQL schema: "+err.Error())
+		return
+	}
+	c.String(http.StatusOK, schema.SDL())
+}
+
+type graphqlLoaderKey struct{}
+
+func withGraphQLLoader(ctx context.Context, loader *graphqlLoader) context.Context {
+	return context.WithValue(ctx, graphqlLoaderKey{}, loader)
+}
+
+func graphqlLoaderFrom(ctx context.Context) *graphqlLoader {
+	loader, _ := ctx.Value(graphqlLoaderKey{}).(*graphqlLoader)
+	return loader
+}
+
+// graphqlLoader 单次查询内缓存账户和文件夹，避免列表中的每封邮件重复查询所属账户
+type graphqlLoader struct {
+	emailService services.EmailService
+	userID       uint
+
+	mu          sync.Mutex
+	accounts    []*models.EmailAccount
+	accountByID map[uint]*models.EmailAccount
+	folders     map[uint]*models.Folder
+	folderLists map[uint][]*models.Folder
+}
+
+func newGraphQLLoader(emailService services.EmailService, userID uint) *graphqlLoader {
+	return &graphqlLoader{
+		emailService: emailService,
+		userID:       userID,
+		folders:      make(map[uint]*models.Folder),
+		folderLists:  make(map[uint][]*models.Folder),
+	}
+}
+
+// Accounts 当前用户的全部邮件账户
+func (l *graphqlLoader) Accounts(ctx context.Context) ([]*models.EmailAccount, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.loadAccounts(ctx)
+}
+
+func (l *graphqlLoader) loadAccounts(ctx context.Context) ([]*models.EmailAccount, error) {
+	if l.accountByID != nil {
+		return l.accounts, nil
+	}
+	accounts, err := l.emailService.GetEmailAccounts(ctx, l.userID)
+	if err != nil {
+		return nil, err
+	}
+	l.accounts = accounts
+	l.accountByID = make(map[uint]*models.EmailAccount, len(accounts))
+	for _, account := range accounts {
+		l.accountByID[account.ID] = account
+	}
+	return accounts, nil
+}
+
+// Account 按ID获取账户，不属于当前用户时返回nil
+func (l *graphqlLoader) Account(ctx context.Context, accountID uint) (*models.EmailAccount, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.loadAccounts(ctx); err != nil {
+		return nil, err
+	}
+	return l.accountByID[accountID], nil
+}
+
+// Folders 账户下的文件夹
+func (l *graphqlLoader) Folders(ctx context.Context, accountID uint) ([]*models.Folder, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if folders, ok := l.folderLists[accountID]; ok {
+		return folders, nil
+	}
+	folders, err := l.emailService.GetFolders(ctx, l.userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	l.folderLists[accountID] = folders
+	for _, folder := range folders {
+		l.folders[folder.ID] = folder
+	}
+	return folders, nil
+}
+
+// Folder 按ID获取文件夹，不存在或不属于当前用户时返回nil
+func (l *graphqlLoader) Folder(ctx context.Context, folderID uint) (*models.Folder, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if folder, ok := l.folders[folderID]; ok {
+		return folder, nil
+	}
+	folder, err := l.emailService.GetFolder(ctx, l.userID, folderID)
+	if err != nil {
+		if err.Error() == "folder not found" {
+			l.folders[folderID] = nil
+			return nil, nil
+		}
+		return nil, err
+	}
+	l.folders[folderID] = folder
+	return folder, nil
+}
+
+// parseGraphQLID 把ID参数转换为数据库主键
+func parseGraphQLID(value interface{}) (uint, error) {
+	s, _ := value.(string)
+	id, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || id == 0 {
+		return 0, &graphql.Error{Message: "invalid id: " + strconv.Quote(s)}
+	}
+	return uint(id), nil
+}
+
+// optionalGraphQLID 可选ID参数，未提供时返回nil
+func optionalGraphQLID(args map[string]interface{}, name string) (*uint, error) {
+	value, ok := args[name]
+	if !ok || value == nil {
+		return nil, nil
+	}
+	id, err := parseGraphQLID(value)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// optionalGraphQLBool 可选布尔参数，未提供时返回nil
+func optionalGraphQLBool(args map[string]interface{}, name string) *bool {
+	if value, ok := args[name].(bool); ok {
+		return &value
+	}
+	return nil
+}
+
+func graphqlString(args map[string]interface{}, name string) string {
+	s, _ := args[name].(string)
+	return s
+}
@@ -0,0 +1,568 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"firemail/internal/graphql"
+	"firemail/internal/models"
+	"firemail/internal/services"
+)
+
+// 邮件列表分页参数
+const (
+	graphqlDefaultPageSize = 20
+	graphqlMaxPageSize     = 100
+)
+
+var errGraphQLNoLoader = errors.New("graphql loader missing from context")
+
+func gqlNonNull(t graphql.Type) graphql.Type {
+	return graphql.NewNonNull(t)
+}
+
+func gqlListOf(t graphql.Type) graphql.Type {
+	return graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(t)))
+}
+
+// newGraphQLSchema 构建邮件、文件夹、账户和搜索的只读查询类型
+func newGraphQLSchema() (*graphql.Schema, error) {
+	sortField := &graphql.Enum{Name: "EmailSortField", Description: "邮件排序字段", Values: []string{"DATE", "SUBJECT", "FROM", "SIZE"}}
+	sortOrder := &graphql.Enum{Name: "SortOrder", Description: "排序方向", Values: []string{"ASC", "DESC"}}
+	searchMode := &graphql.Enum{Name: "SearchMode", Description: "搜索模式", Values: []string{"KEYWORD", "SEMANTIC"}}
+
+	address := &graphql.Object{Name: "EmailAddress", Description: "邮件地址", Fields: graphql.Fields{
+		"name":    {Type: gqlNonNull(graphql.String)},
+		"address": {Type: gqlNonNull(graphql.String)},
+	}}
+
+	attachment := &graphql.Object{Name: "Attachment", Description: "邮件附件", Fields: graphql.Fields{
+		"id":          {Type: gqlNonNull(graphql.ID)},
+		"filename":    {Type: gqlNonNull(graphql.String)},
+		"contentType": {Type: gqlNonNull(graphql.String)},
+		"size":        {Type: gqlNonNull(graphql.Float), Description: "字节数"},
+		"contentId":   {Type: graphql.String},
+		"disposition": {Type: gqlNonNull(graphql.String)},
+		"isInline":    {Type: gqlNonNull(graphql.Boolean)},
+	}}
+
+	pageInfo := &graphql.Object{Name: "PageInfo", Description: "分页信息", Fields: graphql.Fields{
+		"hasNextPage":     {Type: gqlNonNull(graphql.Boolean)},
+		"hasPreviousPage": {Type: gqlNonNull(graphql.Boolean)},
+		"startCursor":     {Type: graphql.String},
+		"endCursor":       {Type: graphql.String, Description: "作为下一页的after参数"},
+	}}
+
+	account := &graphql.Object{Name: "EmailAccount", Description: "邮件账户"}
+	folder := &graphql.Object{Name: "Folder", Description: "邮件文件夹"}
+	email := &graphql.Object{Name: "Email", Description: "邮件"}
+	edge := &graphql.Object{Name: "EmailEdge", Fields: graphql.Fields{
+		"cursor": {Type: gqlNonNull(graphql.String)},
+		"node":   {Type: gqlNonNull(email)},
+	}}
+	connection := &graphql.Object{Name: "EmailConnection", Description: "邮件分页结果", Fields: graphql.Fields{
+		"edges":      {Type: gqlListOf(edge), Resolve: resolveConnectionEdges},
+		"nodes":      {Type: gqlListOf(email), Resolve: resolveConnectionNodes},
+		"pageInfo":   {Type: gqlNonNull(pageInfo), Resolve: resolveConnectionPageInfo},
+		"totalCount": {Type: gqlNonNull(graphql.Int), Resolve: resolveConnectionTotal},
+	}}
+
+	// 各处邮件列表共用的过滤、排序和分页参数
+	listArgs := func(extra map[string]*graphql.ArgumentDefinition) map[string]*graphql.ArgumentDefinition {
+		args := map[string]*graphql.ArgumentDefinition{
+			"first":       {Type: graphql.Int, Default: int32(graphqlDefaultPageSize), Description: "每页数量，最大100"},
+			"after":       {Type: graphql.String, Description: "上一页的endCursor"},
+			"isRead":      {Type: graphql.Boolean},
+			"isStarred":   {Type: graphql.Boolean},
+			"isImportant": {Type: graphql.Boolean},
+			"search":      {Type: graphql.String, Description: "在主题、发件人和正文中模糊匹配"},
+			"sortBy":      {Type: sortField, Default: "DATE"},
+			"sortOrder":   {Type: sortOrder, Default: "DESC"},
+		}
+		for name, arg := range extra {
+			args[name] = arg
+		}
+		return args
+	}
+
+	email.Fields = graphql.Fields{
+		"id":            {Type: gqlNonNull(graphql.ID)},
+		"accountId":     {Type: gqlNonNull(graphql.ID)},
+		"folderId":      {Type: graphql.ID},
+		"messageId":     {Type: gqlNonNull(graphql.String)},
+		"subject":       {Type: gqlNonNull(graphql.String)},
+		"from":          {Type: gqlNonNull(graphql.String)},
+		"to":            {Type: gqlListOf(address), Resolve: resolveEmailAddresses((*models.Email).GetToAddresses)},
+		"cc":            {Type: gqlListOf(address), Resolve: resolveEmailAddresses((*models.Email).GetCCAddresses)},
+		"bcc":           {Type: gqlListOf(address), Resolve: resolveEmailAddresses((*models.Email).GetBCCAddresses)},
+		"replyTo":       {Type: graphql.String},
+		"date":          {Type: gqlNonNull(graphql.DateTime)},
+		"textBody":      {Type: gqlNonNull(graphql.String)},
+		"htmlBody":      {Type: gqlNonNull(graphql.String)},
+		"isRead":        {Type: gqlNonNull(graphql.Boolean)},
+		"isStarred":     {Type: gqlNonNull(graphql.Boolean)},
+		"isImportant":   {Type: gqlNonNull(graphql.Boolean)},
+		"isDraft":       {Type: gqlNonNull(graphql.Boolean)},
+		"isSent":        {Type: gqlNonNull(graphql.Boolean)},
+		"size":          {Type: gqlNonNull(graphql.Float), Description: "字节数"},
+		"hasAttachment": {Type: gqlNonNull(graphql.Boolean)},
+		"labels":        {Type: gqlListOf(graphql.String), Resolve: resolveEmailLabels},
+		"priority":      {Type: gqlNonNull(graphql.String)},
+		"syncedAt":      {Type: graphql.DateTime},
+		"account":       {Type: account, Resolve: resolveEmailAccount},
+		"folder":        {Type: folder, Resolve: resolveEmailFolder},
+		"attachments":   {Type: gqlListOf(attachment), Resolve: resolveEmailAttachments},
+	}
+
+	account.Fields = graphql.Fields{
+		"id":           {Type: gqlNonNull(graphql.ID)},
+		"name":         {Type: gqlNonNull(graphql.String)},
+		"email":        {Type: gqlNonNull(graphql.String)},
+		"provider":     {Type: gqlNonNull(graphql.String)},
+		"authMethod":   {Type: gqlNonNull(graphql.String)},
+		"groupId":      {Type: graphql.ID},
+		"isActive":     {Type: gqlNonNull(graphql.Boolean)},
+		"lastSyncAt":   {Type: graphql.DateTime},
+		"syncStatus":   {Type: gqlNonNull(graphql.String)},
+		"errorMessage": {Type: graphql.String},
+		"totalEmails":  {Type: gqlNonNull(graphql.Int)},
+		"unreadEmails": {Type: gqlNonNull(graphql.Int)},
+		"createdAt":    {Type: gqlNonNull(graphql.DateTime)},
+		"folders":      {Type: gqlListOf(folder), Resolve: resolveAccountFolders},
+		"emails": {Type: gqlNonNull(connection), Args: listArgs(nil), Resolve: resolveEmailList(func(p graphql.ResolveParams) (*uint, *uint, error) {
+			accountID := p.Source.(*models.EmailAccount).ID
+			return &accountID, nil, nil
+		})},
+	}
+
+	folder.Fields = graphql.Fields{
+		"id":           {Type: gqlNonNull(graphql.ID)},
+		"accountId":    {Type: gqlNonNull(graphql.ID)},
+		"name":         {Type: gqlNonNull(graphql.String)},
+		"displayName":  {Type: gqlNonNull(graphql.String)},
+		"type":         {Type: gqlNonNull(graphql.String), Description: "inbox, sent, drafts, trash, spam, custom"},
+		"parentId":     {Type: graphql.ID},
+		"path":         {Type: gqlNonNull(graphql.String)},
+		"isSelectable": {Type: gqlNonNull(graphql.Boolean)},
+		"isSubscribed": {Type: gqlNonNull(graphql.Boolean)},
+		"totalEmails":  {Type: gqlNonNull(graphql.Int)},
+		"unreadEmails": {Type: gqlNonNull(graphql.Int)},
+		"color":        {Type: gqlNonNull(graphql.String)},
+		"icon":         {Type: gqlNonNull(graphql.String)},
+		"sortOrder":    {Type: gqlNonNull(graphql.Int)},
+		"account":      {Type: account, Resolve: resolveFolderAccount},
+		"emails": {Type: gqlNonNull(connection), Args: listArgs(nil), Resolve: resolveEmailList(func(p graphql.ResolveParams) (*uint, *uint, error) {
+			f := p.Source.(*models.Folder)
+			accountID, folderID := f.AccountID, f.ID
+			return &accountID, &folderID, nil
+		})},
+	}
+
+	group := &graphql.Object{Name: "EmailGroup", Description: "邮箱分组", Fields: graphql.Fields{
+		"id":           {Type: gqlNonNull(graphql.ID)},
+		"name":         {Type: gqlNonNull(graphql.String)},
+		"sortOrder":    {Type: gqlNonNull(graphql.Int)},
+		"isDefault":    {Type: gqlNonNull(graphql.Boolean)},
+		"accountCount": {Type: gqlNonNull(graphql.Int)},
+		"accounts":     {Type: gqlListOf(account), Resolve: resolveGroupAccounts},
+	}}
+
+	idArg := map[string]*graphql.ArgumentDefinition{"id": {Type: gqlNonNull(graphql.ID)}}
+	query := &graphql.Object{Name: "Query", Fields: graphql.Fields{
+		"accounts": {Type: gqlListOf(account), Description: "当前用户的邮件账户", Resolve: resolveAccounts},
+		"account":  {Type: account, Args: idArg, Resolve: resolveAccount},
+		"folders": {Type: gqlListOf(folder), Description: "账户下的文件夹", Resolve: resolveFolders,
+			Args: map[string]*graphql.ArgumentDefinition{"accountId": {Type: gqlNonNull(graphql.ID)}}},
+		"folder": {Type: folder, Args: idArg, Resolve: resolveFolder},
+		"emails": {Type: gqlNonNull(connection), Description: "邮件列表，按after游标翻页",
+			Args: listArgs(map[string]*graphql.ArgumentDefinition{
+				"accountId": {Type: graphql.ID},
+				"folderId":  {Type: graphql.ID},
+			}),
+			Resolve: resolveEmailList(func(p graphql.ResolveParams) (*uint, *uint, error) {
+				accountID, err := optionalGraphQLID(p.Args, "accountId")
+				if err != nil {
+					return nil, nil, err
+				}
+				folderID, err := optionalGraphQLID(p.Args, "folderId")
+				return accountID, folderID, err
+			})},
+		"email": {Type: email, Args: idArg, Resolve: resolveEmail},
+		"search": {Type: gqlNonNull(connection), Description: "搜索邮件，query支持 from:/to:/subject: 等前缀", Resolve: resolveSearch,
+			Args: map[string]*graphql.ArgumentDefinition{
+				"query":         {Type: gqlNonNull(graphql.String)},
+				"accountId":     {Type: graphql.ID},
+				"folderId":      {Type: graphql.ID},
+				"subject":       {Type: graphql.String},
+				"from":          {Type: graphql.String},
+				"to":            {Type: graphql.String},
+				"body":          {Type: graphql.String},
+				"hasAttachment": {Type: graphql.Boolean},
+				"isRead":        {Type: graphql.Boolean},
+				"isStarred":     {Type: graphql.Boolean},
+				"since":         {Type: graphql.DateTime},
+				"before":        {Type: graphql.DateTime},
+				"mode":          {Type: searchMode, Default: "KEYWORD"},
+				"first":         {Type: graphql.Int, Default: int32(graphqlDefaultPageSize), Description: "每页数量，最大100"},
+				"after":         {Type: graphql.String, Description: "上一页的endCursor"},
+			}},
+		"groups": {Type: gqlListOf(group), Description: "邮箱分组", Resolve: resolveGroups},
+	}}
+
+	return graphql.NewSchema(query)
+}
+
+func loaderFor(p graphql.ResolveParams) (*graphqlLoader, error) {
+	loader := graphqlLoaderFrom(p.Context)
+	if loader == nil {
+		return nil, errGraphQLNoLoader
+	}
+	return loader, nil
+}
+
+func resolveAccounts(p graphql.ResolveParams) (interface{}, error) {
+	loader, err := loaderFor(p)
+	if err != nil {
+		return nil, err
+	}
+	return loader.Accounts(p.Context)
+}
+
+func resolveAccount(p graphql.ResolveParams) (interface{}, error) {
+	loader, err := loaderFor(p)
+	if err != nil {
+		return nil, err
+	}
+	id, err := parseGraphQLID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+	return loader.Account(p.Context, id)
+}
+
+func resolveFolders(p graphql.ResolveParams) (interface{}, error) {
+	loader, err := loaderFor(p)
+	if err != nil {
+		return nil, err
+	}
+	accountID, err := parseGraphQLID(p.Args["accountId"])
+	if err != nil {
+		return nil, err
+	}
+	return loader.Folders(p.Context, accountID)
+}
+
+func resolveFolder(p graphql.ResolveParams) (interface{}, error) {
+	loader, err := loaderFor(p)
+	if err != nil {
+		return nil, err
+	}
+	id, err := parseGraphQLID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+	return loader.Folder(p.Context, id)
+}
+
+func resolveEmail(p graphql.ResolveParams) (interface{}, error) {
+	loader, err := loaderFor(p)
+	if err != nil {
+		return nil, err
+	}
+	id, err := parseGraphQLID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+	email, err := loader.emailService.GetEmail(p.Context, loader.userID, id)
+	if err != nil {
+		if err.Error() == "email not found" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return email, nil
+}
+
+func resolveGroups(p graphql.ResolveParams) (interface{}, error) {
+	loader, err := loaderFor(p)
+	if err != nil {
+		return nil, err
+	}
+	return loader.emailService.GetEmailGroups(p.Context, loader.userID)
+}
+
+func resolveGroupAccounts(p graphql.ResolveParams) (interface{}, error) {
+	loader, err := loaderFor(p)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := loader.Accounts(p.Context)
+	if err != nil {
+		return nil, err
+	}
+	groupID := p.Source.(*models.EmailGroup).ID
+	members := []*models.EmailAccount{}
+	for _, account := range accounts {
+		if account.GroupID != nil && *account.GroupID == groupID {
+			members = append(members, account)
+		}
+	}
+	return members, nil
+}
+
+func resolveAccountFolders(p graphql.ResolveParams) (interface{}, error) {
+	loader, err := loaderFor(p)
+	if err != nil {
+		return nil, err
+	}
+	return loader.Folders(p.Context, p.Source.(*models.EmailAccount).ID)
+}
+
+func resolveFolderAccount(p graphql.ResolveParams) (interface{}, error) {
+	loader, err := loaderFor(p)
+	if err != nil {
+		return nil, err
+	}
+	return loader.Account(p.Context, p.Source.(*models.Folder).AccountID)
+}
+
+func resolveEmailAccount(p graphql.ResolveParams) (interface{}, error) {
+	loader, err := loaderFor(p)
+	if err != nil {
+		return nil, err
+	}
+	return loader.Account(p.Context, p.Source.(*models.Email).AccountID)
+}
+
+func resolveEmailFolder(p graphql.ResolveParams) (interface{}, error) {
+	email := p.Source.(*models.Email)
+	if email.Folder != nil {
+		return email.Folder, nil
+	}
+	if email.FolderID == nil {
+		return nil, nil
+	}
+	loader, err := loaderFor(p)
+	if err != nil {
+		return nil, err
+	}
+	return loader.Folder(p.Context, *email.FolderID)
+}
+
+// resolveEmailAttachments 列表查询不预加载附件，按需读取邮件详情
+func resolveEmailAttachments(p graphql.ResolveParams) (interface{}, error) {
+	email := p.Source.(*models.Email)
+	if email.Attachments != nil || !email.HasAttachment {
+		return email.Attachments, nil
+	}
+	loader, err := loaderFor(p)
+	if err != nil {
+		return nil, err
+	}
+	full, err := loader.emailService.GetEmail(p.Context, loader.userID, email.ID)
+	if err != nil {
+		return nil, err
+	}
+	return full.Attachments, nil
+}
+
+func resolveEmailAddresses(get func(*models.Email) ([]models.EmailAddress, error)) graphql.ResolveFunc {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(*models.Email))
+	}
+}
+
+func resolveEmailLabels(p graphql.ResolveParams) (interface{}, error) {
+	return p.Source.(*models.Email).GetLabels()
+}
+
+// emailConnection 一页邮件及其在整个结果中的起始位置
+type emailConnection struct {
+	emails []*models.Email
+	start  int
+	total  int64
+}
+
+type emailEdge struct {
+	Cursor string        `json:"cursor"`
+	Node   *models.Email `json:"node"`
+}
+
+// 游标编码邮件在结果中的位置，对客户端不透明
+func encodeEmailCursor(position int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(position)))
+}
+
+func decodeEmailCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil && strings.HasPrefix(string(raw), "offset:") {
+		if position, err := strconv.Atoi(strings.TrimPrefix(string(raw), "offset:")); err == nil && position >= 0 {
+			return position, nil
+		}
+	}
+	return 0, &graphql.Error{Message: "invalid cursor: " + strconv.Quote(cursor)}
+}
+
+// connectionWindow 解析first/after，返回每页数量和起始位置
+func connectionWindow(args map[string]interface{}) (int, int, error) {
+	first := graphqlDefaultPageSize
+	if value, ok := args["first"].(int32); ok {
+		first = int(value)
+	}
+	if first < 1 || first > graphqlMaxPageSize {
+		return 0, 0, &graphql.Error{Message: "first must be between 1 and " + strconv.Itoa(graphqlMaxPageSize)}
+	}
+
+	start := 0
+	if after := graphqlString(args, "after"); after != "" {
+		position, err := decodeEmailCursor(after)
+		if err != nil {
+			return 0, 0, err
+		}
+		start = position + 1
+	}
+	return first, start, nil
+}
+
+// fetchEmailWindow 在按页查询的服务之上读取 [start, start+first) 区间，起点不在页边界时多读一页
+func fetchEmailWindow(first, start int, fetch func(page, pageSize int) (*services.GetEmailsResponse, error)) (*emailConnection, error) {
+	page := start/first + 1
+	resp, err := fetch(page, first)
+	if err != nil {
+		return nil, err
+	}
+
+	skip := start % first
+	emails := resp.Emails
+	if skip < len(emails) {
+		emails = emails[skip:]
+	} else {
+		emails = nil
+	}
+	if skip > 0 && len(resp.Emails) == first {
+		next, err := fetch(page+1, first)
+		if err != nil {
+			return nil, err
+		}
+		emails = append(append([]*models.Email{}, emails...), next.Emails...)
+	}
+	if len(emails) > first {
+		emails = emails[:first]
+	}
+	return &emailConnection{emails: emails, start: start, total: resp.Total}, nil
+}
+
+func resolveEmailList(scope func(p graphql.ResolveParams) (*uint, *uint, error)) graphql.ResolveFunc {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		loader, err := loaderFor(p)
+		if err != nil {
+			return nil, err
+		}
+		first, start, err := connectionWindow(p.Args)
+		if err != nil {
+			return nil, err
+		}
+		accountID, folderID, err := scope(p)
+		if err != nil {
+			return nil, err
+		}
+
+		base := services.GetEmailsRequest{
+			AccountID:   accountID,
+			FolderID:    folderID,
+			IsRead:      optionalGraphQLBool(p.Args, "isRead"),
+			IsStarred:   optionalGraphQLBool(p.Args, "isStarred"),
+			IsImportant: optionalGraphQLBool(p.Args, "isImportant"),
+			SortBy:      strings.ToLower(graphqlString(p.Args, "sortBy")),
+			SortOrder:   strings.ToLower(graphqlString(p.Args, "sortOrder")),
+			SearchQuery: graphqlString(p.Args, "search"),
+		}
+		return fetchEmailWindow(first, start, func(page, pageSize int) (*services.GetEmailsResponse, error) {
+			req := base
+			req.Page, req.PageSize = page, pageSize
+			return loader.emailService.GetEmails(p.Context, loader.userID, &req)
+		})
+	}
+}
+
+func resolveSearch(p graphql.ResolveParams) (interface{}, error) {
+	loader, err := loaderFor(p)
+	if err != nil {
+		return nil, err
+	}
+	first, start, err := connectionWindow(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	accountID, err := optionalGraphQLID(p.Args, "accountId")
+	if err != nil {
+		return nil, err
+	}
+	folderID, err := optionalGraphQLID(p.Args, "folderId")
+	if err != nil {
+		return nil, err
+	}
+
+	base := services.SearchEmailsRequest{
+		AccountID:     accountID,
+		FolderID:      folderID,
+		Query:         graphqlString(p.Args, "query"),
+		Subject:       graphqlString(p.Args, "subject"),
+		From:          graphqlString(p.Args, "from"),
+		To:            graphqlString(p.Args, "to"),
+		Body:          graphqlString(p.Args, "body"),
+		HasAttachment: optionalGraphQLBool(p.Args, "hasAttachment"),
+		IsRead:        optionalGraphQLBool(p.Args, "isRead"),
+		IsStarred:     optionalGraphQLBool(p.Args, "isStarred"),
+		Mode:          strings.ToLower(graphqlString(p.Args, "mode")),
+	}
+	if base.Query == "" && base.Subject == "" && base.From == "" && base.To == "" && base.Body == "" {
+		return nil, &graphql.Error{Message: "at least one search parameter is required"}
+	}
+	if since, ok := p.Args["since"].(time.Time); ok {
+		base.Since = &since
+	}
+	if before, ok := p.Args["before"].(time.Time); ok {
+		base.Before = &before
+	}
+
+	return fetchEmailWindow(first, start, func(page, pageSize int) (*services.GetEmailsResponse, error) {
+		// SearchEmails会改写请求中的查询词，每页使用独立副本
+		req := base
+		req.Page, req.PageSize = page, pageSize
+		return loader.emailService.SearchEmails(p.Context, loader.userID, &req)
+	})
+}
+
+func resolveConnectionEdges(p graphql.ResolveParams) (interface{}, error) {
+	conn := p.Source.(*emailConnection)
+	edges := make([]*emailEdge, len(conn.emails))
+	for i, email := range conn.emails {
+		edges[i] = &emailEdge{Cursor: encodeEmailCursor(conn.start + i), Node: email}
+	}
+	return edges, nil
+}
+
+func resolveConnectionNodes(p graphql.ResolveParams) (interface{}, error) {
+	return p.Source.(*emailConnection).emails, nil
+}
+
+func resolveConnectionTotal(p graphql.ResolveParams) (interface{}, error) {
+	return p.Source.(*emailConnection).total, nil
+}
+
+func resolveConnectionPageInfo(p graphql.ResolveParams) (interface{}, error) {
+	conn := p.Source.(*emailConnection)
+	info := map[string]interface{}{
+		"hasNextPage":     int64(conn.start+len(conn.emails)) < conn.total,
+		"hasPreviousPage": conn.start > 0,
+	}
+	if len(conn.emails) > 0 {
+		info["startCursor"] = encodeEmailCursor(conn.start)
+		info["endCursor"] = encodeEmailCursor(conn.start + len(conn.emails) - 1)
+	}
+	return info, nil
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"testing"
+
+	"firemail/internal/models"
+	"firemail/internal/services"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphQLSchemaBuilds(t *testing.T) {
+	schema, err := buildGraphQLSchema()
+	require.NoError(t, err)
+
+	sdl := schema.SDL()
+	require.Contains(t, sdl, "type Query {")
+	require.Contains(t, sdl, "type EmailConnection {")
+}
+
+func TestEmailCursorRoundTrip(t *testing.T) {
+	position, err := decodeEmailCursor(encodeEmailCursor(42))
+	require.NoError(t, err)
+	require.Equal(t, 42, position)
+
+	_, err = decodeEmailCursor("not-a-cursor")
+	require.Error(t, err)
+}
+
+func TestFetchEmailWindowAcrossPages(t *testing.T) {
+	all := make([]*models.Email, 25)
+	for i := range all {
+		all[i] = &models.Email{}
+		all[i].ID = uint(i + 1)
+	}
+
+	var pages []int
+	fetch := func(page, pageSize int) (*services.GetEmailsResponse, error) {
+		pages = append(pages, page)
+		from := (page - 1) * pageSize
+		to := from + pageSize
+		if from > len(all) {
+			from = len(all)
+		}
+		if to > len(all) {
+			to = len(all)
+		}
+		return &services.GetEmailsResponse{Emails: all[from:to], Total: int64(len(all))}, nil
+	}
+
+	// 起点不在页边界，需要读取相邻两页
+	conn, err := fetchEmailWindow(10, 5, fetch)
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2}, pages)
+	require.Len(t, conn.emails, 10)
+	require.Equal(t, uint(6), conn.emails[0].ID)
+	require.Equal(t, uint(15), conn.emails[9].ID)
+
+	// 最后一页不足时只返回剩余部分
+	pages = nil
+	conn, err = fetchEmailWindow(10, 20, fetch)
+	require.NoError(t, err)
+	require.Equal(t, []int{3}, pages)
+	require.Len(t, conn.emails, 5)
+	require.Equal(t, int64(25), conn.total)
+}
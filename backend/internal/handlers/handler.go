@@ -2,16 +2,24 @@ package handlers
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"strconv"
+	"time"
 
 	"firemail/internal/auth"
 	"firemail/internal/cache"
 	"firemail/internal/config"
+	"firemail/internal/i18n"
+	"firemail/internal/imapd"
 	"firemail/internal/middleware"
+	"firemail/internal/models"
 	"firemail/internal/providers"
 	"firemail/internal/services"
+	"firemail/internal/smtpd"
 	"firemail/internal/sse"
 
 	"github.com/gin-gonic/gin"
@@ -32,6 +40,31 @@ type Handler struct {
 	softDeleteService     services.SoftDeleteService
 	attachmentService     services.AttachmentDownloader
 	scheduledEmailService services.ScheduledEmailService
+	emailSendHandler      *EmailSendHandler
+	mailMergeService      services.MailMergeService
+	changeLogService      services.ChangeLogService
+	emailSender           services.EmailSender
+	emailShareService     services.EmailShareService
+	attachmentLinkService services.AttachmentLinkService
+	migrationService      services.MailboxMigrationService
+	analyticsService      services.AnalyticsService
+	retentionService      services.RetentionService
+	mailFetcherService    services.MailFetcherService
+	forwardingService     services.ForwardingService
+	cannedResponseService services.CannedResponseService
+	notificationService   services.NotificationService
+	legalHoldService      services.LegalHoldService
+	ingestService         services.IngestService
+	organizationService   services.OrganizationService
+	settingsService       services.SettingsService
+	securityEventService  services.SecurityEventService
+	authFailureService    services.AuthFailureService
+	jobQueue              services.JobQueueService
+	adminAccessRule       *middleware.IPAccessRule
+	loginAccessRule       *middleware.IPAccessRule
+	smtpServer            *smtpd.Server
+	imapStore             imapd.Store
+	imapServer            *imapd.Server
 }
 
 // New 创建处理器实例
@@ -44,6 +77,10 @@ func New(db *gorm.DB, cfg *config.Config) *Handler {
 
 	// 创建提供商工厂
 	providerFactory := providers.NewProviderFactory()
+	providers.ConfigureRateLimiter(cfg.RateLimit)
+	providers.ConfigureOutgoing(cfg.Outgoing)
+	providers.ConfigureDNS(cfg.DNS)
+	middleware.ConfigureSessionCookies(cfg.Auth)
 
 	// 创建SSE配置
 	sseConfig := &sse.SSEConfig{
@@ -58,8 +95,18 @@ func New(db *gorm.DB, cfg *config.Config) *Handler {
 	// 创建SSE服务
 	sseService := sse.NewSSEService(db, sseConfig)
 
+	// 创建持久化后台任务队列，同步、导出、清理和只入队模式的投递都作为任务执行
+	jobQueue := services.NewJobQueueService(db, cfg.Jobs)
+
+	// 多实例部署时使用Redis共享缓存并跨实例分发事件和任务通知，需在创建其他服务之前完成
+	configureRedisBackends(cfg.Redis, sseService, jobQueue)
+
+	// 需要用户关注的事件在推送前保存到通知中心，用户不在线时也能稍后查看
+	eventPublisher := services.NewNotificationPublisher(db, sseService.GetEventPublisher())
+	notificationService := services.NewNotificationService(db, eventPublisher)
+
 	// 创建邮件服务
-	emailService := services.NewEmailService(db, providerFactory, sseService.GetEventPublisher())
+	emailService := services.NewEmailService(db, providerFactory, eventPublisher)
 
 	// 创建去重工厂
 	deduplicatorFactory := services.NewDeduplicatorFactory(db)
@@ -68,11 +115,29 @@ func New(db *gorm.DB, cfg *config.Config) *Handler {
 	attachmentStorage := services.NewLocalFileStorage(nil) // 使用默认配置
 
 	// 创建同步服务（现在包含附件存储和缓存管理器）
-	syncService := services.NewSyncService(db, providerFactory, sseService.GetEventPublisher(), deduplicatorFactory, attachmentStorage, cache.GlobalCacheManager)
+	syncService := services.NewSyncService(db, providerFactory, eventPublisher, deduplicatorFactory, attachmentStorage, cache.GlobalCacheManager)
+	syncService.SetFolderWorkers(cfg.Sync.FolderWorkers, cfg.Sync.MaxFolderWorkers)
 
 	// 设置EmailService的SyncService依赖
 	if emailServiceImpl, ok := emailService.(*services.EmailServiceImpl); ok {
 		emailServiceImpl.SetSyncService(syncService)
+		emailServiceImpl.SetJobQueue(jobQueue)
+	}
+	syncService.RegisterJobs(jobQueue)
+
+	// 创建变更日志服务，供前端重连后增量同步
+	changeLogService := services.NewChangeLogService(db)
+	syncService.SetChangeLog(changeLogService)
+	if emailServiceImpl, ok := emailService.(*services.EmailServiceImpl); ok {
+		emailServiceImpl.SetChangeLog(changeLogService)
+	}
+
+	// 邮件头分析和访问地区限制的来源IP地理位置查询（可选）
+	ipGeoLocator := services.NewHTTPIPGeoLocator(cfg.GeoIP)
+	if ipGeoLocator != nil {
+		if emailServiceImpl, ok := emailService.(*services.EmailServiceImpl); ok {
+			emailServiceImpl.SetIPGeoLocator(ipGeoLocator)
+		}
 	}
 
 	// 创建OAuth2状态管理服务
@@ -94,11 +159,93 @@ func New(db *gorm.DB, cfg *config.Config) *Handler {
 
 	// 创建邮件组装器和发送器
 	emailComposer := services.NewStandardEmailComposer(&services.EmailComposerConfig{}, db)
-	emailSender := services.NewStandardEmailSender(db, providerFactory, sseService.GetEventPublisher())
+	emailSender := services.NewStandardEmailSender(db, providerFactory, eventPublisher)
+	if sender, ok := emailSender.(*services.StandardEmailSender); ok {
+		sender.SetJobQueue(jobQueue)
+	}
 
 	// 创建定时邮件服务
 	scheduledEmailService := services.NewScheduledEmailService(db, emailService, emailComposer, emailSender)
 
+	// 创建草稿服务（草稿同步到服务器Drafts文件夹）
+	draftSyncer := services.NewIMAPDraftSyncer(db, providerFactory)
+	draftService := services.NewDraftService(db)
+	if draftServiceImpl, ok := draftService.(*services.DraftServiceImpl); ok {
+		draftServiceImpl.SetDraftSyncer(draftSyncer)
+	}
+	if emailServiceImpl, ok := emailService.(*services.EmailServiceImpl); ok {
+		emailServiceImpl.SetDraftSyncer(draftSyncer)
+	}
+
+	// 创建模板服务，供邮件组装器渲染模板
+	templateService := services.NewEmailTemplateService(db)
+	if composer, ok := emailComposer.(*services.StandardEmailComposer); ok {
+		composer.SetTemplateService(templateService)
+	}
+
+	// 创建邮件分享链接服务，链接使用JWT密钥派生的密钥签名
+	emailShareService := services.NewEmailShareService(db, attachmentService, cfg.Auth.JWTSecret, cfg.Sharing)
+
+	// 创建附件下载链接服务，发信时超过大小上限的附件可改为下载链接
+	attachmentLinkService := services.NewAttachmentLinkService(db, attachmentStorage, cfg.Auth.JWTSecret, cfg.Sharing.AttachmentLinkExpiry)
+	if emailServiceImpl, ok := emailService.(*services.EmailServiceImpl); ok {
+		emailServiceImpl.SetAttachmentLinkService(attachmentLinkService)
+	}
+
+	// 创建邮箱迁移服务
+	migrationService := services.NewMailboxMigrationService(db, emailService, eventPublisher)
+
+	// 创建统计分析服务
+	analyticsService := services.NewAnalyticsService(db)
+
+	// 创建邮件保留规则服务
+	retentionService := services.NewRetentionService(db, emailService)
+
+	// 创建代收外部邮箱服务
+	mailFetcherService := services.NewMailFetcherService(db, emailService)
+
+	// 创建自动转发规则服务，同步到的新邮件由其排队转发
+	forwardingService := services.NewForwardingService(db, emailService)
+	syncService.SetForwarder(forwardingService)
+
+	// 创建快捷回复服务
+	cannedResponseService := services.NewCannedResponseService(db)
+
+	// 创建法律保全服务
+	legalHoldService := services.NewLegalHoldService(db, emailService, cfg.Auth.JWTSecret, cfg.Compliance)
+	if legalHoldServiceImpl, ok := legalHoldService.(*services.LegalHoldServiceImpl); ok {
+		legalHoldServiceImpl.SetJobQueue(jobQueue)
+	}
+
+	// 创建入站邮件服务
+	ingestService := services.NewIngestService(db, emailService, syncService, cfg.Ingest)
+
+	// 创建本地IMAP服务器外观的存储
+	imapStore := services.NewIMAPFacadeStore(db, emailService, attachmentStorage)
+
+	// 创建邮件合并服务
+	mailMergeService := services.NewMailMergeService(db, emailComposer, emailSender, cfg.Tracking)
+
+	// 创建组织服务
+	organizationService := services.NewOrganizationService(db, eventPublisher)
+
+	// 创建用户设置服务，未设置的项使用配置中的默认值
+	services.ConfigureUserSettingDefaults(cfg.UserDefaults)
+	services.ConfigureAttachmentPolicy(cfg.Attachments)
+	services.ConfigureCompose(cfg.Compose)
+	settingsService := services.NewSettingsService(db)
+
+	// 创建草稿/模板处理器，共享邮箱的 send_as 成员也可以发信
+	emailSendHandler := NewEmailSendHandler(emailComposer, emailSender, draftService, templateService, db)
+	emailSendHandler.SetOrganizationService(organizationService)
+	emailSendHandler.SetSettingsService(settingsService)
+
+	// 管理接口和登录的来源IP限制，被拒绝的访问和登录失败写入安全事件审计日志
+	securityEventService := services.NewSecurityEventService(db)
+	authFailureService := services.NewAuthFailureService(db, securityEventService, cfg.Access)
+	adminAccessRule := newIPAccessRule("admin", cfg.Access.AdminAllowlist, cfg.Access, ipGeoLocator)
+	loginAccessRule := newIPAccessRule("login", cfg.Access.LoginAllowlist, cfg.Access, ipGeoLocator)
+
 	return &Handler{
 		db:                    db,
 		config:                cfg,
@@ -112,17 +259,101 @@ func New(db *gorm.DB, cfg *config.Config) *Handler {
 		softDeleteService:     softDeleteService,
 		attachmentService:     attachmentService,
 		scheduledEmailService: scheduledEmailService,
+		emailSendHandler:      emailSendHandler,
+		mailMergeService:      mailMergeService,
+		changeLogService:      changeLogService,
+		emailSender:           emailSender,
+		emailShareService:     emailShareService,
+		attachmentLinkService: attachmentLinkService,
+		migrationService:      migrationService,
+		analyticsService:      analyticsService,
+		retentionService:      retentionService,
+		mailFetcherService:    mailFetcherService,
+		forwardingService:     forwardingService,
+		cannedResponseService: cannedResponseService,
+		notificationService:   notificationService,
+		legalHoldService:      legalHoldService,
+		ingestService:         ingestService,
+		organizationService:   organizationService,
+		settingsService:       settingsService,
+		securityEventService:  securityEventService,
+		authFailureService:    authFailureService,
+		jobQueue:              jobQueue,
+		adminAccessRule:       adminAccessRule,
+		loginAccessRule:       loginAccessRule,
+		imapStore:             imapStore,
+	}
+}
+
+// configureRedisBackends 按配置启用Redis缓存、事件分发和任务通知，连接失败时回退到进程内实现
+func configureRedisBackends(cfg config.RedisConfig, sseService *sse.SSEServiceImpl, jobQueue services.JobQueueService) {
+	useRedisCache := cfg.CacheBackend == config.BackendRedis
+	useRedisEvents := cfg.EventBackend == config.BackendRedis
+	useRedisJobs := cfg.JobBackend == config.BackendRedis
+	if !useRedisCache && !useRedisEvents && !useRedisJobs {
+		return
+	}
+
+	client, err := cache.NewRedisClient(cfg.URL)
+	if err != nil {
+		log.Printf("Warning: redis backend unavailable, falling back to in-process cache, events and job polling: %v", err)
+		return
+	}
+
+	if useRedisCache {
+		cache.GlobalCacheManager.UseBackend(cache.NewRedisCacheManager(client, cfg.KeyPrefix))
+		log.Println("Using redis cache backend")
+	}
+	if useRedisEvents {
+		sseService.EnableRedisFanout(client, cfg.KeyPrefix+"events")
+		log.Println("Using redis SSE event fanout")
+	}
+	if queue, ok := jobQueue.(*services.JobQueueServiceImpl); ok && useRedisJobs {
+		queue.EnableRedisNotify(client, cfg.KeyPrefix+"jobs")
+		log.Println("Using redis job notifications")
 	}
 }
 
 // AuthRequired 返回认证中间件
 func (h *Handler) AuthRequired() gin.HandlerFunc {
-	return middleware.AuthRequiredWithService(h.authService)
+	return middleware.AuthRequiredWithService(h.authService, h.applyUserLocale)
 }
 
 // OptionalAuth 返回可选认证中间件
 func (h *Handler) OptionalAuth() gin.HandlerFunc {
-	return middleware.OptionalAuthWithService(h.authService)
+	return middleware.OptionalAuthWithService(h.authService, h.applyUserLocale)
+}
+
+// applyUserLocale 用户设置了语言时，以其覆盖请求上下文中按Accept-Language选择的语言环境
+func (h *Handler) applyUserLocale(c *gin.Context) {
+	userID, ok := c.Get("userID")
+	if !ok || h.settingsService == nil {
+		return
+	}
+	settings, err := h.settingsService.GetSettings(c.Request.Context(), userID.(uint))
+	if err != nil {
+		log.Printf("Failed to load locale for user %v: %v", userID, err)
+		return
+	}
+	if settings.Locale != "" {
+		c.Request = c.Request.WithContext(i18n.WithLocale(c.Request.Context(), settings.Locale))
+	}
+}
+
+// localize 按请求的语言环境翻译响应消息
+func localize(c *gin.Context, message string) string {
+	if c.Request == nil {
+		return i18n.Translate(i18n.Default, message)
+	}
+	return i18n.Translate(i18n.FromContext(c.Request.Context()), message)
+}
+
+// localizef 按请求的语言环境翻译消息模板后格式化
+func localizef(c *gin.Context, format string, args ...interface{}) string {
+	if c.Request == nil {
+		return i18n.T(i18n.Default, format, args...)
+	}
+	return i18n.T(i18n.FromContext(c.Request.Context()), format, args...)
 }
 
 // GetAuthService 获取认证服务（用于向后兼容）
@@ -135,6 +366,11 @@ func (h *Handler) GetDB() *gorm.DB {
 	return h.db
 }
 
+// GetEmailSendHandler 获取草稿/模板处理器
+func (h *Handler) GetEmailSendHandler() *EmailSendHandler {
+	return h.emailSendHandler
+}
+
 // GetProviderFactory 获取提供商工厂
 func (h *Handler) GetProviderFactory() *providers.ProviderFactory {
 	return h.providerFactory
@@ -151,9 +387,12 @@ func (h *Handler) HealthCheck(c *gin.Context) {
 
 // ErrorResponse 错误响应结构
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message,omitempty"`
-	Code    string `json:"code,omitempty"`
+	Error      string                         `json:"error"`
+	Message    string                         `json:"message,omitempty"`
+	Code       string                         `json:"code,omitempty"`
+	SetupGuide *models.AccountSetupGuide      `json:"setup_guide,omitempty"` // 需要用户先完成邮箱设置时的分步说明
+	SizeLimit  *services.MessageTooLargeError `json:"size_limit,omitempty"`  // 邮件超过提供商大小上限时的实际大小和上限
+	Current    interface{}                    `json:"current,omitempty"`     // 版本冲突时记录的最新状态
 }
 
 // SuccessResponse 成功响应结构
@@ -167,7 +406,7 @@ type SuccessResponse struct {
 func (h *Handler) respondWithError(c *gin.Context, statusCode int, message string) {
 	c.JSON(statusCode, ErrorResponse{
 		Error:   http.StatusText(statusCode),
-		Message: message,
+		Message: localize(c, message),
 	})
 }
 
@@ -179,7 +418,7 @@ func (h *Handler) respondWithSuccess(c *gin.Context, data interface{}, message .
 	}
 
 	if len(message) > 0 {
-		response.Message = message[0]
+		response.Message = localize(c, message[0])
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -193,7 +432,7 @@ func (h *Handler) respondWithCreated(c *gin.Context, data interface{}, message .
 	}
 
 	if len(message) > 0 {
-		response.Message = message[0]
+		response.Message = localize(c, message[0])
 	}
 
 	c.JSON(http.StatusCreated, response)
@@ -214,6 +453,68 @@ func (h *Handler) respondWithEmailGroupError(c *gin.Context, fallbackStatus int,
 	h.respondWithError(c, statusCode, prefix+err.Error())
 }
 
+// respondWithProviderError 返回邮件服务器操作相关错误。
+// 按提供商错误类别给出状态码与错误代码，便于前端区分重新授权、稍后重试等处理。
+func (h *Handler) respondWithProviderError(c *gin.Context, fallbackStatus int, prefix string, err error) {
+	if err == nil {
+		return
+	}
+
+	statusCode := fallbackStatus
+	switch {
+	case errors.Is(err, providers.ErrAuth):
+		// 不使用401，避免前端误判为登录失效
+		statusCode = http.StatusUnprocessableEntity
+	case errors.Is(err, providers.ErrRateLimited):
+		statusCode = http.StatusTooManyRequests
+		if retryAfter, ok := providers.RetryAfter(err); ok {
+			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+		}
+	case errors.Is(err, providers.ErrQuotaExceeded):
+		statusCode = http.StatusInsufficientStorage
+	case errors.Is(err, providers.ErrFolderMissing):
+		statusCode = http.StatusNotFound
+	case errors.Is(err, providers.ErrTransient):
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	c.JSON(statusCode, ErrorResponse{
+		Error:      http.StatusText(statusCode),
+		Message:    localize(c, prefix+err.Error()),
+		Code:       providers.ErrorCode(err),
+		SetupGuide: localizeSetupGuide(c, providers.SetupGuideOf(err)),
+	})
+}
+
+// respondWithMessageTooLarge 邮件超过提供商大小上限时返回413并附带实际大小和上限，其他错误返回 false
+func respondWithMessageTooLarge(c *gin.Context, message string, err error) bool {
+	var tooLarge *services.MessageTooLargeError
+	if !errors.As(err, &tooLarge) {
+		return false
+	}
+	c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{
+		Error:     http.StatusText(http.StatusRequestEntityTooLarge),
+		Message:   localize(c, message),
+		Code:      "message_too_large",
+		SizeLimit: tooLarge,
+	})
+	return true
+}
+
+// localizeSetupGuide 按请求的语言环境翻译账户设置说明
+func localizeSetupGuide(c *gin.Context, guide *models.AccountSetupGuide) *models.AccountSetupGuide {
+	if guide == nil {
+		return nil
+	}
+	localized := *guide
+	localized.Message = localize(c, guide.Message)
+	localized.Steps = make([]string, len(guide.Steps))
+	for i, step := range guide.Steps {
+		localized.Steps[i] = localize(c, step)
+	}
+	return &localized
+}
+
 // bindJSON 绑定JSON请求体
 func (h *Handler) bindJSON(c *gin.Context, obj interface{}) bool {
 	if err := c.ShouldBindJSON(obj); err != nil {
@@ -361,21 +662,362 @@ func (h *Handler) StartBackupService(ctx context.Context) error {
 	return h.backupService.StartAutoBackup(ctx)
 }
 
-// StartSoftDeleteCleanup 启动软删除清理服务
+// StartSoftDeleteCleanup 注册每周执行的软删除清理任务
 func (h *Handler) StartSoftDeleteCleanup(ctx context.Context, retentionDays int) error {
-	return h.softDeleteService.StartAutoCleanup(ctx, retentionDays)
+	if retentionDays <= 0 {
+		return fmt.Errorf("retention days must be positive")
+	}
+	h.jobQueue.Register(services.JobTypeSoftDeleteCleanup, func(ctx context.Context, job *models.Job) error {
+		return h.softDeleteService.CleanupExpiredSoftDeletes(ctx, retentionDays)
+	})
+	h.jobQueue.Every(services.JobTypeSoftDeleteCleanup, 7*24*time.Hour)
+	return nil
 }
 
-// StartTemporaryAttachmentCleanup 启动临时附件清理服务
+// StartTemporaryAttachmentCleanup 注册每天执行的临时附件清理任务
 func (h *Handler) StartTemporaryAttachmentCleanup(ctx context.Context, maxAgeHours int) error {
-	// 获取AttachmentService实例
-	if attachmentService, ok := h.attachmentService.(*services.AttachmentService); ok {
-		return attachmentService.StartAutoCleanup(ctx, maxAgeHours)
+	attachmentService, ok := h.attachmentService.(*services.AttachmentService)
+	if !ok {
+		return fmt.Errorf("attachment service does not support auto cleanup")
+	}
+	if maxAgeHours <= 0 {
+		return fmt.Errorf("max age hours must be positive")
+	}
+	h.jobQueue.Register(services.JobTypeTemporaryAttachCleanup, func(ctx context.Context, job *models.Job) error {
+		return attachmentService.CleanupTemporaryAttachments(ctx, maxAgeHours)
+	})
+	h.jobQueue.Every(services.JobTypeTemporaryAttachCleanup, 24*time.Hour)
+	return nil
+}
+
+// StartFolderCountReconciliation 注册周期校正文件夹和账户邮件计数的任务
+func (h *Handler) StartFolderCountReconciliation(ctx context.Context) error {
+	emailService, ok := h.emailService.(*services.EmailServiceImpl)
+	if !ok {
+		return fmt.Errorf("email service does not support folder count reconciliation")
+	}
+	h.jobQueue.Register(services.JobTypeFolderCountReconcile, func(ctx context.Context, job *models.Job) error {
+		return emailService.ReconcileAllFolderCounts(ctx)
+	})
+	h.jobQueue.Every(services.JobTypeFolderCountReconcile, services.FolderCountReconcileInterval)
+	return nil
+}
+
+// StartNotificationCleanup 注册每天执行的任务，删除超过保留期的通知
+func (h *Handler) StartNotificationCleanup(ctx context.Context) error {
+	h.jobQueue.Register(services.JobTypeNotificationCleanup, func(ctx context.Context, job *models.Job) error {
+		pruned, err := h.notificationService.PruneNotifications(ctx, time.Now().Add(-services.NotificationRetention))
+		if err == nil && pruned > 0 {
+			log.Printf("Pruned %d expired notifications", pruned)
+		}
+		return err
+	})
+	h.jobQueue.Every(services.JobTypeNotificationCleanup, 24*time.Hour)
+	return nil
+}
+
+// StartEmbeddingIndex 配置了向量模型时注册语义搜索索引任务，周期执行并在账户同步完成后入队
+func (h *Handler) StartEmbeddingIndex(ctx context.Context) error {
+	embeddingModel := services.NewHTTPEmbeddingModel(h.config.SemanticSearch)
+	if embeddingModel == nil {
+		return nil
+	}
+	emailService, ok := h.emailService.(*services.EmailServiceImpl)
+	if !ok {
+		return fmt.Errorf("email service does not support semantic search")
+	}
+	emailService.SetEmbeddingModel(embeddingModel)
+	h.syncService.SetEmbeddingIndexQueue(h.jobQueue)
+	h.jobQueue.Register(services.JobTypeEmbeddingIndex, func(ctx context.Context, job *models.Job) error {
+		indexed, err := emailService.IndexEmailEmbeddings(ctx)
+		if indexed > 0 {
+			log.Printf("Indexed %d emails for semantic search with model %s", indexed, embeddingModel.Name())
+		}
+		return err
+	})
+	h.jobQueue.Every(services.JobTypeEmbeddingIndex, services.EmbeddingIndexInterval)
+	return nil
+}
+
+// StartJobQueue 启动后台任务队列，需在注册周期任务之后调用
+func (h *Handler) StartJobQueue(ctx context.Context) error {
+	return h.jobQueue.Start(ctx)
+}
+
+// StartReplyLaterReminders 启动稍后回复到期提醒
+func (h *Handler) StartReplyLaterReminders(ctx context.Context) {
+	h.emailService.StartReplyLaterReminders(ctx)
+}
+
+// ResumeOutboundQueue 继续投递上次进程退出时未完成的邮件
+func (h *Handler) ResumeOutboundQueue(ctx context.Context) error {
+	sender, ok := h.emailSender.(*services.StandardEmailSender)
+	if !ok {
+		return nil
+	}
+	resumed, err := sender.ResumePendingSends(ctx)
+	if err != nil {
+		return err
+	}
+	if resumed > 0 {
+		log.Printf("Resumed %d interrupted outbound emails", resumed)
+	}
+	return nil
+}
+
+// SetSendQueueOnly 发送请求只写入发送队列，由工作进程投递
+func (h *Handler) SetSendQueueOnly() {
+	if sender, ok := h.emailSender.(*services.StandardEmailSender); ok {
+		sender.SetQueueOnly(true)
 	}
-	return fmt.Errorf("attachment service does not support auto cleanup")
+}
+
+// DeliverQueuedSends 投递HTTP进程写入发送队列但没有投递任务的邮件，其余邮件由投递任务处理
+func (h *Handler) DeliverQueuedSends(ctx context.Context) error {
+	sender, ok := h.emailSender.(*services.StandardEmailSender)
+	if !ok {
+		return nil
+	}
+	claimed, err := sender.ProcessQueuedSends(ctx)
+	if err != nil {
+		return err
+	}
+	if claimed > 0 {
+		log.Printf("Delivering %d queued outbound emails", claimed)
+	}
+	return nil
 }
 
 // StartScheduledEmailService 启动定时邮件服务
 func (h *Handler) StartScheduledEmailService(ctx context.Context) error {
 	return h.scheduledEmailService.StartScheduler(ctx)
 }
+
+// StartMailMergeService 启动邮件合并服务（恢复运行中的活动）
+func (h *Handler) StartMailMergeService(ctx context.Context) error {
+	return h.mailMergeService.Start(ctx)
+}
+
+// StartMailboxMigrationService 启动邮箱迁移服务（恢复运行中的迁移）
+func (h *Handler) StartMailboxMigrationService(ctx context.Context) error {
+	return h.migrationService.Start(ctx)
+}
+
+// StartRetentionService 启动邮件保留规则定时任务
+func (h *Handler) StartRetentionService(ctx context.Context) error {
+	return h.retentionService.Start(ctx)
+}
+
+// StartMailFetcherService 启动代收外部邮箱定时任务
+func (h *Handler) StartMailFetcherService(ctx context.Context) error {
+	return h.mailFetcherService.Start(ctx)
+}
+
+// StartForwardingService 启动自动转发队列
+func (h *Handler) StartForwardingService(ctx context.Context) error {
+	return h.forwardingService.Start(ctx)
+}
+
+// StartLegalHoldService 启动法律保全服务
+func (h *Handler) StartLegalHoldService(ctx context.Context) error {
+	return h.legalHoldService.Start(ctx)
+}
+
+// StartSMTPServer 按配置启动内置SMTP收信服务器，未启用时不做任何事
+func (h *Handler) StartSMTPServer(ctx context.Context) error {
+	cfg := h.config.SMTPServer
+	if !cfg.Enabled {
+		return nil
+	}
+
+	serverConfig := smtpd.Config{
+		Hostname:        cfg.Hostname,
+		Domains:         cfg.Domains,
+		RequireTLS:      cfg.RequireTLS,
+		MaxMessageBytes: h.ingestService.MaxMessageBytes() - smtpd.TraceReserve,
+		MaxRecipients:   cfg.MaxRecipients,
+		Timeout:         cfg.Timeout,
+	}
+	if cfg.TLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load SMTP TLS certificate: %w", err)
+		}
+		serverConfig.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	}
+	if len(cfg.DNSBLZones) > 0 {
+		serverConfig.Hooks = append(serverConfig.Hooks, smtpd.NewDNSBLHook(cfg.DNSBLZones, nil))
+	}
+
+	server := smtpd.NewServer(serverConfig, services.NewSMTPDeliveryBackend(h.ingestService))
+	for _, addr := range cfg.Addrs {
+		if _, err := server.Listen(addr); err != nil {
+			server.Close()
+			return fmt.Errorf("failed to listen on %s: %w", addr, err)
+		}
+		log.Printf("SMTP server listening on %s for %v", addr, cfg.Domains)
+	}
+	h.smtpServer = server
+	return nil
+}
+
+// StartIMAPServer 按配置启动本地IMAP服务器，未启用时不做任何事
+func (h *Handler) StartIMAPServer(ctx context.Context) error {
+	cfg := h.config.IMAPServer
+	if !cfg.Enabled {
+		return nil
+	}
+
+	serverConfig := imapd.Config{
+		AllowInsecureAuth: cfg.AllowInsecureAuth,
+		OnAuthFailure: func(ip, username string) {
+			h.authFailureService.Record(context.Background(), services.AuthFailure{
+				Service:  services.AuthServiceIMAP,
+				IP:       ip,
+				Username: username,
+				Reason:   "invalid_credentials",
+			})
+		},
+	}
+	if cfg.TLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load IMAP TLS certificate: %w", err)
+		}
+		serverConfig.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	}
+
+	server := imapd.NewServer(serverConfig, h.imapStore)
+	for _, addr := range cfg.Addrs {
+		if _, err := server.Listen(addr); err != nil {
+			server.Close()
+			return fmt.Errorf("failed to listen on %s: %w", addr, err)
+		}
+		log.Printf("IMAP server listening on %s", addr)
+	}
+	h.imapServer = server
+	return nil
+}
+
+// Shutdown 排空后台任务：取消进行中的同步，暂停发送队列并等待正在发送的邮件，
+// ctx 到期后不再等待，未完成的任务在下次启动时恢复
+func (h *Handler) Shutdown(ctx context.Context) error {
+	var errs []error
+
+	// 先停止接收新邮件
+	if h.smtpServer != nil {
+		smtpDone := make(chan struct{})
+		go func() {
+			h.smtpServer.Close()
+			close(smtpDone)
+		}()
+		select {
+		case <-smtpDone:
+		case <-ctx.Done():
+			errs = append(errs, fmt.Errorf("timed out waiting for SMTP sessions: %w", ctx.Err()))
+		}
+	}
+	if h.imapServer != nil {
+		if err := h.imapServer.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// 停止任务队列，执行中的任务被取消后放回等待队列，下次启动时继续
+	jobQueueDone := make(chan struct{})
+	go func() {
+		h.jobQueue.Stop()
+		close(jobQueueDone)
+	}()
+	select {
+	case <-jobQueueDone:
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("timed out waiting for background jobs: %w", ctx.Err()))
+	}
+
+	if err := h.syncService.Shutdown(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := h.scheduledEmailService.Shutdown(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	mailMergeDone := make(chan struct{})
+	go func() {
+		h.mailMergeService.Stop()
+		close(mailMergeDone)
+	}()
+	select {
+	case <-mailMergeDone:
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("timed out waiting for mail merge campaigns: %w", ctx.Err()))
+	}
+
+	migrationDone := make(chan struct{})
+	go func() {
+		h.migrationService.Stop()
+		close(migrationDone)
+	}()
+	select {
+	case <-migrationDone:
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("timed out waiting for mailbox migrations: %w", ctx.Err()))
+	}
+
+	retentionDone := make(chan struct{})
+	go func() {
+		h.retentionService.Stop()
+		close(retentionDone)
+	}()
+	select {
+	case <-retentionDone:
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("timed out waiting for retention policies: %w", ctx.Err()))
+	}
+
+	mailFetcherDone := make(chan struct{})
+	go func() {
+		h.mailFetcherService.Stop()
+		close(mailFetcherDone)
+	}()
+	select {
+	case <-mailFetcherDone:
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("timed out waiting for mail fetchers: %w", ctx.Err()))
+	}
+
+	forwardingDone := make(chan struct{})
+	go func() {
+		h.forwardingService.Stop()
+		close(forwardingDone)
+	}()
+	select {
+	case <-forwardingDone:
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("timed out waiting for forwarding queue: %w", ctx.Err()))
+	}
+
+	legalHoldDone := make(chan struct{})
+	go func() {
+		h.legalHoldService.Stop()
+		close(legalHoldDone)
+	}()
+	select {
+	case <-legalHoldDone:
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("timed out waiting for legal hold exports: %w", ctx.Err()))
+	}
+
+	if sender, ok := h.emailSender.(*services.StandardEmailSender); ok {
+		if err := sender.Wait(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := h.authFailureService.Close(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ingestFormMIMEField Mailgun等服务以表单转发原始邮件时使用的字段名
+const ingestFormMIMEField = "body-mime"
+
+// GetIngestEndpoints 获取入站邮件端点列表
+func (h *Handler) GetIngestEndpoints(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	endpoints, err := h.ingestService.ListEndpoints(c.Request.Context(), userID)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get ingest endpoints: "+err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, endpoints)
+}
+
+// CreateIngestEndpoint 创建入站邮件端点，令牌只在响应中返回一次
+func (h *Handler) CreateIngestEndpoint(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	var req services.CreateIngestEndpointRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	endpoint, err := h.ingestService.CreateEndpoint(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondWithIngestError(c, err, "Failed to create ingest endpoint")
+		return
+	}
+
+	h.respondWithCreated(c, endpoint, "Ingest endpoint created")
+}
+
+// UpdateIngestEndpoint 修改入站邮件端点
+func (h *Handler) UpdateIngestEndpoint(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	endpointID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	var req services.UpdateIngestEndpointRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	endpoint, err := h.ingestService.UpdateEndpoint(c.Request.Context(), userID, endpointID, &req)
+	if err != nil {
+		h.respondWithIngestError(c, err, "Failed to update ingest endpoint")
+		return
+	}
+
+	h.respondWithSuccess(c, endpoint, "Ingest endpoint updated")
+}
+
+// RotateIngestToken 重新生成入站端点的令牌
+func (h *Handler) RotateIngestToken(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	endpointID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	endpoint, err := h.ingestService.RotateToken(c.Request.Context(), userID, endpointID)
+	if err != nil {
+		h.respondWithIngestError(c, err, "Failed to rotate ingest token")
+		return
+	}
+
+	h.respondWithSuccess(c, endpoint, "Ingest token rotated")
+}
+
+// DeleteIngestEndpoint 删除入站邮件端点
+func (h *Handler) DeleteIngestEndpoint(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	endpointID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	if err := h.ingestService.DeleteEndpoint(c.Request.Context(), userID, endpointID); err != nil {
+		h.respondWithIngestError(c, err, "Failed to delete ingest endpoint")
+		return
+	}
+
+	h.respondWithSuccess(c, nil, "Ingest endpoint deleted")
+}
+
+// IngestEmail 接收外部服务推送的邮件，凭入站令牌认证。
+// 支持JSON、带body-mime字段的表单（Mailgun）以及直接提交的原始MIME邮件
+func (h *Handler) IngestEmail(c *gin.Context) {
+	endpoint, err := h.ingestService.Authenticate(c.Request.Context(), ingestToken(c))
+	if err != nil {
+		h.respondWithIngestError(c, err, "Failed to authenticate ingest request")
+		return
+	}
+
+	// JSON和表单中的Base64、URL编码会增大体积，按两倍上限读取，解码后由服务再次校验
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, 2*h.ingestService.MaxMessageBytes())
+
+	var result *services.IngestResult
+	switch c.ContentType() {
+	case gin.MIMEJSON:
+		var req services.IngestMessageRequest
+		if !h.bindJSON(c, &req) {
+			return
+		}
+		result, err = h.ingestService.IngestMessage(c.Request.Context(), endpoint, &req)
+	case gin.MIMEMultipartPOSTForm, gin.MIMEPOSTForm:
+		raw, ok := c.GetPostForm(ingestFormMIMEField)
+		if !ok {
+			h.respondWithError(c, http.StatusBadRequest, "Missing form field: "+ingestFormMIMEField)
+			return
+		}
+		result, err = h.ingestService.IngestRaw(c.Request.Context(), endpoint, []byte(raw))
+	default:
+		raw, readErr := io.ReadAll(c.Request.Body)
+		if readErr != nil {
+			err = readErr
+			break
+		}
+		result, err = h.ingestService.IngestRaw(c.Request.Context(), endpoint, raw)
+	}
+	if err != nil {
+		h.respondWithIngestError(c, err, "Failed to ingest email")
+		return
+	}
+
+	if result.Duplicate {
+		h.respondWithSuccess(c, result, "Email already ingested")
+		return
+	}
+	h.respondWithCreated(c, result, "Email ingested")
+}
+
+// ingestToken 从Authorization头、X-Ingest-Token头或token查询参数中读取入站令牌
+func ingestToken(c *gin.Context) string {
+	if header := c.GetHeader("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		return strings.TrimPrefix(header, "Bearer ")
+	}
+	if token := c.GetHeader("X-Ingest-Token"); token != "" {
+		return token
+	}
+	return c.Query("token")
+}
+
+// respondWithIngestError 将入站邮件服务错误映射为HTTP状态码
+func (h *Handler) respondWithIngestError(c *gin.Context, err error, message string) {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.Is(err, services.ErrIngestEndpointNotFound):
+		h.respondWithError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrInvalidIngestToken):
+		h.respondWithError(c, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, services.ErrInvalidIngestEndpoint), errors.Is(err, services.ErrInvalidIngestMessage):
+		h.respondWithError(c, http.StatusBadRequest, err.Error())
+	case errors.As(err, &maxBytesErr):
+		h.respondWithError(c, http.StatusRequestEntityTooLarge, "Email exceeds the ingest size limit")
+	default:
+		h.respondWithError(c, http.StatusInternalServerError, message+": "+err.Error())
+	}
+}
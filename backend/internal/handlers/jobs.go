@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListJobs 列出后台任务及各状态的任务数
+func (h *Handler) ListJobs(c *gin.Context) {
+	filter := services.JobFilter{
+		Status: c.Query("status"),
+		Type:   c.Query("type"),
+		Limit:  h.parseIntQuery(c, "limit", 0),
+	}
+
+	jobs, err := h.jobQueue.ListJobs(c.Request.Context(), filter)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to list jobs")
+		return
+	}
+	h.respondWithSuccess(c, jobs)
+}
+
+// GetJob 获取后台任务详情
+func (h *Handler) GetJob(c *gin.Context) {
+	jobID, ok := h.parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	job, err := h.jobQueue.GetJob(c.Request.Context(), jobID)
+	if err != nil {
+		h.respondWithJobError(c, err, "Failed to get job")
+		return
+	}
+	h.respondWithSuccess(c, job)
+}
+
+// RetryJob 重新执行死信或已取消的任务
+func (h *Handler) RetryJob(c *gin.Context) {
+	jobID, ok := h.parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	job, err := h.jobQueue.RetryJob(c.Request.Context(), jobID)
+	if err != nil {
+		h.respondWithJobError(c, err, "Failed to retry job")
+		return
+	}
+	h.respondWithSuccess(c, job, "Job queued for retry")
+}
+
+// CancelJob 取消等待执行的任务
+func (h *Handler) CancelJob(c *gin.Context) {
+	jobID, ok := h.parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	job, err := h.jobQueue.CancelJob(c.Request.Context(), jobID)
+	if err != nil {
+		h.respondWithJobError(c, err, "Failed to cancel job")
+		return
+	}
+	h.respondWithSuccess(c, job, "Job cancelled")
+}
+
+// respondWithJobError 将任务队列错误映射为HTTP状态码
+func (h *Handler) respondWithJobError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrJobNotFound):
+		h.respondWithError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrJobNotRetryable), errors.Is(err, services.ErrJobNotCancellable):
+		h.respondWithError(c, http.StatusConflict, err.Error())
+	default:
+		h.respondWithError(c, http.StatusInternalServerError, message+": "+err.Error())
+	}
+}
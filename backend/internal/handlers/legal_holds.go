@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetLegalHolds 获取法律保全列表
+func (h *Handler) GetLegalHolds(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	holds, err := h.legalHoldService.ListHolds(c.Request.Context(), userID)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get legal holds: "+err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, holds)
+}
+
+// CreateLegalHold 创建法律保全
+func (h *Handler) CreateLegalHold(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	var req services.CreateLegalHoldRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	hold, err := h.legalHoldService.CreateHold(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondWithLegalHoldError(c, err, "Failed to create legal hold")
+		return
+	}
+
+	h.respondWithCreated(c, hold, "Legal hold created")
+}
+
+// GetLegalHold 获取法律保全详情
+func (h *Handler) GetLegalHold(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	holdID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	hold, err := h.legalHoldService.GetHold(c.Request.Context(), userID, holdID)
+	if err != nil {
+		h.respondWithLegalHoldError(c, err, "Failed to get legal hold")
+		return
+	}
+
+	h.respondWithSuccess(c, hold)
+}
+
+// ReleaseLegalHold 解除法律保全
+func (h *Handler) ReleaseLegalHold(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	holdID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	hold, err := h.legalHoldService.ReleaseHold(c.Request.Context(), userID, holdID)
+	if err != nil {
+		h.respondWithLegalHoldError(c, err, "Failed to release legal hold")
+		return
+	}
+
+	h.respondWithSuccess(c, hold, "Legal hold released")
+}
+
+// CreateLegalHoldExport 在后台导出法律保全覆盖的邮件
+func (h *Handler) CreateLegalHoldExport(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	holdID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	export, err := h.legalHoldService.CreateExport(c.Request.Context(), userID, holdID)
+	if err != nil {
+		h.respondWithLegalHoldError(c, err, "Failed to create legal hold export")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, SuccessResponse{
+		Success: true,
+		Data:    export,
+		Message: localize(c, "Legal hold export started"),
+	})
+}
+
+// GetLegalHoldExports 获取法律保全的导出列表
+func (h *Handler) GetLegalHoldExports(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	holdID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	exports, err := h.legalHoldService.ListExports(c.Request.Context(), userID, holdID)
+	if err != nil {
+		h.respondWithLegalHoldError(c, err, "Failed to get legal hold exports")
+		return
+	}
+
+	h.respondWithSuccess(c, exports)
+}
+
+// GetLegalHoldExport 获取法律保全导出的进度和结果
+func (h *Handler) GetLegalHoldExport(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	holdID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+	exportID, exists := h.parseUintParam(c, "export_id")
+	if !exists {
+		return
+	}
+
+	export, err := h.legalHoldService.GetExport(c.Request.Context(), userID, holdID, exportID)
+	if err != nil {
+		h.respondWithLegalHoldError(c, err, "Failed to get legal hold export")
+		return
+	}
+
+	h.respondWithSuccess(c, export)
+}
+
+// DownloadLegalHoldExport 下载已完成的法律保全导出压缩包
+func (h *Handler) DownloadLegalHoldExport(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	holdID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+	exportID, exists := h.parseUintParam(c, "export_id")
+	if !exists {
+		return
+	}
+
+	export, file, err := h.legalHoldService.OpenExport(c.Request.Context(), userID, holdID, exportID)
+	if err != nil {
+		h.respondWithLegalHoldError(c, err, "Failed to download legal hold export")
+		return
+	}
+	defer file.Close()
+
+	filename := fmt.Sprintf("legal_hold_%d_export_%d.zip", export.HoldID, export.ID)
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("X-Archive-SHA256", export.ArchiveSHA256)
+
+	modified := export.StartedAt
+	if export.FinishedAt != nil {
+		modified = *export.FinishedAt
+	}
+	http.ServeContent(c.Writer, c.Request, filename, modified, file)
+}
+
+// VerifyLegalHoldExport 校验法律保全导出文件的完整性
+func (h *Handler) VerifyLegalHoldExport(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	holdID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+	exportID, exists := h.parseUintParam(c, "export_id")
+	if !exists {
+		return
+	}
+
+	result, err := h.legalHoldService.VerifyExport(c.Request.Context(), userID, holdID, exportID)
+	if err != nil {
+		h.respondWithLegalHoldError(c, err, "Failed to verify legal hold export")
+		return
+	}
+
+	h.respondWithSuccess(c, result)
+}
+
+// respondWithLegalHoldError 将法律保全服务错误映射为HTTP状态码
+func (h *Handler) respondWithLegalHoldError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrLegalHoldNotFound), errors.Is(err, services.ErrLegalHoldExportNotFound):
+		h.respondWithError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrInvalidLegalHold):
+		h.respondWithError(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrLegalHoldReleased),
+		errors.Is(err, services.ErrLegalHoldExportRunning),
+		errors.Is(err, services.ErrLegalHoldExportNotReady):
+		h.respondWithError(c, http.StatusConflict, err.Error())
+	default:
+		h.respondWithError(c, http.StatusInternalServerError, message+": "+err.Error())
+	}
+}
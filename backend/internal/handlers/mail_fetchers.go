@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMailFetchers 获取代收外部邮箱列表
+func (h *Handler) GetMailFetchers(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	fetchers, err := h.mailFetcherService.ListFetchers(c.Request.Context(), userID)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get mail fetchers: "+err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, fetchers)
+}
+
+// CreateMailFetcher 创建代收外部邮箱
+func (h *Handler) CreateMailFetcher(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	var req services.MailFetcherRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	fetcher, err := h.mailFetcherService.CreateFetcher(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondWithMailFetcherError(c, err, "Failed to create mail fetcher")
+		return
+	}
+
+	h.respondWithCreated(c, fetcher, "Mail fetcher created")
+}
+
+// UpdateMailFetcher 修改代收外部邮箱
+func (h *Handler) UpdateMailFetcher(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	fetcherID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	var req services.MailFetcherRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	fetcher, err := h.mailFetcherService.UpdateFetcher(c.Request.Context(), userID, fetcherID, &req)
+	if err != nil {
+		h.respondWithMailFetcherError(c, err, "Failed to update mail fetcher")
+		return
+	}
+
+	h.respondWithSuccess(c, fetcher, "Mail fetcher updated")
+}
+
+// DeleteMailFetcher 删除代收外部邮箱
+func (h *Handler) DeleteMailFetcher(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	fetcherID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	if err := h.mailFetcherService.DeleteFetcher(c.Request.Context(), userID, fetcherID); err != nil {
+		h.respondWithMailFetcherError(c, err, "Failed to delete mail fetcher")
+		return
+	}
+
+	h.respondWithSuccess(c, nil, "Mail fetcher deleted")
+}
+
+// RunMailFetcher 立即执行一次代收
+func (h *Handler) RunMailFetcher(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	fetcherID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	result, err := h.mailFetcherService.RunFetcher(c.Request.Context(), userID, fetcherID)
+	if err != nil {
+		h.respondWithMailFetcherError(c, err, "Failed to run mail fetcher")
+		return
+	}
+
+	h.respondWithSuccess(c, result)
+}
+
+// respondWithMailFetcherError 将代收服务错误映射为HTTP状态码
+func (h *Handler) respondWithMailFetcherError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrMailFetcherNotFound):
+		h.respondWithError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrInvalidMailFetcher):
+		h.respondWithError(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrMailFetcherRunning):
+		h.respondWithError(c, http.StatusConflict, err.Error())
+	default:
+		h.respondWithError(c, http.StatusInternalServerError, message+": "+err.Error())
+	}
+}
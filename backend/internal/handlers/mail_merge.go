@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"firemail/internal/models"
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 收件人CSV大小上限
+const mailMergeMaxCSVSize = 10 * 1024 * 1024
+
+// trackingPixelGIF 1x1透明GIF
+var trackingPixelGIF = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+// CreateMailMergeCampaign 上传收件人CSV创建邮件合并活动
+func (h *Handler) CreateMailMergeCampaign(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	var req services.CreateMailMergeCampaignRequest
+	if err := c.ShouldBind(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Recipients CSV file is required")
+		return
+	}
+	defer file.Close()
+
+	if header.Size > mailMergeMaxCSVSize {
+		h.respondWithError(c, http.StatusBadRequest, "Recipients CSV too large (max 10MB)")
+		return
+	}
+
+	campaign, err := h.mailMergeService.CreateCampaign(c.Request.Context(), userID, &req, file)
+	if err != nil {
+		h.respondWithMailMergeError(c, err, "Failed to create campaign")
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Success: true,
+		Message: localize(c, "Campaign created successfully"),
+		Data:    campaign,
+	})
+}
+
+// GetMailMergeCampaigns 获取邮件合并活动列表
+func (h *Handler) GetMailMergeCampaigns(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	campaigns, err := h.mailMergeService.ListCampaigns(c.Request.Context(), userID)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get campaigns: "+err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, campaigns)
+}
+
+// GetMailMergeCampaign 获取邮件合并活动
+func (h *Handler) GetMailMergeCampaign(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	campaignID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	campaign, err := h.mailMergeService.GetCampaign(c.Request.Context(), userID, campaignID)
+	if err != nil {
+		h.respondWithMailMergeError(c, err, "Failed to get campaign")
+		return
+	}
+
+	h.respondWithSuccess(c, campaign)
+}
+
+// GetMailMergeRecipients 获取邮件合并活动的收件人发送状态
+func (h *Handler) GetMailMergeRecipients(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	campaignID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	var req services.ListMailMergeRecipientsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid query parameters: "+err.Error())
+		return
+	}
+
+	response, err := h.mailMergeService.ListRecipients(c.Request.Context(), userID, campaignID, &req)
+	if err != nil {
+		h.respondWithMailMergeError(c, err, "Failed to get recipients")
+		return
+	}
+
+	h.respondWithSuccess(c, response)
+}
+
+// StartMailMergeCampaign 开始发送邮件合并活动
+func (h *Handler) StartMailMergeCampaign(c *gin.Context) {
+	h.changeMailMergeCampaignStatus(c, h.mailMergeService.StartCampaign, "Campaign started")
+}
+
+// PauseMailMergeCampaign 暂停邮件合并活动
+func (h *Handler) PauseMailMergeCampaign(c *gin.Context) {
+	h.changeMailMergeCampaignStatus(c, h.mailMergeService.PauseCampaign, "Campaign paused")
+}
+
+// ResumeMailMergeCampaign 恢复邮件合并活动
+func (h *Handler) ResumeMailMergeCampaign(c *gin.Context) {
+	h.changeMailMergeCampaignStatus(c, h.mailMergeService.ResumeCampaign, "Campaign resumed")
+}
+
+// CancelMailMergeCampaign 取消邮件合并活动
+func (h *Handler) CancelMailMergeCampaign(c *gin.Context) {
+	h.changeMailMergeCampaignStatus(c, h.mailMergeService.CancelCampaign, "Campaign cancelled")
+}
+
+// GetMailMergeCampaignReport 获取活动的打开和点击统计
+func (h *Handler) GetMailMergeCampaignReport(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	campaignID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	report, err := h.mailMergeService.GetCampaignReport(c.Request.Context(), userID, campaignID)
+	if err != nil {
+		h.respondWithMailMergeError(c, err, "Failed to get campaign report")
+		return
+	}
+
+	h.respondWithSuccess(c, report)
+}
+
+// GetTrackingOptOuts 获取不跟踪名单
+func (h *Handler) GetTrackingOptOuts(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	optOuts, err := h.mailMergeService.ListTrackingOptOuts(c.Request.Context(), userID)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get tracking opt-outs: "+err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, optOuts)
+}
+
+// AddTrackingOptOut 添加不跟踪的地址或域名
+func (h *Handler) AddTrackingOptOut(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	var req services.TrackingOptOutRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	optOut, err := h.mailMergeService.AddTrackingOptOut(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondWithMailMergeError(c, err, "Failed to add tracking opt-out")
+		return
+	}
+
+	h.respondWithCreated(c, optOut, "Tracking opt-out added")
+}
+
+// DeleteTrackingOptOut 从不跟踪名单中移除
+func (h *Handler) DeleteTrackingOptOut(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	optOutID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	if err := h.mailMergeService.DeleteTrackingOptOut(c.Request.Context(), userID, optOutID); err != nil {
+		h.respondWithMailMergeError(c, err, "Failed to delete tracking opt-out")
+		return
+	}
+
+	h.respondWithSuccess(c, nil, "Tracking opt-out deleted")
+}
+
+// TrackMailMergeOpen 返回打开跟踪像素。令牌无效时同样返回图片，不向外透露令牌是否存在
+func (h *Handler) TrackMailMergeOpen(c *gin.Context) {
+	if err := h.mailMergeService.RecordOpen(c.Request.Context(), c.Param("token")); err != nil &&
+		!errors.Is(err, services.ErrTrackingLinkNotFound) {
+		log.Printf("Failed to record campaign open: %v", err)
+	}
+
+	c.Header("Cache-Control", "no-store, no-cache, must-revalidate, private")
+	c.Data(http.StatusOK, "image/gif", trackingPixelGIF)
+}
+
+// TrackMailMergeClick 记录点击并跳转到原始链接
+func (h *Handler) TrackMailMergeClick(c *gin.Context) {
+	linkID, exists := h.parseUintParam(c, "link_id")
+	if !exists {
+		return
+	}
+
+	target, err := h.mailMergeService.RecordClick(c.Request.Context(), c.Param("token"), linkID)
+	if err != nil {
+		h.respondWithMailMergeError(c, err, "Failed to record click")
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, target)
+}
+
+// changeMailMergeCampaignStatus 处理活动状态变更请求
+func (h *Handler) changeMailMergeCampaignStatus(c *gin.Context, change func(ctx context.Context, userID, campaignID uint) (*models.MailMergeCampaign, error), message string) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	campaignID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	campaign, err := change(c.Request.Context(), userID, campaignID)
+	if err != nil {
+		h.respondWithMailMergeError(c, err, "Failed to update campaign")
+		return
+	}
+
+	h.respondWithSuccess(c, campaign, message)
+}
+
+// respondWithMailMergeError 将邮件合并服务错误映射为HTTP状态码
+func (h *Handler) respondWithMailMergeError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrMailMergeCampaignNotFound),
+		errors.Is(err, services.ErrTrackingLinkNotFound),
+		errors.Is(err, services.ErrTrackingOptOutNotFound):
+		h.respondWithError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrMailMergeTrackingDisabled),
+		errors.Is(err, services.ErrInvalidTrackingOptOut):
+		h.respondWithError(c, http.StatusBadRequest, err.Error())
+	case strings.Contains(err.Error(), "access denied"),
+		strings.Contains(err.Error(), "permission denied"):
+		h.respondWithError(c, http.StatusForbidden, err.Error())
+	case strings.Contains(err.Error(), "not found"):
+		h.respondWithError(c, http.StatusNotFound, err.Error())
+	case strings.HasPrefix(err.Error(), "cannot change campaign status"),
+		strings.Contains(err.Error(), "status changed concurrently"):
+		h.respondWithError(c, http.StatusConflict, err.Error())
+	case strings.Contains(err.Error(), "CSV"),
+		strings.Contains(err.Error(), "recipients"),
+		strings.Contains(err.Error(), "rate_per_minute"),
+		strings.Contains(err.Error(), "is required"):
+		h.respondWithError(c, http.StatusBadRequest, err.Error())
+	default:
+		h.respondWithError(c, http.StatusInternalServerError, message+": "+err.Error())
+	}
+}
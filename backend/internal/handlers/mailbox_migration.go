@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"firemail/internal/models"
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MailboxMigrationPlanQuery 生成迁移文件夹映射的查询参数
+type MailboxMigrationPlanQuery struct {
+	SourceAccountID uint `form:"source_account_id" binding:"required"`
+	TargetAccountID uint `form:"target_account_id" binding:"required"`
+}
+
+// PlanMailboxMigration 获取源账户到目标账户的默认文件夹映射
+func (h *Handler) PlanMailboxMigration(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	var query MailboxMigrationPlanQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid query parameters: "+err.Error())
+		return
+	}
+
+	mappings, err := h.migrationService.PlanMigration(c.Request.Context(), userID, query.SourceAccountID, query.TargetAccountID)
+	if err != nil {
+		h.respondWithMailboxMigrationError(c, err, "Failed to plan migration")
+		return
+	}
+
+	h.respondWithSuccess(c, mappings)
+}
+
+// CreateMailboxMigration 创建邮箱迁移并开始复制
+func (h *Handler) CreateMailboxMigration(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	var req services.CreateMailboxMigrationRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	migration, err := h.migrationService.CreateMigration(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondWithMailboxMigrationError(c, err, "Failed to create migration")
+		return
+	}
+
+	h.respondWithCreated(c, migration, "Migration started")
+}
+
+// GetMailboxMigrations 获取邮箱迁移列表
+func (h *Handler) GetMailboxMigrations(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	migrations, err := h.migrationService.ListMigrations(c.Request.Context(), userID)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get migrations: "+err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, migrations)
+}
+
+// GetMailboxMigration 获取邮箱迁移及各文件夹进度
+func (h *Handler) GetMailboxMigration(c *gin.Context) {
+	h.changeMailboxMigrationStatus(c, h.migrationService.GetMigration, "")
+}
+
+// GetMailboxMigrationReport 获取邮箱迁移的核对报告
+func (h *Handler) GetMailboxMigrationReport(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	migrationID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	report, err := h.migrationService.GetMigrationReport(c.Request.Context(), userID, migrationID)
+	if err != nil {
+		h.respondWithMailboxMigrationError(c, err, "Failed to get migration report")
+		return
+	}
+
+	h.respondWithSuccess(c, report)
+}
+
+// PauseMailboxMigration 暂停邮箱迁移
+func (h *Handler) PauseMailboxMigration(c *gin.Context) {
+	h.changeMailboxMigrationStatus(c, h.migrationService.PauseMigration, "Migration paused")
+}
+
+// ResumeMailboxMigration 从断点继续邮箱迁移
+func (h *Handler) ResumeMailboxMigration(c *gin.Context) {
+	h.changeMailboxMigrationStatus(c, h.migrationService.ResumeMigration, "Migration resumed")
+}
+
+// CancelMailboxMigration 取消邮箱迁移
+func (h *Handler) CancelMailboxMigration(c *gin.Context) {
+	h.changeMailboxMigrationStatus(c, h.migrationService.CancelMigration, "Migration cancelled")
+}
+
+// changeMailboxMigrationStatus 处理按ID获取或变更迁移状态的请求
+func (h *Handler) changeMailboxMigrationStatus(c *gin.Context, change func(ctx context.Context, userID, migrationID uint) (*models.MailboxMigration, error), message string) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	migrationID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	migration, err := change(c.Request.Context(), userID, migrationID)
+	if err != nil {
+		h.respondWithMailboxMigrationError(c, err, "Failed to update migration")
+		return
+	}
+
+	if message == "" {
+		h.respondWithSuccess(c, migration)
+		return
+	}
+	h.respondWithSuccess(c, migration, message)
+}
+
+// respondWithMailboxMigrationError 将邮箱迁移服务错误映射为HTTP状态码
+func (h *Handler) respondWithMailboxMigrationError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrMailboxMigrationNotFound):
+		h.respondWithError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrInvalidMailboxMigration):
+		h.respondWithError(c, http.StatusBadRequest, err.Error())
+	case err.Error() == "account not found":
+		h.respondWithError(c, http.StatusNotFound, err.Error())
+	case strings.HasPrefix(err.Error(), "cannot change migration status"),
+		strings.Contains(err.Error(), "status changed concurrently"):
+		h.respondWithError(c, http.StatusConflict, err.Error())
+	default:
+		h.respondWithError(c, http.StatusInternalServerError, message+": "+err.Error())
+	}
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"net/http"
+
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMailboxStorageReport 获取账户的空间占用分析
+func (h *Handler) GetMailboxStorageReport(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	accountID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	var req services.StorageTopRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid query parameters: "+err.Error())
+		return
+	}
+
+	report, err := h.emailService.GetMailboxStorageReport(c.Request.Context(), userID, accountID, &req)
+	if err != nil {
+		h.respondWithStorageError(c, err, "Failed to analyze mailbox storage")
+		return
+	}
+
+	h.respondWithSuccess(c, report)
+}
+
+// StartStorageCleanup 按条件启动邮箱清理任务
+func (h *Handler) StartStorageCleanup(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	accountID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	var req services.StartStorageCleanupRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	job, err := h.emailService.StartStorageCleanup(c.Request.Context(), userID, accountID, &req)
+	if err != nil {
+		h.respondWithStorageError(c, err, "Failed to start cleanup")
+		return
+	}
+
+	if job.Finished() {
+		h.respondWithSuccess(c, job)
+		return
+	}
+	c.JSON(http.StatusAccepted, SuccessResponse{
+		Success: true,
+		Data:    job,
+		Message: localize(c, "Cleanup job started"),
+	})
+}
+
+// GetStorageCleanup 获取邮箱清理任务状态
+func (h *Handler) GetStorageCleanup(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	accountID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	job, err := h.emailService.GetStorageCleanup(c.Request.Context(), userID, accountID, c.Param("job_id"))
+	if err != nil {
+		h.respondWithError(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, job)
+}
+
+// CancelStorageCleanup 取消邮箱清理任务
+func (h *Handler) CancelStorageCleanup(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	accountID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	job, err := h.emailService.CancelStorageCleanup(c.Request.Context(), userID, accountID, c.Param("job_id"))
+	if err != nil {
+		h.respondWithError(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, job, "Cleanup job cancelled")
+}
+
+// respondWithStorageError 将空间分析/清理错误映射为HTTP状态码
+func (h *Handler) respondWithStorageError(c *gin.Context, err error, message string) {
+	switch err.Error() {
+	case "account not found", "folder not found":
+		h.respondWithError(c, http.StatusNotFound, err.Error())
+	case "at least one cleanup filter is required":
+		h.respondWithError(c, http.StatusBadRequest, err.Error())
+	default:
+		h.respondWithError(c, http.StatusInternalServerError, message+": "+err.Error())
+	}
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MuteThread 静音邮件所在会话
+func (h *Handler) MuteThread(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	emailID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	muted, err := h.emailService.MuteThread(c.Request.Context(), userID, emailID)
+	if err != nil {
+		h.respondWithMutedThreadError(c, err, "Failed to mute thread")
+		return
+	}
+
+	h.respondWithSuccess(c, muted, "Thread muted")
+}
+
+// UnmuteThread 取消静音邮件所在会话
+func (h *Handler) UnmuteThread(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	emailID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	if err := h.emailService.UnmuteThread(c.Request.Context(), userID, emailID); err != nil {
+		h.respondWithMutedThreadError(c, err, "Failed to unmute thread")
+		return
+	}
+
+	h.respondWithSuccess(c, nil, "Thread unmuted")
+}
+
+// GetMutedThreads 获取已静音的会话
+func (h *Handler) GetMutedThreads(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	threads, err := h.emailService.ListMutedThreads(c.Request.Context(), userID)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get muted threads")
+		return
+	}
+
+	h.respondWithSuccess(c, threads)
+}
+
+// DeleteMutedThread 从静音列表中移除会话
+func (h *Handler) DeleteMutedThread(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	mutedThreadID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	if err := h.emailService.DeleteMutedThread(c.Request.Context(), userID, mutedThreadID); err != nil {
+		h.respondWithMutedThreadError(c, err, "Failed to unmute thread")
+		return
+	}
+
+	h.respondWithSuccess(c, nil, "Thread unmuted")
+}
+
+// respondWithMutedThreadError 将会话静音错误映射为HTTP状态码
+func (h *Handler) respondWithMutedThreadError(c *gin.Context, err error, message string) {
+	switch err.Error() {
+	case "email not found", "muted thread not found", "thread is not muted":
+		h.respondWithError(c, http.StatusNotFound, err.Error())
+	default:
+		h.respondWithError(c, http.StatusInternalServerError, message+": "+err.Error())
+	}
+}
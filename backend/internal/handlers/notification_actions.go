@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExecuteNotificationAction 执行通知中的快捷操作（归档、标记已读、删除），
+// 令牌本身即授权，无需登录，每个令牌只能使用一次
+func (h *Handler) ExecuteNotificationAction(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Referrer-Policy", "no-referrer")
+
+	result, err := h.emailService.ExecuteNotificationAction(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNotificationActionInvalid):
+			h.respondWithError(c, http.StatusNotFound, "Notification action is invalid or already used")
+		case errors.Is(err, services.ErrNotificationActionExpired):
+			h.respondWithError(c, http.StatusGone, "Notification action has expired")
+		case err.Error() == "email not found":
+			h.respondWithError(c, http.StatusNotFound, "Email not found")
+		default:
+			log.Printf("Failed to execute notification action: %v", err)
+			h.respondWithError(c, http.StatusInternalServerError, "Failed to execute notification action")
+		}
+		return
+	}
+
+	h.respondWithSuccess(c, result, "Notification action completed")
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetNotifications 分页获取通知中心的通知
+func (h *Handler) GetNotifications(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	var req services.ListNotificationsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid query parameters: "+err.Error())
+		return
+	}
+
+	result, err := h.notificationService.ListNotifications(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get notifications: "+err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, result)
+}
+
+// GetNotificationCounts 获取未读通知的角标计数
+func (h *Handler) GetNotificationCounts(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	counts, err := h.notificationService.GetNotificationCounts(c.Request.Context(), userID)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get notifications: "+err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, counts)
+}
+
+// MarkNotifications 批量标记通知为已读或未读，未指定ID时修改全部通知
+func (h *Handler) MarkNotifications(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	var req services.MarkNotificationsRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.notificationService.MarkNotifications(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to update notifications: "+err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, result, "Notifications updated")
+}
+
+// UpdateNotification 标记单条通知为已读或未读
+func (h *Handler) UpdateNotification(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	notificationID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	var req services.UpdateNotificationRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	notification, err := h.notificationService.UpdateNotification(c.Request.Context(), userID, notificationID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrNotificationNotFound) {
+			h.respondWithError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to update notifications: "+err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, notification, "Notifications updated")
+}
@@ -125,7 +125,7 @@ func (h *Handler) InitOAuth(c *gin.Context) {
 		"state":    state,
 	}
 
-	h.respondWithSuccess(c, response, fmt.Sprintf("%s OAuth2 authorization URL generated", provider))
+	h.respondWithSuccess(c, response, localizef(c, "%s OAuth2 authorization URL generated", provider))
 }
 
 // InitGmailOAuth 初始化Gmail OAuth2认证
@@ -369,7 +369,7 @@ func (h *Handler) CreateOAuth2Account(c *gin.Context) {
 		gmailClientSecret := h.config.OAuth.Gmail.ClientSecret
 		if gmailClientSecret == "" {
 			if req.AccessToken == "" {
-				h.respondWithError(c, http.StatusServiceUnavailable, "Gmail OAuth2 client_secret 未配置，且缺少访问令牌，无法验证")
+				h.respondWithError(c, http.StatusServiceUnavailable, "Gmail OAuth2 client_secret is not configured and no access token was provided")
 				return
 			}
 			log.Printf("Gmail client_secret 未配置，跳过刷新，直接使用外部 OAuth 返回的令牌")
@@ -470,8 +470,8 @@ func (h *Handler) CreateOAuth2Account(c *gin.Context) {
 				"error_message": err.Error(),
 			})
 		} else {
-			// 测试成功，开始同步文件夹
-			if err := h.syncService.SyncEmails(context.Background(), accountID); err != nil {
+			// 测试成功，入队同步任务
+			if _, err := services.EnqueueAccountSync(context.Background(), h.jobQueue, userID, accountID); err != nil {
 				// 记录错误但不影响账户创建
 				h.db.Model(&models.EmailAccount{}).Where("id = ?", accountID).Updates(map[string]interface{}{
 					"sync_status":   "error",
@@ -670,8 +670,8 @@ func (h *Handler) CreateManualOAuth2Account(c *gin.Context) {
 				"error_message": err.Error(),
 			})
 		} else {
-			// 测试成功，开始同步文件夹
-			if err := h.syncService.SyncEmails(context.Background(), fullAccount.ID); err != nil {
+			// 测试成功，入队同步任务
+			if _, err := services.EnqueueAccountSync(context.Background(), h.jobQueue, fullAccount.UserID, fullAccount.ID); err != nil {
 				// 记录错误但不影响账户创建
 				h.db.Model(&models.EmailAccount{}).Where("id = ?", fullAccount.ID).Updates(map[string]interface{}{
 					"sync_status":   "error",
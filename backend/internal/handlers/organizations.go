@@ -0,0 +1,516 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetOrganizations 获取当前用户加入的组织
+func (h *Handler) GetOrganizations(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	orgs, err := h.organizationService.ListOrganizations(c.Request.Context(), userID)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get organizations: "+err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, orgs)
+}
+
+// CreateOrganization 创建组织
+func (h *Handler) CreateOrganization(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	var req services.CreateOrganizationRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	org, err := h.organizationService.CreateOrganization(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondWithOrganizationError(c, err, "Failed to create organization")
+		return
+	}
+
+	h.respondWithCreated(c, org, "Organization created")
+}
+
+// GetOrganization 获取组织详情
+func (h *Handler) GetOrganization(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	orgID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	org, err := h.organizationService.GetOrganization(c.Request.Context(), userID, orgID)
+	if err != nil {
+		h.respondWithOrganizationError(c, err, "Failed to get organization")
+		return
+	}
+
+	h.respondWithSuccess(c, org)
+}
+
+// DeleteOrganization 删除组织
+func (h *Handler) DeleteOrganization(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	orgID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	if err := h.organizationService.DeleteOrganization(c.Request.Context(), userID, orgID); err != nil {
+		h.respondWithOrganizationError(c, err, "Failed to delete organization")
+		return
+	}
+
+	h.respondWithSuccess(c, nil, "Organization deleted")
+}
+
+// InviteOrganizationMember 邀请成员加入组织
+func (h *Handler) InviteOrganizationMember(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	orgID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	var req services.InviteOrganizationMemberRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	invite, err := h.organizationService.InviteMember(c.Request.Context(), userID, orgID, &req)
+	if err != nil {
+		h.respondWithOrganizationError(c, err, "Failed to invite member")
+		return
+	}
+
+	h.respondWithCreated(c, invite, "Invite sent")
+}
+
+// RevokeOrganizationInvite 撤销组织邀请
+func (h *Handler) RevokeOrganizationInvite(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	orgID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+	inviteID, exists := h.parseUintParam(c, "invite_id")
+	if !exists {
+		return
+	}
+
+	if err := h.organizationService.RevokeInvite(c.Request.Context(), userID, orgID, inviteID); err != nil {
+		h.respondWithOrganizationError(c, err, "Failed to revoke invite")
+		return
+	}
+
+	h.respondWithSuccess(c, nil, "Invite revoked")
+}
+
+// UpdateOrganizationMember 修改成员角色
+func (h *Handler) UpdateOrganizationMember(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	orgID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+	memberID, exists := h.parseUintParam(c, "user_id")
+	if !exists {
+		return
+	}
+
+	var req services.UpdateOrganizationMemberRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	member, err := h.organizationService.UpdateMemberRole(c.Request.Context(), userID, orgID, memberID, req.Role)
+	if err != nil {
+		h.respondWithOrganizationError(c, err, "Failed to update member")
+		return
+	}
+
+	h.respondWithSuccess(c, member, "Member updated")
+}
+
+// RemoveOrganizationMember 移除成员，成员移除自己即退出组织
+func (h *Handler) RemoveOrganizationMember(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	orgID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+	memberID, exists := h.parseUintParam(c, "user_id")
+	if !exists {
+		return
+	}
+
+	if err := h.organizationService.RemoveMember(c.Request.Context(), userID, orgID, memberID); err != nil {
+		h.respondWithOrganizationError(c, err, "Failed to remove member")
+		return
+	}
+
+	h.respondWithSuccess(c, nil, "Member removed")
+}
+
+// ShareOrganizationMailbox 将邮箱账户共享到组织
+func (h *Handler) ShareOrganizationMailbox(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	orgID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	var req services.ShareMailboxRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	mailbox, err := h.organizationService.ShareMailbox(c.Request.Context(), userID, orgID, req.AccountID)
+	if err != nil {
+		h.respondWithOrganizationError(c, err, "Failed to share mailbox")
+		return
+	}
+
+	h.respondWithCreated(c, mailbox, "Mailbox shared")
+}
+
+// UnshareOrganizationMailbox 取消共享邮箱
+func (h *Handler) UnshareOrganizationMailbox(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	orgID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+	mailboxID, exists := h.parseUintParam(c, "mailbox_id")
+	if !exists {
+		return
+	}
+
+	if err := h.organizationService.UnshareMailbox(c.Request.Context(), userID, orgID, mailboxID); err != nil {
+		h.respondWithOrganizationError(c, err, "Failed to unshare mailbox")
+		return
+	}
+
+	h.respondWithSuccess(c, nil, "Mailbox unshared")
+}
+
+// SetSharedMailboxGrant 授予或修改成员对共享邮箱的权限
+func (h *Handler) SetSharedMailboxGrant(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	orgID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+	mailboxID, exists := h.parseUintParam(c, "mailbox_id")
+	if !exists {
+		return
+	}
+
+	var req services.SharedMailboxGrantRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	grant, err := h.organizationService.SetMailboxGrant(c.Request.Context(), userID, orgID, mailboxID, &req)
+	if err != nil {
+		h.respondWithOrganizationError(c, err, "Failed to set mailbox grant")
+		return
+	}
+
+	h.respondWithSuccess(c, grant, "Mailbox grant saved")
+}
+
+// RevokeSharedMailboxGrant 撤销成员对共享邮箱的权限
+func (h *Handler) RevokeSharedMailboxGrant(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	orgID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+	mailboxID, exists := h.parseUintParam(c, "mailbox_id")
+	if !exists {
+		return
+	}
+	memberID, exists := h.parseUintParam(c, "user_id")
+	if !exists {
+		return
+	}
+
+	if err := h.organizationService.RevokeMailboxGrant(c.Request.Context(), userID, orgID, mailboxID, memberID); err != nil {
+		h.respondWithOrganizationError(c, err, "Failed to revoke mailbox grant")
+		return
+	}
+
+	h.respondWithSuccess(c, nil, "Mailbox grant revoked")
+}
+
+// GetOrganizationInvites 获取当前用户收到的待处理邀请
+func (h *Handler) GetOrganizationInvites(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	invites, err := h.organizationService.ListInvites(c.Request.Context(), userID)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get invites: "+err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, invites)
+}
+
+// AcceptOrganizationInvite 接受组织邀请
+func (h *Handler) AcceptOrganizationInvite(c *gin.Context) {
+	h.respondToOrganizationInvite(c, true)
+}
+
+// DeclineOrganizationInvite 拒绝组织邀请
+func (h *Handler) DeclineOrganizationInvite(c *gin.Context) {
+	h.respondToOrganizationInvite(c, false)
+}
+
+func (h *Handler) respondToOrganizationInvite(c *gin.Context, accept bool) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	inviteID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	invite, err := h.organizationService.RespondToInvite(c.Request.Context(), userID, inviteID, accept)
+	if err != nil {
+		h.respondWithOrganizationError(c, err, "Failed to respond to invite")
+		return
+	}
+
+	message := "Invite declined"
+	if accept {
+		message = "Invite accepted"
+	}
+	h.respondWithSuccess(c, invite, message)
+}
+
+// GetSharedMailboxes 获取当前用户可以访问的共享邮箱
+func (h *Handler) GetSharedMailboxes(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	mailboxes, err := h.organizationService.ListSharedMailboxes(c.Request.Context(), userID)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get shared mailboxes: "+err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, mailboxes)
+}
+
+// GetSharedMailboxEmails 获取共享邮箱中的邮件及认领状态
+func (h *Handler) GetSharedMailboxEmails(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	mailboxID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	var req services.ListSharedEmailsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid query parameters: "+err.Error())
+		return
+	}
+
+	result, err := h.organizationService.ListSharedEmails(c.Request.Context(), userID, mailboxID, &req)
+	if err != nil {
+		h.respondWithOrganizationError(c, err, "Failed to get shared mailbox emails")
+		return
+	}
+
+	h.respondWithSuccess(c, result)
+}
+
+// GetSharedMailboxEmail 获取共享邮箱中的邮件
+func (h *Handler) GetSharedMailboxEmail(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	mailboxID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+	emailID, exists := h.parseUintParam(c, "email_id")
+	if !exists {
+		return
+	}
+
+	email, err := h.organizationService.GetSharedEmail(c.Request.Context(), userID, mailboxID, emailID)
+	if err != nil {
+		h.respondWithOrganizationError(c, err, "Failed to get shared mailbox email")
+		return
+	}
+
+	h.respondWithSuccess(c, email)
+}
+
+// ClaimSharedMailboxEmail 认领共享邮箱中的邮件
+func (h *Handler) ClaimSharedMailboxEmail(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	mailboxID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+	emailID, exists := h.parseUintParam(c, "email_id")
+	if !exists {
+		return
+	}
+
+	assignment, err := h.organizationService.ClaimEmail(c.Request.Context(), userID, mailboxID, emailID)
+	if err != nil {
+		h.respondWithOrganizationError(c, err, "Failed to claim email")
+		return
+	}
+
+	h.respondWithSuccess(c, assignment, "Email claimed")
+}
+
+// AssignSharedMailboxEmail 将共享邮箱中的邮件分配给成员
+func (h *Handler) AssignSharedMailboxEmail(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	mailboxID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+	emailID, exists := h.parseUintParam(c, "email_id")
+	if !exists {
+		return
+	}
+
+	var req services.AssignEmailRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	assignment, err := h.organizationService.AssignEmail(c.Request.Context(), userID, mailboxID, emailID, req.AssigneeID)
+	if err != nil {
+		h.respondWithOrganizationError(c, err, "Failed to assign email")
+		return
+	}
+
+	h.respondWithSuccess(c, assignment, "Email assigned")
+}
+
+// ReleaseSharedMailboxEmail 释放共享邮箱中邮件的认领
+func (h *Handler) ReleaseSharedMailboxEmail(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	mailboxID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+	emailID, exists := h.parseUintParam(c, "email_id")
+	if !exists {
+		return
+	}
+
+	if err := h.organizationService.ReleaseEmail(c.Request.Context(), userID, mailboxID, emailID); err != nil {
+		h.respondWithOrganizationError(c, err, "Failed to release email")
+		return
+	}
+
+	h.respondWithSuccess(c, nil, "Email released")
+}
+
+// respondWithOrganizationError 将组织服务错误映射为HTTP状态码
+func (h *Handler) respondWithOrganizationError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrOrganizationNotFound),
+		errors.Is(err, services.ErrOrganizationInviteNotFound),
+		errors.Is(err, services.ErrSharedMailboxNotFound),
+		errors.Is(err, services.ErrSharedEmailNotFound):
+		h.respondWithError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrOrganizationForbidden):
+		h.respondWithError(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrInvalidOrganizationRequest):
+		h.respondWithError(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrOrganizationConflict), errors.Is(err, services.ErrEmailAlreadyAssigned):
+		h.respondWithError(c, http.StatusConflict, err.Error())
+	default:
+		h.respondWithError(c, http.StatusInternalServerError, message+": "+err.Error())
+	}
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StartReparseJob 启动批量重新解析任务
+func (h *Handler) StartReparseJob(c *gin.Context) {
+	var req services.StartReparseJobRequest
+	if c.Request.ContentLength > 0 && !h.bindJSON(c, &req) {
+		return
+	}
+
+	job, err := h.emailService.StartReparseJob(c.Request.Context(), &req)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to start reparse job: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusAccepted, SuccessResponse{
+		Success: true,
+		Data:    job,
+		Message: localize(c, "Reparse job started"),
+	})
+}
+
+// GetReparseJob 获取批量重新解析任务状态
+func (h *Handler) GetReparseJob(c *gin.Context) {
+	job, err := h.emailService.GetReparseJob(c.Request.Context(), c.Param("job_id"))
+	if err != nil {
+		h.respondWithError(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, job)
+}
+
+// CancelReparseJob 取消批量重新解析任务
+func (h *Handler) CancelReparseJob(c *gin.Context) {
+	job, err := h.emailService.CancelReparseJob(c.Request.Context(), c.Param("job_id"))
+	if err != nil {
+		h.respondWithError(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, job, "Reparse job cancelled")
+}
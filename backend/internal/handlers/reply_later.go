@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetReplyLater 把邮件加入稍后回复，可选到期后回到收件箱顶部
+func (h *Handler) SetReplyLater(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	emailID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	var req services.ReplyLaterRequest
+	if c.Request.ContentLength > 0 && !h.bindJSON(c, &req) {
+		return
+	}
+
+	email, err := h.emailService.SetReplyLater(c.Request.Context(), userID, emailID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidReplyLater):
+			h.respondWithError(c, http.StatusBadRequest, err.Error())
+		case err.Error() == "email not found":
+			h.respondWithError(c, http.StatusNotFound, "Email not found")
+		default:
+			h.respondWithError(c, http.StatusInternalServerError, "Failed to update reply later")
+		}
+		return
+	}
+
+	h.respondWithSuccess(c, email, "Email added to reply later")
+}
+
+// ClearReplyLater 把邮件移出稍后回复
+func (h *Handler) ClearReplyLater(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	emailID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	email, err := h.emailService.ClearReplyLater(c.Request.Context(), userID, emailID)
+	if err != nil {
+		if err.Error() == "email not found" {
+			h.respondWithError(c, http.StatusNotFound, "Email not found")
+			return
+		}
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to update reply later")
+		return
+	}
+
+	h.respondWithSuccess(c, email, "Email removed from reply later")
+}
+
+// GetReplyLaterEmails 获取稍后回复列表
+func (h *Handler) GetReplyLaterEmails(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	req := &services.ReplyLaterListRequest{
+		AccountID: h.parseOptionalUintQuery(c, "account_id"),
+		Page:      h.parseIntQuery(c, "page", 1),
+		PageSize:  h.parseIntQuery(c, "page_size", 20),
+	}
+	req.Page, req.PageSize = h.validatePagination(req.Page, req.PageSize)
+
+	response, err := h.emailService.ListReplyLaterEmails(c.Request.Context(), userID, req)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get reply later emails")
+		return
+	}
+
+	h.respondWithSuccess(c, response)
+}
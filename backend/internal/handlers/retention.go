@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetRetentionPolicies 获取邮件保留规则列表
+func (h *Handler) GetRetentionPolicies(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	policies, err := h.retentionService.ListPolicies(c.Request.Context(), userID)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get retention policies: "+err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, policies)
+}
+
+// CreateRetentionPolicy 创建邮件保留规则
+func (h *Handler) CreateRetentionPolicy(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	var req services.RetentionPolicyRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	policy, err := h.retentionService.CreatePolicy(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondWithRetentionError(c, err, "Failed to create retention policy")
+		return
+	}
+
+	h.respondWithCreated(c, policy, "Retention policy created")
+}
+
+// UpdateRetentionPolicy 修改邮件保留规则
+func (h *Handler) UpdateRetentionPolicy(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	policyID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	var req services.RetentionPolicyRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	policy, err := h.retentionService.UpdatePolicy(c.Request.Context(), userID, policyID, &req)
+	if err != nil {
+		h.respondWithRetentionError(c, err, "Failed to update retention policy")
+		return
+	}
+
+	h.respondWithSuccess(c, policy, "Retention policy updated")
+}
+
+// DeleteRetentionPolicy 删除邮件保留规则及其执行记录
+func (h *Handler) DeleteRetentionPolicy(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	policyID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	if err := h.retentionService.DeletePolicy(c.Request.Context(), userID, policyID); err != nil {
+		h.respondWithRetentionError(c, err, "Failed to delete retention policy")
+		return
+	}
+
+	h.respondWithSuccess(c, nil, "Retention policy deleted")
+}
+
+// PreviewRetentionPolicy 试运行保留规则，返回会被处理的邮件数量和样例
+func (h *Handler) PreviewRetentionPolicy(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	policyID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	preview, err := h.retentionService.PreviewPolicy(c.Request.Context(), userID, policyID)
+	if err != nil {
+		h.respondWithRetentionError(c, err, "Failed to preview retention policy")
+		return
+	}
+
+	h.respondWithSuccess(c, preview)
+}
+
+// RunRetentionPolicy 立即在后台执行保留规则
+func (h *Handler) RunRetentionPolicy(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	policyID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	run, err := h.retentionService.RunPolicy(c.Request.Context(), userID, policyID)
+	if err != nil {
+		h.respondWithRetentionError(c, err, "Failed to run retention policy")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, SuccessResponse{
+		Success: true,
+		Message: localize(c, "Retention policy started"),
+		Data:    run,
+	})
+}
+
+// GetRetentionRuns 获取保留规则的执行记录
+func (h *Handler) GetRetentionRuns(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	var req services.ListRetentionRunsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid query parameters: "+err.Error())
+		return
+	}
+
+	runs, err := h.retentionService.ListRuns(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondWithRetentionError(c, err, "Failed to get retention runs")
+		return
+	}
+
+	h.respondWithSuccess(c, runs)
+}
+
+// respondWithRetentionError 将保留规则服务错误映射为HTTP状态码
+func (h *Handler) respondWithRetentionError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrRetentionPolicyNotFound):
+		h.respondWithError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrInvalidRetentionPolicy):
+		h.respondWithError(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrRetentionPolicyRunning):
+		h.respondWithError(c, http.StatusConflict, err.Error())
+	default:
+		h.respondWithError(c, http.StatusInternalServerError, message+": "+err.Error())
+	}
+}
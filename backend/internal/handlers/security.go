@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"firemail/internal/config"
+	"firemail/internal/middleware"
+	"firemail/internal/models"
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newIPAccessRule 创建按来源IP限制访问的规则，配置在启动时已校验
+func newIPAccessRule(name string, allowlist []string, cfg config.AccessConfig, locator services.IPGeoLocator) *middleware.IPAccessRule {
+	var locate middleware.CountryLocator
+	if locator != nil {
+		locate = func(ctx context.Context, ip string) (string, error) {
+			info, err := locator.Locate(ctx, ip)
+			if err != nil {
+				return "", err
+			}
+			return info.CountryCode, nil
+		}
+	}
+	rule, err := middleware.NewIPAccessRule(allowlist, cfg.AllowedCountries, cfg.BlockedCountries, locate)
+	if err != nil {
+		log.Printf("Warning: Invalid %s access rule: %v", name, err)
+		return nil
+	}
+	return rule
+}
+
+// AdminIPAccess 管理接口的来源IP限制
+func (h *Handler) AdminIPAccess() gin.HandlerFunc {
+	return middleware.IPAccess(h.adminAccessRule, h.recordIPDenial)
+}
+
+// LoginIPAccess 登录接口的来源IP限制
+func (h *Handler) LoginIPAccess() gin.HandlerFunc {
+	return middleware.IPAccess(h.loginAccessRule, h.recordIPDenial)
+}
+
+// recordIPDenial 记录被拒绝的访问，审计日志写入失败不影响拒绝请求
+func (h *Handler) recordIPDenial(c *gin.Context, denial *middleware.IPDenial) {
+	event := &models.SecurityEvent{
+		EventType:   models.SecurityEventIPDenied,
+		IP:          denial.IP,
+		Method:      c.Request.Method,
+		Path:        c.Request.URL.Path,
+		Reason:      denial.Reason,
+		CountryCode: denial.CountryCode,
+		UserAgent:   c.Request.UserAgent(),
+	}
+	if err := h.securityEventService.Record(c.Request.Context(), event); err != nil {
+		log.Printf("Failed to record security event: %v", err)
+	}
+}
+
+// GetSecurityEvents 列出安全事件审计日志
+func (h *Handler) GetSecurityEvents(c *gin.Context) {
+	filter := services.SecurityEventFilter{
+		EventType: c.Query("type"),
+		IP:        c.Query("ip"),
+		Limit:     h.parseIntQuery(c, "limit", 0),
+	}
+	if since := c.Query("since"); since != "" {
+		parsed, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			h.respondWithError(c, http.StatusBadRequest, "Invalid since parameter, expected RFC3339 time")
+			return
+		}
+		filter.Since = &parsed
+	}
+
+	events, err := h.securityEventService.List(c.Request.Context(), filter)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to list security events")
+		return
+	}
+	h.respondWithSuccess(c, events)
+}
+
+// recordAuthFailure 记录网页登录失败，写入fail2ban可读取的日志和安全事件审计日志
+func (h *Handler) recordAuthFailure(c *gin.Context, username, reason string) {
+	h.authFailureService.Record(c.Request.Context(), services.AuthFailure{
+		Service:  services.AuthServiceWeb,
+		IP:       c.ClientIP(),
+		Username: username,
+		Reason:   reason,
+	})
+}
+
+// AuthFailuresResponse 认证失败汇总
+type AuthFailuresResponse struct {
+	Window    string                        `json:"window"`
+	Threshold int                           `json:"threshold"`
+	IPs       []services.AuthFailureSummary `json:"ips"`
+}
+
+// GetAuthFailures 按IP汇总最近的认证失败，失败次数达到阈值的IP标记为建议封禁
+func (h *Handler) GetAuthFailures(c *gin.Context) {
+	window := h.authFailureService.BanWindow()
+	if value := c.Query("window"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			h.respondWithError(c, http.StatusBadRequest, "Invalid window parameter, expected a positive duration such as 30m")
+			return
+		}
+		window = parsed
+	}
+
+	summaries, err := h.authFailureService.Summaries(c.Request.Context(), window)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to list auth failures")
+		return
+	}
+	h.respondWithSuccess(c, AuthFailuresResponse{
+		Window:    window.String(),
+		Threshold: h.authFailureService.BanThreshold(),
+		IPs:       summaries,
+	})
+}
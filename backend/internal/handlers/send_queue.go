@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetStuckSends 列出发送队列中超过阈值仍未发出的邮件
+func (h *Handler) GetStuckSends(c *gin.Context) {
+	var olderThan time.Duration
+	if value := c.Query("older_than"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			h.respondWithError(c, http.StatusBadRequest, "Invalid older_than parameter, expected a positive duration such as 15m")
+			return
+		}
+		olderThan = parsed
+	}
+
+	sender, ok := h.emailSender.(*services.StandardEmailSender)
+	if !ok {
+		h.respondWithError(c, http.StatusNotImplemented, "Send queue inspection is not supported by the configured sender")
+		return
+	}
+
+	stuck, err := sender.ListStuckSends(c.Request.Context(), olderThan)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to list stuck sends")
+		return
+	}
+	h.respondWithSuccess(c, stuck)
+}
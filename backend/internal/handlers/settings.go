@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSettings 获取当前用户的设置
+func (h *Handler) GetSettings(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	settings, err := h.settingsService.GetSettings(c.Request.Context(), userID)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get settings: "+err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, settings)
+}
+
+// UpdateSettings 修改当前用户的设置，只修改提供的字段
+func (h *Handler) UpdateSettings(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	var req services.UpdateUserSettingsRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	settings, err := h.settingsService.UpdateSettings(c.Request.Context(), userID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidUserSettings) {
+			h.respondWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to update settings: "+err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, settings, "Settings updated")
+}
+
+// emailsPerPage 用户设置的邮件列表每页数量，读取失败时使用默认值
+func (h *Handler) emailsPerPage(c *gin.Context, userID uint) int {
+	settings, err := h.settingsService.GetSettings(c.Request.Context(), userID)
+	if err != nil {
+		return 20
+	}
+	return settings.EmailsPerPage
+}
@@ -1,18 +1,32 @@
 package handlers
 
 import (
+	"context"
+	"log"
 	"net/http"
+	"strconv"
+	"time"
 
+	"firemail/internal/middleware"
 	"firemail/internal/sse"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
+// 长轮询默认和最长的等待时间（秒）
+const (
+	defaultPollTimeoutSeconds = 25
+	maxPollTimeoutSeconds     = 60
+)
+
 // HandleSSE 处理SSE连接
 func (h *Handler) HandleSSE(c *gin.Context) {
-	// 尝试从查询参数获取token进行认证
+	// 尝试从查询参数获取token进行认证，EventSource会自动附带会话Cookie
 	token := c.Query("token")
+	if token == "" {
+		token = middleware.SessionCookieToken(c)
+	}
 	var userID uint
 	var exists bool
 
@@ -25,6 +39,8 @@ func (h *Handler) HandleSSE(c *gin.Context) {
 		}
 		userID = user.ID
 		exists = true
+		c.Set("userID", userID)
+		h.applyUserLocale(c)
 	} else {
 		// 尝试从中间件获取用户ID
 		userID, exists = h.getCurrentUserID(c)
@@ -40,6 +56,18 @@ func (h *Handler) HandleSSE(c *gin.Context) {
 		clientID = uuid.New().String()
 	}
 
+	// 按类别和账户过滤推送的事件，未指定时接收全部事件
+	subscription, err := sse.ParseSubscription(c.Query("events"), c.Query("account_ids"))
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid subscription: "+err.Error())
+		return
+	}
+
+	since, ok := h.parseEventCursor(c)
+	if !ok {
+		return
+	}
+
 	// 检查Accept头（放宽要求，支持EventSource默认行为）
 	accept := c.GetHeader("Accept")
 	if accept != "" && accept != "text/event-stream" && accept != "*/*" {
@@ -55,16 +83,67 @@ func (h *Handler) HandleSSE(c *gin.Context) {
 	c.Header("Access-Control-Allow-Headers", "Cache-Control")
 
 	// 处理SSE连接
-	if err := h.sseService.HandleConnection(c.Request.Context(), userID, clientID, c.Writer, c.Request); err != nil {
+	ctx := sse.WithSubscription(c.Request.Context(), subscription)
+	if since != nil {
+		ctx = sse.WithReplayCursor(ctx, *since)
+	}
+	if err := h.sseService.HandleConnection(ctx, userID, clientID, c.Writer, c.Request); err != nil {
 		h.respondWithError(c, http.StatusInternalServerError, "Failed to establish SSE connection: "+err.Error())
 		return
 	}
 
-	// 保持连接打开
+	// 保持连接打开，直到客户端断开或服务关闭
 	select {
 	case <-c.Request.Context().Done():
 		return
+	case <-h.sseService.Done():
+		return
+	}
+}
+
+// PollEvents 长轮询获取事件，供无法保持SSE连接的客户端使用。有游标之后的事件时立即返回，
+// 否则最多等待 timeout 秒；返回的 cursor 作为下次请求的 since
+func (h *Handler) PollEvents(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
 	}
+
+	since, ok := h.parseEventCursor(c)
+	if !ok {
+		return
+	}
+
+	subscription, err := sse.ParseSubscription(c.Query("events"), c.Query("account_ids"))
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid subscription: "+err.Error())
+		return
+	}
+
+	timeout := h.parseIntQuery(c, "timeout", defaultPollTimeoutSeconds)
+	if timeout < 0 {
+		timeout = 0
+	}
+	if timeout > maxPollTimeoutSeconds {
+		timeout = maxPollTimeoutSeconds
+	}
+
+	batch := h.sseService.PollEvents(c.Request.Context(), userID, since, subscription, time.Duration(timeout)*time.Second)
+	h.respondWithSuccess(c, batch)
+}
+
+// parseEventCursor 解析事件游标参数 since，未提供时返回 nil
+func (h *Handler) parseEventCursor(c *gin.Context) (*uint64, bool) {
+	value := c.Query("since")
+	if value == "" {
+		return nil, true
+	}
+	since, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid since cursor")
+		return nil, false
+	}
+	return &since, true
 }
 
 // GetSSEStats 获取SSE统计信息
@@ -94,7 +173,7 @@ func (h *Handler) SendTestEvent(c *gin.Context) {
 	var event *sse.Event
 	switch req.Type {
 	case "notification":
-		event = sse.NewNotificationEvent("测试通知", req.Message, "info", userID)
+		event = sse.NewNotificationEvent(localize(c, "Test notification"), req.Message, "info", userID)
 	case "heartbeat":
 		event = sse.NewHeartbeatEvent("")
 	default:
@@ -116,10 +195,18 @@ func (h *Handler) SendTestEvent(c *gin.Context) {
 
 // StartSSEService 启动SSE服务
 func (h *Handler) StartSSEService() error {
-	return h.sseService.Start(nil)
+	return h.sseService.Start(context.Background())
 }
 
 // StopSSEService 停止SSE服务
 func (h *Handler) StopSSEService() error {
 	return h.sseService.Stop()
 }
+
+// ShutdownSSEService 通知SSE客户端服务即将关闭并结束所有长连接，
+// 否则HTTP服务器关闭时会一直等待这些连接
+func (h *Handler) ShutdownSSEService() {
+	if err := h.sseService.Shutdown(); err != nil {
+		log.Printf("Failed to shut down SSE service: %v", err)
+	}
+}
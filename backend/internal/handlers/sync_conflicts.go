@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSyncConflicts 获取同步冲突，可按状态筛选
+func (h *Handler) GetSyncConflicts(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	conflicts, err := h.emailService.ListSyncConflicts(c.Request.Context(), userID, c.Query("status"))
+	if err != nil {
+		h.respondWithSyncConflictError(c, err, "Failed to get sync conflicts")
+		return
+	}
+
+	h.respondWithSuccess(c, conflicts)
+}
+
+// ResolveSyncConflict 选择以服务器或本地状态处理同步冲突
+func (h *Handler) ResolveSyncConflict(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	conflictID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	var req services.ResolveSyncConflictRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	conflict, err := h.emailService.ResolveSyncConflict(c.Request.Context(), userID, conflictID, req.Resolution)
+	if err != nil {
+		h.respondWithSyncConflictError(c, err, "Failed to resolve sync conflict")
+		return
+	}
+
+	h.respondWithSuccess(c, conflict, "Sync conflict resolved")
+}
+
+// respondWithSyncConflictError 将同步冲突错误映射为HTTP状态码
+func (h *Handler) respondWithSyncConflictError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrSyncConflictResolved):
+		h.respondWithError(c, http.StatusConflict, "Sync conflict already resolved")
+	case err.Error() == "sync conflict not found", err.Error() == "email not found":
+		h.respondWithError(c, http.StatusNotFound, err.Error())
+	case strings.HasPrefix(err.Error(), "invalid status"), strings.HasPrefix(err.Error(), "invalid resolution"):
+		h.respondWithError(c, http.StatusBadRequest, err.Error())
+	default:
+		h.respondWithError(c, http.StatusInternalServerError, message+": "+err.Error())
+	}
+}
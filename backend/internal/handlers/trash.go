@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTrash 获取回收站中的邮件
+func (h *Handler) GetTrash(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	req := &services.TrashListRequest{
+		AccountID: h.parseOptionalUintQuery(c, "account_id"),
+		Page:      h.parseIntQuery(c, "page", 1),
+		PageSize:  h.parseIntQuery(c, "page_size", 20),
+	}
+	req.Page, req.PageSize = h.validatePagination(req.Page, req.PageSize)
+
+	response, err := h.emailService.ListTrashedEmails(c.Request.Context(), userID, req)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get trash")
+		return
+	}
+
+	h.respondWithSuccess(c, response)
+}
+
+// RestoreTrashedEmails 从回收站恢复邮件
+func (h *Handler) RestoreTrashedEmails(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	var req services.TrashEmailsRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	restored, err := h.emailService.RestoreEmails(c.Request.Context(), userID, req.EmailIDs)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to restore emails")
+		return
+	}
+
+	h.respondWithSuccess(c, &services.TrashOperationResponse{Affected: restored}, "Emails restored successfully")
+}
+
+// PurgeTrashedEmails 彻底删除回收站中的邮件
+func (h *Handler) PurgeTrashedEmails(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	var req services.TrashEmailsRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	purged, err := h.emailService.PurgeEmails(c.Request.Context(), userID, req.EmailIDs)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to purge emails")
+		return
+	}
+
+	h.respondWithSuccess(c, &services.TrashOperationResponse{Affected: purged}, "Emails permanently deleted")
+}
+
+// EmptyTrash 清空回收站，可按账户限定
+func (h *Handler) EmptyTrash(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	purged, err := h.emailService.EmptyTrash(c.Request.Context(), userID, h.parseOptionalUintQuery(c, "account_id"))
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to empty trash")
+		return
+	}
+
+	h.respondWithSuccess(c, &services.TrashOperationResponse{Affected: purged}, "Trash emptied")
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetVerificationCodes 获取最近收到的邮件中的验证码
+func (h *Handler) GetVerificationCodes(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	req := &services.VerificationCodesRequest{
+		AccountID: h.parseOptionalUintQuery(c, "account_id"),
+		Minutes:   h.parseIntQuery(c, "minutes", 0),
+	}
+
+	codes, err := h.emailService.GetVerificationCodes(c.Request.Context(), userID, req)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get verification codes")
+		return
+	}
+
+	h.respondWithSuccess(c, codes)
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ifMatchVersion 解析 If-Match 中客户端读取到的版本号，未提供时返回nil；格式错误时返回400
+func (h *Handler) ifMatchVersion(c *gin.Context) (*uint, bool) {
+	value := strings.TrimSpace(c.GetHeader("If-Match"))
+	if value == "" || value == "*" {
+		return nil, true
+	}
+	value = strings.Trim(strings.TrimPrefix(value, "W/"), `"`)
+	version, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || version == 0 {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid If-Match header, expected the record version")
+		return nil, false
+	}
+	result := uint(version)
+	return &result, true
+}
+
+// versionedContext 把 If-Match 中的版本号附带到请求上下文，更新时要求记录仍是该版本
+func (h *Handler) versionedContext(c *gin.Context) (context.Context, bool) {
+	version, ok := h.ifMatchVersion(c)
+	if !ok {
+		return nil, false
+	}
+	if version == nil {
+		return c.Request.Context(), true
+	}
+	return services.WithExpectedVersion(c.Request.Context(), *version), true
+}
+
+// setVersionETag 在响应头中返回记录的版本号，客户端修改时通过 If-Match 提交
+func setVersionETag(c *gin.Context, version uint) {
+	c.Header("ETag", `"`+strconv.FormatUint(uint64(version), 10)+`"`)
+}
+
+// respondWithVersionConflict 版本冲突时返回409并附带记录的最新状态，其他错误返回 false
+func respondWithVersionConflict(c *gin.Context, err error) bool {
+	var conflict *services.VersionConflictError
+	if !errors.As(err, &conflict) {
+		return false
+	}
+	c.JSON(http.StatusConflict, ErrorResponse{
+		Error:   http.StatusText(http.StatusConflict),
+		Message: localize(c, conflict.Error()),
+		Code:    "version_conflict",
+		Current: conflict.Current,
+	})
+	return true
+}
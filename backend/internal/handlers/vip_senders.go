@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+
+	"firemail/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetVIPSenders 获取VIP发件人列表
+func (h *Handler) GetVIPSenders(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	senders, err := h.emailService.ListVIPSenders(c.Request.Context(), userID)
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get VIP senders")
+		return
+	}
+
+	h.respondWithSuccess(c, senders)
+}
+
+// AddVIPSender 添加VIP发件人
+func (h *Handler) AddVIPSender(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	var req services.AddVIPSenderRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	sender, err := h.emailService.AddVIPSender(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondWithVIPSenderError(c, err, "Failed to add VIP sender")
+		return
+	}
+
+	h.respondWithCreated(c, sender, "VIP sender added")
+}
+
+// RemoveVIPSender 移除VIP发件人
+func (h *Handler) RemoveVIPSender(c *gin.Context) {
+	userID, exists := h.getCurrentUserID(c)
+	if !exists {
+		return
+	}
+
+	senderID, exists := h.parseUintParam(c, "id")
+	if !exists {
+		return
+	}
+
+	if err := h.emailService.RemoveVIPSender(c.Request.Context(), userID, senderID); err != nil {
+		h.respondWithVIPSenderError(c, err, "Failed to remove VIP sender")
+		return
+	}
+
+	h.respondWithSuccess(c, nil, "VIP sender removed")
+}
+
+// respondWithVIPSenderError 将VIP发件人错误映射为HTTP状态码
+func (h *Handler) respondWithVIPSenderError(c *gin.Context, err error, message string) {
+	switch err.Error() {
+	case "vip sender not found":
+		h.respondWithError(c, http.StatusNotFound, err.Error())
+	case "vip sender already exists":
+		h.respondWithError(c, http.StatusConflict, err.Error())
+	case "invalid email address":
+		h.respondWithError(c, http.StatusBadRequest, err.Error())
+	default:
+		h.respondWithError(c, http.StatusInternalServerError, message+": "+err.Error())
+	}
+}
@@ -0,0 +1,152 @@
+// Package i18n 将面向用户的文本按语言环境翻译。
+// 消息以英文原文作为消息ID，英文为源语言不需要目录，其他语言的目录位于 locales 目录下
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ZhCN 简体中文
+	ZhCN = "zh-CN"
+	// En 英文
+	En = "en"
+
+	// Default 无法确定语言环境时使用的语言
+	Default = ZhCN
+)
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// catalogs 各语言的消息目录，英文为源语言，目录为空
+var catalogs = map[string]map[string]string{En: {}}
+
+func init() {
+	entries, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		panic(fmt.Sprintf("i18n: failed to read catalogs: %v", err))
+	}
+	for _, entry := range entries {
+		data, err := localeFiles.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("i18n: failed to read catalog %s: %v", entry.Name(), err))
+		}
+		var catalog map[string]string
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog %s: %v", entry.Name(), err))
+		}
+		catalogs[strings.TrimSuffix(entry.Name(), ".json")] = catalog
+	}
+}
+
+// Supported 返回支持的语言环境
+func Supported() []string {
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Normalize 将语言标签规范化为支持的语言环境，如 zh、zh_cn、zh-Hans-CN 都对应 zh-CN
+func Normalize(tag string) (string, bool) {
+	tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	if tag == "" {
+		return "", false
+	}
+	for locale := range catalogs {
+		if strings.ToLower(locale) == tag {
+			return locale, true
+		}
+	}
+	// 按主语言匹配，繁体中文没有目录，不回退到简体
+	primary := strings.SplitN(tag, "-", 2)[0]
+	switch {
+	case primary == "en":
+		return En, true
+	case primary == "zh" && !strings.Contains(tag, "hant") && !strings.HasSuffix(tag, "-tw") && !strings.HasSuffix(tag, "-hk"):
+		return ZhCN, true
+	}
+	return "", false
+}
+
+// FromAcceptLanguage 按 Accept-Language 请求头的权重选择支持的语言环境，没有匹配时返回空字符串
+func FromAcceptLanguage(header string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q <= bestQ {
+			continue
+		}
+		if locale, ok := Normalize(fields[0]); ok {
+			best, bestQ = locale, q
+		}
+	}
+	return best
+}
+
+// T 翻译消息，有参数时按 fmt 格式化；没有对应的翻译时使用原文
+func T(locale, message string, args ...interface{}) string {
+	if translated, ok := catalogs[locale][message]; ok && translated != "" {
+		message = translated
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// Translate 翻译已经生成的消息。
+// 接口错误通常为 "Failed to xxx: 原因" 的形式，整体没有翻译时按 ": " 拆开逐段翻译，无法翻译的部分保留原文
+func Translate(locale, message string) string {
+	catalog := catalogs[locale]
+	if len(catalog) == 0 || message == "" {
+		return message
+	}
+	if translated, ok := catalog[message]; ok && translated != "" {
+		return translated
+	}
+	head, rest, found := strings.Cut(message, ": ")
+	if !found {
+		return message
+	}
+	if translated, ok := catalog[head]; ok && translated != "" {
+		head = translated
+	}
+	return head + ": " + Translate(locale, rest)
+}
+
+type localeKey struct{}
+
+// WithLocale 在上下文中记录请求的语言环境
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// FromContext 上下文中记录的语言环境，没有时返回 Default
+func FromContext(ctx context.Context) string {
+	if ctx != nil {
+		if locale, ok := ctx.Value(localeKey{}).(string); ok && locale != "" {
+			return locale
+		}
+	}
+	return Default
+}
@@ -0,0 +1,49 @@
+package i18n
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeAndAcceptLanguage(t *testing.T) {
+	for tag, want := range map[string]string{
+		"zh-CN": ZhCN, "zh_cn": ZhCN, "zh": ZhCN, "zh-Hans-CN": ZhCN,
+		"EN": En, "en-US": En, "en_GB": En,
+	} {
+		got, ok := Normalize(tag)
+		require.True(t, ok, tag)
+		require.Equal(t, want, got, tag)
+	}
+	for _, tag := range []string{"", "fr-FR", "zh-TW", "zh-Hant"} {
+		_, ok := Normalize(tag)
+		require.False(t, ok, tag)
+	}
+
+	require.Equal(t, En, FromAcceptLanguage("fr-FR,en-US;q=0.8,zh-CN;q=0.5"))
+	require.Equal(t, ZhCN, FromAcceptLanguage("en;q=0.3, zh-CN"))
+	require.Empty(t, FromAcceptLanguage("fr, de;q=0.9"))
+	require.Empty(t, FromAcceptLanguage(""))
+}
+
+func TestTranslate(t *testing.T) {
+	require.Equal(t, "邮件已回复", T(ZhCN, "Email replied"))
+	require.Equal(t, "Email replied", T(En, "Email replied"))
+	require.Equal(t, "已回复邮件: Invoice", T(ZhCN, "Replied to email: %s", "Invoice"))
+	require.Equal(t, "文件夹 'Inbox' 内的 3 封邮件已标记为已读", T(ZhCN, "Marked %[2]d emails in folder '%[1]s' as read", "Inbox", 3))
+	require.Equal(t, "Marked 3 emails in folder 'Inbox' as read", T(En, "Marked %[2]d emails in folder '%[1]s' as read", "Inbox", 3))
+	require.Equal(t, "Not in catalog 7", T(ZhCN, "Not in catalog %d", 7))
+
+	// 带原因的错误逐段翻译，无法翻译的部分保留原文
+	require.Equal(t, "更新设置失败: 用户设置无效: unknown time zone", Translate(ZhCN, "Failed to update settings: invalid user settings: unknown time zone"))
+	require.Equal(t, "获取邮件失败: database is locked", Translate(ZhCN, "Failed to get emails: database is locked"))
+	require.Equal(t, "Failed to get emails: email not found", Translate(En, "Failed to get emails: email not found"))
+	require.Equal(t, "something: else", Translate(ZhCN, "something: else"))
+}
+
+func TestLocaleContext(t *testing.T) {
+	require.Equal(t, Default, FromContext(context.Background()))
+	require.Equal(t, En, FromContext(WithLocale(context.Background(), En)))
+	require.Equal(t, []string{En, ZhCN}, Supported())
+}
@@ -0,0 +1,188 @@
+package imapd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/emersion/go-imap"
+	imapbackend "github.com/emersion/go-imap/backend"
+)
+
+// updateTimeout 等待未经请求的响应写出的最长时间，连接已断开时不再等待
+const updateTimeout = 5 * time.Second
+
+// backend go-imap后端，连接登录后的操作都转交给Store
+type backend struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	store    Store
+	updates  chan imapbackend.Update
+	sessions atomic.Uint64
+
+	onAuthFailure func(ip, username string)
+}
+
+func newBackend(ctx context.Context, cancel context.CancelFunc, store Store) *backend {
+	return &backend{
+		ctx:     ctx,
+		cancel:  cancel,
+		store:   store,
+		updates: make(chan imapbackend.Update, 16),
+	}
+}
+
+// Login 校验FireMail用户名和密码
+func (b *backend) Login(connInfo *imap.ConnInfo, username, password string) (imapbackend.User, error) {
+	userID, err := b.store.Authenticate(b.ctx, username, password)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCredentials) {
+			if b.onAuthFailure != nil {
+				b.onAuthFailure(remoteIP(connInfo), username)
+			}
+			return nil, imapbackend.ErrInvalidCredentials
+		}
+		return nil, err
+	}
+	return &user{
+		backend:  b,
+		userID:   userID,
+		username: username,
+		session:  fmt.Sprintf("%s#%d", username, b.sessions.Add(1)),
+	}, nil
+}
+
+// remoteIP 连接的来源IP，取不到时返回空字符串
+func remoteIP(connInfo *imap.ConnInfo) string {
+	if connInfo == nil || connInfo.RemoteAddr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(connInfo.RemoteAddr.String())
+	if err != nil {
+		return connInfo.RemoteAddr.String()
+	}
+	return host
+}
+
+// Updates 未经请求的响应（EXISTS、EXPUNGE、FETCH），每个会话只收到自己的更新
+func (b *backend) Updates() <-chan imapbackend.Update {
+	return b.updates
+}
+
+// notify 发送更新并等待写出，确保在命令的完成响应之前到达客户端。
+// Done() 第一次调用时才创建通道，需在发送前取得，避免与服务端的 listenUpdates 并发创建
+func (b *backend) notify(updates ...imapbackend.Update) {
+	for _, update := range updates {
+		done := update.Done()
+		select {
+		case b.updates <- update:
+		case <-b.ctx.Done():
+			return
+		}
+		select {
+		case <-done:
+		case <-time.After(updateTimeout):
+		case <-b.ctx.Done():
+			return
+		}
+	}
+}
+
+// user 一个登录会话
+type user struct {
+	backend  *backend
+	userID   uint
+	username string
+	// session 会话标识，go-imap按Username()投递更新，每个会话的邮件快照不同，
+	// 使用会话标识避免更新发到同一用户的其他连接
+	session string
+}
+
+// Username 返回会话标识，仅用于投递更新
+func (u *user) Username() string {
+	return u.session
+}
+
+// ListMailboxes 列出全部文件夹，补齐不可选择的上级文件夹；不区分订阅状态
+func (u *user) ListMailboxes(subscribed bool) ([]imapbackend.Mailbox, error) {
+	folders, err := u.backend.store.ListFolders(u.backend.ctx, u.userID)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*mailbox, len(folders))
+	for _, folder := range folders {
+		byName[folder.Name] = &mailbox{user: u, name: folder.Name, attributes: folder.Attributes}
+	}
+	for _, folder := range folders {
+		for _, parent := range parentNames(folder.Name) {
+			if _, ok := byName[parent]; !ok {
+				byName[parent] = &mailbox{user: u, name: parent, attributes: []string{imap.NoSelectAttr}}
+			}
+			byName[parent].hasChildren = true
+		}
+	}
+
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	mailboxes := make([]imapbackend.Mailbox, 0, len(names))
+	for _, name := range names {
+		mailboxes = append(mailboxes, byName[name])
+	}
+	return mailboxes, nil
+}
+
+// GetMailbox 打开文件夹并加载邮件快照，快照在选中期间保持序号稳定
+func (u *user) GetMailbox(name string) (imapbackend.Mailbox, error) {
+	if strings.EqualFold(name, "INBOX") {
+		name = "INBOX"
+	}
+
+	folders, err := u.backend.store.ListFolders(u.backend.ctx, u.userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, folder := range folders {
+		if folder.Name != name {
+			continue
+		}
+		mbox := &mailbox{user: u, name: folder.Name, attributes: folder.Attributes}
+		if err := mbox.load(); err != nil {
+			if errors.Is(err, ErrNoSuchFolder) {
+				return nil, imapbackend.ErrNoSuchMailbox
+			}
+			return nil, err
+		}
+		return mbox, nil
+	}
+	return nil, imapbackend.ErrNoSuchMailbox
+}
+
+// CreateMailbox 文件夹与邮箱账户对应，不支持通过IMAP新建
+func (u *user) CreateMailbox(name string) error {
+	return ErrNotSupported
+}
+
+// DeleteMailbox 不支持通过IMAP删除文件夹
+func (u *user) DeleteMailbox(name string) error {
+	return ErrNotSupported
+}
+
+// RenameMailbox 不支持通过IMAP重命名文件夹
+func (u *user) RenameMailbox(existingName, newName string) error {
+	return ErrNotSupported
+}
+
+// Logout 会话结束，快照随连接释放
+func (u *user) Logout() error {
+	return nil
+}
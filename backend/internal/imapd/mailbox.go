@@ -0,0 +1,519 @@
+package imapd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/emersion/go-imap"
+	imapbackend "github.com/emersion/go-imap/backend"
+	"github.com/emersion/go-imap/backend/backendutil"
+	"github.com/emersion/go-message"
+	"github.com/emersion/go-message/textproto"
+)
+
+// uidValidity UID即邮件在本地存储中的ID，不会重复使用，所有文件夹的UIDVALIDITY固定不变
+const uidValidity = 1
+
+// mailbox 一个文件夹。GetMailbox返回的文件夹持有邮件快照，ListMailboxes返回的只用于列出
+type mailbox struct {
+	user        *user
+	name        string
+	attributes  []string
+	hasChildren bool
+
+	mutex    sync.Mutex
+	messages []*Message
+}
+
+// Name 文件夹名称
+func (m *mailbox) Name() string {
+	return m.name
+}
+
+// Info 文件夹属性
+func (m *mailbox) Info() (*imap.MailboxInfo, error) {
+	attributes := append([]string(nil), m.attributes...)
+	if m.hasChildren {
+		attributes = append(attributes, imap.HasChildrenAttr)
+	} else {
+		attributes = append(attributes, imap.HasNoChildrenAttr)
+	}
+	return &imap.MailboxInfo{Attributes: attributes, Delimiter: Delimiter, Name: m.name}, nil
+}
+
+// load 从存储加载邮件快照
+func (m *mailbox) load() error {
+	messages, err := m.user.backend.store.ListMessages(m.user.backend.ctx, m.user.userID, m.name)
+	if err != nil {
+		return err
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.messages = make([]*Message, len(messages))
+	for i := range messages {
+		m.messages[i] = &messages[i]
+	}
+	return nil
+}
+
+// Status 文件夹状态，UIDNEXT取快照中最大UID加一
+func (m *mailbox) Status(items []imap.StatusItem) (*imap.MailboxStatus, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	status := imap.NewMailboxStatus(m.name, items)
+	status.Flags = []string{imap.SeenFlag, imap.FlaggedFlag, imap.DeletedFlag, imap.DraftFlag}
+	status.PermanentFlags = PermanentFlags
+
+	var unseen uint32
+	for i, msg := range m.messages {
+		if !hasFlag(msg.Flags, imap.SeenFlag) {
+			unseen++
+			if status.UnseenSeqNum == 0 {
+				status.UnseenSeqNum = uint32(i + 1)
+			}
+		}
+	}
+
+	for _, item := range items {
+		switch item {
+		case imap.StatusMessages:
+			status.Messages = uint32(len(m.messages))
+		case imap.StatusUidNext:
+			status.UidNext = m.uidNext()
+		case imap.StatusUidValidity:
+			status.UidValidity = uidValidity
+		case imap.StatusRecent:
+			status.Recent = 0
+		case imap.StatusUnseen:
+			status.Unseen = unseen
+		}
+	}
+	return status, nil
+}
+
+// uidNext 调用方需持有锁
+func (m *mailbox) uidNext() uint32 {
+	if len(m.messages) == 0 {
+		return 1
+	}
+	return m.messages[len(m.messages)-1].UID + 1
+}
+
+// SetSubscribed 所有文件夹都视为已订阅
+func (m *mailbox) SetSubscribed(subscribed bool) error {
+	return nil
+}
+
+// Check 没有需要整理的状态
+func (m *mailbox) Check() error {
+	return nil
+}
+
+// selected 返回快照中匹配序号集合的邮件及其序号
+func (m *mailbox) selected(uid bool, seqset *imap.SeqSet) ([]uint32, []*Message) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var seqNums []uint32
+	var messages []*Message
+	for i, msg := range m.messages {
+		id := uint32(i + 1)
+		if uid {
+			id = msg.UID
+		}
+		if seqset.Contains(id) {
+			seqNums = append(seqNums, uint32(i+1))
+			messages = append(messages, msg)
+		}
+	}
+	return seqNums, messages
+}
+
+// ListMessages 返回邮件数据，只在需要邮件内容时从存储读取
+func (m *mailbox) ListMessages(uid bool, seqset *imap.SeqSet, items []imap.FetchItem, ch chan<- *imap.Message) error {
+	defer close(ch)
+
+	seqNums, messages := m.selected(uid, seqset)
+	for i, msg := range messages {
+		fetched, err := m.fetch(seqNums[i], msg, items)
+		if err != nil {
+			return err
+		}
+		ch <- fetched
+	}
+	return nil
+}
+
+func (m *mailbox) fetch(seqNum uint32, msg *Message, items []imap.FetchItem) (*imap.Message, error) {
+	m.mutex.Lock()
+	flags := append([]string(nil), msg.Flags...)
+	m.mutex.Unlock()
+
+	fetched := imap.NewMessage(seqNum, items)
+	var raw []byte
+	content := func() ([]byte, error) {
+		if raw == nil {
+			data, err := m.user.backend.store.FetchMessage(m.user.backend.ctx, m.user.userID, msg.UID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch message %d: %w", msg.UID, err)
+			}
+			raw = data
+		}
+		return raw, nil
+	}
+
+	for _, item := range items {
+		switch item {
+		case imap.FetchFlags:
+			fetched.Flags = flags
+		case imap.FetchInternalDate:
+			fetched.InternalDate = msg.Date
+		case imap.FetchUid:
+			fetched.Uid = msg.UID
+		case imap.FetchRFC822Size:
+			data, err := content()
+			if err != nil {
+				return nil, err
+			}
+			fetched.Size = uint32(len(data))
+		case imap.FetchEnvelope:
+			data, err := content()
+			if err != nil {
+				return nil, err
+			}
+			header, _, err := readHeader(data)
+			if err != nil {
+				return nil, err
+			}
+			if fetched.Envelope, err = backendutil.FetchEnvelope(header); err != nil {
+				return nil, err
+			}
+		case imap.FetchBody, imap.FetchBodyStructure:
+			data, err := content()
+			if err != nil {
+				return nil, err
+			}
+			header, body, err := readHeader(data)
+			if err != nil {
+				return nil, err
+			}
+			if fetched.BodyStructure, err = backendutil.FetchBodyStructure(header, body, item == imap.FetchBodyStructure); err != nil {
+				return nil, err
+			}
+		default:
+			section, err := imap.ParseBodySectionName(item)
+			if err != nil {
+				continue
+			}
+			data, err := content()
+			if err != nil {
+				return nil, err
+			}
+			header, body, err := readHeader(data)
+			if err != nil {
+				return nil, err
+			}
+			literal, err := backendutil.FetchBodySection(header, body, section)
+			if err != nil {
+				// 请求的分段不存在时返回空内容
+				literal = bytes.NewReader(nil)
+			}
+			fetched.Body[section] = literal
+		}
+	}
+	return fetched, nil
+}
+
+// SearchMessages 搜索邮件，只有按内容或邮件头搜索时才读取邮件内容
+func (m *mailbox) SearchMessages(uid bool, criteria *imap.SearchCriteria) ([]uint32, error) {
+	m.mutex.Lock()
+	messages := make([]Message, len(m.messages))
+	for i, msg := range m.messages {
+		messages[i] = *msg
+		messages[i].Flags = append([]string(nil), msg.Flags...)
+	}
+	m.mutex.Unlock()
+
+	withContent := needsContent(criteria)
+	var ids []uint32
+	for i, msg := range messages {
+		seqNum := uint32(i + 1)
+		entity := &message.Entity{}
+		if withContent {
+			data, err := m.user.backend.store.FetchMessage(m.user.backend.ctx, m.user.userID, msg.UID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch message %d: %w", msg.UID, err)
+			}
+			if entity, err = message.Read(bytes.NewReader(data)); err != nil && !message.IsUnknownCharset(err) {
+				continue
+			}
+		}
+		ok, err := backendutil.Match(entity, seqNum, msg.UID, msg.Date, msg.Flags, criteria)
+		if err != nil || !ok {
+			continue
+		}
+		if uid {
+			ids = append(ids, msg.UID)
+		} else {
+			ids = append(ids, seqNum)
+		}
+	}
+	return ids, nil
+}
+
+// CreateMessage 不支持APPEND，邮件只能通过同步或入站写入
+func (m *mailbox) CreateMessage(flags []string, date time.Time, body imap.Literal) error {
+	return ErrNotSupported
+}
+
+// UpdateMessagesFlags 修改标记：\Seen、\Flagged 写入存储，\Deleted 只在会话内保存直到EXPUNGE，\Draft 不可修改
+func (m *mailbox) UpdateMessagesFlags(uid bool, seqset *imap.SeqSet, operation imap.FlagsOp, flags []string) error {
+	seqNums, messages := m.selected(uid, seqset)
+
+	type change struct {
+		flag  string
+		value bool
+	}
+	changes := make(map[change][]uint32)
+	updated := make([][]string, len(messages))
+
+	m.mutex.Lock()
+	for i, msg := range messages {
+		next := backendutil.UpdateFlags(append([]string(nil), msg.Flags...), operation, flags)
+		next = filterFlags(next, hasFlag(msg.Flags, imap.DraftFlag))
+		for _, flag := range []string{imap.SeenFlag, imap.FlaggedFlag} {
+			if before, after := hasFlag(msg.Flags, flag), hasFlag(next, flag); before != after {
+				key := change{flag: flag, value: after}
+				changes[key] = append(changes[key], msg.UID)
+			}
+		}
+		updated[i] = next
+	}
+	m.mutex.Unlock()
+
+	for key, uids := range changes {
+		if err := m.user.backend.store.SetFlag(m.user.backend.ctx, m.user.userID, uids, key.flag, key.value); err != nil {
+			return err
+		}
+	}
+
+	m.mutex.Lock()
+	updates := make([]imapbackend.Update, 0, len(messages))
+	for i, msg := range messages {
+		msg.Flags = updated[i]
+		fetched := imap.NewMessage(seqNums[i], []imap.FetchItem{imap.FetchFlags, imap.FetchUid})
+		fetched.Flags = append([]string(nil), updated[i]...)
+		fetched.Uid = msg.UID
+		updates = append(updates, &imapbackend.MessageUpdate{Update: m.newUpdate(), Message: fetched})
+	}
+	m.mutex.Unlock()
+
+	m.user.backend.notify(updates...)
+	return nil
+}
+
+// CopyMessages 本地存储中一封邮件只属于一个文件夹，不支持复制
+func (m *mailbox) CopyMessages(uid bool, seqset *imap.SeqSet, dest string) error {
+	return ErrNotSupported
+}
+
+// MoveMessages 将邮件移动到目标文件夹，并从快照中移除
+func (m *mailbox) MoveMessages(uid bool, seqset *imap.SeqSet, dest string) error {
+	if dest == m.name {
+		return nil
+	}
+	_, messages := m.selected(uid, seqset)
+	if len(messages) == 0 {
+		return nil
+	}
+
+	uids := make([]uint32, len(messages))
+	for i, msg := range messages {
+		uids[i] = msg.UID
+	}
+	if err := m.user.backend.store.MoveMessages(m.user.backend.ctx, m.user.userID, uids, dest); err != nil {
+		if errors.Is(err, ErrNoSuchFolder) {
+			return imapbackend.ErrNoSuchMailbox
+		}
+		return err
+	}
+	m.remove(uids)
+	return nil
+}
+
+// Expunge 删除带 \Deleted 标记的邮件
+func (m *mailbox) Expunge() error {
+	m.mutex.Lock()
+	var uids []uint32
+	for _, msg := range m.messages {
+		if hasFlag(msg.Flags, imap.DeletedFlag) {
+			uids = append(uids, msg.UID)
+		}
+	}
+	m.mutex.Unlock()
+	if len(uids) == 0 {
+		return nil
+	}
+
+	if err := m.user.backend.store.DeleteMessages(m.user.backend.ctx, m.user.userID, uids); err != nil {
+		return err
+	}
+	m.remove(uids)
+	return nil
+}
+
+// Poll 重新读取文件夹：通知已删除的邮件和标记变化，UID大于快照的新邮件追加到末尾。
+// UID小于快照最大UID的邮件（从其他文件夹移入）要等客户端重新选中文件夹后才出现
+func (m *mailbox) Poll() error {
+	fresh, err := m.user.backend.store.ListMessages(m.user.backend.ctx, m.user.userID, m.name)
+	if err != nil {
+		return err
+	}
+	byUID := make(map[uint32]*Message, len(fresh))
+	for i := range fresh {
+		byUID[fresh[i].UID] = &fresh[i]
+	}
+
+	m.mutex.Lock()
+	var updates []imapbackend.Update
+	// 从后往前删除，之前的序号不受影响
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if _, ok := byUID[m.messages[i].UID]; !ok {
+			m.messages = append(m.messages[:i], m.messages[i+1:]...)
+			updates = append(updates, &imapbackend.ExpungeUpdate{Update: m.newUpdate(), SeqNum: uint32(i + 1)})
+		}
+	}
+	for i, msg := range m.messages {
+		current := byUID[msg.UID]
+		flags := current.Flags
+		if hasFlag(msg.Flags, imap.DeletedFlag) {
+			flags = append(append([]string(nil), flags...), imap.DeletedFlag)
+		}
+		if sameFlags(msg.Flags, flags) {
+			continue
+		}
+		msg.Flags = flags
+		fetched := imap.NewMessage(uint32(i+1), []imap.FetchItem{imap.FetchFlags, imap.FetchUid})
+		fetched.Flags = append([]string(nil), flags...)
+		fetched.Uid = msg.UID
+		updates = append(updates, &imapbackend.MessageUpdate{Update: m.newUpdate(), Message: fetched})
+	}
+	uidNext := m.uidNext()
+	added := false
+	for i := range fresh {
+		if fresh[i].UID >= uidNext {
+			m.messages = append(m.messages, &fresh[i])
+			added = true
+		}
+	}
+	if added {
+		status := imap.NewMailboxStatus(m.name, []imap.StatusItem{imap.StatusMessages})
+		status.Messages = uint32(len(m.messages))
+		updates = append(updates, &imapbackend.MailboxUpdate{Update: m.newUpdate(), MailboxStatus: status})
+	}
+	m.mutex.Unlock()
+
+	m.user.backend.notify(updates...)
+	return nil
+}
+
+// remove 从快照中移除邮件并通知客户端
+func (m *mailbox) remove(uids []uint32) {
+	removed := make(map[uint32]bool, len(uids))
+	for _, uid := range uids {
+		removed[uid] = true
+	}
+
+	m.mutex.Lock()
+	var updates []imapbackend.Update
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if removed[m.messages[i].UID] {
+			m.messages = append(m.messages[:i], m.messages[i+1:]...)
+			updates = append(updates, &imapbackend.ExpungeUpdate{Update: m.newUpdate(), SeqNum: uint32(i + 1)})
+		}
+	}
+	m.mutex.Unlock()
+
+	m.user.backend.notify(updates...)
+}
+
+// newUpdate 只发给当前会话选中的本文件夹
+func (m *mailbox) newUpdate() imapbackend.Update {
+	return imapbackend.NewUpdate(m.user.session, m.name)
+}
+
+// readHeader 解析邮件头，返回邮件头和正文
+func readHeader(data []byte) (textproto.Header, *bufio.Reader, error) {
+	body := bufio.NewReader(bytes.NewReader(data))
+	header, err := textproto.ReadHeader(body)
+	if err != nil {
+		return header, nil, fmt.Errorf("failed to parse message header: %w", err)
+	}
+	return header, body, nil
+}
+
+// needsContent 搜索条件是否涉及邮件头、正文或大小
+func needsContent(criteria *imap.SearchCriteria) bool {
+	if criteria == nil {
+		return false
+	}
+	if len(criteria.Header) > 0 || len(criteria.Body) > 0 || len(criteria.Text) > 0 ||
+		criteria.Larger > 0 || criteria.Smaller > 0 ||
+		!criteria.SentSince.IsZero() || !criteria.SentBefore.IsZero() {
+		return true
+	}
+	for _, not := range criteria.Not {
+		if needsContent(not) {
+			return true
+		}
+	}
+	for _, or := range criteria.Or {
+		if needsContent(or[0]) || needsContent(or[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterFlags 只保留外观支持的标记，\Draft 保持原状
+func filterFlags(flags []string, draft bool) []string {
+	filtered := make([]string, 0, len(flags))
+	for _, flag := range flags {
+		switch flag {
+		case imap.SeenFlag, imap.FlaggedFlag, imap.DeletedFlag:
+			if !hasFlag(filtered, flag) {
+				filtered = append(filtered, flag)
+			}
+		}
+	}
+	if draft {
+		filtered = append(filtered, imap.DraftFlag)
+	}
+	return filtered
+}
+
+func hasFlag(flags []string, flag string) bool {
+	for _, f := range flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+func sameFlags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, flag := range a {
+		if !hasFlag(b, flag) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,159 @@
+// Package imapd 提供本地IMAP服务器外观：将本地同步的邮件以IMAP协议暴露给桌面客户端，
+// 用户可以用Thunderbird、Apple Mail等客户端通过FireMail统一访问所有邮箱账户。
+// 收件箱、已发送等常用文件夹按类型合并为统一文件夹，各账户的文件夹位于 Accounts/<邮箱地址>/ 下
+package imapd
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/emersion/go-imap"
+	imapserver "github.com/emersion/go-imap/server"
+)
+
+// Delimiter 文件夹层级分隔符
+const Delimiter = "/"
+
+var (
+	// ErrInvalidCredentials 用户名或密码错误
+	ErrInvalidCredentials = errors.New("imapd: invalid credentials")
+	// ErrNoSuchFolder 文件夹不存在
+	ErrNoSuchFolder = errors.New("imapd: no such folder")
+	// ErrNotSupported 外观不支持的操作，如新建文件夹、追加邮件
+	ErrNotSupported = errors.New("imapd: operation not supported")
+)
+
+// Folder 暴露给客户端的文件夹
+type Folder struct {
+	Name       string   // 完整名称，以Delimiter分隔层级
+	Attributes []string // SPECIAL-USE属性，如 \Sent、\Trash
+}
+
+// Message 文件夹中一封邮件的元数据
+type Message struct {
+	UID   uint32    // 在所有文件夹中唯一且不变，邮件移动后保持不变
+	Flags []string  // 持久保存的标记，如 \Seen、\Flagged
+	Date  time.Time // 内部日期
+}
+
+// Store 外观读写的本地邮件存储
+type Store interface {
+	// Authenticate 校验用户名和密码，返回用户ID，失败时返回ErrInvalidCredentials
+	Authenticate(ctx context.Context, username, password string) (uint, error)
+
+	// ListFolders 列出用户可访问的文件夹，上级文件夹不存在时由服务器补齐为不可选择的文件夹
+	ListFolders(ctx context.Context, userID uint) ([]Folder, error)
+
+	// ListMessages 列出文件夹中的邮件，按UID升序，文件夹不存在时返回ErrNoSuchFolder
+	ListMessages(ctx context.Context, userID uint, folder string) ([]Message, error)
+
+	// FetchMessage 返回邮件的RFC 822内容，同一封邮件每次返回的内容必须相同
+	FetchMessage(ctx context.Context, userID uint, uid uint32) ([]byte, error)
+
+	// SetFlag 设置或清除邮件的标记，只会收到PermanentFlags中的标记
+	SetFlag(ctx context.Context, userID uint, uids []uint32, flag string, value bool) error
+
+	// MoveMessages 将邮件移动到目标文件夹
+	MoveMessages(ctx context.Context, userID uint, uids []uint32, dest string) error
+
+	// DeleteMessages 删除邮件（EXPUNGE带 \Deleted 标记的邮件时调用）
+	DeleteMessages(ctx context.Context, userID uint, uids []uint32) error
+}
+
+// PermanentFlags 客户端可以修改并持久保存的标记，\Deleted 只在会话内保存直到EXPUNGE
+var PermanentFlags = []string{imap.SeenFlag, imap.FlaggedFlag}
+
+// Config 服务器配置
+type Config struct {
+	TLSConfig         *tls.Config // 为空时不提供STARTTLS
+	AllowInsecureAuth bool        // 允许在未加密的连接上登录，仅应在监听本机地址时开启
+	AutoLogout        time.Duration
+	OnAuthFailure     func(ip, username string) // 登录失败时调用，用于写入认证失败日志
+}
+
+// Server IMAP服务器
+type Server struct {
+	imap    *imapserver.Server
+	backend *backend
+
+	mutex  sync.Mutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewServer 创建IMAP服务器
+func NewServer(config Config, store Store) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	bkd := newBackend(ctx, cancel, store)
+	bkd.onAuthFailure = config.OnAuthFailure
+
+	server := imapserver.New(bkd)
+	server.TLSConfig = config.TLSConfig
+	server.AllowInsecureAuth = config.AllowInsecureAuth
+	server.AutoLogout = config.AutoLogout
+	server.ErrorLog = log.New(log.Writer(), "IMAP server: ", log.LstdFlags)
+	return &Server{imap: server, backend: bkd}
+}
+
+// Listen 在地址上监听并在后台接受连接，监听失败时立即返回错误
+func (s *Server) Listen(addr string) (net.Listener, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		listener.Close()
+		return nil, errors.New("imapd: server closed")
+	}
+	s.wg.Add(1)
+	s.mutex.Unlock()
+
+	go func() {
+		defer s.wg.Done()
+		if err := s.imap.Serve(listener); err != nil && !s.isClosed() && !errors.Is(err, net.ErrClosed) {
+			log.Printf("IMAP server on %s stopped: %v", addr, err)
+		}
+	}()
+	return listener, nil
+}
+
+// Close 关闭所有监听器和连接，取消进行中的存储操作
+func (s *Server) Close() error {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mutex.Unlock()
+
+	s.backend.cancel()
+	err := s.imap.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) isClosed() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.closed
+}
+
+// parentNames 返回文件夹名称的各级上级名称，如 a/b/c 返回 a 和 a/b
+func parentNames(name string) []string {
+	parts := strings.Split(name, Delimiter)
+	parents := make([]string, 0, len(parts)-1)
+	for i := 1; i < len(parts); i++ {
+		parents = append(parents, strings.Join(parts[:i], Delimiter))
+	}
+	return parents
+}
@@ -0,0 +1,291 @@
+package imapd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/client"
+	"github.com/stretchr/testify/require"
+)
+
+type testStore struct {
+	mutex    sync.Mutex
+	folders  []Folder
+	messages map[string][]Message
+	deleted  []uint32
+}
+
+func newTestStore() *testStore {
+	date := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	return &testStore{
+		folders: []Folder{
+			{Name: "INBOX"},
+			{Name: "Trash", Attributes: []string{imap.TrashAttr}},
+			{Name: "Accounts/alice@example.com/Projects"},
+		},
+		messages: map[string][]Message{
+			"INBOX": {
+				{UID: 3, Flags: []string{imap.SeenFlag}, Date: date},
+				{UID: 7, Flags: []string{}, Date: date.Add(time.Hour)},
+				{UID: 9, Flags: []string{imap.FlaggedFlag}, Date: date.Add(2 * time.Hour)},
+			},
+			"Trash":                              {},
+			"Accounts/alice@example.com/Projects": {},
+		},
+	}
+}
+
+func (s *testStore) Authenticate(ctx context.Context, username, password string) (uint, error) {
+	if username == "alice" && password == "secret" {
+		return 1, nil
+	}
+	return 0, ErrInvalidCredentials
+}
+
+func (s *testStore) ListFolders(ctx context.Context, userID uint) ([]Folder, error) {
+	return s.folders, nil
+}
+
+func (s *testStore) ListMessages(ctx context.Context, userID uint, folder string) ([]Message, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	messages, ok := s.messages[folder]
+	if !ok {
+		return nil, ErrNoSuchFolder
+	}
+	result := make([]Message, len(messages))
+	for i, msg := range messages {
+		result[i] = msg
+		result[i].Flags = append([]string(nil), msg.Flags...)
+	}
+	return result, nil
+}
+
+func (s *testStore) FetchMessage(ctx context.Context, userID uint, uid uint32) ([]byte, error) {
+	return []byte(fmt.Sprintf("From: Bob <bob@example.com>\r\nTo: alice@example.com\r\nSubject: Message %d\r\n"+
+		"Date: Thu, 01 Oct 2026 09:00:00 +0000\r\nContent-Type: text/plain; charset=utf-8\r\n\r\nBody of message %d\r\n", uid, uid)), nil
+}
+
+func (s *testStore) SetFlag(ctx context.Context, userID uint, uids []uint32, flag string, value bool) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for folder, messages := range s.messages {
+		for i, msg := range messages {
+			for _, uid := range uids {
+				if msg.UID != uid {
+					continue
+				}
+				var flags []string
+				for _, f := range msg.Flags {
+					if f != flag {
+						flags = append(flags, f)
+					}
+				}
+				if value {
+					flags = append(flags, flag)
+				}
+				s.messages[folder][i].Flags = flags
+			}
+		}
+	}
+	return nil
+}
+
+func (s *testStore) MoveMessages(ctx context.Context, userID uint, uids []uint32, dest string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.messages[dest]; !ok {
+		return ErrNoSuchFolder
+	}
+	for _, uid := range uids {
+		for folder, messages := range s.messages {
+			for i, msg := range messages {
+				if msg.UID == uid {
+					s.messages[folder] = append(messages[:i:i], messages[i+1:]...)
+					s.messages[dest] = append(s.messages[dest], msg)
+					break
+				}
+			}
+		}
+	}
+	for folder := range s.messages {
+		sort.Slice(s.messages[folder], func(i, j int) bool { return s.messages[folder][i].UID < s.messages[folder][j].UID })
+	}
+	return nil
+}
+
+func (s *testStore) DeleteMessages(ctx context.Context, userID uint, uids []uint32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.deleted = append(s.deleted, uids...)
+	for _, uid := range uids {
+		for folder, messages := range s.messages {
+			for i, msg := range messages {
+				if msg.UID == uid {
+					s.messages[folder] = append(messages[:i:i], messages[i+1:]...)
+					break
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *testStore) add(folder string, msg Message) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.messages[folder] = append(s.messages[folder], msg)
+}
+
+func startTestServer(t *testing.T) (*client.Client, *testStore) {
+	t.Helper()
+
+	store := newTestStore()
+	server := NewServer(Config{AllowInsecureAuth: true}, store)
+	listener, err := server.Listen("127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { server.Close() })
+
+	c, err := client.Dial(listener.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Logout() })
+	return c, store
+}
+
+func listNames(t *testing.T, c *client.Client) map[string][]string {
+	t.Helper()
+	ch := make(chan *imap.MailboxInfo, 20)
+	require.NoError(t, c.List("", "*", ch))
+	names := make(map[string][]string)
+	for info := range ch {
+		names[info.Name] = info.Attributes
+	}
+	return names
+}
+
+func TestServerLoginAndList(t *testing.T) {
+	c, _ := startTestServer(t)
+
+	require.Error(t, c.Login("alice", "wrong"))
+	require.NoError(t, c.Login("alice", "secret"))
+
+	names := listNames(t, c)
+	require.Len(t, names, 5)
+	require.Contains(t, names["Trash"], imap.TrashAttr)
+	require.Contains(t, names["Accounts"], imap.NoSelectAttr)
+	require.Contains(t, names["Accounts/alice@example.com"], imap.HasChildrenAttr)
+	require.Contains(t, names["Accounts/alice@example.com/Projects"], imap.HasNoChildrenAttr)
+
+	_, err := c.Select("Accounts", false)
+	require.Error(t, err)
+	require.Error(t, c.Create("New"))
+}
+
+func TestServerFetchSearchAndFlags(t *testing.T) {
+	c, store := startTestServer(t)
+	require.NoError(t, c.Login("alice", "secret"))
+
+	status, err := c.Select("inbox", false)
+	require.NoError(t, err)
+	require.Equal(t, uint32(3), status.Messages)
+	require.Equal(t, uint32(10), status.UidNext)
+	require.Equal(t, uint32(2), status.UnseenSeqNum)
+
+	seqset, _ := imap.ParseSeqSet("1:*")
+	section := &imap.BodySectionName{Peek: true}
+	ch := make(chan *imap.Message, 10)
+	require.NoError(t, c.Fetch(seqset, []imap.FetchItem{imap.FetchUid, imap.FetchEnvelope, imap.FetchRFC822Size, section.FetchItem()}, ch))
+	var fetched []*imap.Message
+	for msg := range ch {
+		fetched = append(fetched, msg)
+	}
+	require.Len(t, fetched, 3)
+	require.Equal(t, uint32(7), fetched[1].Uid)
+	require.Equal(t, "Message 7", fetched[1].Envelope.Subject)
+	body, err := io.ReadAll(fetched[1].GetBody(section))
+	require.NoError(t, err)
+	require.Contains(t, string(body), "Body of message 7")
+	require.Equal(t, uint32(len(body)), fetched[1].Size)
+
+	criteria := imap.NewSearchCriteria()
+	criteria.WithoutFlags = []string{imap.SeenFlag}
+	uids, err := c.UidSearch(criteria)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []uint32{7, 9}, uids)
+
+	criteria = imap.NewSearchCriteria()
+	criteria.Body = []string{"message 9"}
+	uids, err = c.UidSearch(criteria)
+	require.NoError(t, err)
+	require.Equal(t, []uint32{9}, uids)
+
+	// \Seen 写入存储，不支持的标记被忽略
+	uidset, _ := imap.ParseSeqSet("7")
+	ch = make(chan *imap.Message, 10)
+	require.NoError(t, c.UidStore(uidset, imap.FormatFlagsOp(imap.AddFlags, false), []interface{}{imap.SeenFlag, "$Label1"}, ch))
+	var updated []*imap.Message
+	for msg := range ch {
+		updated = append(updated, msg)
+	}
+	require.Len(t, updated, 1)
+	require.Equal(t, []string{imap.SeenFlag}, updated[0].Flags)
+
+	messages, err := store.ListMessages(context.Background(), 1, "INBOX")
+	require.NoError(t, err)
+	require.Equal(t, []string{imap.SeenFlag}, messages[1].Flags)
+}
+
+func expunged(updates <-chan client.Update) []uint32 {
+	var seqNums []uint32
+	for {
+		select {
+		case update := <-updates:
+			if expunge, ok := update.(*client.ExpungeUpdate); ok {
+				seqNums = append(seqNums, expunge.SeqNum)
+			}
+		default:
+			return seqNums
+		}
+	}
+}
+
+func TestServerMoveExpungeAndPoll(t *testing.T) {
+	c, store := startTestServer(t)
+	require.NoError(t, c.Login("alice", "secret"))
+
+	updates := make(chan client.Update, 20)
+	c.Updates = updates
+
+	_, err := c.Select("INBOX", false)
+	require.NoError(t, err)
+
+	uidset, _ := imap.ParseSeqSet("3")
+	require.NoError(t, c.UidMove(uidset, "Trash"))
+	require.Equal(t, []uint32{1}, expunged(updates))
+	uidset, _ = imap.ParseSeqSet("9")
+	require.Error(t, c.UidMove(uidset, "Missing"))
+
+	// \Deleted 只在会话内保存，EXPUNGE时删除
+	seqset, _ := imap.ParseSeqSet("1")
+	require.NoError(t, c.Store(seqset, imap.FormatFlagsOp(imap.AddFlags, true), []interface{}{imap.DeletedFlag}, nil))
+	require.NoError(t, c.Expunge(nil))
+	require.Equal(t, []uint32{7}, store.deleted)
+	require.Equal(t, []uint32{1}, expunged(updates))
+
+	trash, err := store.ListMessages(context.Background(), 1, "Trash")
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+
+	// 新邮件在NOOP时通知客户端
+	store.add("INBOX", Message{UID: 12, Flags: []string{}, Date: time.Now()})
+	require.NoError(t, c.Noop())
+	require.Equal(t, uint32(2), c.Mailbox().Messages)
+
+	require.Error(t, c.Copy(seqset, "Trash"))
+}
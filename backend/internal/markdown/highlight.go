@@ -0,0 +1,290 @@
+package markdown
+
+import (
+	"html"
+	"sort"
+	"strings"
+)
+
+// Theme 代码块配色
+type Theme struct {
+	Background       string
+	Foreground       string
+	InlineBackground string
+	Comment          string
+	Keyword          string
+	String           string
+	Number           string
+}
+
+// DefaultTheme 未指定配色时使用的主题
+const DefaultTheme = "light"
+
+var themes = map[string]*Theme{
+	"light": {
+		Background:       "#f6f8fa",
+		Foreground:       "#24292f",
+		InlineBackground: "#eff1f3",
+		Comment:          "#6e7781",
+		Keyword:          "#cf222e",
+		String:           "#0a3069",
+		Number:           "#0550ae",
+	},
+	"dark": {
+		Background:       "#272822",
+		Foreground:       "#f8f8f2",
+		InlineBackground: "#eff1f3",
+		Comment:          "#75715e",
+		Keyword:          "#f92672",
+		String:           "#e6db74",
+		Number:           "#ae81ff",
+	},
+	"solarized": {
+		Background:       "#fdf6e3",
+		Foreground:       "#657b83",
+		InlineBackground: "#eee8d5",
+		Comment:          "#93a1a1",
+		Keyword:          "#859900",
+		String:           "#2aa198",
+		Number:           "#d33682",
+	},
+}
+
+// Themes 返回支持的配色名称
+func Themes() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsTheme 配色名称是否受支持
+func IsTheme(name string) bool {
+	_, ok := themes[strings.ToLower(name)]
+	return ok
+}
+
+func lookupTheme(name string) *Theme {
+	if theme, ok := themes[strings.ToLower(name)]; ok {
+		return theme
+	}
+	return themes[DefaultTheme]
+}
+
+// language 着色规则：关键字、注释和字符串定界符
+type language struct {
+	keywords     map[string]bool
+	lineComments []string
+	blockComment [2]string
+	quotes       string
+}
+
+func words(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(s) {
+		set[word] = true
+	}
+	return set
+}
+
+var (
+	cLike = [2]string{"/*", "*/"}
+
+	languages = map[string]*language{
+		"go": {
+			keywords: words(`break case chan const continue default defer else fallthrough for func go goto if import
+				interface map package range return select struct switch type var nil true false iota`),
+			lineComments: []string{"//"}, blockComment: cLike, quotes: "\"'`",
+		},
+		"javascript": {
+			keywords: words(`async await break case catch class const continue debugger default delete do else export
+				extends finally for from function if import in instanceof let new of return static super switch this throw
+				try typeof var void while yield null undefined true false interface type enum implements readonly`),
+			lineComments: []string{"//"}, blockComment: cLike, quotes: "\"'`",
+		},
+		"python": {
+			keywords: words(`and as assert async await break class continue def del elif else except finally for from
+				global if import in is lambda nonlocal not or pass raise return try while with yield None True False self`),
+			lineComments: []string{"#"}, quotes: "\"'",
+		},
+		"java": {
+			keywords: words(`abstract boolean break byte case catch char class const continue default do double else enum
+				extends final finally float for if implements import instanceof int interface long new package private
+				protected public return short static super switch this throw throws try void volatile while null true false`),
+			lineComments: []string{"//"}, blockComment: cLike, quotes: "\"'",
+		},
+		"c": {
+			keywords: words(`auto bool break case char class const continue default delete do double else enum extern
+				float for goto if include define inline int long namespace new nullptr private protected public return short
+				signed sizeof static struct switch template this typedef union unsigned using virtual void volatile while NULL true false`),
+			lineComments: []string{"//"}, blockComment: cLike, quotes: "\"'",
+		},
+		"rust": {
+			keywords: words(`as async await break const continue crate else enum extern false fn for if impl in let loop
+				match mod move mut pub ref return self Self static struct super trait true type unsafe use where while`),
+			lineComments: []string{"//"}, blockComment: cLike, quotes: "\"",
+		},
+		"shell": {
+			keywords: words(`if then else elif fi case esac for while until do done in function return export local
+				echo exit set unset readonly shift source`),
+			lineComments: []string{"#"}, quotes: "\"'",
+		},
+		"sql": {
+			keywords: words(`select from where and or not insert into values update set delete create table alter drop
+				index join left right inner outer on group by order having limit offset as distinct union null is in
+				like between exists primary key default SELECT FROM WHERE AND OR NOT INSERT INTO VALUES UPDATE SET DELETE
+				CREATE TABLE ALTER DROP INDEX JOIN LEFT RIGHT INNER OUTER ON GROUP BY ORDER HAVING LIMIT OFFSET AS DISTINCT
+				UNION NULL IS IN LIKE BETWEEN EXISTS PRIMARY KEY DEFAULT`),
+			lineComments: []string{"--"}, blockComment: cLike, quotes: "'\"",
+		},
+		"json": {
+			keywords: words(`true false null`),
+			quotes:   "\"",
+		},
+		"yaml": {
+			keywords:     words(`true false null yes no on off`),
+			lineComments: []string{"#"}, quotes: "\"'",
+		},
+	}
+
+	languageAliases = map[string]string{
+		"golang":     "go",
+		"js":         "javascript",
+		"jsx":        "javascript",
+		"ts":         "javascript",
+		"tsx":        "javascript",
+		"typescript": "javascript",
+		"py":         "python",
+		"kt":         "java",
+		"kotlin":     "java",
+		"cs":         "java",
+		"csharp":     "java",
+		"cpp":        "c",
+		"c++":        "c",
+		"h":          "c",
+		"hpp":        "c",
+		"rs":         "rust",
+		"sh":         "shell",
+		"bash":       "shell",
+		"zsh":        "shell",
+		"console":    "shell",
+		"yml":        "yaml",
+		"psql":       "sql",
+		"mysql":      "sql",
+		"sqlite":     "sql",
+	}
+)
+
+func lookupLanguage(name string) *language {
+	if alias, ok := languageAliases[name]; ok {
+		name = alias
+	}
+	return languages[name]
+}
+
+// highlight 按语言规则把代码拆成注释、字符串、数字和关键字，用内联颜色输出
+func (r *renderer) highlight(code string, lang *language) string {
+	var b strings.Builder
+	span := func(color, text string) {
+		b.WriteString(`<span style="color:` + color + `">` + html.EscapeString(text) + "</span>")
+	}
+
+	for i := 0; i < len(code); {
+		rest := code[i:]
+
+		if lang.blockComment[0] != "" && strings.HasPrefix(rest, lang.blockComment[0]) {
+			end := strings.Index(rest[len(lang.blockComment[0]):], lang.blockComment[1])
+			n := len(rest)
+			if end >= 0 {
+				n = len(lang.blockComment[0]) + end + len(lang.blockComment[1])
+			}
+			span(r.theme.Comment, rest[:n])
+			i += n
+			continue
+		}
+
+		if comment := hasAnyPrefix(rest, lang.lineComments); comment != "" {
+			n := strings.IndexByte(rest, '\n')
+			if n < 0 {
+				n = len(rest)
+			}
+			span(r.theme.Comment, rest[:n])
+			i += n
+			continue
+		}
+
+		c := code[i]
+		if strings.IndexByte(lang.quotes, c) >= 0 {
+			n := stringLiteralLength(rest, c)
+			span(r.theme.String, rest[:n])
+			i += n
+			continue
+		}
+
+		if isDigit(c) && (i == 0 || !isIdentByte(code[i-1])) {
+			n := 1
+			for n < len(rest) && (isIdentByte(rest[n]) || rest[n] == '.') {
+				n++
+			}
+			span(r.theme.Number, rest[:n])
+			i += n
+			continue
+		}
+
+		if isIdentByte(c) {
+			n := 1
+			for n < len(rest) && isIdentByte(rest[n]) {
+				n++
+			}
+			if lang.keywords[rest[:n]] {
+				span(r.theme.Keyword, rest[:n])
+			} else {
+				b.WriteString(html.EscapeString(rest[:n]))
+			}
+			i += n
+			continue
+		}
+
+		b.WriteString(html.EscapeString(code[i : i+1]))
+		i++
+	}
+	return b.String()
+}
+
+// stringLiteralLength 字符串字面量的长度；除反引号外字符串不跨行
+func stringLiteralLength(s string, quote byte) int {
+	for n := 1; n < len(s); n++ {
+		switch s[n] {
+		case '\\':
+			if quote != '`' {
+				n++
+			}
+		case '\n':
+			if quote != '`' {
+				return n
+			}
+		case quote:
+			return n + 1
+		}
+	}
+	return len(s)
+}
+
+func hasAnyPrefix(s string, prefixes []string) string {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return prefix
+		}
+	}
+	return ""
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,450 @@
+// Package markdown 把撰写邮件时使用的Markdown渲染为适合邮件客户端的HTML。
+// 原始HTML一律转义，链接只允许http、https和mailto，样式全部内联，输出无需再经过HTML过滤
+package markdown
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Options 渲染选项
+type Options struct {
+	// CodeHighlight 为标注了语言的代码块着色
+	CodeHighlight bool
+	// CodeTheme 代码块配色，见 Themes，未知名称使用默认配色
+	CodeTheme string
+}
+
+// Render 渲染Markdown为HTML片段
+func Render(source string, opts Options) string {
+	r := &renderer{opts: opts, theme: lookupTheme(opts.CodeTheme)}
+	var b strings.Builder
+	r.renderBlocks(&b, parseBlocks(splitLines(source)), false)
+	return strings.TrimRight(b.String(), "\n")
+}
+
+type blockKind int
+
+const (
+	blockParagraph blockKind = iota
+	blockHeading
+	blockCode
+	blockQuote
+	blockList
+	blockRule
+	blockTable
+)
+
+// block 块级元素
+type block struct {
+	kind blockKind
+
+	// 段落和标题的行内内容
+	text  string
+	level int
+
+	// 代码块
+	lang string
+	code string
+
+	// 引用块
+	children []*block
+
+	// 列表
+	ordered bool
+	start   int
+	loose   bool
+	items   [][]*block
+
+	// 表格
+	header []string
+	aligns []string
+	rows   [][]string
+}
+
+var (
+	atxHeadingPattern  = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	rulePattern        = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	fencePattern       = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*([^`\\s]*)")
+	bulletPattern      = regexp.MustCompile(`^( {0,3})([-*+])( +|$)`)
+	orderedPattern     = regexp.MustCompile(`^( {0,3})(\d{1,9})([.)])( +|$)`)
+	setextH1Pattern    = regexp.MustCompile(`^ {0,3}=+[ \t]*$`)
+	setextH2Pattern    = regexp.MustCompile(`^ {0,3}-+[ \t]*$`)
+	tableDelimiterCell = regexp.MustCompile(`^:?-+:?$`)
+)
+
+// splitLines 统一换行符并展开制表符
+func splitLines(source string) []string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = strings.ReplaceAll(source, "\r", "\n")
+	lines := strings.Split(source, "\n")
+	for i, line := range lines {
+		lines[i] = expandTabs(line)
+	}
+	return lines
+}
+
+func expandTabs(line string) string {
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+	var b strings.Builder
+	column := 0
+	for _, c := range line {
+		if c == '\t' {
+			n := 4 - column%4
+			b.WriteString(strings.Repeat(" ", n))
+			column += n
+			continue
+		}
+		b.WriteRune(c)
+		column++
+	}
+	return b.String()
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// listMarker 列表项标记
+type listMarker struct {
+	ordered bool
+	delim   string
+	start   int
+	indent  int // 列表项内容的缩进列数
+	rest    string
+}
+
+func parseListMarker(line string) (*listMarker, bool) {
+	if m := bulletPattern.FindStringSubmatch(line); m != nil {
+		if rulePattern.MatchString(line) {
+			return nil, false
+		}
+		return newListMarker(line, false, m[2], 0, len(m[1])+len(m[2]), m[3]), true
+	}
+	if m := orderedPattern.FindStringSubmatch(line); m != nil {
+		start, _ := strconv.Atoi(m[2])
+		return newListMarker(line, true, m[3], start, len(m[1])+len(m[2])+len(m[3]), m[4]), true
+	}
+	return nil, false
+}
+
+func isListMarker(line string) bool {
+	_, ok := parseListMarker(line)
+	return ok
+}
+
+func newListMarker(line string, ordered bool, delim string, start, markerWidth int, spacing string) *listMarker {
+	// 标记后超过4个空格时视为缩进代码，内容从标记后一个空格开始
+	padding := len(spacing)
+	if padding == 0 || padding > 4 {
+		padding = 1
+	}
+	indent := markerWidth + padding
+	rest := ""
+	if indent <= len(line) {
+		rest = line[indent:]
+	}
+	return &listMarker{ordered: ordered, delim: delim, start: start, indent: indent, rest: rest}
+}
+
+// startsBlock 该行是否开始一个新的块，用于结束段落
+func startsBlock(line string) bool {
+	if atxHeadingPattern.MatchString(line) || rulePattern.MatchString(line) || fencePattern.MatchString(line) {
+		return true
+	}
+	if strings.HasPrefix(strings.TrimLeft(line, " "), ">") && indentOf(line) < 4 {
+		return true
+	}
+	if marker, ok := parseListMarker(line); ok && !isBlank(marker.rest) {
+		return !marker.ordered || marker.start == 1
+	}
+	return false
+}
+
+func parseBlocks(lines []string) []*block {
+	var blocks []*block
+	i := 0
+	for i < len(lines) {
+		line := lines[i]
+		if isBlank(line) {
+			i++
+			continue
+		}
+
+		if m := fencePattern.FindStringSubmatch(line); m != nil {
+			b, next := parseFence(lines, i, len(m[1]), m[2], m[3])
+			blocks = append(blocks, b)
+			i = next
+			continue
+		}
+
+		if indentOf(line) >= 4 {
+			b, next := parseIndentedCode(lines, i)
+			blocks = append(blocks, b)
+			i = next
+			continue
+		}
+
+		if m := atxHeadingPattern.FindStringSubmatch(line); m != nil {
+			blocks = append(blocks, &block{kind: blockHeading, level: len(m[1]), text: strings.TrimSpace(m[2])})
+			i++
+			continue
+		}
+
+		if rulePattern.MatchString(line) {
+			blocks = append(blocks, &block{kind: blockRule})
+			i++
+			continue
+		}
+
+		if strings.HasPrefix(strings.TrimLeft(line, " "), ">") {
+			b, next := parseQuote(lines, i)
+			blocks = append(blocks, b)
+			i = next
+			continue
+		}
+
+		if marker, ok := parseListMarker(line); ok {
+			b, next := parseList(lines, i, marker)
+			blocks = append(blocks, b)
+			i = next
+			continue
+		}
+
+		if i+1 < len(lines) && strings.Contains(line, "|") && isTableDelimiter(lines[i+1]) {
+			if b, next, ok := parseTable(lines, i); ok {
+				blocks = append(blocks, b)
+				i = next
+				continue
+			}
+		}
+
+		b, next := parseParagraph(lines, i)
+		blocks = append(blocks, b)
+		i = next
+	}
+	return blocks
+}
+
+func parseFence(lines []string, i, indent int, fence, lang string) (*block, int) {
+	var code []string
+	i++
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimLeft(lines[i], " ")
+		if indentOf(lines[i]) < 4 && strings.HasPrefix(trimmed, fence[:1]) &&
+			len(strings.TrimRight(trimmed, " ")) >= len(fence) && strings.Trim(strings.TrimRight(trimmed, " "), fence[:1]) == "" {
+			i++
+			break
+		}
+		// 去掉与开始标记相同的缩进
+		line := lines[i]
+		remove := indentOf(line)
+		if remove > indent {
+			remove = indent
+		}
+		code = append(code, line[remove:])
+	}
+	return &block{kind: blockCode, lang: strings.ToLower(lang), code: strings.Join(code, "\n")}, i
+}
+
+func parseIndentedCode(lines []string, i int) (*block, int) {
+	var code []string
+	for i < len(lines) {
+		if isBlank(lines[i]) {
+			code = append(code, "")
+			i++
+			continue
+		}
+		if indentOf(lines[i]) < 4 {
+			break
+		}
+		code = append(code, lines[i][4:])
+		i++
+	}
+	// 代码块末尾的空行属于后面的内容
+	for len(code) > 0 && code[len(code)-1] == "" {
+		code = code[:len(code)-1]
+	}
+	return &block{kind: blockCode, code: strings.Join(code, "\n")}, i
+}
+
+func parseQuote(lines []string, i int) (*block, int) {
+	var inner []string
+	for i < len(lines) {
+		trimmed := strings.TrimLeft(lines[i], " ")
+		if indentOf(lines[i]) >= 4 || !strings.HasPrefix(trimmed, ">") {
+			// 段落的惰性续行
+			if len(inner) > 0 && !isBlank(inner[len(inner)-1]) && !isBlank(lines[i]) && !startsBlock(lines[i]) {
+				inner = append(inner, lines[i])
+				i++
+				continue
+			}
+			break
+		}
+		trimmed = trimmed[1:]
+		trimmed = strings.TrimPrefix(trimmed, " ")
+		inner = append(inner, trimmed)
+		i++
+	}
+	return &block{kind: blockQuote, children: parseBlocks(inner)}, i
+}
+
+func parseList(lines []string, i int, first *listMarker) (*block, int) {
+	list := &block{kind: blockList, ordered: first.ordered, start: first.start}
+	for i < len(lines) {
+		marker, ok := parseListMarker(lines[i])
+		if !ok || marker.ordered != first.ordered || marker.delim != first.delim {
+			break
+		}
+
+		item := []string{marker.rest}
+		i++
+		sawBlank := false
+		for i < len(lines) {
+			line := lines[i]
+			switch {
+			case isBlank(line):
+				item = append(item, "")
+				sawBlank = true
+				i++
+				continue
+			case indentOf(line) >= marker.indent:
+				item = append(item, line[marker.indent:])
+				i++
+				continue
+			case !sawBlank && !startsBlock(line) && !isListMarker(line) && !isBlank(item[len(item)-1]):
+				// 段落的惰性续行
+				item = append(item, strings.TrimLeft(line, " "))
+				i++
+				continue
+			}
+			break
+		}
+
+		// 列表项末尾的空行：后面还有同一列表的列表项时列表为松散列表
+		trailing := 0
+		for len(item) > 1 && item[len(item)-1] == "" {
+			item = item[:len(item)-1]
+			trailing++
+		}
+		for _, line := range item {
+			if line == "" {
+				list.loose = true
+			}
+		}
+		if trailing > 0 && i < len(lines) {
+			if next, ok := parseListMarker(lines[i]); ok && next.ordered == first.ordered && next.delim == first.delim {
+				list.loose = true
+			}
+		}
+
+		list.items = append(list.items, parseBlocks(item))
+		if trailing > 0 && !list.loose {
+			break
+		}
+	}
+	return list, i
+}
+
+func isTableDelimiter(line string) bool {
+	cells := splitTableRow(line)
+	if len(cells) == 0 {
+		return false
+	}
+	for _, cell := range cells {
+		if !tableDelimiterCell.MatchString(cell) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseTable(lines []string, i int) (*block, int, bool) {
+	header := splitTableRow(lines[i])
+	delimiters := splitTableRow(lines[i+1])
+	if len(header) != len(delimiters) {
+		return nil, i, false
+	}
+
+	table := &block{kind: blockTable, header: header, aligns: make([]string, len(delimiters))}
+	for n, cell := range delimiters {
+		switch {
+		case strings.HasPrefix(cell, ":") && strings.HasSuffix(cell, ":"):
+			table.aligns[n] = "center"
+		case strings.HasSuffix(cell, ":"):
+			table.aligns[n] = "right"
+		case strings.HasPrefix(cell, ":"):
+			table.aligns[n] = "left"
+		}
+	}
+
+	i += 2
+	for ; i < len(lines) && !isBlank(lines[i]) && strings.Contains(lines[i], "|"); i++ {
+		row := splitTableRow(lines[i])
+		// 单元格数量按表头补齐或截断
+		for len(row) < len(header) {
+			row = append(row, "")
+		}
+		table.rows = append(table.rows, row[:len(header)])
+	}
+	return table, i, true
+}
+
+// splitTableRow 按未转义的竖线拆分表格行，去掉两端的竖线
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, "\\|") {
+		line = line[:len(line)-1]
+	}
+	if line == "" {
+		return nil
+	}
+
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+func parseParagraph(lines []string, i int) (*block, int) {
+	text := []string{strings.TrimLeft(lines[i], " ")}
+	i++
+	for i < len(lines) {
+		line := lines[i]
+		if isBlank(line) {
+			break
+		}
+		// Setext标题：段落下一行全是=或-
+		if setextH1Pattern.MatchString(line) {
+			return &block{kind: blockHeading, level: 1, text: strings.Join(text, "\n")}, i + 1
+		}
+		if setextH2Pattern.MatchString(line) {
+			return &block{kind: blockHeading, level: 2, text: strings.Join(text, "\n")}, i + 1
+		}
+		if startsBlock(line) {
+			break
+		}
+		text = append(text, strings.TrimLeft(line, " "))
+		i++
+	}
+	return &block{kind: blockParagraph, text: strings.TrimRight(strings.Join(text, "\n"), " ")}, i
+}
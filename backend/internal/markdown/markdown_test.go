@@ -0,0 +1,94 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderBlocks(t *testing.T) {
+	source := strings.Join([]string{
+		"# 发布说明",
+		"",
+		"Hello **bold**, *em*, ~~old~~ and `code`.",
+		"",
+		"- one",
+		"- two",
+		"  1. nested",
+		"",
+		"> quoted",
+		"",
+		"| Name | Qty |",
+		"|:-----|----:|",
+		"| pen  | 2   |",
+		"",
+		"---",
+	}, "\n")
+
+	expected := strings.Join([]string{
+		"<h1>发布说明</h1>",
+		"<p>Hello <strong>bold</strong>, <em>em</em>, <del>old</del> and <code style=",
+	}, "\n")
+	got := Render(source, Options{})
+	if !strings.HasPrefix(got, expected) {
+		t.Fatalf("unexpected heading and paragraph:\n%s", got)
+	}
+	for _, fragment := range []string{
+		"<ul>\n<li>one</li>\n<li>two\n<ol>\n<li>nested</li>\n</ol>\n</li>\n</ul>",
+		"<p>quoted</p>\n</blockquote>",
+		`<th style="border:1px solid #d0d7de;padding:6px 12px;text-align:left">Name</th>`,
+		`<td style="border:1px solid #d0d7de;padding:6px 12px;text-align:right">2</td>`,
+		"<hr",
+	} {
+		if !strings.Contains(got, fragment) {
+			t.Fatalf("missing %q in:\n%s", fragment, got)
+		}
+	}
+}
+
+func TestRenderEscapesUnsafeContent(t *testing.T) {
+	got := Render(`<script>alert(1)</script> [x](javascript:alert(1)) [ok](https://example.com "Site") ![logo](cid:logo)`, Options{})
+	if strings.Contains(got, "<script>") || strings.Contains(got, `href="javascript`) {
+		t.Fatalf("unsafe content rendered: %s", got)
+	}
+	for _, fragment := range []string{
+		"&lt;script&gt;",
+		`<a href="https://example.com" title="Site">ok</a>`,
+		`<img src="cid:logo" alt="logo"`,
+	} {
+		if !strings.Contains(got, fragment) {
+			t.Fatalf("missing %q in: %s", fragment, got)
+		}
+	}
+
+	got = Render("see https://example.com/a_b. and snake_case_name", Options{})
+	if got != `<p>see <a href="https://example.com/a_b">https://example.com/a_b</a>. and snake_case_name</p>` {
+		t.Fatalf("unexpected autolink rendering: %s", got)
+	}
+}
+
+func TestRenderCodeHighlight(t *testing.T) {
+	source := "```go\nfunc main() { // start\n\treturn \"<ok>\"\n}\n```"
+
+	plain := Render(source, Options{})
+	if strings.Contains(plain, "<span") || !strings.Contains(plain, "&#34;&lt;ok&gt;&#34;") {
+		t.Fatalf("unexpected plain code block: %s", plain)
+	}
+
+	dark := themes["dark"]
+	highlighted := Render(source, Options{CodeHighlight: true, CodeTheme: "dark"})
+	for _, fragment := range []string{
+		`background:` + dark.Background,
+		`<span style="color:` + dark.Keyword + `">func</span>`,
+		`<span style="color:` + dark.Comment + `">// start</span>`,
+		`<span style="color:` + dark.String + `">&#34;&lt;ok&gt;&#34;</span>`,
+	} {
+		if !strings.Contains(highlighted, fragment) {
+			t.Fatalf("missing %q in: %s", fragment, highlighted)
+		}
+	}
+
+	// 未知语言不着色
+	if got := Render("```brainfuck\n+++\n```", Options{CodeHighlight: true}); strings.Contains(got, "<span") {
+		t.Fatalf("unknown language should not be highlighted: %s", got)
+	}
+}
@@ -0,0 +1,451 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// renderer 输出HTML，块级样式全部内联以适配会丢弃<style>的邮件客户端
+type renderer struct {
+	opts  Options
+	theme *Theme
+}
+
+const codeFont = `SFMono-Regular,Consolas,"Liberation Mono",Menlo,monospace`
+
+func (r *renderer) renderBlocks(b *strings.Builder, blocks []*block, tight bool) {
+	for n, blk := range blocks {
+		switch blk.kind {
+		case blockParagraph:
+			if tight {
+				// 紧凑列表项中的段落不包<p>
+				b.WriteString(r.inline(blk.text))
+				if n < len(blocks)-1 {
+					b.WriteString("\n")
+				}
+				continue
+			}
+			b.WriteString("<p>" + r.inline(blk.text) + "</p>\n")
+		case blockHeading:
+			tag := "h" + strconv.Itoa(blk.level)
+			b.WriteString("<" + tag + ">" + r.inline(blk.text) + "</" + tag + ">\n")
+		case blockRule:
+			b.WriteString(`<hr style="border:0;border-top:1px solid #d0d7de">` + "\n")
+		case blockCode:
+			r.renderCode(b, blk)
+		case blockQuote:
+			b.WriteString(`<blockquote style="margin:0 0 1em 0;padding:0 1em;border-left:3px solid #d0d7de;color:#57606a">` + "\n")
+			r.renderBlocks(b, blk.children, false)
+			b.WriteString("</blockquote>\n")
+		case blockList:
+			r.renderList(b, blk)
+		case blockTable:
+			r.renderTable(b, blk)
+		}
+	}
+}
+
+func (r *renderer) renderList(b *strings.Builder, blk *block) {
+	tag := "ul"
+	if blk.ordered {
+		tag = "ol"
+	}
+	b.WriteString("<" + tag)
+	if blk.ordered && blk.start != 1 {
+		b.WriteString(` start="` + strconv.Itoa(blk.start) + `"`)
+	}
+	b.WriteString(">\n")
+	for _, item := range blk.items {
+		b.WriteString("<li>")
+		if blk.loose && len(item) > 0 {
+			b.WriteString("\n")
+		}
+		r.renderBlocks(b, item, !blk.loose)
+		b.WriteString("</li>\n")
+	}
+	b.WriteString("</" + tag + ">\n")
+}
+
+func (r *renderer) renderTable(b *strings.Builder, blk *block) {
+	const cellStyle = "border:1px solid #d0d7de;padding:6px 12px"
+	cell := func(tag, content, align string) {
+		style := cellStyle
+		if align != "" {
+			style += ";text-align:" + align
+		}
+		b.WriteString("<" + tag + ` style="` + style + `">` + r.inline(content) + "</" + tag + ">")
+	}
+
+	b.WriteString(`<table style="border-collapse:collapse;margin:0 0 1em 0">` + "\n<thead>\n<tr>")
+	for n, content := range blk.header {
+		cell("th", content, blk.aligns[n])
+	}
+	b.WriteString("</tr>\n</thead>\n")
+	if len(blk.rows) > 0 {
+		b.WriteString("<tbody>\n")
+		for _, row := range blk.rows {
+			b.WriteString("<tr>")
+			for n, content := range row {
+				cell("td", content, blk.aligns[n])
+			}
+			b.WriteString("</tr>\n")
+		}
+		b.WriteString("</tbody>\n")
+	}
+	b.WriteString("</table>\n")
+}
+
+func (r *renderer) renderCode(b *strings.Builder, blk *block) {
+	b.WriteString(`<pre style="background:` + r.theme.Background + ";color:" + r.theme.Foreground +
+		";padding:12px;border-radius:6px;overflow:auto;font-family:" + html.EscapeString(codeFont) +
+		`;font-size:13px;line-height:1.45"><code`)
+	if blk.lang != "" {
+		b.WriteString(` class="language-` + html.EscapeString(blk.lang) + `"`)
+	}
+	b.WriteString(">")
+	if lang := lookupLanguage(blk.lang); r.opts.CodeHighlight && lang != nil {
+		b.WriteString(r.highlight(blk.code, lang))
+	} else {
+		b.WriteString(html.EscapeString(blk.code))
+	}
+	b.WriteString("</code></pre>\n")
+}
+
+// inline 渲染行内元素，源文本中的HTML标签按文字输出
+func (r *renderer) inline(s string) string {
+	var out []byte
+	text := func(t string) {
+		out = append(out, html.EscapeString(t)...)
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && s[i+1] == '\n':
+			out = append(out, "<br>\n"...)
+			i += 2
+			continue
+		case c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]):
+			text(s[i+1 : i+2])
+			i += 2
+			continue
+		case c == '\n':
+			// 行尾两个以上空格为硬换行
+			trimmed := strings.TrimRight(string(out), " ")
+			if len(out)-len(trimmed) >= 2 {
+				out = append([]byte(trimmed), "<br>\n"...)
+			} else {
+				out = append([]byte(trimmed), '\n')
+			}
+			i++
+			continue
+		case c == '`':
+			if rendered, next, ok := r.codeSpan(s, i); ok {
+				out = append(out, rendered...)
+				i = next
+				continue
+			}
+			// 没有闭合的反引号串按原样输出
+			run := runLength(s, i, '`')
+			text(s[i : i+run])
+			i += run
+			continue
+		case c == '!' && i+1 < len(s) && s[i+1] == '[':
+			if rendered, next, ok := r.link(s, i+1, true); ok {
+				out = append(out, rendered...)
+				i = next
+				continue
+			}
+		case c == '[':
+			if rendered, next, ok := r.link(s, i, false); ok {
+				out = append(out, rendered...)
+				i = next
+				continue
+			}
+		case c == '<':
+			if rendered, next, ok := autolink(s, i); ok {
+				out = append(out, rendered...)
+				i = next
+				continue
+			}
+		case c == '*' || c == '_' || c == '~':
+			if rendered, next, ok := r.emphasis(s, i); ok {
+				out = append(out, rendered...)
+				i = next
+				continue
+			}
+			run := runLength(s, i, c)
+			text(s[i : i+run])
+			i += run
+			continue
+		case c == 'h' || c == 'H':
+			if rendered, next, ok := bareURL(s, i); ok {
+				out = append(out, rendered...)
+				i = next
+				continue
+			}
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		text(s[i : i+size])
+		i += size
+	}
+	return string(out)
+}
+
+func (r *renderer) codeSpan(s string, i int) (string, int, bool) {
+	run := runLength(s, i, '`')
+	for j := i + run; j < len(s); {
+		if s[j] != '`' {
+			j++
+			continue
+		}
+		closing := runLength(s, j, '`')
+		if closing != run {
+			j += closing
+			continue
+		}
+		code := strings.ReplaceAll(s[i+run:j], "\n", " ")
+		if len(code) > 1 && strings.HasPrefix(code, " ") && strings.HasSuffix(code, " ") && strings.TrimSpace(code) != "" {
+			code = code[1 : len(code)-1]
+		}
+		return `<code style="background:` + r.theme.InlineBackground + ";padding:1px 4px;border-radius:4px;font-family:" +
+			html.EscapeString(codeFont) + `">` + html.EscapeString(code) + "</code>", j + closing, true
+	}
+	return "", i, false
+}
+
+// emphasis 渲染 *em*、**strong**、_em_、__strong__ 和 ~~del~~
+func (r *renderer) emphasis(s string, i int) (string, int, bool) {
+	c := s[i]
+	run := runLength(s, i, c)
+	after := i + run
+	if after >= len(s) || isSpaceByte(s[after]) {
+		return "", i, false
+	}
+	// 下划线不在单词内部生效
+	if c == '_' && i > 0 && isWordByte(s[i-1]) {
+		return "", i, false
+	}
+
+	if c == '~' {
+		if run != 2 {
+			return "", i, false
+		}
+		if j := findCloser(s, after, c, func(n int) bool { return n == 2 }); j >= 0 {
+			return "<del>" + r.inline(s[after:j]) + "</del>", j + 2, true
+		}
+		return "", i, false
+	}
+
+	if run >= 2 {
+		if j := findCloser(s, after, c, func(n int) bool { return n >= 2 }); j >= 0 {
+			closing := runLength(s, j, c)
+			// ***x*** 拆成 <strong><em>x</em></strong>
+			inner := s[i+2 : j+closing-2]
+			return "<strong>" + r.inline(inner) + "</strong>", j + closing, true
+		}
+	}
+	if j := findCloser(s, i+1, c, func(n int) bool { return n != 2 }); j >= 0 && j > i+1 {
+		closing := runLength(s, j, c)
+		inner := s[i+1 : j+closing-1]
+		return "<em>" + r.inline(inner) + "</em>", j + closing, true
+	}
+	return "", i, false
+}
+
+// findCloser 查找可以闭合强调的分隔符串：前面不是空白，下划线后面不是单词字符
+func findCloser(s string, from int, c byte, accept func(int) bool) int {
+	for j := from; j < len(s); {
+		switch s[j] {
+		case '\\':
+			j += 2
+			continue
+		case '`':
+			// 跳过代码片段
+			run := runLength(s, j, '`')
+			if end := strings.Index(s[j+run:], strings.Repeat("`", run)); end >= 0 {
+				j += run + end + run
+			} else {
+				j += run
+			}
+			continue
+		case c:
+			run := runLength(s, j, c)
+			canClose := j > from && !isSpaceByte(s[j-1])
+			if c == '_' && j+run < len(s) && isWordByte(s[j+run]) {
+				canClose = false
+			}
+			if canClose && accept(run) {
+				return j
+			}
+			j += run
+			continue
+		}
+		j++
+	}
+	return -1
+}
+
+// link 渲染 [text](url "title") 和 ![alt](src)，i 指向左方括号
+func (r *renderer) link(s string, i int, image bool) (string, int, bool) {
+	closeBracket := matchBracket(s, i, '[', ']')
+	if closeBracket < 0 || closeBracket+1 >= len(s) || s[closeBracket+1] != '(' {
+		return "", i, false
+	}
+	closeParen := matchBracket(s, closeBracket+1, '(', ')')
+	if closeParen < 0 {
+		return "", i, false
+	}
+
+	label := s[i+1 : closeBracket]
+	destination, title := splitLinkDestination(s[closeBracket+2 : closeParen])
+	if !safeURL(destination, image) {
+		return "", i, false
+	}
+
+	if image {
+		tag := `<img src="` + html.EscapeString(destination) + `" alt="` + html.EscapeString(plainLabel(label)) + `"`
+		if title != "" {
+			tag += ` title="` + html.EscapeString(title) + `"`
+		}
+		return tag + ` style="max-width:100%">`, closeParen + 1, true
+	}
+
+	tag := `<a href="` + html.EscapeString(destination) + `"`
+	if title != "" {
+		tag += ` title="` + html.EscapeString(title) + `"`
+	}
+	return tag + ">" + r.inline(label) + "</a>", closeParen + 1, true
+}
+
+// matchBracket 返回与 s[i] 配对的右括号位置，支持嵌套和反斜杠转义
+func matchBracket(s string, i int, open, close byte) int {
+	depth := 0
+	for j := i; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return j
+			}
+		case '\n':
+			if open == '(' {
+				return -1
+			}
+		}
+	}
+	return -1
+}
+
+var linkTitlePattern = regexp.MustCompile(`^(\S+)\s+(?:"([^"]*)"|'([^']*)')$`)
+
+func splitLinkDestination(raw string) (string, string) {
+	raw = strings.TrimSpace(raw)
+	if m := linkTitlePattern.FindStringSubmatch(raw); m != nil {
+		return unescapeURL(m[1]), m[2] + m[3]
+	}
+	return unescapeURL(raw), ""
+}
+
+func unescapeURL(u string) string {
+	u = strings.TrimSuffix(strings.TrimPrefix(u, "<"), ">")
+	var b strings.Builder
+	for i := 0; i < len(u); i++ {
+		if u[i] == '\\' && i+1 < len(u) && isASCIIPunct(u[i+1]) {
+			i++
+		}
+		b.WriteByte(u[i])
+	}
+	return b.String()
+}
+
+// plainLabel 图片的alt文字去掉强调等标记
+func plainLabel(label string) string {
+	return strings.NewReplacer("*", "", "_", "", "`", "", "\\", "").Replace(label)
+}
+
+// safeURL 链接只允许http、https和mailto，图片只允许http、https和cid
+func safeURL(u string, image bool) bool {
+	if u == "" || strings.ContainsAny(u, " \n\"<>") {
+		return false
+	}
+	lower := strings.ToLower(u)
+	if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") {
+		return true
+	}
+	if image {
+		return strings.HasPrefix(lower, "cid:")
+	}
+	return strings.HasPrefix(lower, "mailto:")
+}
+
+var (
+	autolinkURLPattern   = regexp.MustCompile(`^<((?i:https?)://[^\s<>]+)>`)
+	autolinkEmailPattern = regexp.MustCompile(`^<(?:(?i:mailto):)?([a-zA-Z0-9.!#$%&'*+/=?^_{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9.-]*[a-zA-Z0-9])?)>`)
+	bareURLPattern       = regexp.MustCompile(`^(?i:https?)://[^\s<>]+`)
+)
+
+func autolink(s string, i int) (string, int, bool) {
+	if m := autolinkURLPattern.FindStringSubmatch(s[i:]); m != nil {
+		return `<a href="` + html.EscapeString(m[1]) + `">` + html.EscapeString(m[1]) + "</a>", i + len(m[0]), true
+	}
+	if m := autolinkEmailPattern.FindStringSubmatch(s[i:]); m != nil {
+		return `<a href="mailto:` + html.EscapeString(m[1]) + `">` + html.EscapeString(m[1]) + "</a>", i + len(m[0]), true
+	}
+	return "", i, false
+}
+
+// bareURL 把正文中裸露的http(s)地址转换为链接，去掉句末标点和不配对的右括号
+func bareURL(s string, i int) (string, int, bool) {
+	if i > 0 && (isWordByte(s[i-1]) || s[i-1] == '/' || s[i-1] == '"' || s[i-1] == '=') {
+		return "", i, false
+	}
+	u := bareURLPattern.FindString(s[i:])
+	if u == "" {
+		return "", i, false
+	}
+	for len(u) > 0 {
+		last := u[len(u)-1]
+		if strings.IndexByte(".,:;!?'\"*_~", last) >= 0 {
+			u = u[:len(u)-1]
+			continue
+		}
+		if last == ')' && strings.Count(u, "(") < strings.Count(u, ")") {
+			u = u[:len(u)-1]
+			continue
+		}
+		break
+	}
+	if !strings.Contains(u, "://") || strings.HasSuffix(u, "://") {
+		return "", i, false
+	}
+	return `<a href="` + html.EscapeString(u) + `">` + html.EscapeString(u) + "</a>", i + len(u), true
+}
+
+func runLength(s string, i int, c byte) int {
+	n := 0
+	for i+n < len(s) && s[i+n] == c {
+		n++
+	}
+	return n
+}
+
+func isASCIIPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+func isSpaceByte(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\t'
+}
+
+func isWordByte(c byte) bool {
+	return c < utf8.RuneSelf && (unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)))
+}
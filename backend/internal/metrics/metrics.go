@@ -0,0 +1,52 @@
+// Package metrics 定义服务的Prometheus指标，ENABLE_METRICS=true 时通过 /metrics 暴露
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// 批量写入的结果标签
+const (
+	BatchResultCommitted = "committed" // 整批提交成功
+	BatchResultSplit     = "split"     // 唯一约束冲突，拆分后重试
+	BatchResultFailed    = "failed"    // 其他错误，整批失败
+)
+
+var (
+	// syncWriteBatchSize 同步时每个写入事务包含的新邮件数
+	syncWriteBatchSize = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "firemail",
+		Subsystem: "sync",
+		Name:      "write_batch_size",
+		Help:      "Number of new emails written per sync transaction.",
+		Buckets:   []float64{1, 5, 10, 25, 50, 100, 250, 500},
+	})
+
+	// syncWriteBatchDuration 同步写入事务的耗时，按结果区分
+	syncWriteBatchDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "firemail",
+		Subsystem: "sync",
+		Name:      "write_batch_duration_seconds",
+		Help:      "Duration of sync write transactions by result.",
+		Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	}, []string{"result"})
+)
+
+func init() {
+	prometheus.MustRegister(syncWriteBatchSize, syncWriteBatchDuration)
+}
+
+// ObserveSyncWriteBatch 记录一个同步写入事务的邮件数和耗时
+func ObserveSyncWriteBatch(size int, duration time.Duration, result string) {
+	syncWriteBatchSize.Observe(float64(size))
+	syncWriteBatchDuration.WithLabelValues(result).Observe(duration.Seconds())
+}
+
+// Handler Prometheus抓取接口
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -4,7 +4,7 @@ import (
 	"log"
 	"net/http"
 
-	"firemail/internal/auth"
+	"firemail/internal/i18n"
 	"firemail/internal/models"
 
 	"github.com/gin-gonic/gin"
@@ -41,36 +41,23 @@ func OptionalAuth() gin.HandlerFunc {
 }
 
 // AuthRequiredWithService 认证中间件（带服务参数）
-func AuthRequiredWithService(authService AuthService) gin.HandlerFunc {
+// onAuthenticated 在认证通过、用户信息写入context之后依次调用
+func AuthRequiredWithService(authService AuthService, onAuthenticated ...gin.HandlerFunc) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		log.Printf("AuthRequiredWithService: Processing request %s %s", c.Request.Method, c.Request.URL.Path)
 
-		// 从header中获取token
-		authHeader := c.GetHeader("Authorization")
-		log.Printf("AuthRequiredWithService: Authorization header: %s", authHeader[:min(50, len(authHeader))])
-
-		if authHeader == "" {
-			log.Printf("AuthRequiredWithService: No authorization header")
+		// 按认证方式从请求头或会话Cookie中获取token
+		token, fromCookie, problem := requestToken(c)
+		if problem != "" {
+			log.Printf("AuthRequiredWithService: %s", problem)
 			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Authorization header is required",
+				"error": i18n.T(i18n.FromContext(c.Request.Context()), problem),
 			})
 			c.Abort()
 			return
 		}
-
-		// 提取token
-		token := auth.ExtractTokenFromHeader(authHeader)
 		log.Printf("AuthRequiredWithService: Extracted token: %s", token[:min(50, len(token))])
 
-		if token == "" {
-			log.Printf("AuthRequiredWithService: Failed to extract token")
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid authorization header format",
-			})
-			c.Abort()
-			return
-		}
-
 		// 验证token
 		user, err := authService.ValidateToken(token)
 		if err != nil {
@@ -81,7 +68,16 @@ func AuthRequiredWithService(authService AuthService) gin.HandlerFunc {
 			}
 			log.Printf("Token validation failed: %v, token: %s", err, tokenPreview)
 			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid or expired token",
+				"error": i18n.T(i18n.FromContext(c.Request.Context()), "Invalid or expired token"),
+			})
+			c.Abort()
+			return
+		}
+
+		// 浏览器会自动附带Cookie，使用Cookie认证的修改请求必须带CSRF令牌
+		if fromCookie && !validCSRF(c, token) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": i18n.T(i18n.FromContext(c.Request.Context()), "Invalid or missing CSRF token"),
 			})
 			c.Abort()
 			return
@@ -93,30 +89,28 @@ func AuthRequiredWithService(authService AuthService) gin.HandlerFunc {
 		c.Set("username", user.Username)
 		c.Set("role", user.Role)
 
+		for _, hook := range onAuthenticated {
+			hook(c)
+		}
+
 		c.Next()
 	}
 }
 
 // OptionalAuthWithService 可选认证中间件（带服务参数）
-func OptionalAuthWithService(authService AuthService) gin.HandlerFunc {
+// onAuthenticated 只在请求带有效令牌时调用
+func OptionalAuthWithService(authService AuthService, onAuthenticated ...gin.HandlerFunc) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 从header中获取token
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.Next()
-			return
-		}
-
-		// 提取token
-		token := auth.ExtractTokenFromHeader(authHeader)
-		if token == "" {
+		// 按认证方式从请求头或会话Cookie中获取token
+		token, fromCookie, problem := requestToken(c)
+		if problem != "" {
 			c.Next()
 			return
 		}
 
-		// 验证token
+		// 验证token，使用Cookie但缺少CSRF令牌的修改请求按未登录处理
 		user, err := authService.ValidateToken(token)
-		if err != nil {
+		if err != nil || (fromCookie && !validCSRF(c, token)) {
 			c.Next()
 			return
 		}
@@ -127,6 +121,10 @@ func OptionalAuthWithService(authService AuthService) gin.HandlerFunc {
 		c.Set("username", user.Username)
 		c.Set("role", user.Role)
 
+		for _, hook := range onAuthenticated {
+			hook(c)
+		}
+
 		c.Next()
 	}
 }
@@ -138,7 +136,7 @@ func RoleRequired(requiredRoles ...string) gin.HandlerFunc {
 		role, exists := c.Get("role")
 		if !exists {
 			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "User role not found",
+				"error": i18n.T(i18n.FromContext(c.Request.Context()), "User role not found"),
 			})
 			c.Abort()
 			return
@@ -157,7 +155,7 @@ func RoleRequired(requiredRoles ...string) gin.HandlerFunc {
 
 		if !hasPermission {
 			c.JSON(http.StatusForbidden, gin.H{
-				"error": "Insufficient permissions",
+				"error": i18n.T(i18n.FromContext(c.Request.Context()), "Insufficient permissions"),
 			})
 			c.Abort()
 			return
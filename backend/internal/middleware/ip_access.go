@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+
+	"firemail/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CountryLocator 查询IP所在的国家或地区代码（ISO 3166-1 alpha-2）
+type CountryLocator func(ctx context.Context, ip string) (string, error)
+
+// IPAccessRule 按来源IP限制访问的规则，网段和地区都为空时不限制
+type IPAccessRule struct {
+	Networks         []*net.IPNet
+	AllowedCountries map[string]bool
+	BlockedCountries map[string]bool
+	Locate           CountryLocator
+}
+
+// IPDenial 被拒绝的访问，交给调用方记录审计日志
+type IPDenial struct {
+	IP          string
+	CountryCode string
+	Reason      string
+}
+
+// NewIPAccessRule 根据白名单（IP或CIDR）和允许、禁止的地区创建规则
+func NewIPAccessRule(allowlist, allowedCountries, blockedCountries []string, locate CountryLocator) (*IPAccessRule, error) {
+	networks, err := ParseIPNetworks(allowlist)
+	if err != nil {
+		return nil, err
+	}
+	return &IPAccessRule{
+		Networks:         networks,
+		AllowedCountries: countrySet(allowedCountries),
+		BlockedCountries: countrySet(blockedCountries),
+		Locate:           locate,
+	}, nil
+}
+
+// ParseIPNetworks 解析IP和CIDR列表，单个IP按 /32 或 /128 处理
+func ParseIPNetworks(entries []string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address %q", entry)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", entry, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+func countrySet(codes []string) map[string]bool {
+	set := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		if code = strings.ToUpper(strings.TrimSpace(code)); code != "" {
+			set[code] = true
+		}
+	}
+	return set
+}
+
+// Enabled 规则是否有任何限制
+func (r *IPAccessRule) Enabled() bool {
+	return r != nil && (len(r.Networks) > 0 || len(r.AllowedCountries) > 0 || len(r.BlockedCountries) > 0)
+}
+
+// Check 检查来源IP，允许时返回nil
+func (r *IPAccessRule) Check(ctx context.Context, clientIP string) *IPDenial {
+	if !r.Enabled() {
+		return nil
+	}
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return &IPDenial{IP: clientIP, Reason: "unrecognized client address"}
+	}
+
+	if len(r.Networks) > 0 && !containsIP(r.Networks, ip) {
+		return &IPDenial{IP: clientIP, Reason: "address not in allowlist"}
+	}
+
+	// 内网和本机地址没有地理位置，地区限制只针对公网来源
+	if (len(r.AllowedCountries) == 0 && len(r.BlockedCountries) == 0) || r.Locate == nil ||
+		ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() {
+		return nil
+	}
+
+	country, err := r.Locate(ctx, clientIP)
+	country = strings.ToUpper(country)
+	if err != nil || country == "" {
+		// 查询失败时，配置了允许地区则拒绝，只配置了禁止地区则放行
+		if len(r.AllowedCountries) > 0 {
+			return &IPDenial{IP: clientIP, Reason: "country lookup failed"}
+		}
+		log.Printf("Country lookup for %s failed, allowing request: %v", clientIP, err)
+		return nil
+	}
+	if r.BlockedCountries[country] {
+		return &IPDenial{IP: clientIP, CountryCode: country, Reason: "country is blocked"}
+	}
+	if len(r.AllowedCountries) > 0 && !r.AllowedCountries[country] {
+		return &IPDenial{IP: clientIP, CountryCode: country, Reason: "country not allowed"}
+	}
+	return nil
+}
+
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// IPAccess 按来源IP限制访问的中间件，规则为空时直接放行。onDenied 在拒绝请求前调用，用于记录审计日志
+func IPAccess(rule *IPAccessRule, onDenied func(c *gin.Context, denial *IPDenial)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !rule.Enabled() {
+			c.Next()
+			return
+		}
+
+		denial := rule.Check(c.Request.Context(), c.ClientIP())
+		if denial == nil {
+			c.Next()
+			return
+		}
+
+		log.Printf("Access denied for %s %s from %s: %s", c.Request.Method, c.Request.URL.Path, denial.IP, denial.Reason)
+		if onDenied != nil {
+			onDenied(c, denial)
+		}
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": i18n.T(i18n.FromContext(c.Request.Context()), "Access denied from this IP address"),
+		})
+		c.Abort()
+	}
+}
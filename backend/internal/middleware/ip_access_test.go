@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIPAccessRule(t *testing.T) {
+	countries := map[string]string{"203.0.113.7": "CN", "198.51.100.1": "US"}
+	locate := func(ctx context.Context, ip string) (string, error) {
+		if code, ok := countries[ip]; ok {
+			return code, nil
+		}
+		return "", errors.New("lookup failed")
+	}
+
+	rule, err := NewIPAccessRule([]string{"10.0.0.0/8", " 203.0.113.7 ", "2001:db8::/32"}, nil, nil, locate)
+	require.NoError(t, err)
+	require.Nil(t, rule.Check(context.Background(), "10.1.2.3"))
+	require.Nil(t, rule.Check(context.Background(), "203.0.113.7"))
+	require.Nil(t, rule.Check(context.Background(), "2001:db8::1"))
+	require.NotNil(t, rule.Check(context.Background(), "203.0.113.8"))
+	require.NotNil(t, rule.Check(context.Background(), "not-an-ip"))
+
+	// 只配置地区：内网放行，查询失败时按是否配置了允许地区决定
+	blocked, err := NewIPAccessRule(nil, nil, []string{"us"}, locate)
+	require.NoError(t, err)
+	require.Nil(t, blocked.Check(context.Background(), "203.0.113.7"))
+	require.Equal(t, "US", blocked.Check(context.Background(), "198.51.100.1").CountryCode)
+	require.Nil(t, blocked.Check(context.Background(), "192.0.2.1"))
+
+	allowed, err := NewIPAccessRule(nil, []string{"CN"}, nil, locate)
+	require.NoError(t, err)
+	require.Nil(t, allowed.Check(context.Background(), "203.0.113.7"))
+	require.Nil(t, allowed.Check(context.Background(), "192.168.1.5"))
+	require.NotNil(t, allowed.Check(context.Background(), "198.51.100.1"))
+	require.NotNil(t, allowed.Check(context.Background(), "192.0.2.1"))
+
+	_, err = NewIPAccessRule([]string{"10.0.0.0/33"}, nil, nil, nil)
+	require.Error(t, err)
+}
+
+func TestIPAccessMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rule, err := NewIPAccessRule([]string{"10.0.0.0/8"}, nil, nil, nil)
+	require.NoError(t, err)
+
+	var denials []*IPDenial
+	router := gin.New()
+	require.NoError(t, router.SetTrustedProxies(nil))
+	router.GET("/admin", IPAccess(rule, func(c *gin.Context, denial *IPDenial) { denials = append(denials, denial) }), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	do := func(remoteAddr, forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		req.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	require.Equal(t, http.StatusOK, do("10.0.0.5:1234", ""))
+	require.Equal(t, http.StatusForbidden, do("192.0.2.10:1234", ""))
+	// 不信任代理时伪造的 X-Forwarded-For 无效
+	require.Equal(t, http.StatusForbidden, do("192.0.2.10:1234", "10.0.0.5"))
+	require.Len(t, denials, 2)
+	require.Equal(t, "192.0.2.10", denials[1].IP)
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"firemail/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Locale 语言环境中间件，按 Accept-Language 请求头在请求上下文中记录语言环境。
+// 认证通过后处理器会按用户设置覆盖
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if locale := i18n.FromAcceptLanguage(c.GetHeader("Accept-Language")); locale != "" {
+			c.Request = c.Request.WithContext(i18n.WithLocale(c.Request.Context(), locale))
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"sync"
+	"time"
+
+	"firemail/internal/auth"
+	"firemail/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CSRFHeader 使用Cookie认证时，修改数据的请求携带CSRF令牌的请求头
+const CSRFHeader = "X-CSRF-Token"
+
+var (
+	sessionConfigMu sync.RWMutex
+	sessionConfig   = config.AuthConfig{Mode: config.AuthModeHeader}
+)
+
+// ConfigureSessionCookies 设置认证方式和会话Cookie属性，启动时调用一次
+func ConfigureSessionCookies(cfg config.AuthConfig) {
+	sessionConfigMu.Lock()
+	defer sessionConfigMu.Unlock()
+	sessionConfig = cfg
+}
+
+func currentSessionConfig() config.AuthConfig {
+	sessionConfigMu.RLock()
+	defer sessionConfigMu.RUnlock()
+	return sessionConfig
+}
+
+// CookieAuthEnabled 是否启用了会话Cookie认证
+func CookieAuthEnabled() bool {
+	return currentSessionConfig().Mode != config.AuthModeHeader
+}
+
+// HeaderAuthEnabled 是否接受 Authorization 请求头中的令牌
+func HeaderAuthEnabled() bool {
+	return currentSessionConfig().Mode != config.AuthModeCookie
+}
+
+// requestToken 按认证方式从请求中取出令牌。problem 非空时为缺少令牌或格式错误的说明
+func requestToken(c *gin.Context) (token string, fromCookie bool, problem string) {
+	cfg := currentSessionConfig()
+
+	if cfg.Mode != config.AuthModeCookie {
+		if authHeader := c.GetHeader("Authorization"); authHeader != "" {
+			token = auth.ExtractTokenFromHeader(authHeader)
+			if token == "" {
+				return "", false, "Invalid authorization header format"
+			}
+			return token, false, ""
+		}
+	}
+
+	if cfg.Mode != config.AuthModeHeader {
+		if token := SessionCookieToken(c); token != "" {
+			return token, true, ""
+		}
+		return "", false, "Authentication required"
+	}
+	return "", false, "Authorization header is required"
+}
+
+// SessionCookieToken 会话Cookie中的令牌，未启用Cookie认证或没有Cookie时返回空字符串
+func SessionCookieToken(c *gin.Context) string {
+	cfg := currentSessionConfig()
+	if cfg.Mode == config.AuthModeHeader {
+		return ""
+	}
+	token, err := c.Cookie(cfg.CookieName)
+	if err != nil {
+		return ""
+	}
+	return token
+}
+
+// CSRFToken 会话对应的CSRF令牌。令牌由会话令牌签名得到，攻击者写入的Cookie无法配出有效令牌
+func CSRFToken(sessionToken string) string {
+	mac := hmac.New(sha256.New, []byte(currentSessionConfig().JWTSecret))
+	mac.Write([]byte("csrf:" + sessionToken))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// validCSRF 检查请求的CSRF令牌，GET、HEAD、OPTIONS 请求不修改数据，无需检查
+func validCSRF(c *gin.Context, sessionToken string) bool {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	provided := c.GetHeader(CSRFHeader)
+	return provided != "" && hmac.Equal([]byte(provided), []byte(CSRFToken(sessionToken)))
+}
+
+// SetSessionCookies 登录成功后写入会话Cookie（HttpOnly）和CSRF Cookie（前端可读），返回CSRF令牌
+func SetSessionCookies(c *gin.Context, token string, expiresAt time.Time) string {
+	csrfToken := CSRFToken(token)
+	maxAge := int(time.Until(expiresAt).Seconds())
+	setSessionCookie(c, currentSessionConfig().CookieName, token, maxAge, true)
+	setSessionCookie(c, currentSessionConfig().CookieName+"_csrf", csrfToken, maxAge, false)
+	return csrfToken
+}
+
+// ClearSessionCookies 登出时删除会话Cookie和CSRF Cookie
+func ClearSessionCookies(c *gin.Context) {
+	setSessionCookie(c, currentSessionConfig().CookieName, "", -1, true)
+	setSessionCookie(c, currentSessionConfig().CookieName+"_csrf", "", -1, false)
+}
+
+func setSessionCookie(c *gin.Context, name, value string, maxAge int, httpOnly bool) {
+	cfg := currentSessionConfig()
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Domain:   cfg.CookieDomain,
+		MaxAge:   maxAge,
+		Secure:   cfg.CookieSecure,
+		HttpOnly: httpOnly,
+		SameSite: cookieSameSite(cfg.CookieSameSite),
+	})
+}
+
+func cookieSameSite(value string) http.SameSite {
+	switch value {
+	case "lax":
+		return http.SameSiteLaxMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteStrictMode
+	}
+}
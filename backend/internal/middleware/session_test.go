@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"firemail/internal/config"
+	"firemail/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTokenValidator struct{}
+
+func (fakeTokenValidator) ValidateToken(token string) (*models.User, error) {
+	if token != "valid-token" {
+		return nil, errors.New("invalid token")
+	}
+	user := &models.User{Username: "alice", Role: "user"}
+	user.ID = 7
+	return user, nil
+}
+
+func TestCookieAuthRequiresCSRF(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ConfigureSessionCookies(config.AuthConfig{
+		JWTSecret:      "secret",
+		Mode:           config.AuthModeCookie,
+		CookieName:     "sid",
+		CookieSecure:   true,
+		CookieSameSite: "strict",
+	})
+	t.Cleanup(func() { ConfigureSessionCookies(config.AuthConfig{Mode: config.AuthModeHeader}) })
+
+	router := gin.New()
+	router.Use(AuthRequiredWithService(fakeTokenValidator{}))
+	router.GET("/items", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/items", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	// 登录时写入的Cookie
+	login := httptest.NewRecorder()
+	loginCtx, _ := gin.CreateTestContext(login)
+	csrf := SetSessionCookies(loginCtx, "valid-token", time.Now().Add(time.Hour))
+	cookies := login.Result().Cookies()
+	require.Len(t, cookies, 2)
+	require.True(t, cookies[0].HttpOnly)
+	require.True(t, cookies[0].Secure)
+	require.Equal(t, http.SameSiteStrictMode, cookies[0].SameSite)
+	require.Equal(t, "sid_csrf", cookies[1].Name)
+	require.False(t, cookies[1].HttpOnly)
+
+	do := func(method string, headers map[string]string) int {
+		req := httptest.NewRequest(method, "/items", nil)
+		req.AddCookie(cookies[0])
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	require.Equal(t, http.StatusOK, do(http.MethodGet, nil))
+	require.Equal(t, http.StatusForbidden, do(http.MethodPost, nil))
+	require.Equal(t, http.StatusForbidden, do(http.MethodPost, map[string]string{CSRFHeader: CSRFToken("other-token")}))
+	require.Equal(t, http.StatusOK, do(http.MethodPost, map[string]string{CSRFHeader: csrf}))
+
+	// 只接受Cookie时忽略Authorization请求头
+	req := httptest.NewRequest(http.MethodGet, "/items", nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+}
@@ -21,6 +21,10 @@ type Attachment struct {
 	IsInline     bool   `gorm:"column:is_inline;not null;default:false" json:"is_inline"` // 是否为内联附件
 	Encoding     string `gorm:"size:50;not null;default:'7bit'" json:"encoding"` // 传输编码类型：base64, quoted-printable, 7bit, 8bit等
 
+	// 安全策略
+	IsBlocked   bool   `gorm:"column:is_blocked;not null;default:false" json:"is_blocked"` // 是否被附件安全策略拦截
+	BlockReason string `gorm:"column:block_reason;size:255" json:"block_reason,omitempty"` // 拦截原因
+
 	// IMAP信息
 	PartID string `gorm:"column:part_id;size:50" json:"part_id"` // IMAP part ID，用于从IMAP服务器下载附件
 
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// AttachmentLink 附件下载链接。发信时超过大小上限的附件存入附件存储，邮件正文中只放签名的下载地址，
+// 收件人在有效期内凭链接下载
+type AttachmentLink struct {
+	BaseModel
+	UserID       uint      `gorm:"not null;index" json:"-"`
+	AttachmentID uint      `gorm:"not null;index" json:"attachment_id"`
+	ExpiresAt    time.Time `gorm:"not null;index" json:"expires_at"`
+
+	// 下载统计
+	DownloadCount    int        `gorm:"not null;default:0" json:"download_count"`
+	LastDownloadedAt *time.Time `json:"last_downloaded_at,omitempty"`
+
+	// 关联关系
+	Attachment Attachment `gorm:"foreignKey:AttachmentID" json:"-"`
+}
+
+// TableName 指定表名
+func (AttachmentLink) TableName() string {
+	return "attachment_links"
+}
+
+// IsActive 链接未过期
+func (l *AttachmentLink) IsActive(now time.Time) bool {
+	return now.Before(l.ExpiresAt)
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// 屏蔽发件人邮件的处理方式
+const (
+	BlockedSenderActionTrash = "trash" // 移入回收站
+	BlockedSenderActionSpam  = "spam"  // 移入垃圾邮件文件夹，账户没有垃圾邮件文件夹时移入回收站
+)
+
+// BlockedSender 用户屏蔽的发件人地址或域名，同步时来自这些发件人的新邮件会被自动移走
+type BlockedSender struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_blocked_senders_user_pattern" json:"-"`
+	Pattern   string    `gorm:"size:255;not null;uniqueIndex:idx_blocked_senders_user_pattern" json:"pattern"` // 小写邮箱地址，或以@开头的域名（同时匹配子域名）
+	Action    string    `gorm:"size:20;not null;default:trash" json:"action"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (BlockedSender) TableName() string {
+	return "blocked_senders"
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CannedResponse 快捷回复：撰写邮件时插入的简短文本片段，支持模板变量
+type CannedResponse struct {
+	ID         uint       `gorm:"primarykey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"-"`
+	Name       string     `gorm:"size:100;not null" json:"name"`
+	Shortcut   string     `gorm:"size:50;index" json:"shortcut,omitempty"` // 撰写时输入的快捷指令，如 thanks，同一用户内唯一
+	TextBody   string     `gorm:"type:text;not null" json:"text_body"`
+	HTMLBody   string     `gorm:"type:text" json:"html_body,omitempty"`
+	FolderIDs  string     `gorm:"type:text" json:"folder_ids"` // JSON数组，在这些文件夹中回复时优先推荐
+	UsageCount int        `gorm:"not null;default:0" json:"usage_count"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (CannedResponse) TableName() string {
+	return "canned_responses"
+}
+
+// GetFolderIDs 获取推荐文件夹列表
+func (r *CannedResponse) GetFolderIDs() []uint {
+	if r.FolderIDs == "" {
+		return []uint{}
+	}
+
+	var folderIDs []uint
+	if err := json.Unmarshal([]byte(r.FolderIDs), &folderIDs); err != nil {
+		return []uint{}
+	}
+	return folderIDs
+}
+
+// SetFolderIDs 设置推荐文件夹列表
+func (r *CannedResponse) SetFolderIDs(folderIDs []uint) error {
+	if len(folderIDs) == 0 {
+		r.FolderIDs = ""
+		return nil
+	}
+
+	data, err := json.Marshal(folderIDs)
+	if err != nil {
+		return err
+	}
+	r.FolderIDs = string(data)
+	return nil
+}
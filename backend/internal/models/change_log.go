@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// 变更日志实体类型
+const (
+	ChangeEntityEmail   = "email"
+	ChangeEntityFolder  = "folder"
+	ChangeEntityAccount = "account"
+)
+
+// 变更日志操作类型
+const (
+	ChangeActionCreated = "created"
+	ChangeActionUpdated = "updated"
+	ChangeActionDeleted = "deleted"
+)
+
+// ChangeLogEntry 用户级变更日志，自增ID作为增量同步令牌
+type ChangeLogEntry struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	UserID     uint      `gorm:"not null;index" json:"user_id"`
+	AccountID  uint      `gorm:"not null;default:0" json:"account_id"`
+	EntityType string    `gorm:"size:20;not null" json:"entity_type"` // email, folder, account
+	EntityID   uint      `gorm:"not null" json:"entity_id"`
+	Action     string    `gorm:"size:20;not null" json:"action"` // created, updated, deleted
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+}
+
+// TableName 指定表名
+func (ChangeLogEntry) TableName() string {
+	return "change_log_entries"
+}
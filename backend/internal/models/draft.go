@@ -20,6 +20,9 @@ type Draft struct {
 	// 邮件内容
 	TextBody string `gorm:"type:text" json:"text_body"`
 	HTMLBody string `gorm:"type:text" json:"html_body"`
+
+	// MarkdownBody 用Markdown撰写时的源文本，正文由其渲染，再次编辑时使用
+	MarkdownBody string `gorm:"type:text" json:"markdown_body,omitempty"`
 	
 	// 附件信息
 	AttachmentIDs string `gorm:"type:text" json:"attachment_ids"` // JSON格式的附件ID列表
@@ -30,6 +33,12 @@ type Draft struct {
 	TemplateName string     `gorm:"size:100" json:"template_name,omitempty"`
 	LastEditedAt *time.Time `json:"last_edited_at"`
 	
+	// IMAP同步信息（草稿同步到服务器Drafts文件夹）
+	RemoteMessageID string     `gorm:"size:255;index" json:"remote_message_id,omitempty"`
+	RemoteUID       uint32     `gorm:"default:0" json:"remote_uid,omitempty"`
+	RemoteFolder    string     `gorm:"size:255" json:"remote_folder,omitempty"`
+	RemoteSyncedAt  *time.Time `json:"remote_synced_at,omitempty"`
+	
 	// 关联关系
 	User    User         `gorm:"foreignKey:UserID" json:"user,omitempty"`
 	Account EmailAccount `gorm:"foreignKey:AccountID" json:"account,omitempty"`
@@ -0,0 +1,65 @@
+package models
+
+// 草稿修订来源
+const (
+	DraftRevisionSourceSave     = "save"
+	DraftRevisionSourceAutosave = "autosave"
+	DraftRevisionSourceRestore  = "restore"
+)
+
+// DraftRevision 草稿修订历史模型
+type DraftRevision struct {
+	BaseModel
+	DraftID  uint   `gorm:"not null;index" json:"draft_id"`
+	UserID   uint   `gorm:"not null;index" json:"user_id"`
+	Revision int    `gorm:"not null" json:"revision"`
+	Source   string `gorm:"size:20;not null" json:"source"` // save, autosave, restore
+
+	// 草稿内容快照
+	Subject       string `gorm:"size:500" json:"subject"`
+	To            string `gorm:"column:to_addresses;type:text" json:"to"`
+	CC            string `gorm:"column:cc_addresses;type:text" json:"cc"`
+	BCC           string `gorm:"column:bcc_addresses;type:text" json:"bcc"`
+	TextBody      string `gorm:"type:text" json:"text_body"`
+	HTMLBody      string `gorm:"type:text" json:"html_body"`
+	MarkdownBody  string `gorm:"type:text" json:"markdown_body,omitempty"`
+	AttachmentIDs string `gorm:"type:text" json:"attachment_ids"`
+	Priority      string `gorm:"size:20" json:"priority"`
+}
+
+// TableName 指定表名
+func (DraftRevision) TableName() string {
+	return "draft_revisions"
+}
+
+// NewDraftRevision 根据草稿当前内容创建修订快照
+func NewDraftRevision(draft *Draft, revision int, source string) *DraftRevision {
+	return &DraftRevision{
+		DraftID:       draft.ID,
+		UserID:        draft.UserID,
+		Revision:      revision,
+		Source:        source,
+		Subject:       draft.Subject,
+		To:            draft.To,
+		CC:            draft.CC,
+		BCC:           draft.BCC,
+		TextBody:      draft.TextBody,
+		HTMLBody:      draft.HTMLBody,
+		MarkdownBody:  draft.MarkdownBody,
+		AttachmentIDs: draft.AttachmentIDs,
+		Priority:      draft.Priority,
+	}
+}
+
+// ApplyTo 将修订内容恢复到草稿
+func (r *DraftRevision) ApplyTo(draft *Draft) {
+	draft.Subject = r.Subject
+	draft.To = r.To
+	draft.CC = r.CC
+	draft.BCC = r.BCC
+	draft.TextBody = r.TextBody
+	draft.HTMLBody = r.HTMLBody
+	draft.MarkdownBody = r.MarkdownBody
+	draft.AttachmentIDs = r.AttachmentIDs
+	draft.Priority = r.Priority
+}
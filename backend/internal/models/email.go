@@ -3,15 +3,21 @@ package models
 import (
 	"encoding/json"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // Email 邮件模型
 type Email struct {
 	BaseModel
+	Version uint `gorm:"not null;default:1" json:"version"` // 乐观锁版本号，每次修改递增；更新时通过 If-Match 提交读取到的版本
+
 	AccountID uint   `gorm:"not null;index" json:"account_id"`
+	UserID    uint   `gorm:"not null;default:0;index" json:"-"` // 冗余自所属账户，列表查询无需关联账户表
 	FolderID  *uint  `gorm:"index" json:"folder_id,omitempty"`
 	MessageID string `gorm:"not null;size:255;index" json:"message_id"` // 邮件唯一标识
 	UID       uint32 `gorm:"not null;index" json:"uid"`                 // IMAP UID
+	ThreadID  string `gorm:"size:255;index" json:"thread_id"`           // 会话ID，即会话首封邮件的Message-ID
 
 	// 邮件头信息
 	Subject string    `gorm:"size:500" json:"subject"`
@@ -22,9 +28,9 @@ type Email struct {
 	ReplyTo string    `gorm:"size:255" json:"reply_to"`
 	Date    time.Time `gorm:"index" json:"date"`
 
-	// 邮件内容
-	TextBody string `gorm:"type:text" json:"text_body"`
-	HTMLBody string `gorm:"type:text" json:"html_body"`
+	// 邮件内容，保存在 email_bodies 表，只有 Preload("Body") 时才会读取
+	TextBody string `gorm:"-" json:"text_body"`
+	HTMLBody string `gorm:"-" json:"html_body"`
 
 	// 邮件状态
 	IsRead      bool `gorm:"not null;default:false;index" json:"is_read"`
@@ -34,9 +40,41 @@ type Email struct {
 	IsDraft     bool `gorm:"not null;default:false" json:"is_draft"`
 	IsSent      bool `gorm:"not null;default:false" json:"is_sent"`
 
+	// 已回复和已转发，对应服务器的 \Answered 和 $Forwarded 标志
+	IsAnswered  bool `gorm:"not null;default:false" json:"is_answered"`
+	IsForwarded bool `gorm:"not null;default:false" json:"is_forwarded"`
+
+	// 旗标：星标颜色和后续跟进，尽量映射为IMAP关键字与服务器同步；跟进截止时间仅保存在本地
+	FlagColor           string     `gorm:"size:20;not null;default:''" json:"flag_color"` // 为空表示默认星标
+	IsFollowUp          bool       `gorm:"not null;default:false" json:"is_follow_up"`
+	FollowUpAt          *time.Time `json:"follow_up_at,omitempty"`
+	FollowUpCompletedAt *time.Time `json:"follow_up_completed_at,omitempty"`
+
+	// 置顶状态，仅保存在本地，与星标互不影响
+	IsPinned bool       `gorm:"not null;default:false" json:"is_pinned"`
+	PinnedAt *time.Time `json:"pinned_at,omitempty"`
+
+	// 稍后回复，仅保存在本地；到提醒时间后邮件标记为未读并置顶，用户回复后自动清除
+	ReplyLaterAt       *time.Time `json:"reply_later_at,omitempty"`
+	ReplyLaterRemindAt *time.Time `gorm:"index" json:"reply_later_remind_at,omitempty"`
+
+	// 优先收件箱分类：自动分类结果和得分，用户反馈后不再被自动分类覆盖
+	ImportanceBucket string `gorm:"size:20;not null;default:other;index" json:"importance_bucket"` // important, other
+	ImportanceScore  int    `gorm:"not null;default:0" json:"importance_score"`
+	ImportanceManual bool   `gorm:"not null;default:false" json:"importance_manual"`
+
+	// 发件人是否为VIP，同步时根据VIP列表设置，VIP列表变化时回填
+	IsVIP bool `gorm:"column:is_vip;not null;default:false;index" json:"is_vip"`
+
 	// 邮件大小和附件信息
-	Size          int64 `gorm:"default:0" json:"size"`
-	HasAttachment bool  `gorm:"not null;default:false" json:"has_attachment"`
+	Size            int64 `gorm:"default:0" json:"size"`
+	HasAttachment   bool  `gorm:"not null;default:false" json:"has_attachment"`
+	AttachmentCount int   `gorm:"not null;default:0" json:"attachment_count"`
+
+	// 列表摘要，创建邮件时生成；Preview 为nil表示历史邮件尚未生成
+	Preview          *string `gorm:"type:text" json:"preview,omitempty"`
+	SenderInitials   string  `gorm:"size:8;not null;default:''" json:"sender_initials"`
+	SenderAvatarHash string  `gorm:"size:32;not null;default:''" json:"sender_avatar_hash"` // 发件人地址的MD5，可用于Gravatar
 
 	// 邮件标签和分类
 	Labels   string `gorm:"type:text" json:"labels"`                  // JSON数组格式
@@ -45,10 +83,18 @@ type Email struct {
 	// 同步信息
 	SyncedAt *time.Time `json:"synced_at"`
 
+	// 移入回收站的时间，超过保留期后物理删除
+	TrashedAt *time.Time `gorm:"index" json:"trashed_at,omitempty"`
+
 	// 关联关系
 	Account     EmailAccount `gorm:"foreignKey:AccountID" json:"account,omitempty"`
 	Folder      *Folder      `gorm:"foreignKey:FolderID" json:"folder,omitempty"`
 	Attachments []Attachment `gorm:"foreignKey:EmailID" json:"attachments,omitempty"`
+	Notes       []EmailNote  `gorm:"foreignKey:EmailID" json:"notes,omitempty"` // 当前用户的私有笔记，仅详情接口返回
+	Body        *EmailBody   `gorm:"foreignKey:EmailID" json:"-"`               // 正文，读取后填充到 TextBody 和 HTMLBody
+
+	// 详情接口去掉的追踪像素数量，不保存
+	TrackersRemoved int `gorm:"-" json:"trackers_removed,omitempty"`
 }
 
 // TableName 指定表名
@@ -56,6 +102,41 @@ func (Email) TableName() string {
 	return "emails"
 }
 
+// BeforeCreate 创建前钩子，生成列表摘要，未设置用户ID时从所属账户补齐
+func (e *Email) BeforeCreate(tx *gorm.DB) error {
+	if e.Preview == nil {
+		e.FillSummary()
+	}
+	if e.UserID != 0 || e.AccountID == 0 {
+		return nil
+	}
+	if e.Account.ID == e.AccountID && e.Account.UserID != 0 {
+		e.UserID = e.Account.UserID
+		return nil
+	}
+	return tx.Session(&gorm.Session{NewDB: true}).
+		Model(&EmailAccount{}).
+		Where("id = ?", e.AccountID).
+		Pluck("user_id", &e.UserID).Error
+}
+
+// AfterCreate 创建后钩子，把正文写入 email_bodies 表
+func (e *Email) AfterCreate(tx *gorm.DB) error {
+	if e.TextBody == "" && e.HTMLBody == "" {
+		return nil
+	}
+	return SaveEmailBody(tx.Session(&gorm.Session{NewDB: true}), e.ID, e.TextBody, e.HTMLBody)
+}
+
+// AfterFind 查询后钩子，预加载了正文时填充 TextBody 和 HTMLBody
+func (e *Email) AfterFind(tx *gorm.DB) error {
+	if e.Body != nil {
+		e.TextBody = e.Body.TextBody
+		e.HTMLBody = e.Body.HTMLBody
+	}
+	return nil
+}
+
 // EmailAddress 邮件地址结构
 type EmailAddress struct {
 	Name    string `json:"name"`
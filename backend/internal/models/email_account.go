@@ -2,12 +2,17 @@ package models
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // EmailAccount 邮件账户模型
 type EmailAccount struct {
 	BaseModel
+	Version uint `gorm:"not null;default:1" json:"version"` // 乐观锁版本号，每次修改递增；更新时通过 If-Match 提交读取到的版本
+
 	UserID     uint   `gorm:"not null;index" json:"user_id"`
 	Name       string `gorm:"not null;size:100" json:"name"`       // 账户显示名称
 	Email      string `gorm:"not null;size:100" json:"email"`      // 邮箱地址
@@ -15,6 +20,13 @@ type EmailAccount struct {
 	AuthMethod string `gorm:"not null;size:20" json:"auth_method"` // 认证方式 (password, oauth2)
 	GroupID    *uint  `gorm:"index" json:"group_id,omitempty"`     // 分组ID，未分组时为空
 
+	// 显示设置，保存在服务端使各设备的统一收件箱显示一致
+	Nickname       string `gorm:"size:100" json:"nickname"`                      // 列表中代替名称显示的昵称
+	Color          string `gorm:"size:7" json:"color"`                           // #rrggbb
+	AvatarEmoji    string `gorm:"size:32" json:"avatar_emoji"`                   // 头像表情，优先于首字母
+	AvatarInitials string `gorm:"size:12" json:"avatar_initials"`                // 头像首字母，最多3个字符
+	DisplayOrder   int    `gorm:"not null;default:0;index" json:"display_order"` // 账户列表按此升序排列
+
 	// IMAP配置
 	IMAPHost     string `gorm:"size:100" json:"imap_host"`
 	IMAPPort     int    `gorm:"default:993" json:"imap_port"`
@@ -25,23 +37,59 @@ type EmailAccount struct {
 	SMTPPort     int    `gorm:"default:587" json:"smtp_port"`
 	SMTPSecurity string `gorm:"size:20;default:'STARTTLS'" json:"smtp_security"` // SSL, TLS, STARTTLS, NONE
 
+	// TLS设置，同时用于IMAP和SMTP连接
+	TLSMinVersion         string `gorm:"column:tls_min_version;size:10" json:"tls_min_version"`                                  // 1.0、1.1、1.2、1.3，为空时使用默认值
+	TLSCACert             string `gorm:"column:tls_ca_cert;type:text" json:"tls_ca_cert"`                                        // 额外信任的CA证书（PEM），用于自建服务器
+	TLSInsecureSkipVerify bool   `gorm:"column:tls_insecure_skip_verify;not null;default:false" json:"tls_insecure_skip_verify"` // 跳过证书校验，仅用于测试环境
+	TLSPinnedKeys         string `gorm:"column:tls_pinned_keys;type:text" json:"tls_pinned_keys"`                                // 证书公钥指纹（JSON数组，sha256/<base64>）
+
+	// DNS解析器：DNS服务器地址或DoH地址（https://），为空时使用全局设置 DNS_RESOLVER
+	DNSResolver string `gorm:"column:dns_resolver;size:255" json:"dns_resolver"`
+
+	// 大邮件获取配置
+	MaxPartSize int64 `gorm:"default:0" json:"max_part_size"` // 单个MIME部分内联下载的大小上限（字节），0表示使用默认值
+
 	// 认证信息（加密存储）
 	Username string `gorm:"size:100" json:"username,omitempty"`
 	Password string `gorm:"size:255" json:"-"` // 密码不在JSON中返回
 
+	// 发件别名（JSON数组格式），如iCloud自定义域名地址、隐藏邮件地址，可以作为发件人地址
+	SendAliases string `gorm:"type:text" json:"send_aliases"`
+
+	// 外发邮件标识，为空时使用实例配置
+	EHLOName        string `gorm:"column:ehlo_name;size:255" json:"ehlo_name"`                 // SMTP问候使用的主机名
+	MessageIDDomain string `gorm:"column:message_id_domain;size:255" json:"message_id_domain"` // Message-ID的域名部分
+	Mailer          string `gorm:"size:100" json:"mailer"`                                     // User-Agent和X-Mailer头的值
+
+	// 退信地址，作为SMTP信封发件人（Return-Path），为空时使用发件人地址
+	BounceAddress string `gorm:"size:255" json:"bounce_address"`
+
 	// OAuth2信息
 	OAuth2Token string `gorm:"column:oauth2_token;type:text" json:"-"` // OAuth2 token（JSON格式，加密存储）
 
 	// 状态信息
 	IsActive     bool       `gorm:"not null;default:true" json:"is_active"`
 	LastSyncAt   *time.Time `json:"last_sync_at"`
-	SyncStatus   string     `gorm:"size:20;default:'pending'" json:"sync_status"` // pending, syncing, success, error
+	SyncStatus   string     `gorm:"size:20;default:'pending'" json:"sync_status"` // pending, syncing, success, error, paused
 	ErrorMessage string     `gorm:"type:text" json:"error_message,omitempty"`
 
+	// 暂停同步后计划同步和手动同步都会被拒绝，直到恢复
+	SyncPaused   bool       `gorm:"not null;default:false" json:"sync_paused"`
+	SyncPausedAt *time.Time `json:"sync_paused_at,omitempty"`
+
+	// 静音后新邮件事件标记为静默，VIP发件人的邮件仍会通知
+	NotificationsMuted bool `gorm:"not null;default:false" json:"notifications_muted"`
+
 	// 统计信息
 	TotalEmails  int `gorm:"default:0" json:"total_emails"`
 	UnreadEmails int `gorm:"default:0" json:"unread_emails"`
 
+	// 跳过证书校验时的安全警告，读取账户时生成，不保存
+	TLSWarning string `gorm:"-" json:"tls_warning,omitempty"`
+
+	// 创建账户时连接失败且需要用户先在邮箱网页版完成设置时返回，不保存
+	SetupGuide *AccountSetupGuide `gorm:"-" json:"setup_guide,omitempty"`
+
 	// 关联关系
 	User    User        `gorm:"foreignKey:UserID" json:"user,omitempty"`
 	Emails  []Email     `gorm:"foreignKey:AccountID" json:"emails,omitempty"`
@@ -54,6 +102,14 @@ func (EmailAccount) TableName() string {
 	return "email_accounts"
 }
 
+// AccountSetupGuide 账户设置说明：未开启IMAP服务、未使用授权码等需要用户操作的连接失败
+type AccountSetupGuide struct {
+	Code    string   `json:"code"`               // 失败原因代码
+	Message string   `json:"message"`            // 失败原因说明
+	Steps   []string `json:"steps"`              // 分步设置说明
+	HelpURL string   `json:"help_url,omitempty"` // 提供商的帮助页面
+}
+
 // OAuth2TokenData OAuth2 token数据结构
 type OAuth2TokenData struct {
 	AccessToken  string    `json:"access_token"`
@@ -64,6 +120,90 @@ type OAuth2TokenData struct {
 	ClientID     string    `json:"client_id,omitempty"` // 用于手动OAuth2配置
 }
 
+// GetSendAliases 获取发件别名列表
+func (ea *EmailAccount) GetSendAliases() []string {
+	if ea.SendAliases == "" {
+		return []string{}
+	}
+
+	var aliases []string
+	if err := json.Unmarshal([]byte(ea.SendAliases), &aliases); err != nil {
+		return []string{}
+	}
+	return aliases
+}
+
+// SetSendAliases 设置发件别名列表
+func (ea *EmailAccount) SetSendAliases(aliases []string) error {
+	if len(aliases) == 0 {
+		ea.SendAliases = ""
+		return nil
+	}
+	data, err := json.Marshal(aliases)
+	if err != nil {
+		return err
+	}
+	ea.SendAliases = string(data)
+	return nil
+}
+
+// AfterFind 读取账户后生成TLS安全警告
+func (ea *EmailAccount) AfterFind(tx *gorm.DB) error {
+	ea.TLSWarning = ea.TLSSecurityWarning()
+	return nil
+}
+
+// TLSSecurityWarning 跳过证书校验时返回警告，固定了公钥时只提示证书链不再校验
+func (ea *EmailAccount) TLSSecurityWarning() string {
+	if !ea.TLSInsecureSkipVerify {
+		return ""
+	}
+	if len(ea.GetTLSPinnedKeys()) > 0 {
+		return "TLS certificate chain verification is disabled, only the pinned public keys are checked"
+	}
+	return "TLS certificate verification is disabled, connections to this server can be intercepted. Only use this in lab setups"
+}
+
+// GetTLSPinnedKeys 获取证书公钥指纹列表
+func (ea *EmailAccount) GetTLSPinnedKeys() []string {
+	if ea.TLSPinnedKeys == "" {
+		return nil
+	}
+
+	var pins []string
+	if err := json.Unmarshal([]byte(ea.TLSPinnedKeys), &pins); err != nil {
+		return nil
+	}
+	return pins
+}
+
+// SetTLSPinnedKeys 设置证书公钥指纹列表
+func (ea *EmailAccount) SetTLSPinnedKeys(pins []string) error {
+	if len(pins) == 0 {
+		ea.TLSPinnedKeys = ""
+		return nil
+	}
+	data, err := json.Marshal(pins)
+	if err != nil {
+		return err
+	}
+	ea.TLSPinnedKeys = string(data)
+	return nil
+}
+
+// CanSendAs 判断地址是否为账户邮箱或发件别名，忽略大小写
+func (ea *EmailAccount) CanSendAs(address string) bool {
+	if strings.EqualFold(address, ea.Email) {
+		return true
+	}
+	for _, alias := range ea.GetSendAliases() {
+		if strings.EqualFold(address, alias) {
+			return true
+		}
+	}
+	return false
+}
+
 // SetOAuth2Token 设置OAuth2 token
 func (ea *EmailAccount) SetOAuth2Token(token *OAuth2TokenData) error {
 	tokenBytes, err := json.Marshal(token)
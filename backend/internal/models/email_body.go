@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EmailBody 邮件正文，与邮件元数据分表保存，列表查询和全表扫描不读取正文
+type EmailBody struct {
+	EmailID   uint      `gorm:"primaryKey;autoIncrement:false" json:"email_id"`
+	TextBody  string    `gorm:"type:text" json:"text_body"`
+	HTMLBody  string    `gorm:"type:text" json:"html_body"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (EmailBody) TableName() string {
+	return "email_bodies"
+}
+
+// SaveEmailBody 写入邮件正文，已存在时覆盖
+func SaveEmailBody(db *gorm.DB, emailID uint, textBody, htmlBody string) error {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "email_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"text_body", "html_body", "updated_at"}),
+	}).Create(&EmailBody{EmailID: emailID, TextBody: textBody, HTMLBody: htmlBody}).Error
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// EmailEmbedding 邮件向量索引模型（用于相似度搜索）
+type EmailEmbedding struct {
+	BaseModel
+	EmailID   uint   `gorm:"not null;uniqueIndex" json:"email_id"`
+	AccountID uint   `gorm:"not null;index" json:"account_id"`
+	Model     string `gorm:"not null;size:50" json:"model"`
+	Dimension int    `gorm:"not null" json:"dimension"`
+	Vector    []byte `gorm:"type:blob;not null" json:"-"`
+}
+
+// TableName 指定表名
+func (EmailEmbedding) TableName() string {
+	return "email_embeddings"
+}
+
+// GetVector 解码向量
+func (e *EmailEmbedding) GetVector() ([]float32, error) {
+	if len(e.Vector)%4 != 0 {
+		return nil, fmt.Errorf("invalid embedding vector length: %d", len(e.Vector))
+	}
+
+	vector := make([]float32, len(e.Vector)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(e.Vector[i*4:]))
+	}
+	return vector, nil
+}
+
+// SetVector 编码向量
+func (e *EmailEmbedding) SetVector(vector []float32) {
+	buf := make([]byte, len(vector)*4)
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(v))
+	}
+	e.Vector = buf
+	e.Dimension = len(vector)
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// 邮件历史事件类型
+const (
+	EmailEventSynced        = "synced"         // 首次同步到本地
+	EmailEventRead          = "read"           // 标记为已读
+	EmailEventUnread        = "unread"         // 标记为未读
+	EmailEventStarred       = "starred"        // 加星标
+	EmailEventUnstarred     = "unstarred"      // 取消星标
+	EmailEventImportant     = "important"      // 标记为重要
+	EmailEventUnimportant   = "unimportant"    // 取消重要
+	EmailEventPinned        = "pinned"         // 置顶
+	EmailEventUnpinned      = "unpinned"       // 取消置顶
+	EmailEventMoved         = "moved"          // 移动到其他文件夹（包括归档和跨账户移动）
+	EmailEventCopied        = "copied"         // 复制到其他账户，RelatedEmailID为副本
+	EmailEventDeleted       = "deleted"        // 移入回收站
+	EmailEventRestored      = "restored"       // 从回收站恢复
+	EmailEventReplied       = "replied"        // 已回复
+	EmailEventForwarded     = "forwarded"      // 已转发
+	EmailEventLabelsChanged = "labels_changed" // 标签变化
+	EmailEventReplyLater    = "reply_later"    // 加入稍后回复
+	EmailEventReplyLaterDue = "reply_reminder" // 稍后回复到期，回到收件箱顶部
+)
+
+// 邮件历史事件来源
+const (
+	EmailEventSourceUser = "user" // 用户在本应用中的操作
+	EmailEventSourceSync = "sync" // 同步时发现的服务器端变化（如其他设备上的操作）
+)
+
+// EmailEvent 单封邮件的操作历史，用于解释邮件状态和位置的变化
+type EmailEvent struct {
+	ID           uint   `gorm:"primarykey" json:"id"`
+	UserID       uint   `gorm:"not null;index" json:"-"`
+	AccountID    uint   `gorm:"not null" json:"account_id"`
+	EmailID      uint   `gorm:"not null;index" json:"email_id"`
+	Type         string `gorm:"size:30;not null" json:"type"`
+	Source       string `gorm:"size:20;not null" json:"source"`
+	FromFolderID *uint  `json:"from_folder_id,omitempty"`
+	ToFolderID   *uint  `json:"to_folder_id,omitempty"`
+
+	// 关联邮件（如复制出的副本）
+	RelatedEmailID *uint `json:"related_email_id,omitempty"`
+
+	// 事件详情，JSON对象格式（如回复的主题和收件人、增减的标签）
+	Details string `gorm:"type:text" json:"details,omitempty"`
+
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// TableName 指定表名
+func (EmailEvent) TableName() string {
+	return "email_events"
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// EmailNote 用户附加在邮件上的私有笔记
+type EmailNote struct {
+	BaseModel
+	UserID  uint   `gorm:"not null;index" json:"-"`
+	EmailID uint   `gorm:"not null;index" json:"email_id"`
+	Content string `gorm:"type:text;not null" json:"content"`
+
+	// 是否通过IMAP ANNOTATE同步到服务器，以及最后一次成功同步的时间
+	SyncToServer bool       `gorm:"not null;default:false" json:"sync_to_server"`
+	SyncedAt     *time.Time `json:"synced_at,omitempty"`
+}
+
+// TableName 指定表名
+func (EmailNote) TableName() string {
+	return "email_notes"
+}
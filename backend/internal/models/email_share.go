@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// EmailShare 邮件分享链接，持有签名链接的任何人都可以在有效期内查看邮件的只读版本
+type EmailShare struct {
+	BaseModel
+	UserID           uint       `gorm:"not null;index" json:"-"`
+	EmailID          uint       `gorm:"not null;index" json:"email_id"`
+	AllowAttachments bool       `gorm:"not null;default:false" json:"allow_attachments"` // 是否允许下载非内联附件
+	ExpiresAt        time.Time  `gorm:"not null;index" json:"expires_at"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+
+	// 访问统计
+	AccessCount    int        `gorm:"not null;default:0" json:"access_count"`
+	LastAccessedAt *time.Time `json:"last_accessed_at,omitempty"`
+
+	// 关联关系
+	Email Email `gorm:"foreignKey:EmailID" json:"-"`
+}
+
+// TableName 指定表名
+func (EmailShare) TableName() string {
+	return "email_shares"
+}
+
+// IsActive 链接未撤销且未过期
+func (s *EmailShare) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"net/mail"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"firemail/internal/sanitize"
+)
+
+// EmailPreviewLength 预览文本的最大字符数
+const EmailPreviewLength = 200
+
+// FillSummary 根据正文、发件人和附件生成列表摘要
+func (e *Email) FillSummary() {
+	preview := BuildEmailPreview(e.TextBody, e.HTMLBody)
+	e.Preview = &preview
+	e.SenderInitials, e.SenderAvatarHash = SenderAvatar(e.From)
+	if e.AttachmentCount == 0 && len(e.Attachments) > 0 {
+		e.AttachmentCount = len(e.Attachments)
+	}
+}
+
+// BuildEmailPreview 生成预览文本：优先使用纯文本正文，跳过引用行，没有纯文本时从HTML提取
+func BuildEmailPreview(textBody, htmlBody string) string {
+	var text string
+	if strings.TrimSpace(textBody) != "" {
+		lines := strings.Split(textBody, "\n")
+		kept := lines[:0]
+		for _, line := range lines {
+			if !strings.HasPrefix(strings.TrimSpace(line), ">") {
+				kept = append(kept, line)
+			}
+		}
+		text = strings.Join(strings.Fields(strings.Join(kept, " ")), " ")
+	} else if htmlBody != "" {
+		text = sanitize.Text(htmlBody)
+	}
+
+	if utf8.RuneCountInString(text) <= EmailPreviewLength {
+		return text
+	}
+	return string([]rune(text)[:EmailPreviewLength])
+}
+
+// SenderAvatar 从发件人生成头像信息：姓名或地址的首字母（最多两个），以及小写地址的MD5
+func SenderAvatar(from string) (string, string) {
+	name, address := from, ""
+	if parsed, err := mail.ParseAddress(from); err == nil {
+		name, address = parsed.Name, parsed.Address
+	} else if strings.Contains(from, "@") {
+		name, address = "", strings.TrimSpace(from)
+	}
+	address = strings.ToLower(strings.TrimSpace(address))
+
+	hash := ""
+	if address != "" {
+		sum := md5.Sum([]byte(address))
+		hash = hex.EncodeToString(sum[:])
+	}
+
+	if strings.TrimSpace(name) == "" {
+		name = address
+		if at := strings.Index(name, "@"); at >= 0 {
+			name = name[:at]
+		}
+	}
+	return initials(name), hash
+}
+
+// initials 取名字中前两个单词的首字母；中日韩等不以空格分词的名字只取第一个字
+func initials(name string) string {
+	var result []rune
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '.' || r == '_' || r == '-'
+	}) {
+		first, _ := utf8.DecodeRuneInString(word)
+		if !unicode.IsLetter(first) && !unicode.IsDigit(first) {
+			continue
+		}
+		if unicode.Is(unicode.Han, first) || unicode.Is(unicode.Hangul, first) ||
+			unicode.Is(unicode.Hiragana, first) || unicode.Is(unicode.Katakana, first) {
+			if len(result) == 0 {
+				return string(first)
+			}
+			break
+		}
+		result = append(result, unicode.ToUpper(first))
+		if len(result) == 2 {
+			break
+		}
+	}
+	return string(result)
+}
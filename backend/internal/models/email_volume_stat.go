@@ -0,0 +1,16 @@
+package models
+
+// EmailVolumeStat 按账户和小时汇总的收发邮件数，同步新邮件时增量维护，供统计分析使用
+type EmailVolumeStat struct {
+	ID        uint  `gorm:"primarykey" json:"-"`
+	UserID    uint  `gorm:"not null;index" json:"user_id"`
+	AccountID uint  `gorm:"not null;uniqueIndex:idx_email_volume_stats_account_hour" json:"account_id"`
+	Hour      int64 `gorm:"not null;uniqueIndex:idx_email_volume_stats_account_hour" json:"hour"` // UTC整点的Unix小时数（Unix秒/3600）
+	Received  int   `gorm:"not null;default:0" json:"received"`
+	Sent      int   `gorm:"not null;default:0" json:"sent"`
+}
+
+// TableName 指定表名
+func (EmailVolumeStat) TableName() string {
+	return "email_volume_stats"
+}
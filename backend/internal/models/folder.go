@@ -6,7 +6,8 @@ type Folder struct {
 	AccountID   uint   `gorm:"not null;index" json:"account_id"`
 	Name        string `gorm:"not null;size:100" json:"name"`
 	DisplayName string `gorm:"size:100" json:"display_name"`
-	Type        string `gorm:"not null;size:20" json:"type"` // inbox, sent, drafts, trash, spam, custom
+	Type        string `gorm:"not null;size:20" json:"type"`              // inbox, sent, drafts, trash, spam, archive, custom
+	TypeLocked  bool   `gorm:"not null;default:false" json:"type_locked"` // 类型由用户手动指定，同步时不再按服务器属性和名称检测
 	ParentID    *uint  `gorm:"index" json:"parent_id,omitempty"`
 	Path        string `gorm:"size:500" json:"path"`     // IMAP文件夹路径
 	Delimiter   string `gorm:"size:10" json:"delimiter"` // IMAP路径分隔符
@@ -15,6 +16,16 @@ type Folder struct {
 	IsSelectable bool `gorm:"not null;default:true" json:"is_selectable"`
 	IsSubscribed bool `gorm:"not null;default:true" json:"is_subscribed"`
 
+	// Gmail标签映射方式：folder、merge或skip，为空时使用默认方式，仅对Gmail账户生效
+	LabelMode string `gorm:"size:10;not null;default:''" json:"label_mode,omitempty"`
+	// 合并方式的文件夹已处理到的最大UID，合并的邮件不保存在该文件夹中，增量同步以此为起点
+	LastMergedUID uint32 `gorm:"column:last_merged_uid;not null;default:0" json:"-"`
+
+	// 显示属性，保存在服务端使同一账户的各个客户端显示一致
+	Color     string `gorm:"size:20" json:"color"`                       // 十六进制颜色，如 #1a73e8
+	Icon      string `gorm:"size:50" json:"icon"`                        // 图标名称
+	SortOrder int    `gorm:"not null;default:0;index" json:"sort_order"` // 显示顺序，相同时按类型和名称排序
+
 	// 统计信息
 	TotalEmails  int `gorm:"default:0" json:"total_emails"`
 	UnreadEmails int `gorm:"default:0" json:"unread_emails"`
@@ -37,12 +48,20 @@ func (Folder) TableName() string {
 
 // FolderType 文件夹类型常量
 const (
-	FolderTypeInbox  = "inbox"
-	FolderTypeSent   = "sent"
-	FolderTypeDrafts = "drafts"
-	FolderTypeTrash  = "trash"
-	FolderTypeSpam   = "spam"
-	FolderTypeCustom = "custom"
+	FolderTypeInbox   = "inbox"
+	FolderTypeSent    = "sent"
+	FolderTypeDrafts  = "drafts"
+	FolderTypeTrash   = "trash"
+	FolderTypeSpam    = "spam"
+	FolderTypeArchive = "archive"
+	FolderTypeCustom  = "custom"
+)
+
+// Gmail标签映射方式
+const (
+	FolderLabelModeFolder = "folder" // 标签作为独立文件夹，邮件在其中单独显示
+	FolderLabelModeMerge  = "merge"  // 标签合并到邮件的标签列表，不重复保存已在其他文件夹中的邮件
+	FolderLabelModeSkip   = "skip"   // 不同步该标签
 )
 
 // IsSystemFolder 检查是否为系统文件夹
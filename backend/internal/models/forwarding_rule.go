@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// 自动转发的方式
+const (
+	ForwardingModeInline     = "inline"     // 原邮件正文引用在转发邮件中，附件一并转发
+	ForwardingModeAttachment = "attachment" // 原邮件作为message/rfc822附件转发
+)
+
+// ForwardingRule 自动转发规则：账户收到的匹配邮件通过该账户的SMTP自动转发到外部地址
+type ForwardingRule struct {
+	ID              uint       `gorm:"primarykey" json:"id"`
+	UserID          uint       `gorm:"not null;index" json:"-"`
+	AccountID       uint       `gorm:"not null;index" json:"account_id"`
+	Name            string     `gorm:"size:100;not null" json:"name"`
+	ForwardTo       string     `gorm:"size:255;not null" json:"forward_to"`
+	Mode            string     `gorm:"size:20;not null;default:inline" json:"mode"` // inline, attachment
+	FromContains    string     `gorm:"size:255" json:"from_contains,omitempty"`     // 为空时不限制发件人
+	SubjectContains string     `gorm:"size:255" json:"subject_contains,omitempty"`  // 为空时不限制主题
+	IsEnabled       bool       `gorm:"not null;default:true" json:"is_enabled"`
+	ForwardedCount  int        `gorm:"not null;default:0" json:"forwarded_count"`
+	FailedCount     int        `gorm:"not null;default:0" json:"failed_count"`
+	LoopCount       int        `gorm:"not null;default:0" json:"loop_count"` // 因转发环路跳过的邮件数
+	LastForwardedAt *time.Time `json:"last_forwarded_at,omitempty"`
+	LastError       string     `gorm:"type:text" json:"last_error,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (ForwardingRule) TableName() string {
+	return "forwarding_rules"
+}
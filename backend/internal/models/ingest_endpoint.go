@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// IngestEndpoint 入站邮件端点：持有令牌的外部服务可将邮件写入指定的虚拟账户文件夹
+type IngestEndpoint struct {
+	ID            uint       `gorm:"primarykey" json:"id"`
+	UserID        uint       `gorm:"not null;index" json:"-"`
+	AccountID     uint       `gorm:"not null;index" json:"account_id"`
+	FolderID      uint       `gorm:"not null" json:"folder_id"`
+	Name          string     `gorm:"size:100;not null" json:"name"`
+	Address       *string    `gorm:"size:255;uniqueIndex" json:"address,omitempty"` // 内置SMTP服务器的收件地址，以@开头时接收整个域名
+	TokenHash     string     `gorm:"size:64;not null;uniqueIndex" json:"-"`         // 令牌的SHA-256，令牌本身只在创建时返回
+	TokenPrefix   string     `gorm:"size:16;not null" json:"token_prefix"`          // 令牌开头几位，便于识别
+	IsEnabled     bool       `gorm:"not null;default:true" json:"is_enabled"`
+	ReceivedCount int        `gorm:"not null;default:0" json:"received_count"`
+	LastUsedAt    *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (IngestEndpoint) TableName() string {
+	return "ingest_endpoints"
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// 后台任务状态
+const (
+	JobStatusPending   = "pending"   // 等待执行，或失败后等待重试
+	JobStatusRunning   = "running"   // 已被工作进程认领
+	JobStatusSucceeded = "succeeded" // 执行成功
+	JobStatusDead      = "dead"      // 尝试次数用尽，进入死信等待人工处理
+	JobStatusCancelled = "cancelled" // 已取消
+)
+
+// Job 持久化的后台任务，由任意进程入队、工作进程认领执行
+type Job struct {
+	ID          uint       `gorm:"primarykey" json:"id"`
+	Type        string     `gorm:"size:100;not null;index" json:"type"`
+	Payload     string     `gorm:"type:text" json:"payload"` // JSON格式的任务参数
+	UserID      uint       `gorm:"index" json:"user_id,omitempty"`
+	UniqueKey   string     `gorm:"size:255;index" json:"unique_key,omitempty"` // 同一键的任务未结束时不重复入队
+	Status      string     `gorm:"size:20;not null;default:'pending';index:idx_jobs_status_run_at" json:"status"`
+	Attempts    int        `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts int        `gorm:"not null;default:5" json:"max_attempts"`
+	RunAt       time.Time  `gorm:"not null;index:idx_jobs_status_run_at" json:"run_at"` // 最早执行时间，重试时推迟
+	LockedBy    string     `gorm:"size:255" json:"locked_by,omitempty"`
+	LockedAt    *time.Time `json:"locked_at,omitempty"`
+	LastError   string     `gorm:"type:text" json:"last_error,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Job) TableName() string {
+	return "jobs"
+}
+
+// IsFinished 任务是否已结束（成功、死信或已取消）
+func (j *Job) IsFinished() bool {
+	return j.Status == JobStatusSucceeded || j.Status == JobStatusDead || j.Status == JobStatusCancelled
+}
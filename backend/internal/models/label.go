@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Label 邮件标签的显示属性。标签本身保存在邮件的 labels 字段中，这里只记录颜色、图标和显示顺序
+type Label struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	AccountID uint      `gorm:"not null;uniqueIndex:idx_labels_account_name" json:"account_id"`
+	Name      string    `gorm:"size:100;not null;uniqueIndex:idx_labels_account_name" json:"name"`
+	Color     string    `gorm:"size:20" json:"color"`
+	Icon      string    `gorm:"size:50" json:"icon"`
+	SortOrder int       `gorm:"not null;default:0" json:"sort_order"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Label) TableName() string {
+	return "labels"
+}
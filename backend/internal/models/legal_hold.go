@@ -0,0 +1,102 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 法律保全导出状态
+const (
+	LegalHoldExportStatusRunning   = "running"
+	LegalHoldExportStatusCompleted = "completed"
+	LegalHoldExportStatusFailed    = "failed"
+)
+
+// LegalHold 法律保全：符合发件人和日期条件的邮件在解除前不能被删除
+type LegalHold struct {
+	ID          uint       `gorm:"primarykey" json:"id"`
+	UserID      uint       `gorm:"not null;index" json:"-"`
+	Name        string     `gorm:"size:100;not null" json:"name"`
+	Description string     `gorm:"type:text" json:"description"`
+	Senders     string     `gorm:"type:text" json:"senders"`     // JSON数组格式，小写邮箱地址或以@开头的域名；为空时不限发件人
+	AccountIDs  string     `gorm:"type:text" json:"account_ids"` // JSON数组格式；为空时适用于所有账户
+	Since       *time.Time `json:"since,omitempty"`              // 邮件日期下限（含）
+	Until       *time.Time `json:"until,omitempty"`              // 邮件日期上限（不含）
+	ReleasedAt  *time.Time `gorm:"index" json:"released_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (LegalHold) TableName() string {
+	return "legal_holds"
+}
+
+// IsActive 保全是否仍然生效
+func (h *LegalHold) IsActive() bool {
+	return h.ReleasedAt == nil
+}
+
+// GetSenders 获取发件人条件
+func (h *LegalHold) GetSenders() ([]string, error) {
+	senders := []string{}
+	if h.Senders == "" {
+		return senders, nil
+	}
+	err := json.Unmarshal([]byte(h.Senders), &senders)
+	return senders, err
+}
+
+// SetSenders 设置发件人条件
+func (h *LegalHold) SetSenders(senders []string) error {
+	data, err := json.Marshal(senders)
+	if err != nil {
+		return err
+	}
+	h.Senders = string(data)
+	return nil
+}
+
+// GetAccountIDs 获取账户条件
+func (h *LegalHold) GetAccountIDs() ([]uint, error) {
+	accountIDs := []uint{}
+	if h.AccountIDs == "" {
+		return accountIDs, nil
+	}
+	err := json.Unmarshal([]byte(h.AccountIDs), &accountIDs)
+	return accountIDs, err
+}
+
+// SetAccountIDs 设置账户条件
+func (h *LegalHold) SetAccountIDs(accountIDs []uint) error {
+	data, err := json.Marshal(accountIDs)
+	if err != nil {
+		return err
+	}
+	h.AccountIDs = string(data)
+	return nil
+}
+
+// LegalHoldExport 法律保全的一次导出，压缩包中包含邮件原文和带哈希的清单
+type LegalHoldExport struct {
+	ID             uint       `gorm:"primarykey" json:"id"`
+	HoldID         uint       `gorm:"not null;index" json:"hold_id"`
+	UserID         uint       `gorm:"not null;index" json:"-"`
+	Status         string     `gorm:"size:20;not null" json:"status"` // running, completed, failed
+	TotalEmails    int        `gorm:"not null;default:0" json:"total_emails"`
+	ExportedEmails int        `gorm:"not null;default:0" json:"exported_emails"`
+	LocalEmails    int        `gorm:"not null;default:0" json:"local_emails"` // 无法从服务器获取原文、导出本地副本的邮件数
+	FilePath       string     `gorm:"size:500" json:"-"`
+	FileSize       int64      `gorm:"not null;default:0" json:"file_size"`
+	ArchiveSHA256  string     `gorm:"column:archive_sha256;size:64" json:"archive_sha256,omitempty"`
+	ManifestSHA256 string     `gorm:"column:manifest_sha256;size:64" json:"manifest_sha256,omitempty"`
+	Signature      string     `gorm:"size:64" json:"signature,omitempty"` // 清单的HMAC-SHA256签名
+	LastError      string     `gorm:"type:text" json:"last_error,omitempty"`
+	StartedAt      time.Time  `json:"started_at"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty"`
+}
+
+// TableName 指定表名
+func (LegalHoldExport) TableName() string {
+	return "legal_hold_exports"
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 代收外部邮箱使用的协议
+const (
+	MailFetcherProtocolPOP3 = "pop3"
+	MailFetcherProtocolIMAP = "imap"
+)
+
+// MailFetcher 代收外部邮箱：定期从外部POP3/IMAP邮箱收取邮件，写入已有账户的文件夹（类似Gmail的"从其他账户查收邮件"）
+type MailFetcher struct {
+	ID               uint       `gorm:"primarykey" json:"id"`
+	UserID           uint       `gorm:"not null;index" json:"-"`
+	AccountID        uint       `gorm:"not null;index" json:"account_id"` // 邮件写入的账户
+	FolderID         uint       `gorm:"not null" json:"folder_id"`        // 邮件写入的文件夹
+	Name             string     `gorm:"size:100;not null" json:"name"`
+	Protocol         string     `gorm:"size:10;not null" json:"protocol"` // pop3, imap
+	Host             string     `gorm:"size:255;not null" json:"host"`
+	Port             int        `gorm:"not null" json:"port"`
+	Security         string     `gorm:"size:20;not null;default:SSL" json:"security"` // SSL, TLS, STARTTLS, NONE
+	Username         string     `gorm:"size:255;not null" json:"username"`
+	Password         string     `gorm:"size:255" json:"-"`
+	SourceFolder     string     `gorm:"size:255;not null;default:INBOX" json:"source_folder"` // 只用于IMAP
+	DeleteAfterFetch bool       `gorm:"not null;default:false" json:"delete_after_fetch"`     // 收取后从外部邮箱删除
+	Label            string     `gorm:"size:100" json:"label,omitempty"`                      // 收取的邮件附加的标签
+	IntervalMinutes  int        `gorm:"not null;default:10" json:"interval_minutes"`
+	IsEnabled        bool       `gorm:"not null;default:true" json:"is_enabled"`
+	UIDValidity      uint32     `gorm:"not null;default:0" json:"-"` // IMAP断点
+	LastUID          uint32     `gorm:"not null;default:0" json:"-"`
+	FetchedUIDLs     string     `gorm:"column:fetched_uidls;type:text" json:"-"` // POP3保留邮件时已收取邮件的UIDL（JSON数组）
+	FetchedCount     int        `gorm:"not null;default:0" json:"fetched_count"`
+	LastRunAt        *time.Time `json:"last_run_at,omitempty"`
+	LastError        string     `gorm:"type:text" json:"last_error,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (MailFetcher) TableName() string {
+	return "mail_fetchers"
+}
+
+// GetFetchedUIDLs 已收取邮件的UIDL
+func (f *MailFetcher) GetFetchedUIDLs() []string {
+	if f.FetchedUIDLs == "" {
+		return nil
+	}
+	var uidls []string
+	if err := json.Unmarshal([]byte(f.FetchedUIDLs), &uidls); err != nil {
+		return nil
+	}
+	return uidls
+}
+
+// SetFetchedUIDLs 设置已收取邮件的UIDL
+func (f *MailFetcher) SetFetchedUIDLs(uidls []string) error {
+	if len(uidls) == 0 {
+		f.FetchedUIDLs = ""
+		return nil
+	}
+	data, err := json.Marshal(uidls)
+	if err != nil {
+		return err
+	}
+	f.FetchedUIDLs = string(data)
+	return nil
+}
@@ -0,0 +1,150 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 邮件合并活动状态
+const (
+	MailMergeStatusDraft     = "draft"
+	MailMergeStatusRunning   = "running"
+	MailMergeStatusPaused    = "paused"
+	MailMergeStatusCompleted = "completed"
+	MailMergeStatusCancelled = "cancelled"
+)
+
+// 邮件合并收件人状态
+const (
+	MailMergeRecipientPending   = "pending"
+	MailMergeRecipientSending   = "sending"
+	MailMergeRecipientSent      = "sent"
+	MailMergeRecipientFailed    = "failed"
+	MailMergeRecipientSkipped   = "skipped"
+	MailMergeRecipientCancelled = "cancelled"
+)
+
+// MailMergeCampaign 邮件合并活动模型
+type MailMergeCampaign struct {
+	BaseModel
+	UserID     uint   `gorm:"not null;index" json:"user_id"`
+	AccountID  uint   `gorm:"not null;index" json:"account_id"`
+	TemplateID uint   `gorm:"not null;index" json:"template_id"`
+	Name       string `gorm:"size:200;not null" json:"name"`
+	Status     string `gorm:"size:20;not null;default:'draft';index" json:"status"` // draft, running, paused, completed, cancelled
+
+	// 发送节流（每分钟最多发送数）
+	RatePerMinute int `gorm:"not null;default:30" json:"rate_per_minute"`
+
+	// 是否使用VERP信封发件人，退信地址中编码收件人，便于识别退信对应的收件人
+	UseVERP bool `gorm:"column:use_verp;default:false" json:"use_verp"`
+
+	// 打开和点击跟踪，需显式开启；不跟踪名单中的收件人自动排除
+	TrackOpens  bool `gorm:"default:false" json:"track_opens"`
+	TrackClicks bool `gorm:"default:false" json:"track_clicks"`
+
+	// 统计信息
+	TotalRecipients int `gorm:"default:0" json:"total_recipients"`
+	SentCount       int `gorm:"default:0" json:"sent_count"`
+	FailedCount     int `gorm:"default:0" json:"failed_count"`
+	SkippedCount    int `gorm:"default:0" json:"skipped_count"`
+	OpenedCount     int `gorm:"default:0" json:"opened_count"`  // 至少打开过一次的收件人数
+	ClickedCount    int `gorm:"default:0" json:"clicked_count"` // 至少点击过一次的收件人数
+
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	LastError   string     `gorm:"type:text" json:"last_error,omitempty"`
+
+	// 关联关系
+	User     User          `gorm:"foreignKey:UserID" json:"-"`
+	Account  EmailAccount  `gorm:"foreignKey:AccountID" json:"-"`
+	Template EmailTemplate `gorm:"foreignKey:TemplateID" json:"-"`
+}
+
+// TableName 指定表名
+func (MailMergeCampaign) TableName() string {
+	return "mail_merge_campaigns"
+}
+
+// IsFinished 活动是否已结束
+func (c *MailMergeCampaign) IsFinished() bool {
+	return c.Status == MailMergeStatusCompleted || c.Status == MailMergeStatusCancelled
+}
+
+// MailMergeRecipient 邮件合并收件人模型
+type MailMergeRecipient struct {
+	BaseModel
+	CampaignID uint       `gorm:"not null;index" json:"campaign_id"`
+	LineNumber int        `gorm:"not null" json:"line_number"` // CSV中的行号（含表头）
+	Email      string     `gorm:"size:255;not null" json:"email"`
+	Name       string     `gorm:"size:255" json:"name"`
+	Variables  string     `gorm:"type:text" json:"variables"` // JSON格式的模板变量
+	Status     string     `gorm:"size:20;not null;default:'pending';index" json:"status"`
+	Error      string     `gorm:"type:text" json:"error,omitempty"`
+	SendID     string     `gorm:"size:100" json:"send_id,omitempty"`
+	SentAt     *time.Time `json:"sent_at,omitempty"`
+
+	// 跟踪统计
+	TrackingToken    string     `gorm:"size:64;index" json:"-"`
+	TrackingExcluded bool       `gorm:"default:false" json:"tracking_excluded"` // 命中不跟踪名单
+	OpenCount        int        `gorm:"default:0" json:"open_count"`
+	ClickCount       int        `gorm:"default:0" json:"click_count"`
+	FirstOpenedAt    *time.Time `json:"first_opened_at,omitempty"`
+	FirstClickedAt   *time.Time `json:"first_clicked_at,omitempty"`
+}
+
+// TableName 指定表名
+func (MailMergeRecipient) TableName() string {
+	return "mail_merge_recipients"
+}
+
+// GetVariables 获取模板变量
+func (r *MailMergeRecipient) GetVariables() (map[string]interface{}, error) {
+	variables := make(map[string]interface{})
+	if r.Variables == "" {
+		return variables, nil
+	}
+
+	if err := json.Unmarshal([]byte(r.Variables), &variables); err != nil {
+		return nil, err
+	}
+
+	return variables, nil
+}
+
+// SetVariables 设置模板变量
+func (r *MailMergeRecipient) SetVariables(variables map[string]interface{}) error {
+	data, err := json.Marshal(variables)
+	if err != nil {
+		return err
+	}
+	r.Variables = string(data)
+	return nil
+}
+
+// MailMergeLink 活动邮件中被改写为跟踪链接的原始链接
+type MailMergeLink struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	CampaignID uint      `gorm:"not null;index" json:"campaign_id"`
+	URL        string    `gorm:"type:text;not null" json:"url"`
+	ClickCount int       `gorm:"default:0" json:"click_count"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (MailMergeLink) TableName() string {
+	return "mail_merge_links"
+}
+
+// TrackingOptOut 不跟踪名单，活动发给这些地址或域名时不插入跟踪像素，也不改写链接
+type TrackingOptOut struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_tracking_opt_outs_user_pattern" json:"-"`
+	Pattern   string    `gorm:"size:255;not null;uniqueIndex:idx_tracking_opt_outs_user_pattern" json:"pattern"` // 小写邮箱地址，或以@开头的域名（同时匹配子域名）
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (TrackingOptOut) TableName() string {
+	return "tracking_opt_outs"
+}
@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// 邮箱迁移状态
+const (
+	MailboxMigrationStatusRunning   = "running"
+	MailboxMigrationStatusPaused    = "paused"
+	MailboxMigrationStatusCompleted = "completed"
+	MailboxMigrationStatusFailed    = "failed"
+	MailboxMigrationStatusCancelled = "cancelled"
+)
+
+// 迁移中单个文件夹的状态
+const (
+	MailboxMigrationFolderPending   = "pending"
+	MailboxMigrationFolderCompleted = "completed"
+)
+
+// MailboxMigration 邮箱迁移任务，将一个账户的全部邮件按文件夹映射复制到另一个账户
+type MailboxMigration struct {
+	BaseModel
+	UserID          uint   `gorm:"not null;index" json:"user_id"`
+	SourceAccountID uint   `gorm:"not null;index" json:"source_account_id"`
+	TargetAccountID uint   `gorm:"not null;index" json:"target_account_id"`
+	Status          string `gorm:"size:20;not null;default:'running';index" json:"status"` // running, paused, completed, failed, cancelled
+
+	// 统计信息
+	TotalEmails  int `gorm:"default:0" json:"total_emails"`
+	CopiedEmails int `gorm:"default:0" json:"copied_emails"`
+	FailedEmails int `gorm:"default:0" json:"failed_emails"`
+
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	VerifiedAt  *time.Time `json:"verified_at,omitempty"` // 完成后核对各文件夹邮件数的时间
+	LastError   string     `gorm:"type:text" json:"last_error,omitempty"`
+
+	// 关联关系
+	Folders []MailboxMigrationFolder `gorm:"foreignKey:MigrationID" json:"folders,omitempty"`
+}
+
+// TableName 指定表名
+func (MailboxMigration) TableName() string {
+	return "mailbox_migrations"
+}
+
+// IsFinished 迁移是否已结束
+func (m *MailboxMigration) IsFinished() bool {
+	return m.Status == MailboxMigrationStatusCompleted || m.Status == MailboxMigrationStatusCancelled
+}
+
+// MailboxMigrationFolder 迁移的文件夹映射，LastUID为断点，中断后从下一封邮件继续
+type MailboxMigrationFolder struct {
+	BaseModel
+	MigrationID uint   `gorm:"not null;index" json:"migration_id"`
+	SourcePath  string `gorm:"size:255;not null" json:"source_path"`
+	TargetPath  string `gorm:"size:255;not null" json:"target_path"`
+	SortOrder   int    `gorm:"not null;default:0" json:"sort_order"`
+	Status      string `gorm:"size:20;not null;default:'pending'" json:"status"` // pending, completed
+
+	// 断点：源文件夹UIDVALIDITY变化后UID不再可比，需要从头复制
+	UIDValidity uint32 `gorm:"column:uid_validity;not null;default:0" json:"uid_validity"`
+	LastUID     uint32 `gorm:"column:last_uid;not null;default:0" json:"last_uid"`
+
+	// 统计信息
+	TotalEmails  int `gorm:"default:0" json:"total_emails"`
+	CopiedEmails int `gorm:"default:0" json:"copied_emails"`
+	FailedEmails int `gorm:"default:0" json:"failed_emails"`
+
+	// 校验结果：迁移完成后两端服务器上的邮件数
+	SourceCount *int   `json:"source_count,omitempty"`
+	TargetCount *int   `json:"target_count,omitempty"`
+	LastError   string `gorm:"type:text" json:"last_error,omitempty"`
+}
+
+// TableName 指定表名
+func (MailboxMigrationFolder) TableName() string {
+	return "mailbox_migration_folders"
+}
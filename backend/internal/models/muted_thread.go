@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// MutedThread 用户静音的会话，会话中后续收到的邮件自动标记为已读且不发送通知
+type MutedThread struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_muted_threads_user_thread" json:"-"`
+	ThreadID  string    `gorm:"size:255;not null;uniqueIndex:idx_muted_threads_user_thread" json:"thread_id"`
+	Subject   string    `gorm:"size:500" json:"subject"` // 静音时所选邮件的主题，便于在列表中识别
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (MutedThread) TableName() string {
+	return "muted_threads"
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// 通知级别
+const (
+	NotificationLevelInfo    = "info"
+	NotificationLevelSuccess = "success"
+	NotificationLevelWarning = "warning"
+	NotificationLevelError   = "error"
+)
+
+// Notification 通知中心中保存的通知，SSE推送时用户不在线也能稍后查看
+type Notification struct {
+	ID        uint   `gorm:"primarykey" json:"id"`
+	UserID    uint   `gorm:"not null;index:idx_notifications_user_read" json:"-"`
+	AccountID *uint  `json:"account_id,omitempty"`
+	EventType string `gorm:"size:50;not null" json:"event_type"` // 产生通知的SSE事件类型
+	Level     string `gorm:"size:20;not null" json:"level"`      // info, success, warning, error
+	Title     string `gorm:"size:255;not null" json:"title"`
+	Message   string `gorm:"type:text" json:"message,omitempty"`
+
+	// 事件数据，JSON对象格式（如邮件ID、发送ID），客户端据此跳转
+	Data string `gorm:"type:text" json:"data,omitempty"`
+
+	IsRead    bool       `gorm:"not null;default:false;index:idx_notifications_user_read" json:"is_read"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `gorm:"index" json:"created_at"`
+}
+
+// TableName 指定表名
+func (Notification) TableName() string {
+	return "notifications"
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// NotificationActionToken 通知快捷操作令牌：随新邮件通知下发，凭令牌可免登录执行一次指定操作
+type NotificationActionToken struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	EmailID   uint       `gorm:"not null;index" json:"email_id"`
+	Action    string     `gorm:"size:20;not null" json:"action"`
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex" json:"-"` // 令牌的SHA-256，令牌本身只随通知下发
+	ExpiresAt time.Time  `gorm:"not null;index" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName 指定表名
+func (NotificationActionToken) TableName() string {
+	return "notification_action_tokens"
+}
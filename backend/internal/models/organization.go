@@ -0,0 +1,129 @@
+package models
+
+import "time"
+
+// 组织成员角色
+const (
+	OrganizationRoleOwner  = "owner"  // 创建者，可以删除组织
+	OrganizationRoleAdmin  = "admin"  // 邀请成员、共享邮箱、分配权限和邮件
+	OrganizationRoleMember = "member" // 只能访问被授权的共享邮箱
+)
+
+// 邀请状态
+const (
+	OrganizationInvitePending  = "pending"
+	OrganizationInviteAccepted = "accepted"
+	OrganizationInviteDeclined = "declined"
+	OrganizationInviteRevoked  = "revoked"
+)
+
+// 共享邮箱权限
+const (
+	SharedMailboxReadOnly = "read_only" // 查看邮件、认领和分配
+	SharedMailboxSendAs   = "send_as"   // 另外可以用该账户发信
+)
+
+// Organization 组织：成员之间共享邮箱账户
+type Organization struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	Name      string    `gorm:"size:100;not null" json:"name"`
+	CreatedBy uint      `gorm:"not null" json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Organization) TableName() string {
+	return "organizations"
+}
+
+// OrganizationMember 组织成员
+type OrganizationMember struct {
+	ID             uint      `gorm:"primarykey" json:"id"`
+	OrganizationID uint      `gorm:"not null;uniqueIndex:idx_organization_members_org_user" json:"organization_id"`
+	UserID         uint      `gorm:"not null;uniqueIndex:idx_organization_members_org_user;index" json:"user_id"`
+	Role           string    `gorm:"size:20;not null" json:"role"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (OrganizationMember) TableName() string {
+	return "organization_members"
+}
+
+// IsAdmin 是否可以管理组织
+func (m *OrganizationMember) IsAdmin() bool {
+	return m.Role == OrganizationRoleOwner || m.Role == OrganizationRoleAdmin
+}
+
+// OrganizationInvite 组织邀请，被邀请的用户接受后成为成员
+type OrganizationInvite struct {
+	ID             uint       `gorm:"primarykey" json:"id"`
+	OrganizationID uint       `gorm:"not null;index" json:"organization_id"`
+	InviteeID      uint       `gorm:"not null;index" json:"invitee_id"`
+	InvitedBy      uint       `gorm:"not null" json:"invited_by"`
+	Role           string     `gorm:"size:20;not null" json:"role"`
+	Status         string     `gorm:"size:20;not null;default:'pending'" json:"status"`
+	ExpiresAt      time.Time  `gorm:"not null" json:"expires_at"`
+	RespondedAt    *time.Time `json:"responded_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+
+	// 关联关系
+	Organization *Organization `gorm:"foreignKey:OrganizationID" json:"organization,omitempty"`
+	Invitee      *User         `gorm:"foreignKey:InviteeID" json:"invitee,omitempty"`
+}
+
+// TableName 指定表名
+func (OrganizationInvite) TableName() string {
+	return "organization_invites"
+}
+
+// SharedMailbox 共享到组织的邮箱账户，账户仍属于共享者，同步和设置不变
+type SharedMailbox struct {
+	ID             uint      `gorm:"primarykey" json:"id"`
+	OrganizationID uint      `gorm:"not null;index" json:"organization_id"`
+	AccountID      uint      `gorm:"not null;uniqueIndex" json:"account_id"` // 一个账户只能共享到一个组织
+	SharedBy       uint      `gorm:"not null" json:"shared_by"`
+	CreatedAt      time.Time `json:"created_at"`
+
+	// 关联关系
+	Account *EmailAccount        `gorm:"foreignKey:AccountID" json:"account,omitempty"`
+	Grants  []SharedMailboxGrant `gorm:"foreignKey:SharedMailboxID" json:"grants,omitempty"`
+}
+
+// TableName 指定表名
+func (SharedMailbox) TableName() string {
+	return "shared_mailboxes"
+}
+
+// SharedMailboxGrant 成员对共享邮箱的权限
+type SharedMailboxGrant struct {
+	ID              uint      `gorm:"primarykey" json:"id"`
+	SharedMailboxID uint      `gorm:"not null;uniqueIndex:idx_shared_mailbox_grants_mailbox_user" json:"shared_mailbox_id"`
+	UserID          uint      `gorm:"not null;uniqueIndex:idx_shared_mailbox_grants_mailbox_user;index" json:"user_id"`
+	Permission      string    `gorm:"size:20;not null" json:"permission"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (SharedMailboxGrant) TableName() string {
+	return "shared_mailbox_grants"
+}
+
+// EmailAssignment 共享邮箱中邮件的认领/分配，每封邮件同时只有一个负责人
+type EmailAssignment struct {
+	ID              uint      `gorm:"primarykey" json:"id"`
+	SharedMailboxID uint      `gorm:"not null;index" json:"shared_mailbox_id"`
+	EmailID         uint      `gorm:"not null;uniqueIndex" json:"email_id"`
+	AssigneeID      uint      `gorm:"not null;index" json:"assignee_id"`
+	AssignedBy      uint      `gorm:"not null" json:"assigned_by"` // 与负责人相同时表示自行认领
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (EmailAssignment) TableName() string {
+	return "email_assignments"
+}
@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+// 保留规则对过期邮件的处理方式
+const (
+	RetentionActionDelete  = "delete"  // 移入回收站
+	RetentionActionArchive = "archive" // 移动到归档文件夹
+)
+
+// 保留规则执行状态
+const (
+	RetentionRunStatusRunning   = "running"
+	RetentionRunStatusCompleted = "completed"
+	RetentionRunStatusFailed    = "failed"
+	RetentionRunStatusCancelled = "cancelled"
+)
+
+// 保留规则执行的触发方式
+const (
+	RetentionTriggerScheduled = "scheduled"
+	RetentionTriggerManual    = "manual"
+)
+
+// RetentionPolicy 账户或文件夹的邮件保留规则，例如回收站邮件30天后删除、订阅邮件6个月后归档。
+// 定时任务每天执行一次，星标和置顶的邮件不受影响
+type RetentionPolicy struct {
+	ID            uint       `gorm:"primarykey" json:"id"`
+	UserID        uint       `gorm:"not null;index" json:"-"`
+	AccountID     uint       `gorm:"not null;index" json:"account_id"`
+	FolderID      *uint      `gorm:"index" json:"folder_id,omitempty"` // 为空时适用于账户的所有文件夹
+	Name          string     `gorm:"size:100;not null" json:"name"`
+	Action        string     `gorm:"size:20;not null;default:delete" json:"action"` // delete, archive
+	OlderThanDays int        `gorm:"not null" json:"older_than_days"`
+	UnreadOnly    bool       `gorm:"not null;default:false" json:"unread_only"`
+	Newsletters   bool       `gorm:"not null;default:false" json:"newsletters"` // 只处理订阅/通知类发件人的邮件
+	IsEnabled     bool       `gorm:"not null;default:true" json:"is_enabled"`
+	LastRunAt     *time.Time `json:"last_run_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (RetentionPolicy) TableName() string {
+	return "retention_policies"
+}
+
+// RetentionRun 保留规则的一次执行记录
+type RetentionRun struct {
+	ID          uint       `gorm:"primarykey" json:"id"`
+	PolicyID    uint       `gorm:"not null;index" json:"policy_id"`
+	UserID      uint       `gorm:"not null;index" json:"-"`
+	TriggeredBy string     `gorm:"size:20;not null" json:"triggered_by"` // scheduled, manual
+	Status      string     `gorm:"size:20;not null" json:"status"`       // running, completed, failed, cancelled
+	Matched     int        `gorm:"not null;default:0" json:"matched"`
+	Succeeded   int        `gorm:"not null;default:0" json:"succeeded"`
+	Failed      int        `gorm:"not null;default:0" json:"failed"`
+	FreedBytes  int64      `gorm:"not null;default:0" json:"freed_bytes"`
+	LastError   string     `gorm:"type:text" json:"last_error,omitempty"`
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+}
+
+// TableName 指定表名
+func (RetentionRun) TableName() string {
+	return "retention_runs"
+}
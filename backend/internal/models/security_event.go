@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// 安全事件类型
+const (
+	SecurityEventIPDenied   = "ip_denied"   // 来源IP不在白名单中或所在地区被限制
+	SecurityEventAuthFailed = "auth_failed" // 登录失败（网页登录或本地IMAP服务器）
+)
+
+// SecurityEvent 安全事件，记录被拒绝的访问等需要管理员关注的请求
+type SecurityEvent struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	EventType   string    `gorm:"size:50;not null" json:"event_type"`
+	IP          string    `gorm:"column:ip;size:45;not null;index" json:"ip"`
+	Username    string    `gorm:"size:100" json:"username,omitempty"`
+	Method      string    `gorm:"size:10" json:"method,omitempty"`
+	Path        string    `gorm:"size:500" json:"path,omitempty"`
+	Reason      string    `gorm:"size:255" json:"reason,omitempty"`
+	CountryCode string    `gorm:"size:2" json:"country_code,omitempty"`
+	UserAgent   string    `gorm:"size:500" json:"user_agent,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (SecurityEvent) TableName() string {
+	return "security_events"
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// 优先收件箱分类结果
+const (
+	ImportanceBucketImportant = "important" // 重要邮件
+	ImportanceBucketOther     = "other"     // 其他邮件
+)
+
+// SenderImportance 用户对某发件人邮件的重要性反馈次数，用于训练优先收件箱分类
+type SenderImportance struct {
+	ID             uint      `gorm:"primarykey" json:"-"`
+	UserID         uint      `gorm:"not null;uniqueIndex:idx_sender_importance_user_sender" json:"-"`
+	Sender         string    `gorm:"size:255;not null;uniqueIndex:idx_sender_importance_user_sender" json:"sender"` // 小写发件人地址
+	ImportantCount int       `gorm:"not null;default:0" json:"important_count"`
+	OtherCount     int       `gorm:"not null;default:0" json:"other_count"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (SenderImportance) TableName() string {
+	return "sender_importance"
+}
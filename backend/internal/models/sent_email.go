@@ -198,7 +198,13 @@ type SendQueue struct {
 	
 	// 邮件内容
 	EmailData   string `gorm:"type:text;not null" json:"email_data"` // JSON格式的邮件数据
-	
+
+	// 待投递的MIME原文和信封信息，进程重启后据此继续发送
+	RawMessage   []byte `gorm:"type:blob" json:"-"`
+	EnvelopeFrom string `gorm:"size:255" json:"envelope_from,omitempty"`
+	Recipients   string `gorm:"type:text" json:"recipients,omitempty"` // 逗号分隔的信封收件人
+	Subject      string `gorm:"size:500" json:"subject,omitempty"`
+
 	// 发送设置
 	ScheduledAt *time.Time `gorm:"index" json:"scheduled_at,omitempty"` // 计划发送时间
 	Priority    int        `gorm:"default:5" json:"priority"`           // 优先级 1-10
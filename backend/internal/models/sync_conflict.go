@@ -0,0 +1,102 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// 同步冲突处理策略，用户设置 sync_conflict_policy 的取值
+const (
+	SyncConflictPolicyServerWins = "server_wins" // 以服务器状态为准，覆盖本地修改
+	SyncConflictPolicyLocalWins  = "local_wins"  // 以本地状态为准，同步时写回服务器
+	SyncConflictPolicyAsk        = "ask"         // 保留本地状态，等待用户选择
+)
+
+// 同步冲突状态
+const (
+	SyncConflictStatusPending  = "pending"  // 等待用户选择
+	SyncConflictStatusResolved = "resolved" // 已处理
+)
+
+// 同步冲突处理结果
+const (
+	SyncConflictResolutionServer = "server" // 采用服务器状态
+	SyncConflictResolutionLocal  = "local"  // 采用本地状态
+)
+
+// 发生冲突的邮件状态
+const (
+	SyncConflictFieldRead    = "read"    // 已读/未读
+	SyncConflictFieldStarred = "starred" // 星标
+	SyncConflictFieldFolder  = "folder"  // 所在文件夹
+	SyncConflictFieldDeleted = "deleted" // 本地已删除，服务器上仍存在
+)
+
+// IsSyncConflictPolicy 是否为支持的冲突处理策略
+func IsSyncConflictPolicy(policy string) bool {
+	switch policy {
+	case SyncConflictPolicyServerWins, SyncConflictPolicyLocalWins, SyncConflictPolicyAsk:
+		return true
+	}
+	return false
+}
+
+// SyncConflictState 冲突发生时一方的邮件状态
+type SyncConflictState struct {
+	IsRead    bool  `json:"is_read"`
+	IsStarred bool  `json:"is_starred"`
+	IsDeleted bool  `json:"is_deleted"`
+	FolderID  *uint `json:"folder_id,omitempty"`
+}
+
+// SyncConflict 同步冲突：两次同步之间同一封邮件在本应用和其他设备上被改成了不同的状态
+type SyncConflict struct {
+	ID        uint   `gorm:"primarykey" json:"id"`
+	UserID    uint   `gorm:"not null;index" json:"-"`
+	AccountID uint   `gorm:"not null" json:"account_id"`
+	EmailID   uint   `gorm:"not null;index" json:"email_id"`
+	Fields    string `gorm:"size:100;not null" json:"-"` // 冲突的状态，逗号分隔
+
+	// 解析后的冲突状态，由服务层填充
+	FieldNames []string `gorm:"-" json:"fields"`
+
+	// 双方的状态，JSON格式
+	LocalState  string             `gorm:"type:text;not null" json:"-"`
+	RemoteState string             `gorm:"type:text;not null" json:"-"`
+	Local       *SyncConflictState `gorm:"-" json:"local"`
+	Remote      *SyncConflictState `gorm:"-" json:"remote"`
+
+	// 服务器上邮件所在文件夹中的UID，以本地为准时用于写回服务器
+	RemoteUID uint32 `json:"remote_uid"`
+
+	Policy     string     `gorm:"size:20;not null" json:"policy"`
+	Status     string     `gorm:"size:20;not null;index" json:"status"`
+	Resolution string     `gorm:"size:20" json:"resolution,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (SyncConflict) TableName() string {
+	return "sync_conflicts"
+}
+
+// FieldList 冲突的状态列表
+func (c *SyncConflict) FieldList() []string {
+	if c.Fields == "" {
+		return nil
+	}
+	return strings.Split(c.Fields, ",")
+}
+
+// HasField 指定状态是否冲突
+func (c *SyncConflict) HasField(field string) bool {
+	for _, f := range c.FieldList() {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
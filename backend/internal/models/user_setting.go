@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// 用户设置键，值以JSON编码保存，未设置的键使用配置中的默认值
+const (
+	SettingReplyAll           = "reply_all"            // bool：回复未指定收件人时回复全部
+	SettingBlockRemoteImages  = "block_remote_images"  // bool：默认不加载邮件中的远程图片
+	SettingStripTrackers      = "strip_trackers"       // bool：查看邮件时去掉追踪像素
+	SettingTimezone           = "timezone"             // string：IANA时区名，用于引用日期和定时发送
+	SettingSignature          = "signature"            // string：回复和转发时插入的纯文本签名，为空时不插入
+	SettingEmailsPerPage      = "emails_per_page"      // int：邮件列表每页数量
+	SettingNotificationsMuted = "notifications_muted"  // bool：新邮件静默推送，不弹出通知
+	SettingLocale             = "locale"               // string：接口消息和通知的语言，为空时按请求的Accept-Language选择
+	SettingSyncConflictPolicy = "sync_conflict_policy" // string：本地和服务器同时修改同一封邮件时的处理方式
+)
+
+// UserSetting 用户的一项设置
+type UserSetting struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_user_settings_user_key" json:"user_id"`
+	Key       string    `gorm:"size:50;not null;uniqueIndex:idx_user_settings_user_key" json:"key"`
+	Value     string    `gorm:"type:text;not null" json:"value"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (UserSetting) TableName() string {
+	return "user_settings"
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// VIPSender 用户标记的VIP发件人，来自VIP的邮件会被标记并发送不受静音影响的通知
+type VIPSender struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_vip_senders_user_address" json:"-"`
+	Address   string    `gorm:"size:255;not null;uniqueIndex:idx_vip_senders_user_address" json:"address"` // 小写邮箱地址
+	Name      string    `gorm:"size:100" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (VIPSender) TableName() string {
+	return "vip_senders"
+}
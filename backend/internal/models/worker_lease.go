@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// WorkerLease 多个工作进程共享数据库时的领导者租约，持有者需在到期前续约
+type WorkerLease struct {
+	Name      string    `gorm:"primaryKey;size:100" json:"name"`
+	Holder    string    `gorm:"size:255;not null" json:"holder"` // 持有者标识：主机名、进程号和随机后缀
+	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (WorkerLease) TableName() string {
+	return "worker_leases"
+}
@@ -0,0 +1,267 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const bearerAuth = "bearerAuth"
+
+// 常用的非JSON响应类型
+const (
+	ContentTypeEventStream = "text/event-stream"
+	ContentTypeBinary      = "application/octet-stream"
+)
+
+// Route 描述一个接口，是手写文档的注解单元；请求和响应类型用零值表示，由反射生成结构
+type Route struct {
+	Method  string
+	Path    string // gin风格路径，如 /api/v1/emails/:id
+	ID      string // operationId，同时作为生成客户端的方法名
+	Tag     string
+	Summary string
+
+	Params []*Parameter // 无法从结构体推导的参数，与路径参数同名时覆盖默认推导
+	Query  interface{}  // 带form标签的查询结构体
+	Body   interface{}  // JSON请求体
+	Form   interface{}  // multipart表单字段（form标签），上传文件字段名为file
+
+	Status      int         // 成功状态码，默认200
+	Data        interface{} // 成功响应的data字段，nil表示无数据
+	Raw         bool        // 响应不使用统一的JSON包装
+	ContentType string      // Raw响应的内容类型
+	Public      bool        // 无需Bearer认证
+}
+
+// PathParam 声明路径参数
+func PathParam(name, typ, description string) *Parameter {
+	return &Parameter{Name: name, In: "path", Required: true, Description: description, Schema: &Schema{Type: typ}}
+}
+
+// QueryParam 声明查询参数
+func QueryParam(name, typ, description string) *Parameter {
+	schema := &Schema{Type: typ}
+	if typ == "date-time" {
+		schema = &Schema{Type: "string", Format: "date-time"}
+	}
+	return &Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+// RequiredQueryParam 声明必填的查询参数
+func RequiredQueryParam(name, typ, description string) *Parameter {
+	param := QueryParam(name, typ, description)
+	param.Required = true
+	return param
+}
+
+// Spec 文档生成输入
+type Spec struct {
+	Info    Info
+	Servers []Server
+	Tags    []Tag
+	Routes  []Route
+	Error   interface{} // 错误响应结构
+
+	// Overrides 自定义JSON编解码的类型无法通过反射推导，在此直接指定结构
+	Overrides map[reflect.Type]*Schema
+}
+
+// Build 生成OpenAPI文档，路由重复或operationId冲突时返回错误
+func Build(spec Spec) (*Document, error) {
+	registry := newSchemaRegistry(spec.Overrides)
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    spec.Info,
+		Servers: spec.Servers,
+		Tags:    spec.Tags,
+		Paths:   make(map[string]*PathItem),
+		Components: Components{
+			SecuritySchemes: map[string]*SecurityScheme{
+				bearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+
+	errorSchema := registry.schemaOf(spec.Error)
+	seenIDs := make(map[string]string)
+	for _, route := range spec.Routes {
+		key := route.Method + " " + route.Path
+		if route.ID == "" {
+			return nil, fmt.Errorf("route %s has no operation id", key)
+		}
+		if previous, exists := seenIDs[route.ID]; exists {
+			return nil, fmt.Errorf("operation id %s used by both %s and %s", route.ID, previous, key)
+		}
+		seenIDs[route.ID] = key
+
+		path := ConvertPath(route.Path)
+		item := doc.Paths[path]
+		if item == nil {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		for _, existing := range item.Operations() {
+			if existing.Method == route.Method {
+				return nil, fmt.Errorf("route %s documented twice", key)
+			}
+		}
+		if !item.set(route.Method, buildOperation(registry, route, errorSchema)) {
+			return nil, fmt.Errorf("route %s uses unsupported method", key)
+		}
+	}
+
+	doc.Components.Schemas = registry.schemas
+	return doc, nil
+}
+
+func buildOperation(registry *schemaRegistry, route Route, errorSchema *Schema) *Operation {
+	op := &Operation{
+		OperationID: route.ID,
+		Summary:     route.Summary,
+		Responses:   make(map[string]*Response),
+	}
+	if route.Tag != "" {
+		op.Tags = []string{route.Tag}
+	}
+	if route.Public {
+		op.Security = []SecurityRequirement{{}}
+	} else {
+		op.Security = []SecurityRequirement{{bearerAuth: {}}}
+	}
+
+	op.Parameters = buildParameters(registry, route)
+
+	switch {
+	case route.Form != nil:
+		form := registry.formSchema(route.Form)
+		form.Properties["file"] = &Schema{Type: "string", Format: "binary"}
+		form.Required = append(form.Required, "file")
+		op.RequestBody = &RequestBody{Required: true, Content: map[string]*MediaType{
+			"multipart/form-data": {Schema: form},
+		}}
+	case route.Body != nil:
+		op.RequestBody = &RequestBody{Required: true, Content: map[string]*MediaType{
+			"application/json": {Schema: registry.schemaOf(route.Body)},
+		}}
+	}
+
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := &Response{Description: http.StatusText(status)}
+	switch {
+	case route.Raw:
+		contentType := route.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		var schema *Schema
+		if route.Data != nil {
+			schema = registry.schemaOf(route.Data)
+		} else if contentType != "application/json" {
+			schema = &Schema{Type: "string", Format: "binary"}
+		}
+		success.Content = map[string]*MediaType{contentType: {Schema: schema}}
+	default:
+		success.Content = map[string]*MediaType{
+			"application/json": {Schema: envelope(registry.schemaOf(route.Data))},
+		}
+	}
+	op.Responses[strconv.Itoa(status)] = success
+
+	if errorSchema != nil {
+		op.Responses["default"] = &Response{
+			Description: "Error",
+			Content:     map[string]*MediaType{"application/json": {Schema: errorSchema}},
+		}
+	}
+	return op
+}
+
+// buildParameters 合并路径参数、显式参数和查询结构体，路径参数默认按名称推断类型
+func buildParameters(registry *schemaRegistry, route Route) []*Parameter {
+	explicit := make(map[string]*Parameter)
+	for _, param := range route.Params {
+		explicit[param.In+":"+param.Name] = param
+	}
+
+	var params []*Parameter
+	for _, name := range pathParamNames(route.Path) {
+		if param, ok := explicit["path:"+name]; ok {
+			params = append(params, param)
+			delete(explicit, "path:"+name)
+			continue
+		}
+		typ := "string"
+		if name == "id" || strings.HasSuffix(name, "_id") {
+			typ = "integer"
+		}
+		params = append(params, PathParam(name, typ, ""))
+	}
+	for _, param := range route.Params {
+		if _, ok := explicit[param.In+":"+param.Name]; ok {
+			params = append(params, param)
+		}
+	}
+	return append(params, registry.queryParameters(route.Query)...)
+}
+
+// envelope 包装为 {success, data, message} 统一响应结构
+func envelope(data *Schema) *Schema {
+	schema := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"success": {Type: "boolean"},
+			"message": {Type: "string"},
+		},
+		Required: []string{"success"},
+	}
+	if data != nil {
+		schema.Properties["data"] = data
+		schema.Required = append(schema.Required, "data")
+	}
+	return schema
+}
+
+// ConvertPath 把gin路径参数 :id 转换为OpenAPI的 {id}
+func ConvertPath(ginPath string) string {
+	segments := strings.Split(ginPath, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func pathParamNames(ginPath string) []string {
+	var names []string
+	for _, segment := range strings.Split(ginPath, "/") {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			names = append(names, segment[1:])
+		}
+	}
+	return names
+}
+
+// Undocumented 返回已注册但没有文档的路由，用于保证文档覆盖全部接口
+func Undocumented(registered []string, routes []Route) []string {
+	documented := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		documented[route.Method+" "+route.Path] = true
+	}
+
+	var missing []string
+	for _, key := range registered {
+		if !documented[key] {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
@@ -0,0 +1,382 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+)
+
+// GenerateGoClient 根据文档生成Go客户端源码
+func GenerateGoClient(doc *Document, pkg string) ([]byte, error) {
+	g := &goGenerator{doc: doc, emitted: make(map[string]bool)}
+
+	var body bytes.Buffer
+	for _, name := range sortedKeys(doc.Components.Schemas) {
+		g.writeStruct(name, doc.Components.Schemas[name])
+	}
+	for _, op := range sortedOperations(doc) {
+		g.writeOperation(op)
+	}
+	body.Write(g.types.Bytes())
+	body.Write(g.methods.Bytes())
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by firemail/cmd/openapi. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "// Package %s 是根据OpenAPI文档生成的 %s 客户端\n", pkg, doc.Info.Title)
+	fmt.Fprintf(&out, "package %s\n\n", pkg)
+	out.WriteString(goClientRuntime)
+	out.Write(body.Bytes())
+
+	source, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated go client: %w", err)
+	}
+	return source, nil
+}
+
+type goGenerator struct {
+	doc     *Document
+	types   bytes.Buffer
+	methods bytes.Buffer
+	emitted map[string]bool
+}
+
+// goType 返回Schema对应的Go类型，内联对象以hint命名生成独立结构体
+func (g *goGenerator) goType(s *Schema, hint string) string {
+	if s == nil {
+		return "interface{}"
+	}
+	if name := s.RefName(); name != "" {
+		return "*" + name
+	}
+
+	var typ string
+	switch s.Type {
+	case "string":
+		switch s.Format {
+		case "date-time":
+			typ = "time.Time"
+		case "byte", "binary":
+			return "[]byte"
+		default:
+			typ = "string"
+		}
+	case "integer":
+		typ = "int64"
+		if s.Format == "int32" {
+			typ = "int32"
+		}
+	case "number":
+		typ = "float64"
+	case "boolean":
+		typ = "bool"
+	case "array":
+		return "[]" + g.goType(s.Items, hint+"Item")
+	case "object":
+		switch {
+		case len(s.Properties) > 0:
+			g.writeStruct(hint, s)
+			return "*" + hint
+		case s.AdditionalProperties != nil:
+			return "map[string]" + g.goType(s.AdditionalProperties, hint+"Value")
+		default:
+			return "map[string]interface{}"
+		}
+	default:
+		return "interface{}"
+	}
+
+	if s.Nullable {
+		return "*" + typ
+	}
+	return typ
+}
+
+func (g *goGenerator) writeStruct(name string, s *Schema) {
+	if g.emitted[name] {
+		return
+	}
+	g.emitted[name] = true
+
+	var fields bytes.Buffer
+	for _, prop := range sortedKeys(s.Properties) {
+		tag := prop
+		if !contains(s.Required, prop) {
+			tag += ",omitempty"
+		}
+		fieldName := goName(prop)
+		fmt.Fprintf(&fields, "\t%s %s `json:\"%s\"`\n", fieldName, g.goType(s.Properties[prop], name+fieldName), tag)
+	}
+
+	fmt.Fprintf(&g.types, "// %s 对应组件 %s\ntype %s struct {\n%s}\n\n", name, name, name, fields.String())
+}
+
+func (g *goGenerator) writeOperation(op pathOperation) {
+	id := op.Operation.OperationID
+	args := []string{"ctx context.Context"}
+
+	pathExpr := fmt.Sprintf("%q", op.Path)
+	if pathParams := op.parameters("path"); len(pathParams) > 0 {
+		format := op.Path
+		var values []string
+		for _, param := range pathParams {
+			arg := lowerCamel(goName(param.Name))
+			args = append(args, arg+" "+g.goType(param.Schema, id+goName(param.Name)))
+			format = strings.Replace(format, "{"+param.Name+"}", "%v", 1)
+			values = append(values, "url.PathEscape(fmt.Sprint("+arg+"))")
+		}
+		pathExpr = fmt.Sprintf("fmt.Sprintf(%q, %s)", format, strings.Join(values, ", "))
+	}
+
+	queryExpr := "nil"
+	if queryParams := op.parameters("query"); len(queryParams) > 0 {
+		paramsType := id + "Params"
+		var fields bytes.Buffer
+		var encode bytes.Buffer
+		for _, param := range queryParams {
+			fieldName := goName(param.Name)
+			fieldType := g.goType(param.Schema, paramsType+fieldName)
+			if !param.Required && !strings.HasPrefix(fieldType, "*") && !strings.HasPrefix(fieldType, "[]") {
+				fieldType = "*" + fieldType
+			}
+			if param.Description != "" {
+				fmt.Fprintf(&fields, "\t// %s\n", param.Description)
+			}
+			fmt.Fprintf(&fields, "\t%s %s\n", fieldName, fieldType)
+			fmt.Fprintf(&encode, "\taddQuery(query, %q, p.%s)\n", param.Name, fieldName)
+		}
+		fmt.Fprintf(&g.types, "// %s %s 的查询参数\ntype %s struct {\n%s}\n\n", paramsType, id, paramsType, fields.String())
+		fmt.Fprintf(&g.types, "func (p *%s) values() url.Values {\n\tquery := url.Values{}\n\tif p == nil {\n\t\treturn query\n\t}\n%s\treturn query\n}\n\n", paramsType, encode.String())
+		args = append(args, "params *"+paramsType)
+		queryExpr = "params.values()"
+	}
+
+	bodyExpr := "nil"
+	bodySchema, multipart := op.bodySchema()
+	switch {
+	case multipart:
+		formType := id + "Form"
+		form := *bodySchema
+		form.Properties = make(map[string]*Schema)
+		for name, prop := range bodySchema.Properties {
+			if name != "file" {
+				form.Properties[name] = prop
+			}
+		}
+		g.writeStruct(formType, &form)
+		args = append(args, "form *"+formType, "filename string", "file io.Reader")
+		bodyExpr = "multipartBody(form, filename, file)"
+	case bodySchema != nil:
+		args = append(args, "body "+g.goType(bodySchema, id+"Request"))
+		bodyExpr = "jsonBody(body)"
+	}
+
+	dataSchema, raw := op.dataSchema()
+	summary := op.Operation.Summary
+	if summary == "" {
+		summary = op.Method + " " + op.Path
+	}
+	fmt.Fprintf(&g.methods, "// %s %s\n", id, summary)
+
+	switch {
+	case raw:
+		fmt.Fprintf(&g.methods, "func (c *Client) %s(%s) (*http.Response, error) {\n", id, strings.Join(args, ", "))
+		fmt.Fprintf(&g.methods, "\treturn c.doRaw(ctx, %q, %s, %s, %s)\n}\n\n", op.Method, pathExpr, queryExpr, bodyExpr)
+	case dataSchema == nil:
+		fmt.Fprintf(&g.methods, "func (c *Client) %s(%s) error {\n", id, strings.Join(args, ", "))
+		fmt.Fprintf(&g.methods, "\treturn c.do(ctx, %q, %s, %s, %s, nil)\n}\n\n", op.Method, pathExpr, queryExpr, bodyExpr)
+	default:
+		resultType := g.goType(dataSchema, id+"Data")
+		valueType := strings.TrimPrefix(resultType, "*")
+		fmt.Fprintf(&g.methods, "func (c *Client) %s(%s) (%s, error) {\n", id, strings.Join(args, ", "), resultType)
+		fmt.Fprintf(&g.methods, "\tvar out %s\n", valueType)
+		fmt.Fprintf(&g.methods, "\tif err := c.do(ctx, %q, %s, %s, %s, &out); err != nil {\n", op.Method, pathExpr, queryExpr, bodyExpr)
+		zero := "nil"
+		if resultType == valueType && !isNilable(resultType) {
+			zero = "out"
+		}
+		fmt.Fprintf(&g.methods, "\t\treturn %s, err\n\t}\n", zero)
+		if resultType != valueType {
+			fmt.Fprintf(&g.methods, "\treturn &out, nil\n}\n\n")
+		} else {
+			fmt.Fprintf(&g.methods, "\treturn out, nil\n}\n\n")
+		}
+	}
+}
+
+func isNilable(goType string) bool {
+	return strings.HasPrefix(goType, "[]") || strings.HasPrefix(goType, "map[") || goType == "interface{}"
+}
+
+// goClientRuntime 生成客户端共用的请求与错误处理代码
+const goClientRuntime = `import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Client API客户端，Token为空时不发送Authorization头
+type Client struct {
+	BaseURL    string
+	Token      string
+	HTTPClient *http.Client
+}
+
+// New 创建客户端
+func New(baseURL, token string) *Client {
+	return &Client{BaseURL: strings.TrimRight(baseURL, "/"), Token: token, HTTPClient: http.DefaultClient}
+}
+
+// APIError 服务端返回的错误响应
+type APIError struct {
+	StatusCode int
+	Response   ErrorResponse
+}
+
+func (e *APIError) Error() string {
+	if e.Response.Message != "" {
+		return fmt.Sprintf("firemail: %d %s: %s", e.StatusCode, e.Response.Error, e.Response.Message)
+	}
+	return fmt.Sprintf("firemail: %d %s", e.StatusCode, e.Response.Error)
+}
+
+type requestBody struct {
+	reader      io.Reader
+	contentType string
+	err         error
+}
+
+func jsonBody(v interface{}) *requestBody {
+	data, err := json.Marshal(v)
+	return &requestBody{reader: bytes.NewReader(data), contentType: "application/json", err: err}
+}
+
+func multipartBody(form interface{}, filename string, file io.Reader) *requestBody {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	fields := map[string]interface{}{}
+	data, err := json.Marshal(form)
+	if err != nil {
+		return &requestBody{err: err}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		return &requestBody{err: err}
+	}
+	for name, value := range fields {
+		if err := writer.WriteField(name, fmt.Sprint(value)); err != nil {
+			return &requestBody{err: err}
+		}
+	}
+
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		return &requestBody{err: err}
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return &requestBody{err: err}
+	}
+	if err := writer.Close(); err != nil {
+		return &requestBody{err: err}
+	}
+	return &requestBody{reader: &buf, contentType: writer.FormDataContentType()}
+}
+
+func addQuery(query url.Values, name string, value interface{}) {
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			query.Add(name, fmt.Sprint(v.Index(i).Interface()))
+		}
+	default:
+		if t, ok := v.Interface().(time.Time); ok {
+			query.Set(name, t.Format(time.RFC3339))
+			return
+		}
+		query.Set(name, fmt.Sprint(v.Interface()))
+	}
+}
+
+func (c *Client) doRaw(ctx context.Context, method, path string, query url.Values, body *requestBody) (*http.Response, error) {
+	target := c.BaseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		if body.err != nil {
+			return nil, body.err
+		}
+		reader = body.reader
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", body.contentType)
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		if err := json.NewDecoder(resp.Body).Decode(&apiErr.Response); err != nil {
+			apiErr.Response.Error = http.StatusText(resp.StatusCode)
+		}
+		return nil, apiErr
+	}
+	return resp, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body *requestBody, out interface{}) error {
+	resp, err := c.doRaw(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Success bool            ` + "`json:\"success\"`" + `
+		Data    json.RawMessage ` + "`json:\"data\"`" + `
+		Message string          ` + "`json:\"message\"`" + `
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("firemail: decode response: %w", err)
+	}
+	if out == nil || len(envelope.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(envelope.Data, out)
+}
+
+`
@@ -0,0 +1,255 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// GenerateTypeScriptClient 根据文档生成基于fetch的TypeScript客户端
+func GenerateTypeScriptClient(doc *Document) []byte {
+	var out bytes.Buffer
+	out.WriteString("// Code generated by firemail/cmd/openapi. DO NOT EDIT.\n")
+	out.WriteString("/* eslint-disable */\n\n")
+
+	for _, name := range sortedKeys(doc.Components.Schemas) {
+		fmt.Fprintf(&out, "export interface %s %s\n\n", name, tsObject(doc.Components.Schemas[name], ""))
+	}
+
+	var methods bytes.Buffer
+	for _, op := range sortedOperations(doc) {
+		writeTSOperation(&out, &methods, op)
+	}
+
+	out.WriteString(strings.Replace(tsClientRuntime, "{{methods}}", strings.TrimRight(methods.String(), "\n"), 1))
+	return out.Bytes()
+}
+
+func tsType(s *Schema, indent string) string {
+	if s == nil {
+		return "unknown"
+	}
+	if name := s.RefName(); name != "" {
+		return name
+	}
+
+	var typ string
+	switch s.Type {
+	case "string":
+		if len(s.Enum) > 0 {
+			quoted := make([]string, len(s.Enum))
+			for i, value := range s.Enum {
+				quoted[i] = fmt.Sprintf("%q", value)
+			}
+			typ = strings.Join(quoted, " | ")
+		} else if s.Format == "binary" {
+			typ = "Blob"
+		} else {
+			typ = "string"
+		}
+	case "integer", "number":
+		typ = "number"
+	case "boolean":
+		typ = "boolean"
+	case "array":
+		item := tsType(s.Items, indent)
+		if strings.ContainsAny(item, " |") {
+			item = "(" + item + ")"
+		}
+		typ = item + "[]"
+	case "object":
+		switch {
+		case len(s.Properties) > 0:
+			typ = tsObject(s, indent)
+		case s.AdditionalProperties != nil:
+			typ = "Record<string, " + tsType(s.AdditionalProperties, indent) + ">"
+		default:
+			typ = "Record<string, unknown>"
+		}
+	default:
+		return "unknown"
+	}
+
+	if s.Nullable {
+		return typ + " | null"
+	}
+	return typ
+}
+
+func tsObject(s *Schema, indent string) string {
+	if len(s.Properties) == 0 {
+		return "{}"
+	}
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, name := range sortedKeys(s.Properties) {
+		optional := "?"
+		if contains(s.Required, name) {
+			optional = ""
+		}
+		fmt.Fprintf(&b, "%s  %s%s: %s;\n", indent, tsPropertyName(name), optional, tsType(s.Properties[name], indent+"  "))
+	}
+	b.WriteString(indent + "}")
+	return b.String()
+}
+
+func tsPropertyName(name string) string {
+	for _, r := range name {
+		if !(r == '_' || r == '$' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9')) {
+			return fmt.Sprintf("%q", name)
+		}
+	}
+	return name
+}
+
+func writeTSOperation(types, methods *bytes.Buffer, op pathOperation) {
+	id := op.Operation.OperationID
+	var args []string
+
+	pathExpr := op.Path
+	for _, param := range op.parameters("path") {
+		arg := lowerCamel(goName(param.Name))
+		args = append(args, arg+": "+tsType(param.Schema, ""))
+		pathExpr = strings.Replace(pathExpr, "{"+param.Name+"}", "${encodeURIComponent(String("+arg+"))}", 1)
+	}
+
+	queryArg := "undefined"
+	if queryParams := op.parameters("query"); len(queryParams) > 0 {
+		queryType := id + "Query"
+		required := false
+		fmt.Fprintf(types, "export interface %s {\n", queryType)
+		for _, param := range queryParams {
+			optional := "?"
+			if param.Required {
+				optional = ""
+				required = true
+			}
+			if param.Description != "" {
+				fmt.Fprintf(types, "  /** %s */\n", param.Description)
+			}
+			fmt.Fprintf(types, "  %s%s: %s;\n", tsPropertyName(param.Name), optional, tsType(param.Schema, "  "))
+		}
+		types.WriteString("}\n\n")
+		if required {
+			args = append(args, "query: "+queryType)
+		} else {
+			args = append(args, "query?: "+queryType)
+		}
+		queryArg = "query"
+	}
+
+	bodyArg := ""
+	bodySchema, multipart := op.bodySchema()
+	switch {
+	case multipart:
+		args = append(args, "form: "+tsType(bodySchema, "  "))
+		bodyArg = ", form"
+	case bodySchema != nil:
+		args = append(args, "body: "+tsType(bodySchema, "  "))
+		bodyArg = ", body"
+	}
+
+	dataSchema, raw := op.dataSchema()
+	summary := op.Operation.Summary
+	if summary == "" {
+		summary = op.Method + " " + op.Path
+	}
+
+	fmt.Fprintf(methods, "  /** %s */\n", summary)
+	fmt.Fprintf(methods, "  %s(%s): Promise<", lowerCamel(id), strings.Join(args, ", "))
+	switch {
+	case raw:
+		fmt.Fprintf(methods, "Response> {\n    return this.raw(%q, `%s`, %s", op.Method, pathExpr, queryArg)
+	case dataSchema == nil:
+		fmt.Fprintf(methods, "void> {\n    return this.request<void>(%q, `%s`, %s", op.Method, pathExpr, queryArg)
+	default:
+		dataType := tsType(dataSchema, "    ")
+		fmt.Fprintf(methods, "%s> {\n    return this.request<%s>(%q, `%s`, %s", dataType, dataType, op.Method, pathExpr, queryArg)
+	}
+	if multipart {
+		fmt.Fprintf(methods, ", undefined%s", bodyArg)
+	} else {
+		methods.WriteString(bodyArg)
+	}
+	methods.WriteString(");\n  }\n\n")
+}
+
+// tsClientRuntime 客户端类及通用请求逻辑，{{methods}}替换为各接口方法
+const tsClientRuntime = `export type QueryValue = string | number | boolean | null | undefined | Array<string | number | boolean>;
+
+export interface FireMailClientOptions {
+  baseUrl: string;
+  token?: string | (() => string | null | undefined);
+  fetch?: typeof fetch;
+}
+
+export class FireMailApiError extends Error {
+  constructor(
+    public readonly status: number,
+    public readonly response: ErrorResponse
+  ) {
+    super(response.message || response.error);
+    this.name = 'FireMailApiError';
+  }
+}
+
+export class FireMailClient {
+  constructor(private readonly options: FireMailClientOptions) {}
+
+{{methods}}
+
+  private async raw(
+    method: string,
+    path: string,
+    query?: object,
+    body?: unknown,
+    form?: object
+  ): Promise<Response> {
+    const params = new URLSearchParams();
+    for (const [key, value] of Object.entries((query ?? {}) as Record<string, QueryValue>)) {
+      if (value === undefined || value === null) continue;
+      for (const item of Array.isArray(value) ? value : [value]) {
+        params.append(key, String(item));
+      }
+    }
+    const search = params.toString();
+    const url = this.options.baseUrl.replace(/\/+$/, '') + path + (search ? '?' + search : '');
+
+    const headers: Record<string, string> = {};
+    const token = typeof this.options.token === 'function' ? this.options.token() : this.options.token;
+    if (token) headers.Authorization = ` + "`Bearer ${token}`" + `;
+
+    let payload: BodyInit | undefined;
+    if (form) {
+      const data = new FormData();
+      for (const [key, value] of Object.entries(form)) {
+        if (value === undefined || value === null) continue;
+        data.append(key, value instanceof Blob ? value : String(value));
+      }
+      payload = data;
+    } else if (body !== undefined) {
+      headers['Content-Type'] = 'application/json';
+      payload = JSON.stringify(body);
+    }
+
+    const response = await (this.options.fetch ?? fetch)(url, { method, headers, body: payload });
+    if (!response.ok) {
+      const error = (await response.json().catch(() => ({ error: response.statusText }))) as ErrorResponse;
+      throw new FireMailApiError(response.status, error);
+    }
+    return response;
+  }
+
+  private async request<T>(
+    method: string,
+    path: string,
+    query?: object,
+    body?: unknown,
+    form?: object
+  ): Promise<T> {
+    const response = await this.raw(method, path, query, body, form);
+    const envelope = (await response.json()) as { success: boolean; data?: T; message?: string };
+    return envelope.data as T;
+  }
+}
+`
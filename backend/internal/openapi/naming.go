@@ -0,0 +1,137 @@
+package openapi
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// 生成Go标识符时整体大写的缩写
+var initialisms = map[string]string{
+	"api": "API", "bcc": "BCC", "cc": "CC", "html": "HTML", "http": "HTTP", "id": "ID",
+	"ids": "IDs", "imap": "IMAP", "ip": "IP", "json": "JSON", "smtp": "SMTP", "ssl": "SSL",
+	"tls": "TLS", "uid": "UID", "url": "URL", "uuid": "UUID",
+}
+
+// goName 把snake_case字段名转换为导出的Go标识符
+func goName(name string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if upper, ok := initialisms[strings.ToLower(part)]; ok && strings.ToLower(part) == part {
+			b.WriteString(upper)
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	result := b.String()
+	if result == "" || unicode.IsDigit(rune(result[0])) {
+		result = "X" + result
+	}
+	return result
+}
+
+// lowerCamel 把operationId转换为TypeScript方法名
+func lowerCamel(name string) string {
+	if name == "" {
+		return name
+	}
+	runes := []rune(name)
+	i := 0
+	for i < len(runes) && unicode.IsUpper(runes[i]) {
+		i++
+	}
+	switch {
+	case i == 0:
+		return name
+	case i == 1 || i == len(runes):
+		return strings.ToLower(string(runes[:i])) + string(runes[i:])
+	default:
+		// 连续大写的缩写保留最后一个字母作为下一个单词的开头
+		return strings.ToLower(string(runes[:i-1])) + string(runes[i-1:])
+	}
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedOperations 按路径和方法排序，保证生成结果稳定
+func sortedOperations(doc *Document) []pathOperation {
+	var ops []pathOperation
+	for _, path := range sortedKeys(doc.Paths) {
+		for _, item := range doc.Paths[path].Operations() {
+			ops = append(ops, pathOperation{Path: path, Method: item.Method, Operation: item.Operation})
+		}
+	}
+	return ops
+}
+
+type pathOperation struct {
+	Path      string
+	Method    string
+	Operation *Operation
+}
+
+// successResponse 返回操作的成功响应及状态码
+func (p pathOperation) successResponse() (*Response, string) {
+	for _, code := range sortedKeys(p.Operation.Responses) {
+		if strings.HasPrefix(code, "2") {
+			return p.Operation.Responses[code], code
+		}
+	}
+	return nil, ""
+}
+
+// dataSchema 返回统一包装中的data结构，raw表示响应未经包装
+func (p pathOperation) dataSchema() (schema *Schema, raw bool) {
+	response, _ := p.successResponse()
+	if response == nil {
+		return nil, false
+	}
+	if media, ok := response.Content["application/json"]; ok {
+		if media.Schema != nil && media.Schema.Properties["success"] != nil {
+			return media.Schema.Properties["data"], false
+		}
+		return media.Schema, true
+	}
+	return nil, true
+}
+
+func (p pathOperation) bodySchema() (schema *Schema, multipart bool) {
+	if p.Operation.RequestBody == nil {
+		return nil, false
+	}
+	if media, ok := p.Operation.RequestBody.Content["multipart/form-data"]; ok {
+		return media.Schema, true
+	}
+	if media, ok := p.Operation.RequestBody.Content["application/json"]; ok {
+		return media.Schema, false
+	}
+	return nil, false
+}
+
+func (p pathOperation) parameters(in string) []*Parameter {
+	var params []*Parameter
+	for _, param := range p.Operation.Parameters {
+		if param.In == in {
+			params = append(params, param)
+		}
+	}
+	return params
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type testBase struct {
+	ID        uint           `json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty"`
+}
+
+type testFolder struct {
+	testBase
+	Name     string         `json:"name" binding:"required"`
+	Kind     string         `json:"kind" binding:"omitempty,oneof=inbox sent"`
+	ParentID *uint          `json:"parent_id"`
+	Parent   *testFolder    `json:"parent,omitempty"`
+	Children []testFolder   `json:"children,omitempty"`
+	Labels   map[string]int `json:"labels"`
+	Extra    interface{}    `json:"extra"`
+	Secret   string         `json:"-"`
+	internal string
+}
+
+type testListQuery struct {
+	Page   int    `form:"page"`
+	Status string `form:"status" binding:"required,oneof=open closed"`
+}
+
+type testError struct {
+	Error   string `json:"error"`
+	Message string `json:"message,omitempty"`
+}
+
+func testSpec(routes ...Route) Spec {
+	return Spec{Info: Info{Title: "Test", Version: "1"}, Routes: routes, Error: testError{}}
+}
+
+func TestBuildReflectsStructSchemas(t *testing.T) {
+	doc, err := Build(testSpec(Route{
+		Method: "GET", Path: "/api/folders/:id", ID: "GetFolder", Data: testFolder{},
+	}))
+	require.NoError(t, err)
+
+	folder := doc.Components.Schemas["testFolder"]
+	require.NotNil(t, folder)
+	require.Equal(t, []string{"name"}, folder.Required)
+	require.Contains(t, folder.Properties, "id", "embedded struct fields are promoted")
+	require.Equal(t, "date-time", folder.Properties["created_at"].Format)
+	require.True(t, folder.Properties["deleted_at"].Nullable)
+	require.Equal(t, []string{"inbox", "sent"}, folder.Properties["kind"].Enum)
+	require.True(t, folder.Properties["parent_id"].Nullable)
+	require.Equal(t, "#/components/schemas/testFolder", folder.Properties["parent"].Ref, "self references resolve to the component")
+	require.Equal(t, "#/components/schemas/testFolder", folder.Properties["children"].Items.Ref)
+	require.Equal(t, "integer", folder.Properties["labels"].AdditionalProperties.Type)
+	require.NotContains(t, folder.Properties, "Secret")
+	require.NotContains(t, folder.Properties, "internal")
+
+	op := doc.Paths["/api/folders/{id}"].Get
+	require.NotNil(t, op)
+	require.Len(t, op.Parameters, 1)
+	require.Equal(t, "integer", op.Parameters[0].Schema.Type)
+	data := op.Responses["200"].Content["application/json"].Schema.Properties["data"]
+	require.Equal(t, "#/components/schemas/testFolder", data.Ref)
+	require.Equal(t, "#/components/schemas/testError", op.Responses["default"].Content["application/json"].Schema.Ref)
+	require.Equal(t, []SecurityRequirement{{bearerAuth: {}}}, op.Security)
+}
+
+func TestBuildQueryAndFormParameters(t *testing.T) {
+	doc, err := Build(testSpec(
+		Route{Method: "GET", Path: "/items", ID: "ListItems", Query: testListQuery{}, Public: true},
+		Route{Method: "POST", Path: "/items", ID: "UploadItems", Form: testListQuery{}, Status: 201},
+	))
+	require.NoError(t, err)
+
+	list := doc.Paths["/items"].Get
+	require.Len(t, list.Parameters, 2)
+	require.Equal(t, "status", list.Parameters[1].Name)
+	require.True(t, list.Parameters[1].Required)
+	require.Equal(t, []string{"open", "closed"}, list.Parameters[1].Schema.Enum)
+	require.Equal(t, []SecurityRequirement{{}}, list.Security)
+
+	upload := doc.Paths["/items"].Post
+	form := upload.RequestBody.Content["multipart/form-data"].Schema
+	require.Equal(t, "binary", form.Properties["file"].Format)
+	require.ElementsMatch(t, []string{"status", "file"}, form.Required)
+	require.Contains(t, upload.Responses, "201")
+}
+
+func TestBuildRejectsDuplicates(t *testing.T) {
+	_, err := Build(testSpec(
+		Route{Method: "GET", Path: "/a", ID: "Same"},
+		Route{Method: "GET", Path: "/b", ID: "Same"},
+	))
+	require.ErrorContains(t, err, "operation id Same")
+
+	_, err = Build(testSpec(
+		Route{Method: "GET", Path: "/a", ID: "First"},
+		Route{Method: "GET", Path: "/a", ID: "Second"},
+	))
+	require.ErrorContains(t, err, "documented twice")
+}
+
+func TestUndocumented(t *testing.T) {
+	missing := Undocumented(
+		[]string{"GET /a", "POST /b", "DELETE /c/:id"},
+		[]Route{{Method: "GET", Path: "/a"}},
+	)
+	require.Equal(t, []string{"DELETE /c/:id", "POST /b"}, missing)
+}
+
+func TestGenerateClients(t *testing.T) {
+	doc, err := Build(testSpec(
+		Route{Method: "GET", Path: "/api/folders/:id", ID: "GetFolder", Data: testFolder{}},
+		Route{Method: "GET", Path: "/items", ID: "ListItems", Query: testListQuery{}, Data: []testFolder{}},
+		Route{Method: "POST", Path: "/items", ID: "UploadItems", Form: testListQuery{}},
+		Route{Method: "GET", Path: "/items/:id/raw", ID: "DownloadItem", Raw: true, ContentType: ContentTypeBinary},
+	))
+	require.NoError(t, err)
+
+	goClient, err := GenerateGoClient(doc, "client")
+	require.NoError(t, err)
+	source := string(goClient)
+	require.Contains(t, source, "func (c *Client) GetFolder(ctx context.Context, id int64) (*testFolder, error)")
+	require.Contains(t, source, "func (c *Client) ListItems(ctx context.Context, params *ListItemsParams) ([]*testFolder, error)")
+	require.Contains(t, source, "func (c *Client) UploadItems(ctx context.Context, form *UploadItemsForm, filename string, file io.Reader) error")
+	require.Contains(t, source, "func (c *Client) DownloadItem(ctx context.Context, id int64) (*http.Response, error)")
+	require.Regexp(t, `ParentID\s+\*int64`, source)
+
+	tsClient := string(GenerateTypeScriptClient(doc))
+	require.Contains(t, tsClient, "export interface testFolder {")
+	require.Contains(t, tsClient, "  name: string;")
+	require.Contains(t, tsClient, `  kind?: "inbox" | "sent";`)
+	require.Contains(t, tsClient, "getFolder(id: number): Promise<testFolder>")
+	require.Contains(t, tsClient, "listItems(query: ListItemsQuery): Promise<testFolder[]>")
+	require.True(t, strings.Contains(tsClient, "downloadItem(id: number): Promise<Response>"))
+}
+
+func TestNaming(t *testing.T) {
+	require.Equal(t, "AccountID", goName("account_id"))
+	require.Equal(t, "HTMLBody", goName("html_body"))
+	require.Equal(t, "EmailIDs", goName("email_ids"))
+	require.Equal(t, "getEmails", lowerCamel("GetEmails"))
+	require.Equal(t, "sseStats", lowerCamel("SSEStats"))
+	require.Equal(t, "/emails/{id}/revisions/{revision_id}", ConvertPath("/emails/:id/revisions/:revision_id"))
+}
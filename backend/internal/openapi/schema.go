@@ -0,0 +1,258 @@
+package openapi
+
+import (
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaRegistry 通过反射把Go类型转换为Schema，命名结构体登记为可复用组件
+type schemaRegistry struct {
+	overrides map[reflect.Type]*Schema
+	schemas   map[string]*Schema
+	names     map[reflect.Type]string
+	owners    map[string]reflect.Type
+}
+
+func newSchemaRegistry(overrides map[reflect.Type]*Schema) *schemaRegistry {
+	return &schemaRegistry{
+		overrides: overrides,
+		schemas:   make(map[string]*Schema),
+		names:     make(map[reflect.Type]string),
+		owners:    make(map[string]reflect.Type),
+	}
+}
+
+// schemaOf 返回值对应的Schema，nil表示无结构
+func (r *schemaRegistry) schemaOf(v interface{}) *Schema {
+	if v == nil {
+		return nil
+	}
+	return r.schemaFor(reflect.TypeOf(v))
+}
+
+func (r *schemaRegistry) schemaFor(t reflect.Type) *Schema {
+	if override, ok := r.overrides[t]; ok {
+		copied := *override
+		return &copied
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case isNullTime(t):
+		return &Schema{Type: "string", Format: "date-time", Nullable: true}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		elem := r.schemaFor(t.Elem())
+		if elem.Ref != "" {
+			// OpenAPI 3.0中$ref的兄弟字段会被忽略，引用保持原样
+			return elem
+		}
+		nullable := *elem
+		nullable.Nullable = true
+		return &nullable
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: r.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.objectSchema(t)
+		}
+		return r.componentRef(t)
+	default:
+		// interface{} 等任意值
+		return &Schema{}
+	}
+}
+
+// componentRef 登记命名结构体并返回引用，先占位以支持自引用类型
+func (r *schemaRegistry) componentRef(t reflect.Type) *Schema {
+	name, ok := r.names[t]
+	if !ok {
+		name = r.componentName(t)
+		r.names[t] = name
+		r.owners[name] = t
+		placeholder := &Schema{}
+		r.schemas[name] = placeholder
+		*placeholder = *r.objectSchema(t)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// componentName 使用类型名作为组件名，不同包同名时加上包名前缀
+func (r *schemaRegistry) componentName(t reflect.Type) string {
+	base := sanitizeName(t.Name())
+	if _, taken := r.owners[base]; !taken {
+		return base
+	}
+
+	name := exportName(path.Base(t.PkgPath())) + base
+	for i := 2; ; i++ {
+		if _, taken := r.owners[name]; !taken {
+			return name
+		}
+		name = exportName(path.Base(t.PkgPath())) + base + strconv.Itoa(i)
+	}
+}
+
+func (r *schemaRegistry) objectSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	r.collectFields(t, schema)
+	return schema
+}
+
+// collectFields 按encoding/json规则收集字段，匿名嵌入的结构体字段提升到外层
+func (r *schemaRegistry) collectFields(t reflect.Type, schema *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+
+		fieldType := field.Type
+		if field.Anonymous && name == "" {
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct && fieldType != timeType && !isNullTime(fieldType) {
+				r.collectFields(fieldType, schema)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		switch fieldType.Kind() {
+		case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+			continue
+		}
+
+		property := r.schemaFor(fieldType)
+		required, enum := bindingRules(field.Tag.Get("binding"))
+		if len(enum) > 0 && property.Ref == "" {
+			constrained := *property
+			constrained.Enum = enum
+			property = &constrained
+		}
+		schema.Properties[name] = property
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// queryParameters 把带form标签的结构体展开为查询参数
+func (r *schemaRegistry) queryParameters(v interface{}) []*Parameter {
+	if v == nil {
+		return nil
+	}
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var params []*Parameter
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("form"), ",")[0]
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+		required, enum := bindingRules(field.Tag.Get("binding"))
+		schema := r.schemaFor(field.Type)
+		if len(enum) > 0 && schema.Ref == "" {
+			schema.Enum = enum
+		}
+		params = append(params, &Parameter{Name: name, In: "query", Required: required, Schema: schema})
+	}
+	return params
+}
+
+// formSchema 把带form标签的结构体转换为multipart表单结构
+func (r *schemaRegistry) formSchema(v interface{}) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for _, param := range r.queryParameters(v) {
+		schema.Properties[param.Name] = param.Schema
+		if param.Required {
+			schema.Required = append(schema.Required, param.Name)
+		}
+	}
+	return schema
+}
+
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	return strings.Split(tag, ",")[0], true
+}
+
+// bindingRules 从gin的binding标签中提取必填和枚举约束
+func bindingRules(tag string) (bool, []string) {
+	var required bool
+	var enum []string
+	for _, rule := range strings.Split(tag, ",") {
+		switch {
+		case rule == "required":
+			required = true
+		case strings.HasPrefix(rule, "oneof="):
+			enum = strings.Fields(strings.TrimPrefix(rule, "oneof="))
+		}
+	}
+	return required, enum
+}
+
+// isNullTime 识别以可空时间序列化的类型，如gorm.DeletedAt和sql.NullTime
+func isNullTime(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t.NumField() != 2 {
+		return false
+	}
+	return t.Field(0).Type == timeType && t.Field(1).Type.Kind() == reflect.Bool
+}
+
+func sanitizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, name)
+}
+
+func exportName(name string) string {
+	name = sanitizeName(name)
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
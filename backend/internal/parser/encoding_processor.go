@@ -73,7 +73,8 @@ func (p *EncodingProcessor) initCharsetMap() {
 	p.charsetMap["windows-1258"] = charmap.Windows1258
 
 	// 中文编码
-	p.charsetMap["gb2312"] = simplifiedchinese.HZGB2312
+	p.charsetMap["gb2312"] = simplifiedchinese.GBK // 声明为gb2312的邮件实际多为GBK编码
+	p.charsetMap["hz-gb-2312"] = simplifiedchinese.HZGB2312
 	p.charsetMap["gbk"] = simplifiedchinese.GBK
 	p.charsetMap["gb18030"] = simplifiedchinese.GB18030
 	p.charsetMap["big5"] = traditionalchinese.Big5
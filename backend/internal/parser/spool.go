@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime/quotedprintable"
+	"os"
+	"strings"
+)
+
+// spoolBuffer 内存中最多保留limit字节，超出后整体溢出到临时文件
+type spoolBuffer struct {
+	limit int64
+	dir   string
+	buf   bytes.Buffer
+	file  *os.File
+	size  int64
+}
+
+func newSpoolBuffer(limit int64, dir string) *spoolBuffer {
+	return &spoolBuffer{limit: limit, dir: dir}
+}
+
+// Write 写入数据，超过内存上限时切换到临时文件
+func (s *spoolBuffer) Write(p []byte) (int, error) {
+	if s.file == nil && int64(s.buf.Len()+len(p)) > s.limit {
+		file, err := os.CreateTemp(s.dir, "firemail-part-*")
+		if err != nil {
+			return 0, fmt.Errorf("failed to create spool file: %w", err)
+		}
+		if _, err := file.Write(s.buf.Bytes()); err != nil {
+			file.Close()
+			os.Remove(file.Name())
+			return 0, fmt.Errorf("failed to write spool file: %w", err)
+		}
+		s.buf = bytes.Buffer{}
+		s.file = file
+	}
+
+	var n int
+	var err error
+	if s.file != nil {
+		n, err = s.file.Write(p)
+	} else {
+		n, err = s.buf.Write(p)
+	}
+	s.size += int64(n)
+	return n, err
+}
+
+// finish 结束写入，返回内存中的内容或临时文件路径
+func (s *spoolBuffer) finish() ([]byte, string, error) {
+	if s.file == nil {
+		return s.buf.Bytes(), "", nil
+	}
+
+	path := s.file.Name()
+	err := s.file.Close()
+	s.file = nil
+	if err != nil {
+		os.Remove(path)
+		return nil, "", fmt.Errorf("failed to close spool file: %w", err)
+	}
+	return nil, path, nil
+}
+
+// discard 丢弃已写入的内容
+func (s *spoolBuffer) discard() {
+	if s.file != nil {
+		s.file.Close()
+		os.Remove(s.file.Name())
+		s.file = nil
+	}
+	s.buf = bytes.Buffer{}
+}
+
+// newTransferDecoder 返回按传输编码边读边解码的Reader
+func newTransferDecoder(reader io.Reader, encoding string) (io.Reader, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "", "7bit", "8bit", "binary":
+		return reader, nil
+	case "quoted-printable":
+		return quotedprintable.NewReader(reader), nil
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, reader), nil
+	default:
+		return nil, fmt.Errorf("unsupported transfer encoding: %s", encoding)
+	}
+}
@@ -0,0 +1,479 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"mime"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"firemail/internal/encoding"
+)
+
+// Outlook以TNEF格式（winmail.dat）发送邮件时，真正的附件和RTF正文都封装在这一个附件里。
+// 这里只解析附件和压缩RTF正文，其余MAPI属性忽略
+
+const tnefSignature = 0x223E9F78
+
+// TNEF属性级别
+const (
+	tnefLevelMessage    = 0x01
+	tnefLevelAttachment = 0x02
+)
+
+// TNEF属性ID（低16位）
+const (
+	tnefAttBody           = 0x800C
+	tnefAttAttachData     = 0x800F
+	tnefAttAttachTitle    = 0x8010
+	tnefAttAttachRendData = 0x9002
+	tnefAttMAPIProps      = 0x9003
+	tnefAttAttachment     = 0x9005
+)
+
+// MAPI属性ID
+const (
+	mapiAttachDataBin      = 0x3701
+	mapiAttachFilename     = 0x3704
+	mapiAttachLongFilename = 0x3707
+	mapiAttachMimeTag      = 0x370E
+	mapiAttachContentID    = 0x3712
+	mapiRTFCompressed      = 0x1009
+)
+
+// MAPI属性类型
+const (
+	mapiTypeObject  = 0x000D
+	mapiTypeString8 = 0x001E
+	mapiTypeUnicode = 0x001F
+	mapiTypeBinary  = 0x0102
+	mapiTypeMulti   = 0x1000
+)
+
+// 压缩RTF的格式标记
+const (
+	rtfCompressedLZFu = 0x75465A4C
+	rtfUncompressed   = 0x414C454D
+)
+
+// rtfDictionaryPrefix 压缩RTF字典的初始内容
+const rtfDictionaryPrefix = "{\\rtf1\\ansi\\mac\\deff0\\deftab720{\\fonttbl;}{\\f0\\fnil \\froman \\fswiss \\fmodern \\fscript \\fdecor MS Sans SerifSymbolArialTimes New RomanCourier{\\colortbl\\red0\\green0\\blue0\r\n\\par \\pard\\plain\\f0\\fs20\\b\\i\\u\\tab\\tx"
+
+// TNEFBodyFilename TNEF中的RTF正文作为附件保存时使用的文件名
+const TNEFBodyFilename = "message.rtf"
+
+// tnefPartSeparator 从TNEF中取出的内容使用 <winmail.dat的PartID>#tnef-<序号> 作为PartID，
+// 序号为body时表示RTF正文，重新下载时先取回winmail.dat再从中取出对应内容
+const tnefPartSeparator = "#tnef-"
+
+var (
+	// ErrNotTNEF 内容不是TNEF格式
+	ErrNotTNEF = errors.New("not a TNEF stream")
+	// ErrTNEFTruncated TNEF内容不完整
+	ErrTNEFTruncated = errors.New("truncated TNEF stream")
+)
+
+// TNEFMessage TNEF中解析出的内容
+type TNEFMessage struct {
+	// 附件，按在TNEF中出现的顺序排列
+	Attachments []*TNEFAttachment
+	// 解压后的RTF正文，没有时为空
+	RTFBody []byte
+}
+
+// TNEFAttachment TNEF中的附件
+type TNEFAttachment struct {
+	Filename    string
+	ContentType string
+	ContentID   string
+	Data        []byte
+}
+
+// IsTNEF 判断附件是否为TNEF封装（winmail.dat）
+func IsTNEF(mediaType, filename string) bool {
+	switch strings.ToLower(mediaType) {
+	case "application/ms-tnef", "application/vnd.ms-tnef":
+		return true
+	}
+	return strings.EqualFold(filename, "winmail.dat")
+}
+
+// TNEFPartID 生成TNEF中第index个附件的PartID，index为0时表示RTF正文
+func TNEFPartID(parentPartID string, index int) string {
+	if index == 0 {
+		return parentPartID + tnefPartSeparator + "body"
+	}
+	return parentPartID + tnefPartSeparator + strconv.Itoa(index)
+}
+
+// SplitTNEFPartID 拆分从TNEF中取出内容的PartID，返回winmail.dat的PartID和内容序号
+func SplitTNEFPartID(partID string) (string, int, bool) {
+	index := strings.LastIndex(partID, tnefPartSeparator)
+	if index < 0 {
+		return "", 0, false
+	}
+	parent, item := partID[:index], partID[index+len(tnefPartSeparator):]
+	if item == "body" {
+		return parent, 0, true
+	}
+	n, err := strconv.Atoi(item)
+	if err != nil || n <= 0 {
+		return "", 0, false
+	}
+	return parent, n, true
+}
+
+// ExtractTNEFPart 从TNEF内容中取出序号对应的附件，序号为0时取出RTF正文
+func ExtractTNEFPart(data []byte, index int) ([]byte, error) {
+	message, err := DecodeTNEF(data)
+	if err != nil {
+		return nil, err
+	}
+	if index == 0 {
+		if message.RTFBody == nil {
+			return nil, fmt.Errorf("TNEF stream has no RTF body")
+		}
+		return message.RTFBody, nil
+	}
+	if index > len(message.Attachments) {
+		return nil, fmt.Errorf("TNEF attachment %d not found", index)
+	}
+	return message.Attachments[index-1].Data, nil
+}
+
+// DecodeTNEF 解析TNEF内容，取出附件和RTF正文。嵌入的Outlook项目（邮件、联系人等）没有文件内容，不会返回
+func DecodeTNEF(data []byte) (*TNEFMessage, error) {
+	if len(data) < 6 || binary.LittleEndian.Uint32(data) != tnefSignature {
+		return nil, ErrNotTNEF
+	}
+
+	message := &TNEFMessage{}
+	var attachments []*TNEFAttachment
+	var current *TNEFAttachment
+	pos := 6
+	for pos < len(data) {
+		// 每个属性：级别(1) ID(4) 长度(4) 数据 校验和(2)
+		if len(data)-pos < 9 {
+			return nil, ErrTNEFTruncated
+		}
+		level := data[pos]
+		id := binary.LittleEndian.Uint32(data[pos+1:]) & 0xFFFF
+		length := binary.LittleEndian.Uint32(data[pos+5:])
+		pos += 9
+		if uint64(length)+2 > uint64(len(data)-pos) {
+			return nil, ErrTNEFTruncated
+		}
+		value := data[pos : pos+int(length)]
+		pos += int(length) + 2
+
+		switch {
+		case id == tnefAttAttachRendData:
+			current = &TNEFAttachment{}
+			attachments = append(attachments, current)
+		case id == tnefAttAttachTitle && current != nil:
+			if current.Filename == "" {
+				current.Filename = tnefString8(value)
+			}
+		case id == tnefAttAttachData && current != nil:
+			current.Data = value
+		case id == tnefAttAttachment && current != nil:
+			// 附件的MAPI属性，长文件名优先于attAttachTitle中的8.3文件名
+			props := readMAPIProps(value)
+			if name := props.string(mapiAttachLongFilename); name != "" {
+				current.Filename = name
+			} else if name := props.string(mapiAttachFilename); name != "" && current.Filename == "" {
+				current.Filename = name
+			}
+			current.ContentType = props.string(mapiAttachMimeTag)
+			current.ContentID = strings.Trim(props.string(mapiAttachContentID), "<>")
+			if current.Data == nil {
+				if prop, ok := props[mapiAttachDataBin]; ok && prop.typ == mapiTypeBinary {
+					current.Data = prop.data
+				}
+			}
+		case id == tnefAttMAPIProps && level == tnefLevelMessage:
+			props := readMAPIProps(value)
+			if prop, ok := props[mapiRTFCompressed]; ok {
+				rtf, err := decompressRTF(prop.data)
+				if err != nil {
+					return nil, fmt.Errorf("failed to decompress RTF body: %w", err)
+				}
+				message.RTFBody = rtf
+			}
+		}
+	}
+
+	for _, attachment := range attachments {
+		if attachment.Data == nil {
+			continue
+		}
+		if attachment.Filename == "" {
+			attachment.Filename = fmt.Sprintf("attachment_%d", len(message.Attachments)+1)
+		}
+		if attachment.ContentType == "" {
+			attachment.ContentType = mime.TypeByExtension(strings.ToLower(filepath.Ext(attachment.Filename)))
+			if mediaType, _, err := mime.ParseMediaType(attachment.ContentType); err == nil {
+				attachment.ContentType = mediaType
+			} else {
+				attachment.ContentType = "application/octet-stream"
+			}
+		}
+		message.Attachments = append(message.Attachments, attachment)
+	}
+
+	return message, nil
+}
+
+// mapiProp 单个MAPI属性值，多值属性只保留第一个值
+type mapiProp struct {
+	typ  uint16
+	data []byte
+}
+
+type mapiProps map[uint16]mapiProp
+
+// string 取出字符串属性
+func (p mapiProps) string(id uint16) string {
+	prop, ok := p[id]
+	if !ok {
+		return ""
+	}
+	switch prop.typ {
+	case mapiTypeUnicode:
+		return tnefUnicode(prop.data)
+	case mapiTypeString8:
+		return tnefString8(prop.data)
+	}
+	return ""
+}
+
+// readMAPIProps 解析MAPI属性列表，遇到无法识别的属性类型时返回已解析的部分
+func readMAPIProps(data []byte) mapiProps {
+	props := make(mapiProps)
+	r := &tnefReader{data: data}
+	count, ok := r.uint32()
+	for i := uint32(0); ok && i < count; i++ {
+		var typ, id uint16
+		if typ, ok = r.uint16(); !ok {
+			break
+		}
+		if id, ok = r.uint16(); !ok {
+			break
+		}
+
+		// 命名属性：GUID(16) 类型(4) 后跟数字ID或名称
+		if id >= 0x8000 {
+			var kind uint32
+			if !r.skip(16) {
+				break
+			}
+			if kind, ok = r.uint32(); !ok {
+				break
+			}
+			if kind == 0 {
+				ok = r.skip(4)
+			} else {
+				var size uint32
+				if size, ok = r.uint32(); ok {
+					ok = r.skip(padTo4(size))
+				}
+			}
+			if !ok {
+				break
+			}
+		}
+
+		base := typ &^ mapiTypeMulti
+		values := uint32(1)
+		variable := base == mapiTypeObject || base == mapiTypeString8 || base == mapiTypeUnicode || base == mapiTypeBinary
+		if typ&mapiTypeMulti != 0 || variable {
+			if values, ok = r.uint32(); !ok {
+				break
+			}
+		}
+
+		for v := uint32(0); ok && v < values; v++ {
+			var value []byte
+			if variable {
+				var size uint32
+				if size, ok = r.uint32(); !ok {
+					break
+				}
+				if value, ok = r.bytes(size); !ok {
+					break
+				}
+				ok = r.skip(padTo4(size) - size)
+			} else {
+				size := mapiFixedSize(base)
+				if size == 0 {
+					return props
+				}
+				value, ok = r.bytes(size)
+			}
+			if _, exists := props[id]; ok && !exists {
+				props[id] = mapiProp{typ: base, data: value}
+			}
+		}
+	}
+	return props
+}
+
+// mapiFixedSize 定长属性在TNEF中占用的字节数（按4字节对齐），未知类型返回0
+func mapiFixedSize(typ uint16) uint32 {
+	switch typ {
+	case 0x0001, 0x0002, 0x0003, 0x0004, 0x000A, 0x000B:
+		return 4
+	case 0x0005, 0x0006, 0x0007, 0x0014, 0x0040:
+		return 8
+	case 0x0048:
+		return 16
+	}
+	return 0
+}
+
+func padTo4(size uint32) uint32 {
+	return (size + 3) &^ 3
+}
+
+// tnefReader 按小端字节序顺序读取
+type tnefReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *tnefReader) bytes(n uint32) ([]byte, bool) {
+	if uint64(n) > uint64(len(r.data)-r.pos) {
+		return nil, false
+	}
+	value := r.data[r.pos : r.pos+int(n)]
+	r.pos += int(n)
+	return value, true
+}
+
+func (r *tnefReader) skip(n uint32) bool {
+	_, ok := r.bytes(n)
+	return ok
+}
+
+func (r *tnefReader) uint16() (uint16, bool) {
+	value, ok := r.bytes(2)
+	if !ok {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint16(value), true
+}
+
+func (r *tnefReader) uint32() (uint32, bool) {
+	value, ok := r.bytes(4)
+	if !ok {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint32(value), true
+}
+
+// tnefString8 解码以NUL结尾的ANSI字符串，不是合法UTF-8时按Windows-1252解码
+func tnefString8(data []byte) string {
+	if index := bytes.IndexByte(data, 0); index >= 0 {
+		data = data[:index]
+	}
+	if utf8.Valid(data) {
+		return string(data)
+	}
+	if decoded, err := encoding.DecodeCharset(data, "windows-1252"); err == nil {
+		return string(decoded)
+	}
+	return string(data)
+}
+
+// tnefUnicode 解码以NUL结尾的UTF-16LE字符串
+func tnefUnicode(data []byte) string {
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		unit := binary.LittleEndian.Uint16(data[i:])
+		if unit == 0 {
+			break
+		}
+		units = append(units, unit)
+	}
+	return string(utf16.Decode(units))
+}
+
+// decompressRTF 解压PR_RTF_COMPRESSED属性（MS-OXRTFCP）
+func decompressRTF(data []byte) ([]byte, error) {
+	if len(data) < 16 {
+		return nil, ErrTNEFTruncated
+	}
+	compSize := binary.LittleEndian.Uint32(data)
+	rawSize := binary.LittleEndian.Uint32(data[4:])
+	compType := binary.LittleEndian.Uint32(data[8:])
+
+	// compSize不包含自身的4个字节，包含其后12字节的头部
+	body := data[16:]
+	if compSize >= 12 && uint64(compSize-12) < uint64(len(body)) {
+		body = body[:compSize-12]
+	}
+
+	switch compType {
+	case rtfUncompressed:
+		if uint64(rawSize) < uint64(len(body)) {
+			body = body[:rawSize]
+		}
+		return append([]byte(nil), body...), nil
+	case rtfCompressedLZFu:
+	default:
+		return nil, fmt.Errorf("unknown compressed RTF type 0x%08x", compType)
+	}
+
+	// 4096字节的环形字典，以固定前缀初始化
+	var dictionary [4096]byte
+	copy(dictionary[:], rtfDictionaryPrefix)
+	write := len(rtfDictionaryPrefix)
+
+	out := make([]byte, 0, len(body)*2)
+	pos := 0
+	for pos < len(body) {
+		control := body[pos]
+		pos++
+		for bit := uint(0); bit < 8 && pos < len(body); bit++ {
+			if control&(1<<bit) == 0 {
+				b := body[pos]
+				pos++
+				out = append(out, b)
+				dictionary[write] = b
+				write = (write + 1) % len(dictionary)
+				continue
+			}
+
+			// 字典引用：高12位为偏移，低4位为长度减2；偏移等于写入位置时表示结束
+			if pos+1 >= len(body) {
+				return nil, ErrTNEFTruncated
+			}
+			reference := int(body[pos])<<8 | int(body[pos+1])
+			pos += 2
+			offset := reference >> 4
+			if offset == write {
+				return trimRTF(out, rawSize), nil
+			}
+			for i := 0; i < reference&0x0F+2; i++ {
+				b := dictionary[(offset+i)%len(dictionary)]
+				out = append(out, b)
+				dictionary[write] = b
+				write = (write + 1) % len(dictionary)
+			}
+		}
+	}
+
+	return trimRTF(out, rawSize), nil
+}
+
+func trimRTF(out []byte, rawSize uint32) []byte {
+	if uint64(rawSize) < uint64(len(out)) {
+		return out[:rawSize]
+	}
+	return out
+}
@@ -0,0 +1,214 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// tnefBuilder 按TNEF格式拼装测试数据
+type tnefBuilder struct {
+	buf bytes.Buffer
+}
+
+func newTNEFBuilder() *tnefBuilder {
+	b := &tnefBuilder{}
+	binary.Write(&b.buf, binary.LittleEndian, uint32(tnefSignature))
+	binary.Write(&b.buf, binary.LittleEndian, uint16(0x0001))
+	return b
+}
+
+func (b *tnefBuilder) attribute(level byte, id uint32, data []byte) *tnefBuilder {
+	b.buf.WriteByte(level)
+	binary.Write(&b.buf, binary.LittleEndian, id)
+	binary.Write(&b.buf, binary.LittleEndian, uint32(len(data)))
+	b.buf.Write(data)
+	var checksum uint16
+	for _, c := range data {
+		checksum += uint16(c)
+	}
+	binary.Write(&b.buf, binary.LittleEndian, checksum)
+	return b
+}
+
+// mapiVariableProp 单值变长MAPI属性
+func mapiVariableProp(typ, id uint16, value []byte) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, typ)
+	binary.Write(&buf, binary.LittleEndian, id)
+	binary.Write(&buf, binary.LittleEndian, uint32(1))
+	binary.Write(&buf, binary.LittleEndian, uint32(len(value)))
+	buf.Write(value)
+	buf.Write(make([]byte, padTo4(uint32(len(value)))-uint32(len(value))))
+	return buf.Bytes()
+}
+
+func mapiPropList(props ...[]byte) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, uint32(len(props)))
+	for _, prop := range props {
+		buf.Write(prop)
+	}
+	return buf.Bytes()
+}
+
+func utf16Value(s string) []byte {
+	var buf bytes.Buffer
+	for _, unit := range utf16.Encode([]rune(s + "\x00")) {
+		binary.Write(&buf, binary.LittleEndian, unit)
+	}
+	return buf.Bytes()
+}
+
+// compressedRTFExample MS-OXRTFCP 中的压缩RTF示例
+var compressedRTFExample = []byte{
+	0x2d, 0x00, 0x00, 0x00, 0x2b, 0x00, 0x00, 0x00, 0x4c, 0x5a, 0x46, 0x75, 0xf1, 0xc5, 0xc7, 0xa7,
+	0x03, 0x00, 0x0a, 0x00, 0x72, 0x63, 0x70, 0x67, 0x31, 0x32, 0x35, 0x42, 0x32, 0x0a, 0xf3, 0x20,
+	0x68, 0x65, 0x6c, 0x09, 0x00, 0x20, 0x62, 0x77, 0x05, 0xb0, 0x6c, 0x64, 0x7d, 0x0a, 0x80, 0x0f,
+	0xa0,
+}
+
+const rtfExample = "{\\rtf1\\ansi\\ansicpg1252\\pard hello world}\r\n"
+
+func buildWinmail() []byte {
+	// 命名属性和定长属性放在前面，确认解析能正确跳过
+	named := new(bytes.Buffer)
+	binary.Write(named, binary.LittleEndian, uint16(0x0003))
+	binary.Write(named, binary.LittleEndian, uint16(0x8001))
+	named.Write(make([]byte, 16))
+	binary.Write(named, binary.LittleEndian, uint32(0))
+	binary.Write(named, binary.LittleEndian, uint32(0x8233))
+	binary.Write(named, binary.LittleEndian, uint32(42))
+
+	b := newTNEFBuilder()
+	b.attribute(tnefLevelMessage, 0x00069003, mapiPropList(
+		named.Bytes(),
+		mapiVariableProp(mapiTypeBinary, mapiRTFCompressed, compressedRTFExample),
+	))
+
+	b.attribute(tnefLevelAttachment, 0x00069002, make([]byte, 14))
+	b.attribute(tnefLevelAttachment, 0x00018010, []byte("REPORT~1.PDF\x00"))
+	b.attribute(tnefLevelAttachment, 0x0006800F, []byte("%PDF-1.4 report"))
+	b.attribute(tnefLevelAttachment, 0x00069005, mapiPropList(
+		mapiVariableProp(mapiTypeUnicode, mapiAttachLongFilename, utf16Value("季度报告.pdf")),
+	))
+
+	// 嵌入的Outlook项目没有文件内容，应被忽略
+	b.attribute(tnefLevelAttachment, 0x00069002, make([]byte, 14))
+	b.attribute(tnefLevelAttachment, 0x00018010, []byte("Meeting\x00"))
+
+	b.attribute(tnefLevelAttachment, 0x00069002, make([]byte, 14))
+	b.attribute(tnefLevelAttachment, 0x00069005, mapiPropList(
+		mapiVariableProp(mapiTypeString8, mapiAttachLongFilename, []byte("notes.txt\x00")),
+		mapiVariableProp(mapiTypeBinary, mapiAttachDataBin, []byte("plain notes")),
+	))
+	return b.buf.Bytes()
+}
+
+func TestDecompressRTF(t *testing.T) {
+	if len(rtfDictionaryPrefix) != 207 {
+		t.Fatalf("expected 207-byte dictionary prefix, got %d", len(rtfDictionaryPrefix))
+	}
+
+	rtf, err := decompressRTF(compressedRTFExample)
+	if err != nil {
+		t.Fatalf("decompress failed: %v", err)
+	}
+	if string(rtf) != rtfExample {
+		t.Fatalf("unexpected RTF: %q", rtf)
+	}
+}
+
+func TestDecodeTNEF(t *testing.T) {
+	message, err := DecodeTNEF(buildWinmail())
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+
+	if string(message.RTFBody) != rtfExample {
+		t.Fatalf("unexpected RTF body: %q", message.RTFBody)
+	}
+	if len(message.Attachments) != 2 {
+		t.Fatalf("expected 2 attachments, got %d", len(message.Attachments))
+	}
+
+	report := message.Attachments[0]
+	if report.Filename != "季度报告.pdf" || report.ContentType != "application/pdf" || string(report.Data) != "%PDF-1.4 report" {
+		t.Fatalf("unexpected first attachment: %+v", report)
+	}
+	notes := message.Attachments[1]
+	if notes.Filename != "notes.txt" || notes.ContentType != "text/plain" || string(notes.Data) != "plain notes" {
+		t.Fatalf("unexpected second attachment: %+v", notes)
+	}
+
+	if _, err := DecodeTNEF([]byte("not tnef")); err != ErrNotTNEF {
+		t.Fatalf("expected ErrNotTNEF, got %v", err)
+	}
+	data := buildWinmail()
+	if _, err := DecodeTNEF(data[:len(data)-5]); err != ErrTNEFTruncated {
+		t.Fatalf("expected ErrTNEFTruncated, got %v", err)
+	}
+}
+
+func TestParseEmailExpandsWinmailDat(t *testing.T) {
+	raw := strings.Join([]string{
+		"From: sender@example.com",
+		"To: receiver@example.com",
+		"Subject: tnef",
+		"MIME-Version: 1.0",
+		`Content-Type: multipart/mixed; boundary="b1"`,
+		"",
+		"--b1",
+		"Content-Type: text/plain; charset=utf-8",
+		"",
+		"see attached",
+		"--b1",
+		`Content-Type: application/ms-tnef; name="winmail.dat"`,
+		`Content-Disposition: attachment; filename="winmail.dat"`,
+		"Content-Transfer-Encoding: base64",
+		"",
+		base64.StdEncoding.EncodeToString(buildWinmail()),
+		"--b1--",
+		"",
+	}, "\r\n")
+
+	parsed, err := ParseEmail([]byte(raw))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	defer parsed.Cleanup()
+
+	if parsed.TextBody != "see attached" {
+		t.Fatalf("unexpected text body: %q", parsed.TextBody)
+	}
+	if len(parsed.Attachments) != 3 {
+		t.Fatalf("expected 3 attachments, got %d", len(parsed.Attachments))
+	}
+
+	body := parsed.Attachments[0]
+	if body.Filename != TNEFBodyFilename || body.ContentType != "application/rtf" || string(body.Content) != rtfExample {
+		t.Fatalf("unexpected RTF attachment: %+v", body)
+	}
+	if body.PartID != "1.2#tnef-body" || body.Encoding != "base64" {
+		t.Fatalf("unexpected RTF part: %s %s", body.PartID, body.Encoding)
+	}
+	if parsed.Attachments[1].Filename != "季度报告.pdf" || parsed.Attachments[2].Filename != "notes.txt" {
+		t.Fatalf("unexpected attachments: %s, %s", parsed.Attachments[1].Filename, parsed.Attachments[2].Filename)
+	}
+
+	// 重新下载时按PartID从winmail.dat中取出对应内容
+	parent, index, ok := SplitTNEFPartID(parsed.Attachments[2].PartID)
+	if !ok || parent != "1.2" || index != 2 {
+		t.Fatalf("unexpected split: %s %d %v", parent, index, ok)
+	}
+	content, err := ExtractTNEFPart(buildWinmail(), index)
+	if err != nil || string(content) != "plain notes" {
+		t.Fatalf("unexpected extracted content: %q %v", content, err)
+	}
+	if _, _, ok := SplitTNEFPartID("1.2"); ok {
+		t.Fatal("plain part ID should not be treated as TNEF")
+	}
+}
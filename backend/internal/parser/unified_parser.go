@@ -12,7 +12,17 @@ import (
 	"mime/quotedprintable"
 	"net/mail"
 	"net/textproto"
+	"os"
 	"strings"
+
+	"firemail/internal/encoding"
+)
+
+const (
+	// DefaultMemoryLimit 单个部分在内存中缓冲的默认上限，超出后溢出到临时文件
+	DefaultMemoryLimit int64 = 1024 * 1024
+	// DefaultMaxBodySize 文本/HTML正文的默认最大长度，超出部分被截断
+	DefaultMaxBodySize int64 = 10 * 1024 * 1024
 )
 
 // UnifiedParser 统一的MIME解析器
@@ -31,6 +41,14 @@ type ParseOptions struct {
 	StrictMode bool
 	// 最大错误数量
 	MaxErrors int
+	// 单个附件在内存中缓冲的上限（字节），超出后溢出到临时文件
+	MemoryLimit int64
+	// 正文最大长度（字节）
+	MaxBodySize int64
+	// 溢出文件所在目录，为空时使用系统临时目录
+	TempDir string
+	// 强制使用的正文字符集，为空时按声明的字符集解码并自动检测
+	Charset string
 }
 
 // DefaultParseOptions 默认解析选项
@@ -40,6 +58,8 @@ func DefaultParseOptions() *ParseOptions {
 		MaxAttachmentSize:        25 * 1024 * 1024, // 25MB
 		StrictMode:               false,
 		MaxErrors:                10,
+		MemoryLimit:              DefaultMemoryLimit,
+		MaxBodySize:              DefaultMaxBodySize,
 	}
 }
 
@@ -48,8 +68,18 @@ func NewUnifiedParser(options *ParseOptions) *UnifiedParser {
 	if options == nil {
 		options = DefaultParseOptions()
 	}
+
+	// 未设置的缓冲上限使用默认值
+	normalized := *options
+	if normalized.MemoryLimit <= 0 {
+		normalized.MemoryLimit = DefaultMemoryLimit
+	}
+	if normalized.MaxBodySize <= 0 {
+		normalized.MaxBodySize = DefaultMaxBodySize
+	}
+
 	return &UnifiedParser{
-		options: options,
+		options: &normalized,
 	}
 }
 
@@ -85,14 +115,46 @@ type AttachmentInfo struct {
 	Disposition string
 	// 传输编码
 	Encoding string
-	// 内容数据
+	// 内容数据（已解码）
 	Content []byte
+	// 内容超过内存上限时溢出到的临时文件（已解码），使用完毕后需调用ParsedEmail.Cleanup删除
+	ContentPath string
+}
+
+// HasContent 是否携带了附件内容
+func (a *AttachmentInfo) HasContent() bool {
+	return len(a.Content) > 0 || a.ContentPath != ""
+}
+
+// Open 打开附件内容，内容可能位于内存或溢出文件中
+func (a *AttachmentInfo) Open() (io.ReadCloser, error) {
+	if a.ContentPath != "" {
+		return os.Open(a.ContentPath)
+	}
+	return io.NopCloser(bytes.NewReader(a.Content)), nil
+}
+
+// Cleanup 删除解析过程中产生的溢出文件
+func (e *ParsedEmail) Cleanup() {
+	for _, list := range [][]*AttachmentInfo{e.Attachments, e.InlineAttachments} {
+		for _, attachment := range list {
+			if attachment.ContentPath != "" {
+				os.Remove(attachment.ContentPath)
+				attachment.ContentPath = ""
+			}
+		}
+	}
 }
 
 // ParseEmail 解析邮件内容
 func (p *UnifiedParser) ParseEmail(rawEmail []byte) (*ParsedEmail, error) {
+	return p.ParseEmailFromReader(bytes.NewReader(rawEmail))
+}
+
+// ParseEmailFromReader 从Reader流式解析邮件，不会将整封邮件读入内存
+func (p *UnifiedParser) ParseEmailFromReader(reader io.Reader) (*ParsedEmail, error) {
 	// 使用net/mail解析邮件
-	msg, err := mail.ReadMessage(bytes.NewReader(rawEmail))
+	msg, err := mail.ReadMessage(bufio.NewReader(reader))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read message: %w", err)
 	}
@@ -107,6 +169,7 @@ func (p *UnifiedParser) ParseEmail(rawEmail []byte) (*ParsedEmail, error) {
 	// 解析邮件体
 	if err := p.parseMessageBody(msg, result); err != nil {
 		if p.options.StrictMode {
+			result.Cleanup()
 			return nil, fmt.Errorf("failed to parse message body: %w", err)
 		}
 		result.Errors = append(result.Errors, err)
@@ -115,18 +178,6 @@ func (p *UnifiedParser) ParseEmail(rawEmail []byte) (*ParsedEmail, error) {
 	return result, nil
 }
 
-// ParseEmailFromReader 从Reader解析邮件
-func (p *UnifiedParser) ParseEmailFromReader(reader io.Reader) (*ParsedEmail, error) {
-	// 读取所有内容
-	content, err := io.ReadAll(reader)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read content: %w", err)
-	}
-
-	// 使用ParseEmail方法
-	return p.ParseEmail(content)
-}
-
 // parseMessageBody 解析邮件正文
 func (p *UnifiedParser) parseMessageBody(msg *mail.Message, result *ParsedEmail) error {
 	// 获取Content-Type
@@ -282,8 +333,8 @@ func (p *UnifiedParser) parsePartSafely(part *multipart.Part, result *ParsedEmai
 
 // parseMultipartFallback 回退的multipart解析策略
 func (p *UnifiedParser) parseMultipartFallback(body io.Reader, boundary string, result *ParsedEmail, partID string) error {
-	// 读取所有内容到内存中进行手动解析
-	content, err := io.ReadAll(body)
+	// 读取剩余内容到内存中进行手动解析，长度受正文上限约束
+	content, err := io.ReadAll(io.LimitReader(body, p.options.MaxBodySize))
 	if err != nil {
 		return fmt.Errorf("failed to read multipart content: %w", err)
 	}
@@ -415,7 +466,20 @@ func (p *UnifiedParser) decodePartContent(content []byte, headers textproto.MIME
 		return content, err
 	}
 
-	return decoded, nil
+	return p.decodeTextCharset(decoded, headers), nil
+}
+
+// decodeTextCharset 将正文转换为UTF-8，声明的字符集缺失或与内容不符时自动检测
+func (p *UnifiedParser) decodeTextCharset(content []byte, headers textproto.MIMEHeader) []byte {
+	if p.options.Charset != "" {
+		if decoded, err := encoding.DecodeCharset(content, p.options.Charset); err == nil {
+			return decoded
+		}
+	}
+
+	_, params, _ := mime.ParseMediaType(headers.Get("Content-Type"))
+	decoded, _ := encoding.DecodeWithDetection(content, params["charset"])
+	return decoded
 }
 
 // parsePart 解析单个部分
@@ -502,22 +566,27 @@ func (p *UnifiedParser) handleAttachment(reader io.Reader, headers textproto.MIM
 
 	// 读取内容
 	if p.options.IncludeAttachmentContent {
-		content, err := p.readPartContent(reader, headers)
+		content, contentPath, size, err := p.spoolPartContent(reader, headers)
 		if err != nil {
 			return err
 		}
-		
+		attachment.Size = size
+
 		// 检查大小限制
-		if int64(len(content)) > p.options.MaxAttachmentSize {
+		if p.options.MaxAttachmentSize > 0 && size > p.options.MaxAttachmentSize {
 			if p.options.StrictMode {
-				return fmt.Errorf("attachment too large: %d bytes", len(content))
+				return fmt.Errorf("attachment too large: %d bytes", size)
 			}
-			log.Printf("Warning: attachment %s too large (%d bytes), skipping content", filename, len(content))
+			log.Printf("Warning: attachment %s too large (%d bytes), skipping content", filename, size)
 		} else {
 			attachment.Content = content
+			attachment.ContentPath = contentPath
 		}
-		
-		attachment.Size = int64(len(content))
+	}
+
+	// winmail.dat 换成其中的附件和RTF正文，无法解析时保留原附件
+	if IsTNEF(mediaType, filename) && attachment.HasContent() && p.expandTNEF(attachment, result) {
+		return nil
 	}
 
 	// 根据disposition类型添加到相应列表
@@ -530,24 +599,114 @@ func (p *UnifiedParser) handleAttachment(reader io.Reader, headers textproto.MIM
 	return nil
 }
 
-// readPartContent 读取部分内容并解码
+// expandTNEF 把TNEF附件中的附件和RTF正文作为普通附件加入结果，成功时删除winmail.dat的溢出文件。
+// 取出的附件沿用winmail.dat的传输编码，重新下载时按该编码解码winmail.dat后再取出内容
+func (p *UnifiedParser) expandTNEF(tnef *AttachmentInfo, result *ParsedEmail) bool {
+	reader, err := tnef.Open()
+	if err != nil {
+		log.Printf("Warning: failed to open TNEF attachment %s: %v", tnef.PartID, err)
+		return false
+	}
+	data, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		log.Printf("Warning: failed to read TNEF attachment %s: %v", tnef.PartID, err)
+		return false
+	}
+
+	message, err := DecodeTNEF(data)
+	if err != nil {
+		log.Printf("Warning: failed to decode TNEF attachment %s: %v", tnef.PartID, err)
+		return false
+	}
+	if len(message.Attachments) == 0 && message.RTFBody == nil {
+		return false
+	}
+
+	if message.RTFBody != nil {
+		result.Attachments = append(result.Attachments, &AttachmentInfo{
+			PartID:      TNEFPartID(tnef.PartID, 0),
+			Filename:    TNEFBodyFilename,
+			ContentType: "application/rtf",
+			Size:        int64(len(message.RTFBody)),
+			Disposition: "attachment",
+			Encoding:    tnef.Encoding,
+			Content:     message.RTFBody,
+		})
+	}
+	for i, extracted := range message.Attachments {
+		result.Attachments = append(result.Attachments, &AttachmentInfo{
+			PartID:      TNEFPartID(tnef.PartID, i+1),
+			Filename:    extracted.Filename,
+			ContentType: extracted.ContentType,
+			Size:        int64(len(extracted.Data)),
+			ContentID:   extracted.ContentID,
+			Disposition: "attachment",
+			Encoding:    tnef.Encoding,
+			Content:     extracted.Data,
+		})
+	}
+
+	if tnef.ContentPath != "" {
+		os.Remove(tnef.ContentPath)
+	}
+	return true
+}
+
+// readPartContent 读取正文部分并解码，超过正文上限的内容被截断
 func (p *UnifiedParser) readPartContent(reader io.Reader, headers textproto.MIMEHeader) ([]byte, error) {
-	// 读取原始内容
-	content, err := io.ReadAll(reader)
+	decoder, err := newTransferDecoder(reader, headers.Get("Content-Transfer-Encoding"))
 	if err != nil {
-		return nil, fmt.Errorf("failed to read content: %w", err)
+		return nil, fmt.Errorf("failed to decode transfer encoding: %w", err)
 	}
 
-	// 获取传输编码
-	encoding := headers.Get("Content-Transfer-Encoding")
-	
-	// 解码传输编码
-	decoded, err := p.decodeTransferEncoding(content, encoding)
+	decoded, err := io.ReadAll(io.LimitReader(decoder, p.options.MaxBodySize+1))
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode transfer encoding: %w", err)
 	}
 
-	return decoded, nil
+	if int64(len(decoded)) > p.options.MaxBodySize {
+		log.Printf("Warning: body part exceeds %d bytes, truncating", p.options.MaxBodySize)
+		decoded = decoded[:p.options.MaxBodySize]
+	}
+
+	return p.decodeTextCharset(decoded, headers), nil
+}
+
+// spoolPartContent 边解码边缓冲附件内容，超过内存上限时溢出到临时文件；
+// 超过附件大小上限时只统计大小，不保留内容
+func (p *UnifiedParser) spoolPartContent(reader io.Reader, headers textproto.MIMEHeader) ([]byte, string, int64, error) {
+	decoder, err := newTransferDecoder(reader, headers.Get("Content-Transfer-Encoding"))
+	if err != nil {
+		return nil, "", 0, fmt.Errorf("failed to decode transfer encoding: %w", err)
+	}
+
+	spool := newSpoolBuffer(p.options.MemoryLimit, p.options.TempDir)
+	source := decoder
+	if p.options.MaxAttachmentSize > 0 {
+		source = io.LimitReader(decoder, p.options.MaxAttachmentSize+1)
+	}
+
+	if _, err := io.Copy(spool, source); err != nil {
+		spool.discard()
+		return nil, "", 0, fmt.Errorf("failed to decode transfer encoding: %w", err)
+	}
+
+	size := spool.size
+	if p.options.MaxAttachmentSize > 0 && size > p.options.MaxAttachmentSize {
+		spool.discard()
+		rest, err := io.Copy(io.Discard, decoder)
+		if err != nil {
+			return nil, "", 0, fmt.Errorf("failed to read content: %w", err)
+		}
+		return nil, "", size + rest, nil
+	}
+
+	content, path, err := spool.finish()
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return content, path, size, nil
 }
 
 // extractFilename 提取文件名
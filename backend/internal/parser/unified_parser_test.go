@@ -0,0 +1,131 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func buildMessageWithAttachment(content []byte) string {
+	encoded := base64.StdEncoding.EncodeToString(content)
+	var lines []string
+	for len(encoded) > 76 {
+		lines = append(lines, encoded[:76])
+		encoded = encoded[76:]
+	}
+	lines = append(lines, encoded)
+
+	return strings.Join([]string{
+		"From: sender@example.com",
+		"To: receiver@example.com",
+		"Subject: spool test",
+		"MIME-Version: 1.0",
+		`Content-Type: multipart/mixed; boundary="b1"`,
+		"",
+		"--b1",
+		"Content-Type: text/plain; charset=utf-8",
+		"",
+		"hello",
+		"--b1",
+		`Content-Type: application/octet-stream; name="data.bin"`,
+		`Content-Disposition: attachment; filename="data.bin"`,
+		"Content-Transfer-Encoding: base64",
+		"",
+		strings.Join(lines, "\r\n"),
+		"--b1--",
+		"",
+	}, "\r\n")
+}
+
+func TestParseEmailSpillsLargeAttachmentToFile(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789abcdef"), 4096)
+
+	options := DefaultParseOptions()
+	options.MemoryLimit = 1024
+	options.TempDir = t.TempDir()
+	parsed, err := NewUnifiedParser(options).ParseEmailFromReader(strings.NewReader(buildMessageWithAttachment(content)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.TrimSpace(parsed.TextBody) != "hello" {
+		t.Errorf("unexpected text body %q", parsed.TextBody)
+	}
+	if len(parsed.Attachments) != 1 {
+		t.Fatalf("expected 1 attachment, got %d", len(parsed.Attachments))
+	}
+
+	attachment := parsed.Attachments[0]
+	if attachment.ContentPath == "" || attachment.Content != nil {
+		t.Fatalf("expected attachment to spill to a file, got path %q and %d bytes in memory", attachment.ContentPath, len(attachment.Content))
+	}
+	if attachment.Size != int64(len(content)) {
+		t.Errorf("expected size %d, got %d", len(content), attachment.Size)
+	}
+
+	reader, err := attachment.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, content) {
+		t.Error("spilled content does not match the original attachment")
+	}
+
+	path := attachment.ContentPath
+	parsed.Cleanup()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected spool file to be removed, got %v", err)
+	}
+}
+
+func TestParseEmailKeepsSmallAttachmentInMemory(t *testing.T) {
+	content := []byte("small attachment")
+
+	parsed, err := NewUnifiedParser(nil).ParseEmail([]byte(buildMessageWithAttachment(content)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed.Attachments) != 1 {
+		t.Fatalf("expected 1 attachment, got %d", len(parsed.Attachments))
+	}
+	if parsed.Attachments[0].ContentPath != "" || !bytes.Equal(parsed.Attachments[0].Content, content) {
+		t.Errorf("expected in-memory content, got %+v", parsed.Attachments[0])
+	}
+}
+
+func TestParseEmailDetectsUndeclaredCharset(t *testing.T) {
+	body, err := simplifiedchinese.GBK.NewEncoder().Bytes([]byte("您好，附件是本周的工作报告，请查收。"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := "From: sender@qq.com\r\nSubject: report\r\nContent-Type: text/plain\r\n\r\n" + string(body)
+
+	parsed, err := NewUnifiedParser(nil).ParseEmail([]byte(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.TextBody != "您好，附件是本周的工作报告，请查收。" {
+		t.Errorf("unexpected text body %q", parsed.TextBody)
+	}
+
+	// 强制字符集优先于检测结果
+	options := DefaultParseOptions()
+	options.Charset = "big5"
+	parsed, err = NewUnifiedParser(options).ParseEmail([]byte(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.TextBody == "您好，附件是本周的工作报告，请查收。" {
+		t.Error("expected forced charset to override detection")
+	}
+}
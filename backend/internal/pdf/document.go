@@ -0,0 +1,254 @@
+// Package pdf 提供生成归档用PDF的最小实现：标准字体与CJK字体文本、分页排版、JPEG/PNG/GIF图片
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// PageSize 纸张尺寸，单位为点（1/72英寸）
+type PageSize struct {
+	Name   string
+	Width  float64
+	Height float64
+}
+
+var (
+	A4     = PageSize{Name: "A4", Width: 595.28, Height: 841.89}
+	Letter = PageSize{Name: "Letter", Width: 612, Height: 792}
+	Legal  = PageSize{Name: "Legal", Width: 612, Height: 1008}
+)
+
+// ParsePageSize 按名称解析纸张尺寸，不区分大小写，空字符串返回A4
+func ParsePageSize(name string) (PageSize, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "a4":
+		return A4, true
+	case "letter":
+		return Letter, true
+	case "legal":
+		return Legal, true
+	}
+	return PageSize{}, false
+}
+
+// page 单个页面及其内容流
+type page struct {
+	content bytes.Buffer
+	images  map[string]bool
+}
+
+// Document PDF文档
+type Document struct {
+	size    PageSize
+	title   string
+	created time.Time
+	pages   []*page
+	images  []*imageObject
+}
+
+// NewDocument 创建指定纸张尺寸的空文档
+func NewDocument(size PageSize) *Document {
+	return &Document{size: size, created: time.Now()}
+}
+
+// SetTitle 设置文档标题
+func (d *Document) SetTitle(title string) {
+	d.title = title
+}
+
+// PageCount 已创建的页数
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+// addPage 追加新页面
+func (d *Document) addPage() *page {
+	p := &page{images: make(map[string]bool)}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// 固定对象编号：目录、页面树、字体、信息字典，其后依次为图片和页面
+const (
+	objCatalog = iota + 1
+	objPages
+	objFontRegular
+	objFontBold
+	objFontCJK
+	objFontCJKDescendant
+	objFontCJKDescriptor
+	objInfo
+	objFirstDynamic
+)
+
+// WriteTo 输出完整的PDF文件
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.addPage()
+	}
+
+	out := &pdfWriter{w: w}
+	out.printf("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+
+	imageBase := objFirstDynamic
+	pageBase := imageBase + len(d.images)
+	total := pageBase + 2*len(d.pages)
+	offsets := make([]int64, total)
+
+	begin := func(id int) {
+		offsets[id] = out.n
+		out.printf("%d 0 obj\n", id)
+	}
+	end := func() {
+		out.printf("endobj\n")
+	}
+
+	begin(objCatalog)
+	out.printf("<< /Type /Catalog /Pages %d 0 R >>\n", objPages)
+	end()
+
+	begin(objPages)
+	out.printf("<< /Type /Pages /Count %d /Kids [", len(d.pages))
+	for i := range d.pages {
+		out.printf(" %d 0 R", pageBase+2*i)
+	}
+	out.printf(" ] >>\n")
+	end()
+
+	begin(objFontRegular)
+	out.printf("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>\n")
+	end()
+
+	begin(objFontBold)
+	out.printf("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>\n")
+	end()
+
+	begin(objFontCJK)
+	out.printf("<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light /Encoding /UniGB-UTF16-H /DescendantFonts [%d 0 R] >>\n", objFontCJKDescendant)
+	end()
+
+	begin(objFontCJKDescendant)
+	out.printf("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light /CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 4 >> /FontDescriptor %d 0 R /DW 1000 >>\n", objFontCJKDescriptor)
+	end()
+
+	begin(objFontCJKDescriptor)
+	out.printf("<< /Type /FontDescriptor /FontName /STSong-Light /Flags 6 /FontBBox [-25 -254 1000 880] /ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>\n")
+	end()
+
+	begin(objInfo)
+	out.printf("<< /Producer %s /CreationDate (D:%s)", textString("FireMail"), d.created.UTC().Format("20060102150405Z"))
+	if d.title != "" {
+		out.printf(" /Title %s", textString(d.title))
+	}
+	out.printf(" >>\n")
+	end()
+
+	for i, img := range d.images {
+		begin(imageBase + i)
+		out.printf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /%s /BitsPerComponent 8 /Filter /%s /Length %d >>\nstream\n",
+			img.width, img.height, img.colorSpace, img.filter, len(img.data))
+		out.write(img.data)
+		out.printf("\nendstream\n")
+		end()
+	}
+
+	for i, p := range d.pages {
+		pageID := pageBase + 2*i
+		contentID := pageID + 1
+
+		begin(pageID)
+		out.printf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Contents %d 0 R", objPages, num(d.size.Width), num(d.size.Height), contentID)
+		out.printf(" /Resources << /Font << /F1 %d 0 R /F2 %d 0 R /F3 %d 0 R >>", objFontRegular, objFontBold, objFontCJK)
+		if len(p.images) > 0 {
+			out.printf(" /XObject <<")
+			for j, img := range d.images {
+				if p.images[img.name] {
+					out.printf(" /%s %d 0 R", img.name, imageBase+j)
+				}
+			}
+			out.printf(" >>")
+		}
+		out.printf(" >> >>\n")
+		end()
+
+		var compressed bytes.Buffer
+		zw := zlib.NewWriter(&compressed)
+		if _, err := zw.Write(p.content.Bytes()); err != nil {
+			return out.n, err
+		}
+		if err := zw.Close(); err != nil {
+			return out.n, err
+		}
+		begin(contentID)
+		out.printf("<< /Length %d /Filter /FlateDecode >>\nstream\n", compressed.Len())
+		out.write(compressed.Bytes())
+		out.printf("\nendstream\n")
+		end()
+	}
+
+	xref := out.n
+	out.printf("xref\n0 %d\n0000000000 65535 f \n", total)
+	for id := 1; id < total; id++ {
+		out.printf("%010d 00000 n \n", offsets[id])
+	}
+	out.printf("trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", total, objCatalog, objInfo, xref)
+
+	return out.n, out.err
+}
+
+// Bytes 以字节切片返回PDF内容
+func (d *Document) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := d.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// pdfWriter 记录写入偏移量并保留第一个错误
+type pdfWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (p *pdfWriter) write(b []byte) {
+	if p.err != nil {
+		return
+	}
+	n, err := p.w.Write(b)
+	p.n += int64(n)
+	p.err = err
+}
+
+func (p *pdfWriter) printf(format string, args ...interface{}) {
+	p.write([]byte(fmt.Sprintf(format, args...)))
+}
+
+// num 格式化坐标，最多保留两位小数
+func num(v float64) string {
+	s := fmt.Sprintf("%.2f", v)
+	s = strings.TrimRight(s, "0")
+	s = strings.TrimSuffix(s, ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// textString 以带BOM的UTF-16BE十六进制串编码信息字典中的文本
+func textString(s string) string {
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	b.WriteString(">")
+	return b.String()
+}
@@ -0,0 +1,269 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ImageResolver 根据img的src返回图片数据，无法解析时返回false
+type ImageResolver func(src string) ([]byte, bool)
+
+// headingSizes 标题层级对应的字号
+var headingSizes = map[atom.Atom]float64{
+	atom.H1: 18, atom.H2: 15, atom.H3: 13, atom.H4: 11, atom.H5: 11, atom.H6: 11,
+}
+
+// blockElements 前后需要断行的块级元素
+var blockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true, atom.Header: true,
+	atom.Footer: true, atom.Main: true, atom.Nav: true, atom.Aside: true, atom.Address: true,
+	atom.Figure: true, atom.Figcaption: true, atom.Center: true, atom.Form: true, atom.Fieldset: true,
+	atom.Dl: true, atom.Dt: true, atom.Table: true, atom.Tr: true, atom.Caption: true,
+}
+
+// skippedElements 不可见或不可信的内容，连同子节点一起丢弃
+var skippedElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Head: true, atom.Title: true, atom.Noscript: true,
+	atom.Template: true, atom.Iframe: true, atom.Object: true, atom.Embed: true, atom.Svg: true,
+	atom.Button: true, atom.Select: true, atom.Input: true, atom.Textarea: true,
+}
+
+// htmlRenderer 把HTML节点树转换为段落、分隔线和图片
+type htmlRenderer struct {
+	w       *Writer
+	resolve ImageResolver
+	base    ParagraphStyle
+	runs    []Run
+	bold    int
+	pre     int
+	indent  float64
+	size    float64
+}
+
+// HTML 把HTML正文排版进文档。脚本、样式和表单等内容被丢弃；图片只通过resolve获取，
+// 无法获取的图片以替代文本表示，渲染过程不会发起任何网络请求
+func (w *Writer) HTML(body string, resolve ImageResolver, style ParagraphStyle) error {
+	doc, err := html.Parse(strings.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to parse html: %w", err)
+	}
+	if style.Size <= 0 {
+		style.Size = 10
+	}
+
+	r := &htmlRenderer{w: w, resolve: resolve, base: style, indent: style.Indent, size: style.Size}
+	r.walk(doc)
+	r.flush(0)
+	return nil
+}
+
+// text 追加当前样式的文本
+func (r *htmlRenderer) text(s string) {
+	bold := r.bold > 0
+	if n := len(r.runs); n > 0 && r.runs[n-1].Bold == bold {
+		r.runs[n-1].Text += s
+		return
+	}
+	r.runs = append(r.runs, Run{Text: s, Bold: bold})
+}
+
+// plain 尚未输出的文本
+func (r *htmlRenderer) plain() string {
+	var b strings.Builder
+	for _, run := range r.runs {
+		b.WriteString(run.Text)
+	}
+	return b.String()
+}
+
+// flush 把累积的文本作为一个段落输出
+func (r *htmlRenderer) flush(spaceAfter float64) {
+	runs := r.runs
+	r.runs = nil
+
+	empty := true
+	for _, run := range runs {
+		if strings.TrimSpace(run.Text) != "" {
+			empty = false
+			break
+		}
+	}
+	if empty {
+		return
+	}
+
+	style := r.base
+	style.Indent = r.indent
+	style.Size = r.size
+	style.Preformatted = r.pre > 0
+	style.SpaceAfter = spaceAfter
+	r.w.Paragraph(runs, style)
+}
+
+func (r *htmlRenderer) walkChildren(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		r.walk(c)
+	}
+}
+
+func (r *htmlRenderer) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		r.text(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		r.walkChildren(n)
+		return
+	}
+
+	if skippedElements[n.DataAtom] {
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Br:
+		if r.pre > 0 {
+			r.text("\n")
+		} else if len(r.runs) == 0 {
+			r.w.Space(r.size * 1.4)
+		} else {
+			r.flush(0)
+		}
+
+	case atom.Hr:
+		r.flush(0)
+		r.w.Rule()
+
+	case atom.Img:
+		r.image(n)
+
+	case atom.B, atom.Strong, atom.Th:
+		if n.DataAtom == atom.Th {
+			r.text(" ")
+		}
+		r.bold++
+		r.walkChildren(n)
+		r.bold--
+
+	case atom.Td:
+		r.text(" ")
+		r.walkChildren(n)
+
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		r.flush(4)
+		size := r.size
+		r.size = headingSizes[n.DataAtom]
+		r.bold++
+		r.walkChildren(n)
+		r.flush(6)
+		r.bold--
+		r.size = size
+
+	case atom.Pre:
+		r.flush(4)
+		r.pre++
+		r.walkChildren(n)
+		r.flush(4)
+		r.pre--
+
+	case atom.Blockquote, atom.Ul, atom.Ol, atom.Dd:
+		r.flush(4)
+		r.indent += 15
+		if n.DataAtom == atom.Ol {
+			r.orderedList(n)
+		} else {
+			r.walkChildren(n)
+		}
+		r.flush(4)
+		r.indent -= 15
+
+	case atom.Li:
+		r.flush(0)
+		r.text("- ")
+		r.walkChildren(n)
+		r.flush(2)
+
+	case atom.A:
+		// 链接内的块级元素可能已经输出了部分文字，此时不再附加地址
+		mark := len(r.plain())
+		r.walkChildren(n)
+		if plain := r.plain(); len(plain) >= mark {
+			r.linkTarget(n, plain[mark:])
+		}
+
+	default:
+		if blockElements[n.DataAtom] {
+			r.flush(4)
+			r.walkChildren(n)
+			r.flush(4)
+		} else {
+			r.walkChildren(n)
+		}
+	}
+}
+
+// orderedList 为有序列表的每一项加上序号
+func (r *htmlRenderer) orderedList(n *html.Node) {
+	index := 1
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || c.DataAtom != atom.Li {
+			r.walk(c)
+			continue
+		}
+		r.flush(0)
+		r.text(fmt.Sprintf("%d. ", index))
+		r.walkChildren(c)
+		r.flush(2)
+		index++
+	}
+}
+
+// linkTarget 链接文字与地址不同时在文字后附上地址，便于纸面核对
+func (r *htmlRenderer) linkTarget(n *html.Node, label string) {
+	href := strings.TrimSpace(attr(n, "href"))
+	lower := strings.ToLower(href)
+	if !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://") && !strings.HasPrefix(lower, "mailto:") {
+		return
+	}
+
+	text := strings.TrimSpace(label)
+	if text == href || text == strings.TrimPrefix(href, "mailto:") {
+		return
+	}
+	r.text(" <" + href + ">")
+}
+
+// image 嵌入可解析的图片，否则输出替代文本
+func (r *htmlRenderer) image(n *html.Node) {
+	src := strings.TrimSpace(attr(n, "src"))
+	if src != "" && r.resolve != nil {
+		if data, ok := r.resolve(src); ok {
+			r.flush(0)
+			if err := r.w.Image(data); err == nil {
+				return
+			}
+		}
+	}
+
+	alt := strings.TrimSpace(attr(n, "alt"))
+	if alt == "" {
+		alt = "image"
+	} else {
+		alt = "image: " + alt
+	}
+	r.text(" [" + alt + "] ")
+}
+
+// attr 读取元素属性
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"  // 注册GIF解码器
+	_ "image/jpeg" // 注册JPEG解码器
+	_ "image/png"  // 注册PNG解码器
+)
+
+// maxImagePixels 单张图片允许的最大像素数，防止解压炸弹
+const maxImagePixels = 40 * 1000 * 1000
+
+// ErrUnsupportedImage 图片格式无法嵌入PDF
+var ErrUnsupportedImage = errors.New("unsupported image")
+
+// imageObject 嵌入文档的图片
+type imageObject struct {
+	name       string
+	width      int
+	height     int
+	colorSpace string
+	filter     string
+	data       []byte
+}
+
+// addImage 解析图片数据并登记为XObject；RGB/灰度JPEG原样嵌入，其余格式解码后压缩为RGB
+func (d *Document) addImage(data []byte) (*imageObject, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxImagePixels {
+		return nil, fmt.Errorf("%w: invalid dimensions %dx%d", ErrUnsupportedImage, cfg.Width, cfg.Height)
+	}
+
+	img := &imageObject{
+		name:   fmt.Sprintf("Im%d", len(d.images)+1),
+		width:  cfg.Width,
+		height: cfg.Height,
+	}
+
+	if format == "jpeg" && (cfg.ColorModel == color.YCbCrModel || cfg.ColorModel == color.GrayModel) {
+		img.filter = "DCTDecode"
+		img.colorSpace = "DeviceRGB"
+		if cfg.ColorModel == color.GrayModel {
+			img.colorSpace = "DeviceGray"
+		}
+		img.data = data
+	} else {
+		decoded, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+		}
+		if img.data, err = flateRGB(decoded); err != nil {
+			return nil, err
+		}
+		img.filter = "FlateDecode"
+		img.colorSpace = "DeviceRGB"
+	}
+
+	d.images = append(d.images, img)
+	return img, nil
+}
+
+// flateRGB 把图片铺在白色背景上转换为RGB并压缩
+func flateRGB(img image.Image) ([]byte, error) {
+	bounds := img.Bounds()
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	row := make([]byte, 0, bounds.Dx()*3)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		row = row[:0]
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			// 预乘alpha的分量叠加白色背景
+			bg := 0xffff - a
+			row = append(row, byte((r+bg)>>8), byte((g+bg)>>8), byte((b+bg)>>8))
+		}
+		if _, err := zw.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
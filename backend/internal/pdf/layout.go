@@ -0,0 +1,309 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Run 段落中样式一致的一段文本
+type Run struct {
+	Text string
+	Bold bool
+}
+
+// ParagraphStyle 段落样式
+type ParagraphStyle struct {
+	Size         float64 // 字号，默认10
+	Indent       float64 // 左缩进
+	Gray         float64 // 文字灰度，0为黑色
+	Preformatted bool    // 保留空白和换行
+	SpaceAfter   float64 // 段后间距
+}
+
+// Writer 自上而下排版的文档写入器，内容超出页面时自动分页
+type Writer struct {
+	doc    *Document
+	page   *page
+	margin float64
+	y      float64 // 下一行顶部的纵坐标
+}
+
+// NewWriter 创建指定纸张尺寸的写入器
+func NewWriter(size PageSize) *Writer {
+	return &Writer{doc: NewDocument(size), margin: 50}
+}
+
+// Document 底层文档
+func (w *Writer) Document() *Document {
+	return w.doc
+}
+
+// Bytes 输出PDF内容
+func (w *Writer) Bytes() ([]byte, error) {
+	return w.doc.Bytes()
+}
+
+// contentWidth 可排版区域宽度
+func (w *Writer) contentWidth() float64 {
+	return w.doc.size.Width - 2*w.margin
+}
+
+// ensure 保证当前页还能容纳height高度的内容，否则换页
+func (w *Writer) ensure(height float64) {
+	if w.page == nil || w.y-height < w.margin {
+		w.page = w.doc.addPage()
+		w.y = w.doc.size.Height - w.margin
+	}
+}
+
+// Space 插入垂直间距
+func (w *Writer) Space(height float64) {
+	if w.page == nil {
+		return
+	}
+	w.y -= height
+}
+
+// Rule 绘制水平分隔线
+func (w *Writer) Rule() {
+	w.ensure(12)
+	w.y -= 6
+	fmt.Fprintf(&w.page.content, "0.75 G 0.5 w %s %s m %s %s l S 0 G\n",
+		num(w.margin), num(w.y), num(w.doc.size.Width-w.margin), num(w.y))
+	w.y -= 6
+}
+
+// Text 以单一样式写入段落
+func (w *Writer) Text(text string, bold bool, style ParagraphStyle) {
+	w.Paragraph([]Run{{Text: text, Bold: bold}}, style)
+}
+
+// Paragraph 写入段落，按宽度自动换行
+func (w *Writer) Paragraph(runs []Run, style ParagraphStyle) {
+	if style.Size <= 0 {
+		style.Size = 10
+	}
+	width := w.contentWidth() - style.Indent
+	if width < style.Size {
+		width = style.Size
+	}
+
+	var lines [][]Run
+	if style.Preformatted {
+		lines = wrapPreformatted(runs, style.Size, width)
+	} else {
+		lines = wrapRuns(runs, style.Size, width)
+	}
+
+	lineHeight := style.Size * 1.4
+	for _, line := range lines {
+		w.ensure(lineHeight)
+		baseline := w.y - style.Size*1.1
+		w.drawLine(line, w.margin+style.Indent, baseline, style)
+		w.y -= lineHeight
+	}
+	if len(lines) > 0 {
+		w.y -= style.SpaceAfter
+	}
+}
+
+// drawLine 输出一行文本
+func (w *Writer) drawLine(line []Run, x, baseline float64, style ParagraphStyle) {
+	c := &w.page.content
+	if style.Gray > 0 {
+		fmt.Fprintf(c, "%s g %s G\n", num(style.Gray), num(style.Gray))
+	}
+	for _, run := range line {
+		for _, fr := range fontRuns(run.Text, run.Bold) {
+			// 宋体没有粗体字形，以描边模拟加粗
+			fakeBold := fr.font == fontCJK && run.Bold
+			c.WriteString("BT ")
+			if fakeBold {
+				fmt.Fprintf(c, "2 Tr %s w ", num(style.Size/30))
+			}
+			fmt.Fprintf(c, "/%s %s Tf %s %s Td %s Tj ",
+				fr.font.resource(), num(style.Size), num(x), num(baseline), encodeText(fr.text, fr.font))
+			if fakeBold {
+				c.WriteString("0 Tr ")
+			}
+			c.WriteString("ET\n")
+			x += textWidth(fr.text, run.Bold, style.Size)
+		}
+	}
+	if style.Gray > 0 {
+		c.WriteString("0 g 0 G\n")
+	}
+}
+
+// Image 按可排版宽度等比缩放插入图片
+func (w *Writer) Image(data []byte) error {
+	img, err := w.doc.addImage(data)
+	if err != nil {
+		return err
+	}
+
+	// 按96dpi换算为点，超出可排版区域时缩小
+	width := float64(img.width) * 0.75
+	height := float64(img.height) * 0.75
+	maxWidth := w.contentWidth()
+	maxHeight := w.doc.size.Height - 2*w.margin
+	if width > maxWidth {
+		height *= maxWidth / width
+		width = maxWidth
+	}
+	if height > maxHeight {
+		width *= maxHeight / height
+		height = maxHeight
+	}
+
+	w.ensure(height + 4)
+	w.page.images[img.name] = true
+	fmt.Fprintf(&w.page.content, "q %s 0 0 %s %s %s cm /%s Do Q\n",
+		num(width), num(height), num(w.margin), num(w.y-height), img.name)
+	w.y -= height + 4
+	return nil
+}
+
+// token 换行的最小单位：连续的拉丁字符、单个CJK字符或空白
+type token struct {
+	text  string
+	bold  bool
+	space bool
+}
+
+// tokenize 把文本切分为可换行的单位
+func tokenize(runs []Run) []token {
+	var tokens []token
+	for _, run := range runs {
+		var word strings.Builder
+		flush := func() {
+			if word.Len() > 0 {
+				tokens = append(tokens, token{text: word.String(), bold: run.Bold})
+				word.Reset()
+			}
+		}
+		for _, r := range run.Text {
+			switch {
+			case unicode.IsSpace(r):
+				flush()
+				tokens = append(tokens, token{text: " ", bold: run.Bold, space: true})
+			case !isLatin(r):
+				flush()
+				tokens = append(tokens, token{text: string(r), bold: run.Bold})
+			default:
+				word.WriteRune(r)
+			}
+		}
+		flush()
+	}
+	return tokens
+}
+
+// lineBuilder 累积一行内的文本并合并相同样式
+type lineBuilder struct {
+	runs  []Run
+	width float64
+}
+
+func (l *lineBuilder) add(text string, bold bool, width float64) {
+	if n := len(l.runs); n > 0 && l.runs[n-1].Bold == bold {
+		l.runs[n-1].Text += text
+	} else {
+		l.runs = append(l.runs, Run{Text: text, Bold: bold})
+	}
+	l.width += width
+}
+
+// trimTrailingSpace 去掉行尾空白
+func (l *lineBuilder) trimTrailingSpace() {
+	for n := len(l.runs); n > 0; n = len(l.runs) {
+		trimmed := strings.TrimRight(l.runs[n-1].Text, " ")
+		if trimmed != "" {
+			l.runs[n-1].Text = trimmed
+			return
+		}
+		l.runs = l.runs[:n-1]
+	}
+}
+
+// wrapRuns 折叠空白后贪心换行，超长单词按字符拆分
+func wrapRuns(runs []Run, size, width float64) [][]Run {
+	var lines [][]Run
+	line := &lineBuilder{}
+	newLine := func() {
+		line.trimTrailingSpace()
+		lines = append(lines, line.runs)
+		line = &lineBuilder{}
+	}
+
+	lastSpace := true
+	for _, tok := range tokenize(runs) {
+		if tok.space {
+			if lastSpace || len(line.runs) == 0 {
+				continue
+			}
+			lastSpace = true
+			line.add(" ", tok.bold, textWidth(" ", tok.bold, size))
+			continue
+		}
+		lastSpace = false
+
+		tw := textWidth(tok.text, tok.bold, size)
+		if line.width+tw <= width {
+			line.add(tok.text, tok.bold, tw)
+			continue
+		}
+		if len(line.runs) > 0 {
+			newLine()
+		}
+		if tw <= width {
+			line.add(tok.text, tok.bold, tw)
+			continue
+		}
+		for _, r := range tok.text {
+			rw := runeWidth(r, tok.bold, size)
+			if line.width+rw > width && len(line.runs) > 0 {
+				newLine()
+			}
+			line.add(string(r), tok.bold, rw)
+		}
+	}
+	if len(line.runs) > 0 {
+		newLine()
+	}
+	return lines
+}
+
+// wrapPreformatted 保留原始换行和空白，超宽的行按字符折行
+func wrapPreformatted(runs []Run, size, width float64) [][]Run {
+	var lines [][]Run
+	line := &lineBuilder{}
+	newLine := func() {
+		lines = append(lines, line.runs)
+		line = &lineBuilder{}
+	}
+
+	for _, run := range runs {
+		text := strings.ReplaceAll(run.Text, "\r\n", "\n")
+		text = strings.ReplaceAll(text, "\t", "    ")
+		for _, r := range text {
+			if r == '\n' {
+				newLine()
+				continue
+			}
+			if r < 0x20 {
+				continue
+			}
+			rw := runeWidth(r, run.Bold, size)
+			if line.width+rw > width && len(line.runs) > 0 {
+				newLine()
+			}
+			line.add(string(r), run.Bold, rw)
+		}
+	}
+	if len(line.runs) > 0 {
+		newLine()
+	}
+	return lines
+}
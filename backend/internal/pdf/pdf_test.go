@@ -0,0 +1,148 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// pageContents 解压文档中所有FlateDecode内容流
+func pageContents(t *testing.T, data []byte) string {
+	t.Helper()
+	var out strings.Builder
+	re := regexp.MustCompile(`(?s)/Length (\d+) /Filter /FlateDecode >>\nstream\n`)
+	for _, loc := range re.FindAllSubmatchIndex(data, -1) {
+		length, err := strconv.Atoi(string(data[loc[2]:loc[3]]))
+		require.NoError(t, err)
+		zr, err := zlib.NewReader(bytes.NewReader(data[loc[1] : loc[1]+length]))
+		require.NoError(t, err)
+		content, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		out.Write(content)
+	}
+	return out.String()
+}
+
+func TestDocumentStructure(t *testing.T) {
+	doc := NewDocument(Letter)
+	doc.SetTitle("季度报告")
+	data, err := doc.Bytes()
+	require.NoError(t, err)
+
+	require.True(t, bytes.HasPrefix(data, []byte("%PDF-1.4")))
+	require.True(t, bytes.HasSuffix(data, []byte("%%EOF\n")))
+	require.Contains(t, string(data), "/MediaBox [0 0 612 792]")
+	require.Contains(t, string(data), "/Title <FEFF5B635EA662A5544A>")
+
+	// xref中的偏移量必须指向对应对象
+	xref := regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(data)
+	require.NotNil(t, xref)
+	offset, err := strconv.Atoi(string(xref[1]))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(data[offset:], []byte("xref\n")))
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(data, -1)
+	require.NotEmpty(t, entries)
+	for i, entry := range entries {
+		pos, err := strconv.Atoi(string(entry[1]))
+		require.NoError(t, err)
+		require.True(t, bytes.HasPrefix(data[pos:], []byte(strconv.Itoa(i+1)+" 0 obj")), "object %d", i+1)
+	}
+}
+
+func TestParsePageSize(t *testing.T) {
+	size, ok := ParsePageSize("")
+	require.True(t, ok)
+	require.Equal(t, A4, size)
+
+	size, ok = ParsePageSize("LEGAL")
+	require.True(t, ok)
+	require.Equal(t, Legal, size)
+
+	_, ok = ParsePageSize("tabloid")
+	require.False(t, ok)
+}
+
+func TestWrapRuns(t *testing.T) {
+	lines := wrapRuns([]Run{{Text: "hello   world  "}, {Text: "again", Bold: true}}, 10, 60)
+	require.Equal(t, [][]Run{
+		{{Text: "hello world"}},
+		{{Text: "again", Bold: true}},
+	}, lines)
+
+	// CJK字符逐字换行，超长单词按字符拆分
+	lines = wrapRuns([]Run{{Text: "中文换行"}}, 10, 25)
+	require.Equal(t, [][]Run{{{Text: "中文"}}, {{Text: "换行"}}}, lines)
+
+	lines = wrapRuns([]Run{{Text: "aaaaaaaaaa"}}, 10, 30)
+	require.Len(t, lines, 2)
+}
+
+func TestEncodeText(t *testing.T) {
+	require.Equal(t, `(a\(b\)\\ \351)`, encodeText("a(b)\\ é", fontRegular))
+	require.Equal(t, "<4E2D6587>", encodeText("中文", fontCJK))
+	require.Equal(t, []fontRun{{text: "Re: ", font: fontBold}, {text: "报告", font: fontCJK}}, fontRuns("Re: 报告", true))
+}
+
+func TestHTMLRendering(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	img.Set(1, 1, color.NRGBA{R: 255, A: 128})
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+
+	var requested []string
+	resolve := func(src string) ([]byte, bool) {
+		requested = append(requested, src)
+		if src == "cid:logo" {
+			return buf.Bytes(), true
+		}
+		return nil, false
+	}
+
+	w := NewWriter(A4)
+	err := w.HTML(`<html><head><style>p{color:red}</style><script>alert(1)</script></head>
+<body><h1>Title</h1><p>Visit <a href="https://example.com">our site</a></p>
+<img src="cid:logo"><img src="https://tracker.example/pixel.gif" alt="pixel">
+<ol><li>first</li><li>second</li></ol><pre>line 1
+  line 2</pre></body></html>`, resolve, ParagraphStyle{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"cid:logo", "https://tracker.example/pixel.gif"}, requested)
+
+	data, err := w.Bytes()
+	require.NoError(t, err)
+	require.Contains(t, string(data), "/Subtype /Image /Width 4 /Height 4")
+
+	content := pageContents(t, data)
+	require.Contains(t, content, "(Title)")
+	require.Contains(t, content, "(Visit our site <https://example.com>)")
+	require.Contains(t, content, "([image: pixel])")
+	require.Contains(t, content, "(1. first)")
+	require.Contains(t, content, "(  line 2)")
+	require.Contains(t, content, "/Im1 Do")
+	require.NotContains(t, content, "alert")
+	require.NotContains(t, content, "color:red")
+}
+
+func TestWriterPaginates(t *testing.T) {
+	w := NewWriter(A4)
+	for i := 0; i < 200; i++ {
+		w.Text("paragraph "+strconv.Itoa(i), false, ParagraphStyle{})
+	}
+	require.Greater(t, w.Document().PageCount(), 1)
+
+	data, err := w.Bytes()
+	require.NoError(t, err)
+	require.Contains(t, string(data), "/Count "+strconv.Itoa(w.Document().PageCount()))
+}
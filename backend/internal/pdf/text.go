@@ -0,0 +1,144 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// fontKind 文本实际使用的字体
+type fontKind int
+
+const (
+	fontRegular fontKind = iota
+	fontBold
+	fontCJK
+)
+
+// resource 字体在页面资源中的名称
+func (f fontKind) resource() string {
+	switch f {
+	case fontBold:
+		return "F2"
+	case fontCJK:
+		return "F3"
+	}
+	return "F1"
+}
+
+// helveticaWidths Helvetica字形宽度（千分之一字号），覆盖0x20-0x7E
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// helveticaBoldWidths Helvetica-Bold字形宽度，覆盖0x20-0x7E
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
+
+// isLatin 能否用WinAnsi编码的标准字体输出
+func isLatin(r rune) bool {
+	return (r >= 0x20 && r <= 0x7E) || (r >= 0xA0 && r <= 0xFF)
+}
+
+// fontFor 按字符和粗细选择字体
+func fontFor(r rune, bold bool) fontKind {
+	if !isLatin(r) {
+		return fontCJK
+	}
+	if bold {
+		return fontBold
+	}
+	return fontRegular
+}
+
+// runeWidth 单个字符在指定字号下的宽度
+func runeWidth(r rune, bold bool, size float64) float64 {
+	w := 1000
+	if isLatin(r) {
+		w = 556
+		if r <= 0x7E {
+			if bold {
+				w = helveticaBoldWidths[r-0x20]
+			} else {
+				w = helveticaWidths[r-0x20]
+			}
+		}
+	}
+	return float64(w) * size / 1000
+}
+
+// textWidth 字符串在指定字号下的宽度
+func textWidth(s string, bold bool, size float64) float64 {
+	var w float64
+	for _, r := range s {
+		w += runeWidth(r, bold, size)
+	}
+	return w
+}
+
+// encodeText 把同一字体的文本编码为PDF字符串
+func encodeText(s string, font fontKind) string {
+	var b strings.Builder
+	if font == fontCJK {
+		b.WriteString("<")
+		for _, u := range utf16.Encode([]rune(s)) {
+			fmt.Fprintf(&b, "%04X", u)
+		}
+		b.WriteString(">")
+		return b.String()
+	}
+
+	b.WriteString("(")
+	for _, r := range s {
+		switch r {
+		case '(', ')', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		default:
+			if r > 0x7E {
+				fmt.Fprintf(&b, "\\%03o", r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+// fontRun 使用同一字体的一段文本
+type fontRun struct {
+	text string
+	font fontKind
+}
+
+// fontRuns 按字体切分文本
+func fontRuns(s string, bold bool) []fontRun {
+	var runs []fontRun
+	var cur strings.Builder
+	curFont := fontKind(-1)
+	for _, r := range s {
+		f := fontFor(r, bold)
+		if f != curFont && cur.Len() > 0 {
+			runs = append(runs, fontRun{text: cur.String(), font: curFont})
+			cur.Reset()
+		}
+		curFont = f
+		cur.WriteRune(r)
+	}
+	if cur.Len() > 0 {
+		runs = append(runs, fontRun{text: cur.String(), font: curFont})
+	}
+	return runs
+}
@@ -0,0 +1,194 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// minBandwidthChunk 单次读写的最小字节数，避免预算很小时频繁系统调用
+const minBandwidthChunk = 4 * 1024
+
+// ByteBudget 按字节计的令牌桶，同一账户的所有IMAP连接共享，用于限制同步占用的带宽
+type ByteBudget struct {
+	mutex  sync.Mutex
+	rate   float64 // 每秒可用字节数
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time                           // 测试时替换
+	sleep  func(context.Context, time.Duration) error // 测试时替换
+}
+
+// NewByteBudget 创建每分钟 bytesPerMinute 字节的预算，小于等于0时返回nil表示不限制
+func NewByteBudget(bytesPerMinute int) *ByteBudget {
+	if bytesPerMinute <= 0 {
+		return nil
+	}
+	rate := float64(bytesPerMinute) / 60
+	burst := rate
+	if burst < minBandwidthChunk {
+		burst = minBandwidthChunk
+	}
+	return &ByteBudget{
+		rate:   rate,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+		now:    time.Now,
+		sleep:  sleepWithContext,
+	}
+}
+
+// chunk 单次读写的最大字节数，不超过一次突发的额度
+func (b *ByteBudget) chunk() int {
+	return int(b.burst)
+}
+
+// reserve 扣除n字节的额度，额度不足时返回需要等待的时间（允许透支，等待后偿还）
+func (b *ByteBudget) reserve(n int) time.Duration {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	now := b.now()
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+	}
+
+	b.tokens -= float64(n)
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// consume 扣除n字节并在额度不足时等待，ctx取消时提前返回
+func (b *ByteBudget) consume(ctx context.Context, n int) error {
+	if n <= 0 {
+		return nil
+	}
+	if wait := b.reserve(n); wait > 0 {
+		return b.sleep(ctx, wait)
+	}
+	return nil
+}
+
+// throttledConn 按字节预算限制读写速度的连接。
+// 等待额度时遵守连接的读写截止时间，连接关闭后立即返回
+type throttledConn struct {
+	net.Conn
+	budget *ByteBudget
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mutex         sync.Mutex
+	readDeadline  time.Time
+	writeDeadline time.Time
+}
+
+// throttleConn 用预算包装连接，预算为nil时原样返回
+func throttleConn(conn net.Conn, budget *ByteBudget) net.Conn {
+	if budget == nil {
+		return conn
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &throttledConn{Conn: conn, budget: budget, ctx: ctx, cancel: cancel}
+}
+
+// consume 扣除额度并等待，超过截止时间返回超时错误，连接关闭后返回 net.ErrClosed
+func (c *throttledConn) consume(n int, deadline time.Time) error {
+	ctx := c.ctx
+	if !deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+	err := c.budget.consume(ctx, n)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, context.DeadlineExceeded):
+		return os.ErrDeadlineExceeded
+	case c.ctx.Err() != nil:
+		return net.ErrClosed
+	default:
+		return err
+	}
+}
+
+// Read 读取后按实际字节数扣除额度
+func (c *throttledConn) Read(p []byte) (int, error) {
+	if chunk := c.budget.chunk(); len(p) > chunk {
+		p = p[:chunk]
+	}
+	n, err := c.Conn.Read(p)
+	if waitErr := c.consume(n, c.deadline(&c.readDeadline)); err == nil {
+		err = waitErr
+	}
+	return n, err
+}
+
+// Write 分块写入，每块写入前扣除额度
+func (c *throttledConn) Write(p []byte) (int, error) {
+	written := 0
+	chunk := c.budget.chunk()
+	for written < len(p) {
+		end := written + chunk
+		if end > len(p) {
+			end = len(p)
+		}
+		if err := c.consume(end-written, c.deadline(&c.writeDeadline)); err != nil {
+			return written, err
+		}
+		n, err := c.Conn.Write(p[written:end])
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// Close 关闭连接并结束正在进行的等待
+func (c *throttledConn) Close() error {
+	c.cancel()
+	return c.Conn.Close()
+}
+
+// SetDeadline 同时设置读写截止时间
+func (c *throttledConn) SetDeadline(t time.Time) error {
+	c.mutex.Lock()
+	c.readDeadline, c.writeDeadline = t, t
+	c.mutex.Unlock()
+	return c.Conn.SetDeadline(t)
+}
+
+// SetReadDeadline 设置读截止时间
+func (c *throttledConn) SetReadDeadline(t time.Time) error {
+	c.mutex.Lock()
+	c.readDeadline = t
+	c.mutex.Unlock()
+	return c.Conn.SetReadDeadline(t)
+}
+
+// SetWriteDeadline 设置写截止时间
+func (c *throttledConn) SetWriteDeadline(t time.Time) error {
+	c.mutex.Lock()
+	c.writeDeadline = t
+	c.mutex.Unlock()
+	return c.Conn.SetWriteDeadline(t)
+}
+
+// deadline 读取截止时间
+func (c *throttledConn) deadline(t *time.Time) time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return *t
+}
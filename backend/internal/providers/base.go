@@ -29,6 +29,8 @@ type BaseProvider struct {
 	smtpConnected     bool // SMTP连接状态
 	mutex             sync.RWMutex
 	tokenUpdateCallback TokenUpdateCallback // OAuth2 token更新回调
+	rateLimitAccountID  uint                // 当前连接账户，用于限速
+	releaseConnection   func()              // 释放连接名额
 }
 
 // NewBaseProvider 创建基础提供商
@@ -156,14 +158,59 @@ func (p *BaseProvider) Connect(ctx context.Context, account *models.EmailAccount
 		return nil
 	}
 
+	if err := p.acquireConnectionSlot(ctx, account); err != nil {
+		return err
+	}
+
 	// 根据认证方式连接
+	var err error
 	switch account.AuthMethod {
 	case "password":
-		return p.connectWithPassword(ctx, account)
+		err = p.connectWithPassword(ctx, account)
 	case "oauth2":
-		return p.connectWithOAuth2(ctx, account)
+		err = p.connectWithOAuth2(ctx, account)
 	default:
-		return fmt.Errorf("unsupported auth method: %s", account.AuthMethod)
+		err = fmt.Errorf("unsupported auth method: %s", account.AuthMethod)
+	}
+
+	if !p.connected {
+		p.releaseConnectionSlot()
+	}
+	return ClassifyProviderError(p.config.Name, err)
+}
+
+// clientIMAPIDInfo 登录后通过IMAP ID发送的客户端身份信息，网易、QQ等邮箱要求提供
+func clientIMAPIDInfo() map[string]string {
+	return map[string]string{
+		"name":          "FireMail",
+		"version":       "1.0.0",
+		"vendor":        "FireMail Team",
+		"support-email": "support@firemail.com",
+		"os":            "Linux",
+		"os-version":    "Ubuntu 20.04",
+	}
+}
+
+// acquireConnectionSlot 按账户限制并发连接数（调用方需持有锁）
+func (p *BaseProvider) acquireConnectionSlot(ctx context.Context, account *models.EmailAccount) error {
+	p.rateLimitAccountID = account.ID
+	if p.releaseConnection != nil {
+		return nil
+	}
+
+	release, err := GetGlobalRateLimiter().AcquireConnection(ctx, p.config.Name, account.ID)
+	if err != nil {
+		return err
+	}
+	p.releaseConnection = release
+	return nil
+}
+
+// releaseConnectionSlot 释放连接名额（调用方需持有锁）
+func (p *BaseProvider) releaseConnectionSlot() {
+	if p.releaseConnection != nil {
+		p.releaseConnection()
+		p.releaseConnection = nil
 	}
 }
 
@@ -178,11 +225,18 @@ func (p *BaseProvider) connectWithPassword(ctx context.Context, account *models.
 	// 连接IMAP
 	if p.imapClient != nil {
 		imapConfig := IMAPClientConfig{
-			Host:     account.IMAPHost,
-			Port:     account.IMAPPort,
-			Security: account.IMAPSecurity,
-			Username: account.Username,
-			Password: account.Password,
+			Host:        account.IMAPHost,
+			Port:        account.IMAPPort,
+			Security:    account.IMAPSecurity,
+			Username:    account.Username,
+			Password:    account.Password,
+			MaxPartSize: account.MaxPartSize,
+			Bandwidth:   GetGlobalRateLimiter().SyncBandwidth(p.config.Name, account.ID),
+			TLS:         TLSOptionsForAccount(account),
+			Resolver:    ResolverForAccount(account),
+		}
+		if p.config.Quirks != nil && p.config.Quirks.RequiresIMAPID {
+			imapConfig.IMAPIDInfo = clientIMAPIDInfo()
 		}
 		if err := p.imapClient.Connect(ctx, imapConfig); err != nil {
 			imapErr = fmt.Errorf("failed to connect IMAP: %w", err)
@@ -201,6 +255,9 @@ func (p *BaseProvider) connectWithPassword(ctx context.Context, account *models.
 			Security: account.SMTPSecurity,
 			Username: account.Username,
 			Password: account.Password,
+			EHLOName: IdentityForAccount(account).EHLOName,
+			TLS:      TLSOptionsForAccount(account),
+			Resolver: ResolverForAccount(account),
 		}
 		if err := p.smtpClient.Connect(ctx, smtpConfig); err != nil {
 			smtpErr = fmt.Errorf("failed to connect SMTP: %w", err)
@@ -304,6 +361,10 @@ func (p *BaseProvider) connectWithOAuth2(ctx context.Context, account *models.Em
 			Security:    account.IMAPSecurity,
 			Username:    account.Username,
 			OAuth2Token: oauth2Token,
+			MaxPartSize: account.MaxPartSize,
+			Bandwidth:   GetGlobalRateLimiter().SyncBandwidth(p.config.Name, account.ID),
+			TLS:         TLSOptionsForAccount(account),
+			Resolver:    ResolverForAccount(account),
 		}
 		if err := p.imapClient.Connect(ctx, imapConfig); err != nil {
 			imapErr = fmt.Errorf("failed to connect IMAP with OAuth2: %w", err)
@@ -322,6 +383,9 @@ func (p *BaseProvider) connectWithOAuth2(ctx context.Context, account *models.Em
 			Security:    account.SMTPSecurity,
 			Username:    account.Username,
 			OAuth2Token: oauth2Token,
+			EHLOName:    IdentityForAccount(account).EHLOName,
+			TLS:         TLSOptionsForAccount(account),
+			Resolver:    ResolverForAccount(account),
 		}
 		if err := p.smtpClient.Connect(ctx, smtpConfig); err != nil {
 			smtpErr = fmt.Errorf("failed to connect SMTP with OAuth2: %w", err)
@@ -389,6 +453,7 @@ func (p *BaseProvider) Disconnect() error {
 
 	// 更新总体连接状态
 	p.connected = p.imapConnected || p.smtpConnected
+	p.releaseConnectionSlot()
 
 	if len(errors) > 0 {
 		return fmt.Errorf("disconnect errors: %v", errors)
@@ -440,14 +505,20 @@ func (p *BaseProvider) TestConnection(ctx context.Context, account *models.Email
 	return nil
 }
 
-// IMAPClient 获取IMAP客户端
+// IMAPClient 获取IMAP客户端（连接账户后附带拉取限速）
 func (p *BaseProvider) IMAPClient() IMAPClient {
-	return p.imapClient
+	if p.imapClient == nil || p.rateLimitAccountID == 0 {
+		return p.imapClient
+	}
+	return newRateLimitedIMAPClient(p.imapClient, GetGlobalRateLimiter(), p.config.Name, p.rateLimitAccountID)
 }
 
-// SMTPClient 获取SMTP客户端
+// SMTPClient 获取SMTP客户端（连接账户后附带发送限速）
 func (p *BaseProvider) SMTPClient() SMTPClient {
-	return p.smtpClient
+	if p.smtpClient == nil || p.rateLimitAccountID == 0 {
+		return p.smtpClient
+	}
+	return newRateLimitedSMTPClient(p.smtpClient, GetGlobalRateLimiter(), p.config.Name, p.rateLimitAccountID)
 }
 
 // OAuth2Client 获取OAuth2客户端
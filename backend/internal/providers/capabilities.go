@@ -47,6 +47,7 @@ type ProviderCapabilities struct {
 // ProviderLimits 提供商限制
 type ProviderLimits struct {
 	AttachmentSize  int64 `json:"attachment_size"`   // 附件大小限制（字节）
+	MessageSize     int64 `json:"message_size"`      // 整封邮件编码后的大小限制（字节）
 	DailySend       int   `json:"daily_send"`        // 每日发送限制
 	HourlySend      int   `json:"hourly_send"`       // 每小时发送限制
 	RateLimitWindow int   `json:"rate_limit_window"` // 频率限制窗口（秒）
@@ -151,6 +152,7 @@ func (d *StandardCapabilityDetector) DetectCapabilities(ctx context.Context, acc
 	if limits, ok := providerInfo["limits"].(map[string]interface{}); ok {
 		capabilities.Limits = ProviderLimits{
 			AttachmentSize:  getInt64(limits, "attachment_size"),
+			MessageSize:     getInt64(limits, "message_size"),
 			DailySend:       getInt(limits, "daily_send"),
 			HourlySend:      getInt(limits, "hourly_send"),
 			RateLimitWindow: getInt(limits, "rate_limit_window"),
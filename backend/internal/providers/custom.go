@@ -31,6 +31,11 @@ func NewCustomProvider(config *config.EmailProviderConfig) EmailProvider {
 
 // Connect 连接到自定义邮件服务器
 func (p *CustomProvider) Connect(ctx context.Context, account *models.EmailAccount) error {
+	return ClassifyProviderError(p.GetName(), p.connect(ctx, account))
+}
+
+// connect 执行实际的连接流程
+func (p *CustomProvider) connect(ctx context.Context, account *models.EmailAccount) error {
 	// 验证自定义配置
 	if err := p.validateCustomConfig(account); err != nil {
 		return fmt.Errorf("custom provider validation failed: %w", err)
@@ -160,6 +165,8 @@ func (p *CustomProvider) testIMAPConnection(ctx context.Context, account *models
 			Security: account.IMAPSecurity,
 			Username: account.Username,
 			Password: account.Password,
+			TLS:      TLSOptionsForAccount(account),
+			Resolver: ResolverForAccount(account),
 		}
 	case "oauth2":
 		tokenData, err := account.GetOAuth2Token()
@@ -181,6 +188,8 @@ func (p *CustomProvider) testIMAPConnection(ctx context.Context, account *models
 			Security:    account.IMAPSecurity,
 			Username:    account.Username,
 			OAuth2Token: oauth2Token,
+			TLS:         TLSOptionsForAccount(account),
+			Resolver:    ResolverForAccount(account),
 		}
 	}
 
@@ -209,6 +218,9 @@ func (p *CustomProvider) testSMTPConnection(ctx context.Context, account *models
 			Security: account.SMTPSecurity,
 			Username: account.Username,
 			Password: account.Password,
+			EHLOName: IdentityForAccount(account).EHLOName,
+			TLS:      TLSOptionsForAccount(account),
+			Resolver: ResolverForAccount(account),
 		}
 	case "oauth2":
 		tokenData, err := account.GetOAuth2Token()
@@ -230,6 +242,9 @@ func (p *CustomProvider) testSMTPConnection(ctx context.Context, account *models
 			Security:    account.SMTPSecurity,
 			Username:    account.Username,
 			OAuth2Token: oauth2Token,
+			EHLOName:    IdentityForAccount(account).EHLOName,
+			TLS:         TLSOptionsForAccount(account),
+			Resolver:    ResolverForAccount(account),
 		}
 	}
 
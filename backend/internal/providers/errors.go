@@ -2,6 +2,7 @@ package providers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -32,6 +33,7 @@ const (
 	ErrorTypeRateLimit          ErrorType = "rate_limit"
 	ErrorTypeQuotaExceeded      ErrorType = "quota_exceeded"
 	ErrorTypeServiceUnavailable ErrorType = "service_unavailable"
+	ErrorTypeFolderNotFound     ErrorType = "folder_not_found"
 
 	// 配置错误
 	ErrorTypeConfig     ErrorType = "configuration"
@@ -45,6 +47,20 @@ const (
 	ErrorTypeUnknown ErrorType = "unknown"
 )
 
+// 提供商操作的错误类别，可通过 errors.Is 判断 ProviderError 所属类别
+var (
+	// ErrAuth 认证失败，重试无效，需要用户更新凭据
+	ErrAuth = errors.New("provider authentication failed")
+	// ErrRateLimited 被服务器或本地限速，应在稍后重试
+	ErrRateLimited = errors.New("provider rate limited")
+	// ErrTransient 连接中断、超时等临时错误，可重试
+	ErrTransient = errors.New("provider transient failure")
+	// ErrFolderMissing 文件夹在服务器上不存在
+	ErrFolderMissing = errors.New("provider folder missing")
+	// ErrQuotaExceeded 存储或发送配额已满，重试无效
+	ErrQuotaExceeded = errors.New("provider quota exceeded")
+)
+
 // ErrorSeverity 错误严重程度
 type ErrorSeverity string
 
@@ -84,17 +100,83 @@ func (e *ProviderError) Unwrap() error {
 	return e.Cause
 }
 
-// Is 实现errors.Is接口
+// Is 实现errors.Is接口，支持按错误类别（ErrAuth等）匹配
 func (e *ProviderError) Is(target error) bool {
 	if pe, ok := target.(*ProviderError); ok {
 		return e.Type == pe.Type && e.Code == pe.Code
 	}
+
+	switch target {
+	case ErrAuth:
+		return e.Type == ErrorTypeAuth || e.Type == ErrorTypeCredentials || e.Type == ErrorTypeOAuth2
+	case ErrRateLimited:
+		return e.Type == ErrorTypeRateLimit
+	case ErrTransient:
+		return e.Type == ErrorTypeConnection || e.Type == ErrorTypeTimeout ||
+			e.Type == ErrorTypeNetworkError || e.Type == ErrorTypeServiceUnavailable
+	case ErrFolderMissing:
+		return e.Type == ErrorTypeFolderNotFound
+	case ErrQuotaExceeded:
+		return e.Type == ErrorTypeQuotaExceeded
+	}
 	return false
 }
 
+// IsRetryableError 判断提供商错误是否值得稍后重试
+func IsRetryableError(err error) bool {
+	return errors.Is(err, ErrTransient) || errors.Is(err, ErrRateLimited)
+}
+
+// IsPermanentError 判断是否为重试无效的错误（认证失败、配额已满、文件夹不存在）
+func IsPermanentError(err error) bool {
+	return errors.Is(err, ErrAuth) || errors.Is(err, ErrQuotaExceeded) || errors.Is(err, ErrFolderMissing)
+}
+
+// ErrorCode 返回错误类别对应的代码，供API返回给前端；未分类的错误返回空字符串
+func ErrorCode(err error) string {
+	switch {
+	case errors.Is(err, ErrAuth):
+		return "PROVIDER_AUTH_FAILED"
+	case errors.Is(err, ErrRateLimited):
+		return "PROVIDER_RATE_LIMITED"
+	case errors.Is(err, ErrQuotaExceeded):
+		return "PROVIDER_QUOTA_EXCEEDED"
+	case errors.Is(err, ErrFolderMissing):
+		return "PROVIDER_FOLDER_MISSING"
+	case errors.Is(err, ErrTransient):
+		return "PROVIDER_UNAVAILABLE"
+	}
+	return ""
+}
+
+// defaultErrorClassifier 提供商边界使用的错误分类器
+var defaultErrorClassifier = NewErrorClassifier()
+
+// ClassifyProviderError 将服务器返回的原始错误转换为带类别的ProviderError，无法识别时原样返回
+func ClassifyProviderError(provider string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) {
+		return err
+	}
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	classified := defaultErrorClassifier.ClassifyError(err, provider)
+	if classified.Type == ErrorTypeUnknown {
+		return err
+	}
+	return classified
+}
+
 // ErrorClassifier 错误分类器
 type ErrorClassifier struct {
 	patterns map[ErrorType][]ErrorPattern
+	order    []ErrorType // 匹配顺序，保证分类结果稳定
 }
 
 // ErrorPattern 错误模式
@@ -106,6 +188,8 @@ type ErrorPattern struct {
 	Retryable   bool          `json:"retryable"`
 	Temporary   bool          `json:"temporary"`
 	Suggestions []string      `json:"suggestions"`
+	// RequireKeyword 为true时必须命中关键词，错误代码仅用于提取Code
+	RequireKeyword bool `json:"require_keyword"`
 }
 
 // NewErrorClassifier 创建错误分类器
@@ -120,6 +204,29 @@ func NewErrorClassifier() *ErrorClassifier {
 
 // initializePatterns 初始化错误模式
 func (ec *ErrorClassifier) initializePatterns() {
+	ec.order = []ErrorType{
+		ErrorTypeFolderNotFound,
+		ErrorTypeConnection,
+		ErrorTypeTimeout,
+		ErrorTypeAuth,
+		ErrorTypeRateLimit,
+		ErrorTypeServiceUnavailable,
+		ErrorTypeServerError,
+		ErrorTypeProtocol,
+	}
+
+	// 文件夹不存在错误模式
+	ec.patterns[ErrorTypeFolderNotFound] = []ErrorPattern{
+		{
+			Keywords:    []string{"folder not exist", "folder does not exist", "mailbox does not exist", "mailbox doesn't exist", "no such mailbox", "mailbox not found", "[nonexistent]"},
+			Type:        ErrorTypeFolderNotFound,
+			Severity:    SeverityMedium,
+			Retryable:   false,
+			Temporary:   false,
+			Suggestions: []string{"Refresh the folder list", "Check whether the folder was deleted on the server"},
+		},
+	}
+
 	// 连接错误模式
 	ec.patterns[ErrorTypeConnection] = []ErrorPattern{
 		{
@@ -191,6 +298,8 @@ func (ec *ErrorClassifier) initializePatterns() {
 			Retryable:   true,
 			Temporary:   true,
 			Suggestions: []string{"Reduce request frequency", "Wait before retrying", "Check rate limits"},
+			// 550/552/554 也用于拒收等永久错误，必须同时命中限流关键词
+			RequireKeyword: true,
 		},
 	}
 
@@ -252,12 +361,12 @@ func (ec *ErrorClassifier) ClassifyError(err error, provider string) *ProviderEr
 
 	errStr := strings.ToLower(err.Error())
 
-	// 遍历所有模式进行匹配
-	for errorType, patterns := range ec.patterns {
-		for _, pattern := range patterns {
+	// 按固定顺序遍历模式进行匹配
+	for _, errorType := range ec.order {
+		for _, pattern := range ec.patterns[errorType] {
 			if ec.matchesPattern(errStr, pattern) {
 				return &ProviderError{
-					Type:        errorType,
+					Type:        pattern.Type,
 					Code:        ec.extractCode(errStr, pattern),
 					Message:     err.Error(),
 					Provider:    provider,
@@ -295,6 +404,9 @@ func (ec *ErrorClassifier) matchesPattern(errStr string, pattern ErrorPattern) b
 
 	// 检查错误代码
 	for _, code := range pattern.Codes {
+		if pattern.RequireKeyword {
+			break
+		}
 		if strings.Contains(errStrLower, strings.ToLower(code)) {
 			return true
 		}
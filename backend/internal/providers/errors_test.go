@@ -3,6 +3,7 @@ package providers
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 )
@@ -470,3 +471,50 @@ func TestDefaultRetryConfig(t *testing.T) {
 		}
 	}
 }
+
+func TestClassifyProviderError_Sentinels(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		sentinel error
+		code     string
+	}{
+		{"auth", errors.New("535 5.7.8 Username and Password not accepted"), ErrAuth, "PROVIDER_AUTH_FAILED"},
+		{"rate limited", errors.New("550 frequency limit exceeded"), ErrRateLimited, "PROVIDER_RATE_LIMITED"},
+		{"transient", errors.New("read tcp: connection reset by peer"), ErrTransient, "PROVIDER_UNAVAILABLE"},
+		{"folder missing", errors.New("NO [NONEXISTENT] Mailbox doesn't exist: Archive"), ErrFolderMissing, "PROVIDER_FOLDER_MISSING"},
+		{"quota", errors.New("452 4.2.2 mailbox full"), ErrQuotaExceeded, "PROVIDER_QUOTA_EXCEEDED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fmt.Errorf("operation failed: %w", ClassifyProviderError("gmail", tt.err))
+			if !errors.Is(err, tt.sentinel) {
+				t.Fatalf("expected %v to match %v", err, tt.sentinel)
+			}
+			if code := ErrorCode(err); code != tt.code {
+				t.Errorf("expected code %s, got %s", tt.code, code)
+			}
+		})
+	}
+}
+
+func TestClassifyProviderError_KeepsUnknownErrors(t *testing.T) {
+	original := errors.New("550 5.1.1 recipient rejected")
+	if err := ClassifyProviderError("qq", original); err != original {
+		t.Errorf("expected unknown error to be returned unchanged, got %v", err)
+	}
+	if IsRetryableError(original) {
+		t.Error("permanent rejection must not be retryable")
+	}
+}
+
+func TestErrorClassifier_StableOrder(t *testing.T) {
+	classifier := NewErrorClassifier()
+	for i := 0; i < 50; i++ {
+		result := classifier.ClassifyError(errors.New("connection timeout occurred"), "outlook")
+		if result.Type != ErrorTypeConnection {
+			t.Fatalf("expected connection error on iteration %d, got %s", i, result.Type)
+		}
+	}
+}
@@ -0,0 +1,555 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// ewsServerVersion 请求使用的EWS架构版本，兼容 Exchange 2010 SP2 及以上的本地部署
+	ewsServerVersion = "Exchange2010_SP2"
+	// ewsDefaultPath Exchange 服务器上EWS的默认路径
+	ewsDefaultPath = "/EWS/Exchange.asmx"
+	// ewsRequestTimeout 单个EWS请求的超时时间
+	ewsRequestTimeout = 2 * time.Minute
+	// ewsDialTimeout 建立到EWS服务器连接的超时时间
+	ewsDialTimeout = 30 * time.Second
+	// ewsMaxResponseSize EWS响应的大小上限，包含MIME内容的大邮件不超过该大小
+	ewsMaxResponseSize = 64 * 1024 * 1024
+)
+
+// EWS扩展属性（MAPI属性标签）
+const (
+	// ewsPropArticleNumber PR_INTERNET_ARTICLE_NUMBER，Exchange为文件夹中每封邮件分配的递增编号，即IMAP UID
+	ewsPropArticleNumber = 0x0E23
+	// ewsPropMessageFlags PR_MESSAGE_FLAGS，写入邮件时标记已读和非草稿
+	ewsPropMessageFlags = 0x0E07
+	// ewsPropFlagStatus PR_FLAG_STATUS，2表示已标记
+	ewsPropFlagStatus = 0x1090
+	// ewsPropLastVerb PR_LAST_VERB_EXECUTED，102/103表示已回复，104表示已转发
+	ewsPropLastVerb = 0x1081
+	// ewsPropSenderAddress PR_SENT_REPRESENTING_EMAIL_ADDRESS，按发件人搜索
+	ewsPropSenderAddress = 0x0065
+	// ewsPropDisplayTo PR_DISPLAY_TO，按收件人搜索
+	ewsPropDisplayTo = 0x0E04
+)
+
+// newEWSHTTPClient 创建EWS和自动发现使用的HTTP客户端，与IMAP/SMTP连接一样使用账户的TLS设置和DNS解析器
+func newEWSHTTPClient(options *TLSOptions, resolver *net.Resolver, serverName string) (*http.Client, error) {
+	tlsConfig, err := options.Config(serverName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS settings: %w", err)
+	}
+	dialer := &net.Dialer{Timeout: ewsDialTimeout, KeepAlive: 30 * time.Second, Resolver: resolver}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Timeout: ewsRequestTimeout, Transport: transport}, nil
+}
+
+// ewsService EWS SOAP接口的调用方，使用基本认证（本地部署的Exchange需要开启EWS的基本认证）
+type ewsService struct {
+	endpoint string
+	username string
+	password string
+	client   *http.Client
+}
+
+// newEWSService 由连接配置创建EWS调用方，HTTP客户端按账户的TLS设置和DNS解析器建立
+func newEWSService(host string, port int, security, username, password string, options *TLSOptions, resolver *net.Resolver) (*ewsService, error) {
+	endpoint := ewsEndpoint(host, port, security)
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EWS URL %q: %w", endpoint, err)
+	}
+	client, err := newEWSHTTPClient(options, resolver, parsed.Hostname())
+	if err != nil {
+		return nil, err
+	}
+	return &ewsService{endpoint: endpoint, username: username, password: password, client: client}, nil
+}
+
+// close 关闭空闲的HTTP连接
+func (s *ewsService) close() {
+	s.client.CloseIdleConnections()
+}
+
+// ewsEndpoint 由账户的服务器配置得到EWS地址：可以是完整URL，也可以只是主机名
+func ewsEndpoint(host string, port int, security string) string {
+	if strings.HasPrefix(host, "https://") || strings.HasPrefix(host, "http://") {
+		return host
+	}
+	scheme := "https"
+	if strings.EqualFold(security, "NONE") {
+		scheme = "http"
+	}
+	if port != 0 && !(scheme == "https" && port == 443) && !(scheme == "http" && port == 80) {
+		host = fmt.Sprintf("%s:%d", host, port)
+	}
+	return scheme + "://" + host + ewsDefaultPath
+}
+
+// soapEnvelope SOAP响应
+type soapEnvelope struct {
+	Body struct {
+		Fault *struct {
+			FaultString string `xml:"faultstring"`
+		} `xml:"Fault"`
+		Content []byte `xml:",innerxml"`
+	} `xml:"Body"`
+}
+
+// call 调用EWS操作，body为 soap:Body 中的请求元素，返回各响应消息，任一消息失败时返回错误
+func (s *ewsService) call(ctx context.Context, body string) ([]ewsResponseMessage, error) {
+	var envelope bytes.Buffer
+	envelope.WriteString(`<?xml version="1.0" encoding="utf-8"?>`)
+	envelope.WriteString(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/" ` +
+		`xmlns:t="http://schemas.microsoft.com/exchange/services/2006/types" ` +
+		`xmlns:m="http://schemas.microsoft.com/exchange/services/2006/messages">`)
+	envelope.WriteString(`<soap:Header><t:RequestServerVersion Version="` + ewsServerVersion + `"/></soap:Header>`)
+	envelope.WriteString(`<soap:Body>` + body + `</soap:Body></soap:Envelope>`)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, &envelope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create EWS request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/xml; charset=utf-8")
+	req.SetBasicAuth(s.username, s.password)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("EWS request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, ewsMaxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read EWS response: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return nil, fmt.Errorf("EWS authentication failed: HTTP 401")
+	case resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == http.StatusTooManyRequests:
+		return nil, fmt.Errorf("EWS server busy: HTTP %d", resp.StatusCode)
+	case resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusInternalServerError:
+		// 500 用于返回 SOAP Fault，其余状态码没有可解析的响应
+		return nil, fmt.Errorf("EWS request failed: HTTP %d", resp.StatusCode)
+	}
+
+	var parsed soapEnvelope
+	if err := xml.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse EWS response: %w", err)
+	}
+	if parsed.Body.Fault != nil {
+		return nil, fmt.Errorf("EWS request failed: %s", ewsErrorText(parsed.Body.Fault.FaultString))
+	}
+
+	var operation struct {
+		ResponseMessages struct {
+			Messages []ewsResponseMessage `xml:",any"`
+		} `xml:"ResponseMessages"`
+	}
+	if err := xml.Unmarshal(parsed.Body.Content, &operation); err != nil {
+		return nil, fmt.Errorf("failed to parse EWS response: %w", err)
+	}
+
+	for _, message := range operation.ResponseMessages.Messages {
+		if message.ResponseClass == "Error" {
+			return nil, message.err()
+		}
+	}
+	return operation.ResponseMessages.Messages, nil
+}
+
+// ewsErrorText 把EWS错误说明中的常见错误改写为错误分类能识别的说法
+func ewsErrorText(text string) string {
+	switch {
+	case strings.Contains(text, "ErrorServerBusy"):
+		return "server busy: " + text
+	case strings.Contains(text, "ErrorFolderNotFound"):
+		return "folder does not exist: " + text
+	}
+	return text
+}
+
+// ewsResponseMessage EWS响应消息，包含各操作可能返回的字段
+type ewsResponseMessage struct {
+	ResponseClass string        `xml:"ResponseClass,attr"`
+	ResponseCode  string        `xml:"ResponseCode"`
+	MessageText   string        `xml:"MessageText"`
+	RootFolder    ewsRootFolder `xml:"RootFolder"`
+	Folders       ewsFolders    `xml:"Folders"`
+	Items         ewsItems      `xml:"Items"`
+
+	// SyncFolderItems
+	SyncState               string     `xml:"SyncState"`
+	IncludesLastItemInRange bool       `xml:"IncludesLastItemInRange"`
+	Changes                 ewsChanges `xml:"Changes"`
+}
+
+// err 响应消息对应的错误
+func (m *ewsResponseMessage) err() error {
+	return fmt.Errorf("EWS %s: %s", m.ResponseCode, ewsErrorText(m.ResponseCode+" "+m.MessageText))
+}
+
+// ewsRootFolder FindFolder/FindItem的结果
+type ewsRootFolder struct {
+	IncludesLastItemInRange bool       `xml:"IncludesLastItemInRange,attr"`
+	TotalItemsInView        int        `xml:"TotalItemsInView,attr"`
+	Folders                 ewsFolders `xml:"Folders"`
+	Items                   ewsItems   `xml:"Items"`
+}
+
+// ewsID 文件夹或邮件ID
+type ewsID struct {
+	ID        string `xml:"Id,attr"`
+	ChangeKey string `xml:"ChangeKey,attr"`
+}
+
+// ewsFolders 文件夹列表，元素名称随文件夹类型不同（Folder、CalendarFolder等）
+type ewsFolders struct {
+	Folders []ewsFolder `xml:",any"`
+}
+
+// ewsFolder 文件夹
+type ewsFolder struct {
+	FolderID       ewsID  `xml:"FolderId"`
+	ParentFolderID ewsID  `xml:"ParentFolderId"`
+	FolderClass    string `xml:"FolderClass"`
+	DisplayName    string `xml:"DisplayName"`
+	TotalCount     int    `xml:"TotalCount"`
+	UnreadCount    int    `xml:"UnreadCount"`
+}
+
+// ewsItems 邮件列表，元素名称随邮件类型不同（Message、MeetingRequest等）
+type ewsItems struct {
+	Items []ewsItem `xml:",any"`
+}
+
+// ewsMailbox 邮箱地址
+type ewsMailbox struct {
+	Name         string `xml:"Name"`
+	EmailAddress string `xml:"EmailAddress"`
+}
+
+// ewsRecipients 收件人列表
+type ewsRecipients struct {
+	Mailboxes []ewsMailbox `xml:"Mailbox"`
+}
+
+// ewsExtendedProperty 扩展属性
+type ewsExtendedProperty struct {
+	FieldURI struct {
+		PropertyTag string `xml:"PropertyTag,attr"`
+	} `xml:"ExtendedFieldURI"`
+	Value string `xml:"Value"`
+}
+
+// ewsItem 邮件
+type ewsItem struct {
+	ItemID             ewsID                 `xml:"ItemId"`
+	MimeContent        string                `xml:"MimeContent"`
+	Subject            string                `xml:"Subject"`
+	DateTimeReceived   time.Time             `xml:"DateTimeReceived"`
+	Size               int64                 `xml:"Size"`
+	Importance         string                `xml:"Importance"`
+	InReplyTo          string                `xml:"InReplyTo"`
+	From               *ewsRecipients        `xml:"From"`
+	ToRecipients       ewsRecipients         `xml:"ToRecipients"`
+	CcRecipients       ewsRecipients         `xml:"CcRecipients"`
+	BccRecipients      ewsRecipients         `xml:"BccRecipients"`
+	ReplyTo            ewsRecipients         `xml:"ReplyTo"`
+	IsRead             bool                  `xml:"IsRead"`
+	InternetMessageID  string                `xml:"InternetMessageId"`
+	ExtendedProperties []ewsExtendedProperty `xml:"ExtendedProperty"`
+}
+
+// property 读取整数扩展属性，不存在时返回0
+func (i *ewsItem) property(tag int) uint64 {
+	for _, prop := range i.ExtendedProperties {
+		parsed, err := strconv.ParseUint(prop.FieldURI.PropertyTag, 0, 32)
+		if err != nil || int(parsed) != tag {
+			continue
+		}
+		value, err := strconv.ParseInt(prop.Value, 10, 64)
+		if err != nil || value < 0 {
+			return 0
+		}
+		return uint64(value)
+	}
+	return 0
+}
+
+// uid 邮件在文件夹中的编号
+func (i *ewsItem) uid() uint32 {
+	return uint32(i.property(ewsPropArticleNumber))
+}
+
+// ewsChanges SyncFolderItems返回的变更
+type ewsChanges struct {
+	Changes []ewsChange `xml:",any"`
+}
+
+// ewsChange 单个变更：Create、Update、Delete或ReadFlagChange
+type ewsChange struct {
+	XMLName xml.Name
+	ItemID  ewsID     `xml:"ItemId"`
+	Items   []ewsItem `xml:",any"`
+}
+
+// item 新建或更新的邮件，Delete和ReadFlagChange没有邮件
+func (c *ewsChange) item() *ewsItem {
+	for i := range c.Items {
+		if c.Items[i].ItemID.ID != "" {
+			return &c.Items[i]
+		}
+	}
+	return nil
+}
+
+// ewsEscape 转义XML文本和属性值
+func ewsEscape(value string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(value))
+	return buf.String()
+}
+
+// ewsFolderIDXML 文件夹ID元素，distinguished为true时使用内置文件夹名称
+func ewsFolderIDXML(id string, distinguished bool) string {
+	if distinguished {
+		return `<t:DistinguishedFolderId Id="` + ewsEscape(id) + `"/>`
+	}
+	return `<t:FolderId Id="` + ewsEscape(id) + `"/>`
+}
+
+// ewsItemIDsXML 邮件ID列表
+func ewsItemIDsXML(ids []string) string {
+	var buf strings.Builder
+	for _, id := range ids {
+		buf.WriteString(`<t:ItemId Id="` + ewsEscape(id) + `"/>`)
+	}
+	return buf.String()
+}
+
+// ewsExtendedFieldXML 整数或字符串扩展属性的字段引用
+func ewsExtendedFieldXML(tag int, propertyType string) string {
+	return fmt.Sprintf(`<t:ExtendedFieldURI PropertyTag="0x%04X" PropertyType="%s"/>`, tag, propertyType)
+}
+
+// ewsMessageProperties 同步邮件时获取的属性
+var ewsMessageProperties = `<t:FieldURI FieldURI="item:Subject"/>` +
+	`<t:FieldURI FieldURI="item:DateTimeReceived"/>` +
+	`<t:FieldURI FieldURI="item:Size"/>` +
+	`<t:FieldURI FieldURI="item:Importance"/>` +
+	`<t:FieldURI FieldURI="item:InReplyTo"/>` +
+	`<t:FieldURI FieldURI="message:From"/>` +
+	`<t:FieldURI FieldURI="message:ToRecipients"/>` +
+	`<t:FieldURI FieldURI="message:CcRecipients"/>` +
+	`<t:FieldURI FieldURI="message:BccRecipients"/>` +
+	`<t:FieldURI FieldURI="message:ReplyTo"/>` +
+	`<t:FieldURI FieldURI="message:IsRead"/>` +
+	`<t:FieldURI FieldURI="message:InternetMessageId"/>` +
+	ewsExtendedFieldXML(ewsPropArticleNumber, "Integer") +
+	ewsExtendedFieldXML(ewsPropFlagStatus, "Integer") +
+	ewsExtendedFieldXML(ewsPropLastVerb, "Integer")
+
+// findFolders 列出邮箱根目录下的所有文件夹
+func (s *ewsService) findFolders(ctx context.Context) ([]ewsFolder, error) {
+	body := `<m:FindFolder Traversal="Deep">` +
+		`<m:FolderShape><t:BaseShape>Default</t:BaseShape><t:AdditionalProperties>` +
+		`<t:FieldURI FieldURI="folder:FolderClass"/><t:FieldURI FieldURI="folder:ParentFolderId"/>` +
+		`</t:AdditionalProperties></m:FolderShape>` +
+		`<m:ParentFolderIds>` + ewsFolderIDXML("msgfolderroot", true) + `</m:ParentFolderIds>` +
+		`</m:FindFolder>`
+	messages, err := s.call(ctx, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list folders: %w", err)
+	}
+	var folders []ewsFolder
+	for _, message := range messages {
+		folders = append(folders, message.RootFolder.Folders.Folders...)
+	}
+	return folders, nil
+}
+
+// getFolders 获取文件夹，结果与ids顺序一致
+func (s *ewsService) getFolders(ctx context.Context, ids []string, distinguished bool) ([]ewsFolder, error) {
+	var buf strings.Builder
+	for _, id := range ids {
+		buf.WriteString(ewsFolderIDXML(id, distinguished))
+	}
+	body := `<m:GetFolder><m:FolderShape><t:BaseShape>Default</t:BaseShape></m:FolderShape>` +
+		`<m:FolderIds>` + buf.String() + `</m:FolderIds></m:GetFolder>`
+	messages, err := s.call(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	folders := make([]ewsFolder, 0, len(messages))
+	for _, message := range messages {
+		if len(message.Folders.Folders) > 0 {
+			folders = append(folders, message.Folders.Folders[0])
+		}
+	}
+	if len(folders) != len(ids) {
+		return nil, fmt.Errorf("EWS returned %d folders for %d ids", len(folders), len(ids))
+	}
+	return folders, nil
+}
+
+// syncFolderItems 获取文件夹自syncState以来的变更，syncState为空时返回全部邮件
+func (s *ewsService) syncFolderItems(ctx context.Context, folderID, syncState string) (*ewsResponseMessage, error) {
+	body := `<m:SyncFolderItems>` +
+		`<m:ItemShape><t:BaseShape>IdOnly</t:BaseShape><t:AdditionalProperties>` +
+		ewsExtendedFieldXML(ewsPropArticleNumber, "Integer") +
+		`</t:AdditionalProperties></m:ItemShape>` +
+		`<m:SyncFolderId>` + ewsFolderIDXML(folderID, false) + `</m:SyncFolderId>`
+	if syncState != "" {
+		body += `<m:SyncState>` + ewsEscape(syncState) + `</m:SyncState>`
+	}
+	body += `<m:MaxChangesReturned>512</m:MaxChangesReturned></m:SyncFolderItems>`
+
+	messages, err := s.call(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("EWS returned no sync result")
+	}
+	return &messages[0], nil
+}
+
+// getItems 获取邮件，includeMime为true时同时获取MIME原文
+func (s *ewsService) getItems(ctx context.Context, ids []string, includeMime bool) ([]ewsItem, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	body := `<m:GetItem><m:ItemShape><t:BaseShape>IdOnly</t:BaseShape>` +
+		`<t:IncludeMimeContent>` + strconv.FormatBool(includeMime) + `</t:IncludeMimeContent>` +
+		`<t:AdditionalProperties>` + ewsMessageProperties + `</t:AdditionalProperties></m:ItemShape>` +
+		`<m:ItemIds>` + ewsItemIDsXML(ids) + `</m:ItemIds></m:GetItem>`
+	messages, err := s.call(ctx, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get items: %w", err)
+	}
+	var items []ewsItem
+	for _, message := range messages {
+		items = append(items, message.Items.Items...)
+	}
+	return items, nil
+}
+
+// findItems 按条件查找文件夹中的邮件，restriction为空时返回全部邮件
+func (s *ewsService) findItems(ctx context.Context, folderID, restriction string) ([]ewsItem, error) {
+	const pageSize = 1000
+	var items []ewsItem
+	for offset := 0; ; offset += pageSize {
+		body := `<m:FindItem Traversal="Shallow">` +
+			`<m:ItemShape><t:BaseShape>IdOnly</t:BaseShape><t:AdditionalProperties>` +
+			ewsExtendedFieldXML(ewsPropArticleNumber, "Integer") +
+			`</t:AdditionalProperties></m:ItemShape>` +
+			fmt.Sprintf(`<m:IndexedPageItemView MaxEntriesReturned="%d" Offset="%d" BasePoint="Beginning"/>`, pageSize, offset)
+		if restriction != "" {
+			body += `<m:Restriction>` + restriction + `</m:Restriction>`
+		}
+		body += `<m:ParentFolderIds>` + ewsFolderIDXML(folderID, false) + `</m:ParentFolderIds></m:FindItem>`
+
+		messages, err := s.call(ctx, body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find items: %w", err)
+		}
+		if len(messages) == 0 {
+			return items, nil
+		}
+		root := messages[0].RootFolder
+		items = append(items, root.Items.Items...)
+		if root.IncludesLastItemInRange || len(root.Items.Items) == 0 {
+			return items, nil
+		}
+	}
+}
+
+// setReadFlag 设置邮件的已读状态
+func (s *ewsService) setReadFlag(ctx context.Context, ids []string, read bool) error {
+	var changes strings.Builder
+	for _, id := range ids {
+		changes.WriteString(`<t:ItemChange><t:ItemId Id="` + ewsEscape(id) + `"/><t:Updates><t:SetItemField>` +
+			`<t:FieldURI FieldURI="message:IsRead"/><t:Message><t:IsRead>` + strconv.FormatBool(read) + `</t:IsRead></t:Message>` +
+			`</t:SetItemField></t:Updates></t:ItemChange>`)
+	}
+	body := `<m:UpdateItem MessageDisposition="SaveOnly" ConflictResolution="AlwaysOverwrite">` +
+		`<m:ItemChanges>` + changes.String() + `</m:ItemChanges></m:UpdateItem>`
+	_, err := s.call(ctx, body)
+	return err
+}
+
+// deleteItems 删除邮件：SoftDelete 与 IMAP EXPUNGE 相同，邮件进入可恢复项目，不再出现在已删除邮件中
+func (s *ewsService) deleteItems(ctx context.Context, ids []string) error {
+	body := `<m:DeleteItem DeleteType="SoftDelete"><m:ItemIds>` + ewsItemIDsXML(ids) + `</m:ItemIds></m:DeleteItem>`
+	_, err := s.call(ctx, body)
+	return err
+}
+
+// transferItems 移动（MoveItem）或复制（CopyItem）邮件到目标文件夹
+func (s *ewsService) transferItems(ctx context.Context, operation string, ids []string, targetFolderID string) error {
+	body := `<m:` + operation + `><m:ToFolderId>` + ewsFolderIDXML(targetFolderID, false) + `</m:ToFolderId>` +
+		`<m:ItemIds>` + ewsItemIDsXML(ids) + `</m:ItemIds></m:` + operation + `>`
+	_, err := s.call(ctx, body)
+	return err
+}
+
+// createMimeItem 用MIME原文创建邮件：disposition 为 SaveOnly 时保存到文件夹，为 SendAndSaveCopy 时发送并保存到已发送，
+// extra 为MIME原文之后附加的邮件属性（如MIME头中没有的密送收件人）
+func (s *ewsService) createMimeItem(ctx context.Context, disposition, folderXML string, mime []byte, extra string) error {
+	body := `<m:CreateItem MessageDisposition="` + disposition + `">` +
+		`<m:SavedItemFolderId>` + folderXML + `</m:SavedItemFolderId>` +
+		`<m:Items><t:Message><t:MimeContent CharacterSet="UTF-8">` + base64.StdEncoding.EncodeToString(mime) + `</t:MimeContent>` +
+		extra + `</t:Message></m:Items></m:CreateItem>`
+	_, err := s.call(ctx, body)
+	return err
+}
+
+// createFolder 在父文件夹下创建邮件文件夹
+func (s *ewsService) createFolder(ctx context.Context, parentXML, name string) error {
+	body := `<m:CreateFolder><m:ParentFolderId>` + parentXML + `</m:ParentFolderId>` +
+		`<m:Folders><t:Folder><t:FolderClass>IPF.Note</t:FolderClass><t:DisplayName>` + ewsEscape(name) + `</t:DisplayName></t:Folder></m:Folders>` +
+		`</m:CreateFolder>`
+	_, err := s.call(ctx, body)
+	return err
+}
+
+// deleteFolder 删除文件夹，与邮件一样进入可恢复项目
+func (s *ewsService) deleteFolder(ctx context.Context, folderID string) error {
+	body := `<m:DeleteFolder DeleteType="SoftDelete"><m:FolderIds>` + ewsFolderIDXML(folderID, false) + `</m:FolderIds></m:DeleteFolder>`
+	_, err := s.call(ctx, body)
+	return err
+}
+
+// renameFolder 修改文件夹显示名称
+func (s *ewsService) renameFolder(ctx context.Context, folderID, name string) error {
+	body := `<m:UpdateFolder><m:FolderChanges><t:FolderChange>` + ewsFolderIDXML(folderID, false) +
+		`<t:Updates><t:SetFolderField><t:FieldURI FieldURI="folder:DisplayName"/>` +
+		`<t:Folder><t:DisplayName>` + ewsEscape(name) + `</t:DisplayName></t:Folder>` +
+		`</t:SetFolderField></t:Updates></t:FolderChange></m:FolderChanges></m:UpdateFolder>`
+	_, err := s.call(ctx, body)
+	return err
+}
+
+// moveFolder 移动文件夹到新的父文件夹下
+func (s *ewsService) moveFolder(ctx context.Context, folderID, parentXML string) error {
+	body := `<m:MoveFolder><m:ToFolderId>` + parentXML + `</m:ToFolderId>` +
+		`<m:FolderIds>` + ewsFolderIDXML(folderID, false) + `</m:FolderIds></m:MoveFolder>`
+	_, err := s.call(ctx, body)
+	return err
+}
@@ -0,0 +1,889 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"firemail/internal/models"
+)
+
+// ewsFolderDelimiter EWS文件夹路径的分隔符
+const ewsFolderDelimiter = "/"
+
+// ewsInboxPath 收件箱的路径，与IMAP一致使用 INBOX
+const ewsInboxPath = "INBOX"
+
+// ewsDistinguishedFolderTypes 内置文件夹对应的文件夹类型
+var ewsDistinguishedFolderTypes = []struct {
+	ID   string
+	Type string
+}{
+	{"inbox", "inbox"},
+	{"sentitems", "sent"},
+	{"drafts", "drafts"},
+	{"deleteditems", "trash"},
+	{"junkemail", "spam"},
+}
+
+// ewsFolderEntry 文件夹路径对应的EWS文件夹
+type ewsFolderEntry struct {
+	ID    string
+	Type  string
+	Total int
+}
+
+// ewsFolderState 文件夹的同步状态：SyncFolderItems的同步状态和UID到邮件ID的映射。
+// 按账户和文件夹在进程内共享，后续同步只获取变更；进程重启后第一次同步重新获取邮件ID列表（不下载邮件内容）
+type ewsFolderState struct {
+	mu        sync.Mutex
+	syncState string
+	items     map[uint32]string // UID -> 邮件ID
+	uids      map[string]uint32 // 邮件ID -> UID
+}
+
+// ewsFolderStates 进程内的文件夹同步状态，键为 EWS地址|用户名|文件夹ID
+var ewsFolderStates sync.Map
+
+// ewsIMAPClient 通过EWS实现的IMAP客户端：文件夹使用路径表示，邮件UID为Exchange分配的文章编号
+type ewsIMAPClient struct {
+	mutex     sync.RWMutex
+	service   *ewsService
+	connected bool
+	folders   map[string]*ewsFolderEntry // 路径 -> 文件夹
+	selected  string
+}
+
+// newEWSIMAPClient 创建EWS的IMAP客户端
+func newEWSIMAPClient() *ewsIMAPClient {
+	return &ewsIMAPClient{}
+}
+
+// Connect 保存连接配置并获取文件夹列表验证凭据
+func (c *ewsIMAPClient) Connect(ctx context.Context, config IMAPClientConfig) error {
+	service, err := newEWSService(config.Host, config.Port, config.Security, config.Username, config.Password, config.TLS, config.Resolver)
+	if err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	if c.service != nil {
+		c.service.close()
+	}
+	c.service = service
+	c.folders = nil
+	c.mutex.Unlock()
+
+	if _, err := c.loadFolders(ctx); err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	c.connected = true
+	c.mutex.Unlock()
+	return nil
+}
+
+// Disconnect EWS基于HTTP请求，只需关闭空闲的HTTP连接
+func (c *ewsIMAPClient) Disconnect() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.connected = false
+	if c.service != nil {
+		c.service.close()
+	}
+	return nil
+}
+
+// IsConnected 检查连接状态
+func (c *ewsIMAPClient) IsConnected() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.connected
+}
+
+// loadFolders 获取文件夹列表并缓存路径到文件夹ID的映射
+func (c *ewsIMAPClient) loadFolders(ctx context.Context) ([]*FolderInfo, error) {
+	c.mutex.RLock()
+	service := c.service
+	c.mutex.RUnlock()
+	if service == nil {
+		return nil, fmt.Errorf("EWS client not connected")
+	}
+
+	ids := make([]string, 0, len(ewsDistinguishedFolderTypes)+1)
+	ids = append(ids, "msgfolderroot")
+	for _, distinguished := range ewsDistinguishedFolderTypes {
+		ids = append(ids, distinguished.ID)
+	}
+	special, err := service.getFolders(ctx, ids, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get special folders: %w", err)
+	}
+	rootID := special[0].FolderID.ID
+	types := make(map[string]string, len(ewsDistinguishedFolderTypes))
+	for i, distinguished := range ewsDistinguishedFolderTypes {
+		types[special[i+1].FolderID.ID] = distinguished.Type
+	}
+
+	all, err := service.findFolders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	infos, entries := buildEWSFolders(rootID, types, all)
+
+	c.mutex.Lock()
+	c.folders = entries
+	c.mutex.Unlock()
+	return infos, nil
+}
+
+// buildEWSFolders 由文件夹的父子关系得到路径，只保留邮件文件夹（日历、联系人等文件夹被忽略）
+func buildEWSFolders(rootID string, types map[string]string, all []ewsFolder) ([]*FolderInfo, map[string]*ewsFolderEntry) {
+	byID := make(map[string]*ewsFolder, len(all))
+	for i := range all {
+		byID[all[i].FolderID.ID] = &all[i]
+	}
+
+	paths := make(map[string]string, len(all))
+	var pathOf func(id string, depth int) string
+	pathOf = func(id string, depth int) string {
+		if path, ok := paths[id]; ok {
+			return path
+		}
+		folder, ok := byID[id]
+		if !ok || id == rootID || depth > 32 {
+			return ""
+		}
+		name := folder.DisplayName
+		if types[id] == "inbox" {
+			name = ewsInboxPath
+		}
+		path := name
+		if parent := pathOf(folder.ParentFolderID.ID, depth+1); parent != "" {
+			path = parent + ewsFolderDelimiter + name
+		}
+		paths[id] = path
+		return path
+	}
+
+	var infos []*FolderInfo
+	entries := make(map[string]*ewsFolderEntry)
+	for i := range all {
+		folder := &all[i]
+		if folder.FolderClass != "" && !strings.HasPrefix(folder.FolderClass, "IPF.Note") {
+			continue
+		}
+		path := pathOf(folder.FolderID.ID, 0)
+		if path == "" {
+			continue
+		}
+		folderType := types[folder.FolderID.ID]
+		if folderType == "" {
+			folderType = "custom"
+		}
+		parent := ""
+		if idx := strings.LastIndex(path, ewsFolderDelimiter); idx >= 0 {
+			parent = path[:idx]
+		}
+
+		entries[path] = &ewsFolderEntry{ID: folder.FolderID.ID, Type: folderType, Total: folder.TotalCount}
+		infos = append(infos, &FolderInfo{
+			Name:         path,
+			DisplayName:  folder.DisplayName,
+			Type:         folderType,
+			Path:         path,
+			Delimiter:    ewsFolderDelimiter,
+			IsSelectable: true,
+			IsSubscribed: true,
+			Parent:       parent,
+		})
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Path < infos[j].Path })
+	return infos, entries
+}
+
+// folder 查找路径对应的文件夹，缓存中没有时重新获取文件夹列表
+func (c *ewsIMAPClient) folder(ctx context.Context, path string) (*ewsFolderEntry, error) {
+	c.mutex.RLock()
+	entry := c.folders[path]
+	c.mutex.RUnlock()
+	if entry != nil {
+		return entry, nil
+	}
+
+	if _, err := c.loadFolders(ctx); err != nil {
+		return nil, err
+	}
+	c.mutex.RLock()
+	entry = c.folders[path]
+	c.mutex.RUnlock()
+	if entry == nil {
+		return nil, fmt.Errorf("folder does not exist: %s", path)
+	}
+	return entry, nil
+}
+
+// ListFolders 列出邮件文件夹
+func (c *ewsIMAPClient) ListFolders(ctx context.Context) ([]*FolderInfo, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("EWS client not connected")
+	}
+	return c.loadFolders(ctx)
+}
+
+// SelectFolder 选择文件夹，之后按UID的操作作用于该文件夹
+func (c *ewsIMAPClient) SelectFolder(ctx context.Context, folderName string) (*FolderStatus, error) {
+	status, err := c.GetFolderStatus(ctx, folderName)
+	if err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	c.selected = folderName
+	c.mutex.Unlock()
+	return status, nil
+}
+
+// parentXML 路径的父文件夹，顶层文件夹的父文件夹为邮箱根目录
+func (c *ewsIMAPClient) parentXML(ctx context.Context, path string) (string, string, error) {
+	idx := strings.LastIndex(path, ewsFolderDelimiter)
+	if idx < 0 {
+		return ewsFolderIDXML("msgfolderroot", true), path, nil
+	}
+	parent, err := c.folder(ctx, path[:idx])
+	if err != nil {
+		return "", "", err
+	}
+	return ewsFolderIDXML(parent.ID, false), path[idx+1:], nil
+}
+
+// CreateFolder 创建文件夹
+func (c *ewsIMAPClient) CreateFolder(ctx context.Context, folderName string) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("EWS client not connected")
+	}
+	parentXML, name, err := c.parentXML(ctx, folderName)
+	if err != nil {
+		return err
+	}
+	if err := c.service.createFolder(ctx, parentXML, name); err != nil {
+		return fmt.Errorf("failed to create folder: %w", err)
+	}
+	c.invalidateFolders()
+	return nil
+}
+
+// DeleteFolder 删除文件夹
+func (c *ewsIMAPClient) DeleteFolder(ctx context.Context, folderName string) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("EWS client not connected")
+	}
+	entry, err := c.folder(ctx, folderName)
+	if err != nil {
+		return err
+	}
+	if err := c.service.deleteFolder(ctx, entry.ID); err != nil {
+		return fmt.Errorf("failed to delete folder: %w", err)
+	}
+	c.invalidateFolders()
+	return nil
+}
+
+// RenameFolder 重命名文件夹，父路径变化时同时移动文件夹
+func (c *ewsIMAPClient) RenameFolder(ctx context.Context, oldName, newName string) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("EWS client not connected")
+	}
+	entry, err := c.folder(ctx, oldName)
+	if err != nil {
+		return err
+	}
+	newParentXML, name, err := c.parentXML(ctx, newName)
+	if err != nil {
+		return err
+	}
+	oldParentXML, _, err := c.parentXML(ctx, oldName)
+	if err != nil {
+		return err
+	}
+
+	if newParentXML != oldParentXML {
+		if err := c.service.moveFolder(ctx, entry.ID, newParentXML); err != nil {
+			return fmt.Errorf("failed to move folder: %w", err)
+		}
+	}
+	if err := c.service.renameFolder(ctx, entry.ID, name); err != nil {
+		return fmt.Errorf("failed to rename folder: %w", err)
+	}
+	c.invalidateFolders()
+	return nil
+}
+
+// invalidateFolders 文件夹变化后清空缓存，下次使用时重新获取
+func (c *ewsIMAPClient) invalidateFolders() {
+	c.mutex.Lock()
+	c.folders = nil
+	c.mutex.Unlock()
+}
+
+// folderState 获取文件夹的共享同步状态
+func (c *ewsIMAPClient) folderState(folderID string) *ewsFolderState {
+	key := c.service.endpoint + "|" + c.service.username + "|" + folderID
+	state, _ := ewsFolderStates.LoadOrStore(key, &ewsFolderState{
+		items: make(map[uint32]string),
+		uids:  make(map[string]uint32),
+	})
+	return state.(*ewsFolderState)
+}
+
+// syncFolder 用SyncFolderItems把文件夹的UID映射更新到最新，返回更新后的状态（调用方不需要加锁）
+func (c *ewsIMAPClient) syncFolder(ctx context.Context, folderName string) (*ewsFolderState, error) {
+	entry, err := c.folder(ctx, folderName)
+	if err != nil {
+		return nil, err
+	}
+	state := c.folderState(entry.ID)
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	for resets := 0; ; {
+		result, err := c.service.syncFolderItems(ctx, entry.ID, state.syncState)
+		if err != nil {
+			// 同步状态失效时重新获取完整的邮件ID列表
+			if strings.Contains(err.Error(), "ErrorInvalidSyncStateData") && resets == 0 {
+				resets++
+				state.reset()
+				continue
+			}
+			return nil, fmt.Errorf("failed to sync folder items: %w", err)
+		}
+		state.apply(result.Changes.Changes)
+		state.syncState = result.SyncState
+		if result.IncludesLastItemInRange {
+			return state, nil
+		}
+	}
+}
+
+// reset 清空同步状态（调用方需持有锁）
+func (s *ewsFolderState) reset() {
+	s.syncState = ""
+	s.items = make(map[uint32]string)
+	s.uids = make(map[string]uint32)
+}
+
+// apply 应用SyncFolderItems返回的变更（调用方需持有锁）
+func (s *ewsFolderState) apply(changes []ewsChange) {
+	for i := range changes {
+		change := &changes[i]
+		switch change.XMLName.Local {
+		case "Create", "Update":
+			item := change.item()
+			if item == nil {
+				continue
+			}
+			uid := item.uid()
+			if uid == 0 {
+				log.Printf("EWS item without article number skipped: %s", item.ItemID.ID)
+				continue
+			}
+			s.items[uid] = item.ItemID.ID
+			s.uids[item.ItemID.ID] = uid
+		case "Delete":
+			if uid, ok := s.uids[change.ItemID.ID]; ok {
+				delete(s.items, uid)
+				delete(s.uids, change.ItemID.ID)
+			}
+		}
+	}
+}
+
+// itemIDs 按UID查找邮件ID，跳过已不存在的邮件（调用方需持有锁）
+func (s *ewsFolderState) itemIDs(uids []uint32) []string {
+	ids := make([]string, 0, len(uids))
+	for _, uid := range uids {
+		if id, ok := s.items[uid]; ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// uidsInRange 范围内的UID，endUID为0表示不限上限（调用方需持有锁）
+func (s *ewsFolderState) uidsInRange(startUID, endUID uint32) []uint32 {
+	var uids []uint32
+	for uid := range s.items {
+		if uid >= startUID && (endUID == 0 || uid <= endUID) {
+			uids = append(uids, uid)
+		}
+	}
+	sort.Slice(uids, func(i, j int) bool { return uids[i] < uids[j] })
+	return uids
+}
+
+// maxUID 文件夹中最大的UID（调用方需持有锁）
+func (s *ewsFolderState) maxUID() uint32 {
+	var max uint32
+	for uid := range s.items {
+		if uid > max {
+			max = uid
+		}
+	}
+	return max
+}
+
+// resolveItems 在文件夹中按UID查找邮件ID
+func (c *ewsIMAPClient) resolveItems(ctx context.Context, folderName string, uids []uint32) ([]string, error) {
+	if folderName == "" {
+		return nil, fmt.Errorf("no folder selected")
+	}
+	state, err := c.syncFolder(ctx, folderName)
+	if err != nil {
+		return nil, err
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	return state.itemIDs(uids), nil
+}
+
+// selectedFolder 当前选中的文件夹
+func (c *ewsIMAPClient) selectedFolder() string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.selected
+}
+
+// GetFolderStatus 获取文件夹状态，UIDNext为已知最大UID加一
+func (c *ewsIMAPClient) GetFolderStatus(ctx context.Context, folderName string) (*FolderStatus, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("EWS client not connected")
+	}
+	entry, err := c.folder(ctx, folderName)
+	if err != nil {
+		return nil, err
+	}
+	folders, err := c.service.getFolders(ctx, []string{entry.ID}, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get folder status: %w", err)
+	}
+	state, err := c.syncFolder(ctx, folderName)
+	if err != nil {
+		return nil, err
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	return &FolderStatus{
+		Name:         folderName,
+		TotalEmails:  folders[0].TotalCount,
+		UnreadEmails: folders[0].UnreadCount,
+		UIDValidity:  1,
+		UIDNext:      state.maxUID() + 1,
+	}, nil
+}
+
+// fetchItems 获取邮件并转换为EmailMessage，includeBody为true时解析MIME原文中的正文和附件
+func (c *ewsIMAPClient) fetchItems(ctx context.Context, ids []string, includeBody bool) ([]*EmailMessage, error) {
+	const batchSize = 50
+	var emails []*EmailMessage
+	for start := 0; start < len(ids); start += batchSize {
+		end := start + batchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		items, err := c.service.getItems(ctx, ids[start:end], includeBody)
+		if err != nil {
+			ReleaseEmailContents(emails)
+			return nil, err
+		}
+		for i := range items {
+			emails = append(emails, convertEWSItem(&items[i], includeBody))
+		}
+	}
+	return emails, nil
+}
+
+// convertEWSItem 转换EWS邮件为EmailMessage
+func convertEWSItem(item *ewsItem, includeBody bool) *EmailMessage {
+	email := &EmailMessage{
+		UID:       item.uid(),
+		MessageID: item.InternetMessageID,
+		Subject:   item.Subject,
+		Date:      item.DateTimeReceived,
+		Size:      item.Size,
+		To:        convertEWSMailboxes(item.ToRecipients),
+		CC:        convertEWSMailboxes(item.CcRecipients),
+		BCC:       convertEWSMailboxes(item.BccRecipients),
+		Headers:   make(map[string][]string),
+	}
+	if item.From != nil && len(item.From.Mailboxes) > 0 {
+		email.From = convertEWSMailbox(item.From.Mailboxes[0])
+	}
+	if replyTo := convertEWSMailboxes(item.ReplyTo); len(replyTo) > 0 {
+		email.ReplyTo = replyTo[0]
+	}
+	if item.InReplyTo != "" {
+		email.Headers["In-Reply-To"] = []string{item.InReplyTo}
+	}
+	if strings.EqualFold(item.Importance, "High") {
+		email.Priority = "high"
+	} else if strings.EqualFold(item.Importance, "Low") {
+		email.Priority = "low"
+	}
+
+	if item.IsRead {
+		email.Flags = append(email.Flags, "\\Seen")
+	}
+	if item.property(ewsPropFlagStatus) == 2 {
+		email.Flags = append(email.Flags, "\\Flagged")
+	}
+	switch item.property(ewsPropLastVerb) {
+	case 102, 103:
+		email.Flags = append(email.Flags, "\\Answered")
+	case 104:
+		email.Flags = append(email.Flags, "$Forwarded")
+	}
+
+	if includeBody && item.MimeContent != "" {
+		mime, err := base64.StdEncoding.DecodeString(item.MimeContent)
+		if err != nil {
+			log.Printf("Failed to decode EWS MIME content for item %s: %v", item.ItemID.ID, err)
+		} else {
+			email.TextBody, email.HTMLBody, email.Attachments = parseEmailBodyUnified(bytes.NewReader(mime))
+		}
+	}
+	return email
+}
+
+// convertEWSMailbox 转换EWS邮箱地址
+func convertEWSMailbox(mailbox ewsMailbox) *models.EmailAddress {
+	return &models.EmailAddress{Name: mailbox.Name, Address: mailbox.EmailAddress}
+}
+
+// convertEWSMailboxes 转换EWS收件人列表
+func convertEWSMailboxes(recipients ewsRecipients) []*models.EmailAddress {
+	var result []*models.EmailAddress
+	for _, mailbox := range recipients.Mailboxes {
+		if mailbox.EmailAddress != "" {
+			result = append(result, convertEWSMailbox(mailbox))
+		}
+	}
+	return result
+}
+
+// FetchEmails 获取文件夹中的邮件，没有指定UID时获取全部邮件
+func (c *ewsIMAPClient) FetchEmails(ctx context.Context, criteria *FetchCriteria) ([]*EmailMessage, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("EWS client not connected")
+	}
+	folderName := criteria.FolderName
+	if folderName == "" {
+		folderName = c.selectedFolder()
+	}
+	state, err := c.syncFolder(ctx, folderName)
+	if err != nil {
+		return nil, err
+	}
+
+	state.mu.Lock()
+	uids := criteria.UIDs
+	if len(uids) == 0 {
+		uids = state.uidsInRange(1, 0)
+	}
+	ids := state.itemIDs(uids)
+	state.mu.Unlock()
+
+	return c.fetchItems(ctx, ids, criteria.IncludeBody)
+}
+
+// FetchEmailByUID 获取当前文件夹中的单个邮件
+func (c *ewsIMAPClient) FetchEmailByUID(ctx context.Context, uid uint32) (*EmailMessage, error) {
+	emails, err := c.FetchEmails(ctx, &FetchCriteria{UIDs: []uint32{uid}, IncludeBody: true})
+	if err != nil {
+		return nil, err
+	}
+	if len(emails) == 0 {
+		return nil, fmt.Errorf("email with UID %d not found", uid)
+	}
+	return emails[0], nil
+}
+
+// FetchEmailHeaders 获取当前文件夹中邮件的头信息
+func (c *ewsIMAPClient) FetchEmailHeaders(ctx context.Context, uids []uint32) ([]*EmailHeader, error) {
+	emails, err := c.FetchEmails(ctx, &FetchCriteria{UIDs: uids})
+	if err != nil {
+		return nil, err
+	}
+	headers := make([]*EmailHeader, 0, len(emails))
+	for _, email := range emails {
+		headers = append(headers, &EmailHeader{
+			UID:       email.UID,
+			MessageID: email.MessageID,
+			Subject:   email.Subject,
+			From:      email.From,
+			Date:      email.Date,
+			Size:      email.Size,
+			Flags:     email.Flags,
+		})
+	}
+	return headers, nil
+}
+
+// FetchRawEmail 获取当前文件夹中邮件的MIME原文
+func (c *ewsIMAPClient) FetchRawEmail(ctx context.Context, uid uint32) (io.ReadCloser, error) {
+	mime, err := c.fetchMime(ctx, c.selectedFolder(), uid)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(mime)), nil
+}
+
+// fetchMime 获取邮件的MIME原文
+func (c *ewsIMAPClient) fetchMime(ctx context.Context, folderName string, uid uint32) ([]byte, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("EWS client not connected")
+	}
+	ids, err := c.resolveItems(ctx, folderName, []uint32{uid})
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("email with UID %d not found", uid)
+	}
+	items, err := c.service.getItems(ctx, ids, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 || items[0].MimeContent == "" {
+		return nil, fmt.Errorf("email with UID %d has no MIME content", uid)
+	}
+	return base64.StdEncoding.DecodeString(items[0].MimeContent)
+}
+
+// updateItems 对当前文件夹中的邮件执行操作
+func (c *ewsIMAPClient) updateItems(ctx context.Context, uids []uint32, operation func(ids []string) error) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("EWS client not connected")
+	}
+	ids, err := c.resolveItems(ctx, c.selectedFolder(), uids)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return operation(ids)
+}
+
+// MarkAsRead 标记为已读
+func (c *ewsIMAPClient) MarkAsRead(ctx context.Context, uids []uint32) error {
+	return c.updateItems(ctx, uids, func(ids []string) error {
+		return c.service.setReadFlag(ctx, ids, true)
+	})
+}
+
+// MarkAsUnread 标记为未读
+func (c *ewsIMAPClient) MarkAsUnread(ctx context.Context, uids []uint32) error {
+	return c.updateItems(ctx, uids, func(ids []string) error {
+		return c.service.setReadFlag(ctx, ids, false)
+	})
+}
+
+// DeleteEmails 删除邮件
+func (c *ewsIMAPClient) DeleteEmails(ctx context.Context, uids []uint32) error {
+	return c.updateItems(ctx, uids, func(ids []string) error {
+		return c.service.deleteItems(ctx, ids)
+	})
+}
+
+// MoveEmails 移动邮件到目标文件夹
+func (c *ewsIMAPClient) MoveEmails(ctx context.Context, uids []uint32, targetFolder string) error {
+	return c.transfer(ctx, "MoveItem", uids, targetFolder)
+}
+
+// CopyEmails 复制邮件到目标文件夹
+func (c *ewsIMAPClient) CopyEmails(ctx context.Context, uids []uint32, targetFolder string) error {
+	return c.transfer(ctx, "CopyItem", uids, targetFolder)
+}
+
+// transfer 移动或复制邮件
+func (c *ewsIMAPClient) transfer(ctx context.Context, operation string, uids []uint32, targetFolder string) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("EWS client not connected")
+	}
+	target, err := c.folder(ctx, targetFolder)
+	if err != nil {
+		return err
+	}
+	return c.updateItems(ctx, uids, func(ids []string) error {
+		return c.service.transferItems(ctx, operation, ids, target.ID)
+	})
+}
+
+// AppendMessage 把MIME原文保存到文件夹，\Seen 标志对应已读，写入的邮件不是草稿
+func (c *ewsIMAPClient) AppendMessage(ctx context.Context, folderName string, flags []string, date time.Time, data []byte) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("EWS client not connected")
+	}
+	entry, err := c.folder(ctx, folderName)
+	if err != nil {
+		return err
+	}
+	messageFlags := "0"
+	for _, flag := range flags {
+		if flag == "\\Seen" {
+			messageFlags = "1"
+		}
+	}
+	extra := `<t:ExtendedProperty>` + ewsExtendedFieldXML(ewsPropMessageFlags, "Integer") +
+		`<t:Value>` + messageFlags + `</t:Value></t:ExtendedProperty>`
+	if err := c.service.createMimeItem(ctx, "SaveOnly", ewsFolderIDXML(entry.ID, false), data, extra); err != nil {
+		return fmt.Errorf("failed to append message: %w", err)
+	}
+	return nil
+}
+
+// SearchEmails 在文件夹中搜索邮件，返回UID
+func (c *ewsIMAPClient) SearchEmails(ctx context.Context, criteria *SearchCriteria) ([]uint32, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("EWS client not connected")
+	}
+	folderName := criteria.FolderName
+	if folderName == "" {
+		folderName = c.selectedFolder()
+	}
+	entry, err := c.folder(ctx, folderName)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := c.service.findItems(ctx, entry.ID, buildEWSRestriction(criteria))
+	if err != nil {
+		return nil, err
+	}
+	uids := make([]uint32, 0, len(items))
+	for i := range items {
+		if uid := items[i].uid(); uid != 0 {
+			uids = append(uids, uid)
+		}
+	}
+	sort.Slice(uids, func(i, j int) bool { return uids[i] < uids[j] })
+	return uids, nil
+}
+
+// buildEWSRestriction 把搜索条件转换为FindItem的限制条件，没有条件时返回空字符串
+func buildEWSRestriction(criteria *SearchCriteria) string {
+	var conditions []string
+	contains := func(field, value string) {
+		conditions = append(conditions, `<t:Contains ContainmentMode="Substring" ContainmentComparison="IgnoreCase">`+
+			field+`<t:Constant Value="`+ewsEscape(value)+`"/></t:Contains>`)
+	}
+	compare := func(operator, field, value string) {
+		conditions = append(conditions, `<t:`+operator+`>`+field+
+			`<t:FieldURIOrConstant><t:Constant Value="`+ewsEscape(value)+`"/></t:FieldURIOrConstant></t:`+operator+`>`)
+	}
+
+	if criteria.Subject != "" {
+		contains(`<t:FieldURI FieldURI="item:Subject"/>`, criteria.Subject)
+	}
+	if criteria.Body != "" {
+		contains(`<t:FieldURI FieldURI="item:Body"/>`, criteria.Body)
+	}
+	if criteria.From != "" {
+		contains(ewsExtendedFieldXML(ewsPropSenderAddress, "String"), criteria.From)
+	}
+	if criteria.To != "" {
+		contains(ewsExtendedFieldXML(ewsPropDisplayTo, "String"), criteria.To)
+	}
+	if criteria.MessageID != "" {
+		compare("IsEqualTo", `<t:FieldURI FieldURI="message:InternetMessageId"/>`, criteria.MessageID)
+	}
+	if criteria.Since != nil {
+		compare("IsGreaterThanOrEqualTo", `<t:FieldURI FieldURI="item:DateTimeReceived"/>`, criteria.Since.UTC().Format(time.RFC3339))
+	}
+	if criteria.Before != nil {
+		compare("IsLessThan", `<t:FieldURI FieldURI="item:DateTimeReceived"/>`, criteria.Before.UTC().Format(time.RFC3339))
+	}
+	if criteria.Seen != nil {
+		compare("IsEqualTo", `<t:FieldURI FieldURI="message:IsRead"/>`, fmt.Sprintf("%t", *criteria.Seen))
+	}
+	if criteria.Flagged != nil {
+		operator := "IsNotEqualTo"
+		if *criteria.Flagged {
+			operator = "IsEqualTo"
+		}
+		compare(operator, ewsExtendedFieldXML(ewsPropFlagStatus, "Integer"), "2")
+	}
+
+	switch len(conditions) {
+	case 0:
+		return ""
+	case 1:
+		return conditions[0]
+	default:
+		return `<t:And>` + strings.Join(conditions, "") + `</t:And>`
+	}
+}
+
+// GetNewEmails 获取文件夹中UID大于lastUID的邮件
+func (c *ewsIMAPClient) GetNewEmails(ctx context.Context, folderName string, lastUID uint32) ([]*EmailMessage, error) {
+	return c.GetEmailsInUIDRange(ctx, folderName, lastUID+1, 0)
+}
+
+// GetEmailsInUIDRange 获取文件夹中UID在范围内的邮件，endUID为0表示不限上限
+func (c *ewsIMAPClient) GetEmailsInUIDRange(ctx context.Context, folderName string, startUID, endUID uint32) ([]*EmailMessage, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("EWS client not connected")
+	}
+	state, err := c.syncFolder(ctx, folderName)
+	if err != nil {
+		return nil, err
+	}
+
+	state.mu.Lock()
+	ids := state.itemIDs(state.uidsInRange(startUID, endUID))
+	state.mu.Unlock()
+
+	return c.fetchItems(ctx, ids, true)
+}
+
+// GetAttachment 从邮件MIME原文中取出附件内容
+func (c *ewsIMAPClient) GetAttachment(ctx context.Context, folderName string, uid uint32, partID string) (io.ReadCloser, error) {
+	mime, err := c.fetchMime(ctx, folderName, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	_, _, attachments := parseEmailBodyUnified(bytes.NewReader(mime))
+	parsed := &EmailMessage{Attachments: attachments}
+	for _, attachment := range attachments {
+		if attachment.PartID != partID {
+			continue
+		}
+		if attachment.ContentPath == "" {
+			defer parsed.ReleaseContent()
+			return attachment.OpenContent()
+		}
+		// 溢出到临时文件的附件交给读取方，关闭时删除
+		file, err := os.Open(attachment.ContentPath)
+		if err != nil {
+			parsed.ReleaseContent()
+			return nil, err
+		}
+		attachment.ContentPath = ""
+		parsed.ReleaseContent()
+		return &tempFileReader{File: file}, nil
+	}
+	parsed.ReleaseContent()
+	return nil, fmt.Errorf("attachment part %s not found", partID)
+}
@@ -0,0 +1,118 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/mail"
+	"strings"
+	"sync"
+)
+
+// ewsSMTPClient 通过EWS发送邮件的SMTP客户端：用CreateItem发送MIME原文并在已发送中保存副本
+type ewsSMTPClient struct {
+	mutex   sync.RWMutex
+	service *ewsService
+}
+
+// newEWSSMTPClient 创建EWS的SMTP客户端
+func newEWSSMTPClient() *ewsSMTPClient {
+	return &ewsSMTPClient{}
+}
+
+// Connect 保存连接配置并获取已发送文件夹验证凭据
+func (c *ewsSMTPClient) Connect(ctx context.Context, config SMTPClientConfig) error {
+	service, err := newEWSService(config.Host, config.Port, config.Security, config.Username, config.Password, config.TLS, config.Resolver)
+	if err != nil {
+		return err
+	}
+	if _, err := service.getFolders(ctx, []string{"sentitems"}, true); err != nil {
+		service.close()
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.service != nil {
+		c.service.close()
+	}
+	c.service = service
+	return nil
+}
+
+// Disconnect EWS基于HTTP请求，只需关闭空闲的HTTP连接
+func (c *ewsSMTPClient) Disconnect() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.service != nil {
+		c.service.close()
+	}
+	c.service = nil
+	return nil
+}
+
+// IsConnected 检查连接状态
+func (c *ewsSMTPClient) IsConnected() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.service != nil
+}
+
+// SendEmail 发送邮件，密送收件人不写入邮件头，通过BccRecipients传递
+func (c *ewsSMTPClient) SendEmail(ctx context.Context, message *OutgoingMessage) error {
+	data, err := BuildMessage(message)
+	if err != nil {
+		return fmt.Errorf("failed to build email data: %w", err)
+	}
+
+	bcc := make([]string, 0, len(message.BCC))
+	for _, addr := range message.BCC {
+		bcc = append(bcc, addr.Address)
+	}
+	return c.send(ctx, data, bcc)
+}
+
+// SendRawEmail 发送原始邮件，邮件头中没有的信封收件人作为密送收件人
+func (c *ewsSMTPClient) SendRawEmail(ctx context.Context, from string, to []string, data []byte) error {
+	listed := make(map[string]bool)
+	if msg, err := mail.ReadMessage(bytes.NewReader(data)); err == nil {
+		for _, header := range []string{"To", "Cc"} {
+			addresses, _ := msg.Header.AddressList(header)
+			for _, addr := range addresses {
+				listed[strings.ToLower(addr.Address)] = true
+			}
+		}
+	}
+
+	var bcc []string
+	for _, recipient := range to {
+		if !listed[strings.ToLower(recipient)] {
+			bcc = append(bcc, recipient)
+		}
+	}
+	return c.send(ctx, data, bcc)
+}
+
+// send 发送MIME原文并保存到已发送
+func (c *ewsSMTPClient) send(ctx context.Context, data []byte, bcc []string) error {
+	c.mutex.RLock()
+	service := c.service
+	c.mutex.RUnlock()
+	if service == nil {
+		return fmt.Errorf("EWS client not connected")
+	}
+
+	var extra strings.Builder
+	if len(bcc) > 0 {
+		extra.WriteString(`<t:BccRecipients>`)
+		for _, address := range bcc {
+			extra.WriteString(`<t:Mailbox><t:EmailAddress>` + ewsEscape(address) + `</t:EmailAddress></t:Mailbox>`)
+		}
+		extra.WriteString(`</t:BccRecipients>`)
+	}
+
+	if err := service.createMimeItem(ctx, "SendAndSaveCopy", ewsFolderIDXML("sentitems", true), data, extra.String()); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
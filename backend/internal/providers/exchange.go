@@ -0,0 +1,219 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"firemail/internal/config"
+	"firemail/internal/models"
+)
+
+// ewsAutodiscoverURLs 自动发现的候选地址，{domain} 替换为邮箱域名，按顺序尝试
+var ewsAutodiscoverURLs = []string{
+	"https://{domain}/autodiscover/autodiscover.xml",
+	"https://autodiscover.{domain}/autodiscover/autodiscover.xml",
+}
+
+// ewsAutodiscoverMaxRedirects 自动发现返回 redirectAddr 时最多跟随的次数
+const ewsAutodiscoverMaxRedirects = 2
+
+// ExchangeProvider 本地部署Exchange的提供商，通过EWS收发邮件，不需要服务器开启IMAP/SMTP。
+// 账户的IMAPHost和SMTPHost都保存EWS地址（完整URL或主机名）
+type ExchangeProvider struct {
+	*BaseProvider
+}
+
+// NewExchangeProvider 创建Exchange提供商
+func NewExchangeProvider(config *config.EmailProviderConfig) EmailProvider {
+	provider := &ExchangeProvider{
+		BaseProvider: NewBaseProvider(config),
+	}
+
+	// EWS同时承担收件和发件
+	provider.SetIMAPClient(newEWSIMAPClient())
+	provider.SetSMTPClient(newEWSSMTPClient())
+
+	return provider
+}
+
+// Connect 连接到Exchange服务器，未配置EWS地址时先自动发现
+func (p *ExchangeProvider) Connect(ctx context.Context, account *models.EmailAccount) error {
+	if err := p.ensureExchangeConfig(ctx, account); err != nil {
+		return ClassifyProviderError(p.GetName(), err)
+	}
+	return p.BaseProvider.Connect(ctx, account)
+}
+
+// TestConnection 测试Exchange连接
+func (p *ExchangeProvider) TestConnection(ctx context.Context, account *models.EmailAccount) error {
+	if err := p.ensureExchangeConfig(ctx, account); err != nil {
+		return ClassifyProviderError(p.GetName(), err)
+	}
+	return p.BaseProvider.TestConnection(ctx, account)
+}
+
+// ensureExchangeConfig 确保账户配置了EWS地址
+func (p *ExchangeProvider) ensureExchangeConfig(ctx context.Context, account *models.EmailAccount) error {
+	if account.AuthMethod != "password" {
+		return fmt.Errorf("Exchange provider only supports password authentication")
+	}
+	if account.Username == "" {
+		account.Username = account.Email
+	}
+	if account.IMAPHost != "" {
+		if account.SMTPHost == "" {
+			account.SMTPHost, account.SMTPPort, account.SMTPSecurity = account.IMAPHost, account.IMAPPort, account.IMAPSecurity
+		}
+		return nil
+	}
+
+	endpoint, err := DiscoverEWSURL(ctx, account)
+	if err != nil {
+		return err
+	}
+	SetExchangeEndpoint(account, endpoint, 443, "SSL")
+	return nil
+}
+
+// SetExchangeEndpoint 把EWS地址写入账户的收件和发件服务器配置
+func SetExchangeEndpoint(account *models.EmailAccount, endpoint string, port int, security string) {
+	if port == 0 {
+		port = 443
+	}
+	if security == "" {
+		security = "SSL"
+	}
+	account.IMAPHost, account.IMAPPort, account.IMAPSecurity = endpoint, port, security
+	account.SMTPHost, account.SMTPPort, account.SMTPSecurity = endpoint, port, security
+}
+
+// GetProviderInfo 获取Exchange提供商信息
+func (p *ExchangeProvider) GetProviderInfo() map[string]interface{} {
+	info := p.BaseProvider.GetProviderInfo()
+	info["protocol"] = "ews"
+	info["autodiscover"] = true
+	return info
+}
+
+// ValidateEmailAddress 验证邮箱地址（Exchange支持任意域名）
+func (p *ExchangeProvider) ValidateEmailAddress(email string) error {
+	if extractDomain(email) == "" || strings.HasPrefix(email, "@") {
+		return fmt.Errorf("invalid email format")
+	}
+	return nil
+}
+
+// autodiscoverResponse POX自动发现响应
+type autodiscoverResponse struct {
+	Response struct {
+		Error *struct {
+			ErrorCode string `xml:"ErrorCode"`
+			Message   string `xml:"Message"`
+		} `xml:"Error"`
+		Account struct {
+			Action       string `xml:"Action"`
+			RedirectAddr string `xml:"RedirectAddr"`
+			Protocols    []struct {
+				Type   string `xml:"Type"`
+				EwsURL string `xml:"EwsUrl"`
+			} `xml:"Protocol"`
+		} `xml:"Account"`
+	} `xml:"Response"`
+}
+
+// DiscoverEWSURL 通过Exchange自动发现（POX）获取邮箱的EWS地址，使用账户的TLS设置和DNS解析器
+func DiscoverEWSURL(ctx context.Context, account *models.EmailAccount) (string, error) {
+	email, username, password := account.Email, account.Username, account.Password
+	if username == "" {
+		username = email
+	}
+	tlsOptions, resolver := TLSOptionsForAccount(account), ResolverForAccount(account)
+
+	for redirects := 0; redirects <= ewsAutodiscoverMaxRedirects; redirects++ {
+		domain := extractDomain(email)
+		if domain == "" {
+			return "", fmt.Errorf("invalid email address: %s", email)
+		}
+
+		var lastErr error
+		var result *autodiscoverResponse
+		for _, pattern := range ewsAutodiscoverURLs {
+			url := strings.ReplaceAll(pattern, "{domain}", domain)
+			result, lastErr = requestAutodiscover(ctx, url, email, username, password, tlsOptions, resolver)
+			if lastErr == nil {
+				break
+			}
+		}
+		if result == nil {
+			return "", fmt.Errorf("Exchange autodiscover failed for %s: %w", domain, lastErr)
+		}
+
+		account := result.Response.Account
+		if strings.EqualFold(account.Action, "redirectAddr") && account.RedirectAddr != "" {
+			email = account.RedirectAddr
+			continue
+		}
+		for _, protocol := range account.Protocols {
+			if (protocol.Type == "EXCH" || protocol.Type == "EXPR") && protocol.EwsURL != "" {
+				return protocol.EwsURL, nil
+			}
+		}
+		return "", fmt.Errorf("Exchange autodiscover response for %s has no EWS URL", domain)
+	}
+
+	return "", fmt.Errorf("Exchange autodiscover redirected too many times")
+}
+
+// requestAutodiscover 向一个候选地址发送自动发现请求
+func requestAutodiscover(ctx context.Context, url, email, username, password string, tlsOptions *TLSOptions, resolver *net.Resolver) (*autodiscoverResponse, error) {
+	var body bytes.Buffer
+	body.WriteString(`<?xml version="1.0" encoding="utf-8"?>`)
+	body.WriteString(`<Autodiscover xmlns="http://schemas.microsoft.com/exchange/autodiscover/outlook/requestschema/2006"><Request>`)
+	body.WriteString(`<EMailAddress>` + ewsEscape(email) + `</EMailAddress>`)
+	body.WriteString(`<AcceptableResponseSchema>http://schemas.microsoft.com/exchange/autodiscover/outlook/responseschema/2006a</AcceptableResponseSchema>`)
+	body.WriteString(`</Request></Autodiscover>`)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "text/xml; charset=utf-8")
+	req.SetBasicAuth(username, password)
+
+	client, err := newEWSHTTPClient(tlsOptions, resolver, req.URL.Hostname())
+	if err != nil {
+		return nil, err
+	}
+	defer client.CloseIdleConnections()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("Exchange autodiscover authentication failed: HTTP 401")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected autodiscover status: HTTP %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	var result autodiscoverResponse
+	if err := xml.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("invalid autodiscover response: %w", err)
+	}
+	if result.Response.Error != nil {
+		return nil, fmt.Errorf("autodiscover error %s: %s", result.Response.Error.ErrorCode, result.Response.Error.Message)
+	}
+	return &result, nil
+}
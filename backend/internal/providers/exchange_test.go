@@ -0,0 +1,318 @@
+package providers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"firemail/internal/config"
+	"firemail/internal/models"
+)
+
+// fakeEWSServer 模拟自动发现和EWS的测试服务器
+type fakeEWSServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	syncCalls []string // 每次SyncFolderItems携带的同步状态
+	sent      string   // 最近一次CreateItem请求
+}
+
+var ewsDistinguishedIDPattern = regexp.MustCompile(`DistinguishedFolderId Id="([^"]+)"`)
+
+const ewsTestMime = "From: Alice <alice@corp.example>\r\nTo: me@corp.example\r\nSubject: Quarterly report\r\n" +
+	"Message-ID: <report@corp.example>\r\nContent-Type: text/plain; charset=utf-8\r\n\r\nNumbers attached.\r\n"
+
+func newFakeEWSServer(t *testing.T) *fakeEWSServer {
+	return startFakeEWSServer(t, httptest.NewServer)
+}
+
+// startFakeEWSServer 用 start 启动测试服务器，httptest.NewTLSServer 得到使用自签名证书的HTTPS服务器
+func startFakeEWSServer(t *testing.T, start func(http.Handler) *httptest.Server) *fakeEWSServer {
+	fake := &fakeEWSServer{}
+	fake.Server = start(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, _ := r.BasicAuth(); user != "CORP\\me" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		body := string(data)
+
+		switch {
+		case r.URL.Path == "/missing/autodiscover.xml":
+			w.WriteHeader(http.StatusNotFound)
+		case r.URL.Path == "/autodiscover/autodiscover.xml":
+			fmt.Fprintf(w, `<Autodiscover><Response><Account><Action>settings</Action>`+
+				`<Protocol><Type>EXCH</Type><EwsUrl>%s/EWS/Exchange.asmx</EwsUrl></Protocol></Account></Response></Autodiscover>`, fake.URL)
+		default:
+			fake.handleEWS(w, body)
+		}
+	}))
+	t.Cleanup(fake.Close)
+	return fake
+}
+
+// handleEWS 按请求的操作返回响应
+func (f *fakeEWSServer) handleEWS(w http.ResponseWriter, body string) {
+	var messages string
+	switch {
+	case strings.Contains(body, "<m:GetFolder>"):
+		ids := ewsDistinguishedIDPattern.FindAllStringSubmatch(body, -1)
+		if len(ids) == 0 {
+			ids = [][]string{{"", "inbox"}}
+		}
+		for _, id := range ids {
+			name := id[1]
+			if name == "id-inbox" {
+				name = "inbox"
+			}
+			messages += `<m:GetFolderResponseMessage ResponseClass="Success"><m:Folders><t:Folder>` +
+				`<t:FolderId Id="id-` + name + `"/><t:DisplayName>` + name + `</t:DisplayName>` +
+				`<t:TotalCount>2</t:TotalCount><t:UnreadCount>1</t:UnreadCount></t:Folder></m:Folders></m:GetFolderResponseMessage>`
+		}
+	case strings.Contains(body, "<m:FindFolder"):
+		messages = `<m:FindFolderResponseMessage ResponseClass="Success"><m:RootFolder IncludesLastItemInRange="true"><t:Folders>` +
+			ewsTestFolder("id-inbox", "id-msgfolderroot", "IPF.Note", "Inbox") +
+			ewsTestFolder("id-projects", "id-inbox", "IPF.Note", "Projects") +
+			ewsTestFolder("id-sentitems", "id-msgfolderroot", "IPF.Note", "Sent Items") +
+			ewsTestFolder("id-calendar", "id-msgfolderroot", "IPF.Appointment", "Calendar") +
+			`</t:Folders></m:RootFolder></m:FindFolderResponseMessage>`
+	case strings.Contains(body, "<m:SyncFolderItems>"):
+		state := ""
+		if match := regexp.MustCompile(`<m:SyncState>([^<]*)</m:SyncState>`).FindStringSubmatch(body); match != nil {
+			state = match[1]
+		}
+		f.mu.Lock()
+		f.syncCalls = append(f.syncCalls, state)
+		f.mu.Unlock()
+
+		var changes string
+		if state == "" {
+			changes = `<t:Create><t:Message><t:ItemId Id="item-1"/>` + ewsTestArticle(1) + `</t:Message></t:Create>` +
+				`<t:Create><t:Message><t:ItemId Id="item-2"/>` + ewsTestArticle(2) + `</t:Message></t:Create>`
+		} else {
+			changes = `<t:Delete><t:ItemId Id="item-1"/></t:Delete>` +
+				`<t:Create><t:Message><t:ItemId Id="item-3"/>` + ewsTestArticle(3) + `</t:Message></t:Create>`
+		}
+		messages = `<m:SyncFolderItemsResponseMessage ResponseClass="Success"><m:SyncState>state-` + fmt.Sprint(len(f.syncCalls)) +
+			`</m:SyncState><m:IncludesLastItemInRange>true</m:IncludesLastItemInRange><m:Changes>` + changes +
+			`</m:Changes></m:SyncFolderItemsResponseMessage>`
+	case strings.Contains(body, "<m:GetItem>"):
+		for _, id := range regexp.MustCompile(`ItemId Id="item-(\d+)"`).FindAllStringSubmatch(body, -1) {
+			messages += `<m:GetItemResponseMessage ResponseClass="Success"><m:Items><t:Message>` +
+				`<t:MimeContent CharacterSet="UTF-8">` + base64.StdEncoding.EncodeToString([]byte(ewsTestMime)) + `</t:MimeContent>` +
+				`<t:ItemId Id="item-` + id[1] + `"/><t:Subject>Quarterly report</t:Subject>` +
+				`<t:DateTimeReceived>2026-10-01T08:00:00Z</t:DateTimeReceived><t:Size>512</t:Size>` +
+				`<t:From><t:Mailbox><t:Name>Alice</t:Name><t:EmailAddress>alice@corp.example</t:EmailAddress></t:Mailbox></t:From>` +
+				`<t:IsRead>false</t:IsRead><t:InternetMessageId>&lt;report@corp.example&gt;</t:InternetMessageId>` +
+				ewsTestArticle(mustAtoi(id[1])) +
+				`<t:ExtendedProperty><t:ExtendedFieldURI PropertyTag="0x1081" PropertyType="Integer"/><t:Value>102</t:Value></t:ExtendedProperty>` +
+				`</t:Message></m:Items></m:GetItemResponseMessage>`
+		}
+	case strings.Contains(body, "<m:CreateItem"):
+		f.mu.Lock()
+		f.sent = body
+		f.mu.Unlock()
+		messages = `<m:CreateItemResponseMessage ResponseClass="Success"><m:Items/></m:CreateItemResponseMessage>`
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" `+
+		`xmlns:m="http://schemas.microsoft.com/exchange/services/2006/messages" xmlns:t="http://schemas.microsoft.com/exchange/services/2006/types">`+
+		`<s:Body><m:Response><m:ResponseMessages>`+messages+`</m:ResponseMessages></m:Response></s:Body></s:Envelope>`)
+}
+
+func ewsTestFolder(id, parent, class, name string) string {
+	return `<t:Folder><t:FolderId Id="` + id + `"/><t:ParentFolderId Id="` + parent + `"/>` +
+		`<t:FolderClass>` + class + `</t:FolderClass><t:DisplayName>` + name + `</t:DisplayName></t:Folder>`
+}
+
+func ewsTestArticle(uid int) string {
+	return fmt.Sprintf(`<t:ExtendedProperty><t:ExtendedFieldURI PropertyTag="0xe23" PropertyType="Integer"/><t:Value>%d</t:Value></t:ExtendedProperty>`, uid)
+}
+
+func mustAtoi(value string) int {
+	var n int
+	fmt.Sscan(value, &n)
+	return n
+}
+
+func TestDiscoverEWSURL(t *testing.T) {
+	server := newFakeEWSServer(t)
+	original := ewsAutodiscoverURLs
+	defer func() { ewsAutodiscoverURLs = original }()
+	ewsAutodiscoverURLs = []string{server.URL + "/missing/autodiscover.xml", server.URL + "/autodiscover/autodiscover.xml"}
+
+	account := &models.EmailAccount{Email: "me@corp.example", Username: "CORP\\me", Password: "secret"}
+	url, err := DiscoverEWSURL(context.Background(), account)
+	if err != nil {
+		t.Fatalf("DiscoverEWSURL failed: %v", err)
+	}
+	if url != server.URL+"/EWS/Exchange.asmx" {
+		t.Errorf("unexpected EWS URL: %s", url)
+	}
+
+	account.Password = "wrong"
+	if _, err := DiscoverEWSURL(context.Background(), account); err == nil ||
+		!strings.Contains(err.Error(), "authentication failed") {
+		t.Errorf("expected authentication error, got %v", err)
+	}
+}
+
+func TestExchangeProviderSync(t *testing.T) {
+	server := newFakeEWSServer(t)
+	original := ewsAutodiscoverURLs
+	defer func() { ewsAutodiscoverURLs = original }()
+	ewsAutodiscoverURLs = []string{server.URL + "/autodiscover/autodiscover.xml"}
+
+	provider := NewExchangeProvider(config.GetProviderByName("exchange")).(*ExchangeProvider)
+	account := &models.EmailAccount{Email: "me@corp.example", Username: "CORP\\me", Password: "secret", AuthMethod: "password", Provider: "exchange"}
+	ctx := context.Background()
+	if err := provider.Connect(ctx, account); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer provider.Disconnect()
+	if account.IMAPHost != server.URL+"/EWS/Exchange.asmx" || account.SMTPHost != account.IMAPHost {
+		t.Errorf("autodiscovered endpoint not applied: %s / %s", account.IMAPHost, account.SMTPHost)
+	}
+
+	client := provider.imapClient
+	folders, err := client.ListFolders(ctx)
+	if err != nil {
+		t.Fatalf("ListFolders failed: %v", err)
+	}
+	var paths []string
+	for _, folder := range folders {
+		paths = append(paths, folder.Path+":"+folder.Type)
+	}
+	if got := strings.Join(paths, ","); got != "INBOX:inbox,INBOX/Projects:custom,Sent Items:sent" {
+		t.Errorf("unexpected folders: %s", got)
+	}
+
+	status, err := client.GetFolderStatus(ctx, "INBOX")
+	if err != nil {
+		t.Fatalf("GetFolderStatus failed: %v", err)
+	}
+	if status.UIDNext != 3 || status.TotalEmails != 2 {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	// 第二次同步只获取变更：item-1被删除，新增item-3
+	emails, err := client.GetNewEmails(ctx, "INBOX", 1)
+	if err != nil {
+		t.Fatalf("GetNewEmails failed: %v", err)
+	}
+	if len(emails) != 2 || emails[0].UID != 2 || emails[1].UID != 3 {
+		t.Fatalf("unexpected emails: %+v", emails)
+	}
+	email := emails[0]
+	if email.From.Address != "alice@corp.example" || email.TextBody == "" || email.MessageID != "<report@corp.example>" {
+		t.Errorf("unexpected email: %+v", email)
+	}
+	if strings.Join(email.Flags, ",") != "\\Answered" {
+		t.Errorf("unexpected flags: %v", email.Flags)
+	}
+	if got := strings.Join(server.syncCalls, ","); got != ",state-1" {
+		t.Errorf("sync should continue from the previous state, got %q", got)
+	}
+
+	// 发送邮件时密送收件人通过BccRecipients传递
+	err = provider.smtpClient.SendEmail(ctx, &OutgoingMessage{
+		From:     &models.EmailAddress{Address: "me@corp.example"},
+		To:       []*models.EmailAddress{{Address: "bob@corp.example"}},
+		BCC:      []*models.EmailAddress{{Address: "audit@corp.example"}},
+		Subject:  "Hello",
+		TextBody: "Hi Bob",
+	})
+	if err != nil {
+		t.Fatalf("SendEmail failed: %v", err)
+	}
+	if !strings.Contains(server.sent, `MessageDisposition="SendAndSaveCopy"`) ||
+		!strings.Contains(server.sent, `<t:BccRecipients><t:Mailbox><t:EmailAddress>audit@corp.example</t:EmailAddress>`) {
+		t.Errorf("unexpected CreateItem request: %s", server.sent)
+	}
+}
+
+func TestEWSClientUsesAccountTLSAndResolver(t *testing.T) {
+	server := startFakeEWSServer(t, httptest.NewTLSServer)
+	cert := server.Certificate()
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+	digest := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	pin := tlsPinPrefix + base64.StdEncoding.EncodeToString(digest[:])
+	otherPin := tlsPinPrefix + base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))
+
+	connect := func(host string, options *TLSOptions, resolver *net.Resolver) error {
+		client := newEWSIMAPClient()
+		defer client.Disconnect()
+		return client.Connect(context.Background(), IMAPClientConfig{
+			Host: host, Security: "SSL", Username: "CORP\\me", Password: "secret", TLS: options, Resolver: resolver,
+		})
+	}
+
+	endpoint := server.URL + ewsDefaultPath
+	if err := connect(endpoint, nil, nil); err == nil {
+		t.Fatal("expected self-signed certificate to be rejected by default")
+	}
+	if err := connect(endpoint, &TLSOptions{CACertPEM: caPEM, MinVersion: "1.2"}, nil); err != nil {
+		t.Fatalf("custom CA bundle: %v", err)
+	}
+
+	// 账户的DNS解析器把EWS主机名解析到测试服务器，证书只按公钥指纹校验
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	resolver := newStaticTestResolver(t, "ews.corp.example.", [4]byte{127, 0, 0, 1})
+	endpoint = "https://ews.corp.example:" + port + ewsDefaultPath
+	if err := connect(endpoint, &TLSOptions{InsecureSkipVerify: true, PinnedKeys: []string{pin}}, resolver); err != nil {
+		t.Fatalf("account resolver with pinned key: %v", err)
+	}
+	if err := connect(endpoint, &TLSOptions{InsecureSkipVerify: true, PinnedKeys: []string{otherPin}}, resolver); err == nil ||
+		!strings.Contains(err.Error(), "certificate pin mismatch") {
+		t.Fatalf("expected pin mismatch, got %v", err)
+	}
+}
+
+// newStaticTestResolver 返回只把 name 解析为 ip 的DNS解析器，通过测试用的DoH服务器应答
+func newStaticTestResolver(t *testing.T, name string, ip [4]byte) *net.Resolver {
+	t.Helper()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var query dnsmessage.Message
+		if err := query.Unpack(body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		answer := dnsmessage.Message{
+			Header:    dnsmessage.Header{ID: query.ID, Response: true, RecursionAvailable: true},
+			Questions: query.Questions,
+		}
+		if question := query.Questions[0]; question.Type == dnsmessage.TypeA && question.Name.String() == name {
+			answer.Answers = []dnsmessage.Resource{{
+				Header: dnsmessage.ResourceHeader{Name: question.Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 60},
+				Body:   &dnsmessage.AResource{A: ip},
+			}}
+		}
+		packed, _ := answer.Pack()
+		w.Header().Set("Content-Type", "application/dns-message")
+		w.Write(packed)
+	}))
+	t.Cleanup(server.Close)
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return &dohConn{ctx: ctx, client: server.Client(), url: server.URL}, nil
+		},
+	}
+}
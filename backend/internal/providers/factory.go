@@ -1,8 +1,11 @@
 package providers
 
 import (
+	"context"
 	"fmt"
+	"net"
 	"strings"
+	"time"
 
 	"firemail/internal/config"
 	"firemail/internal/models"
@@ -30,6 +33,7 @@ func NewProviderFactory() *ProviderFactory {
 	factory.RegisterProvider("qq", NewQQProvider)
 	factory.RegisterProvider("163", NewNetEaseProvider)
 	factory.RegisterProvider("icloud", NewiCloudProvider)
+	factory.RegisterProvider("exchange", NewExchangeProvider)
 	factory.RegisterProvider("custom", NewCustomProvider)
 	// TODO: 实现新浪邮箱提供商
 	// factory.RegisterProvider("sina", NewSinaProvider)
@@ -44,6 +48,11 @@ func (f *ProviderFactory) RegisterProvider(name string, constructor func(*config
 
 // CreateProvider 创建提供商实例
 func (f *ProviderFactory) CreateProvider(name string) (EmailProvider, error) {
+	// 入站虚拟账户没有服务器配置
+	if name == IngestProviderName {
+		return NewIngestProvider(), nil
+	}
+
 	constructor, exists := f.providers[name]
 	if !exists {
 		return nil, fmt.Errorf("unknown provider: %s", name)
@@ -99,13 +108,44 @@ func (f *ProviderFactory) GetProviderConfigByDomain(domain string) *config.Email
 	return config.GetProviderByDomain(domain)
 }
 
-// DetectProvider 检测邮箱的提供商
+// mxLookupTimeout 检测自定义域名提供商时查询MX记录的超时时间
+const mxLookupTimeout = 3 * time.Second
+
+// lookupMX 查询域名的MX记录，测试中可替换
+var lookupMX = func(ctx context.Context, domain string) ([]*net.MX, error) {
+	return DefaultResolver().LookupMX(ctx, domain)
+}
+
+// DetectProvider 检测邮箱的提供商；域名不属于内置提供商时按MX记录识别托管在提供商的自定义域名
 func (f *ProviderFactory) DetectProvider(email string) *config.EmailProviderConfig {
 	domain := extractDomain(email)
 	if domain == "" {
 		return nil
 	}
-	return config.GetProviderByDomain(domain)
+	providerConfig := config.GetProviderByDomain(domain)
+	if providerConfig.Name != "custom" {
+		return providerConfig
+	}
+	if hosted := detectProviderByMX(domain); hosted != nil {
+		return hosted
+	}
+	return providerConfig
+}
+
+// detectProviderByMX 按MX记录查找托管该域名的提供商，查询失败时返回nil
+func detectProviderByMX(domain string) *config.EmailProviderConfig {
+	ctx, cancel := context.WithTimeout(context.Background(), mxLookupTimeout)
+	defer cancel()
+
+	records, err := lookupMX(ctx, domain)
+	if err != nil {
+		return nil
+	}
+	hosts := make([]string, 0, len(records))
+	for _, record := range records {
+		hosts = append(hosts, record.Host)
+	}
+	return config.GetProviderByMX(hosts)
 }
 
 // ValidateProviderConfig 验证提供商配置
@@ -0,0 +1,149 @@
+package providers
+
+import (
+	"strings"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/responses"
+
+	"firemail/internal/models"
+)
+
+// specialUseFolderTypes SPECIAL-USE（RFC 6154）和Gmail XLIST属性对应的文件夹类型，属性不区分大小写
+var specialUseFolderTypes = map[string]string{
+	`\inbox`:   models.FolderTypeInbox, // XLIST
+	`\sent`:    models.FolderTypeSent,
+	`\drafts`:  models.FolderTypeDrafts,
+	`\trash`:   models.FolderTypeTrash,
+	`\junk`:    models.FolderTypeSpam,
+	`\spam`:    models.FolderTypeSpam, // XLIST
+	`\archive`: models.FolderTypeArchive,
+}
+
+// localizedFolderTypes 服务器未返回SPECIAL-USE属性时，按常见客户端和服务商使用的本地化名称识别文件夹。
+// 键为路径最后一级的小写名称
+var localizedFolderTypes = map[string]string{}
+
+func init() {
+	names := map[string][]string{
+		models.FolderTypeInbox: {
+			"收件箱", "收件匣",
+		},
+		models.FolderTypeSent: {
+			"sent", "sent items", "sent mail", "sent messages", "sent-mail", "sent e-mail",
+			"gesendet", "gesendete objekte", "gesendete elemente", "gesendete nachrichten",
+			"envoyés", "envoyes", "éléments envoyés", "elements envoyes", "messages envoyés", "envoyé",
+			"enviados", "elementos enviados", "correo enviado", "mensajes enviados",
+			"inviati", "posta inviata", "elementi inviati",
+			"enviadas", "itens enviados", "mensagens enviadas",
+			"verzonden", "verzonden items", "verzonden berichten",
+			"отправленные", "отправленные элементы",
+			"wysłane", "elementy wysłane",
+			"skickat", "skickade", "skickade objekt",
+			"sendt", "sendte", "sendte elementer", "sendte objekter",
+			"lähetetyt", "lähetetyt kohteet",
+			"odeslané", "odeslaná pošta",
+			"gönderilmiş öğeler", "gönderilenler",
+			"送信済み", "送信済みアイテム", "送信済みメール",
+			"보낸편지함", "보낸 편지함",
+			"已发送", "已发送邮件", "发件箱", "已傳送", "寄件備份", "已寄出",
+		},
+		models.FolderTypeDrafts: {
+			"drafts", "draft",
+			"entwürfe", "brouillons", "borradores", "bozze", "rascunhos", "concepten",
+			"черновики", "kopie robocze", "utkast", "kladder", "luonnokset", "koncepty", "taslaklar",
+			"下書き", "임시보관함", "임시 보관함",
+			"草稿", "草稿箱", "草稿夹", "草稿匣",
+		},
+		models.FolderTypeTrash: {
+			"trash", "deleted", "deleted items", "deleted messages", "bin",
+			"papierkorb", "gelöschte objekte", "gelöschte elemente",
+			"corbeille", "éléments supprimés", "elements supprimes",
+			"papelera", "elementos eliminados",
+			"cestino", "posta eliminata", "elementi eliminati",
+			"lixeira", "lixo", "itens excluídos", "itens eliminados",
+			"prullenbak", "verwijderde items",
+			"корзина", "удаленные", "удалённые",
+			"kosz", "elementy usunięte",
+			"papperskorgen", "borttagna objekt",
+			"papirkurv", "slettede elementer", "slettet",
+			"roskakori", "poistetut",
+			"koš", "odstraněná pošta",
+			"çöp kutusu", "silinmiş öğeler",
+			"ゴミ箱", "削除済みアイテム", "휴지통",
+			"垃圾箱", "已删除", "已删除邮件", "已刪除", "垃圾桶", "回收站",
+		},
+		models.FolderTypeSpam: {
+			"spam", "junk", "junk e-mail", "junk email", "junk mail", "bulk mail",
+			"spamverdacht", "junk-e-mail",
+			"courrier indésirable", "indésirables", "pourriel",
+			"correo no deseado", "no deseado",
+			"posta indesiderata",
+			"lixo eletrônico", "lixo eletrónico",
+			"ongewenste e-mail", "ongewenst",
+			"спам", "нежелательная почта",
+			"wiadomości-śmieci", "skräppost",
+			"uønsket e-post", "uønsket post",
+			"roskaposti", "nevyžádaná pošta", "istenmeyen e-posta",
+			"迷惑メール", "스팸메일함",
+			"垃圾邮件", "垃圾郵件",
+		},
+		models.FolderTypeArchive: {
+			"archive", "archives", "archiv", "archivo", "archivio", "arquivo", "archief",
+			"архив", "archiwum", "arkiv", "arkisto", "archív", "arşiv",
+			"アーカイブ", "보관함",
+			"存档", "归档", "已归档", "封存",
+		},
+	}
+	for folderType, list := range names {
+		for _, name := range list {
+			localizedFolderTypes[name] = folderType
+		}
+	}
+}
+
+// detectFolderType 检测文件夹类型：优先使用LIST/XLIST返回的SPECIAL-USE属性，
+// 其次按路径最后一级的本地化名称识别，都不匹配时为自定义文件夹
+func detectFolderType(name, delimiter string, attributes []string) string {
+	// INBOX 名称固定且不区分大小写（RFC 3501）
+	if strings.EqualFold(name, "INBOX") {
+		return models.FolderTypeInbox
+	}
+
+	for _, attribute := range attributes {
+		if folderType, ok := specialUseFolderTypes[strings.ToLower(attribute)]; ok {
+			return folderType
+		}
+	}
+
+	leaf := name
+	if delimiter != "" {
+		if i := strings.LastIndex(name, delimiter); i >= 0 {
+			leaf = name[i+len(delimiter):]
+		}
+	}
+	if folderType, ok := localizedFolderTypes[strings.ToLower(strings.TrimSpace(leaf))]; ok {
+		return folderType
+	}
+
+	return models.FolderTypeCustom
+}
+
+// xlistResponse 收集Gmail旧版XLIST命令返回的文件夹，格式与LIST相同
+type xlistResponse struct {
+	mailboxes []*imap.MailboxInfo
+}
+
+func (r *xlistResponse) Handle(resp imap.Resp) error {
+	name, fields, ok := imap.ParseNamedResp(resp)
+	if !ok || name != "XLIST" {
+		return responses.ErrUnhandled
+	}
+
+	mbox := &imap.MailboxInfo{}
+	if err := mbox.Parse(fields); err != nil {
+		return err
+	}
+	r.mailboxes = append(r.mailboxes, mbox)
+	return nil
+}
@@ -0,0 +1,38 @@
+package providers
+
+import (
+	"testing"
+
+	"firemail/internal/models"
+)
+
+func TestDetectFolderType(t *testing.T) {
+	tests := []struct {
+		name       string
+		delimiter  string
+		attributes []string
+		want       string
+	}{
+		{name: "INBOX", delimiter: "/", want: models.FolderTypeInbox},
+		{name: "Inbox", delimiter: ".", want: models.FolderTypeInbox},
+		{name: "[Gmail]/Gesendet", delimiter: "/", attributes: []string{"\\HasNoChildren", "\\Sent"}, want: models.FolderTypeSent},
+		{name: "[Gmail]/Spam", delimiter: "/", attributes: []string{"\\Spam"}, want: models.FolderTypeSpam},
+		{name: "Alles", delimiter: "/", attributes: []string{"\\JUNK"}, want: models.FolderTypeSpam},
+		{name: "Ablage", delimiter: "/", attributes: []string{"\\Archive"}, want: models.FolderTypeArchive},
+		{name: "Papierkorb", delimiter: "/", want: models.FolderTypeTrash},
+		{name: "INBOX.Éléments envoyés", delimiter: ".", want: models.FolderTypeSent},
+		{name: "INBOX.Drafts", delimiter: ".", want: models.FolderTypeDrafts},
+		{name: "INBOX/Work", delimiter: "/", want: models.FolderTypeCustom},
+		{name: "Consent forms", delimiter: "/", want: models.FolderTypeCustom},
+		{name: "Sent Messages", delimiter: "/", want: models.FolderTypeSent},
+		{name: "已删除", delimiter: "/", want: models.FolderTypeTrash},
+		{name: "垃圾邮件", delimiter: "/", want: models.FolderTypeSpam},
+		{name: "Posta indesiderata", delimiter: "/", want: models.FolderTypeSpam},
+	}
+
+	for _, tt := range tests {
+		if got := detectFolderType(tt.name, tt.delimiter, tt.attributes); got != tt.want {
+			t.Errorf("detectFolderType(%q, %v) = %q, want %q", tt.name, tt.attributes, got, tt.want)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package providers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -47,6 +48,11 @@ func NewGmailProvider(config *config.EmailProviderConfig) EmailProvider {
 
 // Connect 连接到Gmail服务器
 func (p *GmailProvider) Connect(ctx context.Context, account *models.EmailAccount) error {
+	return ClassifyProviderError(p.GetName(), p.connect(ctx, account))
+}
+
+// connect 执行实际的连接流程
+func (p *GmailProvider) connect(ctx context.Context, account *models.EmailAccount) error {
 	// 确保使用正确的服务器配置
 	p.ensureGmailConfig(account)
 
@@ -244,6 +250,11 @@ func (p *GmailProvider) isNonRetryableError(err error) bool {
 		return false
 	}
 
+	// 已分类的认证、配额、限速错误不在连接重试中处理
+	if IsPermanentError(err) || errors.Is(err, ErrRateLimited) {
+		return true
+	}
+
 	errStr := err.Error()
 
 	// 认证错误、配置错误等不需要重试
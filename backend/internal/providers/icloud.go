@@ -2,6 +2,7 @@ package providers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -37,6 +38,11 @@ func NewiCloudProvider(config *config.EmailProviderConfig) EmailProvider {
 
 // Connect 连接到iCloud邮箱服务器
 func (p *iCloudProvider) Connect(ctx context.Context, account *models.EmailAccount) error {
+	return ClassifyProviderError(p.GetName(), p.connect(ctx, account))
+}
+
+// connect 执行实际的连接流程
+func (p *iCloudProvider) connect(ctx context.Context, account *models.EmailAccount) error {
 	// 确保使用正确的服务器配置
 	p.ensureiCloudConfig(account)
 
@@ -45,9 +51,14 @@ func (p *iCloudProvider) Connect(ctx context.Context, account *models.EmailAccou
 		return fmt.Errorf("iCloud mail only supports password authentication with app-specific passwords")
 	}
 
+	// 自定义域名和隐藏邮件地址使用 Apple ID 登录，密码为该 Apple ID 的应用专用密码
+	if err := p.validateAppleIDLogin(account); err != nil {
+		return err
+	}
+
 	// 验证是否使用了应用专用密码
 	if err := p.validateAppSpecificPassword(account); err != nil {
-		return fmt.Errorf("iCloud mail authentication failed: %w", err)
+		return p.appPasswordSetupError(account, err)
 	}
 
 	// 使用重试机制连接
@@ -119,6 +130,56 @@ func (p *iCloudProvider) validateAppSpecificPassword(account *models.EmailAccoun
 	return nil
 }
 
+// iCloudDomains iCloud邮箱自有域名，其他域名为托管在iCloud的自定义域名
+var iCloudDomains = []string{"icloud.com", "me.com", "mac.com"}
+
+// iCloudAppPasswordSteps 生成 iCloud 应用专用密码的步骤
+var iCloudAppPasswordSteps = []string{
+	"Sign in to your Apple ID at appleid.apple.com",
+	"Open Sign-In and Security > App-Specific Passwords",
+	"Generate a new app-specific password labeled FireMail",
+	"Enter the password in the format xxxx-xxxx-xxxx-xxxx",
+}
+
+// iCloudAppleIDSteps 自定义域名和隐藏邮件地址的登录设置步骤
+var iCloudAppleIDSteps = []string{
+	"Use your primary iCloud Mail address (ending in @icloud.com, @me.com or @mac.com) as the username",
+	"Keep the custom domain or Hide My Email address as the account email and add other addresses as send aliases",
+	"Use an app-specific password generated for that Apple ID as the password",
+}
+
+// isiCloudDomainAddress 判断地址是否属于iCloud邮箱自有域名
+func isiCloudDomainAddress(address string) bool {
+	domain := extractDomain(address)
+	for _, supported := range iCloudDomains {
+		if domain == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// validateAppleIDLogin 自定义域名地址不能用于登录，需要使用 Apple ID 的 iCloud 邮箱地址作为用户名
+func (p *iCloudProvider) validateAppleIDLogin(account *models.EmailAccount) error {
+	if isiCloudDomainAddress(account.Username) {
+		return nil
+	}
+	return NewSetupError(p.GetName(), "ICLOUD_APPLE_ID_REQUIRED",
+		"iCloud custom domain addresses must sign in with the primary iCloud Mail address of the Apple ID",
+		p.config.HelpURLs["mail_setup"], iCloudAppleIDSteps, nil)
+}
+
+// appPasswordSetupError 创建附带应用专用密码设置步骤的设置错误；
+// 别名账户的密码必须是登录所用 Apple ID 的应用专用密码
+func (p *iCloudProvider) appPasswordSetupError(account *models.EmailAccount, cause error) error {
+	message := "iCloud mail requires an app-specific password"
+	if !strings.EqualFold(account.Username, account.Email) {
+		message = "iCloud mail aliases require an app-specific password of the Apple ID used as the username"
+	}
+	return NewSetupError(p.GetName(), "ICLOUD_APP_PASSWORD_INVALID", message,
+		p.config.HelpURLs["app_passwords"], iCloudAppPasswordSteps, cause)
+}
+
 // connectWithRetry 带重试机制的连接
 func (p *iCloudProvider) connectWithRetry(ctx context.Context, account *models.EmailAccount) error {
 	maxRetries := 3
@@ -193,6 +254,11 @@ func (p *iCloudProvider) isNonRetryableError(err error) bool {
 		return false
 	}
 
+	// 已分类的认证、配额、限速错误不在连接重试中处理
+	if IsPermanentError(err) || errors.Is(err, ErrRateLimited) {
+		return true
+	}
+
 	errStr := err.Error()
 
 	// 认证错误、配置错误等不需要重试
@@ -218,9 +284,13 @@ func (p *iCloudProvider) TestConnection(ctx context.Context, account *models.Ema
 	// 确保配置正确
 	p.ensureiCloudConfig(account)
 
+	if err := p.validateAppleIDLogin(account); err != nil {
+		return err
+	}
+
 	// 验证应用专用密码
 	if err := p.validateAppSpecificPassword(account); err != nil {
-		return err
+		return p.appPasswordSetupError(account, err)
 	}
 
 	// 调用基类测试方法
@@ -279,9 +349,9 @@ func (p *iCloudProvider) SendEmail(ctx context.Context, account *models.EmailAcc
 		}
 	}
 
-	// 验证发件人地址是否匹配账户
-	if message.From.Address != account.Email {
-		return fmt.Errorf("sender address must match account email for iCloud mail")
+	// 发件人地址必须是账户邮箱或发件别名（自定义域名地址、隐藏邮件地址）
+	if !account.CanSendAs(message.From.Address) {
+		return fmt.Errorf("sender address must match account email or one of its send aliases for iCloud mail")
 	}
 
 	// 检查发送限制
@@ -309,20 +379,20 @@ func (p *iCloudProvider) checkSendingLimits(message *OutgoingMessage) error {
 	return nil
 }
 
-// ValidateEmailAddress 验证iCloud邮箱地址格式
+// ValidateEmailAddress 验证iCloud邮箱地址格式，自定义域名需要MX记录指向iCloud
 func (p *iCloudProvider) ValidateEmailAddress(email string) error {
-	email = strings.ToLower(email)
-
-	// 检查是否是支持的iCloud邮箱域名
-	supportedDomains := []string{"icloud.com", "me.com", "mac.com"}
+	if isiCloudDomainAddress(email) {
+		return nil
+	}
 
-	for _, domain := range supportedDomains {
-		if strings.HasSuffix(email, "@"+domain) {
+	domain := extractDomain(email)
+	if domain != "" {
+		if hosted := detectProviderByMX(domain); hosted != nil && hosted.Name == p.GetName() {
 			return nil
 		}
 	}
 
-	return fmt.Errorf("unsupported iCloud mail domain. Supported domains: %s", strings.Join(supportedDomains, ", "))
+	return fmt.Errorf("unsupported iCloud mail domain. Supported domains: %s or a custom domain hosted by iCloud", strings.Join(iCloudDomains, ", "))
 }
 
 // GetAppSpecificPasswordInstructions 获取iCloud应用专用密码设置说明
@@ -354,7 +424,7 @@ func (p *iCloudProvider) GetProviderInfo() map[string]interface{} {
 		"name":         "iCloud邮箱",
 		"display_name": "iCloud邮箱（Apple）",
 		"auth_methods": []string{"password"},
-		"domains":      []string{"icloud.com", "me.com", "mac.com"},
+		"domains":      iCloudDomains,
 		"servers": map[string]interface{}{
 			"imap": map[string]interface{}{
 				"host":     "imap.mail.me.com",
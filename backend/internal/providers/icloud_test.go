@@ -0,0 +1,94 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"firemail/internal/config"
+	"firemail/internal/models"
+)
+
+func TestDetectProviderByMX(t *testing.T) {
+	original := lookupMX
+	defer func() { lookupMX = original }()
+
+	lookupMX = func(ctx context.Context, domain string) ([]*net.MX, error) {
+		switch domain {
+		case "example.org":
+			return []*net.MX{{Host: "mx01.mail.icloud.com.", Pref: 10}, {Host: "mx02.mail.icloud.com.", Pref: 10}}, nil
+		case "self-hosted.net":
+			return []*net.MX{{Host: "mail.self-hosted.net.", Pref: 10}}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+
+	factory := NewProviderFactory()
+	cases := map[string]string{
+		"me@example.org":     "icloud",
+		"me@self-hosted.net": "custom",
+		"me@unknown.test":    "custom",
+		"me@icloud.com":      "icloud",
+		"me@qq.com":          "qq",
+	}
+	for email, want := range cases {
+		if got := factory.DetectProvider(email); got == nil || got.Name != want {
+			t.Errorf("DetectProvider(%s) = %v, want %s", email, got, want)
+		}
+	}
+
+	provider := newiCloudProviderImpl(config.GetProviderByName("icloud"))
+	if err := provider.ValidateEmailAddress("me@example.org"); err != nil {
+		t.Errorf("custom domain hosted by iCloud should be valid: %v", err)
+	}
+	if err := provider.ValidateEmailAddress("me@self-hosted.net"); err == nil {
+		t.Error("domain not hosted by iCloud should be rejected")
+	}
+}
+
+func TestICloudAliasLogin(t *testing.T) {
+	provider := newiCloudProviderImpl(config.GetProviderByName("icloud"))
+
+	// 自定义域名地址不能作为用户名登录
+	account := &models.EmailAccount{Email: "me@example.org", Username: "me@example.org", Password: "abcd-efgh-ijkl-mnop"}
+	err := provider.validateAppleIDLogin(account)
+	if guide := SetupGuideOf(err); guide == nil || guide.Code != "ICLOUD_APPLE_ID_REQUIRED" {
+		t.Fatalf("expected Apple ID setup guide, got %v", err)
+	}
+
+	account.Username = "someone@icloud.com"
+	if err := provider.validateAppleIDLogin(account); err != nil {
+		t.Errorf("Apple ID login should be accepted: %v", err)
+	}
+
+	// 别名账户同样需要应用专用密码
+	account.Password = "my-apple-password"
+	err = provider.TestConnection(context.Background(), account)
+	guide := SetupGuideOf(err)
+	if guide == nil || guide.Code != "ICLOUD_APP_PASSWORD_INVALID" {
+		t.Fatalf("expected app-specific password setup guide, got %v", err)
+	}
+	if guide.Message != "iCloud mail aliases require an app-specific password of the Apple ID used as the username" {
+		t.Errorf("unexpected message: %s", guide.Message)
+	}
+	if !errors.Is(err, ErrAuth) {
+		t.Errorf("setup error should be an auth error: %v", err)
+	}
+}
+
+func TestEmailAccountCanSendAs(t *testing.T) {
+	account := &models.EmailAccount{Email: "someone@icloud.com"}
+	if err := account.SetSendAliases([]string{"me@example.org", "random_words_0a@icloud.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, address := range []string{"someone@icloud.com", "Me@Example.org", "random_words_0a@icloud.com"} {
+		if !account.CanSendAs(address) {
+			t.Errorf("expected %s to be allowed", address)
+		}
+	}
+	if account.CanSendAs("other@example.org") {
+		t.Error("unknown address should not be allowed")
+	}
+}
@@ -1,6 +1,7 @@
 package providers
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"fmt"
@@ -32,6 +33,7 @@ type StandardIMAPClient struct {
 	mutex            sync.RWMutex
 	conn             net.Conn // 保存底层连接用于超时管理
 	readWriteTimeout time.Duration
+	maxPartSize      int64 // 单个部分内联下载的大小上限，0表示使用默认值
 }
 
 // NewStandardIMAPClient 创建标准IMAP客户端
@@ -65,55 +67,62 @@ func (c *StandardIMAPClient) Connect(ctx context.Context, config IMAPClientConfi
 	switch strings.ToUpper(config.Security) {
 	case "SSL", "TLS":
 		// 直接使用TLS连接
-		tlsConfig := &tls.Config{
-			ServerName: config.Host,
+		tlsConfig, err := config.TLS.Config(config.Host)
+		if err != nil {
+			return err
 		}
 
 		// 使用带超时的连接
 		dialer := &net.Dialer{
-			Timeout: connectTimeout,
+			Timeout:  connectTimeout,
+			Resolver: config.Resolver,
 		}
 		conn, err := tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
 		if err != nil {
 			return fmt.Errorf("failed to connect to IMAP server with TLS: %w", err)
 		}
 
-		// 设置读写超时
-		conn.SetDeadline(time.Now().Add(readWriteTimeout))
+		// 按带宽预算限速，并设置读写超时
+		throttled := throttleConn(conn, config.Bandwidth)
+		throttled.SetDeadline(time.Now().Add(readWriteTimeout))
 
 		// 创建IMAP客户端
-		imapClient, err = client.New(conn)
+		imapClient, err = client.New(throttled)
 		if err != nil {
-			conn.Close()
+			throttled.Close()
 			return fmt.Errorf("failed to create IMAP client: %w", err)
 		}
 
 		// 保存连接引用
-		c.conn = conn
+		c.conn = throttled
 
 	case "STARTTLS":
 		// 先明文连接，然后升级到TLS
 		dialer := &net.Dialer{
-			Timeout: connectTimeout,
+			Timeout:  connectTimeout,
+			Resolver: config.Resolver,
 		}
 		conn, err := dialer.Dial("tcp", addr)
 		if err != nil {
 			return fmt.Errorf("failed to connect to IMAP server: %w", err)
 		}
 
-		// 设置读写超时
-		conn.SetDeadline(time.Now().Add(readWriteTimeout))
+		// 按带宽预算限速，并设置读写超时
+		throttled := throttleConn(conn, config.Bandwidth)
+		throttled.SetDeadline(time.Now().Add(readWriteTimeout))
 
 		// 创建IMAP客户端
-		imapClient, err = client.New(conn)
+		imapClient, err = client.New(throttled)
 		if err != nil {
-			conn.Close()
+			throttled.Close()
 			return fmt.Errorf("failed to create IMAP client: %w", err)
 		}
 
 		// 升级到TLS
-		tlsConfig := &tls.Config{
-			ServerName: config.Host,
+		tlsConfig, err := config.TLS.Config(config.Host)
+		if err != nil {
+			imapClient.Close()
+			return err
 		}
 		err = imapClient.StartTLS(tlsConfig)
 		if err != nil {
@@ -122,30 +131,32 @@ func (c *StandardIMAPClient) Connect(ctx context.Context, config IMAPClientConfi
 		}
 
 		// 保存连接引用
-		c.conn = conn
+		c.conn = throttled
 
 	case "NONE":
 		// 明文连接
 		dialer := &net.Dialer{
-			Timeout: connectTimeout,
+			Timeout:  connectTimeout,
+			Resolver: config.Resolver,
 		}
 		conn, err := dialer.Dial("tcp", addr)
 		if err != nil {
 			return fmt.Errorf("failed to connect to IMAP server: %w", err)
 		}
 
-		// 设置读写超时
-		conn.SetDeadline(time.Now().Add(readWriteTimeout))
+		// 按带宽预算限速，并设置读写超时
+		throttled := throttleConn(conn, config.Bandwidth)
+		throttled.SetDeadline(time.Now().Add(readWriteTimeout))
 
 		// 创建IMAP客户端
-		imapClient, err = client.New(conn)
+		imapClient, err = client.New(throttled)
 		if err != nil {
-			conn.Close()
+			throttled.Close()
 			return fmt.Errorf("failed to create IMAP client: %w", err)
 		}
 
 		// 保存连接引用
-		c.conn = conn
+		c.conn = throttled
 
 	default:
 		return fmt.Errorf("unsupported security type: %s", config.Security)
@@ -191,6 +202,7 @@ func (c *StandardIMAPClient) Connect(ctx context.Context, config IMAPClientConfi
 
 	c.client = imapClient
 	c.connected = true
+	c.maxPartSize = config.MaxPartSize
 
 	return nil
 }
@@ -374,17 +386,14 @@ func (c *StandardIMAPClient) ListFolders(ctx context.Context) ([]*FolderInfo, er
 		return nil, fmt.Errorf("IMAP client not connected")
 	}
 
-	// 获取文件夹列表
-	mailboxes := make(chan *imap.MailboxInfo, 10)
-	done := make(chan error, 1)
-
-	go func() {
-		done <- c.client.List("", "*", mailboxes)
-	}()
+	mailboxes, err := c.listMailboxes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list folders: %w", err)
+	}
 
 	var folders []*FolderInfo
-	for m := range mailboxes {
-		folderType := detectFolderType(m.Name)
+	for _, m := range mailboxes {
+		folderType := detectFolderType(m.Name, m.Delimiter, m.Attributes)
 		folder := &FolderInfo{
 			Name:         m.Name,
 			DisplayName:  m.Name,
@@ -397,11 +406,42 @@ func (c *StandardIMAPClient) ListFolders(ctx context.Context) ([]*FolderInfo, er
 		folders = append(folders, folder)
 	}
 
-	if err := <-done; err != nil {
-		return nil, fmt.Errorf("failed to list folders: %w", err)
+	return folders, nil
+}
+
+// listMailboxes 列出所有文件夹。支持SPECIAL-USE的服务器在LIST中返回特殊用途属性；
+// 只支持旧版XLIST的服务器（早期Gmail）改用XLIST获取这些属性
+func (c *StandardIMAPClient) listMailboxes() ([]*imap.MailboxInfo, error) {
+	specialUse, _ := c.client.Support("SPECIAL-USE")
+	xlist, _ := c.client.Support("XLIST")
+	if xlist && !specialUse {
+		res := &xlistResponse{}
+		status, err := c.client.Execute(&imap.Command{Name: "XLIST", Arguments: []interface{}{"", "*"}}, res)
+		if err == nil {
+			err = status.Err()
+		}
+		if err == nil {
+			return res.mailboxes, nil
+		}
+		log.Printf("XLIST failed, falling back to LIST: %v", err)
 	}
 
-	return folders, nil
+	mailboxes := make(chan *imap.MailboxInfo, 10)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- c.client.List("", "*", mailboxes)
+	}()
+
+	var result []*imap.MailboxInfo
+	for m := range mailboxes {
+		result = append(result, m)
+	}
+
+	if err := <-done; err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 // SelectFolder 选择文件夹
@@ -480,29 +520,6 @@ func (c *StandardIMAPClient) GetFolderStatus(ctx context.Context, folderName str
 
 // 辅助函数
 
-// detectFolderType 检测文件夹类型
-func detectFolderType(name string) string {
-	name = strings.ToLower(name)
-
-	if strings.Contains(name, "inbox") || name == "收件箱" {
-		return "inbox"
-	}
-	if strings.Contains(name, "sent") || name == "已发送" || name == "发件箱" {
-		return "sent"
-	}
-	if strings.Contains(name, "draft") || name == "草稿" || name == "草稿箱" {
-		return "drafts"
-	}
-	if strings.Contains(name, "trash") || strings.Contains(name, "deleted") || name == "垃圾箱" || name == "已删除" {
-		return "trash"
-	}
-	if strings.Contains(name, "spam") || strings.Contains(name, "junk") || name == "垃圾邮件" {
-		return "spam"
-	}
-
-	return "custom"
-}
-
 // contains函数已在capabilities.go中定义
 
 // OAuth2Auth OAuth2认证器
@@ -564,41 +581,64 @@ func (c *StandardIMAPClient) FetchEmails(ctx context.Context, criteria *FetchCri
 		imap.FetchUid,
 	}
 
-	if criteria.IncludeBody {
-		items = append(items, imap.FetchRFC822)
-	}
+	if !criteria.IncludeBody {
+		messages, err := c.uidFetch(seqSet, items)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch emails: %w", err)
+		}
 
-	// 获取邮件
-	messages := make(chan *imap.Message, 10)
-	done := make(chan error, 1)
+		var emails []*EmailMessage
+		for _, msg := range messages {
+			if email := convertIMAPMessage(msg, false); email != nil {
+				emails = append(emails, email)
+			}
+		}
+		return emails, nil
+	}
 
 	// 如果包含邮件正文，增加超时时间以防止大邮件被截断
-	if criteria.IncludeBody {
-		c.RefreshConnectionTimeout()
+	c.RefreshConnectionTimeout()
+
+	// 先获取BODYSTRUCTURE，超过上限的大邮件按部分获取，避免整封下载超时
+	messages, err := c.uidFetch(seqSet, append(items, imap.FetchBodyStructure))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch emails: %w", err)
 	}
 
-	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				log.Printf("IMAP fetch panic recovered: %v", r)
-				done <- fmt.Errorf("IMAP fetch panic: %v", r)
-			}
-		}()
-		done <- c.client.UidFetch(seqSet, items, messages)
-	}()
+	limit := c.partSizeLimit()
+	fullSet := new(imap.SeqSet)
+	byUID := make(map[uint32]*EmailMessage, len(messages))
+	for _, msg := range messages {
+		if msg.BodyStructure == nil || int64(msg.Size) <= limit {
+			fullSet.AddNum(msg.Uid)
+			continue
+		}
 
-	var emails []*EmailMessage
-	for msg := range messages {
-		if msg != nil {
-			email := convertIMAPMessage(msg, criteria.IncludeBody)
-			if email != nil {
-				emails = append(emails, email)
+		email, err := c.fetchMessageParts(ctx, msg, limit)
+		if err != nil {
+			return nil, err
+		}
+		byUID[msg.Uid] = email
+	}
+
+	if !fullSet.Empty() {
+		fullMessages, err := c.uidFetch(fullSet, append(items, imap.FetchRFC822))
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch emails: %w", err)
+		}
+		for _, msg := range fullMessages {
+			if email := convertIMAPMessage(msg, true); email != nil {
+				byUID[msg.Uid] = email
 			}
 		}
 	}
 
-	if err := <-done; err != nil {
-		return nil, fmt.Errorf("failed to fetch emails: %w", err)
+	// 保持服务器返回的顺序
+	var emails []*EmailMessage
+	for _, msg := range messages {
+		if email, ok := byUID[msg.Uid]; ok {
+			emails = append(emails, email)
+		}
 	}
 
 	return emails, nil
@@ -703,6 +743,28 @@ func (c *StandardIMAPClient) setFlags(uids []uint32, flags []string, add bool) e
 	return c.client.UidStore(seqSet, operation, flags, nil)
 }
 
+// AllowsKeywords 当前选中文件夹是否允许保存自定义关键字
+func (c *StandardIMAPClient) AllowsKeywords() bool {
+	if !c.IsConnected() {
+		return false
+	}
+	mailbox := c.client.Mailbox()
+	if mailbox == nil {
+		return false
+	}
+	for _, flag := range mailbox.PermanentFlags {
+		if flag == imap.TryCreateFlag {
+			return true
+		}
+	}
+	return false
+}
+
+// StoreFlags 添加或移除邮件标志
+func (c *StandardIMAPClient) StoreFlags(ctx context.Context, uids []uint32, flags []string, add bool) error {
+	return c.setFlags(uids, flags, add)
+}
+
 // MoveEmails 移动邮件
 func (c *StandardIMAPClient) MoveEmails(ctx context.Context, uids []uint32, targetFolder string) error {
 	if !c.IsConnected() {
@@ -717,6 +779,45 @@ func (c *StandardIMAPClient) MoveEmails(ctx context.Context, uids []uint32, targ
 	return c.client.UidMove(seqSet, targetFolder)
 }
 
+// SupportsAnnotations 服务器是否支持ANNOTATE扩展
+func (c *StandardIMAPClient) SupportsAnnotations() bool {
+	if !c.IsConnected() {
+		return false
+	}
+	supported, err := c.client.Support("ANNOTATE-EXPERIMENT-1")
+	return err == nil && supported
+}
+
+// SetAnnotation 使用UID STORE ANNOTATION设置邮件的私有注释（value.priv），value为空时删除
+func (c *StandardIMAPClient) SetAnnotation(ctx context.Context, uid uint32, entry, value string) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("IMAP client not connected")
+	}
+
+	seqSet := new(imap.SeqSet)
+	seqSet.AddNum(uid)
+
+	var annotationValue interface{}
+	if value != "" {
+		annotationValue = value
+	}
+	cmd := &imap.Command{
+		Name: "UID",
+		Arguments: []interface{}{
+			imap.RawString("STORE"),
+			seqSet,
+			imap.RawString("ANNOTATION"),
+			[]interface{}{entry, []interface{}{imap.RawString("value.priv"), annotationValue}},
+		},
+	}
+
+	status, err := c.client.Execute(cmd, nil)
+	if err != nil {
+		return fmt.Errorf("failed to store annotation: %w", err)
+	}
+	return status.Err()
+}
+
 // CopyEmails 复制邮件
 func (c *StandardIMAPClient) CopyEmails(ctx context.Context, uids []uint32, targetFolder string) error {
 	if !c.IsConnected() {
@@ -731,6 +832,19 @@ func (c *StandardIMAPClient) CopyEmails(ctx context.Context, uids []uint32, targ
 	return c.client.UidCopy(seqSet, targetFolder)
 }
 
+// AppendMessage 将原始邮件追加到指定文件夹（用于草稿同步）
+func (c *StandardIMAPClient) AppendMessage(ctx context.Context, folderName string, flags []string, date time.Time, data []byte) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("IMAP client not connected")
+	}
+
+	if err := c.client.Append(folderName, flags, date, bytes.NewBuffer(data)); err != nil {
+		return fmt.Errorf("failed to append message: %w", err)
+	}
+
+	return nil
+}
+
 // SearchEmails 搜索邮件
 func (c *StandardIMAPClient) SearchEmails(ctx context.Context, criteria *SearchCriteria) ([]uint32, error) {
 	if !c.IsConnected() {
@@ -977,6 +1091,10 @@ func convertIMAPMessage(msg *imap.Message, includeBody bool) *EmailMessage {
 		if len(msg.Envelope.ReplyTo) > 0 {
 			email.ReplyTo = convertIMAPAddressWithDecoding(msg.Envelope.ReplyTo[0], encodingHelper)
 		}
+
+		if msg.Envelope.InReplyTo != "" {
+			email.Headers["In-Reply-To"] = []string{msg.Envelope.InReplyTo}
+		}
 	}
 
 	// 解析邮件正文和附件
@@ -1094,6 +1212,10 @@ func buildSearchCriteria(criteria *SearchCriteria) *imap.SearchCriteria {
 		searchCriteria.Text = []string{criteria.Body}
 	}
 
+	if criteria.MessageID != "" {
+		searchCriteria.Header.Set("Message-Id", criteria.MessageID)
+	}
+
 	if criteria.Since != nil {
 		searchCriteria.Since = *criteria.Since
 	}
@@ -1133,73 +1255,42 @@ func (c *StandardIMAPClient) GetAttachment(ctx context.Context, folderName strin
 		return nil, fmt.Errorf("failed to select folder: %w", err)
 	}
 
-	// 构建序列集
-	seqSet := new(imap.SeqSet)
-	seqSet.AddNum(uid)
-
-	// 构建获取项目 - 获取指定部分的内容
-	section := &imap.BodySectionName{
-		BodyPartName: imap.BodyPartName{
-			Specifier: imap.PartSpecifier(partID),
-		},
+	if parsePartPath(partID) == nil {
+		return nil, fmt.Errorf("invalid part ID: %s", partID)
 	}
 
-	items := []imap.FetchItem{imap.FetchItem("BODY[" + partID + "]")}
-
-	// 获取附件内容
-	messages := make(chan *imap.Message, 1)
-	done := make(chan error, 1)
-
-	go func() {
-		done <- c.client.UidFetch(seqSet, items, messages)
-	}()
-
-	// 等待消息
-	var msg *imap.Message
-	select {
-	case msg = <-messages:
-		if msg == nil {
-			return nil, fmt.Errorf("attachment not found")
-		}
-	case <-ctx.Done():
-		return nil, ctx.Err()
+	// 分块下载到临时文件，读取方关闭时自动删除
+	file, err := c.downloadPartToFile(ctx, uid, partID)
+	if err != nil {
+		return nil, err
 	}
 
-	// 等待完成
-	if err := <-done; err != nil {
-		return nil, fmt.Errorf("failed to fetch attachment: %w", err)
+	return &tempFileReader{File: file}, nil
+}
+
+// FetchRawEmail 获取当前文件夹中指定UID邮件的原始内容，读取方关闭时删除临时文件
+func (c *StandardIMAPClient) FetchRawEmail(ctx context.Context, uid uint32) (io.ReadCloser, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("IMAP client not connected")
 	}
 
-	// 获取附件内容
-	literal := msg.GetBody(section)
-	if literal == nil {
-		return nil, fmt.Errorf("attachment content not found")
+	// 空的部分编号对应BODY.PEEK[]，即整封邮件
+	file, err := c.downloadPartToFile(ctx, uid, "")
+	if err != nil {
+		return nil, err
 	}
 
-	// 包装literal为ReadCloser
-	return io.NopCloser(literal), nil
+	return &tempFileReader{File: file}, nil
 }
 
-
-
-// parseEmailBodyUnified 使用统一解析器解析邮件正文
+// parseEmailBodyUnified 使用统一解析器流式解析邮件正文，较大的附件内容溢出到临时文件
 func parseEmailBodyUnified(body io.Reader) (textBody, htmlBody string, attachments []*AttachmentInfo) {
 	if body == nil {
 		return "", "", nil
 	}
 
-	// 读取邮件内容
-	content, err := io.ReadAll(body)
-	if err != nil {
-		log.Printf("Failed to read email body: %v", err)
-		return "", "", nil
-	}
-
-	if len(content) == 0 {
-		return "", "", nil
-	}
-
-	log.Printf("📧 [UNIFIED] Starting unified email parsing, content size: %d bytes", len(content))
+	// 解析只会在读取邮件头时失败，保留已读取的开头部分用于纯文本回退
+	head := &headBuffer{limit: fallbackHeadLimit}
 
 	// 使用新的统一解析器
 	options := &parser.ParseOptions{
@@ -1207,14 +1298,17 @@ func parseEmailBodyUnified(body io.Reader) (textBody, htmlBody string, attachmen
 		MaxAttachmentSize:        25 * 1024 * 1024, // 25MB
 		StrictMode:               false,
 		MaxErrors:                10,
+		MemoryLimit:              parser.DefaultMemoryLimit,
+		MaxBodySize:              parser.DefaultMaxBodySize,
 	}
 	unifiedParser := parser.NewUnifiedParser(options)
 
-	parsed, err := unifiedParser.ParseEmail(content)
+	parsed, err := unifiedParser.ParseEmailFromReader(io.TeeReader(body, head))
 	if err != nil {
 		log.Printf("Warning: Unified parsing failed: %v, falling back to simple parsing", err)
 		// 简单回退：尝试将内容作为纯文本处理
-		return string(content), "", nil
+		rest, _ := io.ReadAll(io.LimitReader(body, parser.DefaultMaxBodySize))
+		return head.String() + string(rest), "", nil
 	}
 
 	// 提取解析结果
@@ -1236,6 +1330,27 @@ func parseEmailBodyUnified(body io.Reader) (textBody, htmlBody string, attachmen
 	return textBody, htmlBody, attachments
 }
 
+// 解析失败回退时保留的已读取内容上限
+const fallbackHeadLimit = 64 * 1024
+
+// headBuffer 只保留写入数据的前limit字节
+type headBuffer struct {
+	bytes.Buffer
+	limit int64
+}
+
+// Write 写入数据，超出上限的部分被忽略
+func (b *headBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - int64(b.Len()); remaining > 0 {
+		if int64(len(p)) > remaining {
+			b.Buffer.Write(p[:remaining])
+		} else {
+			b.Buffer.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
 // convertUnifiedAttachmentsToLegacyFormat 转换统一解析器的附件格式为兼容格式
 func convertUnifiedAttachmentsToLegacyFormat(unifiedAttachments []*parser.AttachmentInfo) []*AttachmentInfo {
 	var legacyAttachments []*AttachmentInfo
@@ -1254,6 +1369,7 @@ func convertUnifiedAttachmentsToLegacyFormat(unifiedAttachments []*parser.Attach
 			Disposition: att.Disposition,
 			Encoding:    att.Encoding,
 			Content:     att.Content,
+			ContentPath: att.ContentPath,
 		}
 
 		legacyAttachments = append(legacyAttachments, legacyAtt)
@@ -0,0 +1,342 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/emersion/go-imap"
+
+	"firemail/internal/encoding"
+	"firemail/internal/encoding/transfer"
+)
+
+const (
+	// DefaultMaxPartSize 账户未配置时单个MIME部分内联下载的大小上限
+	DefaultMaxPartSize int64 = 10 * 1024 * 1024
+	// 流式下载附件时每次请求的字节数
+	attachmentChunkSize = 1024 * 1024
+)
+
+// messagePart BODYSTRUCTURE中的一个叶子部分
+type messagePart struct {
+	PartID    string
+	Structure *imap.BodyStructure
+}
+
+// planMessageParts 根据BODYSTRUCTURE区分正文部分与附件部分
+func planMessageParts(bs *imap.BodyStructure) (textParts, attachmentParts []*messagePart) {
+	if bs == nil {
+		return nil, nil
+	}
+
+	bs.Walk(func(path []int, part *imap.BodyStructure) bool {
+		if len(part.Parts) > 0 {
+			return true
+		}
+
+		partID := formatPartPath(path)
+		mimeType := strings.ToLower(part.MIMEType + "/" + part.MIMESubType)
+		filename, _ := part.Filename()
+		isAttachment := strings.EqualFold(part.Disposition, "attachment") || filename != ""
+
+		if (mimeType == "text/plain" || mimeType == "text/html") && !isAttachment {
+			textParts = append(textParts, &messagePart{PartID: partID, Structure: part})
+		} else {
+			// 内嵌的message/rfc822整体作为附件处理，不再展开
+			attachmentParts = append(attachmentParts, &messagePart{PartID: partID, Structure: part})
+		}
+		return false
+	})
+
+	return textParts, attachmentParts
+}
+
+func formatPartPath(path []int) string {
+	parts := make([]string, len(path))
+	for i, num := range path {
+		parts[i] = strconv.Itoa(num)
+	}
+	return strings.Join(parts, ".")
+}
+
+// partSection 构建获取指定部分的BODY.PEEK请求，limit>0时只获取前limit字节
+func partSection(partID string, limit int64) *imap.BodySectionName {
+	section := &imap.BodySectionName{
+		BodyPartName: imap.BodyPartName{Path: parsePartPath(partID)},
+		Peek:         true,
+	}
+	if limit > 0 {
+		section.Partial = []int{0, int(limit)}
+	}
+	return section
+}
+
+func parsePartPath(partID string) []int {
+	var path []int
+	for _, field := range strings.Split(strings.Trim(partID, "."), ".") {
+		num, err := strconv.Atoi(field)
+		if err != nil {
+			return nil
+		}
+		path = append(path, num)
+	}
+	return path
+}
+
+// estimateDecodedSize 根据传输编码估算部分解码后的大小
+func estimateDecodedSize(part *imap.BodyStructure) int64 {
+	size := int64(part.Size)
+	if strings.EqualFold(part.Encoding, "base64") {
+		return size * 3 / 4
+	}
+	return size
+}
+
+// partSizeLimit 单个部分内联下载的大小上限
+func (c *StandardIMAPClient) partSizeLimit() int64 {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if c.maxPartSize > 0 {
+		return c.maxPartSize
+	}
+	return DefaultMaxPartSize
+}
+
+// uidFetch 执行UID FETCH并收集返回的消息
+func (c *StandardIMAPClient) uidFetch(seqSet *imap.SeqSet, items []imap.FetchItem) ([]*imap.Message, error) {
+	messages := make(chan *imap.Message, 10)
+	done := make(chan error, 1)
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("IMAP fetch panic recovered: %v", r)
+				done <- fmt.Errorf("IMAP fetch panic: %v", r)
+			}
+		}()
+		done <- c.client.UidFetch(seqSet, items, messages)
+	}()
+
+	var result []*imap.Message
+	for msg := range messages {
+		if msg != nil {
+			result = append(result, msg)
+		}
+	}
+
+	if err := <-done; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// fetchMessageParts 按BODYSTRUCTURE分部分获取超大邮件：
+// 正文部分优先获取，不超过上限的附件一并获取，超过上限的附件只记录元数据，由附件服务按需下载
+func (c *StandardIMAPClient) fetchMessageParts(ctx context.Context, msg *imap.Message, limit int64) (*EmailMessage, error) {
+	email := convertIMAPMessage(msg, false)
+	textParts, attachmentParts := planMessageParts(msg.BodyStructure)
+
+	var items []imap.FetchItem
+	textSections := make(map[string]*imap.BodySectionName)
+	for _, part := range textParts {
+		var partLimit int64
+		if int64(part.Structure.Size) > limit {
+			partLimit = limit
+			log.Printf("Text part %s of UID %d exceeds %d bytes, truncating", part.PartID, msg.Uid, limit)
+		}
+		section := partSection(part.PartID, partLimit)
+		textSections[part.PartID] = section
+		items = append(items, section.FetchItem())
+	}
+
+	inlineSections := make(map[string]*imap.BodySectionName)
+	for _, part := range attachmentParts {
+		if int64(part.Structure.Size) <= limit {
+			section := partSection(part.PartID, 0)
+			inlineSections[part.PartID] = section
+			items = append(items, section.FetchItem())
+		}
+	}
+
+	var fetched *imap.Message
+	if len(items) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		seqSet := new(imap.SeqSet)
+		seqSet.AddNum(msg.Uid)
+		messages, err := c.uidFetch(seqSet, items)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch parts of UID %d: %w", msg.Uid, err)
+		}
+		if len(messages) == 0 {
+			return nil, fmt.Errorf("email with UID %d not found", msg.Uid)
+		}
+		fetched = messages[0]
+	}
+
+	encodingHelper := encoding.NewEmailEncodingHelper()
+	for _, part := range textParts {
+		raw := readSection(fetched, textSections[part.PartID])
+		if raw == nil {
+			continue
+		}
+		content, err := encodingHelper.DecodeEmailContentWithTransferEncoding(raw, part.Structure.Encoding, part.Structure.Params["charset"])
+		if err != nil {
+			content, _ = encodingHelper.DecodeWithFallbackStrategies(raw, part.Structure.Encoding, part.Structure.Params["charset"])
+		}
+
+		if strings.EqualFold(part.Structure.MIMESubType, "html") {
+			email.HTMLBody = joinBody(email.HTMLBody, string(content))
+		} else {
+			email.TextBody = joinBody(email.TextBody, string(content))
+		}
+	}
+
+	for _, part := range attachmentParts {
+		attachment := newPartAttachment(part)
+		if section, ok := inlineSections[part.PartID]; ok {
+			if raw := readSection(fetched, section); raw != nil {
+				content, err := transfer.DecodeWithFallback(raw, part.Structure.Encoding)
+				if err != nil {
+					content = raw
+				}
+				attachment.Content = content
+				attachment.Size = int64(len(content))
+			}
+		}
+		email.Attachments = append(email.Attachments, attachment)
+	}
+
+	log.Printf("Fetched UID %d by parts (size: %d, text parts: %d, attachments: %d, inline: %d)",
+		msg.Uid, msg.Size, len(textParts), len(attachmentParts), len(inlineSections))
+	return email, nil
+}
+
+// newPartAttachment 根据BODYSTRUCTURE构建附件信息，PartID为IMAP部分编号，可直接用于GetAttachment
+func newPartAttachment(part *messagePart) *AttachmentInfo {
+	bs := part.Structure
+	filename, err := bs.Filename()
+	if err != nil {
+		// 未知字符集时返回的是未解码的原始文件名
+		filename = encoding.NewEmailEncodingHelper().DecodeEmailSubject(filename)
+	}
+	if filename == "" {
+		filename = "attachment_" + strings.ReplaceAll(part.PartID, ".", "_")
+	}
+
+	disposition := strings.ToLower(bs.Disposition)
+	if disposition == "" {
+		disposition = "attachment"
+	}
+
+	return &AttachmentInfo{
+		PartID:      part.PartID,
+		Filename:    filename,
+		ContentType: strings.ToLower(bs.MIMEType + "/" + bs.MIMESubType),
+		Size:        estimateDecodedSize(bs),
+		ContentID:   strings.Trim(bs.Id, "<>"),
+		Disposition: disposition,
+		Encoding:    strings.ToLower(bs.Encoding),
+	}
+}
+
+func readSection(msg *imap.Message, section *imap.BodySectionName) []byte {
+	if msg == nil || section == nil {
+		return nil
+	}
+	literal := msg.GetBody(section)
+	if literal == nil {
+		return nil
+	}
+	data, err := io.ReadAll(literal)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func joinBody(existing, content string) string {
+	if existing == "" {
+		return content
+	}
+	return existing + "\n" + content
+}
+
+// downloadPartToFile 分块获取指定部分并写入临时文件，避免大附件整体驻留内存
+func (c *StandardIMAPClient) downloadPartToFile(ctx context.Context, uid uint32, partID string) (*os.File, error) {
+	file, err := os.CreateTemp("", "firemail-part-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	cleanup := func() {
+		file.Close()
+		os.Remove(file.Name())
+	}
+
+	seqSet := new(imap.SeqSet)
+	seqSet.AddNum(uid)
+
+	for offset := 0; ; offset += attachmentChunkSize {
+		if err := ctx.Err(); err != nil {
+			cleanup()
+			return nil, err
+		}
+
+		section := partSection(partID, 0)
+		section.Partial = []int{offset, attachmentChunkSize}
+
+		messages, err := c.uidFetch(seqSet, []imap.FetchItem{section.FetchItem()})
+		if err != nil {
+			cleanup()
+			return nil, fmt.Errorf("failed to fetch attachment: %w", err)
+		}
+		if len(messages) == 0 {
+			cleanup()
+			return nil, fmt.Errorf("attachment not found")
+		}
+
+		literal := messages[0].GetBody(section)
+		if literal == nil {
+			if offset == 0 {
+				cleanup()
+				return nil, fmt.Errorf("attachment content not found")
+			}
+			break
+		}
+
+		written, err := io.Copy(file, literal)
+		if err != nil {
+			cleanup()
+			return nil, fmt.Errorf("failed to write attachment chunk: %w", err)
+		}
+		if written < attachmentChunkSize {
+			break
+		}
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		cleanup()
+		return nil, fmt.Errorf("failed to rewind temp file: %w", err)
+	}
+	return file, nil
+}
+
+// tempFileReader 关闭时删除临时文件
+type tempFileReader struct {
+	*os.File
+}
+
+// Close 关闭并删除临时文件
+func (r *tempFileReader) Close() error {
+	err := r.File.Close()
+	os.Remove(r.File.Name())
+	return err
+}
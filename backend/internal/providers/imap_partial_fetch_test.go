@@ -0,0 +1,99 @@
+package providers
+
+import (
+	"os"
+	"testing"
+
+	"github.com/emersion/go-imap"
+)
+
+func TestPlanMessageParts(t *testing.T) {
+	bs := &imap.BodyStructure{
+		MIMEType:    "multipart",
+		MIMESubType: "mixed",
+		Parts: []*imap.BodyStructure{
+			{
+				MIMEType:    "multipart",
+				MIMESubType: "alternative",
+				Parts: []*imap.BodyStructure{
+					{MIMEType: "text", MIMESubType: "plain", Params: map[string]string{"charset": "utf-8"}, Size: 120},
+					{MIMEType: "text", MIMESubType: "html", Params: map[string]string{"charset": "utf-8"}, Size: 480},
+				},
+			},
+			{
+				MIMEType:          "application",
+				MIMESubType:       "pdf",
+				Encoding:          "base64",
+				Size:              40 * 1024 * 1024,
+				Disposition:       "attachment",
+				DispositionParams: map[string]string{"filename": "report.pdf"},
+			},
+			{
+				MIMEType:          "text",
+				MIMESubType:       "plain",
+				Size:              64,
+				Disposition:       "attachment",
+				DispositionParams: map[string]string{"filename": "notes.txt"},
+			},
+		},
+	}
+
+	textParts, attachmentParts := planMessageParts(bs)
+
+	if len(textParts) != 2 || textParts[0].PartID != "1.1" || textParts[1].PartID != "1.2" {
+		t.Fatalf("unexpected text parts: %+v", textParts)
+	}
+	if len(attachmentParts) != 2 || attachmentParts[0].PartID != "2" || attachmentParts[1].PartID != "3" {
+		t.Fatalf("unexpected attachment parts: %+v", attachmentParts)
+	}
+
+	attachment := newPartAttachment(attachmentParts[0])
+	if attachment.Filename != "report.pdf" || attachment.ContentType != "application/pdf" {
+		t.Errorf("unexpected attachment info: %+v", attachment)
+	}
+	if attachment.Size != 30*1024*1024 {
+		t.Errorf("expected decoded size estimate, got %d", attachment.Size)
+	}
+	if attachment.Content != nil {
+		t.Error("oversized attachment should not carry content")
+	}
+}
+
+func TestPlanMessagePartsSinglePart(t *testing.T) {
+	bs := &imap.BodyStructure{MIMEType: "text", MIMESubType: "plain", Size: 10}
+
+	textParts, attachmentParts := planMessageParts(bs)
+	if len(textParts) != 1 || textParts[0].PartID != "1" {
+		t.Fatalf("unexpected text parts: %+v", textParts)
+	}
+	if len(attachmentParts) != 0 {
+		t.Fatalf("expected no attachments, got %d", len(attachmentParts))
+	}
+}
+
+func TestPartSectionFetchItem(t *testing.T) {
+	if item := partSection("1.2", 0).FetchItem(); item != "BODY.PEEK[1.2]" {
+		t.Errorf("unexpected fetch item %q", item)
+	}
+	if item := partSection("2", 1024).FetchItem(); item != "BODY.PEEK[2]<0.1024>" {
+		t.Errorf("unexpected partial fetch item %q", item)
+	}
+	if parsePartPath("1.x") != nil {
+		t.Error("expected invalid part ID to be rejected")
+	}
+}
+
+func TestTempFileReaderRemovesFileOnClose(t *testing.T) {
+	file, err := os.CreateTemp(t.TempDir(), "part-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader := &tempFileReader{File: file}
+	if err := reader.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(file.Name()); !os.IsNotExist(err) {
+		t.Errorf("expected temp file to be removed, got %v", err)
+	}
+}
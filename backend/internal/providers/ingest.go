@@ -0,0 +1,179 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"firemail/internal/models"
+)
+
+// IngestProviderName 入站虚拟账户的提供商名称，邮件由入站接口写入，没有对应的IMAP/SMTP服务器
+const IngestProviderName = "ingest"
+
+var (
+	// ErrIngestSourceUnavailable 虚拟账户不保存邮件原文
+	ErrIngestSourceUnavailable = errors.New("email source unavailable: inbound account has no mail server")
+	// ErrIngestSendUnsupported 虚拟账户不能发送邮件
+	ErrIngestSendUnsupported = errors.New("inbound account cannot send email")
+)
+
+// IngestProvider 入站虚拟账户的提供商：状态变更只在本地生效，服务器端操作均为空操作
+type IngestProvider struct {
+	connected bool
+	client    *ingestIMAPClient
+}
+
+// NewIngestProvider 创建入站虚拟账户的提供商
+func NewIngestProvider() EmailProvider {
+	return &IngestProvider{client: &ingestIMAPClient{}}
+}
+
+// GetName 获取提供商名称
+func (p *IngestProvider) GetName() string {
+	return IngestProviderName
+}
+
+// GetDisplayName 获取显示名称
+func (p *IngestProvider) GetDisplayName() string {
+	return "Inbound Webhook"
+}
+
+// GetSupportedAuthMethods 获取支持的认证方式
+func (p *IngestProvider) GetSupportedAuthMethods() []string {
+	return []string{"none"}
+}
+
+// GetProviderInfo 获取提供商信息
+func (p *IngestProvider) GetProviderInfo() map[string]interface{} {
+	return map[string]interface{}{
+		"name":         IngestProviderName,
+		"display_name": p.GetDisplayName(),
+		"auth_methods": p.GetSupportedAuthMethods(),
+		"virtual":      true,
+	}
+}
+
+// Connect 虚拟账户无需连接
+func (p *IngestProvider) Connect(ctx context.Context, account *models.EmailAccount) error {
+	p.connected = true
+	return nil
+}
+
+// Disconnect 断开连接
+func (p *IngestProvider) Disconnect() error {
+	p.connected = false
+	return nil
+}
+
+// IsConnected 检查连接状态
+func (p *IngestProvider) IsConnected() bool {
+	return p.connected
+}
+
+// IsIMAPConnected 检查IMAP连接状态
+func (p *IngestProvider) IsIMAPConnected() bool {
+	return p.connected
+}
+
+// IsSMTPConnected 虚拟账户没有SMTP连接
+func (p *IngestProvider) IsSMTPConnected() bool {
+	return false
+}
+
+// TestConnection 虚拟账户总是可用
+func (p *IngestProvider) TestConnection(ctx context.Context, account *models.EmailAccount) error {
+	return nil
+}
+
+// IMAPClient 获取本地空操作的IMAP客户端
+func (p *IngestProvider) IMAPClient() IMAPClient {
+	return p.client
+}
+
+// SMTPClient 虚拟账户没有SMTP客户端
+func (p *IngestProvider) SMTPClient() SMTPClient {
+	return nil
+}
+
+// OAuth2Client 虚拟账户不使用OAuth2
+func (p *IngestProvider) OAuth2Client() OAuth2Client {
+	return nil
+}
+
+// SendEmail 虚拟账户不能发送邮件
+func (p *IngestProvider) SendEmail(ctx context.Context, account *models.EmailAccount, message *OutgoingMessage) error {
+	return ErrIngestSendUnsupported
+}
+
+// SyncEmails 虚拟账户没有需要同步的邮件
+func (p *IngestProvider) SyncEmails(ctx context.Context, account *models.EmailAccount, folderName string, lastUID uint32) ([]*EmailMessage, error) {
+	return nil, nil
+}
+
+// ingestIMAPClient 虚拟账户的IMAP客户端：标记、移动、删除等操作直接成功，由调用方更新本地数据
+type ingestIMAPClient struct{}
+
+func (c *ingestIMAPClient) Connect(ctx context.Context, config IMAPClientConfig) error { return nil }
+func (c *ingestIMAPClient) Disconnect() error                                          { return nil }
+func (c *ingestIMAPClient) IsConnected() bool                                          { return true }
+
+func (c *ingestIMAPClient) ListFolders(ctx context.Context) ([]*FolderInfo, error) {
+	return nil, nil
+}
+func (c *ingestIMAPClient) SelectFolder(ctx context.Context, folderName string) (*FolderStatus, error) {
+	return &FolderStatus{Name: folderName}, nil
+}
+func (c *ingestIMAPClient) CreateFolder(ctx context.Context, folderName string) error { return nil }
+func (c *ingestIMAPClient) DeleteFolder(ctx context.Context, folderName string) error { return nil }
+func (c *ingestIMAPClient) RenameFolder(ctx context.Context, oldName, newName string) error {
+	return nil
+}
+
+func (c *ingestIMAPClient) FetchEmails(ctx context.Context, criteria *FetchCriteria) ([]*EmailMessage, error) {
+	return nil, nil
+}
+func (c *ingestIMAPClient) FetchEmailByUID(ctx context.Context, uid uint32) (*EmailMessage, error) {
+	return nil, ErrIngestSourceUnavailable
+}
+func (c *ingestIMAPClient) FetchEmailHeaders(ctx context.Context, uids []uint32) ([]*EmailHeader, error) {
+	return nil, nil
+}
+func (c *ingestIMAPClient) FetchRawEmail(ctx context.Context, uid uint32) (io.ReadCloser, error) {
+	return nil, ErrIngestSourceUnavailable
+}
+
+func (c *ingestIMAPClient) MarkAsRead(ctx context.Context, uids []uint32) error   { return nil }
+func (c *ingestIMAPClient) MarkAsUnread(ctx context.Context, uids []uint32) error { return nil }
+func (c *ingestIMAPClient) DeleteEmails(ctx context.Context, uids []uint32) error { return nil }
+func (c *ingestIMAPClient) MoveEmails(ctx context.Context, uids []uint32, targetFolder string) error {
+	return nil
+}
+func (c *ingestIMAPClient) CopyEmails(ctx context.Context, uids []uint32, targetFolder string) error {
+	return nil
+}
+
+// AppendMessage 虚拟账户不保存原文，不能接收从其他账户复制的邮件
+func (c *ingestIMAPClient) AppendMessage(ctx context.Context, folderName string, flags []string, date time.Time, data []byte) error {
+	return ErrIngestSourceUnavailable
+}
+
+func (c *ingestIMAPClient) SearchEmails(ctx context.Context, criteria *SearchCriteria) ([]uint32, error) {
+	return nil, nil
+}
+
+func (c *ingestIMAPClient) GetFolderStatus(ctx context.Context, folderName string) (*FolderStatus, error) {
+	return &FolderStatus{Name: folderName}, nil
+}
+func (c *ingestIMAPClient) GetNewEmails(ctx context.Context, folderName string, lastUID uint32) ([]*EmailMessage, error) {
+	return nil, nil
+}
+func (c *ingestIMAPClient) GetEmailsInUIDRange(ctx context.Context, folderName string, startUID, endUID uint32) ([]*EmailMessage, error) {
+	return nil, nil
+}
+
+// GetAttachment 入站邮件的附件在写入时已保存到本地
+func (c *ingestIMAPClient) GetAttachment(ctx context.Context, folderName string, uid uint32, partID string) (io.ReadCloser, error) {
+	return nil, ErrIngestSourceUnavailable
+}
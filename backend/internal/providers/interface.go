@@ -1,8 +1,11 @@
 package providers
 
 import (
+	"bytes"
 	"context"
 	"io"
+	"net"
+	"os"
 	"time"
 
 	"firemail/internal/models"
@@ -56,6 +59,7 @@ type IMAPClient interface {
 	FetchEmails(ctx context.Context, criteria *FetchCriteria) ([]*EmailMessage, error)
 	FetchEmailByUID(ctx context.Context, uid uint32) (*EmailMessage, error)
 	FetchEmailHeaders(ctx context.Context, uids []uint32) ([]*EmailHeader, error)
+	FetchRawEmail(ctx context.Context, uid uint32) (io.ReadCloser, error)
 
 	// 邮件状态操作
 	MarkAsRead(ctx context.Context, uids []uint32) error
@@ -63,6 +67,7 @@ type IMAPClient interface {
 	DeleteEmails(ctx context.Context, uids []uint32) error
 	MoveEmails(ctx context.Context, uids []uint32, targetFolder string) error
 	CopyEmails(ctx context.Context, uids []uint32, targetFolder string) error
+	AppendMessage(ctx context.Context, folderName string, flags []string, date time.Time, data []byte) error
 
 	// 搜索操作
 	SearchEmails(ctx context.Context, criteria *SearchCriteria) ([]uint32, error)
@@ -76,6 +81,22 @@ type IMAPClient interface {
 	GetAttachment(ctx context.Context, folderName string, uid uint32, partID string) (io.ReadCloser, error)
 }
 
+// AnnotationClient 支持IMAP ANNOTATE扩展（RFC 5257）的客户端，通过类型断言使用
+type AnnotationClient interface {
+	// SupportsAnnotations 服务器是否声明支持ANNOTATE扩展
+	SupportsAnnotations() bool
+	// SetAnnotation 在当前选中文件夹中设置邮件的私有注释，value为空时删除
+	SetAnnotation(ctx context.Context, uid uint32, entry, value string) error
+}
+
+// FlagClient 支持写入任意标志和关键字的客户端，通过类型断言使用
+type FlagClient interface {
+	// AllowsKeywords 当前选中文件夹是否允许保存自定义关键字（PERMANENTFLAGS 包含 \*）
+	AllowsKeywords() bool
+	// StoreFlags 在当前选中文件夹中为邮件添加或移除标志
+	StoreFlags(ctx context.Context, uids []uint32, flags []string, add bool) error
+}
+
 // SMTPClient SMTP客户端接口
 type SMTPClient interface {
 	// 连接管理
@@ -111,6 +132,10 @@ type IMAPClientConfig struct {
 	Password    string
 	OAuth2Token *OAuth2Token
 	IMAPIDInfo  map[string]string // IMAP ID信息，用于163等邮箱的可信部分
+	MaxPartSize int64             // 单个MIME部分内联下载的大小上限（字节），0表示使用默认值
+	Bandwidth   *ByteBudget       // 连接的读写带宽预算，nil表示不限制
+	TLS         *TLSOptions       // 账户的TLS设置，nil表示使用默认设置
+	Resolver    *net.Resolver     // 解析服务器地址使用的DNS解析器，nil表示使用系统DNS
 }
 
 // SMTPClientConfig SMTP客户端配置
@@ -121,6 +146,9 @@ type SMTPClientConfig struct {
 	Username    string
 	Password    string
 	OAuth2Token *OAuth2Token
+	EHLOName    string        // EHLO问候使用的主机名，为空时使用net/smtp的默认值
+	TLS         *TLSOptions   // 账户的TLS设置，nil表示使用默认设置
+	Resolver    *net.Resolver // 解析服务器地址使用的DNS解析器，nil表示使用系统DNS
 }
 
 // OAuth2Token OAuth2令牌
@@ -187,6 +215,25 @@ func (e *EmailMessage) GetLabels() []string {
 	return e.Labels
 }
 
+// ReleaseContent 删除解析时溢出到临时文件的附件内容
+func (e *EmailMessage) ReleaseContent() {
+	for _, attachment := range e.Attachments {
+		if attachment.ContentPath != "" {
+			os.Remove(attachment.ContentPath)
+			attachment.ContentPath = ""
+		}
+	}
+}
+
+// ReleaseEmailContents 批量释放邮件的溢出文件
+func ReleaseEmailContents(emails []*EmailMessage) {
+	for _, email := range emails {
+		if email != nil {
+			email.ReleaseContent()
+		}
+	}
+}
+
 // EmailHeader 邮件头信息
 type EmailHeader struct {
 	UID       uint32
@@ -210,21 +257,36 @@ type AttachmentInfo struct {
 	Disposition string
 	Encoding    string
 	Content     []byte // 附件内容（可选，用于同步时保存）
+	ContentPath string // 内容较大时溢出到的临时文件（已解码），由ReleaseContent删除
+}
+
+// HasContent 是否携带了附件内容
+func (a *AttachmentInfo) HasContent() bool {
+	return len(a.Content) > 0 || a.ContentPath != ""
+}
+
+// OpenContent 打开附件内容，内容可能位于内存或溢出文件中
+func (a *AttachmentInfo) OpenContent() (io.ReadCloser, error) {
+	if a.ContentPath != "" {
+		return os.Open(a.ContentPath)
+	}
+	return io.NopCloser(bytes.NewReader(a.Content)), nil
 }
 
 // OutgoingMessage 发送邮件消息
 type OutgoingMessage struct {
-	From        *models.EmailAddress
-	To          []*models.EmailAddress
-	CC          []*models.EmailAddress
-	BCC         []*models.EmailAddress
-	ReplyTo     *models.EmailAddress
-	Subject     string
-	TextBody    string
-	HTMLBody    string
-	Attachments []*OutgoingAttachment
-	Headers     map[string]string
-	Priority    string
+	From         *models.EmailAddress
+	EnvelopeFrom string // SMTP信封发件人，为空时使用From地址
+	To           []*models.EmailAddress
+	CC           []*models.EmailAddress
+	BCC          []*models.EmailAddress
+	ReplyTo      *models.EmailAddress
+	Subject      string
+	TextBody     string
+	HTMLBody     string
+	Attachments  []*OutgoingAttachment
+	Headers      map[string]string
+	Priority     string
 }
 
 // OutgoingAttachment 发送附件
@@ -266,6 +328,7 @@ type SearchCriteria struct {
 	Draft      *bool
 	Answered   *bool
 	Size       *SizeCondition
+	MessageID  string // 按Message-ID头精确查找
 }
 
 // SizeCondition 大小条件
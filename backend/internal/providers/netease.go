@@ -2,6 +2,7 @@ package providers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -37,6 +38,11 @@ func NewNetEaseProvider(config *config.EmailProviderConfig) EmailProvider {
 
 // Connect 连接到网易邮箱服务器
 func (p *NetEaseProvider) Connect(ctx context.Context, account *models.EmailAccount) error {
+	return ClassifyProviderError(p.GetName(), p.connect(ctx, account))
+}
+
+// connect 执行实际的连接流程
+func (p *NetEaseProvider) connect(ctx context.Context, account *models.EmailAccount) error {
 	// 确保使用正确的服务器配置
 	p.ensureNetEaseConfig(account)
 
@@ -54,25 +60,6 @@ func (p *NetEaseProvider) Connect(ctx context.Context, account *models.EmailAcco
 	return p.connectWithRetryAndIMAPID(ctx, account)
 }
 
-// is163Email 检查是否是163邮箱
-func (p *NetEaseProvider) is163Email(email string) bool {
-	return strings.HasSuffix(strings.ToLower(email), "@163.com") ||
-		strings.HasSuffix(strings.ToLower(email), "@vip.163.com")
-}
-
-// get163IMAPIDInfo 获取163邮箱的IMAP ID信息
-// 根据163邮箱文档要求，需要提供客户端身份信息
-func (p *NetEaseProvider) get163IMAPIDInfo() map[string]string {
-	return map[string]string{
-		"name":          "FireMail",
-		"version":       "1.0.0",
-		"vendor":        "FireMail Team",
-		"support-email": "support@firemail.com",
-		"os":            "Linux",
-		"os-version":    "Ubuntu 20.04",
-	}
-}
-
 // ensureNetEaseConfig 确保网易邮箱配置正确
 func (p *NetEaseProvider) ensureNetEaseConfig(account *models.EmailAccount) {
 	domain := extractDomainFromEmail(account.Email)
@@ -193,19 +180,32 @@ func (p *NetEaseProvider) connectWithIMAPID(ctx context.Context, account *models
 		return nil
 	}
 
+	if err := p.acquireConnectionSlot(ctx, account); err != nil {
+		return err
+	}
+	defer func() {
+		if !p.connected {
+			p.releaseConnectionSlot()
+		}
+	}()
+
 	// 连接IMAP
 	if p.imapClient != nil {
 		imapConfig := IMAPClientConfig{
-			Host:     account.IMAPHost,
-			Port:     account.IMAPPort,
-			Security: account.IMAPSecurity,
-			Username: account.Username,
-			Password: account.Password,
+			Host:        account.IMAPHost,
+			Port:        account.IMAPPort,
+			Security:    account.IMAPSecurity,
+			Username:    account.Username,
+			Password:    account.Password,
+			MaxPartSize: account.MaxPartSize,
+			Bandwidth:   GetGlobalRateLimiter().SyncBandwidth(p.config.Name, account.ID),
+			TLS:         TLSOptionsForAccount(account),
+			Resolver:    ResolverForAccount(account),
 		}
 
-		// 为163邮箱添加IMAP ID信息（可信部分）
-		if p.is163Email(account.Email) {
-			imapConfig.IMAPIDInfo = p.get163IMAPIDInfo()
+		// 网易邮箱要求发送IMAP ID信息（可信部分），163、126和yeah.net均适用
+		if p.config.Quirks != nil && p.config.Quirks.RequiresIMAPID {
+			imapConfig.IMAPIDInfo = clientIMAPIDInfo()
 		}
 
 		if err := p.imapClient.Connect(ctx, imapConfig); err != nil {
@@ -221,6 +221,9 @@ func (p *NetEaseProvider) connectWithIMAPID(ctx context.Context, account *models
 			Security: account.SMTPSecurity,
 			Username: account.Username,
 			Password: account.Password,
+			EHLOName: IdentityForAccount(account).EHLOName,
+			TLS:      TLSOptionsForAccount(account),
+			Resolver: ResolverForAccount(account),
 		}
 		if err := p.smtpClient.Connect(ctx, smtpConfig); err != nil {
 			return fmt.Errorf("failed to connect SMTP: %w", err)
@@ -309,6 +312,11 @@ func (p *NetEaseProvider) isNonRetryableError(err error) bool {
 		return false
 	}
 
+	// 已分类的认证、配额、限速错误不在连接重试中处理
+	if IsPermanentError(err) || errors.Is(err, ErrRateLimited) {
+		return true
+	}
+
 	errStr := err.Error()
 
 	// 认证错误、配置错误等不需要重试
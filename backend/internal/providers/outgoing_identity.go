@@ -0,0 +1,103 @@
+package providers
+
+import (
+	"fmt"
+	"net/textproto"
+	"os"
+	"strings"
+	"sync"
+
+	"firemail/internal/config"
+	"firemail/internal/models"
+)
+
+var (
+	outgoingDefaults      = config.OutgoingConfig{Mailer: "FireMail"}
+	outgoingDefaultsMutex sync.RWMutex
+)
+
+// ConfigureOutgoing 使用应用配置更新外发邮件的默认标识
+func ConfigureOutgoing(cfg config.OutgoingConfig) {
+	outgoingDefaultsMutex.Lock()
+	defer outgoingDefaultsMutex.Unlock()
+	outgoingDefaults = cfg
+}
+
+// OutgoingIdentity 外发邮件的标识：SMTP问候名、Message-ID域名和客户端名称
+type OutgoingIdentity struct {
+	EHLOName        string
+	MessageIDDomain string
+	Mailer          string
+}
+
+// IdentityForAccount 账户外发邮件使用的标识，账户未设置的项使用实例配置
+func IdentityForAccount(account *models.EmailAccount) OutgoingIdentity {
+	outgoingDefaultsMutex.RLock()
+	identity := OutgoingIdentity{
+		EHLOName:        outgoingDefaults.EHLOName,
+		MessageIDDomain: outgoingDefaults.MessageIDDomain,
+		Mailer:          outgoingDefaults.Mailer,
+	}
+	outgoingDefaultsMutex.RUnlock()
+
+	if account != nil {
+		if account.EHLOName != "" {
+			identity.EHLOName = account.EHLOName
+		}
+		if account.MessageIDDomain != "" {
+			identity.MessageIDDomain = account.MessageIDDomain
+		}
+		if account.Mailer != "" {
+			identity.Mailer = account.Mailer
+		}
+	}
+
+	// 未配置时使用本机的完整主机名，net/smtp 默认的 localhost 容易被收件服务器扣分
+	if identity.EHLOName == "" {
+		if hostname, err := os.Hostname(); err == nil && strings.Contains(hostname, ".") && config.ValidHostname(hostname) {
+			identity.EHLOName = hostname
+		}
+	}
+	return identity
+}
+
+// MessageID 生成 <localPart@域名> 格式的Message-ID，未配置域名时使用发件地址的域名
+func (i OutgoingIdentity) MessageID(localPart, from string) string {
+	domain := i.MessageIDDomain
+	if domain == "" {
+		if at := strings.LastIndex(from, "@"); at >= 0 && at < len(from)-1 {
+			domain = strings.ToLower(from[at+1:])
+		}
+	}
+	if domain == "" {
+		domain = "firemail.localhost"
+	}
+	return fmt.Sprintf("<%s@%s>", localPart, domain)
+}
+
+// Apply 为邮件补充Message-ID和客户端名称头，已有的同名头保持不变
+func (i OutgoingIdentity) Apply(message *OutgoingMessage, localPart string) {
+	headers := make(map[string]string, len(message.Headers)+3)
+	present := make(map[string]bool, len(message.Headers))
+	for key, value := range message.Headers {
+		headers[key] = value
+		present[textproto.CanonicalMIMEHeaderKey(key)] = true
+	}
+
+	if !present["Message-Id"] {
+		from := ""
+		if message.From != nil {
+			from = message.From.Address
+		}
+		headers["Message-ID"] = i.MessageID(localPart, from)
+	}
+	if i.Mailer != "" {
+		if !present["X-Mailer"] {
+			headers["X-Mailer"] = i.Mailer
+		}
+		if !present["User-Agent"] {
+			headers["User-Agent"] = i.Mailer
+		}
+	}
+	message.Headers = headers
+}
@@ -0,0 +1,60 @@
+package providers
+
+import (
+	"context"
+	"testing"
+
+	"firemail/internal/config"
+	"firemail/internal/models"
+)
+
+func TestOutgoingIdentityAccountOverridesInstance(t *testing.T) {
+	ConfigureOutgoing(config.OutgoingConfig{EHLOName: "mail.example.net", MessageIDDomain: "example.net", Mailer: "FireMail"})
+	t.Cleanup(func() { ConfigureOutgoing(config.OutgoingConfig{Mailer: "FireMail"}) })
+
+	identity := IdentityForAccount(&models.EmailAccount{MessageIDDomain: "corp.example.com", Mailer: "Corp Mail"})
+	if identity.EHLOName != "mail.example.net" || identity.MessageIDDomain != "corp.example.com" || identity.Mailer != "Corp Mail" {
+		t.Fatalf("unexpected identity: %+v", identity)
+	}
+
+	message := &OutgoingMessage{
+		From:    &models.EmailAddress{Address: "me@example.com"},
+		Headers: map[string]string{"x-mailer": "Custom"},
+	}
+	identity.Apply(message, "abc123")
+	if message.Headers["Message-ID"] != "<abc123@corp.example.com>" {
+		t.Fatalf("unexpected Message-ID: %q", message.Headers["Message-ID"])
+	}
+	if _, ok := message.Headers["X-Mailer"]; ok {
+		t.Fatalf("existing X-Mailer header must be kept: %v", message.Headers)
+	}
+	if message.Headers["User-Agent"] != "Corp Mail" {
+		t.Fatalf("unexpected User-Agent: %q", message.Headers["User-Agent"])
+	}
+
+	// 未配置域名时使用发件地址的域名
+	if id := (OutgoingIdentity{}).MessageID("xyz", "Me@Example.ORG"); id != "<xyz@example.org>" {
+		t.Fatalf("unexpected fallback Message-ID: %s", id)
+	}
+}
+
+func TestSMTPClientUsesConfiguredEHLOName(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	cfg := server.config()
+	cfg.EHLOName = "mail.example.net"
+
+	client := NewStandardSMTPClient()
+	if err := client.Connect(context.Background(), cfg); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer client.Disconnect()
+	if err := client.SendRawEmail(context.Background(), "me@example.com", []string{"a@example.org"}, []byte("Subject: hi\r\n\r\nhi\r\n")); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.ehlo != "mail.example.net" {
+		t.Fatalf("expected EHLO mail.example.net, got %q", server.ehlo)
+	}
+}
@@ -2,6 +2,7 @@ package providers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -38,6 +39,11 @@ func NewOutlookProvider(config *config.EmailProviderConfig) EmailProvider {
 
 // Connect 连接到Outlook服务器
 func (p *OutlookProvider) Connect(ctx context.Context, account *models.EmailAccount) error {
+	return ClassifyProviderError(p.GetName(), p.connect(ctx, account))
+}
+
+// connect 执行实际的连接流程
+func (p *OutlookProvider) connect(ctx context.Context, account *models.EmailAccount) error {
 	// 设置OAuth2客户端
 	if account.AuthMethod == "oauth2" && p.oauth2Client == nil {
 		// 从账户的OAuth2Token中获取client_id
@@ -190,6 +196,11 @@ func (p *OutlookProvider) isNonRetryableError(err error) bool {
 		return false
 	}
 
+	// 已分类的认证、配额、限速错误不在连接重试中处理
+	if IsPermanentError(err) || errors.Is(err, ErrRateLimited) {
+		return true
+	}
+
 	errStr := err.Error()
 
 	// 认证错误、配置错误等不需要重试
@@ -460,7 +471,9 @@ func (p *OutlookProvider) SendEmail(ctx context.Context, account *models.EmailAc
 	}
 
 	// 添加Exchange相关头信息
-	message.Headers["X-Mailer"] = "FireMail"
+	if mailer := IdentityForAccount(account).Mailer; mailer != "" {
+		message.Headers["X-Mailer"] = mailer
+	}
 
 	// 如果设置了优先级，添加相应的头信息
 	if message.Priority == "high" {
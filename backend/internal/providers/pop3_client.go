@@ -0,0 +1,172 @@
+package providers
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// pop3ConnectTimeout POP3连接超时
+	pop3ConnectTimeout = 30 * time.Second
+	// pop3CommandTimeout 单条命令（包括下载一封邮件）的超时
+	pop3CommandTimeout = 2 * time.Minute
+)
+
+// POP3ClientConfig POP3客户端配置
+type POP3ClientConfig struct {
+	Host     string
+	Port     int
+	Security string // SSL, TLS, STARTTLS, NONE
+	Username string
+	Password string
+}
+
+// POP3Message 邮箱中的一封邮件：会话内的编号和服务器分配的唯一标识（UIDL）
+type POP3Message struct {
+	Number int
+	UID    string
+}
+
+// POP3Client 最小的POP3客户端（RFC 1939），只支持USER/PASS登录、UIDL、RETR和DELE
+type POP3Client struct {
+	conn net.Conn
+	text *textproto.Conn
+}
+
+// DialPOP3 连接POP3服务器并登录
+func DialPOP3(ctx context.Context, config POP3ClientConfig) (*POP3Client, error) {
+	addr := net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+	dialer := &net.Dialer{Timeout: pop3ConnectTimeout}
+
+	var conn net.Conn
+	var err error
+	switch strings.ToUpper(config.Security) {
+	case "SSL", "TLS":
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: config.Host}}).DialContext(ctx, "tcp", addr)
+	default:
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to POP3 server: %w", err)
+	}
+
+	c := &POP3Client{conn: conn, text: textproto.NewConn(conn)}
+	if _, err := c.readResponse(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("POP3 greeting failed: %w", err)
+	}
+
+	if strings.EqualFold(config.Security, "STARTTLS") {
+		if _, err := c.cmd("STLS"); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("POP3 STLS failed: %w", err)
+		}
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: config.Host})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("POP3 TLS handshake failed: %w", err)
+		}
+		c.conn = tlsConn
+		c.text = textproto.NewConn(tlsConn)
+	}
+
+	if _, err := c.cmd("USER %s", config.Username); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("POP3 authentication failed: %w", err)
+	}
+	if _, err := c.cmd("PASS %s", config.Password); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("POP3 authentication failed: %w", err)
+	}
+	return c, nil
+}
+
+// readResponse 读取单行响应，-ERR 作为错误返回
+func (c *POP3Client) readResponse() (string, error) {
+	c.conn.SetDeadline(time.Now().Add(pop3CommandTimeout))
+	line, err := c.text.ReadLine()
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case strings.HasPrefix(line, "+OK"):
+		return strings.TrimSpace(strings.TrimPrefix(line, "+OK")), nil
+	case strings.HasPrefix(line, "-ERR"):
+		return "", fmt.Errorf("%s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+	default:
+		return "", fmt.Errorf("unexpected POP3 response: %s", line)
+	}
+}
+
+// cmd 发送命令并读取单行响应
+func (c *POP3Client) cmd(format string, args ...interface{}) (string, error) {
+	c.conn.SetDeadline(time.Now().Add(pop3CommandTimeout))
+	if err := c.text.PrintfLine(format, args...); err != nil {
+		return "", err
+	}
+	return c.readResponse()
+}
+
+// UIDL 列出邮箱中所有邮件的编号和唯一标识
+func (c *POP3Client) UIDL() ([]POP3Message, error) {
+	if _, err := c.cmd("UIDL"); err != nil {
+		return nil, fmt.Errorf("POP3 UIDL failed: %w", err)
+	}
+	lines, err := c.text.ReadDotLines()
+	if err != nil {
+		return nil, fmt.Errorf("POP3 UIDL failed: %w", err)
+	}
+
+	messages := make([]POP3Message, 0, len(lines))
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		number, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		messages = append(messages, POP3Message{Number: number, UID: fields[1]})
+	}
+	return messages, nil
+}
+
+// Retrieve 下载邮件原文
+func (c *POP3Client) Retrieve(number int) ([]byte, error) {
+	if _, err := c.cmd("RETR %d", number); err != nil {
+		return nil, fmt.Errorf("POP3 RETR %d failed: %w", number, err)
+	}
+	data, err := c.text.ReadDotBytes()
+	if err != nil {
+		return nil, fmt.Errorf("POP3 RETR %d failed: %w", number, err)
+	}
+	// ReadDotBytes 把行尾统一为LF，还原为邮件原文使用的CRLF
+	return []byte(strings.ReplaceAll(string(data), "\n", "\r\n")), nil
+}
+
+// Delete 标记删除邮件，QUIT后服务器才真正删除
+func (c *POP3Client) Delete(number int) error {
+	if _, err := c.cmd("DELE %d", number); err != nil {
+		return fmt.Errorf("POP3 DELE %d failed: %w", number, err)
+	}
+	return nil
+}
+
+// Quit 结束会话，服务器在此时删除标记的邮件
+func (c *POP3Client) Quit() error {
+	_, err := c.cmd("QUIT")
+	c.conn.Close()
+	return err
+}
+
+// Close 不提交删除直接断开连接
+func (c *POP3Client) Close() error {
+	return c.conn.Close()
+}
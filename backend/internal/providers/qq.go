@@ -2,7 +2,9 @@ package providers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -40,6 +42,11 @@ func NewQQProvider(config *config.EmailProviderConfig) EmailProvider {
 
 // Connect 连接到QQ邮箱服务器
 func (p *QQProvider) Connect(ctx context.Context, account *models.EmailAccount) error {
+	return ClassifyProviderError(p.GetName(), p.connect(ctx, account))
+}
+
+// connect 执行实际的连接流程
+func (p *QQProvider) connect(ctx context.Context, account *models.EmailAccount) error {
 	// 确保使用正确的服务器配置
 	p.ensureQQConfig(account)
 
@@ -50,7 +57,7 @@ func (p *QQProvider) Connect(ctx context.Context, account *models.EmailAccount)
 
 	// 验证是否使用了授权码
 	if err := p.validateAuthCode(account); err != nil {
-		return fmt.Errorf("QQ mail authentication failed: %w", err)
+		return p.authCodeSetupError("QQ_AUTH_CODE_INVALID", "QQ mail requires a 16-character authorization code instead of the QQ password", err)
 	}
 
 	// 使用重试机制连接
@@ -68,6 +75,11 @@ func (p *QQProvider) connectWithRetry(ctx context.Context, account *models.Email
 			return nil
 		}
 
+		// 未开启服务或未使用授权码，重试无效
+		if setupErr := p.loginSetupError(err); setupErr != nil {
+			return setupErr
+		}
+
 		// 处理QQ邮箱特定错误
 		qqErr := p.HandleQQError(err)
 
@@ -100,6 +112,11 @@ func (p *QQProvider) isNonRetryableError(err error) bool {
 		return false
 	}
 
+	// 已分类的认证、配额、限速错误不在连接重试中处理
+	if IsPermanentError(err) || errors.Is(err, ErrRateLimited) {
+		return true
+	}
+
 	errStr := err.Error()
 
 	// 认证错误、配置错误等不需要重试
@@ -159,6 +176,41 @@ func (p *QQProvider) validateAuthCode(account *models.EmailAccount) error {
 	return nil
 }
 
+// qqAuthCodeSteps QQ邮箱开启IMAP服务并生成授权码的步骤
+var qqAuthCodeSteps = []string{
+	"Sign in to QQ Mail on the web at mail.qq.com",
+	"Open Settings > Account",
+	"Find the POP3/IMAP/SMTP/Exchange/CardDAV/CalDAV service section and turn on the IMAP/SMTP service",
+	"Click \"Generate authorization code\" and verify by SMS as prompted",
+	"Enter the 16-character authorization code as the password instead of the QQ password",
+}
+
+// qqLoginFailureKeywords QQ邮箱服务器拒绝登录时的提示，多为未开启IMAP服务或未使用授权码
+var qqLoginFailureKeywords = []string{"login fail", "authorization code", "authorized code", "service is not open"}
+
+// authCodeSetupError 创建附带授权码设置步骤的设置错误
+func (p *QQProvider) authCodeSetupError(code, message string, cause error) error {
+	helpURL := p.config.HelpURLs["auth_code"]
+	if helpURL == "" {
+		helpURL = "https://service.mail.qq.com/cgi-bin/help?subtype=1&&id=28&&no=1001256"
+	}
+	return NewSetupError(p.GetName(), code, message, helpURL, qqAuthCodeSteps, cause)
+}
+
+// loginSetupError 服务器拒绝登录且提示需要开启服务或使用授权码时，返回附带设置步骤的错误，否则返回nil
+func (p *QQProvider) loginSetupError(err error) error {
+	if err == nil {
+		return nil
+	}
+	lower := strings.ToLower(err.Error())
+	for _, keyword := range qqLoginFailureKeywords {
+		if strings.Contains(lower, keyword) {
+			return p.authCodeSetupError("QQ_LOGIN_FAILED", "QQ mail refused the login: make sure the IMAP/SMTP service is on and use an authorization code", err)
+		}
+	}
+	return nil
+}
+
 // HandleQQError 处理QQ邮箱特定错误
 func (p *QQProvider) HandleQQError(err error) error {
 	if err == nil {
@@ -193,11 +245,15 @@ func (p *QQProvider) TestConnection(ctx context.Context, account *models.EmailAc
 
 	// 验证授权码
 	if err := p.validateAuthCode(account); err != nil {
-		return err
+		return p.authCodeSetupError("QQ_AUTH_CODE_INVALID", "QQ mail requires a 16-character authorization code instead of the QQ password", err)
 	}
 
 	// 调用基类测试方法
-	return p.BaseProvider.TestConnection(ctx, account)
+	err := p.BaseProvider.TestConnection(ctx, account)
+	if setupErr := p.loginSetupError(err); setupErr != nil {
+		return setupErr
+	}
+	return err
 }
 
 // GetSpecialFolders 获取QQ邮箱特殊文件夹映射
@@ -247,6 +303,9 @@ func (p *QQProvider) SyncEmails(ctx context.Context, account *models.EmailAccoun
 		return nil, fmt.Errorf("failed to get new emails: %w", err)
 	}
 
+	// QQ邮箱的UID搜索不严格遵守范围，可能返回已同步的UID或重复、乱序的结果
+	emails = filterQQNewEmails(emails, lastUID)
+
 	// QQ邮箱特殊处理：处理编码
 	for _, email := range emails {
 		p.processQQEncoding(email)
@@ -255,6 +314,21 @@ func (p *QQProvider) SyncEmails(ctx context.Context, account *models.EmailAccoun
 	return emails, nil
 }
 
+// filterQQNewEmails 只保留UID大于lastUID的邮件，去掉重复的UID并按UID升序排列
+func filterQQNewEmails(emails []*EmailMessage, lastUID uint32) []*EmailMessage {
+	seen := make(map[uint32]bool, len(emails))
+	filtered := emails[:0]
+	for _, email := range emails {
+		if email == nil || email.UID <= lastUID || seen[email.UID] {
+			continue
+		}
+		seen[email.UID] = true
+		filtered = append(filtered, email)
+	}
+	sort.Slice(filtered, func(i, j int) bool { return filtered[i].UID < filtered[j].UID })
+	return filtered
+}
+
 // processQQEncoding 处理QQ邮箱编码
 func (p *QQProvider) processQQEncoding(email *EmailMessage) {
 	// 处理邮件主题编码
@@ -2,6 +2,7 @@ package providers
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -444,3 +445,51 @@ func TestQQProvider_ConnectWithRetry(t *testing.T) {
 		}
 	}
 }
+
+func TestQQProvider_SetupErrors(t *testing.T) {
+	provider := newQQProviderImpl(config.GetProviderByName("qq"))
+
+	account := &models.EmailAccount{
+		Email:      "test@qq.com",
+		Password:   "qq-password",
+		AuthMethod: "password",
+	}
+	err := provider.Connect(context.Background(), account)
+	if !errors.Is(err, ErrAuth) {
+		t.Fatalf("Expected authentication error, got %v", err)
+	}
+	guide := SetupGuideOf(err)
+	if guide == nil || guide.Code != "QQ_AUTH_CODE_INVALID" || len(guide.Steps) != len(qqAuthCodeSteps) || guide.HelpURL == "" {
+		t.Fatalf("Expected authorization code setup guide, got %+v", guide)
+	}
+
+	loginErr := errors.New("failed to connect IMAP: Login fail. Account is abnormal, service is not open, password is incorrect")
+	if guide := SetupGuideOf(provider.loginSetupError(loginErr)); guide == nil || guide.Code != "QQ_LOGIN_FAILED" {
+		t.Errorf("Expected login setup guide, got %+v", guide)
+	}
+	if provider.loginSetupError(errors.New("dial tcp: i/o timeout")) != nil {
+		t.Error("Network errors should not be reported as setup errors")
+	}
+	if SetupGuideOf(errors.New("plain error")) != nil {
+		t.Error("Plain errors should not carry a setup guide")
+	}
+}
+
+func TestFilterQQNewEmails(t *testing.T) {
+	emails := []*EmailMessage{{UID: 12}, {UID: 9}, {UID: 11}, {UID: 12}, {UID: 10}}
+
+	filtered := filterQQNewEmails(emails, 10)
+	if len(filtered) != 2 || filtered[0].UID != 11 || filtered[1].UID != 12 {
+		t.Errorf("Expected UIDs [11 12], got %v", filtered)
+	}
+}
+
+func TestQQFolderQuirks(t *testing.T) {
+	quirks := config.GetProviderByName("qq").Quirks
+	if quirks == nil || !quirks.RequiresIMAPID {
+		t.Fatal("Expected QQ quirks to require IMAP ID")
+	}
+	if folderType := quirks.FolderType("垃圾箱"); folderType != "spam" {
+		t.Errorf("Expected 垃圾箱 to be spam, got %q", folderType)
+	}
+}
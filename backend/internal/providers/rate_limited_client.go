@@ -0,0 +1,233 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// rateLimitedIMAPClient 为批量拉取操作附加节奏控制、并将错误按类别分类的IMAP客户端
+type rateLimitedIMAPClient struct {
+	IMAPClient
+	limiter   *RateLimiter
+	provider  string
+	accountID uint
+}
+
+func newRateLimitedIMAPClient(client IMAPClient, limiter *RateLimiter, provider string, accountID uint) *rateLimitedIMAPClient {
+	return &rateLimitedIMAPClient{
+		IMAPClient: client,
+		limiter:    limiter,
+		provider:   provider,
+		accountID:  accountID,
+	}
+}
+
+// FetchEmails 获取邮件
+func (c *rateLimitedIMAPClient) FetchEmails(ctx context.Context, criteria *FetchCriteria) ([]*EmailMessage, error) {
+	if err := c.limiter.PaceFetch(ctx, c.provider, c.accountID); err != nil {
+		return nil, err
+	}
+	emails, err := c.IMAPClient.FetchEmails(ctx, criteria)
+	return emails, c.classifyError(err)
+}
+
+// FetchEmailHeaders 获取邮件头
+func (c *rateLimitedIMAPClient) FetchEmailHeaders(ctx context.Context, uids []uint32) ([]*EmailHeader, error) {
+	if err := c.limiter.PaceFetch(ctx, c.provider, c.accountID); err != nil {
+		return nil, err
+	}
+	headers, err := c.IMAPClient.FetchEmailHeaders(ctx, uids)
+	return headers, c.classifyError(err)
+}
+
+// FetchRawEmail 获取邮件原始内容
+func (c *rateLimitedIMAPClient) FetchRawEmail(ctx context.Context, uid uint32) (io.ReadCloser, error) {
+	if err := c.limiter.PaceFetch(ctx, c.provider, c.accountID); err != nil {
+		return nil, err
+	}
+	reader, err := c.IMAPClient.FetchRawEmail(ctx, uid)
+	return reader, c.classifyError(err)
+}
+
+// GetNewEmails 获取新邮件
+func (c *rateLimitedIMAPClient) GetNewEmails(ctx context.Context, folderName string, lastUID uint32) ([]*EmailMessage, error) {
+	if err := c.limiter.PaceFetch(ctx, c.provider, c.accountID); err != nil {
+		return nil, err
+	}
+	emails, err := c.IMAPClient.GetNewEmails(ctx, folderName, lastUID)
+	return emails, c.classifyError(err)
+}
+
+// GetEmailsInUIDRange 获取UID范围内的邮件
+func (c *rateLimitedIMAPClient) GetEmailsInUIDRange(ctx context.Context, folderName string, startUID, endUID uint32) ([]*EmailMessage, error) {
+	if err := c.limiter.PaceFetch(ctx, c.provider, c.accountID); err != nil {
+		return nil, err
+	}
+	emails, err := c.IMAPClient.GetEmailsInUIDRange(ctx, folderName, startUID, endUID)
+	return emails, c.classifyError(err)
+}
+
+// ListFolders 获取文件夹列表
+func (c *rateLimitedIMAPClient) ListFolders(ctx context.Context) ([]*FolderInfo, error) {
+	folders, err := c.IMAPClient.ListFolders(ctx)
+	return folders, c.classifyError(err)
+}
+
+// SelectFolder 选择文件夹
+func (c *rateLimitedIMAPClient) SelectFolder(ctx context.Context, folderName string) (*FolderStatus, error) {
+	status, err := c.IMAPClient.SelectFolder(ctx, folderName)
+	return status, c.classifyError(err)
+}
+
+// CreateFolder 创建文件夹
+func (c *rateLimitedIMAPClient) CreateFolder(ctx context.Context, folderName string) error {
+	return c.classifyError(c.IMAPClient.CreateFolder(ctx, folderName))
+}
+
+// DeleteFolder 删除文件夹
+func (c *rateLimitedIMAPClient) DeleteFolder(ctx context.Context, folderName string) error {
+	return c.classifyError(c.IMAPClient.DeleteFolder(ctx, folderName))
+}
+
+// RenameFolder 重命名文件夹
+func (c *rateLimitedIMAPClient) RenameFolder(ctx context.Context, oldName, newName string) error {
+	return c.classifyError(c.IMAPClient.RenameFolder(ctx, oldName, newName))
+}
+
+// FetchEmailByUID 根据UID获取邮件
+func (c *rateLimitedIMAPClient) FetchEmailByUID(ctx context.Context, uid uint32) (*EmailMessage, error) {
+	email, err := c.IMAPClient.FetchEmailByUID(ctx, uid)
+	return email, c.classifyError(err)
+}
+
+// MarkAsRead 标记为已读
+func (c *rateLimitedIMAPClient) MarkAsRead(ctx context.Context, uids []uint32) error {
+	return c.classifyError(c.IMAPClient.MarkAsRead(ctx, uids))
+}
+
+// MarkAsUnread 标记为未读
+func (c *rateLimitedIMAPClient) MarkAsUnread(ctx context.Context, uids []uint32) error {
+	return c.classifyError(c.IMAPClient.MarkAsUnread(ctx, uids))
+}
+
+// DeleteEmails 删除邮件
+func (c *rateLimitedIMAPClient) DeleteEmails(ctx context.Context, uids []uint32) error {
+	return c.classifyError(c.IMAPClient.DeleteEmails(ctx, uids))
+}
+
+// MoveEmails 移动邮件
+func (c *rateLimitedIMAPClient) MoveEmails(ctx context.Context, uids []uint32, targetFolder string) error {
+	return c.classifyError(c.IMAPClient.MoveEmails(ctx, uids, targetFolder))
+}
+
+// CopyEmails 复制邮件
+func (c *rateLimitedIMAPClient) CopyEmails(ctx context.Context, uids []uint32, targetFolder string) error {
+	return c.classifyError(c.IMAPClient.CopyEmails(ctx, uids, targetFolder))
+}
+
+// AppendMessage 追加邮件到文件夹
+func (c *rateLimitedIMAPClient) AppendMessage(ctx context.Context, folderName string, flags []string, date time.Time, data []byte) error {
+	return c.classifyError(c.IMAPClient.AppendMessage(ctx, folderName, flags, date, data))
+}
+
+// SearchEmails 搜索邮件
+func (c *rateLimitedIMAPClient) SearchEmails(ctx context.Context, criteria *SearchCriteria) ([]uint32, error) {
+	uids, err := c.IMAPClient.SearchEmails(ctx, criteria)
+	return uids, c.classifyError(err)
+}
+
+// GetFolderStatus 获取文件夹状态
+func (c *rateLimitedIMAPClient) GetFolderStatus(ctx context.Context, folderName string) (*FolderStatus, error) {
+	status, err := c.IMAPClient.GetFolderStatus(ctx, folderName)
+	return status, c.classifyError(err)
+}
+
+// GetAttachment 获取附件
+func (c *rateLimitedIMAPClient) GetAttachment(ctx context.Context, folderName string, uid uint32, partID string) (io.ReadCloser, error) {
+	reader, err := c.IMAPClient.GetAttachment(ctx, folderName, uid, partID)
+	return reader, c.classifyError(err)
+}
+
+// IsConnectionAlive 转发连接健康检查
+func (c *rateLimitedIMAPClient) IsConnectionAlive() bool {
+	if checker, ok := c.IMAPClient.(interface{ IsConnectionAlive() bool }); ok {
+		return checker.IsConnectionAlive()
+	}
+	return c.IMAPClient.IsConnected()
+}
+
+// RefreshConnectionTimeout 转发连接超时刷新
+func (c *rateLimitedIMAPClient) RefreshConnectionTimeout() error {
+	if refresher, ok := c.IMAPClient.(interface{ RefreshConnectionTimeout() error }); ok {
+		return refresher.RefreshConnectionTimeout()
+	}
+	return nil
+}
+
+// AllowsKeywords 转发自定义关键字支持检查
+func (c *rateLimitedIMAPClient) AllowsKeywords() bool {
+	if flags, ok := c.IMAPClient.(FlagClient); ok {
+		return flags.AllowsKeywords()
+	}
+	return false
+}
+
+// StoreFlags 转发标志写入，底层客户端不支持时返回错误
+func (c *rateLimitedIMAPClient) StoreFlags(ctx context.Context, uids []uint32, flags []string, add bool) error {
+	client, ok := c.IMAPClient.(FlagClient)
+	if !ok {
+		return fmt.Errorf("IMAP client does not support storing flags")
+	}
+	return c.classifyError(client.StoreFlags(ctx, uids, flags, add))
+}
+
+func (c *rateLimitedIMAPClient) classifyError(err error) error {
+	return handleServerThrottle(c.limiter, c.provider, c.accountID, err)
+}
+
+// rateLimitedSMTPClient 按账户限制发送速率、并将错误按类别分类的SMTP客户端
+type rateLimitedSMTPClient struct {
+	SMTPClient
+	limiter   *RateLimiter
+	provider  string
+	accountID uint
+}
+
+func newRateLimitedSMTPClient(client SMTPClient, limiter *RateLimiter, provider string, accountID uint) *rateLimitedSMTPClient {
+	return &rateLimitedSMTPClient{
+		SMTPClient: client,
+		limiter:    limiter,
+		provider:   provider,
+		accountID:  accountID,
+	}
+}
+
+// SendEmail 发送邮件
+func (c *rateLimitedSMTPClient) SendEmail(ctx context.Context, message *OutgoingMessage) error {
+	if err := c.limiter.AcquireSend(ctx, c.provider, c.accountID); err != nil {
+		return err
+	}
+	return handleServerThrottle(c.limiter, c.provider, c.accountID, c.SMTPClient.SendEmail(ctx, message))
+}
+
+// SendRawEmail 发送原始邮件
+func (c *rateLimitedSMTPClient) SendRawEmail(ctx context.Context, from string, to []string, data []byte) error {
+	if err := c.limiter.AcquireSend(ctx, c.provider, c.accountID); err != nil {
+		return err
+	}
+	return handleServerThrottle(c.limiter, c.provider, c.accountID, c.SMTPClient.SendRawEmail(ctx, from, to, data))
+}
+
+// handleServerThrottle 服务器返回限流响应时暂停该账户并转换为限流错误，其余错误按类别分类
+func handleServerThrottle(limiter *RateLimiter, provider string, accountID uint, err error) error {
+	if err == nil {
+		return nil
+	}
+	if !IsServerThrottleError(err) {
+		return ClassifyProviderError(provider, err)
+	}
+	limiter.Backoff(provider, accountID, defaultServerThrottleBackoff)
+	return NewRateLimitError(provider, defaultServerThrottleBackoff, err)
+}
@@ -0,0 +1,389 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"firemail/internal/config"
+)
+
+const (
+	// 服务器返回限流响应后的默认退避时间
+	defaultServerThrottleBackoff = 5 * time.Minute
+	// 连接占用超过该时长视为泄漏，自动回收
+	connectionLeaseTTL = 30 * time.Minute
+)
+
+// serverThrottleKeywords 邮件服务器限流响应的特征
+var serverThrottleKeywords = []string{
+	"rate limit",
+	"too many",
+	"too frequent",
+	"frequency limit",
+	"try again later",
+	"4.7.0 temporary system problem",
+	"4.7.28",
+	"throttl",
+	"频率",
+}
+
+// RateLimiter 按提供商+账户限制发送速率、并发连接数与拉取节奏
+type RateLimiter struct {
+	mutex    sync.Mutex
+	config   config.RateLimitConfig
+	accounts map[string]*accountRateState
+}
+
+// accountRateState 单个账户的限速状态
+type accountRateState struct {
+	limit        config.ProviderRateLimit
+	tokens       float64
+	lastRefill   time.Time
+	blockedUntil time.Time
+	nextFetch    time.Time
+	leases       map[uint64]time.Time
+	nextLeaseID  uint64
+	released     chan struct{}
+	bandwidth    *ByteBudget // 同步带宽预算，不限制时为nil
+}
+
+// NewRateLimiter 创建限速器
+func NewRateLimiter(cfg config.RateLimitConfig) *RateLimiter {
+	return &RateLimiter{
+		config:   cfg,
+		accounts: make(map[string]*accountRateState),
+	}
+}
+
+// 全局限速器实例
+var globalRateLimiter *RateLimiter
+var rateLimiterOnce sync.Once
+
+// GetGlobalRateLimiter 获取全局限速器
+func GetGlobalRateLimiter() *RateLimiter {
+	rateLimiterOnce.Do(func() {
+		globalRateLimiter = NewRateLimiter(config.Load().RateLimit)
+	})
+	return globalRateLimiter
+}
+
+// ConfigureRateLimiter 使用应用配置更新全局限速器
+func ConfigureRateLimiter(cfg config.RateLimitConfig) {
+	limiter := GetGlobalRateLimiter()
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	limiter.config = cfg
+	limiter.accounts = make(map[string]*accountRateState)
+}
+
+// RateLimitKey 生成限速键
+func RateLimitKey(provider string, accountID uint) string {
+	return fmt.Sprintf("%s:%d", strings.ToLower(provider), accountID)
+}
+
+// AcquireSend 获取一次发送配额，等待时间超过上限时返回限流错误
+func (r *RateLimiter) AcquireSend(ctx context.Context, provider string, accountID uint) error {
+	for {
+		r.mutex.Lock()
+		if !r.config.Enabled {
+			r.mutex.Unlock()
+			return nil
+		}
+		state := r.getState(provider, accountID)
+		if state.limit.SendPerMinute <= 0 {
+			r.mutex.Unlock()
+			return nil
+		}
+
+		now := time.Now()
+		state.refill(now)
+
+		var wait time.Duration
+		switch {
+		case now.Before(state.blockedUntil):
+			wait = state.blockedUntil.Sub(now)
+		case state.tokens >= 1:
+			state.tokens--
+			r.mutex.Unlock()
+			return nil
+		default:
+			wait = time.Duration((1 - state.tokens) * float64(state.sendInterval()))
+		}
+		maxWait := r.config.MaxWait
+		r.mutex.Unlock()
+
+		if wait > maxWait {
+			return NewRateLimitError(provider, wait, nil)
+		}
+		if err := sleepWithContext(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// AcquireConnection 占用一个连接名额，返回释放函数
+func (r *RateLimiter) AcquireConnection(ctx context.Context, provider string, accountID uint) (func(), error) {
+	deadline := time.Now().Add(r.maxWait())
+
+	for {
+		r.mutex.Lock()
+		if !r.config.Enabled {
+			r.mutex.Unlock()
+			return func() {}, nil
+		}
+		state := r.getState(provider, accountID)
+		if state.limit.MaxConnections <= 0 {
+			r.mutex.Unlock()
+			return func() {}, nil
+		}
+
+		now := time.Now()
+		state.expireLeases(now)
+		if len(state.leases) < state.limit.MaxConnections && !now.Before(state.blockedUntil) {
+			state.nextLeaseID++
+			leaseID := state.nextLeaseID
+			state.leases[leaseID] = now
+			r.mutex.Unlock()
+
+			var once sync.Once
+			return func() {
+				once.Do(func() { r.releaseConnection(state, leaseID) })
+			}, nil
+		}
+
+		wait := deadline.Sub(now)
+		if now.Before(state.blockedUntil) && state.blockedUntil.Sub(now) > wait {
+			r.mutex.Unlock()
+			return nil, NewRateLimitError(provider, state.blockedUntil.Sub(now), nil)
+		}
+		released := state.released
+		r.mutex.Unlock()
+
+		if wait <= 0 {
+			return nil, NewRateLimitError(provider, time.Second*10, fmt.Errorf("too many concurrent connections"))
+		}
+
+		timer := time.NewTimer(minDuration(wait, time.Second))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-released:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// PaceFetch 控制相邻两批邮件拉取之间的间隔
+func (r *RateLimiter) PaceFetch(ctx context.Context, provider string, accountID uint) error {
+	r.mutex.Lock()
+	if !r.config.Enabled {
+		r.mutex.Unlock()
+		return nil
+	}
+	state := r.getState(provider, accountID)
+
+	now := time.Now()
+	start := now
+	if state.nextFetch.After(start) {
+		start = state.nextFetch
+	}
+	if state.blockedUntil.After(start) {
+		start = state.blockedUntil
+	}
+	state.nextFetch = start.Add(state.limit.FetchInterval)
+	maxWait := r.config.MaxWait
+	r.mutex.Unlock()
+
+	wait := start.Sub(now)
+	if wait > maxWait {
+		return NewRateLimitError(provider, wait, nil)
+	}
+	return sleepWithContext(ctx, wait)
+}
+
+// Backoff 在服务器返回限流响应后暂停该账户的访问
+func (r *RateLimiter) Backoff(provider string, accountID uint, duration time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	state := r.getState(provider, accountID)
+	until := time.Now().Add(duration)
+	if until.After(state.blockedUntil) {
+		state.blockedUntil = until
+	}
+}
+
+func (r *RateLimiter) maxWait() time.Duration {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.config.MaxWait
+}
+
+func (r *RateLimiter) getState(provider string, accountID uint) *accountRateState {
+	key := RateLimitKey(provider, accountID)
+	state, exists := r.accounts[key]
+	if !exists {
+		limit := r.config.ForProvider(provider)
+		state = &accountRateState{
+			limit:      limit,
+			tokens:     float64(sendBurst(limit)),
+			lastRefill: time.Now(),
+			leases:     make(map[uint64]time.Time),
+			released:   make(chan struct{}),
+		}
+		r.accounts[key] = state
+	}
+	return state
+}
+
+// ConnectionLimit 获取账户的并发连接数上限，未启用限速或不限制时返回0
+func (r *RateLimiter) ConnectionLimit(provider string) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if !r.config.Enabled {
+		return 0
+	}
+	return r.config.ForProvider(provider).MaxConnections
+}
+
+// SyncBandwidth 获取账户的同步带宽预算，同一账户的连接共享；未配置时返回nil。
+// 带宽限制单独配置，不受 RATE_LIMIT_ENABLED 影响
+func (r *RateLimiter) SyncBandwidth(provider string, accountID uint) *ByteBudget {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	state := r.getState(provider, accountID)
+	if state.bandwidth == nil {
+		state.bandwidth = NewByteBudget(state.limit.SyncBytesPerMinute)
+	}
+	return state.bandwidth
+}
+
+func (r *RateLimiter) releaseConnection(state *accountRateState, leaseID uint64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := state.leases[leaseID]; !exists {
+		return
+	}
+	delete(state.leases, leaseID)
+	close(state.released)
+	state.released = make(chan struct{})
+}
+
+func (s *accountRateState) sendInterval() time.Duration {
+	return time.Minute / time.Duration(s.limit.SendPerMinute)
+}
+
+func (s *accountRateState) refill(now time.Time) {
+	elapsed := now.Sub(s.lastRefill)
+	if elapsed <= 0 {
+		return
+	}
+	s.tokens += float64(elapsed) / float64(s.sendInterval())
+	if burst := float64(sendBurst(s.limit)); s.tokens > burst {
+		s.tokens = burst
+	}
+	s.lastRefill = now
+}
+
+func (s *accountRateState) expireLeases(now time.Time) {
+	for id, acquiredAt := range s.leases {
+		if now.Sub(acquiredAt) > connectionLeaseTTL {
+			delete(s.leases, id)
+		}
+	}
+}
+
+// sendBurst 允许的突发发送数量，避免一次性用完整分钟的配额
+func sendBurst(limit config.ProviderRateLimit) int {
+	burst := limit.SendPerMinute / 10
+	if burst < 1 {
+		burst = 1
+	}
+	return burst
+}
+
+// NewRateLimitError 创建限流错误，RetryAfter 可从中读取建议的重试时间
+func NewRateLimitError(provider string, retryAfter time.Duration, cause error) *ProviderError {
+	retryAfter = retryAfter.Round(time.Second)
+	if retryAfter < time.Second {
+		retryAfter = time.Second
+	}
+
+	message := fmt.Sprintf("rate limit exceeded, retry after %s", retryAfter)
+	if cause != nil {
+		message = fmt.Sprintf("%s: %v", message, cause)
+	}
+
+	return &ProviderError{
+		Type:      ErrorTypeRateLimit,
+		Code:      "429",
+		Message:   message,
+		Provider:  provider,
+		Severity:  SeverityMedium,
+		Retryable: true,
+		Temporary: true,
+		Cause:     cause,
+		Context: map[string]interface{}{
+			"retry_after": retryAfter,
+		},
+		Timestamp:   time.Now(),
+		Suggestions: []string{"Reduce request frequency", "Wait before retrying"},
+	}
+}
+
+// RetryAfter 从限流错误中读取建议的重试等待时间
+func RetryAfter(err error) (time.Duration, bool) {
+	var providerErr *ProviderError
+	if !errors.As(err, &providerErr) || providerErr.Type != ErrorTypeRateLimit {
+		return 0, false
+	}
+	if retryAfter, ok := providerErr.Context["retry_after"].(time.Duration); ok {
+		return retryAfter, true
+	}
+	return defaultServerThrottleBackoff, true
+}
+
+// IsServerThrottleError 判断是否为服务器返回的限流响应
+func IsServerThrottleError(err error) bool {
+	if err == nil {
+		return false
+	}
+	errStr := strings.ToLower(err.Error())
+	for _, keyword := range serverThrottleKeywords {
+		if strings.Contains(errStr, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
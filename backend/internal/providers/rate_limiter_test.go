@@ -0,0 +1,214 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"firemail/internal/config"
+)
+
+func newTestRateLimiter(limit config.ProviderRateLimit) *RateLimiter {
+	return NewRateLimiter(config.RateLimitConfig{
+		Enabled: true,
+		MaxWait: 50 * time.Millisecond,
+		Default: limit,
+	})
+}
+
+func TestRateLimiter_AcquireSendReturnsRateLimitError(t *testing.T) {
+	limiter := newTestRateLimiter(config.ProviderRateLimit{SendPerMinute: 10})
+	ctx := context.Background()
+
+	// 突发配额为1，第二次发送需要等待约6秒，超过最大等待时间
+	if err := limiter.AcquireSend(ctx, "qq", 1); err != nil {
+		t.Fatalf("first send should pass: %v", err)
+	}
+
+	err := limiter.AcquireSend(ctx, "qq", 1)
+	retryAfter, ok := RetryAfter(err)
+	if !ok {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+	if retryAfter < 5*time.Second || retryAfter > 6*time.Second {
+		t.Errorf("unexpected retry after %s", retryAfter)
+	}
+
+	// 不同账户互不影响
+	if err := limiter.AcquireSend(ctx, "qq", 2); err != nil {
+		t.Errorf("other account should not be limited: %v", err)
+	}
+}
+
+func TestRateLimiter_ConnectionLimit(t *testing.T) {
+	limiter := newTestRateLimiter(config.ProviderRateLimit{MaxConnections: 1})
+	ctx := context.Background()
+
+	release, err := limiter.AcquireConnection(ctx, "gmail", 1)
+	if err != nil {
+		t.Fatalf("first connection should pass: %v", err)
+	}
+
+	if _, err := limiter.AcquireConnection(ctx, "gmail", 1); err == nil {
+		t.Fatal("expected second connection to be rejected")
+	}
+
+	// 等待中的连接在名额释放后应立即获得
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		release()
+		release()
+	}()
+	next, err := limiter.AcquireConnection(ctx, "gmail", 1)
+	if err != nil {
+		t.Fatalf("connection should be acquired after release: %v", err)
+	}
+	next()
+}
+
+func TestRateLimiter_BackoffAfterServerThrottle(t *testing.T) {
+	limiter := newTestRateLimiter(config.ProviderRateLimit{SendPerMinute: 600})
+	client := newRateLimitedSMTPClient(&throttledSMTPClient{}, limiter, "gmail", 1)
+
+	err := client.SendEmail(context.Background(), &OutgoingMessage{})
+	var providerErr *ProviderError
+	if !errors.As(err, &providerErr) || providerErr.Type != ErrorTypeRateLimit {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+
+	// 退避期间不再访问服务器
+	if err := limiter.AcquireSend(context.Background(), "gmail", 1); err == nil {
+		t.Fatal("expected send to be blocked during backoff")
+	}
+	if err := limiter.PaceFetch(context.Background(), "gmail", 1); err == nil {
+		t.Fatal("expected fetch to be blocked during backoff")
+	}
+}
+
+func TestRateLimiter_Disabled(t *testing.T) {
+	limiter := NewRateLimiter(config.RateLimitConfig{
+		Enabled: false,
+		Default: config.ProviderRateLimit{SendPerMinute: 1, MaxConnections: 1},
+	})
+
+	for i := 0; i < 3; i++ {
+		if err := limiter.AcquireSend(context.Background(), "qq", 1); err != nil {
+			t.Fatalf("disabled limiter should not block: %v", err)
+		}
+	}
+}
+
+type throttledSMTPClient struct {
+	SMTPClient
+}
+
+func (c *throttledSMTPClient) SendEmail(ctx context.Context, message *OutgoingMessage) error {
+	return errors.New("421 4.7.28 Our system has detected an unusual rate of unsolicited mail")
+}
+
+func TestRateLimiter_SyncBandwidthThrottlesConn(t *testing.T) {
+	limiter := newTestRateLimiter(config.ProviderRateLimit{SyncBytesPerMinute: 60 * 8192})
+	if limiter.SyncBandwidth("gmail", 2) == limiter.SyncBandwidth("gmail", 1) {
+		t.Fatal("accounts should have separate budgets")
+	}
+	budget := limiter.SyncBandwidth("gmail", 1)
+	if budget != limiter.SyncBandwidth("gmail", 1) {
+		t.Fatal("connections of one account should share the budget")
+	}
+
+	clock := budget.last
+	var slept time.Duration
+	budget.now = func() time.Time { return clock }
+	budget.sleep = func(ctx context.Context, d time.Duration) error {
+		slept += d
+		clock = clock.Add(d)
+		return nil
+	}
+
+	server, client := net.Pipe()
+	defer server.Close()
+	conn := throttleConn(client, budget)
+	defer conn.Close()
+
+	payload := make([]byte, 3*8192)
+	go func() {
+		server.Write(payload)
+	}()
+
+	// 每秒8KB，一次读取不超过突发额度，读完24KB约需等待2秒
+	buf := make([]byte, len(payload))
+	read := 0
+	for read < len(payload) {
+		n, err := conn.Read(buf[read:])
+		if err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		if n > 8192 {
+			t.Fatalf("read %d bytes exceeds burst", n)
+		}
+		read += n
+	}
+	if slept < 1900*time.Millisecond || slept > 2100*time.Millisecond {
+		t.Errorf("unexpected throttle wait %s", slept)
+	}
+
+	// 未配置预算时不限制
+	if newTestRateLimiter(config.ProviderRateLimit{}).SyncBandwidth("gmail", 1) != nil {
+		t.Error("budget should be nil when unlimited")
+	}
+	if throttleConn(client, nil) != client {
+		t.Error("nil budget should not wrap the connection")
+	}
+
+	// 带宽限制不受 RATE_LIMIT_ENABLED 影响
+	disabled := NewRateLimiter(config.RateLimitConfig{Default: config.ProviderRateLimit{SyncBytesPerMinute: 60 * 8192}})
+	if disabled.SyncBandwidth("gmail", 1) == nil {
+		t.Error("bandwidth should be limited when rate limiting is disabled")
+	}
+}
+
+func TestThrottledConnWaitHonorsDeadlineAndClose(t *testing.T) {
+	// 每秒1KB，突发额度4KB，写入超过额度的数据需要等待数秒
+	newConn := func() (net.Conn, net.Conn) {
+		server, client := net.Pipe()
+		go func() {
+			buf := make([]byte, 1024)
+			for {
+				if _, err := server.Read(buf); err != nil {
+					return
+				}
+			}
+		}()
+		return server, throttleConn(client, NewByteBudget(60*1024))
+	}
+	payload := make([]byte, 3*minBandwidthChunk)
+
+	server, conn := newConn()
+	defer server.Close()
+	conn.SetWriteDeadline(time.Now().Add(50 * time.Millisecond))
+	start := time.Now()
+	if _, err := conn.Write(payload); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("expected deadline error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("write waited %s past the deadline", elapsed)
+	}
+	conn.Close()
+
+	server, conn = newConn()
+	defer server.Close()
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		conn.Close()
+	}()
+	start = time.Now()
+	if _, err := conn.Write(payload); !errors.Is(err, net.ErrClosed) {
+		t.Fatalf("expected closed error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("write waited %s after close", elapsed)
+	}
+}
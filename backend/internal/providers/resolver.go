@@ -0,0 +1,202 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"firemail/internal/config"
+	"firemail/internal/models"
+)
+
+// dohMaxResponseSize DNS消息的最大长度
+const dohMaxResponseSize = 65535
+
+var (
+	dnsMu sync.RWMutex
+	// globalResolver 全局DNS解析器，nil表示使用系统DNS
+	globalResolver *net.Resolver
+	dnsTimeout     = 5 * time.Second
+	// accountResolvers 按账户设置缓存的解析器，DoH解析器复用HTTP连接
+	accountResolvers sync.Map
+)
+
+// ConfigureDNS 设置全局DNS解析器和查询超时，连接邮件服务器和查询MX记录时使用；启动时调用一次
+func ConfigureDNS(cfg config.DNSConfig) {
+	dnsMu.Lock()
+	defer dnsMu.Unlock()
+
+	if cfg.Timeout > 0 {
+		dnsTimeout = cfg.Timeout
+	}
+	resolver, err := newResolver(cfg.Resolver, dnsTimeout)
+	if err != nil {
+		log.Printf("Warning: ignoring DNS resolver setting: %v", err)
+		resolver = nil
+	}
+	globalResolver = resolver
+	accountResolvers.Range(func(key, _ interface{}) bool {
+		accountResolvers.Delete(key)
+		return true
+	})
+}
+
+// DefaultResolver 全局DNS解析器，未设置时为系统DNS
+func DefaultResolver() *net.Resolver {
+	dnsMu.RLock()
+	defer dnsMu.RUnlock()
+	if globalResolver == nil {
+		return net.DefaultResolver
+	}
+	return globalResolver
+}
+
+// ResolverForAccount 账户使用的DNS解析器：账户单独设置时优先，否则为全局设置
+func ResolverForAccount(account *models.EmailAccount) *net.Resolver {
+	if account == nil || account.DNSResolver == "" {
+		return DefaultResolver()
+	}
+	if cached, ok := accountResolvers.Load(account.DNSResolver); ok {
+		return cached.(*net.Resolver)
+	}
+
+	dnsMu.RLock()
+	timeout := dnsTimeout
+	dnsMu.RUnlock()
+	resolver, err := newResolver(account.DNSResolver, timeout)
+	if err != nil || resolver == nil {
+		log.Printf("Warning: invalid DNS resolver for account %d, using the default resolver: %v", account.ID, err)
+		return DefaultResolver()
+	}
+	cached, _ := accountResolvers.LoadOrStore(account.DNSResolver, resolver)
+	return cached.(*net.Resolver)
+}
+
+// ValidateDNSResolver 检查DNS解析器设置，格式与 DNS_RESOLVER 相同
+func ValidateDNSResolver(spec string) error {
+	_, _, err := config.ParseDNSResolver(spec)
+	return err
+}
+
+// newResolver 根据设置创建解析器：DNS服务器地址使用Go的解析器直接查询该服务器，
+// https:// 地址通过DoH（RFC 8484）查询。设置为空时返回nil
+func newResolver(spec string, timeout time.Duration) (*net.Resolver, error) {
+	dohURL, server, err := config.ParseDNSResolver(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case dohURL != "":
+		// DoH服务器本身的地址用系统DNS解析，DNS被污染时应使用IP形式的地址，如 https://1.1.1.1/dns-query
+		client := &http.Client{Timeout: timeout}
+		return &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return &dohConn{ctx: ctx, client: client, url: dohURL}, nil
+			},
+		}, nil
+	case server != "":
+		dialer := &net.Dialer{Timeout: timeout}
+		return &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, server)
+			},
+		}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// dohConn 把Go解析器的TCP格式DNS查询（2字节长度前缀）转换为DoH请求。
+// 不实现 net.PacketConn，解析器因此按流式连接读写
+type dohConn struct {
+	ctx      context.Context
+	client   *http.Client
+	url      string
+	deadline time.Time
+
+	request  bytes.Buffer
+	response bytes.Reader
+}
+
+func (c *dohConn) Write(b []byte) (int, error) {
+	c.request.Write(b)
+	for c.request.Len() >= 2 {
+		size := int(binary.BigEndian.Uint16(c.request.Bytes()[:2]))
+		if c.request.Len() < 2+size {
+			break
+		}
+		c.request.Next(2)
+		query := append([]byte(nil), c.request.Next(size)...)
+		if err := c.exchange(query); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+func (c *dohConn) exchange(query []byte) error {
+	ctx := c.ctx
+	if !c.deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, c.deadline)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(query))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("DNS-over-HTTPS request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("DNS-over-HTTPS server returned %s", resp.Status)
+	}
+	answer, err := io.ReadAll(io.LimitReader(resp.Body, dohMaxResponseSize+1))
+	if err != nil {
+		return fmt.Errorf("failed to read DNS-over-HTTPS response: %w", err)
+	}
+	if len(answer) > dohMaxResponseSize {
+		return fmt.Errorf("DNS-over-HTTPS response too large")
+	}
+
+	framed := make([]byte, 2+len(answer))
+	binary.BigEndian.PutUint16(framed, uint16(len(answer)))
+	copy(framed[2:], answer)
+	c.response.Reset(framed)
+	return nil
+}
+
+func (c *dohConn) Read(b []byte) (int, error) {
+	if c.response.Len() == 0 {
+		return 0, io.EOF
+	}
+	return c.response.Read(b)
+}
+
+func (c *dohConn) Close() error                       { return nil }
+func (c *dohConn) LocalAddr() net.Addr                { return dohAddr{} }
+func (c *dohConn) RemoteAddr() net.Addr               { return dohAddr{} }
+func (c *dohConn) SetDeadline(t time.Time) error      { c.deadline = t; return nil }
+func (c *dohConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *dohConn) SetWriteDeadline(t time.Time) error { c.deadline = t; return nil }
+
+type dohAddr struct{}
+
+func (dohAddr) Network() string { return "https" }
+func (dohAddr) String() string  { return "dns-over-https" }
@@ -0,0 +1,80 @@
+package providers
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+
+	"firemail/internal/config"
+	"firemail/internal/models"
+)
+
+func TestDoHResolverLookup(t *testing.T) {
+	var queries []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "application/dns-message", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		var query dnsmessage.Message
+		require.NoError(t, query.Unpack(body))
+		question := query.Questions[0]
+		queries = append(queries, question.Name.String())
+
+		answer := dnsmessage.Message{
+			Header:    dnsmessage.Header{ID: query.ID, Response: true, RecursionAvailable: true},
+			Questions: query.Questions,
+		}
+		if question.Type == dnsmessage.TypeA && question.Name.String() == "imap.gmail.com." {
+			answer.Answers = []dnsmessage.Resource{{
+				Header: dnsmessage.ResourceHeader{Name: question.Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 60},
+				Body:   &dnsmessage.AResource{A: [4]byte{142, 250, 0, 108}},
+			}}
+		}
+		packed, err := answer.Pack()
+		require.NoError(t, err)
+		w.Header().Set("Content-Type", "application/dns-message")
+		w.Write(packed)
+	}))
+	defer server.Close()
+
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return &dohConn{ctx: ctx, client: server.Client(), url: server.URL}, nil
+		},
+	}
+	addrs, err := resolver.LookupIPAddr(context.Background(), "imap.gmail.com")
+	require.NoError(t, err)
+	require.Len(t, addrs, 1)
+	require.Equal(t, "142.250.0.108", addrs[0].IP.String())
+	require.Contains(t, queries, "imap.gmail.com.")
+}
+
+func TestResolverForAccount(t *testing.T) {
+	ConfigureDNS(config.DNSConfig{Resolver: "127.0.0.1:5353"})
+	defer ConfigureDNS(config.DNSConfig{})
+
+	global := DefaultResolver()
+	require.NotSame(t, net.DefaultResolver, global)
+	require.Same(t, global, ResolverForAccount(&models.EmailAccount{}))
+
+	// 账户设置优先，相同设置复用解析器
+	account := &models.EmailAccount{DNSResolver: "https://1.1.1.1/dns-query"}
+	resolver := ResolverForAccount(account)
+	require.NotSame(t, global, resolver)
+	require.Same(t, resolver, ResolverForAccount(&models.EmailAccount{DNSResolver: account.DNSResolver}))
+
+	// 无效设置退回全局解析器
+	require.Same(t, global, ResolverForAccount(&models.EmailAccount{DNSResolver: "ftp://example.com"}))
+
+	ConfigureDNS(config.DNSConfig{})
+	require.Same(t, net.DefaultResolver, DefaultResolver())
+}
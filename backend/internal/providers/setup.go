@@ -0,0 +1,45 @@
+package providers
+
+import (
+	"errors"
+	"time"
+
+	"firemail/internal/models"
+)
+
+// setupHelpURLKey 设置错误在 ProviderError.Context 中保存帮助页面的键
+const setupHelpURLKey = "setup_help_url"
+
+// NewSetupError 创建需要用户先在邮箱网页版完成设置的认证错误（如未开启IMAP服务、未使用授权码），
+// steps 为分步设置说明，作为 Suggestions 返回。错误属于 ErrAuth，连接时不会重试
+func NewSetupError(provider, code, message, helpURL string, steps []string, cause error) *ProviderError {
+	return &ProviderError{
+		Type:        ErrorTypeCredentials,
+		Code:        code,
+		Message:     message,
+		Provider:    provider,
+		Severity:    SeverityHigh,
+		Cause:       cause,
+		Context:     map[string]interface{}{setupHelpURLKey: helpURL},
+		Timestamp:   time.Now(),
+		Suggestions: steps,
+	}
+}
+
+// SetupGuideOf 取出设置错误附带的设置说明，不是设置错误时返回nil
+func SetupGuideOf(err error) *models.AccountSetupGuide {
+	var providerErr *ProviderError
+	if !errors.As(err, &providerErr) {
+		return nil
+	}
+	helpURL, ok := providerErr.Context[setupHelpURLKey].(string)
+	if !ok {
+		return nil
+	}
+	return &models.AccountSetupGuide{
+		Code:    providerErr.Code,
+		Message: providerErr.Message,
+		Steps:   append([]string(nil), providerErr.Suggestions...),
+		HelpURL: helpURL,
+	}
+}
@@ -1,9 +1,11 @@
 package providers
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"crypto/tls"
+	"encoding/base64"
 	"fmt"
 	"io"
 	"mime"
@@ -52,30 +54,55 @@ func (c *StandardSMTPClient) Connect(ctx context.Context, config SMTPClientConfi
 	var err error
 	var smtpClient *smtp.Client
 
+	// 使用账户的DNS解析器，Resolver为nil时使用系统DNS
+	dialer := &net.Dialer{
+		Timeout:  30 * time.Second,
+		Resolver: config.Resolver,
+	}
+	dial := func() (*smtp.Client, error) {
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return nil, err
+		}
+		return smtp.NewClient(conn, config.Host)
+	}
+
 	// 根据安全类型连接
 	switch strings.ToUpper(config.Security) {
 	case "SSL", "TLS":
 		// 直接使用TLS连接
-		tlsConfig := &tls.Config{
-			ServerName: config.Host,
+		tlsConfig, err := config.TLS.Config(config.Host)
+		if err != nil {
+			return err
 		}
-		conn, err := tls.Dial("tcp", addr, tlsConfig)
+		conn, err := tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
 		if err != nil {
 			return fmt.Errorf("failed to dial TLS: %w", err)
 		}
 		smtpClient, err = smtp.NewClient(conn, config.Host)
+		if err == nil {
+			err = c.hello(smtpClient)
+		}
 	case "STARTTLS":
 		// 先明文连接，然后升级到TLS
-		smtpClient, err = smtp.Dial(addr)
+		smtpClient, err = dial()
 		if err == nil {
-			tlsConfig := &tls.Config{
-				ServerName: config.Host,
+			err = c.hello(smtpClient)
+		}
+		if err == nil {
+			var tlsConfig *tls.Config
+			if tlsConfig, err = config.TLS.Config(config.Host); err == nil {
+				err = smtpClient.StartTLS(tlsConfig)
+			} else {
+				smtpClient.Close()
 			}
-			err = smtpClient.StartTLS(tlsConfig)
 		}
 	case "NONE":
 		// 明文连接
-		smtpClient, err = smtp.Dial(addr)
+		smtpClient, err = dial()
+		if err == nil {
+			err = c.hello(smtpClient)
+		}
 	default:
 		return fmt.Errorf("unsupported security type: %s", config.Security)
 	}
@@ -120,6 +147,18 @@ func (c *StandardSMTPClient) Connect(ctx context.Context, config SMTPClientConfi
 	return nil
 }
 
+// hello 使用配置的主机名发送EHLO，必须在其他命令之前调用
+func (c *StandardSMTPClient) hello(smtpClient *smtp.Client) error {
+	if c.config.EHLOName == "" {
+		return nil
+	}
+	if err := smtpClient.Hello(c.config.EHLOName); err != nil {
+		smtpClient.Close()
+		return err
+	}
+	return nil
+}
+
 // authenticateUnencrypted 在未加密连接上进行认证
 func (c *StandardSMTPClient) authenticateUnencrypted(smtpClient *smtp.Client, config SMTPClientConfig) error {
 	// 对于未加密连接，很多现代SMTP服务器不允许认证
@@ -226,12 +265,6 @@ func (c *StandardSMTPClient) SendEmail(ctx context.Context, message *OutgoingMes
 		return fmt.Errorf("SMTP client not connected")
 	}
 
-	// 构建邮件内容
-	emailData, err := c.buildEmailData(message)
-	if err != nil {
-		return fmt.Errorf("failed to build email data: %w", err)
-	}
-
 	// 提取收件人地址
 	var recipients []string
 
@@ -245,8 +278,18 @@ func (c *StandardSMTPClient) SendEmail(ctx context.Context, message *OutgoingMes
 		recipients = append(recipients, addr.Address)
 	}
 
-	// 发送邮件
-	return c.SendRawEmail(ctx, message.From.Address, recipients, emailData)
+	// 发送邮件，邮件内容直接流式写入连接，附件不整体驻留内存
+	envelopeFrom := message.From.Address
+	if message.EnvelopeFrom != "" {
+		envelopeFrom = message.EnvelopeFrom
+	}
+	return c.sendData(envelopeFrom, recipients, func(w io.Writer) error {
+		buffered := bufio.NewWriter(w)
+		if err := c.writeMessage(buffered, message); err != nil {
+			return fmt.Errorf("failed to build email data: %w", err)
+		}
+		return buffered.Flush()
+	})
 }
 
 // SendRawEmail 发送原始邮件数据
@@ -255,9 +298,72 @@ func (c *StandardSMTPClient) SendRawEmail(ctx context.Context, from string, to [
 		return fmt.Errorf("SMTP client not connected")
 	}
 
+	return c.sendData(from, to, func(w io.Writer) error {
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("failed to write email data: %w", err)
+		}
+		return nil
+	})
+}
+
+// smtpChunkSize 服务器支持CHUNKING时每个BDAT数据块的大小
+const smtpChunkSize = 1024 * 1024
+
+// sendData 执行MAIL/RCPT/DATA流程，由write写入邮件内容。
+// 服务器支持PIPELINING时MAIL和RCPT一次发出，支持CHUNKING时用BDAT代替DATA；
+// 事务失败后发送RSET，同一会话可以继续发送下一封邮件
+func (c *StandardSMTPClient) sendData(from string, to []string, write func(w io.Writer) error) error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
+	if c.client == nil {
+		return fmt.Errorf("SMTP client not connected")
+	}
+
+	pipelining, _ := c.client.Extension("PIPELINING")
+	chunking, _ := c.client.Extension("CHUNKING")
+
+	var err error
+	if pipelining {
+		err = c.pipelineEnvelope(from, to)
+	} else {
+		err = c.sendEnvelope(from, to)
+	}
+	if err != nil {
+		c.resetTransaction()
+		return err
+	}
+
+	// 发送邮件数据
+	var writer io.WriteCloser
+	if chunking {
+		writer = &bdatWriter{client: c}
+	} else {
+		writer, err = c.client.Data()
+		if err != nil {
+			c.resetTransaction()
+			return fmt.Errorf("failed to get data writer: %w", err)
+		}
+	}
+
+	if err := write(writer); err != nil {
+		// 写入中途失败时直接断开连接，避免服务器收到被截断的邮件
+		c.client.Close()
+		c.client = nil
+		c.connected = false
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		c.resetTransaction()
+		return fmt.Errorf("failed to finish email data: %w", err)
+	}
+
+	return nil
+}
+
+// sendEnvelope 逐条发送MAIL和RCPT命令
+func (c *StandardSMTPClient) sendEnvelope(from string, to []string) error {
 	// 设置发件人
 	if err := c.client.Mail(from); err != nil {
 		return fmt.Errorf("failed to set sender: %w", err)
@@ -269,27 +375,153 @@ func (c *StandardSMTPClient) SendRawEmail(ctx context.Context, from string, to [
 			return fmt.Errorf("failed to set recipient %s: %w", recipient, err)
 		}
 	}
+	return nil
+}
 
-	// 发送邮件数据
-	writer, err := c.client.Data()
-	if err != nil {
-		return fmt.Errorf("failed to get data writer: %w", err)
+// pipelineEnvelope 一次发出MAIL和全部RCPT命令后再依次读取响应（RFC 2920），
+// DATA在确认全部收件人被接受后再单独发送，任一收件人被拒绝时整封邮件不发送
+func (c *StandardSMTPClient) pipelineEnvelope(from string, to []string) error {
+	for _, address := range append([]string{from}, to...) {
+		if strings.ContainsAny(address, "\r\n") {
+			return fmt.Errorf("smtp: A line must not contain CR or LF")
+		}
+	}
+
+	mailCommand := fmt.Sprintf("MAIL FROM:<%s>", from)
+	if ok, _ := c.client.Extension("8BITMIME"); ok {
+		mailCommand += " BODY=8BITMIME"
+	}
+	if ok, _ := c.client.Extension("SMTPUTF8"); ok {
+		mailCommand += " SMTPUTF8"
+	}
+	commands := []string{mailCommand}
+	for _, recipient := range to {
+		commands = append(commands, fmt.Sprintf("RCPT TO:<%s>", recipient))
 	}
-	defer writer.Close()
 
-	if _, err := writer.Write(data); err != nil {
-		return fmt.Errorf("failed to write email data: %w", err)
+	text := c.client.Text
+	ids := make([]uint, len(commands))
+	for i, command := range commands {
+		id, err := text.Cmd("%s", command)
+		if err != nil {
+			return fmt.Errorf("failed to send pipelined command: %w", err)
+		}
+		ids[i] = id
+	}
+
+	// 必须读完所有响应，会话才能继续使用
+	var firstErr error
+	for i, id := range ids {
+		text.StartResponse(id)
+		_, _, err := text.ReadResponse(25)
+		text.EndResponse(id)
+		if err != nil && firstErr == nil {
+			if i == 0 {
+				firstErr = fmt.Errorf("failed to set sender: %w", err)
+			} else {
+				firstErr = fmt.Errorf("failed to set recipient %s: %w", to[i-1], err)
+			}
+		}
 	}
+	return firstErr
+}
 
-	return nil
+// resetTransaction 放弃当前事务，连接保持可用；RSET失败说明连接已不可用，直接断开
+func (c *StandardSMTPClient) resetTransaction() {
+	if c.client == nil {
+		return
+	}
+	if err := c.client.Reset(); err != nil {
+		c.client.Close()
+		c.client = nil
+		c.connected = false
+	}
+}
+
+// bdatWriter 按CHUNKING扩展（RFC 3030）以BDAT数据块发送邮件内容，无需点转义
+type bdatWriter struct {
+	client *StandardSMTPClient
+	buffer []byte
+}
+
+// Write 缓冲邮件内容，攒满一个数据块后发送
+func (w *bdatWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := smtpChunkSize - len(w.buffer)
+		if n > len(p) {
+			n = len(p)
+		}
+		w.buffer = append(w.buffer, p[:n]...)
+		p = p[n:]
+		written += n
+		if len(w.buffer) == smtpChunkSize {
+			if err := w.client.bdat(w.buffer, false); err != nil {
+				return written, err
+			}
+			w.buffer = w.buffer[:0]
+		}
+	}
+	return written, nil
+}
+
+// Close 以LAST标记发送最后一个数据块
+func (w *bdatWriter) Close() error {
+	return w.client.bdat(w.buffer, true)
+}
+
+// bdat 发送一个BDAT数据块并等待服务器确认
+func (c *StandardSMTPClient) bdat(chunk []byte, last bool) error {
+	text := c.client.Text
+	command := fmt.Sprintf("BDAT %d", len(chunk))
+	if last {
+		command += " LAST"
+	}
+
+	id := text.Next()
+	text.StartRequest(id)
+	_, err := text.W.WriteString(command + "\r\n")
+	if err == nil {
+		_, err = text.W.Write(chunk)
+	}
+	if err == nil {
+		err = text.W.Flush()
+	}
+	text.EndRequest(id)
+	if err != nil {
+		return err
+	}
+
+	text.StartResponse(id)
+	defer text.EndResponse(id)
+	_, _, err = text.ReadResponse(250)
+	return err
+}
+
+// BuildMessage 构建RFC 5322格式的原始邮件（用于IMAP APPEND等场景）
+func BuildMessage(message *OutgoingMessage) ([]byte, error) {
+	return NewStandardSMTPClient().buildEmailData(message)
 }
 
 // buildEmailData 构建邮件数据
 func (c *StandardSMTPClient) buildEmailData(message *OutgoingMessage) ([]byte, error) {
-	var builder strings.Builder
+	var buf bytes.Buffer
+	if err := c.writeMessage(&buf, message); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// messageWriter 邮件内容的写入目标
+type messageWriter interface {
+	io.Writer
+	io.StringWriter
+}
 
+// writeMessage 按RFC 5322格式写入邮件，附件边读边编码
+func (c *StandardSMTPClient) writeMessage(builder messageWriter, message *OutgoingMessage) error {
 	// 写入邮件头
-	c.writeHeaders(&builder, message)
+	c.writeHeaders(builder, message)
 
 	// 检查是否有附件
 	if len(message.Attachments) > 0 {
@@ -299,12 +531,12 @@ func (c *StandardSMTPClient) buildEmailData(message *OutgoingMessage) ([]byte, e
 		builder.WriteString("\r\n")
 
 		// 邮件正文部分
-		c.writeTextPart(&builder, message, boundary)
+		c.writeTextPart(builder, message, boundary)
 
 		// 附件部分
 		for _, attachment := range message.Attachments {
-			if err := c.writeAttachmentPart(&builder, attachment, boundary); err != nil {
-				return nil, fmt.Errorf("failed to write attachment: %w", err)
+			if err := c.writeAttachmentPart(builder, attachment, boundary); err != nil {
+				return fmt.Errorf("failed to write attachment: %w", err)
 			}
 		}
 
@@ -312,14 +544,14 @@ func (c *StandardSMTPClient) buildEmailData(message *OutgoingMessage) ([]byte, e
 		builder.WriteString(fmt.Sprintf("\r\n--%s--\r\n", boundary))
 	} else {
 		// 简单邮件
-		c.writeSimpleBody(&builder, message)
+		c.writeSimpleBody(builder, message)
 	}
 
-	return []byte(builder.String()), nil
+	return nil
 }
 
 // writeHeaders 写入邮件头
-func (c *StandardSMTPClient) writeHeaders(builder *strings.Builder, message *OutgoingMessage) {
+func (c *StandardSMTPClient) writeHeaders(builder messageWriter, message *OutgoingMessage) {
 	// 基本头信息
 	builder.WriteString(fmt.Sprintf("From: %s\r\n", c.formatAddress(message.From)))
 	builder.WriteString(fmt.Sprintf("To: %s\r\n", c.formatAddresses(message.To)))
@@ -355,7 +587,7 @@ func (c *StandardSMTPClient) writeHeaders(builder *strings.Builder, message *Out
 }
 
 // writeSimpleBody 写入简单邮件正文
-func (c *StandardSMTPClient) writeSimpleBody(builder *strings.Builder, message *OutgoingMessage) {
+func (c *StandardSMTPClient) writeSimpleBody(builder messageWriter, message *OutgoingMessage) {
 	if message.HTMLBody != "" && message.TextBody != "" {
 		// 多部分替代内容
 		boundary := generateBoundary()
@@ -395,7 +627,7 @@ func (c *StandardSMTPClient) writeSimpleBody(builder *strings.Builder, message *
 }
 
 // writeTextPart 写入文本部分
-func (c *StandardSMTPClient) writeTextPart(builder *strings.Builder, message *OutgoingMessage, boundary string) {
+func (c *StandardSMTPClient) writeTextPart(builder messageWriter, message *OutgoingMessage, boundary string) {
 	builder.WriteString(fmt.Sprintf("--%s\r\n", boundary))
 
 	if message.HTMLBody != "" && message.TextBody != "" {
@@ -439,7 +671,7 @@ func (c *StandardSMTPClient) writeTextPart(builder *strings.Builder, message *Ou
 }
 
 // writeAttachmentPart 写入附件部分
-func (c *StandardSMTPClient) writeAttachmentPart(builder *strings.Builder, attachment *OutgoingAttachment, boundary string) error {
+func (c *StandardSMTPClient) writeAttachmentPart(builder messageWriter, attachment *OutgoingAttachment, boundary string) error {
 	builder.WriteString(fmt.Sprintf("\r\n--%s\r\n", boundary))
 
 	// 内容类型
@@ -475,16 +707,16 @@ func (c *StandardSMTPClient) writeAttachmentPart(builder *strings.Builder, attac
 		return fmt.Errorf("attachment %s has nil content", attachment.Filename)
 	}
 
-	content, err := io.ReadAll(attachment.Content)
-	if err != nil {
+	// Base64编码，每行76个字符
+	lines := &lineWrapWriter{w: builder, lineLength: 76}
+	encoder := base64.NewEncoder(base64.StdEncoding, lines)
+	if _, err := io.Copy(encoder, attachment.Content); err != nil {
 		return fmt.Errorf("failed to read attachment content: %w", err)
 	}
-
-	// Base64编码
-	encoded := encodeBase64(content)
-	builder.WriteString(encoded)
-
-	return nil
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode attachment content: %w", err)
+	}
+	return lines.finish()
 }
 
 // formatAddress 格式化邮件地址
@@ -510,52 +742,47 @@ func generateBoundary() string {
 	return fmt.Sprintf("boundary_%d", time.Now().UnixNano())
 }
 
-// encodeBase64 Base64编码，每行76个字符
-func encodeBase64(data []byte) string {
-	const lineLength = 76
-	encoded := make([]byte, 0, len(data)*4/3+4)
-
-	// Base64编码表
-	const base64Table = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
-
-	for i := 0; i < len(data); i += 3 {
-		var b1, b2, b3 byte
-		b1 = data[i]
-		if i+1 < len(data) {
-			b2 = data[i+1]
-		}
-		if i+2 < len(data) {
-			b3 = data[i+2]
-		}
-
-		// 编码4个字符
-		encoded = append(encoded, base64Table[b1>>2])
-		encoded = append(encoded, base64Table[((b1&0x03)<<4)|((b2&0xf0)>>4)])
+// lineWrapWriter 按固定长度插入CRLF换行
+type lineWrapWriter struct {
+	w          io.Writer
+	lineLength int
+	column     int
+}
 
-		if i+1 < len(data) {
-			encoded = append(encoded, base64Table[((b2&0x0f)<<2)|((b3&0xc0)>>6)])
-		} else {
-			encoded = append(encoded, '=')
+// Write 写入数据并在每行末尾插入换行
+func (l *lineWrapWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := l.lineLength - l.column
+		if chunk > len(p) {
+			chunk = len(p)
 		}
-
-		if i+2 < len(data) {
-			encoded = append(encoded, base64Table[b3&0x3f])
-		} else {
-			encoded = append(encoded, '=')
+		n, err := l.w.Write(p[:chunk])
+		written += n
+		l.column += n
+		if err != nil {
+			return written, err
 		}
+		p = p[chunk:]
 
-		// 每76个字符换行
-		if len(encoded)%lineLength == 0 {
-			encoded = append(encoded, '\r', '\n')
+		if l.column == l.lineLength {
+			if _, err := l.w.Write([]byte("\r\n")); err != nil {
+				return written, err
+			}
+			l.column = 0
 		}
 	}
+	return written, nil
+}
 
-	// 确保以换行结束
-	if len(encoded) > 0 && encoded[len(encoded)-1] != '\n' {
-		encoded = append(encoded, '\r', '\n')
+// finish 确保以换行结束
+func (l *lineWrapWriter) finish() error {
+	if l.column == 0 {
+		return nil
 	}
-
-	return string(encoded)
+	l.column = 0
+	_, err := l.w.Write([]byte("\r\n"))
+	return err
 }
 
 // encodeQuotedPrintable 对文本进行quoted-printable编码
@@ -0,0 +1,254 @@
+package providers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+)
+
+func TestWriteMessageStreamsWrappedBase64Attachment(t *testing.T) {
+	content := bytes.Repeat([]byte("attachment-data-"), 100)
+	message := &OutgoingMessage{
+		From:     &models.EmailAddress{Address: "sender@example.com"},
+		To:       []*models.EmailAddress{{Address: "receiver@example.com"}},
+		Subject:  "stream",
+		TextBody: "body",
+		Attachments: []*OutgoingAttachment{
+			{Filename: "data.bin", ContentType: "application/octet-stream", Content: bytes.NewReader(content)},
+		},
+	}
+
+	data, err := (&StandardSMTPClient{}).buildEmailData(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw := string(data)
+	start := strings.Index(raw, "Content-Transfer-Encoding: base64\r\n\r\n")
+	if start < 0 {
+		t.Fatalf("attachment part not found in:\n%s", raw)
+	}
+	body := raw[start+len("Content-Transfer-Encoding: base64\r\n\r\n"):]
+	body = body[:strings.Index(body, "\r\n--")]
+
+	for _, line := range strings.Split(body, "\r\n") {
+		if len(line) > 76 {
+			t.Fatalf("base64 line exceeds 76 characters: %d", len(line))
+		}
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(body, "\r\n", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded, content) {
+		t.Error("decoded attachment does not match the original content")
+	}
+}
+
+// fakeSMTPServer 简易SMTP服务器，记录收到的命令和邮件内容
+type fakeSMTPServer struct {
+	listener   net.Listener
+	extensions []string
+
+	mu        sync.Mutex
+	commands  []string
+	messages  []string
+	ehlo      string // EHLO命令携带的主机名
+	pipelined bool   // MAIL的响应发出前已收到RCPT
+}
+
+func newFakeSMTPServer(t *testing.T, extensions ...string) *fakeSMTPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := &fakeSMTPServer{listener: listener, extensions: extensions}
+	t.Cleanup(func() { listener.Close() })
+	go server.serve()
+	return server
+}
+
+func (s *fakeSMTPServer) config() SMTPClientConfig {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	return SMTPClientConfig{Host: "127.0.0.1", Port: addr.Port, Security: "NONE"}
+}
+
+func (s *fakeSMTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTPServer) record(command string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.commands = append(s.commands, command)
+}
+
+func (s *fakeSMTPServer) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := textproto.NewReader(bufio.NewReader(conn))
+	reply := func(lines ...string) { conn.Write([]byte(strings.Join(lines, "\r\n") + "\r\n")) }
+	rcptReply := func(line string) string {
+		if strings.Contains(line, "reject") {
+			return "550 no such user"
+		}
+		return "250 OK"
+	}
+
+	reply("220 fake ESMTP")
+	var body strings.Builder
+	for {
+		line, err := reader.ReadLine()
+		if err != nil {
+			return
+		}
+		s.record(strings.Fields(line)[0])
+		verb := strings.ToUpper(strings.Fields(line)[0])
+		switch verb {
+		case "EHLO":
+			s.mu.Lock()
+			s.ehlo = strings.TrimSpace(line[len("EHLO"):])
+			s.mu.Unlock()
+			lines := []string{"250-fake"}
+			for _, ext := range s.extensions {
+				lines = append(lines, "250-"+ext)
+			}
+			reply(append(lines, "250 SIZE 1000000")...)
+		case "MAIL":
+			// 短暂等待后续命令，已管道化发送的RCPT会在MAIL的响应之前到达
+			replies := []string{"250 OK"}
+			for {
+				conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+				next, err := reader.ReadLine()
+				conn.SetReadDeadline(time.Time{})
+				if err != nil {
+					break
+				}
+				s.record(strings.Fields(next)[0])
+				s.mu.Lock()
+				s.pipelined = true
+				s.mu.Unlock()
+				replies = append(replies, rcptReply(next))
+			}
+			reply(replies...)
+		case "RCPT":
+			reply(rcptReply(line))
+		case "DATA":
+			reply("354 go ahead")
+			lines, err := reader.ReadDotLines()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, strings.Join(lines, "\r\n"))
+			s.mu.Unlock()
+			reply("250 queued")
+		case "BDAT":
+			fields := strings.Fields(line)
+			size, _ := strconv.Atoi(fields[1])
+			chunk := make([]byte, size)
+			if _, err := io.ReadFull(reader.R, chunk); err != nil {
+				return
+			}
+			body.Write(chunk)
+			if len(fields) > 2 && fields[2] == "LAST" {
+				s.mu.Lock()
+				s.messages = append(s.messages, body.String())
+				s.mu.Unlock()
+				body.Reset()
+			}
+			reply("250 chunk accepted")
+		case "RSET":
+			body.Reset()
+			reply("250 reset")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 unrecognized")
+		}
+	}
+}
+
+func TestSMTPSessionReusePipeliningAndChunking(t *testing.T) {
+	server := newFakeSMTPServer(t, "PIPELINING", "CHUNKING")
+	client := NewStandardSMTPClient()
+	ctx := context.Background()
+	if err := client.Connect(ctx, server.config()); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer client.Disconnect()
+
+	first := []byte("Subject: one\r\n\r\nfirst\r\n")
+	if err := client.SendRawEmail(ctx, "me@example.com", []string{"a@example.org", "b@example.org"}, first); err != nil {
+		t.Fatalf("first send: %v", err)
+	}
+	// 收件人被拒绝后会话仍可继续使用
+	err := client.SendRawEmail(ctx, "me@example.com", []string{"reject@example.org"}, []byte("Subject: two\r\n\r\nx\r\n"))
+	if err == nil || !strings.Contains(err.Error(), "reject@example.org") {
+		t.Fatalf("expected recipient rejection, got %v", err)
+	}
+	third := []byte("Subject: three\r\n\r\nthird\r\n")
+	if err := client.SendRawEmail(ctx, "me@example.com", []string{"c@example.org"}, third); err != nil {
+		t.Fatalf("third send on reused session: %v", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if !server.pipelined {
+		t.Fatalf("expected MAIL and RCPT to be pipelined")
+	}
+	if len(server.messages) != 2 || server.messages[0] != string(first) || server.messages[1] != string(third) {
+		t.Fatalf("unexpected messages: %q", server.messages)
+	}
+	commands := strings.Join(server.commands, " ")
+	if strings.Count(commands, "EHLO") != 1 || strings.Contains(commands, "DATA") || !strings.Contains(commands, "RSET") {
+		t.Fatalf("unexpected command sequence: %s", commands)
+	}
+}
+
+func TestSMTPSessionFallsBackWithoutExtensions(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	client := NewStandardSMTPClient()
+	ctx := context.Background()
+	if err := client.Connect(ctx, server.config()); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer client.Disconnect()
+
+	for i := 0; i < 2; i++ {
+		if err := client.SendRawEmail(ctx, "me@example.com", []string{"a@example.org"}, []byte("Subject: plain\r\n\r\n.dot\r\n")); err != nil {
+			t.Fatalf("send %d: %v", i, err)
+		}
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.pipelined {
+		t.Fatalf("commands must not be pipelined when the server does not advertise PIPELINING")
+	}
+	if len(server.messages) != 2 || !strings.Contains(server.messages[0], "\r\n.dot") {
+		t.Fatalf("unexpected messages: %q", server.messages)
+	}
+	if strings.Count(strings.Join(server.commands, " "), "DATA") != 2 {
+		t.Fatalf("expected DATA for each message: %v", server.commands)
+	}
+}
@@ -0,0 +1,156 @@
+package providers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+
+	"firemail/internal/models"
+)
+
+// tlsPinPrefix 公钥指纹的前缀，格式与 curl --pinnedpubkey 和HPKP一致
+const tlsPinPrefix = "sha256/"
+
+// tlsVersions 支持设置的TLS最低版本
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TLSOptions 连接邮件服务器的TLS设置，同时用于IMAP、SMTP和EWS；nil表示使用默认设置
+type TLSOptions struct {
+	MinVersion         string   // 1.0、1.1、1.2 或 1.3，为空时使用Go的默认值
+	CACertPEM          string   // 额外信任的CA证书（PEM），用于使用私有CA的自建服务器
+	InsecureSkipVerify bool     // 跳过证书校验，仅用于测试环境
+	PinnedKeys         []string // 证书公钥指纹 sha256/<base64>，设置后证书链中必须有一个公钥匹配
+}
+
+// TLSOptionsForAccount 账户的TLS设置，全部为默认值时返回nil
+func TLSOptionsForAccount(account *models.EmailAccount) *TLSOptions {
+	if account == nil {
+		return nil
+	}
+	options := &TLSOptions{
+		MinVersion:         account.TLSMinVersion,
+		CACertPEM:          account.TLSCACert,
+		InsecureSkipVerify: account.TLSInsecureSkipVerify,
+		PinnedKeys:         account.GetTLSPinnedKeys(),
+	}
+	if options.MinVersion == "" && options.CACertPEM == "" && !options.InsecureSkipVerify && len(options.PinnedKeys) == 0 {
+		return nil
+	}
+	return options
+}
+
+// NormalizeTLSPin 把公钥指纹统一为 sha256/<base64>，也接受不带前缀的base64和64位十六进制
+func NormalizeTLSPin(pin string) (string, error) {
+	digest, err := decodeTLSPin(pin)
+	if err != nil {
+		return "", err
+	}
+	return tlsPinPrefix + base64.StdEncoding.EncodeToString(digest), nil
+}
+
+func decodeTLSPin(pin string) ([]byte, error) {
+	value := strings.TrimSpace(pin)
+	if len(value) >= len(tlsPinPrefix) && strings.EqualFold(value[:len(tlsPinPrefix)], tlsPinPrefix) {
+		value = value[len(tlsPinPrefix):]
+	}
+	if len(value) == hex.EncodedLen(sha256.Size) {
+		if digest, err := hex.DecodeString(value); err == nil {
+			return digest, nil
+		}
+	}
+	digest, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(digest) != sha256.Size {
+		return nil, fmt.Errorf("invalid certificate pin %q, expected sha256/<base64 SPKI digest>", pin)
+	}
+	return digest, nil
+}
+
+// Validate 检查设置是否有效，不建立连接
+func (o *TLSOptions) Validate() error {
+	_, err := o.config("")
+	return err
+}
+
+// Config 生成连接 serverName 使用的TLS配置。跳过证书校验时每次连接都记录警告
+func (o *TLSOptions) Config(serverName string) (*tls.Config, error) {
+	cfg, err := o.config(serverName)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.InsecureSkipVerify {
+		if cfg.VerifyPeerCertificate != nil {
+			log.Printf("⚠️ WARNING: TLS certificate chain verification is disabled for %s, only the pinned public keys are checked", serverName)
+		} else {
+			log.Printf("⚠️ WARNING: TLS certificate verification is DISABLED for %s, the connection can be intercepted without notice. Only use this in lab setups", serverName)
+		}
+	}
+	return cfg, nil
+}
+
+func (o *TLSOptions) config(serverName string) (*tls.Config, error) {
+	cfg := &tls.Config{ServerName: serverName}
+	if o == nil {
+		return cfg, nil
+	}
+
+	if o.MinVersion != "" {
+		version, ok := tlsVersions[o.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("invalid TLS minimum version %q, expected 1.0, 1.1, 1.2 or 1.3", o.MinVersion)
+		}
+		cfg.MinVersion = version
+	}
+
+	if strings.TrimSpace(o.CACertPEM) != "" {
+		roots, err := x509.SystemCertPool()
+		if err != nil || roots == nil {
+			roots = x509.NewCertPool()
+		}
+		if !roots.AppendCertsFromPEM([]byte(o.CACertPEM)) {
+			return nil, fmt.Errorf("no valid PEM certificates found in the CA bundle")
+		}
+		cfg.RootCAs = roots
+	}
+
+	cfg.InsecureSkipVerify = o.InsecureSkipVerify
+
+	if len(o.PinnedKeys) > 0 {
+		pins := make([][]byte, 0, len(o.PinnedKeys))
+		for _, pin := range o.PinnedKeys {
+			digest, err := decodeTLSPin(pin)
+			if err != nil {
+				return nil, err
+			}
+			pins = append(pins, digest)
+		}
+		// 在常规证书校验之后执行；跳过校验时是唯一的检查
+		cfg.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			for _, raw := range rawCerts {
+				cert, err := x509.ParseCertificate(raw)
+				if err != nil {
+					continue
+				}
+				digest := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+				for _, pin := range pins {
+					if bytes.Equal(digest[:], pin) {
+						return nil
+					}
+				}
+			}
+			return fmt.Errorf("certificate pin mismatch for %s: no certificate in the chain matches the pinned public keys", serverName)
+		}
+	}
+
+	return cfg, nil
+}
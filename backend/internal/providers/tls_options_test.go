@@ -0,0 +1,87 @@
+package providers
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"net"
+	"net/http/httptest"
+	"testing"
+
+	"firemail/internal/models"
+)
+
+func dialWithTLSOptions(t *testing.T, server *httptest.Server, options *TLSOptions) error {
+	t.Helper()
+	host, _, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := options.Config(host)
+	if err != nil {
+		t.Fatalf("Config() error = %v", err)
+	}
+	conn, err := tls.Dial("tcp", server.Listener.Addr().String(), cfg)
+	if err == nil {
+		conn.Close()
+	}
+	return err
+}
+
+func TestTLSOptionsConfig(t *testing.T) {
+	server := httptest.NewTLSServer(nil)
+	defer server.Close()
+
+	cert := server.Certificate()
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+	digest := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	pin := tlsPinPrefix + base64.StdEncoding.EncodeToString(digest[:])
+	otherPin := tlsPinPrefix + base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))
+
+	if err := dialWithTLSOptions(t, server, nil); err == nil {
+		t.Fatal("expected self-signed certificate to be rejected by default")
+	}
+	if err := dialWithTLSOptions(t, server, &TLSOptions{CACertPEM: caPEM}); err != nil {
+		t.Fatalf("custom CA bundle: %v", err)
+	}
+	if err := dialWithTLSOptions(t, server, &TLSOptions{InsecureSkipVerify: true}); err != nil {
+		t.Fatalf("insecure: %v", err)
+	}
+	if err := dialWithTLSOptions(t, server, &TLSOptions{CACertPEM: caPEM, PinnedKeys: []string{otherPin, pin}}); err != nil {
+		t.Fatalf("matching pin: %v", err)
+	}
+	if err := dialWithTLSOptions(t, server, &TLSOptions{InsecureSkipVerify: true, PinnedKeys: []string{otherPin}}); err == nil {
+		t.Fatal("expected pin mismatch to be rejected even when verification is skipped")
+	}
+	if err := dialWithTLSOptions(t, server, &TLSOptions{CACertPEM: caPEM, MinVersion: "1.3"}); err != nil {
+		t.Fatalf("TLS 1.3: %v", err)
+	}
+}
+
+func TestTLSOptionsValidate(t *testing.T) {
+	invalid := []*TLSOptions{
+		{MinVersion: "1.4"},
+		{CACertPEM: "not a certificate"},
+		{PinnedKeys: []string{"sha256/short"}},
+	}
+	for _, options := range invalid {
+		if err := options.Validate(); err == nil {
+			t.Errorf("Validate(%+v) expected error", options)
+		}
+	}
+
+	digest := sha256.Sum256([]byte("key"))
+	normalized, err := NormalizeTLSPin(hex.EncodeToString(digest[:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := tlsPinPrefix + base64.StdEncoding.EncodeToString(digest[:]); normalized != want {
+		t.Errorf("NormalizeTLSPin() = %q, want %q", normalized, want)
+	}
+
+	if options := TLSOptionsForAccount(&models.EmailAccount{}); options != nil {
+		t.Errorf("TLSOptionsForAccount() = %+v, want nil for default settings", options)
+	}
+}
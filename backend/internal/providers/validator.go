@@ -3,7 +3,6 @@ package providers
 import (
 	"context"
 	"fmt"
-	"net"
 	"regexp"
 	"strings"
 	"time"
@@ -177,7 +176,7 @@ func (v *ProviderValidator) validateDomainDNS(domain string, result *ValidationR
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	mxRecords, err := net.DefaultResolver.LookupMX(ctx, domain)
+	mxRecords, err := DefaultResolver().LookupMX(ctx, domain)
 	if err != nil {
 		v.addWarning(result, "email", "DNS_CHECK_FAILED",
 			fmt.Sprintf("Could not verify MX records for domain '%s': %v", domain, err))
@@ -0,0 +1,159 @@
+// Package sanitize 按白名单清理邮件HTML，用于在应用之外展示邮件内容
+package sanitize
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// ImageRewriter 决定img的src如何输出，返回false时丢弃该图片
+type ImageRewriter func(src string) (string, bool)
+
+// allowedElements 保留的元素
+var allowedElements = map[string]bool{
+	"a": true, "abbr": true, "address": true, "article": true, "b": true, "blockquote": true,
+	"br": true, "caption": true, "center": true, "cite": true, "code": true, "col": true,
+	"colgroup": true, "dd": true, "del": true, "div": true, "dl": true, "dt": true, "em": true,
+	"figcaption": true, "figure": true, "font": true, "footer": true, "h1": true, "h2": true,
+	"h3": true, "h4": true, "h5": true, "h6": true, "header": true, "hr": true, "i": true,
+	"img": true, "ins": true, "kbd": true, "li": true, "mark": true, "ol": true, "p": true,
+	"pre": true, "q": true, "s": true, "section": true, "small": true, "span": true,
+	"strike": true, "strong": true, "sub": true, "sup": true, "table": true, "tbody": true,
+	"td": true, "tfoot": true, "th": true, "thead": true, "time": true, "tr": true, "tt": true,
+	"u": true, "ul": true,
+}
+
+// droppedElements 连同内容一起丢弃的元素
+var droppedElements = map[string]bool{
+	"script": true, "style": true, "head": true, "title": true, "noscript": true,
+	"template": true, "iframe": true, "frame": true, "frameset": true, "object": true,
+	"embed": true, "applet": true, "svg": true, "math": true, "textarea": true,
+	"select": true, "button": true, "audio": true, "video": true,
+}
+
+// allowedAttributes 不含URL的展示类属性
+var allowedAttributes = map[string]bool{
+	"align": true, "alt": true, "bgcolor": true, "border": true, "cellpadding": true,
+	"cellspacing": true, "color": true, "colspan": true, "dir": true, "face": true,
+	"height": true, "lang": true, "rowspan": true, "size": true, "span": true, "start": true,
+	"title": true, "valign": true, "width": true,
+}
+
+// safeLinkSchemes 允许的链接协议
+var safeLinkSchemes = []string{"http://", "https://", "mailto:"}
+
+// HTML 清理HTML片段：只保留白名单内的元素和属性，丢弃脚本、样式、表单及事件属性，
+// 链接只允许http、https和mailto，图片地址交由rewrite决定，rewrite为nil时丢弃所有图片
+func HTML(input string, rewrite ImageRewriter) string {
+	var out strings.Builder
+	z := html.NewTokenizer(strings.NewReader(input))
+
+	skipTag := ""
+	skipDepth := 0
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			// io.EOF或解析错误都在此结束，已输出的内容均经过清理
+			return out.String()
+		}
+		token := z.Token()
+
+		if skipDepth > 0 {
+			switch {
+			case tt == html.StartTagToken && token.Data == skipTag:
+				skipDepth++
+			case tt == html.EndTagToken && token.Data == skipTag:
+				skipDepth--
+			}
+			continue
+		}
+
+		switch tt {
+		case html.TextToken:
+			out.WriteString(html.EscapeString(token.Data))
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if droppedElements[token.Data] {
+				if tt == html.StartTagToken {
+					skipTag = token.Data
+					skipDepth = 1
+				}
+				continue
+			}
+			if !allowedElements[token.Data] {
+				continue
+			}
+			attrs, ok := sanitizeAttributes(token, rewrite)
+			if !ok {
+				continue
+			}
+			out.WriteString("<" + token.Data + attrs)
+			if tt == html.SelfClosingTagToken {
+				out.WriteString(" /")
+			}
+			out.WriteString(">")
+
+		case html.EndTagToken:
+			if allowedElements[token.Data] {
+				out.WriteString("</" + token.Data + ">")
+			}
+		}
+	}
+}
+
+// sanitizeAttributes 过滤属性，返回false表示整个元素应被丢弃
+func sanitizeAttributes(token html.Token, rewrite ImageRewriter) (string, bool) {
+	var b strings.Builder
+	write := func(key, value string) {
+		b.WriteString(" " + key + `="` + html.EscapeString(value) + `"`)
+	}
+
+	for _, attr := range token.Attr {
+		key := strings.ToLower(attr.Key)
+		switch {
+		case token.Data == "a" && key == "href":
+			if href := strings.TrimSpace(attr.Val); isSafeLink(href) {
+				write("href", href)
+			}
+		case token.Data == "img" && key == "src":
+			// src在下方统一处理
+		case allowedAttributes[key]:
+			write(key, attr.Val)
+		}
+	}
+
+	switch token.Data {
+	case "a":
+		write("target", "_blank")
+		write("rel", "noopener noreferrer nofollow")
+	case "img":
+		src := ""
+		for _, attr := range token.Attr {
+			if strings.ToLower(attr.Key) == "src" {
+				src = strings.TrimSpace(attr.Val)
+			}
+		}
+		if rewrite == nil || src == "" {
+			return "", false
+		}
+		rewritten, ok := rewrite(src)
+		if !ok {
+			return "", false
+		}
+		write("src", rewritten)
+	}
+
+	return b.String(), true
+}
+
+// isSafeLink 链接是否使用允许的协议
+func isSafeLink(href string) bool {
+	lower := strings.ToLower(href)
+	for _, scheme := range safeLinkSchemes {
+		if strings.HasPrefix(lower, scheme) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package sanitize
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTMLRemovesActiveContent(t *testing.T) {
+	out := HTML(`<html><head><title>t</title><style>body{}</style></head><body>
+<script>alert(1)</script><p onclick="steal()" style="position:fixed" align="center">Hi <b>there</b></p>
+<iframe src="https://evil.example"><p>inner</p></iframe>
+<a href="javascript:alert(1)">bad</a> <a href="https://example.com/?a=1&b=2" onmouseover="x()">good</a>
+<form action="https://evil.example"><input name="password"></form></body></html>`, nil)
+
+	require.NotContains(t, out, "alert")
+	require.NotContains(t, out, "steal")
+	require.NotContains(t, out, "position")
+	require.NotContains(t, out, "inner")
+	require.NotContains(t, out, "evil")
+	require.NotContains(t, out, "<title>")
+	require.Contains(t, out, `<p align="center">Hi <b>there</b></p>`)
+	require.Contains(t, out, "<a target=\"_blank\" rel=\"noopener noreferrer nofollow\">bad</a>")
+	require.Contains(t, out, `<a href="https://example.com/?a=1&amp;b=2" target="_blank" rel="noopener noreferrer nofollow">good</a>`)
+}
+
+func TestHTMLRewritesImages(t *testing.T) {
+	rewrite := func(src string) (string, bool) {
+		if strings.HasPrefix(src, "cid:") {
+			return "/inline/" + strings.TrimPrefix(src, "cid:"), true
+		}
+		return "", false
+	}
+
+	out := HTML(`<img src="cid:logo" alt="Logo" onerror="x()"><img src="https://tracker.example/p.gif">`, rewrite)
+	require.Equal(t, `<img alt="Logo" src="/inline/logo">`, out)
+	require.Empty(t, HTML(`<img src="cid:logo">`, nil))
+}
+
+func TestHTMLEscapesText(t *testing.T) {
+	require.Equal(t, "&lt;not a tag&gt; &amp; text", HTML("&lt;not a tag&gt; &amp; text", nil))
+	require.Equal(t, "<p>nested <i>ok</i></p>", HTML("<p>nested <noscript><p>x</p></noscript><i>ok</i></p>", nil))
+}
+
+func TestTextExtractsVisibleText(t *testing.T) {
+	out := Text(`<html><head><style>p{}</style></head><body><script>alert(1)</script>
+<p>Hello&nbsp;<b>world</b></p><table><tr><td>a</td><td>b</td></tr></table></body></html>`)
+	require.Equal(t, "Hello world a b", out)
+}
+
+func TestRemoveTrackers(t *testing.T) {
+	input := `<p>Hi</p><img src="https://cdn.example.com/logo.png" width="120" height="40">` +
+		`<IMG SRC="https://news.example.com/o.gif" WIDTH="1" HEIGHT="1">` +
+		`<img src="//t.example.net/p.gif" style="width: 1px; height: 1px">` +
+		`<img src="https://x.example.org/a.png" style="display: none">` +
+		`<img src="https://mailtrack.io/trace/mail/abc.png">` +
+		`<img src="https://acme.us1.list-manage.com/track/open.php?u=1">` +
+		`<img src="cid:logo" width="1" height="1"><img src="data:image/gif;base64,R0lGOD" width="1" height="1">`
+
+	out, removed := RemoveTrackers(input)
+	require.Equal(t, 5, removed)
+	require.Equal(t, `<p>Hi</p><img src="https://cdn.example.com/logo.png" width="120" height="40">`+
+		`<img src="cid:logo" width="1" height="1"><img src="data:image/gif;base64,R0lGOD" width="1" height="1">`, out)
+
+	clean := `<div style="color:red">No <b>trackers</b> &amp; unchanged</div>`
+	out, removed = RemoveTrackers(clean)
+	require.Zero(t, removed)
+	require.Equal(t, clean, out)
+}
+
+func TestPlainTextKeepsStructure(t *testing.T) {
+	out := PlainText(`<html><head><style>p{}</style></head><body><h2>Quarterly   update</h2>
+<p>Hello <b>team</b>,<br>see the <a href="https://example.com/report">full report</a> or mail <a href="mailto:a@example.com">a@example.com</a>.</p>
+<ul><li>One</li><li>Two<ol start="3"><li>Nested</li></ol></li></ul>
+<blockquote><p>Quoted</p><p>Second</p></blockquote>
+<table><tr><th>Name</th><th>Qty</th></tr><tr><td>Apple</td><td>3</td></tr></table>
+<p><img src="logo.png" alt="Logo"> <i>thanks</i></p><script>alert(1)</script></body></html>`)
+
+	require.Equal(t, `## Quarterly update
+
+Hello **team**,
+see the full report (https://example.com/report) or mail a@example.com.
+
+- One
+- Two
+  3. Nested
+
+> Quoted
+>
+> Second
+
+Name | Qty
+Apple | 3
+
+[Logo] _thanks_`, out)
+}
@@ -0,0 +1,356 @@
+package sanitize
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// PlainText 把HTML正文转换为可读的纯文本，用作邮件的text/plain备选部分。
+// 保留段落和换行，标题、列表、引用、强调按类似Markdown的写法输出，链接地址写在文字后的括号中
+func PlainText(input string) string {
+	w := &plainTextWriter{}
+	z := html.NewTokenizer(strings.NewReader(input))
+
+	skipTag := ""
+	skipDepth := 0
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return w.String()
+		}
+		token := z.Token()
+
+		if skipDepth > 0 {
+			switch {
+			case tt == html.StartTagToken && token.Data == skipTag:
+				skipDepth++
+			case tt == html.EndTagToken && token.Data == skipTag:
+				skipDepth--
+			}
+			continue
+		}
+
+		switch tt {
+		case html.TextToken:
+			w.text(token.Data)
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if droppedElements[token.Data] {
+				if tt == html.StartTagToken {
+					skipTag = token.Data
+					skipDepth = 1
+				}
+				continue
+			}
+			w.start(token, tt == html.SelfClosingTagToken)
+		case html.EndTagToken:
+			w.end(token.Data)
+		}
+	}
+}
+
+// plainTextList 正在输出的列表
+type plainTextList struct {
+	ordered bool
+	next    int
+}
+
+// plainTextLink 正在输出的链接
+type plainTextLink struct {
+	href string
+	text strings.Builder
+}
+
+// plainTextWriter 按块级元素管理换行，行首输出引用和列表缩进
+type plainTextWriter struct {
+	out strings.Builder
+
+	// 下一段文字前需要的换行数，1为换行，2为空一行；breakDepth为请求换行时的前缀层数
+	pendingBreak int
+	breakDepth   int
+	pendingSpace bool
+	lineStart    bool
+	afterMark    bool
+
+	// 行首前缀，列表项首行用marker替换最后一级前缀
+	prefixes []string
+	marker   string
+
+	lists     []*plainTextList
+	links     []*plainTextLink
+	preDepth  int
+	cellIndex int
+}
+
+func (w *plainTextWriter) block(n int) {
+	if w.pendingBreak == 0 || w.breakDepth > len(w.prefixes) {
+		w.breakDepth = len(w.prefixes)
+	}
+	if n > w.pendingBreak {
+		w.pendingBreak = n
+	}
+	w.pendingSpace = false
+}
+
+// write 输出一段不含换行的文字，必要时先补换行和行首前缀
+func (w *plainTextWriter) write(s string) {
+	if s == "" {
+		return
+	}
+	if w.out.Len() == 0 {
+		w.pendingBreak = 0
+		w.lineStart = true
+	}
+	for i := 0; i < w.pendingBreak; i++ {
+		if i > 0 {
+			// 引用块内的空行也保留引用前缀，引用块前后的空行不带前缀
+			w.out.WriteString(strings.TrimRight(strings.Join(w.prefixes[:w.breakDepth], ""), " "))
+		}
+		w.out.WriteString("\n")
+		w.lineStart = true
+	}
+	w.pendingBreak = 0
+	if w.lineStart {
+		w.out.WriteString(w.linePrefix())
+		w.lineStart = false
+		w.pendingSpace = false
+	} else if w.pendingSpace && !w.afterMark {
+		w.out.WriteString(" ")
+	}
+	w.pendingSpace = false
+	w.afterMark = false
+	w.out.WriteString(s)
+	for _, link := range w.links {
+		link.text.WriteString(s)
+	}
+}
+
+func (w *plainTextWriter) linePrefix() string {
+	if w.marker == "" || len(w.prefixes) == 0 {
+		return strings.Join(w.prefixes, "")
+	}
+	prefix := strings.Join(w.prefixes[:len(w.prefixes)-1], "") + w.marker
+	w.marker = ""
+	return prefix
+}
+
+func (w *plainTextWriter) text(data string) {
+	if w.preDepth > 0 {
+		for i, line := range strings.Split(data, "\n") {
+			if i > 0 {
+				w.block(1)
+			}
+			w.write(strings.TrimRight(line, "\r"))
+		}
+		return
+	}
+
+	if data != "" && isHTMLSpace(data[0]) {
+		w.pendingSpace = true
+	}
+	for _, word := range strings.Fields(data) {
+		w.write(word)
+		w.pendingSpace = true
+	}
+	if data != "" && !isHTMLSpace(data[len(data)-1]) {
+		w.pendingSpace = false
+	}
+}
+
+func (w *plainTextWriter) start(token html.Token, selfClosing bool) {
+	switch token.Data {
+	case "br":
+		// 连续的<br>保留为空行
+		w.block(w.pendingBreak + 1)
+	case "p", "table", "dl":
+		w.block(2)
+	case "div", "section", "article", "header", "footer", "tr", "dt", "dd", "center":
+		w.block(1)
+		if token.Data == "tr" {
+			w.cellIndex = 0
+		}
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		w.block(2)
+		level, _ := strconv.Atoi(token.Data[1:])
+		w.write(strings.Repeat("#", level))
+		w.pendingSpace = true
+	case "hr":
+		w.block(2)
+		w.write("---")
+		w.block(2)
+	case "blockquote":
+		w.block(2)
+		w.prefixes = append(w.prefixes, "> ")
+	case "pre":
+		w.block(2)
+		w.prefixes = append(w.prefixes, "    ")
+		w.preDepth++
+	case "ul", "ol":
+		if len(w.lists) == 0 {
+			w.block(2)
+		} else {
+			w.block(1)
+		}
+		list := &plainTextList{ordered: token.Data == "ol", next: 1}
+		if start, err := strconv.Atoi(attribute(token, "start")); err == nil && list.ordered {
+			list.next = start
+		}
+		w.lists = append(w.lists, list)
+	case "li":
+		w.block(1)
+		marker := "- "
+		if len(w.lists) > 0 {
+			if list := w.lists[len(w.lists)-1]; list.ordered {
+				marker = strconv.Itoa(list.next) + ". "
+				list.next++
+			}
+		}
+		w.prefixes = append(w.prefixes, strings.Repeat(" ", len(marker)))
+		w.marker = marker
+	case "td", "th":
+		if w.cellIndex > 0 {
+			w.pendingSpace = true
+			w.write("|")
+			w.pendingSpace = true
+		}
+		w.cellIndex++
+	case "b", "strong":
+		w.inlineMark("**")
+	case "i", "em":
+		w.inlineMark("_")
+	case "code":
+		if w.preDepth == 0 {
+			w.inlineMark("`")
+		}
+	case "img":
+		if alt := strings.TrimSpace(attribute(token, "alt")); alt != "" {
+			w.write("[" + alt + "]")
+		}
+	case "a":
+		if !selfClosing {
+			w.links = append(w.links, &plainTextLink{href: attribute(token, "href")})
+		}
+	}
+}
+
+func (w *plainTextWriter) end(tag string) {
+	switch tag {
+	case "p", "table", "dl", "h1", "h2", "h3", "h4", "h5", "h6":
+		w.block(2)
+	case "div", "section", "article", "header", "footer", "tr", "dt", "dd", "center":
+		w.block(1)
+	case "blockquote":
+		w.popPrefix()
+		w.block(2)
+	case "pre":
+		if w.preDepth > 0 {
+			w.preDepth--
+			w.popPrefix()
+		}
+		w.block(2)
+	case "ul", "ol":
+		if len(w.lists) > 0 {
+			w.lists = w.lists[:len(w.lists)-1]
+		}
+		if len(w.lists) == 0 {
+			w.block(2)
+		} else {
+			w.block(1)
+		}
+	case "li":
+		w.popPrefix()
+		w.marker = ""
+		w.block(1)
+	case "b", "strong":
+		w.closeInlineMark("**")
+	case "i", "em":
+		w.closeInlineMark("_")
+	case "code":
+		if w.preDepth == 0 {
+			w.closeInlineMark("`")
+		}
+	case "a":
+		if len(w.links) == 0 {
+			return
+		}
+		link := w.links[len(w.links)-1]
+		w.links = w.links[:len(w.links)-1]
+		if href := linkTarget(link.href, link.text.String()); href != "" {
+			w.pendingSpace = true
+			w.write("(" + href + ")")
+		}
+	}
+}
+
+// inlineMark 输出强调等行内标记，标记紧贴后面的文字
+func (w *plainTextWriter) inlineMark(mark string) {
+	w.write(mark)
+	w.afterMark = true
+}
+
+func (w *plainTextWriter) closeInlineMark(mark string) {
+	space := w.pendingSpace
+	w.pendingSpace = false
+	w.write(mark)
+	w.pendingSpace = space
+}
+
+func (w *plainTextWriter) popPrefix() {
+	if len(w.prefixes) > 0 {
+		w.prefixes = w.prefixes[:len(w.prefixes)-1]
+	}
+	if w.breakDepth > len(w.prefixes) {
+		w.breakDepth = len(w.prefixes)
+	}
+}
+
+// String 返回转换结果，去掉行尾空白和多余的空行
+func (w *plainTextWriter) String() string {
+	lines := strings.Split(w.out.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	text := strings.Join(lines, "\n")
+	text = excessBlankLines.ReplaceAllString(text, "\n\n")
+	return strings.TrimSpace(text)
+}
+
+var excessBlankLines = regexp.MustCompile(`\n{3,}`)
+
+// linkTarget 链接后需要附上的地址；地址与文字相同、页内锚点和脚本链接不附地址
+func linkTarget(href, text string) string {
+	href = strings.TrimSpace(href)
+	lower := strings.ToLower(href)
+	switch {
+	case href == "", strings.HasPrefix(href, "#"), strings.HasPrefix(lower, "javascript:"):
+		return ""
+	case strings.HasPrefix(lower, "mailto:"):
+		address := href[len("mailto:"):]
+		if index := strings.Index(address, "?"); index >= 0 {
+			address = address[:index]
+		}
+		if strings.EqualFold(address, strings.TrimSpace(text)) {
+			return ""
+		}
+	}
+	text = strings.TrimSpace(text)
+	if text == href || strings.TrimSuffix(text, "/") == strings.TrimSuffix(href, "/") {
+		return ""
+	}
+	return href
+}
+
+func attribute(token html.Token, key string) string {
+	for _, attr := range token.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+func isHTMLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
@@ -0,0 +1,48 @@
+package sanitize
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Text 提取HTML中可见的文本，丢弃脚本、样式等不展示的内容，连续空白合并为一个空格
+func Text(input string) string {
+	var out strings.Builder
+	z := html.NewTokenizer(strings.NewReader(input))
+
+	skipTag := ""
+	skipDepth := 0
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return strings.Join(strings.Fields(out.String()), " ")
+		}
+		token := z.Token()
+
+		if skipDepth > 0 {
+			switch {
+			case tt == html.StartTagToken && token.Data == skipTag:
+				skipDepth++
+			case tt == html.EndTagToken && token.Data == skipTag:
+				skipDepth--
+			}
+			continue
+		}
+
+		switch tt {
+		case html.TextToken:
+			out.WriteString(token.Data)
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if droppedElements[token.Data] && tt == html.StartTagToken {
+				skipTag = token.Data
+				skipDepth = 1
+				continue
+			}
+			// 元素之间补空格，避免相邻单元格或段落的文字粘连
+			out.WriteString(" ")
+		case html.EndTagToken:
+			out.WriteString(" ")
+		}
+	}
+}
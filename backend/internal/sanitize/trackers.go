@@ -0,0 +1,118 @@
+package sanitize
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// trackerDomains 常见的邮件打开追踪服务，匹配域名本身及其子域名
+var trackerDomains = []string{
+	"mailtrack.io", "mltrk.io", "getnotify.com", "bananatag.com", "yesware.com",
+	"mailfoogae.appspot.com", "r.superhuman.com", "pixel.app.returnpath.net",
+}
+
+// trackerPaths 群发平台的打开追踪路径（Mailchimp、SendGrid）
+var trackerPaths = []string{"/track/open", "/wf/open"}
+
+// RemoveTrackers 去掉HTML中的追踪像素：1x1或隐藏的远程图片，以及指向已知追踪服务的图片。
+// 其余内容原样保留，返回处理后的HTML和去掉的图片数量
+func RemoveTrackers(input string) (string, int) {
+	var out strings.Builder
+	z := html.NewTokenizer(strings.NewReader(input))
+
+	removed := 0
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return out.String(), removed
+		}
+		// Token会就地改写缓冲区，先保存原始内容
+		raw := string(z.Raw())
+		if tt == html.StartTagToken || tt == html.SelfClosingTagToken {
+			if token := z.Token(); token.Data == "img" && isTrackingImage(token) {
+				removed++
+				continue
+			}
+		}
+		out.WriteString(raw)
+	}
+}
+
+// isTrackingImage 图片是否为追踪像素，只判断会发起网络请求的远程图片
+func isTrackingImage(token html.Token) bool {
+	var src, width, height, style string
+	for _, attr := range token.Attr {
+		switch strings.ToLower(attr.Key) {
+		case "src":
+			src = strings.TrimSpace(attr.Val)
+		case "width":
+			width = attr.Val
+		case "height":
+			height = attr.Val
+		case "style":
+			style = strings.ToLower(strings.Join(strings.Fields(attr.Val), ""))
+		}
+	}
+
+	lower := strings.ToLower(src)
+	if !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://") && !strings.HasPrefix(lower, "//") {
+		return false
+	}
+	if IsTrackerURL(src) {
+		return true
+	}
+	if isTinyDimension(width) && isTinyDimension(height) {
+		return true
+	}
+	if strings.Contains(style, "display:none") || strings.Contains(style, "visibility:hidden") {
+		return true
+	}
+	return isTinyDimension(styleValue(style, "width")) && isTinyDimension(styleValue(style, "height"))
+}
+
+// IsTrackerURL 地址是否指向已知的追踪服务
+func IsTrackerURL(src string) bool {
+	if strings.HasPrefix(src, "//") {
+		src = "https:" + src
+	}
+	parsed, err := url.Parse(src)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(parsed.Hostname())
+	for _, domain := range trackerDomains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	path := strings.ToLower(parsed.Path)
+	for _, trackerPath := range trackerPaths {
+		if strings.Contains(path, trackerPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTinyDimension 尺寸是否不超过1像素，未设置时返回false
+func isTinyDimension(value string) bool {
+	value = strings.TrimSuffix(strings.TrimSpace(strings.ToLower(value)), "px")
+	if value == "" {
+		return false
+	}
+	size, err := strconv.ParseFloat(value, 64)
+	return err == nil && size <= 1
+}
+
+// styleValue 取出已去掉空白的内联样式中某个属性的值
+func styleValue(style, property string) string {
+	for _, declaration := range strings.Split(style, ";") {
+		if name, value, ok := strings.Cut(declaration, ":"); ok && name == property {
+			return strings.TrimSuffix(value, "!important")
+		}
+	}
+	return ""
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"firemail/internal/models"
+)
+
+// 账户显示设置的长度上限
+const (
+	maxAccountNicknameLength = 100
+	maxAccountInitialsLength = 3
+	maxAccountEmojiRunes     = 8 // 组合表情由多个码点组成
+)
+
+var accountColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// applyAccountDisplay 校验并设置账户的显示设置，传空字符串表示清除
+func applyAccountDisplay(account *models.EmailAccount, req *UpdateEmailAccountRequest) error {
+	if req.Nickname != nil {
+		nickname := strings.TrimSpace(*req.Nickname)
+		if utf8.RuneCountInString(nickname) > maxAccountNicknameLength {
+			return fmt.Errorf("nickname must be at most %d characters", maxAccountNicknameLength)
+		}
+		account.Nickname = nickname
+	}
+
+	if req.Color != nil {
+		color := strings.TrimSpace(*req.Color)
+		if color != "" && !accountColorPattern.MatchString(color) {
+			return fmt.Errorf("invalid color: %s, expected #RRGGBB", color)
+		}
+		account.Color = strings.ToLower(color)
+	}
+
+	if req.AvatarEmoji != nil {
+		emoji := strings.TrimSpace(*req.AvatarEmoji)
+		if emoji != "" && !validAvatarEmoji(emoji) {
+			return fmt.Errorf("invalid avatar_emoji: %s", emoji)
+		}
+		account.AvatarEmoji = emoji
+	}
+
+	if req.AvatarInitials != nil {
+		initials := strings.TrimSpace(*req.AvatarInitials)
+		if initials != "" && !validAvatarInitials(initials) {
+			return fmt.Errorf("avatar_initials must be 1 to %d letters or digits", maxAccountInitialsLength)
+		}
+		account.AvatarInitials = strings.ToUpper(initials)
+	}
+
+	if req.DisplayOrder != nil {
+		if *req.DisplayOrder < 0 {
+			return fmt.Errorf("display_order must not be negative")
+		}
+		account.DisplayOrder = *req.DisplayOrder
+	}
+	return nil
+}
+
+// validAvatarEmoji 头像表情不能以字母开头，不含空白和控制字符
+func validAvatarEmoji(emoji string) bool {
+	if !utf8.ValidString(emoji) || utf8.RuneCountInString(emoji) > maxAccountEmojiRunes {
+		return false
+	}
+	first, _ := utf8.DecodeRuneInString(emoji)
+	if unicode.IsLetter(first) {
+		return false
+	}
+	for _, r := range emoji {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func validAvatarInitials(initials string) bool {
+	if utf8.RuneCountInString(initials) > maxAccountInitialsLength {
+		return false
+	}
+	for _, r := range initials {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// ReorderEmailAccounts 按给定顺序设置账户的显示顺序，未列出的账户按原顺序排在后面
+func (s *EmailServiceImpl) ReorderEmailAccounts(ctx context.Context, userID uint, order []uint) ([]*models.EmailAccount, error) {
+	accounts, err := s.GetEmailAccounts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	owned := make(map[uint]bool, len(accounts))
+	for _, account := range accounts {
+		owned[account.ID] = true
+	}
+	listed := make(map[uint]bool, len(order))
+	for _, id := range order {
+		if !owned[id] {
+			return nil, fmt.Errorf("invalid account id: %d", id)
+		}
+		if listed[id] {
+			return nil, fmt.Errorf("duplicate account id: %d", id)
+		}
+		listed[id] = true
+	}
+
+	ordered := append([]uint{}, order...)
+	for _, account := range accounts {
+		if !listed[account.ID] {
+			ordered = append(ordered, account.ID)
+		}
+	}
+
+	tx := s.db.WithContext(ctx).Begin()
+	for i, id := range ordered {
+		if err := tx.Model(&models.EmailAccount{}).
+			Where("id = ? AND user_id = ?", id, userID).
+			Update("display_order", i+1).Error; err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to update display order: %w", err)
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, fmt.Errorf("failed to commit display order: %w", err)
+	}
+
+	return s.GetEmailAccounts(ctx, userID)
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateAccountDisplaySettings(t *testing.T) {
+	env := setupEmailGroupServiceTestEnv(t)
+	defaultGroup := env.ensureDefaultGroup(t)
+	ctx := context.Background()
+	account := env.createAccountRecord(t, "display@qq.com", &defaultGroup.ID)
+
+	nickname, color, emoji, initials, order := " 工作 ", "#1A73E8", "📮", "wk", 3
+	updated, err := env.service.UpdateEmailAccount(ctx, env.user.ID, account.ID, &UpdateEmailAccountRequest{
+		Nickname:       &nickname,
+		Color:          &color,
+		AvatarEmoji:    &emoji,
+		AvatarInitials: &initials,
+		DisplayOrder:   &order,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "工作", updated.Nickname)
+	require.Equal(t, "#1a73e8", updated.Color)
+	require.Equal(t, "📮", updated.AvatarEmoji)
+	require.Equal(t, "WK", updated.AvatarInitials)
+	require.Equal(t, 3, updated.DisplayOrder)
+
+	badColor, badEmoji, badInitials, badOrder := "blue", "ab", "ABCD", -1
+	for _, req := range []*UpdateEmailAccountRequest{
+		{Color: &badColor},
+		{AvatarEmoji: &badEmoji},
+		{AvatarInitials: &badInitials},
+		{DisplayOrder: &badOrder},
+	} {
+		_, err := env.service.UpdateEmailAccount(ctx, env.user.ID, account.ID, req)
+		require.Error(t, err)
+	}
+
+	// 空字符串清除设置
+	empty := ""
+	updated, err = env.service.UpdateEmailAccount(ctx, env.user.ID, account.ID, &UpdateEmailAccountRequest{Color: &empty, AvatarEmoji: &empty})
+	require.NoError(t, err)
+	require.Empty(t, updated.Color)
+	require.Empty(t, updated.AvatarEmoji)
+	require.Equal(t, "WK", updated.AvatarInitials)
+}
+
+func TestReorderEmailAccounts(t *testing.T) {
+	env := setupEmailGroupServiceTestEnv(t)
+	defaultGroup := env.ensureDefaultGroup(t)
+	ctx := context.Background()
+	first := env.createAccountRecord(t, "first@qq.com", &defaultGroup.ID)
+	second := env.createAccountRecord(t, "second@qq.com", &defaultGroup.ID)
+	third := env.createAccountRecord(t, "third@qq.com", &defaultGroup.ID)
+
+	accounts, err := env.service.ReorderEmailAccounts(ctx, env.user.ID, []uint{second.ID, first.ID})
+	require.NoError(t, err)
+	require.Len(t, accounts, 3)
+	require.Equal(t, []uint{second.ID, first.ID, third.ID}, []uint{accounts[0].ID, accounts[1].ID, accounts[2].ID})
+	require.Equal(t, 3, accounts[2].DisplayOrder)
+
+	_, err = env.service.ReorderEmailAccounts(ctx, env.user.ID, []uint{first.ID, first.ID})
+	require.Error(t, err)
+	_, err = env.service.ReorderEmailAccounts(ctx, env.user.ID+1, []uint{first.ID})
+	require.Error(t, err)
+}
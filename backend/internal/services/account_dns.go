@@ -0,0 +1,19 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"firemail/internal/models"
+	"firemail/internal/providers"
+)
+
+// applyAccountDNSResolver 校验并设置账户的DNS解析器，空字符串表示使用全局设置
+func applyAccountDNSResolver(account *models.EmailAccount, spec string) error {
+	spec = strings.TrimSpace(spec)
+	if err := providers.ValidateDNSResolver(spec); err != nil {
+		return fmt.Errorf("invalid dns_resolver: %w", err)
+	}
+	account.DNSResolver = spec
+	return nil
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"firemail/internal/models"
+	"firemail/internal/providers"
+)
+
+// AccountTLSRequest 账户的TLS设置，同时用于IMAP和SMTP，未提供的字段保持不变
+type AccountTLSRequest struct {
+	TLSMinVersion         *string   `json:"tls_min_version"`          // 1.0、1.1、1.2 或 1.3，空字符串表示使用默认值
+	TLSCACert             *string   `json:"tls_ca_cert"`              // 额外信任的CA证书（PEM），空字符串表示清除
+	TLSInsecureSkipVerify *bool     `json:"tls_insecure_skip_verify"` // 跳过证书校验，仅用于测试环境
+	TLSPinnedKeys         *[]string `json:"tls_pinned_keys"`          // 证书公钥指纹 sha256/<base64>，传空数组表示清除
+}
+
+// applyAccountTLS 校验并设置账户的TLS设置
+func applyAccountTLS(account *models.EmailAccount, req *AccountTLSRequest) error {
+	if req.TLSMinVersion != nil {
+		account.TLSMinVersion = strings.TrimSpace(*req.TLSMinVersion)
+	}
+	if req.TLSCACert != nil {
+		account.TLSCACert = strings.TrimSpace(*req.TLSCACert)
+	}
+	if req.TLSInsecureSkipVerify != nil {
+		account.TLSInsecureSkipVerify = *req.TLSInsecureSkipVerify
+	}
+	if req.TLSPinnedKeys != nil {
+		pins := make([]string, 0, len(*req.TLSPinnedKeys))
+		seen := make(map[string]bool)
+		for _, pin := range *req.TLSPinnedKeys {
+			normalized, err := providers.NormalizeTLSPin(pin)
+			if err != nil {
+				return err
+			}
+			if !seen[normalized] {
+				seen[normalized] = true
+				pins = append(pins, normalized)
+			}
+		}
+		if err := account.SetTLSPinnedKeys(pins); err != nil {
+			return fmt.Errorf("failed to save tls_pinned_keys: %w", err)
+		}
+	}
+
+	if err := providers.TLSOptionsForAccount(account).Validate(); err != nil {
+		return err
+	}
+	account.TLSWarning = account.TLSSecurityWarning()
+	if account.TLSWarning != "" {
+		log.Printf("⚠️ WARNING: account %s: %s", account.Email, account.TLSWarning)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateAccountTLSSettings(t *testing.T) {
+	env := setupEmailGroupServiceTestEnv(t)
+	defaultGroup := env.ensureDefaultGroup(t)
+	ctx := context.Background()
+	account := env.createAccountRecord(t, "tls@qq.com", &defaultGroup.ID)
+
+	first, second := sha256.Sum256([]byte("first")), sha256.Sum256([]byte("second"))
+	minVersion, insecure := "1.2", true
+	pins := []string{hex.EncodeToString(first[:]), "sha256/" + base64.StdEncoding.EncodeToString(second[:]), hex.EncodeToString(first[:])}
+	updated, err := env.service.UpdateEmailAccount(ctx, env.user.ID, account.ID, &UpdateEmailAccountRequest{
+		AccountTLSRequest: AccountTLSRequest{
+			TLSMinVersion:         &minVersion,
+			TLSInsecureSkipVerify: &insecure,
+			TLSPinnedKeys:         &pins,
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "1.2", updated.TLSMinVersion)
+	require.Len(t, updated.GetTLSPinnedKeys(), 2)
+	require.Contains(t, updated.TLSWarning, "pinned")
+
+	// 读取账户时同样返回警告
+	var stored models.EmailAccount
+	require.NoError(t, env.db.First(&stored, account.ID).Error)
+	require.True(t, stored.TLSInsecureSkipVerify)
+	require.NotEmpty(t, stored.TLSWarning)
+
+	badVersion, badCA, badPins := "1.5", "-----BEGIN CERTIFICATE-----", []string{"sha256/abc"}
+	for _, req := range []AccountTLSRequest{
+		{TLSMinVersion: &badVersion},
+		{TLSCACert: &badCA},
+		{TLSPinnedKeys: &badPins},
+	} {
+		_, err := env.service.UpdateEmailAccount(ctx, env.user.ID, account.ID, &UpdateEmailAccountRequest{AccountTLSRequest: req})
+		require.Error(t, err)
+	}
+
+	secure, noPins := false, []string{}
+	updated, err = env.service.UpdateEmailAccount(ctx, env.user.ID, account.ID, &UpdateEmailAccountRequest{
+		AccountTLSRequest: AccountTLSRequest{TLSInsecureSkipVerify: &secure, TLSPinnedKeys: &noPins},
+	})
+	require.NoError(t, err)
+	require.Empty(t, updated.TLSWarning)
+	require.Empty(t, updated.TLSPinnedKeys)
+}
@@ -0,0 +1,614 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"firemail/internal/database/writequeue"
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 统计分析的时间粒度
+const (
+	AnalyticsIntervalDay   = "day"
+	AnalyticsIntervalWeek  = "week"
+	AnalyticsIntervalMonth = "month"
+)
+
+const (
+	// 未指定时间范围时统计最近30天
+	defaultAnalyticsRange = 30 * 24 * time.Hour
+	// 时间范围上限，避免按天统计时生成过多数据点
+	maxAnalyticsRange = 3 * 366 * 24 * time.Hour
+
+	defaultAnalyticsLimit = 10
+	maxAnalyticsLimit     = 100
+
+	// 回复时间统计最多采样的回复邮件数
+	maxResponseTimeSamples = 500
+	// 只在该时间窗口内查找被回复的原邮件
+	responseTimeLookback = 30 * 24 * time.Hour
+)
+
+// ownAddressCondition 发件人为账户本身（即发出的邮件），需要关联email_accounts表
+const ownAddressCondition = "(LOWER(emails.from_address) = LOWER(email_accounts.email) OR " +
+	"LOWER(emails.from_address) LIKE '%<' || LOWER(email_accounts.email) || '>')"
+
+// AnalyticsQuery 统计分析查询参数，时间使用RFC3339格式
+type AnalyticsQuery struct {
+	AccountID *uint      `form:"account_id" json:"account_id,omitempty"`
+	From      *time.Time `form:"from" json:"from,omitempty"`         // 默认为30天前
+	To        *time.Time `form:"to" json:"to,omitempty"`             // 默认为当前时间
+	Interval  string     `form:"interval" json:"interval,omitempty"` // day、week、month，默认day
+	TZ        string     `form:"tz" json:"tz,omitempty"`             // IANA时区，用于按天和按小时分组，默认UTC
+	Limit     int        `form:"limit" json:"limit,omitempty"`       // 排行榜条数，默认10，最多100
+}
+
+// RebuildVolumeStatsRequest 重建收发统计请求，未指定账户时重建全部账户
+type RebuildVolumeStatsRequest struct {
+	AccountID *uint `json:"account_id,omitempty"`
+}
+
+// AnalyticsVolumePoint 一个时间段内收到和发出的邮件数
+type AnalyticsVolumePoint struct {
+	Period   string `json:"period"` // 日期、周起始日期或月份
+	Received int    `json:"received"`
+	Sent     int    `json:"sent"`
+}
+
+// AnalyticsHourActivity 一天中某个小时的收发邮件数
+type AnalyticsHourActivity struct {
+	Hour     int `json:"hour"`
+	Received int `json:"received"`
+	Sent     int `json:"sent"`
+}
+
+// AnalyticsWeekdayActivity 一周中某天的收发邮件数，0为周日
+type AnalyticsWeekdayActivity struct {
+	Weekday  int `json:"weekday"`
+	Received int `json:"received"`
+	Sent     int `json:"sent"`
+}
+
+// AnalyticsBusiestHours 按小时和星期汇总的收发邮件数
+type AnalyticsBusiestHours struct {
+	TZ       string                     `json:"tz"`
+	Hours    []AnalyticsHourActivity    `json:"hours"`
+	Weekdays []AnalyticsWeekdayActivity `json:"weekdays"`
+}
+
+// AnalyticsRecipient 发出邮件的收件人统计
+type AnalyticsRecipient struct {
+	Address string    `json:"address"`
+	Name    string    `json:"name,omitempty"`
+	Emails  int       `json:"emails"`
+	LastAt  time.Time `json:"last_at"`
+}
+
+// AnalyticsResponseTimes 回复邮件所用时间的统计
+type AnalyticsResponseTimes struct {
+	Samples        int   `json:"samples"`
+	AverageSeconds int64 `json:"average_seconds"`
+	MedianSeconds  int64 `json:"median_seconds"`
+	WithinHour     int   `json:"within_hour"`
+	WithinDay      int   `json:"within_day"`
+}
+
+// AnalyticsService 邮件统计分析服务接口
+type AnalyticsService interface {
+	// GetVolume 按时间段统计收发邮件数
+	GetVolume(ctx context.Context, userID uint, query *AnalyticsQuery) ([]AnalyticsVolumePoint, error)
+
+	// GetBusiestHours 按小时和星期统计收发邮件数
+	GetBusiestHours(ctx context.Context, userID uint, query *AnalyticsQuery) (*AnalyticsBusiestHours, error)
+
+	// GetTopSenders 来信最多的发件人
+	GetTopSenders(ctx context.Context, userID uint, query *AnalyticsQuery) ([]SenderVolume, error)
+
+	// GetTopRecipients 发信最多的收件人
+	GetTopRecipients(ctx context.Context, userID uint, query *AnalyticsQuery) ([]AnalyticsRecipient, error)
+
+	// GetResponseTimes 统计回复邮件所用的时间
+	GetResponseTimes(ctx context.Context, userID uint, query *AnalyticsQuery) (*AnalyticsResponseTimes, error)
+
+	// GetOutboundMessages 发出邮件及其是否、何时收到回复
+	GetOutboundMessages(ctx context.Context, userID uint, query *AnalyticsQuery, unreplied bool) ([]AnalyticsOutboundMessage, error)
+
+	// GetOutboundAccounts 按账户统计发出邮件的回复率和回复用时
+	GetOutboundAccounts(ctx context.Context, userID uint, query *AnalyticsQuery) ([]AnalyticsOutboundAccount, error)
+
+	// GetOutboundContacts 按联系人统计发出邮件的回复率和回复用时
+	GetOutboundContacts(ctx context.Context, userID uint, query *AnalyticsQuery) ([]AnalyticsOutboundContact, error)
+
+	// RebuildVolumeStats 根据现有邮件重新计算收发统计
+	RebuildVolumeStats(ctx context.Context, userID uint, accountID *uint) error
+}
+
+// AnalyticsServiceImpl 邮件统计分析服务实现
+type AnalyticsServiceImpl struct {
+	db *gorm.DB
+}
+
+// NewAnalyticsService 创建邮件统计分析服务
+func NewAnalyticsService(db *gorm.DB) AnalyticsService {
+	return &AnalyticsServiceImpl{db: db}
+}
+
+// recordEmailVolume 在同步事务中累加新邮件所在小时的收发统计
+func recordEmailVolume(tx *gorm.DB, account *models.EmailAccount, email *models.Email) error {
+	if email.Date.IsZero() {
+		return nil
+	}
+
+	stat := models.EmailVolumeStat{
+		UserID:    account.UserID,
+		AccountID: account.ID,
+		Hour:      email.Date.Unix() / 3600,
+	}
+	if isSentByAccount(email.From, account.Email) {
+		stat.Sent = 1
+	} else {
+		stat.Received = 1
+	}
+
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "account_id"}, {Name: "hour"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"received": gorm.Expr("received + ?", stat.Received),
+			"sent":     gorm.Expr("sent + ?", stat.Sent),
+		}),
+	}).Create(&stat).Error
+}
+
+// isSentByAccount 发件人是否为账户本身
+func isSentByAccount(from, accountEmail string) bool {
+	address := parseEmailAddress(from)
+	return address != nil && isOwnEmailAddress(address.Address, accountEmail)
+}
+
+// analyticsRange 解析查询的时间范围和时区
+type analyticsRange struct {
+	from     time.Time
+	to       time.Time
+	location *time.Location
+	limit    int
+}
+
+func resolveAnalyticsQuery(query *AnalyticsQuery) (*analyticsRange, error) {
+	r := &analyticsRange{location: time.UTC, to: time.Now(), limit: query.Limit}
+	if query.To != nil {
+		r.to = *query.To
+	}
+	r.from = r.to.Add(-defaultAnalyticsRange)
+	if query.From != nil {
+		r.from = *query.From
+	}
+	if !r.from.Before(r.to) {
+		return nil, fmt.Errorf("from must be before to")
+	}
+	if r.to.Sub(r.from) > maxAnalyticsRange {
+		return nil, fmt.Errorf("date range must not exceed 3 years")
+	}
+
+	if query.TZ != "" {
+		location, err := time.LoadLocation(query.TZ)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone: %s", query.TZ)
+		}
+		r.location = location
+	}
+
+	if r.limit <= 0 {
+		r.limit = defaultAnalyticsLimit
+	}
+	if r.limit > maxAnalyticsLimit {
+		r.limit = maxAnalyticsLimit
+	}
+	return r, nil
+}
+
+// volumeStats 查询时间范围内的小时统计
+func (s *AnalyticsServiceImpl) volumeStats(ctx context.Context, userID uint, accountID *uint, r *analyticsRange) ([]models.EmailVolumeStat, error) {
+	query := s.db.WithContext(ctx).
+		Where("user_id = ? AND hour >= ? AND hour <= ?", userID, r.from.Unix()/3600, r.to.Unix()/3600)
+	if accountID != nil {
+		query = query.Where("account_id = ?", *accountID)
+	}
+
+	var stats []models.EmailVolumeStat
+	if err := query.Find(&stats).Error; err != nil {
+		return nil, fmt.Errorf("failed to load volume stats: %w", err)
+	}
+	return stats, nil
+}
+
+// periodStart 返回时间所在统计周期的起点（按周统计时以周一为起点）
+func periodStart(t time.Time, interval string) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch interval {
+	case AnalyticsIntervalWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case AnalyticsIntervalMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	default:
+		return day
+	}
+}
+
+func nextPeriod(t time.Time, interval string) time.Time {
+	switch interval {
+	case AnalyticsIntervalWeek:
+		return t.AddDate(0, 0, 7)
+	case AnalyticsIntervalMonth:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+func periodLabel(t time.Time, interval string) string {
+	if interval == AnalyticsIntervalMonth {
+		return t.Format("2006-01")
+	}
+	return t.Format("2006-01-02")
+}
+
+// GetVolume 按时间段统计收发邮件数，没有邮件的时间段返回0
+func (s *AnalyticsServiceImpl) GetVolume(ctx context.Context, userID uint, query *AnalyticsQuery) ([]AnalyticsVolumePoint, error) {
+	interval := query.Interval
+	if interval == "" {
+		interval = AnalyticsIntervalDay
+	}
+	if interval != AnalyticsIntervalDay && interval != AnalyticsIntervalWeek && interval != AnalyticsIntervalMonth {
+		return nil, fmt.Errorf("interval must be one of day, week, month")
+	}
+
+	r, err := resolveAnalyticsQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.volumeStats(ctx, userID, query.AccountID, r)
+	if err != nil {
+		return nil, err
+	}
+
+	var points []AnalyticsVolumePoint
+	index := make(map[string]int)
+	end := r.to.In(r.location)
+	for period := periodStart(r.from.In(r.location), interval); !period.After(end); period = nextPeriod(period, interval) {
+		label := periodLabel(period, interval)
+		index[label] = len(points)
+		points = append(points, AnalyticsVolumePoint{Period: label})
+	}
+
+	for _, stat := range stats {
+		label := periodLabel(periodStart(time.Unix(stat.Hour*3600, 0).In(r.location), interval), interval)
+		if i, ok := index[label]; ok {
+			points[i].Received += stat.Received
+			points[i].Sent += stat.Sent
+		}
+	}
+	return points, nil
+}
+
+// GetBusiestHours 按本地时间的小时和星期汇总收发邮件数
+func (s *AnalyticsServiceImpl) GetBusiestHours(ctx context.Context, userID uint, query *AnalyticsQuery) (*AnalyticsBusiestHours, error) {
+	r, err := resolveAnalyticsQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.volumeStats(ctx, userID, query.AccountID, r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AnalyticsBusiestHours{
+		TZ:       r.location.String(),
+		Hours:    make([]AnalyticsHourActivity, 24),
+		Weekdays: make([]AnalyticsWeekdayActivity, 7),
+	}
+	for i := range result.Hours {
+		result.Hours[i].Hour = i
+	}
+	for i := range result.Weekdays {
+		result.Weekdays[i].Weekday = i
+	}
+
+	for _, stat := range stats {
+		local := time.Unix(stat.Hour*3600, 0).In(r.location)
+		hour := &result.Hours[local.Hour()]
+		hour.Received += stat.Received
+		hour.Sent += stat.Sent
+		weekday := &result.Weekdays[int(local.Weekday())]
+		weekday.Received += stat.Received
+		weekday.Sent += stat.Sent
+	}
+	return result, nil
+}
+
+// analyticsEmails 时间范围内的邮件，关联账户表以区分收发
+func (s *AnalyticsServiceImpl) analyticsEmails(ctx context.Context, userID uint, accountID *uint, r *analyticsRange) *gorm.DB {
+	query := s.db.WithContext(ctx).Model(&models.Email{}).
+		Joins("JOIN email_accounts ON emails.account_id = email_accounts.id").
+		Where("emails.user_id = ? AND emails.is_deleted = ? AND emails.date >= ? AND emails.date <= ?", userID, false, r.from, r.to)
+	if accountID != nil {
+		query = query.Where("emails.account_id = ?", *accountID)
+	}
+	return query
+}
+
+// GetTopSenders 统计收到邮件最多的发件人
+func (s *AnalyticsServiceImpl) GetTopSenders(ctx context.Context, userID uint, query *AnalyticsQuery) ([]SenderVolume, error) {
+	r, err := resolveAnalyticsQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	senders, err := senderVolumes(s.analyticsEmails(ctx, userID, query.AccountID, r).Where("NOT " + ownAddressCondition))
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(senders, func(i, j int) bool {
+		return senders[i].Emails > senders[j].Emails
+	})
+	if len(senders) > r.limit {
+		senders = senders[:r.limit]
+	}
+	return senders, nil
+}
+
+// GetTopRecipients 统计发出邮件最多的收件人（包括抄送）
+func (s *AnalyticsServiceImpl) GetTopRecipients(ctx context.Context, userID uint, query *AnalyticsQuery) ([]AnalyticsRecipient, error) {
+	r, err := resolveAnalyticsQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var sent []struct {
+		ToAddresses string
+		CcAddresses string
+		Date        time.Time
+	}
+	if err := s.analyticsEmails(ctx, userID, query.AccountID, r).
+		Where(ownAddressCondition).
+		Select("emails.to_addresses, emails.cc_addresses, emails.date").
+		Scan(&sent).Error; err != nil {
+		return nil, fmt.Errorf("failed to load sent emails: %w", err)
+	}
+
+	byAddress := make(map[string]*AnalyticsRecipient)
+	for _, email := range sent {
+		seen := make(map[string]bool)
+		for _, list := range []string{email.ToAddresses, email.CcAddresses} {
+			addresses, err := parseEmailAddressList(list)
+			if err != nil {
+				continue
+			}
+			for _, address := range addresses {
+				if address == nil || address.Address == "" {
+					continue
+				}
+				key := strings.ToLower(strings.TrimSpace(address.Address))
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+
+				recipient, ok := byAddress[key]
+				if !ok {
+					recipient = &AnalyticsRecipient{Address: key}
+					byAddress[key] = recipient
+				}
+				recipient.Emails++
+				if recipient.Name == "" {
+					recipient.Name = address.Name
+				}
+				if email.Date.After(recipient.LastAt) {
+					recipient.LastAt = email.Date
+				}
+			}
+		}
+	}
+
+	recipients := make([]AnalyticsRecipient, 0, len(byAddress))
+	for _, recipient := range byAddress {
+		recipients = append(recipients, *recipient)
+	}
+	sort.Slice(recipients, func(i, j int) bool {
+		if recipients[i].Emails != recipients[j].Emails {
+			return recipients[i].Emails > recipients[j].Emails
+		}
+		return recipients[i].Address < recipients[j].Address
+	})
+	if len(recipients) > r.limit {
+		recipients = recipients[:r.limit]
+	}
+	return recipients, nil
+}
+
+// replySubjectPrefixes 回复和转发邮件的主题前缀（小写）
+var replySubjectPrefixes = []string{"re:", "回复:", "回复：", "答复:", "答复：", "aw:", "sv:"}
+var forwardSubjectPrefixes = []string{"fw:", "fwd:", "转发:", "转发："}
+
+// normalizeReplySubject 去掉主题中的回复/转发前缀，返回原始主题以及是否为回复
+func normalizeReplySubject(subject string) (string, bool) {
+	subject = strings.TrimSpace(subject)
+	isReply := false
+	for {
+		lower := strings.ToLower(subject)
+		trimmed := false
+		for _, prefix := range replySubjectPrefixes {
+			if strings.HasPrefix(lower, prefix) {
+				subject = strings.TrimSpace(subject[len(prefix):])
+				isReply = true
+				trimmed = true
+				break
+			}
+		}
+		for _, prefix := range forwardSubjectPrefixes {
+			if !trimmed && strings.HasPrefix(lower, prefix) {
+				subject = strings.TrimSpace(subject[len(prefix):])
+				trimmed = true
+				break
+			}
+		}
+		if !trimmed {
+			return subject, isReply
+		}
+	}
+}
+
+// GetResponseTimes 统计回复邮件所用的时间。邮件未保存In-Reply-To，因此按主题匹配：
+// 发出的回复邮件对应同一账户中此前收到的、主题相同且发件人为回复收件人的最近一封邮件
+func (s *AnalyticsServiceImpl) GetResponseTimes(ctx context.Context, userID uint, query *AnalyticsQuery) (*AnalyticsResponseTimes, error) {
+	r, err := resolveAnalyticsQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var replies []models.Email
+	if err := s.analyticsEmails(ctx, userID, query.AccountID, r).
+		Where(ownAddressCondition).
+		Where("LOWER(emails.subject) LIKE ? OR emails.subject LIKE ? OR emails.subject LIKE ? OR LOWER(emails.subject) LIKE ? OR LOWER(emails.subject) LIKE ?",
+			"re:%", "回复%", "答复%", "aw:%", "sv:%").
+		Select("emails.id, emails.account_id, emails.subject, emails.to_addresses, emails.date").
+		Order("emails.date DESC").
+		Limit(maxResponseTimeSamples).
+		Find(&replies).Error; err != nil {
+		return nil, fmt.Errorf("failed to load replies: %w", err)
+	}
+
+	var durations []time.Duration
+	for _, reply := range replies {
+		subject, isReply := normalizeReplySubject(reply.Subject)
+		if !isReply || subject == "" {
+			continue
+		}
+		recipients, err := parseEmailAddressList(reply.To)
+		if err != nil || len(recipients) == 0 {
+			continue
+		}
+		recipientAddresses := make(map[string]bool, len(recipients))
+		for _, recipient := range recipients {
+			if recipient != nil {
+				recipientAddresses[strings.ToLower(strings.TrimSpace(recipient.Address))] = true
+			}
+		}
+
+		var candidates []models.Email
+		if err := s.db.WithContext(ctx).Model(&models.Email{}).
+			Joins("JOIN email_accounts ON emails.account_id = email_accounts.id").
+			Where("emails.account_id = ? AND emails.date < ? AND emails.date >= ?", reply.AccountID, reply.Date, reply.Date.Add(-responseTimeLookback)).
+			Where("emails.subject LIKE ? ESCAPE '\\'", "%"+escapeLike(subject)).
+			Where("NOT " + ownAddressCondition).
+			Select("emails.id, emails.subject, emails.from_address, emails.date").
+			Order("emails.date DESC").
+			Limit(20).
+			Find(&candidates).Error; err != nil {
+			return nil, fmt.Errorf("failed to match replied emails: %w", err)
+		}
+
+		for _, candidate := range candidates {
+			candidateSubject, _ := normalizeReplySubject(candidate.Subject)
+			from := parseEmailAddress(candidate.From)
+			if candidateSubject != subject || from == nil || !recipientAddresses[strings.ToLower(from.Address)] {
+				continue
+			}
+			durations = append(durations, reply.Date.Sub(candidate.Date))
+			break
+		}
+	}
+
+	result := &AnalyticsResponseTimes{Samples: len(durations)}
+	if len(durations) == 0 {
+		return result, nil
+	}
+
+	for _, duration := range durations {
+		if duration <= time.Hour {
+			result.WithinHour++
+		}
+		if duration <= 24*time.Hour {
+			result.WithinDay++
+		}
+	}
+	result.AverageSeconds, result.MedianSeconds = durationSummary(durations)
+	return result, nil
+}
+
+// escapeLike 转义LIKE模式中的特殊字符
+func escapeLike(value string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(value)
+}
+
+// RebuildVolumeStats 根据现有邮件重新计算收发统计，用于修复统计偏差
+func (s *AnalyticsServiceImpl) RebuildVolumeStats(ctx context.Context, userID uint, accountID *uint) error {
+	accountQuery := s.db.WithContext(ctx).Where("user_id = ?", userID)
+	if accountID != nil {
+		accountQuery = accountQuery.Where("id = ?", *accountID)
+	}
+	var accounts []models.EmailAccount
+	if err := accountQuery.Find(&accounts).Error; err != nil {
+		return fmt.Errorf("failed to load accounts: %w", err)
+	}
+	if accountID != nil && len(accounts) == 0 {
+		return fmt.Errorf("account not found")
+	}
+
+	for i := range accounts {
+		account := &accounts[i]
+		stats := make(map[int64]*models.EmailVolumeStat)
+
+		var emails []models.Email
+		err := s.db.WithContext(ctx).Model(&models.Email{}).
+			Select("id, from_address, date").
+			Where("account_id = ?", account.ID).
+			FindInBatches(&emails, 1000, func(tx *gorm.DB, batch int) error {
+				for _, email := range emails {
+					if email.Date.IsZero() {
+						continue
+					}
+					hour := email.Date.Unix() / 3600
+					stat, ok := stats[hour]
+					if !ok {
+						stat = &models.EmailVolumeStat{UserID: account.UserID, AccountID: account.ID, Hour: hour}
+						stats[hour] = stat
+					}
+					if isSentByAccount(email.From, account.Email) {
+						stat.Sent++
+					} else {
+						stat.Received++
+					}
+				}
+				return nil
+			}).Error
+		if err != nil {
+			return fmt.Errorf("failed to scan emails: %w", err)
+		}
+
+		rows := make([]*models.EmailVolumeStat, 0, len(stats))
+		for _, stat := range stats {
+			rows = append(rows, stat)
+		}
+		err = writequeue.Transaction(ctx, s.db.WithContext(ctx), func(tx *gorm.DB) error {
+			if err := tx.Where("account_id = ?", account.ID).Delete(&models.EmailVolumeStat{}).Error; err != nil {
+				return err
+			}
+			if len(rows) == 0 {
+				return nil
+			}
+			return tx.CreateInBatches(rows, 500).Error
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save volume stats: %w", err)
+		}
+	}
+	return nil
+}
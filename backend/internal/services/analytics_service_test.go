@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+// setupAnalyticsTestEnv 创建带收发统计表的测试环境
+func setupAnalyticsTestEnv(t *testing.T) (*emailStateServiceTestEnv, *AnalyticsServiceImpl) {
+	t.Helper()
+
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.EmailVolumeStat{}))
+	return env, NewAnalyticsService(env.db).(*AnalyticsServiceImpl)
+}
+
+func TestRecordEmailVolumeAndRebuild(t *testing.T) {
+	env, service := setupAnalyticsTestEnv(t)
+	ctx := context.Background()
+
+	date := time.Date(2024, 3, 4, 9, 30, 0, 0, time.UTC) // 周一
+	received := &models.Email{From: "Alice <alice@example.com>", Date: date}
+	sent := &models.Email{From: "Me <Tester@example.com>", Date: date.Add(10 * time.Minute)}
+	require.NoError(t, recordEmailVolume(env.db, env.account, received))
+	require.NoError(t, recordEmailVolume(env.db, env.account, received))
+	require.NoError(t, recordEmailVolume(env.db, env.account, sent))
+
+	var stats []models.EmailVolumeStat
+	require.NoError(t, env.db.Find(&stats).Error)
+	require.Len(t, stats, 1)
+	require.Equal(t, 2, stats[0].Received)
+	require.Equal(t, 1, stats[0].Sent)
+	require.Equal(t, env.user.ID, stats[0].UserID)
+
+	from := date.AddDate(0, 0, -1)
+	to := date.AddDate(0, 0, 1)
+	activity, err := service.GetBusiestHours(ctx, env.user.ID, &AnalyticsQuery{From: &from, To: &to, TZ: "Asia/Shanghai"})
+	require.NoError(t, err)
+	require.Equal(t, 2, activity.Hours[17].Received)
+	require.Equal(t, 1, activity.Weekdays[int(time.Monday)].Sent)
+
+	// 统计表与实际邮件不一致时，重建以邮件为准
+	email := env.createEmail(t, env.inbox, 1, "hello", false, false)
+	require.NoError(t, env.db.Model(email).Update("date", date).Error)
+	require.NoError(t, service.RebuildVolumeStats(ctx, env.user.ID, nil))
+
+	require.NoError(t, env.db.Find(&stats).Error)
+	require.Len(t, stats, 1)
+	require.Equal(t, 1, stats[0].Received)
+	require.Zero(t, stats[0].Sent)
+
+	missing := env.account.ID + 100
+	require.EqualError(t, service.RebuildVolumeStats(ctx, env.user.ID, &missing), "account not found")
+}
+
+func TestAnalyticsVolumeFillsEmptyPeriods(t *testing.T) {
+	env, service := setupAnalyticsTestEnv(t)
+	ctx := context.Background()
+
+	day := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, recordEmailVolume(env.db, env.account, &models.Email{From: "a@example.com", Date: day}))
+	require.NoError(t, recordEmailVolume(env.db, env.account, &models.Email{From: "tester@example.com", Date: day.AddDate(0, 0, 2)}))
+
+	from := day.AddDate(0, 0, -1)
+	to := day.AddDate(0, 0, 3)
+	points, err := service.GetVolume(ctx, env.user.ID, &AnalyticsQuery{From: &from, To: &to})
+	require.NoError(t, err)
+	require.Len(t, points, 5)
+	require.Equal(t, AnalyticsVolumePoint{Period: "2024-03-04", Received: 1}, points[1])
+	require.Equal(t, AnalyticsVolumePoint{Period: "2024-03-05"}, points[2])
+	require.Equal(t, AnalyticsVolumePoint{Period: "2024-03-06", Sent: 1}, points[3])
+
+	weeks, err := service.GetVolume(ctx, env.user.ID, &AnalyticsQuery{From: &from, To: &to, Interval: AnalyticsIntervalWeek})
+	require.NoError(t, err)
+	require.Equal(t, []AnalyticsVolumePoint{{Period: "2024-02-26"}, {Period: "2024-03-04", Received: 1, Sent: 1}}, weeks)
+
+	_, err = service.GetVolume(ctx, env.user.ID+1, &AnalyticsQuery{From: &to, To: &from})
+	require.EqualError(t, err, "from must be before to")
+	_, err = service.GetVolume(ctx, env.user.ID, &AnalyticsQuery{Interval: "year"})
+	require.Error(t, err)
+	_, err = service.GetVolume(ctx, env.user.ID, &AnalyticsQuery{TZ: "Mars/Base"})
+	require.EqualError(t, err, "invalid time zone: Mars/Base")
+}
+
+func TestAnalyticsTopContactsAndResponseTimes(t *testing.T) {
+	env, service := setupAnalyticsTestEnv(t)
+	ctx := context.Background()
+
+	base := time.Now().Add(-48 * time.Hour)
+	question := env.createEmail(t, env.inbox, 1, "Budget", false, false)
+	require.NoError(t, env.db.Model(question).Updates(map[string]interface{}{"from_address": "Bob <bob@example.com>", "date": base}).Error)
+	other := env.createEmail(t, env.inbox, 2, "Lunch", false, false)
+	require.NoError(t, env.db.Model(other).Updates(map[string]interface{}{"from_address": "bob@example.com", "date": base.Add(time.Hour)}).Error)
+	env.createEmail(t, env.inbox, 3, "Hi", false, false)
+
+	reply := env.createEmail(t, env.work, 4, "Re: Budget", true, false)
+	require.NoError(t, env.db.Model(reply).Updates(map[string]interface{}{
+		"from_address": "tester@example.com",
+		"to_addresses": `[{"name":"Bob","address":"Bob@example.com"}]`,
+		"cc_addresses": `[{"address":"carol@example.com"}]`,
+		"date":         base.Add(30 * time.Minute),
+	}).Error)
+	followUp := env.createEmail(t, env.work, 5, "Plan", true, false)
+	require.NoError(t, env.db.Model(followUp).Updates(map[string]interface{}{
+		"from_address": "Me <tester@example.com>",
+		"to_addresses": `[{"address":"bob@example.com"}]`,
+	}).Error)
+
+	senders, err := service.GetTopSenders(ctx, env.user.ID, &AnalyticsQuery{Limit: 5})
+	require.NoError(t, err)
+	require.Len(t, senders, 2)
+	require.Equal(t, "bob@example.com", senders[0].Sender)
+	require.EqualValues(t, 2, senders[0].Emails)
+	require.Equal(t, "sender@example.com", senders[1].Sender)
+
+	recipients, err := service.GetTopRecipients(ctx, env.user.ID, &AnalyticsQuery{})
+	require.NoError(t, err)
+	require.Len(t, recipients, 2)
+	require.Equal(t, "bob@example.com", recipients[0].Address)
+	require.Equal(t, 2, recipients[0].Emails)
+	require.Equal(t, "Bob", recipients[0].Name)
+	require.Equal(t, "carol@example.com", recipients[1].Address)
+
+	times, err := service.GetResponseTimes(ctx, env.user.ID, &AnalyticsQuery{})
+	require.NoError(t, err)
+	require.Equal(t, 1, times.Samples)
+	require.EqualValues(t, 30*60, times.MedianSeconds)
+	require.Equal(t, 1, times.WithinHour)
+	require.Equal(t, 1, times.WithinDay)
+
+	subject, isReply := normalizeReplySubject("RE: 回复：Fwd: Budget")
+	require.Equal(t, "Budget", subject)
+	require.True(t, isReply)
+}
+
+func TestOutboundReplyRates(t *testing.T) {
+	env, service := setupAnalyticsTestEnv(t)
+	ctx := context.Background()
+
+	base := time.Now().Add(-72 * time.Hour)
+	send := func(uid uint32, subject, threadID, to string, at time.Time) *models.Email {
+		email := env.createEmail(t, env.work, uid, subject, true, false)
+		require.NoError(t, env.db.Model(email).Updates(map[string]interface{}{
+			"from_address": "Me <tester@example.com>",
+			"to_addresses": to,
+			"thread_id":    threadID,
+			"date":         at,
+		}).Error)
+		email.Date = at
+		return email
+	}
+	receive := func(uid uint32, subject, threadID, from string, at time.Time) *models.Email {
+		email := env.createEmail(t, env.inbox, uid, subject, false, false)
+		require.NoError(t, env.db.Model(email).Updates(map[string]interface{}{
+			"from_address": from,
+			"thread_id":    threadID,
+			"date":         at,
+		}).Error)
+		return email
+	}
+
+	// 报价发给Bob和Carol，Carol两小时后回复
+	quote := send(1, "Quote", "", `[{"name":"Bob","address":"Bob@example.com"},{"address":"carol@example.com"}]`, base)
+	reply := receive(2, "Re: Quote", quote.MessageID, "Carol <carol@example.com>", base.Add(2*time.Hour))
+	// 跟进邮件发给Bob，没有回复
+	send(3, "Re: Quote", quote.MessageID, `[{"address":"bob@example.com"}]`, base.Add(3*time.Hour))
+	// 发给Bob的另一封邮件，跟进之后才收到回复，回复只计入跟进邮件
+	intro := send(4, "Intro", "", `[{"address":"bob@example.com"}]`, base.Add(4*time.Hour))
+	send(5, "Re: Intro", intro.MessageID, `[{"address":"bob@example.com"}]`, base.Add(5*time.Hour))
+	receive(6, "Re: Intro", intro.MessageID, "bob@example.com", base.Add(9*time.Hour))
+
+	messages, err := service.GetOutboundMessages(ctx, env.user.ID, &AnalyticsQuery{}, false)
+	require.NoError(t, err)
+	require.Len(t, messages, 4)
+	require.Equal(t, "Re: Intro", messages[0].Subject)
+	require.EqualValues(t, 4*3600, *messages[0].ResponseSeconds)
+	require.Equal(t, "bob@example.com", messages[0].RepliedBy)
+	require.Nil(t, messages[1].RepliedAt)
+	require.Equal(t, quote.ID, messages[3].EmailID)
+	require.Equal(t, []string{"bob@example.com", "carol@example.com"}, messages[3].Recipients)
+	require.Equal(t, reply.ID, *messages[3].ReplyEmailID)
+
+	unreplied, err := service.GetOutboundMessages(ctx, env.user.ID, &AnalyticsQuery{}, true)
+	require.NoError(t, err)
+	require.Len(t, unreplied, 2)
+
+	accounts, err := service.GetOutboundAccounts(ctx, env.user.ID, &AnalyticsQuery{})
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	require.Equal(t, 4, accounts[0].Sent)
+	require.Equal(t, 2, accounts[0].Replied)
+	require.InDelta(t, 0.5, accounts[0].ReplyRate, 0.001)
+	require.EqualValues(t, 3*3600, accounts[0].MedianResponseSeconds)
+
+	contacts, err := service.GetOutboundContacts(ctx, env.user.ID, &AnalyticsQuery{})
+	require.NoError(t, err)
+	require.Len(t, contacts, 2)
+	require.Equal(t, "bob@example.com", contacts[0].Address)
+	require.Equal(t, "Bob", contacts[0].Name)
+	require.Equal(t, 4, contacts[0].Sent)
+	require.Equal(t, 1, contacts[0].Replied)
+	require.EqualValues(t, 4*3600, contacts[0].MedianResponseSeconds)
+	require.Equal(t, "carol@example.com", contacts[1].Address)
+	require.Equal(t, 1, contacts[1].Replied)
+	require.NotNil(t, contacts[1].LastRepliedAt)
+}
@@ -0,0 +1,286 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"firemail/internal/models"
+)
+
+// 附件类型筛选
+const (
+	AttachmentTypeImage    = "image"
+	AttachmentTypeDocument = "document"
+	AttachmentTypeAudio    = "audio"
+	AttachmentTypeVideo    = "video"
+	AttachmentTypeArchive  = "archive"
+	AttachmentTypeOther    = "other"
+)
+
+// MaxZipAttachments 单次打包下载的最大附件数
+const MaxZipAttachments = 100
+
+// attachmentTypePatterns 各附件类型匹配的Content-Type（LIKE模式）
+var attachmentTypePatterns = map[string][]string{
+	AttachmentTypeImage: {"image/%"},
+	AttachmentTypeDocument: {
+		"application/pdf", "application/msword", "application/rtf", "text/%",
+		"application/vnd.ms-%", "application/vnd.openxmlformats-officedocument.%", "application/vnd.oasis.opendocument.%",
+	},
+	AttachmentTypeAudio: {"audio/%"},
+	AttachmentTypeVideo: {"video/%"},
+	AttachmentTypeArchive: {
+		"application/zip", "application/x-zip-compressed", "application/x-rar-compressed", "application/vnd.rar",
+		"application/x-7z-compressed", "application/gzip", "application/x-gzip", "application/x-tar",
+	},
+}
+
+// ListAttachmentsRequest 跨邮件的附件列表请求
+type ListAttachmentsRequest struct {
+	AccountID     *uint      `form:"account_id" json:"account_id,omitempty"`
+	Type          string     `form:"type" json:"type,omitempty"` // image、document、audio、video、archive、other
+	Since         *time.Time `form:"since" json:"since,omitempty"`
+	Until         *time.Time `form:"until" json:"until,omitempty"`
+	MinSize       int64      `form:"min_size" json:"min_size,omitempty"` // 字节
+	MaxSize       int64      `form:"max_size" json:"max_size,omitempty"`
+	Filename      string     `form:"filename" json:"filename,omitempty"` // 文件名包含的文字
+	IncludeInline bool       `form:"include_inline" json:"include_inline,omitempty"`
+	Page          int        `form:"page" json:"page,omitempty"`
+	PageSize      int        `form:"page_size" json:"page_size,omitempty"`
+}
+
+// AttachmentListItem 附件列表项，附带所属邮件的概要
+type AttachmentListItem struct {
+	ID           uint      `json:"id"`
+	Filename     string    `json:"filename"`
+	ContentType  string    `json:"content_type"`
+	Size         int64     `json:"size"`
+	IsDownloaded bool      `json:"is_downloaded"`
+	IsInline     bool      `json:"is_inline"`
+	EmailID      uint      `json:"email_id"`
+	AccountID    uint      `json:"account_id"`
+	Subject      string    `json:"subject"`
+	From         string    `json:"from"`
+	Date         time.Time `json:"date"`
+}
+
+// AttachmentListResponse 附件列表响应
+type AttachmentListResponse struct {
+	Attachments []AttachmentListItem `json:"attachments"`
+	Total       int64                `json:"total"`
+	Page        int                  `json:"page"`
+	PageSize    int                  `json:"page_size"`
+	TotalPages  int                  `json:"total_pages"`
+}
+
+// DownloadAttachmentsZipRequest 打包下载附件请求
+type DownloadAttachmentsZipRequest struct {
+	AttachmentIDs []uint `json:"attachment_ids" binding:"required,min=1,max=100"`
+}
+
+// attachmentTypeCondition 生成附件类型的查询条件，other为不属于其他任何类型
+func attachmentTypeCondition(attachmentType string) (string, []interface{}, error) {
+	if attachmentType == AttachmentTypeOther {
+		var conditions []string
+		var args []interface{}
+		for _, t := range []string{AttachmentTypeImage, AttachmentTypeDocument, AttachmentTypeAudio, AttachmentTypeVideo, AttachmentTypeArchive} {
+			condition, typeArgs, _ := attachmentTypeCondition(t)
+			conditions = append(conditions, condition)
+			args = append(args, typeArgs...)
+		}
+		return "NOT (" + strings.Join(conditions, " OR ") + ")", args, nil
+	}
+
+	patterns, ok := attachmentTypePatterns[attachmentType]
+	if !ok {
+		return "", nil, fmt.Errorf("invalid attachment type: %s", attachmentType)
+	}
+	conditions := make([]string, len(patterns))
+	args := make([]interface{}, len(patterns))
+	for i, pattern := range patterns {
+		conditions[i] = "LOWER(COALESCE(attachments.content_type, '')) LIKE ?"
+		args[i] = pattern
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", args, nil
+}
+
+// ListAttachments 列出用户所有邮件中的附件，不包含已删除邮件的附件和临时上传的附件
+func (s *AttachmentService) ListAttachments(ctx context.Context, userID uint, req *ListAttachmentsRequest) (*AttachmentListResponse, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 50
+	}
+	if req.PageSize > 200 {
+		req.PageSize = 200
+	}
+
+	query := s.db.WithContext(ctx).Table("attachments").
+		Joins("JOIN emails ON attachments.email_id = emails.id").
+		Joins("JOIN email_accounts ON emails.account_id = email_accounts.id").
+		Where("email_accounts.user_id = ? AND emails.is_deleted = ?", userID, false).
+		Where("attachments.deleted_at IS NULL")
+
+	if req.AccountID != nil {
+		query = query.Where("emails.account_id = ?", *req.AccountID)
+	}
+	if req.Type != "" {
+		condition, args, err := attachmentTypeCondition(req.Type)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(condition, args...)
+	}
+	if req.Since != nil {
+		query = query.Where("emails.date >= ?", *req.Since)
+	}
+	if req.Until != nil {
+		query = query.Where("emails.date < ?", *req.Until)
+	}
+	if req.MinSize > 0 {
+		query = query.Where("attachments.size >= ?", req.MinSize)
+	}
+	if req.MaxSize > 0 {
+		query = query.Where("attachments.size <= ?", req.MaxSize)
+	}
+	if filename := strings.TrimSpace(req.Filename); filename != "" {
+		query = query.Where("LOWER(attachments.filename) LIKE ? ESCAPE '\\'", "%"+escapeLike(strings.ToLower(filename))+"%")
+	}
+	if !req.IncludeInline {
+		query = query.Where("attachments.is_inline = ? AND COALESCE(attachments.disposition, '') <> ?", false, "inline")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count attachments: %w", err)
+	}
+
+	items := make([]AttachmentListItem, 0)
+	if err := query.
+		Select("attachments.id, attachments.filename, attachments.content_type, attachments.size, " +
+			"attachments.is_downloaded, attachments.is_inline, emails.id AS email_id, emails.account_id, " +
+			"emails.subject, emails.from_address AS \"from\", emails.date").
+		Order("emails.date DESC, attachments.id DESC").
+		Offset((req.Page - 1) * req.PageSize).
+		Limit(req.PageSize).
+		Scan(&items).Error; err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+
+	totalPages := int((total + int64(req.PageSize) - 1) / int64(req.PageSize))
+	return &AttachmentListResponse{
+		Attachments: items,
+		Total:       total,
+		Page:        req.Page,
+		PageSize:    req.PageSize,
+		TotalPages:  totalPages,
+	}, nil
+}
+
+// GetAttachmentsForUser 按顺序获取用户的多个附件，任一附件不存在或无权访问时返回错误
+func (s *AttachmentService) GetAttachmentsForUser(ctx context.Context, userID uint, attachmentIDs []uint) ([]*models.Attachment, error) {
+	if len(attachmentIDs) > MaxZipAttachments {
+		return nil, fmt.Errorf("too many attachments: at most %d per download", MaxZipAttachments)
+	}
+
+	var attachments []*models.Attachment
+	if err := s.db.WithContext(ctx).
+		Joins("JOIN emails ON attachments.email_id = emails.id").
+		Joins("JOIN email_accounts ON emails.account_id = email_accounts.id").
+		Where("attachments.id IN ? AND email_accounts.user_id = ?", attachmentIDs, userID).
+		Find(&attachments).Error; err != nil {
+		return nil, fmt.Errorf("failed to get attachments: %w", err)
+	}
+
+	byID := make(map[uint]*models.Attachment, len(attachments))
+	for _, attachment := range attachments {
+		byID[attachment.ID] = attachment
+	}
+	ordered := make([]*models.Attachment, 0, len(attachmentIDs))
+	seen := make(map[uint]bool, len(attachmentIDs))
+	for _, id := range attachmentIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		attachment, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("attachment not found or access denied")
+		}
+		ordered = append(ordered, attachment)
+	}
+	return ordered, nil
+}
+
+// WriteAttachmentsZip 将附件逐个写入ZIP流，未下载的附件先从服务器下载；
+// 单个附件失败时跳过并在压缩包的errors.txt中说明，避免中断已开始的下载
+func (s *AttachmentService) WriteAttachmentsZip(ctx context.Context, userID uint, attachments []*models.Attachment, w io.Writer) error {
+	zipWriter := zip.NewWriter(w)
+	names := make(map[string]int, len(attachments))
+	var failures []string
+
+	for _, attachment := range attachments {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		name := uniqueZipEntryName(names, attachment)
+		if err := s.writeZipEntry(ctx, zipWriter, userID, attachment, name); err != nil {
+			log.Printf("Failed to add attachment %d to zip: %v", attachment.ID, err)
+			failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+
+	if len(failures) > 0 {
+		entry, err := zipWriter.Create("errors.txt")
+		if err != nil {
+			return fmt.Errorf("failed to write zip: %w", err)
+		}
+		if _, err := io.WriteString(entry, strings.Join(failures, "\n")+"\n"); err != nil {
+			return fmt.Errorf("failed to write zip: %w", err)
+		}
+	}
+	return zipWriter.Close()
+}
+
+// writeZipEntry 写入单个附件
+func (s *AttachmentService) writeZipEntry(ctx context.Context, zipWriter *zip.Writer, userID uint, attachment *models.Attachment, name string) error {
+	content, err := s.GetAttachmentContent(ctx, attachment.ID, userID)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	header := &zip.FileHeader{Name: name, Method: zip.Deflate, Modified: attachment.CreatedAt}
+	entry, err := zipWriter.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(entry, content)
+	return err
+}
+
+// uniqueZipEntryName 生成压缩包内不重复的安全文件名，重名时追加序号
+func uniqueZipEntryName(names map[string]int, attachment *models.Attachment) string {
+	name := strings.NewReplacer("/", "_", "\\", "_").Replace(strings.TrimSpace(attachment.Filename))
+	if name == "" || name == "." || name == ".." {
+		name = fmt.Sprintf("attachment_%d", attachment.ID)
+	}
+
+	key := strings.ToLower(name)
+	names[key]++
+	if names[key] == 1 {
+		return name
+	}
+	ext := filepath.Ext(name)
+	candidate := fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(name, ext), names[key], ext)
+	names[strings.ToLower(candidate)]++
+	return candidate
+}
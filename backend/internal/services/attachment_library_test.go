@@ -0,0 +1,103 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListAttachmentsAndDownloadZip(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	service := NewAttachmentService(env.db, NewLocalFileStorage(&AttachmentStorageConfig{BaseDir: dir, MaxFileSize: 1 << 20}), nil)
+
+	older := env.createEmail(t, env.inbox, 1, "Invoice", true, false)
+	require.NoError(t, env.db.Model(older).Update("date", time.Now().AddDate(0, -1, 0)).Error)
+	newer := env.createEmail(t, env.work, 2, "Photos", true, false)
+	deleted := env.createEmail(t, env.inbox, 3, "Old", true, true)
+
+	addAttachment := func(email *models.Email, filename, contentType, content string, inline bool) *models.Attachment {
+		path := filepath.Join(dir, filename+"-"+contentType[:5])
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		attachment := &models.Attachment{
+			EmailID:      &email.ID,
+			Filename:     filename,
+			ContentType:  contentType,
+			Size:         int64(len(content)),
+			IsDownloaded: true,
+			IsInline:     inline,
+			StoragePath:  path,
+		}
+		require.NoError(t, env.db.Create(attachment).Error)
+		return attachment
+	}
+	pdf := addAttachment(older, "report.pdf", "application/pdf", "pdf-1", false)
+	photo := addAttachment(newer, "report.pdf", "image/png", "png", false)
+	addAttachment(newer, "logo.png", "image/png", "inline", true)
+	addAttachment(deleted, "trash.pdf", "application/pdf", "gone", false)
+
+	list, err := service.ListAttachments(ctx, env.user.ID, &ListAttachmentsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), list.Total)
+	require.Equal(t, photo.ID, list.Attachments[0].ID)
+	require.Equal(t, "Photos", list.Attachments[0].Subject)
+	require.Equal(t, newer.ID, list.Attachments[0].EmailID)
+
+	list, err = service.ListAttachments(ctx, env.user.ID, &ListAttachmentsRequest{Type: AttachmentTypeDocument})
+	require.NoError(t, err)
+	require.Len(t, list.Attachments, 1)
+	require.Equal(t, pdf.ID, list.Attachments[0].ID)
+
+	since := time.Now().AddDate(0, 0, -7)
+	list, err = service.ListAttachments(ctx, env.user.ID, &ListAttachmentsRequest{Since: &since, IncludeInline: true})
+	require.NoError(t, err)
+	require.Len(t, list.Attachments, 2)
+
+	list, err = service.ListAttachments(ctx, env.user.ID, &ListAttachmentsRequest{Type: AttachmentTypeOther})
+	require.NoError(t, err)
+	require.Empty(t, list.Attachments)
+
+	_, err = service.ListAttachments(ctx, env.user.ID, &ListAttachmentsRequest{Type: "spreadsheet"})
+	require.EqualError(t, err, "invalid attachment type: spreadsheet")
+
+	_, err = service.GetAttachmentsForUser(ctx, env.user.ID+1, []uint{pdf.ID})
+	require.EqualError(t, err, "attachment not found or access denied")
+
+	attachments, err := service.GetAttachmentsForUser(ctx, env.user.ID, []uint{pdf.ID, photo.ID, pdf.ID})
+	require.NoError(t, err)
+	require.Len(t, attachments, 2)
+
+	var buf bytes.Buffer
+	require.NoError(t, service.WriteAttachmentsZip(ctx, env.user.ID, attachments, &buf))
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, reader.File, 2)
+	require.Equal(t, "report.pdf", reader.File[0].Name)
+	require.Equal(t, "report (2).pdf", reader.File[1].Name)
+
+	entry, err := reader.File[1].Open()
+	require.NoError(t, err)
+	content, err := io.ReadAll(entry)
+	require.NoError(t, err)
+	require.Equal(t, "png", string(content))
+}
+
+func TestUniqueZipEntryName(t *testing.T) {
+	names := map[string]int{}
+	require.Equal(t, "a.txt", uniqueZipEntryName(names, &models.Attachment{Filename: "a.txt"}))
+	require.Equal(t, "A (2).txt", uniqueZipEntryName(names, &models.Attachment{Filename: "A.txt"}))
+	require.Equal(t, "dir_b.txt", uniqueZipEntryName(names, &models.Attachment{Filename: "dir/b.txt"}))
+	require.Equal(t, "attachment_7", uniqueZipEntryName(names, &models.Attachment{BaseModel: models.BaseModel{ID: 7}}))
+}
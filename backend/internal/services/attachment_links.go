@@ -0,0 +1,287 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"firemail/internal/models"
+	"firemail/internal/providers"
+
+	"gorm.io/gorm"
+)
+
+// AttachmentLinkPathPrefix 附件下载链接的路径前缀
+const AttachmentLinkPathPrefix = "/api/v1/attachment-links/"
+
+var (
+	// ErrAttachmentLinkNotFound 下载链接不存在或签名无效
+	ErrAttachmentLinkNotFound = errors.New("attachment link not found")
+	// ErrAttachmentLinkExpired 下载链接已过期
+	ErrAttachmentLinkExpired = errors.New("attachment link expired")
+)
+
+// AttachmentLinkService 附件下载链接服务接口
+type AttachmentLinkService interface {
+	// Offload 把附件存入附件存储并生成下载链接，baseURL 为链接使用的外部访问地址
+	Offload(ctx context.Context, userID uint, attachment *providers.OutgoingAttachment, baseURL string) (*OffloadedAttachment, error)
+
+	// Open 校验下载链接并返回附件内容，同时记录下载
+	Open(ctx context.Context, token string) (*models.Attachment, io.ReadCloser, error)
+}
+
+// OffloadedAttachment 已改为下载链接的附件
+type OffloadedAttachment struct {
+	Filename  string    `json:"filename"`
+	Size      int64     `json:"size"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// AttachmentLinkServiceImpl 附件下载链接服务实现
+type AttachmentLinkServiceImpl struct {
+	db         *gorm.DB
+	storage    AttachmentStorage
+	signingKey []byte
+	expiry     time.Duration
+	now        func() time.Time
+}
+
+// NewAttachmentLinkService 创建附件下载链接服务，链接使用secret派生的密钥签名
+func NewAttachmentLinkService(db *gorm.DB, storage AttachmentStorage, secret string, expiry time.Duration) AttachmentLinkService {
+	key := sha256.Sum256([]byte("firemail-attachment-link:" + secret))
+	return &AttachmentLinkServiceImpl{
+		db:         db,
+		storage:    storage,
+		signingKey: key[:],
+		expiry:     expiry,
+		now:        time.Now,
+	}
+}
+
+// Offload 把附件保存为用户的临时附件并生成下载链接，链接有效期内临时附件不会被清理
+func (s *AttachmentLinkServiceImpl) Offload(ctx context.Context, userID uint, attachment *providers.OutgoingAttachment, baseURL string) (*OffloadedAttachment, error) {
+	data, err := io.ReadAll(attachment.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attachment %s: %w", attachment.Filename, err)
+	}
+
+	stored := &models.Attachment{
+		UserID:       &userID,
+		Filename:     attachment.Filename,
+		ContentType:  attachment.ContentType,
+		Size:         int64(len(data)),
+		Disposition:  "attachment",
+		IsDownloaded: true,
+	}
+	if err := s.db.WithContext(ctx).Create(stored).Error; err != nil {
+		return nil, fmt.Errorf("failed to create attachment record: %w", err)
+	}
+	if err := s.storage.Store(ctx, stored, bytes.NewReader(data)); err != nil {
+		s.db.WithContext(ctx).Delete(stored)
+		return nil, fmt.Errorf("failed to store attachment %s: %w", attachment.Filename, err)
+	}
+	if err := s.db.WithContext(ctx).Model(stored).Update("file_path", s.storage.GetStoragePath(stored)).Error; err != nil {
+		return nil, fmt.Errorf("failed to update attachment storage path: %w", err)
+	}
+
+	link := &models.AttachmentLink{
+		UserID:       userID,
+		AttachmentID: stored.ID,
+		ExpiresAt:    s.now().Add(s.expiry).Truncate(time.Second),
+	}
+	if err := s.db.WithContext(ctx).Create(link).Error; err != nil {
+		return nil, fmt.Errorf("failed to create attachment link: %w", err)
+	}
+
+	return &OffloadedAttachment{
+		Filename:  stored.Filename,
+		Size:      stored.Size,
+		URL:       strings.TrimRight(baseURL, "/") + AttachmentLinkPathPrefix + s.signToken(link.ID, link.ExpiresAt.Unix()),
+		ExpiresAt: link.ExpiresAt,
+	}, nil
+}
+
+// Open 校验签名和有效期，返回附件及其内容
+func (s *AttachmentLinkServiceImpl) Open(ctx context.Context, token string) (*models.Attachment, io.ReadCloser, error) {
+	linkID, expiresAt, ok := s.verifyToken(token)
+	if !ok {
+		return nil, nil, ErrAttachmentLinkNotFound
+	}
+
+	var link models.AttachmentLink
+	if err := s.db.WithContext(ctx).Preload("Attachment").First(&link, linkID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrAttachmentLinkNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to load attachment link: %w", err)
+	}
+	if link.ExpiresAt.Unix() != expiresAt || link.Attachment.ID == 0 {
+		return nil, nil, ErrAttachmentLinkNotFound
+	}
+	now := s.now()
+	if !link.IsActive(now) {
+		return nil, nil, ErrAttachmentLinkExpired
+	}
+
+	content, err := s.storage.Retrieve(ctx, &link.Attachment)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.db.WithContext(ctx).Model(&link).Updates(map[string]interface{}{
+		"download_count":     gorm.Expr("download_count + 1"),
+		"last_downloaded_at": now,
+	}).Error; err != nil {
+		content.Close()
+		return nil, nil, fmt.Errorf("failed to record download: %w", err)
+	}
+
+	return &link.Attachment, content, nil
+}
+
+// signToken 令牌格式为 <链接ID>.<过期时间戳>.<HMAC签名>
+func (s *AttachmentLinkServiceImpl) signToken(linkID uint, expiresAt int64) string {
+	payload := strconv.FormatUint(uint64(linkID), 10) + "." + strconv.FormatInt(expiresAt, 10)
+	return payload + "." + s.signature(payload)
+}
+
+// verifyToken 校验令牌签名并解析链接ID和过期时间
+func (s *AttachmentLinkServiceImpl) verifyToken(token string) (uint, int64, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, 0, false
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(s.signature(payload))) {
+		return 0, 0, false
+	}
+
+	linkID, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return uint(linkID), expiresAt, true
+}
+
+func (s *AttachmentLinkServiceImpl) signature(payload string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// offloadOversizedAttachments 邮件超过大小上限时从最大的附件开始改为下载链接，直到邮件不再超限。
+// 内联附件被正文引用，不会改为链接；全部可改的附件都改完仍超限时返回原来的超限错误
+func offloadOversizedAttachments(ctx context.Context, links AttachmentLinkService, userID uint, message *providers.OutgoingMessage, limit *MessageSizeLimit, baseURL string) error {
+	sizeErr := checkOutgoingMessageSize(message, limit)
+	if sizeErr == nil || links == nil || baseURL == "" {
+		return sizeErr
+	}
+
+	candidates := make([]*providers.OutgoingAttachment, 0, len(message.Attachments))
+	for _, attachment := range message.Attachments {
+		if attachment.Disposition != "inline" && attachment.ContentID == "" {
+			candidates = append(candidates, attachment)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Size > candidates[j].Size
+	})
+
+	// 先按附件大小挑出需要改为链接的附件，确认能降到上限以内再写入存储
+	remaining := make(map[*providers.OutgoingAttachment]bool, len(message.Attachments))
+	for _, attachment := range message.Attachments {
+		remaining[attachment] = true
+	}
+	selected := make([]*providers.OutgoingAttachment, 0)
+	trial := *message
+	for _, attachment := range candidates {
+		delete(remaining, attachment)
+		selected = append(selected, attachment)
+		trial.Attachments = keptAttachments(message.Attachments, remaining)
+		// 预留链接块的长度，链接块随附件数量增加
+		trial.TextBody = message.TextBody + strings.Repeat(" ", attachmentLinkBlockReserve*len(selected))
+		if checkOutgoingMessageSize(&trial, limit) == nil {
+			break
+		}
+	}
+	if checkOutgoingMessageSize(&trial, limit) != nil {
+		return sizeErr
+	}
+
+	offloaded := make([]*OffloadedAttachment, 0, len(selected))
+	for _, attachment := range selected {
+		link, err := links.Offload(ctx, userID, attachment, baseURL)
+		if err != nil {
+			return fmt.Errorf("failed to offload attachment %s: %w", attachment.Filename, err)
+		}
+		offloaded = append(offloaded, link)
+	}
+
+	message.Attachments = keptAttachments(message.Attachments, remaining)
+	appendAttachmentLinks(message, offloaded)
+	return checkOutgoingMessageSize(message, limit)
+}
+
+// attachmentLinkBlockReserve 每个链接在正文中占用长度的估算上限，包括HTML和纯文本两部分
+const attachmentLinkBlockReserve = 2048
+
+// keptAttachments 按原顺序保留未改为链接的附件
+func keptAttachments(attachments []*providers.OutgoingAttachment, remaining map[*providers.OutgoingAttachment]bool) []*providers.OutgoingAttachment {
+	kept := make([]*providers.OutgoingAttachment, 0, len(remaining))
+	for _, attachment := range attachments {
+		if remaining[attachment] {
+			kept = append(kept, attachment)
+		}
+	}
+	return kept
+}
+
+// appendAttachmentLinks 在正文末尾加入下载链接块，HTML正文插入到</body>之前
+func appendAttachmentLinks(message *providers.OutgoingMessage, links []*OffloadedAttachment) {
+	if len(links) == 0 {
+		return
+	}
+
+	if message.TextBody != "" || message.HTMLBody == "" {
+		var text strings.Builder
+		text.WriteString("\n\n----------\nThe following attachments are available for download:\n")
+		for _, link := range links {
+			fmt.Fprintf(&text, "\n%s (%s)\n%s\nAvailable until %s\n", link.Filename, FormatByteSize(link.Size), link.URL, link.ExpiresAt.UTC().Format("2006-01-02 15:04 MST"))
+		}
+		message.TextBody = strings.TrimRight(message.TextBody, "\n") + text.String()
+	}
+
+	if message.HTMLBody == "" {
+		return
+	}
+	var block strings.Builder
+	block.WriteString(`<div style="margin:24px 0 0;padding:12px 16px;border:1px solid #dadce0;border-radius:8px;background:#f8f9fa;font-family:Arial,Helvetica,sans-serif;font-size:14px;color:#202124;">`)
+	block.WriteString(`<div style="margin:0 0 8px;font-weight:bold;">The following attachments are available for download</div>`)
+	for _, link := range links {
+		fmt.Fprintf(&block,
+			`<div style="margin:8px 0 0;"><a href="%s" style="color:#1a73e8;text-decoration:none;font-weight:bold;">%s</a> <span style="color:#5f6368;">(%s, available until %s)</span></div>`,
+			html.EscapeString(link.URL), html.EscapeString(link.Filename), FormatByteSize(link.Size), link.ExpiresAt.UTC().Format("2006-01-02 15:04 MST"))
+	}
+	block.WriteString(`</div>`)
+
+	if index := strings.LastIndex(strings.ToLower(message.HTMLBody), "</body>"); index >= 0 {
+		message.HTMLBody = message.HTMLBody[:index] + block.String() + message.HTMLBody[index:]
+		return
+	}
+	message.HTMLBody += block.String()
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+	"firemail/internal/providers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOffloadOversizedAttachmentsReplacesLargestWithLink(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.AttachmentLink{}))
+	storage := NewLocalFileStorage(&AttachmentStorageConfig{BaseDir: t.TempDir(), MaxFileSize: 1024 * 1024, CreateDirs: true})
+	links := NewAttachmentLinkService(env.db, storage, "secret", 7*24*time.Hour).(*AttachmentLinkServiceImpl)
+	ctx := context.Background()
+
+	large := bytes.Repeat([]byte("L"), 30000)
+	newMessage := func() *providers.OutgoingMessage {
+		return &providers.OutgoingMessage{
+			Subject:  "Design files",
+			TextBody: "Files attached.",
+			HTMLBody: "<html><body><p>Files attached.</p></body></html>",
+			Attachments: []*providers.OutgoingAttachment{
+				{Filename: "notes.txt", ContentType: "text/plain", Content: bytes.NewReader([]byte("small")), Size: 5},
+				{Filename: "mockups.zip", ContentType: "application/zip", Content: bytes.NewReader(large), Size: int64(len(large))},
+				{Filename: "logo.png", ContentType: "image/png", Content: bytes.NewReader(large), Size: int64(len(large)), Disposition: "inline", ContentID: "logo"},
+			},
+		}
+	}
+	limit := &MessageSizeLimit{Provider: "Example", Bytes: 60000}
+
+	// 未提供外部访问地址时不能生成链接，仍然返回超限错误
+	message := newMessage()
+	require.ErrorIs(t, offloadOversizedAttachments(ctx, links, env.user.ID, message, limit, ""), ErrMessageTooLarge)
+	require.Len(t, message.Attachments, 3)
+
+	// 内联图片保留，最大的普通附件改为下载链接
+	message = newMessage()
+	require.NoError(t, offloadOversizedAttachments(ctx, links, env.user.ID, message, limit, "https://mail.example.com/"))
+	require.Len(t, message.Attachments, 2)
+	require.Equal(t, "notes.txt", message.Attachments[0].Filename)
+	require.Equal(t, "logo.png", message.Attachments[1].Filename)
+	require.Contains(t, message.TextBody, "mockups.zip")
+	require.Contains(t, message.TextBody, "https://mail.example.com"+AttachmentLinkPathPrefix)
+	require.True(t, strings.HasSuffix(message.HTMLBody, "</div></body></html>"))
+
+	start := strings.Index(message.TextBody, AttachmentLinkPathPrefix) + len(AttachmentLinkPathPrefix)
+	token := message.TextBody[start : start+strings.Index(message.TextBody[start:], "\n")]
+
+	attachment, content, err := links.Open(ctx, token)
+	require.NoError(t, err)
+	data, err := io.ReadAll(content)
+	content.Close()
+	require.NoError(t, err)
+	require.Equal(t, large, data)
+	require.Equal(t, "mockups.zip", attachment.Filename)
+	require.Nil(t, attachment.EmailID)
+
+	var link models.AttachmentLink
+	require.NoError(t, env.db.First(&link).Error)
+	require.Equal(t, 1, link.DownloadCount)
+	require.NotNil(t, link.LastDownloadedAt)
+
+	_, _, err = links.Open(ctx, token+"x")
+	require.ErrorIs(t, err, ErrAttachmentLinkNotFound)
+
+	links.now = func() time.Time { return time.Now().Add(8 * 24 * time.Hour) }
+	_, _, err = links.Open(ctx, token)
+	require.ErrorIs(t, err, ErrAttachmentLinkExpired)
+
+	// 只有内联附件时无法降到上限以内
+	message = newMessage()
+	message.Attachments = message.Attachments[2:]
+	message.Attachments = append(message.Attachments, &providers.OutgoingAttachment{
+		Filename: "banner.png", Content: bytes.NewReader(large), Size: int64(len(large)), ContentID: "banner",
+	})
+	require.ErrorIs(t, offloadOversizedAttachments(ctx, links, env.user.ID, message, limit, "https://mail.example.com"), ErrMessageTooLarge)
+}
+
+func TestCleanupTemporaryAttachmentsKeepsLinkedAttachments(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.AttachmentLink{}))
+	storage := NewLocalFileStorage(&AttachmentStorageConfig{BaseDir: t.TempDir(), MaxFileSize: 1024 * 1024, CreateDirs: true})
+	links := NewAttachmentLinkService(env.db, storage, "secret", time.Hour)
+	service := NewAttachmentService(env.db, storage, nil).(*AttachmentService)
+	ctx := context.Background()
+
+	linked, err := links.Offload(ctx, env.user.ID, &providers.OutgoingAttachment{
+		Filename: "linked.bin", Content: bytes.NewReader([]byte("linked")),
+	}, "https://mail.example.com")
+	require.NoError(t, err)
+	require.NotEmpty(t, linked.URL)
+
+	orphan := &models.Attachment{UserID: &env.user.ID, Filename: "orphan.bin", Size: 6, IsDownloaded: true}
+	require.NoError(t, env.db.Create(orphan).Error)
+	require.NoError(t, env.db.Model(&models.Attachment{}).Where("email_id IS NULL").
+		Update("created_at", time.Now().Add(-48*time.Hour)).Error)
+
+	require.NoError(t, service.CleanupTemporaryAttachments(ctx, 24))
+
+	var remaining []models.Attachment
+	require.NoError(t, env.db.Where("email_id IS NULL").Find(&remaining).Error)
+	require.Len(t, remaining, 1)
+	require.Equal(t, "linked.bin", remaining[0].Filename)
+}
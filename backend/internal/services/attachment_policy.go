@@ -0,0 +1,271 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"firemail/internal/config"
+	"firemail/internal/encoding/transfer"
+	"firemail/internal/models"
+	"firemail/internal/providers"
+)
+
+// ErrAttachmentBlocked 附件被安全策略拦截
+var ErrAttachmentBlocked = errors.New("attachment blocked by policy")
+
+// attachmentSniffLength 识别附件类型时读取的文件头长度，与 http.DetectContentType 一致
+const attachmentSniffLength = 512
+
+// AttachmentVerdict 附件安全检查结果
+type AttachmentVerdict struct {
+	ContentType string // 按内容和扩展名确定的类型，替代客户端或原邮件声明的类型
+	Blocked     bool
+	Reason      string
+}
+
+// AttachmentPolicy 附件安全策略：按扩展名和文件内容拦截可执行文件和脚本
+type AttachmentPolicy struct {
+	blocked map[string]bool
+}
+
+var (
+	attachmentPolicyMu sync.RWMutex
+	attachmentPolicy   = NewAttachmentPolicy(strings.Split(config.DefaultBlockedAttachmentExtensions, ","))
+)
+
+// ConfigureAttachmentPolicy 设置全局附件安全策略，启动时调用一次
+func ConfigureAttachmentPolicy(cfg config.AttachmentsConfig) {
+	attachmentPolicyMu.Lock()
+	defer attachmentPolicyMu.Unlock()
+	attachmentPolicy = NewAttachmentPolicy(cfg.BlockedExtensions)
+}
+
+// CurrentAttachmentPolicy 返回全局附件安全策略
+func CurrentAttachmentPolicy() *AttachmentPolicy {
+	attachmentPolicyMu.RLock()
+	defer attachmentPolicyMu.RUnlock()
+	return attachmentPolicy
+}
+
+// NewAttachmentPolicy 创建附件安全策略，extensions 为禁止的扩展名，可带或不带点
+func NewAttachmentPolicy(extensions []string) *AttachmentPolicy {
+	blocked := make(map[string]bool, len(extensions))
+	for _, ext := range extensions {
+		ext = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+		if ext != "" {
+			blocked[ext] = true
+		}
+	}
+	return &AttachmentPolicy{blocked: blocked}
+}
+
+// Check 检查附件。head 为文件开头的内容（至少 attachmentSniffLength 字节时识别最准确），
+// declaredType 为客户端或原邮件声明的类型，只在内容和扩展名都无法确定类型时使用
+func (p *AttachmentPolicy) Check(filename, declaredType string, head []byte) AttachmentVerdict {
+	sniffed := SniffContentType(head)
+	verdict := AttachmentVerdict{ContentType: resolveAttachmentContentType(filename, declaredType, sniffed)}
+
+	// 从右到左控制字符（U+202E）可以让 "invoice<RLO>fdp.exe" 显示成 "invoiceexe.pdf"
+	if strings.ContainsRune(filename, '\u202e') {
+		verdict.Blocked, verdict.Reason = true, "filename contains right-to-left override character"
+		return verdict
+	}
+
+	// Windows 会忽略文件名末尾的点和空格，"setup.exe." 实际按 .exe 执行
+	name := strings.TrimRight(strings.TrimSpace(filename), ". ")
+	exts := attachmentExtensions(name)
+	if len(exts) > 0 && p.blocked[exts[len(exts)-1]] {
+		ext := exts[len(exts)-1]
+		if len(exts) > 1 {
+			verdict.Reason = fmt.Sprintf("double extension hides blocked type .%s", ext)
+		} else {
+			verdict.Reason = fmt.Sprintf("file extension .%s is not allowed", ext)
+		}
+		verdict.Blocked = true
+		return verdict
+	}
+
+	if isExecutableContentType(sniffed) {
+		verdict.Blocked, verdict.Reason = true, fmt.Sprintf("content is an executable (%s)", sniffed)
+		return verdict
+	}
+
+	return verdict
+}
+
+// applyAttachmentPolicy 按策略检查附件记录，更新类型和拦截状态。
+// 没有内容时（只同步了结构信息）只检查文件名，下载内容时会再次检查
+func applyAttachmentPolicy(attachment *models.Attachment, head []byte) {
+	verdict := CurrentAttachmentPolicy().Check(attachment.Filename, attachment.ContentType, head)
+	attachment.ContentType = verdict.ContentType
+	attachment.IsBlocked = verdict.Blocked
+	attachment.BlockReason = verdict.Reason
+	if verdict.Blocked {
+		log.Printf("Attachment %s blocked by policy: %s", attachment.Filename, verdict.Reason)
+	}
+}
+
+// checkOutgoingAttachment 检查待发送或转发的附件，返回按内容确定的类型；被拦截时返回 ErrAttachmentBlocked
+func checkOutgoingAttachment(filename, declaredType string, head []byte) (string, error) {
+	verdict := CurrentAttachmentPolicy().Check(filename, declaredType, head)
+	if verdict.Blocked {
+		return "", fmt.Errorf("%w: %s: %s", ErrAttachmentBlocked, filename, verdict.Reason)
+	}
+	return verdict.ContentType, nil
+}
+
+// attachmentInfoHead 读取同步时已获取的附件内容的开头部分（解码后），没有内容时返回nil
+func attachmentInfoHead(info *providers.AttachmentInfo) []byte {
+	if info == nil || !info.HasContent() {
+		return nil
+	}
+	if info.ContentPath == "" {
+		decoded, err := transfer.DecodeWithFallback(info.Content, info.Encoding)
+		if err != nil {
+			decoded = info.Content
+		}
+		if len(decoded) > attachmentSniffLength {
+			decoded = decoded[:attachmentSniffLength]
+		}
+		return decoded
+	}
+
+	return readAttachmentHead(info.OpenContent)
+}
+
+// readAttachmentHead 读取已解码附件内容的开头部分，无法打开时返回nil
+func readAttachmentHead(open func() (io.ReadCloser, error)) []byte {
+	content, err := open()
+	if err != nil {
+		return nil
+	}
+	defer content.Close()
+	head := make([]byte, attachmentSniffLength)
+	n, _ := io.ReadFull(content, head)
+	return head[:n]
+}
+
+// attachmentExtensions 文件名中的扩展名（小写），如 "a.tar.gz" 返回 ["tar", "gz"]。
+// 只把不含空格且不超过10个字符的部分视为扩展名，避免把 "v1.2 release notes" 之类的名称误判
+func attachmentExtensions(name string) []string {
+	base := filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	parts := strings.Split(base, ".")
+	if len(parts) < 2 {
+		return nil
+	}
+
+	var exts []string
+	for _, part := range parts[1:] {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" || len(part) > 10 || strings.ContainsAny(part, " \t") {
+			exts = nil
+			continue
+		}
+		exts = append(exts, part)
+	}
+	return exts
+}
+
+// resolveAttachmentContentType 确定附件类型：能从内容识别出具体格式时以内容为准；
+// 压缩包等容器格式（docx、xlsx 也是zip）和纯文本以扩展名为准；都无法确定时才使用声明的类型
+func resolveAttachmentContentType(filename, declaredType, sniffed string) string {
+	if sniffed != "" && !isGenericContentType(sniffed) {
+		return sniffed
+	}
+	if byExt := mime.TypeByExtension(strings.ToLower(filepath.Ext(filename))); byExt != "" {
+		return byExt
+	}
+	if declared, _, err := mime.ParseMediaType(declaredType); err == nil && declared != "" {
+		return declared
+	}
+	if sniffed != "" {
+		return sniffed
+	}
+	return "application/octet-stream"
+}
+
+// attachmentMagic 常见格式的文件头，http.DetectContentType 不识别可执行文件和部分压缩格式
+var attachmentMagic = []struct {
+	prefix      []byte
+	contentType string
+}{
+	{[]byte("\x7fELF"), "application/x-executable"},
+	{[]byte{0xFE, 0xED, 0xFA, 0xCE}, "application/x-mach-binary"},
+	{[]byte{0xFE, 0xED, 0xFA, 0xCF}, "application/x-mach-binary"},
+	{[]byte{0xCE, 0xFA, 0xED, 0xFE}, "application/x-mach-binary"},
+	{[]byte{0xCF, 0xFA, 0xED, 0xFE}, "application/x-mach-binary"},
+	{[]byte{0xCA, 0xFE, 0xBA, 0xBE}, "application/java-vm"},
+	{[]byte("#!"), "text/x-shellscript"},
+	{[]byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}, "application/x-ole-storage"},
+	{[]byte("Rar!\x1a\x07"), "application/vnd.rar"},
+	{[]byte{'7', 'z', 0xBC, 0xAF, 0x27, 0x1C}, "application/x-7z-compressed"},
+}
+
+// SniffContentType 按文件头识别内容类型，无法识别时返回空字符串
+func SniffContentType(head []byte) string {
+	if len(head) == 0 {
+		return ""
+	}
+	if len(head) > attachmentSniffLength {
+		head = head[:attachmentSniffLength]
+	}
+	if isPEHeader(head) {
+		return "application/x-msdownload"
+	}
+	for _, magic := range attachmentMagic {
+		if bytes.HasPrefix(head, magic.prefix) {
+			return magic.contentType
+		}
+	}
+	detected, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	return detected
+}
+
+// isPEHeader 是否为Windows可执行文件（DOS头 "MZ"，e_lfanew 指向 "PE\0\0"）。
+// 只看 "MZ" 会把以这两个字母开头的文本误判为可执行文件
+func isPEHeader(head []byte) bool {
+	if len(head) < 64 || head[0] != 'M' || head[1] != 'Z' {
+		return false
+	}
+	offset := int(binary.LittleEndian.Uint32(head[60:64]))
+	if offset < 64 || offset > 4096 {
+		return false
+	}
+	if offset+4 > len(head) {
+		// PE头在读取的范围之外，按可执行文件处理
+		return true
+	}
+	return bytes.Equal(head[offset:offset+4], []byte("PE\x00\x00"))
+}
+
+// isGenericContentType 是否为无法说明具体格式的类型，此时以扩展名为准。
+// 文本类的识别结果（text/html、text/xml等）不可靠，CSV、SVG、源代码都可能被识别成它们
+func isGenericContentType(contentType string) bool {
+	if strings.HasPrefix(contentType, "text/") {
+		return true
+	}
+	switch contentType {
+	case "application/octet-stream", "application/zip", "application/x-ole-storage", "application/xml":
+		return true
+	}
+	return false
+}
+
+// isExecutableContentType 是否为可执行程序或脚本
+func isExecutableContentType(contentType string) bool {
+	switch contentType {
+	case "application/x-msdownload", "application/x-executable", "application/x-mach-binary",
+		"application/java-vm", "text/x-shellscript":
+		return true
+	}
+	return false
+}
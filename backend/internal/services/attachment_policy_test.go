@@ -0,0 +1,58 @@
+package services
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachmentPolicyCheck(t *testing.T) {
+	policy := NewAttachmentPolicy([]string{"exe", ".JS", "scr"})
+
+	pe := make([]byte, 128)
+	copy(pe, "MZ")
+	binary.LittleEndian.PutUint32(pe[60:64], 64)
+	copy(pe[64:], "PE\x00\x00")
+	pdf := []byte("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+
+	tests := []struct {
+		name        string
+		filename    string
+		declared    string
+		head        []byte
+		blocked     bool
+		contentType string
+	}{
+		{name: "pdf ignores declared type", filename: "report.pdf", declared: "application/x-msdownload", head: pdf, contentType: "application/pdf"},
+		{name: "png sniffed behind wrong extension", filename: "photo.txt", head: []byte("\x89PNG\r\n\x1a\n0000"), contentType: "image/png"},
+		{name: "text sniff defers to extension", filename: "logo.svg", head: []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"></svg>`), contentType: "image/svg+xml"},
+		{name: "unknown falls back to declared", filename: "data.bin2", declared: "application/x-custom", contentType: "application/x-custom"},
+		{name: "nothing known", filename: "blob", contentType: "application/octet-stream"},
+		{name: "blocked extension", filename: "setup.EXE", blocked: true},
+		{name: "double extension", filename: "invoice.pdf.exe", blocked: true},
+		{name: "trailing dot", filename: "setup.exe.", blocked: true},
+		{name: "configured with dot", filename: "run.js", blocked: true},
+		{name: "right to left override", filename: "invoice\u202efdp.doc", blocked: true},
+		{name: "executable behind harmless extension", filename: "report.pdf", head: pe, blocked: true},
+		{name: "shell script content", filename: "readme.txt", head: []byte("#!/bin/sh\nrm -rf /\n"), blocked: true},
+		{name: "text starting with MZ", filename: "notes.txt", head: []byte("MZ notes: plain text that happens to start with the letters MZ and nothing more")},
+		{name: "dotted words are not extensions", filename: "v1.2 release exe notes.txt", head: []byte("hello")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict := policy.Check(tt.filename, tt.declared, tt.head)
+			require.Equal(t, tt.blocked, verdict.Blocked, verdict.Reason)
+			if tt.blocked {
+				require.NotEmpty(t, verdict.Reason)
+				return
+			}
+			if tt.contentType != "" {
+				require.Equal(t, tt.contentType, verdict.ContentType)
+			}
+		})
+	}
+
+	require.Contains(t, policy.Check("invoice.pdf.exe", "", nil).Reason, "double extension")
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"fmt"
@@ -12,11 +13,15 @@ import (
 
 	"firemail/internal/encoding/transfer"
 	"firemail/internal/models"
+	"firemail/internal/parser"
 	"firemail/internal/providers"
 
 	"gorm.io/gorm"
 )
 
+// 超过该大小的附件下载时流式解码，不整体读入内存
+const attachmentStreamThreshold = 8 * 1024 * 1024
+
 // AttachmentDownloader 附件下载器接口
 type AttachmentDownloader interface {
 	// DownloadAttachment 下载指定附件
@@ -36,6 +41,15 @@ type AttachmentDownloader interface {
 
 	// CleanupTemporaryAttachments 清理临时附件
 	CleanupTemporaryAttachments(ctx context.Context, maxAgeHours int) error
+
+	// ListAttachments 跨邮件列出附件
+	ListAttachments(ctx context.Context, userID uint, req *ListAttachmentsRequest) (*AttachmentListResponse, error)
+
+	// GetAttachmentsForUser 获取用户的多个附件
+	GetAttachmentsForUser(ctx context.Context, userID uint, attachmentIDs []uint) ([]*models.Attachment, error)
+
+	// WriteAttachmentsZip 将附件打包写入ZIP流
+	WriteAttachmentsZip(ctx context.Context, userID uint, attachments []*models.Attachment, w io.Writer) error
 }
 
 // ProviderFactory 提供商工厂接口（本地别名）
@@ -198,12 +212,23 @@ func (s *AttachmentService) GetAttachmentContent(ctx context.Context, attachment
 		return nil, err
 	}
 
+	if attachment.IsBlocked {
+		return nil, fmt.Errorf("%w: %s", ErrAttachmentBlocked, attachment.BlockReason)
+	}
+
 	// 检查是否已下载
 	if !attachment.IsDownloaded || !s.storage.Exists(ctx, attachment) {
 		// 尝试下载
 		if err := s.DownloadAttachment(ctx, attachmentID, userID); err != nil {
 			return nil, fmt.Errorf("failed to download attachment before retrieval: %w", err)
 		}
+		// 下载时会按实际内容重新检查安全策略
+		if attachment, err = s.getAttachmentWithPermissionCheck(ctx, attachmentID, userID); err != nil {
+			return nil, err
+		}
+		if attachment.IsBlocked {
+			return nil, fmt.Errorf("%w: %s", ErrAttachmentBlocked, attachment.BlockReason)
+		}
 	}
 
 	// 获取内容
@@ -222,6 +247,10 @@ func (s *AttachmentService) PreviewAttachment(ctx context.Context, attachmentID
 		AttachmentID: attachmentID,
 		Type:         s.getPreviewType(attachment.ContentType),
 	}
+	if attachment.IsBlocked {
+		preview.Error = fmt.Sprintf("Attachment blocked: %s", attachment.BlockReason)
+		return preview, nil
+	}
 
 	// 如果附件未下载，先下载
 	if !attachment.IsDownloaded || !s.storage.Exists(ctx, attachment) {
@@ -289,42 +318,71 @@ func (s *AttachmentService) downloadAttachmentContent(ctx context.Context, attac
 		}
 	}
 
+	// 从winmail.dat中取出的附件需要先下载整个winmail.dat
+	partID := attachment.PartID
+	tnefPartID, tnefIndex, fromTNEF := parser.SplitTNEFPartID(partID)
+	if fromTNEF {
+		partID = tnefPartID
+	}
+
 	// 下载附件内容
-	attachmentData, err := imapClient.GetAttachment(ctx, folder.Path, email.UID, attachment.PartID)
+	attachmentData, err := imapClient.GetAttachment(ctx, folder.Path, email.UID, partID)
 	if err != nil {
 		return fmt.Errorf("failed to get attachment from IMAP: %w", err)
 	}
 	defer attachmentData.Close()
 
-	// 读取所有原始数据到内存
-	rawData, err := io.ReadAll(attachmentData)
-	if err != nil {
-		return fmt.Errorf("failed to read attachment data: %w", err)
-	}
+	var decodedReader io.Reader
+	if attachment.Size > attachmentStreamThreshold && !fromTNEF {
+		// 大附件边解码边写入存储，避免整体读入内存
+		decodedReader = transfer.NewDecodingReader(attachmentData, attachment.Encoding)
+	} else {
+		// 读取所有原始数据到内存
+		rawData, err := io.ReadAll(attachmentData)
+		if err != nil {
+			return fmt.Errorf("failed to read attachment data: %w", err)
+		}
 
-	// 解码附件数据
-	// 使用附件的编码信息进行解码，如果解码失败则使用原始数据
-	decodedData, err := transfer.DecodeWithFallback(rawData, attachment.Encoding)
-	if err != nil {
-		log.Printf("Warning: Failed to decode attachment %d with encoding %s: %v, using raw data", attachment.ID, attachment.Encoding, err)
-		decodedData = rawData
-	}
+		// 解码附件数据
+		// 使用附件的编码信息进行解码，如果解码失败则使用原始数据
+		decodedData, err := transfer.DecodeWithFallback(rawData, attachment.Encoding)
+		if err != nil {
+			log.Printf("Warning: Failed to decode attachment %d with encoding %s: %v, using raw data", attachment.ID, attachment.Encoding, err)
+			decodedData = rawData
+		}
+		if fromTNEF {
+			if decodedData, err = parser.ExtractTNEFPart(decodedData, tnefIndex); err != nil {
+				return fmt.Errorf("failed to extract attachment from TNEF: %w", err)
+			}
+		}
+
+		// 更新附件大小为解码后的实际大小
+		actualSize := int64(len(decodedData))
+		if actualSize != attachment.Size {
+			log.Printf("Attachment %d size changed after decoding: %d -> %d (encoding: %s)",
+				attachment.ID, attachment.Size, actualSize, attachment.Encoding)
+			// 更新进度跟踪的总大小
+			progress.BytesTotal = actualSize
+		}
 
-	// 更新附件大小为解码后的实际大小
-	actualSize := int64(len(decodedData))
-	if actualSize != attachment.Size {
-		log.Printf("Attachment %d size changed after decoding: %d -> %d (encoding: %s)",
-			attachment.ID, attachment.Size, actualSize, attachment.Encoding)
-		// 更新进度跟踪的总大小
-		progress.BytesTotal = actualSize
+		// 创建解码后数据的Reader
+		decodedReader = bytes.NewReader(decodedData)
 	}
 
-	// 创建解码后数据的Reader
-	decodedReader := bytes.NewReader(decodedData)
+	// 按解码后的文件头重新检查安全策略，同步时声明的类型不可信
+	buffered := bufio.NewReaderSize(decodedReader, attachmentSniffLength)
+	head, _ := buffered.Peek(attachmentSniffLength)
+	verdict := CurrentAttachmentPolicy().Check(attachment.Filename, attachment.ContentType, head)
+	attachment.ContentType = verdict.ContentType
+	if verdict.Blocked {
+		log.Printf("Attachment %d (%s) blocked by policy: %s", attachment.ID, attachment.Filename, verdict.Reason)
+		attachment.IsBlocked = true
+		attachment.BlockReason = verdict.Reason
+	}
 
 	// 创建进度跟踪的Reader
 	progressReader := &progressReader{
-		reader:   decodedReader,
+		reader:   buffered,
 		progress: progress,
 		service:  s,
 	}
@@ -338,6 +396,9 @@ func (s *AttachmentService) downloadAttachmentContent(ctx context.Context, attac
 	return s.db.WithContext(ctx).Model(attachment).Updates(map[string]interface{}{
 		"file_path":      attachment.StoragePath,
 		"is_downloaded":  attachment.IsDownloaded,
+		"content_type":   attachment.ContentType,
+		"is_blocked":     attachment.IsBlocked,
+		"block_reason":   attachment.BlockReason,
 	}).Error
 }
 
@@ -484,11 +545,16 @@ func (s *AttachmentService) CleanupTemporaryAttachments(ctx context.Context, max
 	cutoffTime := time.Now().Add(-time.Duration(maxAgeHours) * time.Hour)
 	log.Printf("Cleaning up temporary attachments older than %d hours (before %s)", maxAgeHours, cutoffTime.Format("2006-01-02 15:04:05"))
 
-	// 查询需要清理的临时附件
+	// 查询需要清理的临时附件，下载链接仍有效的附件保留到链接过期
 	var tempAttachments []models.Attachment
-	err := s.db.WithContext(ctx).
-		Where("email_id IS NULL AND created_at < ?", cutoffTime).
-		Find(&tempAttachments).Error
+	query := s.db.WithContext(ctx).
+		Where("email_id IS NULL AND created_at < ?", cutoffTime)
+	if s.db.Migrator().HasTable(&models.AttachmentLink{}) {
+		query = query.Where("id NOT IN (?)", s.db.Model(&models.AttachmentLink{}).
+			Select("attachment_id").
+			Where("expires_at > ?", time.Now()))
+	}
+	err := query.Find(&tempAttachments).Error
 
 	if err != nil {
 		return fmt.Errorf("failed to query temporary attachments: %w", err)
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 附件下载令牌错误
+var (
+	ErrInvalidAttachmentToken = errors.New("invalid attachment download token")
+	ErrAttachmentTokenExpired = errors.New("attachment download token expired")
+)
+
+// AttachmentToken 附件签名下载令牌
+type AttachmentToken struct {
+	Token     string    `json:"token"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// AttachmentTokenSigner 签发和校验附件下载令牌。令牌格式为 用户ID.过期时间.签名，
+// 签名覆盖附件ID，只能用于下载签发时指定的附件
+type AttachmentTokenSigner struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// NewAttachmentTokenSigner 创建附件下载令牌签发器，secret 通常使用JWT密钥
+func NewAttachmentTokenSigner(secret string, ttl time.Duration) *AttachmentTokenSigner {
+	return &AttachmentTokenSigner{secret: []byte(secret), ttl: ttl, now: time.Now}
+}
+
+// TTL 令牌有效期
+func (s *AttachmentTokenSigner) TTL() time.Duration {
+	return s.ttl
+}
+
+// Sign 为用户签发单个附件的下载令牌
+func (s *AttachmentTokenSigner) Sign(attachmentID, userID uint) (string, time.Time) {
+	expiresAt := s.now().Add(s.ttl).Truncate(time.Second)
+	expires := expiresAt.Unix()
+	token := fmt.Sprintf("%d.%d.%s", userID, expires, s.signature(attachmentID, userID, expires))
+	return token, expiresAt
+}
+
+// Verify 校验附件下载令牌，返回签发时的用户ID
+func (s *AttachmentTokenSigner) Verify(token string, attachmentID uint) (uint, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, ErrInvalidAttachmentToken
+	}
+	userID, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil || userID == 0 {
+		return 0, ErrInvalidAttachmentToken
+	}
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, ErrInvalidAttachmentToken
+	}
+
+	expected := s.signature(attachmentID, uint(userID), expires)
+	if !hmac.Equal([]byte(parts[2]), []byte(expected)) {
+		return 0, ErrInvalidAttachmentToken
+	}
+	if s.now().Unix() >= expires {
+		return 0, ErrAttachmentTokenExpired
+	}
+	return uint(userID), nil
+}
+
+func (s *AttachmentTokenSigner) signature(attachmentID, userID uint, expires int64) string {
+	mac := hmac.New(sha256.New, s.secret)
+	fmt.Fprintf(mac, "attachment-download:%d:%d:%d", attachmentID, userID, expires)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachmentTokenSigner(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	signer := NewAttachmentTokenSigner("secret", 5*time.Minute)
+	signer.now = func() time.Time { return now }
+
+	token, expiresAt := signer.Sign(42, 7)
+	require.Equal(t, now.Add(5*time.Minute), expiresAt)
+
+	userID, err := signer.Verify(token, 42)
+	require.NoError(t, err)
+	require.Equal(t, uint(7), userID)
+
+	// 令牌只对签发时的附件有效
+	_, err = signer.Verify(token, 43)
+	require.ErrorIs(t, err, ErrInvalidAttachmentToken)
+
+	// 篡改用户ID或换用其他密钥都无法通过校验
+	_, err = signer.Verify("8"+token[1:], 42)
+	require.ErrorIs(t, err, ErrInvalidAttachmentToken)
+	_, err = NewAttachmentTokenSigner("other", 5*time.Minute).Verify(token, 42)
+	require.ErrorIs(t, err, ErrInvalidAttachmentToken)
+
+	for _, invalid := range []string{"", "7.1", "abc.def.ghi", "0.9999999999." + token} {
+		_, err = signer.Verify(invalid, 42)
+		require.ErrorIs(t, err, ErrInvalidAttachmentToken)
+	}
+
+	now = now.Add(5 * time.Minute)
+	_, err = signer.Verify(token, 42)
+	require.ErrorIs(t, err, ErrAttachmentTokenExpired)
+}
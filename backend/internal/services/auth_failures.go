@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"firemail/internal/config"
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 认证失败的服务来源
+const (
+	AuthServiceWeb  = "web"
+	AuthServiceIMAP = "imap"
+)
+
+// AuthFailure 一次认证失败
+type AuthFailure struct {
+	Service  string
+	IP       string
+	Username string
+	Reason   string
+}
+
+// AuthFailureSummary 统计窗口内单个IP的认证失败情况
+type AuthFailureSummary struct {
+	IP         string    `json:"ip"`
+	Failures   int       `json:"failures"`
+	Usernames  []string  `json:"usernames"`
+	FirstSeen  time.Time `json:"first_seen"`
+	LastSeen   time.Time `json:"last_seen"`
+	SuggestBan bool      `json:"suggest_ban"`
+}
+
+// AuthFailureService 认证失败日志，同时写入固定格式的日志行和安全事件审计日志
+type AuthFailureService interface {
+	Record(ctx context.Context, failure AuthFailure)
+	Summaries(ctx context.Context, window time.Duration) ([]AuthFailureSummary, error)
+	BanThreshold() int
+	BanWindow() time.Duration
+	Close() error
+}
+
+// AuthFailureServiceImpl 认证失败日志实现
+type AuthFailureServiceImpl struct {
+	db        *gorm.DB
+	events    SecurityEventService
+	threshold int
+	window    time.Duration
+
+	mutex  sync.Mutex
+	writer io.Writer
+	file   *os.File
+}
+
+// NewAuthFailureService 创建认证失败日志，配置了日志文件时以追加方式打开，打开失败则回退到标准日志
+func NewAuthFailureService(db *gorm.DB, events SecurityEventService, cfg config.AccessConfig) *AuthFailureServiceImpl {
+	service := &AuthFailureServiceImpl{
+		db:        db,
+		events:    events,
+		threshold: cfg.AuthBanThreshold,
+		window:    cfg.AuthBanWindow,
+		writer:    log.Writer(),
+	}
+	if cfg.AuthFailureLog != "" {
+		file, err := os.OpenFile(cfg.AuthFailureLog, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+		if err != nil {
+			log.Printf("Warning: Failed to open auth failure log %s, using standard log: %v", cfg.AuthFailureLog, err)
+		} else {
+			service.writer = file
+			service.file = file
+		}
+	}
+	return service
+}
+
+// FormatAuthFailure 生成一行认证失败日志，格式固定以便fail2ban匹配：
+//
+//	2026-01-02T15:04:05Z firemail auth-failure service=web ip=203.0.113.7 user="alice" reason=invalid_credentials
+func FormatAuthFailure(at time.Time, failure AuthFailure) string {
+	return fmt.Sprintf("%s firemail auth-failure service=%s ip=%s user=%s reason=%s\n",
+		at.UTC().Format(time.RFC3339),
+		logToken(failure.Service),
+		logToken(failure.IP),
+		strconv.Quote(failure.Username),
+		logToken(failure.Reason),
+	)
+}
+
+// logToken 日志字段中不能出现空白，空值写为 -
+func logToken(value string) string {
+	value = strings.Join(strings.Fields(value), "_")
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// Record 记录认证失败，写日志和审计日志失败都不影响登录流程
+func (s *AuthFailureServiceImpl) Record(ctx context.Context, failure AuthFailure) {
+	now := time.Now()
+	line := FormatAuthFailure(now, failure)
+
+	s.mutex.Lock()
+	_, err := io.WriteString(s.writer, line)
+	s.mutex.Unlock()
+	if err != nil {
+		log.Printf("Failed to write auth failure log: %v", err)
+	}
+
+	if s.events == nil {
+		return
+	}
+	event := &models.SecurityEvent{
+		EventType: models.SecurityEventAuthFailed,
+		IP:        failure.IP,
+		Username:  failure.Username,
+		Reason:    logToken(failure.Service) + ": " + logToken(failure.Reason),
+		CreatedAt: now,
+	}
+	if err := s.events.Record(ctx, event); err != nil {
+		log.Printf("Failed to record auth failure event: %v", err)
+	}
+}
+
+// Summaries 按IP汇总窗口内的认证失败，失败次数多的在前
+func (s *AuthFailureServiceImpl) Summaries(ctx context.Context, window time.Duration) ([]AuthFailureSummary, error) {
+	if window <= 0 {
+		window = s.window
+	}
+
+	var events []models.SecurityEvent
+	if err := s.db.WithContext(ctx).
+		Where("event_type = ? AND created_at >= ?", models.SecurityEventAuthFailed, time.Now().Add(-window)).
+		Order("created_at ASC, id ASC").
+		Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to list auth failures: %w", err)
+	}
+
+	byIP := make(map[string]*AuthFailureSummary)
+	seenUsers := make(map[string]map[string]bool)
+	for _, event := range events {
+		summary, ok := byIP[event.IP]
+		if !ok {
+			summary = &AuthFailureSummary{IP: event.IP, Usernames: []string{}, FirstSeen: event.CreatedAt}
+			byIP[event.IP] = summary
+			seenUsers[event.IP] = make(map[string]bool)
+		}
+		summary.Failures++
+		summary.LastSeen = event.CreatedAt
+		if event.Username != "" && !seenUsers[event.IP][event.Username] {
+			seenUsers[event.IP][event.Username] = true
+			summary.Usernames = append(summary.Usernames, event.Username)
+		}
+	}
+
+	summaries := make([]AuthFailureSummary, 0, len(byIP))
+	for _, summary := range byIP {
+		summary.SuggestBan = summary.IP != "" && summary.Failures >= s.threshold
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Failures != summaries[j].Failures {
+			return summaries[i].Failures > summaries[j].Failures
+		}
+		return summaries[i].LastSeen.After(summaries[j].LastSeen)
+	})
+	return summaries, nil
+}
+
+// BanThreshold 建议封禁的失败次数
+func (s *AuthFailureServiceImpl) BanThreshold() int {
+	return s.threshold
+}
+
+// BanWindow 默认统计窗口
+func (s *AuthFailureServiceImpl) BanWindow() time.Duration {
+	return s.window
+}
+
+// Close 关闭日志文件
+func (s *AuthFailureServiceImpl) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	s.writer = log.Writer()
+	return err
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"firemail/internal/config"
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestFormatAuthFailure(t *testing.T) {
+	at := time.Date(2026, 1, 2, 15, 4, 5, 0, time.FixedZone("CST", 8*3600))
+	line := FormatAuthFailure(at, AuthFailure{Service: AuthServiceWeb, IP: "203.0.113.7", Username: "al ice\"", Reason: "invalid credentials"})
+	require.Equal(t, "2026-01-02T07:04:05Z firemail auth-failure service=web ip=203.0.113.7 user=\"al ice\\\"\" reason=invalid_credentials\n", line)
+
+	line = FormatAuthFailure(at, AuthFailure{Service: AuthServiceIMAP})
+	require.Contains(t, line, "ip=- user=\"\" reason=-")
+}
+
+func TestAuthFailureServiceSummaries(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.SecurityEvent{}))
+
+	logPath := filepath.Join(t.TempDir(), "auth-failures.log")
+	service := NewAuthFailureService(db, NewSecurityEventService(db), config.AccessConfig{
+		AuthFailureLog:   logPath,
+		AuthBanThreshold: 3,
+		AuthBanWindow:    10 * time.Minute,
+	})
+	ctx := context.Background()
+
+	for _, username := range []string{"alice", "bob", "alice"} {
+		service.Record(ctx, AuthFailure{Service: AuthServiceWeb, IP: "203.0.113.7", Username: username, Reason: "invalid_credentials"})
+	}
+	service.Record(ctx, AuthFailure{Service: AuthServiceIMAP, IP: "198.51.100.1", Username: "carol", Reason: "invalid_credentials"})
+	// 窗口外的失败不计入
+	require.NoError(t, db.Create(&models.SecurityEvent{
+		EventType: models.SecurityEventAuthFailed,
+		IP:        "198.51.100.1",
+		CreatedAt: time.Now().Add(-time.Hour),
+	}).Error)
+	require.NoError(t, service.Close())
+
+	content, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 4)
+	require.Contains(t, lines[3], "service=imap ip=198.51.100.1 user=\"carol\"")
+
+	summaries, err := service.Summaries(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, summaries, 2)
+	require.Equal(t, "203.0.113.7", summaries[0].IP)
+	require.Equal(t, 3, summaries[0].Failures)
+	require.Equal(t, []string{"alice", "bob"}, summaries[0].Usernames)
+	require.True(t, summaries[0].SuggestBan)
+	require.Equal(t, 1, summaries[1].Failures)
+	require.False(t, summaries[1].SuggestBan)
+
+	summaries, err = service.Summaries(ctx, 2*time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, 2, summaries[1].Failures)
+}
@@ -8,6 +8,7 @@ import (
 
 	"gorm.io/gorm"
 
+	"firemail/internal/database/writequeue"
 	"firemail/internal/models"
 )
 
@@ -55,7 +56,7 @@ func (p *BatchProcessor) BatchInsertEmails(ctx context.Context, emails []*models
 
 // insertEmailBatch 插入一批邮件
 func (p *BatchProcessor) insertEmailBatch(ctx context.Context, emails []*models.Email) error {
-	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return writequeue.Transaction(ctx, p.db.WithContext(ctx), func(tx *gorm.DB) error {
 		// 使用批量插入
 		if err := tx.CreateInBatches(emails, len(emails)).Error; err != nil {
 			// 如果批量插入失败，尝试逐个插入以处理重复
@@ -226,25 +227,23 @@ func (p *BatchProcessor) batchUpdateFolder(ctx context.Context, updates []EmailU
 	return nil
 }
 
-// BatchDeleteEmails 批量删除邮件
+// BatchDeleteEmails 批量把邮件移入回收站，与单封删除语义一致
 func (p *BatchProcessor) BatchDeleteEmails(ctx context.Context, emailIDs []uint) error {
 	if len(emailIDs) == 0 {
 		return nil
 	}
 
-	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// 先删除附件
-		if err := tx.Where("email_id IN ?", emailIDs).Delete(&models.Attachment{}).Error; err != nil {
-			return fmt.Errorf("failed to delete attachments: %w", err)
-		}
-
-		// 再删除邮件
-		if err := tx.Where("id IN ?", emailIDs).Delete(&models.Email{}).Error; err != nil {
-			return fmt.Errorf("failed to delete emails: %w", err)
-		}
+	query, err := excludeLegalHolds(p.db.WithContext(ctx).Model(&models.Email{}), nil)
+	if err != nil {
+		return err
+	}
+	if err := query.
+		Where("id IN ? AND is_deleted = ?", emailIDs, false).
+		Updates(map[string]interface{}{"is_deleted": true, "trashed_at": time.Now()}).Error; err != nil {
+		return fmt.Errorf("failed to move emails to trash: %w", err)
+	}
 
-		return nil
-	})
+	return nil
 }
 
 // BatchInsertAttachments 批量插入附件
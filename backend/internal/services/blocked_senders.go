@@ -0,0 +1,369 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// BlockedSenderRequest 添加或导入屏蔽发件人请求，pattern可以是邮箱地址或域名
+type BlockedSenderRequest struct {
+	Pattern string `json:"pattern" binding:"required"`
+	Action  string `json:"action"`
+}
+
+// UpdateBlockedSenderRequest 更新屏蔽发件人请求
+type UpdateBlockedSenderRequest struct {
+	Action string `json:"action" binding:"required"`
+}
+
+// BlockEmailSenderRequest 屏蔽邮件发件人请求
+type BlockEmailSenderRequest struct {
+	Domain bool   `json:"domain"` // 屏蔽整个发件人域名
+	Action string `json:"action"`
+}
+
+// ImportBlockedSendersRequest 导入屏蔽发件人列表请求
+type ImportBlockedSendersRequest struct {
+	Entries []BlockedSenderRequest `json:"entries" binding:"required,max=1000"`
+	Replace bool                   `json:"replace"` // 导入前清空现有列表
+}
+
+// ImportBlockedSendersResult 导入屏蔽发件人列表结果
+type ImportBlockedSendersResult struct {
+	Imported int      `json:"imported"`
+	Skipped  int      `json:"skipped"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// BlockedSenderExport 屏蔽发件人列表导出格式，可直接作为导入请求
+type BlockedSenderExport struct {
+	Entries    []BlockedSenderRequest `json:"entries"`
+	ExportedAt time.Time              `json:"exported_at"`
+}
+
+// blockedSendersAvailable 屏蔽发件人表是否存在
+func blockedSendersAvailable(db *gorm.DB) bool {
+	return db.Migrator().HasTable(&models.BlockedSender{})
+}
+
+// normalizeBlockedSenderPattern 规范化屏蔽规则：邮箱地址转小写，域名统一为@example.com形式
+func normalizeBlockedSenderPattern(pattern string) (string, error) {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	pattern = strings.TrimPrefix(pattern, "*")
+	if pattern == "" {
+		return "", fmt.Errorf("invalid blocked sender pattern")
+	}
+
+	if strings.HasPrefix(pattern, "@") || !strings.Contains(pattern, "@") {
+		domain := strings.Trim(strings.TrimPrefix(pattern, "@"), ".")
+		if domain == "" || !strings.Contains(domain, ".") || strings.ContainsAny(domain, "@<> \t") {
+			return "", fmt.Errorf("invalid blocked sender pattern")
+		}
+		return "@" + domain, nil
+	}
+
+	parsed, err := mail.ParseAddress(pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid blocked sender pattern")
+	}
+	return strings.ToLower(parsed.Address), nil
+}
+
+// normalizeBlockedSenderAction 校验屏蔽处理方式，未指定时移入回收站
+func normalizeBlockedSenderAction(action string) (string, error) {
+	switch action {
+	case "":
+		return models.BlockedSenderActionTrash, nil
+	case models.BlockedSenderActionTrash, models.BlockedSenderActionSpam:
+		return action, nil
+	default:
+		return "", fmt.Errorf("invalid block action: %s", action)
+	}
+}
+
+// blockedSenderCandidates 发件人可能命中的规则：完整地址、所在域名及各级父域名
+func blockedSenderCandidates(from string) []string {
+	sender := parseEmailAddress(from)
+	if sender == nil {
+		return nil
+	}
+	address := strings.ToLower(strings.TrimSpace(sender.Address))
+	at := strings.LastIndex(address, "@")
+	if at <= 0 || at == len(address)-1 {
+		return nil
+	}
+
+	candidates := []string{address}
+	labels := strings.Split(address[at+1:], ".")
+	for i := 0; i < len(labels)-1; i++ {
+		candidates = append(candidates, "@"+strings.Join(labels[i:], "."))
+	}
+	return candidates
+}
+
+// matchBlockedSender 查找命中发件人的屏蔽规则，地址规则优先于域名规则，越具体的域名越优先
+func matchBlockedSender(db *gorm.DB, userID uint, from string) *models.BlockedSender {
+	candidates := blockedSenderCandidates(from)
+	if len(candidates) == 0 || !blockedSendersAvailable(db) {
+		return nil
+	}
+
+	var rules []models.BlockedSender
+	if err := db.Where("user_id = ? AND pattern IN ?", userID, candidates).Find(&rules).Error; err != nil {
+		log.Printf("Warning: failed to check blocked sender: %v", err)
+		return nil
+	}
+	for _, candidate := range candidates {
+		for i := range rules {
+			if rules[i].Pattern == candidate {
+				return &rules[i]
+			}
+		}
+	}
+	return nil
+}
+
+// applyBlockedSender 按屏蔽规则处理同步到的新邮件：移入垃圾邮件文件夹或回收站。
+// 已在发件箱、草稿箱、垃圾邮件和回收站中的邮件不处理，返回邮件是否被移走
+func applyBlockedSender(tx *gorm.DB, email *models.Email, rule *models.BlockedSender) bool {
+	if email.FolderID == nil {
+		return false
+	}
+	var folder models.Folder
+	if err := tx.Select("id", "type").First(&folder, *email.FolderID).Error; err != nil {
+		log.Printf("Warning: failed to load folder for blocked sender: %v", err)
+		return false
+	}
+	switch folder.Type {
+	case models.FolderTypeSent, models.FolderTypeDrafts, models.FolderTypeSpam, models.FolderTypeTrash:
+		return false
+	}
+
+	if rule.Action == models.BlockedSenderActionSpam {
+		var spam models.Folder
+		err := tx.Select("id").
+			Where("account_id = ? AND type = ?", email.AccountID, models.FolderTypeSpam).
+			Take(&spam).Error
+		if err == nil {
+			email.FolderID = &spam.ID
+			return true
+		}
+		if err != gorm.ErrRecordNotFound {
+			log.Printf("Warning: failed to find spam folder for blocked sender: %v", err)
+		}
+	}
+
+	now := time.Now()
+	email.IsDeleted = true
+	email.TrashedAt = &now
+	return true
+}
+
+// ListBlockedSenders 获取用户的屏蔽发件人列表
+func (s *EmailServiceImpl) ListBlockedSenders(ctx context.Context, userID uint) ([]models.BlockedSender, error) {
+	var senders []models.BlockedSender
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("pattern ASC").
+		Find(&senders).Error; err != nil {
+		return nil, fmt.Errorf("failed to load blocked senders: %w", err)
+	}
+	return senders, nil
+}
+
+// AddBlockedSender 添加屏蔽发件人，只影响之后同步到的邮件
+func (s *EmailServiceImpl) AddBlockedSender(ctx context.Context, userID uint, req *BlockedSenderRequest) (*models.BlockedSender, error) {
+	pattern, err := normalizeBlockedSenderPattern(req.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	action, err := normalizeBlockedSenderAction(req.Action)
+	if err != nil {
+		return nil, err
+	}
+
+	sender := &models.BlockedSender{UserID: userID, Pattern: pattern, Action: action}
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createBlockedSender(tx, sender)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sender, nil
+}
+
+// createBlockedSender 在事务中创建屏蔽规则，规则已存在时返回错误
+func createBlockedSender(tx *gorm.DB, sender *models.BlockedSender) error {
+	var existing int64
+	if err := tx.Model(&models.BlockedSender{}).
+		Where("user_id = ? AND pattern = ?", sender.UserID, sender.Pattern).
+		Count(&existing).Error; err != nil {
+		return fmt.Errorf("failed to check blocked sender: %w", err)
+	}
+	if existing > 0 {
+		return fmt.Errorf("sender already blocked")
+	}
+	if err := tx.Create(sender).Error; err != nil {
+		return fmt.Errorf("failed to create blocked sender: %w", err)
+	}
+	return nil
+}
+
+// UpdateBlockedSender 修改屏蔽发件人的处理方式
+func (s *EmailServiceImpl) UpdateBlockedSender(ctx context.Context, userID, senderID uint, req *UpdateBlockedSenderRequest) (*models.BlockedSender, error) {
+	action, err := normalizeBlockedSenderAction(req.Action)
+	if err != nil {
+		return nil, err
+	}
+
+	var sender models.BlockedSender
+	if err := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", senderID, userID).First(&sender).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("blocked sender not found")
+		}
+		return nil, fmt.Errorf("failed to find blocked sender: %w", err)
+	}
+	if err := s.db.WithContext(ctx).Model(&sender).Update("action", action).Error; err != nil {
+		return nil, fmt.Errorf("failed to update blocked sender: %w", err)
+	}
+	return &sender, nil
+}
+
+// DeleteBlockedSender 取消屏蔽发件人
+func (s *EmailServiceImpl) DeleteBlockedSender(ctx context.Context, userID, senderID uint) error {
+	result := s.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", senderID, userID).
+		Delete(&models.BlockedSender{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete blocked sender: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("blocked sender not found")
+	}
+	return nil
+}
+
+// BlockEmailSender 屏蔽邮件的发件人（或其域名），并按处理方式移走这封邮件
+func (s *EmailServiceImpl) BlockEmailSender(ctx context.Context, userID, emailID uint, req *BlockEmailSenderRequest) (*models.BlockedSender, error) {
+	email, err := s.getEmailForUser(ctx, userID, emailID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := blockedSenderCandidates(email.From)
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("email has no sender address")
+	}
+	pattern := candidates[0]
+	if req.Domain {
+		if len(candidates) < 2 {
+			return nil, fmt.Errorf("invalid blocked sender pattern")
+		}
+		pattern = candidates[1]
+	}
+
+	sender, err := s.AddBlockedSender(ctx, userID, &BlockedSenderRequest{Pattern: pattern, Action: req.Action})
+	if err != nil {
+		return nil, err
+	}
+
+	// 邮件移动失败不影响屏蔽规则的创建
+	if err := s.fileBlockedEmail(ctx, userID, email, sender.Action); err != nil {
+		log.Printf("Warning: failed to move email %d from blocked sender: %v", email.ID, err)
+	}
+	return sender, nil
+}
+
+// fileBlockedEmail 将被屏蔽发件人的邮件移入垃圾邮件文件夹，没有垃圾邮件文件夹时删除到回收站
+func (s *EmailServiceImpl) fileBlockedEmail(ctx context.Context, userID uint, email *models.Email, action string) error {
+	if action == models.BlockedSenderActionSpam {
+		var spam models.Folder
+		err := s.db.WithContext(ctx).
+			Where("account_id = ? AND type = ?", email.AccountID, models.FolderTypeSpam).
+			Take(&spam).Error
+		if err == nil {
+			if email.FolderID != nil && *email.FolderID == spam.ID {
+				return nil
+			}
+			return s.MoveEmail(ctx, userID, email.ID, spam.ID)
+		}
+		if err != gorm.ErrRecordNotFound {
+			return fmt.Errorf("failed to find spam folder: %w", err)
+		}
+	}
+	return s.DeleteEmail(ctx, userID, email.ID)
+}
+
+// ExportBlockedSenders 导出屏蔽发件人列表
+func (s *EmailServiceImpl) ExportBlockedSenders(ctx context.Context, userID uint) (*BlockedSenderExport, error) {
+	senders, err := s.ListBlockedSenders(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &BlockedSenderExport{
+		Entries:    make([]BlockedSenderRequest, 0, len(senders)),
+		ExportedAt: time.Now(),
+	}
+	for _, sender := range senders {
+		export.Entries = append(export.Entries, BlockedSenderRequest{Pattern: sender.Pattern, Action: sender.Action})
+	}
+	return export, nil
+}
+
+// ImportBlockedSenders 导入屏蔽发件人列表，无效条目记录错误后跳过，已存在的规则更新处理方式
+func (s *EmailServiceImpl) ImportBlockedSenders(ctx context.Context, userID uint, req *ImportBlockedSendersRequest) (*ImportBlockedSendersResult, error) {
+	result := &ImportBlockedSendersResult{}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if req.Replace {
+			if err := tx.Where("user_id = ?", userID).Delete(&models.BlockedSender{}).Error; err != nil {
+				return fmt.Errorf("failed to clear blocked senders: %w", err)
+			}
+		}
+
+		for i, entry := range req.Entries {
+			pattern, err := normalizeBlockedSenderPattern(entry.Pattern)
+			if err == nil {
+				entry.Action, err = normalizeBlockedSenderAction(entry.Action)
+			}
+			if err != nil {
+				result.Skipped++
+				result.Errors = append(result.Errors, fmt.Sprintf("entry %d: %v", i+1, err))
+				continue
+			}
+
+			var existing models.BlockedSender
+			err = tx.Where("user_id = ? AND pattern = ?", userID, pattern).Take(&existing).Error
+			switch {
+			case err == nil:
+				if existing.Action == entry.Action {
+					result.Skipped++
+					continue
+				}
+				if err := tx.Model(&existing).Update("action", entry.Action).Error; err != nil {
+					return fmt.Errorf("failed to update blocked sender: %w", err)
+				}
+			case err == gorm.ErrRecordNotFound:
+				if err := createBlockedSender(tx, &models.BlockedSender{UserID: userID, Pattern: pattern, Action: entry.Action}); err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("failed to check blocked sender: %w", err)
+			}
+			result.Imported++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
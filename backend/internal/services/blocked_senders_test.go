@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+	"firemail/internal/providers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockedSendersFilterSyncedEmails(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.BlockedSender{}))
+	ctx := context.Background()
+
+	spam := &models.Folder{
+		AccountID:    env.account.ID,
+		Name:         "Junk",
+		DisplayName:  "垃圾邮件",
+		Type:         models.FolderTypeSpam,
+		Path:         "Junk",
+		Delimiter:    "/",
+		IsSelectable: true,
+	}
+	require.NoError(t, env.db.Create(spam).Error)
+
+	address, err := env.service.AddBlockedSender(ctx, env.user.ID, &BlockedSenderRequest{Pattern: " Spammer@Example.com "})
+	require.NoError(t, err)
+	require.Equal(t, "spammer@example.com", address.Pattern)
+	require.Equal(t, models.BlockedSenderActionTrash, address.Action)
+
+	domain, err := env.service.AddBlockedSender(ctx, env.user.ID, &BlockedSenderRequest{Pattern: "*@ads.test", Action: models.BlockedSenderActionSpam})
+	require.NoError(t, err)
+	require.Equal(t, "@ads.test", domain.Pattern)
+
+	_, err = env.service.AddBlockedSender(ctx, env.user.ID, &BlockedSenderRequest{Pattern: "ads.test"})
+	require.EqualError(t, err, "sender already blocked")
+	_, err = env.service.AddBlockedSender(ctx, env.user.ID, &BlockedSenderRequest{Pattern: "localhost"})
+	require.EqualError(t, err, "invalid blocked sender pattern")
+	_, err = env.service.AddBlockedSender(ctx, env.user.ID, &BlockedSenderRequest{Pattern: "x@y.test", Action: "archive"})
+	require.EqualError(t, err, "invalid block action: archive")
+
+	// 子域名命中父域名规则
+	require.Equal(t, domain.ID, matchBlockedSender(env.db, env.user.ID, "Promo <deals@mail.ads.test>").ID)
+	require.Nil(t, matchBlockedSender(env.db, env.user.ID, "friend@example.com"))
+
+	syncService := NewSyncService(env.db, nil, nil, NewDeduplicatorFactory(env.db), nil, nil)
+	trashed, err := syncService.saveSyncedEmail(ctx, &providers.EmailMessage{
+		MessageID: "<blocked-1@example.com>",
+		UID:       11,
+		Subject:   "Buy now",
+		From:      &models.EmailAddress{Address: "spammer@example.com"},
+		Date:      time.Now(),
+	}, env.account.ID, env.inbox.ID, env.user.ID)
+	require.NoError(t, err)
+	require.True(t, trashed.IsDeleted)
+	require.NotNil(t, trashed.TrashedAt)
+
+	moved, err := syncService.saveSyncedEmail(ctx, &providers.EmailMessage{
+		MessageID: "<blocked-2@example.com>",
+		UID:       12,
+		Subject:   "Sale",
+		From:      &models.EmailAddress{Address: "deals@mail.ads.test"},
+		Date:      time.Now(),
+	}, env.account.ID, env.inbox.ID, env.user.ID)
+	require.NoError(t, err)
+	require.False(t, moved.IsDeleted)
+	require.Equal(t, spam.ID, *moved.FolderID)
+
+	syncService.fileBlockedEmailsOnServer(ctx, env.provider.imap, env.inbox, []uint32{11}, map[uint][]uint32{spam.ID: {12}})
+	require.Equal(t, [][]uint32{{11}}, env.provider.imap.deleteCalls)
+	require.Len(t, env.provider.imap.moveCalls, 1)
+	require.Equal(t, "Junk", env.provider.imap.moveCalls[0].TargetFolder)
+	require.Equal(t, []uint32{12}, env.provider.imap.moveCalls[0].UIDs)
+
+	// 垃圾邮件文件夹中的邮件不再处理
+	inSpam, err := syncService.saveSyncedEmail(ctx, &providers.EmailMessage{
+		MessageID: "<blocked-3@example.com>",
+		UID:       13,
+		From:      &models.EmailAddress{Address: "spammer@example.com"},
+		Date:      time.Now(),
+	}, env.account.ID, spam.ID, env.user.ID)
+	require.NoError(t, err)
+	require.False(t, inSpam.IsDeleted)
+
+	updated, err := env.service.UpdateBlockedSender(ctx, env.user.ID, address.ID, &UpdateBlockedSenderRequest{Action: models.BlockedSenderActionSpam})
+	require.NoError(t, err)
+	require.Equal(t, models.BlockedSenderActionSpam, updated.Action)
+
+	require.EqualError(t, env.service.DeleteBlockedSender(ctx, env.user.ID+1, address.ID), "blocked sender not found")
+	require.NoError(t, env.service.DeleteBlockedSender(ctx, env.user.ID, address.ID))
+}
+
+func TestBlockEmailSenderAndImportExport(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.BlockedSender{}, &models.EmailEvent{}))
+	ctx := context.Background()
+
+	email := env.createEmail(t, env.inbox, 21, "Unwanted", false, false)
+	sender, err := env.service.BlockEmailSender(ctx, env.user.ID, email.ID, &BlockEmailSenderRequest{Domain: true})
+	require.NoError(t, err)
+	require.Equal(t, "@example.com", sender.Pattern)
+
+	// 没有垃圾邮件文件夹时删除到回收站
+	var reloaded models.Email
+	require.NoError(t, env.db.First(&reloaded, email.ID).Error)
+	require.True(t, reloaded.IsDeleted)
+	require.Equal(t, [][]uint32{{21}}, env.provider.imap.deleteCalls)
+
+	export, err := env.service.ExportBlockedSenders(ctx, env.user.ID)
+	require.NoError(t, err)
+	require.Equal(t, []BlockedSenderRequest{{Pattern: "@example.com", Action: models.BlockedSenderActionTrash}}, export.Entries)
+
+	result, err := env.service.ImportBlockedSenders(ctx, env.user.ID, &ImportBlockedSendersRequest{Entries: []BlockedSenderRequest{
+		{Pattern: "@example.com", Action: models.BlockedSenderActionTrash},
+		{Pattern: "@example.com", Action: models.BlockedSenderActionSpam},
+		{Pattern: "bulk@news.test"},
+		{Pattern: "not valid"},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, 2, result.Imported)
+	require.Equal(t, 2, result.Skipped)
+	require.Len(t, result.Errors, 1)
+
+	senders, err := env.service.ListBlockedSenders(ctx, env.user.ID)
+	require.NoError(t, err)
+	require.Len(t, senders, 2)
+	require.Equal(t, models.BlockedSenderActionSpam, senders[0].Action)
+
+	result, err = env.service.ImportBlockedSenders(ctx, env.user.ID, &ImportBlockedSendersRequest{
+		Entries: []BlockedSenderRequest{{Pattern: "only@news.test"}},
+		Replace: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Imported)
+	senders, err = env.service.ListBlockedSenders(ctx, env.user.ID)
+	require.NoError(t, err)
+	require.Len(t, senders, 1)
+	require.Equal(t, "only@news.test", senders[0].Pattern)
+}
@@ -0,0 +1,403 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// maxCannedResponses 每个用户最多保存的快捷回复数
+	maxCannedResponses = 500
+	// maxCannedResponseFolders 每条快捷回复最多关联的推荐文件夹数
+	maxCannedResponseFolders = 20
+	// cannedResponsePreviewLength 快捷列表中正文预览的字符数
+	cannedResponsePreviewLength = 80
+	// defaultCannedQuickListLimit 快捷列表默认返回条数
+	defaultCannedQuickListLimit = 20
+	// maxCannedQuickListLimit 快捷列表最多返回条数
+	maxCannedQuickListLimit = 100
+)
+
+var (
+	// ErrCannedResponseNotFound 快捷回复不存在或无权访问
+	ErrCannedResponseNotFound = errors.New("canned response not found")
+	// ErrInvalidCannedResponse 快捷回复参数无效
+	ErrInvalidCannedResponse = errors.New("invalid canned response")
+	// ErrCannedShortcutInUse 快捷指令已被其他快捷回复使用
+	ErrCannedShortcutInUse = errors.New("canned response shortcut already in use")
+
+	cannedShortcutPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,49}$`)
+)
+
+// CannedResponseService 快捷回复服务接口
+type CannedResponseService interface {
+	// ListResponses 列出快捷回复
+	ListResponses(ctx context.Context, userID uint) ([]models.CannedResponse, error)
+
+	// QuickList 撰写邮件时使用的精简列表，不含完整正文
+	QuickList(ctx context.Context, userID uint, req *CannedQuickListRequest) ([]CannedQuickItem, error)
+
+	// CreateResponse 创建快捷回复
+	CreateResponse(ctx context.Context, userID uint, req *CannedResponseRequest) (*models.CannedResponse, error)
+
+	// UpdateResponse 修改快捷回复
+	UpdateResponse(ctx context.Context, userID, responseID uint, req *CannedResponseRequest) (*models.CannedResponse, error)
+
+	// DeleteResponse 删除快捷回复
+	DeleteResponse(ctx context.Context, userID, responseID uint) error
+
+	// RenderResponse 代入变量渲染快捷回复并计入使用次数
+	RenderResponse(ctx context.Context, userID, responseID uint, req *RenderCannedResponseRequest) (*RenderedCannedResponse, error)
+}
+
+// CannedResponseRequest 创建或修改快捷回复的请求
+type CannedResponseRequest struct {
+	Name      string `json:"name" binding:"required,max=100"`
+	Shortcut  string `json:"shortcut,omitempty" binding:"max=50"` // 小写字母、数字、下划线和连字符
+	TextBody  string `json:"text_body" binding:"required"`
+	HTMLBody  string `json:"html_body,omitempty"`
+	FolderIDs []uint `json:"folder_ids,omitempty"` // 在这些文件夹中回复时优先推荐
+}
+
+// CannedQuickListRequest 快捷列表查询参数
+type CannedQuickListRequest struct {
+	Query    string `form:"q"`         // 按名称或快捷指令前缀过滤
+	FolderID uint   `form:"folder_id"` // 当前回复邮件所在文件夹，关联该文件夹的快捷回复排在前面
+	Limit    int    `form:"limit"`
+}
+
+// CannedQuickItem 快捷列表中的一项
+type CannedQuickItem struct {
+	ID         uint   `json:"id"`
+	Name       string `json:"name"`
+	Shortcut   string `json:"shortcut,omitempty"`
+	Preview    string `json:"preview"`
+	Suggested  bool   `json:"suggested"` // 关联了当前文件夹
+	UsageCount int    `json:"usage_count"`
+}
+
+// RenderCannedResponseRequest 渲染快捷回复的请求
+type RenderCannedResponseRequest struct {
+	EmailID   *uint                  `json:"email_id,omitempty"` // 正在回复的邮件，提供发件人、主题等变量
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+// RenderedCannedResponse 渲染后的快捷回复
+type RenderedCannedResponse struct {
+	TextBody         string   `json:"text_body"`
+	HTMLBody         string   `json:"html_body,omitempty"`
+	MissingVariables []string `json:"missing_variables,omitempty"` // 未提供值、以空值代替的变量
+}
+
+// CannedResponseServiceImpl 快捷回复服务实现
+type CannedResponseServiceImpl struct {
+	db *gorm.DB
+}
+
+// NewCannedResponseService 创建快捷回复服务
+func NewCannedResponseService(db *gorm.DB) CannedResponseService {
+	return &CannedResponseServiceImpl{db: db}
+}
+
+// ListResponses 列出快捷回复
+func (s *CannedResponseServiceImpl) ListResponses(ctx context.Context, userID uint) ([]models.CannedResponse, error) {
+	responses := make([]models.CannedResponse, 0)
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("name ASC, id ASC").
+		Find(&responses).Error; err != nil {
+		return nil, fmt.Errorf("failed to list canned responses: %w", err)
+	}
+	return responses, nil
+}
+
+// QuickList 撰写邮件时使用的精简列表：关联当前文件夹的排在前面，其余按使用次数排序
+func (s *CannedResponseServiceImpl) QuickList(ctx context.Context, userID uint, req *CannedQuickListRequest) ([]CannedQuickItem, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultCannedQuickListLimit
+	}
+	if limit > maxCannedQuickListLimit {
+		limit = maxCannedQuickListLimit
+	}
+
+	query := s.db.WithContext(ctx).
+		Select("id", "name", "shortcut", "text_body", "folder_ids", "usage_count").
+		Where("user_id = ?", userID)
+	if q := strings.ToLower(strings.TrimSpace(req.Query)); q != "" {
+		like := "%" + escapeLike(q) + "%"
+		query = query.Where("(LOWER(name) LIKE ? ESCAPE '\\' OR shortcut LIKE ? ESCAPE '\\')", like, escapeLike(q)+"%")
+	}
+
+	var responses []models.CannedResponse
+	if err := query.Find(&responses).Error; err != nil {
+		return nil, fmt.Errorf("failed to list canned responses: %w", err)
+	}
+
+	items := make([]CannedQuickItem, 0, len(responses))
+	for _, response := range responses {
+		suggested := false
+		if req.FolderID != 0 {
+			for _, folderID := range response.GetFolderIDs() {
+				if folderID == req.FolderID {
+					suggested = true
+					break
+				}
+			}
+		}
+		items = append(items, CannedQuickItem{
+			ID:         response.ID,
+			Name:       response.Name,
+			Shortcut:   response.Shortcut,
+			Preview:    cannedResponsePreview(response.TextBody),
+			Suggested:  suggested,
+			UsageCount: response.UsageCount,
+		})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Suggested != items[j].Suggested {
+			return items[i].Suggested
+		}
+		if items[i].UsageCount != items[j].UsageCount {
+			return items[i].UsageCount > items[j].UsageCount
+		}
+		return items[i].Name < items[j].Name
+	})
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+// cannedResponsePreview 正文的单行预览
+func cannedResponsePreview(body string) string {
+	preview := strings.Join(strings.Fields(body), " ")
+	if utf8.RuneCountInString(preview) <= cannedResponsePreviewLength {
+		return preview
+	}
+	return string([]rune(preview)[:cannedResponsePreviewLength]) + "…"
+}
+
+// CreateResponse 创建快捷回复
+func (s *CannedResponseServiceImpl) CreateResponse(ctx context.Context, userID uint, req *CannedResponseRequest) (*models.CannedResponse, error) {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.CannedResponse{}).
+		Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to count canned responses: %w", err)
+	}
+	if count >= maxCannedResponses {
+		return nil, fmt.Errorf("%w: at most %d canned responses", ErrInvalidCannedResponse, maxCannedResponses)
+	}
+
+	response := &models.CannedResponse{UserID: userID}
+	if err := s.applyRequest(ctx, response, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Create(response).Error; err != nil {
+		return nil, fmt.Errorf("failed to create canned response: %w", err)
+	}
+	return response, nil
+}
+
+// UpdateResponse 修改快捷回复
+func (s *CannedResponseServiceImpl) UpdateResponse(ctx context.Context, userID, responseID uint, req *CannedResponseRequest) (*models.CannedResponse, error) {
+	response, err := s.getResponse(ctx, userID, responseID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyRequest(ctx, response, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Save(response).Error; err != nil {
+		return nil, fmt.Errorf("failed to update canned response: %w", err)
+	}
+	return response, nil
+}
+
+// DeleteResponse 删除快捷回复
+func (s *CannedResponseServiceImpl) DeleteResponse(ctx context.Context, userID, responseID uint) error {
+	result := s.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", responseID, userID).
+		Delete(&models.CannedResponse{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete canned response: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrCannedResponseNotFound
+	}
+	return nil
+}
+
+// RenderResponse 代入变量渲染快捷回复并计入使用次数。
+// 提供邮件时可以使用 sender_name、sender_email、subject、my_name、my_email 变量，请求中的变量优先；
+// 未提供值的变量以空值代替并在结果中列出
+func (s *CannedResponseServiceImpl) RenderResponse(ctx context.Context, userID, responseID uint, req *RenderCannedResponseRequest) (*RenderedCannedResponse, error) {
+	response, err := s.getResponse(ctx, userID, responseID)
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string]interface{}{
+		"date": time.Now().In(userSettingsOrDefault(ctx, s.db, userID).Location()).Format("2006-01-02"),
+	}
+	if req.EmailID != nil {
+		if err := s.addEmailVariables(ctx, userID, *req.EmailID, data); err != nil {
+			return nil, err
+		}
+	}
+	for key, value := range req.Variables {
+		data[key] = value
+	}
+
+	undefined := make(map[string]bool)
+	textBody, err := executeCollectingUndefined(func(d map[string]interface{}) (string, error) {
+		return executeTextTemplate("text", response.TextBody, d)
+	}, data, undefined)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCannedResponse, err)
+	}
+	htmlBody, err := executeCollectingUndefined(func(d map[string]interface{}) (string, error) {
+		return executeHTMLTemplate(response.HTMLBody, d)
+	}, data, undefined)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCannedResponse, err)
+	}
+
+	if err := s.db.WithContext(ctx).Model(response).Updates(map[string]interface{}{
+		"usage_count":  gorm.Expr("usage_count + 1"),
+		"last_used_at": time.Now(),
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to record canned response usage: %w", err)
+	}
+
+	rendered := &RenderedCannedResponse{TextBody: textBody, HTMLBody: htmlBody}
+	for name := range undefined {
+		rendered.MissingVariables = append(rendered.MissingVariables, name)
+	}
+	sort.Strings(rendered.MissingVariables)
+	return rendered, nil
+}
+
+// addEmailVariables 添加正在回复的邮件提供的变量
+func (s *CannedResponseServiceImpl) addEmailVariables(ctx context.Context, userID, emailID uint, data map[string]interface{}) error {
+	var email models.Email
+	if err := s.db.WithContext(ctx).
+		Joins("JOIN email_accounts ON emails.account_id = email_accounts.id").
+		Preload("Account").
+		Where("emails.id = ? AND email_accounts.user_id = ?", emailID, userID).
+		First(&email).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: email not found", ErrInvalidCannedResponse)
+		}
+		return fmt.Errorf("failed to load email: %w", err)
+	}
+
+	data["subject"] = email.Subject
+	if sender := replyRecipient(&email); sender != nil {
+		data["sender_email"] = sender.Address
+		data["sender_name"] = sender.Name
+		if sender.Name == "" {
+			data["sender_name"] = sender.Address
+		}
+	}
+	data["my_name"] = email.Account.Name
+	data["my_email"] = email.Account.Email
+	return nil
+}
+
+// getResponse 获取属于用户的快捷回复
+func (s *CannedResponseServiceImpl) getResponse(ctx context.Context, userID, responseID uint) (*models.CannedResponse, error) {
+	var response models.CannedResponse
+	if err := s.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", responseID, userID).
+		First(&response).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCannedResponseNotFound
+		}
+		return nil, fmt.Errorf("failed to get canned response: %w", err)
+	}
+	return &response, nil
+}
+
+// applyRequest 校验请求并写入快捷回复
+func (s *CannedResponseServiceImpl) applyRequest(ctx context.Context, response *models.CannedResponse, req *CannedResponseRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidCannedResponse)
+	}
+	if strings.TrimSpace(req.TextBody) == "" {
+		return fmt.Errorf("%w: text_body is required", ErrInvalidCannedResponse)
+	}
+	if err := validateTemplateSyntax("", req.TextBody, req.HTMLBody); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCannedResponse, err)
+	}
+
+	shortcut := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(req.Shortcut), "/")))
+	if shortcut != "" {
+		if !cannedShortcutPattern.MatchString(shortcut) {
+			return fmt.Errorf("%w: shortcut may only contain lowercase letters, digits, '_' and '-'", ErrInvalidCannedResponse)
+		}
+		var count int64
+		if err := s.db.WithContext(ctx).Model(&models.CannedResponse{}).
+			Where("user_id = ? AND shortcut = ? AND id <> ?", response.UserID, shortcut, response.ID).
+			Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to check shortcut: %w", err)
+		}
+		if count > 0 {
+			return ErrCannedShortcutInUse
+		}
+	}
+
+	folderIDs, err := s.validateFolders(ctx, response.UserID, req.FolderIDs)
+	if err != nil {
+		return err
+	}
+
+	response.Name = name
+	response.Shortcut = shortcut
+	response.TextBody = req.TextBody
+	response.HTMLBody = req.HTMLBody
+	return response.SetFolderIDs(folderIDs)
+}
+
+// validateFolders 去重并确认推荐文件夹属于用户
+func (s *CannedResponseServiceImpl) validateFolders(ctx context.Context, userID uint, folderIDs []uint) ([]uint, error) {
+	seen := make(map[uint]struct{}, len(folderIDs))
+	unique := make([]uint, 0, len(folderIDs))
+	for _, folderID := range folderIDs {
+		if _, exists := seen[folderID]; exists {
+			continue
+		}
+		seen[folderID] = struct{}{}
+		unique = append(unique, folderID)
+	}
+	if len(unique) > maxCannedResponseFolders {
+		return nil, fmt.Errorf("%w: at most %d folders", ErrInvalidCannedResponse, maxCannedResponseFolders)
+	}
+	if len(unique) == 0 {
+		return unique, nil
+	}
+
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.Folder{}).
+		Joins("JOIN email_accounts ON folders.account_id = email_accounts.id").
+		Where("folders.id IN ? AND email_accounts.user_id = ?", unique, userID).
+		Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to check folders: %w", err)
+	}
+	if int(count) != len(unique) {
+		return nil, fmt.Errorf("%w: folder not found", ErrInvalidCannedResponse)
+	}
+	return unique, nil
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setupCannedResponseTest(t *testing.T) (*emailStateServiceTestEnv, CannedResponseService) {
+	t.Helper()
+
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.CannedResponse{}))
+	return env, NewCannedResponseService(env.db)
+}
+
+func TestCannedResponseQuickListSuggestsFolderSnippets(t *testing.T) {
+	env, service := setupCannedResponseTest(t)
+	ctx := context.Background()
+
+	thanks, err := service.CreateResponse(ctx, env.user.ID, &CannedResponseRequest{
+		Name:     "Thanks",
+		Shortcut: "/Thanks",
+		TextBody: "Thanks for reaching out!",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "thanks", thanks.Shortcut)
+
+	support, err := service.CreateResponse(ctx, env.user.ID, &CannedResponseRequest{
+		Name:      "Ticket received",
+		Shortcut:  "ticket",
+		TextBody:  "We have received your ticket and will reply within one business day.",
+		FolderIDs: []uint{env.work.ID, env.work.ID},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []uint{env.work.ID}, support.GetFolderIDs())
+
+	// 快捷指令在用户内唯一，推荐文件夹必须属于用户
+	_, err = service.CreateResponse(ctx, env.user.ID, &CannedResponseRequest{Name: "Dup", Shortcut: "thanks", TextBody: "x"})
+	require.ErrorIs(t, err, ErrCannedShortcutInUse)
+	_, err = service.CreateResponse(ctx, env.user.ID, &CannedResponseRequest{Name: "Bad", TextBody: "x", FolderIDs: []uint{9999}})
+	require.ErrorIs(t, err, ErrInvalidCannedResponse)
+	_, err = service.CreateResponse(ctx, env.user.ID, &CannedResponseRequest{Name: "Broken", TextBody: "{{.name"})
+	require.ErrorIs(t, err, ErrInvalidCannedResponse)
+
+	_, err = service.RenderResponse(ctx, env.user.ID, thanks.ID, &RenderCannedResponseRequest{})
+	require.NoError(t, err)
+
+	// 未指定文件夹时按使用次数排序，在关联的文件夹中推荐的排在前面
+	items, err := service.QuickList(ctx, env.user.ID, &CannedQuickListRequest{})
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	require.Equal(t, thanks.ID, items[0].ID)
+	require.Equal(t, 1, items[0].UsageCount)
+
+	items, err = service.QuickList(ctx, env.user.ID, &CannedQuickListRequest{FolderID: env.work.ID})
+	require.NoError(t, err)
+	require.Equal(t, support.ID, items[0].ID)
+	require.True(t, items[0].Suggested)
+	require.Contains(t, items[0].Preview, "We have received your ticket")
+
+	items, err = service.QuickList(ctx, env.user.ID, &CannedQuickListRequest{Query: "tick"})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, support.ID, items[0].ID)
+}
+
+func TestCannedResponseRenderUsesEmailVariables(t *testing.T) {
+	env, service := setupCannedResponseTest(t)
+	ctx := context.Background()
+
+	email := env.createEmail(t, env.inbox, 1, "Refund request", false, false)
+	require.NoError(t, env.db.Model(email).Update("from_address", "Alice <alice@example.org>").Error)
+
+	response, err := service.CreateResponse(ctx, env.user.ID, &CannedResponseRequest{
+		Name:     "Refund",
+		TextBody: "Hi {{.sender_name}}, about \"{{.subject}}\": refund {{.order}} is on its way. {{.agent}}",
+		HTMLBody: "<p>Hi {{.sender_name}}</p>",
+	})
+	require.NoError(t, err)
+
+	rendered, err := service.RenderResponse(ctx, env.user.ID, response.ID, &RenderCannedResponseRequest{
+		EmailID:   &email.ID,
+		Variables: map[string]interface{}{"order": "#42"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Hi Alice, about \"Refund request\": refund #42 is on its way. ", rendered.TextBody)
+	require.Equal(t, "<p>Hi Alice</p>", rendered.HTMLBody)
+	require.Equal(t, []string{"agent"}, rendered.MissingVariables)
+
+	var stored models.CannedResponse
+	require.NoError(t, env.db.First(&stored, response.ID).Error)
+	require.Equal(t, 1, stored.UsageCount)
+	require.NotNil(t, stored.LastUsedAt)
+
+	_, err = service.RenderResponse(ctx, env.user.ID+1, response.ID, &RenderCannedResponseRequest{})
+	require.ErrorIs(t, err, ErrCannedResponseNotFound)
+}
@@ -0,0 +1,402 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 变更日志保留时长，早于该时间的令牌需要客户端全量刷新
+const changeLogRetention = 30 * 24 * time.Hour
+
+// 清理过期变更日志的最小间隔
+const changeLogPruneInterval = time.Hour
+
+// 单次增量查询返回的默认与最大条目数
+const (
+	defaultChangesLimit = 500
+	maxChangesLimit     = 2000
+)
+
+// ChangeLogService 用户变更日志服务，为前端重连后的增量同步提供数据
+type ChangeLogService interface {
+	RecordEmailChanges(ctx context.Context, userID, accountID uint, action string, emailIDs ...uint) error
+	RecordFolderChanges(ctx context.Context, userID, accountID uint, folderIDs ...uint) error
+	RecordAccountChange(ctx context.Context, userID, accountID uint, action string) error
+	GetChanges(ctx context.Context, userID, since uint, limit int) (*ChangesResponse, error)
+	Prune(ctx context.Context, before time.Time) (int64, error)
+}
+
+// EmailChanges 令牌之后发生变化的邮件ID
+type EmailChanges struct {
+	Created []uint `json:"created"`
+	Updated []uint `json:"updated"`
+	Deleted []uint `json:"deleted"`
+}
+
+// FolderChange 文件夹当前计数
+type FolderChange struct {
+	ID           uint `json:"id"`
+	AccountID    uint `json:"account_id"`
+	TotalEmails  int  `json:"total_emails"`
+	UnreadEmails int  `json:"unread_emails"`
+	Deleted      bool `json:"deleted,omitempty"`
+}
+
+// AccountChange 账户当前状态
+type AccountChange struct {
+	ID           uint       `json:"id"`
+	IsActive     bool       `json:"is_active"`
+	SyncStatus   string     `json:"sync_status"`
+	LastSyncAt   *time.Time `json:"last_sync_at"`
+	ErrorMessage string     `json:"error_message,omitempty"`
+	UnreadEmails int        `json:"unread_emails"`
+	Deleted      bool       `json:"deleted,omitempty"`
+}
+
+// ChangesResponse 增量变更响应
+// Reset为true时令牌已失效（过期或未知），客户端应全量刷新后使用新令牌
+type ChangesResponse struct {
+	Token    uint            `json:"token"`
+	HasMore  bool            `json:"has_more"`
+	Reset    bool            `json:"reset"`
+	Emails   EmailChanges    `json:"emails"`
+	Folders  []FolderChange  `json:"folders"`
+	Accounts []AccountChange `json:"accounts"`
+}
+
+// ChangeLogServiceImpl 基于数据库的变更日志实现
+type ChangeLogServiceImpl struct {
+	db *gorm.DB
+
+	pruneMutex sync.Mutex
+	lastPrune  time.Time
+}
+
+// NewChangeLogService 创建变更日志服务
+func NewChangeLogService(db *gorm.DB) ChangeLogService {
+	return &ChangeLogServiceImpl{db: db}
+}
+
+// RecordEmailChanges 记录邮件变更
+func (s *ChangeLogServiceImpl) RecordEmailChanges(ctx context.Context, userID, accountID uint, action string, emailIDs ...uint) error {
+	entries := make([]models.ChangeLogEntry, 0, len(emailIDs))
+	for _, emailID := range emailIDs {
+		entries = append(entries, models.ChangeLogEntry{
+			UserID:     userID,
+			AccountID:  accountID,
+			EntityType: models.ChangeEntityEmail,
+			EntityID:   emailID,
+			Action:     action,
+		})
+	}
+	return s.insert(ctx, entries)
+}
+
+// RecordFolderChanges 记录文件夹计数变化
+func (s *ChangeLogServiceImpl) RecordFolderChanges(ctx context.Context, userID, accountID uint, folderIDs ...uint) error {
+	entries := make([]models.ChangeLogEntry, 0, len(folderIDs))
+	seen := make(map[uint]bool, len(folderIDs))
+	for _, folderID := range folderIDs {
+		if folderID == 0 || seen[folderID] {
+			continue
+		}
+		seen[folderID] = true
+		entries = append(entries, models.ChangeLogEntry{
+			UserID:     userID,
+			AccountID:  accountID,
+			EntityType: models.ChangeEntityFolder,
+			EntityID:   folderID,
+			Action:     models.ChangeActionUpdated,
+		})
+	}
+	return s.insert(ctx, entries)
+}
+
+// RecordAccountChange 记录账户状态变化
+func (s *ChangeLogServiceImpl) RecordAccountChange(ctx context.Context, userID, accountID uint, action string) error {
+	return s.insert(ctx, []models.ChangeLogEntry{{
+		UserID:     userID,
+		AccountID:  accountID,
+		EntityType: models.ChangeEntityAccount,
+		EntityID:   accountID,
+		Action:     action,
+	}})
+}
+
+func (s *ChangeLogServiceImpl) insert(ctx context.Context, entries []models.ChangeLogEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	if err := s.db.WithContext(ctx).CreateInBatches(entries, 200).Error; err != nil {
+		return fmt.Errorf("failed to record changes: %w", err)
+	}
+	s.pruneIfDue(ctx)
+	return nil
+}
+
+// pruneIfDue 按间隔清理过期日志，失败只记录日志
+func (s *ChangeLogServiceImpl) pruneIfDue(ctx context.Context) {
+	s.pruneMutex.Lock()
+	if time.Since(s.lastPrune) < changeLogPruneInterval {
+		s.pruneMutex.Unlock()
+		return
+	}
+	s.lastPrune = time.Now()
+	s.pruneMutex.Unlock()
+
+	if _, err := s.Prune(ctx, time.Now().Add(-changeLogRetention)); err != nil {
+		log.Printf("Warning: failed to prune change log: %v", err)
+	}
+}
+
+// Prune 删除指定时间之前的变更日志
+func (s *ChangeLogServiceImpl) Prune(ctx context.Context, before time.Time) (int64, error) {
+	result := s.db.WithContext(ctx).Where("created_at < ?", before).Delete(&models.ChangeLogEntry{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to prune change log: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// GetChanges 获取令牌之后的变更，since为0时只返回当前令牌
+func (s *ChangeLogServiceImpl) GetChanges(ctx context.Context, userID, since uint, limit int) (*ChangesResponse, error) {
+	if limit <= 0 {
+		limit = defaultChangesLimit
+	}
+	if limit > maxChangesLimit {
+		limit = maxChangesLimit
+	}
+
+	response := &ChangesResponse{
+		Emails: EmailChanges{
+			Created: []uint{},
+			Updated: []uint{},
+			Deleted: []uint{},
+		},
+		Folders:  []FolderChange{},
+		Accounts: []AccountChange{},
+	}
+
+	db := s.db.WithContext(ctx)
+
+	// 自增ID全局单调递增，早于最早保留条目或晚于最新条目的令牌均视为失效
+	var bounds struct {
+		MinID uint
+		MaxID uint
+	}
+	if err := db.Model(&models.ChangeLogEntry{}).
+		Select("COALESCE(MIN(id), 0) AS min_id, COALESCE(MAX(id), 0) AS max_id").
+		Scan(&bounds).Error; err != nil {
+		return nil, fmt.Errorf("failed to get change log bounds: %w", err)
+	}
+
+	if since == 0 || since > bounds.MaxID || (bounds.MinID > 0 && since+1 < bounds.MinID) {
+		response.Token = bounds.MaxID
+		response.Reset = true
+		return response, nil
+	}
+
+	var entries []models.ChangeLogEntry
+	if err := db.Where("user_id = ? AND id > ?", userID, since).
+		Order("id ASC").
+		Limit(limit + 1).
+		Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to get changes: %w", err)
+	}
+
+	if len(entries) > limit {
+		entries = entries[:limit]
+		response.HasMore = true
+	}
+
+	response.Token = since
+	if len(entries) > 0 {
+		response.Token = entries[len(entries)-1].ID
+	}
+	if !response.HasMore && bounds.MaxID > response.Token {
+		// 其他用户的条目不影响结果，直接推进到最新令牌
+		response.Token = bounds.MaxID
+	}
+
+	emailActions := make(map[uint]string)
+	var emailOrder, folderIDs, accountIDs []uint
+	seenFolders := make(map[uint]bool)
+	seenAccounts := make(map[uint]bool)
+
+	for _, entry := range entries {
+		switch entry.EntityType {
+		case models.ChangeEntityEmail:
+			previous, exists := emailActions[entry.EntityID]
+			if !exists {
+				emailOrder = append(emailOrder, entry.EntityID)
+			}
+			emailActions[entry.EntityID] = mergeChangeAction(previous, entry.Action)
+		case models.ChangeEntityFolder:
+			if !seenFolders[entry.EntityID] {
+				seenFolders[entry.EntityID] = true
+				folderIDs = append(folderIDs, entry.EntityID)
+			}
+		case models.ChangeEntityAccount:
+			if !seenAccounts[entry.EntityID] {
+				seenAccounts[entry.EntityID] = true
+				accountIDs = append(accountIDs, entry.EntityID)
+			}
+		}
+	}
+
+	for _, emailID := range emailOrder {
+		switch emailActions[emailID] {
+		case models.ChangeActionCreated:
+			response.Emails.Created = append(response.Emails.Created, emailID)
+		case models.ChangeActionDeleted:
+			response.Emails.Deleted = append(response.Emails.Deleted, emailID)
+		default:
+			response.Emails.Updated = append(response.Emails.Updated, emailID)
+		}
+	}
+
+	if err := s.loadFolderChanges(ctx, userID, folderIDs, response); err != nil {
+		return nil, err
+	}
+	if err := s.loadAccountChanges(ctx, userID, accountIDs, response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// mergeChangeAction 合并同一邮件的多次变更：删除优先，新建后的更新仍视为新建
+func mergeChangeAction(previous, current string) string {
+	switch {
+	case current == models.ChangeActionDeleted:
+		return models.ChangeActionDeleted
+	case previous == models.ChangeActionCreated:
+		return models.ChangeActionCreated
+	case previous == models.ChangeActionDeleted && current == models.ChangeActionUpdated:
+		return models.ChangeActionDeleted
+	}
+	return current
+}
+
+func (s *ChangeLogServiceImpl) loadFolderChanges(ctx context.Context, userID uint, folderIDs []uint, response *ChangesResponse) error {
+	if len(folderIDs) == 0 {
+		return nil
+	}
+
+	var folders []models.Folder
+	if err := s.db.WithContext(ctx).
+		Joins("JOIN email_accounts ON email_accounts.id = folders.account_id").
+		Where("folders.id IN ? AND email_accounts.user_id = ?", folderIDs, userID).
+		Find(&folders).Error; err != nil {
+		return fmt.Errorf("failed to load changed folders: %w", err)
+	}
+
+	byID := make(map[uint]models.Folder, len(folders))
+	for _, folder := range folders {
+		byID[folder.ID] = folder
+	}
+
+	for _, folderID := range folderIDs {
+		folder, ok := byID[folderID]
+		if !ok {
+			response.Folders = append(response.Folders, FolderChange{ID: folderID, Deleted: true})
+			continue
+		}
+		response.Folders = append(response.Folders, FolderChange{
+			ID:           folder.ID,
+			AccountID:    folder.AccountID,
+			TotalEmails:  folder.TotalEmails,
+			UnreadEmails: folder.UnreadEmails,
+		})
+	}
+	return nil
+}
+
+func (s *ChangeLogServiceImpl) loadAccountChanges(ctx context.Context, userID uint, accountIDs []uint, response *ChangesResponse) error {
+	if len(accountIDs) == 0 {
+		return nil
+	}
+
+	var accounts []models.EmailAccount
+	if err := s.db.WithContext(ctx).
+		Where("id IN ? AND user_id = ?", accountIDs, userID).
+		Find(&accounts).Error; err != nil {
+		return fmt.Errorf("failed to load changed accounts: %w", err)
+	}
+
+	byID := make(map[uint]models.EmailAccount, len(accounts))
+	for _, account := range accounts {
+		byID[account.ID] = account
+	}
+
+	for _, accountID := range accountIDs {
+		account, ok := byID[accountID]
+		if !ok {
+			response.Accounts = append(response.Accounts, AccountChange{ID: accountID, Deleted: true})
+			continue
+		}
+		response.Accounts = append(response.Accounts, AccountChange{
+			ID:           account.ID,
+			IsActive:     account.IsActive,
+			SyncStatus:   account.SyncStatus,
+			LastSyncAt:   account.LastSyncAt,
+			ErrorMessage: account.ErrorMessage,
+			UnreadEmails: account.UnreadEmails,
+		})
+	}
+	return nil
+}
+
+// recordEmailChanges 记录邮件变更，未配置变更日志时忽略，失败不影响主流程
+func recordEmailChanges(ctx context.Context, changeLog ChangeLogService, userID, accountID uint, action string, emailIDs ...uint) {
+	if changeLog == nil || len(emailIDs) == 0 {
+		return
+	}
+	if err := changeLog.RecordEmailChanges(ctx, userID, accountID, action, emailIDs...); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
+// recordFolderChanges 记录文件夹计数变化，nil文件夹ID会被忽略
+func recordFolderChanges(ctx context.Context, changeLog ChangeLogService, userID, accountID uint, folderIDs ...*uint) {
+	if changeLog == nil {
+		return
+	}
+	ids := make([]uint, 0, len(folderIDs))
+	for _, folderID := range folderIDs {
+		if folderID != nil {
+			ids = append(ids, *folderID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	if err := changeLog.RecordFolderChanges(ctx, userID, accountID, ids...); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
+// recordAccountChange 记录账户状态变化
+func recordAccountChange(ctx context.Context, changeLog ChangeLogService, userID, accountID uint, action string) {
+	if changeLog == nil {
+		return
+	}
+	if err := changeLog.RecordAccountChange(ctx, userID, accountID, action); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
+func emailIDsOf(emails []models.Email) []uint {
+	ids := make([]uint, 0, len(emails))
+	for _, email := range emails {
+		ids = append(ids, email.ID)
+	}
+	return ids
+}
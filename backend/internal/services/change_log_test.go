@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setupChangeLogTestEnv(t *testing.T) (*emailStateServiceTestEnv, ChangeLogService) {
+	t.Helper()
+
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.ChangeLogEntry{}))
+
+	changeLog := NewChangeLogService(env.db)
+	env.service.SetChangeLog(changeLog)
+	return env, changeLog
+}
+
+func TestGetChangesReturnsEmailFolderAndAccountDeltas(t *testing.T) {
+	env, changeLog := setupChangeLogTestEnv(t)
+	ctx := context.Background()
+
+	readEmail := env.createEmail(t, env.inbox, 101, "待读", false, false)
+	movedEmail := env.createEmail(t, env.inbox, 102, "待移动", true, false)
+	deletedEmail := env.createEmail(t, env.inbox, 103, "待删除", true, false)
+
+	// 首次请求没有令牌，只返回当前令牌并要求全量刷新
+	initial, err := changeLog.GetChanges(ctx, env.user.ID, 0, 0)
+	require.NoError(t, err)
+	require.True(t, initial.Reset)
+
+	require.NoError(t, changeLog.RecordAccountChange(ctx, env.user.ID, env.account.ID, models.ChangeActionUpdated))
+	token, err := changeLog.GetChanges(ctx, env.user.ID, 0, 0)
+	require.NoError(t, err)
+
+	require.NoError(t, env.service.MarkEmailAsRead(ctx, env.user.ID, readEmail.ID))
+	require.NoError(t, env.service.MoveEmail(ctx, env.user.ID, movedEmail.ID, env.work.ID))
+	require.NoError(t, env.service.DeleteEmail(ctx, env.user.ID, deletedEmail.ID))
+
+	changes, err := changeLog.GetChanges(ctx, env.user.ID, token.Token, 0)
+	require.NoError(t, err)
+	require.False(t, changes.Reset)
+	require.False(t, changes.HasMore)
+	require.Greater(t, changes.Token, token.Token)
+
+	require.Empty(t, changes.Emails.Created)
+	require.ElementsMatch(t, []uint{readEmail.ID, movedEmail.ID}, changes.Emails.Updated)
+	require.Equal(t, []uint{deletedEmail.ID}, changes.Emails.Deleted)
+
+	folderIDs := make([]uint, 0, len(changes.Folders))
+	for _, folder := range changes.Folders {
+		folderIDs = append(folderIDs, folder.ID)
+		require.Equal(t, env.account.ID, folder.AccountID)
+		require.Zero(t, folder.UnreadEmails)
+	}
+	require.ElementsMatch(t, []uint{env.inbox.ID, env.work.ID}, folderIDs)
+
+	require.Len(t, changes.Accounts, 1)
+	require.Equal(t, env.account.ID, changes.Accounts[0].ID)
+
+	// 使用新令牌再次请求时没有变更
+	empty, err := changeLog.GetChanges(ctx, env.user.ID, changes.Token, 0)
+	require.NoError(t, err)
+	require.False(t, empty.Reset)
+	require.Equal(t, changes.Token, empty.Token)
+	require.Empty(t, empty.Emails.Updated)
+	require.Empty(t, empty.Folders)
+}
+
+func TestGetChangesMergesActionsAndPaginates(t *testing.T) {
+	env, changeLog := setupChangeLogTestEnv(t)
+	ctx := context.Background()
+
+	require.NoError(t, changeLog.RecordAccountChange(ctx, env.user.ID, env.account.ID, models.ChangeActionUpdated))
+	start, err := changeLog.GetChanges(ctx, env.user.ID, 0, 0)
+	require.NoError(t, err)
+
+	require.NoError(t, changeLog.RecordEmailChanges(ctx, env.user.ID, env.account.ID, models.ChangeActionCreated, 1, 2, 3))
+	require.NoError(t, changeLog.RecordEmailChanges(ctx, env.user.ID, env.account.ID, models.ChangeActionUpdated, 1))
+	require.NoError(t, changeLog.RecordEmailChanges(ctx, env.user.ID, env.account.ID, models.ChangeActionDeleted, 2))
+	require.NoError(t, changeLog.RecordEmailChanges(ctx, env.user.ID, env.account.ID, models.ChangeActionUpdated, 2))
+
+	// 其他用户的变更不可见
+	require.NoError(t, changeLog.RecordEmailChanges(ctx, env.user.ID+1, env.account.ID, models.ChangeActionCreated, 99))
+
+	all, err := changeLog.GetChanges(ctx, env.user.ID, start.Token, 0)
+	require.NoError(t, err)
+	require.Equal(t, []uint{1, 3}, all.Emails.Created)
+	require.Empty(t, all.Emails.Updated)
+	require.Equal(t, []uint{2}, all.Emails.Deleted)
+
+	page, err := changeLog.GetChanges(ctx, env.user.ID, start.Token, 2)
+	require.NoError(t, err)
+	require.True(t, page.HasMore)
+	require.Equal(t, []uint{1, 2}, page.Emails.Created)
+
+	rest, err := changeLog.GetChanges(ctx, env.user.ID, page.Token, 0)
+	require.NoError(t, err)
+	require.False(t, rest.HasMore)
+	require.Equal(t, []uint{3}, rest.Emails.Created)
+	require.ElementsMatch(t, []uint{1, 2}, append(rest.Emails.Updated, rest.Emails.Deleted...))
+}
+
+func TestGetChangesResetsExpiredToken(t *testing.T) {
+	env, changeLog := setupChangeLogTestEnv(t)
+	ctx := context.Background()
+
+	require.NoError(t, changeLog.RecordEmailChanges(ctx, env.user.ID, env.account.ID, models.ChangeActionCreated, 1, 2))
+	first, err := changeLog.GetChanges(ctx, env.user.ID, 0, 0)
+	require.NoError(t, err)
+	staleToken := first.Token - 1
+
+	require.NoError(t, changeLog.RecordEmailChanges(ctx, env.user.ID, env.account.ID, models.ChangeActionCreated, 3))
+	pruned, err := changeLog.Prune(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.EqualValues(t, 3, pruned)
+
+	require.NoError(t, changeLog.RecordEmailChanges(ctx, env.user.ID, env.account.ID, models.ChangeActionCreated, 4))
+	changes, err := changeLog.GetChanges(ctx, env.user.ID, staleToken, 0)
+	require.NoError(t, err)
+	require.True(t, changes.Reset)
+	require.Empty(t, changes.Emails.Created)
+
+	// 令牌超出已知范围同样要求全量刷新
+	future, err := changeLog.GetChanges(ctx, env.user.ID, changes.Token+100, 0)
+	require.NoError(t, err)
+	require.True(t, future.Reset)
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"firemail/internal/config"
+	"firemail/internal/i18n"
 	"firemail/internal/models"
 
 	"gorm.io/gorm"
@@ -132,10 +133,11 @@ func (m *StandardDeduplicationManager) DeduplicateAccount(ctx context.Context, a
 
 	// 发送开始通知
 	if m.eventTrigger != nil {
-		m.eventTrigger.TriggerNotification(ctx, 
-			"去重开始", 
-			fmt.Sprintf("账户 %s 的邮件去重已开始", account.Email),
-			"info", 
+		locale := userLocale(ctx, m.db, account.UserID)
+		m.eventTrigger.TriggerNotification(ctx,
+			i18n.T(locale, "Deduplication started"),
+			i18n.T(locale, "Deduplication started for account %s", account.Email),
+			"info",
 			account.UserID)
 	}
 
@@ -252,9 +254,10 @@ func (m *StandardDeduplicationManager) DeduplicateAccount(ctx context.Context, a
 			notificationType = "warning"
 		}
 		
+		locale := userLocale(ctx, m.db, account.UserID)
 		m.eventTrigger.TriggerNotification(ctx,
-			"去重完成",
-			fmt.Sprintf("账户 %s 的邮件去重已完成。处理: %d, 重复: %d, 错误: %d",
+			i18n.T(locale, "Deduplication completed"),
+			i18n.T(locale, "Deduplication completed for account %s. Processed: %d, duplicates: %d, errors: %d",
 				account.Email, result.ProcessedCount, result.DuplicateCount, result.ErrorCount),
 			notificationType,
 			account.UserID)
@@ -323,7 +326,7 @@ func (m *StandardDeduplicationManager) GetDeduplicationReport(ctx context.Contex
 		}
 
 		// 生成建议
-		recommendations := m.generateRecommendations(stats, activities)
+		recommendations := m.generateRecommendations(i18n.FromContext(ctx), stats, activities)
 
 		return &DeduplicationReport{
 			AccountID:       accountID,
@@ -337,8 +340,8 @@ func (m *StandardDeduplicationManager) GetDeduplicationReport(ctx context.Contex
 	return nil, fmt.Errorf("enhanced deduplicator not available")
 }
 
-// generateRecommendations 生成去重建议
-func (m *StandardDeduplicationManager) generateRecommendations(stats *DeduplicationStats, activities []*DeduplicationActivity) []*DeduplicationRecommendation {
+// generateRecommendations 按请求的语言生成去重建议
+func (m *StandardDeduplicationManager) generateRecommendations(locale string, stats *DeduplicationStats, activities []*DeduplicationActivity) []*DeduplicationRecommendation {
 	var recommendations []*DeduplicationRecommendation
 
 	// 如果发现大量重复，建议清理
@@ -346,8 +349,8 @@ func (m *StandardDeduplicationManager) generateRecommendations(stats *Deduplicat
 		recommendations = append(recommendations, &DeduplicationRecommendation{
 			Type:        "cleanup",
 			Priority:    "high",
-			Title:       "清理重复邮件",
-			Description: fmt.Sprintf("发现 %d 个重复邮件，建议进行清理以节省存储空间", stats.DuplicatesFound),
+			Title:       i18n.T(locale, "Clean up duplicate emails"),
+			Description: i18n.T(locale, "Found %d duplicate emails, cleaning them up saves storage space", stats.DuplicatesFound),
 			Action:      "cleanup_duplicates",
 		})
 	}
@@ -357,8 +360,8 @@ func (m *StandardDeduplicationManager) generateRecommendations(stats *Deduplicat
 		recommendations = append(recommendations, &DeduplicationRecommendation{
 			Type:        "rebuild_index",
 			Priority:    "medium",
-			Title:       "重建去重索引",
-			Description: "超过30天未更新去重索引，建议重建以提高性能",
+			Title:       i18n.T(locale, "Rebuild deduplication index"),
+			Description: i18n.T(locale, "The deduplication index has not been updated for over 30 days, rebuilding it improves performance"),
 			Action:      "rebuild_index",
 		})
 	}
@@ -376,8 +379,8 @@ func (m *StandardDeduplicationManager) generateRecommendations(stats *Deduplicat
 		recommendations = append(recommendations, &DeduplicationRecommendation{
 			Type:        "schedule",
 			Priority:    "low",
-			Title:       "设置定期去重",
-			Description: "建议设置定期去重任务以保持邮箱整洁",
+			Title:       i18n.T(locale, "Schedule regular deduplication"),
+			Description: i18n.T(locale, "A regular deduplication job keeps the mailbox tidy"),
 			Action:      "schedule_deduplication",
 		})
 	}
@@ -232,26 +232,35 @@ func (d *StandardDeduplicator) HandleDuplicate(ctx context.Context, existing *mo
 		log.Printf("Original context canceled, using new context for duplicate handling")
 	}
 
+	before := *existing
 	switch {
 	case existing.FolderID == nil || *existing.FolderID != folderID:
-		// 更新文件夹信息
+		// 更新文件夹信息和邮件状态
 		existing.FolderID = &folderID
-		return d.db.WithContext(ctx).Save(existing).Error
+		d.applySyncedFlags(existing, new.Flags)
+		return saveSyncedEmailState(d.db.WithContext(ctx), &before, existing)
 
 	case existing.MessageID == "" && new.MessageID != "":
 		// 补充MessageID信息
 		existing.MessageID = new.MessageID
-		return d.db.WithContext(ctx).Save(existing).Error
+		return saveSyncedEmailState(d.db.WithContext(ctx), &before, existing)
 
 	default:
 		// 更新邮件状态（如已读状态等）
-		existing.IsRead = d.isEmailRead(new.Flags)
-		existing.IsStarred = d.isEmailStarred(new.Flags)
-		existing.IsDraft = d.isEmailDraft(new.Flags)
-		return d.db.WithContext(ctx).Save(existing).Error
+		d.applySyncedFlags(existing, new.Flags)
+		return saveSyncedEmailState(d.db.WithContext(ctx), &before, existing)
 	}
 }
 
+// applySyncedFlags 根据服务器上的标志更新已读、星标、草稿、旗标和已回复状态
+func (d *StandardDeduplicator) applySyncedFlags(email *models.Email, flags []string) {
+	email.IsRead = d.isEmailRead(flags)
+	email.IsStarred = d.isEmailStarred(flags)
+	email.IsDraft = d.isEmailDraft(flags)
+	applyFlagKeywords(email, flags)
+	applyReplyFlags(email, flags)
+}
+
 // 辅助方法：检查邮件标志
 func (d *StandardDeduplicator) isEmailRead(flags []string) bool {
 	for _, flag := range flags {
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// maxDraftRevisions 每个草稿保留的修订数量
+	maxDraftRevisions = 20
+	// draftRevisionMergeWindow 连续自动保存在该时间窗口内合并为同一修订
+	draftRevisionMergeWindow = 2 * time.Minute
+)
+
+// AutosaveDraft 自动保存草稿（仅做权限检查，不做完整校验，也不同步到服务器）
+func (s *DraftServiceImpl) AutosaveDraft(ctx context.Context, userID, draftID uint, req *UpdateDraftRequest) (*models.Draft, error) {
+	draft, err := s.getDraftWithPermissionCheck(ctx, draftID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if draft.IsTemplate {
+		return nil, fmt.Errorf("cannot update template as draft")
+	}
+
+	if err := applyDraftUpdate(draft, req); err != nil {
+		return nil, err
+	}
+	draft.UpdateLastEditedAt()
+
+	// 只写入草稿内容列，避免整行保存带来的额外开销
+	if err := s.db.WithContext(ctx).Model(draft).
+		Select("subject", "to_addresses", "cc_addresses", "bcc_addresses", "text_body", "html_body", "markdown_body", "attachment_ids", "priority", "last_edited_at").
+		Updates(draft).Error; err != nil {
+		return nil, fmt.Errorf("failed to autosave draft: %w", err)
+	}
+
+	s.recordDraftRevision(ctx, draft, models.DraftRevisionSourceAutosave)
+
+	return draft, nil
+}
+
+// ListDraftRevisions 列出草稿修订历史（最新的在前）
+func (s *DraftServiceImpl) ListDraftRevisions(ctx context.Context, userID, draftID uint) ([]*models.DraftRevision, error) {
+	if _, err := s.getDraftWithPermissionCheck(ctx, draftID, userID); err != nil {
+		return nil, err
+	}
+
+	var revisions []*models.DraftRevision
+	if err := s.db.WithContext(ctx).
+		Where("draft_id = ? AND user_id = ?", draftID, userID).
+		Order("revision DESC").
+		Find(&revisions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list draft revisions: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// RestoreDraftRevision 将草稿恢复到指定修订
+func (s *DraftServiceImpl) RestoreDraftRevision(ctx context.Context, userID, draftID, revisionID uint) (*models.Draft, error) {
+	draft, err := s.getDraftWithPermissionCheck(ctx, draftID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var revision models.DraftRevision
+	if err := s.db.WithContext(ctx).
+		Where("id = ? AND draft_id = ? AND user_id = ?", revisionID, draftID, userID).
+		First(&revision).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("draft revision not found")
+		}
+		return nil, fmt.Errorf("failed to get draft revision: %w", err)
+	}
+
+	revision.ApplyTo(draft)
+	draft.UpdateLastEditedAt()
+
+	if err := s.db.WithContext(ctx).Save(draft).Error; err != nil {
+		return nil, fmt.Errorf("failed to restore draft: %w", err)
+	}
+
+	s.recordDraftRevision(ctx, draft, models.DraftRevisionSourceRestore)
+	s.pushDraftToServer(draft)
+
+	return draft, nil
+}
+
+// recordDraftRevision 记录草稿修订，失败只记录日志
+func (s *DraftServiceImpl) recordDraftRevision(ctx context.Context, draft *models.Draft, source string) {
+	if err := s.saveDraftRevision(ctx, draft, source); err != nil {
+		log.Printf("Failed to record revision for draft %d: %v", draft.ID, err)
+	}
+}
+
+func (s *DraftServiceImpl) saveDraftRevision(ctx context.Context, draft *models.Draft, source string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var latest models.DraftRevision
+		err := tx.Where("draft_id = ?", draft.ID).Order("revision DESC").First(&latest).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return fmt.Errorf("failed to get latest draft revision: %w", err)
+		}
+		hasLatest := err == nil
+
+		// 连续自动保存合并到最近一次自动保存修订，避免修订被快速挤出
+		if hasLatest && source == models.DraftRevisionSourceAutosave &&
+			latest.Source == models.DraftRevisionSourceAutosave &&
+			time.Since(latest.CreatedAt) < draftRevisionMergeWindow {
+			snapshot := models.NewDraftRevision(draft, latest.Revision, source)
+			snapshot.ID = latest.ID
+			snapshot.CreatedAt = latest.CreatedAt
+			if err := tx.Save(snapshot).Error; err != nil {
+				return fmt.Errorf("failed to update draft revision: %w", err)
+			}
+			return nil
+		}
+
+		next := 1
+		if hasLatest {
+			next = latest.Revision + 1
+		}
+		if err := tx.Create(models.NewDraftRevision(draft, next, source)).Error; err != nil {
+			return fmt.Errorf("failed to create draft revision: %w", err)
+		}
+
+		// 只保留最近的修订
+		if next > maxDraftRevisions {
+			if err := tx.Unscoped().
+				Where("draft_id = ? AND revision <= ?", draft.ID, next-maxDraftRevisions).
+				Delete(&models.DraftRevision{}).Error; err != nil {
+				return fmt.Errorf("failed to prune draft revisions: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
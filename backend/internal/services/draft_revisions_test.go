@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDraftAutosaveKeepsRevisionHistory(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.Draft{}, &models.DraftRevision{}))
+	ctx := context.Background()
+
+	service := NewDraftService(env.db).(*DraftServiceImpl)
+	draft, err := service.CreateDraft(ctx, env.user.ID, &CreateDraftRequest{
+		AccountID: env.account.ID,
+		Subject:   "周报",
+		TextBody:  "第一版",
+	})
+	require.NoError(t, err)
+
+	// 连续自动保存只产生一条修订
+	for _, body := range []string{"第二版", "第三版"} {
+		body := body
+		_, err := service.AutosaveDraft(ctx, env.user.ID, draft.ID, &UpdateDraftRequest{TextBody: &body})
+		require.NoError(t, err)
+	}
+
+	revisions, err := service.ListDraftRevisions(ctx, env.user.ID, draft.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	require.Equal(t, models.DraftRevisionSourceAutosave, revisions[0].Source)
+	require.Equal(t, "第三版", revisions[0].TextBody)
+	require.Equal(t, "第一版", revisions[1].TextBody)
+
+	restored, err := service.RestoreDraftRevision(ctx, env.user.ID, draft.ID, revisions[1].ID)
+	require.NoError(t, err)
+	require.Equal(t, "第一版", restored.TextBody)
+	require.Equal(t, "周报", restored.Subject)
+
+	var stored models.Draft
+	require.NoError(t, env.db.First(&stored, draft.ID).Error)
+	require.Equal(t, "第一版", stored.TextBody)
+
+	_, err = service.ListDraftRevisions(ctx, env.user.ID+1, draft.ID)
+	require.Error(t, err)
+}
+
+func TestDraftRevisionsArePrunedAndExpired(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.Draft{}, &models.DraftRevision{}))
+	ctx := context.Background()
+
+	service := NewDraftService(env.db).(*DraftServiceImpl)
+	draft, err := service.CreateDraft(ctx, env.user.ID, &CreateDraftRequest{
+		AccountID: env.account.ID,
+		Subject:   "草稿",
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < maxDraftRevisions+5; i++ {
+		service.recordDraftRevision(ctx, draft, models.DraftRevisionSourceSave)
+	}
+
+	var count int64
+	require.NoError(t, env.db.Model(&models.DraftRevision{}).Where("draft_id = ?", draft.ID).Count(&count).Error)
+	require.Equal(t, int64(maxDraftRevisions), count)
+
+	// 将部分修订标记为过期
+	old := time.Now().AddDate(0, 0, -60)
+	require.NoError(t, env.db.Model(&models.DraftRevision{}).
+		Where("draft_id = ? AND revision <= ?", draft.ID, 10).
+		UpdateColumn("created_at", old).Error)
+
+	cleaner := NewSoftDeleteService(env.db).(*SoftDeleteServiceImpl)
+	cleaned, err := cleaner.cleanupExpiredDraftRevisions(ctx, time.Now().AddDate(0, 0, -30))
+	require.NoError(t, err)
+	require.Equal(t, 4, cleaned)
+
+	// 草稿删除后其修订全部清理
+	require.NoError(t, env.db.Delete(&models.Draft{}, draft.ID).Error)
+	cleaned, err = cleaner.cleanupExpiredDraftRevisions(ctx, time.Now().AddDate(0, 0, -30))
+	require.NoError(t, err)
+	require.Equal(t, maxDraftRevisions-4, cleaned)
+}
+
+func TestDraftKeepsMarkdownSource(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.Draft{}, &models.DraftRevision{}))
+	ctx := context.Background()
+
+	service := NewDraftService(env.db).(*DraftServiceImpl)
+	draft, err := service.CreateDraft(ctx, env.user.ID, &CreateDraftRequest{
+		AccountID:    env.account.ID,
+		Subject:      "周报",
+		MarkdownBody: "# 本周\n\n- 完成 *发布*",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "<h1>本周</h1>\n<ul>\n<li>完成 <em>发布</em></li>\n</ul>", draft.HTMLBody)
+	require.Equal(t, "# 本周\n\n- 完成 _发布_", draft.TextBody)
+
+	source := "第二版 **加粗**"
+	_, err = service.AutosaveDraft(ctx, env.user.ID, draft.ID, &UpdateDraftRequest{MarkdownBody: &source})
+	require.NoError(t, err)
+
+	var stored models.Draft
+	require.NoError(t, env.db.First(&stored, draft.ID).Error)
+	require.Equal(t, source, stored.MarkdownBody)
+	require.Equal(t, "<p>第二版 <strong>加粗</strong></p>", stored.HTMLBody)
+
+	revisions, err := service.ListDraftRevisions(ctx, env.user.ID, draft.ID)
+	require.NoError(t, err)
+	restored, err := service.RestoreDraftRevision(ctx, env.user.ID, draft.ID, revisions[len(revisions)-1].ID)
+	require.NoError(t, err)
+	require.Equal(t, "# 本周\n\n- 完成 *发布*", restored.MarkdownBody)
+}
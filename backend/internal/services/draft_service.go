@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"log"
 
 	"firemail/internal/models"
 
@@ -28,11 +29,20 @@ type DraftService interface {
 	// 转换操作
 	ConvertDraftToTemplate(ctx context.Context, userID, draftID uint, templateName string) (*models.Draft, error)
 	ConvertTemplateToDraft(ctx context.Context, userID, templateID uint) (*models.Draft, error)
+
+	// 服务器同步
+	ImportRemoteDrafts(ctx context.Context, userID, accountID uint) (int, error)
+
+	// 自动保存与修订历史
+	AutosaveDraft(ctx context.Context, userID, draftID uint, req *UpdateDraftRequest) (*models.Draft, error)
+	ListDraftRevisions(ctx context.Context, userID, draftID uint) ([]*models.DraftRevision, error)
+	RestoreDraftRevision(ctx context.Context, userID, draftID, revisionID uint) (*models.Draft, error)
 }
 
 // DraftServiceImpl 草稿服务实现
 type DraftServiceImpl struct {
-	db *gorm.DB
+	db          *gorm.DB
+	draftSyncer DraftSyncer // 草稿IMAP同步（可选）
 }
 
 // NewDraftService 创建草稿服务
@@ -42,6 +52,11 @@ func NewDraftService(db *gorm.DB) DraftService {
 	}
 }
 
+// SetDraftSyncer 设置草稿同步依赖
+func (s *DraftServiceImpl) SetDraftSyncer(draftSyncer DraftSyncer) {
+	s.draftSyncer = draftSyncer
+}
+
 // CreateDraftRequest 创建草稿请求
 type CreateDraftRequest struct {
 	AccountID     uint                    `json:"account_id" binding:"required"`
@@ -51,6 +66,7 @@ type CreateDraftRequest struct {
 	BCC           []models.EmailAddress   `json:"bcc"`
 	TextBody      string                  `json:"text_body"`
 	HTMLBody      string                  `json:"html_body"`
+	MarkdownBody  string                  `json:"markdown_body"` // 设置时由服务端渲染HTML和纯文本正文
 	AttachmentIDs []uint                  `json:"attachment_ids"`
 	Priority      string                  `json:"priority"`
 }
@@ -63,17 +79,18 @@ type UpdateDraftRequest struct {
 	BCC           *[]models.EmailAddress  `json:"bcc"`
 	TextBody      *string                 `json:"text_body"`
 	HTMLBody      *string                 `json:"html_body"`
+	MarkdownBody  *string                 `json:"markdown_body"` // 设置为非空时由服务端渲染HTML和纯文本正文
 	AttachmentIDs *[]uint                 `json:"attachment_ids"`
 	Priority      *string                 `json:"priority"`
 }
 
 // ListDraftsRequest 列出草稿请求
 type ListDraftsRequest struct {
-	AccountID *uint  `json:"account_id"`
-	Page      int    `json:"page"`
-	PageSize  int    `json:"page_size"`
-	SortBy    string `json:"sort_by"`    // created_at, updated_at, last_edited_at, subject
-	SortOrder string `json:"sort_order"` // asc, desc
+	AccountID *uint  `json:"account_id" form:"account_id"`
+	Page      int    `json:"page" form:"page"`
+	PageSize  int    `json:"page_size" form:"page_size"`
+	SortBy    string `json:"sort_by" form:"sort_by"`       // created_at, updated_at, last_edited_at, subject
+	SortOrder string `json:"sort_order" form:"sort_order"` // asc, desc
 }
 
 // ListDraftsResponse 列出草稿响应
@@ -146,6 +163,7 @@ func (s *DraftServiceImpl) CreateDraft(ctx context.Context, userID uint, req *Cr
 		HTMLBody:  req.HTMLBody,
 		Priority:  req.Priority,
 	}
+	applyDraftMarkdown(draft, req.MarkdownBody, req.TextBody != "")
 	
 	// 设置默认优先级
 	if draft.Priority == "" {
@@ -178,6 +196,9 @@ func (s *DraftServiceImpl) CreateDraft(ctx context.Context, userID uint, req *Cr
 		return nil, fmt.Errorf("failed to create draft: %w", err)
 	}
 	
+	s.recordDraftRevision(ctx, draft, models.DraftRevisionSourceSave)
+	s.pushDraftToServer(draft)
+	
 	return draft, nil
 }
 
@@ -195,55 +216,84 @@ func (s *DraftServiceImpl) UpdateDraft(ctx context.Context, userID, draftID uint
 	}
 	
 	// 更新字段
+	if err := applyDraftUpdate(draft, req); err != nil {
+		return nil, err
+	}
+	
+	// 更新最后编辑时间
+	draft.UpdateLastEditedAt()
+	
+	// 保存到数据库
+	if err := s.db.WithContext(ctx).Save(draft).Error; err != nil {
+		return nil, fmt.Errorf("failed to update draft: %w", err)
+	}
+	
+	s.recordDraftRevision(ctx, draft, models.DraftRevisionSourceSave)
+	s.pushDraftToServer(draft)
+	
+	return draft, nil
+}
+
+// applyDraftMarkdown 保存Markdown源文本并渲染草稿正文；keepText 为真时保留请求中给出的纯文本正文
+func applyDraftMarkdown(draft *models.Draft, source string, keepText bool) {
+	draft.MarkdownBody = source
+	if source == "" {
+		return
+	}
+	rendered := RenderMarkdown(source, nil)
+	draft.HTMLBody = rendered.HTMLBody
+	if !keepText {
+		draft.TextBody = rendered.TextBody
+	}
+}
+
+// applyDraftUpdate 将部分更新应用到草稿
+func applyDraftUpdate(draft *models.Draft, req *UpdateDraftRequest) error {
 	if req.Subject != nil {
 		draft.Subject = *req.Subject
 	}
-	
+
 	if req.TextBody != nil {
 		draft.TextBody = *req.TextBody
 	}
-	
+
 	if req.HTMLBody != nil {
 		draft.HTMLBody = *req.HTMLBody
 	}
-	
+
+	if req.MarkdownBody != nil {
+		applyDraftMarkdown(draft, *req.MarkdownBody, req.TextBody != nil)
+	}
+
 	if req.Priority != nil {
 		draft.Priority = *req.Priority
 	}
-	
+
 	if req.To != nil {
 		if err := draft.SetToAddresses(*req.To); err != nil {
-			return nil, fmt.Errorf("failed to set to addresses: %w", err)
+			return fmt.Errorf("failed to set to addresses: %w", err)
 		}
 	}
-	
+
 	if req.CC != nil {
 		if err := draft.SetCCAddresses(*req.CC); err != nil {
-			return nil, fmt.Errorf("failed to set cc addresses: %w", err)
+			return fmt.Errorf("failed to set cc addresses: %w", err)
 		}
 	}
-	
+
 	if req.BCC != nil {
 		if err := draft.SetBCCAddresses(*req.BCC); err != nil {
-			return nil, fmt.Errorf("failed to set bcc addresses: %w", err)
+			return fmt.Errorf("failed to set bcc addresses: %w", err)
 		}
 	}
-	
+
 	if req.AttachmentIDs != nil {
 		if err := draft.SetAttachmentIDs(*req.AttachmentIDs); err != nil {
-			return nil, fmt.Errorf("failed to set attachment ids: %w", err)
+			return fmt.Errorf("failed to set attachment ids: %w", err)
 		}
 	}
-	
-	// 更新最后编辑时间
-	draft.UpdateLastEditedAt()
-	
-	// 保存到数据库
-	if err := s.db.WithContext(ctx).Save(draft).Error; err != nil {
-		return nil, fmt.Errorf("failed to update draft: %w", err)
-	}
-	
-	return draft, nil
+
+	return nil
 }
 
 // GetDraft 获取草稿
@@ -341,6 +391,8 @@ func (s *DraftServiceImpl) DeleteDraft(ctx context.Context, userID, draftID uint
 		return fmt.Errorf("failed to delete draft: %w", err)
 	}
 
+	s.removeDraftFromServer(draft)
+
 	return nil
 }
 
@@ -560,6 +612,8 @@ func (s *DraftServiceImpl) ConvertDraftToTemplate(ctx context.Context, userID, d
 		return nil, fmt.Errorf("failed to convert draft to template: %w", err)
 	}
 
+	s.removeDraftFromServer(draft)
+
 	return draft, nil
 }
 
@@ -597,6 +651,53 @@ func (s *DraftServiceImpl) ConvertTemplateToDraft(ctx context.Context, userID, t
 	return draft, nil
 }
 
+// ImportRemoteDrafts 导入服务器Drafts文件夹中其他客户端创建的草稿
+func (s *DraftServiceImpl) ImportRemoteDrafts(ctx context.Context, userID, accountID uint) (int, error) {
+	if s.draftSyncer == nil {
+		return 0, fmt.Errorf("draft synchronization is not enabled")
+	}
+
+	if err := s.validateAccountAccess(ctx, accountID, userID); err != nil {
+		return 0, err
+	}
+
+	return s.draftSyncer.ImportDrafts(ctx, userID, accountID)
+}
+
+// pushDraftToServer 异步将草稿同步到服务器（失败只记录日志，不影响本地保存）
+func (s *DraftServiceImpl) pushDraftToServer(draft *models.Draft) {
+	if s.draftSyncer == nil {
+		return
+	}
+
+	snapshot := *draft
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), draftSyncTimeout)
+		defer cancel()
+
+		if err := s.draftSyncer.PushDraft(ctx, &snapshot); err != nil {
+			log.Printf("Failed to sync draft %d to server: %v", snapshot.ID, err)
+		}
+	}()
+}
+
+// removeDraftFromServer 异步删除服务器上的草稿副本
+func (s *DraftServiceImpl) removeDraftFromServer(draft *models.Draft) {
+	if s.draftSyncer == nil {
+		return
+	}
+
+	snapshot := *draft
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), draftSyncTimeout)
+		defer cancel()
+
+		if err := s.draftSyncer.RemoveDraft(ctx, &snapshot); err != nil {
+			log.Printf("Failed to remove draft %d from server: %v", snapshot.ID, err)
+		}
+	}()
+}
+
 // validateAccountAccess 验证账户访问权限
 func (s *DraftServiceImpl) validateAccountAccess(ctx context.Context, accountID, userID uint) error {
 	var count int64
@@ -0,0 +1,461 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"firemail/internal/models"
+	"firemail/internal/providers"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultDraftsFolderName = "Drafts"
+	draftSyncTimeout        = 2 * time.Minute
+)
+
+// DraftSyncer 草稿IMAP同步接口
+type DraftSyncer interface {
+	// PushDraft 将草稿APPEND到服务器Drafts文件夹（已存在时替换旧版本）
+	PushDraft(ctx context.Context, draft *models.Draft) error
+	// RemoveDraft 删除服务器上的草稿副本
+	RemoveDraft(ctx context.Context, draft *models.Draft) error
+	// ImportDrafts 导入其他客户端在服务器上创建的草稿
+	ImportDrafts(ctx context.Context, userID, accountID uint) (int, error)
+}
+
+// IMAPDraftSyncer 基于IMAP APPEND的草稿同步实现
+type IMAPDraftSyncer struct {
+	db              *gorm.DB
+	providerFactory providers.ProviderFactoryInterface
+
+	draftLocksMutex sync.Mutex
+	draftLocks      map[uint]*draftLock // 正在同步的草稿，没有调用方持有或等待时删除
+}
+
+// draftLock 单个草稿的同步锁，refs 为持有和等待该锁的调用方数量
+type draftLock struct {
+	sync.Mutex
+	refs int
+}
+
+// NewIMAPDraftSyncer 创建草稿同步器
+func NewIMAPDraftSyncer(db *gorm.DB, providerFactory providers.ProviderFactoryInterface) *IMAPDraftSyncer {
+	return &IMAPDraftSyncer{
+		db:              db,
+		providerFactory: providerFactory,
+		draftLocks:      make(map[uint]*draftLock),
+	}
+}
+
+// PushDraft 将草稿同步到服务器
+func (s *IMAPDraftSyncer) PushDraft(ctx context.Context, draft *models.Draft) error {
+	if draft == nil || draft.IsTemplate {
+		return nil
+	}
+
+	unlock := s.lockDraft(draft.ID)
+	defer unlock()
+
+	// 重新加载最新内容与同步状态，避免并发保存时重复APPEND
+	current, err := s.reloadDraft(ctx, draft.ID)
+	if err != nil {
+		return err
+	}
+	if current.DeletedAt.Valid {
+		return nil
+	}
+	draft = current
+
+	account, err := s.loadAccount(ctx, draft.AccountID)
+	if err != nil {
+		return err
+	}
+
+	provider, imapClient, err := s.connect(ctx, account)
+	if err != nil {
+		return err
+	}
+	defer provider.Disconnect()
+
+	folderName := s.resolveDraftsFolder(ctx, account.ID, imapClient)
+
+	// 替换旧版本：先删除服务器上的旧草稿
+	if err := s.deleteRemoteCopy(ctx, imapClient, draft); err != nil {
+		log.Printf("Failed to remove previous remote draft %d: %v", draft.ID, err)
+	}
+
+	messageID := generateDraftMessageID(draft, account.Email)
+	message, err := buildDraftOutgoingMessage(draft, account, messageID)
+	if err != nil {
+		return err
+	}
+
+	data, err := providers.BuildMessage(message)
+	if err != nil {
+		return fmt.Errorf("failed to build draft message: %w", err)
+	}
+
+	if err := imapClient.AppendMessage(ctx, folderName, []string{"\\Draft", "\\Seen"}, time.Now(), data); err != nil {
+		return fmt.Errorf("failed to append draft: %w", err)
+	}
+
+	// 查找新草稿的UID（服务器不一定支持UIDPLUS）
+	var remoteUID uint32
+	uids, err := imapClient.SearchEmails(ctx, &providers.SearchCriteria{
+		FolderName: folderName,
+		MessageID:  messageID,
+	})
+	if err != nil {
+		log.Printf("Failed to locate appended draft %d: %v", draft.ID, err)
+	} else {
+		for _, uid := range uids {
+			if uid > remoteUID {
+				remoteUID = uid
+			}
+		}
+	}
+
+	now := time.Now()
+	draft.RemoteMessageID = messageID
+	draft.RemoteUID = remoteUID
+	draft.RemoteFolder = folderName
+	draft.RemoteSyncedAt = &now
+
+	if err := s.db.WithContext(ctx).Model(&models.Draft{}).
+		Where("id = ?", draft.ID).
+		UpdateColumns(map[string]interface{}{
+			"remote_message_id": messageID,
+			"remote_uid":        remoteUID,
+			"remote_folder":     folderName,
+			"remote_synced_at":  now,
+		}).Error; err != nil {
+		return fmt.Errorf("failed to update draft sync state: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveDraft 删除服务器上的草稿
+func (s *IMAPDraftSyncer) RemoveDraft(ctx context.Context, draft *models.Draft) error {
+	if draft == nil {
+		return nil
+	}
+
+	unlock := s.lockDraft(draft.ID)
+	defer unlock()
+
+	// 等待中的同步可能已更新服务器副本，重新读取同步状态
+	if current, err := s.reloadDraft(ctx, draft.ID); err == nil {
+		draft = current
+	}
+	if draft.RemoteFolder == "" {
+		return nil
+	}
+
+	account, err := s.loadAccount(ctx, draft.AccountID)
+	if err != nil {
+		return err
+	}
+
+	provider, imapClient, err := s.connect(ctx, account)
+	if err != nil {
+		return err
+	}
+	defer provider.Disconnect()
+
+	return s.deleteRemoteCopy(ctx, imapClient, draft)
+}
+
+// ImportDrafts 导入服务器上的草稿
+func (s *IMAPDraftSyncer) ImportDrafts(ctx context.Context, userID, accountID uint) (int, error) {
+	var account models.EmailAccount
+	if err := s.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", accountID, userID).
+		First(&account).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, fmt.Errorf("account not found or access denied")
+		}
+		return 0, fmt.Errorf("failed to get account: %w", err)
+	}
+
+	provider, imapClient, err := s.connect(ctx, &account)
+	if err != nil {
+		return 0, err
+	}
+	defer provider.Disconnect()
+
+	folderName := s.resolveDraftsFolder(ctx, account.ID, imapClient)
+
+	status, err := imapClient.SelectFolder(ctx, folderName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to select drafts folder: %w", err)
+	}
+	if status != nil && status.TotalEmails == 0 {
+		return 0, nil
+	}
+
+	messages, err := imapClient.FetchEmails(ctx, &providers.FetchCriteria{
+		FolderName:  folderName,
+		IncludeBody: true,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch remote drafts: %w", err)
+	}
+	defer providers.ReleaseEmailContents(messages)
+
+	imported := 0
+	for _, message := range messages {
+		if message == nil || message.MessageID == "" {
+			continue
+		}
+
+		var count int64
+		if err := s.db.WithContext(ctx).Unscoped().Model(&models.Draft{}).
+			Where("account_id = ? AND remote_message_id = ?", account.ID, message.MessageID).
+			Count(&count).Error; err != nil {
+			return imported, fmt.Errorf("failed to check existing draft: %w", err)
+		}
+		if count > 0 {
+			continue
+		}
+
+		draft, err := newDraftFromRemoteMessage(userID, account.ID, folderName, message)
+		if err != nil {
+			log.Printf("Failed to convert remote draft %s: %v", message.MessageID, err)
+			continue
+		}
+
+		if err := s.db.WithContext(ctx).Create(draft).Error; err != nil {
+			return imported, fmt.Errorf("failed to import draft: %w", err)
+		}
+		imported++
+	}
+
+	return imported, nil
+}
+
+// deleteRemoteCopy 删除服务器上的草稿副本（调用方负责连接）
+func (s *IMAPDraftSyncer) deleteRemoteCopy(ctx context.Context, imapClient providers.IMAPClient, draft *models.Draft) error {
+	if draft.RemoteFolder == "" {
+		return nil
+	}
+
+	uid := draft.RemoteUID
+	if uid == 0 && draft.RemoteMessageID != "" {
+		uids, err := imapClient.SearchEmails(ctx, &providers.SearchCriteria{
+			FolderName: draft.RemoteFolder,
+			MessageID:  draft.RemoteMessageID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to search remote draft: %w", err)
+		}
+		if len(uids) > 0 {
+			uid = uids[len(uids)-1]
+		}
+	}
+	if uid == 0 {
+		return nil
+	}
+
+	if _, err := imapClient.SelectFolder(ctx, draft.RemoteFolder); err != nil {
+		return fmt.Errorf("failed to select drafts folder: %w", err)
+	}
+
+	if err := imapClient.DeleteEmails(ctx, []uint32{uid}); err != nil {
+		return fmt.Errorf("failed to delete remote draft: %w", err)
+	}
+
+	return nil
+}
+
+// resolveDraftsFolder 确定账户的草稿文件夹
+func (s *IMAPDraftSyncer) resolveDraftsFolder(ctx context.Context, accountID uint, imapClient providers.IMAPClient) string {
+	var folder models.Folder
+	err := s.db.WithContext(ctx).
+		Where("account_id = ? AND type = ?", accountID, models.FolderTypeDrafts).
+		First(&folder).Error
+	if err == nil && folder.GetFullPath() != "" {
+		return folder.GetFullPath()
+	}
+
+	if folders, err := imapClient.ListFolders(ctx); err == nil {
+		for _, info := range folders {
+			if info != nil && info.Type == models.FolderTypeDrafts {
+				if info.Path != "" {
+					return info.Path
+				}
+				return info.Name
+			}
+		}
+	}
+
+	return defaultDraftsFolderName
+}
+
+func (s *IMAPDraftSyncer) reloadDraft(ctx context.Context, draftID uint) (*models.Draft, error) {
+	var draft models.Draft
+	if err := s.db.WithContext(ctx).Unscoped().First(&draft, draftID).Error; err != nil {
+		return nil, fmt.Errorf("draft not found: %w", err)
+	}
+	return &draft, nil
+}
+
+func (s *IMAPDraftSyncer) loadAccount(ctx context.Context, accountID uint) (*models.EmailAccount, error) {
+	var account models.EmailAccount
+	if err := s.db.WithContext(ctx).First(&account, accountID).Error; err != nil {
+		return nil, fmt.Errorf("account not found: %w", err)
+	}
+	return &account, nil
+}
+
+func (s *IMAPDraftSyncer) connect(ctx context.Context, account *models.EmailAccount) (providers.EmailProvider, providers.IMAPClient, error) {
+	provider, err := s.providerFactory.CreateProviderForAccount(account)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create provider: %w", err)
+	}
+
+	// 设置OAuth2 token更新回调（如果支持）
+	if tokenSetter, ok := provider.(providers.TokenCallbackSetter); ok {
+		tokenSetter.SetTokenUpdateCallback(func(ctx context.Context, account *models.EmailAccount) error {
+			return s.db.Model(account).Select("oauth2_token").Updates(map[string]interface{}{
+				"oauth2_token": account.OAuth2Token,
+			}).Error
+		})
+	}
+
+	if err := provider.Connect(ctx, account); err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to email server: %w", err)
+	}
+
+	imapClient := provider.IMAPClient()
+	if imapClient == nil {
+		provider.Disconnect()
+		return nil, nil, fmt.Errorf("IMAP client not available")
+	}
+
+	return provider, imapClient, nil
+}
+
+// lockDraft 锁定草稿的同步操作并返回解锁函数，最后一个调用方解锁后删除该草稿的锁
+func (s *IMAPDraftSyncer) lockDraft(draftID uint) func() {
+	s.draftLocksMutex.Lock()
+	lock, exists := s.draftLocks[draftID]
+	if !exists {
+		lock = &draftLock{}
+		s.draftLocks[draftID] = lock
+	}
+	lock.refs++
+	s.draftLocksMutex.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+
+		s.draftLocksMutex.Lock()
+		defer s.draftLocksMutex.Unlock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(s.draftLocks, draftID)
+		}
+	}
+}
+
+// generateDraftMessageID 为草稿生成唯一的Message-ID
+func generateDraftMessageID(draft *models.Draft, accountEmail string) string {
+	domain := "firemail"
+	if parts := strings.Split(accountEmail, "@"); len(parts) == 2 && parts[1] != "" {
+		domain = parts[1]
+	}
+	return fmt.Sprintf("<draft.%d.%d@%s>", draft.ID, time.Now().UnixNano(), domain)
+}
+
+// buildDraftOutgoingMessage 将草稿转换为待APPEND的邮件
+func buildDraftOutgoingMessage(draft *models.Draft, account *models.EmailAccount, messageID string) (*providers.OutgoingMessage, error) {
+	to, err := draft.GetToAddresses()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse to addresses: %w", err)
+	}
+	cc, err := draft.GetCCAddresses()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cc addresses: %w", err)
+	}
+	bcc, err := draft.GetBCCAddresses()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse bcc addresses: %w", err)
+	}
+
+	headers := map[string]string{
+		"Message-ID": messageID,
+	}
+	// 草稿需要保留密送人，发送时才会去除
+	if len(bcc) > 0 {
+		formatted := make([]string, 0, len(bcc))
+		for _, addr := range bcc {
+			if addr.Name != "" {
+				formatted = append(formatted, fmt.Sprintf("%s <%s>", addr.Name, addr.Address))
+			} else {
+				formatted = append(formatted, addr.Address)
+			}
+		}
+		headers["Bcc"] = strings.Join(formatted, ", ")
+	}
+
+	// 附件暂不随草稿同步，服务器副本仅包含正文
+	return &providers.OutgoingMessage{
+		From:     &models.EmailAddress{Name: account.Name, Address: account.Email},
+		To:       convertToEmailAddressPointers(to),
+		CC:       convertToEmailAddressPointers(cc),
+		Subject:  draft.Subject,
+		TextBody: draft.TextBody,
+		HTMLBody: draft.HTMLBody,
+		Headers:  headers,
+		Priority: draft.Priority,
+	}, nil
+}
+
+// newDraftFromRemoteMessage 将服务器草稿转换为本地草稿
+func newDraftFromRemoteMessage(userID, accountID uint, folderName string, message *providers.EmailMessage) (*models.Draft, error) {
+	now := time.Now()
+	draft := &models.Draft{
+		UserID:          userID,
+		AccountID:       accountID,
+		Subject:         message.Subject,
+		TextBody:        message.TextBody,
+		HTMLBody:        message.HTMLBody,
+		Priority:        "normal",
+		RemoteMessageID: message.MessageID,
+		RemoteUID:       message.UID,
+		RemoteFolder:    folderName,
+		RemoteSyncedAt:  &now,
+	}
+	if message.Priority != "" {
+		draft.Priority = message.Priority
+	}
+
+	if err := draft.SetToAddresses(convertEmailAddresses(message.To)); err != nil {
+		return nil, fmt.Errorf("failed to set to addresses: %w", err)
+	}
+	if err := draft.SetCCAddresses(convertEmailAddresses(message.CC)); err != nil {
+		return nil, fmt.Errorf("failed to set cc addresses: %w", err)
+	}
+	if err := draft.SetBCCAddresses(convertEmailAddresses(message.BCC)); err != nil {
+		return nil, fmt.Errorf("failed to set bcc addresses: %w", err)
+	}
+	if err := draft.SetAttachmentIDs([]uint{}); err != nil {
+		return nil, fmt.Errorf("failed to set attachment ids: %w", err)
+	}
+
+	editedAt := message.Date
+	if editedAt.IsZero() {
+		editedAt = now
+	}
+	draft.LastEditedAt = &editedAt
+
+	return draft, nil
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+	"firemail/internal/providers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setupDraftSyncTest(t *testing.T) (*emailStateServiceTestEnv, *IMAPDraftSyncer, *models.Folder) {
+	t.Helper()
+
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.Draft{}))
+
+	drafts := &models.Folder{
+		AccountID:    env.account.ID,
+		Name:         "Drafts",
+		DisplayName:  "草稿箱",
+		Type:         models.FolderTypeDrafts,
+		Path:         "Drafts",
+		Delimiter:    "/",
+		IsSelectable: true,
+		IsSubscribed: true,
+	}
+	require.NoError(t, env.db.Create(drafts).Error)
+
+	return env, NewIMAPDraftSyncer(env.db, env.service.providerFactory), drafts
+}
+
+func TestDraftSyncerPushReplacesPreviousRemoteCopy(t *testing.T) {
+	env, syncer, drafts := setupDraftSyncTest(t)
+	ctx := context.Background()
+
+	draft := &models.Draft{
+		UserID:    env.user.ID,
+		AccountID: env.account.ID,
+		Subject:   "季度报告",
+		TextBody:  "初稿",
+		Priority:  "normal",
+	}
+	require.NoError(t, draft.SetToAddresses([]models.EmailAddress{{Address: "boss@example.com"}}))
+	require.NoError(t, draft.SetBCCAddresses([]models.EmailAddress{{Address: "archive@example.com"}}))
+	require.NoError(t, env.db.Create(draft).Error)
+
+	env.provider.imap.searchUIDs = []uint32{41}
+	require.NoError(t, syncer.PushDraft(ctx, draft))
+
+	require.Len(t, env.provider.imap.appendCalls, 1)
+	appended := env.provider.imap.appendCalls[0]
+	require.Equal(t, drafts.Path, appended.Folder)
+	require.Contains(t, appended.Flags, "\\Draft")
+	require.Contains(t, string(appended.Data), "Bcc: archive@example.com")
+	require.Empty(t, env.provider.imap.deleteCalls)
+
+	var stored models.Draft
+	require.NoError(t, env.db.First(&stored, draft.ID).Error)
+	require.Equal(t, uint32(41), stored.RemoteUID)
+	require.Equal(t, "Drafts", stored.RemoteFolder)
+	require.True(t, strings.HasPrefix(stored.RemoteMessageID, "<draft."))
+
+	// 更新后再次同步：应先删除旧副本再追加新版本
+	env.provider.imap.searchUIDs = []uint32{42}
+	require.NoError(t, syncer.PushDraft(ctx, draft))
+	require.Len(t, env.provider.imap.appendCalls, 2)
+	require.Equal(t, [][]uint32{{41}}, env.provider.imap.deleteCalls)
+
+	require.NoError(t, env.db.First(&stored, draft.ID).Error)
+	require.Equal(t, uint32(42), stored.RemoteUID)
+
+	require.NoError(t, syncer.RemoveDraft(ctx, &stored))
+	require.Equal(t, [][]uint32{{41}, {42}}, env.provider.imap.deleteCalls)
+
+	// 同步结束后不保留草稿的锁
+	require.Empty(t, syncer.draftLocks)
+}
+
+func TestDraftSyncerLockIsReleasedAfterLastHolder(t *testing.T) {
+	syncer := NewIMAPDraftSyncer(nil, nil)
+
+	unlock := syncer.lockDraft(1)
+	acquired := make(chan func())
+	go func() {
+		acquired <- syncer.lockDraft(1)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second caller acquired the draft lock while it was held")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	// 等待中的调用方仍引用该锁，第一个调用方解锁后不能删除
+	unlock()
+	unlockSecond := <-acquired
+	syncer.draftLocksMutex.Lock()
+	require.Len(t, syncer.draftLocks, 1)
+	syncer.draftLocksMutex.Unlock()
+
+	unlockSecond()
+	require.Empty(t, syncer.draftLocks)
+}
+
+func TestDraftSyncerImportsServerDraftsOnce(t *testing.T) {
+	env, syncer, _ := setupDraftSyncTest(t)
+	ctx := context.Background()
+
+	env.provider.imap.fetchMessages = []*providers.EmailMessage{
+		{
+			UID:       7,
+			MessageID: "<other-client@example.com>",
+			Subject:   "来自手机的草稿",
+			To:        []*models.EmailAddress{{Name: "Alice", Address: "alice@example.com"}},
+			TextBody:  "稍后完成",
+			Date:      time.Now().Add(-time.Hour),
+		},
+	}
+
+	imported, err := syncer.ImportDrafts(ctx, env.user.ID, env.account.ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, imported)
+
+	imported, err = syncer.ImportDrafts(ctx, env.user.ID, env.account.ID)
+	require.NoError(t, err)
+	require.Equal(t, 0, imported)
+
+	var drafts []models.Draft
+	require.NoError(t, env.db.Where("account_id = ?", env.account.ID).Find(&drafts).Error)
+	require.Len(t, drafts, 1)
+	require.Equal(t, "来自手机的草稿", drafts[0].Subject)
+	require.Equal(t, uint32(7), drafts[0].RemoteUID)
+
+	to, err := drafts[0].GetToAddresses()
+	require.NoError(t, err)
+	require.Equal(t, "alice@example.com", to[0].Address)
+}
@@ -14,7 +14,7 @@ import (
 var ErrEmailAccountAlreadyExists = errors.New("email account already exists")
 
 func duplicateEmailAccountError() error {
-	return fmt.Errorf("%w: 该邮箱账户已存在", ErrEmailAccountAlreadyExists)
+	return ErrEmailAccountAlreadyExists
 }
 
 // EnsureEmailAccountUnique 检查同一用户下 email + provider 是否已存在。
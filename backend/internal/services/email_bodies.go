@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"firemail/internal/database/writequeue"
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// legacyEmailBodyBatchSize 每批迁移的邮件数，每批单独提交，避免长时间占用写锁
+const legacyEmailBodyBatchSize = 500
+
+// emailBodySearchCondition 按正文匹配邮件的子查询条件，两个参数分别匹配纯文本和HTML正文
+const emailBodySearchCondition = "EXISTS (SELECT 1 FROM email_bodies WHERE email_bodies.email_id = emails.id AND " +
+	"(email_bodies.text_body LIKE ? OR email_bodies.html_body LIKE ?))"
+
+// saveEmailBody 修改已有邮件的正文
+func saveEmailBody(db *gorm.DB, emailID uint, textBody, htmlBody string) error {
+	if err := models.SaveEmailBody(db, emailID, textBody, htmlBody); err != nil {
+		return fmt.Errorf("failed to save email body: %w", err)
+	}
+	return nil
+}
+
+// loadEmailBody 为未预加载正文的邮件读取正文，没有正文时保持为空
+func loadEmailBody(db *gorm.DB, email *models.Email) error {
+	var body models.EmailBody
+	if err := db.Where("email_id = ?", email.ID).Limit(1).Find(&body).Error; err != nil {
+		return fmt.Errorf("failed to load email body: %w", err)
+	}
+	email.TextBody, email.HTMLBody = body.TextBody, body.HTMLBody
+	return nil
+}
+
+// MoveLegacyEmailBodies 把保存在 emails 表中的历史正文分批移到 email_bodies 表，全部移完后删除原来的正文列。
+// 可重复执行，中断后下次启动从剩余的邮件继续
+func MoveLegacyEmailBodies(ctx context.Context, db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&models.EmailBody{}) || !migrator.HasColumn(&models.Email{}, "text_body") {
+		return nil
+	}
+
+	var lastID uint
+	moved := 0
+	for {
+		var ids []uint
+		if err := db.WithContext(ctx).Raw(
+			"SELECT id FROM emails WHERE id > ? AND (text_body IS NOT NULL OR html_body IS NOT NULL) ORDER BY id LIMIT ?",
+			lastID, legacyEmailBodyBatchSize,
+		).Scan(&ids).Error; err != nil {
+			return fmt.Errorf("failed to find emails with legacy bodies: %w", err)
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		err := writequeue.Transaction(ctx, db.WithContext(ctx), func(tx *gorm.DB) error {
+			if err := tx.Exec(
+				"INSERT INTO email_bodies (email_id, text_body, html_body, updated_at) "+
+					"SELECT id, COALESCE(text_body, ''), COALESCE(html_body, ''), CURRENT_TIMESTAMP FROM emails "+
+					"WHERE id IN ? AND (COALESCE(text_body, '') <> '' OR COALESCE(html_body, '') <> '') "+
+					"ON CONFLICT(email_id) DO NOTHING",
+				ids,
+			).Error; err != nil {
+				return err
+			}
+			return tx.Exec("UPDATE emails SET text_body = NULL, html_body = NULL WHERE id IN ?", ids).Error
+		})
+		if err != nil {
+			return fmt.Errorf("failed to move email bodies: %w", err)
+		}
+
+		lastID = ids[len(ids)-1]
+		moved += len(ids)
+		if moved%(legacyEmailBodyBatchSize*20) == 0 {
+			log.Printf("Moved bodies of %d emails to email_bodies", moved)
+		}
+	}
+
+	// 正文已全部移走，原来的列只剩空值，删除后不再占用空间
+	err := writequeue.Transaction(ctx, db.WithContext(ctx), func(tx *gorm.DB) error {
+		if err := tx.Exec("ALTER TABLE emails DROP COLUMN html_body").Error; err != nil {
+			return err
+		}
+		return tx.Exec("ALTER TABLE emails DROP COLUMN text_body").Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to drop legacy email body columns: %w", err)
+	}
+
+	if moved > 0 {
+		log.Printf("Moved bodies of %d emails to email_bodies", moved)
+	}
+	log.Printf("Dropped legacy email body columns, run 'firemailctl db vacuum' to reclaim the space")
+	return nil
+}
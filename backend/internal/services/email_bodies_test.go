@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailBodyStoredSeparately(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+
+	email := env.createEmail(t, env.inbox, 1, "split", false, false)
+	require.NoError(t, models.SaveEmailBody(env.db, email.ID, "季度报告已附上", "<p>季度报告已附上</p>"))
+
+	// 不预加载时不读取正文
+	var metadata models.Email
+	require.NoError(t, env.db.First(&metadata, email.ID).Error)
+	require.Empty(t, metadata.TextBody)
+
+	loaded, err := env.service.GetEmail(ctx, env.user.ID, email.ID)
+	require.NoError(t, err)
+	require.Equal(t, "季度报告已附上", loaded.TextBody)
+	require.Equal(t, "<p>季度报告已附上</p>", loaded.HTMLBody)
+
+	// 按正文搜索命中，结果与列表一样不含正文，历史邮件的预览即时补齐
+	require.NoError(t, env.db.Model(&models.Email{}).Where("id = ?", email.ID).Update("preview", nil).Error)
+	result, err := env.service.SearchEmails(ctx, env.user.ID, &SearchEmailsRequest{Query: "季度报告"})
+	require.NoError(t, err)
+	require.Len(t, result.Emails, 1)
+	require.Empty(t, result.Emails[0].TextBody)
+	require.NotNil(t, result.Emails[0].Preview)
+	require.Equal(t, "季度报告已附上", *result.Emails[0].Preview)
+
+	require.NoError(t, env.db.Model(email).Update("is_deleted", true).Error)
+	purged, err := env.service.PurgeEmails(ctx, env.user.ID, []uint{email.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), purged)
+	var bodies int64
+	require.NoError(t, env.db.Model(&models.EmailBody{}).Where("email_id = ?", email.ID).Count(&bodies).Error)
+	require.Zero(t, bodies)
+}
+
+func TestMoveLegacyEmailBodies(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+
+	// 分表之前的数据库，正文保存在 emails 表中
+	require.NoError(t, env.db.Exec("ALTER TABLE emails ADD COLUMN text_body TEXT").Error)
+	require.NoError(t, env.db.Exec("ALTER TABLE emails ADD COLUMN html_body TEXT").Error)
+	var ids []uint
+	for i := 0; i < legacyEmailBodyBatchSize+3; i++ {
+		email := &models.Email{AccountID: env.account.ID, FolderID: &env.inbox.ID, MessageID: "legacy", UID: uint32(i + 1)}
+		require.NoError(t, env.db.Create(email).Error)
+		ids = append(ids, email.ID)
+	}
+	require.NoError(t, env.db.Exec("UPDATE emails SET text_body = 'legacy text', html_body = ''").Error)
+	require.NoError(t, env.db.Exec("UPDATE emails SET text_body = '', html_body = NULL WHERE id = ?", ids[1]).Error)
+
+	require.NoError(t, MoveLegacyEmailBodies(ctx, env.db))
+
+	var moved int64
+	require.NoError(t, env.db.Model(&models.EmailBody{}).Count(&moved).Error)
+	require.Equal(t, int64(len(ids)-1), moved)
+
+	var email models.Email
+	require.NoError(t, env.db.Preload("Body").First(&email, ids[len(ids)-1]).Error)
+	require.Equal(t, "legacy text", email.TextBody)
+
+	// 移完后删除原来的列，重复执行没有影响
+	require.False(t, env.db.Migrator().HasColumn(&models.Email{}, "text_body"))
+	require.False(t, env.db.Migrator().HasColumn(&models.Email{}, "html_body"))
+	require.NoError(t, MoveLegacyEmailBodies(ctx, env.db))
+	require.NoError(t, env.db.Model(&models.EmailBody{}).Count(&moved).Error)
+	require.Equal(t, int64(len(ids)-1), moved)
+}
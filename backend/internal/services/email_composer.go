@@ -1,13 +1,13 @@
 package services
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/base64"
 	"fmt"
 	"html"
 	"io"
-	"mime"
 	"mime/multipart"
 	"mime/quotedprintable"
 	"net/textproto"
@@ -17,6 +17,8 @@ import (
 	"time"
 
 	"firemail/internal/models"
+	"firemail/internal/providers"
+	"firemail/internal/sanitize"
 	"gorm.io/gorm"
 )
 
@@ -45,17 +47,23 @@ type ComposeEmailRequest struct {
 	Subject                 string                 `json:"subject" binding:"required"`
 	TextBody                string                 `json:"text_body,omitempty"`
 	HTMLBody                string                 `json:"html_body,omitempty"`
+	MarkdownBody            string                 `json:"markdown_body,omitempty"` // Markdown正文，设置时由服务端渲染HTML正文，忽略 html_body
+	CodeHighlight           *bool                  `json:"code_highlight,omitempty"` // Markdown代码块是否着色，为空时使用服务端配置
 	Attachments             []*EmailAttachment     `json:"attachments,omitempty"`
 	AttachmentIDs           []uint                 `json:"attachment_ids,omitempty"`
 	InlineAttachments       []*InlineAttachment    `json:"inline_attachments,omitempty"`
 	Priority                string                 `json:"priority,omitempty"` // high, normal, low
 	Importance              string                 `json:"importance,omitempty"` // high, normal, low
-	ScheduledTime           *string                `json:"scheduled_time,omitempty"` // ISO 8601 format
+	ScheduledTime           *string                `json:"scheduled_time,omitempty"` // ISO 8601 format，不带时区时按用户设置的时区解析
 	RequestReadReceipt      bool                   `json:"request_read_receipt,omitempty"`
 	RequestDeliveryReceipt  bool                   `json:"request_delivery_receipt,omitempty"`
 	Headers                 map[string]string      `json:"headers,omitempty"`
+	EnvelopeFrom            string                 `json:"envelope_from,omitempty"` // SMTP信封发件人（Return-Path），为空时使用账户退信地址或发件人地址
 	TemplateID              *uint                  `json:"template_id,omitempty"`
 	TemplateData            map[string]interface{} `json:"template_data,omitempty"`
+	DisableTextAlternative  bool                   `json:"disable_text_alternative,omitempty"` // 只有HTML正文时不自动生成纯文本备选部分
+	UserID                  uint                   `json:"-"` // 发件用户，用于模板权限检查
+	SizeLimit               *MessageSizeLimit      `json:"-"` // 发件账户所在提供商的邮件大小上限，为空时不检查
 }
 
 // EmailAttachment 邮件附件
@@ -64,10 +72,19 @@ type EmailAttachment struct {
 	ContentType string    `json:"content_type"`
 	Content     io.Reader `json:"-"`
 	Data        []byte    `json:"data,omitempty"`
+	Path        string    `json:"-"` // 已存储附件的文件路径，发送时按需读取
 	Size        int64     `json:"size"`
 	Encoding    string    `json:"encoding,omitempty"` // base64, quoted-printable
 }
 
+// Open 打开附件内容，文件存储的附件从磁盘流式读取
+func (a *EmailAttachment) Open() (io.ReadCloser, error) {
+	if a.Path != "" {
+		return os.Open(a.Path)
+	}
+	return io.NopCloser(bytes.NewReader(a.Data)), nil
+}
+
 // InlineAttachment 内联附件
 type InlineAttachment struct {
 	ContentID   string    `json:"content_id" binding:"required"`
@@ -93,15 +110,16 @@ type ComposedEmail struct {
 	InlineAttachments []*InlineAttachment    `json:"inline_attachments"`
 	Priority          string                 `json:"priority"`
 	Headers           map[string]string      `json:"headers"`
-	MIMEContent       []byte                 `json:"-"`
+	EnvelopeFrom      string                 `json:"envelope_from,omitempty"`
 	CreatedAt         time.Time              `json:"created_at"`
 	Size              int64                  `json:"size"`
 }
 
 // StandardEmailComposer 标准邮件组装器
 type StandardEmailComposer struct {
-	config *EmailComposerConfig
-	db     *gorm.DB
+	config          *EmailComposerConfig
+	db              *gorm.DB
+	templateService EmailTemplateService
 }
 
 // EmailComposerConfig 邮件组装器配置
@@ -133,6 +151,11 @@ func NewStandardEmailComposer(config *EmailComposerConfig, db *gorm.DB) EmailCom
 	}
 }
 
+// SetTemplateService 设置模板服务依赖
+func (c *StandardEmailComposer) SetTemplateService(templateService EmailTemplateService) {
+	c.templateService = templateService
+}
+
 // ComposeEmail 组装邮件
 func (c *StandardEmailComposer) ComposeEmail(ctx context.Context, request *ComposeEmailRequest) (*ComposedEmail, error) {
 	// 验证请求
@@ -153,18 +176,29 @@ func (c *StandardEmailComposer) ComposeEmail(ctx context.Context, request *Compo
 		HTMLBody:          request.HTMLBody,
 		Priority:          request.Priority,
 		Headers:           request.Headers,
+		EnvelopeFrom:      request.EnvelopeFrom,
 		CreatedAt:         time.Now(),
 	}
 
 	// 处理模板
 	if request.TemplateID != nil {
-		if err := c.processTemplate(ctx, email, *request.TemplateID, request.TemplateData); err != nil {
+		if err := c.processTemplate(ctx, email, request.UserID, *request.TemplateID, request.TemplateData); err != nil {
 			return nil, fmt.Errorf("failed to process template: %w", err)
 		}
 	}
 
-	// 处理HTML内容
-	if email.HTMLBody != "" && c.config.EnableHTMLFilter {
+	// Markdown正文渲染为HTML，纯文本备选部分由下面按渲染结果生成
+	if request.MarkdownBody != "" {
+		email.HTMLBody = RenderMarkdown(request.MarkdownBody, request.CodeHighlight).HTMLBody
+	}
+
+	// 只有HTML正文时生成纯文本备选部分，纯文本客户端可读，也避免被判为垃圾邮件
+	if email.TextBody == "" && email.HTMLBody != "" && !request.DisableTextAlternative {
+		email.TextBody = sanitize.PlainText(email.HTMLBody)
+	}
+
+	// 处理HTML内容；Markdown渲染结果已转义原始HTML，样式为内联，不再过滤
+	if email.HTMLBody != "" && c.config.EnableHTMLFilter && request.MarkdownBody == "" {
 		email.HTMLBody = c.sanitizeHTML(email.HTMLBody)
 	}
 
@@ -194,6 +228,11 @@ func (c *StandardEmailComposer) ComposeEmail(ctx context.Context, request *Compo
 		return nil, fmt.Errorf("failed to build MIME content: %w", err)
 	}
 
+	// 在发送前拒绝超过提供商上限的邮件，避免到SMTP DATA阶段才失败
+	if err := checkMessageSize(email, request.SizeLimit); err != nil {
+		return nil, err
+	}
+
 	// 验证最终邮件
 	if err := c.ValidateEmail(email); err != nil {
 		return nil, fmt.Errorf("email validation failed: %w", err)
@@ -249,10 +288,12 @@ func (c *StandardEmailComposer) AddAttachment(email *ComposedEmail, attachment *
 		attachment.Size = int64(len(data))
 	}
 
-	// 检测MIME类型
-	if attachment.ContentType == "" {
-		attachment.ContentType = c.detectContentType(attachment.Filename, attachment.Data)
+	// 按内容识别类型并检查安全策略
+	contentType, err := checkOutgoingAttachment(attachment.Filename, attachment.ContentType, c.attachmentHead(attachment.Data, attachment.Path))
+	if err != nil {
+		return err
 	}
+	attachment.ContentType = contentType
 
 	// 设置默认编码
 	if attachment.Encoding == "" {
@@ -280,10 +321,12 @@ func (c *StandardEmailComposer) AddInlineAttachment(email *ComposedEmail, attach
 		attachment.Size = int64(len(data))
 	}
 
-	// 检测MIME类型
-	if attachment.ContentType == "" {
-		attachment.ContentType = c.detectContentType(attachment.Filename, attachment.Data)
+	// 按内容识别类型并检查安全策略
+	contentType, err := checkOutgoingAttachment(attachment.Filename, attachment.ContentType, attachment.Data)
+	if err != nil {
+		return err
 	}
+	attachment.ContentType = contentType
 
 	email.InlineAttachments = append(email.InlineAttachments, attachment)
 	return nil
@@ -299,14 +342,20 @@ func (c *StandardEmailComposer) validateRequest(request *ComposeEmailRequest) er
 		return fmt.Errorf("at least one recipient is required")
 	}
 
-	if request.Subject == "" {
+	if request.Subject == "" && request.TemplateID == nil {
 		return fmt.Errorf("subject is required")
 	}
 
-	if request.TextBody == "" && request.HTMLBody == "" && request.TemplateID == nil {
+	if request.TextBody == "" && request.HTMLBody == "" && request.MarkdownBody == "" && request.TemplateID == nil {
 		return fmt.Errorf("email body or template is required")
 	}
 
+	envelopeFrom, err := normalizeEnvelopeAddress(request.EnvelopeFrom)
+	if err != nil {
+		return err
+	}
+	request.EnvelopeFrom = envelopeFrom
+
 	return nil
 }
 
@@ -338,21 +387,12 @@ func (c *StandardEmailComposer) validateAttachment(attachment *EmailAttachment)
 	return nil
 }
 
-// detectContentType 检测内容类型
-func (c *StandardEmailComposer) detectContentType(filename string, data []byte) string {
-	// 首先尝试根据文件扩展名检测
-	ext := filepath.Ext(filename)
-	if mimeType := mime.TypeByExtension(ext); mimeType != "" {
-		return mimeType
-	}
-
-	// 如果有数据，尝试检测内容
-	if len(data) > 0 {
-		// 这里可以使用更复杂的内容检测逻辑
-		// 暂时返回默认类型
+// attachmentHead 附件内容的开头部分，内容未读入内存时从文件读取
+func (c *StandardEmailComposer) attachmentHead(data []byte, path string) []byte {
+	if len(data) > 0 || path == "" {
+		return data
 	}
-
-	return "application/octet-stream"
+	return readAttachmentHead(func() (io.ReadCloser, error) { return os.Open(path) })
 }
 
 // sanitizeHTML 清理HTML内容
@@ -362,21 +402,45 @@ func (c *StandardEmailComposer) sanitizeHTML(htmlContent string) string {
 	return html.EscapeString(htmlContent)
 }
 
-// processTemplate 处理邮件模板
-func (c *StandardEmailComposer) processTemplate(ctx context.Context, email *ComposedEmail, templateID uint, data map[string]interface{}) error {
-	// 注意：这里需要模板服务的实例，但为了避免循环依赖，
-	// 我们将在后续重构中通过依赖注入来解决
-	// 暂时返回提示信息
-	return fmt.Errorf("template processing requires TemplateService dependency injection - will be implemented in service integration")
+// processTemplate 处理邮件模板，请求中显式提供的主题和正文优先于模板内容
+func (c *StandardEmailComposer) processTemplate(ctx context.Context, email *ComposedEmail, userID, templateID uint, data map[string]interface{}) error {
+	if c.templateService == nil {
+		return fmt.Errorf("template service not configured")
+	}
+
+	processed, err := c.templateService.ProcessTemplate(ctx, userID, templateID, data)
+	if err != nil {
+		return err
+	}
+
+	if email.Subject == "" {
+		email.Subject = processed.Subject
+	}
+	if email.TextBody == "" && email.HTMLBody == "" {
+		email.TextBody = processed.TextBody
+		email.HTMLBody = processed.HTMLBody
+	}
+
+	return nil
 }
 
-// buildMIMEContent 构建MIME内容
+// buildMIMEContent 构建MIME内容并计算邮件大小，内容直接流过计数器而不整体缓存
 func (c *StandardEmailComposer) buildMIMEContent(email *ComposedEmail) error {
-	var buf bytes.Buffer
-	writer := multipart.NewWriter(&buf)
+	counter := &countingWriter{w: io.Discard}
+	if err := c.WriteMIME(counter, email); err != nil {
+		return err
+	}
+
+	email.Size = counter.n
+	return nil
+}
+
+// WriteMIME 将邮件按MIME格式写入w，附件边读边编码
+func (c *StandardEmailComposer) WriteMIME(w io.Writer, email *ComposedEmail) error {
+	writer := multipart.NewWriter(w)
 
 	// 设置邮件头
-	if err := c.writeEmailHeaders(&buf, email, writer.Boundary()); err != nil {
+	if err := c.writeEmailHeaders(w, email, writer.Boundary()); err != nil {
 		return fmt.Errorf("failed to write email headers: %w", err)
 	}
 
@@ -395,14 +459,26 @@ func (c *StandardEmailComposer) buildMIMEContent(email *ComposedEmail) error {
 		return fmt.Errorf("failed to close multipart writer: %w", err)
 	}
 
-	email.MIMEContent = buf.Bytes()
-	email.Size = int64(len(email.MIMEContent))
-
 	return nil
 }
 
+// countingWriter 统计写入的字节数
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
 // writeEmailHeaders 写入邮件头
-func (c *StandardEmailComposer) writeEmailHeaders(buf *bytes.Buffer, email *ComposedEmail, boundary string) error {
+func (c *StandardEmailComposer) writeEmailHeaders(w io.Writer, email *ComposedEmail, boundary string) error {
+	buf := bufio.NewWriter(w)
+	defer buf.Flush()
+
 	// From
 	buf.WriteString(fmt.Sprintf("From: %s\r\n", c.formatEmailAddress(email.From)))
 
@@ -428,7 +504,11 @@ func (c *StandardEmailComposer) writeEmailHeaders(buf *bytes.Buffer, email *Comp
 	buf.WriteString(fmt.Sprintf("Date: %s\r\n", email.CreatedAt.Format(time.RFC1123Z)))
 
 	// Message-ID
-	buf.WriteString(fmt.Sprintf("Message-ID: <%s@firemail>\r\n", email.ID))
+	from := ""
+	if email.From != nil {
+		from = email.From.Address
+	}
+	buf.WriteString(fmt.Sprintf("Message-ID: %s\r\n", providers.IdentityForAccount(nil).MessageID(email.ID, from)))
 
 	// Priority
 	if email.Priority != "" {
@@ -539,18 +619,22 @@ func (c *StandardEmailComposer) writeAttachment(writer *multipart.Writer, attach
 		return err
 	}
 
+	content, err := attachment.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open attachment content: %w", err)
+	}
+	defer content.Close()
+
 	// 写入附件内容
 	if attachment.Encoding == "base64" {
 		encoder := base64.NewEncoder(base64.StdEncoding, part)
-		_, err = encoder.Write(attachment.Data)
-		if err != nil {
+		if _, err := io.Copy(encoder, content); err != nil {
 			return err
 		}
 		return encoder.Close()
-	} else {
-		_, err = part.Write(attachment.Data)
-		return err
 	}
+	_, err = io.Copy(part, content)
+	return err
 }
 
 // 辅助方法
@@ -709,20 +793,21 @@ func (c *StandardEmailComposer) loadAttachmentsFromIDs(ctx context.Context, emai
 
 	// 转换为EmailAttachment并添加到邮件
 	for _, attachment := range attachments {
-		// 读取附件文件内容
-		var data []byte
+		if attachment.IsBlocked {
+			return fmt.Errorf("%w: %s: %s", ErrAttachmentBlocked, attachment.Filename, attachment.BlockReason)
+		}
+
+		// 附件内容保留在磁盘上，组装和发送时流式读取
 		if attachment.StoragePath != "" {
-			fileData, err := os.ReadFile(attachment.StoragePath)
-			if err != nil {
+			if _, err := os.Stat(attachment.StoragePath); err != nil {
 				return fmt.Errorf("failed to read attachment file %s: %w", attachment.StoragePath, err)
 			}
-			data = fileData
 		}
 
 		emailAttachment := &EmailAttachment{
 			Filename:    attachment.Filename,
 			ContentType: attachment.ContentType,
-			Data:        data,
+			Path:        attachment.StoragePath,
 			Size:        attachment.Size,
 			Encoding:    c.config.DefaultEncoding,
 		}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestComposerGeneratesTextAlternative(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	composer := NewStandardEmailComposer(&EmailComposerConfig{
+		MaxAttachmentSize:     1024 * 1024,
+		MaxAttachments:        5,
+		MaxRecipientsPerEmail: 10,
+		DefaultEncoding:       "base64",
+	}, env.db).(*StandardEmailComposer)
+
+	request := &ComposeEmailRequest{
+		From:     &models.EmailAddress{Address: "tester@example.com"},
+		To:       []*models.EmailAddress{{Address: "alice@example.org"}},
+		Subject:  "Launch",
+		HTMLBody: `<p>We <b>launched</b> today.</p><ul><li>Read the <a href="https://example.com/post">post</a></li></ul>`,
+	}
+
+	composed, err := composer.ComposeEmail(context.Background(), request)
+	require.NoError(t, err)
+	require.Equal(t, "We **launched** today.\n\n- Read the post (https://example.com/post)", composed.TextBody)
+
+	var buf bytes.Buffer
+	require.NoError(t, composer.WriteMIME(&buf, composed))
+	require.Contains(t, buf.String(), "multipart/alternative")
+	require.Contains(t, buf.String(), "Content-Type: text/plain; charset=utf-8")
+
+	// 显式提供的纯文本正文保持不变
+	request.TextBody = "Plain version"
+	composed, err = composer.ComposeEmail(context.Background(), request)
+	require.NoError(t, err)
+	require.Equal(t, "Plain version", composed.TextBody)
+
+	// 请求关闭自动生成时只发送HTML
+	request.TextBody = ""
+	request.DisableTextAlternative = true
+	composed, err = composer.ComposeEmail(context.Background(), request)
+	require.NoError(t, err)
+	require.Empty(t, composed.TextBody)
+	buf.Reset()
+	require.NoError(t, composer.WriteMIME(&buf, composed))
+	require.NotContains(t, buf.String(), "text/plain")
+}
+
+func TestComposerRendersMarkdownBody(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	composer := NewStandardEmailComposer(&EmailComposerConfig{
+		MaxAttachmentSize:     1024 * 1024,
+		MaxAttachments:        5,
+		MaxRecipientsPerEmail: 10,
+		DefaultEncoding:       "base64",
+		EnableHTMLFilter:      true,
+	}, env.db).(*StandardEmailComposer)
+
+	composed, err := composer.ComposeEmail(context.Background(), &ComposeEmailRequest{
+		From:         &models.EmailAddress{Address: "tester@example.com"},
+		To:           []*models.EmailAddress{{Address: "alice@example.org"}},
+		Subject:      "Notes",
+		HTMLBody:     "<p>ignored</p>",
+		MarkdownBody: "We **shipped** <b>it</b>.\n\n```go\nreturn nil\n```",
+	})
+	require.NoError(t, err)
+	require.Contains(t, composed.HTMLBody, "<strong>shipped</strong> &lt;b&gt;it&lt;/b&gt;")
+	require.Contains(t, composed.HTMLBody, `<span style="color:`)
+	require.NotContains(t, composed.HTMLBody, "ignored")
+	require.Equal(t, "We **shipped** <b>it</b>.\n\n    return nil", composed.TextBody)
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// emailCounterDelta 邮件总数与未读数的增量
+type emailCounterDelta struct {
+	Total  int
+	Unread int
+}
+
+func (d emailCounterDelta) isZero() bool {
+	return d.Total == 0 && d.Unread == 0
+}
+
+// counterUpdates 生成增量更新表达式，计数不会被减到负数
+func (d emailCounterDelta) counterUpdates() map[string]interface{} {
+	updates := make(map[string]interface{}, 2)
+	if d.Total != 0 {
+		updates["total_emails"] = gorm.Expr("MAX(total_emails + ?, 0)", d.Total)
+	}
+	if d.Unread != 0 {
+		updates["unread_emails"] = gorm.Expr("MAX(unread_emails + ?, 0)", d.Unread)
+	}
+	return updates
+}
+
+// adjustEmailCounters 按增量维护账户和文件夹的邮件计数，单封邮件的状态变化无需重新统计整张表
+func (s *EmailServiceImpl) adjustEmailCounters(ctx context.Context, userID, accountID uint, account emailCounterDelta, folders map[uint]emailCounterDelta) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if !account.isZero() {
+			if err := tx.Model(&models.EmailAccount{}).
+				Where("id = ?", accountID).
+				UpdateColumns(account.counterUpdates()).Error; err != nil {
+				return fmt.Errorf("failed to update account counters: %w", err)
+			}
+		}
+		for folderID, delta := range folders {
+			if delta.isZero() {
+				continue
+			}
+			if err := tx.Model(&models.Folder{}).
+				Where("id = ?", folderID).
+				UpdateColumns(delta.counterUpdates()).Error; err != nil {
+				return fmt.Errorf("failed to update folder counters: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(folders) == 0 {
+		s.invalidateEmailListCache(userID, accountID, nil)
+	}
+	for folderID := range folders {
+		folderID := folderID
+		s.invalidateEmailListCache(userID, accountID, &folderID)
+		recordFolderChanges(ctx, s.changeLog, userID, accountID, &folderID)
+	}
+	recordAccountChange(ctx, s.changeLog, userID, accountID, models.ChangeActionUpdated)
+
+	return nil
+}
+
+// folderCounterDelta 单个文件夹的增量，文件夹为空时返回nil
+func folderCounterDelta(folderID *uint, delta emailCounterDelta) map[uint]emailCounterDelta {
+	if folderID == nil {
+		return nil
+	}
+	return map[uint]emailCounterDelta{*folderID: delta}
+}
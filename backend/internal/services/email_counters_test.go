@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailCreateFillsUserIDFromAccount(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+
+	email := env.createEmail(t, env.inbox, 5001, "denormalized", false, false)
+
+	var stored models.Email
+	require.NoError(t, env.db.First(&stored, email.ID).Error)
+	require.Equal(t, env.user.ID, stored.UserID)
+}
+
+func TestMoveAndDeleteAdjustCountersIncrementally(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+
+	moved := env.createEmail(t, env.inbox, 5101, "move me", false, false)
+	deleted := env.createEmail(t, env.inbox, 5102, "delete me", false, false)
+	require.NoError(t, env.db.Model(env.account).Updates(map[string]interface{}{"total_emails": 2, "unread_emails": 2}).Error)
+	require.NoError(t, env.db.Model(env.inbox).Updates(map[string]interface{}{"total_emails": 2, "unread_emails": 2}).Error)
+
+	require.NoError(t, env.service.MoveEmail(ctx, env.user.ID, moved.ID, env.work.ID))
+	require.NoError(t, env.service.DeleteEmail(ctx, env.user.ID, deleted.ID))
+
+	var account models.EmailAccount
+	require.NoError(t, env.db.First(&account, env.account.ID).Error)
+	require.Equal(t, 1, account.TotalEmails)
+	require.Equal(t, 1, account.UnreadEmails)
+
+	var inbox models.Folder
+	require.NoError(t, env.db.First(&inbox, env.inbox.ID).Error)
+	require.Equal(t, 0, inbox.TotalEmails)
+	require.Equal(t, 0, inbox.UnreadEmails)
+
+	var work models.Folder
+	require.NoError(t, env.db.First(&work, env.work.ID).Error)
+	require.Equal(t, 1, work.TotalEmails)
+	require.Equal(t, 1, work.UnreadEmails)
+}
+
+func TestGetEmailGroupsIncludesAccountCounts(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+
+	group, err := env.service.ResolveEmailGroup(ctx, env.user.ID, nil)
+	require.NoError(t, err)
+	require.NoError(t, env.db.Model(env.account).Update("group_id", group.ID).Error)
+
+	groups, err := env.service.GetEmailGroups(ctx, env.user.ID)
+	require.NoError(t, err)
+	require.NotEmpty(t, groups)
+	for _, g := range groups {
+		if g.ID == group.ID {
+			require.Equal(t, int64(1), g.AccountCnt)
+			return
+		}
+	}
+	t.Fatalf("group %d not returned", group.ID)
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidEmailCursor 游标无法解析或当前查询不支持游标分页
+var ErrInvalidEmailCursor = errors.New("invalid email cursor")
+
+// emailCursor 邮件列表的键集游标，按 (date, id) 定位上一页最后一封邮件
+type emailCursor struct {
+	Date time.Time `json:"d"`
+	ID   uint      `json:"i"`
+}
+
+// encodeEmailCursor 以邮件的日期和ID生成对客户端不透明的游标
+func encodeEmailCursor(email *models.Email) string {
+	raw, _ := json.Marshal(emailCursor{Date: email.Date, ID: email.ID})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeEmailCursor(cursor string) (*emailCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidEmailCursor, cursor)
+	}
+	var decoded emailCursor
+	if err := json.Unmarshal(raw, &decoded); err != nil || decoded.ID == 0 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidEmailCursor, cursor)
+	}
+	return &decoded, nil
+}
+
+// normalizeSortOrder 只接受ASC/DESC，其余按DESC处理
+func normalizeSortOrder(sortOrder string) string {
+	if strings.EqualFold(sortOrder, "asc") {
+		return "ASC"
+	}
+	return "DESC"
+}
+
+// applyEmailCursor 只保留排在游标之后的邮件；日期相同时用ID区分，保证翻页期间新到邮件不会造成重复或遗漏
+func applyEmailCursor(query *gorm.DB, cursor *emailCursor, sortOrder string) *gorm.DB {
+	if sortOrder == "ASC" {
+		return query.Where("(emails.date > ? OR (emails.date = ? AND emails.id > ?))", cursor.Date, cursor.Date, cursor.ID)
+	}
+	return query.Where("(emails.date < ? OR (emails.date = ? AND emails.id < ?))", cursor.Date, cursor.Date, cursor.ID)
+}
+
+// trimEmailPage 查询时多取一条判断是否还有下一页，截断后返回下一页游标
+func trimEmailPage(emails []*models.Email, pageSize int, withCursor bool) ([]*models.Email, bool, string) {
+	if len(emails) <= pageSize {
+		return emails, false, ""
+	}
+	emails = emails[:pageSize]
+	if !withCursor {
+		return emails, true, ""
+	}
+	return emails, true, encodeEmailCursor(emails[len(emails)-1])
+}
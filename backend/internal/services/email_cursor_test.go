@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEmailsCursorPaginationIsStableAcrossNewMail(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	create := func(uid uint32, date time.Time) *models.Email {
+		email := &models.Email{
+			AccountID: env.account.ID,
+			FolderID:  &env.inbox.ID,
+			MessageID: fmt.Sprintf("<cursor-%d@example.com>", uid),
+			UID:       uid,
+			Subject:   fmt.Sprintf("cursor %d", uid),
+			Date:      date,
+		}
+		require.NoError(t, env.db.Create(email).Error)
+		return email
+	}
+	// 两封邮件日期相同，依靠ID区分先后
+	for uid := uint32(1); uid <= 5; uid++ {
+		create(uid, base.Add(time.Duration(uid/2)*time.Hour))
+	}
+
+	req := &GetEmailsRequest{FolderID: &env.inbox.ID, PageSize: 2}
+	first, err := env.service.GetEmails(context.Background(), env.user.ID, req)
+	require.NoError(t, err)
+	require.Len(t, first.Emails, 2)
+	require.True(t, first.HasMore)
+	require.NotEmpty(t, first.NextCursor)
+
+	// 翻页期间到达的新邮件不应导致重复或遗漏
+	create(6, base.Add(24*time.Hour))
+
+	seen := map[uint]bool{}
+	for _, email := range first.Emails {
+		seen[email.ID] = true
+	}
+	cursor := first.NextCursor
+	for cursor != "" {
+		page, err := env.service.GetEmails(context.Background(), env.user.ID, &GetEmailsRequest{FolderID: &env.inbox.ID, PageSize: 2, Cursor: cursor})
+		require.NoError(t, err)
+		require.Zero(t, page.Page)
+		for _, email := range page.Emails {
+			require.False(t, seen[email.ID], "email %d returned twice", email.ID)
+			seen[email.ID] = true
+		}
+		cursor = page.NextCursor
+	}
+	require.Len(t, seen, 5)
+}
+
+func TestGetEmailsCursorRequiresDateSort(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+
+	_, err := env.service.GetEmails(context.Background(), env.user.ID, &GetEmailsRequest{SortBy: "subject", Cursor: "abc"})
+	require.True(t, errors.Is(err, ErrInvalidEmailCursor))
+
+	_, err = env.service.GetEmails(context.Background(), env.user.ID, &GetEmailsRequest{Cursor: "not base64!"})
+	require.True(t, errors.Is(err, ErrInvalidEmailCursor))
+}
+
+func TestSearchEmailsReturnsNextCursor(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for uid := uint32(1); uid <= 3; uid++ {
+		require.NoError(t, env.db.Create(&models.Email{
+			AccountID: env.account.ID,
+			FolderID:  &env.inbox.ID,
+			MessageID: fmt.Sprintf("<search-cursor-%d@example.com>", uid),
+			UID:       uid,
+			Subject:   "quarterly report",
+			Date:      base.Add(time.Duration(uid) * time.Hour),
+		}).Error)
+	}
+
+	first, err := env.service.SearchEmails(context.Background(), env.user.ID, &SearchEmailsRequest{Query: "report", PageSize: 2})
+	require.NoError(t, err)
+	require.Len(t, first.Emails, 2)
+	require.NotEmpty(t, first.NextCursor)
+
+	next, err := env.service.SearchEmails(context.Background(), env.user.ID, &SearchEmailsRequest{Query: "report", PageSize: 2, Cursor: first.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, next.Emails, 1)
+	require.False(t, next.HasMore)
+	require.Empty(t, next.NextCursor)
+	require.Equal(t, int64(3), next.Total)
+}
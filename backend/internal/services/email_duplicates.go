@@ -0,0 +1,506 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"firemail/internal/models"
+)
+
+// 重复邮件扫描任务状态
+const (
+	DuplicateScanStatusRunning   = "running"
+	DuplicateScanStatusCompleted = "completed"
+	DuplicateScanStatusCancelled = "cancelled"
+	DuplicateScanStatusFailed    = "failed"
+)
+
+// 重复邮件的匹配方式，与去重器的冲突类型一致
+const (
+	DuplicateMatchMessageID = "message_id"
+	DuplicateMatchContent   = "content"
+)
+
+// 重复邮件的清理方式
+const (
+	DuplicateActionDelete = "delete"
+	DuplicateActionMove   = "move"
+)
+
+// 重复邮件扫描每批加载的邮件数量
+const duplicateScanBatchSize = 500
+
+// StartDuplicateScanRequest 重复邮件扫描请求，未指定范围时扫描用户所有账户
+type StartDuplicateScanRequest struct {
+	AccountIDs []uint `json:"account_ids,omitempty"`
+	FolderIDs  []uint `json:"folder_ids,omitempty"`
+}
+
+// DuplicateEmail 重复组中的一封邮件
+type DuplicateEmail struct {
+	ID         uint      `json:"id"`
+	AccountID  uint      `json:"account_id"`
+	FolderID   *uint     `json:"folder_id,omitempty"`
+	FolderName string    `json:"folder_name,omitempty"`
+	Subject    string    `json:"subject"`
+	From       string    `json:"from"`
+	Date       time.Time `json:"date"`
+	Size       int64     `json:"size"`
+	IsRead     bool      `json:"is_read"`
+	IsStarred  bool      `json:"is_starred"`
+}
+
+// DuplicateEmailGroup 指纹相同的一组邮件，CanonicalID为建议保留的邮件
+type DuplicateEmailGroup struct {
+	Fingerprint string           `json:"fingerprint"`
+	MatchType   string           `json:"match_type"`
+	CanonicalID uint             `json:"canonical_id"`
+	Emails      []DuplicateEmail `json:"emails"`
+}
+
+// DuplicateScanJob 重复邮件扫描任务，完成后Groups为按重复数量排序的结果
+type DuplicateScanJob struct {
+	ID             string                `json:"id"`
+	Status         string                `json:"status"`
+	Scanned        int                   `json:"scanned"`
+	GroupCount     int                   `json:"group_count"`
+	DuplicateCount int                   `json:"duplicate_count"` // 除保留邮件外的重复邮件数
+	LastError      string                `json:"last_error,omitempty"`
+	StartedAt      time.Time             `json:"started_at"`
+	FinishedAt     *time.Time            `json:"finished_at,omitempty"`
+	Groups         []DuplicateEmailGroup `json:"groups,omitempty"`
+
+	userID uint
+	cancel context.CancelFunc
+}
+
+// Finished 任务是否已结束
+func (j *DuplicateScanJob) Finished() bool {
+	return j.Status != DuplicateScanStatusRunning
+}
+
+// DuplicateResolution 指定重复组保留的邮件
+type DuplicateResolution struct {
+	Fingerprint string `json:"fingerprint"`
+	CanonicalID uint   `json:"canonical_id"`
+}
+
+// ResolveDuplicatesRequest 清理扫描结果中的重复邮件，未指定Groups时按建议处理全部重复组
+type ResolveDuplicatesRequest struct {
+	Action         string                `json:"action" binding:"required,oneof=delete move"`
+	TargetFolderID *uint                 `json:"target_folder_id,omitempty"` // action为move时必填
+	Groups         []DuplicateResolution `json:"groups,omitempty"`
+}
+
+// ResolveDuplicatesResult 重复邮件清理结果，移动较多邮件时Transfer为后台任务
+type ResolveDuplicatesResult struct {
+	Groups    int               `json:"groups"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Errors    []string          `json:"errors"`
+	Transfer  *EmailTransferJob `json:"transfer,omitempty"`
+}
+
+// duplicateScanJobRegistry 保存运行中和已结束的重复邮件扫描任务
+type duplicateScanJobRegistry struct {
+	mutex sync.RWMutex
+	jobs  map[string]*DuplicateScanJob
+}
+
+func newDuplicateScanJobRegistry() *duplicateScanJobRegistry {
+	return &duplicateScanJobRegistry{jobs: make(map[string]*DuplicateScanJob)}
+}
+
+// snapshot 返回属于该用户的任务副本
+func (r *duplicateScanJobRegistry) snapshot(userID uint, jobID string) (*DuplicateScanJob, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	job, ok := r.jobs[jobID]
+	if !ok || job.userID != userID {
+		return nil, false
+	}
+	copied := *job
+	copied.cancel = nil
+	copied.Groups = append([]DuplicateEmailGroup(nil), job.Groups...)
+	return &copied, true
+}
+
+func (r *duplicateScanJobRegistry) update(jobID string, fn func(job *DuplicateScanJob)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if job, ok := r.jobs[jobID]; ok {
+		fn(job)
+	}
+}
+
+// StartDuplicateScan 在后台扫描重复邮件：有Message-ID的按Message-ID分组，没有的按发件人、主题、日期和正文的内容哈希分组
+func (s *EmailServiceImpl) StartDuplicateScan(ctx context.Context, userID uint, req *StartDuplicateScanRequest) (*DuplicateScanJob, error) {
+	if len(req.AccountIDs) > 0 {
+		var count int64
+		if err := s.db.WithContext(ctx).Model(&models.EmailAccount{}).
+			Where("id IN ? AND user_id = ?", req.AccountIDs, userID).
+			Count(&count).Error; err != nil {
+			return nil, fmt.Errorf("failed to check accounts: %w", err)
+		}
+		if int(count) != len(uniqueUints(req.AccountIDs)) {
+			return nil, fmt.Errorf("account not found")
+		}
+	}
+	if len(req.FolderIDs) > 0 {
+		var count int64
+		if err := s.db.WithContext(ctx).Model(&models.Folder{}).
+			Joins("JOIN email_accounts ON folders.account_id = email_accounts.id").
+			Where("folders.id IN ? AND email_accounts.user_id = ?", req.FolderIDs, userID).
+			Count(&count).Error; err != nil {
+			return nil, fmt.Errorf("failed to check folders: %w", err)
+		}
+		if int(count) != len(uniqueUints(req.FolderIDs)) {
+			return nil, fmt.Errorf("folder not found")
+		}
+	}
+
+	jobCtx, cancel := context.WithCancel(context.Background())
+	job := &DuplicateScanJob{
+		ID:        newDuplicateScanJobID(),
+		Status:    DuplicateScanStatusRunning,
+		StartedAt: time.Now(),
+		userID:    userID,
+		cancel:    cancel,
+	}
+
+	s.duplicateScans.mutex.Lock()
+	s.duplicateScans.jobs[job.ID] = job
+	s.duplicateScans.mutex.Unlock()
+
+	go s.runDuplicateScan(jobCtx, job.ID, userID, req)
+
+	snapshot, _ := s.duplicateScans.snapshot(userID, job.ID)
+	return snapshot, nil
+}
+
+// GetDuplicateScan 获取重复邮件扫描任务及结果
+func (s *EmailServiceImpl) GetDuplicateScan(ctx context.Context, userID uint, jobID string) (*DuplicateScanJob, error) {
+	job, ok := s.duplicateScans.snapshot(userID, jobID)
+	if !ok {
+		return nil, fmt.Errorf("duplicate scan not found")
+	}
+	return job, nil
+}
+
+// CancelDuplicateScan 取消重复邮件扫描任务
+func (s *EmailServiceImpl) CancelDuplicateScan(ctx context.Context, userID uint, jobID string) (*DuplicateScanJob, error) {
+	s.duplicateScans.mutex.RLock()
+	job, ok := s.duplicateScans.jobs[jobID]
+	s.duplicateScans.mutex.RUnlock()
+	if !ok || job.userID != userID {
+		return nil, fmt.Errorf("duplicate scan not found")
+	}
+
+	job.cancel()
+	return s.GetDuplicateScan(ctx, userID, jobID)
+}
+
+// duplicateCandidate 扫描过程中记录的邮件摘要
+type duplicateCandidate struct {
+	email      DuplicateEmail
+	folderType string
+}
+
+func (s *EmailServiceImpl) runDuplicateScan(ctx context.Context, jobID string, userID uint, req *StartDuplicateScanRequest) {
+	defer func() {
+		now := time.Now()
+		s.duplicateScans.update(jobID, func(job *DuplicateScanJob) {
+			if job.Status == DuplicateScanStatusRunning {
+				job.Status = DuplicateScanStatusCompleted
+				if ctx.Err() != nil {
+					job.Status = DuplicateScanStatusCancelled
+				}
+			}
+			job.FinishedAt = &now
+			job.cancel()
+		})
+	}()
+
+	var folders []models.Folder
+	if err := s.db.WithContext(ctx).
+		Joins("JOIN email_accounts ON folders.account_id = email_accounts.id").
+		Where("email_accounts.user_id = ?", userID).
+		Find(&folders).Error; err != nil {
+		s.failDuplicateScan(jobID, fmt.Errorf("failed to load folders: %w", err))
+		return
+	}
+	folderByID := make(map[uint]*models.Folder, len(folders))
+	for i := range folders {
+		folderByID[folders[i].ID] = &folders[i]
+	}
+
+	buckets := make(map[string][]duplicateCandidate)
+	var order []string
+	var lastID uint
+	for ctx.Err() == nil {
+		query := s.db.WithContext(ctx).Model(&models.Email{}).
+			Select("id, account_id, folder_id, message_id, subject, from_address, date, size, is_read, is_starred").
+			Preload("Body").
+			Where("user_id = ? AND is_deleted = ? AND id > ?", userID, false, lastID)
+		if len(req.AccountIDs) > 0 {
+			query = query.Where("account_id IN ?", req.AccountIDs)
+		}
+		if len(req.FolderIDs) > 0 {
+			query = query.Where("folder_id IN ?", req.FolderIDs)
+		}
+
+		var emails []models.Email
+		if err := query.Order("id").Limit(duplicateScanBatchSize).Find(&emails).Error; err != nil {
+			if ctx.Err() == nil {
+				s.failDuplicateScan(jobID, fmt.Errorf("failed to load emails: %w", err))
+			}
+			return
+		}
+		if len(emails) == 0 {
+			break
+		}
+
+		for i := range emails {
+			email := &emails[i]
+			fingerprint := emailFingerprint(email)
+			if _, ok := buckets[fingerprint]; !ok {
+				order = append(order, fingerprint)
+			}
+
+			candidate := duplicateCandidate{email: DuplicateEmail{
+				ID:        email.ID,
+				AccountID: email.AccountID,
+				FolderID:  email.FolderID,
+				Subject:   email.Subject,
+				From:      email.From,
+				Date:      email.Date,
+				Size:      email.Size,
+				IsRead:    email.IsRead,
+				IsStarred: email.IsStarred,
+			}}
+			if email.FolderID != nil {
+				if folder, ok := folderByID[*email.FolderID]; ok {
+					candidate.email.FolderName = folder.DisplayName
+					if candidate.email.FolderName == "" {
+						candidate.email.FolderName = folder.Name
+					}
+					candidate.folderType = folder.Type
+				}
+			}
+			buckets[fingerprint] = append(buckets[fingerprint], candidate)
+		}
+
+		lastID = emails[len(emails)-1].ID
+		s.duplicateScans.update(jobID, func(job *DuplicateScanJob) {
+			job.Scanned += len(emails)
+		})
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	var groups []DuplicateEmailGroup
+	duplicates := 0
+	for _, fingerprint := range order {
+		candidates := buckets[fingerprint]
+		if len(candidates) < 2 {
+			continue
+		}
+
+		matchType := DuplicateMatchMessageID
+		if strings.HasPrefix(fingerprint, DuplicateMatchContent+":") {
+			matchType = DuplicateMatchContent
+		}
+		group := DuplicateEmailGroup{
+			Fingerprint: fingerprint,
+			MatchType:   matchType,
+			CanonicalID: chooseCanonicalDuplicate(candidates),
+			Emails:      make([]DuplicateEmail, 0, len(candidates)),
+		}
+		for _, candidate := range candidates {
+			group.Emails = append(group.Emails, candidate.email)
+		}
+		groups = append(groups, group)
+		duplicates += len(candidates) - 1
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return len(groups[i].Emails) > len(groups[j].Emails)
+	})
+
+	s.duplicateScans.update(jobID, func(job *DuplicateScanJob) {
+		job.Groups = groups
+		job.GroupCount = len(groups)
+		job.DuplicateCount = duplicates
+	})
+}
+
+func (s *EmailServiceImpl) failDuplicateScan(jobID string, err error) {
+	s.duplicateScans.update(jobID, func(job *DuplicateScanJob) {
+		job.Status = DuplicateScanStatusFailed
+		job.LastError = err.Error()
+	})
+}
+
+// emailFingerprint 生成邮件指纹：优先使用规范化后的Message-ID，缺失时使用内容哈希
+func emailFingerprint(email *models.Email) string {
+	messageID := strings.ToLower(strings.Trim(strings.TrimSpace(email.MessageID), "<>"))
+	if messageID != "" {
+		return DuplicateMatchMessageID + ":" + messageID
+	}
+
+	body := strings.TrimSpace(email.TextBody)
+	if body == "" {
+		body = strings.TrimSpace(email.HTMLBody)
+	}
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%s\x00%d\x00",
+		strings.ToLower(strings.TrimSpace(email.From)),
+		strings.TrimSpace(email.Subject),
+		email.Date.UTC().Unix())
+	hash.Write([]byte(body))
+	return DuplicateMatchContent + ":" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// chooseCanonicalDuplicate 选择建议保留的邮件：不在垃圾邮件和已删除文件夹中，优先星标和已读，其次最早同步的一封
+func chooseCanonicalDuplicate(candidates []duplicateCandidate) uint {
+	rank := func(candidate duplicateCandidate) int {
+		score := 0
+		if candidate.folderType != models.FolderTypeSpam && candidate.folderType != models.FolderTypeTrash {
+			score += 4
+		}
+		if candidate.email.IsStarred {
+			score += 2
+		}
+		if candidate.email.IsRead {
+			score++
+		}
+		return score
+	}
+
+	best := candidates[0]
+	for _, candidate := range candidates[1:] {
+		if rank(candidate) > rank(best) {
+			best = candidate
+		}
+	}
+	return best.email.ID
+}
+
+// ResolveDuplicates 清理已完成扫描中的重复邮件：每组保留一封，其余移入回收站或移动到指定文件夹。
+// 处理过的重复组会从扫描结果中移除
+func (s *EmailServiceImpl) ResolveDuplicates(ctx context.Context, userID uint, jobID string, req *ResolveDuplicatesRequest) (*ResolveDuplicatesResult, error) {
+	job, ok := s.duplicateScans.snapshot(userID, jobID)
+	if !ok {
+		return nil, fmt.Errorf("duplicate scan not found")
+	}
+	if job.Status != DuplicateScanStatusCompleted {
+		return nil, fmt.Errorf("duplicate scan is not completed")
+	}
+	if req.Action == DuplicateActionMove && req.TargetFolderID == nil {
+		return nil, fmt.Errorf("target_folder_id is required for move action")
+	}
+
+	groupByFingerprint := make(map[string]*DuplicateEmailGroup, len(job.Groups))
+	for i := range job.Groups {
+		groupByFingerprint[job.Groups[i].Fingerprint] = &job.Groups[i]
+	}
+
+	resolutions := req.Groups
+	if len(resolutions) == 0 {
+		for _, group := range job.Groups {
+			resolutions = append(resolutions, DuplicateResolution{Fingerprint: group.Fingerprint})
+		}
+	}
+
+	var duplicateIDs []uint
+	resolved := make(map[string]bool, len(resolutions))
+	for _, resolution := range resolutions {
+		group, ok := groupByFingerprint[resolution.Fingerprint]
+		if !ok {
+			return nil, fmt.Errorf("duplicate group %s not found", resolution.Fingerprint)
+		}
+		if resolved[group.Fingerprint] {
+			continue
+		}
+
+		canonicalID := group.CanonicalID
+		if resolution.CanonicalID != 0 {
+			canonicalID = resolution.CanonicalID
+		}
+		found := false
+		for _, email := range group.Emails {
+			if email.ID == canonicalID {
+				found = true
+				continue
+			}
+			duplicateIDs = append(duplicateIDs, email.ID)
+		}
+		if !found {
+			return nil, fmt.Errorf("canonical email %d is not in duplicate group %s", canonicalID, group.Fingerprint)
+		}
+		resolved[group.Fingerprint] = true
+	}
+
+	result := &ResolveDuplicatesResult{Groups: len(resolved), Errors: []string{}}
+	if len(duplicateIDs) > 0 {
+		switch req.Action {
+		case DuplicateActionMove:
+			transfer, err := s.TransferEmails(ctx, userID, &TransferEmailsRequest{
+				EmailIDs:       duplicateIDs,
+				TargetFolderID: *req.TargetFolderID,
+			})
+			if err != nil {
+				return nil, err
+			}
+			result.Transfer = transfer
+			result.Succeeded = transfer.Succeeded
+			result.Failed = transfer.Failed
+			if transfer.LastError != "" {
+				result.Errors = append(result.Errors, transfer.LastError)
+			}
+		default:
+			for _, emailID := range duplicateIDs {
+				if err := s.DeleteEmail(ctx, userID, emailID); err != nil {
+					result.Failed++
+					result.Errors = append(result.Errors, fmt.Sprintf("email %d: %v", emailID, err))
+					continue
+				}
+				result.Succeeded++
+			}
+		}
+	}
+
+	s.duplicateScans.update(jobID, func(job *DuplicateScanJob) {
+		remaining := job.Groups[:0:0]
+		duplicates := 0
+		for _, group := range job.Groups {
+			if resolved[group.Fingerprint] {
+				continue
+			}
+			remaining = append(remaining, group)
+			duplicates += len(group.Emails) - 1
+		}
+		job.Groups = remaining
+		job.GroupCount = len(remaining)
+		job.DuplicateCount = duplicates
+	})
+
+	return result, nil
+}
+
+func newDuplicateScanJobID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("dedup_%d", time.Now().UnixNano())
+	}
+	return "dedup_" + hex.EncodeToString(buf)
+}
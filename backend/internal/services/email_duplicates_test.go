@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+// waitForDuplicateScan 等待重复邮件扫描结束
+func waitForDuplicateScan(t *testing.T, env *emailStateServiceTestEnv, jobID string) *DuplicateScanJob {
+	t.Helper()
+
+	var job *DuplicateScanJob
+	require.Eventually(t, func() bool {
+		current, err := env.service.GetDuplicateScan(context.Background(), env.user.ID, jobID)
+		job = current
+		return err == nil && current.Finished()
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func TestDuplicateScanGroupsByMessageIDAndContent(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+
+	original := env.createEmail(t, env.inbox, 1, "report", false, false)
+	copyInWork := env.createEmail(t, env.work, 2, "report", true, false)
+	require.NoError(t, env.db.Model(copyInWork).Update("message_id", "<REPORT-1@example.com>").Error)
+	require.NoError(t, env.db.Model(original).Update("message_id", "<report-1@example.com>").Error)
+
+	date := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	var contentIDs []uint
+	for uid := uint32(3); uid <= 4; uid++ {
+		email := env.createEmail(t, env.inbox, uid, "no-id", false, false)
+		require.NoError(t, env.db.Model(email).Updates(map[string]interface{}{"message_id": "", "date": date}).Error)
+		contentIDs = append(contentIDs, email.ID)
+	}
+	env.createEmail(t, env.inbox, 5, "unique", false, false)
+	trashed := env.createEmail(t, env.work, 6, "report", false, true)
+	require.NoError(t, env.db.Model(trashed).Update("message_id", "<report-1@example.com>").Error)
+
+	job, err := env.service.StartDuplicateScan(ctx, env.user.ID, &StartDuplicateScanRequest{})
+	require.NoError(t, err)
+
+	job = waitForDuplicateScan(t, env, job.ID)
+	require.Equal(t, DuplicateScanStatusCompleted, job.Status, job.LastError)
+	require.Equal(t, 5, job.Scanned)
+	require.Equal(t, 2, job.GroupCount)
+	require.Equal(t, 2, job.DuplicateCount)
+
+	byType := map[string]DuplicateEmailGroup{}
+	for _, group := range job.Groups {
+		byType[group.MatchType] = group
+	}
+	require.Equal(t, "message_id:report-1@example.com", byType[DuplicateMatchMessageID].Fingerprint)
+	require.Equal(t, copyInWork.ID, byType[DuplicateMatchMessageID].CanonicalID, "read copy is preferred")
+	require.Equal(t, "项目", byType[DuplicateMatchMessageID].Emails[1].FolderName)
+	require.Len(t, byType[DuplicateMatchContent].Emails, 2)
+	require.Equal(t, contentIDs[0], byType[DuplicateMatchContent].CanonicalID)
+
+	_, err = env.service.GetDuplicateScan(ctx, env.user.ID+1, job.ID)
+	require.Error(t, err)
+
+	_, err = env.service.StartDuplicateScan(ctx, env.user.ID, &StartDuplicateScanRequest{AccountIDs: []uint{env.account.ID + 100}})
+	require.EqualError(t, err, "account not found")
+}
+
+func TestResolveDuplicatesKeepsChosenCanonical(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+
+	first := env.createEmail(t, env.inbox, 11, "dup", false, false)
+	second := env.createEmail(t, env.work, 12, "dup", false, false)
+	require.NoError(t, env.db.Model(second).Update("message_id", first.MessageID).Error)
+
+	job, err := env.service.StartDuplicateScan(ctx, env.user.ID, &StartDuplicateScanRequest{})
+	require.NoError(t, err)
+	job = waitForDuplicateScan(t, env, job.ID)
+	require.Len(t, job.Groups, 1)
+	require.Equal(t, first.ID, job.Groups[0].CanonicalID)
+
+	_, err = env.service.ResolveDuplicates(ctx, env.user.ID, job.ID, &ResolveDuplicatesRequest{
+		Action: DuplicateActionDelete,
+		Groups: []DuplicateResolution{{Fingerprint: job.Groups[0].Fingerprint, CanonicalID: 999}},
+	})
+	require.ErrorContains(t, err, "is not in duplicate group")
+
+	result, err := env.service.ResolveDuplicates(ctx, env.user.ID, job.ID, &ResolveDuplicatesRequest{
+		Action: DuplicateActionDelete,
+		Groups: []DuplicateResolution{{Fingerprint: job.Groups[0].Fingerprint, CanonicalID: second.ID}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Groups)
+	require.Equal(t, 1, result.Succeeded)
+
+	var deleted, kept models.Email
+	require.NoError(t, env.db.First(&deleted, first.ID).Error)
+	require.NoError(t, env.db.First(&kept, second.ID).Error)
+	require.True(t, deleted.IsDeleted)
+	require.False(t, kept.IsDeleted)
+
+	job, err = env.service.GetDuplicateScan(ctx, env.user.ID, job.ID)
+	require.NoError(t, err)
+	require.Empty(t, job.Groups)
+	require.Zero(t, job.DuplicateCount)
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"firemail/internal/models"
+	"firemail/internal/providers"
+	"firemail/internal/sse"
+)
+
+// ErrInvalidEmailFlag 旗标设置无效
+var ErrInvalidEmailFlag = errors.New("invalid email flag")
+
+// 同步旗标使用的IMAP标志和关键字
+const (
+	imapFlagged     = "\\Flagged"
+	imapFollowUpKey = "$Followup"
+)
+
+// flagColorBits Apple Mail 使用 $MailFlagBit0-2 三个关键字组合表示旗标颜色，没有任何位时为红色
+var flagColorBits = []string{"$MailFlagBit0", "$MailFlagBit1", "$MailFlagBit2"}
+
+// flagColorCodes 旗标颜色与颜色位的对应关系
+var flagColorCodes = map[string]int{
+	"red":    0,
+	"orange": 1,
+	"yellow": 2,
+	"green":  3,
+	"blue":   4,
+	"purple": 5,
+	"gray":   6,
+}
+
+// thunderbirdLabelColors Thunderbird 默认标签关键字对应的颜色，只在读取时使用
+var thunderbirdLabelColors = map[string]string{
+	"$label1": "red",
+	"$label2": "orange",
+	"$label3": "green",
+	"$label4": "blue",
+	"$label5": "purple",
+}
+
+// SetEmailFlagRequest 设置邮件旗标请求，未提供的字段保持不变
+type SetEmailFlagRequest struct {
+	Color      *string    `json:"color,omitempty"`        // red, orange, yellow, green, blue, purple, gray；非空时同时加星，传空字符串恢复默认星标
+	FollowUp   *bool      `json:"follow_up,omitempty"`    // 设置或取消后续跟进，设置时同时加星
+	FollowUpAt *time.Time `json:"follow_up_at,omitempty"` // 跟进截止时间，设置跟进时使用，为空表示没有截止时间
+	Completed  bool       `json:"completed,omitempty"`    // 标记跟进已完成，没有星标颜色时同时取消星标
+}
+
+// ValidEmailFlagColor 是否为支持的旗标颜色，空字符串表示默认星标
+func ValidEmailFlagColor(color string) bool {
+	if color == "" {
+		return true
+	}
+	_, ok := flagColorCodes[color]
+	return ok
+}
+
+// SetEmailFlag 设置邮件的星标颜色和后续跟进旗标，并尽量以IMAP关键字同步到服务器
+func (s *EmailServiceImpl) SetEmailFlag(ctx context.Context, userID, emailID uint, req *SetEmailFlagRequest) (*models.Email, error) {
+	if req.Color == nil && req.FollowUp == nil && !req.Completed {
+		return nil, fmt.Errorf("%w: no flag changes", ErrInvalidEmailFlag)
+	}
+	if req.Color != nil && !ValidEmailFlagColor(*req.Color) {
+		return nil, fmt.Errorf("%w: unsupported color %q", ErrInvalidEmailFlag, *req.Color)
+	}
+	if req.Completed && req.FollowUp != nil && *req.FollowUp {
+		return nil, fmt.Errorf("%w: completed cannot be combined with follow_up", ErrInvalidEmailFlag)
+	}
+
+	email, err := s.getEmailForUser(ctx, userID, emailID, false, "Account", "Folder")
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkEmailVersion(ctx, email); err != nil {
+		return nil, err
+	}
+
+	wasStarred := email.IsStarred
+	if req.Color != nil {
+		email.FlagColor = *req.Color
+		if email.FlagColor != "" {
+			email.IsStarred = true
+		}
+	}
+	if req.FollowUp != nil {
+		email.IsFollowUp = *req.FollowUp
+		email.FollowUpAt = nil
+		if email.IsFollowUp {
+			email.IsStarred = true
+			email.FollowUpAt = req.FollowUpAt
+			email.FollowUpCompletedAt = nil
+		}
+	}
+	if req.Completed {
+		now := time.Now()
+		email.IsFollowUp = false
+		email.FollowUpAt = nil
+		email.FollowUpCompletedAt = &now
+		if email.FlagColor == "" {
+			email.IsStarred = false
+		}
+	}
+
+	if err := s.updateEmailColumns(ctx, s.db, email, map[string]interface{}{
+		"is_starred":             email.IsStarred,
+		"flag_color":             email.FlagColor,
+		"is_follow_up":           email.IsFollowUp,
+		"follow_up_at":           email.FollowUpAt,
+		"follow_up_completed_at": email.FollowUpCompletedAt,
+	}); err != nil {
+		if errors.Is(err, ErrVersionConflict) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update email flag: %w", err)
+	}
+
+	s.invalidateEmailListCache(userID, email.AccountID, email.FolderID)
+	recordEmailChanges(ctx, s.changeLog, userID, email.AccountID, models.ChangeActionUpdated, email.ID)
+	if email.IsStarred != wasStarred {
+		starEvent := models.EmailEventStarred
+		if !email.IsStarred {
+			starEvent = models.EmailEventUnstarred
+		}
+		recordEmailEvents(ctx, s.db, newEmailEvent(userID, email.AccountID, email.ID, starEvent, models.EmailEventSourceUser))
+
+		if s.eventPublisher != nil {
+			event := sse.NewEmailStatusEvent(email.ID, email.AccountID, userID, nil, nil, &email.IsStarred, nil, nil, nil)
+			if err := s.eventPublisher.PublishToUser(ctx, userID, event); err != nil {
+				fmt.Printf("Failed to publish email star event: %v\n", err)
+			}
+		}
+	}
+
+	s.syncEmailFlagKeywords(ctx, email)
+	return email, nil
+}
+
+// syncEmailFlagKeywords 将星标、旗标颜色和跟进状态写入服务器
+func (s *EmailServiceImpl) syncEmailFlagKeywords(ctx context.Context, email *models.Email) {
+	add, remove := emailFlagKeywords(email)
+	s.storeEmailFlags(ctx, email, add, remove)
+}
+
+// storeEmailFlags 在服务器上为邮件添加和移除标志，邮件需预加载账户和文件夹。
+// 文件夹不允许自定义关键字时只同步系统标志；同步失败只记录日志，状态仍保存在本地
+func (s *EmailServiceImpl) storeEmailFlags(ctx context.Context, email *models.Email, add, remove []string) {
+	if email.UID == 0 || email.Folder == nil || email.Folder.GetFullPath() == "" {
+		return
+	}
+
+	provider, err := s.providerFactory.CreateProviderForAccount(&email.Account)
+	if err != nil {
+		log.Printf("Warning: failed to create provider for flag sync: %v", err)
+		return
+	}
+	s.setupProviderTokenCallback(provider)
+
+	if err := provider.Connect(ctx, &email.Account); err != nil {
+		log.Printf("Warning: failed to connect for flag sync: %v", err)
+		return
+	}
+	defer provider.Disconnect()
+
+	client, ok := provider.IMAPClient().(providers.FlagClient)
+	if !ok {
+		return
+	}
+	if _, err := provider.IMAPClient().SelectFolder(ctx, email.Folder.GetFullPath()); err != nil {
+		log.Printf("Warning: failed to select folder for flag sync: %v", err)
+		return
+	}
+
+	if !client.AllowsKeywords() {
+		add, remove = systemFlagsOnly(add), systemFlagsOnly(remove)
+	}
+	uids := []uint32{email.UID}
+	if len(remove) > 0 {
+		if err := client.StoreFlags(ctx, uids, remove, false); err != nil {
+			log.Printf("Warning: failed to remove flags for email %d: %v", email.ID, err)
+			return
+		}
+	}
+	if len(add) > 0 {
+		if err := client.StoreFlags(ctx, uids, add, true); err != nil {
+			log.Printf("Warning: failed to add flags for email %d: %v", email.ID, err)
+		}
+	}
+}
+
+// emailFlagKeywords 根据邮件的旗标状态计算需要添加和移除的IMAP标志
+func emailFlagKeywords(email *models.Email) (add, remove []string) {
+	if !email.IsStarred {
+		return nil, append([]string{imapFlagged, imapFollowUpKey}, flagColorBits...)
+	}
+
+	add = append(add, imapFlagged)
+	code := flagColorCodes[email.FlagColor]
+	for i, bit := range flagColorBits {
+		if code&(1<<i) != 0 {
+			add = append(add, bit)
+		} else {
+			remove = append(remove, bit)
+		}
+	}
+	if email.IsFollowUp {
+		add = append(add, imapFollowUpKey)
+	} else {
+		remove = append(remove, imapFollowUpKey)
+	}
+	return add, remove
+}
+
+// systemFlagsOnly 只保留以反斜杠开头的系统标志
+func systemFlagsOnly(flags []string) []string {
+	var result []string
+	for _, flag := range flags {
+		if strings.HasPrefix(flag, "\\") {
+			result = append(result, flag)
+		}
+	}
+	return result
+}
+
+// applyFlagKeywords 根据同步得到的IMAP标志更新旗标颜色和跟进状态，调用前需已设置 IsStarred。
+// 服务器不一定保存自定义关键字，没有颜色或跟进关键字时保留本地状态，取消星标时一并清除
+func applyFlagKeywords(email *models.Email, flags []string) {
+	if !email.IsStarred {
+		email.FlagColor = ""
+		email.IsFollowUp = false
+		email.FollowUpAt = nil
+		return
+	}
+
+	code, hasBits := 0, false
+	labelColor := ""
+	for _, flag := range flags {
+		for i, bit := range flagColorBits {
+			if strings.EqualFold(flag, bit) {
+				code |= 1 << i
+				hasBits = true
+			}
+		}
+		if color, ok := thunderbirdLabelColors[strings.ToLower(flag)]; ok && labelColor == "" {
+			labelColor = color
+		}
+		if strings.EqualFold(flag, imapFollowUpKey) {
+			email.IsFollowUp = true
+			email.FollowUpCompletedAt = nil
+		}
+	}
+
+	switch {
+	case hasBits:
+		for color, colorCode := range flagColorCodes {
+			if colorCode == code {
+				email.FlagColor = color
+			}
+		}
+	case labelColor != "":
+		email.FlagColor = labelColor
+	}
+}
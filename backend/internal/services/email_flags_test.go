@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+	"firemail/internal/providers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetEmailFlagSyncsKeywordsAndFilters(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.EmailEvent{}))
+	env.provider.imap.allowsKeywords = true
+	ctx := context.Background()
+
+	email := env.createEmail(t, env.inbox, 1, "contract", true, false)
+	other := env.createEmail(t, env.inbox, 2, "newsletter", true, false)
+
+	invalid := "pink"
+	_, err := env.service.SetEmailFlag(ctx, env.user.ID, email.ID, &SetEmailFlagRequest{Color: &invalid})
+	require.True(t, errors.Is(err, ErrInvalidEmailFlag))
+
+	blue := "blue"
+	updated, err := env.service.SetEmailFlag(ctx, env.user.ID, email.ID, &SetEmailFlagRequest{Color: &blue})
+	require.NoError(t, err)
+	require.True(t, updated.IsStarred)
+	require.Equal(t, "blue", updated.FlagColor)
+	require.Len(t, env.provider.imap.storeFlagCalls, 2)
+	require.Equal(t, fakeStoreFlagsCall{UIDs: []uint32{1}, Flags: []string{"$MailFlagBit0", "$MailFlagBit1", "$Followup"}}, env.provider.imap.storeFlagCalls[0])
+	require.Equal(t, fakeStoreFlagsCall{UIDs: []uint32{1}, Flags: []string{"\\Flagged", "$MailFlagBit2"}, Add: true}, env.provider.imap.storeFlagCalls[1])
+
+	due := time.Now().Add(24 * time.Hour)
+	follow := true
+	_, err = env.service.SetEmailFlag(ctx, env.user.ID, other.ID, &SetEmailFlagRequest{FollowUp: &follow, FollowUpAt: &due})
+	require.NoError(t, err)
+
+	list, err := env.service.GetEmails(ctx, env.user.ID, &GetEmailsRequest{FlagColor: "blue", Page: 1, PageSize: 20})
+	require.NoError(t, err)
+	require.Len(t, list.Emails, 1)
+	require.Equal(t, email.ID, list.Emails[0].ID)
+
+	dueBy := due.Add(time.Hour)
+	list, err = env.service.GetEmails(ctx, env.user.ID, &GetEmailsRequest{FollowUpDue: &dueBy, Page: 1, PageSize: 20})
+	require.NoError(t, err)
+	require.Len(t, list.Emails, 1)
+	require.Equal(t, other.ID, list.Emails[0].ID)
+
+	// 完成跟进后没有星标颜色的邮件同时取消星标
+	completed, err := env.service.SetEmailFlag(ctx, env.user.ID, other.ID, &SetEmailFlagRequest{Completed: true})
+	require.NoError(t, err)
+	require.False(t, completed.IsFollowUp)
+	require.False(t, completed.IsStarred)
+	require.NotNil(t, completed.FollowUpCompletedAt)
+	require.Equal(t, fakeStoreFlagsCall{
+		UIDs:  []uint32{2},
+		Flags: []string{"\\Flagged", "$Followup", "$MailFlagBit0", "$MailFlagBit1", "$MailFlagBit2"},
+	}, env.provider.imap.storeFlagCalls[len(env.provider.imap.storeFlagCalls)-1])
+}
+
+func TestApplyFlagKeywordsKeepsLocalStateWithoutKeywords(t *testing.T) {
+	email := &models.Email{IsStarred: true, FlagColor: "green"}
+	applyFlagKeywords(email, []string{"\\Seen", "\\Flagged"})
+	require.Equal(t, "green", email.FlagColor)
+	require.False(t, email.IsFollowUp)
+
+	applyFlagKeywords(email, []string{"\\Flagged", "$MailFlagBit0", "$MailFlagBit2", "$Followup"})
+	require.Equal(t, "purple", email.FlagColor)
+	require.True(t, email.IsFollowUp)
+
+	applyFlagKeywords(email, []string{"\\Flagged", "$label4"})
+	require.Equal(t, "blue", email.FlagColor)
+
+	email.IsStarred = false
+	applyFlagKeywords(email, nil)
+	require.Empty(t, email.FlagColor)
+	require.False(t, email.IsFollowUp)
+}
+
+func TestSyncedDuplicateAppliesFlagKeywords(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.EmailEvent{}, &models.SyncConflict{}, &models.UserSetting{}))
+	ctx := context.Background()
+
+	email := env.createEmail(t, env.inbox, 1, "contract", true, false)
+
+	// 其他客户端移动邮件并设置蓝色旗标和后续跟进
+	syncService := NewSyncService(env.db, nil, env.publisher, NewDeduplicatorFactory(env.db), nil, nil)
+	_, _, err := syncService.storeSyncedEmail(ctx, &providers.EmailMessage{
+		MessageID: email.MessageID,
+		UID:       21,
+		Subject:   email.Subject,
+		Date:      email.Date,
+		Flags:     []string{"\\Seen", "\\Flagged", "$MailFlagBit2", "$Followup"},
+	}, env.account.ID, env.work.ID, env.user.ID)
+	require.NoError(t, err)
+
+	var stored models.Email
+	require.NoError(t, env.db.First(&stored, email.ID).Error)
+	require.Equal(t, env.work.ID, *stored.FolderID)
+	require.True(t, stored.IsStarred)
+	require.Equal(t, "blue", stored.FlagColor)
+	require.True(t, stored.IsFollowUp)
+}
@@ -131,54 +131,42 @@ func RepairEmailGroupInvariantsForUser(ctx context.Context, db *gorm.DB, userID
 	})
 }
 
+// ValidateEmailGroupInvariantsForUser 校验用户分组不变量，各项统计合并为一次查询
 func ValidateEmailGroupInvariantsForUser(ctx context.Context, db *gorm.DB, userID uint) error {
 	if db == nil {
 		return fmt.Errorf("db is nil")
 	}
 
-	var defaultCount int64
-	if err := db.WithContext(ctx).
-		Model(&models.EmailGroup{}).
-		Where("user_id = ? AND is_default = 1", userID).
-		Count(&defaultCount).Error; err != nil {
-		return fmt.Errorf("failed to validate default email group count: %w", err)
-	}
-	if defaultCount != 1 {
-		return fmt.Errorf("%w: 默认分组数量异常（期望 1，实际 %d）", ErrEmailGroupInvariantViolation, defaultCount)
-	}
-
-	var nilGroupAccountCount int64
-	if err := db.WithContext(ctx).
-		Model(&models.EmailAccount{}).
-		Where("user_id = ? AND group_id IS NULL", userID).
-		Count(&nilGroupAccountCount).Error; err != nil {
-		return fmt.Errorf("failed to validate email account group linkage: %w", err)
+	var counts struct {
+		DefaultCount                  int64
+		NilGroupAccountCount          int64
+		PlaceholderCount              int64
+		HiddenSystemGroupAccountCount int64
 	}
-	if nilGroupAccountCount != 0 {
-		return fmt.Errorf("%w: 存在 %d 个邮箱账户未绑定分组", ErrEmailGroupInvariantViolation, nilGroupAccountCount)
+	if err := db.WithContext(ctx).Raw(`SELECT
+		(SELECT COUNT(*) FROM email_groups WHERE user_id = ? AND is_default = 1 AND deleted_at IS NULL) AS default_count,
+		(SELECT COUNT(*) FROM email_accounts WHERE user_id = ? AND group_id IS NULL AND deleted_at IS NULL) AS nil_group_account_count,
+		(SELECT COUNT(*) FROM email_groups WHERE user_id = ? AND system_key = ? AND deleted_at IS NULL) AS placeholder_count,
+		(SELECT COUNT(*) FROM email_accounts
+			JOIN email_groups ON email_groups.id = email_accounts.group_id AND email_groups.deleted_at IS NULL
+			WHERE email_accounts.user_id = ? AND email_accounts.deleted_at IS NULL
+				AND email_groups.system_key IS NOT NULL AND email_groups.is_default = 0) AS hidden_system_group_account_count`,
+		userID, userID, userID, models.EmailGroupSystemKeyDefaultPlaceholder, userID).
+		Scan(&counts).Error; err != nil {
+		return fmt.Errorf("failed to validate email group invariants: %w", err)
 	}
 
-	var placeholderCount int64
-	if err := db.WithContext(ctx).
-		Model(&models.EmailGroup{}).
-		Where("user_id = ? AND system_key = ?", userID, models.EmailGroupSystemKeyDefaultPlaceholder).
-		Count(&placeholderCount).Error; err != nil {
-		return fmt.Errorf("failed to validate placeholder email group count: %w", err)
+	if counts.DefaultCount != 1 {
+		return fmt.Errorf("%w: 默认分组数量异常（期望 1，实际 %d）", ErrEmailGroupInvariantViolation, counts.DefaultCount)
 	}
-	if placeholderCount > 1 {
-		return fmt.Errorf("%w: 系统占位分组数量异常（期望至多 1，实际 %d）", ErrEmailGroupInvariantViolation, placeholderCount)
+	if counts.NilGroupAccountCount != 0 {
+		return fmt.Errorf("%w: 存在 %d 个邮箱账户未绑定分组", ErrEmailGroupInvariantViolation, counts.NilGroupAccountCount)
 	}
-
-	var hiddenSystemGroupAccountCount int64
-	if err := db.WithContext(ctx).
-		Model(&models.EmailAccount{}).
-		Joins("JOIN email_groups ON email_groups.id = email_accounts.group_id").
-		Where("email_accounts.user_id = ? AND email_groups.system_key IS NOT NULL AND email_groups.is_default = 0", userID).
-		Count(&hiddenSystemGroupAccountCount).Error; err != nil {
-		return fmt.Errorf("failed to validate hidden system group assignments: %w", err)
+	if counts.PlaceholderCount > 1 {
+		return fmt.Errorf("%w: 系统占位分组数量异常（期望至多 1，实际 %d）", ErrEmailGroupInvariantViolation, counts.PlaceholderCount)
 	}
-	if hiddenSystemGroupAccountCount != 0 {
-		return fmt.Errorf("%w: 存在 %d 个邮箱账户挂在隐藏系统分组上", ErrEmailGroupInvariantViolation, hiddenSystemGroupAccountCount)
+	if counts.HiddenSystemGroupAccountCount != 0 {
+		return fmt.Errorf("%w: 存在 %d 个邮箱账户挂在隐藏系统分组上", ErrEmailGroupInvariantViolation, counts.HiddenSystemGroupAccountCount)
 	}
 
 	return nil
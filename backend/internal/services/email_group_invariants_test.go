@@ -148,22 +148,22 @@ func TestSystemGroupRestrictions(t *testing.T) {
 	account := env.createAccountRecord(t, "system-guard@qq.com", &workGroup.ID)
 
 	_, err := env.service.UpdateEmailGroup(ctx, env.user.ID, hiddenPlaceholder.ID, &UpdateEmailGroupRequest{Name: ptrString("新名字")})
-	require.ErrorContains(t, err, "系统分组不可编辑")
+	require.ErrorContains(t, err, "system group cannot be edited")
 
 	err = env.service.DeleteEmailGroup(ctx, env.user.ID, hiddenPlaceholder.ID)
-	require.ErrorContains(t, err, "系统分组不可删除")
+	require.ErrorContains(t, err, "system group cannot be deleted")
 
 	_, err = env.service.ResolveEmailGroup(ctx, env.user.ID, &hiddenPlaceholder.ID)
-	require.ErrorContains(t, err, "系统占位分组不可直接分配邮箱")
+	require.ErrorContains(t, err, "system placeholder group cannot be assigned accounts")
 
 	err = env.service.MoveAccountToGroup(ctx, env.user.ID, account.ID, &hiddenPlaceholder.ID)
-	require.ErrorContains(t, err, "系统占位分组不可直接分配邮箱")
+	require.ErrorContains(t, err, "system placeholder group cannot be assigned accounts")
 
 	_, err = env.service.SetDefaultEmailGroup(ctx, env.user.ID, hiddenPlaceholder.ID)
-	require.ErrorContains(t, err, "系统占位分组不可设为默认分组")
+	require.ErrorContains(t, err, "system placeholder group cannot be the default group")
 
 	_, err = env.service.ReorderEmailGroups(ctx, env.user.ID, []uint{hiddenPlaceholder.ID, workGroup.ID})
-	require.ErrorContains(t, err, "系统分组不可参与排序")
+	require.ErrorContains(t, err, "system group cannot be reordered")
 }
 
 func ptrString(value string) *string {
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidEmailGrouping 分组方式无效或与其他列表参数冲突
+var ErrInvalidEmailGrouping = errors.New("invalid email grouping")
+
+// 邮件列表的分组方式
+const (
+	EmailGroupByDate   = "date"   // 按用户时区的日期区间分组，要求按日期排序
+	EmailGroupBySender = "sender" // 按发件人分组，列表先按发件人排序
+)
+
+// 按日期分组时的区间，越靠后越早
+const (
+	EmailDateGroupToday     = "today"
+	EmailDateGroupYesterday = "yesterday"
+	EmailDateGroupLastWeek  = "last_week"  // 昨天之前的7天内
+	EmailDateGroupLastMonth = "last_month" // 一个月内
+	EmailDateGroupOlder     = "older"
+)
+
+// EmailListGroup 邮件列表的一个分组
+type EmailListGroup struct {
+	Key      string `json:"key"`             // 日期区间或发件人地址
+	Label    string `json:"label,omitempty"` // 发件人名称，按日期分组时为空
+	Count    int64  `json:"count"`           // 符合过滤条件的全部邮件中属于该分组的数量，不限于当前页
+	EmailIDs []uint `json:"email_ids"`       // 当前页中属于该分组的邮件，按列表顺序
+}
+
+// ValidEmailGroupBy 分组方式是否受支持，空值表示不分组
+func ValidEmailGroupBy(groupBy string) bool {
+	return groupBy == "" || groupBy == EmailGroupByDate || groupBy == EmailGroupBySender
+}
+
+// dateGroupBounds 各日期区间的起点，按从近到远排列
+type dateGroupBounds struct {
+	keys   []string
+	starts []time.Time
+}
+
+func newDateGroupBounds(now time.Time, loc *time.Location) *dateGroupBounds {
+	local := now.In(loc)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	yesterday := today.AddDate(0, 0, -1)
+	return &dateGroupBounds{
+		keys: []string{EmailDateGroupToday, EmailDateGroupYesterday, EmailDateGroupLastWeek, EmailDateGroupLastMonth},
+		starts: []time.Time{
+			today,
+			yesterday,
+			yesterday.AddDate(0, 0, -7),
+			today.AddDate(0, -1, 0),
+		},
+	}
+}
+
+// key 邮件日期所在的区间，晚于今天的日期算作今天
+func (b *dateGroupBounds) key(date time.Time) string {
+	for i, start := range b.starts {
+		if !date.Before(start) {
+			return b.keys[i]
+		}
+	}
+	return EmailDateGroupOlder
+}
+
+// caseExpression 按区间归类的SQL表达式及参数
+func (b *dateGroupBounds) caseExpression() (string, []interface{}) {
+	var sql strings.Builder
+	args := make([]interface{}, 0, len(b.starts))
+	sql.WriteString("CASE")
+	for i, start := range b.starts {
+		sql.WriteString(" WHEN emails.date >= ? THEN '" + b.keys[i] + "'")
+		args = append(args, start.UTC())
+	}
+	sql.WriteString(" ELSE '" + EmailDateGroupOlder + "' END")
+	return sql.String(), args
+}
+
+// validateEmailGrouping 检查分组方式与排序和分页参数是否兼容
+func validateEmailGrouping(req *GetEmailsRequest, sortBy string) error {
+	switch req.GroupBy {
+	case "":
+		return nil
+	case EmailGroupByDate:
+		if sortBy != "date" {
+			return fmt.Errorf("%w: grouping by date requires sorting by date", ErrInvalidEmailGrouping)
+		}
+	case EmailGroupBySender:
+		if req.Cursor != "" {
+			return fmt.Errorf("%w: cursor pagination does not support grouping by sender", ErrInvalidEmailGrouping)
+		}
+	default:
+		return fmt.Errorf("%w: group_by must be date or sender", ErrInvalidEmailGrouping)
+	}
+	if req.PinnedFirst {
+		return fmt.Errorf("%w: grouping does not support pinned_first", ErrInvalidEmailGrouping)
+	}
+	return nil
+}
+
+// buildEmailGroups 按分组方式整理当前页的邮件，并统计过滤条件下各分组的总数。
+// filtered 为只带过滤条件、未分页的查询
+func buildEmailGroups(ctx context.Context, db *gorm.DB, filtered *gorm.DB, userID uint, groupBy string, sortOrder string, emails []*models.Email) ([]*EmailListGroup, error) {
+	switch groupBy {
+	case EmailGroupByDate:
+		return buildDateGroups(filtered, userSettingsOrDefault(ctx, db, userID).Location(), sortOrder, emails)
+	case EmailGroupBySender:
+		return buildSenderGroups(filtered, emails)
+	}
+	return nil, nil
+}
+
+func buildDateGroups(filtered *gorm.DB, loc *time.Location, sortOrder string, emails []*models.Email) ([]*EmailListGroup, error) {
+	bounds := newDateGroupBounds(time.Now(), loc)
+	expression, args := bounds.caseExpression()
+
+	var rows []struct {
+		Bucket string
+		Count  int64
+	}
+	if err := filtered.Session(&gorm.Session{}).
+		Select(expression+" AS bucket, COUNT(*) AS count", args...).
+		Group("bucket").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count email date groups: %w", err)
+	}
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Bucket] = row.Count
+	}
+
+	// 分组顺序与列表的日期排序一致
+	keys := append(append([]string{}, bounds.keys...), EmailDateGroupOlder)
+	if sortOrder == "ASC" {
+		for i, j := 0, len(keys)-1; i < j; i, j = i+1, j-1 {
+			keys[i], keys[j] = keys[j], keys[i]
+		}
+	}
+
+	groups := make([]*EmailListGroup, 0, len(keys))
+	index := make(map[string]*EmailListGroup, len(keys))
+	for _, key := range keys {
+		if counts[key] == 0 {
+			continue
+		}
+		group := &EmailListGroup{Key: key, Count: counts[key], EmailIDs: []uint{}}
+		groups = append(groups, group)
+		index[key] = group
+	}
+	for _, email := range emails {
+		if group := index[bounds.key(email.Date)]; group != nil {
+			group.EmailIDs = append(group.EmailIDs, email.ID)
+		}
+	}
+	return groups, nil
+}
+
+// buildSenderGroups 只返回当前页出现的发件人；同一地址的不同显示名合并为一组
+func buildSenderGroups(filtered *gorm.DB, emails []*models.Email) ([]*EmailListGroup, error) {
+	var groups []*EmailListGroup
+	index := make(map[string]*EmailListGroup)
+	var fromValues []string
+	seenFrom := make(map[string]bool)
+	for _, email := range emails {
+		key, label := email.From, ""
+		if sender := parseEmailAddress(email.From); sender != nil {
+			key, label = strings.ToLower(sender.Address), sender.Name
+		}
+		group := index[key]
+		if group == nil {
+			group = &EmailListGroup{Key: key, Label: label, EmailIDs: []uint{}}
+			groups = append(groups, group)
+			index[key] = group
+		}
+		group.EmailIDs = append(group.EmailIDs, email.ID)
+		if !seenFrom[email.From] {
+			seenFrom[email.From] = true
+			fromValues = append(fromValues, email.From)
+		}
+	}
+	if len(fromValues) == 0 {
+		return []*EmailListGroup{}, nil
+	}
+
+	var rows []struct {
+		FromAddress string
+		Count       int64
+	}
+	if err := filtered.Session(&gorm.Session{}).
+		Select("emails.from_address AS from_address, COUNT(*) AS count").
+		Where("emails.from_address IN ?", fromValues).
+		Group("emails.from_address").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count email sender groups: %w", err)
+	}
+	for _, row := range rows {
+		key := row.FromAddress
+		if sender := parseEmailAddress(row.FromAddress); sender != nil {
+			key = strings.ToLower(sender.Address)
+		}
+		if group := index[key]; group != nil {
+			group.Count += row.Count
+		}
+	}
+	return groups, nil
+}
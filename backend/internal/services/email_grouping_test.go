@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEmailsGroupedByDate(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	dates := []time.Time{
+		today.Add(time.Minute),
+		today.Add(-time.Hour),
+		today.Add(-2 * time.Hour),
+		today.AddDate(0, 0, -3),
+		today.AddDate(0, -2, 0),
+	}
+	for i, date := range dates {
+		require.NoError(t, env.db.Create(&models.Email{
+			AccountID: env.account.ID,
+			FolderID:  &env.inbox.ID,
+			MessageID: fmt.Sprintf("<date-%d@example.com>", i),
+			UID:       uint32(i + 1),
+			Subject:   "dated",
+			From:      "sender@example.com",
+			Date:      date,
+		}).Error)
+	}
+
+	// 第一页只有三封邮件，分组总数仍按全部邮件统计
+	list, err := env.service.GetEmails(ctx, env.user.ID, &GetEmailsRequest{FolderID: &env.inbox.ID, PageSize: 3, GroupBy: EmailGroupByDate})
+	require.NoError(t, err)
+	require.Len(t, list.Emails, 3)
+
+	keys := make([]string, 0, len(list.Groups))
+	for _, group := range list.Groups {
+		keys = append(keys, group.Key)
+	}
+	require.Equal(t, []string{EmailDateGroupToday, EmailDateGroupYesterday, EmailDateGroupLastWeek, EmailDateGroupOlder}, keys)
+	require.Equal(t, int64(1), list.Groups[0].Count)
+	require.Equal(t, []uint{list.Emails[0].ID}, list.Groups[0].EmailIDs)
+	require.Equal(t, int64(2), list.Groups[1].Count)
+	require.Equal(t, []uint{list.Emails[1].ID, list.Emails[2].ID}, list.Groups[1].EmailIDs)
+	require.Equal(t, int64(1), list.Groups[2].Count)
+	require.Empty(t, list.Groups[2].EmailIDs)
+
+	_, err = env.service.GetEmails(ctx, env.user.ID, &GetEmailsRequest{FolderID: &env.inbox.ID, SortBy: "subject", GroupBy: EmailGroupByDate})
+	require.True(t, errors.Is(err, ErrInvalidEmailGrouping))
+}
+
+func TestGetEmailsGroupedBySender(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+
+	senders := []string{"Bob <bob@example.com>", "Alice <alice@example.com>", "bob@example.com", "Carol <carol@example.com>", "Alice <alice@example.com>"}
+	for i, from := range senders {
+		require.NoError(t, env.db.Create(&models.Email{
+			AccountID: env.account.ID,
+			FolderID:  &env.inbox.ID,
+			MessageID: fmt.Sprintf("<sender-%d@example.com>", i),
+			UID:       uint32(i + 1),
+			Subject:   "hello",
+			From:      from,
+			Date:      time.Now().Add(-time.Duration(i) * time.Minute),
+		}).Error)
+	}
+
+	list, err := env.service.GetEmails(ctx, env.user.ID, &GetEmailsRequest{FolderID: &env.inbox.ID, PageSize: 2, GroupBy: EmailGroupBySender})
+	require.NoError(t, err)
+	require.Len(t, list.Groups, 1)
+	require.Equal(t, "alice@example.com", list.Groups[0].Key)
+	require.Equal(t, "Alice", list.Groups[0].Label)
+	require.Equal(t, int64(2), list.Groups[0].Count)
+	require.Len(t, list.Groups[0].EmailIDs, 2)
+
+	// 同一地址的不同显示名合并为一组
+	list, err = env.service.GetEmails(ctx, env.user.ID, &GetEmailsRequest{FolderID: &env.inbox.ID, PageSize: 10, GroupBy: EmailGroupBySender})
+	require.NoError(t, err)
+	require.Len(t, list.Groups, 3)
+	require.Equal(t, "bob@example.com", list.Groups[1].Key)
+	require.Equal(t, int64(2), list.Groups[1].Count)
+
+	_, err = env.service.GetEmails(ctx, env.user.ID, &GetEmailsRequest{FolderID: &env.inbox.ID, GroupBy: EmailGroupBySender, PinnedFirst: true})
+	require.True(t, errors.Is(err, ErrInvalidEmailGrouping))
+}
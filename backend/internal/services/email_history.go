@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// EmailHistoryEntry 邮件历史事件，附带文件夹名称便于展示
+type EmailHistoryEntry struct {
+	models.EmailEvent
+	FromFolder string `json:"from_folder,omitempty"`
+	ToFolder   string `json:"to_folder,omitempty"`
+}
+
+// newEmailEvent 创建邮件历史事件
+func newEmailEvent(userID, accountID, emailID uint, eventType, source string) *models.EmailEvent {
+	return &models.EmailEvent{
+		UserID:    userID,
+		AccountID: accountID,
+		EmailID:   emailID,
+		Type:      eventType,
+		Source:    source,
+	}
+}
+
+// emailEventDetails 将事件详情序列化为JSON
+func emailEventDetails(details map[string]interface{}) string {
+	data, err := json.Marshal(details)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// recordEmailEvents 写入邮件历史事件，失败只记录日志，不影响主流程
+func recordEmailEvents(ctx context.Context, db *gorm.DB, events ...*models.EmailEvent) {
+	if db == nil || len(events) == 0 {
+		return
+	}
+	if err := db.WithContext(ctx).CreateInBatches(events, 500).Error; err != nil {
+		log.Printf("Warning: failed to record email history: %v", err)
+	}
+}
+
+// emailReadEvents 批量标记已读时为每封邮件生成事件
+func emailReadEvents(userID uint, emails []models.Email) []*models.EmailEvent {
+	events := make([]*models.EmailEvent, 0, len(emails))
+	for _, email := range emails {
+		events = append(events, newEmailEvent(userID, email.AccountID, email.ID, models.EmailEventRead, models.EmailEventSourceUser))
+	}
+	return events
+}
+
+// emailStateEvents 比较邮件更新前后的状态，生成对应的历史事件
+func emailStateEvents(userID uint, before, after *models.Email, source string) []*models.EmailEvent {
+	var events []*models.EmailEvent
+	add := func(eventType string) *models.EmailEvent {
+		event := newEmailEvent(userID, after.AccountID, after.ID, eventType, source)
+		events = append(events, event)
+		return event
+	}
+
+	if before.IsRead != after.IsRead {
+		if after.IsRead {
+			add(models.EmailEventRead)
+		} else {
+			add(models.EmailEventUnread)
+		}
+	}
+	if before.IsStarred != after.IsStarred {
+		if after.IsStarred {
+			add(models.EmailEventStarred)
+		} else {
+			add(models.EmailEventUnstarred)
+		}
+	}
+	if !sameFolderID(before.FolderID, after.FolderID) {
+		event := add(models.EmailEventMoved)
+		event.FromFolderID = before.FolderID
+		event.ToFolderID = after.FolderID
+	}
+	if before.Labels != after.Labels {
+		oldLabels, _ := before.GetLabels()
+		newLabels, _ := after.GetLabels()
+		added, removed := diffLabels(oldLabels, newLabels)
+		if len(added) > 0 || len(removed) > 0 {
+			event := add(models.EmailEventLabelsChanged)
+			event.Details = emailEventDetails(map[string]interface{}{"added": added, "removed": removed})
+		}
+	}
+	return events
+}
+
+func sameFolderID(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// diffLabels 返回新增和移除的标签
+func diffLabels(before, after []string) ([]string, []string) {
+	oldSet := make(map[string]bool, len(before))
+	for _, label := range before {
+		oldSet[label] = true
+	}
+	newSet := make(map[string]bool, len(after))
+	for _, label := range after {
+		newSet[label] = true
+	}
+
+	var added, removed []string
+	for _, label := range after {
+		if !oldSet[label] {
+			added = append(added, label)
+		}
+	}
+	for _, label := range before {
+		if !newSet[label] {
+			removed = append(removed, label)
+		}
+	}
+	return added, removed
+}
+
+// GetEmailHistory 获取邮件的操作历史，按时间先后排序
+func (s *EmailServiceImpl) GetEmailHistory(ctx context.Context, userID, emailID uint) ([]*EmailHistoryEntry, error) {
+	if _, err := s.getEmailForUser(ctx, userID, emailID, true); err != nil {
+		return nil, err
+	}
+
+	var events []models.EmailEvent
+	if err := s.db.WithContext(ctx).
+		Where("email_id = ? AND user_id = ?", emailID, userID).
+		Order("created_at ASC, id ASC").
+		Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to load email history: %w", err)
+	}
+
+	// 一次性加载涉及的文件夹名称
+	folderIDs := make([]uint, 0)
+	for _, event := range events {
+		if event.FromFolderID != nil {
+			folderIDs = append(folderIDs, *event.FromFolderID)
+		}
+		if event.ToFolderID != nil {
+			folderIDs = append(folderIDs, *event.ToFolderID)
+		}
+	}
+	folderNames := make(map[uint]string)
+	if len(folderIDs) > 0 {
+		var folders []models.Folder
+		if err := s.db.WithContext(ctx).Where("id IN ?", uniqueUints(folderIDs)).Find(&folders).Error; err != nil {
+			return nil, fmt.Errorf("failed to load folders: %w", err)
+		}
+		for _, folder := range folders {
+			name := folder.DisplayName
+			if name == "" {
+				name = folder.Name
+			}
+			folderNames[folder.ID] = name
+		}
+	}
+
+	entries := make([]*EmailHistoryEntry, 0, len(events))
+	for _, event := range events {
+		entry := &EmailHistoryEntry{EmailEvent: event}
+		if event.FromFolderID != nil {
+			entry.FromFolder = folderNames[*event.FromFolderID]
+		}
+		if event.ToFolderID != nil {
+			entry.ToFolder = folderNames[*event.ToFolderID]
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// recordOutgoingEmailEvent 为被回复或转发的原邮件记录事件，详情中保存发出邮件的主题和收件人
+func (s *EmailServiceImpl) recordOutgoingEmailEvent(ctx context.Context, userID, emailID uint, eventType string, req *SendEmailRequest) {
+	original, err := s.getEmailForUser(ctx, userID, emailID, true)
+	if err != nil {
+		log.Printf("Warning: failed to record %s history for email %d: %v", eventType, emailID, err)
+		return
+	}
+
+	recipients := make([]string, 0, len(req.To))
+	for _, address := range req.To {
+		if address != nil {
+			recipients = append(recipients, address.Address)
+		}
+	}
+	event := newEmailEvent(userID, original.AccountID, original.ID, eventType, models.EmailEventSourceUser)
+	event.Details = emailEventDetails(map[string]interface{}{
+		"subject":    req.Subject,
+		"to":         recipients,
+		"account_id": req.AccountID,
+	})
+	recordEmailEvents(ctx, s.db, event)
+}
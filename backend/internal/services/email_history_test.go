@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+	"firemail/internal/providers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailHistoryRecordsUserActions(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.EmailEvent{}))
+	ctx := context.Background()
+
+	email := env.createEmail(t, env.inbox, 1, "history", false, false)
+	require.NoError(t, env.service.MarkEmailAsRead(ctx, env.user.ID, email.ID))
+	require.NoError(t, env.service.MoveEmail(ctx, env.user.ID, email.ID, env.work.ID))
+	require.NoError(t, env.service.DeleteEmail(ctx, env.user.ID, email.ID))
+	_, err := env.service.RestoreEmails(ctx, env.user.ID, []uint{email.ID})
+	require.NoError(t, err)
+
+	history, err := env.service.GetEmailHistory(ctx, env.user.ID, email.ID)
+	require.NoError(t, err)
+
+	types := make([]string, len(history))
+	for i, entry := range history {
+		types[i] = entry.Type
+		require.Equal(t, models.EmailEventSourceUser, entry.Source)
+	}
+	require.Equal(t, []string{
+		models.EmailEventRead,
+		models.EmailEventMoved,
+		models.EmailEventDeleted,
+		models.EmailEventRestored,
+	}, types)
+	require.Equal(t, "收件箱", history[1].FromFolder)
+	require.Equal(t, "项目", history[1].ToFolder)
+	require.Equal(t, "项目", history[2].FromFolder)
+
+	_, err = env.service.GetEmailHistory(ctx, env.user.ID+1, email.ID)
+	require.EqualError(t, err, "email not found")
+}
+
+func TestEmailHistoryRecordsSyncChanges(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.EmailEvent{}))
+	ctx := context.Background()
+
+	syncService := NewSyncService(env.db, nil, nil, NewDeduplicatorFactory(env.db), nil, nil)
+	message := &providers.EmailMessage{
+		MessageID: "<sync-history@example.com>",
+		UID:       7,
+		Subject:   "synced",
+		From:      &models.EmailAddress{Address: "sender@example.com"},
+		Date:      time.Now(),
+	}
+	require.NoError(t, syncService.saveEmailToDatabase(ctx, message, env.account.ID, env.inbox.ID, env.user.ID))
+
+	// 其他设备把邮件移到了另一个文件夹
+	message.UID = 3
+	require.NoError(t, syncService.saveEmailToDatabase(ctx, message, env.account.ID, env.work.ID, env.user.ID))
+
+	var email models.Email
+	require.NoError(t, env.db.Where("message_id = ?", message.MessageID).First(&email).Error)
+	history, err := env.service.GetEmailHistory(ctx, env.user.ID, email.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+
+	require.Equal(t, models.EmailEventSynced, history[0].Type)
+	require.Equal(t, models.EmailEventSourceSync, history[0].Source)
+	require.Equal(t, env.inbox.ID, *history[0].ToFolderID)
+	require.Equal(t, models.EmailEventMoved, history[1].Type)
+	require.Equal(t, models.EmailEventSourceSync, history[1].Source)
+	require.Equal(t, env.inbox.ID, *history[1].FromFolderID)
+	require.Equal(t, "项目", history[1].ToFolder)
+}
+
+func TestEmailStateEventsDetectsLabelChanges(t *testing.T) {
+	before := &models.Email{IsRead: true}
+	require.NoError(t, before.SetLabels([]string{"work", "todo"}))
+	after := *before
+	after.IsRead = false
+	after.IsStarred = true
+	require.NoError(t, after.SetLabels([]string{"work", "done"}))
+
+	events := emailStateEvents(1, before, &after, models.EmailEventSourceSync)
+	require.Len(t, events, 3)
+	require.Equal(t, models.EmailEventUnread, events[0].Type)
+	require.Equal(t, models.EmailEventStarred, events[1].Type)
+	require.Equal(t, models.EmailEventLabelsChanged, events[2].Type)
+	require.JSONEq(t, `{"added":["done"],"removed":["todo"]}`, events[2].Details)
+}
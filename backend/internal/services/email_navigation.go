@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrNavigationEmailNotFound 导航的起点邮件不存在或不属于当前用户
+var ErrNavigationEmailNotFound = errors.New("email not found")
+
+// EmailNavigation 邮件在列表中的前后邮件，用于键盘逐封浏览（j/k）
+type EmailNavigation struct {
+	EmailID    uint  `json:"email_id"`
+	PreviousID *uint `json:"previous_id"` // 列表中排在前面的邮件，已是第一封时为空
+	NextID     *uint `json:"next_id"`     // 列表中排在后面的邮件，已是最后一封时为空
+	Position   int64 `json:"position"`    // 当前邮件在列表中的序号（从1开始），已不符合过滤条件时为0
+	Total      int64 `json:"total"`       // 符合过滤条件的邮件总数
+}
+
+// emailSortKey 列表排序中的一列及其在邮件上的取值
+type emailSortKey struct {
+	expr  string
+	desc  bool
+	value func(*models.Email) interface{}
+}
+
+// emailSortKeys 列表的完整排序，最后一列总是ID，保证顺序唯一
+type emailSortKeys []emailSortKey
+
+// emailListOrder 与 GetEmails 一致的排序：置顶优先、按发件人分组、排序字段，最后按ID
+func emailListOrder(req *GetEmailsRequest, sortBy, sortOrder string) emailSortKeys {
+	var keys emailSortKeys
+	if req.PinnedFirst {
+		keys = append(keys,
+			emailSortKey{expr: "emails.is_pinned", desc: true, value: func(e *models.Email) interface{} { return e.IsPinned }},
+			// 未置顶的邮件没有置顶时间，用空串代替NULL，使比较和排序都有确定结果
+			emailSortKey{expr: "COALESCE(emails.pinned_at, '')", desc: true, value: func(e *models.Email) interface{} {
+				if e.PinnedAt == nil {
+					return ""
+				}
+				return *e.PinnedAt
+			}},
+		)
+	}
+	if req.GroupBy == EmailGroupBySender {
+		// 同一发件人的邮件相邻，分组可以跨页延续
+		keys = append(keys, emailSortKey{expr: "emails.from_address", value: func(e *models.Email) interface{} { return e.From }})
+	}
+
+	desc := sortOrder == "DESC"
+	column := emailSortKey{expr: "emails." + sortBy, desc: desc}
+	switch sortBy {
+	case "subject":
+		column.value = func(e *models.Email) interface{} { return e.Subject }
+	case "from_address":
+		column.value = func(e *models.Email) interface{} { return e.From }
+	case "size":
+		column.value = func(e *models.Email) interface{} { return e.Size }
+	default:
+		column.value = func(e *models.Email) interface{} { return e.Date }
+	}
+	return append(keys, column, emailSortKey{expr: "emails.id", desc: desc, value: func(e *models.Email) interface{} { return e.ID }})
+}
+
+// orderClause 排序子句，reverse 时整体反向
+func (keys emailSortKeys) orderClause(reverse bool) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		direction := "ASC"
+		if key.desc != reverse {
+			direction = "DESC"
+		}
+		parts[i] = key.expr + " " + direction
+	}
+	return strings.Join(parts, ", ")
+}
+
+// after 按列表顺序排在 email 之后的条件；reverse 时为排在之前
+func (keys emailSortKeys) after(email *models.Email, reverse bool) (string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+	for i, key := range keys {
+		var parts []string
+		for _, prev := range keys[:i] {
+			parts = append(parts, prev.expr+" = ?")
+			args = append(args, prev.value(email))
+		}
+		op := ">"
+		if key.desc != reverse {
+			op = "<"
+		}
+		parts = append(parts, key.expr+" "+op+" ?")
+		args = append(args, key.value(email))
+		clauses = append(clauses, "("+strings.Join(parts, " AND ")+")")
+	}
+	return "(" + strings.Join(clauses, " OR ") + ")", args
+}
+
+// emailNavigationColumns 计算前后位置需要的列
+var emailNavigationColumns = []string{
+	"emails.id", "emails.subject", "emails.from_address", "emails.date", "emails.size",
+	"emails.is_pinned", "emails.pinned_at",
+}
+
+// GetEmailNavigation 按列表的过滤条件和排序返回邮件的前后邮件，不受分页限制。
+// 起点邮件已不符合过滤条件（例如未读列表中刚被标为已读）时，仍按它原本的位置查找前后邮件
+func (s *EmailServiceImpl) GetEmailNavigation(ctx context.Context, userID, emailID uint, req *GetEmailsRequest) (*EmailNavigation, error) {
+	sortBy := emailListSortColumn(req.SortBy)
+	sortOrder := normalizeSortOrder(req.SortOrder)
+	if err := validateEmailGrouping(req, sortBy); err != nil {
+		return nil, err
+	}
+
+	var current models.Email
+	err := s.db.WithContext(ctx).Select(emailNavigationColumns).
+		Where("emails.id = ? AND emails.user_id = ? AND emails.is_deleted = ?", emailID, userID, false).
+		First(&current).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNavigationEmailNotFound
+		}
+		return nil, fmt.Errorf("failed to get email: %w", err)
+	}
+
+	keys := emailListOrder(req, sortBy, sortOrder)
+	filtered := s.emailListQuery(ctx, userID, req)
+	navigation := &EmailNavigation{EmailID: emailID}
+
+	if err := filtered.Session(&gorm.Session{}).Count(&navigation.Total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count emails: %w", err)
+	}
+
+	neighbour := func(reverse bool) (*uint, error) {
+		condition, args := keys.after(&current, reverse)
+		var ids []uint
+		if err := filtered.Session(&gorm.Session{}).
+			Where(condition, args...).
+			Order(keys.orderClause(reverse)).
+			Limit(1).
+			Pluck("emails.id", &ids).Error; err != nil {
+			return nil, fmt.Errorf("failed to get adjacent email: %w", err)
+		}
+		if len(ids) == 0 {
+			return nil, nil
+		}
+		return &ids[0], nil
+	}
+	if navigation.PreviousID, err = neighbour(true); err != nil {
+		return nil, err
+	}
+	if navigation.NextID, err = neighbour(false); err != nil {
+		return nil, err
+	}
+
+	var matches int64
+	if err := filtered.Session(&gorm.Session{}).Where("emails.id = ?", emailID).Count(&matches).Error; err != nil {
+		return nil, fmt.Errorf("failed to count emails: %w", err)
+	}
+	if matches > 0 {
+		condition, args := keys.after(&current, true)
+		var before int64
+		if err := filtered.Session(&gorm.Session{}).Where(condition, args...).Count(&before).Error; err != nil {
+			return nil, fmt.Errorf("failed to count emails: %w", err)
+		}
+		navigation.Position = before + 1
+	}
+
+	return navigation, nil
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+// walkEmailNavigation 从列表第一封开始沿 next_id 走完整个列表
+func walkEmailNavigation(t *testing.T, env *emailStateServiceTestEnv, req *GetEmailsRequest) []uint {
+	t.Helper()
+	ctx := context.Background()
+
+	page := *req
+	page.Page, page.PageSize = 1, 100
+	list, err := env.service.GetEmails(ctx, env.user.ID, &page)
+	require.NoError(t, err)
+	require.NotEmpty(t, list.Emails)
+
+	var ids []uint
+	var previous *uint
+	for id := &list.Emails[0].ID; id != nil; {
+		navigation, err := env.service.GetEmailNavigation(ctx, env.user.ID, *id, req)
+		require.NoError(t, err)
+		require.Equal(t, previous, navigation.PreviousID)
+		require.Equal(t, int64(len(ids)+1), navigation.Position)
+		require.Equal(t, list.Total, navigation.Total)
+		ids = append(ids, *id)
+		previous, id = id, navigation.NextID
+	}
+
+	expected := make([]uint, 0, len(list.Emails))
+	for _, email := range list.Emails {
+		expected = append(expected, email.ID)
+	}
+	require.Equal(t, expected, ids)
+	return ids
+}
+
+func TestGetEmailNavigationFollowsListOrder(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+
+	base := time.Now().UTC().Truncate(time.Second)
+	dates := []time.Time{base, base.Add(-time.Hour), base.Add(-time.Hour), base.Add(-2 * time.Hour), base.Add(-3 * time.Hour)}
+	senders := []string{"b@example.com", "a@example.com", "b@example.com", "c@example.com", "a@example.com"}
+	emails := make([]*models.Email, len(dates))
+	for i := range dates {
+		emails[i] = &models.Email{
+			AccountID: env.account.ID,
+			FolderID:  &env.inbox.ID,
+			MessageID: fmt.Sprintf("<nav-%d@example.com>", i),
+			UID:       uint32(i + 1),
+			Subject:   fmt.Sprintf("subject %d", len(dates)-i),
+			From:      senders[i],
+			Date:      dates[i],
+			Size:      int64(100 * (i%3 + 1)),
+		}
+		require.NoError(t, env.db.Create(emails[i]).Error)
+	}
+	now := time.Now()
+	require.NoError(t, env.db.Model(emails[3]).Updates(map[string]interface{}{"is_pinned": true, "pinned_at": now}).Error)
+
+	// 日期相同的邮件按ID排序，跨越分页边界逐封浏览
+	ids := walkEmailNavigation(t, env, &GetEmailsRequest{FolderID: &env.inbox.ID, PageSize: 2})
+	require.Equal(t, []uint{emails[0].ID, emails[2].ID, emails[1].ID, emails[3].ID, emails[4].ID}, ids)
+
+	walkEmailNavigation(t, env, &GetEmailsRequest{FolderID: &env.inbox.ID, SortOrder: "asc"})
+	walkEmailNavigation(t, env, &GetEmailsRequest{FolderID: &env.inbox.ID, SortBy: "subject", SortOrder: "asc"})
+	walkEmailNavigation(t, env, &GetEmailsRequest{FolderID: &env.inbox.ID, SortBy: "size"})
+	walkEmailNavigation(t, env, &GetEmailsRequest{FolderID: &env.inbox.ID, PinnedFirst: true})
+	walkEmailNavigation(t, env, &GetEmailsRequest{FolderID: &env.inbox.ID, GroupBy: EmailGroupBySender})
+
+	_, err := env.service.GetEmailNavigation(ctx, env.user.ID+1, emails[0].ID, &GetEmailsRequest{})
+	require.True(t, errors.Is(err, ErrNavigationEmailNotFound))
+
+	_, err = env.service.GetEmailNavigation(ctx, env.user.ID, emails[0].ID, &GetEmailsRequest{SortBy: "subject", GroupBy: EmailGroupByDate})
+	require.True(t, errors.Is(err, ErrInvalidEmailGrouping))
+}
+
+func TestGetEmailNavigationOutsideFilter(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+
+	first := env.createEmail(t, env.inbox, 1, "first", false, false)
+	second := env.createEmail(t, env.inbox, 2, "second", false, false)
+	third := env.createEmail(t, env.inbox, 3, "third", false, false)
+	require.NoError(t, env.db.Model(first).Update("date", second.Date.Add(time.Hour)).Error)
+	require.NoError(t, env.db.Model(third).Update("date", second.Date.Add(-time.Hour)).Error)
+
+	// 未读列表中刚被标为已读的邮件仍能找到原位置前后的邮件
+	require.NoError(t, env.db.Model(second).Update("is_read", true).Error)
+	unread := false
+	navigation, err := env.service.GetEmailNavigation(ctx, env.user.ID, second.ID, &GetEmailsRequest{FolderID: &env.inbox.ID, IsRead: &unread})
+	require.NoError(t, err)
+	require.Equal(t, &first.ID, navigation.PreviousID)
+	require.Equal(t, &third.ID, navigation.NextID)
+	require.Zero(t, navigation.Position)
+	require.Equal(t, int64(2), navigation.Total)
+}
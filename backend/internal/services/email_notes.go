@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"firemail/internal/models"
+	"firemail/internal/providers"
+
+	"gorm.io/gorm"
+)
+
+// emailNoteAnnotationEntry 同步笔记使用的IMAP注释条目（RFC 5257）
+const emailNoteAnnotationEntry = "/comment"
+
+// CreateEmailNoteRequest 创建邮件笔记请求
+type CreateEmailNoteRequest struct {
+	Content      string `json:"content" binding:"required,max=10000"`
+	SyncToServer bool   `json:"sync_to_server"` // 服务器支持ANNOTATE时同步为邮件注释
+}
+
+// UpdateEmailNoteRequest 更新邮件笔记请求，未提供的字段保持不变
+type UpdateEmailNoteRequest struct {
+	Content      *string `json:"content,omitempty" binding:"omitempty,max=10000"`
+	SyncToServer *bool   `json:"sync_to_server,omitempty"`
+}
+
+// emailNotesAvailable 笔记表是否存在，用于在未迁移的数据库上跳过笔记相关查询
+func emailNotesAvailable(db *gorm.DB) bool {
+	return db.Migrator().HasTable(&models.EmailNote{})
+}
+
+// emailNoteSearchCondition 按笔记内容匹配邮件的子查询条件
+const emailNoteSearchCondition = "EXISTS (SELECT 1 FROM email_notes WHERE email_notes.email_id = emails.id AND " +
+	"email_notes.user_id = emails.user_id AND email_notes.deleted_at IS NULL AND email_notes.content LIKE ?)"
+
+// ListEmailNotes 获取邮件的私有笔记
+func (s *EmailServiceImpl) ListEmailNotes(ctx context.Context, userID, emailID uint) ([]models.EmailNote, error) {
+	if _, err := s.getEmailForUser(ctx, userID, emailID, true); err != nil {
+		return nil, err
+	}
+
+	var notes []models.EmailNote
+	if err := s.db.WithContext(ctx).
+		Where("email_id = ? AND user_id = ?", emailID, userID).
+		Order("created_at ASC, id ASC").
+		Find(&notes).Error; err != nil {
+		return nil, fmt.Errorf("failed to load notes: %w", err)
+	}
+	return notes, nil
+}
+
+// CreateEmailNote 为邮件添加私有笔记
+func (s *EmailServiceImpl) CreateEmailNote(ctx context.Context, userID, emailID uint, req *CreateEmailNoteRequest) (*models.EmailNote, error) {
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		return nil, fmt.Errorf("note content cannot be empty")
+	}
+
+	email, err := s.getEmailForUser(ctx, userID, emailID, true, "Account", "Folder")
+	if err != nil {
+		return nil, err
+	}
+
+	note := &models.EmailNote{
+		UserID:       userID,
+		EmailID:      emailID,
+		Content:      content,
+		SyncToServer: req.SyncToServer,
+	}
+	if err := s.db.WithContext(ctx).Create(note).Error; err != nil {
+		return nil, fmt.Errorf("failed to create note: %w", err)
+	}
+
+	if note.SyncToServer {
+		s.syncEmailNoteAnnotation(ctx, userID, email)
+	}
+	recordEmailChanges(ctx, s.changeLog, userID, email.AccountID, models.ChangeActionUpdated, email.ID)
+	return s.reloadEmailNote(ctx, note)
+}
+
+// UpdateEmailNote 更新邮件笔记
+func (s *EmailServiceImpl) UpdateEmailNote(ctx context.Context, userID, emailID, noteID uint, req *UpdateEmailNoteRequest) (*models.EmailNote, error) {
+	email, note, err := s.getEmailNote(ctx, userID, emailID, noteID)
+	if err != nil {
+		return nil, err
+	}
+
+	wasSynced := note.SyncToServer
+	updates := map[string]interface{}{}
+	if req.Content != nil {
+		content := strings.TrimSpace(*req.Content)
+		if content == "" {
+			return nil, fmt.Errorf("note content cannot be empty")
+		}
+		updates["content"] = content
+	}
+	if req.SyncToServer != nil {
+		updates["sync_to_server"] = *req.SyncToServer
+		if !*req.SyncToServer {
+			updates["synced_at"] = nil
+		}
+	}
+	if len(updates) == 0 {
+		return note, nil
+	}
+
+	if err := s.db.WithContext(ctx).Model(note).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update note: %w", err)
+	}
+
+	// 笔记参与同步或刚取消同步时，都需要刷新服务器上的注释
+	if wasSynced || (req.SyncToServer != nil && *req.SyncToServer) {
+		s.syncEmailNoteAnnotation(ctx, userID, email)
+	}
+	recordEmailChanges(ctx, s.changeLog, userID, email.AccountID, models.ChangeActionUpdated, email.ID)
+	return s.reloadEmailNote(ctx, note)
+}
+
+// DeleteEmailNote 删除邮件笔记
+func (s *EmailServiceImpl) DeleteEmailNote(ctx context.Context, userID, emailID, noteID uint) error {
+	email, note, err := s.getEmailNote(ctx, userID, emailID, noteID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.WithContext(ctx).Delete(note).Error; err != nil {
+		return fmt.Errorf("failed to delete note: %w", err)
+	}
+
+	if note.SyncToServer {
+		s.syncEmailNoteAnnotation(ctx, userID, email)
+	}
+	recordEmailChanges(ctx, s.changeLog, userID, email.AccountID, models.ChangeActionUpdated, email.ID)
+	return nil
+}
+
+// getEmailNote 查找当前用户在指定邮件上的笔记
+func (s *EmailServiceImpl) getEmailNote(ctx context.Context, userID, emailID, noteID uint) (*models.Email, *models.EmailNote, error) {
+	email, err := s.getEmailForUser(ctx, userID, emailID, true, "Account", "Folder")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var note models.EmailNote
+	if err := s.db.WithContext(ctx).
+		Where("id = ? AND email_id = ? AND user_id = ?", noteID, emailID, userID).
+		First(&note).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, fmt.Errorf("note not found")
+		}
+		return nil, nil, fmt.Errorf("failed to find note: %w", err)
+	}
+	return email, &note, nil
+}
+
+func (s *EmailServiceImpl) reloadEmailNote(ctx context.Context, note *models.EmailNote) (*models.EmailNote, error) {
+	var current models.EmailNote
+	if err := s.db.WithContext(ctx).First(&current, note.ID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load note: %w", err)
+	}
+	return &current, nil
+}
+
+// syncEmailNoteAnnotation 将邮件上所有需要同步的笔记合并写入服务器注释
+// 服务器不支持ANNOTATE或同步失败时只记录日志，笔记仍保存在本地
+func (s *EmailServiceImpl) syncEmailNoteAnnotation(ctx context.Context, userID uint, email *models.Email) {
+	if email.UID == 0 || email.Folder == nil || email.Folder.GetFullPath() == "" {
+		return
+	}
+
+	var notes []models.EmailNote
+	if err := s.db.WithContext(ctx).
+		Where("email_id = ? AND user_id = ? AND sync_to_server = ?", email.ID, userID, true).
+		Order("created_at ASC, id ASC").
+		Find(&notes).Error; err != nil {
+		log.Printf("Warning: failed to load notes for annotation sync: %v", err)
+		return
+	}
+
+	provider, err := s.providerFactory.CreateProviderForAccount(&email.Account)
+	if err != nil {
+		log.Printf("Warning: failed to create provider for annotation sync: %v", err)
+		return
+	}
+	s.setupProviderTokenCallback(provider)
+
+	if err := provider.Connect(ctx, &email.Account); err != nil {
+		log.Printf("Warning: failed to connect for annotation sync: %v", err)
+		return
+	}
+	defer provider.Disconnect()
+
+	annotations, ok := provider.IMAPClient().(providers.AnnotationClient)
+	if !ok || !annotations.SupportsAnnotations() {
+		return
+	}
+	if _, err := provider.IMAPClient().SelectFolder(ctx, email.Folder.GetFullPath()); err != nil {
+		log.Printf("Warning: failed to select folder for annotation sync: %v", err)
+		return
+	}
+
+	contents := make([]string, len(notes))
+	noteIDs := make([]uint, len(notes))
+	for i, note := range notes {
+		contents[i] = note.Content
+		noteIDs[i] = note.ID
+	}
+	if err := annotations.SetAnnotation(ctx, email.UID, emailNoteAnnotationEntry, strings.Join(contents, "\n\n")); err != nil {
+		log.Printf("Warning: failed to sync notes for email %d: %v", email.ID, err)
+		return
+	}
+
+	if len(noteIDs) > 0 {
+		if err := s.db.WithContext(ctx).Model(&models.EmailNote{}).
+			Where("id IN ?", noteIDs).
+			Update("synced_at", time.Now()).Error; err != nil {
+			log.Printf("Warning: failed to mark notes as synced: %v", err)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailNotesCRUDAndSearch(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.EmailNote{}))
+	ctx := context.Background()
+
+	email := env.createEmail(t, env.inbox, 1, "Invoice", false, false)
+	env.createEmail(t, env.inbox, 2, "Newsletter", false, false)
+
+	note, err := env.service.CreateEmailNote(ctx, env.user.ID, email.ID, &CreateEmailNoteRequest{Content: "  call vendor about refund  "})
+	require.NoError(t, err)
+	require.Equal(t, "call vendor about refund", note.Content)
+	require.Nil(t, note.SyncedAt)
+
+	_, err = env.service.CreateEmailNote(ctx, env.user.ID, email.ID, &CreateEmailNoteRequest{Content: "   "})
+	require.EqualError(t, err, "note content cannot be empty")
+	_, err = env.service.CreateEmailNote(ctx, env.user.ID+1, email.ID, &CreateEmailNoteRequest{Content: "x"})
+	require.EqualError(t, err, "email not found")
+
+	loaded, err := env.service.GetEmail(ctx, env.user.ID, email.ID)
+	require.NoError(t, err)
+	require.Len(t, loaded.Notes, 1)
+	require.Equal(t, note.ID, loaded.Notes[0].ID)
+
+	result, err := env.service.SearchEmails(ctx, env.user.ID, &SearchEmailsRequest{Query: "note:refund"})
+	require.NoError(t, err)
+	require.Len(t, result.Emails, 1)
+	require.Equal(t, email.ID, result.Emails[0].ID)
+
+	result, err = env.service.SearchEmails(ctx, env.user.ID, &SearchEmailsRequest{Query: "vendor"})
+	require.NoError(t, err)
+	require.Len(t, result.Emails, 1)
+
+	content := "escalate to finance"
+	updated, err := env.service.UpdateEmailNote(ctx, env.user.ID, email.ID, note.ID, &UpdateEmailNoteRequest{Content: &content})
+	require.NoError(t, err)
+	require.Equal(t, content, updated.Content)
+
+	_, err = env.service.UpdateEmailNote(ctx, env.user.ID+1, email.ID, note.ID, &UpdateEmailNoteRequest{Content: &content})
+	require.EqualError(t, err, "email not found")
+	require.EqualError(t, env.service.DeleteEmailNote(ctx, env.user.ID, email.ID, note.ID+100), "note not found")
+
+	require.NoError(t, env.service.DeleteEmailNote(ctx, env.user.ID, email.ID, note.ID))
+	notes, err := env.service.ListEmailNotes(ctx, env.user.ID, email.ID)
+	require.NoError(t, err)
+	require.Empty(t, notes)
+}
+
+func TestEmailNotesSyncToServerAnnotation(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.EmailNote{}))
+	ctx := context.Background()
+
+	email := env.createEmail(t, env.inbox, 7, "Contract", false, false)
+
+	// 服务器不支持ANNOTATE时只保存在本地
+	local, err := env.service.CreateEmailNote(ctx, env.user.ID, email.ID, &CreateEmailNoteRequest{Content: "draft", SyncToServer: true})
+	require.NoError(t, err)
+	require.Nil(t, local.SyncedAt)
+	require.Empty(t, env.provider.imap.annotations)
+
+	env.provider.imap.supportsAnnotations = true
+	synced, err := env.service.CreateEmailNote(ctx, env.user.ID, email.ID, &CreateEmailNoteRequest{Content: "signed", SyncToServer: true})
+	require.NoError(t, err)
+	require.NotNil(t, synced.SyncedAt)
+	require.Equal(t, "draft\n\nsigned", env.provider.imap.annotations[7])
+
+	_, err = env.service.CreateEmailNote(ctx, env.user.ID, email.ID, &CreateEmailNoteRequest{Content: "private only"})
+	require.NoError(t, err)
+	require.Equal(t, "draft\n\nsigned", env.provider.imap.annotations[7])
+
+	require.NoError(t, env.service.DeleteEmailNote(ctx, env.user.ID, email.ID, local.ID))
+	require.Equal(t, "signed", env.provider.imap.annotations[7])
+	require.NoError(t, env.service.DeleteEmailNote(ctx, env.user.ID, email.ID, synced.ID))
+	require.Empty(t, env.provider.imap.annotations[7])
+}
@@ -0,0 +1,297 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"firemail/internal/models"
+	"firemail/internal/pdf"
+)
+
+// maxInlineImageSize 嵌入PDF的单张内联图片大小上限
+const maxInlineImageSize = 10 << 20
+
+// ErrInvalidPaperSize 不支持的纸张尺寸
+var ErrInvalidPaperSize = errors.New("invalid paper size")
+
+// EmailPDFOptions 邮件导出PDF的选项
+type EmailPDFOptions struct {
+	Paper             string `form:"paper"`              // A4、Letter或Legal，默认A4
+	BundleAttachments bool   `form:"bundle_attachments"` // 与附件一起打包为zip下载
+}
+
+// EmailExport 导出的邮件文件，内容在WriteTo时生成，附件边读边写入压缩包
+type EmailExport struct {
+	Filename    string
+	ContentType string
+
+	pdf         []byte
+	pdfName     string
+	attachments []models.Attachment
+	open        func(attachmentID uint) (io.ReadCloser, error)
+}
+
+// WriteTo 输出PDF，或包含PDF与全部附件的zip
+func (e *EmailExport) WriteTo(w io.Writer) (int64, error) {
+	if e.attachments == nil {
+		n, err := w.Write(e.pdf)
+		return int64(n), err
+	}
+
+	counter := &countingWriter{w: w}
+	zw := zip.NewWriter(counter)
+	part, err := zw.Create(e.pdfName)
+	if err != nil {
+		return counter.n, err
+	}
+	if _, err := part.Write(e.pdf); err != nil {
+		return counter.n, err
+	}
+
+	used := map[string]int{e.pdfName: 1}
+	for _, attachment := range e.attachments {
+		name := uniqueArchiveName(used, "attachments/"+archiveFilename(attachment.Filename, fmt.Sprintf("attachment_%d", attachment.ID)))
+		if err := e.writeAttachment(zw, name, attachment.ID); err != nil {
+			return counter.n, fmt.Errorf("failed to bundle attachment %d: %w", attachment.ID, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return counter.n, err
+	}
+	return counter.n, nil
+}
+
+func (e *EmailExport) writeAttachment(zw *zip.Writer, name string, attachmentID uint) error {
+	content, err := e.open(attachmentID)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	part, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(part, content)
+	return err
+}
+
+// ExportEmailPDF 把邮件头、经过清理的正文、内联图片和附件列表渲染为PDF，可选连同附件打包
+func (s *EmailServiceImpl) ExportEmailPDF(ctx context.Context, userID, emailID uint, opts *EmailPDFOptions) (*EmailExport, error) {
+	if opts == nil {
+		opts = &EmailPDFOptions{}
+	}
+	size, ok := pdf.ParsePageSize(opts.Paper)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPaperSize, opts.Paper)
+	}
+	if opts.BundleAttachments && s.attachmentService == nil {
+		return nil, fmt.Errorf("attachment service not configured")
+	}
+
+	email, err := s.GetEmail(ctx, userID, emailID)
+	if err != nil {
+		return nil, err
+	}
+
+	open := func(attachmentID uint) (io.ReadCloser, error) {
+		return s.attachmentService.GetAttachmentContent(ctx, attachmentID, userID)
+	}
+	data, err := renderEmailPDF(email, size, s.inlineImageResolver(email, open))
+	if err != nil {
+		return nil, fmt.Errorf("failed to render pdf: %w", err)
+	}
+
+	base := archiveFilename(email.Subject, fmt.Sprintf("email_%d", email.ID))
+	export := &EmailExport{
+		Filename:    base + ".pdf",
+		ContentType: "application/pdf",
+		pdf:         data,
+	}
+	if opts.BundleAttachments {
+		export.Filename = base + ".zip"
+		export.ContentType = "application/zip"
+		export.pdfName = base + ".pdf"
+		export.attachments = append([]models.Attachment{}, email.Attachments...)
+		export.open = open
+	}
+	return export, nil
+}
+
+// inlineImageResolver 只解析cid:引用的内联附件和data:内嵌图片，远程图片不会被下载
+func (s *EmailServiceImpl) inlineImageResolver(email *models.Email, open func(uint) (io.ReadCloser, error)) pdf.ImageResolver {
+	return func(src string) ([]byte, bool) {
+		lower := strings.ToLower(src)
+		switch {
+		case strings.HasPrefix(lower, "data:"):
+			return decodeDataURI(src)
+		case strings.HasPrefix(lower, "cid:"):
+			if s.attachmentService == nil {
+				return nil, false
+			}
+			cid, err := url.PathUnescape(src[len("cid:"):])
+			if err != nil {
+				cid = src[len("cid:"):]
+			}
+			for _, attachment := range email.Attachments {
+				if !strings.EqualFold(strings.Trim(attachment.ContentID, "<>"), strings.Trim(cid, "<>")) {
+					continue
+				}
+				content, err := open(attachment.ID)
+				if err != nil {
+					return nil, false
+				}
+				defer content.Close()
+				data, err := io.ReadAll(io.LimitReader(content, maxInlineImageSize+1))
+				if err != nil || len(data) > maxInlineImageSize {
+					return nil, false
+				}
+				return data, true
+			}
+		}
+		return nil, false
+	}
+}
+
+// decodeDataURI 解码base64编码的data:image URI
+func decodeDataURI(src string) ([]byte, bool) {
+	header, payload, found := strings.Cut(src, ",")
+	if !found {
+		return nil, false
+	}
+	header = strings.ToLower(header)
+	if !strings.HasPrefix(header, "data:image/") || !strings.HasSuffix(header, ";base64") {
+		return nil, false
+	}
+	if base64.StdEncoding.DecodedLen(len(payload)) > maxInlineImageSize {
+		return nil, false
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(payload))
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// renderEmailPDF 按邮件头、正文、附件列表的顺序排版
+func renderEmailPDF(email *models.Email, size pdf.PageSize, resolve pdf.ImageResolver) ([]byte, error) {
+	w := pdf.NewWriter(size)
+	w.Document().SetTitle(email.Subject)
+
+	subject := email.Subject
+	if subject == "" {
+		subject = "(no subject)"
+	}
+	w.Text(subject, true, pdf.ParagraphStyle{Size: 16, SpaceAfter: 8})
+
+	header := func(label, value string) {
+		if strings.TrimSpace(value) == "" {
+			return
+		}
+		w.Paragraph([]pdf.Run{{Text: label + ": ", Bold: true}, {Text: value}}, pdf.ParagraphStyle{Size: 10, SpaceAfter: 2})
+	}
+	to, _ := email.GetToAddresses()
+	cc, _ := email.GetCCAddresses()
+	header("From", email.From)
+	header("To", joinEmailAddresses(to))
+	header("Cc", joinEmailAddresses(cc))
+	header("Reply-To", email.ReplyTo)
+	if !email.Date.IsZero() {
+		header("Date", email.Date.Format(time.RFC1123Z))
+	}
+	w.Rule()
+	w.Space(6)
+
+	body := pdf.ParagraphStyle{Size: 10, SpaceAfter: 4}
+	switch {
+	case strings.TrimSpace(email.HTMLBody) != "":
+		if err := w.HTML(email.HTMLBody, resolve, body); err != nil {
+			return nil, err
+		}
+	case email.TextBody != "":
+		body.Preformatted = true
+		w.Text(email.TextBody, false, body)
+	}
+
+	if len(email.Attachments) > 0 {
+		w.Space(6)
+		w.Rule()
+		w.Text(fmt.Sprintf("Attachments (%d)", len(email.Attachments)), true, pdf.ParagraphStyle{Size: 11, SpaceAfter: 4})
+		for _, attachment := range email.Attachments {
+			detail := FormatByteSize(attachment.Size)
+			if attachment.ContentType != "" {
+				detail = attachment.ContentType + ", " + detail
+			}
+			w.Paragraph([]pdf.Run{{Text: attachment.Filename}, {Text: " (" + detail + ")"}},
+				pdf.ParagraphStyle{Size: 9, Indent: 10, SpaceAfter: 2})
+		}
+	}
+
+	return w.Bytes()
+}
+
+// joinEmailAddresses 格式化地址列表
+func joinEmailAddresses(addresses []models.EmailAddress) string {
+	formatted := make([]string, 0, len(addresses))
+	for _, addr := range addresses {
+		if addr.Name != "" {
+			formatted = append(formatted, fmt.Sprintf("%s <%s>", addr.Name, addr.Address))
+		} else {
+			formatted = append(formatted, addr.Address)
+		}
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// FormatByteSize 以易读单位显示字节数
+func FormatByteSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// archiveFilename 把邮件主题或附件名转换为安全的文件名
+func archiveFilename(name, fallback string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r < 0x20, strings.ContainsRune(`/\:*?"<>|`, r):
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	name = strings.Trim(name, ". ")
+	if runes := []rune(name); len(runes) > 100 {
+		name = string(runes[:100])
+	}
+	if name == "" {
+		return fallback
+	}
+	return name
+}
+
+// uniqueArchiveName 压缩包内同名文件追加序号
+func uniqueArchiveName(used map[string]int, name string) string {
+	used[name]++
+	if used[name] == 1 {
+		return name
+	}
+	ext := path.Ext(name)
+	candidate := fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(name, ext), used[name]-1, ext)
+	return uniqueArchiveName(used, candidate)
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"testing"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+// memoryAttachments 按附件ID返回内存中的内容
+type memoryAttachments struct {
+	AttachmentDownloader
+	content map[uint][]byte
+}
+
+func (m *memoryAttachments) GetAttachmentContent(ctx context.Context, attachmentID uint, userID uint) (io.ReadCloser, error) {
+	data, ok := m.content[attachmentID]
+	if !ok {
+		return nil, errors.New("attachment not found")
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func TestExportEmailPDFWithBundledAttachments(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+
+	email := env.createEmail(t, env.inbox, 7001, "Contract/2024", false, false)
+	require.NoError(t, models.SaveEmailBody(env.db, email.ID, "hello", `<p>See <img src="cid:logo@example"> and attached.</p>`))
+
+	var logo bytes.Buffer
+	require.NoError(t, png.Encode(&logo, image.NewGray(image.Rect(0, 0, 2, 2))))
+	inline := models.Attachment{EmailID: &email.ID, Filename: "logo.png", ContentType: "image/png", ContentID: "<logo@example>", Disposition: "inline"}
+	first := models.Attachment{EmailID: &email.ID, Filename: "terms.txt", ContentType: "text/plain", Size: 5}
+	second := models.Attachment{EmailID: &email.ID, Filename: "terms.txt", ContentType: "text/plain", Size: 6}
+	for _, attachment := range []*models.Attachment{&inline, &first, &second} {
+		require.NoError(t, env.db.Create(attachment).Error)
+	}
+	env.service.SetAttachmentService(&memoryAttachments{content: map[uint][]byte{
+		inline.ID: logo.Bytes(),
+		first.ID:  []byte("first"),
+		second.ID: []byte("second"),
+	}})
+
+	export, err := env.service.ExportEmailPDF(ctx, env.user.ID, email.ID, &EmailPDFOptions{Paper: "letter"})
+	require.NoError(t, err)
+	require.Equal(t, "Contract_2024.pdf", export.Filename)
+	require.Equal(t, "application/pdf", export.ContentType)
+
+	var buf bytes.Buffer
+	_, err = export.WriteTo(&buf)
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+	require.Contains(t, buf.String(), "/MediaBox [0 0 612 792]")
+	require.Contains(t, buf.String(), "/Subtype /Image /Width 2 /Height 2")
+
+	export, err = env.service.ExportEmailPDF(ctx, env.user.ID, email.ID, &EmailPDFOptions{BundleAttachments: true})
+	require.NoError(t, err)
+	require.Equal(t, "Contract_2024.zip", export.Filename)
+
+	buf.Reset()
+	_, err = export.WriteTo(&buf)
+	require.NoError(t, err)
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	contents := make(map[string]string)
+	for _, file := range archive.File {
+		rc, err := file.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		contents[file.Name] = string(data)
+	}
+	require.Len(t, contents, 4)
+	require.Contains(t, contents, "Contract_2024.pdf")
+	require.Contains(t, contents, "attachments/logo.png")
+	require.Equal(t, "first", contents["attachments/terms.txt"])
+	require.Equal(t, "second", contents["attachments/terms (1).txt"])
+}
+
+func TestExportEmailPDFRejectsUnknownPaper(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	email := env.createEmail(t, env.inbox, 7101, "paper", false, false)
+
+	_, err := env.service.ExportEmailPDF(context.Background(), env.user.ID, email.ID, &EmailPDFOptions{Paper: "A3"})
+	require.ErrorIs(t, err, ErrInvalidPaperSize)
+}
+
+func TestDecodeDataURI(t *testing.T) {
+	data, ok := decodeDataURI("data:image/png;base64,aGVsbG8=")
+	require.True(t, ok)
+	require.Equal(t, "hello", string(data))
+
+	_, ok = decodeDataURI("data:text/html;base64,aGVsbG8=")
+	require.False(t, ok)
+}
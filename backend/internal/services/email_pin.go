@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"firemail/internal/models"
+)
+
+// MaxPinnedEmailsPerFolder 单个文件夹内允许置顶的邮件数量上限
+const MaxPinnedEmailsPerFolder = 20
+
+// ErrTooManyPinnedEmails 文件夹内置顶邮件已达上限
+var ErrTooManyPinnedEmails = errors.New("too many pinned emails")
+
+// ToggleEmailPin 切换邮件置顶状态，返回更新后的邮件
+func (s *EmailServiceImpl) ToggleEmailPin(ctx context.Context, userID, emailID uint) (*models.Email, error) {
+	email, err := s.getEmailForUser(ctx, userID, emailID, false, "Folder")
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkEmailVersion(ctx, email); err != nil {
+		return nil, err
+	}
+
+	var pinnedAt *time.Time
+	if !email.IsPinned {
+		var pinned int64
+		if err := s.db.WithContext(ctx).Model(&models.Email{}).
+			Where("folder_id = ? AND is_pinned = ? AND is_deleted = ?", email.FolderID, true, false).
+			Count(&pinned).Error; err != nil {
+			return nil, fmt.Errorf("failed to count pinned emails: %w", err)
+		}
+		if pinned >= MaxPinnedEmailsPerFolder {
+			folderName := ""
+			if email.Folder != nil {
+				folderName = email.Folder.DisplayName
+			}
+			return nil, fmt.Errorf("%w: folder %q already has %d pinned emails (limit %d), unpin one first",
+				ErrTooManyPinnedEmails, folderName, pinned, MaxPinnedEmailsPerFolder)
+		}
+		now := time.Now()
+		pinnedAt = &now
+	}
+
+	if err := s.updateEmailColumns(ctx, s.db, email, map[string]interface{}{
+		"is_pinned": pinnedAt != nil,
+		"pinned_at": pinnedAt,
+	}); err != nil {
+		if errors.Is(err, ErrVersionConflict) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update email pin status: %w", err)
+	}
+	email.IsPinned = pinnedAt != nil
+	email.PinnedAt = pinnedAt
+
+	s.invalidateEmailListCache(userID, email.AccountID, email.FolderID)
+	recordEmailChanges(ctx, s.changeLog, userID, email.AccountID, models.ChangeActionUpdated, email.ID)
+	pinEvent := models.EmailEventPinned
+	if !email.IsPinned {
+		pinEvent = models.EmailEventUnpinned
+	}
+	recordEmailEvents(ctx, s.db, newEmailEvent(userID, email.AccountID, email.ID, pinEvent, models.EmailEventSourceUser))
+
+	return email, nil
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToggleEmailPinAndPinnedFirstOrdering(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.EmailEvent{}))
+	ctx := context.Background()
+
+	older := env.createEmail(t, env.inbox, 1, "older", false, false)
+	newer := env.createEmail(t, env.inbox, 2, "newer", false, false)
+	require.NoError(t, env.db.Model(older).Update("date", newer.Date.Add(-time.Hour)).Error)
+
+	pinned, err := env.service.ToggleEmailPin(ctx, env.user.ID, older.ID)
+	require.NoError(t, err)
+	require.True(t, pinned.IsPinned)
+	require.NotNil(t, pinned.PinnedAt)
+	require.False(t, pinned.IsStarred)
+
+	list, err := env.service.GetEmails(ctx, env.user.ID, &GetEmailsRequest{FolderID: &env.inbox.ID, PinnedFirst: true})
+	require.NoError(t, err)
+	require.Len(t, list.Emails, 2)
+	require.Equal(t, older.ID, list.Emails[0].ID)
+	require.Empty(t, list.NextCursor)
+
+	list, err = env.service.GetEmails(ctx, env.user.ID, &GetEmailsRequest{FolderID: &env.inbox.ID})
+	require.NoError(t, err)
+	require.Equal(t, newer.ID, list.Emails[0].ID)
+
+	_, err = env.service.GetEmails(ctx, env.user.ID, &GetEmailsRequest{PinnedFirst: true, Cursor: "abc"})
+	require.True(t, errors.Is(err, ErrInvalidEmailCursor))
+
+	unpinned, err := env.service.ToggleEmailPin(ctx, env.user.ID, older.ID)
+	require.NoError(t, err)
+	require.False(t, unpinned.IsPinned)
+	require.Nil(t, unpinned.PinnedAt)
+
+	var events []models.EmailEvent
+	require.NoError(t, env.db.Where("email_id = ?", older.ID).Order("id").Find(&events).Error)
+	require.Len(t, events, 2)
+	require.Equal(t, models.EmailEventPinned, events[0].Type)
+	require.Equal(t, models.EmailEventUnpinned, events[1].Type)
+
+	_, err = env.service.ToggleEmailPin(ctx, env.user.ID+1, older.ID)
+	require.EqualError(t, err, "email not found")
+}
+
+func TestToggleEmailPinEnforcesFolderLimit(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+
+	for uid := uint32(1); uid <= MaxPinnedEmailsPerFolder; uid++ {
+		email := env.createEmail(t, env.inbox, uid, "pinned", false, false)
+		require.NoError(t, env.db.Model(email).Update("is_pinned", true).Error)
+	}
+	// 回收站中的置顶邮件不计入上限
+	trashed := env.createEmail(t, env.inbox, 100, "trashed", false, true)
+	require.NoError(t, env.db.Model(trashed).Update("is_pinned", true).Error)
+
+	extra := env.createEmail(t, env.inbox, 101, "extra", false, false)
+	_, err := env.service.ToggleEmailPin(ctx, env.user.ID, extra.ID)
+	require.True(t, errors.Is(err, ErrTooManyPinnedEmails))
+	require.Contains(t, err.Error(), `folder "收件箱" already has 20 pinned emails (limit 20)`)
+
+	// 其他文件夹不受影响
+	other := env.createEmail(t, env.work, 1, "other", false, false)
+	result, err := env.service.ToggleEmailPin(ctx, env.user.ID, other.ID)
+	require.NoError(t, err)
+	require.True(t, result.IsPinned)
+}
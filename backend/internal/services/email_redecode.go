@@ -0,0 +1,117 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"unicode/utf8"
+
+	"firemail/internal/encoding"
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// RedecodeEmail 从服务器重新获取邮件原文，并使用指定的字符集重新解码正文和主题，用于修复已同步的乱码邮件
+func (s *EmailServiceImpl) RedecodeEmail(ctx context.Context, userID, emailID uint, charset string) (*models.Email, error) {
+	if !encoding.IsSupportedCharset(charset) {
+		return nil, fmt.Errorf("unsupported charset: %s", charset)
+	}
+
+	email, err := s.getEmailForUser(ctx, userID, emailID, false, "Account", "Folder")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkEmailSource(email); err != nil {
+		return nil, err
+	}
+	if err := s.checkEmailVersion(ctx, email); err != nil {
+		return nil, err
+	}
+
+	session, err := s.openEmailSourceSession(ctx, &email.Account)
+	if err != nil {
+		return nil, err
+	}
+	defer session.close()
+
+	options := reparseOptions()
+	options.Charset = charset
+	parsed, err := session.parse(ctx, email, options)
+	if err != nil {
+		return nil, err
+	}
+	defer parsed.Cleanup()
+
+	// 正文、预览和主题在同一事务中更新，并递增版本号
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := saveEmailBody(tx, email.ID, parsed.TextBody, parsed.HTMLBody); err != nil {
+			return err
+		}
+		columns := map[string]interface{}{
+			"preview": models.BuildEmailPreview(parsed.TextBody, parsed.HTMLBody),
+		}
+		if subject := decodeHeaderWithCharset(parsed.Headers.Get("Subject"), charset); subject != "" {
+			columns["subject"] = subject
+		}
+		if err := s.updateEmailColumns(ctx, tx, email, columns); err != nil {
+			if errors.Is(err, ErrVersionConflict) {
+				return err
+			}
+			return fmt.Errorf("failed to update email: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateEmailListCache(userID, email.AccountID, email.FolderID)
+	recordEmailChanges(ctx, s.changeLog, userID, email.AccountID, models.ChangeActionUpdated, email.ID)
+
+	return s.getEmailForUser(ctx, userID, emailID, false, "Account", "Folder", "Attachments", "Body")
+}
+
+// decodeHeaderWithCharset 解码邮件头：RFC 2047编码字按其声明的字符集解码，未编码的8位内容按指定字符集解码
+func decodeHeaderWithCharset(value, charset string) string {
+	if value == "" {
+		return ""
+	}
+
+	if hasNonASCII(value) {
+		if decoded, err := encoding.DecodeCharset([]byte(value), charset); err == nil {
+			return string(decoded)
+		}
+	}
+
+	if decoded, err := newCharsetWordDecoder().DecodeHeader(value); err == nil {
+		return decoded
+	}
+	return value
+}
+
+// newCharsetWordDecoder 创建支持非UTF-8字符集的编码词解码器
+func newCharsetWordDecoder() *mime.WordDecoder {
+	return &mime.WordDecoder{
+		CharsetReader: func(label string, input io.Reader) (io.Reader, error) {
+			data, err := io.ReadAll(input)
+			if err != nil {
+				return nil, err
+			}
+			decoded, _ := encoding.DecodeWithDetection(data, label)
+			return bytes.NewReader(decoded), nil
+		},
+	}
+}
+
+func hasNonASCII(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if value[i] >= utf8.RuneSelf {
+			return true
+		}
+	}
+	return false
+}
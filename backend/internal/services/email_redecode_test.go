@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func TestRedecodeEmailUsesChosenCharset(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+
+	email := env.createEmail(t, env.inbox, 3001, "garbled", true, false)
+
+	subject, err := simplifiedchinese.GBK.NewEncoder().String("周报")
+	require.NoError(t, err)
+	body, err := simplifiedchinese.GBK.NewEncoder().String("本周工作总结")
+	require.NoError(t, err)
+	env.provider.imap.rawMessages = map[uint32][]byte{
+		3001: []byte("From: sender@qq.com\r\nSubject: " + subject + "\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n" + body),
+	}
+
+	updated, err := env.service.RedecodeEmail(ctx, env.user.ID, email.ID, "gb18030")
+	require.NoError(t, err)
+	require.Equal(t, "本周工作总结", updated.TextBody)
+	require.Equal(t, "周报", updated.Subject)
+	require.Equal(t, []string{"INBOX"}, env.provider.imap.selectedFolders)
+
+	// 预览与正文一起更新，版本号递增
+	var stored models.Email
+	require.NoError(t, env.db.Preload("Body").First(&stored, email.ID).Error)
+	require.Equal(t, "本周工作总结", stored.TextBody)
+	require.NotNil(t, stored.Preview)
+	require.Equal(t, "本周工作总结", *stored.Preview)
+	require.Equal(t, email.Version+1, stored.Version)
+	require.Equal(t, stored.Version, updated.Version)
+
+	// 附带过期版本号时不连接服务器
+	env.provider.imap.selectedFolders = nil
+	_, err = env.service.RedecodeEmail(WithExpectedVersion(ctx, email.Version), env.user.ID, email.ID, "gb18030")
+	require.ErrorIs(t, err, ErrVersionConflict)
+	require.Empty(t, env.provider.imap.selectedFolders)
+}
+
+func TestRedecodeEmailRejectsUnsupportedCharset(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+
+	email := env.createEmail(t, env.inbox, 3002, "garbled", true, false)
+
+	_, err := env.service.RedecodeEmail(context.Background(), env.user.ID, email.ID, "x-unknown")
+	require.Error(t, err)
+	require.Empty(t, env.provider.imap.selectedFolders)
+}
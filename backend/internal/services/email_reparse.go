@@ -0,0 +1,455 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"firemail/internal/models"
+	"firemail/internal/parser"
+	"firemail/internal/providers"
+
+	"gorm.io/gorm"
+)
+
+// 批量重新解析任务状态
+const (
+	ReparseJobStatusRunning   = "running"
+	ReparseJobStatusCompleted = "completed"
+	ReparseJobStatusCancelled = "cancelled"
+)
+
+// 批量重新解析每批加载的邮件数量
+const reparseBatchSize = 50
+
+// StartReparseJobRequest 批量重新解析请求，未指定过滤条件时处理所有邮件
+type StartReparseJobRequest struct {
+	AccountID *uint      `json:"account_id,omitempty"`
+	FolderID  *uint      `json:"folder_id,omitempty"`
+	Since     *time.Time `json:"since,omitempty"` // 只处理该时间之后的邮件
+}
+
+// ReparseJob 批量重新解析任务
+type ReparseJob struct {
+	ID         string     `json:"id"`
+	Status     string     `json:"status"`
+	Total      int        `json:"total"`
+	Processed  int        `json:"processed"`
+	Succeeded  int        `json:"succeeded"`
+	Failed     int        `json:"failed"`
+	LastError  string     `json:"last_error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+
+	cancel context.CancelFunc
+}
+
+// reparseJobRegistry 保存运行中和已结束的重新解析任务
+type reparseJobRegistry struct {
+	mutex sync.RWMutex
+	jobs  map[string]*ReparseJob
+}
+
+func newReparseJobRegistry() *reparseJobRegistry {
+	return &reparseJobRegistry{jobs: make(map[string]*ReparseJob)}
+}
+
+// snapshot 返回任务的副本，避免调用方读取到并发修改中的数据
+func (r *reparseJobRegistry) snapshot(jobID string) (*ReparseJob, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	job, ok := r.jobs[jobID]
+	if !ok {
+		return nil, false
+	}
+	copied := *job
+	copied.cancel = nil
+	return &copied, true
+}
+
+func (r *reparseJobRegistry) update(jobID string, fn func(job *ReparseJob)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if job, ok := r.jobs[jobID]; ok {
+		fn(job)
+	}
+}
+
+// emailSourceSession 复用同一个IMAP连接获取多封邮件的原文
+type emailSourceSession struct {
+	provider  providers.EmailProvider
+	client    providers.IMAPClient
+	accountID uint
+	folder    string
+}
+
+// openEmailSourceSession 连接账户的IMAP服务器
+func (s *EmailServiceImpl) openEmailSourceSession(ctx context.Context, account *models.EmailAccount) (*emailSourceSession, error) {
+	provider, err := s.providerFactory.CreateProviderForAccount(account)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create provider: %w", err)
+	}
+
+	s.setupProviderTokenCallback(provider)
+
+	if err := provider.Connect(ctx, account); err != nil {
+		return nil, fmt.Errorf("failed to connect to email server: %w", err)
+	}
+
+	imapClient := provider.IMAPClient()
+	if imapClient == nil {
+		provider.Disconnect()
+		return nil, fmt.Errorf("IMAP client not available")
+	}
+
+	return &emailSourceSession{provider: provider, client: imapClient, accountID: account.ID}, nil
+}
+
+func (sess *emailSourceSession) close() {
+	sess.provider.Disconnect()
+}
+
+// selectFolder 切换到指定文件夹，已选中时不重复发送SELECT
+func (sess *emailSourceSession) selectFolder(ctx context.Context, folderPath string) error {
+	if sess.folder == folderPath {
+		return nil
+	}
+	if _, err := sess.client.SelectFolder(ctx, folderPath); err != nil {
+		return fmt.Errorf("failed to select folder %s: %w", folderPath, err)
+	}
+	sess.folder = folderPath
+	return nil
+}
+
+// parse 按UID重新获取邮件原文并使用统一解析器解析，调用方负责Cleanup
+func (sess *emailSourceSession) parse(ctx context.Context, email *models.Email, options *parser.ParseOptions) (*parser.ParsedEmail, error) {
+	if err := sess.selectFolder(ctx, email.Folder.GetFullPath()); err != nil {
+		return nil, err
+	}
+
+	raw, err := sess.client.FetchRawEmail(ctx, email.UID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch email source: %w", err)
+	}
+	defer raw.Close()
+
+	parsed, err := parser.NewUnifiedParser(options).ParseEmailFromReader(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse email source: %w", err)
+	}
+	return parsed, nil
+}
+
+// checkEmailSource 检查邮件是否能从服务器重新获取
+func checkEmailSource(email *models.Email) error {
+	if email.UID == 0 {
+		return fmt.Errorf("email source unavailable: missing UID")
+	}
+	if email.Folder == nil || email.Folder.GetFullPath() == "" {
+		return fmt.Errorf("email source unavailable: missing folder path")
+	}
+	return nil
+}
+
+// reparseOptions 重新解析只需要附件元数据，附件内容由附件服务按需下载
+func reparseOptions() *parser.ParseOptions {
+	options := parser.DefaultParseOptions()
+	options.IncludeAttachmentContent = false
+	return options
+}
+
+// ReparseEmail 从服务器重新获取邮件原文并使用当前解析器重新解析，更新正文和附件信息
+func (s *EmailServiceImpl) ReparseEmail(ctx context.Context, userID, emailID uint) (*models.Email, error) {
+	email, err := s.getEmailForUser(ctx, userID, emailID, false, "Account", "Folder")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkEmailSource(email); err != nil {
+		return nil, err
+	}
+
+	session, err := s.openEmailSourceSession(ctx, &email.Account)
+	if err != nil {
+		return nil, err
+	}
+	defer session.close()
+
+	parsed, err := session.parse(ctx, email, reparseOptions())
+	if err != nil {
+		return nil, err
+	}
+	defer parsed.Cleanup()
+
+	if err := s.applyReparsedEmail(ctx, email, parsed); err != nil {
+		return nil, err
+	}
+
+	return s.getEmailForUser(ctx, userID, emailID, false, "Account", "Folder", "Attachments", "Body")
+}
+
+// applyReparsedEmail 用解析结果更新邮件正文，并按PartID同步附件记录：
+// 已有附件更新元数据（保留已下载的内容），新增的附件创建记录，不再存在的附件删除
+func (s *EmailServiceImpl) applyReparsedEmail(ctx context.Context, email *models.Email, parsed *parser.ParsedEmail) error {
+	parsedAttachments := append(append([]*parser.AttachmentInfo{}, parsed.Attachments...), parsed.InlineAttachments...)
+
+	var removedFiles []string
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := saveEmailBody(tx, email.ID, parsed.TextBody, parsed.HTMLBody); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Email{}).Where("id = ?", email.ID).Updates(map[string]interface{}{
+			"preview":          models.BuildEmailPreview(parsed.TextBody, parsed.HTMLBody),
+			"has_attachment":   len(parsedAttachments) > 0,
+			"attachment_count": len(parsedAttachments),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update email: %w", err)
+		}
+
+		var existing []models.Attachment
+		if err := tx.Where("email_id = ?", email.ID).Find(&existing).Error; err != nil {
+			return fmt.Errorf("failed to load attachments: %w", err)
+		}
+		existingByPart := make(map[string]*models.Attachment, len(existing))
+		for i := range existing {
+			existingByPart[existing[i].PartID] = &existing[i]
+		}
+
+		for _, info := range parsedAttachments {
+			disposition := info.Disposition
+			if disposition == "" {
+				disposition = "attachment"
+			}
+
+			if attachment, ok := existingByPart[info.PartID]; ok {
+				delete(existingByPart, info.PartID)
+				checked := models.Attachment{Filename: info.Filename, ContentType: info.ContentType}
+				applyAttachmentPolicy(&checked, readAttachmentHead(info.Open))
+				updates := map[string]interface{}{
+					"filename":     info.Filename,
+					"content_type": checked.ContentType,
+					"is_blocked":   checked.IsBlocked,
+					"block_reason": checked.BlockReason,
+					"content_id":   info.ContentID,
+					"disposition":  disposition,
+					"is_inline":    disposition == "inline",
+					"encoding":     info.Encoding,
+				}
+				// 已下载的附件以本地文件大小为准
+				if !attachment.IsDownloaded {
+					updates["size"] = info.Size
+				}
+				if err := tx.Model(attachment).Updates(updates).Error; err != nil {
+					return fmt.Errorf("failed to update attachment %s: %w", info.Filename, err)
+				}
+				continue
+			}
+
+			attachment := &models.Attachment{
+				EmailID:     &email.ID,
+				Filename:    info.Filename,
+				ContentType: info.ContentType,
+				Size:        info.Size,
+				ContentID:   info.ContentID,
+				Disposition: disposition,
+				IsInline:    disposition == "inline",
+				PartID:      info.PartID,
+				Encoding:    info.Encoding,
+			}
+			applyAttachmentPolicy(attachment, readAttachmentHead(info.Open))
+			if err := tx.Create(attachment).Error; err != nil {
+				return fmt.Errorf("failed to create attachment %s: %w", info.Filename, err)
+			}
+		}
+
+		for _, attachment := range existingByPart {
+			if err := tx.Delete(attachment).Error; err != nil {
+				return fmt.Errorf("failed to delete attachment %s: %w", attachment.Filename, err)
+			}
+			if attachment.IsDownloaded && attachment.StoragePath != "" {
+				removedFiles = append(removedFiles, attachment.StoragePath)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.invalidateEmailListCache(email.Account.UserID, email.AccountID, email.FolderID)
+	recordEmailChanges(ctx, s.changeLog, email.Account.UserID, email.AccountID, models.ChangeActionUpdated, email.ID)
+
+	// 事务提交后再删除本地文件，避免回滚后记录指向已删除的文件
+	for _, path := range removedFiles {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to remove attachment file %s: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// StartReparseJob 启动批量重新解析任务，在后台按账户复用连接逐封处理
+func (s *EmailServiceImpl) StartReparseJob(ctx context.Context, req *StartReparseJobRequest) (*ReparseJob, error) {
+	query := s.db.WithContext(ctx).Model(&models.Email{}).
+		Where("is_deleted = ? AND uid > 0 AND folder_id IS NOT NULL", false)
+	if req.AccountID != nil {
+		query = query.Where("account_id = ?", *req.AccountID)
+	}
+	if req.FolderID != nil {
+		query = query.Where("folder_id = ?", *req.FolderID)
+	}
+	if req.Since != nil {
+		query = query.Where("date >= ?", *req.Since)
+	}
+
+	var emailIDs []uint
+	if err := query.Order("account_id, folder_id, uid").Pluck("id", &emailIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to query emails: %w", err)
+	}
+
+	jobCtx, cancel := context.WithCancel(context.Background())
+	job := &ReparseJob{
+		ID:        newReparseJobID(),
+		Status:    ReparseJobStatusRunning,
+		Total:     len(emailIDs),
+		StartedAt: time.Now(),
+		cancel:    cancel,
+	}
+
+	s.reparseJobs.mutex.Lock()
+	s.reparseJobs.jobs[job.ID] = job
+	s.reparseJobs.mutex.Unlock()
+
+	go s.runReparseJob(jobCtx, job.ID, emailIDs)
+
+	snapshot, _ := s.reparseJobs.snapshot(job.ID)
+	return snapshot, nil
+}
+
+// GetReparseJob 获取批量重新解析任务状态
+func (s *EmailServiceImpl) GetReparseJob(ctx context.Context, jobID string) (*ReparseJob, error) {
+	job, ok := s.reparseJobs.snapshot(jobID)
+	if !ok {
+		return nil, fmt.Errorf("reparse job not found")
+	}
+	return job, nil
+}
+
+// CancelReparseJob 取消批量重新解析任务，已处理的邮件不会回滚
+func (s *EmailServiceImpl) CancelReparseJob(ctx context.Context, jobID string) (*ReparseJob, error) {
+	s.reparseJobs.mutex.RLock()
+	job, ok := s.reparseJobs.jobs[jobID]
+	s.reparseJobs.mutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("reparse job not found")
+	}
+
+	job.cancel()
+	return s.GetReparseJob(ctx, jobID)
+}
+
+func (s *EmailServiceImpl) runReparseJob(ctx context.Context, jobID string, emailIDs []uint) {
+	var session *emailSourceSession
+	defer func() {
+		if session != nil {
+			session.close()
+		}
+	}()
+
+	for start := 0; start < len(emailIDs) && ctx.Err() == nil; start += reparseBatchSize {
+		end := start + reparseBatchSize
+		if end > len(emailIDs) {
+			end = len(emailIDs)
+		}
+
+		var emails []models.Email
+		if err := s.db.WithContext(ctx).Preload("Account").Preload("Folder").
+			Where("id IN ?", emailIDs[start:end]).
+			Order("account_id, folder_id, uid").
+			Find(&emails).Error; err != nil {
+			s.recordReparseResult(jobID, end-start, fmt.Errorf("failed to load emails: %w", err))
+			continue
+		}
+
+		for i := range emails {
+			if ctx.Err() != nil {
+				break
+			}
+			email := &emails[i]
+
+			if session == nil || session.accountID != email.AccountID {
+				if session != nil {
+					session.close()
+					session = nil
+				}
+				opened, err := s.openEmailSourceSession(ctx, &email.Account)
+				if err != nil {
+					s.recordReparseResult(jobID, 1, fmt.Errorf("email %d: %w", email.ID, err))
+					continue
+				}
+				session = opened
+			}
+
+			err := s.reparseWithSession(ctx, session, email)
+			if err != nil {
+				err = fmt.Errorf("email %d: %w", email.ID, err)
+			}
+			s.recordReparseResult(jobID, 1, err)
+		}
+	}
+
+	s.reparseJobs.update(jobID, func(job *ReparseJob) {
+		now := time.Now()
+		job.FinishedAt = &now
+		job.Status = ReparseJobStatusCompleted
+		if ctx.Err() != nil {
+			job.Status = ReparseJobStatusCancelled
+		}
+	})
+
+	if job, ok := s.reparseJobs.snapshot(jobID); ok {
+		log.Printf("Reparse job %s %s: %d/%d processed, %d failed", job.ID, job.Status, job.Processed, job.Total, job.Failed)
+	}
+}
+
+func (s *EmailServiceImpl) reparseWithSession(ctx context.Context, session *emailSourceSession, email *models.Email) error {
+	if err := checkEmailSource(email); err != nil {
+		return err
+	}
+
+	parsed, err := session.parse(ctx, email, reparseOptions())
+	if err != nil {
+		return err
+	}
+	defer parsed.Cleanup()
+
+	return s.applyReparsedEmail(ctx, email, parsed)
+}
+
+func (s *EmailServiceImpl) recordReparseResult(jobID string, count int, err error) {
+	s.reparseJobs.update(jobID, func(job *ReparseJob) {
+		job.Processed += count
+		if err != nil {
+			job.Failed += count
+			job.LastError = err.Error()
+			return
+		}
+		job.Succeeded += count
+	})
+	if err != nil {
+		log.Printf("Reparse job %s: %v", jobID, err)
+	}
+}
+
+func newReparseJobID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("reparse_%d", time.Now().UnixNano())
+	}
+	return "reparse_" + hex.EncodeToString(buf)
+}
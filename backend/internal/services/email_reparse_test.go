@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+const reparseTestMessage = "From: sender@example.com\r\n" +
+	"Subject: report\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=\"b1\"\r\n" +
+	"\r\n" +
+	"--b1\r\n" +
+	"Content-Type: text/plain; charset=utf-8\r\n" +
+	"\r\n" +
+	"reparsed body\r\n" +
+	"--b1\r\n" +
+	"Content-Type: application/pdf; name=\"report.pdf\"\r\n" +
+	"Content-Disposition: attachment; filename=\"report.pdf\"\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"JVBERi0xLjQK\r\n" +
+	"--b1--\r\n"
+
+func TestReparseEmailUpdatesBodyAndAttachments(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+
+	email := env.createEmail(t, env.inbox, 4001, "report", true, false)
+	stale := &models.Attachment{EmailID: &email.ID, Filename: "stale.bin", Size: 1, PartID: "9"}
+	require.NoError(t, env.db.Create(stale).Error)
+
+	env.provider.imap.rawMessages = map[uint32][]byte{4001: []byte(reparseTestMessage)}
+
+	updated, err := env.service.ReparseEmail(ctx, env.user.ID, email.ID)
+	require.NoError(t, err)
+	require.Contains(t, updated.TextBody, "reparsed body")
+	require.True(t, updated.HasAttachment)
+	require.Len(t, updated.Attachments, 1)
+	require.Equal(t, "report.pdf", updated.Attachments[0].Filename)
+	require.Equal(t, "application/pdf", updated.Attachments[0].ContentType)
+
+	var count int64
+	require.NoError(t, env.db.Model(&models.Attachment{}).Where("id = ?", stale.ID).Count(&count).Error)
+	require.Zero(t, count)
+
+	// 再次解析时复用已有附件记录
+	again, err := env.service.ReparseEmail(ctx, env.user.ID, email.ID)
+	require.NoError(t, err)
+	require.Len(t, again.Attachments, 1)
+	require.Equal(t, updated.Attachments[0].ID, again.Attachments[0].ID)
+}
+
+func TestReparseJobProcessesMatchingEmails(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+
+	first := env.createEmail(t, env.inbox, 5001, "first", true, false)
+	missing := env.createEmail(t, env.inbox, 5002, "missing", true, false)
+	other := env.createEmail(t, env.work, 5003, "other", true, false)
+
+	env.provider.imap.rawMessages = map[uint32][]byte{
+		5001: []byte(reparseTestMessage),
+		5003: []byte(reparseTestMessage),
+	}
+
+	job, err := env.service.StartReparseJob(ctx, &StartReparseJobRequest{FolderID: &env.inbox.ID})
+	require.NoError(t, err)
+	require.Equal(t, 2, job.Total)
+
+	require.Eventually(t, func() bool {
+		current, err := env.service.GetReparseJob(ctx, job.ID)
+		return err == nil && current.Status != ReparseJobStatusRunning
+	}, 5*time.Second, 10*time.Millisecond)
+
+	current, err := env.service.GetReparseJob(ctx, job.ID)
+	require.NoError(t, err)
+	require.Equal(t, ReparseJobStatusCompleted, current.Status)
+	require.Equal(t, 2, current.Processed)
+	require.Equal(t, 1, current.Succeeded)
+	require.Equal(t, 1, current.Failed)
+	require.NotEmpty(t, current.LastError)
+
+	bodies := make(map[uint]string)
+	for _, id := range []uint{first.ID, missing.ID, other.ID} {
+		var stored models.Email
+		require.NoError(t, env.db.Preload("Body").First(&stored, id).Error)
+		bodies[id] = stored.TextBody
+	}
+	require.Contains(t, bodies[first.ID], "reparsed body")
+	require.Equal(t, "hello", bodies[missing.ID])
+	require.Equal(t, "hello", bodies[other.ID])
+
+	_, err = env.service.GetReparseJob(ctx, "unknown")
+	require.Error(t, err)
+}
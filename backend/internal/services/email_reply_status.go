@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 表示已回复和已转发的IMAP标志
+const (
+	imapAnswered     = "\\Answered"
+	imapForwardedKey = "$Forwarded"
+)
+
+// awaitingReplyCondition 等待我回复的邮件：收到的邮件尚未回复，且会话中之后没有发件箱里的邮件。
+// 发件箱、草稿箱、垃圾邮件和回收站中的邮件不计入
+const awaitingReplyCondition = "emails.is_answered = ? AND emails.is_sent = ? AND emails.is_draft = ? AND " +
+	"NOT EXISTS (SELECT 1 FROM folders WHERE folders.id = emails.folder_id AND folders.type IN ?) AND " +
+	"NOT EXISTS (SELECT 1 FROM emails AS replies JOIN folders AS reply_folders ON reply_folders.id = replies.folder_id " +
+	"WHERE replies.user_id = emails.user_id AND replies.thread_id = emails.thread_id AND replies.thread_id <> '' " +
+	"AND replies.date > emails.date AND replies.is_deleted = ? AND reply_folders.type = ?)"
+
+// awaitingReplyArgs awaitingReplyCondition 的参数
+func awaitingReplyArgs() []interface{} {
+	excluded := []string{models.FolderTypeSent, models.FolderTypeDrafts, models.FolderTypeSpam, models.FolderTypeTrash}
+	return []interface{}{false, false, false, excluded, false, models.FolderTypeSent}
+}
+
+// applyReplyFlags 根据同步得到的IMAP标志设置已回复和已转发状态。
+// 本地回复或转发后设置的状态不会因服务器未保存关键字而被清除
+func applyReplyFlags(email *models.Email, flags []string) {
+	for _, flag := range flags {
+		switch {
+		case strings.EqualFold(flag, imapAnswered):
+			email.IsAnswered = true
+		case strings.EqualFold(flag, imapForwardedKey):
+			email.IsForwarded = true
+		}
+	}
+}
+
+// markEmailResponded 通过本应用回复或转发成功后标记原邮件，并把 \Answered 或 $Forwarded 同步到服务器
+func (s *EmailServiceImpl) markEmailResponded(ctx context.Context, userID, emailID uint, forwarded bool) {
+	email, err := s.getEmailForUser(ctx, userID, emailID, false, "Account", "Folder")
+	if err != nil {
+		return
+	}
+
+	column, flag := "is_answered", imapAnswered
+	done := email.IsAnswered
+	if forwarded {
+		column, flag = "is_forwarded", imapForwardedKey
+		done = email.IsForwarded
+	}
+	if done {
+		return
+	}
+
+	if err := s.updateEmailColumns(ctx, s.db, email, map[string]interface{}{column: true}); err != nil {
+		log.Printf("Warning: failed to mark email %d as %s: %v", email.ID, column, err)
+		return
+	}
+	s.invalidateEmailListCache(userID, email.AccountID, email.FolderID)
+	recordEmailChanges(ctx, s.changeLog, userID, email.AccountID, models.ChangeActionUpdated, email.ID)
+
+	s.storeEmailFlags(ctx, email, []string{flag}, nil)
+}
+
+// markAnsweredOnSyncedReply 同步到用户在其他客户端发出的回复时，把被回复的邮件标记为已回复
+func markAnsweredOnSyncedReply(tx *gorm.DB, userID, accountID uint, email *models.Email, inReplyTo string) {
+	if inReplyTo == "" {
+		return
+	}
+	var account models.EmailAccount
+	if err := tx.Select("email", "send_aliases").First(&account, accountID).Error; err != nil {
+		return
+	}
+	from := parseEmailAddress(email.From)
+	if from == nil || !account.CanSendAs(from.Address) {
+		return
+	}
+	if err := tx.Model(&models.Email{}).
+		Where("user_id = ? AND message_id = ? AND is_answered = ?", userID, inReplyTo, false).
+		Updates(map[string]interface{}{"is_answered": true, "version": gorm.Expr("version + 1")}).Error; err != nil {
+		log.Printf("Warning: failed to mark replied email %s as answered: %v", inReplyTo, err)
+	}
+}
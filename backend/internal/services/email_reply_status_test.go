@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+	"firemail/internal/providers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAwaitingReplyFilterAndReplyStatus(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+
+	sent := &models.Folder{
+		AccountID:    env.account.ID,
+		Name:         "Sent",
+		DisplayName:  "已发送",
+		Type:         models.FolderTypeSent,
+		Path:         "Sent",
+		Delimiter:    "/",
+		IsSelectable: true,
+		IsSubscribed: true,
+	}
+	require.NoError(t, env.db.Create(sent).Error)
+
+	pending := env.createEmail(t, env.inbox, 1, "question", true, false)
+	answeredElsewhere := env.createEmail(t, env.inbox, 2, "proposal", true, false)
+	replied := env.createEmail(t, env.inbox, 3, "invoice", true, false)
+	for _, email := range []*models.Email{pending, answeredElsewhere, replied} {
+		require.NoError(t, env.db.Model(email).Update("thread_id", email.MessageID).Error)
+	}
+
+	// 其他客户端发出的回复同步到发件箱：按会话排除，并按 In-Reply-To 标记原邮件
+	reply := env.createEmail(t, sent, 10, "re-proposal", true, false)
+	require.NoError(t, env.db.Model(reply).Updates(map[string]interface{}{
+		"thread_id":    answeredElsewhere.MessageID,
+		"from_address": env.account.Email,
+		"date":         answeredElsewhere.Date.Add(time.Minute),
+	}).Error)
+	reply.From = env.account.Email
+	markAnsweredOnSyncedReply(env.db, env.user.ID, env.account.ID, reply, answeredElsewhere.MessageID)
+
+	// 通过本应用回复后标记原邮件并同步 \Answered
+	env.service.markEmailResponded(ctx, env.user.ID, replied.ID, false)
+	require.Equal(t, fakeStoreFlagsCall{UIDs: []uint32{replied.UID}, Flags: []string{"\\Answered"}, Add: true},
+		env.provider.imap.storeFlagCalls[len(env.provider.imap.storeFlagCalls)-1])
+
+	for _, id := range []uint{answeredElsewhere.ID, replied.ID} {
+		var stored models.Email
+		require.NoError(t, env.db.First(&stored, id).Error)
+		require.True(t, stored.IsAnswered)
+	}
+
+	list, err := env.service.GetEmails(ctx, env.user.ID, &GetEmailsRequest{AwaitingReply: true, Page: 1, PageSize: 20})
+	require.NoError(t, err)
+	require.Len(t, list.Emails, 1)
+	require.Equal(t, pending.ID, list.Emails[0].ID)
+}
+
+func TestApplyReplyFlagsKeepsLocalState(t *testing.T) {
+	email := &models.Email{IsForwarded: true}
+	applyReplyFlags(email, []string{"\\Seen", "\\answered"})
+	require.True(t, email.IsAnswered)
+	require.True(t, email.IsForwarded)
+
+	email = &models.Email{}
+	applyReplyFlags(email, []string{"$Forwarded"})
+	require.False(t, email.IsAnswered)
+	require.True(t, email.IsForwarded)
+}
+
+func TestSyncedDuplicateAppliesReplyFlags(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.EmailEvent{}, &models.SyncConflict{}, &models.UserSetting{}))
+	ctx := context.Background()
+
+	email := env.createEmail(t, env.inbox, 1, "proposal", true, false)
+
+	// 其他客户端回复后把邮件移到项目文件夹
+	syncService := NewSyncService(env.db, nil, env.publisher, NewDeduplicatorFactory(env.db), nil, nil)
+	_, _, err := syncService.storeSyncedEmail(ctx, &providers.EmailMessage{
+		MessageID: email.MessageID,
+		UID:       21,
+		Subject:   email.Subject,
+		Date:      email.Date,
+		Flags:     []string{"\\Seen", "\\Answered"},
+	}, env.account.ID, env.work.ID, env.user.ID)
+	require.NoError(t, err)
+
+	var stored models.Email
+	require.NoError(t, env.db.First(&stored, email.ID).Error)
+	require.Equal(t, env.work.ID, *stored.FolderID)
+	require.True(t, stored.IsAnswered)
+	require.False(t, stored.IsForwarded)
+}
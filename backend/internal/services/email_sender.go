@@ -3,7 +3,9 @@ package services
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"strings"
 	"sync"
@@ -35,7 +37,7 @@ type EmailSender interface {
 type SendResult struct {
 	SendID      string    `json:"send_id"`
 	EmailID     string    `json:"email_id"`
-	Status      string    `json:"status"` // pending, sending, sent, failed
+	Status      string    `json:"status"` // pending, sending, sent, failed, rate_limited
 	Message     string    `json:"message,omitempty"`
 	SentAt      *time.Time `json:"sent_at,omitempty"`
 	Error       string    `json:"error,omitempty"`
@@ -55,9 +57,57 @@ type SendStatus struct {
 	StartTime    time.Time              `json:"start_time"`
 	EndTime      *time.Time             `json:"end_time,omitempty"`
 	Error        string                 `json:"error,omitempty"`
+	ErrorCode    string                 `json:"error_code,omitempty"`  // 提供商错误类别，如 PROVIDER_AUTH_FAILED
+	RetryAfter   *time.Time             `json:"retry_after,omitempty"` // 被限流时建议的重试时间
 	Details      map[string]interface{} `json:"details,omitempty"`
 }
 
+// SendRateLimitedError 发送被限流，需在 RetryAt 之后重试
+type SendRateLimitedError struct {
+	RetryAt time.Time
+	Message string
+}
+
+// Error 实现error接口
+func (e *SendRateLimitedError) Error() string {
+	return e.Message
+}
+
+// awaitSendResult 轮询发送状态直到发送完成、失败或被限流
+func awaitSendResult(ctx context.Context, sender EmailSender, sendID string, timeout, pollInterval time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		status, err := sender.GetSendStatus(ctx, sendID)
+		if err == nil {
+			switch status.Status {
+			case "sent":
+				return nil
+			case "rate_limited":
+				retryAt := time.Now()
+				if status.RetryAfter != nil {
+					retryAt = *status.RetryAfter
+				}
+				return &SendRateLimitedError{RetryAt: retryAt, Message: status.Error}
+			case "failed":
+				if status.Error != "" {
+					return errors.New(status.Error)
+				}
+				return fmt.Errorf("send failed")
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for send result")
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
 // StandardEmailSender 标准邮件发送器
 type StandardEmailSender struct {
 	db              *gorm.DB
@@ -66,8 +116,14 @@ type StandardEmailSender struct {
 	sendStatus      map[string]*SendStatus
 	statusMutex     sync.RWMutex
 	config          *EmailSenderConfig
+	inFlight        sync.WaitGroup // 正在进行的异步发送
+	queueOnly       bool           // 只写入发送队列，由工作进程投递
+	jobs            JobEnqueuer    // 只入队模式下为每封邮件创建投递任务
 }
 
+// sendRetryBaseDelay 临时错误重试的初始等待时间，之后按指数增长
+const sendRetryBaseDelay = 2 * time.Second
+
 // EmailSenderConfig 邮件发送器配置
 type EmailSenderConfig struct {
 	MaxRetries          int           `json:"max_retries"`           // 最大重试次数
@@ -106,8 +162,11 @@ func (s *StandardEmailSender) SendEmail(ctx context.Context, email *ComposedEmai
 		return nil, fmt.Errorf("failed to get email account: %w", err)
 	}
 
-	// 创建发送结果
-	sendID := generateSendID()
+	// 创建发送结果，定时邮件沿用其队列记录的发送ID
+	sendID := sendQueueIDFromContext(ctx)
+	if sendID == "" {
+		sendID = generateSendID()
+	}
 	result := &SendResult{
 		SendID:     sendID,
 		EmailID:    email.ID,
@@ -115,22 +174,24 @@ func (s *StandardEmailSender) SendEmail(ctx context.Context, email *ComposedEmai
 		Recipients: s.getAllRecipients(email),
 	}
 
-	// 创建发送状态
-	if s.config.EnableStatusTracking {
-		status := &SendStatus{
-			SendID:          sendID,
-			EmailID:         email.ID,
-			Status:          "pending",
-			Progress:        0.0,
-			TotalRecipients: len(result.Recipients),
-			StartTime:       time.Now(),
-		}
-		s.setSendStatus(sendID, status)
+	// 投递前先把MIME原文写入发送队列，进程中途退出时重启后继续发送
+	queued, err := s.persistOutbound(ctx, email, account, result)
+	if err != nil {
+		return nil, err
 	}
+	if s.queueOnly {
+		s.enqueueDelivery(ctx, queued)
+		return result, nil
+	}
+
+	// 创建发送状态
+	s.trackSend(result)
 
 	// 异步发送邮件
+	s.inFlight.Add(1)
 	go func() {
-		if err := s.sendEmailAsync(ctx, email, account, result); err != nil {
+		defer s.inFlight.Done()
+		if err := s.sendEmailAsync(ctx, email, account, result, queued); err != nil {
 			log.Printf("Failed to send email %s: %v", email.ID, err)
 		}
 	}()
@@ -138,39 +199,176 @@ func (s *StandardEmailSender) SendEmail(ctx context.Context, email *ComposedEmai
 	return result, nil
 }
 
-// SendBulkEmails 批量发送邮件
+// SendBulkEmails 批量发送邮件，全部邮件复用同一个已认证的SMTP会话依次发送，
+// 避免每封邮件都重新连接、认证而触发提供商限流
 func (s *StandardEmailSender) SendBulkEmails(ctx context.Context, emails []*ComposedEmail, accountID uint) ([]*SendResult, error) {
-	var results []*SendResult
-	var wg sync.WaitGroup
-	semaphore := make(chan struct{}, s.config.MaxConcurrentSends)
-
-	for _, email := range emails {
-		wg.Add(1)
-		go func(e *ComposedEmail) {
-			defer wg.Done()
-			
-			// 获取信号量
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
-
-			result, err := s.SendEmail(ctx, e, accountID)
-			if err != nil {
-				log.Printf("Failed to send bulk email %s: %v", e.ID, err)
-				result = &SendResult{
-					SendID:  generateSendID(),
-					EmailID: e.ID,
-					Status:  "failed",
-					Error:   err.Error(),
-				}
-			}
-			results = append(results, result)
-		}(email)
+	account, err := s.getEmailAccount(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get email account: %w", err)
 	}
 
-	wg.Wait()
+	results := make([]*SendResult, len(emails))
+	jobs := make([]bulkSendJob, 0, len(emails))
+	for i, email := range emails {
+		result := &SendResult{
+			SendID:     generateSendID(),
+			EmailID:    email.ID,
+			Status:     "pending",
+			Recipients: s.getAllRecipients(email),
+		}
+		results[i] = result
+
+		queued, err := s.persistOutbound(ctx, email, account, result)
+		if err != nil {
+			log.Printf("Failed to send bulk email %s: %v", email.ID, err)
+			result.Status = "failed"
+			result.Error = err.Error()
+			continue
+		}
+		if s.queueOnly {
+			s.enqueueDelivery(ctx, queued)
+			continue
+		}
+		s.trackSend(result)
+		jobs = append(jobs, bulkSendJob{email: email, result: result, queued: queued})
+	}
+
+	if len(jobs) > 0 {
+		s.inFlight.Add(1)
+		go func() {
+			defer s.inFlight.Done()
+			s.sendBulkSession(ctx, account, jobs)
+		}()
+	}
 	return results, nil
 }
 
+// bulkSendJob 批量发送中的一封邮件
+type bulkSendJob struct {
+	email  *ComposedEmail
+	result *SendResult
+	queued *models.SendQueue
+}
+
+// defaultMessagesPerSMTPSession 提供商未指定时同一SMTP会话最多发送的邮件数
+const defaultMessagesPerSMTPSession = 50
+
+// sendBulkSession 在同一SMTP会话中依次发送邮件，达到提供商的单会话上限后重新连接
+func (s *StandardEmailSender) sendBulkSession(ctx context.Context, account *models.EmailAccount, jobs []bulkSendJob) {
+	provider, err := s.providerFactory.CreateProviderForAccount(account)
+	if err != nil {
+		err = fmt.Errorf("failed to create provider: %w", err)
+		for _, job := range jobs {
+			s.markOutbound(ctx, job.queued, outboundStatusFailed, err)
+			s.handleSendError(ctx, job.result, account.UserID, err)
+		}
+		return
+	}
+	defer provider.Disconnect()
+
+	limit := defaultMessagesPerSMTPSession
+	if quirks := providerQuirks(provider.GetName()); quirks != nil && quirks.MaxMessagesPerSession > 0 {
+		limit = quirks.MaxMessagesPerSession
+	}
+
+	sessionSent := 0
+	for _, job := range jobs {
+		if sessionSent >= limit {
+			provider.Disconnect()
+			sessionSent = 0
+		}
+
+		job.result.Status = "sending"
+		if s.config.EnableStatusTracking {
+			s.updateSendStatus(job.result.SendID, func(status *SendStatus) {
+				status.Status = "sending"
+				status.Progress = 0.1
+			})
+		}
+		if s.eventPublisher != nil {
+			event := sse.NewEmailSendEvent("email_send_started", job.result.SendID, job.email.ID, account.UserID)
+			s.eventPublisher.PublishToUser(ctx, account.UserID, event)
+		}
+
+		err := s.deliverInSession(ctx, provider, account, job.queued)
+		sessionSent++
+		if err != nil {
+			status := outboundStatusFailed
+			if _, ok := providers.RetryAfter(err); ok {
+				status = outboundStatusRateLimited
+			}
+			s.markOutbound(ctx, job.queued, status, err)
+			if sendErr := s.handleSendError(ctx, job.result, account.UserID, err); sendErr != nil {
+				log.Printf("Failed to send bulk email %s: %v", job.email.ID, sendErr)
+			}
+			continue
+		}
+
+		s.markOutbound(ctx, job.queued, outboundStatusSent, nil)
+		s.handleSendSuccess(ctx, job.result, account, job.email)
+	}
+}
+
+// deliverInSession 在已有会话上投递一封邮件，会话断开时重新连接；仅对临时错误重试
+func (s *StandardEmailSender) deliverInSession(ctx context.Context, provider providers.EmailProvider, account *models.EmailAccount, queued *models.SendQueue) error {
+	for attempt := 0; ; attempt++ {
+		s.markOutbound(ctx, queued, outboundStatusSending, nil)
+		err := s.sendOnSession(ctx, provider, account, queued)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, providers.ErrTransient) || attempt >= s.config.MaxRetries {
+			return err
+		}
+
+		// 服务器可能已关闭空闲会话，断开后下次重新连接
+		provider.Disconnect()
+		delay := sendRetryBaseDelay * time.Duration(1<<uint(attempt))
+		log.Printf("Transient error sending email %s (attempt %d), retrying in %s: %v", queued.SendID, attempt+1, delay, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// sendOnSession 复用已连接的SMTP会话发送保存的MIME原文，未连接时先建立会话
+func (s *StandardEmailSender) sendOnSession(ctx context.Context, provider providers.EmailProvider, account *models.EmailAccount, queued *models.SendQueue) error {
+	smtpClient := provider.SMTPClient()
+	if smtpClient == nil || !smtpClient.IsConnected() {
+		provider.Disconnect()
+		if err := provider.Connect(ctx, account); err != nil {
+			return fmt.Errorf("failed to connect to SMTP: %w", err)
+		}
+		if smtpClient = provider.SMTPClient(); smtpClient == nil {
+			return fmt.Errorf("SMTP client not available")
+		}
+	}
+
+	recipients := strings.Split(queued.Recipients, ",")
+	if err := smtpClient.SendRawEmail(ctx, queued.EnvelopeFrom, recipients, queued.RawMessage); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// Wait 等待正在进行的异步发送完成，用于服务关闭前排空发送任务
+func (s *StandardEmailSender) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for in-flight sends: %w", ctx.Err())
+	}
+}
+
 // GetSendStatus 获取发送状态
 func (s *StandardEmailSender) GetSendStatus(ctx context.Context, sendID string) (*SendStatus, error) {
 	s.statusMutex.RLock()
@@ -203,7 +401,7 @@ func (s *StandardEmailSender) ResendEmail(ctx context.Context, sendID string) (*
 }
 
 // sendEmailAsync 异步发送邮件
-func (s *StandardEmailSender) sendEmailAsync(ctx context.Context, email *ComposedEmail, account *models.EmailAccount, result *SendResult) error {
+func (s *StandardEmailSender) sendEmailAsync(ctx context.Context, email *ComposedEmail, account *models.EmailAccount, result *SendResult, queued *models.SendQueue) error {
 	// 更新状态为发送中
 	result.Status = "sending"
 	if s.config.EnableStatusTracking {
@@ -222,34 +420,67 @@ func (s *StandardEmailSender) sendEmailAsync(ctx context.Context, email *Compose
 	// 创建提供商实例
 	provider, err := s.providerFactory.CreateProviderForAccount(account)
 	if err != nil {
-		return s.handleSendError(ctx, result, account.UserID, fmt.Errorf("failed to create provider: %w", err))
+		err = fmt.Errorf("failed to create provider: %w", err)
+		s.markOutbound(ctx, queued, outboundStatusFailed, err)
+		return s.handleSendError(ctx, result, account.UserID, err)
 	}
 
+	if err := s.deliverWithRetry(ctx, provider, account, queued); err != nil {
+		status := outboundStatusFailed
+		if _, ok := providers.RetryAfter(err); ok {
+			status = outboundStatusRateLimited
+		}
+		s.markOutbound(ctx, queued, status, err)
+		return s.handleSendError(ctx, result, account.UserID, err)
+	}
+
+	// 发送成功
+	s.markOutbound(ctx, queued, outboundStatusSent, nil)
+	return s.handleSendSuccess(ctx, result, account, email)
+}
+
+// deliverWithRetry 投递发送队列中的MIME原文，仅对连接中断、超时等临时错误重试，认证失败、配额已满或被限流时直接返回
+func (s *StandardEmailSender) deliverWithRetry(ctx context.Context, provider providers.EmailProvider, account *models.EmailAccount, queued *models.SendQueue) error {
+	for attempt := 0; ; attempt++ {
+		s.markOutbound(ctx, queued, outboundStatusSending, nil)
+		err := s.deliver(ctx, provider, account, queued)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, providers.ErrTransient) || attempt >= s.config.MaxRetries {
+			return err
+		}
+
+		delay := sendRetryBaseDelay * time.Duration(1<<uint(attempt))
+		log.Printf("Transient error sending email %s (attempt %d), retrying in %s: %v", queued.SendID, attempt+1, delay, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// deliver 连接SMTP服务器并发送一次邮件
+func (s *StandardEmailSender) deliver(ctx context.Context, provider providers.EmailProvider, account *models.EmailAccount, queued *models.SendQueue) error {
 	// 连接到SMTP服务器
 	if err := provider.Connect(ctx, account); err != nil {
-		return s.handleSendError(ctx, result, account.UserID, fmt.Errorf("failed to connect to SMTP: %w", err))
+		return fmt.Errorf("failed to connect to SMTP: %w", err)
 	}
 	defer provider.Disconnect()
 
 	// 获取SMTP客户端
 	smtpClient := provider.SMTPClient()
 	if smtpClient == nil {
-		return s.handleSendError(ctx, result, account.UserID, fmt.Errorf("SMTP client not available"))
+		return fmt.Errorf("SMTP client not available")
 	}
 
-	// 构建发送消息
-	outgoingMessage, err := s.buildOutgoingMessage(email)
-	if err != nil {
-		return s.handleSendError(ctx, result, account.UserID, fmt.Errorf("failed to build outgoing message: %w", err))
+	// 发送保存的MIME原文，重启后重发的内容与首次发送一致
+	recipients := strings.Split(queued.Recipients, ",")
+	if err := smtpClient.SendRawEmail(ctx, queued.EnvelopeFrom, recipients, queued.RawMessage); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
 	}
-
-	// 发送邮件
-	if err := smtpClient.SendEmail(ctx, outgoingMessage); err != nil {
-		return s.handleSendError(ctx, result, account.UserID, fmt.Errorf("failed to send email: %w", err))
-	}
-
-	// 发送成功
-	return s.handleSendSuccess(ctx, result, account, email)
+	return nil
 }
 
 // buildOutgoingMessage 构建发送消息
@@ -269,10 +500,15 @@ func (s *StandardEmailSender) buildOutgoingMessage(email *ComposedEmail) (*provi
 
 	// 转换附件
 	for _, attachment := range email.Attachments {
+		content, err := attachment.Open()
+		if err != nil {
+			closeOutgoingAttachments(message)
+			return nil, fmt.Errorf("failed to open attachment %s: %w", attachment.Filename, err)
+		}
 		outgoingAttachment := &providers.OutgoingAttachment{
 			Filename:    attachment.Filename,
 			ContentType: attachment.ContentType,
-			Content:     bytes.NewReader(attachment.Data),
+			Content:     content,
 			Size:        attachment.Size,
 			Disposition: "attachment",
 		}
@@ -295,6 +531,15 @@ func (s *StandardEmailSender) buildOutgoingMessage(email *ComposedEmail) (*provi
 	return message, nil
 }
 
+// closeOutgoingAttachments 关闭发送消息中打开的附件文件
+func closeOutgoingAttachments(message *providers.OutgoingMessage) {
+	for _, attachment := range message.Attachments {
+		if closer, ok := attachment.Content.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+}
+
 // handleSendSuccess 处理发送成功
 func (s *StandardEmailSender) handleSendSuccess(ctx context.Context, result *SendResult, account *models.EmailAccount, email *ComposedEmail) error {
 	now := time.Now()
@@ -333,10 +578,20 @@ func (s *StandardEmailSender) handleSendError(ctx context.Context, result *SendR
 	result.Error = err.Error()
 	result.RetryCount++
 
+	// 被限流的邮件由发送队列在建议时间后重试
+	var retryAt *time.Time
+	if retryAfter, ok := providers.RetryAfter(err); ok {
+		at := time.Now().Add(retryAfter)
+		retryAt = &at
+		result.Status = "rate_limited"
+	}
+
 	// 更新发送状态
 	if s.config.EnableStatusTracking {
 		s.updateSendStatus(result.SendID, func(status *SendStatus) {
-			status.Status = "failed"
+			status.Status = result.Status
+			status.ErrorCode = providers.ErrorCode(err)
+			status.RetryAfter = retryAt
 			status.Error = err.Error()
 			status.FailedRecipients = status.TotalRecipients
 			now := time.Now()
@@ -386,6 +641,21 @@ func (s *StandardEmailSender) getAllRecipients(email *ComposedEmail) []string {
 	return recipients
 }
 
+// trackSend 为新的发送创建发送状态
+func (s *StandardEmailSender) trackSend(result *SendResult) {
+	if !s.config.EnableStatusTracking {
+		return
+	}
+	s.setSendStatus(result.SendID, &SendStatus{
+		SendID:          result.SendID,
+		EmailID:         result.EmailID,
+		Status:          "pending",
+		Progress:        0.0,
+		TotalRecipients: len(result.Recipients),
+		StartTime:       time.Now(),
+	})
+}
+
 // setSendStatus 设置发送状态
 func (s *StandardEmailSender) setSendStatus(sendID string, status *SendStatus) {
 	s.statusMutex.Lock()
@@ -403,13 +673,6 @@ func (s *StandardEmailSender) updateSendStatus(sendID string, updateFunc func(*S
 	}
 }
 
-// loadSendStatusFromDB 从数据库加载发送状态
-func (s *StandardEmailSender) loadSendStatusFromDB(ctx context.Context, sendID string) (*SendStatus, error) {
-	// 这里应该从数据库加载发送状态
-	// 暂时返回未找到错误
-	return nil, fmt.Errorf("send status not found: %s", sendID)
-}
-
 // loadSentEmailWithAccountFromDB 从数据库加载已发送邮件和账户信息
 func (s *StandardEmailSender) loadSentEmailWithAccountFromDB(ctx context.Context, emailID string) (*ComposedEmail, uint, error) {
 	// 这里应该从数据库加载已发送邮件和账户信息
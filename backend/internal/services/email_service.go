@@ -19,8 +19,10 @@ import (
 
 	"firemail/internal/cache"
 	"firemail/internal/config"
+	"firemail/internal/i18n"
 	"firemail/internal/models"
 	"firemail/internal/providers"
+	"firemail/internal/sanitize"
 	"firemail/internal/sse"
 
 	"gorm.io/gorm"
@@ -35,6 +37,7 @@ type EmailService interface {
 	UpdateEmailAccount(ctx context.Context, userID, accountID uint, req *UpdateEmailAccountRequest) (*models.EmailAccount, error)
 	DeleteEmailAccount(ctx context.Context, userID, accountID uint) error
 	TestEmailAccount(ctx context.Context, userID, accountID uint) error
+	ReorderEmailAccounts(ctx context.Context, userID uint, order []uint) ([]*models.EmailAccount, error)
 
 	// 邮件同步
 	SyncEmails(ctx context.Context, accountID uint) error
@@ -44,6 +47,7 @@ type EmailService interface {
 	// 邮件操作
 	GetEmails(ctx context.Context, userID uint, req *GetEmailsRequest) (*GetEmailsResponse, error)
 	GetEmail(ctx context.Context, userID, emailID uint) (*models.Email, error)
+	GetEmailNavigation(ctx context.Context, userID, emailID uint, req *GetEmailsRequest) (*EmailNavigation, error)
 	SendEmail(ctx context.Context, userID uint, req *SendEmailRequest) error
 	DeleteEmail(ctx context.Context, userID, emailID uint) error
 	MarkEmailAsRead(ctx context.Context, userID, emailID uint) error
@@ -54,13 +58,73 @@ type EmailService interface {
 	MarkAccountsAsRead(ctx context.Context, userID uint, accountIDs []uint) error
 	ToggleEmailStar(ctx context.Context, userID, emailID uint) error
 	ToggleEmailImportant(ctx context.Context, userID, emailID uint) error
+	ToggleEmailPin(ctx context.Context, userID, emailID uint) (*models.Email, error)
+	SetEmailImportance(ctx context.Context, userID, emailID uint, bucket string) (*models.Email, error)
 	MoveEmail(ctx context.Context, userID, emailID uint, targetFolderID uint) error
+	GetEmailHistory(ctx context.Context, userID, emailID uint) ([]*EmailHistoryEntry, error)
+
+	// 邮件私有笔记
+	ListEmailNotes(ctx context.Context, userID, emailID uint) ([]models.EmailNote, error)
+	CreateEmailNote(ctx context.Context, userID, emailID uint, req *CreateEmailNoteRequest) (*models.EmailNote, error)
+	UpdateEmailNote(ctx context.Context, userID, emailID, noteID uint, req *UpdateEmailNoteRequest) (*models.EmailNote, error)
+	DeleteEmailNote(ctx context.Context, userID, emailID, noteID uint) error
+
+	// 会话静音
+	MuteThread(ctx context.Context, userID, emailID uint) (*models.MutedThread, error)
+	UnmuteThread(ctx context.Context, userID, emailID uint) error
+	ListMutedThreads(ctx context.Context, userID uint) ([]models.MutedThread, error)
+	DeleteMutedThread(ctx context.Context, userID, mutedThreadID uint) error
+
+	// 同步冲突
+	ListSyncConflicts(ctx context.Context, userID uint, status string) ([]models.SyncConflict, error)
+	ResolveSyncConflict(ctx context.Context, userID, conflictID uint, resolution string) (*models.SyncConflict, error)
+
+	// VIP发件人
+	ListVIPSenders(ctx context.Context, userID uint) ([]models.VIPSender, error)
+	AddVIPSender(ctx context.Context, userID uint, req *AddVIPSenderRequest) (*models.VIPSender, error)
+	RemoveVIPSender(ctx context.Context, userID, senderID uint) error
+
+	// 屏蔽发件人
+	ListBlockedSenders(ctx context.Context, userID uint) ([]models.BlockedSender, error)
+	AddBlockedSender(ctx context.Context, userID uint, req *BlockedSenderRequest) (*models.BlockedSender, error)
+	UpdateBlockedSender(ctx context.Context, userID, senderID uint, req *UpdateBlockedSenderRequest) (*models.BlockedSender, error)
+	DeleteBlockedSender(ctx context.Context, userID, senderID uint) error
+	BlockEmailSender(ctx context.Context, userID, emailID uint, req *BlockEmailSenderRequest) (*models.BlockedSender, error)
+	ExportBlockedSenders(ctx context.Context, userID uint) (*BlockedSenderExport, error)
+	ImportBlockedSenders(ctx context.Context, userID uint, req *ImportBlockedSendersRequest) (*ImportBlockedSendersResult, error)
+
+	// 跨账户移动/复制
+	TransferEmails(ctx context.Context, userID uint, req *TransferEmailsRequest) (*EmailTransferJob, error)
+	GetEmailTransferJob(ctx context.Context, userID uint, jobID string) (*EmailTransferJob, error)
+	CancelEmailTransferJob(ctx context.Context, userID uint, jobID string) (*EmailTransferJob, error)
+
+	// 重复邮件查找与清理
+	StartDuplicateScan(ctx context.Context, userID uint, req *StartDuplicateScanRequest) (*DuplicateScanJob, error)
+	GetDuplicateScan(ctx context.Context, userID uint, jobID string) (*DuplicateScanJob, error)
+	CancelDuplicateScan(ctx context.Context, userID uint, jobID string) (*DuplicateScanJob, error)
+	ResolveDuplicates(ctx context.Context, userID uint, jobID string, req *ResolveDuplicatesRequest) (*ResolveDuplicatesResult, error)
+
+	// 邮箱空间分析与清理
+	GetMailboxStorageReport(ctx context.Context, userID, accountID uint, req *StorageTopRequest) (*MailboxStorageReport, error)
+	StartStorageCleanup(ctx context.Context, userID, accountID uint, req *StartStorageCleanupRequest) (*StorageCleanupJob, error)
+	GetStorageCleanup(ctx context.Context, userID, accountID uint, jobID string) (*StorageCleanupJob, error)
+	CancelStorageCleanup(ctx context.Context, userID, accountID uint, jobID string) (*StorageCleanupJob, error)
 
 	// 邮件回复、转发、归档操作
 	ReplyEmail(ctx context.Context, userID, emailID uint, req *ReplyEmailRequest) error
 	ReplyAllEmail(ctx context.Context, userID, emailID uint, req *ReplyEmailRequest) error
 	ForwardEmail(ctx context.Context, userID, emailID uint, req *ForwardEmailRequest) error
 	ArchiveEmail(ctx context.Context, userID, emailID uint) error
+	RedecodeEmail(ctx context.Context, userID, emailID uint, charset string) (*models.Email, error)
+	ReparseEmail(ctx context.Context, userID, emailID uint) (*models.Email, error)
+	GetEmailSource(ctx context.Context, userID, emailID uint) (*EmailSource, error)
+	AnalyzeEmailHeaders(ctx context.Context, userID, emailID uint) (*HeaderAnalysis, error)
+	ExportEmailPDF(ctx context.Context, userID, emailID uint, opts *EmailPDFOptions) (*EmailExport, error)
+
+	// 批量重新解析（管理员）
+	StartReparseJob(ctx context.Context, req *StartReparseJobRequest) (*ReparseJob, error)
+	GetReparseJob(ctx context.Context, jobID string) (*ReparseJob, error)
+	CancelReparseJob(ctx context.Context, jobID string) (*ReparseJob, error)
 
 	// 文件夹管理
 	GetFolders(ctx context.Context, userID, accountID uint) ([]*models.Folder, error)
@@ -69,7 +133,14 @@ type EmailService interface {
 	UpdateFolder(ctx context.Context, userID, folderID uint, req *UpdateFolderRequest) (*models.Folder, error)
 	DeleteFolder(ctx context.Context, userID, folderID uint) error
 	MarkFolderAsRead(ctx context.Context, userID, folderID uint) error
+	ReconcileFolderCounts(ctx context.Context, userID uint, req *ReconcileFolderCountsRequest) (*FolderCountReconciliation, error)
 	SyncSpecificFolder(ctx context.Context, userID, folderID uint) error
+	UpdateFolderAppearance(ctx context.Context, userID, folderID uint, req *AppearanceRequest) (*models.Folder, error)
+
+	// 标签显示属性
+	GetLabels(ctx context.Context, userID, accountID uint) ([]models.Label, error)
+	UpdateLabelAppearance(ctx context.Context, userID, accountID uint, req *UpdateLabelAppearanceRequest) (*models.Label, error)
+	DeleteLabelAppearance(ctx context.Context, userID, labelID uint) error
 
 	// 邮箱分组管理
 	GetEmailGroups(ctx context.Context, userID uint) ([]*models.EmailGroup, error)
@@ -83,6 +154,31 @@ type EmailService interface {
 
 	// 搜索
 	SearchEmails(ctx context.Context, userID uint, req *SearchEmailsRequest) (*GetEmailsResponse, error)
+
+	// 回收站
+	ListTrashedEmails(ctx context.Context, userID uint, req *TrashListRequest) (*GetEmailsResponse, error)
+	RestoreEmails(ctx context.Context, userID uint, emailIDs []uint) (int64, error)
+	PurgeEmails(ctx context.Context, userID uint, emailIDs []uint) (int64, error)
+	EmptyTrash(ctx context.Context, userID uint, accountID *uint) (int64, error)
+
+	// 旗标
+	SetEmailFlag(ctx context.Context, userID, emailID uint, req *SetEmailFlagRequest) (*models.Email, error)
+
+	// 稍后回复
+	SetReplyLater(ctx context.Context, userID, emailID uint, req *ReplyLaterRequest) (*models.Email, error)
+	ClearReplyLater(ctx context.Context, userID, emailID uint) (*models.Email, error)
+	ListReplyLaterEmails(ctx context.Context, userID uint, req *ReplyLaterListRequest) (*GetEmailsResponse, error)
+	StartReplyLaterReminders(ctx context.Context)
+
+	// 通知快捷操作
+	ExecuteNotificationAction(ctx context.Context, token string) (*NotificationActionResult, error)
+
+	// Gmail标签映射
+	GetGmailLabelMappings(ctx context.Context, userID, accountID uint) ([]GmailLabelMapping, error)
+	UpdateGmailLabelMappings(ctx context.Context, userID, accountID uint, req *UpdateGmailLabelMappingsRequest) ([]GmailLabelMapping, error)
+
+	// 验证码
+	GetVerificationCodes(ctx context.Context, userID uint, req *VerificationCodesRequest) ([]VerificationCode, error)
 }
 
 // EmailServiceImpl 邮件服务实现
@@ -92,7 +188,17 @@ type EmailServiceImpl struct {
 	eventPublisher    sse.EventPublisher
 	syncService       *SyncService // 添加同步服务依赖
 	cacheManager      *cache.CacheManager
-	attachmentService AttachmentDownloader // 添加附件服务依赖
+	attachmentService AttachmentDownloader       // 添加附件服务依赖
+	embeddingModel    EmbeddingModel             // 语义搜索向量模型，为空时不支持语义搜索
+	draftSyncer       DraftSyncer                // 草稿IMAP同步
+	reparseJobs       *reparseJobRegistry        // 批量重新解析任务
+	transferJobs      *emailTransferJobRegistry  // 跨账户移动/复制任务
+	duplicateScans    *duplicateScanJobRegistry  // 重复邮件扫描任务
+	storageCleanups   *storageCleanupJobRegistry // 邮箱清理任务
+	changeLog         ChangeLogService           // 增量同步变更日志
+	geoLocator        IPGeoLocator               // 邮件头分析的来源IP地理位置查询，可为空
+	jobs              JobEnqueuer                // 后台任务队列，为空时在协程中同步
+	attachmentLinks   AttachmentLinkService      // 超限附件改为下载链接，为空时不支持
 }
 
 // NewEmailService 创建邮件服务实例
@@ -102,6 +208,10 @@ func NewEmailService(db *gorm.DB, providerFactory *providers.ProviderFactory, ev
 		providerFactory: providerFactory,
 		eventPublisher:  eventPublisher,
 		cacheManager:    cache.GlobalCacheManager,
+		reparseJobs:     newReparseJobRegistry(),
+		transferJobs:    newEmailTransferJobRegistry(),
+		duplicateScans:  newDuplicateScanJobRegistry(),
+		storageCleanups: newStorageCleanupJobRegistry(),
 	}
 }
 
@@ -110,11 +220,31 @@ func (s *EmailServiceImpl) SetSyncService(syncService *SyncService) {
 	s.syncService = syncService
 }
 
+// SetDraftSyncer 设置草稿同步依赖
+func (s *EmailServiceImpl) SetDraftSyncer(draftSyncer DraftSyncer) {
+	s.draftSyncer = draftSyncer
+}
+
 // SetAttachmentService 设置附件服务依赖
 func (s *EmailServiceImpl) SetAttachmentService(attachmentService AttachmentDownloader) {
 	s.attachmentService = attachmentService
 }
 
+// SetAttachmentLinkService 设置附件下载链接服务，发信时可把超过大小上限的附件改为下载链接
+func (s *EmailServiceImpl) SetAttachmentLinkService(attachmentLinks AttachmentLinkService) {
+	s.attachmentLinks = attachmentLinks
+}
+
+// SetChangeLog 设置变更日志依赖
+func (s *EmailServiceImpl) SetChangeLog(changeLog ChangeLogService) {
+	s.changeLog = changeLog
+}
+
+// SetIPGeoLocator 设置邮件头分析使用的IP地理位置查询
+func (s *EmailServiceImpl) SetIPGeoLocator(locator IPGeoLocator) {
+	s.geoLocator = locator
+}
+
 // 请求和响应结构体
 
 // CreateEmailAccountRequest 创建邮件账户请求
@@ -132,6 +262,18 @@ type CreateEmailAccountRequest struct {
 	SMTPPort     int    `json:"smtp_port"`
 	SMTPSecurity string `json:"smtp_security"`
 	GroupID      *uint  `json:"group_id"`
+
+	SendAliases []string `json:"send_aliases"` // 发件别名，如iCloud自定义域名地址、隐藏邮件地址
+
+	// 外发邮件标识，为空时使用实例配置
+	EHLOName        string `json:"ehlo_name"`
+	MessageIDDomain string `json:"message_id_domain"`
+	Mailer          string `json:"mailer"`
+
+	BounceAddress string `json:"bounce_address"` // 退信地址，作为SMTP信封发件人，为空时使用发件人地址
+	DNSResolver   string `json:"dns_resolver"`   // 连接服务器使用的DNS服务器或DoH地址，为空时使用全局设置
+
+	AccountTLSRequest
 }
 
 // OptionalGroupID 支持区分 group_id 的三态语义：
@@ -174,29 +316,73 @@ type UpdateEmailAccountRequest struct {
 	SMTPSecurity *string         `json:"smtp_security"`
 	IsActive     *bool           `json:"is_active"`
 	GroupID      OptionalGroupID `json:"group_id"`
+	MaxPartSize  *int64          `json:"max_part_size"` // 单个MIME部分内联下载的大小上限（字节），0表示使用默认值
+
+	NotificationsMuted *bool `json:"notifications_muted"` // 静音后仅VIP发件人的邮件会通知
+
+	SendAliases *[]string `json:"send_aliases"` // 发件别名，传空数组表示清空
+
+	// 外发邮件标识，传空字符串表示使用实例配置
+	EHLOName        *string `json:"ehlo_name"`
+	MessageIDDomain *string `json:"message_id_domain"`
+	Mailer          *string `json:"mailer"`
+
+	BounceAddress *string `json:"bounce_address"` // 退信地址，传空字符串表示使用发件人地址
+	DNSResolver   *string `json:"dns_resolver"`   // DNS服务器或DoH地址，传空字符串表示使用全局设置
+
+	Version *uint `json:"version"` // 读取到的账户版本号，账户已被其他请求修改时返回409；也可通过 If-Match 提交
+
+	// 显示设置，传空字符串表示清除
+	Nickname       *string `json:"nickname"`
+	Color          *string `json:"color"`           // #RRGGBB
+	AvatarEmoji    *string `json:"avatar_emoji"`
+	AvatarInitials *string `json:"avatar_initials"` // 1到3个字母或数字
+	DisplayOrder   *int    `json:"display_order"`
+
+	AccountTLSRequest
 }
 
 // GetEmailsRequest 获取邮件列表请求
 type GetEmailsRequest struct {
-	AccountID   *uint  `json:"account_id"`
-	FolderID    *uint  `json:"folder_id"`
-	IsRead      *bool  `json:"is_read"`
-	IsStarred   *bool  `json:"is_starred"`
-	IsImportant *bool  `json:"is_important"`
-	Page        int    `json:"page"`
-	PageSize    int    `json:"page_size"`
-	SortBy      string `json:"sort_by"`
-	SortOrder   string `json:"sort_order"`
-	SearchQuery string `json:"search_query"`
+	AccountID        *uint      `json:"account_id"`
+	FolderID         *uint      `json:"folder_id"`
+	IsRead           *bool      `json:"is_read"`
+	IsStarred        *bool      `json:"is_starred"`
+	IsImportant      *bool      `json:"is_important"`
+	ImportanceBucket string     `json:"importance_bucket"` // 优先收件箱分类：important 或 other
+	IsVIP            *bool      `json:"is_vip"`
+	FlagColor        string     `json:"flag_color"` // 按旗标颜色过滤
+	IsFollowUp       *bool      `json:"is_follow_up"`
+	FollowUpDue      *time.Time `json:"follow_up_due"` // 只返回跟进截止时间不晚于该时间的邮件
+	IsAnswered       *bool      `json:"is_answered"`
+	AwaitingReply    bool       `json:"awaiting_reply"` // 只返回等待我回复的邮件
+	Page             int        `json:"page"`
+	PageSize         int        `json:"page_size"`
+	SortBy           string     `json:"sort_by"`
+	SortOrder        string     `json:"sort_order"`
+	SearchQuery      string     `json:"search_query"`
+	Cursor           string     `json:"cursor"`       // 非空时按游标分页，忽略Page；仅支持按日期排序
+	PinnedFirst      bool       `json:"pinned_first"` // 置顶邮件排在最前，不支持游标分页
+	View             string     `json:"view"`         // full（默认）或 summary，summary 时只返回列表摘要
+	GroupBy          string     `json:"group_by"`     // date 或 sender，非空时返回当前页的分组和各分组总数
 }
 
 // GetEmailsResponse 获取邮件列表响应
 type GetEmailsResponse struct {
-	Emails     []*models.Email `json:"emails"`
-	Total      int64           `json:"total"`
-	Page       int             `json:"page"`
-	PageSize   int             `json:"page_size"`
-	TotalPages int             `json:"total_pages"`
+	Emails     []*models.Email   `json:"emails"`
+	Summaries  []*EmailSummary   `json:"summaries,omitempty"` // view=summary 时返回，此时 emails 为空
+	Total      int64             `json:"total"`
+	Page       int               `json:"page"`
+	PageSize   int               `json:"page_size"`
+	TotalPages int               `json:"total_pages"`
+	HasMore    bool              `json:"has_more"`
+	NextCursor string            `json:"next_cursor,omitempty"` // 按日期排序时返回，用于请求下一页
+	Groups     []*EmailListGroup `json:"groups,omitempty"`      // 指定 group_by 时返回
+}
+
+// 邮件列表缓存可能存放在Redis中，需要注册以便读取时还原类型
+func init() {
+	cache.RegisterType(&GetEmailsResponse{})
 }
 
 // SendEmailRequest 发送邮件请求
@@ -208,10 +394,19 @@ type SendEmailRequest struct {
 	Subject       string                 `json:"subject" binding:"required"`
 	TextBody      string                 `json:"text_body"`
 	HTMLBody      string                 `json:"html_body"`
+	MarkdownBody  string                 `json:"markdown_body,omitempty"` // Markdown正文，设置时由服务端渲染HTML正文，忽略 html_body
+	CodeHighlight *bool                  `json:"code_highlight,omitempty"` // Markdown代码块是否着色，为空时使用服务端配置
 	Attachments   []*SendEmailAttachment `json:"attachments"`
 	AttachmentIDs []uint                 `json:"attachment_ids"`
 	Priority      string                 `json:"priority"`
 	ReplyToID     *uint                  `json:"reply_to_id"`
+	DraftID       *uint                  `json:"draft_id"`      // 发送成功后删除的草稿
+	Headers       map[string]string      `json:"-"`             // 附加的邮件头，只用于自动转发等内部发送
+	EnvelopeFrom  string                 `json:"envelope_from"` // SMTP信封发件人（Return-Path），为空时使用账户退信地址或发件人地址
+
+	// OffloadLargeAttachments 超过提供商大小上限时把最大的附件存入附件存储，正文中改为有效期内的下载链接
+	OffloadLargeAttachments bool   `json:"offload_large_attachments"`
+	LinkBaseURL             string `json:"-"` // 下载链接使用的外部访问地址，由处理器填写
 }
 
 // SendEmailAttachment 发送邮件附件
@@ -236,6 +431,7 @@ type UpdateFolderRequest struct {
 	Name        *string `json:"name"`
 	DisplayName *string `json:"display_name"`
 	ParentID    *uint   `json:"parent_id"`
+	Type        *string `json:"type"` // 手动指定文件夹类型，同步时不再自动检测；空字符串恢复自动检测
 }
 
 // CreateEmailGroupRequest 创建邮箱分组请求
@@ -257,6 +453,7 @@ type SearchEmailsRequest struct {
 	From          string     `json:"from"`
 	To            string     `json:"to"`
 	Body          string     `json:"body"`
+	Note          string     `json:"note"` // 匹配私有笔记内容
 	Since         *time.Time `json:"since"`
 	Before        *time.Time `json:"before"`
 	HasAttachment *bool      `json:"has_attachment"`
@@ -264,6 +461,8 @@ type SearchEmailsRequest struct {
 	IsStarred     *bool      `json:"is_starred"`
 	Page          int        `json:"page"`
 	PageSize      int        `json:"page_size"`
+	Cursor        string     `json:"cursor"` // 非空时按游标分页，忽略Page；相似度搜索不支持
+	Mode          string     `json:"mode"`   // keyword（默认）或 semantic
 }
 
 // ReplyEmailRequest 回复邮件请求
@@ -338,6 +537,28 @@ func (s *EmailServiceImpl) CreateEmailAccount(ctx context.Context, userID uint,
 	if err := s.configureAccountByProvider(account, req, providerConfig); err != nil {
 		return nil, fmt.Errorf("failed to configure account: %w", err)
 	}
+	if err := applySendAliases(account, req.SendAliases); err != nil {
+		return nil, err
+	}
+	if err := applyOutgoingIdentity(account, &req.EHLOName, &req.MessageIDDomain, &req.Mailer); err != nil {
+		return nil, err
+	}
+	if err := applyBounceAddress(account, req.BounceAddress); err != nil {
+		return nil, err
+	}
+	if err := applyAccountDNSResolver(account, req.DNSResolver); err != nil {
+		return nil, err
+	}
+	if err := applyAccountTLS(account, &req.AccountTLSRequest); err != nil {
+		return nil, err
+	}
+	if account.Provider == "exchange" && account.IMAPHost == "" && account.AuthMethod == "password" {
+		endpoint, err := providers.DiscoverEWSURL(ctx, account)
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover Exchange server, please enter the EWS URL manually: %w", err)
+		}
+		providers.SetExchangeEndpoint(account, endpoint, 0, "")
+	}
 
 	// 调试日志
 	log.Printf("Account before validation: Provider=%s, IMAPHost=%s, IMAPPort=%d, SMTPHost=%s, SMTPPort=%d",
@@ -358,6 +579,7 @@ func (s *EmailServiceImpl) CreateEmailAccount(ctx context.Context, userID uint,
 	}
 
 	s.publishAccountGroupChangedEvent(ctx, userID, account, nil)
+	recordAccountChange(ctx, s.changeLog, userID, account.ID, models.ChangeActionCreated)
 
 	// 测试连接
 	if err := s.TestEmailAccount(ctx, userID, account.ID); err != nil {
@@ -365,6 +587,13 @@ func (s *EmailServiceImpl) CreateEmailAccount(ctx context.Context, userID uint,
 		account.SyncStatus = "error"
 		account.ErrorMessage = err.Error()
 		s.db.Save(account)
+		// 需要用户先在邮箱网页版完成设置时返回分步说明
+		account.SetupGuide = providers.SetupGuideOf(err)
+	} else if s.jobs != nil {
+		// 测试成功，入队文件夹同步任务
+		if err := s.enqueueAccountFoldersSync(ctx, account); err != nil {
+			log.Printf("Failed to enqueue folder sync for account %d: %v", account.ID, err)
+		}
 	} else {
 		// 测试成功，开始同步文件夹
 		go func() {
@@ -389,7 +618,7 @@ func (s *EmailServiceImpl) GetEmailAccounts(ctx context.Context, userID uint) ([
 	var accounts []*models.EmailAccount
 
 	err := s.db.Where("user_id = ?", userID).
-		Order("created_at DESC").
+		Order("display_order ASC, created_at DESC").
 		Find(&accounts).Error
 
 	if err != nil {
@@ -449,6 +678,39 @@ func (s *EmailServiceImpl) UpdateEmailAccount(ctx context.Context, userID, accou
 	if req.IsActive != nil {
 		account.IsActive = *req.IsActive
 	}
+	if req.NotificationsMuted != nil {
+		account.NotificationsMuted = *req.NotificationsMuted
+	}
+	if req.MaxPartSize != nil {
+		if *req.MaxPartSize < 0 {
+			return nil, fmt.Errorf("max_part_size must not be negative")
+		}
+		account.MaxPartSize = *req.MaxPartSize
+	}
+	if req.SendAliases != nil {
+		if err := applySendAliases(account, *req.SendAliases); err != nil {
+			return nil, err
+		}
+	}
+	if err := applyOutgoingIdentity(account, req.EHLOName, req.MessageIDDomain, req.Mailer); err != nil {
+		return nil, err
+	}
+	if req.BounceAddress != nil {
+		if err := applyBounceAddress(account, *req.BounceAddress); err != nil {
+			return nil, err
+		}
+	}
+	if req.DNSResolver != nil {
+		if err := applyAccountDNSResolver(account, *req.DNSResolver); err != nil {
+			return nil, err
+		}
+	}
+	if err := applyAccountDisplay(account, req); err != nil {
+		return nil, err
+	}
+	if err := applyAccountTLS(account, &req.AccountTLSRequest); err != nil {
+		return nil, err
+	}
 	if req.GroupID.Set {
 		targetGroup, err := s.resolveAccountGroup(ctx, userID, req.GroupID.Value)
 		if err != nil {
@@ -462,10 +724,14 @@ func (s *EmailServiceImpl) UpdateEmailAccount(ctx context.Context, userID, accou
 		return nil, fmt.Errorf("invalid provider configuration: %w", err)
 	}
 
-	// 保存更新
-	if err := s.db.Save(account).Error; err != nil {
+	// 保存更新，账户在读取后被其他请求修改时返回版本冲突
+	if err := s.saveEmailAccount(ctx, account, req.Version); err != nil {
+		if errors.Is(err, ErrVersionConflict) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to update email account: %w", err)
 	}
+	recordAccountChange(ctx, s.changeLog, userID, accountID, models.ChangeActionUpdated)
 
 	if req.GroupID.Set && !sameUintPointerValue(previousGroupID, account.GroupID) {
 		s.publishAccountGroupChangedEvent(ctx, userID, account, previousGroupID)
@@ -478,7 +744,10 @@ func (s *EmailServiceImpl) UpdateEmailAccount(ctx context.Context, userID, accou
 		if err := s.TestEmailAccount(ctx, userID, accountID); err != nil {
 			account.SyncStatus = "error"
 			account.ErrorMessage = err.Error()
-			s.db.Save(account)
+			s.db.Model(account).Updates(map[string]interface{}{
+				"sync_status":   account.SyncStatus,
+				"error_message": account.ErrorMessage,
+			})
 		}
 	}
 
@@ -493,6 +762,13 @@ func (s *EmailServiceImpl) DeleteEmailAccount(ctx context.Context, userID, accou
 		return err
 	}
 
+	// 账户中有受法律保全保护的邮件时不能删除
+	if held, err := hasHeldEmails(s.db.WithContext(ctx), userID, "emails.account_id = ?", accountID); err != nil {
+		return err
+	} else if held {
+		return fmt.Errorf("cannot delete account: %w", ErrEmailUnderLegalHold)
+	}
+
 	// 开始事务
 	tx := s.db.Begin()
 	defer func() {
@@ -501,16 +777,10 @@ func (s *EmailServiceImpl) DeleteEmailAccount(ctx context.Context, userID, accou
 		}
 	}()
 
-	// 删除相关的附件（使用子查询避免外键约束问题）
-	if err := tx.Unscoped().Where("email_id IN (SELECT id FROM emails WHERE account_id = ?)", accountID).Delete(&models.Attachment{}).Error; err != nil {
+	// 删除相关的邮件及附件（硬删除，不经过回收站）
+	if _, err := purgeEmails(tx, "account_id = ?", accountID); err != nil {
 		tx.Rollback()
-		return fmt.Errorf("failed to delete attachments: %w", err)
-	}
-
-	// 删除相关的邮件（硬删除）
-	if err := tx.Unscoped().Where("account_id = ?", accountID).Delete(&models.Email{}).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to delete emails: %w", err)
+		return err
 	}
 
 	// 删除相关的文件夹（硬删除）
@@ -519,13 +789,43 @@ func (s *EmailServiceImpl) DeleteEmailAccount(ctx context.Context, userID, accou
 		return fmt.Errorf("failed to delete folders: %w", err)
 	}
 
+	// 删除标签显示属性
+	if labelsAvailable(tx) {
+		if err := tx.Where("account_id = ?", accountID).Delete(&models.Label{}).Error; err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to delete labels: %w", err)
+		}
+	}
+
+	// 删除同步冲突记录
+	if syncConflictsAvailable(tx) {
+		if err := tx.Where("account_id = ?", accountID).Delete(&models.SyncConflict{}).Error; err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to delete sync conflicts: %w", err)
+		}
+	}
+
+	// 删除发送队列中的记录，该表的外键没有级联删除
+	if tx.Migrator().HasTable(&models.SendQueue{}) {
+		if err := tx.Unscoped().Where("account_id = ?", accountID).Delete(&models.SendQueue{}).Error; err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to delete send queue entries: %w", err)
+		}
+	}
+
 	// 删除账户（硬删除）
 	if err := tx.Unscoped().Delete(account).Error; err != nil {
 		tx.Rollback()
 		return fmt.Errorf("failed to delete email account: %w", err)
 	}
 
-	return tx.Commit().Error
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	s.invalidateEmailListCache(userID, accountID, nil)
+	recordAccountChange(ctx, s.changeLog, userID, accountID, models.ChangeActionDeleted)
+	return nil
 }
 
 // TestEmailAccount 测试邮件账户连接
@@ -575,7 +875,12 @@ func (s *EmailServiceImpl) TestEmailAccount(ctx context.Context, userID, account
 		}
 	}
 
-	return s.db.Save(account).Error
+	if err := s.db.Save(account).Error; err != nil {
+		return err
+	}
+
+	recordAccountChange(ctx, s.changeLog, userID, account.ID, models.ChangeActionUpdated)
+	return nil
 }
 
 // setupProviderTokenCallback 为provider设置OAuth2 token更新回调
@@ -624,6 +929,7 @@ func (s *EmailServiceImpl) syncFoldersForAccount(ctx context.Context, accountID
 	if err != nil {
 		return fmt.Errorf("failed to list folders: %w", err)
 	}
+	applyFolderQuirks(folders, providerQuirks(provider.GetName()))
 
 	// 保存文件夹到数据库
 	for _, folderInfo := range folders {
@@ -652,7 +958,9 @@ func (s *EmailServiceImpl) syncFoldersForAccount(ctx context.Context, accountID
 			// 更新现有文件夹
 			existingFolder.Name = folderInfo.Name
 			existingFolder.DisplayName = folderInfo.DisplayName
-			existingFolder.Type = folderInfo.Type
+			if !existingFolder.TypeLocked {
+				existingFolder.Type = folderInfo.Type
+			}
 			existingFolder.IsSelectable = folderInfo.IsSelectable
 			existingFolder.IsSubscribed = folderInfo.IsSubscribed
 
@@ -756,12 +1064,12 @@ func (s *EmailServiceImpl) resolveAccountGroup(ctx context.Context, userID uint,
 		First(&group).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("分组不存在")
+			return nil, fmt.Errorf("group not found")
 		}
 		return nil, err
 	}
 	if group.IsSystemGroup() && !group.IsDefault {
-		return nil, fmt.Errorf("系统占位分组不可直接分配邮箱")
+		return nil, fmt.Errorf("system placeholder group cannot be assigned accounts")
 	}
 
 	return &group, nil
@@ -778,37 +1086,25 @@ func (s *EmailServiceImpl) GetEmailGroups(ctx context.Context, userID uint) ([]*
 		return nil, err
 	}
 
-	var groups []*models.EmailGroup
+	// 分组与账户数量在同一查询中取得
+	var rows []struct {
+		models.EmailGroup
+		AccountCount int64
+	}
 	if err := s.db.WithContext(ctx).
-		Where("user_id = ?", userID).
+		Model(&models.EmailGroup{}).
+		Select("email_groups.*, (SELECT COUNT(*) FROM email_accounts WHERE email_accounts.group_id = email_groups.id AND email_accounts.user_id = email_groups.user_id AND email_accounts.deleted_at IS NULL) AS account_count").
+		Where("email_groups.user_id = ?", userID).
 		Order("is_default DESC, sort_order ASC, id ASC").
-		Find(&groups).Error; err != nil {
+		Scan(&rows).Error; err != nil {
 		return nil, fmt.Errorf("failed to load groups: %w", err)
 	}
 
-	var counts []struct {
-		GroupID *uint
-		Count   int64
-	}
-	if err := s.db.WithContext(ctx).
-		Model(&models.EmailAccount{}).
-		Select("group_id, COUNT(*) as count").
-		Where("user_id = ?", userID).
-		Group("group_id").
-		Scan(&counts).Error; err != nil {
-		return nil, fmt.Errorf("failed to count accounts: %w", err)
-	}
-
-	countMap := make(map[uint]int64)
-	for _, c := range counts {
-		if c.GroupID == nil {
-			continue
-		}
-		countMap[*c.GroupID] = c.Count
-	}
-
-	for _, group := range groups {
-		group.AccountCnt = countMap[group.ID]
+	groups := make([]*models.EmailGroup, len(rows))
+	for i := range rows {
+		group := rows[i].EmailGroup
+		group.AccountCnt = rows[i].AccountCount
+		groups[i] = &group
 	}
 
 	return groups, nil
@@ -817,7 +1113,7 @@ func (s *EmailServiceImpl) GetEmailGroups(ctx context.Context, userID uint) ([]*
 // CreateEmailGroup 创建分组
 func (s *EmailServiceImpl) CreateEmailGroup(ctx context.Context, userID uint, req *CreateEmailGroupRequest) (*models.EmailGroup, error) {
 	if req == nil || strings.TrimSpace(req.Name) == "" {
-		return nil, fmt.Errorf("分组名称不能为空")
+		return nil, fmt.Errorf("group name cannot be empty")
 	}
 
 	if _, err := s.ensureDefaultGroup(ctx, userID); err != nil {
@@ -874,16 +1170,16 @@ func (s *EmailServiceImpl) UpdateEmailGroup(ctx context.Context, userID, groupID
 	}
 
 	if group.IsSystemGroup() {
-		return nil, fmt.Errorf("系统分组不可编辑")
+		return nil, fmt.Errorf("system group cannot be edited")
 	}
 	if group.IsDefault {
-		return nil, fmt.Errorf("默认分组不可编辑")
+		return nil, fmt.Errorf("default group cannot be edited")
 	}
 
 	if req.Name != nil {
 		name := strings.TrimSpace(*req.Name)
 		if name == "" {
-			return nil, fmt.Errorf("分组名称不能为空")
+			return nil, fmt.Errorf("group name cannot be empty")
 		}
 		group.Name = name
 	}
@@ -907,10 +1203,10 @@ func (s *EmailServiceImpl) DeleteEmailGroup(ctx context.Context, userID, groupID
 	}
 
 	if group.IsSystemGroup() {
-		return fmt.Errorf("系统分组不可删除")
+		return fmt.Errorf("system group cannot be deleted")
 	}
 	if group.IsDefault {
-		return fmt.Errorf("默认分组不可删除")
+		return fmt.Errorf("default group cannot be deleted")
 	}
 
 	defaultGroup, err := s.ensureDefaultGroup(ctx, userID)
@@ -960,7 +1256,7 @@ func (s *EmailServiceImpl) ReorderEmailGroups(ctx context.Context, userID uint,
 		if g, ok := groupMap[id]; !ok || g.UserID != userID {
 			return nil, fmt.Errorf("invalid group id: %d", id)
 		} else if g.IsSystemGroup() && !g.IsDefault {
-			return nil, fmt.Errorf("系统分组不可参与排序")
+			return nil, fmt.Errorf("system group cannot be reordered")
 		}
 	}
 
@@ -1048,7 +1344,7 @@ func (s *EmailServiceImpl) SetDefaultEmailGroup(ctx context.Context, userID, gro
 		Where("id = ? AND user_id = ?", groupID, userID).
 		First(&target).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("分组不存在")
+			return nil, fmt.Errorf("group not found")
 		}
 		return nil, err
 	}
@@ -1057,7 +1353,7 @@ func (s *EmailServiceImpl) SetDefaultEmailGroup(ctx context.Context, userID, gro
 		return &target, nil
 	}
 	if target.IsSystemGroup() {
-		return nil, fmt.Errorf("系统占位分组不可设为默认分组")
+		return nil, fmt.Errorf("system placeholder group cannot be the default group")
 	}
 
 	var prevDefault models.EmailGroup
@@ -1160,6 +1456,12 @@ func (s *EmailServiceImpl) configureAccountByProvider(account *models.EmailAccou
 		account.SMTPPort = providerConfig.SMTPPort
 		account.SMTPSecurity = providerConfig.SMTPSecurity
 
+	case "exchange":
+		// Exchange的收件和发件都使用EWS地址，未填写时创建账户前自动发现
+		if req.IMAPHost != "" {
+			providers.SetExchangeEndpoint(account, req.IMAPHost, req.IMAPPort, req.IMAPSecurity)
+		}
+
 	case "custom":
 		// 自定义邮箱允许用户配置服务器设置
 		if req.IMAPHost != "" {
@@ -1225,26 +1527,13 @@ func (s *EmailServiceImpl) SyncFolder(ctx context.Context, accountID uint, folde
 	return fmt.Errorf("sync service not available")
 }
 
-// GetEmails 获取邮件列表
-func (s *EmailServiceImpl) GetEmails(ctx context.Context, userID uint, req *GetEmailsRequest) (*GetEmailsResponse, error) {
-	// 生成缓存键
-	cacheKey := s.generateEmailListCacheKey(userID, req)
-
-	// 尝试从缓存获取
-	if cached, found := s.cacheManager.EmailListCache().Get(cacheKey); found {
-		if response, ok := cached.(*GetEmailsResponse); ok {
-			log.Printf("Cache hit for email list: %s", cacheKey)
-			return response, nil
-		}
-	}
-
-	// 构建查询
-	query := s.db.Model(&models.Email{}).
-		Joins("JOIN email_accounts ON emails.account_id = email_accounts.id").
-		Where("email_accounts.user_id = ?", userID).
+// emailListQuery 按列表请求的过滤条件筛选用户未删除的邮件，不含排序和分页。
+// 按冗余的user_id过滤，无需关联账户表
+func (s *EmailServiceImpl) emailListQuery(ctx context.Context, userID uint, req *GetEmailsRequest) *gorm.DB {
+	query := s.db.WithContext(ctx).Model(&models.Email{}).
+		Where("emails.user_id = ?", userID).
 		Where("emails.is_deleted = ?", false)
 
-	// 添加过滤条件
 	if req.AccountID != nil {
 		query = query.Where("emails.account_id = ?", *req.AccountID)
 	}
@@ -1265,18 +1554,77 @@ func (s *EmailServiceImpl) GetEmails(ctx context.Context, userID uint, req *GetE
 		query = query.Where("emails.is_important = ?", *req.IsImportant)
 	}
 
-	// 搜索查询
+	if req.ImportanceBucket != "" {
+		query = query.Where("emails.importance_bucket = ?", req.ImportanceBucket)
+	}
+
+	if req.IsVIP != nil {
+		query = query.Where("emails.is_vip = ?", *req.IsVIP)
+	}
+
+	if req.FlagColor != "" {
+		query = query.Where("emails.is_starred = ? AND emails.flag_color = ?", true, req.FlagColor)
+	}
+
+	if req.IsFollowUp != nil {
+		query = query.Where("emails.is_follow_up = ?", *req.IsFollowUp)
+	}
+
+	if req.FollowUpDue != nil {
+		query = query.Where("emails.is_follow_up = ? AND emails.follow_up_at <= ?", true, *req.FollowUpDue)
+	}
+
+	if req.IsAnswered != nil {
+		query = query.Where("emails.is_answered = ?", *req.IsAnswered)
+	}
+
+	if req.AwaitingReply {
+		query = query.Where(awaitingReplyCondition, awaitingReplyArgs()...)
+	}
+
+	// 搜索查询（包括私有笔记内容）
 	if req.SearchQuery != "" {
 		searchPattern := "%" + req.SearchQuery + "%"
-		query = query.Where("emails.subject LIKE ? OR emails.from_address LIKE ? OR emails.text_body LIKE ? OR emails.html_body LIKE ?",
-			searchPattern, searchPattern, searchPattern, searchPattern)
+		if emailNotesAvailable(s.db) {
+			query = query.Where("emails.subject LIKE ? OR emails.from_address LIKE ? OR "+emailBodySearchCondition+" OR "+emailNoteSearchCondition,
+				searchPattern, searchPattern, searchPattern, searchPattern, searchPattern)
+		} else {
+			query = query.Where("emails.subject LIKE ? OR emails.from_address LIKE ? OR "+emailBodySearchCondition,
+				searchPattern, searchPattern, searchPattern, searchPattern)
+		}
 	}
+	return query
+}
 
-	// 计算总数
-	var total int64
-	if err := query.Count(&total).Error; err != nil {
-		return nil, fmt.Errorf("failed to count emails: %w", err)
+// emailListSortColumn 把前端排序字段映射为数据库列名，未知字段按日期排序
+func emailListSortColumn(sortBy string) string {
+	switch sortBy {
+	case "subject":
+		return "subject"
+	case "from":
+		return "from_address"
+	case "size":
+		return "size"
+	default:
+		return "date"
 	}
+}
+
+// GetEmails 获取邮件列表
+func (s *EmailServiceImpl) GetEmails(ctx context.Context, userID uint, req *GetEmailsRequest) (*GetEmailsResponse, error) {
+	// 生成缓存键
+	cacheKey := s.generateEmailListCacheKey(userID, req)
+
+	// 尝试从缓存获取
+	if cached, found := s.cacheManager.EmailListCache().Get(cacheKey); found {
+		if response, ok := cached.(*GetEmailsResponse); ok {
+			log.Printf("Cache hit for email list: %s", cacheKey)
+			return response, nil
+		}
+	}
+
+	// 构建查询
+	query := s.emailListQuery(ctx, userID, req)
 
 	// 设置默认值
 	page := req.Page
@@ -1288,42 +1636,56 @@ func (s *EmailServiceImpl) GetEmails(ctx context.Context, userID uint, req *GetE
 		pageSize = 20
 	}
 
-	// 排序字段映射
-	sortBy := req.SortBy
-	if sortBy == "" {
-		sortBy = "date"
+	sortBy := emailListSortColumn(req.SortBy)
+	sortOrder := normalizeSortOrder(req.SortOrder)
+
+	if err := validateEmailGrouping(req, sortBy); err != nil {
+		return nil, err
 	}
+	// 统计分组总数使用只带过滤条件的查询副本
+	filtered := query.Session(&gorm.Session{Initialized: true})
 
-	// 映射前端字段名到数据库字段名
-	switch sortBy {
-	case "date", "received_at":
-		sortBy = "date"
-	case "subject":
-		sortBy = "subject"
-	case "from":
-		sortBy = "from_address"
-	case "size":
-		sortBy = "size"
-	default:
-		sortBy = "date" // 默认按日期排序
+	var cursor *emailCursor
+	if req.Cursor != "" {
+		if req.PinnedFirst {
+			return nil, fmt.Errorf("%w: cursor pagination does not support pinned_first", ErrInvalidEmailCursor)
+		}
+		if sortBy != "date" {
+			return nil, fmt.Errorf("%w: cursor pagination requires sorting by date", ErrInvalidEmailCursor)
+		}
+		decoded, err := decodeEmailCursor(req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		cursor = decoded
 	}
 
-	sortOrder := req.SortOrder
-	if sortOrder == "" {
-		sortOrder = "DESC"
+	// 计算总数（不受游标影响）
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count emails: %w", err)
 	}
 
-	// 分页查询
+	// 分页查询，ID作为第二排序键保证顺序稳定
+	if cursor != nil {
+		query = applyEmailCursor(query, cursor, sortOrder)
+	} else {
+		query = query.Offset((page - 1) * pageSize)
+	}
+	// 列表不读取正文，正文只在详情、导出等接口中加载
+	summaryView := req.View == EmailListViewSummary
+	if summaryView {
+		query = query.Select(emailSummaryColumns)
+	}
 	var emails []*models.Email
-	offset := (page - 1) * pageSize
-	err := query.Order(fmt.Sprintf("emails.%s %s", sortBy, sortOrder)).
-		Limit(pageSize).
-		Offset(offset).
+	err := query.Order(emailListOrder(req, sortBy, sortOrder).orderClause(false)).
+		Limit(pageSize + 1).
 		Find(&emails).Error
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get emails: %w", err)
 	}
+	emails, hasMore, nextCursor := trimEmailPage(emails, pageSize, sortBy == "date" && !req.PinnedFirst)
 
 	// 计算总页数
 	totalPages := int((total + int64(pageSize) - 1) / int64(pageSize))
@@ -1334,10 +1696,29 @@ func (s *EmailServiceImpl) GetEmails(ctx context.Context, userID uint, req *GetE
 		Page:       page,
 		PageSize:   pageSize,
 		TotalPages: totalPages,
+		HasMore:    hasMore,
+		NextCursor: nextCursor,
+	}
+	if cursor != nil {
+		// 游标模式下没有页码
+		response.Page = 0
+	}
+	if req.GroupBy != "" {
+		groups, err := buildEmailGroups(ctx, s.db, filtered, userID, req.GroupBy, sortOrder, emails)
+		if err != nil {
+			return nil, err
+		}
+		response.Groups = groups
+	}
+	if summaryView {
+		response.Summaries = buildEmailSummaries(ctx, s.db, emails)
+		response.Emails = []*models.Email{}
+	} else {
+		fillMissingEmailSummaries(ctx, s.db, emails)
 	}
 
 	// 缓存结果（缓存5分钟）
-	s.cacheManager.EmailListCache().Set(cacheKey, response, 5*time.Minute)
+	s.cacheManager.EmailListCache().SetWithTags(cacheKey, response, 5*time.Minute, cache.EmailListTags(userID, req.AccountID, req.FolderID)...)
 	log.Printf("Cached email list: %s", cacheKey)
 
 	return response, nil
@@ -1348,13 +1729,19 @@ func (s *EmailServiceImpl) GetEmail(ctx context.Context, userID, emailID uint) (
 	var email models.Email
 
 	// 查询邮件，确保用户只能访问自己的邮件
-	err := s.db.WithContext(ctx).
+	query := s.db.WithContext(ctx).
 		Joins("JOIN email_accounts ON emails.account_id = email_accounts.id").
 		Where("emails.id = ? AND email_accounts.user_id = ? AND emails.is_deleted = ?", emailID, userID, false).
 		Preload("Account").
 		Preload("Folder").
 		Preload("Attachments").
-		First(&email).Error
+		Preload("Body")
+	if emailNotesAvailable(s.db) {
+		query = query.Preload("Notes", func(db *gorm.DB) *gorm.DB {
+			return db.Where("user_id = ?", userID).Order("created_at ASC, id ASC")
+		})
+	}
+	err := query.First(&email).Error
 
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -1363,6 +1750,11 @@ func (s *EmailServiceImpl) GetEmail(ctx context.Context, userID, emailID uint) (
 		return nil, fmt.Errorf("failed to get email: %w", err)
 	}
 
+	// 按用户设置去掉追踪像素，避免打开邮件时向发件人暴露
+	if email.HTMLBody != "" && userSettingsOrDefault(ctx, s.db, userID).StripTrackers {
+		email.HTMLBody, email.TrackersRemoved = sanitize.RemoveTrackers(email.HTMLBody)
+	}
+
 	return &email, nil
 }
 
@@ -1374,36 +1766,26 @@ func (s *EmailServiceImpl) SendEmail(ctx context.Context, userID uint, req *Send
 		return fmt.Errorf("invalid account: %w", err)
 	}
 
-	// 创建提供商实例
-	provider, err := s.providerFactory.CreateProviderForAccount(account)
-	if err != nil {
-		return fmt.Errorf("failed to create provider: %w", err)
-	}
-
-	// 设置OAuth2 token更新回调
-	s.setupProviderTokenCallback(provider)
-
-	// 连接到服务器
-	if err := provider.Connect(ctx, account); err != nil {
-		return fmt.Errorf("failed to connect: %w", err)
-	}
-	defer provider.Disconnect()
-
-	// 获取SMTP客户端
-	smtpClient := provider.SMTPClient()
-	if smtpClient == nil {
-		return fmt.Errorf("SMTP client not available")
+	// Markdown正文渲染为HTML和纯文本
+	textBody, htmlBody := req.TextBody, req.HTMLBody
+	if req.MarkdownBody != "" {
+		rendered := RenderMarkdown(req.MarkdownBody, req.CodeHighlight)
+		htmlBody = rendered.HTMLBody
+		if textBody == "" {
+			textBody = rendered.TextBody
+		}
 	}
 
 	// 构建发送邮件消息
 	message := &providers.OutgoingMessage{
 		Subject:  req.Subject,
-		TextBody: req.TextBody,
-		HTMLBody: req.HTMLBody,
+		TextBody: textBody,
+		HTMLBody: htmlBody,
 		To:       req.To,
 		CC:       req.CC,
 		BCC:      req.BCC,
 		Priority: req.Priority,
+		Headers:  req.Headers,
 	}
 
 	// 设置发件人
@@ -1412,13 +1794,27 @@ func (s *EmailServiceImpl) SendEmail(ctx context.Context, userID uint, req *Send
 		Address: account.Email,
 	}
 
+	// 补充Message-ID和客户端名称
+	providers.IdentityForAccount(account).Apply(message, generateEmailID())
+
+	// 设置信封发件人，退信发往该地址
+	envelopeFrom, err := normalizeEnvelopeAddress(req.EnvelopeFrom)
+	if err != nil {
+		return err
+	}
+	message.EnvelopeFrom = envelopeSender(account, &ComposedEmail{From: message.From, EnvelopeFrom: envelopeFrom})
+
 	// 处理附件
 	for _, attachment := range req.Attachments {
+		contentType, err := checkOutgoingAttachment(attachment.Filename, attachment.ContentType, attachment.Content)
+		if err != nil {
+			return err
+		}
 		message.Attachments = append(message.Attachments, &providers.OutgoingAttachment{
 			Filename:    attachment.Filename,
-			ContentType: attachment.ContentType,
+			ContentType: contentType,
 			Content:     bytes.NewReader(attachment.Content),
-			Size:        attachment.Size,
+			Size:        int64(len(attachment.Content)),
 			Disposition: attachment.Disposition,
 			ContentID:   attachment.ContentID,
 		})
@@ -1431,11 +1827,53 @@ func (s *EmailServiceImpl) SendEmail(ctx context.Context, userID uint, req *Send
 		}
 	}
 
+	// 连接服务器前拒绝超过提供商上限的邮件，避免到SMTP DATA阶段才失败
+	sizeLimit := providerMessageSizeLimit(account.Provider)
+	if req.OffloadLargeAttachments {
+		if err := offloadOversizedAttachments(ctx, s.attachmentLinks, userID, message, sizeLimit, req.LinkBaseURL); err != nil {
+			return err
+		}
+	} else if err := checkOutgoingMessageSize(message, sizeLimit); err != nil {
+		return err
+	}
+
+	// 创建提供商实例
+	provider, err := s.providerFactory.CreateProviderForAccount(account)
+	if err != nil {
+		return fmt.Errorf("failed to create provider: %w", err)
+	}
+
+	// 设置OAuth2 token更新回调
+	s.setupProviderTokenCallback(provider)
+
+	// 连接到服务器
+	if err := provider.Connect(ctx, account); err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer provider.Disconnect()
+
+	// 获取SMTP客户端
+	smtpClient := provider.SMTPClient()
+	if smtpClient == nil {
+		return fmt.Errorf("SMTP client not available")
+	}
+
 	// 发送邮件
 	if err := smtpClient.SendEmail(ctx, message); err != nil {
 		return fmt.Errorf("failed to send email: %w", err)
 	}
 
+	// 发送成功后删除对应草稿（包括服务器Drafts文件夹中的副本）
+	if req.DraftID != nil {
+		s.discardSentDraft(ctx, userID, *req.DraftID)
+	}
+
+	if req.ReplyToID != nil {
+		s.recordOutgoingEmailEvent(ctx, userID, *req.ReplyToID, models.EmailEventReplied, req)
+		s.clearReplyLaterOnReply(ctx, userID, *req.ReplyToID)
+		s.markEmailResponded(ctx, userID, *req.ReplyToID, false)
+	}
+
 	// 发布邮件发送事件
 	if s.eventPublisher != nil {
 		sendEvent := sse.NewEmailSendEvent(sse.EventEmailSendCompleted, "", "", userID)
@@ -1453,6 +1891,27 @@ func (s *EmailServiceImpl) SendEmail(ctx context.Context, userID uint, req *Send
 	return nil
 }
 
+// discardSentDraft 删除已发送的草稿，失败只记录日志
+func (s *EmailServiceImpl) discardSentDraft(ctx context.Context, userID, draftID uint) {
+	var draft models.Draft
+	if err := s.db.WithContext(ctx).
+		Where("id = ? AND user_id = ? AND is_template = ?", draftID, userID, false).
+		First(&draft).Error; err != nil {
+		log.Printf("Failed to load sent draft %d: %v", draftID, err)
+		return
+	}
+
+	if s.draftSyncer != nil {
+		if err := s.draftSyncer.RemoveDraft(ctx, &draft); err != nil {
+			log.Printf("Failed to remove sent draft %d from server: %v", draftID, err)
+		}
+	}
+
+	if err := s.db.WithContext(ctx).Delete(&draft).Error; err != nil {
+		log.Printf("Failed to delete sent draft %d: %v", draftID, err)
+	}
+}
+
 func (s *EmailServiceImpl) getEmailForUser(ctx context.Context, userID, emailID uint, includeDeleted bool, preloads ...string) (*models.Email, error) {
 	query := s.db.WithContext(ctx).
 		Joins("JOIN email_accounts ON emails.account_id = email_accounts.id").
@@ -1482,6 +1941,9 @@ func (s *EmailServiceImpl) setEmailReadState(ctx context.Context, userID, emailI
 	if err != nil {
 		return err
 	}
+	if err := s.checkEmailVersion(ctx, email); err != nil {
+		return err
+	}
 
 	if email.IsRead == isRead {
 		return nil
@@ -1534,11 +1996,21 @@ func (s *EmailServiceImpl) setEmailReadState(ctx context.Context, userID, emailI
 		email.MarkAsUnread()
 	}
 
-	if err := s.db.WithContext(ctx).Save(email).Error; err != nil {
+	if err := s.updateEmailColumns(ctx, s.db, email, map[string]interface{}{"is_read": email.IsRead}); err != nil {
+		if errors.Is(err, ErrVersionConflict) {
+			return err
+		}
 		return fmt.Errorf("failed to update email status: %w", err)
 	}
+	recordEmailChanges(ctx, s.changeLog, userID, email.AccountID, models.ChangeActionUpdated, email.ID)
+	readEvent := models.EmailEventRead
+	if !isRead {
+		readEvent = models.EmailEventUnread
+	}
+	recordEmailEvents(ctx, s.db, newEmailEvent(userID, email.AccountID, email.ID, readEvent, models.EmailEventSourceUser))
 
-	if err := s.updateUnreadCounters(ctx, userID, email.AccountID, email.FolderID); err != nil {
+	delta := emailCounterDelta{Unread: unreadDeltaValue}
+	if err := s.adjustEmailCounters(ctx, userID, email.AccountID, delta, folderCounterDelta(email.FolderID, delta)); err != nil {
 		return err
 	}
 
@@ -1567,17 +2039,37 @@ func (s *EmailServiceImpl) setEmailStarState(ctx context.Context, userID, emailI
 	if err != nil {
 		return err
 	}
+	if err := s.checkEmailVersion(ctx, email); err != nil {
+		return err
+	}
 
 	if email.IsStarred == isStarred {
 		return nil
 	}
 
 	email.IsStarred = isStarred
-	if err := s.db.WithContext(ctx).Save(email).Error; err != nil {
+	columns := map[string]interface{}{"is_starred": isStarred}
+	if !isStarred {
+		// 旗标颜色和后续跟进依附于星标，取消星标时一并清除
+		email.FlagColor, email.IsFollowUp, email.FollowUpAt = "", false, nil
+		columns["flag_color"] = ""
+		columns["is_follow_up"] = false
+		columns["follow_up_at"] = nil
+	}
+	if err := s.updateEmailColumns(ctx, s.db, email, columns); err != nil {
+		if errors.Is(err, ErrVersionConflict) {
+			return err
+		}
 		return fmt.Errorf("failed to update email star status: %w", err)
 	}
 
-	s.invalidateEmailListCache(userID)
+	s.invalidateEmailListCache(userID, email.AccountID, email.FolderID)
+	recordEmailChanges(ctx, s.changeLog, userID, email.AccountID, models.ChangeActionUpdated, email.ID)
+	starEvent := models.EmailEventStarred
+	if !isStarred {
+		starEvent = models.EmailEventUnstarred
+	}
+	recordEmailEvents(ctx, s.db, newEmailEvent(userID, email.AccountID, email.ID, starEvent, models.EmailEventSourceUser))
 
 	if s.eventPublisher != nil {
 		event := sse.NewEmailStatusEvent(email.ID, email.AccountID, userID, nil, nil, &email.IsStarred, nil, nil, nil)
@@ -1658,6 +2150,15 @@ func (s *EmailServiceImpl) DeleteEmail(ctx context.Context, userID, emailID uint
 	if email.IsDeleted {
 		return nil
 	}
+	if err := s.checkEmailVersion(ctx, &email); err != nil {
+		return err
+	}
+
+	if held, err := hasHeldEmails(s.db.WithContext(ctx), userID, "emails.id = ?", email.ID); err != nil {
+		return err
+	} else if held {
+		return ErrEmailUnderLegalHold
+	}
 
 	// 先在IMAP服务器上删除邮件
 	if email.Folder != nil && email.UID > 0 {
@@ -1697,12 +2198,22 @@ func (s *EmailServiceImpl) DeleteEmail(ctx context.Context, userID, emailID uint
 		unreadDeltaValue = -1
 	}
 	folderID := email.FolderID
+	trashedAt := time.Now()
 	email.IsDeleted = true
-	if err := s.db.WithContext(ctx).Save(&email).Error; err != nil {
+	email.TrashedAt = &trashedAt
+	if err := s.updateEmailColumns(ctx, s.db, &email, map[string]interface{}{"is_deleted": true, "trashed_at": trashedAt}); err != nil {
+		if errors.Is(err, ErrVersionConflict) {
+			return err
+		}
 		return fmt.Errorf("failed to delete email: %w", err)
 	}
+	recordEmailChanges(ctx, s.changeLog, userID, email.AccountID, models.ChangeActionDeleted, email.ID)
+	deletedEvent := newEmailEvent(userID, email.AccountID, email.ID, models.EmailEventDeleted, models.EmailEventSourceUser)
+	deletedEvent.FromFolderID = folderID
+	recordEmailEvents(ctx, s.db, deletedEvent)
 
-	if err := s.updateUnreadCounters(ctx, userID, email.AccountID, email.FolderID); err != nil {
+	delta := emailCounterDelta{Total: -1, Unread: unreadDeltaValue}
+	if err := s.adjustEmailCounters(ctx, userID, email.AccountID, delta, folderCounterDelta(email.FolderID, delta)); err != nil {
 		return err
 	}
 
@@ -1785,14 +2296,27 @@ func (s *EmailServiceImpl) ToggleEmailImportant(ctx context.Context, userID, ema
 		return fmt.Errorf("failed to find email: %w", err)
 	}
 
+	if err := s.checkEmailVersion(ctx, &email); err != nil {
+		return err
+	}
+
 	// 切换重要状态
 	email.ToggleImportant()
 
-	if err := s.db.Save(&email).Error; err != nil {
+	if err := s.updateEmailColumns(ctx, s.db, &email, map[string]interface{}{"is_important": email.IsImportant}); err != nil {
+		if errors.Is(err, ErrVersionConflict) {
+			return err
+		}
 		return fmt.Errorf("failed to update email important status: %w", err)
 	}
 
-	s.invalidateEmailListCache(userID)
+	s.invalidateEmailListCache(userID, email.AccountID, email.FolderID)
+	recordEmailChanges(ctx, s.changeLog, userID, email.AccountID, models.ChangeActionUpdated, email.ID)
+	importantEvent := models.EmailEventImportant
+	if !email.IsImportant {
+		importantEvent = models.EmailEventUnimportant
+	}
+	recordEmailEvents(ctx, s.db, newEmailEvent(userID, email.AccountID, email.ID, importantEvent, models.EmailEventSourceUser))
 
 	// 发布邮件重要状态变更事件
 	if s.eventPublisher != nil {
@@ -1843,6 +2367,10 @@ func (s *EmailServiceImpl) MoveEmail(ctx context.Context, userID, emailID uint,
 		return fmt.Errorf("failed to find target folder: %w", err)
 	}
 
+	if err := s.checkEmailVersion(ctx, &email); err != nil {
+		return err
+	}
+
 	// 如果已经在目标文件夹，直接返回
 	if email.FolderID != nil && *email.FolderID == targetFolderID {
 		return nil
@@ -1896,16 +2424,29 @@ func (s *EmailServiceImpl) MoveEmail(ctx context.Context, userID, emailID uint,
 	// 更新数据库中的邮件文件夹
 	sourceFolderID := email.FolderID
 	email.FolderID = &targetFolderID
-	if err := s.db.Save(&email).Error; err != nil {
+	if err := s.updateEmailColumns(ctx, s.db, &email, map[string]interface{}{"folder_id": targetFolderID}); err != nil {
+		if errors.Is(err, ErrVersionConflict) {
+			return err
+		}
 		return fmt.Errorf("failed to update email folder in database: %w", err)
 	}
+	recordEmailChanges(ctx, s.changeLog, userID, email.AccountID, models.ChangeActionUpdated, email.ID)
+	movedEvent := newEmailEvent(userID, email.AccountID, email.ID, models.EmailEventMoved, models.EmailEventSourceUser)
+	movedEvent.FromFolderID = sourceFolderID
+	movedEvent.ToFolderID = &targetFolderID
+	recordEmailEvents(ctx, s.db, movedEvent)
 
-	if err := s.updateUnreadCounters(ctx, userID, email.AccountID, sourceFolderID); err != nil {
-		return err
-	}
-
+	// 移动不改变账户计数，只在源和目标文件夹之间转移
 	if sourceFolderID == nil || *sourceFolderID != targetFolderID {
-		if err := s.updateUnreadCounters(ctx, userID, email.AccountID, &targetFolderID); err != nil {
+		moved := emailCounterDelta{Total: 1}
+		if !email.IsRead {
+			moved.Unread = 1
+		}
+		folders := map[uint]emailCounterDelta{targetFolderID: moved}
+		if sourceFolderID != nil {
+			folders[*sourceFolderID] = emailCounterDelta{Total: -moved.Total, Unread: -moved.Unread}
+		}
+		if err := s.adjustEmailCounters(ctx, userID, email.AccountID, emailCounterDelta{}, folders); err != nil {
 			return err
 		}
 	}
@@ -1917,9 +2458,10 @@ func (s *EmailServiceImpl) MoveEmail(ctx context.Context, userID, emailID uint,
 			fmt.Printf("Failed to publish email move event: %v\n", err)
 		}
 
+		locale := userLocale(ctx, s.db, userID)
 		event := sse.NewNotificationEvent(
-			"邮件已移动",
-			fmt.Sprintf("邮件已移动到文件夹: %s", targetFolder.Name),
+			i18n.T(locale, "Email moved"),
+			i18n.T(locale, "Email moved to folder: %s", targetFolder.Name),
 			"info",
 			userID,
 		)
@@ -1943,7 +2485,7 @@ func (s *EmailServiceImpl) GetFolders(ctx context.Context, userID, accountID uin
 	// 从数据库获取文件夹列表
 	var folders []*models.Folder
 	err = s.db.Where("account_id = ?", accountID).
-		Order("type ASC, name ASC").
+		Order("sort_order ASC, type ASC, name ASC").
 		Find(&folders).Error
 
 	if err != nil {
@@ -1961,7 +2503,7 @@ func (s *EmailServiceImpl) GetFolders(ctx context.Context, userID, accountID uin
 
 		// 重新查询文件夹
 		err = s.db.Where("account_id = ?", accountID).
-			Order("type ASC, name ASC").
+			Order("sort_order ASC, type ASC, name ASC").
 			Find(&folders).Error
 
 		if err != nil {
@@ -2085,12 +2627,14 @@ func (s *EmailServiceImpl) CreateFolder(ctx context.Context, userID, accountID u
 		imapClient.DeleteFolder(ctx, folderPath)
 		return nil, fmt.Errorf("failed to save folder to database: %w", err)
 	}
+	recordFolderChanges(ctx, s.changeLog, userID, accountID, &folder.ID)
 
 	// 发布文件夹创建事件
 	if s.eventPublisher != nil {
+		locale := userLocale(ctx, s.db, userID)
 		event := sse.NewNotificationEvent(
-			"文件夹已创建",
-			fmt.Sprintf("文件夹 '%s' 创建成功", folder.DisplayName),
+			i18n.T(locale, "Folder created"),
+			i18n.T(locale, "Folder '%s' created", folder.DisplayName),
 			"success",
 			userID,
 		)
@@ -2110,13 +2654,28 @@ func (s *EmailServiceImpl) UpdateFolder(ctx context.Context, userID, folderID ui
 		return nil, err
 	}
 
-	// 检查是否为系统文件夹（不允许修改）
-	if folder.Type != "custom" {
+	// 检查是否为系统文件夹（不允许重命名或移动）
+	serverChange := req.Name != nil || req.DisplayName != nil || req.ParentID != nil
+	if serverChange && folder.Type != "custom" {
 		return nil, fmt.Errorf("cannot modify system folder")
 	}
 
+	// 修改类型只保存在本地，系统文件夹也可以修改
+	if req.Type != nil {
+		if err := applyFolderTypeOverride(folder, *req.Type); err != nil {
+			return nil, err
+		}
+		if !serverChange {
+			if err := s.db.WithContext(ctx).Model(folder).Select("type", "type_locked").Updates(folder).Error; err != nil {
+				return nil, fmt.Errorf("failed to update folder type: %w", err)
+			}
+			recordFolderChanges(ctx, s.changeLog, userID, folder.AccountID, &folder.ID)
+			return folder, nil
+		}
+	}
+
 	// 如果没有任何更新，直接返回
-	if req.Name == nil && req.DisplayName == nil && req.ParentID == nil {
+	if !serverChange {
 		return folder, nil
 	}
 
@@ -2202,12 +2761,14 @@ func (s *EmailServiceImpl) UpdateFolder(ctx context.Context, userID, folderID ui
 		}
 		return nil, fmt.Errorf("failed to update folder in database: %w", err)
 	}
+	recordFolderChanges(ctx, s.changeLog, userID, folder.AccountID, &folder.ID)
 
 	// 发布文件夹更新事件
 	if s.eventPublisher != nil {
+		locale := userLocale(ctx, s.db, userID)
 		event := sse.NewNotificationEvent(
-			"文件夹已更新",
-			fmt.Sprintf("文件夹 '%s' 更新成功", folder.DisplayName),
+			i18n.T(locale, "Folder updated"),
+			i18n.T(locale, "Folder '%s' updated", folder.DisplayName),
 			"success",
 			userID,
 		)
@@ -2297,12 +2858,14 @@ func (s *EmailServiceImpl) DeleteFolder(ctx context.Context, userID, folderID ui
 		log.Printf("Failed to delete folder from database after server deletion: %v", err)
 		return fmt.Errorf("folder deleted from server but failed to update database: %w", err)
 	}
+	recordFolderChanges(ctx, s.changeLog, userID, folder.AccountID, &folder.ID)
 
 	// 发布文件夹删除事件
 	if s.eventPublisher != nil {
+		locale := userLocale(ctx, s.db, userID)
 		event := sse.NewNotificationEvent(
-			"文件夹已删除",
-			fmt.Sprintf("文件夹 '%s' 删除成功", folder.DisplayName),
+			i18n.T(locale, "Folder deleted"),
+			i18n.T(locale, "Folder '%s' deleted", folder.DisplayName),
 			"success",
 			userID,
 		)
@@ -2356,6 +2919,8 @@ func (s *EmailServiceImpl) MarkFolderAsRead(ctx context.Context, userID, folderI
 	if err != nil {
 		return fmt.Errorf("failed to mark emails as read: %w", err)
 	}
+	recordEmailChanges(ctx, s.changeLog, userID, folder.AccountID, models.ChangeActionUpdated, emailIDsOf(emails)...)
+	recordEmailEvents(ctx, s.db, emailReadEvents(userID, emails)...)
 
 	// 更新未读计数并清理缓存
 	if err := s.updateUnreadCounters(ctx, userID, folder.AccountID, &folderID); err != nil {
@@ -2369,9 +2934,10 @@ func (s *EmailServiceImpl) MarkFolderAsRead(ctx context.Context, userID, folderI
 			log.Printf("Failed to publish folder read state event: %v", err)
 		}
 
+		locale := userLocale(ctx, s.db, userID)
 		notificationEvent := sse.NewNotificationEvent(
-			"文件夹已标记为已读",
-			fmt.Sprintf("文件夹 '%s' 内的 %d 封邮件已标记为已读", folder.DisplayName, len(emails)),
+			i18n.T(locale, "Folder marked as read"),
+			i18n.T(locale, "Marked %[2]d emails in folder '%[1]s' as read", folder.DisplayName, len(emails)),
 			"success",
 			userID,
 		)
@@ -2414,6 +2980,8 @@ func (s *EmailServiceImpl) MarkAccountAsRead(ctx context.Context, userID, accoun
 		Update("is_read", true).Error; err != nil {
 		return fmt.Errorf("failed to mark account emails as read: %w", err)
 	}
+	recordEmailChanges(ctx, s.changeLog, userID, accountID, models.ChangeActionUpdated, emailIDsOf(emails)...)
+	recordEmailEvents(ctx, s.db, emailReadEvents(userID, emails)...)
 
 	// 将账户下所有文件夹的未读计数重置为 0，避免前端显示残留未读
 	if err := s.db.WithContext(ctx).
@@ -2422,6 +2990,14 @@ func (s *EmailServiceImpl) MarkAccountAsRead(ctx context.Context, userID, accoun
 		Update("unread_emails", 0).Error; err != nil {
 		return fmt.Errorf("failed to reset folder unread count: %w", err)
 	}
+	if s.changeLog != nil {
+		var folderIDs []uint
+		if err := s.db.WithContext(ctx).Model(&models.Folder{}).Where("account_id = ?", accountID).Pluck("id", &folderIDs).Error; err == nil {
+			if err := s.changeLog.RecordFolderChanges(ctx, userID, accountID, folderIDs...); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+		}
+	}
 
 	// 更新未读计数并清理缓存
 	if err := s.updateUnreadCounters(ctx, userID, accountID, nil); err != nil {
@@ -2435,9 +3011,10 @@ func (s *EmailServiceImpl) MarkAccountAsRead(ctx context.Context, userID, accoun
 			log.Printf("Failed to publish account read state event: %v", err)
 		}
 
+		locale := userLocale(ctx, s.db, userID)
 		notificationEvent := sse.NewNotificationEvent(
-			"邮箱已标记为已读",
-			fmt.Sprintf("账户 %s 的所有邮件已标记为已读", account.Email),
+			i18n.T(locale, "Mailbox marked as read"),
+			i18n.T(locale, "All emails in account %s marked as read", account.Email),
 			"success",
 			userID,
 		)
@@ -2470,12 +3047,13 @@ type parsedSearchQuery struct {
 	To        string
 	Subject   string
 	Body      string
+	Note      string
 	HasTokens bool
 }
 
-var searchQueryTokenRegexp = regexp.MustCompile(`(?i)\b(from|to|subject|body):`)
+var searchQueryTokenRegexp = regexp.MustCompile(`(?i)\b(from|to|subject|body|note):`)
 
-// 解析搜索语法：from:xxx subject:xxx body:xxx
+// 解析搜索语法：from:xxx subject:xxx body:xxx note:xxx
 func parseSearchQueryTokens(input string) parsedSearchQuery {
 	trimmed := strings.TrimSpace(input)
 	if trimmed == "" {
@@ -2513,6 +3091,8 @@ func parseSearchQueryTokens(input string) parsedSearchQuery {
 			result.Subject = value
 		case "body":
 			result.Body = value
+		case "note":
+			result.Note = value
 		}
 	}
 
@@ -2523,13 +3103,12 @@ func parseSearchQueryTokens(input string) parsedSearchQuery {
 func (s *EmailServiceImpl) SearchEmails(ctx context.Context, userID uint, req *SearchEmailsRequest) (*GetEmailsResponse, error) {
 	// 构建基础查询
 	query := s.db.WithContext(ctx).Model(&models.Email{}).
-		Joins("JOIN email_accounts ON emails.account_id = email_accounts.id").
-		Where("email_accounts.user_id = ?", userID).
+		Where("emails.user_id = ?", userID).
 		Where("emails.is_deleted = ?", false)
 
 	parsedQuery := parseSearchQueryTokens(req.Query)
 	if parsedQuery.HasTokens {
-		hasParsedValue := parsedQuery.FreeText != "" || parsedQuery.From != "" || parsedQuery.To != "" || parsedQuery.Subject != "" || parsedQuery.Body != ""
+		hasParsedValue := parsedQuery.FreeText != "" || parsedQuery.From != "" || parsedQuery.To != "" || parsedQuery.Subject != "" || parsedQuery.Body != "" || parsedQuery.Note != ""
 		if hasParsedValue {
 			if req.From == "" {
 				req.From = parsedQuery.From
@@ -2543,6 +3122,9 @@ func (s *EmailServiceImpl) SearchEmails(ctx context.Context, userID uint, req *S
 			if req.Body == "" {
 				req.Body = parsedQuery.Body
 			}
+			if req.Note == "" {
+				req.Note = parsedQuery.Note
+			}
 			req.Query = parsedQuery.FreeText
 		}
 	}
@@ -2568,11 +3150,61 @@ func (s *EmailServiceImpl) SearchEmails(ctx context.Context, userID uint, req *S
 		query = query.Where("emails.has_attachment = ?", *req.HasAttachment)
 	}
 
-	// 应用搜索条件
+	// 时间范围过滤
+	if req.Since != nil {
+		query = query.Where("emails.date >= ?", *req.Since)
+	}
+
+	if req.Before != nil {
+		query = query.Where("emails.date <= ?", *req.Before)
+	}
+
+	// 应用分页
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	// 语义搜索：不做关键词硬过滤，按向量相似度与关键词命中综合排序
+	if req.Mode == SearchModeSemantic {
+		if req.Cursor != "" {
+			return nil, fmt.Errorf("%w: cursor pagination is not supported for semantic search", ErrInvalidEmailCursor)
+		}
+		return s.semanticSearch(ctx, query, req, page, pageSize)
+	}
+
+	var cursor *emailCursor
+	if req.Cursor != "" {
+		decoded, err := decodeEmailCursor(req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		cursor = decoded
+	}
+
+	// 应用搜索条件（自由文本同时匹配私有笔记）
+	notesAvailable := emailNotesAvailable(s.db)
 	if req.Query != "" {
 		searchTerm := "%" + req.Query + "%"
-		query = query.Where("(emails.subject LIKE ? OR emails.text_body LIKE ? OR emails.html_body LIKE ? OR emails.from_address LIKE ? OR emails.to_addresses LIKE ?)",
-			searchTerm, searchTerm, searchTerm, searchTerm, searchTerm)
+		if notesAvailable {
+			query = query.Where("(emails.subject LIKE ? OR "+emailBodySearchCondition+" OR emails.from_address LIKE ? OR emails.to_addresses LIKE ? OR "+emailNoteSearchCondition+")",
+				searchTerm, searchTerm, searchTerm, searchTerm, searchTerm, searchTerm)
+		} else {
+			query = query.Where("(emails.subject LIKE ? OR "+emailBodySearchCondition+" OR emails.from_address LIKE ? OR emails.to_addresses LIKE ?)",
+				searchTerm, searchTerm, searchTerm, searchTerm, searchTerm)
+		}
+	}
+
+	if req.Note != "" {
+		if notesAvailable {
+			query = query.Where(emailNoteSearchCondition, "%"+req.Note+"%")
+		} else {
+			query = query.Where("1 = 0")
+		}
 	}
 
 	if req.Subject != "" {
@@ -2589,16 +3221,7 @@ func (s *EmailServiceImpl) SearchEmails(ctx context.Context, userID uint, req *S
 
 	if req.Body != "" {
 		bodyTerm := "%" + req.Body + "%"
-		query = query.Where("(emails.text_body LIKE ? OR emails.html_body LIKE ?)", bodyTerm, bodyTerm)
-	}
-
-	// 时间范围过滤
-	if req.Since != nil {
-		query = query.Where("emails.date >= ?", *req.Since)
-	}
-
-	if req.Before != nil {
-		query = query.Where("emails.date <= ?", *req.Before)
+		query = query.Where(emailBodySearchCondition, bodyTerm, bodyTerm)
 	}
 
 	// 计算总数
@@ -2607,49 +3230,50 @@ func (s *EmailServiceImpl) SearchEmails(ctx context.Context, userID uint, req *S
 		return nil, fmt.Errorf("failed to count search results: %w", err)
 	}
 
-	// 应用分页
-	page := req.Page
-	if page < 1 {
-		page = 1
-	}
-	pageSize := req.PageSize
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 20
+	if cursor != nil {
+		query = applyEmailCursor(query, cursor, "DESC")
+	} else {
+		query = query.Offset((page - 1) * pageSize)
 	}
 
-	offset := (page - 1) * pageSize
-
-	// 获取邮件列表
+	// 获取邮件列表（与列表接口一致不加载正文，列表显示 preview）
 	var emails []*models.Email
-	err := query.Order("emails.date DESC").
-		Limit(pageSize).
-		Offset(offset).
+	err := query.Order("emails.date DESC, emails.id DESC").
+		Limit(pageSize + 1).
 		Find(&emails).Error
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to search emails: %w", err)
 	}
+	emails, hasMore, nextCursor := trimEmailPage(emails, pageSize, true)
+	fillMissingEmailSummaries(ctx, s.db, emails)
 
 	// 计算总页数
 	totalPages := int((total + int64(pageSize) - 1) / int64(pageSize))
 
-	return &GetEmailsResponse{
+	response := &GetEmailsResponse{
 		Emails:     emails,
 		Total:      total,
 		Page:       page,
 		PageSize:   pageSize,
 		TotalPages: totalPages,
-	}, nil
+		HasMore:    hasMore,
+		NextCursor: nextCursor,
+	}
+	if cursor != nil {
+		response.Page = 0
+	}
+	return response, nil
 }
 
-// generateEmailListCacheKey 生成邮件列表缓存键
+// generateEmailListCacheKey 生成邮件列表缓存键，范围前缀之后为查询条件的哈希
 func (s *EmailServiceImpl) generateEmailListCacheKey(userID uint, req *GetEmailsRequest) string {
 	// 将请求参数序列化为JSON
 	reqBytes, _ := json.Marshal(req)
 
 	// 生成MD5哈希
-	hash := md5.Sum([]byte(fmt.Sprintf("emails:%d:%s", userID, string(reqBytes))))
-	return hex.EncodeToString(hash[:])
+	hash := md5.Sum(reqBytes)
+	return cache.EmailListKey(userID, req.AccountID, req.FolderID, hex.EncodeToString(hash[:]))
 }
 
 // updateUnreadCounters 更新账户/文件夹的未读计数并清理相关缓存
@@ -2689,28 +3313,28 @@ func (s *EmailServiceImpl) updateUnreadCounters(ctx context.Context, userID, acc
 	}
 
 	// 清理邮件列表缓存，避免返回陈旧数据
-	s.invalidateEmailListCache(userID)
+	s.invalidateEmailListCache(userID, accountID, folderID)
+
+	recordFolderChanges(ctx, s.changeLog, userID, accountID, folderID)
+	recordAccountChange(ctx, s.changeLog, userID, accountID, models.ChangeActionUpdated)
 
 	return nil
 }
 
-// invalidateEmailListCache 使邮件列表缓存失效
-func (s *EmailServiceImpl) invalidateEmailListCache(userID uint) {
-	// 获取所有缓存键
-	keys := s.cacheManager.EmailListCache().Keys()
-
-	// 删除与该用户相关的缓存
-	// 由于我们使用MD5哈希，这里简单地清除所有缓存
-	// 在实际应用中可以通过在缓存键中包含用户ID前缀来优化
-	for _, key := range keys {
-		s.cacheManager.EmailListCache().Delete(key)
-	}
-
-	log.Printf("Invalidated email list cache for user %d", userID)
+// invalidateEmailListCache 使受影响范围内的邮件列表缓存失效，folderID为nil时清理该用户全部列表
+func (s *EmailServiceImpl) invalidateEmailListCache(userID, accountID uint, folderID *uint) {
+	removed := s.cacheManager.InvalidateEmailLists(userID, accountID, folderID)
+	log.Printf("Invalidated %d email list cache entries for user %d", removed, userID)
 }
 
 // ReplyEmail 回复邮件
 func (s *EmailServiceImpl) ReplyEmail(ctx context.Context, userID, emailID uint, req *ReplyEmailRequest) error {
+	// 用户设置为默认回复全部时，未指定收件人的回复按回复全部处理
+	settings := userSettingsOrDefault(ctx, s.db, userID)
+	if settings.ReplyAll && len(req.To) == 0 {
+		return s.ReplyAllEmail(ctx, userID, emailID, req)
+	}
+
 	// 获取原邮件
 	originalEmail, err := s.GetEmail(ctx, userID, emailID)
 	if err != nil {
@@ -2724,17 +3348,12 @@ func (s *EmailServiceImpl) ReplyEmail(ctx context.Context, userID, emailID uint,
 	}
 
 	// 构建回复邮件
-	replySubject := req.Subject
-	if replySubject == "" {
-		replySubject = "Re: " + originalEmail.Subject
-		if !strings.HasPrefix(strings.ToLower(originalEmail.Subject), "re:") {
-			replySubject = "Re: " + originalEmail.Subject
-		} else {
-			replySubject = originalEmail.Subject
-		}
+	subject := req.Subject
+	if subject == "" {
+		subject = replySubject(originalEmail.Subject)
 	}
 
-	// 设置收件人（如果未指定，则回复给原发件人）
+	// 设置收件人（如果未指定，则回复给原邮件的Reply-To或发件人）
 	var toAddresses []*models.EmailAddress
 	if len(req.To) > 0 {
 		for _, addr := range req.To {
@@ -2744,15 +3363,11 @@ func (s *EmailServiceImpl) ReplyEmail(ctx context.Context, userID, emailID uint,
 			})
 		}
 	} else {
-		// 解析原邮件的发件人
-		fromAddr := parseEmailAddress(originalEmail.From)
-		if fromAddr != nil {
-			toAddresses = append(toAddresses, fromAddr)
-		}
+		toAddresses = appendUniqueAddress(toAddresses, replyRecipient(originalEmail))
 	}
 
 	// 构建引用内容
-	quotedBody := s.buildQuotedContent(originalEmail, req.TextBody, req.HTMLBody)
+	quotedBody := s.buildQuotedContent(originalEmail, req.TextBody, req.HTMLBody, settings)
 
 	// 创建发送请求
 	sendReq := &SendEmailRequest{
@@ -2760,10 +3375,11 @@ func (s *EmailServiceImpl) ReplyEmail(ctx context.Context, userID, emailID uint,
 		To:        toAddresses,
 		CC:        convertToEmailAddressPointers(req.CC),
 		BCC:       convertToEmailAddressPointers(req.BCC),
-		Subject:   replySubject,
+		Subject:   subject,
 		TextBody:  quotedBody.TextBody,
 		HTMLBody:  quotedBody.HTMLBody,
 		ReplyToID: &emailID,
+		Headers:   replyHeaders(originalEmail),
 	}
 
 	// 发送邮件
@@ -2773,9 +3389,10 @@ func (s *EmailServiceImpl) ReplyEmail(ctx context.Context, userID, emailID uint,
 
 	// 发布回复事件
 	if s.eventPublisher != nil {
+		locale := userLocale(ctx, s.db, userID)
 		event := sse.NewNotificationEvent(
-			"邮件已回复",
-			fmt.Sprintf("已回复邮件: %s", originalEmail.Subject),
+			i18n.T(locale, "Email replied"),
+			i18n.T(locale, "Replied to email: %s", originalEmail.Subject),
 			"success",
 			userID,
 		)
@@ -2802,53 +3419,43 @@ func (s *EmailServiceImpl) ReplyAllEmail(ctx context.Context, userID, emailID ui
 	}
 
 	// 构建回复邮件主题
-	replySubject := req.Subject
-	if replySubject == "" {
-		if !strings.HasPrefix(strings.ToLower(originalEmail.Subject), "re:") {
-			replySubject = "Re: " + originalEmail.Subject
-		} else {
-			replySubject = originalEmail.Subject
-		}
+	subject := req.Subject
+	if subject == "" {
+		subject = replySubject(originalEmail.Subject)
 	}
 
-	// 获取所有收件人（排除自己的邮箱地址）
+	// 获取所有收件人（排除自己的邮箱地址和发件别名，去除重复地址）
 	var toAddresses []*models.EmailAddress
 	var ccAddresses []*models.EmailAddress
+	ownAddresses := append(account.GetSendAliases(), account.Email)
 
-	// 添加原发件人到收件人
-	fromAddr := parseEmailAddress(originalEmail.From)
-	if fromAddr != nil && !isOwnEmailAddress(fromAddr.Address, account.Email) {
-		toAddresses = append(toAddresses, fromAddr)
-	}
+	// 添加原邮件的Reply-To或发件人到收件人
+	toAddresses = appendUniqueAddress(toAddresses, replyRecipient(originalEmail), ownAddresses...)
 
 	// 添加原收件人到收件人（排除自己）
 	originalToAddresses, _ := parseEmailAddressList(originalEmail.To)
 	for _, addr := range originalToAddresses {
-		if !isOwnEmailAddress(addr.Address, account.Email) {
-			toAddresses = append(toAddresses, addr)
-		}
+		toAddresses = appendUniqueAddress(toAddresses, addr, ownAddresses...)
 	}
 
-	// 添加原抄送人到抄送（排除自己）
-	originalCCAddresses, _ := parseEmailAddressList(originalEmail.CC)
-	for _, addr := range originalCCAddresses {
-		if !isOwnEmailAddress(addr.Address, account.Email) {
-			ccAddresses = append(ccAddresses, addr)
-		}
+	// 如果用户指定了额外的收件人，添加到列表中
+	for _, addr := range req.To {
+		toAddresses = appendUniqueAddress(toAddresses, &models.EmailAddress{
+			Name:    addr.Name,
+			Address: addr.Address,
+		})
 	}
 
-	// 如果用户指定了额外的收件人，添加到列表中
-	if len(req.To) > 0 {
-		for _, addr := range req.To {
-			toAddresses = append(toAddresses, &models.EmailAddress{
-				Name:    addr.Name,
-				Address: addr.Address,
-			})
+	// 添加原抄送人到抄送（排除自己和已在收件人中的地址）
+	originalCCAddresses, _ := parseEmailAddressList(originalEmail.CC)
+	for _, addr := range originalCCAddresses {
+		if addr != nil && !containsAddress(toAddresses, addr.Address) {
+			ccAddresses = appendUniqueAddress(ccAddresses, addr, ownAddresses...)
 		}
 	}
 
 	// 构建引用内容
-	quotedBody := s.buildQuotedContent(originalEmail, req.TextBody, req.HTMLBody)
+	quotedBody := s.buildQuotedContent(originalEmail, req.TextBody, req.HTMLBody, userSettingsOrDefault(ctx, s.db, userID))
 
 	// 创建发送请求
 	sendReq := &SendEmailRequest{
@@ -2856,10 +3463,11 @@ func (s *EmailServiceImpl) ReplyAllEmail(ctx context.Context, userID, emailID ui
 		To:        toAddresses,
 		CC:        ccAddresses,
 		BCC:       convertToEmailAddressPointers(req.BCC),
-		Subject:   replySubject,
+		Subject:   subject,
 		TextBody:  quotedBody.TextBody,
 		HTMLBody:  quotedBody.HTMLBody,
 		ReplyToID: &emailID,
+		Headers:   replyHeaders(originalEmail),
 	}
 
 	// 发送邮件
@@ -2869,9 +3477,10 @@ func (s *EmailServiceImpl) ReplyAllEmail(ctx context.Context, userID, emailID ui
 
 	// 发布回复全部事件
 	if s.eventPublisher != nil {
+		locale := userLocale(ctx, s.db, userID)
 		event := sse.NewNotificationEvent(
-			"邮件已回复全部",
-			fmt.Sprintf("已回复全部: %s", originalEmail.Subject),
+			i18n.T(locale, "Replied to all"),
+			i18n.T(locale, "Replied to all: %s", originalEmail.Subject),
 			"success",
 			userID,
 		)
@@ -2898,24 +3507,24 @@ func (s *EmailServiceImpl) ForwardEmail(ctx context.Context, userID, emailID uin
 	}
 
 	// 构建转发邮件主题
-	forwardSubject := req.Subject
-	if forwardSubject == "" {
-		if !strings.HasPrefix(strings.ToLower(originalEmail.Subject), "fwd:") &&
-			!strings.HasPrefix(strings.ToLower(originalEmail.Subject), "fw:") {
-			forwardSubject = "Fwd: " + originalEmail.Subject
-		} else {
-			forwardSubject = originalEmail.Subject
-		}
+	subject := req.Subject
+	if subject == "" {
+		subject = forwardSubject(originalEmail.Subject)
 	}
 
 	// 构建转发内容
-	forwardedBody := s.buildForwardedContent(originalEmail, req.TextBody, req.HTMLBody)
+	forwardedBody := s.buildForwardedContent(originalEmail, req.TextBody, req.HTMLBody, userSettingsOrDefault(ctx, s.db, userID))
 
 	// 获取原邮件的附件
 	var attachments []*SendEmailAttachment
 	if originalEmail.HasAttachment && len(originalEmail.Attachments) > 0 {
 		// 转换原邮件的附件为发送格式
 		for _, attachment := range originalEmail.Attachments {
+			if attachment.IsBlocked {
+				log.Printf("Skipping blocked attachment %d (%s) when forwarding: %s", attachment.ID, attachment.Filename, attachment.BlockReason)
+				continue
+			}
+
 			// 读取附件内容
 			var content []byte
 			if attachment.IsDownloaded && attachment.StoragePath != "" {
@@ -2948,10 +3557,17 @@ func (s *EmailServiceImpl) ForwardEmail(ctx context.Context, userID, emailID uin
 				}
 			}
 
+			// 同步时可能只检查了文件名，按实际内容再检查一次
+			contentType, err := checkOutgoingAttachment(attachment.Filename, attachment.ContentType, content)
+			if err != nil {
+				log.Printf("Skipping attachment %d when forwarding: %v", attachment.ID, err)
+				continue
+			}
+
 			// 创建SendEmailAttachment
 			sendAttachment := &SendEmailAttachment{
 				Filename:    attachment.Filename,
-				ContentType: attachment.ContentType,
+				ContentType: contentType,
 				Content:     content,
 				Size:        attachment.Size,
 				Disposition: attachment.Disposition,
@@ -2968,22 +3584,26 @@ func (s *EmailServiceImpl) ForwardEmail(ctx context.Context, userID, emailID uin
 		To:          convertToEmailAddressPointers(req.To),
 		CC:          convertToEmailAddressPointers(req.CC),
 		BCC:         convertToEmailAddressPointers(req.BCC),
-		Subject:     forwardSubject,
+		Subject:     subject,
 		TextBody:    forwardedBody.TextBody,
 		HTMLBody:    forwardedBody.HTMLBody,
 		Attachments: attachments,
+		Headers:     forwardHeaders(originalEmail),
 	}
 
 	// 发送邮件
 	if err := s.SendEmail(ctx, userID, sendReq); err != nil {
 		return fmt.Errorf("failed to forward email: %w", err)
 	}
+	s.recordOutgoingEmailEvent(ctx, userID, emailID, models.EmailEventForwarded, sendReq)
+	s.markEmailResponded(ctx, userID, emailID, true)
 
 	// 发布转发事件
 	if s.eventPublisher != nil {
+		locale := userLocale(ctx, s.db, userID)
 		event := sse.NewNotificationEvent(
-			"邮件已转发",
-			fmt.Sprintf("已转发邮件: %s", originalEmail.Subject),
+			i18n.T(locale, "Email forwarded"),
+			i18n.T(locale, "Forwarded email: %s", originalEmail.Subject),
 			"success",
 			userID,
 		)
@@ -3016,9 +3636,10 @@ func (s *EmailServiceImpl) ArchiveEmail(ctx context.Context, userID, emailID uin
 
 	// 发布归档事件
 	if s.eventPublisher != nil {
+		locale := userLocale(ctx, s.db, userID)
 		event := sse.NewNotificationEvent(
-			"邮件已归档",
-			fmt.Sprintf("邮件已归档: %s", email.Subject),
+			i18n.T(locale, "Email archived"),
+			i18n.T(locale, "Email archived: %s", email.Subject),
 			"success",
 			userID,
 		)
@@ -3055,9 +3676,10 @@ func (s *EmailServiceImpl) SyncSpecificFolder(ctx context.Context, userID, folde
 
 	// 发布文件夹同步事件
 	if s.eventPublisher != nil {
+		locale := userLocale(ctx, s.db, userID)
 		event := sse.NewNotificationEvent(
-			"文件夹已同步",
-			fmt.Sprintf("文件夹 '%s' 同步完成", folder.DisplayName),
+			i18n.T(locale, "Folder synced"),
+			i18n.T(locale, "Folder '%s' synced", folder.DisplayName),
 			"success",
 			userID,
 		)
@@ -3132,12 +3754,15 @@ type QuotedContent struct {
 	HTMLBody string
 }
 
-// buildQuotedContent 构建引用内容（用于回复）
-func (s *EmailServiceImpl) buildQuotedContent(originalEmail *models.Email, userText, userHTML string) *QuotedContent {
+// buildQuotedContent 构建引用内容（用于回复），插入用户签名，日期使用用户时区
+func (s *EmailServiceImpl) buildQuotedContent(originalEmail *models.Email, userText, userHTML string, settings *UserSettings) *QuotedContent {
+	userText, userHTML = appendSignature(userText, userHTML, settings.Signature)
+	date := originalEmail.Date.In(settings.Location()).Format("2006-01-02 15:04:05")
+
 	// 构建文本引用
 	textQuote := fmt.Sprintf("\n\n--- Original Message ---\nFrom: %s\nDate: %s\nSubject: %s\n\n%s",
 		originalEmail.From,
-		originalEmail.Date.Format("2006-01-02 15:04:05"),
+		date,
 		originalEmail.Subject,
 		originalEmail.TextBody)
 
@@ -3153,7 +3778,7 @@ func (s *EmailServiceImpl) buildQuotedContent(originalEmail *models.Email, userT
 %s
 </div>`,
 		html.EscapeString(originalEmail.From),
-		originalEmail.Date.Format("2006-01-02 15:04:05"),
+		date,
 		html.EscapeString(originalEmail.Subject),
 		originalEmail.HTMLBody)
 
@@ -3163,14 +3788,30 @@ func (s *EmailServiceImpl) buildQuotedContent(originalEmail *models.Email, userT
 	}
 }
 
-// buildForwardedContent 构建转发内容
-func (s *EmailServiceImpl) buildForwardedContent(originalEmail *models.Email, userText, userHTML string) *QuotedContent {
+// appendSignature 在正文后追加纯文本签名，HTML正文中转义并保留换行
+func appendSignature(text, htmlBody, signature string) (string, string) {
+	if strings.TrimSpace(signature) == "" {
+		return text, htmlBody
+	}
+	text += "\n\n-- \n" + signature
+	if htmlBody != "" {
+		htmlBody += `<br><br><div class="signature">-- <br>` +
+			strings.ReplaceAll(html.EscapeString(signature), "\n", "<br>") + `</div>`
+	}
+	return text, htmlBody
+}
+
+// buildForwardedContent 构建转发内容，插入用户签名，日期使用用户时区
+func (s *EmailServiceImpl) buildForwardedContent(originalEmail *models.Email, userText, userHTML string, settings *UserSettings) *QuotedContent {
+	userText, userHTML = appendSignature(userText, userHTML, settings.Signature)
+	date := originalEmail.Date.In(settings.Location()).Format("2006-01-02 15:04:05")
+
 	// 构建文本转发内容
 	textForward := fmt.Sprintf("%s\n\n--- Forwarded Message ---\nFrom: %s\nTo: %s\nDate: %s\nSubject: %s\n\n%s",
 		userText,
 		originalEmail.From,
 		originalEmail.To,
-		originalEmail.Date.Format("2006-01-02 15:04:05"),
+		date,
 		originalEmail.Subject,
 		originalEmail.TextBody)
 
@@ -3189,7 +3830,7 @@ func (s *EmailServiceImpl) buildForwardedContent(originalEmail *models.Email, us
 		userHTML,
 		html.EscapeString(originalEmail.From),
 		html.EscapeString(originalEmail.To),
-		originalEmail.Date.Format("2006-01-02 15:04:05"),
+		date,
 		html.EscapeString(originalEmail.Subject),
 		originalEmail.HTMLBody)
 
@@ -3277,6 +3918,10 @@ func (s *EmailServiceImpl) loadAttachmentsFromIDs(ctx context.Context, message *
 
 	// 转换为OutgoingAttachment并添加到消息
 	for _, attachment := range attachments {
+		if attachment.IsBlocked {
+			return fmt.Errorf("%w: %s: %s", ErrAttachmentBlocked, attachment.Filename, attachment.BlockReason)
+		}
+
 		// 读取附件文件内容
 		var content io.Reader
 		if attachment.StoragePath != "" {
@@ -3301,6 +3946,11 @@ func (s *EmailServiceImpl) loadAttachmentsFromIDs(ctx context.Context, message *
 				return fmt.Errorf("failed to read attachment file %s (resolved from %s): %w", storagePath, attachment.StoragePath, err)
 			}
 
+			contentType, err := checkOutgoingAttachment(attachment.Filename, attachment.ContentType, fileData)
+			if err != nil {
+				return err
+			}
+			attachment.ContentType = contentType
 			content = bytes.NewReader(fileData)
 		}
 
@@ -0,0 +1,396 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"firemail/internal/config"
+	"firemail/internal/models"
+	"firemail/internal/sanitize"
+
+	"gorm.io/gorm"
+)
+
+// SharedEmailPathPrefix 公开分享页面的路径前缀
+const SharedEmailPathPrefix = "/api/v1/shared/"
+
+var (
+	// ErrEmailShareNotFound 分享不存在、签名无效或已撤销
+	ErrEmailShareNotFound = errors.New("share link not found")
+	// ErrEmailShareExpired 分享链接已过期
+	ErrEmailShareExpired = errors.New("share link expired")
+	// ErrInvalidShareExpiry 有效期超出允许范围
+	ErrInvalidShareExpiry = errors.New("invalid share expiry")
+)
+
+// EmailShareService 邮件分享链接服务接口
+type EmailShareService interface {
+	// CreateShare 为邮件创建签名的公开链接
+	CreateShare(ctx context.Context, userID, emailID uint, req *CreateEmailShareRequest) (*EmailShareLink, error)
+
+	// ListShares 列出邮件的全部分享链接
+	ListShares(ctx context.Context, userID, emailID uint) ([]*EmailShareLink, error)
+
+	// UpdateShare 修改附件访问权限
+	UpdateShare(ctx context.Context, userID, shareID uint, req *UpdateEmailShareRequest) (*EmailShareLink, error)
+
+	// RevokeShare 撤销分享链接
+	RevokeShare(ctx context.Context, userID, shareID uint) error
+
+	// OpenShare 校验公开链接并返回可展示的邮件，同时记录访问
+	OpenShare(ctx context.Context, token string) (*SharedEmail, error)
+
+	// OpenSharedAttachment 通过公开链接读取附件
+	OpenSharedAttachment(ctx context.Context, token string, attachmentID uint) (*models.Attachment, io.ReadCloser, error)
+}
+
+// CreateEmailShareRequest 创建分享链接请求
+type CreateEmailShareRequest struct {
+	ExpiresInHours   int  `json:"expires_in_hours"` // 为0时使用默认有效期
+	AllowAttachments bool `json:"allow_attachments"`
+}
+
+// UpdateEmailShareRequest 修改分享链接请求
+type UpdateEmailShareRequest struct {
+	AllowAttachments *bool `json:"allow_attachments"`
+}
+
+// EmailShareLink 分享链接及其访问地址
+type EmailShareLink struct {
+	*models.EmailShare
+	Active bool   `json:"active"`
+	URL    string `json:"url"`
+}
+
+// SharedEmail 公开链接展示的只读邮件
+type SharedEmail struct {
+	Subject     string                  `json:"subject"`
+	From        string                  `json:"from"`
+	To          string                  `json:"to"`
+	CC          string                  `json:"cc"`
+	Date        time.Time               `json:"date"`
+	HTMLBody    string                  `json:"html_body"` // 已清理的HTML
+	TextBody    string                  `json:"text_body"`
+	Attachments []SharedEmailAttachment `json:"attachments"`
+	ExpiresAt   time.Time               `json:"expires_at"`
+}
+
+// SharedEmailAttachment 公开链接中可下载的附件
+type SharedEmailAttachment struct {
+	ID          uint   `json:"id"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	URL         string `json:"url"`
+}
+
+// EmailShareServiceImpl 邮件分享链接服务实现
+type EmailShareServiceImpl struct {
+	db            *gorm.DB
+	attachments   AttachmentDownloader
+	signingKey    []byte
+	baseURL       string
+	defaultExpiry time.Duration
+	maxExpiry     time.Duration
+	now           func() time.Time
+}
+
+// NewEmailShareService 创建邮件分享链接服务，链接使用secret派生的密钥签名
+func NewEmailShareService(db *gorm.DB, attachments AttachmentDownloader, secret string, cfg config.SharingConfig) EmailShareService {
+	key := sha256.Sum256([]byte("firemail-email-share:" + secret))
+	return &EmailShareServiceImpl{
+		db:            db,
+		attachments:   attachments,
+		signingKey:    key[:],
+		baseURL:       strings.TrimRight(cfg.BaseURL, "/"),
+		defaultExpiry: cfg.DefaultExpiry,
+		maxExpiry:     cfg.MaxExpiry,
+		now:           time.Now,
+	}
+}
+
+// CreateShare 为邮件创建签名的公开链接
+func (s *EmailShareServiceImpl) CreateShare(ctx context.Context, userID, emailID uint, req *CreateEmailShareRequest) (*EmailShareLink, error) {
+	expiry := s.defaultExpiry
+	if req.ExpiresInHours != 0 {
+		expiry = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+	if expiry <= 0 || expiry > s.maxExpiry {
+		return nil, fmt.Errorf("%w: must be between 1 and %d hours", ErrInvalidShareExpiry, int(s.maxExpiry/time.Hour))
+	}
+
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.Email{}).
+		Where("id = ? AND user_id = ? AND is_deleted = ?", emailID, userID, false).
+		Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to check email: %w", err)
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("email not found")
+	}
+
+	share := &models.EmailShare{
+		UserID:           userID,
+		EmailID:          emailID,
+		AllowAttachments: req.AllowAttachments,
+		// 签名只包含秒级时间，存储值与之保持一致
+		ExpiresAt: s.now().Add(expiry).Truncate(time.Second),
+	}
+	if err := s.db.WithContext(ctx).Create(share).Error; err != nil {
+		return nil, fmt.Errorf("failed to create share: %w", err)
+	}
+
+	return s.link(share), nil
+}
+
+// ListShares 列出邮件的全部分享链接，最新创建的在前
+func (s *EmailShareServiceImpl) ListShares(ctx context.Context, userID, emailID uint) ([]*EmailShareLink, error) {
+	var shares []*models.EmailShare
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ? AND email_id = ?", userID, emailID).
+		Order("created_at DESC, id DESC").
+		Find(&shares).Error; err != nil {
+		return nil, fmt.Errorf("failed to list shares: %w", err)
+	}
+
+	links := make([]*EmailShareLink, len(shares))
+	for i, share := range shares {
+		links[i] = s.link(share)
+	}
+	return links, nil
+}
+
+// UpdateShare 修改附件访问权限
+func (s *EmailShareServiceImpl) UpdateShare(ctx context.Context, userID, shareID uint, req *UpdateEmailShareRequest) (*EmailShareLink, error) {
+	share, err := s.getOwnedShare(ctx, userID, shareID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.AllowAttachments != nil {
+		share.AllowAttachments = *req.AllowAttachments
+		if err := s.db.WithContext(ctx).Model(share).
+			Update("allow_attachments", share.AllowAttachments).Error; err != nil {
+			return nil, fmt.Errorf("failed to update share: %w", err)
+		}
+	}
+
+	return s.link(share), nil
+}
+
+// RevokeShare 撤销分享链接，重复撤销保持首次撤销时间
+func (s *EmailShareServiceImpl) RevokeShare(ctx context.Context, userID, shareID uint) error {
+	share, err := s.getOwnedShare(ctx, userID, shareID)
+	if err != nil {
+		return err
+	}
+	if share.RevokedAt != nil {
+		return nil
+	}
+
+	if err := s.db.WithContext(ctx).Model(share).Update("revoked_at", s.now()).Error; err != nil {
+		return fmt.Errorf("failed to revoke share: %w", err)
+	}
+	return nil
+}
+
+// OpenShare 校验公开链接并返回可展示的邮件，同时记录访问
+func (s *EmailShareServiceImpl) OpenShare(ctx context.Context, token string) (*SharedEmail, error) {
+	share, email, err := s.resolve(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	if err := s.db.WithContext(ctx).Model(share).UpdateColumns(map[string]interface{}{
+		"access_count":     gorm.Expr("access_count + 1"),
+		"last_accessed_at": now,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to record share access: %w", err)
+	}
+
+	to, _ := email.GetToAddresses()
+	cc, _ := email.GetCCAddresses()
+	shared := &SharedEmail{
+		Subject:     email.Subject,
+		From:        email.From,
+		To:          joinEmailAddresses(to),
+		CC:          joinEmailAddresses(cc),
+		Date:        email.Date,
+		TextBody:    email.TextBody,
+		Attachments: []SharedEmailAttachment{},
+		ExpiresAt:   share.ExpiresAt,
+	}
+
+	basePath := SharedEmailPathPrefix + token + "/attachments/"
+	if email.HTMLBody != "" {
+		shared.HTMLBody = sanitize.HTML(email.HTMLBody, func(src string) (string, bool) {
+			// 只显示邮件自带的图片，远程图片会向第三方暴露查看者
+			if strings.HasPrefix(strings.ToLower(src), "data:image/") {
+				return src, true
+			}
+			if attachment := findInlineAttachment(email.Attachments, src); attachment != nil {
+				return basePath + strconv.FormatUint(uint64(attachment.ID), 10), true
+			}
+			return "", false
+		})
+	}
+
+	if share.AllowAttachments {
+		for _, attachment := range email.Attachments {
+			if attachment.IsInlineAttachment() {
+				continue
+			}
+			shared.Attachments = append(shared.Attachments, SharedEmailAttachment{
+				ID:          attachment.ID,
+				Filename:    attachment.Filename,
+				ContentType: attachment.ContentType,
+				Size:        attachment.Size,
+				URL:         basePath + strconv.FormatUint(uint64(attachment.ID), 10),
+			})
+		}
+	}
+
+	return shared, nil
+}
+
+// OpenSharedAttachment 通过公开链接读取附件：内联图片随正文始终可见，其他附件需要分享时允许下载
+func (s *EmailShareServiceImpl) OpenSharedAttachment(ctx context.Context, token string, attachmentID uint) (*models.Attachment, io.ReadCloser, error) {
+	share, email, err := s.resolve(ctx, token)
+	if err != nil {
+		return nil, nil, err
+	}
+	if s.attachments == nil {
+		return nil, nil, fmt.Errorf("attachment service not configured")
+	}
+
+	for i := range email.Attachments {
+		attachment := &email.Attachments[i]
+		if attachment.ID != attachmentID {
+			continue
+		}
+		if !attachment.IsInlineAttachment() && !share.AllowAttachments {
+			break
+		}
+		content, err := s.attachments.GetAttachmentContent(ctx, attachment.ID, share.UserID)
+		if err != nil {
+			return nil, nil, err
+		}
+		return attachment, content, nil
+	}
+	return nil, nil, ErrEmailShareNotFound
+}
+
+// resolve 校验签名、撤销和有效期，返回分享及其邮件
+func (s *EmailShareServiceImpl) resolve(ctx context.Context, token string) (*models.EmailShare, *models.Email, error) {
+	shareID, expiresAt, ok := s.verifyToken(token)
+	if !ok {
+		return nil, nil, ErrEmailShareNotFound
+	}
+
+	var share models.EmailShare
+	if err := s.db.WithContext(ctx).First(&share, shareID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrEmailShareNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to load share: %w", err)
+	}
+	if share.RevokedAt != nil || share.ExpiresAt.Unix() != expiresAt {
+		return nil, nil, ErrEmailShareNotFound
+	}
+	if !share.IsActive(s.now()) {
+		return nil, nil, ErrEmailShareExpired
+	}
+
+	// 邮件移入回收站后链接随之失效
+	var email models.Email
+	if err := s.db.WithContext(ctx).
+		Where("id = ? AND user_id = ? AND is_deleted = ?", share.EmailID, share.UserID, false).
+		Preload("Attachments").
+		Preload("Body").
+		First(&email).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrEmailShareNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to load shared email: %w", err)
+	}
+
+	return &share, &email, nil
+}
+
+// getOwnedShare 获取属于用户的分享
+func (s *EmailShareServiceImpl) getOwnedShare(ctx context.Context, userID, shareID uint) (*models.EmailShare, error) {
+	var share models.EmailShare
+	if err := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", shareID, userID).First(&share).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEmailShareNotFound
+		}
+		return nil, fmt.Errorf("failed to get share: %w", err)
+	}
+	return &share, nil
+}
+
+// link 生成分享的访问地址，未配置外部地址时返回相对路径
+func (s *EmailShareServiceImpl) link(share *models.EmailShare) *EmailShareLink {
+	return &EmailShareLink{
+		EmailShare: share,
+		Active:     share.IsActive(s.now()),
+		URL:        s.baseURL + SharedEmailPathPrefix + s.signToken(share.ID, share.ExpiresAt.Unix()),
+	}
+}
+
+// signToken 令牌格式为 <分享ID>.<过期时间戳>.<HMAC签名>
+func (s *EmailShareServiceImpl) signToken(shareID uint, expiresAt int64) string {
+	payload := strconv.FormatUint(uint64(shareID), 10) + "." + strconv.FormatInt(expiresAt, 10)
+	return payload + "." + s.signature(payload)
+}
+
+// verifyToken 校验令牌签名并解析分享ID和过期时间
+func (s *EmailShareServiceImpl) verifyToken(token string) (uint, int64, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, 0, false
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(s.signature(payload))) {
+		return 0, 0, false
+	}
+
+	shareID, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return uint(shareID), expiresAt, true
+}
+
+func (s *EmailShareServiceImpl) signature(payload string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// findInlineAttachment 按cid:引用查找内联附件
+func findInlineAttachment(attachments []models.Attachment, src string) *models.Attachment {
+	if !strings.HasPrefix(strings.ToLower(src), "cid:") {
+		return nil
+	}
+	cid := strings.Trim(src[len("cid:"):], "<>")
+	for i := range attachments {
+		if attachments[i].ContentID != "" && strings.EqualFold(strings.Trim(attachments[i].ContentID, "<>"), cid) {
+			return &attachments[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"firemail/internal/config"
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setupEmailShareTest(t *testing.T) (*emailStateServiceTestEnv, *EmailShareServiceImpl, *models.Email, []models.Attachment) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.EmailShare{}))
+
+	email := env.createEmail(t, env.inbox, 8001, "Quarterly report", false, false)
+	require.NoError(t, models.SaveEmailBody(env.db, email.ID, "hello",
+		`<p onclick="x()">Numbers <img src="cid:chart"></p><script>steal()</script><img src="https://tracker.example/p.gif">`))
+
+	attachments := []models.Attachment{
+		{EmailID: &email.ID, Filename: "chart.png", ContentType: "image/png", ContentID: "<chart>", Disposition: "inline"},
+		{EmailID: &email.ID, Filename: "report.xlsx", ContentType: "application/vnd.ms-excel", Size: 4},
+	}
+	for i := range attachments {
+		require.NoError(t, env.db.Create(&attachments[i]).Error)
+	}
+
+	service := NewEmailShareService(env.db, &memoryAttachments{content: map[uint][]byte{
+		attachments[0].ID: []byte("png"),
+		attachments[1].ID: []byte("xlsx"),
+	}}, "secret", config.SharingConfig{
+		BaseURL:       "https://mail.example.com",
+		DefaultExpiry: 24 * time.Hour,
+		MaxExpiry:     48 * time.Hour,
+	}).(*EmailShareServiceImpl)
+
+	return env, service, email, attachments
+}
+
+// shareToken 从分享地址中取出令牌
+func shareToken(t *testing.T, link *EmailShareLink) string {
+	t.Helper()
+	token := strings.TrimPrefix(link.URL, "https://mail.example.com"+SharedEmailPathPrefix)
+	require.NotEqual(t, link.URL, token)
+	return token
+}
+
+func TestEmailShareLifecycle(t *testing.T) {
+	env, service, email, attachments := setupEmailShareTest(t)
+	ctx := context.Background()
+
+	link, err := service.CreateShare(ctx, env.user.ID, email.ID, &CreateEmailShareRequest{})
+	require.NoError(t, err)
+	require.True(t, link.Active)
+	require.False(t, link.AllowAttachments)
+	token := shareToken(t, link)
+
+	shared, err := service.OpenShare(ctx, token)
+	require.NoError(t, err)
+	require.Equal(t, "Quarterly report", shared.Subject)
+	require.Equal(t, `<p>Numbers <img src="`+SharedEmailPathPrefix+token+`/attachments/`+
+		fmt.Sprint(attachments[0].ID)+`"></p>`, shared.HTMLBody)
+	require.Empty(t, shared.Attachments)
+
+	// 内联图片始终可见，普通附件需要开启下载
+	_, content, err := service.OpenSharedAttachment(ctx, token, attachments[0].ID)
+	require.NoError(t, err)
+	content.Close()
+	_, _, err = service.OpenSharedAttachment(ctx, token, attachments[1].ID)
+	require.ErrorIs(t, err, ErrEmailShareNotFound)
+
+	allow := true
+	_, err = service.UpdateShare(ctx, env.user.ID, link.ID, &UpdateEmailShareRequest{AllowAttachments: &allow})
+	require.NoError(t, err)
+
+	shared, err = service.OpenShare(ctx, token)
+	require.NoError(t, err)
+	require.Len(t, shared.Attachments, 1)
+	require.Equal(t, "report.xlsx", shared.Attachments[0].Filename)
+
+	_, content, err = service.OpenSharedAttachment(ctx, token, attachments[1].ID)
+	require.NoError(t, err)
+	data, err := io.ReadAll(content)
+	require.NoError(t, err)
+	content.Close()
+	require.Equal(t, "xlsx", string(data))
+
+	links, err := service.ListShares(ctx, env.user.ID, email.ID)
+	require.NoError(t, err)
+	require.Len(t, links, 1)
+	require.Equal(t, 2, links[0].AccessCount)
+	require.Equal(t, link.URL, links[0].URL)
+
+	require.NoError(t, service.RevokeShare(ctx, env.user.ID, link.ID))
+	_, err = service.OpenShare(ctx, token)
+	require.ErrorIs(t, err, ErrEmailShareNotFound)
+}
+
+func TestEmailShareRejectsInvalidLinks(t *testing.T) {
+	env, service, email, _ := setupEmailShareTest(t)
+	ctx := context.Background()
+
+	_, err := service.CreateShare(ctx, env.user.ID, email.ID, &CreateEmailShareRequest{ExpiresInHours: 72})
+	require.ErrorIs(t, err, ErrInvalidShareExpiry)
+
+	link, err := service.CreateShare(ctx, env.user.ID, email.ID, &CreateEmailShareRequest{ExpiresInHours: 1})
+	require.NoError(t, err)
+	token := shareToken(t, link)
+
+	// 篡改过期时间会使签名失效
+	parts := strings.Split(token, ".")
+	_, err = service.OpenShare(ctx, parts[0]+".9999999999."+parts[2])
+	require.ErrorIs(t, err, ErrEmailShareNotFound)
+
+	service.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	_, err = service.OpenShare(ctx, token)
+	require.ErrorIs(t, err, ErrEmailShareExpired)
+	service.now = time.Now
+
+	// 邮件移入回收站后链接失效
+	require.NoError(t, env.service.DeleteEmail(ctx, env.user.ID, email.ID))
+	_, err = service.OpenShare(ctx, token)
+	require.ErrorIs(t, err, ErrEmailShareNotFound)
+
+	// 彻底删除邮件时一并删除分享
+	_, err = env.service.PurgeEmails(ctx, env.user.ID, []uint{email.ID})
+	require.NoError(t, err)
+	var remaining int64
+	require.NoError(t, env.db.Unscoped().Model(&models.EmailShare{}).Count(&remaining).Error)
+	require.Zero(t, remaining)
+}
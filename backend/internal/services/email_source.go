@@ -0,0 +1,262 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+)
+
+// 解析邮件结构的限制，防止畸形邮件导致过深递归或过多分段
+const (
+	maxMIMEDepth = 20
+	maxMIMEParts = 500
+)
+
+// EmailHeaderField 邮件头字段，保持原文中的顺序，折行已展开
+type EmailHeaderField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// MIMEPart 邮件MIME结构中的一个分段，PartID与附件记录的part_id一致
+type MIMEPart struct {
+	PartID      string      `json:"part_id"`
+	ContentType string      `json:"content_type"`
+	Charset     string      `json:"charset,omitempty"`
+	Boundary    string      `json:"boundary,omitempty"`
+	Encoding    string      `json:"encoding,omitempty"` // Content-Transfer-Encoding
+	Disposition string      `json:"disposition,omitempty"`
+	Filename    string      `json:"filename,omitempty"`
+	ContentID   string      `json:"content_id,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Size        int64       `json:"size"`  // 编码后的正文字节数
+	Lines       int         `json:"lines"` // 编码后的正文行数
+	Error       string      `json:"error,omitempty"`
+	Children    []*MIMEPart `json:"children,omitempty"`
+}
+
+// EmailSource 邮件原文的邮件头和MIME结构
+type EmailSource struct {
+	EmailID    uint               `json:"email_id"`
+	Size       int64              `json:"size"` // 原文总字节数
+	RawHeaders string             `json:"raw_headers"`
+	Headers    []EmailHeaderField `json:"headers"`
+	Structure  *MIMEPart          `json:"structure"`
+}
+
+// GetEmailSource 从服务器获取邮件原文，返回原始邮件头和解析后的MIME结构，用于排查投递和编码问题
+func (s *EmailServiceImpl) GetEmailSource(ctx context.Context, userID, emailID uint) (*EmailSource, error) {
+	email, err := s.getEmailForUser(ctx, userID, emailID, true, "Account", "Folder")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkEmailSource(email); err != nil {
+		return nil, err
+	}
+
+	session, err := s.openEmailSourceSession(ctx, &email.Account)
+	if err != nil {
+		return nil, err
+	}
+	defer session.close()
+
+	if err := session.selectFolder(ctx, email.Folder.GetFullPath()); err != nil {
+		return nil, err
+	}
+	raw, err := session.client.FetchRawEmail(ctx, email.UID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch email source: %w", err)
+	}
+	defer raw.Close()
+
+	source, err := parseEmailSource(raw)
+	if err != nil {
+		return nil, err
+	}
+	source.EmailID = email.ID
+	return source, nil
+}
+
+// parseEmailSource 流式解析邮件原文，只保留邮件头和结构信息，不保存正文
+func parseEmailSource(raw io.Reader) (*EmailSource, error) {
+	counter := &countingReader{r: raw}
+	reader := bufio.NewReader(counter)
+
+	rawHeaders, headers, err := readHeaderBlock(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse email headers: %w", err)
+	}
+
+	parts := 0
+	structure := parseMIMEPart(reader, headerFieldsToMIME(headers), "1", 0, &parts)
+
+	// 读完剩余内容以统计原文大小
+	if _, err := io.Copy(io.Discard, reader); err != nil {
+		return nil, fmt.Errorf("failed to read email source: %w", err)
+	}
+
+	return &EmailSource{
+		Size:       counter.n,
+		RawHeaders: rawHeaders,
+		Headers:    headers,
+		Structure:  structure,
+	}, nil
+}
+
+// readHeaderBlock 读取到空行为止的邮件头，返回原文和按顺序展开折行后的字段
+func readHeaderBlock(reader *bufio.Reader) (string, []EmailHeaderField, error) {
+	var raw strings.Builder
+	var headers []EmailHeaderField
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", nil, err
+		}
+		trimmed := strings.TrimRight(line, "\r\n")
+		if trimmed == "" {
+			break
+		}
+		raw.WriteString(line)
+
+		if (trimmed[0] == ' ' || trimmed[0] == '\t') && len(headers) > 0 {
+			last := &headers[len(headers)-1]
+			last.Value += " " + strings.TrimSpace(trimmed)
+		} else if colon := strings.IndexByte(trimmed, ':'); colon > 0 {
+			headers = append(headers, EmailHeaderField{
+				Name:  strings.TrimSpace(trimmed[:colon]),
+				Value: strings.TrimSpace(trimmed[colon+1:]),
+			})
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+	return raw.String(), headers, nil
+}
+
+// headerFieldsToMIME 将邮件头字段转换为按名称查找的形式
+func headerFieldsToMIME(fields []EmailHeaderField) textproto.MIMEHeader {
+	header := make(textproto.MIMEHeader, len(fields))
+	for _, field := range fields {
+		header.Add(field.Name, field.Value)
+	}
+	return header
+}
+
+// parseMIMEPart 解析一个分段的结构，multipart分段递归解析子分段，message/rfc822分段解析内嵌邮件
+func parseMIMEPart(body io.Reader, header textproto.MIMEHeader, partID string, depth int, parts *int) *MIMEPart {
+	*parts++
+	part := &MIMEPart{
+		PartID:      partID,
+		ContentType: "text/plain",
+		Encoding:    strings.ToLower(strings.TrimSpace(header.Get("Content-Transfer-Encoding"))),
+		ContentID:   strings.Trim(strings.TrimSpace(header.Get("Content-Id")), "<>"),
+		Description: decodeMIMEWord(header.Get("Content-Description")),
+	}
+
+	var params map[string]string
+	if value := header.Get("Content-Type"); value != "" {
+		mediaType, parsed, err := mime.ParseMediaType(value)
+		if err != nil {
+			part.Error = fmt.Sprintf("invalid Content-Type: %v", err)
+		}
+		if mediaType != "" {
+			part.ContentType = strings.ToLower(mediaType)
+		}
+		params = parsed
+	}
+	part.Charset = params["charset"]
+	part.Filename = decodeMIMEWord(params["name"])
+
+	if value := header.Get("Content-Disposition"); value != "" {
+		disposition, dispositionParams, err := mime.ParseMediaType(value)
+		if err == nil {
+			part.Disposition = strings.ToLower(disposition)
+			if filename := decodeMIMEWord(dispositionParams["filename"]); filename != "" {
+				part.Filename = filename
+			}
+		} else {
+			part.Disposition = strings.ToLower(strings.TrimSpace(strings.SplitN(value, ";", 2)[0]))
+		}
+	}
+
+	counter := &countingReader{r: body}
+	defer func() {
+		// 子分段未读完的部分（结尾分隔符之后的内容等）也计入大小
+		io.Copy(io.Discard, counter)
+		part.Size = counter.n
+		part.Lines = counter.lines
+	}()
+
+	switch {
+	case depth >= maxMIMEDepth || *parts >= maxMIMEParts:
+		if part.Error == "" && (strings.HasPrefix(part.ContentType, "multipart/") || part.ContentType == "message/rfc822") {
+			part.Error = "structure too deep or too many parts, not expanded"
+		}
+	case strings.HasPrefix(part.ContentType, "multipart/"):
+		part.Boundary = params["boundary"]
+		if part.Boundary == "" {
+			part.Error = "multipart without boundary"
+			return part
+		}
+		reader := multipart.NewReader(counter, part.Boundary)
+		for index := 1; ; index++ {
+			child, err := reader.NextRawPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				part.Error = fmt.Sprintf("failed to read part %d: %v", index, err)
+				break
+			}
+			part.Children = append(part.Children, parseMIMEPart(child, child.Header, fmt.Sprintf("%s.%d", partID, index), depth+1, parts))
+			if *parts >= maxMIMEParts {
+				part.Error = "too many parts, remaining parts not expanded"
+				break
+			}
+		}
+	case part.ContentType == "message/rfc822" && (part.Encoding == "" || part.Encoding == "7bit" || part.Encoding == "8bit" || part.Encoding == "binary"):
+		reader := bufio.NewReader(counter)
+		_, headers, err := readHeaderBlock(reader)
+		if err != nil {
+			part.Error = fmt.Sprintf("failed to parse embedded message: %v", err)
+			return part
+		}
+		part.Children = append(part.Children, parseMIMEPart(reader, headerFieldsToMIME(headers), partID+".1", depth+1, parts))
+	}
+	return part
+}
+
+// decodeMIMEWord 解码RFC 2047编码的文字，失败时返回原文
+func decodeMIMEWord(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ""
+	}
+	decoded, err := (&mime.WordDecoder{}).DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+	return decoded
+}
+
+// countingReader 统计读取的字节数和行数
+type countingReader struct {
+	r     io.Reader
+	n     int64
+	lines int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	c.lines += bytes.Count(p[:n], []byte{'\n'})
+	return n, err
+}
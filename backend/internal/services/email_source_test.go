@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const sourceTestMessage = "Received: from mx.example.com\r\n" +
+	"\tby mail.example.org; Mon, 1 Jan 2024 10:00:00 +0000\r\n" +
+	"From: sender@example.com\r\n" +
+	"Subject: =?UTF-8?B?5oql5ZGK?=\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=\"outer\"\r\n" +
+	"\r\n" +
+	"--outer\r\n" +
+	"Content-Type: multipart/alternative; boundary=\"inner\"\r\n" +
+	"\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/plain; charset=gbk\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"hello=20world\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/html; charset=utf-8\r\n" +
+	"\r\n" +
+	"<p>hello</p>\r\n" +
+	"--inner--\r\n" +
+	"--outer\r\n" +
+	"Content-Type: application/pdf; name=\"report.pdf\"\r\n" +
+	"Content-Disposition: attachment; filename=\"=?UTF-8?B?5oql5ZGKLnBkZg==?=\"\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"JVBERi0xLjQK\r\n" +
+	"--outer\r\n" +
+	"Content-Type: message/rfc822\r\n" +
+	"\r\n" +
+	"Subject: forwarded\r\n" +
+	"Content-Type: text/plain\r\n" +
+	"\r\n" +
+	"inner body\r\n" +
+	"--outer--\r\n"
+
+func TestParseEmailSourceStructure(t *testing.T) {
+	source, err := parseEmailSource(strings.NewReader(sourceTestMessage))
+	require.NoError(t, err)
+	require.Equal(t, int64(len(sourceTestMessage)), source.Size)
+	require.True(t, strings.HasPrefix(source.RawHeaders, "Received: from mx.example.com\r\n\tby"))
+
+	require.Equal(t, "Received", source.Headers[0].Name)
+	require.Equal(t, "from mx.example.com by mail.example.org; Mon, 1 Jan 2024 10:00:00 +0000", source.Headers[0].Value)
+	require.Equal(t, "=?UTF-8?B?5oql5ZGK?=", source.Headers[2].Value)
+
+	root := source.Structure
+	require.Equal(t, "1", root.PartID)
+	require.Equal(t, "multipart/mixed", root.ContentType)
+	require.Equal(t, "outer", root.Boundary)
+	require.Len(t, root.Children, 3)
+
+	alternative := root.Children[0]
+	require.Equal(t, "1.1", alternative.PartID)
+	require.Len(t, alternative.Children, 2)
+	plain := alternative.Children[0]
+	require.Equal(t, "1.1.1", plain.PartID)
+	require.Equal(t, "gbk", plain.Charset)
+	require.Equal(t, "quoted-printable", plain.Encoding)
+	require.Equal(t, int64(len("hello=20world")), plain.Size)
+
+	pdf := root.Children[1]
+	require.Equal(t, "1.2", pdf.PartID)
+	require.Equal(t, "attachment", pdf.Disposition)
+	require.Equal(t, "报告.pdf", pdf.Filename)
+	require.Equal(t, "base64", pdf.Encoding)
+
+	forwarded := root.Children[2]
+	require.Equal(t, "message/rfc822", forwarded.ContentType)
+	require.Len(t, forwarded.Children, 1)
+	require.Equal(t, "1.3.1", forwarded.Children[0].PartID)
+	require.Equal(t, "text/plain", forwarded.Children[0].ContentType)
+}
+
+func TestParseEmailSourceReportsBrokenMultipart(t *testing.T) {
+	source, err := parseEmailSource(strings.NewReader("Content-Type: multipart/mixed\r\n\r\nbody\r\n"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart without boundary", source.Structure.Error)
+	require.Equal(t, int64(6), source.Structure.Size)
+}
+
+func TestGetEmailSourceFetchesFromServer(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	email := env.createEmail(t, env.inbox, 4101, "report", true, false)
+	env.provider.imap.rawMessages = map[uint32][]byte{4101: []byte(reparseTestMessage)}
+
+	source, err := env.service.GetEmailSource(context.Background(), env.user.ID, email.ID)
+	require.NoError(t, err)
+	require.Equal(t, email.ID, source.EmailID)
+	require.Len(t, source.Structure.Children, 2)
+	require.Equal(t, "report.pdf", source.Structure.Children[1].Filename)
+
+	_, err = env.service.GetEmailSource(context.Background(), env.user.ID+1, email.ID)
+	require.EqualError(t, err, "email not found")
+}
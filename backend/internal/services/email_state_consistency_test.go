@@ -1,6 +1,7 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -22,9 +23,20 @@ type fakeIMAPClient struct {
 	markReadCalls   [][]uint32
 	markUnreadCalls [][]uint32
 	moveCalls       []fakeMoveCall
+	appendCalls     []fakeAppendCall
+	deleteCalls     [][]uint32
+	searchUIDs      []uint32
+	fetchMessages   []*providers.EmailMessage
+	rawMessages     map[uint32][]byte
 	markReadErr     error
 	markUnreadErr   error
 	moveErr         error
+
+	supportsAnnotations bool
+	annotations         map[uint32]string
+	allowsKeywords      bool
+	storeFlagCalls      []fakeStoreFlagsCall
+	folderStatuses      map[string]*providers.FolderStatus
 }
 
 type fakeMoveCall struct {
@@ -32,6 +44,18 @@ type fakeMoveCall struct {
 	TargetFolder string
 }
 
+type fakeStoreFlagsCall struct {
+	UIDs  []uint32
+	Flags []string
+	Add   bool
+}
+
+type fakeAppendCall struct {
+	Folder string
+	Flags  []string
+	Data   []byte
+}
+
 func (c *fakeIMAPClient) Connect(context.Context, providers.IMAPClientConfig) error { return nil }
 func (c *fakeIMAPClient) Disconnect() error                                         { return nil }
 func (c *fakeIMAPClient) IsConnected() bool                                         { return true }
@@ -40,7 +64,7 @@ func (c *fakeIMAPClient) ListFolders(context.Context) ([]*providers.FolderInfo,
 }
 func (c *fakeIMAPClient) SelectFolder(_ context.Context, folderName string) (*providers.FolderStatus, error) {
 	c.selectedFolders = append(c.selectedFolders, folderName)
-	return &providers.FolderStatus{Name: folderName}, nil
+	return &providers.FolderStatus{Name: folderName, TotalEmails: len(c.fetchMessages)}, nil
 }
 func (c *fakeIMAPClient) CreateFolder(context.Context, string) error { return nil }
 func (c *fakeIMAPClient) DeleteFolder(context.Context, string) error { return nil }
@@ -48,7 +72,7 @@ func (c *fakeIMAPClient) RenameFolder(context.Context, string, string) error {
 	return nil
 }
 func (c *fakeIMAPClient) FetchEmails(context.Context, *providers.FetchCriteria) ([]*providers.EmailMessage, error) {
-	return nil, nil
+	return c.fetchMessages, nil
 }
 func (c *fakeIMAPClient) FetchEmailByUID(context.Context, uint32) (*providers.EmailMessage, error) {
 	return nil, nil
@@ -56,6 +80,13 @@ func (c *fakeIMAPClient) FetchEmailByUID(context.Context, uint32) (*providers.Em
 func (c *fakeIMAPClient) FetchEmailHeaders(context.Context, []uint32) ([]*providers.EmailHeader, error) {
 	return nil, nil
 }
+func (c *fakeIMAPClient) FetchRawEmail(_ context.Context, uid uint32) (io.ReadCloser, error) {
+	raw, ok := c.rawMessages[uid]
+	if !ok {
+		return nil, fmt.Errorf("email with UID %d not found", uid)
+	}
+	return io.NopCloser(bytes.NewReader(raw)), nil
+}
 func (c *fakeIMAPClient) MarkAsRead(_ context.Context, uids []uint32) error {
 	c.markReadCalls = append(c.markReadCalls, append([]uint32(nil), uids...))
 	return c.markReadErr
@@ -64,7 +95,10 @@ func (c *fakeIMAPClient) MarkAsUnread(_ context.Context, uids []uint32) error {
 	c.markUnreadCalls = append(c.markUnreadCalls, append([]uint32(nil), uids...))
 	return c.markUnreadErr
 }
-func (c *fakeIMAPClient) DeleteEmails(context.Context, []uint32) error { return nil }
+func (c *fakeIMAPClient) DeleteEmails(_ context.Context, uids []uint32) error {
+	c.deleteCalls = append(c.deleteCalls, append([]uint32(nil), uids...))
+	return nil
+}
 func (c *fakeIMAPClient) MoveEmails(_ context.Context, uids []uint32, targetFolder string) error {
 	c.moveCalls = append(c.moveCalls, fakeMoveCall{
 		UIDs:         append([]uint32(nil), uids...),
@@ -73,11 +107,15 @@ func (c *fakeIMAPClient) MoveEmails(_ context.Context, uids []uint32, targetFold
 	return c.moveErr
 }
 func (c *fakeIMAPClient) CopyEmails(context.Context, []uint32, string) error { return nil }
+func (c *fakeIMAPClient) AppendMessage(_ context.Context, folderName string, flags []string, _ time.Time, data []byte) error {
+	c.appendCalls = append(c.appendCalls, fakeAppendCall{Folder: folderName, Flags: flags, Data: data})
+	return nil
+}
 func (c *fakeIMAPClient) SearchEmails(context.Context, *providers.SearchCriteria) ([]uint32, error) {
-	return nil, nil
+	return c.searchUIDs, nil
 }
-func (c *fakeIMAPClient) GetFolderStatus(context.Context, string) (*providers.FolderStatus, error) {
-	return nil, nil
+func (c *fakeIMAPClient) GetFolderStatus(_ context.Context, folderName string) (*providers.FolderStatus, error) {
+	return c.folderStatuses[folderName], nil
 }
 func (c *fakeIMAPClient) GetNewEmails(context.Context, string, uint32) ([]*providers.EmailMessage, error) {
 	return nil, nil
@@ -85,6 +123,23 @@ func (c *fakeIMAPClient) GetNewEmails(context.Context, string, uint32) ([]*provi
 func (c *fakeIMAPClient) GetEmailsInUIDRange(context.Context, string, uint32, uint32) ([]*providers.EmailMessage, error) {
 	return nil, nil
 }
+func (c *fakeIMAPClient) SupportsAnnotations() bool { return c.supportsAnnotations }
+func (c *fakeIMAPClient) SetAnnotation(_ context.Context, uid uint32, _ string, value string) error {
+	if c.annotations == nil {
+		c.annotations = make(map[uint32]string)
+	}
+	c.annotations[uid] = value
+	return nil
+}
+func (c *fakeIMAPClient) AllowsKeywords() bool { return c.allowsKeywords }
+func (c *fakeIMAPClient) StoreFlags(_ context.Context, uids []uint32, flags []string, add bool) error {
+	c.storeFlagCalls = append(c.storeFlagCalls, fakeStoreFlagsCall{
+		UIDs:  append([]uint32(nil), uids...),
+		Flags: append([]string(nil), flags...),
+		Add:   add,
+	})
+	return nil
+}
 func (c *fakeIMAPClient) GetAttachment(context.Context, string, uint32, string) (io.ReadCloser, error) {
 	return nil, nil
 }
@@ -155,6 +210,7 @@ func setupEmailStateServiceTestEnv(t *testing.T) *emailStateServiceTestEnv {
 		&models.EmailAccount{},
 		&models.Folder{},
 		&models.Email{},
+		&models.EmailBody{},
 		&models.Attachment{},
 	))
 
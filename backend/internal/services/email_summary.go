@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 邮件列表的返回形式
+const (
+	EmailListViewFull    = "full"    // 完整的邮件模型，正文通过详情接口获取
+	EmailListViewSummary = "summary" // 只返回摘要，不含正文
+)
+
+// emailSummaryColumns 按摘要列出时读取的列
+var emailSummaryColumns = []string{
+	"emails.id", "emails.account_id", "emails.folder_id", "emails.thread_id",
+	"emails.subject", "emails.from_address", "emails.date", "emails.preview",
+	"emails.is_read", "emails.is_starred", "emails.is_important", "emails.is_draft", "emails.is_sent",
+	"emails.is_pinned", "emails.is_vip", "emails.importance_bucket", "emails.priority",
+	"emails.flag_color", "emails.is_follow_up", "emails.follow_up_at", "emails.is_answered", "emails.is_forwarded",
+	"emails.has_attachment", "emails.attachment_count", "emails.size",
+	"emails.sender_initials", "emails.sender_avatar_hash",
+}
+
+// EmailAvatar 发件人头像信息，前端无头像时显示首字母，也可按哈希加载Gravatar
+type EmailAvatar struct {
+	Initials     string `json:"initials"`
+	GravatarHash string `json:"gravatar_hash,omitempty"`
+}
+
+// EmailSummary 邮件列表摘要，用于列表页，不含正文和收件人
+type EmailSummary struct {
+	ID               uint        `json:"id"`
+	AccountID        uint        `json:"account_id"`
+	FolderID         *uint       `json:"folder_id,omitempty"`
+	ThreadID         string      `json:"thread_id"`
+	Subject          string      `json:"subject"`
+	From             string      `json:"from"`
+	Date             time.Time   `json:"date"`
+	Preview          string      `json:"preview"`
+	IsRead           bool        `json:"is_read"`
+	IsStarred        bool        `json:"is_starred"`
+	IsImportant      bool        `json:"is_important"`
+	IsDraft          bool        `json:"is_draft"`
+	IsSent           bool        `json:"is_sent"`
+	IsPinned         bool        `json:"is_pinned"`
+	IsVIP            bool        `json:"is_vip"`
+	IsAnswered       bool        `json:"is_answered"`
+	IsForwarded      bool        `json:"is_forwarded"`
+	ImportanceBucket string      `json:"importance_bucket"`
+	Priority         string      `json:"priority"`
+	FlagColor        string      `json:"flag_color"`
+	IsFollowUp       bool        `json:"is_follow_up"`
+	FollowUpAt       *time.Time  `json:"follow_up_at,omitempty"`
+	HasAttachment    bool        `json:"has_attachment"`
+	AttachmentCount  int         `json:"attachment_count"`
+	Size             int64       `json:"size"`
+	Avatar           EmailAvatar `json:"avatar"`
+}
+
+// newEmailSummary 由只读取了摘要列的邮件生成摘要
+func newEmailSummary(email *models.Email) *EmailSummary {
+	summary := &EmailSummary{
+		ID:               email.ID,
+		AccountID:        email.AccountID,
+		FolderID:         email.FolderID,
+		ThreadID:         email.ThreadID,
+		Subject:          email.Subject,
+		From:             email.From,
+		Date:             email.Date,
+		IsRead:           email.IsRead,
+		IsStarred:        email.IsStarred,
+		IsImportant:      email.IsImportant,
+		IsDraft:          email.IsDraft,
+		IsSent:           email.IsSent,
+		IsPinned:         email.IsPinned,
+		IsVIP:            email.IsVIP,
+		IsAnswered:       email.IsAnswered,
+		IsForwarded:      email.IsForwarded,
+		ImportanceBucket: email.ImportanceBucket,
+		Priority:         email.Priority,
+		FlagColor:        email.FlagColor,
+		IsFollowUp:       email.IsFollowUp,
+		FollowUpAt:       email.FollowUpAt,
+		HasAttachment:    email.HasAttachment,
+		AttachmentCount:  email.AttachmentCount,
+		Size:             email.Size,
+		Avatar: EmailAvatar{
+			Initials:     email.SenderInitials,
+			GravatarHash: email.SenderAvatarHash,
+		},
+	}
+	if email.Preview != nil {
+		summary.Preview = *email.Preview
+	}
+	return summary
+}
+
+// buildEmailSummaries 生成列表摘要，历史邮件尚未生成摘要时即时补齐并保存
+func buildEmailSummaries(ctx context.Context, db *gorm.DB, emails []*models.Email) []*EmailSummary {
+	fillMissingEmailSummaries(ctx, db, emails)
+
+	summaries := make([]*EmailSummary, 0, len(emails))
+	for _, email := range emails {
+		summaries = append(summaries, newEmailSummary(email))
+	}
+	return summaries
+}
+
+// fillMissingEmailSummaries 为尚未生成预览的历史邮件即时补齐并保存摘要列，列表不含正文时客户端显示预览
+func fillMissingEmailSummaries(ctx context.Context, db *gorm.DB, emails []*models.Email) {
+	var missing []uint
+	for _, email := range emails {
+		if email.Preview == nil {
+			missing = append(missing, email.ID)
+		}
+	}
+	if len(missing) > 0 {
+		filled, err := backfillEmailSummaries(ctx, db, missing)
+		if err != nil {
+			log.Printf("Failed to backfill email summaries: %v", err)
+		}
+		for _, email := range emails {
+			if source, ok := filled[email.ID]; ok {
+				email.Preview = source.Preview
+				email.SenderInitials = source.SenderInitials
+				email.SenderAvatarHash = source.SenderAvatarHash
+			}
+		}
+	}
+}
+
+// backfillEmailSummaries 为历史邮件生成并保存列表摘要，返回按ID索引的结果
+func backfillEmailSummaries(ctx context.Context, db *gorm.DB, emailIDs []uint) (map[uint]*models.Email, error) {
+	var emails []*models.Email
+	if err := db.WithContext(ctx).
+		Select("id", "from_address", "attachment_count").
+		Preload("Body").
+		Where("id IN ?", emailIDs).
+		Find(&emails).Error; err != nil {
+		return nil, fmt.Errorf("failed to load emails: %w", err)
+	}
+
+	filled := make(map[uint]*models.Email, len(emails))
+	for _, email := range emails {
+		email.FillSummary()
+		if err := db.WithContext(ctx).Model(&models.Email{}).Where("id = ?", email.ID).Updates(map[string]interface{}{
+			"preview":            *email.Preview,
+			"sender_initials":    email.SenderInitials,
+			"sender_avatar_hash": email.SenderAvatarHash,
+		}).Error; err != nil {
+			return filled, fmt.Errorf("failed to save summary of email %d: %w", email.ID, err)
+		}
+		filled[email.ID] = email
+	}
+	return filled, nil
+}
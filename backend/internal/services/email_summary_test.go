@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEmailsSummaryView(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+
+	// 新邮件创建时生成摘要
+	email := &models.Email{
+		AccountID:       env.account.ID,
+		FolderID:        &env.inbox.ID,
+		MessageID:       "<summary@example.com>",
+		UID:             1,
+		Subject:         "Quarterly report",
+		From:            "Alice Smith <Alice@Example.com>",
+		Date:            time.Now(),
+		HTMLBody:        "<style>p{}</style><p>Numbers are <b>up</b></p>",
+		AttachmentCount: 2,
+		HasAttachment:   true,
+	}
+	require.NoError(t, env.db.Create(email).Error)
+
+	// 历史邮件没有摘要，首次按摘要列出时补齐
+	legacy := &models.Email{
+		AccountID: env.account.ID,
+		FolderID:  &env.inbox.ID,
+		MessageID: "<legacy@example.com>",
+		UID:       2,
+		Subject:   "Old",
+		From:      "张三 <zhang@example.com>",
+		Date:      time.Now().Add(-time.Hour),
+		TextBody:  "See below\n> quoted reply",
+	}
+	require.NoError(t, env.db.Create(legacy).Error)
+	require.NoError(t, env.db.Model(legacy).Update("preview", nil).Error)
+
+	list, err := env.service.GetEmails(ctx, env.user.ID, &GetEmailsRequest{FolderID: &env.inbox.ID, View: EmailListViewSummary})
+	require.NoError(t, err)
+	require.Empty(t, list.Emails)
+	require.Len(t, list.Summaries, 2)
+
+	summary := list.Summaries[0]
+	require.Equal(t, email.ID, summary.ID)
+	require.Equal(t, "Numbers are up", summary.Preview)
+	require.Equal(t, 2, summary.AttachmentCount)
+	require.Equal(t, "AS", summary.Avatar.Initials)
+	require.Equal(t, "c160f8cc69a4f0bf2b0362752353d060", summary.Avatar.GravatarHash)
+
+	require.Equal(t, "See below", list.Summaries[1].Preview)
+	require.Equal(t, "张", list.Summaries[1].Avatar.Initials)
+
+	var stored models.Email
+	require.NoError(t, env.db.First(&stored, legacy.ID).Error)
+	require.NotNil(t, stored.Preview)
+	require.Equal(t, "See below", *stored.Preview)
+}
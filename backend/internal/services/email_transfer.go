@@ -0,0 +1,500 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"firemail/internal/models"
+	"firemail/internal/providers"
+	"firemail/internal/sse"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 跨账户移动/复制任务状态
+const (
+	EmailTransferJobStatusRunning   = "running"
+	EmailTransferJobStatusCompleted = "completed"
+	EmailTransferJobStatusCancelled = "cancelled"
+)
+
+// 不超过该数量的邮件在请求内同步完成，超过时转为后台任务
+const transferInlineLimit = 10
+
+// 跨账户移动/复制每批加载的邮件数量
+const transferBatchSize = 50
+
+// TransferEmailsRequest 将邮件移动或复制到其他账户的文件夹
+type TransferEmailsRequest struct {
+	EmailIDs        []uint `json:"email_ids"`
+	TargetAccountID *uint  `json:"target_account_id,omitempty"` // 可选，用于校验目标文件夹所属账户
+	TargetFolderID  uint   `json:"target_folder_id"`
+	Copy            bool   `json:"copy"` // 为true时保留源邮件
+}
+
+// EmailTransferJob 跨账户移动/复制任务
+type EmailTransferJob struct {
+	ID              string     `json:"id"`
+	Status          string     `json:"status"`
+	Copy            bool       `json:"copy"`
+	TargetAccountID uint       `json:"target_account_id"`
+	TargetFolderID  uint       `json:"target_folder_id"`
+	Total           int        `json:"total"`
+	Processed       int        `json:"processed"`
+	Succeeded       int        `json:"succeeded"`
+	Failed          int        `json:"failed"`
+	LastError       string     `json:"last_error,omitempty"`
+	StartedAt       time.Time  `json:"started_at"`
+	FinishedAt      *time.Time `json:"finished_at,omitempty"`
+
+	userID uint
+	cancel context.CancelFunc
+}
+
+// Finished 任务是否已结束
+func (j *EmailTransferJob) Finished() bool {
+	return j.Status != EmailTransferJobStatusRunning
+}
+
+// emailTransferJobRegistry 保存运行中和已结束的跨账户移动/复制任务
+type emailTransferJobRegistry struct {
+	mutex sync.RWMutex
+	jobs  map[string]*EmailTransferJob
+}
+
+func newEmailTransferJobRegistry() *emailTransferJobRegistry {
+	return &emailTransferJobRegistry{jobs: make(map[string]*EmailTransferJob)}
+}
+
+// snapshot 返回属于该用户的任务副本
+func (r *emailTransferJobRegistry) snapshot(userID uint, jobID string) (*EmailTransferJob, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	job, ok := r.jobs[jobID]
+	if !ok || job.userID != userID {
+		return nil, false
+	}
+	copied := *job
+	copied.cancel = nil
+	return &copied, true
+}
+
+func (r *emailTransferJobRegistry) update(jobID string, fn func(job *EmailTransferJob)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if job, ok := r.jobs[jobID]; ok {
+		fn(job)
+	}
+}
+
+// TransferEmails 将邮件移动或复制到其他账户：从源服务器获取RFC822原文，APPEND到目标文件夹并保留标记和日期。
+// 邮件数量不超过transferInlineLimit时在请求内完成，否则在后台执行并返回运行中的任务
+func (s *EmailServiceImpl) TransferEmails(ctx context.Context, userID uint, req *TransferEmailsRequest) (*EmailTransferJob, error) {
+	if len(req.EmailIDs) == 0 {
+		return nil, fmt.Errorf("no email IDs provided")
+	}
+
+	var targetFolder models.Folder
+	err := s.db.WithContext(ctx).Preload("Account").
+		Joins("JOIN email_accounts ON folders.account_id = email_accounts.id").
+		Where("folders.id = ? AND email_accounts.user_id = ?", req.TargetFolderID, userID).
+		First(&targetFolder).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("target folder not found")
+		}
+		return nil, fmt.Errorf("failed to find target folder: %w", err)
+	}
+	if req.TargetAccountID != nil && *req.TargetAccountID != targetFolder.AccountID {
+		return nil, fmt.Errorf("target folder does not belong to target account")
+	}
+
+	var emailIDs []uint
+	if err := s.db.WithContext(ctx).Model(&models.Email{}).
+		Joins("JOIN email_accounts ON emails.account_id = email_accounts.id").
+		Where("emails.id IN ? AND email_accounts.user_id = ? AND emails.is_deleted = ?", req.EmailIDs, userID, false).
+		Order("emails.account_id, emails.folder_id, emails.uid").
+		Pluck("emails.id", &emailIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to find emails: %w", err)
+	}
+	if len(emailIDs) != len(uniqueUints(req.EmailIDs)) {
+		return nil, fmt.Errorf("email not found")
+	}
+
+	job := &EmailTransferJob{
+		ID:              newEmailTransferJobID(),
+		Status:          EmailTransferJobStatusRunning,
+		Copy:            req.Copy,
+		TargetAccountID: targetFolder.AccountID,
+		TargetFolderID:  targetFolder.ID,
+		Total:           len(emailIDs),
+		StartedAt:       time.Now(),
+		userID:          userID,
+	}
+
+	// 少量邮件随请求完成，请求取消时停止处理；大批量任务与请求生命周期无关
+	jobCtx, cancel := context.WithCancel(ctx)
+	if len(emailIDs) > transferInlineLimit {
+		jobCtx, cancel = context.WithCancel(context.Background())
+	}
+	job.cancel = cancel
+
+	s.transferJobs.mutex.Lock()
+	s.transferJobs.jobs[job.ID] = job
+	s.transferJobs.mutex.Unlock()
+
+	if len(emailIDs) > transferInlineLimit {
+		go s.runTransferJob(jobCtx, job.ID, userID, &targetFolder, req.Copy, emailIDs)
+	} else {
+		s.runTransferJob(jobCtx, job.ID, userID, &targetFolder, req.Copy, emailIDs)
+	}
+
+	snapshot, _ := s.transferJobs.snapshot(userID, job.ID)
+	return snapshot, nil
+}
+
+// GetEmailTransferJob 获取跨账户移动/复制任务状态
+func (s *EmailServiceImpl) GetEmailTransferJob(ctx context.Context, userID uint, jobID string) (*EmailTransferJob, error) {
+	job, ok := s.transferJobs.snapshot(userID, jobID)
+	if !ok {
+		return nil, fmt.Errorf("transfer job not found")
+	}
+	return job, nil
+}
+
+// CancelEmailTransferJob 取消跨账户移动/复制任务，已处理的邮件不会回滚
+func (s *EmailServiceImpl) CancelEmailTransferJob(ctx context.Context, userID uint, jobID string) (*EmailTransferJob, error) {
+	s.transferJobs.mutex.RLock()
+	job, ok := s.transferJobs.jobs[jobID]
+	s.transferJobs.mutex.RUnlock()
+	if !ok || job.userID != userID {
+		return nil, fmt.Errorf("transfer job not found")
+	}
+
+	job.cancel()
+	return s.GetEmailTransferJob(ctx, userID, jobID)
+}
+
+func (s *EmailServiceImpl) runTransferJob(ctx context.Context, jobID string, userID uint, targetFolder *models.Folder, keepSource bool, emailIDs []uint) {
+	defer s.finishTransferJob(ctx, jobID)
+
+	var source, target *emailSourceSession
+	defer func() {
+		if source != nil {
+			source.close()
+		}
+		if target != nil {
+			target.close()
+		}
+	}()
+
+	for start := 0; start < len(emailIDs) && ctx.Err() == nil; start += transferBatchSize {
+		end := start + transferBatchSize
+		if end > len(emailIDs) {
+			end = len(emailIDs)
+		}
+
+		var emails []models.Email
+		if err := s.db.WithContext(ctx).Preload("Account").Preload("Folder").Preload("Attachments").
+			Where("id IN ? AND is_deleted = ?", emailIDs[start:end], false).
+			Order("account_id, folder_id, uid").
+			Find(&emails).Error; err != nil {
+			s.recordTransferResult(jobID, end-start, fmt.Errorf("failed to load emails: %w", err))
+			continue
+		}
+		if missing := end - start - len(emails); missing > 0 {
+			s.recordTransferResult(jobID, missing, fmt.Errorf("%d emails were deleted before transfer", missing))
+		}
+
+		for i := range emails {
+			if ctx.Err() != nil {
+				break
+			}
+			email := &emails[i]
+
+			// 同账户内移动直接使用服务器端MOVE
+			if !keepSource && email.AccountID == targetFolder.AccountID {
+				err := s.MoveEmail(ctx, userID, email.ID, targetFolder.ID)
+				if err != nil {
+					err = fmt.Errorf("email %d: %w", email.ID, err)
+				}
+				s.recordTransferResult(jobID, 1, err)
+				continue
+			}
+
+			if target == nil {
+				opened, err := s.openEmailSourceSession(ctx, &targetFolder.Account)
+				if err != nil {
+					s.recordTransferResult(jobID, 1, fmt.Errorf("target account: %w", err))
+					continue
+				}
+				target = opened
+			}
+			if source == nil || source.accountID != email.AccountID {
+				if source != nil {
+					source.close()
+					source = nil
+				}
+				opened, err := s.openEmailSourceSession(ctx, &email.Account)
+				if err != nil {
+					s.recordTransferResult(jobID, 1, fmt.Errorf("email %d: %w", email.ID, err))
+					continue
+				}
+				source = opened
+			}
+
+			err := s.transferEmail(ctx, userID, source, target, email, targetFolder, keepSource)
+			if err != nil {
+				err = fmt.Errorf("email %d: %w", email.ID, err)
+			}
+			s.recordTransferResult(jobID, 1, err)
+		}
+	}
+}
+
+// transferEmail 复制单封邮件到目标文件夹，移动时在写入成功后删除源服务器上的邮件
+func (s *EmailServiceImpl) transferEmail(ctx context.Context, userID uint, source, target *emailSourceSession, email *models.Email, targetFolder *models.Folder, keepSource bool) error {
+	if err := checkEmailSource(email); err != nil {
+		return err
+	}
+	if err := source.selectFolder(ctx, email.Folder.GetFullPath()); err != nil {
+		return err
+	}
+
+	raw, err := source.client.FetchRawEmail(ctx, email.UID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch email source: %w", err)
+	}
+	data, err := io.ReadAll(raw)
+	raw.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read email source: %w", err)
+	}
+
+	targetPath := targetFolder.GetFullPath()
+	if err := target.client.AppendMessage(ctx, targetPath, transferFlags(email), email.Date, data); err != nil {
+		return fmt.Errorf("failed to append email to target folder: %w", err)
+	}
+	targetUID := locateAppendedEmail(ctx, target, targetPath, email.MessageID)
+
+	var removeErr error
+	if !keepSource {
+		if err := source.client.DeleteEmails(ctx, []uint32{email.UID}); err != nil {
+			// 目标已有副本，本地按复制处理，避免源邮件在数据库中消失
+			removeErr = fmt.Errorf("email copied but failed to remove it from source account: %w", err)
+			keepSource = true
+		}
+	}
+
+	if keepSource {
+		err = s.recordTransferredCopy(ctx, userID, email, targetFolder, targetUID)
+	} else {
+		err = s.recordTransferredMove(ctx, userID, email, targetFolder, targetUID)
+	}
+	if err != nil {
+		return err
+	}
+	return removeErr
+}
+
+// transferFlags 根据本地状态生成APPEND时携带的IMAP标记
+func transferFlags(email *models.Email) []string {
+	var flags []string
+	if email.IsRead {
+		flags = append(flags, "\\Seen")
+	}
+	if email.IsStarred {
+		flags = append(flags, "\\Flagged")
+	}
+	if email.IsDraft {
+		flags = append(flags, "\\Draft")
+	}
+	return flags
+}
+
+// locateAppendedEmail 按Message-ID查找刚写入邮件的UID（服务器不一定支持UIDPLUS），找不到时返回0
+func locateAppendedEmail(ctx context.Context, target *emailSourceSession, folderPath, messageID string) uint32 {
+	if messageID == "" {
+		return 0
+	}
+
+	// SEARCH会切换选中的文件夹
+	target.folder = ""
+	uids, err := target.client.SearchEmails(ctx, &providers.SearchCriteria{
+		FolderName: folderPath,
+		MessageID:  messageID,
+	})
+	if err != nil {
+		log.Printf("Failed to locate transferred email %s: %v", messageID, err)
+		return 0
+	}
+
+	var uid uint32
+	for _, candidate := range uids {
+		if candidate > uid {
+			uid = candidate
+		}
+	}
+	return uid
+}
+
+// recordTransferredMove 将邮件记录改到目标账户，保留ID、附件及本地状态
+func (s *EmailServiceImpl) recordTransferredMove(ctx context.Context, userID uint, email *models.Email, targetFolder *models.Folder, uid uint32) error {
+	now := time.Now()
+	if err := s.db.WithContext(ctx).Model(&models.Email{}).Where("id = ?", email.ID).Updates(map[string]interface{}{
+		"account_id": targetFolder.AccountID,
+		"user_id":    userID,
+		"folder_id":  targetFolder.ID,
+		"uid":        uid,
+		"synced_at":  now,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update email: %w", err)
+	}
+
+	moved := emailCounterDelta{Total: 1}
+	if !email.IsRead {
+		moved.Unread = 1
+	}
+	removed := emailCounterDelta{Total: -moved.Total, Unread: -moved.Unread}
+	sourceFolders := map[uint]emailCounterDelta{}
+	if email.FolderID != nil {
+		sourceFolders[*email.FolderID] = removed
+	}
+	if err := s.adjustEmailCounters(ctx, userID, email.AccountID, removed, sourceFolders); err != nil {
+		return err
+	}
+	if err := s.adjustEmailCounters(ctx, userID, targetFolder.AccountID, moved, map[uint]emailCounterDelta{targetFolder.ID: moved}); err != nil {
+		return err
+	}
+
+	s.invalidateEmailListCache(userID, email.AccountID, email.FolderID)
+	s.invalidateEmailListCache(userID, targetFolder.AccountID, &targetFolder.ID)
+	recordEmailChanges(ctx, s.changeLog, userID, email.AccountID, models.ChangeActionDeleted, email.ID)
+	recordEmailChanges(ctx, s.changeLog, userID, targetFolder.AccountID, models.ChangeActionCreated, email.ID)
+	movedEvent := newEmailEvent(userID, targetFolder.AccountID, email.ID, models.EmailEventMoved, models.EmailEventSourceUser)
+	movedEvent.FromFolderID = email.FolderID
+	movedEvent.ToFolderID = &targetFolder.ID
+	movedEvent.Details = emailEventDetails(map[string]interface{}{"from_account_id": email.AccountID})
+	recordEmailEvents(ctx, s.db, movedEvent)
+
+	if s.eventPublisher != nil {
+		event := sse.NewEmailMovedEvent(email.ID, targetFolder.AccountID, userID, email.FolderID, targetFolder.ID, email.IsRead)
+		if err := s.eventPublisher.PublishToUser(ctx, userID, event); err != nil {
+			log.Printf("Failed to publish email move event: %v", err)
+		}
+	}
+	return nil
+}
+
+// recordTransferredCopy 在目标账户创建邮件副本，附件只复制元数据，内容按需从目标服务器下载
+func (s *EmailServiceImpl) recordTransferredCopy(ctx context.Context, userID uint, email *models.Email, targetFolder *models.Folder, uid uint32) error {
+	now := time.Now()
+	clone := *email
+	clone.BaseModel = models.BaseModel{}
+	clone.AccountID = targetFolder.AccountID
+	clone.UserID = userID
+	clone.FolderID = &targetFolder.ID
+	clone.UID = uid
+	clone.SyncedAt = &now
+	clone.Account = models.EmailAccount{}
+	clone.Folder = nil
+	clone.Attachments = nil
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(&clone).Error; err != nil {
+			return fmt.Errorf("failed to create email copy: %w", err)
+		}
+		for _, attachment := range email.Attachments {
+			attachment.BaseModel = models.BaseModel{}
+			attachment.EmailID = &clone.ID
+			attachment.StoragePath = ""
+			attachment.IsDownloaded = false
+			if err := tx.Omit(clause.Associations).Create(&attachment).Error; err != nil {
+				return fmt.Errorf("failed to copy attachment %s: %w", attachment.Filename, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	added := emailCounterDelta{Total: 1}
+	if !clone.IsRead {
+		added.Unread = 1
+	}
+	if err := s.adjustEmailCounters(ctx, userID, targetFolder.AccountID, added, map[uint]emailCounterDelta{targetFolder.ID: added}); err != nil {
+		return err
+	}
+
+	s.invalidateEmailListCache(userID, targetFolder.AccountID, &targetFolder.ID)
+	recordEmailChanges(ctx, s.changeLog, userID, targetFolder.AccountID, models.ChangeActionCreated, clone.ID)
+	copiedEvent := newEmailEvent(userID, email.AccountID, email.ID, models.EmailEventCopied, models.EmailEventSourceUser)
+	copiedEvent.ToFolderID = &targetFolder.ID
+	copiedEvent.RelatedEmailID = &clone.ID
+	recordEmailEvents(ctx, s.db, copiedEvent)
+
+	if s.eventPublisher != nil {
+		if err := s.eventPublisher.PublishToUser(ctx, userID, sse.NewNewEmailEvent(&clone, userID)); err != nil {
+			log.Printf("Failed to publish new email event: %v", err)
+		}
+	}
+	return nil
+}
+
+func (s *EmailServiceImpl) recordTransferResult(jobID string, count int, err error) {
+	s.transferJobs.update(jobID, func(job *EmailTransferJob) {
+		job.Processed += count
+		if err != nil {
+			job.Failed += count
+			job.LastError = err.Error()
+			return
+		}
+		job.Succeeded += count
+	})
+	if err != nil {
+		log.Printf("Transfer job %s: %v", jobID, err)
+	}
+}
+
+func (s *EmailServiceImpl) finishTransferJob(ctx context.Context, jobID string) {
+	s.transferJobs.update(jobID, func(job *EmailTransferJob) {
+		now := time.Now()
+		job.FinishedAt = &now
+		job.Status = EmailTransferJobStatusCompleted
+		if ctx.Err() != nil && job.Processed < job.Total {
+			job.Status = EmailTransferJobStatusCancelled
+		}
+		job.cancel()
+	})
+}
+
+// uniqueUints 去除重复ID
+func uniqueUints(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+func newEmailTransferJobID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("transfer_%d", time.Now().UnixNano())
+	}
+	return "transfer_" + hex.EncodeToString(buf)
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+// createTransferTarget 为测试用户创建第二个账户及其收件箱
+func createTransferTarget(t *testing.T, env *emailStateServiceTestEnv) (*models.EmailAccount, *models.Folder) {
+	t.Helper()
+
+	account := *env.account
+	account.BaseModel = models.BaseModel{}
+	account.Email = "other@example.com"
+	account.Username = "other@example.com"
+	require.NoError(t, env.db.Create(&account).Error)
+
+	folder := &models.Folder{
+		AccountID:    account.ID,
+		Name:         "Archive",
+		Type:         models.FolderTypeCustom,
+		Path:         "Archive",
+		Delimiter:    "/",
+		IsSelectable: true,
+	}
+	require.NoError(t, env.db.Create(folder).Error)
+	return &account, folder
+}
+
+func TestTransferEmailsMovesAcrossAccounts(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+	targetAccount, targetFolder := createTransferTarget(t, env)
+
+	email := env.createEmail(t, env.inbox, 3001, "cross-move", false, false)
+	require.NoError(t, env.db.Model(email).Update("is_starred", true).Error)
+	require.NoError(t, env.db.Create(&models.Attachment{EmailID: &email.ID, Filename: "a.txt", Size: 1}).Error)
+	require.NoError(t, env.db.Model(env.account).Updates(map[string]interface{}{"total_emails": 1, "unread_emails": 1}).Error)
+
+	imap := env.provider.imap
+	imap.rawMessages = map[uint32][]byte{3001: []byte("Subject: cross-move\r\n\r\nhello")}
+	imap.searchUIDs = []uint32{41, 42}
+
+	job, err := env.service.TransferEmails(ctx, env.user.ID, &TransferEmailsRequest{
+		EmailIDs:        []uint{email.ID},
+		TargetAccountID: &targetAccount.ID,
+		TargetFolderID:  targetFolder.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, EmailTransferJobStatusCompleted, job.Status)
+	require.Equal(t, 1, job.Succeeded, job.LastError)
+
+	require.Len(t, imap.appendCalls, 1)
+	require.Equal(t, "Archive", imap.appendCalls[0].Folder)
+	require.Equal(t, []string{"\\Flagged"}, imap.appendCalls[0].Flags)
+	require.Equal(t, [][]uint32{{3001}}, imap.deleteCalls)
+
+	var stored models.Email
+	require.NoError(t, env.db.Preload("Attachments").First(&stored, email.ID).Error)
+	require.Equal(t, targetAccount.ID, stored.AccountID)
+	require.Equal(t, targetFolder.ID, *stored.FolderID)
+	require.Equal(t, uint32(42), stored.UID)
+	require.Len(t, stored.Attachments, 1)
+
+	var source, target models.EmailAccount
+	require.NoError(t, env.db.First(&source, env.account.ID).Error)
+	require.NoError(t, env.db.First(&target, targetAccount.ID).Error)
+	require.Equal(t, 0, source.TotalEmails)
+	require.Equal(t, 1, target.TotalEmails)
+	require.Equal(t, 1, target.UnreadEmails)
+}
+
+func TestTransferEmailsCopiesInBackgroundForLargeBatches(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+	_, targetFolder := createTransferTarget(t, env)
+
+	imap := env.provider.imap
+	imap.rawMessages = map[uint32][]byte{}
+	var ids []uint
+	for i := 0; i <= transferInlineLimit; i++ {
+		uid := uint32(5000 + i)
+		email := env.createEmail(t, env.inbox, uid, fmt.Sprintf("copy-%d", i), true, false)
+		imap.rawMessages[uid] = []byte("Subject: copy\r\n\r\nbody")
+		ids = append(ids, email.ID)
+	}
+
+	job, err := env.service.TransferEmails(ctx, env.user.ID, &TransferEmailsRequest{
+		EmailIDs:       ids,
+		TargetFolderID: targetFolder.ID,
+		Copy:           true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, len(ids), job.Total)
+
+	require.Eventually(t, func() bool {
+		current, err := env.service.GetEmailTransferJob(ctx, env.user.ID, job.ID)
+		return err == nil && current.Finished()
+	}, 5*time.Second, 10*time.Millisecond)
+
+	current, err := env.service.GetEmailTransferJob(ctx, env.user.ID, job.ID)
+	require.NoError(t, err)
+	require.Equal(t, len(ids), current.Succeeded, current.LastError)
+	require.Empty(t, imap.deleteCalls)
+	for _, call := range imap.appendCalls {
+		require.Equal(t, []string{"\\Seen"}, call.Flags)
+	}
+
+	var copies int64
+	require.NoError(t, env.db.Model(&models.Email{}).Where("folder_id = ?", targetFolder.ID).Count(&copies).Error)
+	require.EqualValues(t, len(ids), copies)
+
+	_, err = env.service.GetEmailTransferJob(ctx, env.user.ID+1, job.ID)
+	require.Error(t, err)
+}
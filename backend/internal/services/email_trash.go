@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"firemail/internal/database/writequeue"
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 邮件删除语义：
+//   - 用户删除的邮件进入回收站（is_deleted=true，trashed_at为删除时间），可以恢复；
+//   - 回收站中的邮件被彻底删除、清空或超过保留期后，连同附件、分享链接和向量索引一起物理删除；
+//   - 删除账户或服务器端UIDVALIDITY变化时本地副本已无意义，直接物理删除，不经过回收站。
+// 邮件不使用gorm的deleted_at软删除。
+
+// TrashListRequest 回收站列表请求
+type TrashListRequest struct {
+	AccountID *uint `json:"account_id"`
+	Page      int   `json:"page"`
+	PageSize  int   `json:"page_size"`
+}
+
+// TrashEmailsRequest 按ID恢复或彻底删除回收站中的邮件
+type TrashEmailsRequest struct {
+	EmailIDs []uint `json:"email_ids" binding:"required,min=1"`
+}
+
+// TrashOperationResponse 回收站操作结果
+type TrashOperationResponse struct {
+	Affected int64 `json:"affected"`
+}
+
+// purgeEmails 物理删除满足条件的邮件及其正文、附件、分享链接和向量索引，受法律保全保护的邮件不删除
+func purgeEmails(tx *gorm.DB, query interface{}, args ...interface{}) (int64, error) {
+	held, heldArgs, err := activeLegalHoldCondition(tx, nil)
+	if err != nil {
+		return 0, err
+	}
+	scope := func(db *gorm.DB) *gorm.DB {
+		db = db.Where(query, args...)
+		if held != "" {
+			db = db.Where("NOT "+held, heldArgs...)
+		}
+		return db
+	}
+
+	emailIDs := tx.Session(&gorm.Session{NewDB: true}).Unscoped().
+		Model(&models.Email{}).
+		Select("id").
+		Scopes(scope)
+
+	if err := tx.Unscoped().Where("email_id IN (?)", emailIDs).Delete(&models.Attachment{}).Error; err != nil {
+		return 0, fmt.Errorf("failed to delete attachments: %w", err)
+	}
+	if tx.Migrator().HasTable(&models.EmailBody{}) {
+		if err := tx.Where("email_id IN (?)", emailIDs).Delete(&models.EmailBody{}).Error; err != nil {
+			return 0, fmt.Errorf("failed to delete email bodies: %w", err)
+		}
+	}
+	if tx.Migrator().HasTable(&models.EmailShare{}) {
+		if err := tx.Unscoped().Where("email_id IN (?)", emailIDs).Delete(&models.EmailShare{}).Error; err != nil {
+			return 0, fmt.Errorf("failed to delete email shares: %w", err)
+		}
+	}
+	if tx.Migrator().HasTable(&models.EmailNote{}) {
+		if err := tx.Unscoped().Where("email_id IN (?)", emailIDs).Delete(&models.EmailNote{}).Error; err != nil {
+			return 0, fmt.Errorf("failed to delete email notes: %w", err)
+		}
+	}
+	if tx.Migrator().HasTable(&models.EmailEvent{}) {
+		if err := tx.Where("email_id IN (?)", emailIDs).Delete(&models.EmailEvent{}).Error; err != nil {
+			return 0, fmt.Errorf("failed to delete email history: %w", err)
+		}
+	}
+	if syncConflictsAvailable(tx) {
+		if err := tx.Where("email_id IN (?)", emailIDs).Delete(&models.SyncConflict{}).Error; err != nil {
+			return 0, fmt.Errorf("failed to delete sync conflicts: %w", err)
+		}
+	}
+	if tx.Migrator().HasTable(&models.EmailEmbedding{}) {
+		if err := tx.Where("email_id IN (?)", emailIDs).Delete(&models.EmailEmbedding{}).Error; err != nil {
+			return 0, fmt.Errorf("failed to delete email embeddings: %w", err)
+		}
+	}
+
+	result := tx.Unscoped().Scopes(scope).Delete(&models.Email{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete emails: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// PurgeExpiredTrashedEmails 物理删除在回收站中超过保留期的邮件
+func PurgeExpiredTrashedEmails(ctx context.Context, db *gorm.DB, cutoff time.Time) (int64, error) {
+	var purged int64
+	err := writequeue.Transaction(ctx, db.WithContext(ctx), func(tx *gorm.DB) error {
+		count, err := purgeEmails(tx, "is_deleted = ? AND trashed_at < ?", true, cutoff)
+		purged = count
+		return err
+	})
+	return purged, err
+}
+
+// trashedEmailsQuery 当前用户回收站中的邮件
+func (s *EmailServiceImpl) trashedEmailsQuery(ctx context.Context, userID uint, accountID *uint) *gorm.DB {
+	query := s.db.WithContext(ctx).Model(&models.Email{}).
+		Where("emails.user_id = ? AND emails.is_deleted = ?", userID, true)
+	if accountID != nil {
+		query = query.Where("emails.account_id = ?", *accountID)
+	}
+	return query
+}
+
+// ListTrashedEmails 回收站列表，按删除时间倒序
+func (s *EmailServiceImpl) ListTrashedEmails(ctx context.Context, userID uint, req *TrashListRequest) (*GetEmailsResponse, error) {
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	query := s.trashedEmailsQuery(ctx, userID, req.AccountID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count trashed emails: %w", err)
+	}
+
+	var emails []*models.Email
+	if err := query.Order("emails.trashed_at DESC, emails.id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize + 1).
+		Find(&emails).Error; err != nil {
+		return nil, fmt.Errorf("failed to list trashed emails: %w", err)
+	}
+	emails, hasMore, _ := trimEmailPage(emails, pageSize, false)
+
+	return &GetEmailsResponse{
+		Emails:     emails,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
+		HasMore:    hasMore,
+	}, nil
+}
+
+// RestoreEmails 把回收站中的邮件恢复到原文件夹，只恢复本地副本
+func (s *EmailServiceImpl) RestoreEmails(ctx context.Context, userID uint, emailIDs []uint) (int64, error) {
+	var emails []*models.Email
+	if err := s.trashedEmailsQuery(ctx, userID, nil).
+		Where("emails.id IN ?", emailIDs).
+		Find(&emails).Error; err != nil {
+		return 0, fmt.Errorf("failed to load trashed emails: %w", err)
+	}
+	if len(emails) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]uint, len(emails))
+	for i, email := range emails {
+		ids[i] = email.ID
+	}
+	result := s.db.WithContext(ctx).Model(&models.Email{}).
+		Where("id IN ? AND is_deleted = ?", ids, true).
+		Updates(map[string]interface{}{"is_deleted": false, "trashed_at": nil})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to restore emails: %w", result.Error)
+	}
+
+	// 恢复的邮件重新计入账户和文件夹计数
+	type counterScope struct {
+		account emailCounterDelta
+		folders map[uint]emailCounterDelta
+		emails  []uint
+	}
+	scopes := make(map[uint]*counterScope)
+	for _, email := range emails {
+		scope, ok := scopes[email.AccountID]
+		if !ok {
+			scope = &counterScope{folders: make(map[uint]emailCounterDelta)}
+			scopes[email.AccountID] = scope
+		}
+		delta := emailCounterDelta{Total: 1}
+		if !email.IsRead {
+			delta.Unread = 1
+		}
+		scope.account.Total += delta.Total
+		scope.account.Unread += delta.Unread
+		if email.FolderID != nil {
+			folder := scope.folders[*email.FolderID]
+			folder.Total += delta.Total
+			folder.Unread += delta.Unread
+			scope.folders[*email.FolderID] = folder
+		}
+		scope.emails = append(scope.emails, email.ID)
+	}
+	for accountID, scope := range scopes {
+		if err := s.adjustEmailCounters(ctx, userID, accountID, scope.account, scope.folders); err != nil {
+			log.Printf("Warning: failed to update counters after restoring emails: %v", err)
+		}
+		recordEmailChanges(ctx, s.changeLog, userID, accountID, models.ChangeActionCreated, scope.emails...)
+		events := make([]*models.EmailEvent, 0, len(scope.emails))
+		for _, emailID := range scope.emails {
+			events = append(events, newEmailEvent(userID, accountID, emailID, models.EmailEventRestored, models.EmailEventSourceUser))
+		}
+		recordEmailEvents(ctx, s.db, events...)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// PurgeEmails 彻底删除回收站中的指定邮件
+func (s *EmailServiceImpl) PurgeEmails(ctx context.Context, userID uint, emailIDs []uint) (int64, error) {
+	var purged int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		count, err := purgeEmails(tx, "user_id = ? AND is_deleted = ? AND id IN ?", userID, true, emailIDs)
+		purged = count
+		return err
+	})
+	return purged, err
+}
+
+// EmptyTrash 清空回收站，accountID为nil时清空全部账户
+func (s *EmailServiceImpl) EmptyTrash(ctx context.Context, userID uint, accountID *uint) (int64, error) {
+	var purged int64
+	err := writequeue.Transaction(ctx, s.db.WithContext(ctx), func(tx *gorm.DB) error {
+		var (
+			count int64
+			err   error
+		)
+		if accountID != nil {
+			count, err = purgeEmails(tx, "user_id = ? AND is_deleted = ? AND account_id = ?", userID, true, *accountID)
+		} else {
+			count, err = purgeEmails(tx, "user_id = ? AND is_deleted = ?", userID, true)
+		}
+		purged = count
+		return err
+	})
+	return purged, err
+}
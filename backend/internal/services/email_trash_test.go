@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrashRestoreAndPurge(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+
+	restored := env.createEmail(t, env.inbox, 6001, "restore me", false, false)
+	purged := env.createEmail(t, env.inbox, 6002, "purge me", true, false)
+	require.NoError(t, env.db.Create(&models.Attachment{EmailID: &purged.ID, Filename: "a.txt"}).Error)
+	require.NoError(t, env.db.Model(env.inbox).Updates(map[string]interface{}{"total_emails": 2, "unread_emails": 1}).Error)
+
+	require.NoError(t, env.service.DeleteEmail(ctx, env.user.ID, restored.ID))
+	require.NoError(t, env.service.DeleteEmail(ctx, env.user.ID, purged.ID))
+
+	trash, err := env.service.ListTrashedEmails(ctx, env.user.ID, &TrashListRequest{})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), trash.Total)
+	for _, email := range trash.Emails {
+		require.NotNil(t, email.TrashedAt)
+	}
+
+	count, err := env.service.RestoreEmails(ctx, env.user.ID, []uint{restored.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+
+	var inbox models.Folder
+	require.NoError(t, env.db.First(&inbox, env.inbox.ID).Error)
+	require.Equal(t, 1, inbox.TotalEmails)
+	require.Equal(t, 1, inbox.UnreadEmails)
+
+	// 不在回收站中的邮件不能被彻底删除
+	count, err = env.service.PurgeEmails(ctx, env.user.ID, []uint{restored.ID, purged.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+
+	var remaining int64
+	require.NoError(t, env.db.Unscoped().Model(&models.Email{}).Where("id = ?", purged.ID).Count(&remaining).Error)
+	require.Zero(t, remaining)
+	require.NoError(t, env.db.Unscoped().Model(&models.Attachment{}).Where("email_id = ?", purged.ID).Count(&remaining).Error)
+	require.Zero(t, remaining)
+
+	var live models.Email
+	require.NoError(t, env.db.First(&live, restored.ID).Error)
+	require.False(t, live.IsDeleted)
+	require.Nil(t, live.TrashedAt)
+}
+
+func TestCleanupPurgesExpiredTrash(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+	require.NoError(t, env.db.AutoMigrate(&models.DraftRevision{}, &models.Draft{}))
+
+	expired := env.createEmail(t, env.inbox, 6101, "expired", true, true)
+	recent := env.createEmail(t, env.inbox, 6102, "recent", true, true)
+	require.NoError(t, env.db.Model(expired).Update("trashed_at", time.Now().AddDate(0, 0, -31)).Error)
+	require.NoError(t, env.db.Model(recent).Update("trashed_at", time.Now().AddDate(0, 0, -1)).Error)
+
+	cleaner := NewSoftDeleteService(env.db)
+	require.NoError(t, cleaner.CleanupExpiredSoftDeletes(ctx, 30))
+
+	var ids []uint
+	require.NoError(t, env.db.Unscoped().Model(&models.Email{}).Pluck("id", &ids).Error)
+	require.Equal(t, []uint{recent.ID}, ids)
+
+	emptied, err := env.service.EmptyTrash(ctx, env.user.ID, nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), emptied)
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"firemail/internal/config"
+)
+
+// embeddingErrorBodyLimit 接口出错时读取的响应正文长度上限
+const embeddingErrorBodyLimit = 1024
+
+// EmbeddingModel 向量模型接口，把文本编码为语义向量
+type EmbeddingModel interface {
+	// Name 模型名称，保存在索引中，更换模型后旧向量不再参与搜索
+	Name() string
+	// Embed 按输入顺序返回每段文本的向量
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// httpEmbeddingModel 通过 OpenAI 兼容的 /embeddings 接口生成向量，Ollama、vLLM 等均支持该格式
+type httpEmbeddingModel struct {
+	url    string
+	model  string
+	apiKey string
+	client *http.Client
+}
+
+// NewHTTPEmbeddingModel 创建基于HTTP接口的向量模型，未配置接口地址时返回nil
+func NewHTTPEmbeddingModel(cfg config.SemanticSearchConfig) EmbeddingModel {
+	if !cfg.Enabled() {
+		return nil
+	}
+	return &httpEmbeddingModel{
+		url:    cfg.EmbeddingURL,
+		model:  cfg.EmbeddingModel,
+		apiKey: cfg.APIKey,
+		client: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// embeddingRequest /embeddings 请求体
+type embeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// embeddingResponse /embeddings 响应体
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Name 模型名称
+func (m *httpEmbeddingModel) Name() string {
+	return m.model
+}
+
+// Embed 批量生成向量，返回的向量已归一化
+func (m *httpEmbeddingModel) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	body, err := json.Marshal(embeddingRequest{Model: m.model, Input: texts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode embedding request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embedding request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if m.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+m.apiKey)
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("embedding request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, embeddingErrorBodyLimit))
+		return nil, fmt.Errorf("embedding request failed with status %d: %s", resp.StatusCode, bytes.TrimSpace(detail))
+	}
+
+	var result embeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode embedding response: %w", err)
+	}
+	if len(result.Data) != len(texts) {
+		return nil, fmt.Errorf("embedding response has %d vectors for %d inputs", len(result.Data), len(texts))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, item := range result.Data {
+		if item.Index < 0 || item.Index >= len(texts) || len(item.Embedding) == 0 {
+			return nil, fmt.Errorf("invalid embedding at index %d", item.Index)
+		}
+		normalizeVector(item.Embedding)
+		vectors[item.Index] = item.Embedding
+	}
+	for i, vector := range vectors {
+		if vector == nil {
+			return nil, fmt.Errorf("embedding response is missing index %d", i)
+		}
+	}
+	return vectors, nil
+}
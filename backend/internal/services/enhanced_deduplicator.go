@@ -261,6 +261,7 @@ func (d *EnhancedStandardDeduplicator) processCrossFolderDuplicateGroup(ctx cont
 	var emails []models.Email
 	err := d.db.WithContext(ctx).
 		Where("account_id = ? AND message_id = ?", accountID, messageID).
+		Preload("Body").
 		Order("created_at ASC").
 		Find(&emails).Error
 
@@ -317,7 +318,7 @@ func (d *EnhancedStandardDeduplicator) mergeDuplicateEmails(ctx context.Context,
 
 	// 保存更新
 	if updated {
-		err := d.db.WithContext(ctx).Save(primary).Error
+		err := saveEmailBody(d.db.WithContext(ctx), primary.ID, primary.TextBody, primary.HTMLBody)
 		if err != nil {
 			return err
 		}
@@ -433,6 +434,7 @@ func (d *EnhancedStandardDeduplicator) cleanupDuplicateGroup(ctx context.Context
 	var emails []models.Email
 	err := d.db.WithContext(ctx).
 		Where("account_id = ? AND message_id = ?", accountID, messageID).
+		Preload("Body").
 		Order("created_at ASC").
 		Find(&emails).Error
 
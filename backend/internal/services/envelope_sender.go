@@ -0,0 +1,81 @@
+package services
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"firemail/internal/models"
+)
+
+// verpDelimiter VERP地址中退信地址本地部分与收件人之间的分隔符
+const verpDelimiter = "+"
+
+// normalizeEnvelopeAddress 校验信封地址，只接受不带显示名的纯地址
+func normalizeEnvelopeAddress(address string) (string, error) {
+	address = strings.TrimSpace(address)
+	if address == "" {
+		return "", nil
+	}
+	parsed, err := mail.ParseAddress(address)
+	if err != nil || parsed.Address != address || strings.ContainsAny(address, "<>\r\n") {
+		return "", fmt.Errorf("invalid envelope address: %s", address)
+	}
+	return address, nil
+}
+
+// applyBounceAddress 校验并设置账户的退信地址，空字符串表示使用发件人地址
+func applyBounceAddress(account *models.EmailAccount, address string) error {
+	normalized, err := normalizeEnvelopeAddress(address)
+	if err != nil {
+		return fmt.Errorf("invalid bounce_address: %s", strings.TrimSpace(address))
+	}
+	account.BounceAddress = strings.ToLower(normalized)
+	return nil
+}
+
+// envelopeSender 选择SMTP信封发件人：单次发送指定的地址优先，其次账户退信地址，最后使用发件人地址
+func envelopeSender(account *models.EmailAccount, email *ComposedEmail) string {
+	if email.EnvelopeFrom != "" {
+		return email.EnvelopeFrom
+	}
+	if account != nil && account.BounceAddress != "" {
+		return account.BounceAddress
+	}
+	if email.From != nil {
+		return email.From.Address
+	}
+	return ""
+}
+
+// VERPAddress 生成VERP信封地址，如 bounces@example.com 与 alice@example.org 得到
+// bounces+alice=example.org@example.com，退信会带着原收件人回到退信邮箱
+func VERPAddress(bounceAddress, recipient string) string {
+	at := strings.LastIndex(bounceAddress, "@")
+	rcptAt := strings.LastIndex(recipient, "@")
+	if at <= 0 || rcptAt <= 0 {
+		return bounceAddress
+	}
+	encoded := recipient[:rcptAt] + "=" + recipient[rcptAt+1:]
+	return bounceAddress[:at] + verpDelimiter + encoded + bounceAddress[at:]
+}
+
+// ParseVERPAddress 从退信的收件地址中还原退信地址和原收件人，不是VERP地址时ok为false
+func ParseVERPAddress(address string) (bounceAddress, recipient string, ok bool) {
+	at := strings.LastIndex(address, "@")
+	if at <= 0 {
+		return "", "", false
+	}
+	local, domain := address[:at], address[at:]
+
+	delimiter := strings.Index(local, verpDelimiter)
+	if delimiter <= 0 {
+		return "", "", false
+	}
+	encoded := local[delimiter+1:]
+	equals := strings.LastIndex(encoded, "=")
+	if equals <= 0 || equals == len(encoded)-1 {
+		return "", "", false
+	}
+	return local[:delimiter] + domain, encoded[:equals] + "@" + encoded[equals+1:], true
+}
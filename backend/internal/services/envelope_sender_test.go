@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVERPAddressRoundTrip(t *testing.T) {
+	address := VERPAddress("bounces@example.com", "alice.smith@example.org")
+	require.Equal(t, "bounces+alice.smith=example.org@example.com", address)
+
+	bounce, recipient, ok := ParseVERPAddress(address)
+	require.True(t, ok)
+	require.Equal(t, "bounces@example.com", bounce)
+	require.Equal(t, "alice.smith@example.org", recipient)
+
+	_, _, ok = ParseVERPAddress("bounces@example.com")
+	require.False(t, ok)
+	_, _, ok = ParseVERPAddress("bounces+tag@example.com")
+	require.False(t, ok)
+}
+
+func TestEnvelopeSenderPrecedence(t *testing.T) {
+	env, sender, smtp := setupOutboundQueueTest(t)
+	ctx := context.Background()
+
+	account := &models.EmailAccount{}
+	require.Error(t, applyBounceAddress(account, "Bounces <bounces@example.com>"))
+	require.NoError(t, applyBounceAddress(account, " Bounces@Example.com "))
+	require.Equal(t, "bounces@example.com", account.BounceAddress)
+	require.NoError(t, env.db.Model(env.account).Update("bounce_address", account.BounceAddress).Error)
+
+	send := func(envelopeFrom string) {
+		_, err := sender.SendEmail(ctx, &ComposedEmail{
+			ID:           "envelope-" + generateEmailID(),
+			From:         &models.EmailAddress{Address: "tester@example.com"},
+			To:           []*models.EmailAddress{{Address: "alice@example.org"}},
+			Subject:      "Bounce routing",
+			TextBody:     "hello",
+			EnvelopeFrom: envelopeFrom,
+		}, env.account.ID)
+		require.NoError(t, err)
+		waitForSends(t, sender)
+	}
+
+	// 账户退信地址作为默认信封发件人，单次发送可以另行指定，From头保持不变
+	send("")
+	send(VERPAddress("bounces@example.com", "alice@example.org"))
+	require.Len(t, smtp.sends, 2)
+	require.Equal(t, "bounces@example.com", smtp.sends[0].from)
+	require.Equal(t, "bounces+alice=example.org@example.com", smtp.sends[1].from)
+	require.Contains(t, string(smtp.sends[1].data), "From: tester@example.com\r\n")
+}
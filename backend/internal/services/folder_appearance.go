@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// appearanceColorPattern 十六进制颜色，#RGB 或 #RRGGBB
+	appearanceColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+	// appearanceIconPattern 图标名称，如 inbox、mdi:star、folder-open
+	appearanceIconPattern = regexp.MustCompile(`^[a-zA-Z0-9_:-]{1,50}$`)
+)
+
+// AppearanceRequest 文件夹或标签的显示属性，未提供的字段保持不变，颜色和图标为空字符串时清除
+type AppearanceRequest struct {
+	Color     *string `json:"color"`
+	Icon      *string `json:"icon"`
+	SortOrder *int    `json:"sort_order"`
+}
+
+// UpdateLabelAppearanceRequest 设置标签显示属性请求
+type UpdateLabelAppearanceRequest struct {
+	Name string `json:"name" binding:"required"`
+	AppearanceRequest
+}
+
+// labelsAvailable 标签显示属性表是否存在
+func labelsAvailable(db *gorm.DB) bool {
+	return db.Migrator().HasTable(&models.Label{})
+}
+
+// validate 校验显示属性，颜色统一为小写
+func (r *AppearanceRequest) validate() error {
+	if r.Color != nil {
+		color := strings.ToLower(strings.TrimSpace(*r.Color))
+		if color != "" && !appearanceColorPattern.MatchString(color) {
+			return fmt.Errorf("invalid color: %s", *r.Color)
+		}
+		r.Color = &color
+	}
+	if r.Icon != nil {
+		icon := strings.TrimSpace(*r.Icon)
+		if icon != "" && !appearanceIconPattern.MatchString(icon) {
+			return fmt.Errorf("invalid icon: %s", *r.Icon)
+		}
+		r.Icon = &icon
+	}
+	return nil
+}
+
+// updates 需要写入的字段
+func (r *AppearanceRequest) updates() map[string]interface{} {
+	updates := make(map[string]interface{})
+	if r.Color != nil {
+		updates["color"] = *r.Color
+	}
+	if r.Icon != nil {
+		updates["icon"] = *r.Icon
+	}
+	if r.SortOrder != nil {
+		updates["sort_order"] = *r.SortOrder
+	}
+	return updates
+}
+
+// UpdateFolderAppearance 更新文件夹的颜色、图标和显示顺序。
+// 只修改本地保存的显示属性，不连接邮件服务器，系统文件夹同样可以设置
+func (s *EmailServiceImpl) UpdateFolderAppearance(ctx context.Context, userID, folderID uint, req *AppearanceRequest) (*models.Folder, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
+	folder, err := s.GetFolder(ctx, userID, folderID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := req.updates()
+	if len(updates) == 0 {
+		return folder, nil
+	}
+	if err := s.db.WithContext(ctx).Model(folder).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update folder appearance: %w", err)
+	}
+	recordFolderChanges(ctx, s.changeLog, userID, folder.AccountID, &folder.ID)
+
+	return s.GetFolder(ctx, userID, folderID)
+}
+
+// GetLabels 获取账户的标签及其显示属性。
+// 邮件中使用但没有设置显示属性的标签也会返回，ID为0
+func (s *EmailServiceImpl) GetLabels(ctx context.Context, userID, accountID uint) ([]models.Label, error) {
+	if _, err := s.GetEmailAccount(ctx, userID, accountID); err != nil {
+		return nil, err
+	}
+
+	var labels []models.Label
+	if labelsAvailable(s.db) {
+		if err := s.db.WithContext(ctx).Where("account_id = ?", accountID).Find(&labels).Error; err != nil {
+			return nil, fmt.Errorf("failed to get labels: %w", err)
+		}
+	}
+
+	known := make(map[string]bool, len(labels))
+	for _, label := range labels {
+		known[label.Name] = true
+	}
+
+	var rawLabels []string
+	if err := s.db.WithContext(ctx).Model(&models.Email{}).
+		Where("account_id = ? AND labels IS NOT NULL AND labels <> '' AND labels <> '[]'", accountID).
+		Distinct().Pluck("labels", &rawLabels).Error; err != nil {
+		return nil, fmt.Errorf("failed to get email labels: %w", err)
+	}
+	for _, raw := range rawLabels {
+		var names []string
+		if err := json.Unmarshal([]byte(raw), &names); err != nil {
+			continue
+		}
+		for _, name := range names {
+			if name == "" || known[name] {
+				continue
+			}
+			known[name] = true
+			labels = append(labels, models.Label{AccountID: accountID, Name: name})
+		}
+	}
+
+	sort.SliceStable(labels, func(i, j int) bool {
+		if labels[i].SortOrder != labels[j].SortOrder {
+			return labels[i].SortOrder < labels[j].SortOrder
+		}
+		return labels[i].Name < labels[j].Name
+	})
+	return labels, nil
+}
+
+// UpdateLabelAppearance 设置标签的颜色、图标和显示顺序，标签尚无显示属性时创建
+func (s *EmailServiceImpl) UpdateLabelAppearance(ctx context.Context, userID, accountID uint, req *UpdateLabelAppearanceRequest) (*models.Label, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("label name is required")
+	}
+	if len(name) > 100 {
+		return nil, fmt.Errorf("label name is too long")
+	}
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+	if _, err := s.GetEmailAccount(ctx, userID, accountID); err != nil {
+		return nil, err
+	}
+
+	var label models.Label
+	err := s.db.WithContext(ctx).Where("account_id = ? AND name = ?", accountID, name).Take(&label).Error
+	switch {
+	case err == gorm.ErrRecordNotFound:
+		label = models.Label{AccountID: accountID, Name: name}
+		if req.Color != nil {
+			label.Color = *req.Color
+		}
+		if req.Icon != nil {
+			label.Icon = *req.Icon
+		}
+		if req.SortOrder != nil {
+			label.SortOrder = *req.SortOrder
+		}
+		if err := s.db.WithContext(ctx).Create(&label).Error; err != nil {
+			return nil, fmt.Errorf("failed to create label: %w", err)
+		}
+	case err != nil:
+		return nil, fmt.Errorf("failed to find label: %w", err)
+	default:
+		if updates := req.updates(); len(updates) > 0 {
+			if err := s.db.WithContext(ctx).Model(&label).Updates(updates).Error; err != nil {
+				return nil, fmt.Errorf("failed to update label: %w", err)
+			}
+			if err := s.db.WithContext(ctx).First(&label, label.ID).Error; err != nil {
+				return nil, fmt.Errorf("failed to reload label: %w", err)
+			}
+		}
+	}
+
+	return &label, nil
+}
+
+// DeleteLabelAppearance 删除标签的显示属性，邮件上的标签不受影响
+func (s *EmailServiceImpl) DeleteLabelAppearance(ctx context.Context, userID, labelID uint) error {
+	result := s.db.WithContext(ctx).
+		Where("id = ? AND account_id IN (?)", labelID,
+			s.db.Model(&models.EmailAccount{}).Select("id").Where("user_id = ?", userID)).
+		Delete(&models.Label{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete label: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("label not found")
+	}
+	return nil
+}
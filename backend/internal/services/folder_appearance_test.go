@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFolderAppearanceOrdersFolders(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+
+	color, icon, order := "#1A73E8", "mdi:briefcase", -1
+	folder, err := env.service.UpdateFolderAppearance(ctx, env.user.ID, env.work.ID, &AppearanceRequest{Color: &color, Icon: &icon, SortOrder: &order})
+	require.NoError(t, err)
+	require.Equal(t, "#1a73e8", folder.Color)
+	require.Equal(t, "mdi:briefcase", folder.Icon)
+	require.Equal(t, -1, folder.SortOrder)
+
+	// 系统文件夹同样可以设置，未提供的字段保持不变
+	inboxIcon := "inbox"
+	_, err = env.service.UpdateFolderAppearance(ctx, env.user.ID, env.inbox.ID, &AppearanceRequest{Icon: &inboxIcon})
+	require.NoError(t, err)
+
+	folders, err := env.service.GetFolders(ctx, env.user.ID, env.account.ID)
+	require.NoError(t, err)
+	require.Len(t, folders, 2)
+	require.Equal(t, env.work.ID, folders[0].ID)
+	require.Equal(t, "inbox", folders[1].Icon)
+	require.Empty(t, folders[1].Color)
+
+	// 清除颜色
+	empty := ""
+	folder, err = env.service.UpdateFolderAppearance(ctx, env.user.ID, env.work.ID, &AppearanceRequest{Color: &empty})
+	require.NoError(t, err)
+	require.Empty(t, folder.Color)
+	require.Equal(t, "mdi:briefcase", folder.Icon)
+
+	bad := "red"
+	_, err = env.service.UpdateFolderAppearance(ctx, env.user.ID, env.work.ID, &AppearanceRequest{Color: &bad})
+	require.EqualError(t, err, "invalid color: red")
+	badIcon := "<svg>"
+	_, err = env.service.UpdateFolderAppearance(ctx, env.user.ID, env.work.ID, &AppearanceRequest{Icon: &badIcon})
+	require.EqualError(t, err, "invalid icon: <svg>")
+}
+
+func TestLabelAppearance(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.Label{}))
+	ctx := context.Background()
+
+	email := &models.Email{
+		AccountID: env.account.ID,
+		FolderID:  &env.inbox.ID,
+		MessageID: "<labels@example.com>",
+		Subject:   "标签",
+		Labels:    `["Work","Travel"]`,
+	}
+	require.NoError(t, env.db.Create(email).Error)
+
+	// 邮件中使用的标签即使没有显示属性也会返回
+	labels, err := env.service.GetLabels(ctx, env.user.ID, env.account.ID)
+	require.NoError(t, err)
+	require.Len(t, labels, 2)
+	require.Equal(t, "Travel", labels[0].Name)
+	require.Zero(t, labels[0].ID)
+
+	color, order := "#f00", -1
+	label, err := env.service.UpdateLabelAppearance(ctx, env.user.ID, env.account.ID, &UpdateLabelAppearanceRequest{
+		Name: "Work", AppearanceRequest: AppearanceRequest{Color: &color, SortOrder: &order},
+	})
+	require.NoError(t, err)
+	require.NotZero(t, label.ID)
+
+	icon := "star"
+	updated, err := env.service.UpdateLabelAppearance(ctx, env.user.ID, env.account.ID, &UpdateLabelAppearanceRequest{
+		Name: "Work", AppearanceRequest: AppearanceRequest{Icon: &icon},
+	})
+	require.NoError(t, err)
+	require.Equal(t, label.ID, updated.ID)
+	require.Equal(t, "#f00", updated.Color)
+	require.Equal(t, "star", updated.Icon)
+
+	labels, err = env.service.GetLabels(ctx, env.user.ID, env.account.ID)
+	require.NoError(t, err)
+	require.Len(t, labels, 2)
+	require.Equal(t, "Work", labels[0].Name)
+	require.Equal(t, "star", labels[0].Icon)
+
+	require.NoError(t, env.service.DeleteLabelAppearance(ctx, env.user.ID, label.ID))
+	require.EqualError(t, env.service.DeleteLabelAppearance(ctx, env.user.ID, label.ID), "label not found")
+
+	labels, err = env.service.GetLabels(ctx, env.user.ID, env.account.ID)
+	require.NoError(t, err)
+	require.Len(t, labels, 2)
+	require.Equal(t, "Travel", labels[0].Name)
+}
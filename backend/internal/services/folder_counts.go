@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"firemail/internal/models"
+)
+
+// FolderCountReconcileInterval 周期校正文件夹计数的间隔
+const FolderCountReconcileInterval = 6 * time.Hour
+
+// ErrFolderCountScopeNotFound 指定的账户或文件夹不存在或不属于当前用户
+var ErrFolderCountScopeNotFound = errors.New("account or folder not found")
+
+// ReconcileFolderCountsRequest 校正文件夹计数的范围和选项，账户和文件夹都为空时检查用户的全部账户
+type ReconcileFolderCountsRequest struct {
+	AccountID   *uint `json:"account_id"`
+	FolderID    *uint `json:"folder_id"`
+	CheckServer bool  `json:"check_server"` // 同时通过IMAP STATUS获取服务器上的计数用于对比，不会用服务器的值修正
+	DryRun      bool  `json:"dry_run"`      // 只报告差异，不修正
+}
+
+// FolderCountReport 单个文件夹的计数对比
+type FolderCountReport struct {
+	FolderID     uint   `json:"folder_id"`
+	AccountID    uint   `json:"account_id"`
+	Path         string `json:"path"`
+	StoredTotal  int    `json:"stored_total"`  // 校正前保存的邮件数
+	StoredUnread int    `json:"stored_unread"` // 校正前保存的未读数
+	Total        int    `json:"total"`         // 按数据库中的邮件重新统计的邮件数
+	Unread       int    `json:"unread"`        // 按数据库中的邮件重新统计的未读数
+	ServerTotal  *int   `json:"server_total,omitempty"`
+	ServerUnread *int   `json:"server_unread,omitempty"`
+	ServerError  string `json:"server_error,omitempty"`
+	Fixed        bool   `json:"fixed"`
+}
+
+// drifted 保存的计数与重新统计的结果不一致
+func (r *FolderCountReport) drifted() bool {
+	return r.StoredTotal != r.Total || r.StoredUnread != r.Unread
+}
+
+// serverMismatch 服务器上的计数与数据库不一致，通常表示有邮件尚未同步
+func (r *FolderCountReport) serverMismatch() bool {
+	return r.ServerTotal != nil && (*r.ServerTotal != r.Total || *r.ServerUnread != r.Unread)
+}
+
+// AccountCountReport 账户计数的对比
+type AccountCountReport struct {
+	AccountID    uint `json:"account_id"`
+	StoredTotal  int  `json:"stored_total"`
+	StoredUnread int  `json:"stored_unread"`
+	Total        int  `json:"total"`
+	Unread       int  `json:"unread"`
+	Fixed        bool `json:"fixed"`
+}
+
+// FolderCountReconciliation 校正结果，只列出有差异的文件夹和账户
+type FolderCountReconciliation struct {
+	CheckedAccounts int                  `json:"checked_accounts"`
+	CheckedFolders  int                  `json:"checked_folders"`
+	DriftedFolders  int                  `json:"drifted_folders"`
+	FixedFolders    int                  `json:"fixed_folders"`
+	ServerMismatch  int                  `json:"server_mismatch"` // 与服务器计数不一致的文件夹数
+	Folders         []FolderCountReport  `json:"folders"`
+	Accounts        []AccountCountReport `json:"accounts"`
+	Errors          []string             `json:"errors,omitempty"` // 连接服务器失败等不影响数据库校正的错误
+}
+
+// emailCountRow 按文件夹分组统计的邮件数
+type emailCountRow struct {
+	FolderID uint
+	Total    int
+	Unread   int
+}
+
+// ReconcileFolderCounts 按数据库中未删除的邮件重新统计文件夹和账户的邮件数、未读数，修正批量操作后累积的偏差
+func (s *EmailServiceImpl) ReconcileFolderCounts(ctx context.Context, userID uint, req *ReconcileFolderCountsRequest) (*FolderCountReconciliation, error) {
+	if req == nil {
+		req = &ReconcileFolderCountsRequest{}
+	}
+
+	query := s.db.WithContext(ctx).Where("user_id = ?", userID)
+	if req.FolderID != nil {
+		query = query.Where("id IN (?)", s.db.Model(&models.Folder{}).Select("account_id").Where("id = ?", *req.FolderID))
+	}
+	if req.AccountID != nil {
+		query = query.Where("id = ?", *req.AccountID)
+	}
+	var accounts []models.EmailAccount
+	if err := query.Order("id").Find(&accounts).Error; err != nil {
+		return nil, fmt.Errorf("failed to get accounts: %w", err)
+	}
+	if len(accounts) == 0 && (req.AccountID != nil || req.FolderID != nil) {
+		return nil, ErrFolderCountScopeNotFound
+	}
+
+	result := &FolderCountReconciliation{
+		Folders:  []FolderCountReport{},
+		Accounts: []AccountCountReport{},
+	}
+	for i := range accounts {
+		if err := s.reconcileAccountCounts(ctx, &accounts[i], req, result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// reconcileAccountCounts 校正单个账户的文件夹计数；只检查一个文件夹时不修正账户计数
+func (s *EmailServiceImpl) reconcileAccountCounts(ctx context.Context, account *models.EmailAccount, req *ReconcileFolderCountsRequest, result *FolderCountReconciliation) error {
+	folderQuery := s.db.WithContext(ctx).Where("account_id = ?", account.ID)
+	if req.FolderID != nil {
+		folderQuery = folderQuery.Where("id = ?", *req.FolderID)
+	}
+	var folders []models.Folder
+	if err := folderQuery.Order("id").Find(&folders).Error; err != nil {
+		return fmt.Errorf("failed to get folders: %w", err)
+	}
+
+	var rows []emailCountRow
+	countQuery := s.db.WithContext(ctx).Model(&models.Email{}).
+		Select("folder_id, COUNT(*) AS total, COALESCE(SUM(CASE WHEN is_read = ? THEN 1 ELSE 0 END), 0) AS unread", false).
+		Where("account_id = ? AND is_deleted = ? AND folder_id IS NOT NULL", account.ID, false)
+	if req.FolderID != nil {
+		countQuery = countQuery.Where("folder_id = ?", *req.FolderID)
+	}
+	if err := countQuery.Group("folder_id").Scan(&rows).Error; err != nil {
+		return fmt.Errorf("failed to count folder emails: %w", err)
+	}
+	counts := make(map[uint]emailCountRow, len(rows))
+	for _, row := range rows {
+		counts[row.FolderID] = row
+	}
+
+	var statusOf func(folder *models.Folder) (int, int, error)
+	if req.CheckServer && len(folders) > 0 {
+		var disconnect func()
+		var err error
+		statusOf, disconnect, err = s.folderStatusReader(ctx, account)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("account %d: %v", account.ID, err))
+		} else {
+			defer disconnect()
+		}
+	}
+
+	var fixedFolderIDs []*uint
+	for i := range folders {
+		folder := &folders[i]
+		report := FolderCountReport{
+			FolderID:     folder.ID,
+			AccountID:    account.ID,
+			Path:         folder.GetFullPath(),
+			StoredTotal:  folder.TotalEmails,
+			StoredUnread: folder.UnreadEmails,
+			Total:        counts[folder.ID].Total,
+			Unread:       counts[folder.ID].Unread,
+		}
+		result.CheckedFolders++
+
+		if statusOf != nil && folder.IsSelectable {
+			total, unread, err := statusOf(folder)
+			if err != nil {
+				report.ServerError = err.Error()
+			} else {
+				report.ServerTotal, report.ServerUnread = &total, &unread
+			}
+		}
+
+		if report.drifted() {
+			result.DriftedFolders++
+			if !req.DryRun {
+				if err := s.db.WithContext(ctx).Model(&models.Folder{}).Where("id = ?", folder.ID).
+					UpdateColumns(map[string]interface{}{"total_emails": report.Total, "unread_emails": report.Unread}).Error; err != nil {
+					return fmt.Errorf("failed to update folder counts: %w", err)
+				}
+				report.Fixed = true
+				result.FixedFolders++
+				fixedFolderIDs = append(fixedFolderIDs, &folder.ID)
+			}
+		}
+		if report.serverMismatch() {
+			result.ServerMismatch++
+		}
+		if report.drifted() || report.serverMismatch() || report.ServerError != "" {
+			result.Folders = append(result.Folders, report)
+		}
+	}
+
+	accountFixed := false
+	if req.FolderID == nil {
+		result.CheckedAccounts++
+		var totals emailCountRow
+		if err := s.db.WithContext(ctx).Model(&models.Email{}).
+			Select("COUNT(*) AS total, COALESCE(SUM(CASE WHEN is_read = ? THEN 1 ELSE 0 END), 0) AS unread", false).
+			Where("account_id = ? AND is_deleted = ?", account.ID, false).
+			Scan(&totals).Error; err != nil {
+			return fmt.Errorf("failed to count account emails: %w", err)
+		}
+		if totals.Total != account.TotalEmails || totals.Unread != account.UnreadEmails {
+			report := AccountCountReport{
+				AccountID:    account.ID,
+				StoredTotal:  account.TotalEmails,
+				StoredUnread: account.UnreadEmails,
+				Total:        totals.Total,
+				Unread:       totals.Unread,
+			}
+			if !req.DryRun {
+				if err := s.db.WithContext(ctx).Model(&models.EmailAccount{}).Where("id = ?", account.ID).
+					UpdateColumns(map[string]interface{}{"total_emails": totals.Total, "unread_emails": totals.Unread}).Error; err != nil {
+					return fmt.Errorf("failed to update account counts: %w", err)
+				}
+				report.Fixed = true
+				accountFixed = true
+			}
+			result.Accounts = append(result.Accounts, report)
+		}
+	}
+
+	if len(fixedFolderIDs) > 0 || accountFixed {
+		s.invalidateEmailListCache(account.UserID, account.ID, nil)
+		recordFolderChanges(ctx, s.changeLog, account.UserID, account.ID, fixedFolderIDs...)
+		recordAccountChange(ctx, s.changeLog, account.UserID, account.ID, models.ChangeActionUpdated)
+	}
+	return nil
+}
+
+// folderStatusReader 连接账户的IMAP服务器，返回读取文件夹STATUS计数的函数和断开连接的函数
+func (s *EmailServiceImpl) folderStatusReader(ctx context.Context, account *models.EmailAccount) (func(*models.Folder) (int, int, error), func(), error) {
+	provider, err := s.providerFactory.CreateProviderForAccount(account)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create provider: %w", err)
+	}
+	s.setupProviderTokenCallback(provider)
+	if err := provider.Connect(ctx, account); err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to email server: %w", err)
+	}
+	imapClient := provider.IMAPClient()
+	if imapClient == nil {
+		provider.Disconnect()
+		return nil, nil, fmt.Errorf("IMAP client not available")
+	}
+
+	statusOf := func(folder *models.Folder) (int, int, error) {
+		status, err := imapClient.GetFolderStatus(ctx, folder.GetFullPath())
+		if err != nil {
+			return 0, 0, err
+		}
+		if status == nil {
+			return 0, 0, fmt.Errorf("no status returned for folder %s", folder.GetFullPath())
+		}
+		return status.TotalEmails, status.UnreadEmails, nil
+	}
+	return statusOf, func() { provider.Disconnect() }, nil
+}
+
+// ReconcileAllFolderCounts 校正所有用户的文件夹和账户计数，只使用数据库，供周期任务调用
+func (s *EmailServiceImpl) ReconcileAllFolderCounts(ctx context.Context) error {
+	var userIDs []uint
+	if err := s.db.WithContext(ctx).Model(&models.EmailAccount{}).Distinct("user_id").Pluck("user_id", &userIDs).Error; err != nil {
+		return fmt.Errorf("failed to get users: %w", err)
+	}
+
+	for _, userID := range userIDs {
+		result, err := s.ReconcileFolderCounts(ctx, userID, &ReconcileFolderCountsRequest{})
+		if err != nil {
+			return err
+		}
+		if result.FixedFolders > 0 || len(result.Accounts) > 0 {
+			log.Printf("Reconciled email counts for user %d: %d of %d folders and %d accounts fixed",
+				userID, result.FixedFolders, result.CheckedFolders, len(result.Accounts))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"firemail/internal/models"
+	"firemail/internal/providers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileFolderCountsFixesDrift(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+
+	env.createEmail(t, env.inbox, 1, "unread", false, false)
+	env.createEmail(t, env.inbox, 2, "read", true, false)
+	env.createEmail(t, env.inbox, 3, "deleted", false, true)
+	env.createEmail(t, env.work, 4, "work", false, false)
+
+	// 批量操作后累积的偏差
+	require.NoError(t, env.db.Model(env.inbox).UpdateColumns(map[string]interface{}{"total_emails": 5, "unread_emails": 4}).Error)
+	require.NoError(t, env.db.Model(env.work).UpdateColumns(map[string]interface{}{"total_emails": 1, "unread_emails": 1}).Error)
+	require.NoError(t, env.db.Model(env.account).UpdateColumns(map[string]interface{}{"total_emails": 7, "unread_emails": 0}).Error)
+
+	dryRun, err := env.service.ReconcileFolderCounts(ctx, env.user.ID, &ReconcileFolderCountsRequest{DryRun: true})
+	require.NoError(t, err)
+	require.Equal(t, 2, dryRun.CheckedFolders)
+	require.Equal(t, 1, dryRun.DriftedFolders)
+	require.Zero(t, dryRun.FixedFolders)
+	require.Len(t, dryRun.Folders, 1)
+	require.Equal(t, FolderCountReport{
+		FolderID: env.inbox.ID, AccountID: env.account.ID, Path: "INBOX",
+		StoredTotal: 5, StoredUnread: 4, Total: 2, Unread: 1,
+	}, dryRun.Folders[0])
+	require.Equal(t, []AccountCountReport{{AccountID: env.account.ID, StoredTotal: 7, Total: 3, Unread: 2}}, dryRun.Accounts)
+
+	var inbox models.Folder
+	require.NoError(t, env.db.First(&inbox, env.inbox.ID).Error)
+	require.Equal(t, 4, inbox.UnreadEmails)
+
+	result, err := env.service.ReconcileFolderCounts(ctx, env.user.ID, &ReconcileFolderCountsRequest{AccountID: &env.account.ID})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.FixedFolders)
+	require.True(t, result.Accounts[0].Fixed)
+
+	require.NoError(t, env.db.First(&inbox, env.inbox.ID).Error)
+	require.Equal(t, 2, inbox.TotalEmails)
+	require.Equal(t, 1, inbox.UnreadEmails)
+	var account models.EmailAccount
+	require.NoError(t, env.db.First(&account, env.account.ID).Error)
+	require.Equal(t, 3, account.TotalEmails)
+	require.Equal(t, 2, account.UnreadEmails)
+
+	// 再次执行没有差异
+	result, err = env.service.ReconcileFolderCounts(ctx, env.user.ID, nil)
+	require.NoError(t, err)
+	require.Zero(t, result.DriftedFolders)
+	require.Empty(t, result.Folders)
+	require.Empty(t, result.Accounts)
+}
+
+func TestReconcileFolderCountsChecksServer(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+
+	env.createEmail(t, env.work, 1, "work", false, false)
+	require.NoError(t, env.db.Model(env.work).UpdateColumns(map[string]interface{}{"total_emails": 1, "unread_emails": 1}).Error)
+	env.provider.imap.folderStatuses = map[string]*providers.FolderStatus{
+		"Projects": {Name: "Projects", TotalEmails: 3, UnreadEmails: 2},
+	}
+
+	// 只检查一个文件夹时与服务器对比，但不用服务器的值修正
+	result, err := env.service.ReconcileFolderCounts(ctx, env.user.ID, &ReconcileFolderCountsRequest{FolderID: &env.work.ID, CheckServer: true})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.CheckedFolders)
+	require.Zero(t, result.CheckedAccounts)
+	require.Equal(t, 1, result.ServerMismatch)
+	require.Len(t, result.Folders, 1)
+	require.Equal(t, 3, *result.Folders[0].ServerTotal)
+	require.Equal(t, 2, *result.Folders[0].ServerUnread)
+	require.False(t, result.Folders[0].Fixed)
+	require.Equal(t, 1, env.provider.disconnects)
+
+	_, err = env.service.ReconcileFolderCounts(ctx, env.user.ID+1, &ReconcileFolderCountsRequest{FolderID: &env.work.ID})
+	require.True(t, errors.Is(err, ErrFolderCountScopeNotFound))
+}
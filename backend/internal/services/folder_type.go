@@ -0,0 +1,37 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"firemail/internal/models"
+)
+
+// overridableFolderTypes 可以手动指定的文件夹类型；收件箱由服务器的 INBOX 决定，不能指定
+var overridableFolderTypes = map[string]bool{
+	models.FolderTypeSent:    true,
+	models.FolderTypeDrafts:  true,
+	models.FolderTypeTrash:   true,
+	models.FolderTypeSpam:    true,
+	models.FolderTypeArchive: true,
+	models.FolderTypeCustom:  true,
+}
+
+// applyFolderTypeOverride 手动指定文件夹类型并锁定，之后同步不再自动检测；
+// 空字符串解除锁定，类型在下次同步文件夹时重新检测
+func applyFolderTypeOverride(folder *models.Folder, folderType string) error {
+	folderType = strings.ToLower(strings.TrimSpace(folderType))
+	if folderType == "" {
+		folder.TypeLocked = false
+		return nil
+	}
+	if folder.Type == models.FolderTypeInbox && strings.EqualFold(folder.Path, "INBOX") {
+		return fmt.Errorf("cannot change the type of INBOX")
+	}
+	if !overridableFolderTypes[folderType] {
+		return fmt.Errorf("invalid folder type: %s", folderType)
+	}
+	folder.Type = folderType
+	folder.TypeLocked = true
+	return nil
+}
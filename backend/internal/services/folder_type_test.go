@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateFolderTypeOverride(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+
+	trash := models.FolderTypeTrash
+	folder, err := env.service.UpdateFolder(ctx, env.user.ID, env.work.ID, &UpdateFolderRequest{Type: &trash})
+	require.NoError(t, err)
+	require.Equal(t, models.FolderTypeTrash, folder.Type)
+	require.True(t, folder.TypeLocked)
+
+	var stored models.Folder
+	require.NoError(t, env.db.First(&stored, env.work.ID).Error)
+	require.Equal(t, models.FolderTypeTrash, stored.Type)
+	require.True(t, stored.TypeLocked)
+
+	// 系统文件夹也可以修改类型，但仍不能重命名
+	custom := models.FolderTypeCustom
+	_, err = env.service.UpdateFolder(ctx, env.user.ID, env.work.ID, &UpdateFolderRequest{Type: &custom, DisplayName: &custom})
+	require.EqualError(t, err, "cannot modify system folder")
+
+	folder, err = env.service.UpdateFolder(ctx, env.user.ID, env.work.ID, &UpdateFolderRequest{Type: &custom})
+	require.NoError(t, err)
+	require.Equal(t, models.FolderTypeCustom, folder.Type)
+
+	auto := ""
+	folder, err = env.service.UpdateFolder(ctx, env.user.ID, env.work.ID, &UpdateFolderRequest{Type: &auto})
+	require.NoError(t, err)
+	require.False(t, folder.TypeLocked)
+
+	invalid := "starred"
+	_, err = env.service.UpdateFolder(ctx, env.user.ID, env.work.ID, &UpdateFolderRequest{Type: &invalid})
+	require.EqualError(t, err, "invalid folder type: starred")
+
+	_, err = env.service.UpdateFolder(ctx, env.user.ID, env.inbox.ID, &UpdateFolderRequest{Type: &trash})
+	require.EqualError(t, err, "cannot change the type of INBOX")
+}
@@ -0,0 +1,556 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+
+	"firemail/internal/models"
+	"firemail/internal/parser"
+	"firemail/internal/providers"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// forwardedByHeader 记录邮件经过的自动转发账户，用于检测转发环路
+	forwardedByHeader = "X-FireMail-Forwarded-By"
+	// maxForwardHops 邮件最多经过的自动转发次数
+	maxForwardHops = 5
+	// forwardingQueueSize 等待转发的邮件数上限
+	forwardingQueueSize = 1000
+	// forwardingDateSkew 规则只转发日期晚于创建时间的邮件，允许的时钟误差
+	forwardingDateSkew = time.Hour
+	// maxForwardedMessageSize 转发邮件原文的大小上限
+	maxForwardedMessageSize = 50 << 20
+)
+
+var (
+	// ErrForwardingRuleNotFound 转发规则不存在或无权访问
+	ErrForwardingRuleNotFound = errors.New("forwarding rule not found")
+	// ErrInvalidForwardingRule 转发规则参数无效
+	ErrInvalidForwardingRule = errors.New("invalid forwarding rule")
+	// errForwardingLoop 邮件已经过目标地址或本账户转发
+	errForwardingLoop = errors.New("forwarding loop detected")
+	// errForwardingSkipped 规则在排队期间被删除或停用
+	errForwardingSkipped = errors.New("forwarding rule removed or disabled")
+)
+
+// EmailForwarder 同步到新邮件时检查并排队自动转发
+type EmailForwarder interface {
+	ForwardNewEmail(ctx context.Context, account *models.EmailAccount, email *models.Email)
+}
+
+// ForwardingService 自动转发规则服务接口
+type ForwardingService interface {
+	EmailForwarder
+
+	// ListRules 列出转发规则
+	ListRules(ctx context.Context, userID uint) ([]models.ForwardingRule, error)
+
+	// CreateRule 创建转发规则
+	CreateRule(ctx context.Context, userID uint, req *ForwardingRuleRequest) (*models.ForwardingRule, error)
+
+	// UpdateRule 修改转发规则，包括启用和停用
+	UpdateRule(ctx context.Context, userID, ruleID uint, req *ForwardingRuleRequest) (*models.ForwardingRule, error)
+
+	// DeleteRule 删除转发规则
+	DeleteRule(ctx context.Context, userID, ruleID uint) error
+
+	// Start 启动转发队列
+	Start(ctx context.Context) error
+
+	// Stop 停止转发队列，未转发的邮件被丢弃
+	Stop()
+}
+
+// ForwardingRuleRequest 创建或修改转发规则的请求
+type ForwardingRuleRequest struct {
+	AccountID       uint   `json:"account_id" binding:"required"`
+	Name            string `json:"name" binding:"required,max=100"`
+	ForwardTo       string `json:"forward_to" binding:"required,email"`
+	Mode            string `json:"mode,omitempty" binding:"omitempty,oneof=inline attachment"` // 默认inline
+	FromContains    string `json:"from_contains,omitempty" binding:"max=255"`
+	SubjectContains string `json:"subject_contains,omitempty" binding:"max=255"`
+	IsEnabled       *bool  `json:"is_enabled,omitempty"` // 默认启用
+}
+
+// forwardingSender 读取原邮件并发送转发邮件，由邮件服务实现
+type forwardingSender interface {
+	openEmailSourceSession(ctx context.Context, account *models.EmailAccount) (*emailSourceSession, error)
+	SendEmail(ctx context.Context, userID uint, req *SendEmailRequest) error
+	buildForwardedContent(originalEmail *models.Email, userText, userHTML string, settings *UserSettings) *QuotedContent
+}
+
+// forwardingJob 一封待转发的邮件及匹配的规则
+type forwardingJob struct {
+	ruleID  uint
+	emailID uint
+}
+
+// ForwardingServiceImpl 自动转发规则服务实现
+type ForwardingServiceImpl struct {
+	db     *gorm.DB
+	sender forwardingSender
+
+	queue  chan forwardingJob
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	mutex  sync.Mutex
+}
+
+// NewForwardingService 创建自动转发规则服务，通过邮件服务读取原邮件和发送
+func NewForwardingService(db *gorm.DB, emailService EmailService) ForwardingService {
+	sender, _ := emailService.(forwardingSender)
+	return &ForwardingServiceImpl{
+		db:     db,
+		sender: sender,
+		queue:  make(chan forwardingJob, forwardingQueueSize),
+	}
+}
+
+// ListRules 列出转发规则
+func (s *ForwardingServiceImpl) ListRules(ctx context.Context, userID uint) ([]models.ForwardingRule, error) {
+	rules := make([]models.ForwardingRule, 0)
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("account_id ASC, id ASC").
+		Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to list forwarding rules: %w", err)
+	}
+	return rules, nil
+}
+
+// getRule 获取属于用户的转发规则
+func (s *ForwardingServiceImpl) getRule(ctx context.Context, userID, ruleID uint) (*models.ForwardingRule, error) {
+	var rule models.ForwardingRule
+	if err := s.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", ruleID, userID).
+		First(&rule).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrForwardingRuleNotFound
+		}
+		return nil, fmt.Errorf("failed to get forwarding rule: %w", err)
+	}
+	return &rule, nil
+}
+
+// applyForwardingRequest 校验请求并写入规则：账户需属于用户且能发信，不能转发给账户自己
+func (s *ForwardingServiceImpl) applyForwardingRequest(ctx context.Context, userID uint, rule *models.ForwardingRule, req *ForwardingRuleRequest) error {
+	var account models.EmailAccount
+	if err := s.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", req.AccountID, userID).
+		First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: account not found", ErrInvalidForwardingRule)
+		}
+		return fmt.Errorf("failed to check account: %w", err)
+	}
+	if account.Provider == providers.IngestProviderName {
+		return fmt.Errorf("%w: inbound accounts cannot send mail", ErrInvalidForwardingRule)
+	}
+
+	forwardTo := strings.TrimSpace(req.ForwardTo)
+	address, err := mail.ParseAddress(forwardTo)
+	if err != nil {
+		return fmt.Errorf("%w: invalid forward_to address", ErrInvalidForwardingRule)
+	}
+	if strings.EqualFold(address.Address, account.Email) {
+		return fmt.Errorf("%w: cannot forward to the account itself", ErrInvalidForwardingRule)
+	}
+
+	mode := req.Mode
+	if mode == "" {
+		mode = models.ForwardingModeInline
+	}
+	if mode != models.ForwardingModeInline && mode != models.ForwardingModeAttachment {
+		return fmt.Errorf("%w: unsupported mode %s", ErrInvalidForwardingRule, mode)
+	}
+
+	rule.AccountID = account.ID
+	rule.Name = strings.TrimSpace(req.Name)
+	rule.ForwardTo = address.Address
+	rule.Mode = mode
+	rule.FromContains = strings.TrimSpace(req.FromContains)
+	rule.SubjectContains = strings.TrimSpace(req.SubjectContains)
+	if req.IsEnabled != nil {
+		rule.IsEnabled = *req.IsEnabled
+	}
+	if rule.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidForwardingRule)
+	}
+	return nil
+}
+
+// CreateRule 创建转发规则，只转发创建之后收到的邮件
+func (s *ForwardingServiceImpl) CreateRule(ctx context.Context, userID uint, req *ForwardingRuleRequest) (*models.ForwardingRule, error) {
+	rule := &models.ForwardingRule{UserID: userID, IsEnabled: true}
+	if err := s.applyForwardingRequest(ctx, userID, rule, req); err != nil {
+		return nil, err
+	}
+
+	enabled := rule.IsEnabled
+	if err := s.db.WithContext(ctx).Create(rule).Error; err != nil {
+		return nil, fmt.Errorf("failed to create forwarding rule: %w", err)
+	}
+	// 创建时false会被字段默认值覆盖，需单独写入
+	if !enabled {
+		if err := s.db.WithContext(ctx).Model(rule).Update("is_enabled", false).Error; err != nil {
+			return nil, fmt.Errorf("failed to create forwarding rule: %w", err)
+		}
+	}
+	return rule, nil
+}
+
+// UpdateRule 修改转发规则
+func (s *ForwardingServiceImpl) UpdateRule(ctx context.Context, userID, ruleID uint, req *ForwardingRuleRequest) (*models.ForwardingRule, error) {
+	rule, err := s.getRule(ctx, userID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyForwardingRequest(ctx, userID, rule, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Save(rule).Error; err != nil {
+		return nil, fmt.Errorf("failed to update forwarding rule: %w", err)
+	}
+	return rule, nil
+}
+
+// DeleteRule 删除转发规则，队列中的邮件不再转发
+func (s *ForwardingServiceImpl) DeleteRule(ctx context.Context, userID, ruleID uint) error {
+	rule, err := s.getRule(ctx, userID, ruleID)
+	if err != nil {
+		return err
+	}
+	if err := s.db.WithContext(ctx).Delete(rule).Error; err != nil {
+		return fmt.Errorf("failed to delete forwarding rule: %w", err)
+	}
+	return nil
+}
+
+// matchForwardingRule 检查邮件是否符合规则的条件，条件为空表示不限制
+func matchForwardingRule(rule *models.ForwardingRule, email *models.Email) bool {
+	// 首次同步的历史邮件不转发
+	if email.Date.Before(rule.CreatedAt.Add(-forwardingDateSkew)) {
+		return false
+	}
+	if rule.FromContains != "" && !strings.Contains(strings.ToLower(email.From), strings.ToLower(rule.FromContains)) {
+		return false
+	}
+	if rule.SubjectContains != "" && !strings.Contains(strings.ToLower(email.Subject), strings.ToLower(rule.SubjectContains)) {
+		return false
+	}
+	return true
+}
+
+// ForwardNewEmail 检查收件箱中的新邮件是否匹配账户的转发规则，匹配的邮件排队转发，不阻塞同步
+func (s *ForwardingServiceImpl) ForwardNewEmail(ctx context.Context, account *models.EmailAccount, email *models.Email) {
+	if email.FolderID == nil || email.IsDeleted {
+		return
+	}
+
+	var rules []models.ForwardingRule
+	if err := s.db.WithContext(ctx).
+		Where("account_id = ? AND is_enabled = ?", account.ID, true).
+		Order("id ASC").
+		Find(&rules).Error; err != nil {
+		log.Printf("Failed to load forwarding rules for account %d: %v", account.ID, err)
+		return
+	}
+	if len(rules) == 0 {
+		return
+	}
+
+	// 只转发收到的邮件，已发送、草稿和垃圾邮件等文件夹中的邮件不转发
+	var folder models.Folder
+	if err := s.db.WithContext(ctx).Select("id", "type").First(&folder, *email.FolderID).Error; err != nil ||
+		folder.Type != models.FolderTypeInbox {
+		return
+	}
+
+	for i := range rules {
+		rule := &rules[i]
+		if !matchForwardingRule(rule, email) {
+			continue
+		}
+		select {
+		case s.queue <- forwardingJob{ruleID: rule.ID, emailID: email.ID}:
+		default:
+			log.Printf("Forwarding queue is full, dropping email %d for rule %d", email.ID, rule.ID)
+			s.recordForwardResult(ctx, rule.ID, fmt.Errorf("forwarding queue is full"))
+		}
+	}
+}
+
+// forward 转发一封邮件
+func (s *ForwardingServiceImpl) forward(ctx context.Context, job forwardingJob) error {
+	if s.sender == nil {
+		return fmt.Errorf("forwarding sender not configured")
+	}
+
+	var rule models.ForwardingRule
+	if err := s.db.WithContext(ctx).First(&rule, job.ruleID).Error; err != nil || !rule.IsEnabled {
+		return errForwardingSkipped
+	}
+
+	var email models.Email
+	if err := s.db.WithContext(ctx).Preload("Account").Preload("Folder").Preload("Body").
+		Where("id = ? AND account_id = ?", job.emailID, rule.AccountID).
+		First(&email).Error; err != nil {
+		return fmt.Errorf("failed to load email: %w", err)
+	}
+	if email.Folder == nil {
+		return fmt.Errorf("email %d has no folder", email.ID)
+	}
+
+	raw, err := s.fetchForwardedSource(ctx, &email)
+	if err != nil {
+		return err
+	}
+
+	chain, err := forwardingChain(raw, email.Account.Email, rule.ForwardTo)
+	if err != nil {
+		return err
+	}
+
+	req, err := s.buildForwardRequest(ctx, &rule, &email, raw)
+	if err != nil {
+		return err
+	}
+	req.Headers = map[string]string{
+		forwardedByHeader: strings.Join(append(chain, email.Account.Email), ", "),
+		// 提示收件方的自动回复不要回复转发邮件
+		"Auto-Submitted": "auto-generated",
+	}
+
+	if err := s.sender.SendEmail(ctx, rule.UserID, req); err != nil {
+		return fmt.Errorf("failed to forward email: %w", err)
+	}
+	return nil
+}
+
+// fetchForwardedSource 从服务器获取原邮件原文
+func (s *ForwardingServiceImpl) fetchForwardedSource(ctx context.Context, email *models.Email) ([]byte, error) {
+	session, err := s.sender.openEmailSourceSession(ctx, &email.Account)
+	if err != nil {
+		return nil, err
+	}
+	defer session.close()
+
+	if err := session.selectFolder(ctx, email.Folder.GetFullPath()); err != nil {
+		return nil, err
+	}
+	reader, err := session.client.FetchRawEmail(ctx, email.UID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch email source: %w", err)
+	}
+	defer reader.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(reader, maxForwardedMessageSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read email source: %w", err)
+	}
+	if len(raw) > maxForwardedMessageSize {
+		return nil, fmt.Errorf("email is too large to forward")
+	}
+	return raw, nil
+}
+
+// forwardingChain 读取原邮件已经过的自动转发账户，经过本账户或目标地址、或转发次数过多时视为环路
+func forwardingChain(raw []byte, accountEmail, forwardTo string) ([]string, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, nil
+	}
+
+	var chain []string
+	for _, value := range msg.Header[textproto.CanonicalMIMEHeaderKey(forwardedByHeader)] {
+		for _, address := range strings.Split(value, ",") {
+			address = strings.TrimSpace(address)
+			if address == "" {
+				continue
+			}
+			if strings.EqualFold(address, accountEmail) || strings.EqualFold(address, forwardTo) {
+				return nil, errForwardingLoop
+			}
+			chain = append(chain, address)
+		}
+	}
+	if len(chain) >= maxForwardHops {
+		return nil, errForwardingLoop
+	}
+	return chain, nil
+}
+
+// buildForwardRequest 按规则的方式构建转发邮件
+func (s *ForwardingServiceImpl) buildForwardRequest(ctx context.Context, rule *models.ForwardingRule, email *models.Email, raw []byte) (*SendEmailRequest, error) {
+	subject := email.Subject
+	lower := strings.ToLower(subject)
+	if !strings.HasPrefix(lower, "fwd:") && !strings.HasPrefix(lower, "fw:") {
+		subject = "Fwd: " + subject
+	}
+
+	req := &SendEmailRequest{
+		AccountID: rule.AccountID,
+		To:        []*models.EmailAddress{{Address: rule.ForwardTo}},
+		Subject:   subject,
+	}
+
+	if rule.Mode == models.ForwardingModeAttachment {
+		req.TextBody = fmt.Sprintf("Forwarded message attached.\n\nFrom: %s\nDate: %s\nSubject: %s\n",
+			email.From, email.Date.Format(time.RFC1123Z), email.Subject)
+		req.Attachments = []*SendEmailAttachment{{
+			Filename:    forwardedAttachmentName(email.Subject),
+			ContentType: "message/rfc822",
+			Content:     raw,
+			Size:        int64(len(raw)),
+			Disposition: "attachment",
+		}}
+		return req, nil
+	}
+
+	// 内联转发不附加签名
+	settings := *userSettingsOrDefault(ctx, s.db, rule.UserID)
+	settings.Signature = ""
+	content := s.sender.buildForwardedContent(email, "", "", &settings)
+	req.TextBody = content.TextBody
+	if email.HTMLBody != "" {
+		req.HTMLBody = content.HTMLBody
+	}
+
+	parsed, err := parser.ParseEmail(raw)
+	if err != nil {
+		log.Printf("Failed to parse email %d for forwarding, sending without attachments: %v", email.ID, err)
+		return req, nil
+	}
+	defer parsed.Cleanup()
+
+	for _, list := range [][]*parser.AttachmentInfo{parsed.Attachments, parsed.InlineAttachments} {
+		for _, attachment := range list {
+			attachmentContent, err := readParsedAttachment(attachment)
+			if err != nil {
+				log.Printf("Skipping attachment %s when forwarding email %d: %v", attachment.Filename, email.ID, err)
+				continue
+			}
+			// 被附件策略拦截的附件不转发
+			contentType, err := checkOutgoingAttachment(attachment.Filename, attachment.ContentType, attachmentContent)
+			if err != nil {
+				log.Printf("Skipping attachment %s when forwarding email %d: %v", attachment.Filename, email.ID, err)
+				continue
+			}
+			req.Attachments = append(req.Attachments, &SendEmailAttachment{
+				Filename:    attachment.Filename,
+				ContentType: contentType,
+				Content:     attachmentContent,
+				Size:        int64(len(attachmentContent)),
+				Disposition: attachment.Disposition,
+				ContentID:   attachment.ContentID,
+			})
+		}
+	}
+	return req, nil
+}
+
+// readParsedAttachment 读取解析出的附件内容
+func readParsedAttachment(attachment *parser.AttachmentInfo) ([]byte, error) {
+	reader, err := attachment.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// forwardedAttachmentName 作为附件转发时的文件名
+func forwardedAttachmentName(subject string) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < 0x20 {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(subject))
+	if name == "" {
+		name = "message"
+	}
+	if len([]rune(name)) > 100 {
+		name = string([]rune(name)[:100])
+	}
+	return name + ".eml"
+}
+
+// recordForwardResult 更新规则的转发统计
+func (s *ForwardingServiceImpl) recordForwardResult(ctx context.Context, ruleID uint, err error) {
+	updates := map[string]interface{}{}
+	switch {
+	case err == nil:
+		updates["forwarded_count"] = gorm.Expr("forwarded_count + 1")
+		updates["last_forwarded_at"] = time.Now()
+		updates["last_error"] = ""
+	case errors.Is(err, errForwardingLoop):
+		updates["loop_count"] = gorm.Expr("loop_count + 1")
+	default:
+		updates["failed_count"] = gorm.Expr("failed_count + 1")
+		updates["last_error"] = err.Error()
+	}
+	if err := s.db.WithContext(context.WithoutCancel(ctx)).Model(&models.ForwardingRule{}).
+		Where("id = ?", ruleID).Updates(updates).Error; err != nil {
+		log.Printf("Failed to update forwarding rule %d statistics: %v", ruleID, err)
+	}
+}
+
+// process 转发一封邮件并记录结果
+func (s *ForwardingServiceImpl) process(ctx context.Context, job forwardingJob) {
+	err := s.forward(ctx, job)
+	if errors.Is(err, errForwardingSkipped) {
+		return
+	}
+	if errors.Is(err, errForwardingLoop) {
+		log.Printf("Skipped forwarding email %d by rule %d: %v", job.emailID, job.ruleID, err)
+	} else if err != nil {
+		log.Printf("Failed to forward email %d by rule %d: %v", job.emailID, job.ruleID, err)
+	}
+	s.recordForwardResult(ctx, job.ruleID, err)
+}
+
+// Start 启动转发队列，逐封转发以免同时建立过多SMTP连接
+func (s *ForwardingServiceImpl) Start(ctx context.Context) error {
+	runCtx, cancel := context.WithCancel(ctx)
+	s.mutex.Lock()
+	s.cancel = cancel
+	s.mutex.Unlock()
+
+	log.Println("Starting forwarding queue...")
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			select {
+			case job := <-s.queue:
+				s.process(runCtx, job)
+			case <-runCtx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop 停止转发队列并等待正在转发的邮件结束
+func (s *ForwardingServiceImpl) Stop() {
+	s.mutex.Lock()
+	cancel := s.cancel
+	s.mutex.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	s.wg.Wait()
+}
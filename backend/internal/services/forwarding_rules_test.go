@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordingForwardSender 记录转发邮件而不真正发送
+type recordingForwardSender struct {
+	*EmailServiceImpl
+	sent []*SendEmailRequest
+}
+
+func (s *recordingForwardSender) SendEmail(_ context.Context, _ uint, req *SendEmailRequest) error {
+	s.sent = append(s.sent, req)
+	return nil
+}
+
+func setupForwardingTest(t *testing.T) (*emailStateServiceTestEnv, *ForwardingServiceImpl, *recordingForwardSender) {
+	t.Helper()
+
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.ForwardingRule{}))
+	env.provider.imap.rawMessages = make(map[uint32][]byte)
+
+	sender := &recordingForwardSender{EmailServiceImpl: env.service}
+	service := &ForwardingServiceImpl{
+		db:     env.db,
+		sender: sender,
+		queue:  make(chan forwardingJob, 10),
+	}
+	return env, service, sender
+}
+
+// drainForwardingQueue 处理队列中的全部转发
+func drainForwardingQueue(service *ForwardingServiceImpl) {
+	for {
+		select {
+		case job := <-service.queue:
+			service.process(context.Background(), job)
+		default:
+			return
+		}
+	}
+}
+
+func TestForwardingRuleForwardsAsAttachment(t *testing.T) {
+	env, service, sender := setupForwardingTest(t)
+	ctx := context.Background()
+
+	rule, err := service.CreateRule(ctx, env.user.ID, &ForwardingRuleRequest{
+		AccountID:       env.account.ID,
+		Name:            "Invoices to accounting",
+		ForwardTo:       "Accounting <books@external.example>",
+		Mode:            models.ForwardingModeAttachment,
+		SubjectContains: "invoice",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "books@external.example", rule.ForwardTo)
+
+	raw := []byte("From: vendor@example.org\r\nTo: tester@example.com\r\nSubject: Invoice 42\r\n\r\nPlease pay.\r\n")
+	email := env.createEmail(t, env.inbox, 7, "Invoice 42", false, false)
+	env.provider.imap.rawMessages[7] = raw
+	other := env.createEmail(t, env.inbox, 8, "Lunch", false, false)
+	filed := env.createEmail(t, env.work, 9, "Invoice 43", false, false)
+
+	for _, candidate := range []*models.Email{email, other, filed} {
+		service.ForwardNewEmail(ctx, env.account, candidate)
+	}
+	require.Len(t, service.queue, 1, "only the matching inbox email should be queued")
+	drainForwardingQueue(service)
+
+	require.Len(t, sender.sent, 1)
+	req := sender.sent[0]
+	require.Equal(t, env.account.ID, req.AccountID)
+	require.Equal(t, "books@external.example", req.To[0].Address)
+	require.Equal(t, "Fwd: Invoice 42", req.Subject)
+	require.Len(t, req.Attachments, 1)
+	require.Equal(t, "message/rfc822", req.Attachments[0].ContentType)
+	require.Equal(t, "Invoice 42.eml", req.Attachments[0].Filename)
+	require.Equal(t, raw, req.Attachments[0].Content)
+	require.Equal(t, "tester@example.com", req.Headers[forwardedByHeader])
+
+	var stored models.ForwardingRule
+	require.NoError(t, env.db.First(&stored, rule.ID).Error)
+	require.Equal(t, 1, stored.ForwardedCount)
+	require.NotNil(t, stored.LastForwardedAt)
+}
+
+func TestForwardingRuleInlineAndLoopProtection(t *testing.T) {
+	env, service, sender := setupForwardingTest(t)
+	ctx := context.Background()
+
+	rule, err := service.CreateRule(ctx, env.user.ID, &ForwardingRuleRequest{
+		AccountID: env.account.ID,
+		Name:      "Everything",
+		ForwardTo: "me@external.example",
+	})
+	require.NoError(t, err)
+	require.Equal(t, models.ForwardingModeInline, rule.Mode)
+
+	env.provider.imap.rawMessages[1] = []byte("From: friend@example.org\r\nSubject: Photos\r\n" +
+		"MIME-Version: 1.0\r\nContent-Type: multipart/mixed; boundary=b1\r\n\r\n" +
+		"--b1\r\nContent-Type: text/plain; charset=utf-8\r\n\r\nSee attached.\r\n" +
+		"--b1\r\nContent-Type: text/plain; name=notes.txt\r\nContent-Disposition: attachment; filename=notes.txt\r\n\r\ntrip notes\r\n" +
+		"--b1--\r\n")
+	// 已经过目标地址转发的邮件不再转发
+	env.provider.imap.rawMessages[2] = []byte("From: friend@example.org\r\nSubject: Looping\r\n" +
+		forwardedByHeader + ": me@external.example\r\n\r\nhi\r\n")
+
+	photos := env.createEmail(t, env.inbox, 1, "Photos", false, false)
+	looping := env.createEmail(t, env.inbox, 2, "Looping", false, false)
+	// 规则创建之前的历史邮件不转发
+	old := env.createEmail(t, env.inbox, 3, "Old", false, false)
+	require.NoError(t, env.db.Model(old).Update("date", time.Now().Add(-48*time.Hour)).Error)
+	require.NoError(t, env.db.First(old, old.ID).Error)
+
+	for _, candidate := range []*models.Email{photos, looping, old} {
+		service.ForwardNewEmail(ctx, env.account, candidate)
+	}
+	drainForwardingQueue(service)
+
+	require.Len(t, sender.sent, 1)
+	req := sender.sent[0]
+	require.Equal(t, "Fwd: Photos", req.Subject)
+	require.True(t, strings.Contains(req.TextBody, "--- Forwarded Message ---"))
+	require.Len(t, req.Attachments, 1)
+	require.Equal(t, "notes.txt", req.Attachments[0].Filename)
+	require.Equal(t, "auto-generated", req.Headers["Auto-Submitted"])
+
+	var stored models.ForwardingRule
+	require.NoError(t, env.db.First(&stored, rule.ID).Error)
+	require.Equal(t, 1, stored.ForwardedCount)
+	require.Equal(t, 1, stored.LoopCount)
+
+	// 停用的规则不再转发
+	disabled := false
+	_, err = service.UpdateRule(ctx, env.user.ID, rule.ID, &ForwardingRuleRequest{
+		AccountID: env.account.ID,
+		Name:      "Everything",
+		ForwardTo: "me@external.example",
+		IsEnabled: &disabled,
+	})
+	require.NoError(t, err)
+	service.ForwardNewEmail(ctx, env.account, photos)
+	require.Empty(t, service.queue)
+}
+
+func TestForwardingRuleValidation(t *testing.T) {
+	env, service, _ := setupForwardingTest(t)
+	ctx := context.Background()
+
+	_, err := service.CreateRule(ctx, env.user.ID, &ForwardingRuleRequest{
+		AccountID: env.account.ID,
+		Name:      "Self",
+		ForwardTo: "Tester@Example.com",
+	})
+	require.ErrorIs(t, err, ErrInvalidForwardingRule)
+
+	_, err = service.CreateRule(ctx, env.user.ID+1, &ForwardingRuleRequest{
+		AccountID: env.account.ID,
+		Name:      "Foreign",
+		ForwardTo: "me@external.example",
+	})
+	require.ErrorIs(t, err, ErrInvalidForwardingRule)
+
+	require.ErrorIs(t, service.DeleteRule(ctx, env.user.ID, 999), ErrForwardingRuleNotFound)
+}
@@ -97,6 +97,27 @@ func (d *GmailDeduplicator) checkGmailMessageIDDuplicate(ctx context.Context, me
 			}, nil
 		}
 
+		// 按标签映射配置：合并的标签只写入已有邮件的标签列表；
+		// 邮件此前只出现在合并的标签中时，移到作为文件夹同步的标签
+		if d.folderLabelMode(ctx, &folderID) == models.FolderLabelModeMerge {
+			return &DuplicateCheckResult{
+				IsDuplicate:   true,
+				ExistingEmail: &existing,
+				ConflictType:  "message_id",
+				Action:        "merge_label",
+				Reason:        "Email already synced, merging Gmail label",
+			}, nil
+		}
+		if d.folderLabelMode(ctx, existing.FolderID) == models.FolderLabelModeMerge {
+			return &DuplicateCheckResult{
+				IsDuplicate:   true,
+				ExistingEmail: &existing,
+				ConflictType:  "message_id",
+				Action:        "move_from_merged",
+				Reason:        "Email found in a Gmail label synced as folder",
+			}, nil
+		}
+
 		// 同一邮件在不同标签中，这在Gmail中是正常的
 		// 但我们需要创建一个新的记录来表示这个标签关系
 		return &DuplicateCheckResult{
@@ -132,6 +153,18 @@ func (d *GmailDeduplicator) HandleDuplicate(ctx context.Context, existing *model
 		// Gmail标签系统：同一邮件在不同标签中
 		return d.createGmailLabelReference(ctx, existing, new, folderID)
 
+	case "merge_label":
+		return d.mergeGmailLabel(ctx, existing, new, folderID)
+
+	case "move_from_merged":
+		// 保留原合并标签，再移到当前文件夹
+		if folder := d.loadFolder(ctx, existing.FolderID); folder != nil {
+			if err := addGmailFolderLabel(d.db.WithContext(ctx), existing, folder); err != nil {
+				log.Printf("Warning: failed to keep merged Gmail label: %v", err)
+			}
+		}
+		return d.updateExistingGmailEmail(ctx, existing, new, folderID)
+
 	case "update":
 		// 更新现有邮件信息
 		return d.updateExistingGmailEmail(ctx, existing, new, folderID)
@@ -163,7 +196,10 @@ func (d *GmailDeduplicator) createGmailLabelReference(ctx context.Context, exist
 		return fmt.Errorf("failed to check label reference: %w", err)
 	}
 
-	// 创建新的标签引用
+	// 创建新的标签引用，复制已有邮件的正文
+	if err := loadEmailBody(d.db.WithContext(ctx), existing); err != nil {
+		return err
+	}
 	labelEmail := &models.Email{
 		AccountID:     existing.AccountID,
 		FolderID:      &folderID,
@@ -180,7 +216,11 @@ func (d *GmailDeduplicator) createGmailLabelReference(ctx context.Context, exist
 		IsDraft:       d.isEmailDraft(new.Flags),
 		HasAttachment: existing.HasAttachment,
 		Priority:      existing.Priority,
+
+		AttachmentCount: existing.AttachmentCount,
 	}
+	applyFlagKeywords(labelEmail, new.Flags)
+	applyReplyFlags(labelEmail, new.Flags)
 
 	// 复制邮件地址信息
 	if err := d.copyEmailAddresses(existing, labelEmail); err != nil {
@@ -195,23 +235,49 @@ func (d *GmailDeduplicator) createGmailLabelReference(ctx context.Context, exist
 	return d.db.WithContext(ctx).Create(labelEmail).Error
 }
 
+// mergeGmailLabel 合并的标签中出现已同步的邮件：只写入标签，并记录该文件夹已处理的UID
+func (d *GmailDeduplicator) mergeGmailLabel(ctx context.Context, existing *models.Email, new *providers.EmailMessage, folderID uint) error {
+	return mergeGmailLabel(d.db.WithContext(ctx), existing, folderID, new.UID)
+}
+
+// loadFolder 读取文件夹，不存在或查询失败时返回nil
+func (d *GmailDeduplicator) loadFolder(ctx context.Context, folderID *uint) *models.Folder {
+	if folderID == nil {
+		return nil
+	}
+	var folder models.Folder
+	if err := d.db.WithContext(ctx).First(&folder, *folderID).Error; err != nil {
+		return nil
+	}
+	return &folder
+}
+
+// folderLabelMode 文件夹实际生效的标签映射方式，文件夹不存在时按独立文件夹处理
+func (d *GmailDeduplicator) folderLabelMode(ctx context.Context, folderID *uint) string {
+	folder := d.loadFolder(ctx, folderID)
+	if folder == nil {
+		return models.FolderLabelModeFolder
+	}
+	return gmailFolderLabelMode(folder)
+}
+
 // updateExistingGmailEmail 更新现有Gmail邮件
 func (d *GmailDeduplicator) updateExistingGmailEmail(ctx context.Context, existing *models.Email, new *providers.EmailMessage, folderID uint) error {
+	before := *existing
+
 	// 更新文件夹信息
 	existing.FolderID = &folderID
 	existing.UID = new.UID
 
 	// 更新邮件状态
-	existing.IsRead = d.isEmailRead(new.Flags)
-	existing.IsStarred = d.isEmailStarred(new.Flags)
-	existing.IsDraft = d.isEmailDraft(new.Flags)
+	d.applySyncedFlags(existing, new.Flags)
 
 	// 更新Gmail标签信息
 	if err := d.updateGmailLabels(ctx, existing, new); err != nil {
 		log.Printf("Warning: failed to update Gmail labels: %v", err)
 	}
 
-	return d.db.WithContext(ctx).Save(existing).Error
+	return saveSyncedEmailState(d.db.WithContext(ctx), &before, existing)
 }
 
 // copyEmailAddresses 复制邮件地址信息
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Gmail标签映射错误
+var (
+	ErrNotGmailAccount  = errors.New("account is not a Gmail account")
+	ErrInvalidLabelMode = errors.New("invalid label mode")
+)
+
+// gmailSystemPrefixes Gmail系统标签的文件夹前缀，部分地区为 [Google Mail]
+var gmailSystemPrefixes = []string{"[Gmail]/", "[Google Mail]/"}
+
+// gmailMergedByDefault 默认合并的Gmail虚拟文件夹，其中的邮件同时出现在其他文件夹中
+var gmailMergedByDefault = map[string]bool{"All Mail": true, "Important": true, "Starred": true}
+
+// GmailLabelMapping Gmail账户中一个标签（文件夹）的映射方式
+type GmailLabelMapping struct {
+	FolderID      uint   `json:"folder_id"`
+	Path          string `json:"path"`
+	DisplayName   string `json:"display_name"`
+	Mode          string `json:"mode,omitempty"`  // 用户设置的方式，为空表示使用默认方式
+	EffectiveMode string `json:"effective_mode"`  // 实际生效的方式
+	Configurable  bool   `json:"configurable"`    // 收件箱、已发送等系统文件夹始终作为文件夹
+	Label         string `json:"label,omitempty"` // 合并时写入邮件标签列表的名称，所有邮件文件夹为空
+}
+
+// GmailLabelModeUpdate 修改一个标签的映射方式，Mode 为空时恢复默认
+type GmailLabelModeUpdate struct {
+	FolderID uint   `json:"folder_id" binding:"required"`
+	Mode     string `json:"mode" binding:"omitempty,oneof=folder merge skip"`
+}
+
+// UpdateGmailLabelMappingsRequest 批量修改Gmail标签映射请求
+type UpdateGmailLabelMappingsRequest struct {
+	Mappings []GmailLabelModeUpdate `json:"mappings" binding:"required,dive"`
+}
+
+// gmailSystemLabel 去掉Gmail系统标签前缀，返回标签名以及是否为系统标签
+func gmailSystemLabel(path string) (string, bool) {
+	for _, prefix := range gmailSystemPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return strings.TrimPrefix(path, prefix), true
+		}
+	}
+	return path, false
+}
+
+// gmailLabelConfigurable 收件箱、已发送、草稿、垃圾箱和垃圾邮件始终作为文件夹同步
+func gmailLabelConfigurable(folder *models.Folder) bool {
+	return !folder.IsSystemFolder() && !strings.EqualFold(folder.Path, "INBOX")
+}
+
+// gmailFolderLabelMode 文件夹实际生效的映射方式：未设置时所有邮件、重要和已加星标合并，其他标签作为文件夹
+func gmailFolderLabelMode(folder *models.Folder) string {
+	if !gmailLabelConfigurable(folder) {
+		return models.FolderLabelModeFolder
+	}
+	if folder.LabelMode != "" {
+		return folder.LabelMode
+	}
+	if name, system := gmailSystemLabel(folder.Path); system && gmailMergedByDefault[name] {
+		return models.FolderLabelModeMerge
+	}
+	return models.FolderLabelModeFolder
+}
+
+// gmailFolderLabel 合并时写入邮件标签列表的名称；所有邮件不是真正的标签，返回空字符串
+func gmailFolderLabel(folder *models.Folder) string {
+	name, system := gmailSystemLabel(folder.Path)
+	if system && name == "All Mail" {
+		return ""
+	}
+	return name
+}
+
+// addGmailFolderLabel 把合并文件夹对应的标签加入邮件的标签列表
+func addGmailFolderLabel(db *gorm.DB, email *models.Email, folder *models.Folder) error {
+	label := gmailFolderLabel(folder)
+	if label == "" {
+		return nil
+	}
+	labels, err := email.GetLabels()
+	if err != nil {
+		labels = nil
+	}
+	for _, existing := range labels {
+		if existing == label {
+			return nil
+		}
+	}
+	if err := email.SetLabels(append(labels, label)); err != nil {
+		return err
+	}
+	return db.Model(email).Update("labels", email.Labels).Error
+}
+
+// mergeGmailLabel 把合并文件夹的标签写入已同步的邮件，并记录该文件夹已处理的UID
+func mergeGmailLabel(db *gorm.DB, email *models.Email, folderID uint, uid uint32) error {
+	var folder models.Folder
+	if err := db.First(&folder, folderID).Error; err != nil {
+		return fmt.Errorf("failed to get folder: %w", err)
+	}
+	if err := addGmailFolderLabel(db, email, &folder); err != nil {
+		return fmt.Errorf("failed to merge Gmail label: %w", err)
+	}
+	return recordGmailMergedUID(db, folderID, uid)
+}
+
+// recordGmailMergedUID 记录合并文件夹已处理到的UID，只增不减
+func recordGmailMergedUID(db *gorm.DB, folderID uint, uid uint32) error {
+	return db.Model(&models.Folder{}).
+		Where("id = ? AND last_merged_uid < ?", folderID, uid).
+		Update("last_merged_uid", uid).Error
+}
+
+// GetGmailLabelMappings 获取Gmail账户各标签的映射方式
+func (s *EmailServiceImpl) GetGmailLabelMappings(ctx context.Context, userID, accountID uint) ([]GmailLabelMapping, error) {
+	account, err := s.GetEmailAccount(ctx, userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.Provider != "gmail" {
+		return nil, ErrNotGmailAccount
+	}
+
+	var folders []models.Folder
+	if err := s.db.WithContext(ctx).
+		Where("account_id = ? AND is_selectable = ?", accountID, true).
+		Order("path ASC").
+		Find(&folders).Error; err != nil {
+		return nil, fmt.Errorf("failed to get folders: %w", err)
+	}
+
+	mappings := make([]GmailLabelMapping, 0, len(folders))
+	for i := range folders {
+		folder := &folders[i]
+		mapping := GmailLabelMapping{
+			FolderID:      folder.ID,
+			Path:          folder.Path,
+			DisplayName:   folder.DisplayName,
+			Mode:          folder.LabelMode,
+			EffectiveMode: gmailFolderLabelMode(folder),
+			Configurable:  gmailLabelConfigurable(folder),
+		}
+		if mapping.Configurable {
+			mapping.Label = gmailFolderLabel(folder)
+		}
+		mappings = append(mappings, mapping)
+	}
+	return mappings, nil
+}
+
+// UpdateGmailLabelMappings 修改Gmail账户的标签映射方式。改为合并或跳过时，
+// 该文件夹中已在其他文件夹保存过的邮件副本会被清除，合并时标签写入保留的邮件
+func (s *EmailServiceImpl) UpdateGmailLabelMappings(ctx context.Context, userID, accountID uint, req *UpdateGmailLabelMappingsRequest) ([]GmailLabelMapping, error) {
+	account, err := s.GetEmailAccount(ctx, userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.Provider != "gmail" {
+		return nil, ErrNotGmailAccount
+	}
+
+	var collapsed []uint
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, update := range req.Mappings {
+			switch update.Mode {
+			case "", models.FolderLabelModeFolder, models.FolderLabelModeMerge, models.FolderLabelModeSkip:
+			default:
+				return fmt.Errorf("%w: %s", ErrInvalidLabelMode, update.Mode)
+			}
+
+			var folder models.Folder
+			if err := tx.Where("id = ? AND account_id = ?", update.FolderID, accountID).First(&folder).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fmt.Errorf("folder not found")
+				}
+				return fmt.Errorf("failed to get folder: %w", err)
+			}
+			if !gmailLabelConfigurable(&folder) {
+				return fmt.Errorf("%w: %s is always synced as a folder", ErrInvalidLabelMode, folder.Path)
+			}
+			if err := tx.Model(&folder).Update("label_mode", update.Mode).Error; err != nil {
+				return fmt.Errorf("failed to update label mode: %w", err)
+			}
+
+			if mode := gmailFolderLabelMode(&folder); mode != models.FolderLabelModeFolder {
+				if err := collapseGmailFolderCopies(tx, &folder, mode == models.FolderLabelModeMerge); err != nil {
+					return err
+				}
+				collapsed = append(collapsed, folder.ID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range collapsed {
+		s.invalidateEmailListCache(userID, accountID, &collapsed[i])
+		recordFolderChanges(ctx, s.changeLog, userID, accountID, &collapsed[i])
+	}
+	return s.GetGmailLabelMappings(ctx, userID, accountID)
+}
+
+// collapseGmailFolderCopies 清除文件夹中已在账户其他作为文件夹同步的标签中保存过的邮件副本，
+// addLabel 为 true 时把该文件夹的标签写入保留的邮件；没有其他副本的邮件保留
+func collapseGmailFolderCopies(tx *gorm.DB, folder *models.Folder, addLabel bool) error {
+	var folders []models.Folder
+	if err := tx.Where("account_id = ? AND id <> ?", folder.AccountID, folder.ID).Find(&folders).Error; err != nil {
+		return fmt.Errorf("failed to get folders: %w", err)
+	}
+	var targets []uint
+	for i := range folders {
+		if gmailFolderLabelMode(&folders[i]) == models.FolderLabelModeFolder {
+			targets = append(targets, folders[i].ID)
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	var copies []models.Email
+	if err := tx.Model(&models.Email{}).
+		Select("id, message_id").
+		Where("folder_id = ? AND message_id <> ''", folder.ID).
+		Where("EXISTS (SELECT 1 FROM emails other WHERE other.account_id = emails.account_id AND other.message_id = emails.message_id AND other.folder_id IN ? AND other.deleted_at IS NULL)", targets).
+		Find(&copies).Error; err != nil {
+		return fmt.Errorf("failed to find duplicated label copies: %w", err)
+	}
+	if len(copies) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(copies))
+	for _, email := range copies {
+		ids = append(ids, email.ID)
+		if !addLabel {
+			continue
+		}
+		var kept []models.Email
+		if err := tx.Where("account_id = ? AND message_id = ? AND folder_id IN ?", folder.AccountID, email.MessageID, targets).
+			Find(&kept).Error; err != nil {
+			return fmt.Errorf("failed to load label targets: %w", err)
+		}
+		for i := range kept {
+			if err := addGmailFolderLabel(tx, &kept[i], folder); err != nil {
+				log.Printf("Warning: failed to add Gmail label to email %d: %v", kept[i].ID, err)
+			}
+		}
+	}
+
+	if _, err := purgeEmails(tx, "id IN ?", ids); err != nil {
+		return fmt.Errorf("failed to remove duplicated label copies: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"firemail/internal/models"
+	"firemail/internal/providers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGmailLabelMappingsCollapseCopies(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+
+	_, err := env.service.GetGmailLabelMappings(ctx, env.user.ID, env.account.ID)
+	require.True(t, errors.Is(err, ErrNotGmailAccount))
+	require.NoError(t, env.db.Model(env.account).Update("provider", "gmail").Error)
+
+	allMail := &models.Folder{
+		AccountID:    env.account.ID,
+		Name:         "All Mail",
+		Type:         models.FolderTypeCustom,
+		Path:         "[Gmail]/All Mail",
+		Delimiter:    "/",
+		IsSelectable: true,
+	}
+	require.NoError(t, env.db.Create(allMail).Error)
+
+	mappings, err := env.service.GetGmailLabelMappings(ctx, env.user.ID, env.account.ID)
+	require.NoError(t, err)
+	modes := make(map[uint]GmailLabelMapping)
+	for _, mapping := range mappings {
+		modes[mapping.FolderID] = mapping
+	}
+	require.Equal(t, models.FolderLabelModeMerge, modes[allMail.ID].EffectiveMode)
+	require.Empty(t, modes[allMail.ID].Label)
+	require.Equal(t, models.FolderLabelModeFolder, modes[env.work.ID].EffectiveMode)
+	require.False(t, modes[env.inbox.ID].Configurable)
+
+	// 收件箱始终作为文件夹同步
+	_, err = env.service.UpdateGmailLabelMappings(ctx, env.user.ID, env.account.ID, &UpdateGmailLabelMappingsRequest{
+		Mappings: []GmailLabelModeUpdate{{FolderID: env.inbox.ID, Mode: models.FolderLabelModeSkip}},
+	})
+	require.True(t, errors.Is(err, ErrInvalidLabelMode))
+
+	kept := env.createEmail(t, env.inbox, 1, "report", false, false)
+	copyInWork := env.createEmail(t, env.work, 7, "report", false, false)
+	onlyInWork := env.createEmail(t, env.work, 8, "archive", false, false)
+	require.NoError(t, env.db.Model(copyInWork).Update("message_id", kept.MessageID).Error)
+
+	mappings, err = env.service.UpdateGmailLabelMappings(ctx, env.user.ID, env.account.ID, &UpdateGmailLabelMappingsRequest{
+		Mappings: []GmailLabelModeUpdate{{FolderID: env.work.ID, Mode: models.FolderLabelModeMerge}},
+	})
+	require.NoError(t, err)
+	for _, mapping := range mappings {
+		if mapping.FolderID == env.work.ID {
+			require.Equal(t, models.FolderLabelModeMerge, mapping.EffectiveMode)
+			require.Equal(t, "Projects", mapping.Label)
+		}
+	}
+
+	var count int64
+	require.NoError(t, env.db.Model(&models.Email{}).Where("id = ?", copyInWork.ID).Count(&count).Error)
+	require.Zero(t, count)
+	require.NoError(t, env.db.Model(&models.Email{}).Where("id = ?", onlyInWork.ID).Count(&count).Error)
+	require.EqualValues(t, 1, count)
+
+	require.NoError(t, env.db.First(kept, kept.ID).Error)
+	labels, err := kept.GetLabels()
+	require.NoError(t, err)
+	require.Equal(t, []string{"Projects"}, labels)
+}
+
+func TestGmailDeduplicatorHonorsLabelMapping(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+	require.NoError(t, env.db.Model(env.work).Update("label_mode", models.FolderLabelModeMerge).Error)
+
+	dedup := NewGmailDeduplicator(env.db)
+
+	// 已在收件箱的邮件出现在合并的标签中：只写入标签
+	inInbox := env.createEmail(t, env.inbox, 1, "invoice", false, false)
+	message := &providers.EmailMessage{MessageID: inInbox.MessageID, UID: 42}
+	result, err := dedup.CheckDuplicate(ctx, message, env.account.ID, env.work.ID)
+	require.NoError(t, err)
+	require.Equal(t, "merge_label", result.Action)
+	require.NoError(t, dedup.HandleDuplicate(ctx, result.ExistingEmail, message, env.work.ID))
+
+	require.NoError(t, env.db.First(inInbox, inInbox.ID).Error)
+	labels, err := inInbox.GetLabels()
+	require.NoError(t, err)
+	require.Equal(t, []string{"Projects"}, labels)
+	var work models.Folder
+	require.NoError(t, env.db.First(&work, env.work.ID).Error)
+	require.EqualValues(t, 42, work.LastMergedUID)
+
+	// 此前只在合并标签中的邮件出现在收件箱：移到收件箱并保留标签
+	merged := env.createEmail(t, env.work, 5, "receipt", false, false)
+	message = &providers.EmailMessage{MessageID: merged.MessageID, UID: 9}
+	result, err = dedup.CheckDuplicate(ctx, message, env.account.ID, env.inbox.ID)
+	require.NoError(t, err)
+	require.Equal(t, "move_from_merged", result.Action)
+	require.NoError(t, dedup.HandleDuplicate(ctx, result.ExistingEmail, message, env.inbox.ID))
+
+	require.NoError(t, env.db.First(merged, merged.ID).Error)
+	require.Equal(t, env.inbox.ID, *merged.FolderID)
+	labels, err = merged.GetLabels()
+	require.NoError(t, err)
+	require.Contains(t, labels, "Projects")
+}
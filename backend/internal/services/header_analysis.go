@@ -0,0 +1,352 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ReceivedHop Received邮件头解析出的一跳投递，按投递顺序排列
+type ReceivedHop struct {
+	Index        int        `json:"index"` // 从1开始，1为最早的一跳
+	From         string     `json:"from,omitempty"`
+	FromIP       string     `json:"from_ip,omitempty"`
+	By           string     `json:"by,omitempty"`
+	With         string     `json:"with,omitempty"`
+	ID           string     `json:"id,omitempty"`
+	For          string     `json:"for,omitempty"`
+	Timestamp    *time.Time `json:"timestamp,omitempty"`
+	DelaySeconds *float64   `json:"delay_seconds,omitempty"` // 与上一跳的时间差
+	Raw          string     `json:"raw"`
+}
+
+// AuthenticationResult SPF/DKIM/DMARC等认证结果，来自接收服务器添加的邮件头
+type AuthenticationResult struct {
+	Method     string            `json:"method"` // spf、dkim、dmarc、arc等
+	Result     string            `json:"result"` // pass、fail、softfail、neutral、none等
+	Reason     string            `json:"reason,omitempty"`
+	Properties map[string]string `json:"properties,omitempty"` // 如smtp.mailfrom、header.d
+	Source     string            `json:"source"`               // 添加结果的服务器（authserv-id）或邮件头名称
+}
+
+// OriginatingIP 邮件来源IP
+type OriginatingIP struct {
+	IP       string     `json:"ip"`
+	Source   string     `json:"source"` // 取自的邮件头
+	Private  bool       `json:"private"`
+	Geo      *IPGeoInfo `json:"geo,omitempty"`
+	GeoError string     `json:"geo_error,omitempty"`
+}
+
+// HeaderAnalysis 邮件头分析结果
+type HeaderAnalysis struct {
+	EmailID           uint                   `json:"email_id"`
+	Hops              []ReceivedHop          `json:"hops"`
+	TotalDelaySeconds *float64               `json:"total_delay_seconds,omitempty"` // 第一跳到最后一跳的时间差
+	SPF               string                 `json:"spf,omitempty"`
+	DKIM              string                 `json:"dkim,omitempty"`
+	DMARC             string                 `json:"dmarc,omitempty"`
+	Authentication    []AuthenticationResult `json:"authentication"`
+	DKIMDomains       []string               `json:"dkim_domains,omitempty"` // DKIM-Signature中的签名域
+	OriginatingIP     *OriginatingIP         `json:"originating_ip,omitempty"`
+	Warnings          []string               `json:"warnings,omitempty"`
+}
+
+// 超过该时长的单跳延迟视为异常
+const slowHopThreshold = 10 * time.Minute
+
+var (
+	bracketIPPattern = regexp.MustCompile(`\[(?:IPv6:)?([0-9A-Fa-f:.]+)\]`)
+	ipv4Pattern      = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+	headerCommentRE  = regexp.MustCompile(`\([^()]*\)`)
+)
+
+// AnalyzeEmailHeaders 分析邮件的Received投递链、认证结果和来源IP
+func (s *EmailServiceImpl) AnalyzeEmailHeaders(ctx context.Context, userID, emailID uint) (*HeaderAnalysis, error) {
+	source, err := s.GetEmailSource(ctx, userID, emailID)
+	if err != nil {
+		return nil, err
+	}
+
+	analysis := analyzeEmailHeaders(source.Headers)
+	analysis.EmailID = source.EmailID
+
+	if origin := analysis.OriginatingIP; origin != nil && !origin.Private && s.geoLocator != nil {
+		geo, err := s.geoLocator.Locate(ctx, origin.IP)
+		if err != nil {
+			log.Printf("Warning: failed to locate IP %s: %v", origin.IP, err)
+			origin.GeoError = err.Error()
+		} else {
+			origin.Geo = geo
+		}
+	}
+	return analysis, nil
+}
+
+// analyzeEmailHeaders 根据邮件头生成分析结果，不包含地理位置
+func analyzeEmailHeaders(headers []EmailHeaderField) *HeaderAnalysis {
+	analysis := &HeaderAnalysis{
+		Hops:           []ReceivedHop{},
+		Authentication: []AuthenticationResult{},
+	}
+
+	// Received由每台服务器添加在最前面，倒序即为投递顺序
+	var received []string
+	for _, header := range headers {
+		switch strings.ToLower(header.Name) {
+		case "received":
+			received = append(received, header.Value)
+		case "authentication-results":
+			analysis.Authentication = append(analysis.Authentication, parseAuthenticationResults(header.Value)...)
+		case "received-spf":
+			if result := parseReceivedSPF(header.Value); result != nil {
+				analysis.Authentication = append(analysis.Authentication, *result)
+			}
+		case "dkim-signature":
+			if domain := dkimSignatureDomain(header.Value); domain != "" {
+				analysis.DKIMDomains = append(analysis.DKIMDomains, domain)
+			}
+		}
+	}
+
+	var previous *time.Time
+	for i := len(received) - 1; i >= 0; i-- {
+		hop := parseReceivedHeader(received[i])
+		hop.Index = len(analysis.Hops) + 1
+		if hop.Timestamp != nil && previous != nil {
+			delay := hop.Timestamp.Sub(*previous)
+			seconds := delay.Seconds()
+			hop.DelaySeconds = &seconds
+			switch {
+			case delay < 0:
+				analysis.Warnings = append(analysis.Warnings, fmt.Sprintf("hop %d is timestamped before the previous hop (clock skew)", hop.Index))
+			case delay > slowHopThreshold:
+				analysis.Warnings = append(analysis.Warnings, fmt.Sprintf("hop %d was delayed by %s", hop.Index, delay.Round(time.Second)))
+			}
+		}
+		if hop.Timestamp != nil {
+			previous = hop.Timestamp
+		}
+		analysis.Hops = append(analysis.Hops, hop)
+	}
+	if first, last := firstHopTime(analysis.Hops), lastHopTime(analysis.Hops); first != nil && last != nil {
+		total := last.Sub(*first).Seconds()
+		analysis.TotalDelaySeconds = &total
+	}
+
+	analysis.SPF = authenticationSummary(analysis.Authentication, "spf")
+	analysis.DKIM = authenticationSummary(analysis.Authentication, "dkim")
+	analysis.DMARC = authenticationSummary(analysis.Authentication, "dmarc")
+	for _, check := range []struct{ method, result string }{{"SPF", analysis.SPF}, {"DKIM", analysis.DKIM}, {"DMARC", analysis.DMARC}} {
+		if check.result == "fail" || check.result == "softfail" || check.result == "permerror" {
+			analysis.Warnings = append(analysis.Warnings, fmt.Sprintf("%s check result: %s", check.method, check.result))
+		}
+	}
+
+	analysis.OriginatingIP = findOriginatingIP(headers, analysis.Hops)
+	return analysis
+}
+
+// parseReceivedHeader 解析Received邮件头，格式如 from a (b [1.2.3.4]) by c with ESMTPS id x for <y>; 日期
+func parseReceivedHeader(value string) ReceivedHop {
+	hop := ReceivedHop{Raw: value}
+
+	clauses := value
+	if semicolon := strings.LastIndex(value, ";"); semicolon >= 0 {
+		clauses = value[:semicolon]
+		if date, err := mail.ParseDate(strings.TrimSpace(headerCommentRE.ReplaceAllString(value[semicolon+1:], ""))); err == nil {
+			hop.Timestamp = &date
+		}
+	}
+
+	fields := map[string]*string{"from": &hop.From, "by": &hop.By, "with": &hop.With, "id": &hop.ID, "for": &hop.For}
+	var current *string
+	var fromClause strings.Builder
+	inFrom := false
+	depth := 0
+	for _, word := range strings.Fields(clauses) {
+		if depth == 0 {
+			if target, ok := fields[strings.ToLower(word)]; ok {
+				current = target
+				inFrom = strings.EqualFold(word, "from")
+				continue
+			}
+		}
+		inComment := depth > 0 || strings.HasPrefix(word, "(")
+		depth += strings.Count(word, "(") - strings.Count(word, ")")
+		if depth < 0 {
+			depth = 0
+		}
+		if inFrom {
+			fromClause.WriteString(word + " ")
+		}
+		// 关键字后的第一个词是值，括号里的注释只用于提取IP
+		if current != nil && *current == "" && !inComment {
+			*current = strings.Trim(word, "<>")
+		}
+	}
+
+	if match := bracketIPPattern.FindStringSubmatch(fromClause.String()); match != nil && net.ParseIP(match[1]) != nil {
+		hop.FromIP = match[1]
+	} else if ip := ipv4Pattern.FindString(fromClause.String()); ip != "" && net.ParseIP(ip) != nil {
+		hop.FromIP = ip
+	}
+	return hop
+}
+
+// parseAuthenticationResults 解析Authentication-Results，格式如 mx.example.com; spf=pass smtp.mailfrom=a.com; dkim=pass header.d=a.com
+func parseAuthenticationResults(value string) []AuthenticationResult {
+	segments := strings.Split(value, ";")
+	authServID := ""
+	if words := strings.Fields(headerCommentRE.ReplaceAllString(segments[0], " ")); len(words) > 0 {
+		authServID = words[0]
+	}
+
+	var results []AuthenticationResult
+	for _, segment := range segments[1:] {
+		result := parseAuthenticationResult(segment)
+		if result == nil {
+			continue
+		}
+		result.Source = authServID
+		results = append(results, *result)
+	}
+	return results
+}
+
+// parseAuthenticationResult 解析单个 method=result (reason) key=value 片段
+func parseAuthenticationResult(segment string) *AuthenticationResult {
+	reason := ""
+	if match := headerCommentRE.FindString(segment); match != "" {
+		reason = strings.Trim(match, "()")
+	}
+	words := strings.Fields(headerCommentRE.ReplaceAllString(segment, " "))
+	if len(words) == 0 {
+		return nil
+	}
+
+	method, value, ok := strings.Cut(words[0], "=")
+	if !ok || method == "" || value == "" {
+		return nil
+	}
+	result := &AuthenticationResult{
+		Method: strings.ToLower(method),
+		Result: strings.ToLower(value),
+		Reason: reason,
+	}
+	for _, word := range words[1:] {
+		key, propValue, ok := strings.Cut(word, "=")
+		if !ok {
+			continue
+		}
+		if strings.EqualFold(key, "reason") {
+			result.Reason = strings.Trim(propValue, `"`)
+			continue
+		}
+		if result.Properties == nil {
+			result.Properties = make(map[string]string)
+		}
+		result.Properties[key] = strings.Trim(propValue, `"`)
+	}
+	return result
+}
+
+// parseReceivedSPF 解析Received-SPF，格式如 pass (reason) client-ip=1.2.3.4; envelope-from=a@b.com
+func parseReceivedSPF(value string) *AuthenticationResult {
+	words := strings.Fields(strings.ReplaceAll(headerCommentRE.ReplaceAllString(value, " "), ";", " "))
+	if len(words) == 0 {
+		return nil
+	}
+	result := &AuthenticationResult{Method: "spf", Result: strings.ToLower(words[0]), Source: "Received-SPF"}
+	if match := headerCommentRE.FindString(value); match != "" {
+		result.Reason = strings.Trim(match, "()")
+	}
+	for _, word := range words[1:] {
+		if key, propValue, ok := strings.Cut(word, "="); ok {
+			if result.Properties == nil {
+				result.Properties = make(map[string]string)
+			}
+			result.Properties[key] = strings.Trim(propValue, `"`)
+		}
+	}
+	return result
+}
+
+// dkimSignatureDomain 取出DKIM-Signature的d=签名域
+func dkimSignatureDomain(value string) string {
+	for _, tag := range strings.Split(value, ";") {
+		key, tagValue, ok := strings.Cut(strings.TrimSpace(tag), "=")
+		if ok && strings.TrimSpace(key) == "d" {
+			return strings.ToLower(strings.TrimSpace(tagValue))
+		}
+	}
+	return ""
+}
+
+// authenticationSummary 汇总某种认证的结果，多个结果时取最早添加（最靠近收件人）的服务器给出的结果
+func authenticationSummary(results []AuthenticationResult, method string) string {
+	for _, result := range results {
+		if result.Method == method {
+			return result.Result
+		}
+	}
+	return ""
+}
+
+// findOriginatingIP 确定来源IP：优先使用X-Originating-IP等邮件头，否则取投递链中第一个公网IP
+func findOriginatingIP(headers []EmailHeaderField, hops []ReceivedHop) *OriginatingIP {
+	for _, name := range []string{"X-Originating-IP", "X-Sender-IP", "X-Client-IP"} {
+		for _, header := range headers {
+			if !strings.EqualFold(header.Name, name) {
+				continue
+			}
+			ip := strings.Trim(strings.TrimSpace(header.Value), "[]")
+			if parsed := net.ParseIP(ip); parsed != nil {
+				return &OriginatingIP{IP: ip, Source: name, Private: isNonPublicIP(parsed)}
+			}
+		}
+	}
+
+	var fallback *OriginatingIP
+	for _, hop := range hops {
+		parsed := net.ParseIP(hop.FromIP)
+		if parsed == nil {
+			continue
+		}
+		if !isNonPublicIP(parsed) {
+			return &OriginatingIP{IP: hop.FromIP, Source: "Received", Private: false}
+		}
+		if fallback == nil {
+			fallback = &OriginatingIP{IP: hop.FromIP, Source: "Received", Private: true}
+		}
+	}
+	return fallback
+}
+
+// isNonPublicIP 是否为内网、回环或链路本地地址
+func isNonPublicIP(ip net.IP) bool {
+	return ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsUnspecified()
+}
+
+func firstHopTime(hops []ReceivedHop) *time.Time {
+	for _, hop := range hops {
+		if hop.Timestamp != nil {
+			return hop.Timestamp
+		}
+	}
+	return nil
+}
+
+func lastHopTime(hops []ReceivedHop) *time.Time {
+	for i := len(hops) - 1; i >= 0; i-- {
+		if hops[i].Timestamp != nil {
+			return hops[i].Timestamp
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"firemail/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+var headerAnalysisTestHeaders = []EmailHeaderField{
+	{Name: "Received", Value: "from mx.example.org (mx.example.org [10.0.0.5]) by inbox.example.org with LMTP id abc for <user@example.org>; Mon, 1 Jan 2024 10:20:05 +0000"},
+	{Name: "Received", Value: "from mail.sender.com (mail.sender.com [203.0.113.7]) by mx.example.org with ESMTPS id 42; Mon, 1 Jan 2024 10:00:05 +0000"},
+	{Name: "Received", Value: "from (unknown [192.168.1.10]) by mail.sender.com with ESMTPSA; Mon, 1 Jan 2024 10:00:00 +0000"},
+	{Name: "Authentication-Results", Value: "mx.example.org; spf=softfail (domain does not designate) smtp.mailfrom=sender.com; dkim=pass header.d=sender.com; dmarc=pass header.from=sender.com"},
+	{Name: "DKIM-Signature", Value: "v=1; a=rsa-sha256; d=Sender.com; s=sel; b=abc"},
+	{Name: "From", Value: "sender@sender.com"},
+}
+
+func TestAnalyzeEmailHeadersHops(t *testing.T) {
+	analysis := analyzeEmailHeaders(headerAnalysisTestHeaders)
+
+	require.Len(t, analysis.Hops, 3)
+	first, second, third := analysis.Hops[0], analysis.Hops[1], analysis.Hops[2]
+
+	require.Equal(t, 1, first.Index)
+	require.Empty(t, first.From)
+	require.Equal(t, "192.168.1.10", first.FromIP)
+	require.Equal(t, "mail.sender.com", first.By)
+	require.Equal(t, "ESMTPSA", first.With)
+	require.Nil(t, first.DelaySeconds)
+
+	require.Equal(t, "mail.sender.com", second.From)
+	require.Equal(t, "203.0.113.7", second.FromIP)
+	require.Equal(t, "42", second.ID)
+	require.NotNil(t, second.DelaySeconds)
+	require.Equal(t, 5.0, *second.DelaySeconds)
+
+	require.Equal(t, "user@example.org", third.For)
+	require.Equal(t, 1200.0, *third.DelaySeconds)
+	require.Equal(t, 1205.0, *analysis.TotalDelaySeconds)
+	require.Contains(t, analysis.Warnings, "hop 3 was delayed by 20m0s")
+}
+
+func TestAnalyzeEmailHeadersAuthentication(t *testing.T) {
+	analysis := analyzeEmailHeaders(headerAnalysisTestHeaders)
+
+	require.Equal(t, "softfail", analysis.SPF)
+	require.Equal(t, "pass", analysis.DKIM)
+	require.Equal(t, "pass", analysis.DMARC)
+	require.Len(t, analysis.Authentication, 3)
+	require.Equal(t, "mx.example.org", analysis.Authentication[0].Source)
+	require.Equal(t, "domain does not designate", analysis.Authentication[0].Reason)
+	require.Equal(t, "sender.com", analysis.Authentication[0].Properties["smtp.mailfrom"])
+	require.Equal(t, []string{"sender.com"}, analysis.DKIMDomains)
+	require.Contains(t, analysis.Warnings, "SPF check result: softfail")
+
+	// 没有Authentication-Results时使用Received-SPF
+	fallback := analyzeEmailHeaders([]EmailHeaderField{
+		{Name: "Received-SPF", Value: "Pass (sender SPF authorized) client-ip=203.0.113.7; envelope-from=a@sender.com"},
+	})
+	require.Equal(t, "pass", fallback.SPF)
+	require.Equal(t, "203.0.113.7", fallback.Authentication[0].Properties["client-ip"])
+}
+
+func TestAnalyzeEmailHeadersOriginatingIP(t *testing.T) {
+	// 跳过内网IP，取第一个公网IP
+	origin := analyzeEmailHeaders(headerAnalysisTestHeaders).OriginatingIP
+	require.NotNil(t, origin)
+	require.Equal(t, "203.0.113.7", origin.IP)
+	require.Equal(t, "Received", origin.Source)
+	require.False(t, origin.Private)
+
+	// X-Originating-IP优先
+	headers := append([]EmailHeaderField{{Name: "X-Originating-IP", Value: "[198.51.100.2]"}}, headerAnalysisTestHeaders...)
+	origin = analyzeEmailHeaders(headers).OriginatingIP
+	require.Equal(t, "198.51.100.2", origin.IP)
+	require.Equal(t, "X-Originating-IP", origin.Source)
+
+	// 只有内网IP时标记为private
+	origin = analyzeEmailHeaders(headerAnalysisTestHeaders[2:3]).OriginatingIP
+	require.Equal(t, "192.168.1.10", origin.IP)
+	require.True(t, origin.Private)
+}
+
+func TestHTTPIPGeoLocator(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if strings.HasSuffix(r.URL.Path, "/203.0.113.7") {
+			w.Write([]byte(`{"status":"success","country":"Japan","countryCode":"JP","city":"Tokyo","isp":"Example ISP"}`))
+			return
+		}
+		w.Write([]byte(`{"status":"fail","message":"reserved range"}`))
+	}))
+	defer server.Close()
+
+	require.Nil(t, NewHTTPIPGeoLocator(config.GeoIPConfig{}))
+	locator := NewHTTPIPGeoLocator(config.GeoIPConfig{LookupURL: server.URL + "/json/{ip}", Timeout: time.Second})
+
+	info, err := locator.Locate(context.Background(), "203.0.113.7")
+	require.NoError(t, err)
+	require.Equal(t, "JP", info.CountryCode)
+	require.Equal(t, "Tokyo", info.City)
+
+	// 结果已缓存
+	_, err = locator.Locate(context.Background(), "203.0.113.7")
+	require.NoError(t, err)
+	require.Equal(t, 1, requests)
+
+	_, err = locator.Locate(context.Background(), "198.51.100.2")
+	require.EqualError(t, err, "geoip lookup failed: reserved range")
+}
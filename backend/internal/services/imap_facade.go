@@ -0,0 +1,488 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"sort"
+	"strings"
+
+	"firemail/internal/imapd"
+	"firemail/internal/models"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-message"
+	gomail "github.com/emersion/go-message/mail"
+	"gorm.io/gorm"
+)
+
+// imapAccountsPrefix 各账户文件夹的上级文件夹
+const imapAccountsPrefix = "Accounts"
+
+// imapUnifiedFolders 按类型合并所有账户的统一文件夹及其SPECIAL-USE属性
+var imapUnifiedFolders = []struct {
+	name       string
+	folderType string
+	attribute  string
+}{
+	{name: "INBOX", folderType: models.FolderTypeInbox},
+	{name: "Sent", folderType: models.FolderTypeSent, attribute: imap.SentAttr},
+	{name: "Drafts", folderType: models.FolderTypeDrafts, attribute: imap.DraftsAttr},
+	{name: "Trash", folderType: models.FolderTypeTrash, attribute: imap.TrashAttr},
+	{name: "Junk", folderType: models.FolderTypeSpam, attribute: imap.JunkAttr},
+}
+
+// IMAPFacadeStore 本地IMAP服务器外观的存储：读取本地同步的邮件，标记、移动和删除经由邮件服务同步到邮件服务器
+type IMAPFacadeStore struct {
+	db           *gorm.DB
+	emailService EmailService
+	storage      AttachmentStorage
+}
+
+// NewIMAPFacadeStore 创建IMAP外观存储，storage为空时邮件不包含附件内容
+func NewIMAPFacadeStore(db *gorm.DB, emailService EmailService, storage AttachmentStorage) *IMAPFacadeStore {
+	return &IMAPFacadeStore{db: db, emailService: emailService, storage: storage}
+}
+
+// Authenticate 使用FireMail的用户名和密码登录
+func (s *IMAPFacadeStore) Authenticate(ctx context.Context, username, password string) (uint, error) {
+	var user models.User
+	if err := s.db.WithContext(ctx).Where("username = ?", username).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, imapd.ErrInvalidCredentials
+		}
+		return 0, err
+	}
+	if !user.IsActive || !user.CheckPassword(password) {
+		return 0, imapd.ErrInvalidCredentials
+	}
+	return user.ID, nil
+}
+
+// ListFolders 列出统一文件夹和各账户的文件夹
+func (s *IMAPFacadeStore) ListFolders(ctx context.Context, userID uint) ([]imapd.Folder, error) {
+	folderIDs, err := s.resolveFolders(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	folders := make([]imapd.Folder, 0, len(folderIDs))
+	for _, unified := range imapUnifiedFolders {
+		folder := imapd.Folder{Name: unified.name}
+		if unified.attribute != "" {
+			folder.Attributes = []string{unified.attribute}
+		}
+		folders = append(folders, folder)
+	}
+
+	names := make([]string, 0, len(folderIDs))
+	for name := range folderIDs {
+		if strings.HasPrefix(name, imapAccountsPrefix+imapd.Delimiter) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		folders = append(folders, imapd.Folder{Name: name})
+	}
+	return folders, nil
+}
+
+// ListMessages 列出文件夹中未删除的邮件，UID即邮件ID
+func (s *IMAPFacadeStore) ListMessages(ctx context.Context, userID uint, folder string) ([]imapd.Message, error) {
+	folderIDs, err := s.resolveFolders(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	ids, ok := folderIDs[folder]
+	if !ok {
+		return nil, imapd.ErrNoSuchFolder
+	}
+	if len(ids) == 0 {
+		return []imapd.Message{}, nil
+	}
+
+	var emails []models.Email
+	if err := s.db.WithContext(ctx).
+		Select("id", "date", "is_read", "is_starred", "is_draft").
+		Where("user_id = ? AND folder_id IN ? AND is_deleted = ?", userID, ids, false).
+		Order("id ASC").
+		Find(&emails).Error; err != nil {
+		return nil, fmt.Errorf("failed to list emails: %w", err)
+	}
+
+	messages := make([]imapd.Message, len(emails))
+	for i, email := range emails {
+		messages[i] = imapd.Message{UID: uint32(email.ID), Flags: imapFlags(&email), Date: email.Date}
+	}
+	return messages, nil
+}
+
+// FetchMessage 用本地保存的邮件头、正文和已下载的附件生成邮件内容，分隔符由邮件ID决定，
+// 同一封邮件每次生成的内容相同
+func (s *IMAPFacadeStore) FetchMessage(ctx context.Context, userID uint, uid uint32) ([]byte, error) {
+	var email models.Email
+	if err := s.db.WithContext(ctx).
+		Preload("Attachments", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Body").
+		Where("id = ? AND user_id = ?", uid, userID).
+		First(&email).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("email not found")
+		}
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := writeLocalMessage(ctx, s.storage, &buf, &email); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SetFlag \Seen 对应已读，\Flagged 对应星标
+func (s *IMAPFacadeStore) SetFlag(ctx context.Context, userID uint, uids []uint32, flag string, value bool) error {
+	var apply func(ctx context.Context, userID, emailID uint) error
+	switch {
+	case flag == imap.SeenFlag && value:
+		apply = s.emailService.MarkEmailAsRead
+	case flag == imap.SeenFlag:
+		apply = s.emailService.MarkEmailAsUnread
+	case flag == imap.FlaggedFlag && value:
+		apply = s.emailService.MarkEmailAsStarred
+	case flag == imap.FlaggedFlag:
+		apply = s.emailService.MarkEmailAsUnstarred
+	default:
+		return imapd.ErrNotSupported
+	}
+
+	for _, uid := range uids {
+		if err := apply(ctx, userID, uint(uid)); err != nil {
+			return fmt.Errorf("failed to update email %d: %w", uid, err)
+		}
+	}
+	return nil
+}
+
+// MoveMessages 移动到目标文件夹；目标为统一文件夹时移动到邮件所属账户的同类型文件夹
+func (s *IMAPFacadeStore) MoveMessages(ctx context.Context, userID uint, uids []uint32, dest string) error {
+	folderIDs, err := s.resolveFolders(ctx, userID)
+	if err != nil {
+		return err
+	}
+	destIDs, ok := folderIDs[dest]
+	if !ok {
+		return imapd.ErrNoSuchFolder
+	}
+
+	var destFolders []models.Folder
+	if len(destIDs) > 0 {
+		if err := s.db.WithContext(ctx).Select("id", "account_id").Where("id IN ?", destIDs).Find(&destFolders).Error; err != nil {
+			return fmt.Errorf("failed to load folders: %w", err)
+		}
+	}
+	folderByAccount := make(map[uint]uint, len(destFolders))
+	for _, folder := range destFolders {
+		if _, ok := folderByAccount[folder.AccountID]; !ok {
+			folderByAccount[folder.AccountID] = folder.ID
+		}
+	}
+
+	emails, err := s.loadEmails(ctx, userID, uids)
+	if err != nil {
+		return err
+	}
+	for _, email := range emails {
+		folderID, ok := folderByAccount[email.AccountID]
+		if !ok {
+			return fmt.Errorf("email %d cannot be moved to %s: folder belongs to another account", email.ID, dest)
+		}
+		if err := s.emailService.MoveEmail(ctx, userID, email.ID, folderID); err != nil {
+			return fmt.Errorf("failed to move email %d: %w", email.ID, err)
+		}
+	}
+	return nil
+}
+
+// DeleteMessages 按邮件服务的删除流程处理，受法律保全保护的邮件会删除失败
+func (s *IMAPFacadeStore) DeleteMessages(ctx context.Context, userID uint, uids []uint32) error {
+	for _, uid := range uids {
+		if err := s.emailService.DeleteEmail(ctx, userID, uint(uid)); err != nil {
+			return fmt.Errorf("failed to delete email %d: %w", uid, err)
+		}
+	}
+	return nil
+}
+
+// resolveFolders 返回外观文件夹名称到本地文件夹ID的映射，统一文件夹可能对应多个账户的文件夹
+func (s *IMAPFacadeStore) resolveFolders(ctx context.Context, userID uint) (map[string][]uint, error) {
+	var accounts []models.EmailAccount
+	if err := s.db.WithContext(ctx).Select("id", "email").Where("user_id = ?", userID).Find(&accounts).Error; err != nil {
+		return nil, fmt.Errorf("failed to load email accounts: %w", err)
+	}
+	accountEmails := make(map[uint]string, len(accounts))
+	accountIDs := make([]uint, 0, len(accounts))
+	for _, account := range accounts {
+		accountEmails[account.ID] = account.Email
+		accountIDs = append(accountIDs, account.ID)
+	}
+
+	result := make(map[string][]uint)
+	for _, unified := range imapUnifiedFolders {
+		result[unified.name] = nil
+	}
+	if len(accountIDs) == 0 {
+		return result, nil
+	}
+
+	var folders []models.Folder
+	if err := s.db.WithContext(ctx).
+		Where("account_id IN ? AND is_selectable = ?", accountIDs, true).
+		Order("id ASC").
+		Find(&folders).Error; err != nil {
+		return nil, fmt.Errorf("failed to load folders: %w", err)
+	}
+	for _, folder := range folders {
+		for _, unified := range imapUnifiedFolders {
+			if folder.Type == unified.folderType {
+				result[unified.name] = append(result[unified.name], folder.ID)
+			}
+		}
+		name := imapAccountFolderName(accountEmails[folder.AccountID], &folder)
+		result[name] = append(result[name], folder.ID)
+	}
+	return result, nil
+}
+
+// loadEmails 按UID加载用户的未删除邮件
+func (s *IMAPFacadeStore) loadEmails(ctx context.Context, userID uint, uids []uint32) ([]models.Email, error) {
+	ids := make([]uint, len(uids))
+	for i, uid := range uids {
+		ids[i] = uint(uid)
+	}
+	var emails []models.Email
+	if err := s.db.WithContext(ctx).
+		Select("id", "account_id").
+		Where("user_id = ? AND id IN ? AND is_deleted = ?", userID, ids, false).
+		Find(&emails).Error; err != nil {
+		return nil, fmt.Errorf("failed to load emails: %w", err)
+	}
+	return emails, nil
+}
+
+// writeLocalMessage 用本地保存的数据生成邮件：正文为text/plain和text/html的multipart/alternative，有附件时外层为multipart/mixed
+func writeLocalMessage(ctx context.Context, storage AttachmentStorage, w io.Writer, email *models.Email) error {
+	var header gomail.Header
+	header.SetDate(email.Date)
+	header.SetSubject(email.Subject)
+	if email.MessageID != "" {
+		header.SetMessageID(strings.Trim(email.MessageID, "<>"))
+	}
+	setIMAPAddressHeader(&header, "From", email.From)
+	setIMAPAddressHeader(&header, "Reply-To", email.ReplyTo)
+	for _, field := range []struct {
+		key    string
+		getter func() ([]models.EmailAddress, error)
+	}{
+		{key: "To", getter: email.GetToAddresses},
+		{key: "Cc", getter: email.GetCCAddresses},
+	} {
+		addresses, err := field.getter()
+		if err != nil || len(addresses) == 0 {
+			continue
+		}
+		list := make([]*gomail.Address, len(addresses))
+		for i, address := range addresses {
+			list[i] = &gomail.Address{Name: address.Name, Address: address.Address}
+		}
+		header.SetAddressList(field.key, list)
+	}
+	header.Set("MIME-Version", "1.0")
+
+	var attachments []models.Attachment
+	if storage != nil {
+		for _, attachment := range email.Attachments {
+			if attachment.IsDownloaded {
+				attachments = append(attachments, attachment)
+			}
+		}
+	}
+
+	bodyParts := make([]message.Header, 0, 2)
+	bodies := make([]string, 0, 2)
+	if email.TextBody != "" || email.HTMLBody == "" {
+		var part message.Header
+		part.SetContentType("text/plain", map[string]string{"charset": "utf-8"})
+		part.Set("Content-Transfer-Encoding", "quoted-printable")
+		bodyParts, bodies = append(bodyParts, part), append(bodies, email.TextBody)
+	}
+	if email.HTMLBody != "" {
+		var part message.Header
+		part.SetContentType("text/html", map[string]string{"charset": "utf-8"})
+		part.Set("Content-Transfer-Encoding", "quoted-printable")
+		bodyParts, bodies = append(bodyParts, part), append(bodies, email.HTMLBody)
+	}
+
+	// 只有一个正文且没有附件时不使用multipart
+	if len(bodyParts) == 1 && len(attachments) == 0 {
+		for _, field := range []string{"Content-Type", "Content-Transfer-Encoding"} {
+			header.Set(field, bodyParts[0].Get(field))
+		}
+		writer, err := message.CreateWriter(w, header.Header)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(writer, bodies[0]); err != nil {
+			return err
+		}
+		return writer.Close()
+	}
+
+	writeBodies := func(writer *message.Writer) error {
+		for i, partHeader := range bodyParts {
+			part, err := writer.CreatePart(partHeader)
+			if err != nil {
+				return err
+			}
+			if _, err := io.WriteString(part, bodies[i]); err != nil {
+				return err
+			}
+			if err := part.Close(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(attachments) == 0 {
+		header.SetContentType("multipart/alternative", map[string]string{"boundary": imapBoundary(email.ID, "alt")})
+		writer, err := message.CreateWriter(w, header.Header)
+		if err != nil {
+			return err
+		}
+		if err := writeBodies(writer); err != nil {
+			return err
+		}
+		return writer.Close()
+	}
+
+	header.SetContentType("multipart/mixed", map[string]string{"boundary": imapBoundary(email.ID, "mixed")})
+	writer, err := message.CreateWriter(w, header.Header)
+	if err != nil {
+		return err
+	}
+	var alternativeHeader message.Header
+	alternativeHeader.SetContentType("multipart/alternative", map[string]string{"boundary": imapBoundary(email.ID, "alt")})
+	alternative, err := writer.CreatePart(alternativeHeader)
+	if err != nil {
+		return err
+	}
+	if err := writeBodies(alternative); err != nil {
+		return err
+	}
+	if err := alternative.Close(); err != nil {
+		return err
+	}
+
+	for i := range attachments {
+		if err := writeLocalAttachment(ctx, storage, writer, &attachments[i]); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// writeLocalAttachment 写入一个附件分段
+func writeLocalAttachment(ctx context.Context, storage AttachmentStorage, writer *message.Writer, attachment *models.Attachment) error {
+	content, err := storage.Retrieve(ctx, attachment)
+	if err != nil {
+		return fmt.Errorf("failed to read attachment %d: %w", attachment.ID, err)
+	}
+	defer content.Close()
+
+	contentType := attachment.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	disposition := "attachment"
+	if attachment.IsInlineAttachment() {
+		disposition = "inline"
+	}
+
+	var partHeader message.Header
+	partHeader.SetContentType(contentType, map[string]string{"name": attachment.Filename})
+	partHeader.SetContentDisposition(disposition, map[string]string{"filename": attachment.Filename})
+	partHeader.Set("Content-Transfer-Encoding", "base64")
+	if attachment.ContentID != "" {
+		partHeader.Set("Content-Id", "<"+strings.Trim(attachment.ContentID, "<>")+">")
+	}
+
+	part, err := writer.CreatePart(partHeader)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, content); err != nil {
+		return fmt.Errorf("failed to read attachment %d: %w", attachment.ID, err)
+	}
+	return part.Close()
+}
+
+// imapAccountFolderName 账户文件夹在外观中的名称：Accounts/<邮箱地址>/<按/分隔的服务器路径>
+func imapAccountFolderName(accountEmail string, folder *models.Folder) string {
+	path := folder.GetFullPath()
+	delimiter := folder.Delimiter
+	if delimiter == "" {
+		delimiter = imapd.Delimiter
+	}
+
+	var parts []string
+	for _, part := range strings.Split(path, delimiter) {
+		if part == "" {
+			continue
+		}
+		// 服务器分隔符不是/时，名称中的/会被误认为层级
+		parts = append(parts, strings.ReplaceAll(part, imapd.Delimiter, "_"))
+	}
+	if len(parts) == 0 {
+		parts = []string{folder.Name}
+	}
+	account := strings.ReplaceAll(accountEmail, imapd.Delimiter, "_")
+	return strings.Join(append([]string{imapAccountsPrefix, account}, parts...), imapd.Delimiter)
+}
+
+// imapFlags 邮件状态对应的IMAP标记
+func imapFlags(email *models.Email) []string {
+	flags := []string{}
+	if email.IsRead {
+		flags = append(flags, imap.SeenFlag)
+	}
+	if email.IsStarred {
+		flags = append(flags, imap.FlaggedFlag)
+	}
+	if email.IsDraft {
+		flags = append(flags, imap.DraftFlag)
+	}
+	return flags
+}
+
+// imapBoundary 由邮件ID决定的multipart分隔符
+func imapBoundary(emailID uint, kind string) string {
+	return fmt.Sprintf("firemail-%d-%s", emailID, kind)
+}
+
+// setIMAPAddressHeader 设置单个地址的邮件头，无法解析时原样写入
+func setIMAPAddressHeader(header *gomail.Header, key, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+	address, err := mail.ParseAddress(value)
+	if err != nil {
+		header.Set(key, value)
+		return
+	}
+	header.SetAddressList(key, []*gomail.Address{address})
+}
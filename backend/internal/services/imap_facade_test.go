@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"firemail/internal/imapd"
+	"firemail/internal/models"
+
+	"github.com/emersion/go-imap"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIMAPFacadeStoreAuthenticateAndListFolders(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	store := NewIMAPFacadeStore(env.db, env.service, nil)
+	ctx := context.Background()
+
+	_, err := store.Authenticate(ctx, env.user.Username, "wrong")
+	require.ErrorIs(t, err, imapd.ErrInvalidCredentials)
+	_, err = store.Authenticate(ctx, "missing", "password123")
+	require.ErrorIs(t, err, imapd.ErrInvalidCredentials)
+	userID, err := store.Authenticate(ctx, env.user.Username, "password123")
+	require.NoError(t, err)
+	require.Equal(t, env.user.ID, userID)
+
+	folders, err := store.ListFolders(ctx, env.user.ID)
+	require.NoError(t, err)
+	names := make([]string, len(folders))
+	for i, folder := range folders {
+		names[i] = folder.Name
+	}
+	require.Equal(t, []string{
+		"INBOX", "Sent", "Drafts", "Trash", "Junk",
+		"Accounts/tester@example.com/INBOX",
+		"Accounts/tester@example.com/Projects",
+	}, names)
+	require.Equal(t, []string{imap.TrashAttr}, folders[3].Attributes)
+}
+
+func TestIMAPFacadeStoreListsAndUpdatesMessages(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	store := NewIMAPFacadeStore(env.db, env.service, nil)
+	ctx := context.Background()
+
+	read := env.createEmail(t, env.inbox, 1, "read", true, false)
+	unread := env.createEmail(t, env.inbox, 2, "unread", false, false)
+	env.createEmail(t, env.inbox, 3, "deleted", false, true)
+	project := env.createEmail(t, env.work, 4, "project", false, false)
+
+	messages, err := store.ListMessages(ctx, env.user.ID, "INBOX")
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	require.Equal(t, uint32(read.ID), messages[0].UID)
+	require.Equal(t, []string{imap.SeenFlag}, messages[0].Flags)
+	require.Equal(t, []string{}, messages[1].Flags)
+
+	messages, err = store.ListMessages(ctx, env.user.ID, "Accounts/tester@example.com/Projects")
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	require.Equal(t, uint32(project.ID), messages[0].UID)
+
+	_, err = store.ListMessages(ctx, env.user.ID, "Accounts/other@example.com/INBOX")
+	require.ErrorIs(t, err, imapd.ErrNoSuchFolder)
+
+	require.NoError(t, store.SetFlag(ctx, env.user.ID, []uint32{uint32(unread.ID)}, imap.SeenFlag, true))
+	require.NoError(t, store.SetFlag(ctx, env.user.ID, []uint32{uint32(unread.ID)}, imap.FlaggedFlag, true))
+	var updated models.Email
+	require.NoError(t, env.db.First(&updated, unread.ID).Error)
+	require.True(t, updated.IsRead)
+	require.True(t, updated.IsStarred)
+
+	require.NoError(t, store.MoveMessages(ctx, env.user.ID, []uint32{uint32(unread.ID)}, "Accounts/tester@example.com/Projects"))
+	require.NoError(t, env.db.First(&updated, unread.ID).Error)
+	require.Equal(t, env.work.ID, *updated.FolderID)
+
+	// 账户没有垃圾箱文件夹，移动到统一文件夹失败
+	require.Error(t, store.MoveMessages(ctx, env.user.ID, []uint32{uint32(read.ID)}, "Trash"))
+
+	require.NoError(t, store.DeleteMessages(ctx, env.user.ID, []uint32{uint32(read.ID)}))
+	messages, err = store.ListMessages(ctx, env.user.ID, "INBOX")
+	require.NoError(t, err)
+	require.Empty(t, messages)
+}
+
+func TestIMAPFacadeStoreFetchMessageIsStable(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	store := NewIMAPFacadeStore(env.db, env.service, nil)
+	ctx := context.Background()
+
+	email := env.createEmail(t, env.inbox, 1, "hello", false, false)
+	require.NoError(t, email.SetToAddresses([]models.EmailAddress{{Name: "Tester", Address: "tester@example.com"}}))
+	require.NoError(t, env.db.Save(email).Error)
+
+	first, err := store.FetchMessage(ctx, env.user.ID, uint32(email.ID))
+	require.NoError(t, err)
+	second, err := store.FetchMessage(ctx, env.user.ID, uint32(email.ID))
+	require.NoError(t, err)
+	require.Equal(t, first, second)
+
+	content := string(first)
+	require.Contains(t, content, "Subject: hello")
+	require.Contains(t, content, "To: \"Tester\" <tester@example.com>")
+	require.Contains(t, content, "multipart/alternative")
+	require.Contains(t, content, imapBoundary(email.ID, "alt"))
+	require.Contains(t, content, "<p>hello</p>")
+
+	_, err = store.FetchMessage(ctx, env.user.ID+1, uint32(email.ID))
+	require.Error(t, err)
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sort"
 	"time"
 
 	"gorm.io/gorm"
 
+	"firemail/internal/database/writequeue"
 	"firemail/internal/models"
 	"firemail/internal/providers"
 	"firemail/internal/sse"
@@ -19,6 +21,7 @@ type IncrementalSyncService struct {
 	providerFactory      providers.ProviderFactory
 	deduplicatorFactory  DeduplicatorFactory
 	eventPublisher       sse.EventPublisher
+	changeLog            ChangeLogService
 	batchSize           int
 	maxConcurrentFolders int
 }
@@ -40,6 +43,11 @@ func NewIncrementalSyncService(
 	}
 }
 
+// SetChangeLog 设置变更日志依赖
+func (s *IncrementalSyncService) SetChangeLog(changeLog ChangeLogService) {
+	s.changeLog = changeLog
+}
+
 // SyncStrategy 同步策略
 type SyncStrategy struct {
 	AccountID        uint
@@ -81,6 +89,7 @@ func (s *IncrementalSyncService) SyncEmailsIncremental(ctx context.Context, stra
 	// 更新同步状态
 	account.SyncStatus = "syncing"
 	s.db.Save(&account)
+	recordAccountChange(ctx, s.changeLog, account.UserID, account.ID, models.ChangeActionUpdated)
 
 	// 发布同步开始事件
 	if s.eventPublisher != nil {
@@ -100,6 +109,7 @@ func (s *IncrementalSyncService) SyncEmailsIncremental(ctx context.Context, stra
 		now := time.Now()
 		account.LastSyncAt = &now
 		s.db.Save(&account)
+		recordAccountChange(ctx, s.changeLog, account.UserID, account.ID, models.ChangeActionUpdated)
 
 		// 发布同步完成事件
 		if s.eventPublisher != nil {
@@ -122,6 +132,18 @@ func (s *IncrementalSyncService) SyncEmailsIncremental(ctx context.Context, stra
 
 	result.TotalFolders = len(folders)
 
+	// 提供商优先同步的文件夹先进入工作队列，并按提供商的连接限制控制并发
+	quirks := providerQuirks(account.Provider)
+	if quirks != nil {
+		sort.SliceStable(folders, func(i, j int) bool {
+			return quirks.PriorityRank(folders[i].Path) < quirks.PriorityRank(folders[j].Path)
+		})
+	}
+	workers := s.maxConcurrentFolders
+	if quirks != nil && quirks.MaxFolderWorkers > 0 && workers > quirks.MaxFolderWorkers {
+		workers = quirks.MaxFolderWorkers
+	}
+
 	// 创建提供商实例
 	provider, err := s.providerFactory.CreateProvider(account.Provider)
 	if err != nil {
@@ -141,7 +163,7 @@ func (s *IncrementalSyncService) SyncEmailsIncremental(ctx context.Context, stra
 	resultChan := make(chan *folderSyncResult, len(folders))
 
 	// 启动工作协程
-	for i := 0; i < s.maxConcurrentFolders && i < len(folders); i++ {
+	for i := 0; i < workers && i < len(folders); i++ {
 		go s.syncFolderWorker(ctx, provider, &account, strategy, folderChan, resultChan)
 	}
 
@@ -199,7 +221,7 @@ func (s *IncrementalSyncService) syncFolderWorker(
 
 // getFoldersToSync 获取要同步的文件夹
 func (s *IncrementalSyncService) getFoldersToSync(ctx context.Context, strategy *SyncStrategy) ([]*models.Folder, error) {
-	query := s.db.Where("account_id = ?", strategy.AccountID)
+	query := s.db.Where("account_id = ? AND label_mode <> ?", strategy.AccountID, models.FolderLabelModeSkip)
 	
 	if len(strategy.FolderIDs) > 0 {
 		query = query.Where("id IN ?", strategy.FolderIDs)
@@ -227,6 +249,10 @@ func (s *IncrementalSyncService) syncSingleFolder(
 	if err != nil {
 		return fmt.Errorf("failed to get last UID: %w", err)
 	}
+	// Gmail合并的标签中已有的邮件不保存在该文件夹，从已处理的UID继续
+	if folder.LastMergedUID > lastUID {
+		lastUID = folder.LastMergedUID
+	}
 
 	// 获取新邮件
 	newEmails, err := provider.SyncEmails(ctx, account, folder.Name, lastUID)
@@ -237,6 +263,7 @@ func (s *IncrementalSyncService) syncSingleFolder(
 	if len(newEmails) == 0 {
 		return nil
 	}
+	defer providers.ReleaseEmailContents(newEmails)
 
 	// 应用策略过滤
 	filteredEmails := s.applyStrategy(newEmails, strategy)
@@ -338,8 +365,10 @@ func (s *IncrementalSyncService) processBatch(
 	deduplicator EmailDeduplicator,
 	accountID, folderID, userID uint,
 ) (newCount, updateCount int, err error) {
+	var createdIDs, updatedIDs []uint
+
 	// 使用事务处理整个批次
-	return newCount, updateCount, s.db.Transaction(func(tx *gorm.DB) error {
+	err = writequeue.Transaction(ctx, s.db, func(tx *gorm.DB) error {
 		for _, emailMsg := range batch {
 			// 检查重复
 			duplicateResult, err := deduplicator.CheckDuplicate(ctx, emailMsg, accountID, folderID)
@@ -348,32 +377,53 @@ func (s *IncrementalSyncService) processBatch(
 				continue
 			}
 
-			if duplicateResult.IsDuplicate && duplicateResult.ExistingEmail != nil {
+			if duplicateResult.IsDuplicate && duplicateResult.ExistingEmail != nil && duplicateResult.Action == "merge_label" {
+				// Gmail合并的标签只写入标签，不移动邮件
+				if err := mergeGmailLabel(tx, duplicateResult.ExistingEmail, folderID, emailMsg.UID); err != nil {
+					log.Printf("Failed to merge Gmail label for email %s: %v", emailMsg.MessageID, err)
+					continue
+				}
+				updatedIDs = append(updatedIDs, duplicateResult.ExistingEmail.ID)
+				updateCount++
+			} else if duplicateResult.IsDuplicate && duplicateResult.ExistingEmail != nil {
 				// 更新现有邮件
 				if err := s.updateExistingEmailInTx(tx, duplicateResult.ExistingEmail, emailMsg, folderID); err != nil {
 					log.Printf("Failed to update existing email %s: %v", emailMsg.MessageID, err)
 					continue
 				}
+				updatedIDs = append(updatedIDs, duplicateResult.ExistingEmail.ID)
 				updateCount++
 			} else {
 				// 创建新邮件
-				if err := s.createNewEmailInTx(tx, emailMsg, accountID, folderID, userID); err != nil {
+				emailID, err := s.createNewEmailInTx(tx, emailMsg, accountID, folderID, userID)
+				if err != nil {
 					log.Printf("Failed to create new email %s: %v", emailMsg.MessageID, err)
 					continue
 				}
+				createdIDs = append(createdIDs, emailID)
 				newCount++
 			}
 		}
 		return nil
 	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	recordEmailChanges(ctx, s.changeLog, userID, accountID, models.ChangeActionCreated, createdIDs...)
+	recordEmailChanges(ctx, s.changeLog, userID, accountID, models.ChangeActionUpdated, updatedIDs...)
+	if len(createdIDs) > 0 || len(updatedIDs) > 0 {
+		recordFolderChanges(ctx, s.changeLog, userID, accountID, &folderID)
+	}
+	return newCount, updateCount, nil
 }
 
-// createNewEmailInTx 在事务中创建新邮件
+// createNewEmailInTx 在事务中创建新邮件，返回新邮件ID
 func (s *IncrementalSyncService) createNewEmailInTx(
 	tx *gorm.DB,
 	emailMsg *providers.EmailMessage,
 	accountID, folderID, userID uint,
-) error {
+) (uint, error) {
 	// 创建邮件记录
 	email := &models.Email{
 		AccountID:     accountID,
@@ -389,7 +439,11 @@ func (s *IncrementalSyncService) createNewEmailInTx(
 		IsStarred:     s.isEmailStarred(emailMsg.Flags),
 		IsDraft:       s.isEmailDraft(emailMsg.Flags),
 		HasAttachment: len(emailMsg.Attachments) > 0,
+
+		AttachmentCount: len(emailMsg.Attachments),
 	}
+	applyFlagKeywords(email, emailMsg.Flags)
+	applyReplyFlags(email, emailMsg.Flags)
 
 	// 设置发件人
 	if emailMsg.From != nil {
@@ -425,10 +479,23 @@ func (s *IncrementalSyncService) createNewEmailInTx(
 		email.CC = fmt.Sprintf("%v", ccAddresses)
 	}
 
+	email.IsVIP = isVIPSender(tx, userID, email.From)
+	email.ThreadID = resolveEmailThreadID(tx, userID, emailMsg.MessageID, emailInReplyTo(emailMsg))
+	threadMuted := isThreadMuted(tx, userID, email.ThreadID)
+	if threadMuted {
+		email.IsRead = true
+	}
+	blocked := false
+	if rule := matchBlockedSender(tx, userID, email.From); rule != nil {
+		blocked = applyBlockedSender(tx, email, rule)
+	}
+
 	// 保存邮件
 	if err := tx.Create(email).Error; err != nil {
-		return fmt.Errorf("failed to create email: %w", err)
+		return 0, fmt.Errorf("failed to create email: %w", err)
 	}
+	clearReplyLaterOnSyncedReply(tx, userID, accountID, email)
+	markAnsweredOnSyncedReply(tx, userID, accountID, email, emailInReplyTo(emailMsg))
 
 	// 处理附件
 	if len(emailMsg.Attachments) > 0 {
@@ -439,16 +506,19 @@ func (s *IncrementalSyncService) createNewEmailInTx(
 	}
 
 	// 发布新邮件事件（在事务外部）
-	go func() {
-		if s.eventPublisher != nil {
-			newEmailEvent := sse.NewNewEmailEvent(email, userID)
-			if err := s.eventPublisher.PublishToUser(context.Background(), userID, newEmailEvent); err != nil {
-				log.Printf("Failed to publish new email event: %v", err)
-			}
-		}
-	}()
+	var account models.EmailAccount
+	if err := tx.Select("id", "notifications_muted").First(&account, accountID).Error; err != nil {
+		log.Printf("Failed to load account notification settings: %v", err)
+	}
+	if !blocked {
+		userMuted := userSettingsOrDefault(context.Background(), tx, userID).NotificationsMuted
+		go func() {
+			publishNewEmailNotification(context.Background(), s.db, s.eventPublisher, &account, email, userID, threadMuted, userMuted)
+			publishVerificationCode(context.Background(), s.eventPublisher, email, userID)
+		}()
+	}
 
-	return nil
+	return email.ID, nil
 }
 
 // updateExistingEmailInTx 在事务中更新现有邮件
@@ -458,13 +528,17 @@ func (s *IncrementalSyncService) updateExistingEmailInTx(
 	emailMsg *providers.EmailMessage,
 	folderID uint,
 ) error {
+	before := *email
+
 	// 更新可能变化的字段
 	email.FolderID = &folderID
 	email.IsRead = s.isEmailRead(emailMsg.Flags)
 	email.IsStarred = s.isEmailStarred(emailMsg.Flags)
 	email.IsDraft = s.isEmailDraft(emailMsg.Flags)
+	applyFlagKeywords(email, emailMsg.Flags)
+	applyReplyFlags(email, emailMsg.Flags)
 
-	return tx.Save(email).Error
+	return saveSyncedEmailState(tx, &before, email)
 }
 
 // saveAttachmentsInTx 在事务中保存附件
@@ -484,6 +558,7 @@ func (s *IncrementalSyncService) saveAttachmentsInTx(
 			Disposition: att.Disposition,
 			Encoding:    att.Encoding,
 		}
+		applyAttachmentPolicy(attachment, attachmentInfoHead(att))
 
 		if err := tx.Create(attachment).Error; err != nil {
 			return fmt.Errorf("failed to create attachment %s: %w", att.Filename, err)
@@ -0,0 +1,716 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+
+	"firemail/internal/config"
+	"firemail/internal/models"
+	"firemail/internal/parser"
+	"firemail/internal/providers"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// ingestTokenPrefix 入站令牌的前缀，便于在日志和配置中识别
+	ingestTokenPrefix = "fmi_"
+	// ingestTokenDisplayLength 展示给用户的令牌开头长度
+	ingestTokenDisplayLength = 12
+	// ingestMessageIDDomain 缺少Message-ID时生成的标识所用的域名
+	ingestMessageIDDomain = "ingest.firemail"
+)
+
+var (
+	// ErrIngestEndpointNotFound 入站端点不存在或无权访问
+	ErrIngestEndpointNotFound = errors.New("ingest endpoint not found")
+	// ErrInvalidIngestEndpoint 入站端点参数无效
+	ErrInvalidIngestEndpoint = errors.New("invalid ingest endpoint")
+	// ErrInvalidIngestToken 入站令牌无效或端点已停用
+	ErrInvalidIngestToken = errors.New("invalid ingest token")
+	// ErrInvalidIngestMessage 入站邮件内容无效
+	ErrInvalidIngestMessage = errors.New("invalid ingest message")
+)
+
+// IngestService 入站邮件服务接口，外部服务（SES、Mailgun等的入站路由）通过令牌将邮件写入虚拟账户
+type IngestService interface {
+	// ListEndpoints 列出入站端点
+	ListEndpoints(ctx context.Context, userID uint) ([]models.IngestEndpoint, error)
+
+	// CreateEndpoint 创建入站端点，未指定账户时创建新的虚拟账户，令牌只在此时返回
+	CreateEndpoint(ctx context.Context, userID uint, req *CreateIngestEndpointRequest) (*IngestEndpointWithToken, error)
+
+	// UpdateEndpoint 修改入站端点的名称、SMTP收件地址或启用状态
+	UpdateEndpoint(ctx context.Context, userID, endpointID uint, req *UpdateIngestEndpointRequest) (*models.IngestEndpoint, error)
+
+	// RotateToken 重新生成令牌，旧令牌立即失效
+	RotateToken(ctx context.Context, userID, endpointID uint) (*IngestEndpointWithToken, error)
+
+	// DeleteEndpoint 删除入站端点，已写入的邮件和虚拟账户保留
+	DeleteEndpoint(ctx context.Context, userID, endpointID uint) error
+
+	// Authenticate 按令牌查找启用的入站端点
+	Authenticate(ctx context.Context, token string) (*models.IngestEndpoint, error)
+
+	// FindEndpointByAddress 按SMTP收件地址查找启用的入站端点，精确地址优先于@域名
+	FindEndpointByAddress(ctx context.Context, address string) (*models.IngestEndpoint, error)
+
+	// IngestRaw 写入原始MIME邮件
+	IngestRaw(ctx context.Context, endpoint *models.IngestEndpoint, raw []byte) (*IngestResult, error)
+
+	// IngestMessage 写入JSON格式的邮件，包含原始MIME时按原文处理
+	IngestMessage(ctx context.Context, endpoint *models.IngestEndpoint, req *IngestMessageRequest) (*IngestResult, error)
+
+	// MaxMessageBytes 单封入站邮件的大小上限
+	MaxMessageBytes() int64
+}
+
+// CreateIngestEndpointRequest 创建入站端点的请求
+type CreateIngestEndpointRequest struct {
+	Name      string `json:"name" binding:"required,max=100"`
+	AccountID *uint  `json:"account_id,omitempty"` // 已有的虚拟账户，为空时创建新账户
+	FolderID  *uint  `json:"folder_id,omitempty"`  // 目标文件夹，为空时使用收件箱
+	Address   string `json:"address,omitempty"`    // 内置SMTP服务器的收件地址，如 support@example.com 或 @example.com
+}
+
+// UpdateIngestEndpointRequest 修改入站端点的请求
+type UpdateIngestEndpointRequest struct {
+	Name      *string `json:"name,omitempty" binding:"omitempty,min=1,max=100"`
+	Address   *string `json:"address,omitempty"` // 空字符串表示取消SMTP收件地址
+	IsEnabled *bool   `json:"is_enabled,omitempty"`
+}
+
+// IngestEndpointWithToken 包含明文令牌的入站端点
+type IngestEndpointWithToken struct {
+	models.IngestEndpoint
+	Token string `json:"token"`
+}
+
+// IngestMessageRequest JSON格式的入站邮件
+type IngestMessageRequest struct {
+	RawMIME       string             `json:"raw_mime,omitempty"`        // 原始MIME邮件
+	RawMIMEBase64 string             `json:"raw_mime_base64,omitempty"` // Base64编码的原始MIME邮件
+	MessageID     string             `json:"message_id,omitempty"`
+	From          string             `json:"from,omitempty"`
+	To            []string           `json:"to,omitempty"`
+	CC            []string           `json:"cc,omitempty"`
+	ReplyTo       string             `json:"reply_to,omitempty"`
+	Subject       string             `json:"subject,omitempty"`
+	Text          string             `json:"text,omitempty"`
+	HTML          string             `json:"html,omitempty"`
+	Date          *time.Time         `json:"date,omitempty"`
+	Attachments   []IngestAttachment `json:"attachments,omitempty"`
+}
+
+// IngestAttachment JSON格式入站邮件的附件
+type IngestAttachment struct {
+	Filename      string `json:"filename" binding:"required"`
+	ContentType   string `json:"content_type,omitempty"`
+	ContentBase64 string `json:"content_base64" binding:"required"`
+}
+
+// IngestResult 入站邮件的写入结果，重复投递时EmailID指向已有邮件
+type IngestResult struct {
+	EmailID   *uint  `json:"email_id,omitempty"`
+	MessageID string `json:"message_id"`
+	Duplicate bool   `json:"duplicate"`
+}
+
+// IngestServiceImpl 入站邮件服务实现，复用同步服务的去重和保存流程
+type IngestServiceImpl struct {
+	db          *gorm.DB
+	emails      *EmailServiceImpl
+	syncService *SyncService
+	maxBytes    int64
+
+	// 分配虚拟UID时串行化，避免同一文件夹的UID冲突
+	mutex sync.Mutex
+}
+
+// NewIngestService 创建入站邮件服务
+func NewIngestService(db *gorm.DB, emailService EmailService, syncService *SyncService, cfg config.IngestConfig) IngestService {
+	emails, _ := emailService.(*EmailServiceImpl)
+	return &IngestServiceImpl{
+		db:          db,
+		emails:      emails,
+		syncService: syncService,
+		maxBytes:    int64(cfg.MaxMessageMB) * 1024 * 1024,
+	}
+}
+
+// MaxMessageBytes 单封入站邮件的大小上限
+func (s *IngestServiceImpl) MaxMessageBytes() int64 {
+	return s.maxBytes
+}
+
+// ListEndpoints 列出入站端点
+func (s *IngestServiceImpl) ListEndpoints(ctx context.Context, userID uint) ([]models.IngestEndpoint, error) {
+	var endpoints []models.IngestEndpoint
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		Find(&endpoints).Error; err != nil {
+		return nil, fmt.Errorf("failed to list ingest endpoints: %w", err)
+	}
+	return endpoints, nil
+}
+
+// CreateEndpoint 创建入站端点
+func (s *IngestServiceImpl) CreateEndpoint(ctx context.Context, userID uint, req *CreateIngestEndpointRequest) (*IngestEndpointWithToken, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidIngestEndpoint)
+	}
+
+	address, err := s.checkAddress(ctx, req.Address, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := generateIngestToken()
+	if err != nil {
+		return nil, err
+	}
+
+	var account *models.EmailAccount
+	var folder *models.Folder
+	if req.AccountID == nil {
+		if req.FolderID != nil {
+			return nil, fmt.Errorf("%w: folder_id requires account_id", ErrInvalidIngestEndpoint)
+		}
+		account, folder, err = s.createVirtualAccount(ctx, userID, name, token)
+	} else {
+		account, folder, err = s.resolveTarget(ctx, userID, *req.AccountID, req.FolderID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := &models.IngestEndpoint{
+		UserID:      userID,
+		AccountID:   account.ID,
+		FolderID:    folder.ID,
+		Name:        name,
+		Address:     address,
+		TokenHash:   hashIngestToken(token),
+		TokenPrefix: token[:ingestTokenDisplayLength],
+		IsEnabled:   true,
+	}
+	if err := s.db.WithContext(ctx).Create(endpoint).Error; err != nil {
+		return nil, fmt.Errorf("failed to create ingest endpoint: %w", err)
+	}
+
+	return &IngestEndpointWithToken{IngestEndpoint: *endpoint, Token: token}, nil
+}
+
+// createVirtualAccount 创建入站虚拟账户及其收件箱
+func (s *IngestServiceImpl) createVirtualAccount(ctx context.Context, userID uint, name, token string) (*models.EmailAccount, *models.Folder, error) {
+	account := &models.EmailAccount{
+		UserID:     userID,
+		Name:       name,
+		Email:      fmt.Sprintf("%s@%s", strings.ReplaceAll(token[:ingestTokenDisplayLength], "_", "-"), ingestMessageIDDomain),
+		Provider:   providers.IngestProviderName,
+		AuthMethod: "none",
+		IsActive:   true,
+		SyncStatus: "success",
+	}
+	if s.emails != nil {
+		group, err := s.emails.resolveAccountGroup(ctx, userID, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid group: %w", err)
+		}
+		account.GroupID = &group.ID
+	}
+
+	folder := &models.Folder{
+		Name:         "INBOX",
+		DisplayName:  "收件箱",
+		Type:         models.FolderTypeInbox,
+		Path:         "INBOX",
+		Delimiter:    "/",
+		IsSelectable: true,
+		IsSubscribed: true,
+		UIDNext:      1,
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(account).Error; err != nil {
+			return fmt.Errorf("failed to create ingest account: %w", NormalizeEmailAccountCreateError(err))
+		}
+		folder.AccountID = account.ID
+		if err := tx.Create(folder).Error; err != nil {
+			return fmt.Errorf("failed to create ingest folder: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if s.emails != nil {
+		recordAccountChange(ctx, s.emails.changeLog, userID, account.ID, models.ChangeActionCreated)
+	}
+	return account, folder, nil
+}
+
+// resolveTarget 校验已有的虚拟账户和目标文件夹，文件夹为空时使用收件箱
+func (s *IngestServiceImpl) resolveTarget(ctx context.Context, userID, accountID uint, folderID *uint) (*models.EmailAccount, *models.Folder, error) {
+	var account models.EmailAccount
+	if err := s.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", accountID, userID).
+		First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("%w: account not found", ErrInvalidIngestEndpoint)
+		}
+		return nil, nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	if account.Provider != providers.IngestProviderName {
+		return nil, nil, fmt.Errorf("%w: account is not an inbound account", ErrInvalidIngestEndpoint)
+	}
+
+	query := s.db.WithContext(ctx).Where("account_id = ?", account.ID)
+	if folderID != nil {
+		query = query.Where("id = ?", *folderID)
+	} else {
+		query = query.Where("type = ?", models.FolderTypeInbox)
+	}
+	var folder models.Folder
+	if err := query.First(&folder).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("%w: folder not found", ErrInvalidIngestEndpoint)
+		}
+		return nil, nil, fmt.Errorf("failed to get folder: %w", err)
+	}
+
+	return &account, &folder, nil
+}
+
+// UpdateEndpoint 修改入站端点
+func (s *IngestServiceImpl) UpdateEndpoint(ctx context.Context, userID, endpointID uint, req *UpdateIngestEndpointRequest) (*models.IngestEndpoint, error) {
+	endpoint, err := s.getEndpoint(ctx, userID, endpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, fmt.Errorf("%w: name is required", ErrInvalidIngestEndpoint)
+		}
+		updates["name"] = name
+	}
+	if req.Address != nil {
+		address, err := s.checkAddress(ctx, *req.Address, endpoint.ID)
+		if err != nil {
+			return nil, err
+		}
+		updates["address"] = address
+	}
+	if req.IsEnabled != nil {
+		updates["is_enabled"] = *req.IsEnabled
+	}
+	if len(updates) > 0 {
+		if err := s.db.WithContext(ctx).Model(endpoint).Updates(updates).Error; err != nil {
+			return nil, fmt.Errorf("failed to update ingest endpoint: %w", err)
+		}
+	}
+
+	return s.getEndpoint(ctx, userID, endpointID)
+}
+
+// RotateToken 重新生成令牌
+func (s *IngestServiceImpl) RotateToken(ctx context.Context, userID, endpointID uint) (*IngestEndpointWithToken, error) {
+	endpoint, err := s.getEndpoint(ctx, userID, endpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := generateIngestToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Model(endpoint).Updates(map[string]interface{}{
+		"token_hash":   hashIngestToken(token),
+		"token_prefix": token[:ingestTokenDisplayLength],
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to rotate ingest token: %w", err)
+	}
+
+	return &IngestEndpointWithToken{IngestEndpoint: *endpoint, Token: token}, nil
+}
+
+// DeleteEndpoint 删除入站端点
+func (s *IngestServiceImpl) DeleteEndpoint(ctx context.Context, userID, endpointID uint) error {
+	result := s.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", endpointID, userID).
+		Delete(&models.IngestEndpoint{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete ingest endpoint: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrIngestEndpointNotFound
+	}
+	return nil
+}
+
+// getEndpoint 获取用户的入站端点
+func (s *IngestServiceImpl) getEndpoint(ctx context.Context, userID, endpointID uint) (*models.IngestEndpoint, error) {
+	var endpoint models.IngestEndpoint
+	if err := s.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", endpointID, userID).
+		First(&endpoint).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrIngestEndpointNotFound
+		}
+		return nil, fmt.Errorf("failed to get ingest endpoint: %w", err)
+	}
+	return &endpoint, nil
+}
+
+// Authenticate 按令牌查找启用的入站端点
+func (s *IngestServiceImpl) Authenticate(ctx context.Context, token string) (*models.IngestEndpoint, error) {
+	token = strings.TrimSpace(token)
+	if !strings.HasPrefix(token, ingestTokenPrefix) {
+		return nil, ErrInvalidIngestToken
+	}
+
+	var endpoint models.IngestEndpoint
+	if err := s.db.WithContext(ctx).
+		Where("token_hash = ? AND is_enabled = ?", hashIngestToken(token), true).
+		First(&endpoint).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidIngestToken
+		}
+		return nil, fmt.Errorf("failed to authenticate ingest token: %w", err)
+	}
+	return &endpoint, nil
+}
+
+// FindEndpointByAddress 按SMTP收件地址查找启用的入站端点
+func (s *IngestServiceImpl) FindEndpointByAddress(ctx context.Context, address string) (*models.IngestEndpoint, error) {
+	address = strings.ToLower(strings.TrimSpace(address))
+	at := strings.LastIndex(address, "@")
+	if at <= 0 {
+		return nil, ErrIngestEndpointNotFound
+	}
+
+	var endpoints []models.IngestEndpoint
+	if err := s.db.WithContext(ctx).
+		Where("address IN ? AND is_enabled = ?", []string{address, address[at:]}, true).
+		Find(&endpoints).Error; err != nil {
+		return nil, fmt.Errorf("failed to find ingest endpoint: %w", err)
+	}
+	if len(endpoints) == 0 {
+		return nil, ErrIngestEndpointNotFound
+	}
+	for i := range endpoints {
+		if *endpoints[i].Address == address {
+			return &endpoints[i], nil
+		}
+	}
+	return &endpoints[0], nil
+}
+
+// checkAddress 规范化SMTP收件地址并检查是否已被其他端点使用，空地址返回nil
+func (s *IngestServiceImpl) checkAddress(ctx context.Context, address string, endpointID uint) (*string, error) {
+	address = strings.ToLower(strings.TrimSpace(address))
+	if address == "" {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(address, "@") {
+		if _, err := mail.ParseAddress("postmaster" + address); err != nil || strings.Count(address, "@") != 1 {
+			return nil, fmt.Errorf("%w: invalid address domain", ErrInvalidIngestEndpoint)
+		}
+	} else if parsed, err := mail.ParseAddress(address); err != nil || parsed.Address != address {
+		return nil, fmt.Errorf("%w: invalid address", ErrInvalidIngestEndpoint)
+	}
+
+	var count int64
+	if err := s.db.WithContext(ctx).
+		Model(&models.IngestEndpoint{}).
+		Where("address = ? AND id <> ?", address, endpointID).
+		Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to check ingest address: %w", err)
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("%w: address already in use", ErrInvalidIngestEndpoint)
+	}
+	return &address, nil
+}
+
+// IngestRaw 写入原始MIME邮件
+func (s *IngestServiceImpl) IngestRaw(ctx context.Context, endpoint *models.IngestEndpoint, raw []byte) (*IngestResult, error) {
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("%w: empty message", ErrInvalidIngestMessage)
+	}
+	if int64(len(raw)) > s.maxBytes {
+		return nil, fmt.Errorf("%w: message exceeds %d bytes", ErrInvalidIngestMessage, s.maxBytes)
+	}
+
+	options := parser.DefaultParseOptions()
+	options.MaxAttachmentSize = s.maxBytes
+	parsed, err := parser.NewUnifiedParser(options).ParseEmail(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIngestMessage, err)
+	}
+	defer parsed.Cleanup()
+
+	headers := parsed.Headers
+	msg := &providers.EmailMessage{
+		MessageID: strings.TrimSpace(headers.Get("Message-Id")),
+		Subject:   decodeHeaderWithCharset(headers.Get("Subject"), ""),
+		From:      firstIngestAddress(headers.Get("From")),
+		To:        parseIngestAddresses(headers.Get("To")),
+		CC:        parseIngestAddresses(headers.Get("Cc")),
+		ReplyTo:   firstIngestAddress(headers.Get("Reply-To")),
+		TextBody:  parsed.TextBody,
+		HTMLBody:  parsed.HTMLBody,
+		Headers:   headers,
+		Size:      int64(len(raw)),
+		Priority:  headers.Get("X-Priority"),
+	}
+	if date, err := mail.ParseDate(headers.Get("Date")); err == nil {
+		msg.Date = date
+	}
+	for _, att := range append(append([]*parser.AttachmentInfo{}, parsed.Attachments...), parsed.InlineAttachments...) {
+		msg.Attachments = append(msg.Attachments, &providers.AttachmentInfo{
+			PartID:      att.PartID,
+			Filename:    att.Filename,
+			ContentType: att.ContentType,
+			Size:        att.Size,
+			ContentID:   att.ContentID,
+			Disposition: att.Disposition,
+			Encoding:    att.Encoding,
+			Content:     att.Content,
+			ContentPath: att.ContentPath,
+		})
+	}
+
+	return s.ingest(ctx, endpoint, msg, raw)
+}
+
+// IngestMessage 写入JSON格式的邮件
+func (s *IngestServiceImpl) IngestMessage(ctx context.Context, endpoint *models.IngestEndpoint, req *IngestMessageRequest) (*IngestResult, error) {
+	if req.RawMIMEBase64 != "" {
+		raw, err := base64.StdEncoding.DecodeString(req.RawMIMEBase64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid raw_mime_base64", ErrInvalidIngestMessage)
+		}
+		return s.IngestRaw(ctx, endpoint, raw)
+	}
+	if req.RawMIME != "" {
+		return s.IngestRaw(ctx, endpoint, []byte(req.RawMIME))
+	}
+
+	if strings.TrimSpace(req.From) == "" {
+		return nil, fmt.Errorf("%w: from is required", ErrInvalidIngestMessage)
+	}
+	if req.Text == "" && req.HTML == "" && req.Subject == "" {
+		return nil, fmt.Errorf("%w: subject or body is required", ErrInvalidIngestMessage)
+	}
+
+	msg := &providers.EmailMessage{
+		MessageID: strings.TrimSpace(req.MessageID),
+		Subject:   req.Subject,
+		From:      firstIngestAddress(req.From),
+		To:        parseIngestAddresses(strings.Join(req.To, ", ")),
+		CC:        parseIngestAddresses(strings.Join(req.CC, ", ")),
+		ReplyTo:   firstIngestAddress(req.ReplyTo),
+		TextBody:  req.Text,
+		HTMLBody:  req.HTML,
+		Date:      time.Now(),
+	}
+	if req.Date != nil {
+		msg.Date = *req.Date
+	}
+
+	size := int64(len(req.Text) + len(req.HTML))
+	for i, att := range req.Attachments {
+		content, err := base64.StdEncoding.DecodeString(att.ContentBase64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid content of attachment %s", ErrInvalidIngestMessage, att.Filename)
+		}
+		contentType := att.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		msg.Attachments = append(msg.Attachments, &providers.AttachmentInfo{
+			PartID:      fmt.Sprintf("%d", i+2),
+			Filename:    att.Filename,
+			ContentType: contentType,
+			Size:        int64(len(content)),
+			Disposition: "attachment",
+			Content:     content,
+		})
+		size += int64(len(content))
+	}
+	if size > s.maxBytes {
+		return nil, fmt.Errorf("%w: message exceeds %d bytes", ErrInvalidIngestMessage, s.maxBytes)
+	}
+	msg.Size = size
+
+	seed, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode ingest message: %w", err)
+	}
+	return s.ingest(ctx, endpoint, msg, seed)
+}
+
+// ingest 分配虚拟UID并通过同步流程保存邮件，同一Message-ID的重复投递直接返回已有邮件
+func (s *IngestServiceImpl) ingest(ctx context.Context, endpoint *models.IngestEndpoint, msg *providers.EmailMessage, seed []byte) (*IngestResult, error) {
+	if msg.From == nil {
+		return nil, fmt.Errorf("%w: invalid sender", ErrInvalidIngestMessage)
+	}
+	if msg.MessageID == "" {
+		// 缺少Message-ID时按内容生成确定的标识，保证重复投递能被识别
+		sum := sha256.Sum256(seed)
+		msg.MessageID = fmt.Sprintf("<%s@%s>", hex.EncodeToString(sum[:16]), ingestMessageIDDomain)
+	}
+	if msg.Date.IsZero() {
+		msg.Date = time.Now()
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var existing models.Email
+	err := s.db.WithContext(ctx).
+		Select("id").
+		Where("account_id = ? AND message_id = ?", endpoint.AccountID, msg.MessageID).
+		First(&existing).Error
+	if err == nil {
+		s.touchEndpoint(ctx, endpoint)
+		return &IngestResult{EmailID: &existing.ID, MessageID: msg.MessageID, Duplicate: true}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to check duplicate: %w", err)
+	}
+
+	uid, err := s.allocateUID(ctx, endpoint.FolderID)
+	if err != nil {
+		return nil, err
+	}
+	msg.UID = uid
+
+	created, err := s.syncService.saveSyncedEmail(ctx, msg, endpoint.AccountID, endpoint.FolderID, endpoint.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save ingested email: %w", err)
+	}
+	s.touchEndpoint(ctx, endpoint)
+	if created == nil {
+		// 内容相似的邮件已存在
+		return &IngestResult{MessageID: msg.MessageID, Duplicate: true}, nil
+	}
+
+	if !created.IsDeleted && s.emails != nil {
+		delta := emailCounterDelta{Total: 1}
+		if !created.IsRead {
+			delta.Unread = 1
+		}
+		if err := s.emails.adjustEmailCounters(ctx, endpoint.UserID, endpoint.AccountID, delta, folderCounterDelta(created.FolderID, delta)); err != nil {
+			log.Printf("Warning: failed to update counters for ingested email %d: %v", created.ID, err)
+		}
+	}
+
+	return &IngestResult{EmailID: &created.ID, MessageID: msg.MessageID}, nil
+}
+
+// allocateUID 为虚拟文件夹分配下一个UID，调用方需持有锁
+func (s *IngestServiceImpl) allocateUID(ctx context.Context, folderID uint) (uint32, error) {
+	var folder models.Folder
+	if err := s.db.WithContext(ctx).First(&folder, folderID).Error; err != nil {
+		return 0, fmt.Errorf("failed to get ingest folder: %w", err)
+	}
+
+	var maxUID uint32
+	if err := s.db.WithContext(ctx).
+		Model(&models.Email{}).
+		Where("folder_id = ?", folderID).
+		Select("COALESCE(MAX(uid), 0)").
+		Scan(&maxUID).Error; err != nil {
+		return 0, fmt.Errorf("failed to get max uid: %w", err)
+	}
+
+	uid := folder.UIDNext
+	if uid <= maxUID {
+		uid = maxUID + 1
+	}
+	if err := s.db.WithContext(ctx).
+		Model(&models.Folder{}).
+		Where("id = ?", folderID).
+		Update("uid_next", uid+1).Error; err != nil {
+		return 0, fmt.Errorf("failed to update uid_next: %w", err)
+	}
+	return uid, nil
+}
+
+// touchEndpoint 更新端点的接收计数和最后使用时间
+func (s *IngestServiceImpl) touchEndpoint(ctx context.Context, endpoint *models.IngestEndpoint) {
+	now := time.Now()
+	if err := s.db.WithContext(ctx).
+		Model(&models.IngestEndpoint{}).
+		Where("id = ?", endpoint.ID).
+		UpdateColumns(map[string]interface{}{
+			"received_count": gorm.Expr("received_count + 1"),
+			"last_used_at":   now,
+		}).Error; err != nil {
+		log.Printf("Warning: failed to update ingest endpoint %d: %v", endpoint.ID, err)
+	}
+}
+
+// parseIngestAddresses 解析地址列表头，编码词按声明的字符集解码，无法解析时整体作为一个地址
+func parseIngestAddresses(value string) []*models.EmailAddress {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+
+	addressParser := &mail.AddressParser{WordDecoder: newCharsetWordDecoder()}
+	list, err := addressParser.ParseList(value)
+	if err != nil {
+		return []*models.EmailAddress{{Address: strings.Trim(decodeHeaderWithCharset(value, ""), "<> ")}}
+	}
+
+	addresses := make([]*models.EmailAddress, 0, len(list))
+	for _, addr := range list {
+		addresses = append(addresses, &models.EmailAddress{Name: addr.Name, Address: addr.Address})
+	}
+	return addresses
+}
+
+// firstIngestAddress 解析单个地址头
+func firstIngestAddress(value string) *models.EmailAddress {
+	addresses := parseIngestAddresses(value)
+	if len(addresses) == 0 {
+		return nil
+	}
+	return addresses[0]
+}
+
+// generateIngestToken 生成随机的入站令牌
+func generateIngestToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate ingest token: %w", err)
+	}
+	return ingestTokenPrefix + hex.EncodeToString(buf), nil
+}
+
+// hashIngestToken 计算令牌的SHA-256，数据库只保存哈希
+func hashIngestToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"firemail/internal/config"
+	"firemail/internal/models"
+	"firemail/internal/providers"
+	"firemail/internal/smtpd"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setupIngestTestEnv(t *testing.T) (*emailStateServiceTestEnv, *IngestServiceImpl) {
+	t.Helper()
+
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.IngestEndpoint{}))
+	syncService := NewSyncService(env.db, nil, nil, NewDeduplicatorFactory(env.db), nil, nil)
+	svc := NewIngestService(env.db, env.service, syncService, config.IngestConfig{MaxMessageMB: 1})
+	return env, svc.(*IngestServiceImpl)
+}
+
+func TestIngestRawMIMEIntoVirtualAccount(t *testing.T) {
+	env, svc := setupIngestTestEnv(t)
+	ctx := context.Background()
+
+	endpoint, err := svc.CreateEndpoint(ctx, env.user.ID, &CreateIngestEndpointRequest{Name: "Support inbox"})
+	require.NoError(t, err)
+	require.NotEmpty(t, endpoint.Token)
+
+	var account models.EmailAccount
+	require.NoError(t, env.db.First(&account, endpoint.AccountID).Error)
+	require.Equal(t, providers.IngestProviderName, account.Provider)
+
+	raw := []byte("Message-ID: <order-1@shop.test>\r\n" +
+		"From: =?UTF-8?B?5byg5LiJ?= <zhang@shop.test>\r\n" +
+		"To: support@example.com, Ops <ops@example.com>\r\n" +
+		"Subject: =?UTF-8?B?6K6i5Y2V56Gu6K6k?=\r\n" +
+		"Date: Mon, 02 Jan 2006 15:04:05 +0000\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/mixed; boundary=b1\r\n\r\n" +
+		"--b1\r\nContent-Type: text/plain; charset=utf-8\r\n\r\nYour order shipped.\r\n" +
+		"--b1\r\nContent-Type: text/plain; name=invoice.txt\r\nContent-Disposition: attachment; filename=invoice.txt\r\n\r\nTotal: 42\r\n" +
+		"--b1--\r\n")
+
+	authenticated, err := svc.Authenticate(ctx, endpoint.Token)
+	require.NoError(t, err)
+	result, err := svc.IngestRaw(ctx, authenticated, raw)
+	require.NoError(t, err)
+	require.False(t, result.Duplicate)
+	require.NotNil(t, result.EmailID)
+
+	var email models.Email
+	require.NoError(t, env.db.Preload("Attachments").Preload("Body").First(&email, *result.EmailID).Error)
+	require.Equal(t, "订单确认", email.Subject)
+	require.Equal(t, "张三 <zhang@shop.test>", email.From)
+	require.Equal(t, uint32(1), email.UID)
+	require.Contains(t, email.TextBody, "Your order shipped.")
+	require.Len(t, email.Attachments, 1)
+	to, err := email.GetToAddresses()
+	require.NoError(t, err)
+	require.Len(t, to, 2)
+
+	// 重复投递返回已有邮件
+	again, err := svc.IngestRaw(ctx, authenticated, raw)
+	require.NoError(t, err)
+	require.True(t, again.Duplicate)
+	require.Equal(t, *result.EmailID, *again.EmailID)
+
+	var folder models.Folder
+	require.NoError(t, env.db.First(&folder, endpoint.FolderID).Error)
+	require.Equal(t, 1, folder.TotalEmails)
+	require.Equal(t, 1, folder.UnreadEmails)
+	require.Equal(t, uint32(2), folder.UIDNext)
+
+	stored, err := svc.getEndpoint(ctx, env.user.ID, endpoint.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2, stored.ReceivedCount)
+	require.NotNil(t, stored.LastUsedAt)
+}
+
+func TestIngestJSONMessageAndTokenRotation(t *testing.T) {
+	env, svc := setupIngestTestEnv(t)
+	ctx := context.Background()
+
+	// 只能写入入站虚拟账户
+	_, err := svc.CreateEndpoint(ctx, env.user.ID, &CreateIngestEndpointRequest{Name: "Bad", AccountID: &env.account.ID})
+	require.ErrorIs(t, err, ErrInvalidIngestEndpoint)
+
+	endpoint, err := svc.CreateEndpoint(ctx, env.user.ID, &CreateIngestEndpointRequest{Name: "Alerts"})
+	require.NoError(t, err)
+
+	req := &IngestMessageRequest{
+		From:    "Monitor <alerts@monitor.test>",
+		To:      []string{"ops@example.com"},
+		Subject: "Disk almost full",
+		Text:    "92% used",
+		Attachments: []IngestAttachment{{
+			Filename:      "graph.csv",
+			ContentType:   "text/csv",
+			ContentBase64: base64.StdEncoding.EncodeToString([]byte("t,v\n1,92\n")),
+		}},
+	}
+	result, err := svc.IngestMessage(ctx, &endpoint.IngestEndpoint, req)
+	require.NoError(t, err)
+	require.False(t, result.Duplicate)
+	require.Contains(t, result.MessageID, "@"+ingestMessageIDDomain)
+
+	// 没有Message-ID时按内容识别重复投递
+	again, err := svc.IngestMessage(ctx, &endpoint.IngestEndpoint, req)
+	require.NoError(t, err)
+	require.True(t, again.Duplicate)
+	require.Equal(t, result.MessageID, again.MessageID)
+
+	_, err = svc.IngestMessage(ctx, &endpoint.IngestEndpoint, &IngestMessageRequest{Subject: "No sender"})
+	require.ErrorIs(t, err, ErrInvalidIngestMessage)
+
+	rotated, err := svc.RotateToken(ctx, env.user.ID, endpoint.ID)
+	require.NoError(t, err)
+	require.NotEqual(t, endpoint.Token, rotated.Token)
+	_, err = svc.Authenticate(ctx, endpoint.Token)
+	require.ErrorIs(t, err, ErrInvalidIngestToken)
+	_, err = svc.Authenticate(ctx, rotated.Token)
+	require.NoError(t, err)
+
+	disabled := false
+	_, err = svc.UpdateEndpoint(ctx, env.user.ID, endpoint.ID, &UpdateIngestEndpointRequest{IsEnabled: &disabled})
+	require.NoError(t, err)
+	_, err = svc.Authenticate(ctx, rotated.Token)
+	require.ErrorIs(t, err, ErrInvalidIngestToken)
+
+	require.NoError(t, svc.DeleteEndpoint(ctx, env.user.ID, endpoint.ID))
+	require.ErrorIs(t, svc.DeleteEndpoint(ctx, env.user.ID, endpoint.ID), ErrIngestEndpointNotFound)
+}
+
+func TestSMTPDeliveryRoutesByEndpointAddress(t *testing.T) {
+	env, svc := setupIngestTestEnv(t)
+	ctx := context.Background()
+
+	support, err := svc.CreateEndpoint(ctx, env.user.ID, &CreateIngestEndpointRequest{Name: "Support", Address: " Support@Example.com "})
+	require.NoError(t, err)
+	require.Equal(t, "support@example.com", *support.Address)
+	catchAll, err := svc.CreateEndpoint(ctx, env.user.ID, &CreateIngestEndpointRequest{Name: "Everything else", Address: "@example.com"})
+	require.NoError(t, err)
+
+	_, err = svc.CreateEndpoint(ctx, env.user.ID, &CreateIngestEndpointRequest{Name: "Dup", Address: "support@example.com"})
+	require.ErrorIs(t, err, ErrInvalidIngestEndpoint)
+	_, err = svc.CreateEndpoint(ctx, env.user.ID, &CreateIngestEndpointRequest{Name: "Bad", Address: "not an address"})
+	require.ErrorIs(t, err, ErrInvalidIngestEndpoint)
+
+	backend := NewSMTPDeliveryBackend(svc)
+	require.NoError(t, backend.ValidateRecipient(ctx, "support@example.com"))
+	require.NoError(t, backend.ValidateRecipient(ctx, "anyone@example.com"))
+	var smtpErr *smtpd.Error
+	require.ErrorAs(t, backend.ValidateRecipient(ctx, "someone@other.test"), &smtpErr)
+	require.Equal(t, 550, smtpErr.Code)
+
+	data := []byte("Received: from sender.test (192.0.2.1)\n\tby mx.example.com (FireMail) with ESMTP id ABC; Mon, 02 Jan 2006 15:04:05 +0000\n" +
+		"Message-ID: <smtp-1@sender.test>\nFrom: alice@sender.test\nTo: support@example.com, bob@example.com, carol@example.com\nSubject: Hi\n\nHello\n")
+	require.NoError(t, backend.Deliver(ctx, &smtpd.Envelope{
+		ID:         "ABC",
+		From:       "alice@sender.test",
+		Recipients: []string{"support@example.com", "bob@example.com", "carol@example.com"},
+	}, data))
+
+	var supportCount, catchAllCount int64
+	require.NoError(t, env.db.Model(&models.Email{}).Where("account_id = ?", support.AccountID).Count(&supportCount).Error)
+	require.NoError(t, env.db.Model(&models.Email{}).Where("account_id = ?", catchAll.AccountID).Count(&catchAllCount).Error)
+	require.Equal(t, int64(1), supportCount)
+	require.Equal(t, int64(1), catchAllCount)
+
+	// 停用后精确地址回退到域名端点
+	disabled := false
+	_, err = svc.UpdateEndpoint(ctx, env.user.ID, support.ID, &UpdateIngestEndpointRequest{IsEnabled: &disabled})
+	require.NoError(t, err)
+	endpoint, err := svc.FindEndpointByAddress(ctx, "support@example.com")
+	require.NoError(t, err)
+	require.Equal(t, catchAll.ID, endpoint.ID)
+
+	// 缺少发件人的邮件被永久拒绝
+	err = backend.Deliver(ctx, &smtpd.Envelope{ID: "DEF", Recipients: []string{"x@example.com"}}, []byte("Subject: No sender\n\nHello\n"))
+	require.ErrorAs(t, err, &smtpErr)
+	require.Equal(t, 554, smtpErr.Code)
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"firemail/internal/config"
+)
+
+// 地理位置查询结果缓存的最大条数，超过后清空重建
+const maxIPGeoCacheEntries = 1000
+
+// IPGeoInfo IP地理位置信息
+type IPGeoInfo struct {
+	IP          string  `json:"ip"`
+	Country     string  `json:"country,omitempty"`
+	CountryCode string  `json:"country_code,omitempty"`
+	Region      string  `json:"region,omitempty"`
+	City        string  `json:"city,omitempty"`
+	Latitude    float64 `json:"latitude,omitempty"`
+	Longitude   float64 `json:"longitude,omitempty"`
+	ISP         string  `json:"isp,omitempty"`
+	Org         string  `json:"org,omitempty"`
+	AS          string  `json:"as,omitempty"`
+}
+
+// IPGeoLocator IP地理位置查询接口
+type IPGeoLocator interface {
+	Locate(ctx context.Context, ip string) (*IPGeoInfo, error)
+}
+
+// httpIPGeoLocator 通过HTTP接口查询IP地理位置，响应为ip-api.com格式
+type httpIPGeoLocator struct {
+	urlTemplate string
+	client      *http.Client
+
+	mu    sync.Mutex
+	cache map[string]*IPGeoInfo
+}
+
+// NewHTTPIPGeoLocator 创建基于HTTP接口的地理位置查询，未配置查询地址时返回nil
+func NewHTTPIPGeoLocator(cfg config.GeoIPConfig) IPGeoLocator {
+	if cfg.LookupURL == "" {
+		return nil
+	}
+	return &httpIPGeoLocator{
+		urlTemplate: cfg.LookupURL,
+		client:      &http.Client{Timeout: cfg.Timeout},
+		cache:       make(map[string]*IPGeoInfo),
+	}
+}
+
+// ipAPIResponse ip-api.com格式的响应
+type ipAPIResponse struct {
+	Status      string  `json:"status"`
+	Message     string  `json:"message"`
+	Country     string  `json:"country"`
+	CountryCode string  `json:"countryCode"`
+	RegionName  string  `json:"regionName"`
+	City        string  `json:"city"`
+	Lat         float64 `json:"lat"`
+	Lon         float64 `json:"lon"`
+	ISP         string  `json:"isp"`
+	Org         string  `json:"org"`
+	AS          string  `json:"as"`
+}
+
+// Locate 查询IP地理位置，结果按IP缓存
+func (l *httpIPGeoLocator) Locate(ctx context.Context, ip string) (*IPGeoInfo, error) {
+	l.mu.Lock()
+	cached, ok := l.cache[ip]
+	l.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	lookupURL := strings.ReplaceAll(l.urlTemplate, "{ip}", url.PathEscape(ip))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, lookupURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create geoip request: %w", err)
+	}
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("geoip lookup failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("geoip lookup failed: status %d", resp.StatusCode)
+	}
+
+	var body ipAPIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid geoip response: %w", err)
+	}
+	if body.Status != "" && body.Status != "success" {
+		return nil, fmt.Errorf("geoip lookup failed: %s", body.Message)
+	}
+
+	info := &IPGeoInfo{
+		IP:          ip,
+		Country:     body.Country,
+		CountryCode: body.CountryCode,
+		Region:      body.RegionName,
+		City:        body.City,
+		Latitude:    body.Lat,
+		Longitude:   body.Lon,
+		ISP:         body.ISP,
+		Org:         body.Org,
+		AS:          body.AS,
+	}
+
+	l.mu.Lock()
+	if len(l.cache) >= maxIPGeoCacheEntries {
+		l.cache = make(map[string]*IPGeoInfo)
+	}
+	l.cache[ip] = info
+	l.mu.Unlock()
+	return info, nil
+}
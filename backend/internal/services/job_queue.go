@@ -0,0 +1,628 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"firemail/internal/config"
+	"firemail/internal/models"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// 后台任务类型
+const (
+	JobTypeSyncAccount            = "sync.account"              // 同步账户邮件
+	JobTypeSyncAccountFolders     = "sync.account_folders"      // 同步新账户的文件夹列表
+	JobTypeSyncFolder             = "sync.folder"               // 同步指定文件夹
+	JobTypeLegalHoldExport        = "legal_hold.export"         // 生成法律保全导出
+	JobTypeSoftDeleteCleanup      = "cleanup.soft_delete"       // 清理过期的软删除数据
+	JobTypeTemporaryAttachCleanup = "cleanup.temp_attachments"  // 清理过期的临时附件
+	JobTypeSendDeliver            = "send.deliver"              // 投递接口进程写入发送队列的邮件
+	JobTypeFolderCountReconcile   = "maintenance.folder_counts" // 校正文件夹和账户的邮件计数
+	JobTypeNotificationCleanup    = "cleanup.notifications"     // 删除超过保留期的通知
+	JobTypeEmbeddingIndex         = "maintenance.embeddings"    // 为缺少向量的邮件建立语义搜索索引
+)
+
+const (
+	// jobClaimBatchSize 每次查询的候选任务数，认领失败（被其他工作者抢先）时依次尝试下一个
+	jobClaimBatchSize = 10
+	// jobMaintenanceInterval 安排周期任务和清理历史任务的间隔
+	jobMaintenanceInterval = time.Minute
+	// jobRetryBaseDelay 第一次重试的等待时间，之后每次翻倍
+	jobRetryBaseDelay = 10 * time.Second
+	// jobRetryMaxDelay 重试等待时间上限
+	jobRetryMaxDelay = time.Hour
+	// periodicJobKeyPrefix 周期任务的去重键前缀
+	periodicJobKeyPrefix = "periodic:"
+)
+
+var (
+	// ErrJobPermanent 包装该错误的任务失败后不再重试，直接进入死信
+	ErrJobPermanent = errors.New("permanent job failure")
+	// ErrJobNotFound 任务不存在
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobNotRetryable 只有死信和已取消的任务可以重试
+	ErrJobNotRetryable = errors.New("only dead or cancelled jobs can be retried")
+	// ErrJobNotCancellable 只有等待执行的任务可以取消
+	ErrJobNotCancellable = errors.New("only pending jobs can be cancelled")
+)
+
+// JobHandler 任务处理函数，返回错误时按退避策略重试
+type JobHandler func(ctx context.Context, job *models.Job) error
+
+// JobOptions 入队选项，零值使用默认设置
+type JobOptions struct {
+	UserID      uint          // 任务所属用户，便于排查
+	UniqueKey   string        // 同一键已有未结束的任务时返回该任务而不重复入队
+	MaxAttempts int           // 最大尝试次数，不大于0时使用 JOB_MAX_ATTEMPTS
+	Delay       time.Duration // 延迟执行
+}
+
+// JobEnqueuer 任务入队接口，各服务只依赖入队能力
+type JobEnqueuer interface {
+	Enqueue(ctx context.Context, jobType string, payload interface{}, opts *JobOptions) (*models.Job, error)
+}
+
+// JobQueueService 持久化的后台任务队列。任务保存在数据库中，任意进程可以入队，
+// 运行队列的进程认领并执行已注册类型的任务，失败按指数退避重试，尝试次数用尽后进入死信
+type JobQueueService interface {
+	JobEnqueuer
+	Register(jobType string, handler JobHandler)
+	Every(jobType string, interval time.Duration)
+	Start(ctx context.Context) error
+	Stop()
+
+	ListJobs(ctx context.Context, filter JobFilter) (*JobList, error)
+	GetJob(ctx context.Context, jobID uint) (*models.Job, error)
+	RetryJob(ctx context.Context, jobID uint) (*models.Job, error)
+	CancelJob(ctx context.Context, jobID uint) (*models.Job, error)
+}
+
+// JobFilter 任务查询条件，为空的条件不过滤
+type JobFilter struct {
+	Status string
+	Type   string
+	Limit  int
+}
+
+// JobList 任务列表及各状态的任务数
+type JobList struct {
+	Jobs   []models.Job     `json:"jobs"`
+	Counts map[string]int64 `json:"counts"`
+}
+
+// 任务列表默认与最大返回条数
+const (
+	defaultJobListLimit = 100
+	maxJobListLimit     = 1000
+)
+
+// JobQueueServiceImpl 基于数据库的任务队列，可选通过Redis在入队时通知工作进程
+type JobQueueServiceImpl struct {
+	db       *gorm.DB
+	cfg      config.JobsConfig
+	workerID string
+
+	handlers map[string]JobHandler
+	periodic map[string]time.Duration
+
+	redis   redis.UniversalClient
+	channel string
+	wake    chan struct{}
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	mutex  sync.RWMutex
+}
+
+// NewJobQueueService 创建任务队列服务
+func NewJobQueueService(db *gorm.DB, cfg config.JobsConfig) JobQueueService {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 2 * time.Second
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	return &JobQueueServiceImpl{
+		db:       db,
+		cfg:      cfg,
+		workerID: leaseHolderID(),
+		handlers: make(map[string]JobHandler),
+		periodic: make(map[string]time.Duration),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// EnableRedisNotify 入队时通过Redis频道通知运行队列的进程立即认领，不必等待下一次轮询
+func (s *JobQueueServiceImpl) EnableRedisNotify(client redis.UniversalClient, channel string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.redis = client
+	s.channel = channel
+}
+
+// Register 注册任务类型的处理函数，只有注册过的类型会被本进程认领
+func (s *JobQueueServiceImpl) Register(jobType string, handler JobHandler) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.handlers[jobType] = handler
+}
+
+// Every 按间隔周期执行任务，下一次执行时间从上一次结束时算起，进程重启不会重新计时
+func (s *JobQueueServiceImpl) Every(jobType string, interval time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.periodic[jobType] = interval
+}
+
+// DecodeJobPayload 解析任务参数
+func DecodeJobPayload(job *models.Job, v interface{}) error {
+	if err := json.Unmarshal([]byte(job.Payload), v); err != nil {
+		return fmt.Errorf("%w: invalid payload: %v", ErrJobPermanent, err)
+	}
+	return nil
+}
+
+// Enqueue 任务入队
+func (s *JobQueueServiceImpl) Enqueue(ctx context.Context, jobType string, payload interface{}, opts *JobOptions) (*models.Job, error) {
+	if opts == nil {
+		opts = &JobOptions{}
+	}
+	data := []byte("{}")
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode job payload: %w", err)
+		}
+		data = encoded
+	}
+
+	if opts.UniqueKey != "" {
+		var existing models.Job
+		err := s.db.WithContext(ctx).
+			Where("unique_key = ? AND status IN ?", opts.UniqueKey, []string{models.JobStatusPending, models.JobStatusRunning}).
+			First(&existing).Error
+		if err == nil {
+			return &existing, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to check existing job: %w", err)
+		}
+	}
+
+	maxAttempts := opts.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = s.cfg.MaxAttempts
+	}
+	job := &models.Job{
+		Type:        jobType,
+		Payload:     string(data),
+		UserID:      opts.UserID,
+		UniqueKey:   opts.UniqueKey,
+		Status:      models.JobStatusPending,
+		MaxAttempts: maxAttempts,
+		RunAt:       time.Now().Add(opts.Delay),
+	}
+	if err := s.db.WithContext(ctx).Create(job).Error; err != nil {
+		return nil, fmt.Errorf("failed to enqueue job: %w", err)
+	}
+	s.notify(ctx, jobType)
+	return job, nil
+}
+
+// notify 唤醒本进程的工作者，启用Redis时同时通知其他进程
+func (s *JobQueueServiceImpl) notify(ctx context.Context, jobType string) {
+	s.wakeWorkers()
+
+	s.mutex.RLock()
+	client, channel := s.redis, s.channel
+	s.mutex.RUnlock()
+	if client == nil {
+		return
+	}
+	if err := client.Publish(context.WithoutCancel(ctx), channel, jobType).Err(); err != nil {
+		log.Printf("Failed to publish job notification: %v", err)
+	}
+}
+
+func (s *JobQueueServiceImpl) wakeWorkers() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start 启动工作者。同一时间只应有一个进程运行队列（all 模式或持有租约的工作进程），
+// 因此上次退出时仍在执行的任务视为中断，重新放回等待队列
+func (s *JobQueueServiceImpl) Start(ctx context.Context) error {
+	if err := s.db.WithContext(ctx).Model(&models.Job{}).
+		Where("status = ?", models.JobStatusRunning).
+		Updates(map[string]interface{}{
+			"status":    models.JobStatusPending,
+			"locked_by": "",
+			"locked_at": nil,
+			"run_at":    time.Now(),
+		}).Error; err != nil {
+		return fmt.Errorf("failed to reset interrupted jobs: %w", err)
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	s.mutex.Lock()
+	s.cancel = cancel
+	client, channel := s.redis, s.channel
+	s.mutex.Unlock()
+
+	if client != nil {
+		pubsub := client.Subscribe(runCtx, channel)
+		if _, err := pubsub.Receive(runCtx); err != nil {
+			pubsub.Close()
+			log.Printf("Warning: failed to subscribe to job notifications, falling back to polling: %v", err)
+		} else {
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				s.receiveNotifications(runCtx, pubsub)
+			}()
+		}
+	}
+
+	for i := 0; i < s.cfg.Workers; i++ {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.work(runCtx)
+		}()
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.maintain(runCtx)
+	}()
+
+	log.Printf("Job queue started with %d workers", s.cfg.Workers)
+	return nil
+}
+
+// Stop 停止认领新任务并等待执行中的任务退出，被中断的任务放回等待队列
+func (s *JobQueueServiceImpl) Stop() {
+	s.mutex.Lock()
+	cancel := s.cancel
+	s.mutex.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	s.wg.Wait()
+}
+
+// receiveNotifications 收到其他进程的入队通知时唤醒工作者
+func (s *JobQueueServiceImpl) receiveNotifications(ctx context.Context, pubsub *redis.PubSub) {
+	defer pubsub.Close()
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-messages:
+			if !ok {
+				return
+			}
+			s.wakeWorkers()
+		}
+	}
+}
+
+// work 工作者循环：有任务时连续执行，没有时等待轮询间隔或入队通知
+func (s *JobQueueServiceImpl) work(ctx context.Context) {
+	for {
+		job, err := s.claimNext(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Failed to claim job: %v", err)
+		}
+		if job != nil {
+			s.execute(ctx, job)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-time.After(s.cfg.PollInterval):
+		}
+	}
+}
+
+// registeredTypes 本进程可以执行的任务类型
+func (s *JobQueueServiceImpl) registeredTypes() []string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	types := make([]string, 0, len(s.handlers))
+	for jobType := range s.handlers {
+		types = append(types, jobType)
+	}
+	return types
+}
+
+func (s *JobQueueServiceImpl) handler(jobType string) JobHandler {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.handlers[jobType]
+}
+
+// claimNext 认领一个到期的任务。认领通过条件更新状态完成，多个工作者同时认领也不会重复执行
+func (s *JobQueueServiceImpl) claimNext(ctx context.Context) (*models.Job, error) {
+	types := s.registeredTypes()
+	if len(types) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	var candidates []models.Job
+	if err := s.db.WithContext(ctx).
+		Where("status = ? AND run_at <= ? AND type IN ?", models.JobStatusPending, now, types).
+		Order("run_at ASC, id ASC").
+		Limit(jobClaimBatchSize).
+		Find(&candidates).Error; err != nil {
+		return nil, fmt.Errorf("failed to query jobs: %w", err)
+	}
+
+	for i := range candidates {
+		job := &candidates[i]
+		result := s.db.WithContext(ctx).Model(&models.Job{}).
+			Where("id = ? AND status = ?", job.ID, models.JobStatusPending).
+			Updates(map[string]interface{}{
+				"status":    models.JobStatusRunning,
+				"attempts":  gorm.Expr("attempts + 1"),
+				"locked_by": s.workerID,
+				"locked_at": now,
+			})
+		if result.Error != nil {
+			return nil, fmt.Errorf("failed to claim job: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		job.Status = models.JobStatusRunning
+		job.Attempts++
+		job.LockedBy = s.workerID
+		job.LockedAt = &now
+		return job, nil
+	}
+	return nil, nil
+}
+
+// execute 执行任务并按结果更新状态：成功、稍后重试、进入死信，或因停止而放回等待队列
+func (s *JobQueueServiceImpl) execute(ctx context.Context, job *models.Job) {
+	err := s.runHandler(ctx, job)
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"locked_by": "",
+		"locked_at": nil,
+	}
+	switch {
+	case err == nil:
+		updates["status"] = models.JobStatusSucceeded
+		updates["last_error"] = ""
+		updates["finished_at"] = now
+	case ctx.Err() != nil:
+		// 进程停止导致的中断不计入尝试次数
+		updates["status"] = models.JobStatusPending
+		updates["attempts"] = gorm.Expr("attempts - 1")
+		updates["last_error"] = err.Error()
+		updates["run_at"] = now
+	case errors.Is(err, ErrJobPermanent) || job.Attempts >= job.MaxAttempts:
+		updates["status"] = models.JobStatusDead
+		updates["last_error"] = err.Error()
+		updates["finished_at"] = now
+		log.Printf("Job %d (%s) moved to dead letter after %d attempts: %v", job.ID, job.Type, job.Attempts, err)
+	default:
+		updates["status"] = models.JobStatusPending
+		updates["last_error"] = err.Error()
+		updates["run_at"] = now.Add(jobRetryDelay(job.Attempts))
+		log.Printf("Job %d (%s) failed on attempt %d, will retry: %v", job.ID, job.Type, job.Attempts, err)
+	}
+
+	// 服务停止时ctx已取消，结果仍需写入
+	if err := s.db.WithContext(context.WithoutCancel(ctx)).Model(&models.Job{}).
+		Where("id = ?", job.ID).Updates(updates).Error; err != nil {
+		log.Printf("Failed to update job %d: %v", job.ID, err)
+	}
+}
+
+// runHandler 调用处理函数，处理函数panic时视为不可重试的失败
+func (s *JobQueueServiceImpl) runHandler(ctx context.Context, job *models.Job) (err error) {
+	handler := s.handler(job.Type)
+	if handler == nil {
+		return fmt.Errorf("%w: no handler registered for job type %s", ErrJobPermanent, job.Type)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Job %d (%s) panicked: %v\n%s", job.ID, job.Type, r, debug.Stack())
+			err = fmt.Errorf("%w: panic: %v", ErrJobPermanent, r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// jobRetryDelay 第 attempt 次失败后的重试等待时间
+func jobRetryDelay(attempt int) time.Duration {
+	delay := jobRetryBaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= jobRetryMaxDelay {
+			return jobRetryMaxDelay
+		}
+	}
+	return delay
+}
+
+// maintain 定期安排周期任务并删除超过保留时长的历史任务
+func (s *JobQueueServiceImpl) maintain(ctx context.Context) {
+	ticker := time.NewTicker(jobMaintenanceInterval)
+	defer ticker.Stop()
+	for {
+		s.schedulePeriodic(ctx)
+		if err := s.purgeFinished(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to purge finished jobs: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// schedulePeriodic 为没有未结束任务的周期任务入队下一次执行
+func (s *JobQueueServiceImpl) schedulePeriodic(ctx context.Context) {
+	s.mutex.RLock()
+	periodic := make(map[string]time.Duration, len(s.periodic))
+	for jobType, interval := range s.periodic {
+		periodic[jobType] = interval
+	}
+	s.mutex.RUnlock()
+
+	now := time.Now()
+	for jobType, interval := range periodic {
+		key := periodicJobKeyPrefix + jobType
+
+		// 上一次结束后满一个间隔再执行，从未执行过的立即执行
+		var delay time.Duration
+		var last models.Job
+		err := s.db.WithContext(ctx).
+			Where("unique_key = ? AND finished_at IS NOT NULL", key).
+			Order("finished_at DESC").
+			First(&last).Error
+		if err == nil {
+			delay = last.FinishedAt.Add(interval).Sub(now)
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("Failed to load last %s job: %v", jobType, err)
+			continue
+		}
+		if delay < 0 {
+			delay = 0
+		}
+
+		if _, err := s.Enqueue(ctx, jobType, nil, &JobOptions{UniqueKey: key, Delay: delay}); err != nil {
+			log.Printf("Failed to schedule %s job: %v", jobType, err)
+		}
+	}
+}
+
+// purgeFinished 删除超过保留时长的成功和已取消任务，死信任务保留到手动处理
+func (s *JobQueueServiceImpl) purgeFinished(ctx context.Context) error {
+	return s.db.WithContext(ctx).
+		Where("status IN ? AND finished_at < ?", []string{models.JobStatusSucceeded, models.JobStatusCancelled}, time.Now().Add(-s.cfg.Retention)).
+		Delete(&models.Job{}).Error
+}
+
+// ListJobs 按创建时间倒序列出任务，并统计各状态的任务数
+func (s *JobQueueServiceImpl) ListJobs(ctx context.Context, filter JobFilter) (*JobList, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultJobListLimit
+	}
+	if limit > maxJobListLimit {
+		limit = maxJobListLimit
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.Job{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+
+	list := &JobList{Jobs: []models.Job{}, Counts: make(map[string]int64)}
+	if err := query.Order("id DESC").Limit(limit).Find(&list.Jobs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+
+	var counts []struct {
+		Status string
+		Count  int64
+	}
+	countQuery := s.db.WithContext(ctx).Model(&models.Job{})
+	if filter.Type != "" {
+		countQuery = countQuery.Where("type = ?", filter.Type)
+	}
+	if err := countQuery.Select("status, COUNT(*) AS count").Group("status").Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("failed to count jobs: %w", err)
+	}
+	for _, count := range counts {
+		list.Counts[count.Status] = count.Count
+	}
+	return list, nil
+}
+
+// GetJob 获取任务
+func (s *JobQueueServiceImpl) GetJob(ctx context.Context, jobID uint) (*models.Job, error) {
+	var job models.Job
+	if err := s.db.WithContext(ctx).First(&job, jobID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrJobNotFound
+		}
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	return &job, nil
+}
+
+// RetryJob 将死信或已取消的任务重新放回等待队列并清零尝试次数
+func (s *JobQueueServiceImpl) RetryJob(ctx context.Context, jobID uint) (*models.Job, error) {
+	job, err := s.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	result := s.db.WithContext(ctx).Model(&models.Job{}).
+		Where("id = ? AND status IN ?", job.ID, []string{models.JobStatusDead, models.JobStatusCancelled}).
+		Updates(map[string]interface{}{
+			"status":      models.JobStatusPending,
+			"attempts":    0,
+			"run_at":      time.Now(),
+			"finished_at": nil,
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to retry job: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("%w: job is %s", ErrJobNotRetryable, job.Status)
+	}
+	s.notify(ctx, job.Type)
+	return s.GetJob(ctx, jobID)
+}
+
+// CancelJob 取消等待执行的任务，执行中的任务无法取消
+func (s *JobQueueServiceImpl) CancelJob(ctx context.Context, jobID uint) (*models.Job, error) {
+	job, err := s.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	result := s.db.WithContext(ctx).Model(&models.Job{}).
+		Where("id = ? AND status = ?", job.ID, models.JobStatusPending).
+		Updates(map[string]interface{}{
+			"status":      models.JobStatusCancelled,
+			"finished_at": time.Now(),
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to cancel job: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("%w: job is %s", ErrJobNotCancellable, job.Status)
+	}
+	return s.GetJob(ctx, jobID)
+}
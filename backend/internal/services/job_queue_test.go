@@ -0,0 +1,270 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"firemail/internal/config"
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupJobQueueTest(t *testing.T, db *gorm.DB) *JobQueueServiceImpl {
+	t.Helper()
+	require.NoError(t, db.AutoMigrate(&models.Job{}))
+	queue, ok := NewJobQueueService(db, config.JobsConfig{
+		Workers:      2,
+		PollInterval: 10 * time.Millisecond,
+		MaxAttempts:  2,
+		Retention:    time.Hour,
+	}).(*JobQueueServiceImpl)
+	require.True(t, ok)
+	return queue
+}
+
+// runNextJob 认领并执行一个到期的任务，返回执行后的任务
+func runNextJob(t *testing.T, queue *JobQueueServiceImpl) *models.Job {
+	t.Helper()
+	ctx := context.Background()
+	job, err := queue.claimNext(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	queue.execute(ctx, job)
+
+	updated, err := queue.GetJob(ctx, job.ID)
+	require.NoError(t, err)
+	return updated
+}
+
+func waitForJobStatus(t *testing.T, queue *JobQueueServiceImpl, jobID uint, status string) *models.Job {
+	t.Helper()
+	var job *models.Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = queue.GetJob(context.Background(), jobID)
+		return err == nil && job.Status == status
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func TestJobQueueRetriesThenMovesToDeadLetter(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	queue := setupJobQueueTest(t, env.db)
+	ctx := context.Background()
+
+	queue.Register("test.flaky", func(ctx context.Context, job *models.Job) error {
+		return errors.New("upstream unavailable")
+	})
+	job, err := queue.Enqueue(ctx, "test.flaky", map[string]int{"n": 1}, nil)
+	require.NoError(t, err)
+	require.Equal(t, `{"n":1}`, job.Payload)
+	require.Equal(t, 2, job.MaxAttempts)
+
+	// 第一次失败后推迟重试
+	job = runNextJob(t, queue)
+	require.Equal(t, models.JobStatusPending, job.Status)
+	require.Equal(t, 1, job.Attempts)
+	require.Equal(t, "upstream unavailable", job.LastError)
+	require.True(t, job.RunAt.After(time.Now()))
+
+	next, err := queue.claimNext(ctx)
+	require.NoError(t, err)
+	require.Nil(t, next)
+
+	// 到期后再次失败，尝试次数用尽进入死信
+	require.NoError(t, env.db.Model(&models.Job{}).Where("id = ?", job.ID).Update("run_at", time.Now()).Error)
+	job = runNextJob(t, queue)
+	require.Equal(t, models.JobStatusDead, job.Status)
+	require.Equal(t, 2, job.Attempts)
+	require.NotNil(t, job.FinishedAt)
+
+	_, err = queue.CancelJob(ctx, job.ID)
+	require.ErrorIs(t, err, ErrJobNotCancellable)
+
+	// 手动重试清零尝试次数
+	job, err = queue.RetryJob(ctx, job.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.JobStatusPending, job.Status)
+	require.Zero(t, job.Attempts)
+	require.Nil(t, job.FinishedAt)
+
+	_, err = queue.RetryJob(ctx, job.ID)
+	require.ErrorIs(t, err, ErrJobNotRetryable)
+
+	job, err = queue.CancelJob(ctx, job.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.JobStatusCancelled, job.Status)
+
+	_, err = queue.GetJob(ctx, job.ID+100)
+	require.ErrorIs(t, err, ErrJobNotFound)
+}
+
+func TestJobQueuePermanentFailuresAndUniqueKey(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	queue := setupJobQueueTest(t, env.db)
+	ctx := context.Background()
+
+	queue.Register("test.permanent", func(ctx context.Context, job *models.Job) error {
+		return fmt.Errorf("%w: bad input", ErrJobPermanent)
+	})
+	queue.Register("test.panic", func(ctx context.Context, job *models.Job) error {
+		panic("boom")
+	})
+
+	first, err := queue.Enqueue(ctx, "test.permanent", nil, &JobOptions{UniqueKey: "only-once", MaxAttempts: 5})
+	require.NoError(t, err)
+	second, err := queue.Enqueue(ctx, "test.permanent", nil, &JobOptions{UniqueKey: "only-once"})
+	require.NoError(t, err)
+	require.Equal(t, first.ID, second.ID)
+
+	job := runNextJob(t, queue)
+	require.Equal(t, first.ID, job.ID)
+	require.Equal(t, models.JobStatusDead, job.Status)
+	require.Equal(t, 1, job.Attempts)
+
+	// 结束后同一键可以再次入队
+	third, err := queue.Enqueue(ctx, "test.permanent", nil, &JobOptions{UniqueKey: "only-once"})
+	require.NoError(t, err)
+	require.NotEqual(t, first.ID, third.ID)
+	_, err = queue.CancelJob(ctx, third.ID)
+	require.NoError(t, err)
+
+	_, err = queue.Enqueue(ctx, "test.panic", nil, nil)
+	require.NoError(t, err)
+	job = runNextJob(t, queue)
+	require.Equal(t, models.JobStatusDead, job.Status)
+	require.Contains(t, job.LastError, "panic: boom")
+
+	// 未注册的类型不会被认领
+	_, err = queue.Enqueue(ctx, "test.unknown", nil, nil)
+	require.NoError(t, err)
+	next, err := queue.claimNext(ctx)
+	require.NoError(t, err)
+	require.Nil(t, next)
+
+	list, err := queue.ListJobs(ctx, JobFilter{})
+	require.NoError(t, err)
+	require.Len(t, list.Jobs, 4)
+	require.Equal(t, int64(2), list.Counts[models.JobStatusDead])
+	require.Equal(t, int64(1), list.Counts[models.JobStatusCancelled])
+	require.Equal(t, int64(1), list.Counts[models.JobStatusPending])
+
+	list, err = queue.ListJobs(ctx, JobFilter{Status: models.JobStatusDead, Type: "test.panic"})
+	require.NoError(t, err)
+	require.Len(t, list.Jobs, 1)
+}
+
+func TestJobQueueWorkersRunJobsAndRequeueOnStop(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	queue := setupJobQueueTest(t, env.db)
+	ctx := context.Background()
+
+	var runs atomic.Int32
+	queue.Register("test.ok", func(ctx context.Context, job *models.Job) error {
+		runs.Add(1)
+		return nil
+	})
+	started := make(chan struct{})
+	queue.Register("test.blocking", func(ctx context.Context, job *models.Job) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	require.NoError(t, queue.Start(ctx))
+	done, err := queue.Enqueue(ctx, "test.ok", nil, nil)
+	require.NoError(t, err)
+	waitForJobStatus(t, queue, done.ID, models.JobStatusSucceeded)
+	require.Equal(t, int32(1), runs.Load())
+
+	blocking, err := queue.Enqueue(ctx, "test.blocking", nil, nil)
+	require.NoError(t, err)
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("blocking job did not start")
+	}
+	queue.Stop()
+
+	// 停止时被中断的任务放回等待队列，不计入尝试次数
+	job, err := queue.GetJob(ctx, blocking.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.JobStatusPending, job.Status)
+	require.Zero(t, job.Attempts)
+	require.Empty(t, job.LockedBy)
+}
+
+func TestJobQueueSchedulesPeriodicJobsFromLastRun(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	queue := setupJobQueueTest(t, env.db)
+	ctx := context.Background()
+
+	queue.Register("test.cleanup", func(ctx context.Context, job *models.Job) error { return nil })
+	queue.Every("test.cleanup", time.Hour)
+
+	// 从未执行过时立即执行，未结束前不重复安排
+	queue.schedulePeriodic(ctx)
+	queue.schedulePeriodic(ctx)
+	var count int64
+	require.NoError(t, env.db.Model(&models.Job{}).Where("type = ?", "test.cleanup").Count(&count).Error)
+	require.Equal(t, int64(1), count)
+
+	job := runNextJob(t, queue)
+	require.Equal(t, models.JobStatusSucceeded, job.Status)
+
+	// 下一次在上一次结束后满一个间隔执行
+	queue.schedulePeriodic(ctx)
+	var next models.Job
+	require.NoError(t, env.db.Where("type = ? AND status = ?", "test.cleanup", models.JobStatusPending).First(&next).Error)
+	require.WithinDuration(t, job.FinishedAt.Add(time.Hour), next.RunAt, time.Second)
+
+	// 超过保留时长的成功任务被删除
+	require.NoError(t, env.db.Model(&models.Job{}).Where("id = ?", job.ID).Update("finished_at", time.Now().Add(-2*time.Hour)).Error)
+	require.NoError(t, queue.purgeFinished(ctx))
+	_, err := queue.GetJob(ctx, job.ID)
+	require.ErrorIs(t, err, ErrJobNotFound)
+}
+
+func TestJobQueueDeliversQueuedSends(t *testing.T) {
+	env, worker, smtp := setupOutboundQueueTest(t)
+	queue := setupJobQueueTest(t, env.db)
+	ctx := context.Background()
+
+	api, ok := NewStandardEmailSender(env.db, worker.providerFactory, nil).(*StandardEmailSender)
+	require.True(t, ok)
+	api.SetQueueOnly(true)
+	api.SetJobQueue(queue)
+	worker.SetJobQueue(queue)
+
+	result, err := api.SendEmail(ctx, &ComposedEmail{
+		ID:       "composed-job",
+		From:     &models.EmailAddress{Address: "tester@example.com"},
+		To:       []*models.EmailAddress{{Address: "alice@example.org"}},
+		Subject:  "Via job queue",
+		TextBody: "Delivered by a job.",
+	}, env.account.ID)
+	require.NoError(t, err)
+	require.Empty(t, smtp.sends)
+
+	var job models.Job
+	require.NoError(t, env.db.Where("type = ?", JobTypeSendDeliver).First(&job).Error)
+	require.Equal(t, "send:"+result.SendID, job.UniqueKey)
+
+	job = *runNextJob(t, queue)
+	require.Equal(t, models.JobStatusSucceeded, job.Status)
+	require.Len(t, smtp.sends, 1)
+
+	status, err := api.GetSendStatus(ctx, result.SendID)
+	require.NoError(t, err)
+	require.Equal(t, "sent", status.Status)
+
+	// 已投递的邮件再次执行任务不会重复发送
+	require.NoError(t, worker.DeliverQueuedSend(ctx, result.SendID))
+	require.Len(t, smtp.sends, 1)
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WorkerLeaseName 后台任务工作进程使用的租约名称
+const WorkerLeaseName = "background-worker"
+
+// defaultLeaseTTL 租约有效期，持有者每隔三分之一有效期续约一次
+const defaultLeaseTTL = 30 * time.Second
+
+// LeaderLease 基于数据库的领导者租约。多个工作进程共享数据库时只有持有租约的进程运行后台任务，
+// 持有者退出或失联超过有效期后由其他进程接替
+type LeaderLease struct {
+	db     *gorm.DB
+	name   string
+	holder string
+	ttl    time.Duration
+}
+
+// NewLeaderLease 创建租约，ttl不大于0时使用默认有效期
+func NewLeaderLease(db *gorm.DB, name string, ttl time.Duration) *LeaderLease {
+	if ttl <= 0 {
+		ttl = defaultLeaseTTL
+	}
+	return &LeaderLease{db: db, name: name, holder: leaseHolderID(), ttl: ttl}
+}
+
+// leaseHolderID 当前进程的持有者标识
+func leaseHolderID() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "unknown"
+	}
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), hex.EncodeToString(buf))
+}
+
+// Holder 当前进程的持有者标识
+func (l *LeaderLease) Holder() string {
+	return l.holder
+}
+
+// TryAcquire 尝试获取或续约租约：租约不存在、已过期或已由自己持有时成功
+func (l *LeaderLease) TryAcquire(ctx context.Context) (bool, error) {
+	now := time.Now()
+	db := l.db.WithContext(ctx)
+
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.WorkerLease{
+		Name:      l.name,
+		Holder:    l.holder,
+		ExpiresAt: now.Add(l.ttl),
+	}).Error; err != nil {
+		return false, fmt.Errorf("failed to create lease: %w", err)
+	}
+
+	result := db.Model(&models.WorkerLease{}).
+		Where("name = ? AND (holder = ? OR expires_at < ?)", l.name, l.holder, now).
+		Updates(map[string]interface{}{"holder": l.holder, "expires_at": now.Add(l.ttl)})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to acquire lease: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Acquire 阻塞直到获得租约，ctx 结束时返回错误
+func (l *LeaderLease) Acquire(ctx context.Context) error {
+	interval := l.ttl / 3
+	waiting := false
+	for {
+		acquired, err := l.TryAcquire(ctx)
+		if err != nil {
+			log.Printf("Warning: %v", err)
+		} else if acquired {
+			return nil
+		} else if !waiting {
+			log.Printf("Lease %s is held by another process, waiting as standby", l.name)
+			waiting = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// Hold 在后台定期续约，续约失败（租约被接替或数据库不可用超过有效期）时关闭返回的通道。
+// ctx 结束时停止续约
+func (l *LeaderLease) Hold(ctx context.Context) <-chan struct{} {
+	lost := make(chan struct{})
+	go func() {
+		defer close(lost)
+		ticker := time.NewTicker(l.ttl / 3)
+		defer ticker.Stop()
+
+		lastRenewed := time.Now()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			acquired, err := l.TryAcquire(ctx)
+			switch {
+			case err == nil && acquired:
+				lastRenewed = time.Now()
+			case err == nil:
+				log.Printf("Lease %s was taken over by another process", l.name)
+				return
+			case time.Since(lastRenewed) >= l.ttl:
+				log.Printf("Failed to renew lease %s: %v", l.name, err)
+				return
+			}
+		}
+	}()
+	return lost
+}
+
+// Release 主动释放租约，其他进程无需等待到期即可接替
+func (l *LeaderLease) Release(ctx context.Context) error {
+	return l.db.WithContext(ctx).Model(&models.WorkerLease{}).
+		Where("name = ? AND holder = ?", l.name, l.holder).
+		Update("expires_at", time.Now().Add(-time.Second)).Error
+}
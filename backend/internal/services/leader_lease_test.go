@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeaderLeaseSingleHolderAndTakeover(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.WorkerLease{}))
+	ctx := context.Background()
+
+	first := NewLeaderLease(env.db, WorkerLeaseName, time.Minute)
+	second := NewLeaderLease(env.db, WorkerLeaseName, time.Minute)
+	require.NotEqual(t, first.Holder(), second.Holder())
+
+	acquired, err := first.TryAcquire(ctx)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	acquired, err = second.TryAcquire(ctx)
+	require.NoError(t, err)
+	require.False(t, acquired)
+
+	// 持有者续约成功
+	acquired, err = first.TryAcquire(ctx)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	// 主动释放后其他进程立即接替
+	require.NoError(t, first.Release(ctx))
+	acquired, err = second.TryAcquire(ctx)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	// 持有者失联超过有效期后也可以接替
+	require.NoError(t, env.db.Model(&models.WorkerLease{}).Where("name = ?", WorkerLeaseName).
+		Update("expires_at", time.Now().Add(-time.Second)).Error)
+	acquired, err = first.TryAcquire(ctx)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	var lease models.WorkerLease
+	require.NoError(t, env.db.First(&lease, "name = ?", WorkerLeaseName).Error)
+	require.Equal(t, first.Holder(), lease.Holder)
+}
+
+func TestLeaderLeaseHoldReportsTakeover(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.WorkerLease{}))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	lease := NewLeaderLease(env.db, WorkerLeaseName, 150*time.Millisecond)
+	require.NoError(t, lease.Acquire(ctx))
+	lost := lease.Hold(ctx)
+
+	require.NoError(t, env.db.Model(&models.WorkerLease{}).Where("name = ?", WorkerLeaseName).
+		Update("holder", "another-worker").Error)
+
+	select {
+	case <-lost:
+	case <-time.After(5 * time.Second):
+		t.Fatal("lease loss was not reported")
+	}
+}
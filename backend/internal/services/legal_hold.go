@@ -0,0 +1,130 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 法律保全的删除保护：
+//   - 生效中的保全覆盖的邮件不能被用户删除，所在账户也不能删除；
+//   - 回收站清理、彻底删除和保留规则等批量操作跳过这些邮件；
+//   - 保全解除后恢复正常的删除语义。
+
+// ErrEmailUnderLegalHold 邮件受法律保全保护，不能删除
+var ErrEmailUnderLegalHold = errors.New("email is under legal hold")
+
+// legalHoldSenderCondition 单个发件人条件：邮箱地址精确匹配，域名同时匹配子域名
+func legalHoldSenderCondition(sender string) (string, []interface{}) {
+	if strings.HasPrefix(sender, "@") {
+		domain := escapeLike(strings.TrimPrefix(sender, "@"))
+		return "(LOWER(emails.from_address) LIKE ? ESCAPE '\\' OR LOWER(emails.from_address) LIKE ? ESCAPE '\\' OR " +
+				"LOWER(emails.from_address) LIKE ? ESCAPE '\\' OR LOWER(emails.from_address) LIKE ? ESCAPE '\\')",
+			[]interface{}{"%@" + domain, "%@" + domain + ">", "%." + domain, "%." + domain + ">"}
+	}
+	return "(LOWER(emails.from_address) = ? OR LOWER(emails.from_address) LIKE ? ESCAPE '\\')",
+		[]interface{}{sender, "%<" + escapeLike(sender) + ">"}
+}
+
+// legalHoldEmailCondition 生成保全覆盖邮件的查询条件
+func legalHoldEmailCondition(hold *models.LegalHold) (string, []interface{}, error) {
+	conditions := []string{"emails.user_id = ?"}
+	args := []interface{}{hold.UserID}
+
+	accountIDs, err := hold.GetAccountIDs()
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid legal hold %d accounts: %w", hold.ID, err)
+	}
+	if len(accountIDs) > 0 {
+		conditions = append(conditions, "emails.account_id IN ?")
+		args = append(args, accountIDs)
+	}
+	if hold.Since != nil {
+		conditions = append(conditions, "emails.date >= ?")
+		args = append(args, *hold.Since)
+	}
+	if hold.Until != nil {
+		conditions = append(conditions, "emails.date < ?")
+		args = append(args, *hold.Until)
+	}
+
+	senders, err := hold.GetSenders()
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid legal hold %d senders: %w", hold.ID, err)
+	}
+	if len(senders) > 0 {
+		senderConditions := make([]string, len(senders))
+		for i, sender := range senders {
+			condition, senderArgs := legalHoldSenderCondition(sender)
+			senderConditions[i] = condition
+			args = append(args, senderArgs...)
+		}
+		conditions = append(conditions, "("+strings.Join(senderConditions, " OR ")+")")
+	}
+	return "(" + strings.Join(conditions, " AND ") + ")", args, nil
+}
+
+// activeLegalHoldCondition 生成所有生效中保全的合并条件，userID为nil时包含所有用户；没有保全时返回空条件
+func activeLegalHoldCondition(db *gorm.DB, userID *uint) (string, []interface{}, error) {
+	db = db.Session(&gorm.Session{NewDB: true})
+	if !db.Migrator().HasTable(&models.LegalHold{}) {
+		return "", nil, nil
+	}
+
+	query := db.Model(&models.LegalHold{}).Where("released_at IS NULL")
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	var holds []models.LegalHold
+	if err := query.Find(&holds).Error; err != nil {
+		return "", nil, fmt.Errorf("failed to load legal holds: %w", err)
+	}
+
+	var conditions []string
+	var args []interface{}
+	for i := range holds {
+		condition, holdArgs, err := legalHoldEmailCondition(&holds[i])
+		if err != nil {
+			return "", nil, err
+		}
+		conditions = append(conditions, condition)
+		args = append(args, holdArgs...)
+	}
+	if len(conditions) == 0 {
+		return "", nil, nil
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", args, nil
+}
+
+// excludeLegalHolds 从查询中排除受保全保护的邮件
+func excludeLegalHolds(query *gorm.DB, userID *uint) (*gorm.DB, error) {
+	held, args, err := activeLegalHoldCondition(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	if held == "" {
+		return query, nil
+	}
+	return query.Where("NOT "+held, args...), nil
+}
+
+// hasHeldEmails 用户满足条件的邮件中是否有受生效中的保全保护的
+func hasHeldEmails(db *gorm.DB, userID uint, query interface{}, args ...interface{}) (bool, error) {
+	held, heldArgs, err := activeLegalHoldCondition(db, &userID)
+	if err != nil || held == "" {
+		return false, err
+	}
+
+	var count int64
+	if err := db.Session(&gorm.Session{NewDB: true}).Model(&models.Email{}).
+		Where(query, args...).
+		Where(held, heldArgs...).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check legal hold: %w", err)
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,883 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"firemail/internal/config"
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// 导出时每批加载的邮件数
+	legalHoldExportBatchSize = 100
+	// 压缩包内的清单和签名文件名
+	legalHoldManifestName  = "manifest.json"
+	legalHoldSignatureName = "manifest.sig"
+)
+
+var (
+	// ErrLegalHoldNotFound 法律保全不存在或无权访问
+	ErrLegalHoldNotFound = errors.New("legal hold not found")
+	// ErrInvalidLegalHold 法律保全参数无效
+	ErrInvalidLegalHold = errors.New("invalid legal hold")
+	// ErrLegalHoldReleased 法律保全已解除
+	ErrLegalHoldReleased = errors.New("legal hold already released")
+	// ErrLegalHoldExportNotFound 导出不存在或无权访问
+	ErrLegalHoldExportNotFound = errors.New("legal hold export not found")
+	// ErrLegalHoldExportRunning 同一保全已有导出在进行
+	ErrLegalHoldExportRunning = errors.New("legal hold export is already running")
+	// ErrLegalHoldExportNotReady 导出尚未完成或文件不可用
+	ErrLegalHoldExportNotReady = errors.New("legal hold export is not available")
+)
+
+// LegalHoldService 法律保全服务接口
+type LegalHoldService interface {
+	// ListHolds 列出法律保全
+	ListHolds(ctx context.Context, userID uint) ([]models.LegalHold, error)
+
+	// CreateHold 创建法律保全，立即保护符合条件的邮件
+	CreateHold(ctx context.Context, userID uint, req *CreateLegalHoldRequest) (*models.LegalHold, error)
+
+	// GetHold 获取法律保全及当前覆盖的邮件数
+	GetHold(ctx context.Context, userID, holdID uint) (*LegalHoldDetail, error)
+
+	// ReleaseHold 解除法律保全，覆盖的邮件恢复正常的删除语义
+	ReleaseHold(ctx context.Context, userID, holdID uint) (*models.LegalHold, error)
+
+	// CreateExport 在后台导出保全覆盖的邮件
+	CreateExport(ctx context.Context, userID, holdID uint) (*models.LegalHoldExport, error)
+
+	// ListExports 列出保全的导出
+	ListExports(ctx context.Context, userID, holdID uint) ([]models.LegalHoldExport, error)
+
+	// GetExport 获取导出
+	GetExport(ctx context.Context, userID, holdID, exportID uint) (*models.LegalHoldExport, error)
+
+	// OpenExport 打开已完成的导出文件，调用方负责关闭
+	OpenExport(ctx context.Context, userID, holdID, exportID uint) (*models.LegalHoldExport, *os.File, error)
+
+	// VerifyExport 重新计算导出文件的哈希并校验清单签名
+	VerifyExport(ctx context.Context, userID, holdID, exportID uint) (*LegalHoldExportVerification, error)
+
+	// Start 启动服务，上次关闭时未完成的导出标记为失败
+	Start(ctx context.Context) error
+
+	// Stop 停止服务并等待进行中的导出结束
+	Stop()
+}
+
+// CreateLegalHoldRequest 创建法律保全的请求，至少需要一个条件
+type CreateLegalHoldRequest struct {
+	Name        string     `json:"name" binding:"required,max=100"`
+	Description string     `json:"description,omitempty"`
+	Senders     []string   `json:"senders,omitempty"`     // 邮箱地址或域名，为空时不限发件人
+	AccountIDs  []uint     `json:"account_ids,omitempty"` // 为空时适用于所有账户
+	Since       *time.Time `json:"since,omitempty"`       // 邮件日期下限（含）
+	Until       *time.Time `json:"until,omitempty"`       // 邮件日期上限（不含）
+}
+
+// LegalHoldDetail 法律保全详情
+type LegalHoldDetail struct {
+	models.LegalHold
+	MatchedEmails int64 `json:"matched_emails"`
+	MatchedBytes  int64 `json:"matched_bytes"`
+}
+
+// LegalHoldManifest 导出压缩包中的清单，签名覆盖清单文件的全部字节
+type LegalHoldManifest struct {
+	HoldID      uint                     `json:"hold_id"`
+	HoldName    string                   `json:"hold_name"`
+	Senders     []string                 `json:"senders"`
+	AccountIDs  []uint                   `json:"account_ids"`
+	Since       *time.Time               `json:"since,omitempty"`
+	Until       *time.Time               `json:"until,omitempty"`
+	ExportID    uint                     `json:"export_id"`
+	GeneratedAt time.Time                `json:"generated_at"`
+	Emails      []LegalHoldManifestEntry `json:"emails"`
+}
+
+// LegalHoldManifestEntry 清单中的一封邮件
+type LegalHoldManifestEntry struct {
+	EmailID   uint      `json:"email_id"`
+	AccountID uint      `json:"account_id"`
+	Folder    string    `json:"folder,omitempty"`
+	UID       uint32    `json:"uid,omitempty"`
+	MessageID string    `json:"message_id"`
+	From      string    `json:"from"`
+	Subject   string    `json:"subject"`
+	Date      time.Time `json:"date"`
+	File      string    `json:"file"`
+	Source    string    `json:"source"` // server: 服务器原文；local: 本地保存的副本
+	Size      int64     `json:"size"`
+	SHA256    string    `json:"sha256"`
+	Error     string    `json:"error,omitempty"` // 无法获取原文的原因
+}
+
+// LegalHoldExportVerification 导出文件的校验结果
+type LegalHoldExportVerification struct {
+	ExportID        uint     `json:"export_id"`
+	Valid           bool     `json:"valid"`
+	ArchiveSHA256   string   `json:"archive_sha256"`
+	ArchiveMatches  bool     `json:"archive_matches"`
+	SignatureValid  bool     `json:"signature_valid"`
+	EntriesChecked  int      `json:"entries_checked"`
+	EntryMismatches []string `json:"entry_mismatches"`
+}
+
+// legalHoldLocalCopy 无法从服务器获取原文时导出的本地副本
+type legalHoldLocalCopy struct {
+	EmailID   uint      `json:"email_id"`
+	MessageID string    `json:"message_id"`
+	Subject   string    `json:"subject"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	CC        string    `json:"cc"`
+	BCC       string    `json:"bcc"`
+	ReplyTo   string    `json:"reply_to"`
+	Date      time.Time `json:"date"`
+	TextBody  string    `json:"text_body"`
+	HTMLBody  string    `json:"html_body"`
+}
+
+// LegalHoldServiceImpl 法律保全服务实现
+type LegalHoldServiceImpl struct {
+	db         *gorm.DB
+	connector  mailboxConnector
+	signingKey []byte
+	exportDir  string
+	jobs       JobEnqueuer // 设置后导出作为后台任务执行，进程重启后继续
+
+	baseCtx context.Context
+	cancel  context.CancelFunc
+	running map[uint]bool // 正在导出的保全
+	wg      sync.WaitGroup
+	mutex   sync.Mutex
+}
+
+// NewLegalHoldService 创建法律保全服务，导出清单使用secret派生的密钥签名
+func NewLegalHoldService(db *gorm.DB, emailService EmailService, secret string, cfg config.ComplianceConfig) LegalHoldService {
+	connector, _ := emailService.(mailboxConnector)
+	key := sha256.Sum256([]byte("firemail-legal-hold:" + secret))
+	return &LegalHoldServiceImpl{
+		db:         db,
+		connector:  connector,
+		signingKey: key[:],
+		exportDir:  cfg.ExportDir,
+		baseCtx:    context.Background(),
+		running:    make(map[uint]bool),
+	}
+}
+
+// ListHolds 列出法律保全，生效中的在前
+func (s *LegalHoldServiceImpl) ListHolds(ctx context.Context, userID uint) ([]models.LegalHold, error) {
+	holds := make([]models.LegalHold, 0)
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("released_at IS NOT NULL, created_at DESC, id DESC").
+		Find(&holds).Error; err != nil {
+		return nil, fmt.Errorf("failed to list legal holds: %w", err)
+	}
+	return holds, nil
+}
+
+// getHold 获取属于用户的法律保全
+func (s *LegalHoldServiceImpl) getHold(ctx context.Context, userID, holdID uint) (*models.LegalHold, error) {
+	var hold models.LegalHold
+	if err := s.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", holdID, userID).
+		First(&hold).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLegalHoldNotFound
+		}
+		return nil, fmt.Errorf("failed to get legal hold: %w", err)
+	}
+	return &hold, nil
+}
+
+// CreateHold 创建法律保全，发件人统一为小写地址或@域名
+func (s *LegalHoldServiceImpl) CreateHold(ctx context.Context, userID uint, req *CreateLegalHoldRequest) (*models.LegalHold, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidLegalHold)
+	}
+	if len(req.Senders) == 0 && len(req.AccountIDs) == 0 && req.Since == nil && req.Until == nil {
+		return nil, fmt.Errorf("%w: at least one of senders, account_ids, since or until is required", ErrInvalidLegalHold)
+	}
+	if req.Since != nil && req.Until != nil && !req.Since.Before(*req.Until) {
+		return nil, fmt.Errorf("%w: since must be before until", ErrInvalidLegalHold)
+	}
+
+	senders := make([]string, 0, len(req.Senders))
+	seen := make(map[string]bool, len(req.Senders))
+	for _, sender := range req.Senders {
+		normalized, err := normalizeBlockedSenderPattern(sender)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid sender %q", ErrInvalidLegalHold, sender)
+		}
+		if !seen[normalized] {
+			seen[normalized] = true
+			senders = append(senders, normalized)
+		}
+	}
+
+	accountIDs := make([]uint, 0, len(req.AccountIDs))
+	if len(req.AccountIDs) > 0 {
+		if err := s.db.WithContext(ctx).Model(&models.EmailAccount{}).
+			Where("id IN ? AND user_id = ?", req.AccountIDs, userID).
+			Order("id ASC").
+			Pluck("id", &accountIDs).Error; err != nil {
+			return nil, fmt.Errorf("failed to check accounts: %w", err)
+		}
+		unique := make(map[uint]bool, len(req.AccountIDs))
+		for _, id := range req.AccountIDs {
+			unique[id] = true
+		}
+		if len(accountIDs) != len(unique) {
+			return nil, fmt.Errorf("%w: account not found", ErrInvalidLegalHold)
+		}
+	}
+
+	hold := &models.LegalHold{
+		UserID:      userID,
+		Name:        name,
+		Description: strings.TrimSpace(req.Description),
+		Since:       req.Since,
+		Until:       req.Until,
+	}
+	if err := hold.SetSenders(senders); err != nil {
+		return nil, fmt.Errorf("failed to create legal hold: %w", err)
+	}
+	if err := hold.SetAccountIDs(accountIDs); err != nil {
+		return nil, fmt.Errorf("failed to create legal hold: %w", err)
+	}
+	if err := s.db.WithContext(ctx).Create(hold).Error; err != nil {
+		return nil, fmt.Errorf("failed to create legal hold: %w", err)
+	}
+	return hold, nil
+}
+
+// GetHold 获取法律保全及当前覆盖的邮件数
+func (s *LegalHoldServiceImpl) GetHold(ctx context.Context, userID, holdID uint) (*LegalHoldDetail, error) {
+	hold, err := s.getHold(ctx, userID, holdID)
+	if err != nil {
+		return nil, err
+	}
+	condition, args, err := legalHoldEmailCondition(hold)
+	if err != nil {
+		return nil, err
+	}
+
+	var totals struct {
+		Count int64
+		Bytes int64
+	}
+	if err := s.db.WithContext(ctx).Model(&models.Email{}).
+		Where(condition, args...).
+		Select("COUNT(*) AS count, COALESCE(SUM(emails.size), 0) AS bytes").
+		Scan(&totals).Error; err != nil {
+		return nil, fmt.Errorf("failed to count held emails: %w", err)
+	}
+	return &LegalHoldDetail{LegalHold: *hold, MatchedEmails: totals.Count, MatchedBytes: totals.Bytes}, nil
+}
+
+// ReleaseHold 解除法律保全，覆盖的邮件恢复正常的删除语义
+func (s *LegalHoldServiceImpl) ReleaseHold(ctx context.Context, userID, holdID uint) (*models.LegalHold, error) {
+	hold, err := s.getHold(ctx, userID, holdID)
+	if err != nil {
+		return nil, err
+	}
+	if !hold.IsActive() {
+		return nil, ErrLegalHoldReleased
+	}
+
+	now := time.Now()
+	if err := s.db.WithContext(ctx).Model(hold).Update("released_at", now).Error; err != nil {
+		return nil, fmt.Errorf("failed to release legal hold: %w", err)
+	}
+	hold.ReleasedAt = &now
+	return hold, nil
+}
+
+// CreateExport 创建导出记录并在后台生成压缩包，同一保全同时只有一个导出
+func (s *LegalHoldServiceImpl) CreateExport(ctx context.Context, userID, holdID uint) (*models.LegalHoldExport, error) {
+	hold, err := s.getHold(ctx, userID, holdID)
+	if err != nil {
+		return nil, err
+	}
+	if s.jobs != nil {
+		return s.enqueueExport(ctx, userID, hold)
+	}
+
+	s.mutex.Lock()
+	if s.running[hold.ID] {
+		s.mutex.Unlock()
+		return nil, ErrLegalHoldExportRunning
+	}
+	s.running[hold.ID] = true
+	baseCtx := s.baseCtx
+	s.mutex.Unlock()
+
+	release := func() {
+		s.mutex.Lock()
+		delete(s.running, hold.ID)
+		s.mutex.Unlock()
+	}
+
+	export := &models.LegalHoldExport{
+		HoldID:    hold.ID,
+		UserID:    userID,
+		Status:    models.LegalHoldExportStatusRunning,
+		StartedAt: time.Now(),
+	}
+	if err := s.db.WithContext(ctx).Create(export).Error; err != nil {
+		release()
+		return nil, fmt.Errorf("failed to create legal hold export: %w", err)
+	}
+
+	snapshot := *export
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer release()
+		s.runExport(baseCtx, hold, export)
+	}()
+	return &snapshot, nil
+}
+
+// legalHoldExportPayload 导出任务的参数
+type legalHoldExportPayload struct {
+	ExportID uint `json:"export_id"`
+}
+
+// legalHoldExportJobKey 导出任务的去重键，启动时据此找出没有任务的进行中导出
+func legalHoldExportJobKey(exportID uint) string {
+	return fmt.Sprintf("legal_hold_export:%d", exportID)
+}
+
+// SetJobQueue 导出改为通过任务队列执行，并注册导出任务的处理函数
+func (s *LegalHoldServiceImpl) SetJobQueue(queue JobQueueService) {
+	s.jobs = queue
+	queue.Register(JobTypeLegalHoldExport, s.runExportJob)
+}
+
+// enqueueExport 创建导出记录并入队导出任务；同一保全的进行中导出记录在数据库中，多个进程间同样互斥
+func (s *LegalHoldServiceImpl) enqueueExport(ctx context.Context, userID uint, hold *models.LegalHold) (*models.LegalHoldExport, error) {
+	var running int64
+	if err := s.db.WithContext(ctx).Model(&models.LegalHoldExport{}).
+		Where("hold_id = ? AND status = ?", hold.ID, models.LegalHoldExportStatusRunning).
+		Count(&running).Error; err != nil {
+		return nil, fmt.Errorf("failed to check running exports: %w", err)
+	}
+	if running > 0 {
+		return nil, ErrLegalHoldExportRunning
+	}
+
+	export := &models.LegalHoldExport{
+		HoldID:    hold.ID,
+		UserID:    userID,
+		Status:    models.LegalHoldExportStatusRunning,
+		StartedAt: time.Now(),
+	}
+	if err := s.db.WithContext(ctx).Create(export).Error; err != nil {
+		return nil, fmt.Errorf("failed to create legal hold export: %w", err)
+	}
+	if _, err := s.jobs.Enqueue(ctx, JobTypeLegalHoldExport, legalHoldExportPayload{ExportID: export.ID}, &JobOptions{
+		UserID:    userID,
+		UniqueKey: legalHoldExportJobKey(export.ID),
+	}); err != nil {
+		s.finishExport(ctx, hold, export, err)
+		return nil, err
+	}
+	return export, nil
+}
+
+// runExportJob 执行导出任务。失败时保持导出为进行中并交给任务队列重试，最后一次尝试失败才记为失败；
+// 进程停止导致的中断同样保持进行中，重启后由任务继续
+func (s *LegalHoldServiceImpl) runExportJob(ctx context.Context, job *models.Job) error {
+	var payload legalHoldExportPayload
+	if err := DecodeJobPayload(job, &payload); err != nil {
+		return err
+	}
+
+	var export models.LegalHoldExport
+	if err := s.db.WithContext(ctx).First(&export, payload.ExportID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: export %d not found", ErrJobPermanent, payload.ExportID)
+		}
+		return fmt.Errorf("failed to load legal hold export: %w", err)
+	}
+	if export.Status != models.LegalHoldExportStatusRunning {
+		return nil
+	}
+	var hold models.LegalHold
+	if err := s.db.WithContext(ctx).First(&hold, export.HoldID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: legal hold %d not found", ErrJobPermanent, export.HoldID)
+		}
+		return fmt.Errorf("failed to load legal hold: %w", err)
+	}
+
+	err := s.writeExport(ctx, &hold, &export)
+	if err != nil && (ctx.Err() != nil || job.Attempts < job.MaxAttempts) {
+		return err
+	}
+	s.finishExport(ctx, &hold, &export, err)
+	return err
+}
+
+// runExport 生成导出文件并记录结果；先写入临时文件，完成后再改名，避免留下不完整的压缩包
+func (s *LegalHoldServiceImpl) runExport(ctx context.Context, hold *models.LegalHold, export *models.LegalHoldExport) {
+	s.finishExport(ctx, hold, export, s.writeExport(ctx, hold, export))
+}
+
+// finishExport 记录导出结果
+func (s *LegalHoldServiceImpl) finishExport(ctx context.Context, hold *models.LegalHold, export *models.LegalHoldExport, err error) {
+	finishedAt := time.Now()
+	export.FinishedAt = &finishedAt
+	if err != nil {
+		export.Status = models.LegalHoldExportStatusFailed
+		export.LastError = err.Error()
+		log.Printf("Legal hold %d export %d failed: %v", hold.ID, export.ID, err)
+	} else {
+		export.Status = models.LegalHoldExportStatusCompleted
+	}
+
+	// 服务停止时ctx已取消，结果仍需写入
+	if err := s.db.WithContext(context.WithoutCancel(ctx)).Save(export).Error; err != nil {
+		log.Printf("Failed to save legal hold export %d: %v", export.ID, err)
+	}
+}
+
+// writeExport 写入压缩包：邮件原文、清单和清单签名
+func (s *LegalHoldServiceImpl) writeExport(ctx context.Context, hold *models.LegalHold, export *models.LegalHoldExport) error {
+	condition, args, err := legalHoldEmailCondition(hold)
+	if err != nil {
+		return err
+	}
+	senders, _ := hold.GetSenders()
+	accountIDs, _ := hold.GetAccountIDs()
+
+	var total int64
+	if err := s.db.WithContext(ctx).Model(&models.Email{}).Where(condition, args...).Count(&total).Error; err != nil {
+		return fmt.Errorf("failed to count held emails: %w", err)
+	}
+	export.TotalEmails = int(total)
+	if err := s.db.WithContext(ctx).Model(export).Update("total_emails", export.TotalEmails).Error; err != nil {
+		return fmt.Errorf("failed to update export progress: %w", err)
+	}
+
+	if err := os.MkdirAll(s.exportDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+	finalPath := filepath.Join(s.exportDir, fmt.Sprintf("legal-hold-%d-export-%d.zip", hold.ID, export.ID))
+	tmpPath := finalPath + ".tmp"
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			file.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	archiveHash := sha256.New()
+	counter := &countingWriter{w: io.MultiWriter(file, archiveHash)}
+	zipWriter := zip.NewWriter(counter)
+
+	manifest := &LegalHoldManifest{
+		HoldID:      hold.ID,
+		HoldName:    hold.Name,
+		Senders:     senders,
+		AccountIDs:  accountIDs,
+		Since:       hold.Since,
+		Until:       hold.Until,
+		ExportID:    export.ID,
+		GeneratedAt: time.Now().UTC(),
+		Emails:      make([]LegalHoldManifestEntry, 0, total),
+	}
+
+	sessions := make(map[uint]*emailSourceSession)
+	sessionErrors := make(map[uint]error)
+	defer func() {
+		for _, session := range sessions {
+			session.close()
+		}
+	}()
+
+	var emails []models.Email
+	err = s.db.WithContext(ctx).
+		Preload("Account").Preload("Folder").Preload("Body").
+		Where(condition, args...).
+		Order("emails.account_id ASC, emails.folder_id ASC, emails.id ASC").
+		FindInBatches(&emails, legalHoldExportBatchSize, func(tx *gorm.DB, batch int) error {
+			for i := range emails {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				entry, err := s.writeExportEmail(ctx, zipWriter, &emails[i], sessions, sessionErrors)
+				if err != nil {
+					return err
+				}
+				manifest.Emails = append(manifest.Emails, *entry)
+				export.ExportedEmails++
+				if entry.Source == "local" {
+					export.LocalEmails++
+				}
+			}
+			return s.db.WithContext(ctx).Model(export).Updates(map[string]interface{}{
+				"exported_emails": export.ExportedEmails,
+				"local_emails":    export.LocalEmails,
+			}).Error
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to export emails: %w", err)
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	manifestHash := sha256.Sum256(manifestData)
+	signature := s.signManifest(manifestData)
+	if err := writeZipFile(zipWriter, legalHoldManifestName, manifest.GeneratedAt, manifestData); err != nil {
+		return err
+	}
+	if err := writeZipFile(zipWriter, legalHoldSignatureName, manifest.GeneratedAt, []byte(signature+"\n")); err != nil {
+		return err
+	}
+	if err := zipWriter.Close(); err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+	if err := os.Rename(tmpPath, finalPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to save export file: %w", err)
+	}
+	succeeded = true
+
+	export.FilePath = finalPath
+	export.FileSize = counter.n
+	export.ArchiveSHA256 = hex.EncodeToString(archiveHash.Sum(nil))
+	export.ManifestSHA256 = hex.EncodeToString(manifestHash[:])
+	export.Signature = signature
+	return nil
+}
+
+// writeExportEmail 写入一封邮件：优先使用服务器上的原文，无法获取时写入本地副本
+func (s *LegalHoldServiceImpl) writeExportEmail(ctx context.Context, zipWriter *zip.Writer, email *models.Email, sessions map[uint]*emailSourceSession, sessionErrors map[uint]error) (*LegalHoldManifestEntry, error) {
+	entry := &LegalHoldManifestEntry{
+		EmailID:   email.ID,
+		AccountID: email.AccountID,
+		UID:       email.UID,
+		MessageID: email.MessageID,
+		From:      email.From,
+		Subject:   email.Subject,
+		Date:      email.Date,
+	}
+	if email.Folder != nil {
+		entry.Folder = email.Folder.GetFullPath()
+	}
+
+	raw, fetchErr := s.fetchRawEmail(ctx, email, sessions, sessionErrors)
+	if fetchErr == nil {
+		defer raw.Close()
+		entry.File = fmt.Sprintf("emails/%d/%d.eml", email.AccountID, email.ID)
+		entry.Source = "server"
+		size, sum, err := writeHashedZipEntry(zipWriter, entry.File, email.Date, raw)
+		if err == nil {
+			entry.Size, entry.SHA256 = size, sum
+			return entry, nil
+		}
+		// 原文读取中途失败时压缩包已写入部分内容，无法回退
+		return nil, fmt.Errorf("email %d: %w", email.ID, err)
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	data, err := json.MarshalIndent(legalHoldLocalCopy{
+		EmailID:   email.ID,
+		MessageID: email.MessageID,
+		Subject:   email.Subject,
+		From:      email.From,
+		To:        email.To,
+		CC:        email.CC,
+		BCC:       email.BCC,
+		ReplyTo:   email.ReplyTo,
+		Date:      email.Date,
+		TextBody:  email.TextBody,
+		HTMLBody:  email.HTMLBody,
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("email %d: %w", email.ID, err)
+	}
+	entry.File = fmt.Sprintf("emails/%d/%d.json", email.AccountID, email.ID)
+	entry.Source = "local"
+	entry.Error = fetchErr.Error()
+	size, sum, err := writeHashedZipEntry(zipWriter, entry.File, email.Date, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("email %d: %w", email.ID, err)
+	}
+	entry.Size, entry.SHA256 = size, sum
+	return entry, nil
+}
+
+// fetchRawEmail 从服务器获取邮件原文，每个账户只连接一次，连接失败的账户不再重试
+func (s *LegalHoldServiceImpl) fetchRawEmail(ctx context.Context, email *models.Email, sessions map[uint]*emailSourceSession, sessionErrors map[uint]error) (io.ReadCloser, error) {
+	if s.connector == nil {
+		return nil, fmt.Errorf("email source unavailable: IMAP not supported")
+	}
+	if err := checkEmailSource(email); err != nil {
+		return nil, err
+	}
+	if err := sessionErrors[email.AccountID]; err != nil {
+		return nil, err
+	}
+
+	session := sessions[email.AccountID]
+	if session == nil {
+		var err error
+		session, err = s.connector.openEmailSourceSession(ctx, &email.Account)
+		if err != nil {
+			sessionErrors[email.AccountID] = err
+			return nil, err
+		}
+		sessions[email.AccountID] = session
+	}
+	if err := session.selectFolder(ctx, email.Folder.GetFullPath()); err != nil {
+		return nil, err
+	}
+	raw, err := session.client.FetchRawEmail(ctx, email.UID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch email source: %w", err)
+	}
+	return raw, nil
+}
+
+// signManifest 计算清单的HMAC-SHA256签名
+func (s *LegalHoldServiceImpl) signManifest(data []byte) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// writeHashedZipEntry 写入压缩包条目，返回内容的字节数和SHA-256
+func writeHashedZipEntry(zipWriter *zip.Writer, name string, modified time.Time, content io.Reader) (int64, string, error) {
+	entry, err := zipWriter.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return 0, "", err
+	}
+	contentHash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(entry, contentHash), content)
+	if err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(contentHash.Sum(nil)), nil
+}
+
+// writeZipFile 写入内容已知的压缩包条目
+func writeZipFile(zipWriter *zip.Writer, name string, modified time.Time, data []byte) error {
+	if _, _, err := writeHashedZipEntry(zipWriter, name, modified, bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// ListExports 列出保全的导出，最新的在前
+func (s *LegalHoldServiceImpl) ListExports(ctx context.Context, userID, holdID uint) ([]models.LegalHoldExport, error) {
+	if _, err := s.getHold(ctx, userID, holdID); err != nil {
+		return nil, err
+	}
+	exports := make([]models.LegalHoldExport, 0)
+	if err := s.db.WithContext(ctx).
+		Where("hold_id = ? AND user_id = ?", holdID, userID).
+		Order("started_at DESC, id DESC").
+		Find(&exports).Error; err != nil {
+		return nil, fmt.Errorf("failed to list legal hold exports: %w", err)
+	}
+	return exports, nil
+}
+
+// GetExport 获取导出
+func (s *LegalHoldServiceImpl) GetExport(ctx context.Context, userID, holdID, exportID uint) (*models.LegalHoldExport, error) {
+	var export models.LegalHoldExport
+	if err := s.db.WithContext(ctx).
+		Where("id = ? AND hold_id = ? AND user_id = ?", exportID, holdID, userID).
+		First(&export).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLegalHoldExportNotFound
+		}
+		return nil, fmt.Errorf("failed to get legal hold export: %w", err)
+	}
+	return &export, nil
+}
+
+// OpenExport 打开已完成的导出文件，调用方负责关闭
+func (s *LegalHoldServiceImpl) OpenExport(ctx context.Context, userID, holdID, exportID uint) (*models.LegalHoldExport, *os.File, error) {
+	export, err := s.GetExport(ctx, userID, holdID, exportID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if export.Status != models.LegalHoldExportStatusCompleted || export.FilePath == "" {
+		return nil, nil, ErrLegalHoldExportNotReady
+	}
+	file, err := os.Open(export.FilePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, ErrLegalHoldExportNotReady
+		}
+		return nil, nil, fmt.Errorf("failed to open export file: %w", err)
+	}
+	return export, file, nil
+}
+
+// VerifyExport 重新计算压缩包哈希，并校验清单签名和每个条目的哈希
+func (s *LegalHoldServiceImpl) VerifyExport(ctx context.Context, userID, holdID, exportID uint) (*LegalHoldExportVerification, error) {
+	export, file, err := s.OpenExport(ctx, userID, holdID, exportID)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	result := &LegalHoldExportVerification{ExportID: export.ID, EntryMismatches: make([]string, 0)}
+	archiveHash := sha256.New()
+	size, err := io.Copy(archiveHash, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export file: %w", err)
+	}
+	result.ArchiveSHA256 = hex.EncodeToString(archiveHash.Sum(nil))
+	result.ArchiveMatches = result.ArchiveSHA256 == export.ArchiveSHA256
+
+	reader, err := zip.NewReader(file, size)
+	if err != nil {
+		// 文件已损坏，无法继续校验条目
+		return result, nil
+	}
+	files := make(map[string]*zip.File, len(reader.File))
+	for _, f := range reader.File {
+		files[f.Name] = f
+	}
+
+	manifestData, err := readZipFile(files[legalHoldManifestName])
+	if err != nil {
+		result.EntryMismatches = append(result.EntryMismatches, legalHoldManifestName+": "+err.Error())
+		return result, nil
+	}
+	signature, err := readZipFile(files[legalHoldSignatureName])
+	if err == nil {
+		expected := s.signManifest(manifestData)
+		result.SignatureValid = hmac.Equal([]byte(strings.TrimSpace(string(signature))), []byte(expected)) &&
+			expected == export.Signature
+	}
+
+	var manifest LegalHoldManifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		result.EntryMismatches = append(result.EntryMismatches, legalHoldManifestName+": "+err.Error())
+		return result, nil
+	}
+	for _, entry := range manifest.Emails {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		result.EntriesChecked++
+		sum, err := hashZipFile(files[entry.File])
+		if err != nil {
+			result.EntryMismatches = append(result.EntryMismatches, entry.File+": "+err.Error())
+		} else if sum != entry.SHA256 {
+			result.EntryMismatches = append(result.EntryMismatches, entry.File+": hash mismatch")
+		}
+	}
+
+	result.Valid = result.ArchiveMatches && result.SignatureValid && len(result.EntryMismatches) == 0
+	return result, nil
+}
+
+// readZipFile 读取压缩包中的小文件
+func readZipFile(f *zip.File) ([]byte, error) {
+	if f == nil {
+		return nil, fmt.Errorf("missing from archive")
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// hashZipFile 计算压缩包条目内容的SHA-256
+func hashZipFile(f *zip.File) (string, error) {
+	if f == nil {
+		return "", fmt.Errorf("missing from archive")
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	contentHash := sha256.New()
+	if _, err := io.Copy(contentHash, rc); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(contentHash.Sum(nil)), nil
+}
+
+// Start 启动服务，上次关闭时未完成的导出标记为失败；使用任务队列时由任务继续导出，只处理没有任务的导出
+func (s *LegalHoldServiceImpl) Start(ctx context.Context) error {
+	query := s.db.WithContext(ctx).Model(&models.LegalHoldExport{}).
+		Where("status = ?", models.LegalHoldExportStatusRunning)
+	if s.jobs != nil {
+		query = query.Where("NOT EXISTS (SELECT 1 FROM jobs WHERE jobs.unique_key = 'legal_hold_export:' || legal_hold_exports.id AND jobs.status IN ?)",
+			[]string{models.JobStatusPending, models.JobStatusRunning})
+	}
+	if err := query.Updates(map[string]interface{}{
+		"status":      models.LegalHoldExportStatusFailed,
+		"last_error":  "interrupted by shutdown",
+		"finished_at": time.Now(),
+	}).Error; err != nil {
+		return fmt.Errorf("failed to reset interrupted legal hold exports: %w", err)
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	s.mutex.Lock()
+	s.baseCtx = runCtx
+	s.cancel = cancel
+	s.mutex.Unlock()
+	return nil
+}
+
+// Stop 停止服务并等待进行中的导出结束
+func (s *LegalHoldServiceImpl) Stop() {
+	s.mutex.Lock()
+	cancel := s.cancel
+	s.mutex.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	s.wg.Wait()
+}
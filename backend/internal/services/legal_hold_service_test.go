@@ -0,0 +1,174 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"firemail/internal/config"
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setupLegalHoldTestEnv(t *testing.T) (*emailStateServiceTestEnv, *LegalHoldServiceImpl) {
+	t.Helper()
+
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.LegalHold{}, &models.LegalHoldExport{}))
+	svc := NewLegalHoldService(env.db, env.service, "test-secret", config.ComplianceConfig{ExportDir: t.TempDir()})
+	return env, svc.(*LegalHoldServiceImpl)
+}
+
+func TestLegalHoldBlocksDeletionUntilReleased(t *testing.T) {
+	env, svc := setupLegalHoldTestEnv(t)
+	ctx := context.Background()
+
+	held := env.createEmail(t, env.inbox, 1, "Contract", true, false)
+	heldInTrash := env.createEmail(t, env.inbox, 2, "Old contract", true, true)
+	other := env.createEmail(t, env.inbox, 3, "Newsletter", true, false)
+	require.NoError(t, env.db.Model(other).Update("from_address", "news@other.org").Error)
+
+	hold, err := svc.CreateHold(ctx, env.user.ID, &CreateLegalHoldRequest{
+		Name:    "Case 42",
+		Senders: []string{" Sender@Example.com ", "sender@example.com"},
+	})
+	require.NoError(t, err)
+	senders, err := hold.GetSenders()
+	require.NoError(t, err)
+	require.Equal(t, []string{"sender@example.com"}, senders)
+
+	detail, err := svc.GetHold(ctx, env.user.ID, hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), detail.MatchedEmails)
+
+	require.ErrorIs(t, env.service.DeleteEmail(ctx, env.user.ID, held.ID), ErrEmailUnderLegalHold)
+	require.ErrorIs(t, env.service.DeleteEmailAccount(ctx, env.user.ID, env.account.ID), ErrEmailUnderLegalHold)
+	require.NoError(t, env.service.DeleteEmail(ctx, env.user.ID, other.ID))
+
+	// 清空回收站时跳过受保护的邮件
+	purged, err := env.service.EmptyTrash(ctx, env.user.ID, nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), purged)
+	var count int64
+	require.NoError(t, env.db.Model(&models.Email{}).Where("id = ?", heldInTrash.ID).Count(&count).Error)
+	require.Equal(t, int64(1), count)
+
+	released, err := svc.ReleaseHold(ctx, env.user.ID, hold.ID)
+	require.NoError(t, err)
+	require.False(t, released.IsActive())
+	_, err = svc.ReleaseHold(ctx, env.user.ID, hold.ID)
+	require.ErrorIs(t, err, ErrLegalHoldReleased)
+
+	require.NoError(t, env.service.DeleteEmail(ctx, env.user.ID, held.ID))
+	purged, err = env.service.EmptyTrash(ctx, env.user.ID, nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), purged)
+}
+
+func TestLegalHoldExportWritesSignedManifest(t *testing.T) {
+	env, svc := setupLegalHoldTestEnv(t)
+	ctx := context.Background()
+
+	raw := []byte("From: sender@example.com\r\nSubject: Contract\r\n\r\nSigned copy attached.\r\n")
+	env.provider.imap.rawMessages = map[uint32][]byte{11: raw}
+	fromServer := env.createEmail(t, env.inbox, 11, "Contract", true, false)
+	localOnly := env.createEmail(t, env.work, 12, "Missing on server", true, false)
+
+	hold, err := svc.CreateHold(ctx, env.user.ID, &CreateLegalHoldRequest{
+		Name:       "Case 43",
+		Senders:    []string{"@example.com"},
+		AccountIDs: []uint{env.account.ID},
+	})
+	require.NoError(t, err)
+
+	export, err := svc.CreateExport(ctx, env.user.ID, hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.LegalHoldExportStatusRunning, export.Status)
+	svc.Stop()
+
+	export, err = svc.GetExport(ctx, env.user.ID, hold.ID, export.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.LegalHoldExportStatusCompleted, export.Status, export.LastError)
+	require.Equal(t, 2, export.TotalEmails)
+	require.Equal(t, 2, export.ExportedEmails)
+	require.Equal(t, 1, export.LocalEmails)
+	require.NotEmpty(t, export.Signature)
+
+	archive, err := zip.OpenReader(export.FilePath)
+	require.NoError(t, err)
+	files := make(map[string]*zip.File)
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+	manifestData, err := readZipFile(files[legalHoldManifestName])
+	require.NoError(t, err)
+	require.NoError(t, archive.Close())
+
+	var manifest LegalHoldManifest
+	require.NoError(t, json.Unmarshal(manifestData, &manifest))
+	require.Len(t, manifest.Emails, 2)
+	sum := sha256.Sum256(raw)
+	for _, entry := range manifest.Emails {
+		switch entry.EmailID {
+		case fromServer.ID:
+			require.Equal(t, "server", entry.Source)
+			require.Equal(t, hex.EncodeToString(sum[:]), entry.SHA256)
+		case localOnly.ID:
+			require.Equal(t, "local", entry.Source)
+			require.NotEmpty(t, entry.Error)
+		default:
+			t.Fatalf("unexpected email %d in manifest", entry.EmailID)
+		}
+	}
+
+	result, err := svc.VerifyExport(ctx, env.user.ID, hold.ID, export.ID)
+	require.NoError(t, err)
+	require.True(t, result.Valid, "%+v", result)
+	require.Equal(t, 2, result.EntriesChecked)
+
+	// 文件被修改后校验失败
+	file, err := os.OpenFile(export.FilePath, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = file.Write([]byte("tampered"))
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	result, err = svc.VerifyExport(ctx, env.user.ID, hold.ID, export.ID)
+	require.NoError(t, err)
+	require.False(t, result.Valid)
+	require.False(t, result.ArchiveMatches)
+}
+
+func TestLegalHoldExportRunsAsJob(t *testing.T) {
+	env, svc := setupLegalHoldTestEnv(t)
+	queue := setupJobQueueTest(t, env.db)
+	svc.SetJobQueue(queue)
+	ctx := context.Background()
+
+	env.createEmail(t, env.inbox, 21, "Invoice", true, false)
+	hold, err := svc.CreateHold(ctx, env.user.ID, &CreateLegalHoldRequest{Name: "Case 44", Senders: []string{"@example.com"}})
+	require.NoError(t, err)
+
+	export, err := svc.CreateExport(ctx, env.user.ID, hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.LegalHoldExportStatusRunning, export.Status)
+
+	// 导出任务未完成前不能再次导出，重启时也不会被标记为失败
+	_, err = svc.CreateExport(ctx, env.user.ID, hold.ID)
+	require.ErrorIs(t, err, ErrLegalHoldExportRunning)
+	require.NoError(t, svc.Start(ctx))
+
+	job := runNextJob(t, queue)
+	require.Equal(t, JobTypeLegalHoldExport, job.Type)
+	require.Equal(t, models.JobStatusSucceeded, job.Status, job.LastError)
+
+	export, err = svc.GetExport(ctx, env.user.ID, hold.ID, export.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.LegalHoldExportStatusCompleted, export.Status, export.LastError)
+	require.Equal(t, 1, export.ExportedEmails)
+}
@@ -0,0 +1,724 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"firemail/internal/models"
+	"firemail/internal/providers"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// 定时任务检查到期代收的间隔
+	mailFetcherCheckInterval = time.Minute
+	// 代收间隔的默认值和下限（分钟）
+	defaultMailFetcherInterval = 10
+	minMailFetcherInterval     = 5
+	// 单次代收最多收取的邮件数，剩余的邮件在下次代收时收取
+	maxMailFetchPerRun = 200
+)
+
+var (
+	// ErrMailFetcherNotFound 代收不存在或无权访问
+	ErrMailFetcherNotFound = errors.New("mail fetcher not found")
+	// ErrInvalidMailFetcher 代收参数无效
+	ErrInvalidMailFetcher = errors.New("invalid mail fetcher")
+	// ErrMailFetcherRunning 代收正在执行
+	ErrMailFetcherRunning = errors.New("mail fetcher is already running")
+)
+
+// MailFetcherService 代收外部邮箱服务接口
+type MailFetcherService interface {
+	// ListFetchers 列出代收
+	ListFetchers(ctx context.Context, userID uint) ([]models.MailFetcher, error)
+
+	// CreateFetcher 创建代收
+	CreateFetcher(ctx context.Context, userID uint, req *MailFetcherRequest) (*models.MailFetcher, error)
+
+	// UpdateFetcher 修改代收，密码为空时保留原密码
+	UpdateFetcher(ctx context.Context, userID, fetcherID uint, req *MailFetcherRequest) (*models.MailFetcher, error)
+
+	// DeleteFetcher 删除代收，已收取的邮件保留
+	DeleteFetcher(ctx context.Context, userID, fetcherID uint) error
+
+	// RunFetcher 立即执行一次代收并返回结果
+	RunFetcher(ctx context.Context, userID, fetcherID uint) (*MailFetchResult, error)
+
+	// Start 启动定时代收
+	Start(ctx context.Context) error
+
+	// Stop 停止定时代收并等待执行中的代收结束
+	Stop()
+}
+
+// MailFetcherRequest 创建或修改代收的请求
+type MailFetcherRequest struct {
+	Name             string `json:"name" binding:"required,max=100"`
+	AccountID        uint   `json:"account_id" binding:"required"`
+	FolderID         *uint  `json:"folder_id,omitempty"` // 为空时写入收件箱
+	Protocol         string `json:"protocol" binding:"required,oneof=pop3 imap"`
+	Host             string `json:"host" binding:"required"`
+	Port             int    `json:"port" binding:"required,min=1,max=65535"`
+	Security         string `json:"security" binding:"omitempty,oneof=SSL TLS STARTTLS NONE"` // 默认SSL
+	Username         string `json:"username" binding:"required"`
+	Password         string `json:"password"`                // 创建时必填，修改时为空表示不变
+	SourceFolder     string `json:"source_folder,omitempty"` // IMAP收取的文件夹，默认INBOX
+	DeleteAfterFetch bool   `json:"delete_after_fetch,omitempty"`
+	Label            string `json:"label,omitempty" binding:"max=100"`
+	IntervalMinutes  int    `json:"interval_minutes,omitempty"` // 默认10分钟，最少5分钟
+	IsEnabled        *bool  `json:"is_enabled,omitempty"`       // 默认启用
+}
+
+// MailFetchResult 一次代收的结果
+type MailFetchResult struct {
+	FetcherID uint   `json:"fetcher_id"`
+	Fetched   int    `json:"fetched"`
+	Failed    int    `json:"failed"`
+	Remaining int    `json:"remaining"` // 超过单次上限、留到下次收取的邮件数
+	Error     string `json:"error,omitempty"`
+}
+
+// mailFetchTarget 写入目标账户并同步，由邮件服务实现
+type mailFetchTarget interface {
+	openEmailSourceSession(ctx context.Context, account *models.EmailAccount) (*emailSourceSession, error)
+	SyncFolder(ctx context.Context, accountID uint, folderName string) error
+}
+
+// pop3Mailbox POP3会话，便于测试替换
+type pop3Mailbox interface {
+	UIDL() ([]providers.POP3Message, error)
+	Retrieve(number int) ([]byte, error)
+	Delete(number int) error
+	Quit() error
+	Close() error
+}
+
+// dialMailFetchPOP3 连接外部POP3邮箱
+var dialMailFetchPOP3 = func(ctx context.Context, config providers.POP3ClientConfig) (pop3Mailbox, error) {
+	return providers.DialPOP3(ctx, config)
+}
+
+// dialMailFetchIMAP 连接外部IMAP邮箱
+var dialMailFetchIMAP = func(ctx context.Context, config providers.IMAPClientConfig) (providers.IMAPClient, error) {
+	client := providers.NewStandardIMAPClient()
+	if err := client.Connect(ctx, config); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// MailFetcherServiceImpl 代收外部邮箱服务实现
+type MailFetcherServiceImpl struct {
+	db     *gorm.DB
+	target mailFetchTarget
+
+	cancel  context.CancelFunc
+	running map[uint]bool // 正在执行的代收
+	wg      sync.WaitGroup
+	mutex   sync.Mutex
+}
+
+// NewMailFetcherService 创建代收外部邮箱服务，写入和同步复用邮件服务的逻辑
+func NewMailFetcherService(db *gorm.DB, emailService EmailService) MailFetcherService {
+	target, _ := emailService.(mailFetchTarget)
+	return &MailFetcherServiceImpl{
+		db:      db,
+		target:  target,
+		running: make(map[uint]bool),
+	}
+}
+
+// ListFetchers 列出代收
+func (s *MailFetcherServiceImpl) ListFetchers(ctx context.Context, userID uint) ([]models.MailFetcher, error) {
+	fetchers := make([]models.MailFetcher, 0)
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("account_id ASC, id ASC").
+		Find(&fetchers).Error; err != nil {
+		return nil, fmt.Errorf("failed to list mail fetchers: %w", err)
+	}
+	return fetchers, nil
+}
+
+// getFetcher 获取属于用户的代收
+func (s *MailFetcherServiceImpl) getFetcher(ctx context.Context, userID, fetcherID uint) (*models.MailFetcher, error) {
+	var fetcher models.MailFetcher
+	if err := s.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", fetcherID, userID).
+		First(&fetcher).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMailFetcherNotFound
+		}
+		return nil, fmt.Errorf("failed to get mail fetcher: %w", err)
+	}
+	return &fetcher, nil
+}
+
+// resolveFetcherTarget 校验目标账户属于当前用户且可以写入，返回目标文件夹ID，未指定时使用收件箱
+func (s *MailFetcherServiceImpl) resolveFetcherTarget(ctx context.Context, userID uint, req *MailFetcherRequest) (uint, error) {
+	var account models.EmailAccount
+	if err := s.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", req.AccountID, userID).
+		First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, fmt.Errorf("%w: account not found", ErrInvalidMailFetcher)
+		}
+		return 0, fmt.Errorf("failed to check account: %w", err)
+	}
+	if account.Provider == providers.IngestProviderName {
+		return 0, fmt.Errorf("%w: inbound accounts cannot receive fetched mail", ErrInvalidMailFetcher)
+	}
+
+	var folder models.Folder
+	query := s.db.WithContext(ctx).Where("account_id = ?", account.ID)
+	if req.FolderID != nil {
+		query = query.Where("id = ?", *req.FolderID)
+	} else {
+		query = query.Where("type = ?", models.FolderTypeInbox)
+	}
+	if err := query.First(&folder).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, fmt.Errorf("%w: folder not found", ErrInvalidMailFetcher)
+		}
+		return 0, fmt.Errorf("failed to check folder: %w", err)
+	}
+	return folder.ID, nil
+}
+
+// applyFetcherRequest 将请求写入代收，连接参数变化时重置收取断点
+func applyFetcherRequest(fetcher *models.MailFetcher, req *MailFetcherRequest, folderID uint) error {
+	security := strings.ToUpper(strings.TrimSpace(req.Security))
+	if security == "" {
+		security = "SSL"
+	}
+	sourceFolder := strings.TrimSpace(req.SourceFolder)
+	if sourceFolder == "" || req.Protocol == models.MailFetcherProtocolPOP3 {
+		sourceFolder = "INBOX"
+	}
+	interval := req.IntervalMinutes
+	if interval == 0 {
+		interval = defaultMailFetcherInterval
+	}
+	if interval < minMailFetcherInterval {
+		return fmt.Errorf("%w: interval_minutes must be at least %d", ErrInvalidMailFetcher, minMailFetcherInterval)
+	}
+
+	host := strings.TrimSpace(req.Host)
+	username := strings.TrimSpace(req.Username)
+	if fetcher.Protocol != req.Protocol || !strings.EqualFold(fetcher.Host, host) ||
+		fetcher.Username != username || fetcher.SourceFolder != sourceFolder {
+		fetcher.UIDValidity = 0
+		fetcher.LastUID = 0
+		fetcher.FetchedUIDLs = ""
+	}
+
+	fetcher.Name = strings.TrimSpace(req.Name)
+	fetcher.AccountID = req.AccountID
+	fetcher.FolderID = folderID
+	fetcher.Protocol = req.Protocol
+	fetcher.Host = host
+	fetcher.Port = req.Port
+	fetcher.Security = security
+	fetcher.Username = username
+	if req.Password != "" {
+		fetcher.Password = req.Password
+	}
+	fetcher.SourceFolder = sourceFolder
+	fetcher.DeleteAfterFetch = req.DeleteAfterFetch
+	fetcher.Label = strings.TrimSpace(req.Label)
+	fetcher.IntervalMinutes = interval
+	if req.IsEnabled != nil {
+		fetcher.IsEnabled = *req.IsEnabled
+	}
+
+	if fetcher.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidMailFetcher)
+	}
+	if fetcher.Host == "" || fetcher.Username == "" {
+		return fmt.Errorf("%w: host and username are required", ErrInvalidMailFetcher)
+	}
+	if fetcher.Password == "" {
+		return fmt.Errorf("%w: password is required", ErrInvalidMailFetcher)
+	}
+	return nil
+}
+
+// CreateFetcher 创建代收
+func (s *MailFetcherServiceImpl) CreateFetcher(ctx context.Context, userID uint, req *MailFetcherRequest) (*models.MailFetcher, error) {
+	folderID, err := s.resolveFetcherTarget(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	fetcher := &models.MailFetcher{UserID: userID, IsEnabled: true}
+	if err := applyFetcherRequest(fetcher, req, folderID); err != nil {
+		return nil, err
+	}
+	enabled := fetcher.IsEnabled
+	if err := s.db.WithContext(ctx).Create(fetcher).Error; err != nil {
+		return nil, fmt.Errorf("failed to create mail fetcher: %w", err)
+	}
+	// 创建时false会被字段默认值覆盖，需单独写入
+	if !enabled {
+		if err := s.db.WithContext(ctx).Model(fetcher).Update("is_enabled", false).Error; err != nil {
+			return nil, fmt.Errorf("failed to create mail fetcher: %w", err)
+		}
+	}
+	return fetcher, nil
+}
+
+// UpdateFetcher 修改代收
+func (s *MailFetcherServiceImpl) UpdateFetcher(ctx context.Context, userID, fetcherID uint, req *MailFetcherRequest) (*models.MailFetcher, error) {
+	fetcher, err := s.getFetcher(ctx, userID, fetcherID)
+	if err != nil {
+		return nil, err
+	}
+	folderID, err := s.resolveFetcherTarget(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyFetcherRequest(fetcher, req, folderID); err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Save(fetcher).Error; err != nil {
+		return nil, fmt.Errorf("failed to update mail fetcher: %w", err)
+	}
+	return fetcher, nil
+}
+
+// DeleteFetcher 删除代收，执行中的代收不能删除
+func (s *MailFetcherServiceImpl) DeleteFetcher(ctx context.Context, userID, fetcherID uint) error {
+	fetcher, err := s.getFetcher(ctx, userID, fetcherID)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	running := s.running[fetcher.ID]
+	s.mutex.Unlock()
+	if running {
+		return ErrMailFetcherRunning
+	}
+
+	if err := s.db.WithContext(ctx).Delete(fetcher).Error; err != nil {
+		return fmt.Errorf("failed to delete mail fetcher: %w", err)
+	}
+	return nil
+}
+
+// RunFetcher 立即执行一次代收
+func (s *MailFetcherServiceImpl) RunFetcher(ctx context.Context, userID, fetcherID uint) (*MailFetchResult, error) {
+	fetcher, err := s.getFetcher(ctx, userID, fetcherID)
+	if err != nil {
+		return nil, err
+	}
+	return s.run(ctx, fetcher)
+}
+
+// run 执行代收，同一代收同时只有一个执行；结果写入代收的状态
+func (s *MailFetcherServiceImpl) run(ctx context.Context, fetcher *models.MailFetcher) (*MailFetchResult, error) {
+	s.mutex.Lock()
+	if s.running[fetcher.ID] {
+		s.mutex.Unlock()
+		return nil, ErrMailFetcherRunning
+	}
+	s.running[fetcher.ID] = true
+	s.mutex.Unlock()
+	defer func() {
+		s.mutex.Lock()
+		delete(s.running, fetcher.ID)
+		s.mutex.Unlock()
+	}()
+
+	result := &MailFetchResult{FetcherID: fetcher.ID}
+	runErr := s.fetch(ctx, fetcher, result)
+	if runErr != nil {
+		result.Error = runErr.Error()
+	}
+
+	now := time.Now()
+	// 请求取消时结果仍需写入
+	if err := s.db.WithContext(context.WithoutCancel(ctx)).Model(fetcher).Updates(map[string]interface{}{
+		"last_run_at":   now,
+		"last_error":    result.Error,
+		"fetched_count": gorm.Expr("fetched_count + ?", result.Fetched),
+	}).Error; err != nil {
+		log.Printf("Failed to save mail fetcher %d status: %v", fetcher.ID, err)
+	}
+	fetcher.LastRunAt = &now
+	fetcher.LastError = result.Error
+	fetcher.FetchedCount += result.Fetched
+
+	if result.Fetched > 0 || result.Failed > 0 {
+		log.Printf("Mail fetcher %d: %d fetched, %d failed, %d remaining", fetcher.ID, result.Fetched, result.Failed, result.Remaining)
+	}
+	return result, nil
+}
+
+// fetchRun 一次代收期间的目标会话和已写入邮件
+type fetchRun struct {
+	fetcher    *models.MailFetcher
+	target     *emailSourceSession
+	folderPath string
+	messageIDs []string // 已写入邮件的Message-ID，用于同步后附加标签
+}
+
+// fetch 连接目标账户和外部邮箱，收取新邮件写入目标文件夹，完成后同步目标文件夹
+func (s *MailFetcherServiceImpl) fetch(ctx context.Context, fetcher *models.MailFetcher, result *MailFetchResult) error {
+	if s.target == nil {
+		return fmt.Errorf("mail fetch target not configured")
+	}
+
+	var account models.EmailAccount
+	if err := s.db.WithContext(ctx).First(&account, fetcher.AccountID).Error; err != nil {
+		return fmt.Errorf("failed to load target account: %w", err)
+	}
+	var folder models.Folder
+	if err := s.db.WithContext(ctx).Where("id = ? AND account_id = ?", fetcher.FolderID, account.ID).First(&folder).Error; err != nil {
+		return fmt.Errorf("failed to load target folder: %w", err)
+	}
+
+	session, err := s.target.openEmailSourceSession(ctx, &account)
+	if err != nil {
+		return fmt.Errorf("target account %s: %w", account.Email, err)
+	}
+	run := &fetchRun{fetcher: fetcher, target: session, folderPath: folder.GetFullPath()}
+
+	switch fetcher.Protocol {
+	case models.MailFetcherProtocolPOP3:
+		err = s.fetchPOP3(ctx, run, result)
+	case models.MailFetcherProtocolIMAP:
+		err = s.fetchIMAP(ctx, run, result)
+	default:
+		err = fmt.Errorf("unsupported protocol: %s", fetcher.Protocol)
+	}
+	session.close()
+
+	if result.Fetched > 0 {
+		if syncErr := s.target.SyncFolder(ctx, account.ID, run.folderPath); syncErr != nil {
+			log.Printf("Mail fetcher %d: failed to sync %s: %v", fetcher.ID, run.folderPath, syncErr)
+		}
+		s.labelFetchedEmails(ctx, run, &folder)
+	}
+	return err
+}
+
+// deliver 把邮件原文写入目标文件夹，写入的邮件为未读
+func (s *MailFetcherServiceImpl) deliver(ctx context.Context, run *fetchRun, raw []byte) error {
+	var date time.Time
+	if msg, err := mail.ReadMessage(bytes.NewReader(raw)); err == nil {
+		if parsed, err := msg.Header.Date(); err == nil {
+			date = parsed
+		}
+		if messageID := strings.TrimSpace(msg.Header.Get("Message-ID")); messageID != "" {
+			run.messageIDs = append(run.messageIDs, messageID)
+		}
+	}
+	if err := run.target.client.AppendMessage(ctx, run.folderPath, nil, date, raw); err != nil {
+		return fmt.Errorf("failed to append email: %w", err)
+	}
+	return nil
+}
+
+// fetchPOP3 收取POP3邮箱中未收取的邮件；保留邮件时按UIDL记录已收取的邮件
+func (s *MailFetcherServiceImpl) fetchPOP3(ctx context.Context, run *fetchRun, result *MailFetchResult) error {
+	fetcher := run.fetcher
+	mailbox, err := dialMailFetchPOP3(ctx, providers.POP3ClientConfig{
+		Host:     fetcher.Host,
+		Port:     fetcher.Port,
+		Security: fetcher.Security,
+		Username: fetcher.Username,
+		Password: fetcher.Password,
+	})
+	if err != nil {
+		return err
+	}
+
+	messages, err := mailbox.UIDL()
+	if err != nil {
+		mailbox.Close()
+		return err
+	}
+
+	fetched := make(map[string]bool)
+	for _, uidl := range fetcher.GetFetchedUIDLs() {
+		fetched[uidl] = true
+	}
+
+	var fetchErr error
+	processed := 0
+	deleted := make(map[string]bool)
+	for _, message := range messages {
+		if fetched[message.UID] {
+			continue
+		}
+		if processed >= maxMailFetchPerRun {
+			result.Remaining++
+			continue
+		}
+		if ctx.Err() != nil {
+			fetchErr = ctx.Err()
+			break
+		}
+		processed++
+
+		raw, err := mailbox.Retrieve(message.Number)
+		if err == nil {
+			err = s.deliver(ctx, run, raw)
+		}
+		if err != nil {
+			result.Failed++
+			fetchErr = err
+			log.Printf("Mail fetcher %d: POP3 message %s: %v", fetcher.ID, message.UID, err)
+			continue
+		}
+		result.Fetched++
+		fetched[message.UID] = true
+
+		if fetcher.DeleteAfterFetch {
+			if err := mailbox.Delete(message.Number); err != nil {
+				fetchErr = err
+			} else {
+				deleted[message.UID] = true
+			}
+		}
+	}
+
+	// 删除在QUIT成功后才生效，QUIT失败时已标记删除的邮件也需要记录，避免下次重复收取
+	if err := mailbox.Quit(); err != nil {
+		if fetchErr == nil {
+			fetchErr = fmt.Errorf("POP3 QUIT failed: %w", err)
+		}
+		deleted = nil
+	}
+
+	// 只记录邮箱中仍存在的已收取邮件
+	var keep []string
+	for _, message := range messages {
+		if fetched[message.UID] && !deleted[message.UID] {
+			keep = append(keep, message.UID)
+		}
+	}
+	if err := fetcher.SetFetchedUIDLs(keep); err == nil {
+		if err := s.db.WithContext(context.WithoutCancel(ctx)).Model(fetcher).
+			Update("fetched_uidls", fetcher.FetchedUIDLs).Error; err != nil {
+			log.Printf("Failed to save mail fetcher %d checkpoint: %v", fetcher.ID, err)
+		}
+	}
+	return fetchErr
+}
+
+// fetchIMAP 收取IMAP文件夹中断点之后的邮件，每封写入成功后推进断点
+func (s *MailFetcherServiceImpl) fetchIMAP(ctx context.Context, run *fetchRun, result *MailFetchResult) error {
+	fetcher := run.fetcher
+	client, err := dialMailFetchIMAP(ctx, providers.IMAPClientConfig{
+		Host:     fetcher.Host,
+		Port:     fetcher.Port,
+		Security: fetcher.Security,
+		Username: fetcher.Username,
+		Password: fetcher.Password,
+	})
+	if err != nil {
+		return err
+	}
+	defer client.Disconnect()
+
+	status, err := client.SelectFolder(ctx, fetcher.SourceFolder)
+	if err != nil {
+		return fmt.Errorf("failed to select source folder: %w", err)
+	}
+	if status != nil {
+		if fetcher.UIDValidity != 0 && status.UIDValidity != fetcher.UIDValidity {
+			log.Printf("Mail fetcher %d: UIDVALIDITY of %s changed, fetching from the beginning", fetcher.ID, fetcher.SourceFolder)
+			fetcher.LastUID = 0
+		}
+		fetcher.UIDValidity = status.UIDValidity
+	}
+
+	uids, err := client.SearchEmails(ctx, &providers.SearchCriteria{FolderName: fetcher.SourceFolder})
+	if err != nil {
+		return fmt.Errorf("failed to list source emails: %w", err)
+	}
+	sort.Slice(uids, func(i, j int) bool { return uids[i] < uids[j] })
+
+	var pending []uint32
+	for _, uid := range uids {
+		if uid > fetcher.LastUID {
+			pending = append(pending, uid)
+		}
+	}
+	if len(pending) > maxMailFetchPerRun {
+		result.Remaining = len(pending) - maxMailFetchPerRun
+		pending = pending[:maxMailFetchPerRun]
+	}
+
+	saveCheckpoint := func() {
+		if err := s.db.WithContext(context.WithoutCancel(ctx)).Model(fetcher).Updates(map[string]interface{}{
+			"uid_validity": fetcher.UIDValidity,
+			"last_uid":     fetcher.LastUID,
+		}).Error; err != nil {
+			log.Printf("Failed to save mail fetcher %d checkpoint: %v", fetcher.ID, err)
+		}
+	}
+	saveCheckpoint()
+
+	var fetchErr error
+	for _, uid := range pending {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		err := s.fetchIMAPMessage(ctx, run, client, uid)
+		if err != nil {
+			// 写入失败时断点停在这里，下次代收从该邮件重试
+			result.Failed++
+			result.Remaining += len(pending) - result.Fetched
+			return err
+		}
+		result.Fetched++
+
+		fetcher.LastUID = uid
+		saveCheckpoint()
+
+		if fetcher.DeleteAfterFetch {
+			if err := client.DeleteEmails(ctx, []uint32{uid}); err != nil {
+				fetchErr = fmt.Errorf("failed to delete fetched email: %w", err)
+			}
+		}
+	}
+	return fetchErr
+}
+
+// fetchIMAPMessage 获取IMAP邮件原文并写入目标文件夹
+func (s *MailFetcherServiceImpl) fetchIMAPMessage(ctx context.Context, run *fetchRun, client providers.IMAPClient, uid uint32) error {
+	raw, err := client.FetchRawEmail(ctx, uid)
+	if err != nil {
+		return fmt.Errorf("failed to fetch email source: %w", err)
+	}
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(raw)
+	raw.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read email source: %w", err)
+	}
+	return s.deliver(ctx, run, buf.Bytes())
+}
+
+// labelFetchedEmails 为同步到目标文件夹的代收邮件附加标签
+func (s *MailFetcherServiceImpl) labelFetchedEmails(ctx context.Context, run *fetchRun, folder *models.Folder) {
+	label := run.fetcher.Label
+	if label == "" || len(run.messageIDs) == 0 {
+		return
+	}
+
+	var emails []models.Email
+	if err := s.db.WithContext(ctx).
+		Where("account_id = ? AND folder_id = ? AND message_id IN ?", folder.AccountID, folder.ID, run.messageIDs).
+		Find(&emails).Error; err != nil {
+		log.Printf("Mail fetcher %d: failed to load fetched emails: %v", run.fetcher.ID, err)
+		return
+	}
+
+	for i := range emails {
+		email := &emails[i]
+		labels, err := email.GetLabels()
+		if err != nil {
+			labels = nil
+		}
+		labeled := false
+		for _, existing := range labels {
+			if existing == label {
+				labeled = true
+				break
+			}
+		}
+		if labeled {
+			continue
+		}
+		if err := email.SetLabels(append(labels, label)); err != nil {
+			continue
+		}
+		if err := s.db.WithContext(ctx).Model(email).Update("labels", email.Labels).Error; err != nil {
+			log.Printf("Mail fetcher %d: failed to label email %d: %v", run.fetcher.ID, email.ID, err)
+		}
+	}
+}
+
+// runDueFetchers 执行所有到期的启用代收
+func (s *MailFetcherServiceImpl) runDueFetchers(ctx context.Context) {
+	var fetchers []models.MailFetcher
+	if err := s.db.WithContext(ctx).
+		Where("is_enabled = ?", true).
+		Order("id ASC").
+		Find(&fetchers).Error; err != nil {
+		log.Printf("Failed to load mail fetchers: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for i := range fetchers {
+		fetcher := &fetchers[i]
+		if fetcher.LastRunAt != nil && now.Sub(*fetcher.LastRunAt) < time.Duration(fetcher.IntervalMinutes)*time.Minute {
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			if _, err := s.run(ctx, fetcher); err != nil && !errors.Is(err, ErrMailFetcherRunning) {
+				log.Printf("Failed to run mail fetcher %d: %v", fetcher.ID, err)
+			}
+		}()
+	}
+}
+
+// Start 启动定时代收
+func (s *MailFetcherServiceImpl) Start(ctx context.Context) error {
+	runCtx, cancel := context.WithCancel(ctx)
+	s.mutex.Lock()
+	s.cancel = cancel
+	s.mutex.Unlock()
+
+	log.Println("Starting mail fetcher scheduler...")
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(mailFetcherCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.runDueFetchers(runCtx)
+			case <-runCtx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop 停止定时代收并等待执行中的代收结束
+func (s *MailFetcherServiceImpl) Stop() {
+	s.mutex.Lock()
+	cancel := s.cancel
+	s.mutex.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	s.wg.Wait()
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+	"firemail/internal/providers"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakePOP3Mailbox 模拟外部POP3邮箱，删除在QUIT时生效
+type fakePOP3Mailbox struct {
+	messages []providers.POP3Message
+	raw      map[int][]byte
+	marked   map[int]bool
+	quits    int
+}
+
+func newFakePOP3Mailbox(count int) *fakePOP3Mailbox {
+	mailbox := &fakePOP3Mailbox{raw: make(map[int][]byte), marked: make(map[int]bool)}
+	for i := 1; i <= count; i++ {
+		mailbox.messages = append(mailbox.messages, providers.POP3Message{Number: i, UID: fmt.Sprintf("uidl-%d", i)})
+		mailbox.raw[i] = []byte(fmt.Sprintf("From: remote@example.net\r\nTo: me@example.net\r\nSubject: Remote %d\r\n"+
+			"Message-ID: <remote-%d@example.net>\r\nDate: Mon, 05 Oct 2026 10:00:00 +0000\r\n\r\nbody %d\r\n", i, i, i))
+	}
+	return mailbox
+}
+
+func (m *fakePOP3Mailbox) UIDL() ([]providers.POP3Message, error) {
+	// 每个会话重新编号
+	m.marked = make(map[int]bool)
+	result := make([]providers.POP3Message, len(m.messages))
+	for i, message := range m.messages {
+		result[i] = providers.POP3Message{Number: i + 1, UID: message.UID}
+	}
+	return result, nil
+}
+
+func (m *fakePOP3Mailbox) Retrieve(number int) ([]byte, error) {
+	if number < 1 || number > len(m.messages) {
+		return nil, errors.New("no such message")
+	}
+	return m.raw[m.messages[number-1].Number], nil
+}
+
+func (m *fakePOP3Mailbox) Delete(number int) error {
+	m.marked[number] = true
+	return nil
+}
+
+func (m *fakePOP3Mailbox) Quit() error {
+	m.quits++
+	var kept []providers.POP3Message
+	for i, message := range m.messages {
+		if !m.marked[i+1] {
+			kept = append(kept, message)
+		}
+	}
+	m.messages = kept
+	return nil
+}
+
+func (m *fakePOP3Mailbox) Close() error { return nil }
+
+// syncingFetchTarget 同步目标文件夹时把写入的邮件保存到本地，模拟真实同步
+type syncingFetchTarget struct {
+	*EmailServiceImpl
+	env *emailStateServiceTestEnv
+}
+
+func (s *syncingFetchTarget) SyncFolder(ctx context.Context, accountID uint, folderName string) error {
+	for i, call := range s.env.provider.imap.appendCalls {
+		msg, err := mail.ReadMessage(bytes.NewReader(call.Data))
+		if err != nil {
+			return err
+		}
+		message := msg.Header.Get("Message-ID")
+		var count int64
+		s.env.db.Model(&models.Email{}).Where("message_id = ?", message).Count(&count)
+		if count > 0 {
+			continue
+		}
+		folder := s.env.inbox
+		if folderName == s.env.work.Path {
+			folder = s.env.work
+		}
+		email := &models.Email{
+			AccountID: accountID,
+			FolderID:  &folder.ID,
+			MessageID: message,
+			UID:       uint32(100 + i),
+			Subject:   "fetched",
+			Date:      time.Now(),
+		}
+		if err := s.env.db.Create(email).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setupMailFetcherTest(t *testing.T, mailbox *fakePOP3Mailbox) (*emailStateServiceTestEnv, *MailFetcherServiceImpl) {
+	t.Helper()
+
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.MailFetcher{}))
+
+	original := dialMailFetchPOP3
+	dialMailFetchPOP3 = func(ctx context.Context, config providers.POP3ClientConfig) (pop3Mailbox, error) {
+		if config.Password != "remote-secret" {
+			return nil, errors.New("POP3 authentication failed: invalid password")
+		}
+		return mailbox, nil
+	}
+	t.Cleanup(func() { dialMailFetchPOP3 = original })
+
+	service := &MailFetcherServiceImpl{
+		db:      env.db,
+		target:  &syncingFetchTarget{EmailServiceImpl: env.service, env: env},
+		running: make(map[uint]bool),
+	}
+	return env, service
+}
+
+func TestMailFetcherPOP3KeepsMailAndSkipsFetched(t *testing.T) {
+	mailbox := newFakePOP3Mailbox(2)
+	env, service := setupMailFetcherTest(t, mailbox)
+	ctx := context.Background()
+
+	fetcher, err := service.CreateFetcher(ctx, env.user.ID, &MailFetcherRequest{
+		Name:      "Old ISP mailbox",
+		AccountID: env.account.ID,
+		FolderID:  &env.work.ID,
+		Protocol:  models.MailFetcherProtocolPOP3,
+		Host:      "pop.example.net",
+		Port:      995,
+		Username:  "me@example.net",
+		Password:  "remote-secret",
+	})
+	require.NoError(t, err)
+	require.Equal(t, defaultMailFetcherInterval, fetcher.IntervalMinutes)
+	require.Equal(t, "SSL", fetcher.Security)
+
+	result, err := service.RunFetcher(ctx, env.user.ID, fetcher.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2, result.Fetched)
+	require.Empty(t, result.Error)
+
+	appends := env.provider.imap.appendCalls
+	require.Len(t, appends, 2)
+	require.Equal(t, "Projects", appends[0].Folder)
+	require.Empty(t, appends[0].Flags, "fetched mail should arrive unread")
+	require.Len(t, mailbox.messages, 2, "mail should stay on the remote server")
+
+	// 新邮件到达后只收取未收取过的邮件
+	mailbox.messages = append(mailbox.messages, providers.POP3Message{Number: 3, UID: "uidl-3"})
+	mailbox.raw[3] = newFakePOP3Mailbox(3).raw[3]
+	result, err = service.RunFetcher(ctx, env.user.ID, fetcher.ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Fetched)
+	require.Len(t, env.provider.imap.appendCalls, 3)
+
+	var stored models.MailFetcher
+	require.NoError(t, env.db.First(&stored, fetcher.ID).Error)
+	require.Equal(t, []string{"uidl-1", "uidl-2", "uidl-3"}, stored.GetFetchedUIDLs())
+	require.Equal(t, 3, stored.FetchedCount)
+	require.NotNil(t, stored.LastRunAt)
+}
+
+func TestMailFetcherPOP3DeleteAfterFetchAndLabel(t *testing.T) {
+	mailbox := newFakePOP3Mailbox(2)
+	env, service := setupMailFetcherTest(t, mailbox)
+	ctx := context.Background()
+
+	fetcher, err := service.CreateFetcher(ctx, env.user.ID, &MailFetcherRequest{
+		Name:             "Side project",
+		AccountID:        env.account.ID,
+		Protocol:         models.MailFetcherProtocolPOP3,
+		Host:             "pop.example.net",
+		Port:             995,
+		Username:         "me@example.net",
+		Password:         "remote-secret",
+		DeleteAfterFetch: true,
+		Label:            "side-project",
+	})
+	require.NoError(t, err)
+	require.Equal(t, env.inbox.ID, fetcher.FolderID, "target folder should default to the inbox")
+
+	result, err := service.RunFetcher(ctx, env.user.ID, fetcher.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2, result.Fetched)
+	require.Empty(t, mailbox.messages, "fetched mail should be deleted from the remote server")
+
+	var stored models.MailFetcher
+	require.NoError(t, env.db.First(&stored, fetcher.ID).Error)
+	require.Empty(t, stored.GetFetchedUIDLs())
+
+	var emails []models.Email
+	require.NoError(t, env.db.Where("folder_id = ?", env.inbox.ID).Find(&emails).Error)
+	require.Len(t, emails, 2)
+	for _, email := range emails {
+		labels, err := email.GetLabels()
+		require.NoError(t, err)
+		require.Equal(t, []string{"side-project"}, labels)
+	}
+}
+
+func TestMailFetcherRecordsErrorsAndValidates(t *testing.T) {
+	env, service := setupMailFetcherTest(t, newFakePOP3Mailbox(1))
+	ctx := context.Background()
+
+	req := &MailFetcherRequest{
+		Name:            "Broken",
+		AccountID:       env.account.ID,
+		Protocol:        models.MailFetcherProtocolPOP3,
+		Host:            "pop.example.net",
+		Port:            110,
+		Security:        "NONE",
+		Username:        "me@example.net",
+		IntervalMinutes: 15,
+	}
+	_, err := service.CreateFetcher(ctx, env.user.ID, req)
+	require.ErrorIs(t, err, ErrInvalidMailFetcher, "password is required on create")
+
+	req.Password = "wrong"
+	req.IntervalMinutes = 1
+	_, err = service.CreateFetcher(ctx, env.user.ID, req)
+	require.ErrorIs(t, err, ErrInvalidMailFetcher)
+
+	req.IntervalMinutes = 15
+	fetcher, err := service.CreateFetcher(ctx, env.user.ID, req)
+	require.NoError(t, err)
+
+	result, err := service.RunFetcher(ctx, env.user.ID, fetcher.ID)
+	require.NoError(t, err)
+	require.Contains(t, result.Error, "authentication failed")
+
+	// 修改时密码为空保留原密码
+	req.Name = "Renamed"
+	req.Password = ""
+	updated, err := service.UpdateFetcher(ctx, env.user.ID, fetcher.ID, req)
+	require.NoError(t, err)
+	require.Equal(t, "wrong", updated.Password)
+	require.Contains(t, updated.LastError, "authentication failed")
+
+	_, err = service.RunFetcher(ctx, env.user.ID+1, fetcher.ID)
+	require.ErrorIs(t, err, ErrMailFetcherNotFound)
+}
@@ -0,0 +1,790 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+
+	"firemail/internal/config"
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// 邮件合并限制
+	mailMergeMaxRecipients      = 10000
+	mailMergeDefaultRatePerMin  = 30
+	mailMergeMaxRatePerMin      = 600
+	mailMergeSendPollInterval   = 500 * time.Millisecond
+	mailMergeSendResultTimeout  = 5 * time.Minute
+	mailMergeEmailColumn        = "email"
+	mailMergeNameColumn         = "name"
+	mailMergeInterruptedMessage = "interrupted while sending"
+)
+
+var (
+	// ErrMailMergeCampaignNotFound 活动不存在或无权访问
+	ErrMailMergeCampaignNotFound = errors.New("campaign not found or access denied")
+)
+
+// MailMergeService 邮件合并服务接口
+type MailMergeService interface {
+	// CreateCampaign 根据CSV创建邮件合并活动
+	CreateCampaign(ctx context.Context, userID uint, req *CreateMailMergeCampaignRequest, recipients io.Reader) (*models.MailMergeCampaign, error)
+
+	// GetCampaign 获取活动
+	GetCampaign(ctx context.Context, userID, campaignID uint) (*models.MailMergeCampaign, error)
+
+	// ListCampaigns 列出活动
+	ListCampaigns(ctx context.Context, userID uint) ([]*models.MailMergeCampaign, error)
+
+	// ListRecipients 列出活动收件人
+	ListRecipients(ctx context.Context, userID, campaignID uint, req *ListMailMergeRecipientsRequest) (*ListMailMergeRecipientsResponse, error)
+
+	// StartCampaign 开始发送
+	StartCampaign(ctx context.Context, userID, campaignID uint) (*models.MailMergeCampaign, error)
+
+	// PauseCampaign 暂停发送
+	PauseCampaign(ctx context.Context, userID, campaignID uint) (*models.MailMergeCampaign, error)
+
+	// ResumeCampaign 恢复发送
+	ResumeCampaign(ctx context.Context, userID, campaignID uint) (*models.MailMergeCampaign, error)
+
+	// CancelCampaign 取消活动
+	CancelCampaign(ctx context.Context, userID, campaignID uint) (*models.MailMergeCampaign, error)
+
+	// GetCampaignReport 获取活动的打开和点击统计
+	GetCampaignReport(ctx context.Context, userID, campaignID uint) (*MailMergeCampaignReport, error)
+
+	// RecordOpen 记录跟踪像素被加载
+	RecordOpen(ctx context.Context, token string) error
+
+	// RecordClick 记录跟踪链接被点击，返回原始链接
+	RecordClick(ctx context.Context, token string, linkID uint) (string, error)
+
+	// ListTrackingOptOuts 列出不跟踪名单
+	ListTrackingOptOuts(ctx context.Context, userID uint) ([]models.TrackingOptOut, error)
+
+	// AddTrackingOptOut 添加不跟踪的地址或域名
+	AddTrackingOptOut(ctx context.Context, userID uint, req *TrackingOptOutRequest) (*models.TrackingOptOut, error)
+
+	// DeleteTrackingOptOut 从不跟踪名单中移除
+	DeleteTrackingOptOut(ctx context.Context, userID, optOutID uint) error
+
+	// Start 启动服务并恢复运行中的活动
+	Start(ctx context.Context) error
+
+	// Stop 停止所有发送任务
+	Stop()
+}
+
+// CreateMailMergeCampaignRequest 创建邮件合并活动请求
+type CreateMailMergeCampaignRequest struct {
+	Name          string `form:"name" binding:"required"`
+	AccountID     uint   `form:"account_id" binding:"required"`
+	TemplateID    uint   `form:"template_id" binding:"required"`
+	RatePerMinute int    `form:"rate_per_minute"`
+	UseVERP       bool   `form:"use_verp"`     // 使用VERP信封发件人，退信可以对应到收件人
+	TrackOpens    bool   `form:"track_opens"`  // 插入打开跟踪像素，需要实例配置了跟踪地址
+	TrackClicks   bool   `form:"track_clicks"` // 改写HTML正文中的链接以统计点击
+}
+
+// ListMailMergeRecipientsRequest 列出收件人请求
+type ListMailMergeRecipientsRequest struct {
+	Status   string `form:"status"`
+	Page     int    `form:"page"`
+	PageSize int    `form:"page_size"`
+}
+
+// ListMailMergeRecipientsResponse 列出收件人响应
+type ListMailMergeRecipientsResponse struct {
+	Recipients []*models.MailMergeRecipient `json:"recipients"`
+	Total      int64                        `json:"total"`
+	Page       int                          `json:"page"`
+	PageSize   int                          `json:"page_size"`
+	TotalPages int                          `json:"total_pages"`
+}
+
+// mailMergeWorker 单个活动的发送任务
+type mailMergeWorker struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// MailMergeServiceImpl 邮件合并服务实现
+type MailMergeServiceImpl struct {
+	db            *gorm.DB
+	emailComposer EmailComposer
+	emailSender   EmailSender
+
+	// trackingBaseURL 跟踪像素和跳转链接的外部访问地址，为空时不能开启跟踪
+	trackingBaseURL string
+
+	baseCtx context.Context
+	workers map[uint]*mailMergeWorker
+	wg      sync.WaitGroup
+	mutex   sync.Mutex
+
+	pollInterval  time.Duration
+	resultTimeout time.Duration
+}
+
+// NewMailMergeService 创建邮件合并服务
+func NewMailMergeService(db *gorm.DB, emailComposer EmailComposer, emailSender EmailSender, trackingCfg config.TrackingConfig) MailMergeService {
+	return &MailMergeServiceImpl{
+		db:              db,
+		emailComposer:   emailComposer,
+		emailSender:     emailSender,
+		trackingBaseURL: strings.TrimRight(trackingCfg.BaseURL, "/"),
+		baseCtx:         context.Background(),
+		workers:         make(map[uint]*mailMergeWorker),
+		pollInterval:    mailMergeSendPollInterval,
+		resultTimeout:   mailMergeSendResultTimeout,
+	}
+}
+
+// CreateCampaign 根据CSV创建邮件合并活动
+func (s *MailMergeServiceImpl) CreateCampaign(ctx context.Context, userID uint, req *CreateMailMergeCampaignRequest, recipients io.Reader) (*models.MailMergeCampaign, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, fmt.Errorf("campaign name is required")
+	}
+
+	rate := req.RatePerMinute
+	if rate <= 0 {
+		rate = mailMergeDefaultRatePerMin
+	}
+	if rate > mailMergeMaxRatePerMin {
+		return nil, fmt.Errorf("rate_per_minute must not exceed %d", mailMergeMaxRatePerMin)
+	}
+	if (req.TrackOpens || req.TrackClicks) && s.trackingBaseURL == "" {
+		return nil, ErrMailMergeTrackingDisabled
+	}
+
+	var account models.EmailAccount
+	if err := s.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", req.AccountID, userID).
+		First(&account).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("account not found or access denied")
+		}
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+
+	var template models.EmailTemplate
+	if err := s.db.WithContext(ctx).First(&template, req.TemplateID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("template not found")
+		}
+		return nil, fmt.Errorf("failed to get template: %w", err)
+	}
+	if !template.IsOwnedBy(userID) {
+		return nil, fmt.Errorf("permission denied: cannot access this template")
+	}
+
+	rows, err := parseMailMergeCSV(recipients)
+	if err != nil {
+		return nil, err
+	}
+
+	campaign := &models.MailMergeCampaign{
+		UserID:          userID,
+		AccountID:       account.ID,
+		TemplateID:      template.ID,
+		Name:            strings.TrimSpace(req.Name),
+		Status:          models.MailMergeStatusDraft,
+		RatePerMinute:   rate,
+		UseVERP:         req.UseVERP,
+		TrackOpens:      req.TrackOpens,
+		TrackClicks:     req.TrackClicks,
+		TotalRecipients: len(rows),
+	}
+
+	// 预先渲染每个收件人，变量不合法的行标记为跳过
+	seen := make(map[string]struct{}, len(rows))
+	for _, row := range rows {
+		if row.Status == models.MailMergeRecipientSkipped {
+			campaign.SkippedCount++
+			continue
+		}
+
+		variables, err := row.GetVariables()
+		if err == nil {
+			_, err = renderTemplate(&template, variables)
+		}
+		if err != nil {
+			row.Status = models.MailMergeRecipientSkipped
+			row.Error = err.Error()
+		} else if _, duplicate := seen[strings.ToLower(row.Email)]; duplicate {
+			row.Status = models.MailMergeRecipientSkipped
+			row.Error = "duplicate recipient"
+		}
+		seen[strings.ToLower(row.Email)] = struct{}{}
+		if row.Status == models.MailMergeRecipientSkipped {
+			campaign.SkippedCount++
+		}
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(campaign).Error; err != nil {
+			return fmt.Errorf("failed to create campaign: %w", err)
+		}
+
+		for _, row := range rows {
+			row.CampaignID = campaign.ID
+		}
+		if err := tx.CreateInBatches(rows, 500).Error; err != nil {
+			return fmt.Errorf("failed to create campaign recipients: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return campaign, nil
+}
+
+// parseMailMergeCSV 解析收件人CSV：首行为表头，必须包含email列，其余列作为模板变量
+func parseMailMergeCSV(r io.Reader) ([]*models.MailMergeRecipient, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("recipients CSV is empty")
+		}
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	emailIndex, nameIndex := -1, -1
+	for i, column := range header {
+		column = strings.TrimSpace(strings.TrimPrefix(column, "\ufeff"))
+		header[i] = column
+		switch strings.ToLower(column) {
+		case mailMergeEmailColumn:
+			emailIndex = i
+		case mailMergeNameColumn:
+			nameIndex = i
+		}
+	}
+	if emailIndex < 0 {
+		return nil, fmt.Errorf("recipients CSV must contain an %q column", mailMergeEmailColumn)
+	}
+
+	var recipients []*models.MailMergeRecipient
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read recipients CSV: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		if len(recipients) >= mailMergeMaxRecipients {
+			return nil, fmt.Errorf("too many recipients (max %d)", mailMergeMaxRecipients)
+		}
+
+		variables := make(map[string]interface{}, len(header))
+		for i, column := range header {
+			if i < len(record) && column != "" {
+				variables[column] = strings.TrimSpace(record[i])
+			}
+		}
+
+		recipient := &models.MailMergeRecipient{
+			LineNumber: line,
+			Status:     models.MailMergeRecipientPending,
+		}
+		if emailIndex < len(record) {
+			recipient.Email = strings.TrimSpace(record[emailIndex])
+		}
+		if nameIndex >= 0 && nameIndex < len(record) {
+			recipient.Name = strings.TrimSpace(record[nameIndex])
+		}
+		if err := recipient.SetVariables(variables); err != nil {
+			return nil, fmt.Errorf("failed to encode variables on CSV line %d: %w", line, err)
+		}
+
+		if address, err := mail.ParseAddress(recipient.Email); err != nil {
+			recipient.Status = models.MailMergeRecipientSkipped
+			recipient.Error = "invalid email address"
+			if recipient.Email == "" {
+				recipient.Email = "-"
+			}
+		} else {
+			recipient.Email = address.Address
+		}
+
+		recipients = append(recipients, recipient)
+	}
+
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("recipients CSV contains no rows")
+	}
+
+	return recipients, nil
+}
+
+// GetCampaign 获取活动
+func (s *MailMergeServiceImpl) GetCampaign(ctx context.Context, userID, campaignID uint) (*models.MailMergeCampaign, error) {
+	var campaign models.MailMergeCampaign
+	if err := s.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", campaignID, userID).
+		First(&campaign).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrMailMergeCampaignNotFound
+		}
+		return nil, fmt.Errorf("failed to get campaign: %w", err)
+	}
+	return &campaign, nil
+}
+
+// ListCampaigns 列出活动
+func (s *MailMergeServiceImpl) ListCampaigns(ctx context.Context, userID uint) ([]*models.MailMergeCampaign, error) {
+	var campaigns []*models.MailMergeCampaign
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&campaigns).Error; err != nil {
+		return nil, fmt.Errorf("failed to list campaigns: %w", err)
+	}
+	return campaigns, nil
+}
+
+// ListRecipients 列出活动收件人
+func (s *MailMergeServiceImpl) ListRecipients(ctx context.Context, userID, campaignID uint, req *ListMailMergeRecipientsRequest) (*ListMailMergeRecipientsResponse, error) {
+	if _, err := s.GetCampaign(ctx, userID, campaignID); err != nil {
+		return nil, err
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.MailMergeRecipient{}).Where("campaign_id = ?", campaignID)
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count recipients: %w", err)
+	}
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 50
+	}
+
+	var recipients []*models.MailMergeRecipient
+	if err := query.Order("line_number ASC").
+		Limit(pageSize).
+		Offset((page - 1) * pageSize).
+		Find(&recipients).Error; err != nil {
+		return nil, fmt.Errorf("failed to list recipients: %w", err)
+	}
+
+	return &ListMailMergeRecipientsResponse{
+		Recipients: recipients,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
+	}, nil
+}
+
+// StartCampaign 开始发送
+func (s *MailMergeServiceImpl) StartCampaign(ctx context.Context, userID, campaignID uint) (*models.MailMergeCampaign, error) {
+	return s.transition(ctx, userID, campaignID, []string{models.MailMergeStatusDraft}, models.MailMergeStatusRunning)
+}
+
+// PauseCampaign 暂停发送
+func (s *MailMergeServiceImpl) PauseCampaign(ctx context.Context, userID, campaignID uint) (*models.MailMergeCampaign, error) {
+	return s.transition(ctx, userID, campaignID, []string{models.MailMergeStatusRunning}, models.MailMergeStatusPaused)
+}
+
+// ResumeCampaign 恢复发送
+func (s *MailMergeServiceImpl) ResumeCampaign(ctx context.Context, userID, campaignID uint) (*models.MailMergeCampaign, error) {
+	return s.transition(ctx, userID, campaignID, []string{models.MailMergeStatusPaused}, models.MailMergeStatusRunning)
+}
+
+// CancelCampaign 取消活动，未发送的收件人标记为已取消
+func (s *MailMergeServiceImpl) CancelCampaign(ctx context.Context, userID, campaignID uint) (*models.MailMergeCampaign, error) {
+	return s.transition(ctx, userID, campaignID,
+		[]string{models.MailMergeStatusDraft, models.MailMergeStatusRunning, models.MailMergeStatusPaused},
+		models.MailMergeStatusCancelled)
+}
+
+// transition 切换活动状态并启动/停止发送任务
+func (s *MailMergeServiceImpl) transition(ctx context.Context, userID, campaignID uint, from []string, to string) (*models.MailMergeCampaign, error) {
+	campaign, err := s.GetCampaign(ctx, userID, campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := false
+	for _, status := range from {
+		if campaign.Status == status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, fmt.Errorf("cannot change campaign status from %s to %s", campaign.Status, to)
+	}
+
+	updates := map[string]interface{}{"status": to}
+	now := time.Now()
+	if to == models.MailMergeStatusRunning && campaign.StartedAt == nil {
+		updates["started_at"] = now
+	}
+	if to == models.MailMergeStatusCancelled {
+		updates["completed_at"] = now
+	}
+
+	// 条件更新，避免与发送任务并发修改状态
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.MailMergeCampaign{}).
+			Where("id = ? AND status = ?", campaign.ID, campaign.Status).
+			Updates(updates)
+		if result.Error != nil {
+			return fmt.Errorf("failed to update campaign status: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("campaign status changed concurrently, please retry")
+		}
+
+		if to == models.MailMergeStatusCancelled {
+			if err := tx.Model(&models.MailMergeRecipient{}).
+				Where("campaign_id = ? AND status = ?", campaign.ID, models.MailMergeRecipientPending).
+				Update("status", models.MailMergeRecipientCancelled).Error; err != nil {
+				return fmt.Errorf("failed to cancel pending recipients: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if to == models.MailMergeStatusRunning {
+		s.startWorker(campaign.ID)
+	} else {
+		s.stopWorker(campaign.ID)
+	}
+
+	return s.GetCampaign(ctx, userID, campaignID)
+}
+
+// Start 启动服务并恢复运行中的活动
+func (s *MailMergeServiceImpl) Start(ctx context.Context) error {
+	s.mutex.Lock()
+	s.baseCtx = ctx
+	s.mutex.Unlock()
+
+	// 服务中断时正在发送的收件人无法确认结果，标记为失败以免重复发送
+	if err := s.db.WithContext(ctx).Model(&models.MailMergeRecipient{}).
+		Where("status = ?", models.MailMergeRecipientSending).
+		Updates(map[string]interface{}{
+			"status": models.MailMergeRecipientFailed,
+			"error":  mailMergeInterruptedMessage,
+		}).Error; err != nil {
+		return fmt.Errorf("failed to reset interrupted recipients: %w", err)
+	}
+
+	var campaignIDs []uint
+	if err := s.db.WithContext(ctx).Model(&models.MailMergeCampaign{}).
+		Where("status = ?", models.MailMergeStatusRunning).
+		Pluck("id", &campaignIDs).Error; err != nil {
+		return fmt.Errorf("failed to load running campaigns: %w", err)
+	}
+
+	for _, campaignID := range campaignIDs {
+		s.refreshCounters(ctx, campaignID)
+		s.startWorker(campaignID)
+	}
+
+	if len(campaignIDs) > 0 {
+		log.Printf("Resumed %d mail merge campaigns", len(campaignIDs))
+	}
+	return nil
+}
+
+// Stop 停止所有发送任务
+func (s *MailMergeServiceImpl) Stop() {
+	s.mutex.Lock()
+	for _, worker := range s.workers {
+		worker.cancel()
+	}
+	s.mutex.Unlock()
+
+	s.wg.Wait()
+}
+
+// startWorker 启动活动发送任务；若上一个任务仍在退出中，等待其结束后再开始，保证同一活动只有一个发送者
+func (s *MailMergeServiceImpl) startWorker(campaignID uint) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous := s.workers[campaignID]
+	if previous != nil && previous.ctx.Err() == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(s.baseCtx)
+	worker := &mailMergeWorker{ctx: ctx, cancel: cancel, done: make(chan struct{})}
+	s.workers[campaignID] = worker
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+		defer close(worker.done)
+		defer func() {
+			s.mutex.Lock()
+			if s.workers[campaignID] == worker {
+				delete(s.workers, campaignID)
+			}
+			s.mutex.Unlock()
+			cancel()
+		}()
+
+		if previous != nil {
+			select {
+			case <-previous.done:
+			case <-ctx.Done():
+				return
+			}
+		}
+		s.runCampaign(ctx, campaignID)
+	}()
+}
+
+func (s *MailMergeServiceImpl) stopWorker(campaignID uint) {
+	s.mutex.Lock()
+	worker := s.workers[campaignID]
+	s.mutex.Unlock()
+
+	if worker != nil {
+		worker.cancel()
+	}
+}
+
+// runCampaign 按节流速率逐个发送收件人，直到完成、暂停或取消
+func (s *MailMergeServiceImpl) runCampaign(ctx context.Context, campaignID uint) {
+	for {
+		var campaign models.MailMergeCampaign
+		if err := s.db.WithContext(ctx).First(&campaign, campaignID).Error; err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Mail merge campaign %d stopped: %v", campaignID, err)
+			}
+			return
+		}
+		if campaign.Status != models.MailMergeStatusRunning {
+			return
+		}
+
+		var recipient models.MailMergeRecipient
+		err := s.db.WithContext(ctx).
+			Where("campaign_id = ? AND status = ?", campaignID, models.MailMergeRecipientPending).
+			Order("line_number ASC").
+			First(&recipient).Error
+		if err == gorm.ErrRecordNotFound {
+			s.completeCampaign(ctx, campaignID)
+			return
+		}
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Failed to load next recipient for campaign %d: %v", campaignID, err)
+			}
+			return
+		}
+
+		// 节流：按每分钟速率等待，被服务器限流时等待至建议的重试时间
+		interval := time.Minute / time.Duration(campaign.RatePerMinute)
+		if backoff := s.sendToRecipient(ctx, &campaign, &recipient); backoff > interval {
+			interval = backoff
+		}
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// sendToRecipient 渲染并发送单个收件人的邮件，记录结果；被限流时返回需要等待的时间
+func (s *MailMergeServiceImpl) sendToRecipient(ctx context.Context, campaign *models.MailMergeCampaign, recipient *models.MailMergeRecipient) time.Duration {
+	result := s.db.WithContext(ctx).Model(recipient).
+		Where("status = ?", models.MailMergeRecipientPending).
+		Update("status", models.MailMergeRecipientSending)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return 0
+	}
+
+	sendID, err := s.deliver(ctx, campaign, recipient)
+	now := time.Now()
+
+	// 被限流的收件人放回待发送队列，不计入失败
+	var rateLimited *SendRateLimitedError
+	if errors.As(err, &rateLimited) {
+		db := s.db.WithContext(context.Background())
+		if err := db.Model(recipient).Updates(map[string]interface{}{
+			"status": models.MailMergeRecipientPending,
+			"error":  rateLimited.Error(),
+		}).Error; err != nil {
+			log.Printf("Failed to requeue mail merge recipient %d: %v", recipient.ID, err)
+		}
+		if err := db.Model(&models.MailMergeCampaign{}).Where("id = ?", campaign.ID).
+			Update("last_error", rateLimited.Error()).Error; err != nil {
+			log.Printf("Failed to update mail merge campaign %d: %v", campaign.ID, err)
+		}
+		return rateLimited.RetryAt.Sub(now)
+	}
+
+	updates := map[string]interface{}{"send_id": sendID}
+	counter := "sent_count"
+	if err != nil {
+		updates["status"] = models.MailMergeRecipientFailed
+		updates["error"] = err.Error()
+		counter = "failed_count"
+	} else {
+		updates["status"] = models.MailMergeRecipientSent
+		updates["sent_at"] = now
+	}
+
+	// 即使发送期间被暂停也要记录结果
+	db := s.db.WithContext(context.Background())
+	if err := db.Model(recipient).Updates(updates).Error; err != nil {
+		log.Printf("Failed to update mail merge recipient %d: %v", recipient.ID, err)
+	}
+	campaignUpdates := map[string]interface{}{counter: gorm.Expr(counter + " + 1")}
+	if err != nil {
+		campaignUpdates["last_error"] = err.Error()
+	}
+	if err := db.Model(&models.MailMergeCampaign{}).Where("id = ?", campaign.ID).
+		Updates(campaignUpdates).Error; err != nil {
+		log.Printf("Failed to update mail merge campaign %d counters: %v", campaign.ID, err)
+	}
+	return 0
+}
+
+// deliver 组装并发送邮件，等待发送器给出最终结果
+func (s *MailMergeServiceImpl) deliver(ctx context.Context, campaign *models.MailMergeCampaign, recipient *models.MailMergeRecipient) (string, error) {
+	var account models.EmailAccount
+	if err := s.db.WithContext(ctx).First(&account, campaign.AccountID).Error; err != nil {
+		return "", fmt.Errorf("failed to get account: %w", err)
+	}
+
+	var template models.EmailTemplate
+	if err := s.db.WithContext(ctx).First(&template, campaign.TemplateID).Error; err != nil {
+		return "", fmt.Errorf("failed to get template: %w", err)
+	}
+
+	variables, err := recipient.GetVariables()
+	if err != nil {
+		return "", fmt.Errorf("failed to decode recipient variables: %w", err)
+	}
+
+	rendered, err := renderTemplate(&template, variables)
+	if err != nil {
+		return "", err
+	}
+	if err := s.applyTracking(ctx, campaign, recipient, rendered); err != nil {
+		return "", err
+	}
+
+	composed, err := s.emailComposer.ComposeEmail(ctx, &ComposeEmailRequest{
+		From:     &models.EmailAddress{Name: account.Name, Address: account.Email},
+		To:       []*models.EmailAddress{{Name: recipient.Name, Address: recipient.Email}},
+		Subject:  rendered.Subject,
+		TextBody: rendered.TextBody,
+		HTMLBody: rendered.HTMLBody,
+		UserID:   campaign.UserID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to compose email: %w", err)
+	}
+	if campaign.UseVERP {
+		bounceAddress := account.BounceAddress
+		if bounceAddress == "" {
+			bounceAddress = account.Email
+		}
+		composed.EnvelopeFrom = VERPAddress(bounceAddress, recipient.Email)
+	}
+
+	sendResult, err := s.emailSender.SendEmail(ctx, composed, campaign.AccountID)
+	if err != nil {
+		return "", err
+	}
+
+	return sendResult.SendID, s.waitForSendResult(ctx, sendResult)
+}
+
+// waitForSendResult 等待发送器给出最终结果
+func (s *MailMergeServiceImpl) waitForSendResult(ctx context.Context, result *SendResult) error {
+	// 已提交给发送器的邮件会继续发送，等待结果不受暂停影响
+	return awaitSendResult(context.WithoutCancel(ctx), s.emailSender, result.SendID, s.resultTimeout, s.pollInterval)
+}
+
+// completeCampaign 所有收件人处理完毕后标记活动完成
+func (s *MailMergeServiceImpl) completeCampaign(ctx context.Context, campaignID uint) {
+	now := time.Now()
+	if err := s.db.WithContext(ctx).Model(&models.MailMergeCampaign{}).
+		Where("id = ? AND status = ?", campaignID, models.MailMergeStatusRunning).
+		Updates(map[string]interface{}{
+			"status":       models.MailMergeStatusCompleted,
+			"completed_at": now,
+		}).Error; err != nil {
+		log.Printf("Failed to complete mail merge campaign %d: %v", campaignID, err)
+	}
+}
+
+// refreshCounters 根据收件人状态重新计算活动统计
+func (s *MailMergeServiceImpl) refreshCounters(ctx context.Context, campaignID uint) {
+	type statusCount struct {
+		Status string
+		Count  int
+	}
+	var counts []statusCount
+	if err := s.db.WithContext(ctx).Model(&models.MailMergeRecipient{}).
+		Select("status, COUNT(*) AS count").
+		Where("campaign_id = ?", campaignID).
+		Group("status").
+		Scan(&counts).Error; err != nil {
+		log.Printf("Failed to refresh mail merge campaign %d counters: %v", campaignID, err)
+		return
+	}
+
+	updates := map[string]interface{}{"sent_count": 0, "failed_count": 0, "skipped_count": 0}
+	for _, count := range counts {
+		switch count.Status {
+		case models.MailMergeRecipientSent:
+			updates["sent_count"] = count.Count
+		case models.MailMergeRecipientFailed:
+			updates["failed_count"] = count.Count
+		case models.MailMergeRecipientSkipped:
+			updates["skipped_count"] = count.Count
+		}
+	}
+
+	if err := s.db.WithContext(ctx).Model(&models.MailMergeCampaign{}).
+		Where("id = ?", campaignID).
+		Updates(updates).Error; err != nil {
+		log.Printf("Failed to refresh mail merge campaign %d counters: %v", campaignID, err)
+	}
+}
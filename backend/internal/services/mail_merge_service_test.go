@@ -0,0 +1,319 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"firemail/internal/config"
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeMailMergeSender struct {
+	mutex    sync.Mutex
+	sent     []*ComposedEmail
+	statuses map[string]*SendStatus
+	failFor  string
+	// 首次发送给该地址时返回限流状态
+	rateLimitFor string
+}
+
+func (s *fakeMailMergeSender) SendEmail(ctx context.Context, email *ComposedEmail, accountID uint) (*SendResult, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	sendID := fmt.Sprintf("send-%d", len(s.sent)+1)
+	status := &SendStatus{SendID: sendID, Status: "sent"}
+	if email.To[0].Address == s.failFor {
+		status.Status = "failed"
+		status.Error = "mailbox unavailable"
+	}
+	if email.To[0].Address == s.rateLimitFor {
+		retryAt := time.Now().Add(50 * time.Millisecond)
+		status.Status = "rate_limited"
+		status.Error = "rate limit exceeded"
+		status.RetryAfter = &retryAt
+		s.rateLimitFor = ""
+	}
+	s.sent = append(s.sent, email)
+	s.statuses[sendID] = status
+	return &SendResult{SendID: sendID, Status: "pending"}, nil
+}
+
+func (s *fakeMailMergeSender) SendBulkEmails(ctx context.Context, emails []*ComposedEmail, accountID uint) ([]*SendResult, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (s *fakeMailMergeSender) GetSendStatus(ctx context.Context, sendID string) (*SendStatus, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if status, ok := s.statuses[sendID]; ok {
+		return status, nil
+	}
+	return nil, fmt.Errorf("send status not found")
+}
+
+func (s *fakeMailMergeSender) ResendEmail(ctx context.Context, sendID string) (*SendResult, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (s *fakeMailMergeSender) sentCount() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.sent)
+}
+
+func setupMailMergeTest(t *testing.T) (*emailStateServiceTestEnv, *MailMergeServiceImpl, *fakeMailMergeSender, *models.EmailTemplate) {
+	t.Helper()
+
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.EmailTemplate{}, &models.MailMergeCampaign{}, &models.MailMergeRecipient{},
+		&models.MailMergeLink{}, &models.TrackingOptOut{}))
+
+	tmpl, err := NewEmailTemplateService(env.db).CreateTemplate(context.Background(), env.user.ID, &CreateEmailTemplateRequest{
+		Name:      "邀请函",
+		Subject:   "{{.name}}，您的邀请码 {{.code}}",
+		TextBody:  "邀请码：{{.code}}",
+		Variables: []models.TemplateVariable{{Name: "code", Required: true}},
+	})
+	require.NoError(t, err)
+
+	sender := &fakeMailMergeSender{statuses: make(map[string]*SendStatus)}
+	composer := NewStandardEmailComposer(&EmailComposerConfig{MaxRecipientsPerEmail: 10}, env.db)
+	service := NewMailMergeService(env.db, composer, sender, config.TrackingConfig{}).(*MailMergeServiceImpl)
+	service.pollInterval = 10 * time.Millisecond
+	t.Cleanup(service.Stop)
+
+	return env, service, sender, tmpl
+}
+
+func TestMailMergeCampaignSendsPerRecipient(t *testing.T) {
+	env, service, sender, tmpl := setupMailMergeTest(t)
+	ctx := context.Background()
+	sender.failFor = "carol@example.com"
+
+	csvData := strings.Join([]string{
+		"email,name,code",
+		"alice@example.com,Alice,A-1",
+		"bob@example.com,Bob,",
+		"not-an-email,Nobody,X-1",
+		"ALICE@example.com,Alice Again,A-2",
+		"carol@example.com,Carol,C-1",
+	}, "\n")
+
+	campaign, err := service.CreateCampaign(ctx, env.user.ID, &CreateMailMergeCampaignRequest{
+		Name:          "发布会邀请",
+		AccountID:     env.account.ID,
+		TemplateID:    tmpl.ID,
+		RatePerMinute: mailMergeMaxRatePerMin,
+	}, strings.NewReader(csvData))
+	require.NoError(t, err)
+	require.Equal(t, models.MailMergeStatusDraft, campaign.Status)
+	require.Equal(t, 5, campaign.TotalRecipients)
+	require.Equal(t, 3, campaign.SkippedCount)
+
+	_, err = service.PauseCampaign(ctx, env.user.ID, campaign.ID)
+	require.Error(t, err)
+
+	_, err = service.StartCampaign(ctx, env.user.ID, campaign.ID)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		current, err := service.GetCampaign(ctx, env.user.ID, campaign.ID)
+		return err == nil && current.Status == models.MailMergeStatusCompleted
+	}, 5*time.Second, 20*time.Millisecond)
+
+	current, err := service.GetCampaign(ctx, env.user.ID, campaign.ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, current.SentCount)
+	require.Equal(t, 1, current.FailedCount)
+	require.NotNil(t, current.CompletedAt)
+
+	require.Len(t, sender.sent, 2)
+	require.Equal(t, "Alice，您的邀请码 A-1", sender.sent[0].Subject)
+	require.Equal(t, "alice@example.com", sender.sent[0].To[0].Address)
+
+	resp, err := service.ListRecipients(ctx, env.user.ID, campaign.ID, &ListMailMergeRecipientsRequest{})
+	require.NoError(t, err)
+	statuses := make(map[int]string)
+	for _, recipient := range resp.Recipients {
+		statuses[recipient.LineNumber] = recipient.Status
+	}
+	require.Equal(t, map[int]string{
+		2: models.MailMergeRecipientSent,
+		3: models.MailMergeRecipientSkipped,
+		4: models.MailMergeRecipientSkipped,
+		5: models.MailMergeRecipientSkipped,
+		6: models.MailMergeRecipientFailed,
+	}, statuses)
+}
+
+func TestMailMergeCampaignPauseResumeCancel(t *testing.T) {
+	env, service, sender, tmpl := setupMailMergeTest(t)
+	ctx := context.Background()
+
+	campaign, err := service.CreateCampaign(ctx, env.user.ID, &CreateMailMergeCampaignRequest{
+		Name:          "慢速活动",
+		AccountID:     env.account.ID,
+		TemplateID:    tmpl.ID,
+		RatePerMinute: 1,
+	}, strings.NewReader("email,name,code\na@example.com,A,1\nb@example.com,B,2\nc@example.com,C,3\n"))
+	require.NoError(t, err)
+
+	_, err = service.StartCampaign(ctx, env.user.ID, campaign.ID)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return sender.sentCount() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	// 暂停后节流等待被打断，不会继续发送
+	paused, err := service.PauseCampaign(ctx, env.user.ID, campaign.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.MailMergeStatusPaused, paused.Status)
+
+	_, err = service.ResumeCampaign(ctx, env.user.ID, campaign.ID)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return sender.sentCount() == 2 }, 5*time.Second, 10*time.Millisecond)
+
+	cancelled, err := service.CancelCampaign(ctx, env.user.ID, campaign.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.MailMergeStatusCancelled, cancelled.Status)
+
+	resp, err := service.ListRecipients(ctx, env.user.ID, campaign.ID, &ListMailMergeRecipientsRequest{Status: models.MailMergeRecipientCancelled})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), resp.Total)
+	require.Equal(t, "c@example.com", resp.Recipients[0].Email)
+
+	_, err = service.ResumeCampaign(ctx, env.user.ID, campaign.ID)
+	require.Error(t, err)
+}
+
+func TestMailMergeCampaignRequeuesRateLimitedRecipient(t *testing.T) {
+	env, service, sender, tmpl := setupMailMergeTest(t)
+	ctx := context.Background()
+	sender.rateLimitFor = "a@example.com"
+
+	campaign, err := service.CreateCampaign(ctx, env.user.ID, &CreateMailMergeCampaignRequest{
+		Name:          "限流活动",
+		AccountID:     env.account.ID,
+		TemplateID:    tmpl.ID,
+		RatePerMinute: mailMergeMaxRatePerMin,
+	}, strings.NewReader("email,name,code\na@example.com,A,1\nb@example.com,B,2\n"))
+	require.NoError(t, err)
+
+	_, err = service.StartCampaign(ctx, env.user.ID, campaign.ID)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		current, err := service.GetCampaign(ctx, env.user.ID, campaign.ID)
+		return err == nil && current.Status == models.MailMergeStatusCompleted
+	}, 5*time.Second, 20*time.Millisecond)
+
+	// 被限流的收件人在退避后重新发送，不计入失败
+	current, err := service.GetCampaign(ctx, env.user.ID, campaign.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2, current.SentCount)
+	require.Equal(t, 0, current.FailedCount)
+	require.Equal(t, 3, sender.sentCount())
+	require.Equal(t, "a@example.com", sender.sent[1].To[0].Address)
+}
+
+func TestParseMailMergeCSVRequiresEmailColumn(t *testing.T) {
+	_, err := parseMailMergeCSV(strings.NewReader("name,code\nAlice,1\n"))
+	require.ErrorContains(t, err, `"email" column`)
+
+	_, err = parseMailMergeCSV(strings.NewReader("email\n"))
+	require.ErrorContains(t, err, "no rows")
+}
+
+func TestMailMergeTrackingRequiresBaseURL(t *testing.T) {
+	env, service, _, tmpl := setupMailMergeTest(t)
+
+	_, err := service.CreateCampaign(context.Background(), env.user.ID, &CreateMailMergeCampaignRequest{
+		Name:       "跟踪",
+		AccountID:  env.account.ID,
+		TemplateID: tmpl.ID,
+		TrackOpens: true,
+	}, strings.NewReader("email,code\na@example.com,A-1\n"))
+	require.ErrorIs(t, err, ErrMailMergeTrackingDisabled)
+}
+
+func TestMailMergeTrackingRewritesLinksAndRecordsEvents(t *testing.T) {
+	env, service, sender, _ := setupMailMergeTest(t)
+	ctx := context.Background()
+	service.trackingBaseURL = "https://mail.example.com"
+
+	tmpl, err := NewEmailTemplateService(env.db).CreateTemplate(ctx, env.user.ID, &CreateEmailTemplateRequest{
+		Name:     "新闻稿",
+		Subject:  "本周动态",
+		HTMLBody: `<html><body><p>你好 {{.name}}</p><a href="https://example.org/a?x=1&amp;y=2">阅读</a> <a href='mailto:hi@example.org'>联系</a></body></html>`,
+	})
+	require.NoError(t, err)
+
+	optOut, err := service.AddTrackingOptOut(ctx, env.user.ID, &TrackingOptOutRequest{Pattern: "*@private.example"})
+	require.NoError(t, err)
+	require.Equal(t, "@private.example", optOut.Pattern)
+	_, err = service.AddTrackingOptOut(ctx, env.user.ID, &TrackingOptOutRequest{Pattern: "not a pattern"})
+	require.ErrorIs(t, err, ErrInvalidTrackingOptOut)
+
+	campaign, err := service.CreateCampaign(ctx, env.user.ID, &CreateMailMergeCampaignRequest{
+		Name:          "新闻稿",
+		AccountID:     env.account.ID,
+		TemplateID:    tmpl.ID,
+		RatePerMinute: mailMergeMaxRatePerMin,
+		TrackOpens:    true,
+		TrackClicks:   true,
+	}, strings.NewReader("email,name\nalice@example.com,Alice\nbob@mx.private.example,Bob\n"))
+	require.NoError(t, err)
+
+	_, err = service.StartCampaign(ctx, env.user.ID, campaign.ID)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		current, err := service.GetCampaign(ctx, env.user.ID, campaign.ID)
+		return err == nil && current.Status == models.MailMergeStatusCompleted
+	}, 5*time.Second, 20*time.Millisecond)
+
+	require.Len(t, sender.sent, 2)
+	tracked, excluded := sender.sent[0].HTMLBody, sender.sent[1].HTMLBody
+	require.NotContains(t, tracked, "https://example.org/a")
+	require.Contains(t, tracked, "mailto:hi@example.org")
+	require.Contains(t, tracked, "https://mail.example.com"+TrackingOpenPathPrefix)
+	require.NotContains(t, excluded, "https://mail.example.com")
+	require.Contains(t, excluded, "https://example.org/a")
+
+	var alice models.MailMergeRecipient
+	require.NoError(t, env.db.Where("campaign_id = ? AND email = ?", campaign.ID, "alice@example.com").First(&alice).Error)
+	require.NotEmpty(t, alice.TrackingToken)
+	var link models.MailMergeLink
+	require.NoError(t, env.db.Where("campaign_id = ?", campaign.ID).First(&link).Error)
+	require.Equal(t, "https://example.org/a?x=1&y=2", link.URL)
+	require.Contains(t, tracked, fmt.Sprintf("%s%s/%d", TrackingClickPathPrefix, alice.TrackingToken, link.ID))
+
+	// 点击同时计为打开，重复事件不重复计入去重人数
+	target, err := service.RecordClick(ctx, alice.TrackingToken, link.ID)
+	require.NoError(t, err)
+	require.Equal(t, link.URL, target)
+	require.NoError(t, service.RecordOpen(ctx, alice.TrackingToken))
+	_, err = service.RecordClick(ctx, alice.TrackingToken, link.ID+100)
+	require.ErrorIs(t, err, ErrTrackingLinkNotFound)
+	require.ErrorIs(t, service.RecordOpen(ctx, "unknown"), ErrTrackingLinkNotFound)
+
+	report, err := service.GetCampaignReport(ctx, env.user.ID, campaign.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), report.TrackedCount)
+	require.Equal(t, int64(1), report.ExcludedCount)
+	require.Equal(t, 1, report.OpenedCount)
+	require.Equal(t, 1, report.ClickedCount)
+	require.Equal(t, 1.0, report.OpenRate)
+	require.Len(t, report.TopLinks, 1)
+	require.Equal(t, 1, report.TopLinks[0].ClickCount)
+
+	require.NoError(t, env.db.First(&alice, alice.ID).Error)
+	require.Equal(t, 1, alice.OpenCount)
+	require.Equal(t, 1, alice.ClickCount)
+	require.NotNil(t, alice.FirstOpenedAt)
+}
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"time"
+
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// TrackingOpenPathPrefix 打开跟踪像素的路径前缀，后接收件人跟踪令牌
+	TrackingOpenPathPrefix = "/api/v1/t/o/"
+	// TrackingClickPathPrefix 跟踪链接的路径前缀，后接 令牌/链接ID
+	TrackingClickPathPrefix = "/api/v1/t/c/"
+
+	// mailMergeTopLinks 活动报告中列出的链接数
+	mailMergeTopLinks = 20
+)
+
+var (
+	// ErrMailMergeTrackingDisabled 实例未配置跟踪地址，不能开启打开和点击跟踪
+	ErrMailMergeTrackingDisabled = errors.New("tracking is not available: TRACKING_BASE_URL is not configured")
+	// ErrTrackingLinkNotFound 跟踪令牌或链接无效
+	ErrTrackingLinkNotFound = errors.New("tracking link not found")
+	// ErrTrackingOptOutNotFound 不跟踪名单条目不存在或无权访问
+	ErrTrackingOptOutNotFound = errors.New("tracking opt-out not found")
+	// ErrInvalidTrackingOptOut 不跟踪名单条目格式不正确
+	ErrInvalidTrackingOptOut = errors.New("invalid tracking opt-out")
+)
+
+// trackedHrefPattern HTML正文中指向 http(s) 地址的链接
+var trackedHrefPattern = regexp.MustCompile(`(?i)(<a\b[^>]*?\bhref\s*=\s*)("https?://[^"]+"|'https?://[^']+')`)
+
+// TrackingOptOutRequest 添加不跟踪名单条目请求
+type TrackingOptOutRequest struct {
+	Pattern string `json:"pattern" binding:"required"` // 邮箱地址，或 @example.com 形式的域名
+}
+
+// MailMergeCampaignReport 活动的发送、打开和点击统计
+type MailMergeCampaignReport struct {
+	Campaign      *models.MailMergeCampaign `json:"campaign"`
+	TrackedCount  int64                     `json:"tracked_count"`  // 已发送且启用了跟踪的收件人数
+	ExcludedCount int64                     `json:"excluded_count"` // 命中不跟踪名单而未跟踪的收件人数
+	OpenedCount   int                       `json:"opened_count"`
+	ClickedCount  int                       `json:"clicked_count"`
+	OpenRate      float64                   `json:"open_rate"`  // 打开人数 / 跟踪人数
+	ClickRate     float64                   `json:"click_rate"` // 点击人数 / 跟踪人数
+	TopLinks      []models.MailMergeLink    `json:"top_links"`
+}
+
+// trackingEnabled 活动是否开启了任一跟踪
+func trackingEnabled(campaign *models.MailMergeCampaign) bool {
+	return campaign.TrackOpens || campaign.TrackClicks
+}
+
+// newTrackingToken 生成收件人的跟踪令牌
+func newTrackingToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate tracking token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// applyTracking 为收件人的HTML正文插入跟踪像素并改写链接。
+// 命中不跟踪名单的收件人保持原样并标记为排除；纯文本正文不做改写
+func (s *MailMergeServiceImpl) applyTracking(ctx context.Context, campaign *models.MailMergeCampaign, recipient *models.MailMergeRecipient, rendered *ProcessedTemplate) error {
+	if !trackingEnabled(campaign) || s.trackingBaseURL == "" || rendered.HTMLBody == "" {
+		return nil
+	}
+
+	db := s.db.WithContext(ctx)
+	if s.trackingOptedOut(db, campaign.UserID, recipient.Email) {
+		recipient.TrackingExcluded = true
+		return db.Model(recipient).Update("tracking_excluded", true).Error
+	}
+
+	token := recipient.TrackingToken
+	if token == "" {
+		var err error
+		if token, err = newTrackingToken(); err != nil {
+			return err
+		}
+		if err := db.Model(recipient).Update("tracking_token", token).Error; err != nil {
+			return fmt.Errorf("failed to save tracking token: %w", err)
+		}
+		recipient.TrackingToken = token
+	}
+
+	body := rendered.HTMLBody
+	if campaign.TrackClicks {
+		var rewriteErr error
+		body = trackedHrefPattern.ReplaceAllStringFunc(body, func(match string) string {
+			parts := trackedHrefPattern.FindStringSubmatch(match)
+			quoted := parts[2]
+			target := html.UnescapeString(quoted[1 : len(quoted)-1])
+
+			link, err := s.campaignLink(db, campaign.ID, target)
+			if err != nil {
+				rewriteErr = err
+				return match
+			}
+			tracked := fmt.Sprintf("%s%s%s/%d", s.trackingBaseURL, TrackingClickPathPrefix, token, link.ID)
+			return parts[1] + `"` + tracked + `"`
+		})
+		if rewriteErr != nil {
+			return rewriteErr
+		}
+	}
+	if campaign.TrackOpens {
+		pixel := fmt.Sprintf(`<img src="%s%s%s" width="1" height="1" alt="" style="display:none">`, s.trackingBaseURL, TrackingOpenPathPrefix, token)
+		if idx := strings.LastIndex(strings.ToLower(body), "</body>"); idx >= 0 {
+			body = body[:idx] + pixel + body[idx:]
+		} else {
+			body += pixel
+		}
+	}
+	rendered.HTMLBody = body
+	return nil
+}
+
+// campaignLink 获取活动中某个链接的记录，同一链接在所有收件人间共用
+func (s *MailMergeServiceImpl) campaignLink(db *gorm.DB, campaignID uint, target string) (*models.MailMergeLink, error) {
+	link := &models.MailMergeLink{}
+	if err := db.Where(models.MailMergeLink{CampaignID: campaignID, URL: target}).FirstOrCreate(link).Error; err != nil {
+		return nil, fmt.Errorf("failed to save tracked link: %w", err)
+	}
+	return link, nil
+}
+
+// trackingOptedOut 收件人地址或其所在域名是否在不跟踪名单中
+func (s *MailMergeServiceImpl) trackingOptedOut(db *gorm.DB, userID uint, address string) bool {
+	candidates := blockedSenderCandidates(address)
+	if len(candidates) == 0 {
+		return false
+	}
+	var count int64
+	if err := db.Model(&models.TrackingOptOut{}).
+		Where("user_id = ? AND pattern IN ?", userID, candidates).
+		Count(&count).Error; err != nil {
+		// 无法确认时按不跟踪处理
+		return true
+	}
+	return count > 0
+}
+
+// recipientByToken 按跟踪令牌查找收件人
+func (s *MailMergeServiceImpl) recipientByToken(db *gorm.DB, token string) (*models.MailMergeRecipient, error) {
+	if token == "" {
+		return nil, ErrTrackingLinkNotFound
+	}
+	var recipient models.MailMergeRecipient
+	if err := db.Where("tracking_token = ?", token).First(&recipient).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTrackingLinkNotFound
+		}
+		return nil, fmt.Errorf("failed to get recipient: %w", err)
+	}
+	return &recipient, nil
+}
+
+// RecordOpen 记录一次打开，收件人首次打开时计入活动的打开人数
+func (s *MailMergeServiceImpl) RecordOpen(ctx context.Context, token string) error {
+	db := s.db.WithContext(ctx)
+	recipient, err := s.recipientByToken(db, token)
+	if err != nil {
+		return err
+	}
+	if err := db.Model(recipient).UpdateColumn("open_count", gorm.Expr("open_count + 1")).Error; err != nil {
+		return fmt.Errorf("failed to record open: %w", err)
+	}
+	return s.markFirstEvent(db, recipient, "first_opened_at", "opened_count")
+}
+
+// RecordClick 记录一次点击并返回原始链接；点击也视为打开，图片被拦截时打开数不至于偏低
+func (s *MailMergeServiceImpl) RecordClick(ctx context.Context, token string, linkID uint) (string, error) {
+	db := s.db.WithContext(ctx)
+	recipient, err := s.recipientByToken(db, token)
+	if err != nil {
+		return "", err
+	}
+
+	var link models.MailMergeLink
+	if err := db.Where("id = ? AND campaign_id = ?", linkID, recipient.CampaignID).First(&link).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrTrackingLinkNotFound
+		}
+		return "", fmt.Errorf("failed to get tracked link: %w", err)
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&link).UpdateColumn("click_count", gorm.Expr("click_count + 1")).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(recipient).UpdateColumn("click_count", gorm.Expr("click_count + 1")).Error; err != nil {
+			return err
+		}
+		if err := s.markFirstEvent(tx, recipient, "first_clicked_at", "clicked_count"); err != nil {
+			return err
+		}
+		return s.markFirstEvent(tx, recipient, "first_opened_at", "opened_count")
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to record click: %w", err)
+	}
+	return link.URL, nil
+}
+
+// markFirstEvent 首次发生时记录时间并增加活动的去重计数
+func (s *MailMergeServiceImpl) markFirstEvent(db *gorm.DB, recipient *models.MailMergeRecipient, column, counter string) error {
+	result := db.Model(&models.MailMergeRecipient{}).
+		Where("id = ? AND "+column+" IS NULL", recipient.ID).
+		UpdateColumn(column, time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+	return db.Model(&models.MailMergeCampaign{}).Where("id = ?", recipient.CampaignID).
+		UpdateColumn(counter, gorm.Expr(counter+" + 1")).Error
+}
+
+// GetCampaignReport 汇总活动的打开和点击统计
+func (s *MailMergeServiceImpl) GetCampaignReport(ctx context.Context, userID, campaignID uint) (*MailMergeCampaignReport, error) {
+	campaign, err := s.GetCampaign(ctx, userID, campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	db := s.db.WithContext(ctx)
+	report := &MailMergeCampaignReport{
+		Campaign:     campaign,
+		OpenedCount:  campaign.OpenedCount,
+		ClickedCount: campaign.ClickedCount,
+		TopLinks:     []models.MailMergeLink{},
+	}
+	if err := db.Model(&models.MailMergeRecipient{}).
+		Where("campaign_id = ? AND status = ? AND tracking_token <> ''", campaignID, models.MailMergeRecipientSent).
+		Count(&report.TrackedCount).Error; err != nil {
+		return nil, fmt.Errorf("failed to count tracked recipients: %w", err)
+	}
+	if err := db.Model(&models.MailMergeRecipient{}).
+		Where("campaign_id = ? AND tracking_excluded = ?", campaignID, true).
+		Count(&report.ExcludedCount).Error; err != nil {
+		return nil, fmt.Errorf("failed to count excluded recipients: %w", err)
+	}
+	if report.TrackedCount > 0 {
+		report.OpenRate = float64(report.OpenedCount) / float64(report.TrackedCount)
+		report.ClickRate = float64(report.ClickedCount) / float64(report.TrackedCount)
+	}
+	if err := db.Where("campaign_id = ?", campaignID).
+		Order("click_count DESC, id ASC").
+		Limit(mailMergeTopLinks).
+		Find(&report.TopLinks).Error; err != nil {
+		return nil, fmt.Errorf("failed to get tracked links: %w", err)
+	}
+	return report, nil
+}
+
+// ListTrackingOptOuts 列出不跟踪名单
+func (s *MailMergeServiceImpl) ListTrackingOptOuts(ctx context.Context, userID uint) ([]models.TrackingOptOut, error) {
+	optOuts := []models.TrackingOptOut{}
+	if err := s.db.WithContext(ctx).Where("user_id = ?", userID).Order("pattern ASC").Find(&optOuts).Error; err != nil {
+		return nil, fmt.Errorf("failed to get tracking opt-outs: %w", err)
+	}
+	return optOuts, nil
+}
+
+// AddTrackingOptOut 添加不跟踪的地址或域名，已存在时返回原条目
+func (s *MailMergeServiceImpl) AddTrackingOptOut(ctx context.Context, userID uint, req *TrackingOptOutRequest) (*models.TrackingOptOut, error) {
+	pattern, err := normalizeBlockedSenderPattern(req.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTrackingOptOut, strings.TrimSpace(req.Pattern))
+	}
+
+	optOut := &models.TrackingOptOut{}
+	if err := s.db.WithContext(ctx).
+		Where(models.TrackingOptOut{UserID: userID, Pattern: pattern}).
+		FirstOrCreate(optOut).Error; err != nil {
+		return nil, fmt.Errorf("failed to add tracking opt-out: %w", err)
+	}
+	return optOut, nil
+}
+
+// DeleteTrackingOptOut 从不跟踪名单中移除
+func (s *MailMergeServiceImpl) DeleteTrackingOptOut(ctx context.Context, userID, optOutID uint) error {
+	result := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", optOutID, userID).Delete(&models.TrackingOptOut{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete tracking opt-out: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrTrackingOptOutNotFound
+	}
+	return nil
+}
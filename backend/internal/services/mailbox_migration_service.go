@@ -0,0 +1,859 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"firemail/internal/models"
+	"firemail/internal/providers"
+	"firemail/internal/sse"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// 每批获取邮件头（标记和日期）的邮件数量
+	mailboxMigrationBatchSize = 50
+	// 连续失败达到该次数时认为连接已不可用，暂停迁移等待恢复
+	mailboxMigrationMaxConsecutiveFailures = 5
+)
+
+var (
+	// ErrMailboxMigrationNotFound 迁移任务不存在或无权访问
+	ErrMailboxMigrationNotFound = errors.New("migration not found or access denied")
+	// ErrInvalidMailboxMigration 迁移参数无效
+	ErrInvalidMailboxMigration = errors.New("invalid migration")
+)
+
+// MailboxMigrationService 邮箱迁移服务接口
+type MailboxMigrationService interface {
+	// PlanMigration 生成默认的文件夹映射，供创建迁移前确认
+	PlanMigration(ctx context.Context, userID, sourceAccountID, targetAccountID uint) ([]MailboxMigrationFolderMapping, error)
+
+	// CreateMigration 创建迁移并立即开始复制
+	CreateMigration(ctx context.Context, userID uint, req *CreateMailboxMigrationRequest) (*models.MailboxMigration, error)
+
+	// GetMigration 获取迁移及各文件夹进度
+	GetMigration(ctx context.Context, userID, migrationID uint) (*models.MailboxMigration, error)
+
+	// ListMigrations 列出迁移
+	ListMigrations(ctx context.Context, userID uint) ([]*models.MailboxMigration, error)
+
+	// PauseMigration 暂停迁移，已复制的邮件保留断点
+	PauseMigration(ctx context.Context, userID, migrationID uint) (*models.MailboxMigration, error)
+
+	// ResumeMigration 从断点继续暂停或失败的迁移
+	ResumeMigration(ctx context.Context, userID, migrationID uint) (*models.MailboxMigration, error)
+
+	// CancelMigration 取消迁移，已复制到目标账户的邮件不会删除
+	CancelMigration(ctx context.Context, userID, migrationID uint) (*models.MailboxMigration, error)
+
+	// GetMigrationReport 获取迁移完成后的逐文件夹核对报告
+	GetMigrationReport(ctx context.Context, userID, migrationID uint) (*MailboxMigrationReport, error)
+
+	// Start 启动服务并恢复运行中的迁移
+	Start(ctx context.Context) error
+
+	// Stop 停止所有迁移任务
+	Stop()
+}
+
+// MailboxMigrationFolderMapping 源文件夹到目标文件夹的映射
+type MailboxMigrationFolderMapping struct {
+	SourcePath string `json:"source_path"`
+	TargetPath string `json:"target_path"`
+}
+
+// CreateMailboxMigrationRequest 创建邮箱迁移请求
+type CreateMailboxMigrationRequest struct {
+	SourceAccountID uint                            `json:"source_account_id" binding:"required"`
+	TargetAccountID uint                            `json:"target_account_id" binding:"required"`
+	Folders         []MailboxMigrationFolderMapping `json:"folders"` // 为空时迁移所有文件夹并使用默认映射
+}
+
+// MailboxMigrationFolderReport 单个文件夹的核对结果
+type MailboxMigrationFolderReport struct {
+	SourcePath   string `json:"source_path"`
+	TargetPath   string `json:"target_path"`
+	SourceCount  *int   `json:"source_count,omitempty"`
+	TargetCount  *int   `json:"target_count,omitempty"`
+	CopiedEmails int    `json:"copied_emails"`
+	FailedEmails int    `json:"failed_emails"`
+	Matched      bool   `json:"matched"` // 没有失败且目标文件夹邮件数不少于源文件夹
+}
+
+// MailboxMigrationReport 迁移核对报告
+type MailboxMigrationReport struct {
+	MigrationID  uint                           `json:"migration_id"`
+	Status       string                         `json:"status"`
+	VerifiedAt   *time.Time                     `json:"verified_at,omitempty"`
+	CopiedEmails int                            `json:"copied_emails"`
+	FailedEmails int                            `json:"failed_emails"`
+	Matched      bool                           `json:"matched"`
+	Folders      []MailboxMigrationFolderReport `json:"folders"`
+}
+
+// mailboxConnector 打开账户的IMAP会话，由邮件服务实现
+type mailboxConnector interface {
+	openEmailSourceSession(ctx context.Context, account *models.EmailAccount) (*emailSourceSession, error)
+}
+
+// mailboxMigrationWorker 单个迁移的复制任务
+type mailboxMigrationWorker struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// MailboxMigrationServiceImpl 邮箱迁移服务实现
+type MailboxMigrationServiceImpl struct {
+	db             *gorm.DB
+	connector      mailboxConnector
+	eventPublisher sse.EventPublisher
+
+	baseCtx context.Context
+	workers map[uint]*mailboxMigrationWorker
+	wg      sync.WaitGroup
+	mutex   sync.Mutex
+}
+
+// NewMailboxMigrationService 创建邮箱迁移服务，复用邮件服务的IMAP连接逻辑
+func NewMailboxMigrationService(db *gorm.DB, emailService EmailService, eventPublisher sse.EventPublisher) MailboxMigrationService {
+	connector, _ := emailService.(mailboxConnector)
+	return &MailboxMigrationServiceImpl{
+		db:             db,
+		connector:      connector,
+		eventPublisher: eventPublisher,
+		baseCtx:        context.Background(),
+		workers:        make(map[uint]*mailboxMigrationWorker),
+	}
+}
+
+// loadMigrationAccounts 校验源和目标账户属于当前用户
+func (s *MailboxMigrationServiceImpl) loadMigrationAccounts(ctx context.Context, userID, sourceAccountID, targetAccountID uint) (*models.EmailAccount, *models.EmailAccount, error) {
+	if sourceAccountID == targetAccountID {
+		return nil, nil, fmt.Errorf("%w: source and target account must differ", ErrInvalidMailboxMigration)
+	}
+
+	var accounts []models.EmailAccount
+	if err := s.db.WithContext(ctx).
+		Where("id IN ? AND user_id = ?", []uint{sourceAccountID, targetAccountID}, userID).
+		Find(&accounts).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to load accounts: %w", err)
+	}
+
+	var source, target *models.EmailAccount
+	for i := range accounts {
+		switch accounts[i].ID {
+		case sourceAccountID:
+			source = &accounts[i]
+		case targetAccountID:
+			target = &accounts[i]
+		}
+	}
+	if source == nil || target == nil {
+		return nil, nil, fmt.Errorf("account not found")
+	}
+	return source, target, nil
+}
+
+// PlanMigration 生成默认的文件夹映射：特殊文件夹按类型对应，其他文件夹保持相同路径（按目标分隔符转换）
+func (s *MailboxMigrationServiceImpl) PlanMigration(ctx context.Context, userID, sourceAccountID, targetAccountID uint) ([]MailboxMigrationFolderMapping, error) {
+	if _, _, err := s.loadMigrationAccounts(ctx, userID, sourceAccountID, targetAccountID); err != nil {
+		return nil, err
+	}
+
+	var sourceFolders, targetFolders []models.Folder
+	if err := s.db.WithContext(ctx).
+		Where("account_id = ? AND is_selectable = ?", sourceAccountID, true).
+		Order("path").
+		Find(&sourceFolders).Error; err != nil {
+		return nil, fmt.Errorf("failed to load source folders: %w", err)
+	}
+	if err := s.db.WithContext(ctx).
+		Where("account_id = ?", targetAccountID).
+		Find(&targetFolders).Error; err != nil {
+		return nil, fmt.Errorf("failed to load target folders: %w", err)
+	}
+
+	targetByType := make(map[string]string)
+	targetDelimiter := "/"
+	for _, folder := range targetFolders {
+		if folder.Type != models.FolderTypeCustom && folder.Type != "" {
+			if _, ok := targetByType[folder.Type]; !ok {
+				targetByType[folder.Type] = folder.GetFullPath()
+			}
+		}
+		if folder.Delimiter != "" {
+			targetDelimiter = folder.Delimiter
+		}
+	}
+
+	// 收件箱优先迁移，其余按路径排序
+	sort.SliceStable(sourceFolders, func(i, j int) bool {
+		return sourceFolders[i].Type == models.FolderTypeInbox && sourceFolders[j].Type != models.FolderTypeInbox
+	})
+
+	mappings := make([]MailboxMigrationFolderMapping, 0, len(sourceFolders))
+	for _, folder := range sourceFolders {
+		targetPath, ok := targetByType[folder.Type]
+		if !ok {
+			targetPath = folder.GetFullPath()
+			if folder.Type == models.FolderTypeInbox {
+				targetPath = "INBOX"
+			} else if folder.Delimiter != "" && folder.Delimiter != targetDelimiter {
+				targetPath = strings.ReplaceAll(targetPath, folder.Delimiter, targetDelimiter)
+			}
+		}
+		mappings = append(mappings, MailboxMigrationFolderMapping{
+			SourcePath: folder.GetFullPath(),
+			TargetPath: targetPath,
+		})
+	}
+	return mappings, nil
+}
+
+// CreateMigration 创建迁移并立即开始复制
+func (s *MailboxMigrationServiceImpl) CreateMigration(ctx context.Context, userID uint, req *CreateMailboxMigrationRequest) (*models.MailboxMigration, error) {
+	plan, err := s.PlanMigration(ctx, userID, req.SourceAccountID, req.TargetAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	mappings := req.Folders
+	if len(mappings) == 0 {
+		mappings = plan
+	} else {
+		known := make(map[string]bool, len(plan))
+		for _, mapping := range plan {
+			known[mapping.SourcePath] = true
+		}
+		seen := make(map[string]bool, len(mappings))
+		for i := range mappings {
+			mappings[i].SourcePath = strings.TrimSpace(mappings[i].SourcePath)
+			mappings[i].TargetPath = strings.TrimSpace(mappings[i].TargetPath)
+			if !known[mappings[i].SourcePath] {
+				return nil, fmt.Errorf("%w: unknown source folder %q", ErrInvalidMailboxMigration, mappings[i].SourcePath)
+			}
+			if mappings[i].TargetPath == "" {
+				return nil, fmt.Errorf("%w: target folder for %q is required", ErrInvalidMailboxMigration, mappings[i].SourcePath)
+			}
+			if seen[mappings[i].SourcePath] {
+				return nil, fmt.Errorf("%w: source folder %q mapped more than once", ErrInvalidMailboxMigration, mappings[i].SourcePath)
+			}
+			seen[mappings[i].SourcePath] = true
+		}
+	}
+	if len(mappings) == 0 {
+		return nil, fmt.Errorf("%w: source account has no folders, sync it first", ErrInvalidMailboxMigration)
+	}
+
+	now := time.Now()
+	migration := &models.MailboxMigration{
+		UserID:          userID,
+		SourceAccountID: req.SourceAccountID,
+		TargetAccountID: req.TargetAccountID,
+		Status:          models.MailboxMigrationStatusRunning,
+		StartedAt:       &now,
+	}
+	for i, mapping := range mappings {
+		migration.Folders = append(migration.Folders, models.MailboxMigrationFolder{
+			SourcePath: mapping.SourcePath,
+			TargetPath: mapping.TargetPath,
+			SortOrder:  i,
+			Status:     models.MailboxMigrationFolderPending,
+		})
+	}
+
+	if err := s.db.WithContext(ctx).Create(migration).Error; err != nil {
+		return nil, fmt.Errorf("failed to create migration: %w", err)
+	}
+
+	s.startWorker(migration.ID)
+	return s.GetMigration(ctx, userID, migration.ID)
+}
+
+// GetMigration 获取迁移及各文件夹进度
+func (s *MailboxMigrationServiceImpl) GetMigration(ctx context.Context, userID, migrationID uint) (*models.MailboxMigration, error) {
+	var migration models.MailboxMigration
+	if err := s.db.WithContext(ctx).
+		Preload("Folders", func(db *gorm.DB) *gorm.DB { return db.Order("sort_order ASC") }).
+		Where("id = ? AND user_id = ?", migrationID, userID).
+		First(&migration).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrMailboxMigrationNotFound
+		}
+		return nil, fmt.Errorf("failed to get migration: %w", err)
+	}
+	return &migration, nil
+}
+
+// ListMigrations 列出迁移
+func (s *MailboxMigrationServiceImpl) ListMigrations(ctx context.Context, userID uint) ([]*models.MailboxMigration, error) {
+	var migrations []*models.MailboxMigration
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&migrations).Error; err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+	return migrations, nil
+}
+
+// PauseMigration 暂停迁移
+func (s *MailboxMigrationServiceImpl) PauseMigration(ctx context.Context, userID, migrationID uint) (*models.MailboxMigration, error) {
+	return s.transition(ctx, userID, migrationID, []string{models.MailboxMigrationStatusRunning}, models.MailboxMigrationStatusPaused)
+}
+
+// ResumeMigration 从断点继续迁移
+func (s *MailboxMigrationServiceImpl) ResumeMigration(ctx context.Context, userID, migrationID uint) (*models.MailboxMigration, error) {
+	return s.transition(ctx, userID, migrationID,
+		[]string{models.MailboxMigrationStatusPaused, models.MailboxMigrationStatusFailed},
+		models.MailboxMigrationStatusRunning)
+}
+
+// CancelMigration 取消迁移
+func (s *MailboxMigrationServiceImpl) CancelMigration(ctx context.Context, userID, migrationID uint) (*models.MailboxMigration, error) {
+	return s.transition(ctx, userID, migrationID,
+		[]string{models.MailboxMigrationStatusRunning, models.MailboxMigrationStatusPaused, models.MailboxMigrationStatusFailed},
+		models.MailboxMigrationStatusCancelled)
+}
+
+// transition 切换迁移状态并启动/停止复制任务
+func (s *MailboxMigrationServiceImpl) transition(ctx context.Context, userID, migrationID uint, from []string, to string) (*models.MailboxMigration, error) {
+	migration, err := s.GetMigration(ctx, userID, migrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := false
+	for _, status := range from {
+		if migration.Status == status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, fmt.Errorf("cannot change migration status from %s to %s", migration.Status, to)
+	}
+
+	updates := map[string]interface{}{"status": to}
+	if to == models.MailboxMigrationStatusRunning {
+		updates["last_error"] = ""
+	}
+	if to == models.MailboxMigrationStatusCancelled {
+		updates["completed_at"] = time.Now()
+	}
+
+	// 条件更新，避免与复制任务并发修改状态
+	result := s.db.WithContext(ctx).Model(&models.MailboxMigration{}).
+		Where("id = ? AND status = ?", migration.ID, migration.Status).
+		Updates(updates)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to update migration status: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("migration status changed concurrently, please retry")
+	}
+
+	if to == models.MailboxMigrationStatusRunning {
+		s.startWorker(migration.ID)
+	} else {
+		s.stopWorker(migration.ID)
+	}
+
+	return s.GetMigration(ctx, userID, migrationID)
+}
+
+// GetMigrationReport 获取逐文件夹核对报告，核对在迁移完成时进行
+func (s *MailboxMigrationServiceImpl) GetMigrationReport(ctx context.Context, userID, migrationID uint) (*MailboxMigrationReport, error) {
+	migration, err := s.GetMigration(ctx, userID, migrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &MailboxMigrationReport{
+		MigrationID:  migration.ID,
+		Status:       migration.Status,
+		VerifiedAt:   migration.VerifiedAt,
+		CopiedEmails: migration.CopiedEmails,
+		FailedEmails: migration.FailedEmails,
+		Matched:      migration.VerifiedAt != nil,
+		Folders:      make([]MailboxMigrationFolderReport, 0, len(migration.Folders)),
+	}
+	for _, folder := range migration.Folders {
+		item := MailboxMigrationFolderReport{
+			SourcePath:   folder.SourcePath,
+			TargetPath:   folder.TargetPath,
+			SourceCount:  folder.SourceCount,
+			TargetCount:  folder.TargetCount,
+			CopiedEmails: folder.CopiedEmails,
+			FailedEmails: folder.FailedEmails,
+		}
+		// 目标文件夹可能原本就有邮件，因此只要求数量不少于源文件夹
+		item.Matched = folder.FailedEmails == 0 && folder.SourceCount != nil && folder.TargetCount != nil &&
+			*folder.TargetCount >= *folder.SourceCount
+		if !item.Matched {
+			report.Matched = false
+		}
+		report.Folders = append(report.Folders, item)
+	}
+	return report, nil
+}
+
+// Start 启动服务并恢复运行中的迁移，断点保证不会重复复制已完成的邮件
+func (s *MailboxMigrationServiceImpl) Start(ctx context.Context) error {
+	s.mutex.Lock()
+	s.baseCtx = ctx
+	s.mutex.Unlock()
+
+	var migrationIDs []uint
+	if err := s.db.WithContext(ctx).Model(&models.MailboxMigration{}).
+		Where("status = ?", models.MailboxMigrationStatusRunning).
+		Pluck("id", &migrationIDs).Error; err != nil {
+		return fmt.Errorf("failed to load running migrations: %w", err)
+	}
+
+	for _, migrationID := range migrationIDs {
+		s.startWorker(migrationID)
+	}
+
+	if len(migrationIDs) > 0 {
+		log.Printf("Resumed %d mailbox migrations", len(migrationIDs))
+	}
+	return nil
+}
+
+// Stop 停止所有迁移任务，状态保持为运行中，下次启动时继续
+func (s *MailboxMigrationServiceImpl) Stop() {
+	s.mutex.Lock()
+	for _, worker := range s.workers {
+		worker.cancel()
+	}
+	s.mutex.Unlock()
+
+	s.wg.Wait()
+}
+
+// startWorker 启动迁移任务；若上一个任务仍在退出中，等待其结束后再开始，保证同一迁移只有一个复制者
+func (s *MailboxMigrationServiceImpl) startWorker(migrationID uint) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous := s.workers[migrationID]
+	if previous != nil && previous.ctx.Err() == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(s.baseCtx)
+	worker := &mailboxMigrationWorker{ctx: ctx, cancel: cancel, done: make(chan struct{})}
+	s.workers[migrationID] = worker
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+		defer close(worker.done)
+		defer func() {
+			s.mutex.Lock()
+			if s.workers[migrationID] == worker {
+				delete(s.workers, migrationID)
+			}
+			s.mutex.Unlock()
+			cancel()
+		}()
+
+		if previous != nil {
+			select {
+			case <-previous.done:
+			case <-ctx.Done():
+				return
+			}
+		}
+		s.runMigration(ctx, migrationID)
+	}()
+}
+
+func (s *MailboxMigrationServiceImpl) stopWorker(migrationID uint) {
+	s.mutex.Lock()
+	worker := s.workers[migrationID]
+	s.mutex.Unlock()
+
+	if worker != nil {
+		worker.cancel()
+	}
+}
+
+// migrationRun 一次迁移执行期间的连接和状态
+type migrationRun struct {
+	migration *models.MailboxMigration
+	source    *emailSourceSession
+	target    *emailSourceSession
+	created   map[string]bool // 本次已确认存在的目标文件夹
+}
+
+func (r *migrationRun) close() {
+	if r.source != nil {
+		r.source.close()
+	}
+	if r.target != nil {
+		r.target.close()
+	}
+}
+
+// runMigration 按文件夹顺序复制邮件，全部完成后核对邮件数
+func (s *MailboxMigrationServiceImpl) runMigration(ctx context.Context, migrationID uint) {
+	var migration models.MailboxMigration
+	if err := s.db.WithContext(ctx).
+		Preload("Folders", func(db *gorm.DB) *gorm.DB { return db.Order("sort_order ASC") }).
+		First(&migration, migrationID).Error; err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Mailbox migration %d stopped: %v", migrationID, err)
+		}
+		return
+	}
+	if migration.Status != models.MailboxMigrationStatusRunning {
+		return
+	}
+
+	run := &migrationRun{migration: &migration, created: make(map[string]bool)}
+	defer run.close()
+
+	if err := s.connectMigration(ctx, run); err != nil {
+		s.failMigration(ctx, &migration, err)
+		return
+	}
+
+	for i := range migration.Folders {
+		folder := &migration.Folders[i]
+		if folder.Status == models.MailboxMigrationFolderCompleted {
+			continue
+		}
+		if err := s.copyFolder(ctx, run, folder); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.failMigration(ctx, &migration, fmt.Errorf("folder %s: %w", folder.SourcePath, err))
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+
+	s.verifyMigration(ctx, run)
+	s.completeMigration(ctx, &migration)
+}
+
+// connectMigration 连接源和目标账户
+func (s *MailboxMigrationServiceImpl) connectMigration(ctx context.Context, run *migrationRun) error {
+	if s.connector == nil {
+		return fmt.Errorf("mailbox connector not configured")
+	}
+
+	var accounts []models.EmailAccount
+	if err := s.db.WithContext(ctx).
+		Where("id IN ?", []uint{run.migration.SourceAccountID, run.migration.TargetAccountID}).
+		Find(&accounts).Error; err != nil {
+		return fmt.Errorf("failed to load accounts: %w", err)
+	}
+	for i := range accounts {
+		session, err := s.connector.openEmailSourceSession(ctx, &accounts[i])
+		if err != nil {
+			return fmt.Errorf("account %s: %w", accounts[i].Email, err)
+		}
+		if accounts[i].ID == run.migration.SourceAccountID {
+			run.source = session
+		} else {
+			run.target = session
+		}
+	}
+	if run.source == nil || run.target == nil {
+		return fmt.Errorf("source or target account no longer exists")
+	}
+	return nil
+}
+
+// copyFolder 复制单个文件夹中断点之后的邮件，每封邮件写入成功后推进断点
+func (s *MailboxMigrationServiceImpl) copyFolder(ctx context.Context, run *migrationRun, folder *models.MailboxMigrationFolder) error {
+	if !run.created[folder.TargetPath] {
+		// 文件夹已存在时服务器会返回错误，真正不可用时APPEND会失败
+		if err := run.target.client.CreateFolder(ctx, folder.TargetPath); err != nil {
+			log.Printf("Mailbox migration %d: create folder %s: %v", run.migration.ID, folder.TargetPath, err)
+		}
+		run.created[folder.TargetPath] = true
+	}
+
+	status, err := run.source.client.SelectFolder(ctx, folder.SourcePath)
+	if err != nil {
+		return fmt.Errorf("failed to select source folder: %w", err)
+	}
+	run.source.folder = folder.SourcePath
+
+	if folder.UIDValidity != 0 && status != nil && status.UIDValidity != folder.UIDValidity {
+		log.Printf("Mailbox migration %d: UIDVALIDITY of %s changed, restarting folder", run.migration.ID, folder.SourcePath)
+		folder.LastUID = 0
+	}
+	if status != nil {
+		folder.UIDValidity = status.UIDValidity
+	}
+
+	uids, err := run.source.client.SearchEmails(ctx, &providers.SearchCriteria{FolderName: folder.SourcePath})
+	if err != nil {
+		return fmt.Errorf("failed to list source emails: %w", err)
+	}
+	sort.Slice(uids, func(i, j int) bool { return uids[i] < uids[j] })
+
+	var pending []uint32
+	for _, uid := range uids {
+		if uid > folder.LastUID {
+			pending = append(pending, uid)
+		}
+	}
+
+	folder.TotalEmails = len(uids)
+	if err := s.db.WithContext(ctx).Model(folder).Updates(map[string]interface{}{
+		"uid_validity": folder.UIDValidity,
+		"last_uid":     folder.LastUID,
+		"total_emails": folder.TotalEmails,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to save folder checkpoint: %w", err)
+	}
+	s.refreshTotals(ctx, run.migration)
+
+	consecutiveFailures := 0
+	for start := 0; start < len(pending); start += mailboxMigrationBatchSize {
+		end := start + mailboxMigrationBatchSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+		batch := pending[start:end]
+
+		headers := make(map[uint32]*providers.EmailHeader, len(batch))
+		fetched, err := run.source.client.FetchEmailHeaders(ctx, batch)
+		if err != nil {
+			return fmt.Errorf("failed to fetch email flags: %w", err)
+		}
+		for _, header := range fetched {
+			if header != nil {
+				headers[header.UID] = header
+			}
+		}
+
+		for _, uid := range batch {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			err := s.copyMessage(ctx, run, folder, uid, headers[uid])
+			if err == nil {
+				consecutiveFailures = 0
+				continue
+			}
+
+			consecutiveFailures++
+			log.Printf("Mailbox migration %d: %s UID %d: %v", run.migration.ID, folder.SourcePath, uid, err)
+			if consecutiveFailures >= mailboxMigrationMaxConsecutiveFailures {
+				// 连续失败通常是连接问题，撤销之前几封的失败计数，恢复后从断点重试
+				s.recordFolderFailures(ctx, run.migration, folder, 1-consecutiveFailures, err)
+				return fmt.Errorf("too many consecutive failures: %w", err)
+			}
+			s.recordFolderFailures(ctx, run.migration, folder, 1, err)
+		}
+
+		s.publishMigrationEvent(ctx, sse.EventMigrationProgress, run.migration, folder.SourcePath)
+	}
+
+	folder.Status = models.MailboxMigrationFolderCompleted
+	if err := s.db.WithContext(ctx).Model(folder).Update("status", folder.Status).Error; err != nil {
+		return fmt.Errorf("failed to complete folder: %w", err)
+	}
+	return nil
+}
+
+// copyMessage 获取邮件原文并写入目标文件夹，保留标记和日期
+func (s *MailboxMigrationServiceImpl) copyMessage(ctx context.Context, run *migrationRun, folder *models.MailboxMigrationFolder, uid uint32, header *providers.EmailHeader) error {
+	raw, err := run.source.client.FetchRawEmail(ctx, uid)
+	if err != nil {
+		return fmt.Errorf("failed to fetch email source: %w", err)
+	}
+	data, err := io.ReadAll(raw)
+	raw.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read email source: %w", err)
+	}
+
+	var flags []string
+	var date time.Time
+	if header != nil {
+		flags = migrationFlags(header.Flags)
+		date = header.Date
+	}
+	if err := run.target.client.AppendMessage(ctx, folder.TargetPath, flags, date, data); err != nil {
+		return fmt.Errorf("failed to append email: %w", err)
+	}
+
+	folder.LastUID = uid
+	folder.CopiedEmails++
+	run.migration.CopiedEmails++
+
+	// 邮件已写入目标服务器，即使任务被暂停也要保存断点，避免恢复后重复复制
+	return s.db.WithContext(context.WithoutCancel(ctx)).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(folder).Updates(map[string]interface{}{
+			"last_uid":      uid,
+			"copied_emails": gorm.Expr("copied_emails + 1"),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to save folder checkpoint: %w", err)
+		}
+		return tx.Model(run.migration).UpdateColumn("copied_emails", gorm.Expr("copied_emails + 1")).Error
+	})
+}
+
+// migrationFlags 过滤不能由客户端设置的标记
+func migrationFlags(flags []string) []string {
+	var result []string
+	for _, flag := range flags {
+		if strings.EqualFold(flag, "\\Recent") || strings.EqualFold(flag, "\\Deleted") {
+			continue
+		}
+		result = append(result, flag)
+	}
+	return result
+}
+
+// recordFolderFailures 调整失败计数，delta为负时撤销
+func (s *MailboxMigrationServiceImpl) recordFolderFailures(ctx context.Context, migration *models.MailboxMigration, folder *models.MailboxMigrationFolder, delta int, cause error) {
+	folder.FailedEmails += delta
+	migration.FailedEmails += delta
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(folder).Updates(map[string]interface{}{
+			"failed_emails": gorm.Expr("MAX(failed_emails + ?, 0)", delta),
+			"last_error":    cause.Error(),
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Model(migration).UpdateColumn("failed_emails", gorm.Expr("MAX(failed_emails + ?, 0)", delta)).Error
+	})
+	if err != nil {
+		log.Printf("Failed to record mailbox migration %d failure: %v", migration.ID, err)
+	}
+}
+
+// refreshTotals 按各文件夹已知的邮件数更新迁移总数
+func (s *MailboxMigrationServiceImpl) refreshTotals(ctx context.Context, migration *models.MailboxMigration) {
+	var total int64
+	if err := s.db.WithContext(ctx).Model(&models.MailboxMigrationFolder{}).
+		Where("migration_id = ?", migration.ID).
+		Select("COALESCE(SUM(total_emails), 0)").
+		Scan(&total).Error; err != nil {
+		log.Printf("Failed to refresh mailbox migration %d totals: %v", migration.ID, err)
+		return
+	}
+	migration.TotalEmails = int(total)
+	if err := s.db.WithContext(ctx).Model(migration).UpdateColumn("total_emails", total).Error; err != nil {
+		log.Printf("Failed to refresh mailbox migration %d totals: %v", migration.ID, err)
+	}
+}
+
+// verifyMigration 核对两端服务器上各文件夹的邮件数
+func (s *MailboxMigrationServiceImpl) verifyMigration(ctx context.Context, run *migrationRun) {
+	for i := range run.migration.Folders {
+		folder := &run.migration.Folders[i]
+		updates := map[string]interface{}{}
+		if status, err := run.source.client.GetFolderStatus(ctx, folder.SourcePath); err == nil && status != nil {
+			updates["source_count"] = status.TotalEmails
+		} else if err != nil {
+			log.Printf("Mailbox migration %d: verify %s: %v", run.migration.ID, folder.SourcePath, err)
+		}
+		if status, err := run.target.client.GetFolderStatus(ctx, folder.TargetPath); err == nil && status != nil {
+			updates["target_count"] = status.TotalEmails
+		} else if err != nil {
+			log.Printf("Mailbox migration %d: verify %s: %v", run.migration.ID, folder.TargetPath, err)
+		}
+		if len(updates) == 0 {
+			continue
+		}
+		if err := s.db.WithContext(ctx).Model(folder).Updates(updates).Error; err != nil {
+			log.Printf("Failed to save mailbox migration %d verification: %v", run.migration.ID, err)
+		}
+	}
+}
+
+// completeMigration 标记迁移完成
+func (s *MailboxMigrationServiceImpl) completeMigration(ctx context.Context, migration *models.MailboxMigration) {
+	now := time.Now()
+	result := s.db.WithContext(ctx).Model(&models.MailboxMigration{}).
+		Where("id = ? AND status = ?", migration.ID, models.MailboxMigrationStatusRunning).
+		Updates(map[string]interface{}{
+			"status":       models.MailboxMigrationStatusCompleted,
+			"completed_at": now,
+			"verified_at":  now,
+		})
+	if result.Error != nil {
+		log.Printf("Failed to complete mailbox migration %d: %v", migration.ID, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		return
+	}
+
+	migration.Status = models.MailboxMigrationStatusCompleted
+	s.publishMigrationEvent(ctx, sse.EventMigrationCompleted, migration, "")
+}
+
+// failMigration 标记迁移失败，断点保留，可以恢复
+func (s *MailboxMigrationServiceImpl) failMigration(ctx context.Context, migration *models.MailboxMigration, cause error) {
+	log.Printf("Mailbox migration %d failed: %v", migration.ID, cause)
+
+	result := s.db.WithContext(ctx).Model(&models.MailboxMigration{}).
+		Where("id = ? AND status = ?", migration.ID, models.MailboxMigrationStatusRunning).
+		Updates(map[string]interface{}{
+			"status":     models.MailboxMigrationStatusFailed,
+			"last_error": cause.Error(),
+		})
+	if result.Error != nil {
+		log.Printf("Failed to mark mailbox migration %d as failed: %v", migration.ID, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		return
+	}
+
+	migration.Status = models.MailboxMigrationStatusFailed
+	migration.LastError = cause.Error()
+	s.publishMigrationEvent(ctx, sse.EventMigrationFailed, migration, "")
+}
+
+func (s *MailboxMigrationServiceImpl) publishMigrationEvent(ctx context.Context, eventType sse.EventType, migration *models.MailboxMigration, folderPath string) {
+	if s.eventPublisher == nil {
+		return
+	}
+
+	data := &sse.MigrationEventData{
+		MigrationID:     migration.ID,
+		SourceAccountID: migration.SourceAccountID,
+		TargetAccountID: migration.TargetAccountID,
+		Status:          migration.Status,
+		FolderPath:      folderPath,
+		TotalEmails:     migration.TotalEmails,
+		CopiedEmails:    migration.CopiedEmails,
+		FailedEmails:    migration.FailedEmails,
+		ErrorMessage:    migration.LastError,
+	}
+	if migration.TotalEmails > 0 {
+		data.Progress = float64(migration.CopiedEmails+migration.FailedEmails) / float64(migration.TotalEmails)
+	}
+	if migration.Status == models.MailboxMigrationStatusCompleted {
+		data.Progress = 1
+	}
+
+	if err := s.eventPublisher.PublishToUser(ctx, migration.UserID, sse.NewMigrationEvent(eventType, data, migration.UserID)); err != nil {
+		log.Printf("Failed to publish mailbox migration event: %v", err)
+	}
+}
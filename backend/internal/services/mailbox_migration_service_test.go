@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+// setupMailboxMigrationTest 创建迁移服务及目标账户
+func setupMailboxMigrationTest(t *testing.T) (*emailStateServiceTestEnv, *MailboxMigrationServiceImpl, *models.EmailAccount) {
+	t.Helper()
+
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.MailboxMigration{}, &models.MailboxMigrationFolder{}))
+	target, _ := createTransferTarget(t, env)
+	require.NoError(t, env.db.Create(&models.Folder{
+		AccountID:    target.ID,
+		Name:         "INBOX",
+		Type:         models.FolderTypeInbox,
+		Path:         "INBOX",
+		Delimiter:    ".",
+		IsSelectable: true,
+	}).Error)
+
+	service := NewMailboxMigrationService(env.db, env.service, env.publisher).(*MailboxMigrationServiceImpl)
+	t.Cleanup(service.Stop)
+	return env, service, target
+}
+
+// waitForMigrationStatus 等待迁移进入指定状态
+func waitForMigrationStatus(t *testing.T, service *MailboxMigrationServiceImpl, userID, migrationID uint, status string) *models.MailboxMigration {
+	t.Helper()
+
+	var migration *models.MailboxMigration
+	require.Eventually(t, func() bool {
+		current, err := service.GetMigration(context.Background(), userID, migrationID)
+		if err != nil {
+			return false
+		}
+		migration = current
+		return current.Status == status
+	}, 5*time.Second, 10*time.Millisecond)
+	return migration
+}
+
+func TestPlanMailboxMigrationMapsFolders(t *testing.T) {
+	env, service, target := setupMailboxMigrationTest(t)
+
+	plan, err := service.PlanMigration(context.Background(), env.user.ID, env.account.ID, target.ID)
+	require.NoError(t, err)
+	require.Equal(t, []MailboxMigrationFolderMapping{
+		{SourcePath: "INBOX", TargetPath: "INBOX"},
+		{SourcePath: "Projects", TargetPath: "Projects"},
+	}, plan)
+
+	_, err = service.PlanMigration(context.Background(), env.user.ID+1, env.account.ID, target.ID)
+	require.EqualError(t, err, "account not found")
+
+	_, err = service.PlanMigration(context.Background(), env.user.ID, env.account.ID, env.account.ID)
+	require.ErrorIs(t, err, ErrInvalidMailboxMigration)
+}
+
+func TestMailboxMigrationCopiesFoldersAndReports(t *testing.T) {
+	env, service, target := setupMailboxMigrationTest(t)
+	ctx := context.Background()
+
+	imap := env.provider.imap
+	imap.searchUIDs = []uint32{7, 3}
+	imap.rawMessages = map[uint32][]byte{
+		3: []byte("Subject: three\r\n\r\nbody"),
+		7: []byte("Subject: seven\r\n\r\nbody"),
+	}
+
+	migration, err := service.CreateMigration(ctx, env.user.ID, &CreateMailboxMigrationRequest{
+		SourceAccountID: env.account.ID,
+		TargetAccountID: target.ID,
+		Folders:         []MailboxMigrationFolderMapping{{SourcePath: "Projects", TargetPath: "Imported/Projects"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, migration.Folders, 1)
+
+	migration = waitForMigrationStatus(t, service, env.user.ID, migration.ID, models.MailboxMigrationStatusCompleted)
+	require.Equal(t, 2, migration.CopiedEmails)
+	require.Equal(t, uint32(7), migration.Folders[0].LastUID)
+	require.NotNil(t, migration.VerifiedAt)
+
+	require.Len(t, imap.appendCalls, 2)
+	require.Equal(t, "Imported/Projects", imap.appendCalls[0].Folder)
+	require.Equal(t, []byte("Subject: three\r\n\r\nbody"), imap.appendCalls[0].Data)
+
+	report, err := service.GetMigrationReport(ctx, env.user.ID, migration.ID)
+	require.NoError(t, err)
+	require.Len(t, report.Folders, 1)
+	require.Equal(t, 2, report.Folders[0].CopiedEmails)
+
+	_, err = service.GetMigration(ctx, env.user.ID+1, migration.ID)
+	require.ErrorIs(t, err, ErrMailboxMigrationNotFound)
+
+	_, err = service.CreateMigration(ctx, env.user.ID, &CreateMailboxMigrationRequest{
+		SourceAccountID: env.account.ID,
+		TargetAccountID: target.ID,
+		Folders:         []MailboxMigrationFolderMapping{{SourcePath: "Missing", TargetPath: "Missing"}},
+	})
+	require.ErrorIs(t, err, ErrInvalidMailboxMigration)
+}
+
+func TestMailboxMigrationResumesFromCheckpoint(t *testing.T) {
+	env, service, target := setupMailboxMigrationTest(t)
+	ctx := context.Background()
+
+	imap := env.provider.imap
+	imap.searchUIDs = []uint32{1, 2, 3}
+	imap.rawMessages = map[uint32][]byte{
+		1: []byte("Subject: one\r\n\r\nbody"),
+		2: []byte("Subject: two\r\n\r\nbody"),
+		3: []byte("Subject: three\r\n\r\nbody"),
+	}
+
+	migration := &models.MailboxMigration{
+		UserID:          env.user.ID,
+		SourceAccountID: env.account.ID,
+		TargetAccountID: target.ID,
+		Status:          models.MailboxMigrationStatusPaused,
+		CopiedEmails:    2,
+		Folders: []models.MailboxMigrationFolder{{
+			SourcePath:   "INBOX",
+			TargetPath:   "INBOX",
+			Status:       models.MailboxMigrationFolderPending,
+			LastUID:      2,
+			CopiedEmails: 2,
+		}},
+	}
+	require.NoError(t, env.db.Create(migration).Error)
+
+	_, err := service.CancelMigration(ctx, env.user.ID+1, migration.ID)
+	require.ErrorIs(t, err, ErrMailboxMigrationNotFound)
+
+	_, err = service.ResumeMigration(ctx, env.user.ID, migration.ID)
+	require.NoError(t, err)
+
+	current := waitForMigrationStatus(t, service, env.user.ID, migration.ID, models.MailboxMigrationStatusCompleted)
+	require.Equal(t, 3, current.CopiedEmails)
+	require.Len(t, imap.appendCalls, 1)
+	require.Equal(t, []byte("Subject: three\r\n\r\nbody"), imap.appendCalls[0].Data)
+
+	_, err = service.PauseMigration(ctx, env.user.ID, migration.ID)
+	require.ErrorContains(t, err, "cannot change migration status")
+}
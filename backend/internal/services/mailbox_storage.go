@@ -0,0 +1,482 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 邮箱清理任务状态
+const (
+	StorageCleanupStatusRunning   = "running"
+	StorageCleanupStatusCompleted = "completed"
+	StorageCleanupStatusCancelled = "cancelled"
+	StorageCleanupStatusFailed    = "failed"
+)
+
+// 邮箱清理方式
+const (
+	StorageCleanupActionDelete  = "delete"  // 移入回收站
+	StorageCleanupActionArchive = "archive" // 移动到归档文件夹
+)
+
+const (
+	defaultStorageTopLimit = 20
+	maxStorageTopLimit     = 100
+)
+
+// bulkSenderPatterns 订阅邮件/通知类发件人地址的常见特征
+var bulkSenderPatterns = []string{
+	"newsletter", "noreply", "no-reply", "no_reply", "donotreply", "do-not-reply",
+	"news@", "marketing", "mailer", "digest", "notification", "promo",
+}
+
+// StorageTopRequest 邮箱空间分析请求
+type StorageTopRequest struct {
+	Limit int `form:"limit" json:"limit"` // 每个列表返回的条数，默认20，最多100
+}
+
+// StorageEmailSummary 空间分析中的邮件摘要
+type StorageEmailSummary struct {
+	ID            uint      `json:"id"`
+	FolderID      *uint     `json:"folder_id,omitempty"`
+	Subject       string    `json:"subject"`
+	From          string    `gorm:"column:from_address" json:"from"`
+	Date          time.Time `json:"date"`
+	Size          int64     `json:"size"`
+	IsRead        bool      `json:"is_read"`
+	HasAttachment bool      `json:"has_attachment"`
+}
+
+// StorageAttachmentSummary 空间分析中的附件摘要
+type StorageAttachmentSummary struct {
+	ID          uint      `json:"id"`
+	EmailID     uint      `json:"email_id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Subject     string    `json:"subject"`
+	Date        time.Time `json:"date"`
+}
+
+// SenderVolume 单个发件人的邮件数量和占用空间
+type SenderVolume struct {
+	Sender     string    `json:"sender"`
+	Emails     int64     `json:"emails"`
+	Unread     int64     `json:"unread"`
+	TotalSize  int64     `json:"total_size"`
+	OldestDate time.Time `json:"oldest_date"`
+	NewestDate time.Time `json:"newest_date"`
+}
+
+// MailboxStorageReport 账户空间占用分析
+type MailboxStorageReport struct {
+	AccountID               uint                       `json:"account_id"`
+	TotalEmails             int64                      `json:"total_emails"`
+	TotalSize               int64                      `json:"total_size"`
+	LargestEmails           []StorageEmailSummary      `json:"largest_emails"`
+	LargestAttachments      []StorageAttachmentSummary `json:"largest_attachments"`
+	OldestUnreadNewsletters []StorageEmailSummary      `json:"oldest_unread_newsletters"`
+	TopSenders              []SenderVolume             `json:"top_senders"`
+}
+
+// StartStorageCleanupRequest 按条件批量清理邮件，例如删除所有超过10MB且早于两年前的邮件。
+// 至少需要指定一个过滤条件
+type StartStorageCleanupRequest struct {
+	Action        string `json:"action" binding:"required,oneof=delete archive"`
+	MinSize       int64  `json:"min_size,omitempty"`        // 邮件大小下限（字节）
+	OlderThanDays int    `json:"older_than_days,omitempty"` // 只处理早于该天数的邮件
+	FolderID      *uint  `json:"folder_id,omitempty"`
+	Sender        string `json:"sender,omitempty"` // 发件人地址，不区分大小写
+	UnreadOnly    bool   `json:"unread_only,omitempty"`
+	Newsletters   bool   `json:"newsletters,omitempty"` // 只处理订阅/通知类发件人的邮件
+	DryRun        bool   `json:"dry_run,omitempty"`     // 只统计匹配的邮件数量和大小
+}
+
+// StorageCleanupJob 邮箱清理任务
+type StorageCleanupJob struct {
+	ID           string     `json:"id"`
+	AccountID    uint       `json:"account_id"`
+	Action       string     `json:"action"`
+	DryRun       bool       `json:"dry_run"`
+	Status       string     `json:"status"`
+	Total        int        `json:"total"`
+	MatchedBytes int64      `json:"matched_bytes"`
+	Processed    int        `json:"processed"`
+	Succeeded    int        `json:"succeeded"`
+	Failed       int        `json:"failed"`
+	FreedBytes   int64      `json:"freed_bytes"`
+	LastError    string     `json:"last_error,omitempty"`
+	StartedAt    time.Time  `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+
+	userID uint
+	cancel context.CancelFunc
+}
+
+// Finished 任务是否已结束
+func (j *StorageCleanupJob) Finished() bool {
+	return j.Status != StorageCleanupStatusRunning
+}
+
+// storageCleanupJobRegistry 保存运行中和已结束的邮箱清理任务
+type storageCleanupJobRegistry struct {
+	mutex sync.RWMutex
+	jobs  map[string]*StorageCleanupJob
+}
+
+func newStorageCleanupJobRegistry() *storageCleanupJobRegistry {
+	return &storageCleanupJobRegistry{jobs: make(map[string]*StorageCleanupJob)}
+}
+
+// snapshot 返回属于该用户和账户的任务副本
+func (r *storageCleanupJobRegistry) snapshot(userID, accountID uint, jobID string) (*StorageCleanupJob, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	job, ok := r.jobs[jobID]
+	if !ok || job.userID != userID || job.AccountID != accountID {
+		return nil, false
+	}
+	copied := *job
+	copied.cancel = nil
+	return &copied, true
+}
+
+func (r *storageCleanupJobRegistry) update(jobID string, fn func(job *StorageCleanupJob)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if job, ok := r.jobs[jobID]; ok {
+		fn(job)
+	}
+}
+
+// storageAccount 校验账户属于当前用户
+func (s *EmailServiceImpl) storageAccount(ctx context.Context, userID, accountID uint) error {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.EmailAccount{}).
+		Where("id = ? AND user_id = ?", accountID, userID).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check account: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("account not found")
+	}
+	return nil
+}
+
+// bulkSenderCondition 订阅/通知类发件人的查询条件
+func bulkSenderCondition(db *gorm.DB) *gorm.DB {
+	condition := db
+	for i, pattern := range bulkSenderPatterns {
+		if i == 0 {
+			condition = condition.Where("LOWER(emails.from_address) LIKE ?", "%"+pattern+"%")
+		} else {
+			condition = condition.Or("LOWER(emails.from_address) LIKE ?", "%"+pattern+"%")
+		}
+	}
+	return condition
+}
+
+// GetMailboxStorageReport 分析账户的空间占用：最大的邮件和附件、最早的未读订阅邮件以及按发件人统计的占用
+func (s *EmailServiceImpl) GetMailboxStorageReport(ctx context.Context, userID, accountID uint, req *StorageTopRequest) (*MailboxStorageReport, error) {
+	if err := s.storageAccount(ctx, userID, accountID); err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultStorageTopLimit
+	}
+	if limit > maxStorageTopLimit {
+		limit = maxStorageTopLimit
+	}
+
+	emails := func() *gorm.DB {
+		return s.db.WithContext(ctx).Model(&models.Email{}).
+			Where("emails.account_id = ? AND emails.is_deleted = ?", accountID, false)
+	}
+	report := &MailboxStorageReport{AccountID: accountID}
+
+	var totals struct {
+		Count int64
+		Size  int64
+	}
+	if err := emails().Select("COUNT(*) AS count, COALESCE(SUM(emails.size), 0) AS size").Scan(&totals).Error; err != nil {
+		return nil, fmt.Errorf("failed to count emails: %w", err)
+	}
+	report.TotalEmails = totals.Count
+	report.TotalSize = totals.Size
+
+	summaryColumns := "emails.id, emails.folder_id, emails.subject, emails.from_address, emails.date, emails.size, emails.is_read, emails.has_attachment"
+	report.LargestEmails = []StorageEmailSummary{}
+	if err := emails().Select(summaryColumns).
+		Where("emails.size > 0").
+		Order("emails.size DESC").Limit(limit).
+		Scan(&report.LargestEmails).Error; err != nil {
+		return nil, fmt.Errorf("failed to find largest emails: %w", err)
+	}
+
+	report.LargestAttachments = []StorageAttachmentSummary{}
+	if err := s.db.WithContext(ctx).Model(&models.Attachment{}).
+		Select("attachments.id, attachments.email_id, attachments.filename, attachments.content_type, attachments.size, emails.subject, emails.date").
+		Joins("JOIN emails ON attachments.email_id = emails.id").
+		Where("emails.account_id = ? AND emails.is_deleted = ?", accountID, false).
+		Order("attachments.size DESC").Limit(limit).
+		Scan(&report.LargestAttachments).Error; err != nil {
+		return nil, fmt.Errorf("failed to find largest attachments: %w", err)
+	}
+
+	report.OldestUnreadNewsletters = []StorageEmailSummary{}
+	if err := emails().Select(summaryColumns).
+		Where("emails.is_read = ?", false).
+		Where(bulkSenderCondition(s.db)).
+		Order("emails.date ASC").Limit(limit).
+		Scan(&report.OldestUnreadNewsletters).Error; err != nil {
+		return nil, fmt.Errorf("failed to find unread newsletters: %w", err)
+	}
+
+	senders, err := senderVolumes(emails())
+	if err != nil {
+		return nil, err
+	}
+	if len(senders) > limit {
+		senders = senders[:limit]
+	}
+	report.TopSenders = senders
+
+	return report, nil
+}
+
+// senderVolumes 按发件人地址汇总邮件数量和大小，同一地址的不同显示名合并统计
+func senderVolumes(query *gorm.DB) ([]SenderVolume, error) {
+	var rows []struct {
+		FromAddress string
+		Emails      int64
+		Unread      int64
+		TotalSize   int64
+		OldestDate  string
+		NewestDate  string
+	}
+	if err := query.
+		Select("emails.from_address, COUNT(*) AS emails, " +
+			"SUM(CASE WHEN emails.is_read THEN 0 ELSE 1 END) AS unread, " +
+			"COALESCE(SUM(emails.size), 0) AS total_size, MIN(emails.date) AS oldest_date, MAX(emails.date) AS newest_date").
+		Group("emails.from_address").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to aggregate senders: %w", err)
+	}
+
+	bySender := make(map[string]*SenderVolume)
+	for _, row := range rows {
+		sender := row.FromAddress
+		if address := parseEmailAddress(row.FromAddress); address != nil {
+			sender = address.Address
+		}
+		sender = strings.ToLower(sender)
+
+		oldest := parseAggregateTime(row.OldestDate)
+		newest := parseAggregateTime(row.NewestDate)
+		volume, ok := bySender[sender]
+		if !ok {
+			volume = &SenderVolume{Sender: sender, OldestDate: oldest, NewestDate: newest}
+			bySender[sender] = volume
+		}
+		volume.Emails += row.Emails
+		volume.Unread += row.Unread
+		volume.TotalSize += row.TotalSize
+		if oldest.Before(volume.OldestDate) {
+			volume.OldestDate = oldest
+		}
+		if newest.After(volume.NewestDate) {
+			volume.NewestDate = newest
+		}
+	}
+
+	volumes := make([]SenderVolume, 0, len(bySender))
+	for _, volume := range bySender {
+		volumes = append(volumes, *volume)
+	}
+	sort.Slice(volumes, func(i, j int) bool {
+		if volumes[i].TotalSize != volumes[j].TotalSize {
+			return volumes[i].TotalSize > volumes[j].TotalSize
+		}
+		return volumes[i].Sender < volumes[j].Sender
+	})
+	return volumes, nil
+}
+
+// parseAggregateTime 解析MIN/MAX聚合返回的时间，SQLite聚合结果为字符串
+func parseAggregateTime(value string) time.Time {
+	for _, layout := range []string{"2006-01-02 15:04:05.999999999-07:00", time.RFC3339Nano, "2006-01-02 15:04:05"} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed
+		}
+	}
+	return time.Time{}
+}
+
+// cleanupQuery 构造清理任务的匹配条件
+func (s *EmailServiceImpl) cleanupQuery(ctx context.Context, accountID uint, req *StartStorageCleanupRequest) *gorm.DB {
+	query := s.db.WithContext(ctx).Model(&models.Email{}).
+		Where("emails.account_id = ? AND emails.is_deleted = ?", accountID, false)
+	if req.MinSize > 0 {
+		query = query.Where("emails.size >= ?", req.MinSize)
+	}
+	if req.OlderThanDays > 0 {
+		query = query.Where("emails.date < ?", time.Now().AddDate(0, 0, -req.OlderThanDays))
+	}
+	if req.FolderID != nil {
+		query = query.Where("emails.folder_id = ?", *req.FolderID)
+	}
+	if sender := strings.ToLower(strings.TrimSpace(req.Sender)); sender != "" {
+		query = query.Where("(LOWER(emails.from_address) = ? OR LOWER(emails.from_address) LIKE ?)", sender, "%<"+sender+">")
+	}
+	if req.UnreadOnly {
+		query = query.Where("emails.is_read = ?", false)
+	}
+	if req.Newsletters {
+		query = query.Where(bulkSenderCondition(s.db))
+	}
+	return query
+}
+
+// StartStorageCleanup 按条件在后台批量删除或归档邮件，DryRun时只统计匹配结果
+func (s *EmailServiceImpl) StartStorageCleanup(ctx context.Context, userID, accountID uint, req *StartStorageCleanupRequest) (*StorageCleanupJob, error) {
+	if err := s.storageAccount(ctx, userID, accountID); err != nil {
+		return nil, err
+	}
+	if req.MinSize <= 0 && req.OlderThanDays <= 0 && req.FolderID == nil &&
+		strings.TrimSpace(req.Sender) == "" && !req.UnreadOnly && !req.Newsletters {
+		return nil, fmt.Errorf("at least one cleanup filter is required")
+	}
+	if req.FolderID != nil {
+		var count int64
+		if err := s.db.WithContext(ctx).Model(&models.Folder{}).
+			Where("id = ? AND account_id = ?", *req.FolderID, accountID).
+			Count(&count).Error; err != nil {
+			return nil, fmt.Errorf("failed to check folder: %w", err)
+		}
+		if count == 0 {
+			return nil, fmt.Errorf("folder not found")
+		}
+	}
+
+	var matches []struct {
+		ID   uint
+		Size int64
+	}
+	if err := s.cleanupQuery(ctx, accountID, req).
+		Select("emails.id, emails.size").
+		Order("emails.date ASC").
+		Scan(&matches).Error; err != nil {
+		return nil, fmt.Errorf("failed to find emails: %w", err)
+	}
+
+	jobCtx, cancel := context.WithCancel(context.Background())
+	job := &StorageCleanupJob{
+		ID:        newStorageCleanupJobID(),
+		AccountID: accountID,
+		Action:    req.Action,
+		DryRun:    req.DryRun,
+		Status:    StorageCleanupStatusRunning,
+		Total:     len(matches),
+		StartedAt: time.Now(),
+		userID:    userID,
+		cancel:    cancel,
+	}
+	for _, match := range matches {
+		job.MatchedBytes += match.Size
+	}
+
+	s.storageCleanups.mutex.Lock()
+	s.storageCleanups.jobs[job.ID] = job
+	s.storageCleanups.mutex.Unlock()
+
+	if req.DryRun || len(matches) == 0 {
+		s.finishStorageCleanup(jobCtx, job.ID)
+	} else {
+		go func() {
+			defer s.finishStorageCleanup(jobCtx, job.ID)
+			for _, match := range matches {
+				if jobCtx.Err() != nil {
+					return
+				}
+
+				var err error
+				if req.Action == StorageCleanupActionArchive {
+					err = s.ArchiveEmail(jobCtx, userID, match.ID)
+				} else {
+					err = s.DeleteEmail(jobCtx, userID, match.ID)
+				}
+				s.storageCleanups.update(job.ID, func(job *StorageCleanupJob) {
+					job.Processed++
+					if err != nil {
+						job.Failed++
+						job.LastError = fmt.Sprintf("email %d: %v", match.ID, err)
+						return
+					}
+					job.Succeeded++
+					job.FreedBytes += match.Size
+				})
+			}
+		}()
+	}
+
+	snapshot, _ := s.storageCleanups.snapshot(userID, accountID, job.ID)
+	return snapshot, nil
+}
+
+func (s *EmailServiceImpl) finishStorageCleanup(ctx context.Context, jobID string) {
+	now := time.Now()
+	s.storageCleanups.update(jobID, func(job *StorageCleanupJob) {
+		job.Status = StorageCleanupStatusCompleted
+		if ctx.Err() != nil {
+			job.Status = StorageCleanupStatusCancelled
+		} else if job.Total > 0 && job.Failed == job.Total {
+			job.Status = StorageCleanupStatusFailed
+		}
+		job.FinishedAt = &now
+		job.cancel()
+	})
+}
+
+// GetStorageCleanup 获取邮箱清理任务状态
+func (s *EmailServiceImpl) GetStorageCleanup(ctx context.Context, userID, accountID uint, jobID string) (*StorageCleanupJob, error) {
+	job, ok := s.storageCleanups.snapshot(userID, accountID, jobID)
+	if !ok {
+		return nil, fmt.Errorf("cleanup job not found")
+	}
+	return job, nil
+}
+
+// CancelStorageCleanup 取消邮箱清理任务，已处理的邮件不会恢复
+func (s *EmailServiceImpl) CancelStorageCleanup(ctx context.Context, userID, accountID uint, jobID string) (*StorageCleanupJob, error) {
+	s.storageCleanups.mutex.RLock()
+	job, ok := s.storageCleanups.jobs[jobID]
+	s.storageCleanups.mutex.RUnlock()
+	if !ok || job.userID != userID || job.AccountID != accountID {
+		return nil, fmt.Errorf("cleanup job not found")
+	}
+
+	job.cancel()
+	return s.GetStorageCleanup(ctx, userID, accountID, jobID)
+}
+
+func newStorageCleanupJobID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("cleanup_%d", time.Now().UnixNano())
+	}
+	return "cleanup_" + hex.EncodeToString(buf)
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMailboxStorageReport(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+
+	large := env.createEmail(t, env.inbox, 1, "large", true, false)
+	small := env.createEmail(t, env.inbox, 2, "small", false, false)
+	news := env.createEmail(t, env.work, 3, "weekly", false, false)
+	trashed := env.createEmail(t, env.inbox, 4, "trashed", false, true)
+	require.NoError(t, env.db.Model(large).Updates(map[string]interface{}{"size": 20 << 20, "from_address": "Alice <Alice@example.com>"}).Error)
+	require.NoError(t, env.db.Model(small).Updates(map[string]interface{}{"size": 1 << 10, "from_address": "alice@example.com"}).Error)
+	require.NoError(t, env.db.Model(news).Updates(map[string]interface{}{"size": 5 << 10, "from_address": "Shop <newsletter@shop.example>"}).Error)
+	require.NoError(t, env.db.Model(trashed).Update("size", 50<<20).Error)
+	require.NoError(t, env.db.Create(&models.Attachment{EmailID: &large.ID, Filename: "video.mp4", Size: 19 << 20}).Error)
+
+	report, err := env.service.GetMailboxStorageReport(ctx, env.user.ID, env.account.ID, &StorageTopRequest{Limit: 2})
+	require.NoError(t, err)
+	require.EqualValues(t, 3, report.TotalEmails)
+	require.EqualValues(t, 20<<20+1<<10+5<<10, report.TotalSize)
+
+	require.Len(t, report.LargestEmails, 2)
+	require.Equal(t, large.ID, report.LargestEmails[0].ID)
+	require.Equal(t, "Alice <Alice@example.com>", report.LargestEmails[0].From)
+	require.Len(t, report.LargestAttachments, 1)
+	require.Equal(t, "video.mp4", report.LargestAttachments[0].Filename)
+	require.Equal(t, "large", report.LargestAttachments[0].Subject)
+
+	require.Len(t, report.OldestUnreadNewsletters, 1)
+	require.Equal(t, news.ID, report.OldestUnreadNewsletters[0].ID)
+
+	require.Len(t, report.TopSenders, 2)
+	require.Equal(t, "alice@example.com", report.TopSenders[0].Sender)
+	require.EqualValues(t, 2, report.TopSenders[0].Emails)
+	require.EqualValues(t, 1, report.TopSenders[0].Unread)
+	require.False(t, report.TopSenders[0].OldestDate.IsZero())
+
+	_, err = env.service.GetMailboxStorageReport(ctx, env.user.ID+1, env.account.ID, &StorageTopRequest{})
+	require.EqualError(t, err, "account not found")
+}
+
+func TestStorageCleanupDeletesMatchingEmails(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+
+	old := time.Now().AddDate(-3, 0, 0)
+	oldLarge := env.createEmail(t, env.inbox, 1, "old-large", true, false)
+	recentLarge := env.createEmail(t, env.inbox, 2, "recent-large", true, false)
+	oldSmall := env.createEmail(t, env.inbox, 3, "old-small", true, false)
+	require.NoError(t, env.db.Model(oldLarge).Updates(map[string]interface{}{"size": 11 << 20, "date": old}).Error)
+	require.NoError(t, env.db.Model(recentLarge).Update("size", 11<<20).Error)
+	require.NoError(t, env.db.Model(oldSmall).Updates(map[string]interface{}{"size": 1 << 10, "date": old}).Error)
+
+	_, err := env.service.StartStorageCleanup(ctx, env.user.ID, env.account.ID, &StartStorageCleanupRequest{Action: StorageCleanupActionDelete})
+	require.EqualError(t, err, "at least one cleanup filter is required")
+
+	request := &StartStorageCleanupRequest{
+		Action:        StorageCleanupActionDelete,
+		MinSize:       10 << 20,
+		OlderThanDays: 730,
+		DryRun:        true,
+	}
+	preview, err := env.service.StartStorageCleanup(ctx, env.user.ID, env.account.ID, request)
+	require.NoError(t, err)
+	require.True(t, preview.Finished())
+	require.Equal(t, 1, preview.Total)
+	require.EqualValues(t, 11<<20, preview.MatchedBytes)
+	require.Zero(t, preview.Processed)
+
+	request.DryRun = false
+	job, err := env.service.StartStorageCleanup(ctx, env.user.ID, env.account.ID, request)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		current, err := env.service.GetStorageCleanup(ctx, env.user.ID, env.account.ID, job.ID)
+		job = current
+		return err == nil && current.Finished()
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, StorageCleanupStatusCompleted, job.Status, job.LastError)
+	require.Equal(t, 1, job.Succeeded)
+	require.EqualValues(t, 11<<20, job.FreedBytes)
+
+	var emails []models.Email
+	require.NoError(t, env.db.Order("uid").Find(&emails).Error)
+	require.True(t, emails[0].IsDeleted)
+	require.False(t, emails[1].IsDeleted)
+	require.False(t, emails[2].IsDeleted)
+
+	_, err = env.service.GetStorageCleanup(ctx, env.user.ID, env.account.ID+1, job.ID)
+	require.Error(t, err)
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"sync"
+
+	"firemail/internal/config"
+	"firemail/internal/markdown"
+	"firemail/internal/sanitize"
+)
+
+var (
+	composeConfigMu sync.RWMutex
+	composeConfig   = config.ComposeConfig{CodeHighlight: true, CodeTheme: markdown.DefaultTheme}
+)
+
+// ConfigureCompose 设置撰写邮件的全局配置，启动时调用一次
+func ConfigureCompose(cfg config.ComposeConfig) {
+	composeConfigMu.Lock()
+	defer composeConfigMu.Unlock()
+	composeConfig = cfg
+}
+
+func currentComposeConfig() config.ComposeConfig {
+	composeConfigMu.RLock()
+	defer composeConfigMu.RUnlock()
+	return composeConfig
+}
+
+// MarkdownPreviewRequest Markdown预览请求
+type MarkdownPreviewRequest struct {
+	Markdown      string `json:"markdown"`
+	CodeHighlight *bool  `json:"code_highlight,omitempty"` // 为空时使用服务端配置
+}
+
+// MarkdownPreview Markdown渲染结果，即发信时使用的HTML正文和纯文本备选部分
+type MarkdownPreview struct {
+	HTMLBody string `json:"html_body"`
+	TextBody string `json:"text_body"`
+}
+
+// RenderMarkdown 渲染Markdown正文，codeHighlight 为空时按服务端配置决定是否为代码块着色
+func RenderMarkdown(source string, codeHighlight *bool) *MarkdownPreview {
+	cfg := currentComposeConfig()
+	opts := markdown.Options{CodeHighlight: cfg.CodeHighlight, CodeTheme: cfg.CodeTheme}
+	if codeHighlight != nil {
+		opts.CodeHighlight = *codeHighlight
+	}
+
+	htmlBody := markdown.Render(source, opts)
+	return &MarkdownPreview{HTMLBody: htmlBody, TextBody: sanitize.PlainText(htmlBody)}
+}
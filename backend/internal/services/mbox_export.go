@@ -0,0 +1,95 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// mboxExportBatchSize 每批加载的邮件数
+const mboxExportBatchSize = 100
+
+// mboxFromLine 需要转义的正文行（mboxrd：以任意个>开头的From行再加一个>）
+var mboxFromLine = regexp.MustCompile(`^>*From `)
+
+// MboxExportOptions 邮箱导出范围，AccountID和FolderID为0时不限制
+type MboxExportOptions struct {
+	AccountID uint
+	FolderID  uint
+}
+
+// ExportMbox 将用户本地保存的未删除邮件按mboxrd格式写出，邮件内容由本地数据生成，只包含已下载的附件。
+// 返回导出的邮件数
+func ExportMbox(ctx context.Context, db *gorm.DB, storage AttachmentStorage, userID uint, opts MboxExportOptions, w io.Writer) (int, error) {
+	query := db.WithContext(ctx).
+		Preload("Attachments", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Body").
+		Where("user_id = ? AND is_deleted = ?", userID, false)
+	if opts.AccountID != 0 {
+		query = query.Where("account_id = ?", opts.AccountID)
+	}
+	if opts.FolderID != 0 {
+		query = query.Where("folder_id = ?", opts.FolderID)
+	}
+
+	out := bufio.NewWriter(w)
+	count := 0
+	var writeErr error
+	var emails []models.Email
+	result := query.Order("id ASC").FindInBatches(&emails, mboxExportBatchSize, func(tx *gorm.DB, batch int) error {
+		for i := range emails {
+			if err := writeMboxMessage(ctx, storage, out, &emails[i]); err != nil {
+				writeErr = fmt.Errorf("failed to export email %d: %w", emails[i].ID, err)
+				return writeErr
+			}
+			count++
+		}
+		return nil
+	})
+	if writeErr != nil {
+		return count, writeErr
+	}
+	if result.Error != nil {
+		return count, fmt.Errorf("failed to load emails: %w", result.Error)
+	}
+	return count, out.Flush()
+}
+
+// writeMboxMessage 写出一封邮件：From分隔行、转义后的内容和结尾空行，换行统一为LF
+func writeMboxMessage(ctx context.Context, storage AttachmentStorage, w io.Writer, email *models.Email) error {
+	var buf bytes.Buffer
+	if err := writeLocalMessage(ctx, storage, &buf, email); err != nil {
+		return err
+	}
+
+	sender := "MAILER-DAEMON"
+	if address, err := mail.ParseAddress(email.From); err == nil && address.Address != "" {
+		sender = address.Address
+	}
+	if _, err := fmt.Fprintf(w, "From %s %s\n", sender, email.Date.UTC().Format(time.ANSIC)); err != nil {
+		return err
+	}
+
+	content := strings.ReplaceAll(buf.String(), "\r\n", "\n")
+	content = strings.TrimSuffix(content, "\n")
+	for _, line := range strings.Split(content, "\n") {
+		if mboxFromLine.MatchString(line) {
+			line = ">" + line
+		}
+		if _, err := io.WriteString(w, line+"\n"); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"firemail/internal/config"
+	"firemail/internal/models"
+	"firemail/internal/providers"
+
+	"gorm.io/gorm"
+)
+
+// ErrMessageTooLarge 邮件超过发件提供商的大小上限
+var ErrMessageTooLarge = errors.New("message exceeds the provider size limit")
+
+// MessageSizeLimit 发件账户所在提供商的邮件大小上限
+type MessageSizeLimit struct {
+	Provider string `json:"provider"` // 提供商显示名称
+	Bytes    int64  `json:"bytes"`
+}
+
+// MessageTooLargeError 邮件超过大小上限时的详细信息，客户端据此提示用户需要删减的附件大小
+type MessageTooLargeError struct {
+	Size            int64  `json:"size"`             // 整封邮件编码后的大小
+	AttachmentsSize int64  `json:"attachments_size"` // 附件按base64编码后的大小合计
+	Limit           int64  `json:"limit"`
+	Provider        string `json:"provider"`
+}
+
+func (e *MessageTooLargeError) Error() string {
+	return fmt.Sprintf("%s: %s exceeds the %s limit of %s (attachments %s after encoding)",
+		ErrMessageTooLarge.Error(), formatMessageSize(e.Size), e.Provider, formatMessageSize(e.Limit), formatMessageSize(e.AttachmentsSize))
+}
+
+func (e *MessageTooLargeError) Unwrap() error {
+	return ErrMessageTooLarge
+}
+
+// AccountMessageSizeLimit 账户所在提供商的邮件大小上限，提供商未配置上限时返回 nil
+func AccountMessageSizeLimit(ctx context.Context, db *gorm.DB, accountID uint) (*MessageSizeLimit, error) {
+	var account models.EmailAccount
+	if err := db.WithContext(ctx).Select("id", "provider").First(&account, accountID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	return providerMessageSizeLimit(account.Provider), nil
+}
+
+// providerMessageSizeLimit 提供商配置中的邮件大小上限，未配置时返回 nil
+func providerMessageSizeLimit(providerName string) *MessageSizeLimit {
+	provider := config.GetProviderByName(providerName)
+	if provider == nil || provider.MessageSizeLimit() <= 0 {
+		return nil
+	}
+	return &MessageSizeLimit{Provider: provider.DisplayName, Bytes: provider.MessageSizeLimit()}
+}
+
+// checkMessageSize 检查组装后的邮件是否超过上限
+func checkMessageSize(email *ComposedEmail, limit *MessageSizeLimit) error {
+	if limit == nil || email.Size <= limit.Bytes {
+		return nil
+	}
+
+	var attachmentsSize int64
+	for _, attachment := range email.Attachments {
+		attachmentsSize += base64EncodedSize(attachment.Size, false)
+	}
+	for _, attachment := range email.InlineAttachments {
+		attachmentsSize += base64EncodedSize(attachment.Size, false)
+	}
+
+	return &MessageTooLargeError{
+		Size:            email.Size,
+		AttachmentsSize: attachmentsSize,
+		Limit:           limit.Bytes,
+		Provider:        limit.Provider,
+	}
+}
+
+// checkOutgoingMessageSize 检查直接通过SMTP发送的邮件是否超过上限。附件按SMTP客户端每行76个字符的base64编码计算，
+// 正文和邮件头按原始长度计算，估算值不会大于实际大小
+func checkOutgoingMessageSize(message *providers.OutgoingMessage, limit *MessageSizeLimit) error {
+	if limit == nil {
+		return nil
+	}
+
+	var attachmentsSize int64
+	for _, attachment := range message.Attachments {
+		attachmentsSize += base64EncodedSize(attachment.Size, true)
+	}
+	size := attachmentsSize + int64(len(message.Subject)+len(message.TextBody)+len(message.HTMLBody))
+	for name, value := range message.Headers {
+		size += int64(len(name) + len(value))
+	}
+	if size <= limit.Bytes {
+		return nil
+	}
+
+	return &MessageTooLargeError{
+		Size:            size,
+		AttachmentsSize: attachmentsSize,
+		Limit:           limit.Bytes,
+		Provider:        limit.Provider,
+	}
+}
+
+// base64EncodedSize 内容按base64编码后的大小，wrapped 为 true 时每76个字符换行
+func base64EncodedSize(size int64, wrapped bool) int64 {
+	encoded := (size + 2) / 3 * 4
+	if wrapped {
+		encoded += (encoded + 75) / 76 * 2
+	}
+	return encoded
+}
+
+// formatMessageSize 以MB为单位显示大小
+func formatMessageSize(size int64) string {
+	return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024))
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"firemail/internal/config"
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestComposerRejectsMessagesOverProviderLimit(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	composer := NewStandardEmailComposer(&EmailComposerConfig{
+		MaxAttachmentSize:     1024 * 1024,
+		MaxAttachments:        5,
+		MaxRecipientsPerEmail: 10,
+		DefaultEncoding:       "base64",
+	}, env.db)
+
+	request := func(limit *MessageSizeLimit) *ComposeEmailRequest {
+		return &ComposeEmailRequest{
+			From:     &models.EmailAddress{Address: "tester@example.com"},
+			To:       []*models.EmailAddress{{Address: "alice@example.org"}},
+			Subject:  "Quarterly report",
+			TextBody: "See attached.",
+			Attachments: []*EmailAttachment{{
+				Filename: "report.txt",
+				Data:     bytes.Repeat([]byte("a"), 3000),
+				Size:     3000,
+			}},
+			SizeLimit: limit,
+		}
+	}
+
+	// 附件按base64编码后超过上限
+	_, err := composer.ComposeEmail(context.Background(), request(&MessageSizeLimit{Provider: "Example", Bytes: 3500}))
+	require.ErrorIs(t, err, ErrMessageTooLarge)
+	var tooLarge *MessageTooLargeError
+	require.True(t, errors.As(err, &tooLarge))
+	require.Equal(t, int64(4000), tooLarge.AttachmentsSize)
+	require.Greater(t, tooLarge.Size, tooLarge.AttachmentsSize)
+	require.Equal(t, int64(3500), tooLarge.Limit)
+	require.Contains(t, err.Error(), "Example limit of")
+
+	composed, err := composer.ComposeEmail(context.Background(), request(&MessageSizeLimit{Provider: "Example", Bytes: 64 * 1024}))
+	require.NoError(t, err)
+	require.Less(t, composed.Size, int64(64*1024))
+
+	_, err = composer.ComposeEmail(context.Background(), request(nil))
+	require.NoError(t, err)
+}
+
+func TestSendEmailChecksProviderLimitBeforeConnecting(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.Model(env.account).Update("provider", "gmail").Error)
+
+	limit := config.GetProviderByName("gmail").MessageSizeLimit()
+	require.Equal(t, int64(25*1024*1024), limit)
+
+	// 原始大小低于上限，base64编码后超过上限；gmail 未注册到测试的提供商工厂，连接前就应返回
+	content := make([]byte, limit*4/5)
+	err := env.service.SendEmail(context.Background(), env.user.ID, &SendEmailRequest{
+		AccountID: env.account.ID,
+		To:        []*models.EmailAddress{{Address: "alice@example.org"}},
+		Subject:   "Large upload",
+		TextBody:  "Attached.",
+		Attachments: []*SendEmailAttachment{{
+			Filename:    "archive.bin",
+			ContentType: "application/octet-stream",
+			Content:     content,
+		}},
+	})
+	require.ErrorIs(t, err, ErrMessageTooLarge)
+
+	var tooLarge *MessageTooLargeError
+	require.True(t, errors.As(err, &tooLarge))
+	require.Equal(t, "Gmail", tooLarge.Provider)
+	require.Greater(t, tooLarge.AttachmentsSize, limit)
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"firemail/internal/models"
+	"firemail/internal/providers"
+
+	"gorm.io/gorm"
+)
+
+// mutedThreadsAvailable 静音会话表是否存在
+func mutedThreadsAvailable(db *gorm.DB) bool {
+	return db.Migrator().HasTable(&models.MutedThread{})
+}
+
+// emailThreadKey 邮件所属会话ID，未记录会话的旧邮件以自身Message-ID作为会话
+func emailThreadKey(email *models.Email) string {
+	if email.ThreadID != "" {
+		return email.ThreadID
+	}
+	return email.MessageID
+}
+
+// emailInReplyTo 取出邮件回复的Message-ID，有多个时使用第一个
+func emailInReplyTo(emailMsg *providers.EmailMessage) string {
+	values := emailMsg.Headers["In-Reply-To"]
+	if len(values) == 0 {
+		return ""
+	}
+	fields := strings.Fields(values[0])
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+// resolveEmailThreadID 根据In-Reply-To确定新邮件所属会话，
+// 被回复的邮件不在本地时以其Message-ID作为会话ID，使同一会话的其他回复归到一起
+func resolveEmailThreadID(db *gorm.DB, userID uint, messageID, inReplyTo string) string {
+	if inReplyTo == "" {
+		return messageID
+	}
+
+	var parent models.Email
+	err := db.Select("thread_id", "message_id").
+		Where("user_id = ? AND message_id = ?", userID, inReplyTo).
+		Take(&parent).Error
+	if err == nil {
+		return emailThreadKey(&parent)
+	}
+	if err != gorm.ErrRecordNotFound {
+		log.Printf("Warning: failed to resolve email thread: %v", err)
+	}
+	return inReplyTo
+}
+
+// isThreadMuted 会话是否已被用户静音
+func isThreadMuted(db *gorm.DB, userID uint, threadID string) bool {
+	if threadID == "" || !mutedThreadsAvailable(db) {
+		return false
+	}
+
+	var count int64
+	if err := db.Model(&models.MutedThread{}).
+		Where("user_id = ? AND thread_id = ?", userID, threadID).
+		Count(&count).Error; err != nil {
+		log.Printf("Warning: failed to check muted thread: %v", err)
+		return false
+	}
+	return count > 0
+}
+
+// MuteThread 静音邮件所在会话，之后收到的会话邮件自动标记为已读且不发送通知
+func (s *EmailServiceImpl) MuteThread(ctx context.Context, userID, emailID uint) (*models.MutedThread, error) {
+	email, err := s.getEmailForUser(ctx, userID, emailID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	threadID := emailThreadKey(email)
+	var muted models.MutedThread
+	err = s.db.WithContext(ctx).Where("user_id = ? AND thread_id = ?", userID, threadID).Take(&muted).Error
+	if err == nil {
+		return &muted, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to find muted thread: %w", err)
+	}
+
+	muted = models.MutedThread{UserID: userID, ThreadID: threadID, Subject: email.Subject}
+	if err := s.db.WithContext(ctx).Create(&muted).Error; err != nil {
+		return nil, fmt.Errorf("failed to mute thread: %w", err)
+	}
+	return &muted, nil
+}
+
+// UnmuteThread 取消静音邮件所在会话
+func (s *EmailServiceImpl) UnmuteThread(ctx context.Context, userID, emailID uint) error {
+	email, err := s.getEmailForUser(ctx, userID, emailID, false)
+	if err != nil {
+		return err
+	}
+
+	result := s.db.WithContext(ctx).
+		Where("user_id = ? AND thread_id = ?", userID, emailThreadKey(email)).
+		Delete(&models.MutedThread{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to unmute thread: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("thread is not muted")
+	}
+	return nil
+}
+
+// ListMutedThreads 获取用户静音的会话列表
+func (s *EmailServiceImpl) ListMutedThreads(ctx context.Context, userID uint) ([]models.MutedThread, error) {
+	var threads []models.MutedThread
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		Find(&threads).Error; err != nil {
+		return nil, fmt.Errorf("failed to load muted threads: %w", err)
+	}
+	return threads, nil
+}
+
+// DeleteMutedThread 从静音列表中移除会话
+func (s *EmailServiceImpl) DeleteMutedThread(ctx context.Context, userID, mutedThreadID uint) error {
+	result := s.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", mutedThreadID, userID).
+		Delete(&models.MutedThread{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to unmute thread: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("muted thread not found")
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+	"firemail/internal/providers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMutedThreadAutoReadsFollowUps(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.MutedThread{}))
+	ctx := context.Background()
+
+	syncService := NewSyncService(env.db, nil, nil, NewDeduplicatorFactory(env.db), nil, nil)
+	root := &providers.EmailMessage{
+		MessageID: "<root@example.com>",
+		UID:       1,
+		Subject:   "Weekly sync",
+		From:      &models.EmailAddress{Address: "team@example.com"},
+		Date:      time.Now().Add(-time.Hour),
+	}
+	require.NoError(t, syncService.saveEmailToDatabase(ctx, root, env.account.ID, env.inbox.ID, env.user.ID))
+
+	var rootEmail models.Email
+	require.NoError(t, env.db.Where("message_id = ?", root.MessageID).First(&rootEmail).Error)
+	require.Equal(t, root.MessageID, rootEmail.ThreadID)
+
+	muted, err := env.service.MuteThread(ctx, env.user.ID, rootEmail.ID)
+	require.NoError(t, err)
+	require.Equal(t, root.MessageID, muted.ThreadID)
+	require.Equal(t, "Weekly sync", muted.Subject)
+
+	// 重复静音返回已有记录
+	again, err := env.service.MuteThread(ctx, env.user.ID, rootEmail.ID)
+	require.NoError(t, err)
+	require.Equal(t, muted.ID, again.ID)
+
+	reply := &providers.EmailMessage{
+		MessageID: "<reply@example.com>",
+		UID:       2,
+		Subject:   "Re: Weekly sync",
+		From:      &models.EmailAddress{Address: "bob@example.com"},
+		Date:      time.Now(),
+		Headers:   map[string][]string{"In-Reply-To": {"<root@example.com>"}},
+	}
+	require.NoError(t, syncService.saveEmailToDatabase(ctx, reply, env.account.ID, env.inbox.ID, env.user.ID))
+	require.Contains(t, reply.Flags, "\\Seen")
+
+	var replyEmail models.Email
+	require.NoError(t, env.db.Where("message_id = ?", reply.MessageID).First(&replyEmail).Error)
+	require.Equal(t, root.MessageID, replyEmail.ThreadID)
+	require.True(t, replyEmail.IsRead)
+
+	// 回复的回复仍然归入同一会话
+	require.Equal(t, root.MessageID, resolveEmailThreadID(env.db, env.user.ID, "<third@example.com>", "<reply@example.com>"))
+
+	syncService.markMutedEmailsAsRead(ctx, env.provider.imap, env.inbox, []uint32{reply.UID})
+	require.Equal(t, [][]uint32{{2}}, env.provider.imap.markReadCalls)
+
+	threads, err := env.service.ListMutedThreads(ctx, env.user.ID)
+	require.NoError(t, err)
+	require.Len(t, threads, 1)
+
+	require.NoError(t, env.service.UnmuteThread(ctx, env.user.ID, replyEmail.ID))
+	require.EqualError(t, env.service.UnmuteThread(ctx, env.user.ID, replyEmail.ID), "thread is not muted")
+	require.EqualError(t, env.service.DeleteMutedThread(ctx, env.user.ID, muted.ID), "muted thread not found")
+
+	other := &providers.EmailMessage{
+		MessageID: "<other@example.com>",
+		UID:       3,
+		Subject:   "Re: Weekly sync",
+		From:      &models.EmailAddress{Address: "carol@example.com"},
+		Date:      time.Now(),
+		Headers:   map[string][]string{"In-Reply-To": {"<root@example.com>"}},
+	}
+	require.NoError(t, syncService.saveEmailToDatabase(ctx, other, env.account.ID, env.inbox.ID, env.user.ID))
+	require.NotContains(t, other.Flags, "\\Seen")
+}
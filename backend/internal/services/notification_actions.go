@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"firemail/internal/models"
+	"firemail/internal/sse"
+
+	"gorm.io/gorm"
+)
+
+// 通知快捷操作
+const (
+	NotificationActionArchive = "archive"
+	NotificationActionRead    = "read"
+	NotificationActionDelete  = "delete"
+)
+
+// NotificationActionPathPrefix 通知快捷操作的路径前缀，令牌紧随其后
+const NotificationActionPathPrefix = "/api/v1/notification-actions/"
+
+// notificationActionTTL 快捷操作令牌有效期，通知通常在一天内处理
+const notificationActionTTL = 24 * time.Hour
+
+// notificationActions 每条新邮件通知附带的快捷操作
+var notificationActions = []string{NotificationActionArchive, NotificationActionRead, NotificationActionDelete}
+
+// 通知快捷操作错误
+var (
+	ErrNotificationActionInvalid = errors.New("notification action token invalid or already used")
+	ErrNotificationActionExpired = errors.New("notification action token expired")
+)
+
+// NotificationActionResult 快捷操作执行结果
+type NotificationActionResult struct {
+	Action  string `json:"action"`
+	EmailID uint   `json:"email_id"`
+}
+
+// issueNotificationActions 为新邮件签发归档、标记已读、删除各一个一次性令牌，
+// 数据库只保存令牌的哈希，顺带清理该用户已过期的令牌
+func issueNotificationActions(ctx context.Context, db *gorm.DB, userID, emailID uint) ([]sse.NotificationAction, error) {
+	now := time.Now()
+	expiresAt := now.Add(notificationActionTTL).Truncate(time.Second)
+
+	actions := make([]sse.NotificationAction, 0, len(notificationActions))
+	records := make([]*models.NotificationActionToken, 0, len(notificationActions))
+	for _, action := range notificationActions {
+		buf := make([]byte, 24)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, fmt.Errorf("failed to generate notification action token: %w", err)
+		}
+		token := hex.EncodeToString(buf)
+		records = append(records, &models.NotificationActionToken{
+			UserID:    userID,
+			EmailID:   emailID,
+			Action:    action,
+			TokenHash: hashNotificationActionToken(token),
+			ExpiresAt: expiresAt,
+		})
+		actions = append(actions, sse.NotificationAction{
+			Action:    action,
+			URL:       NotificationActionPathPrefix + token,
+			ExpiresAt: expiresAt,
+		})
+	}
+
+	if err := db.WithContext(ctx).
+		Where("user_id = ? AND expires_at <= ?", userID, now).
+		Delete(&models.NotificationActionToken{}).Error; err != nil {
+		log.Printf("Warning: failed to purge expired notification action tokens: %v", err)
+	}
+	if err := db.WithContext(ctx).Create(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to save notification action tokens: %w", err)
+	}
+	return actions, nil
+}
+
+// ExecuteNotificationAction 凭通知中的令牌执行快捷操作。令牌先被原子地标记为已使用，
+// 并发或重复提交只有一次生效；同一邮件的其他令牌仍可使用
+func (s *EmailServiceImpl) ExecuteNotificationAction(ctx context.Context, token string) (*NotificationActionResult, error) {
+	if token == "" {
+		return nil, ErrNotificationActionInvalid
+	}
+
+	var record models.NotificationActionToken
+	if err := s.db.WithContext(ctx).
+		Where("token_hash = ?", hashNotificationActionToken(token)).
+		First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotificationActionInvalid
+		}
+		return nil, fmt.Errorf("failed to load notification action: %w", err)
+	}
+	if record.UsedAt != nil {
+		return nil, ErrNotificationActionInvalid
+	}
+	now := time.Now()
+	if !now.Before(record.ExpiresAt) {
+		return nil, ErrNotificationActionExpired
+	}
+	// 邮件已被删除或移走时不消耗令牌
+	if _, err := s.getEmailForUser(ctx, record.UserID, record.EmailID, false); err != nil {
+		return nil, err
+	}
+
+	result := s.db.WithContext(ctx).Model(&models.NotificationActionToken{}).
+		Where("id = ? AND used_at IS NULL", record.ID).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to consume notification action: %w", result.Error)
+	}
+	if result.RowsAffected != 1 {
+		return nil, ErrNotificationActionInvalid
+	}
+
+	var err error
+	switch record.Action {
+	case NotificationActionArchive:
+		err = s.ArchiveEmail(ctx, record.UserID, record.EmailID)
+	case NotificationActionRead:
+		err = s.MarkEmailAsRead(ctx, record.UserID, record.EmailID)
+	case NotificationActionDelete:
+		err = s.DeleteEmail(ctx, record.UserID, record.EmailID)
+	default:
+		err = ErrNotificationActionInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &NotificationActionResult{Action: record.Action, EmailID: record.EmailID}, nil
+}
+
+// hashNotificationActionToken 计算令牌的SHA-256，数据库只保存哈希
+func hashNotificationActionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
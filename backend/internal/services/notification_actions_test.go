@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+	"firemail/internal/sse"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationActionsAreSingleUse(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.NotificationActionToken{}))
+	ctx := context.Background()
+
+	email := env.createEmail(t, env.inbox, 7, "invoice", false, false)
+	publisher := &recordingEventPublisher{}
+	publishNewEmailNotification(ctx, env.db, publisher, env.account, email, env.user.ID, false, false)
+	require.Len(t, publisher.events, 1)
+
+	tokens := map[string]string{}
+	for _, action := range publisher.events[0].Data.(*sse.NewEmailEventData).Actions {
+		require.True(t, strings.HasPrefix(action.URL, NotificationActionPathPrefix))
+		tokens[action.Action] = strings.TrimPrefix(action.URL, NotificationActionPathPrefix)
+	}
+	require.Len(t, tokens, 3)
+
+	// 数据库只保存哈希
+	var stored int64
+	require.NoError(t, env.db.Model(&models.NotificationActionToken{}).Where("token_hash = ?", tokens[NotificationActionRead]).Count(&stored).Error)
+	require.Zero(t, stored)
+
+	// 并发提交同一令牌只有一次生效
+	var wg sync.WaitGroup
+	results := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := env.service.ExecuteNotificationAction(ctx, tokens[NotificationActionRead])
+			results <- err
+		}()
+	}
+	wg.Wait()
+	close(results)
+	succeeded := 0
+	for err := range results {
+		if err == nil {
+			succeeded++
+		} else {
+			require.ErrorIs(t, err, ErrNotificationActionInvalid)
+		}
+	}
+	require.Equal(t, 1, succeeded)
+	require.Equal(t, []uint32{email.UID}, flattenUIDCalls(env.provider.imap.markReadCalls))
+
+	_, err := env.service.ExecuteNotificationAction(ctx, "not-a-token")
+	require.ErrorIs(t, err, ErrNotificationActionInvalid)
+
+	require.NoError(t, env.db.Model(&models.NotificationActionToken{}).
+		Where("action = ?", NotificationActionDelete).
+		Update("expires_at", time.Now().Add(-time.Minute)).Error)
+	_, err = env.service.ExecuteNotificationAction(ctx, tokens[NotificationActionDelete])
+	require.ErrorIs(t, err, ErrNotificationActionExpired)
+
+	// 静默通知不附带快捷操作
+	publisher.events = nil
+	publishNewEmailNotification(ctx, env.db, publisher, &models.EmailAccount{NotificationsMuted: true}, email, env.user.ID, false, false)
+	require.Empty(t, publisher.events[0].Data.(*sse.NewEmailEventData).Actions)
+}
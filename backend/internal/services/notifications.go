@@ -0,0 +1,417 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"firemail/internal/i18n"
+	"firemail/internal/models"
+	"firemail/internal/sse"
+
+	"gorm.io/gorm"
+)
+
+// NotificationRetention 通知中心保留通知的时间，更早的通知由清理任务删除
+const NotificationRetention = 90 * 24 * time.Hour
+
+// 通知列表默认与最大每页条数
+const (
+	defaultNotificationsPageSize = 20
+	maxNotificationsPageSize     = 100
+)
+
+// ErrNotificationNotFound 通知不存在或不属于当前用户
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// NotificationService 通知中心：保存的通知及其已读状态
+type NotificationService interface {
+	ListNotifications(ctx context.Context, userID uint, req *ListNotificationsRequest) (*ListNotificationsResponse, error)
+	GetNotificationCounts(ctx context.Context, userID uint) (*NotificationCounts, error)
+	UpdateNotification(ctx context.Context, userID, notificationID uint, req *UpdateNotificationRequest) (*models.Notification, error)
+	MarkNotifications(ctx context.Context, userID uint, req *MarkNotificationsRequest) (*MarkNotificationsResult, error)
+	PruneNotifications(ctx context.Context, before time.Time) (int64, error)
+}
+
+// ListNotificationsRequest 通知列表查询条件，为空的条件不过滤
+type ListNotificationsRequest struct {
+	UnreadOnly bool   `json:"unread_only" form:"unread_only"`
+	Level      string `json:"level" form:"level"` // info, success, warning, error
+	AccountID  *uint  `json:"account_id" form:"account_id"`
+	Page       int    `json:"page" form:"page"`
+	PageSize   int    `json:"page_size" form:"page_size"`
+}
+
+// ListNotificationsResponse 通知列表，按时间倒序
+type ListNotificationsResponse struct {
+	Notifications []models.Notification `json:"notifications"`
+	Total         int64                 `json:"total"`
+	Page          int                   `json:"page"`
+	PageSize      int                   `json:"page_size"`
+	TotalPages    int                   `json:"total_pages"`
+	UnreadCount   int64                 `json:"unread_count"` // 全部未读通知数，不受筛选条件影响
+}
+
+// NotificationCounts 未读通知的角标计数
+type NotificationCounts struct {
+	Unread      int64            `json:"unread"`
+	ByLevel     map[string]int64 `json:"by_level"`
+	ByEventType map[string]int64 `json:"by_event_type"`
+}
+
+// UpdateNotificationRequest 修改单条通知的已读状态
+type UpdateNotificationRequest struct {
+	IsRead *bool `json:"is_read" binding:"required"`
+}
+
+// MarkNotificationsRequest 批量修改通知的已读状态，IDs为空时修改全部通知
+type MarkNotificationsRequest struct {
+	IDs    []uint `json:"ids"`
+	IsRead *bool  `json:"is_read" binding:"required"`
+}
+
+// MarkNotificationsResult 批量修改的结果
+type MarkNotificationsResult struct {
+	Updated     int64 `json:"updated"`
+	UnreadCount int64 `json:"unread_count"`
+}
+
+// NotificationServiceImpl 基于数据库的通知中心
+type NotificationServiceImpl struct {
+	db             *gorm.DB
+	eventPublisher sse.EventPublisher
+}
+
+// NewNotificationService 创建通知中心服务，已读状态变化通过 eventPublisher 同步给用户的其他客户端
+func NewNotificationService(db *gorm.DB, eventPublisher sse.EventPublisher) NotificationService {
+	return &NotificationServiceImpl{db: db, eventPublisher: eventPublisher}
+}
+
+// ListNotifications 分页列出用户的通知
+func (s *NotificationServiceImpl) ListNotifications(ctx context.Context, userID uint, req *ListNotificationsRequest) (*ListNotificationsResponse, error) {
+	if req == nil {
+		req = &ListNotificationsRequest{}
+	}
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultNotificationsPageSize
+	}
+	if pageSize > maxNotificationsPageSize {
+		pageSize = maxNotificationsPageSize
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.Notification{}).Where("user_id = ?", userID)
+	if req.UnreadOnly {
+		query = query.Where("is_read = ?", false)
+	}
+	if req.Level != "" {
+		query = query.Where("level = ?", req.Level)
+	}
+	if req.AccountID != nil {
+		query = query.Where("account_id = ?", *req.AccountID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count notifications: %w", err)
+	}
+	notifications := []models.Notification{}
+	if err := query.Order("created_at DESC, id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&notifications).Error; err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	unread, err := s.unreadCount(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListNotificationsResponse{
+		Notifications: notifications,
+		Total:         total,
+		Page:          page,
+		PageSize:      pageSize,
+		TotalPages:    int((total + int64(pageSize) - 1) / int64(pageSize)),
+		UnreadCount:   unread,
+	}, nil
+}
+
+// GetNotificationCounts 按级别和事件类型统计未读通知
+func (s *NotificationServiceImpl) GetNotificationCounts(ctx context.Context, userID uint) (*NotificationCounts, error) {
+	var rows []struct {
+		Level     string
+		EventType string
+		Count     int64
+	}
+	if err := s.db.WithContext(ctx).Model(&models.Notification{}).
+		Select("level, event_type, COUNT(*) AS count").
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Group("level, event_type").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count notifications: %w", err)
+	}
+
+	counts := &NotificationCounts{ByLevel: map[string]int64{}, ByEventType: map[string]int64{}}
+	for _, row := range rows {
+		counts.Unread += row.Count
+		counts.ByLevel[row.Level] += row.Count
+		counts.ByEventType[row.EventType] += row.Count
+	}
+	return counts, nil
+}
+
+// UpdateNotification 标记单条通知为已读或未读
+func (s *NotificationServiceImpl) UpdateNotification(ctx context.Context, userID, notificationID uint, req *UpdateNotificationRequest) (*models.Notification, error) {
+	var notification models.Notification
+	if err := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", notificationID, userID).First(&notification).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotificationNotFound
+		}
+		return nil, fmt.Errorf("failed to get notification: %w", err)
+	}
+
+	if notification.IsRead != *req.IsRead {
+		if _, err := s.setRead(ctx, userID, []uint{notification.ID}, *req.IsRead); err != nil {
+			return nil, err
+		}
+		if err := s.db.WithContext(ctx).First(&notification, notification.ID).Error; err != nil {
+			return nil, fmt.Errorf("failed to reload notification: %w", err)
+		}
+	}
+	return &notification, nil
+}
+
+// MarkNotifications 批量标记通知为已读或未读
+func (s *NotificationServiceImpl) MarkNotifications(ctx context.Context, userID uint, req *MarkNotificationsRequest) (*MarkNotificationsResult, error) {
+	updated, err := s.setRead(ctx, userID, req.IDs, *req.IsRead)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := s.unreadCount(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &MarkNotificationsResult{Updated: updated, UnreadCount: unread}, nil
+}
+
+// setRead 修改已读状态并通知用户的其他客户端更新角标，ids为空时修改全部通知
+func (s *NotificationServiceImpl) setRead(ctx context.Context, userID uint, ids []uint, isRead bool) (int64, error) {
+	var readAt *time.Time
+	if isRead {
+		now := time.Now()
+		readAt = &now
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.Notification{}).Where("user_id = ? AND is_read = ?", userID, !isRead)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	result := query.Updates(map[string]interface{}{"is_read": isRead, "read_at": readAt})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to update notifications: %w", result.Error)
+	}
+
+	if result.RowsAffected > 0 && s.eventPublisher != nil {
+		unread, err := s.unreadCount(ctx, userID)
+		if err != nil {
+			return 0, err
+		}
+		event := sse.NewNotificationReadEvent(&sse.NotificationReadEventData{NotificationIDs: ids, IsRead: isRead, UnreadCount: unread}, userID)
+		if err := s.eventPublisher.PublishToUser(ctx, userID, event); err != nil {
+			log.Printf("Failed to publish notification read event: %v", err)
+		}
+	}
+	return result.RowsAffected, nil
+}
+
+func (s *NotificationServiceImpl) unreadCount(ctx context.Context, userID uint) (int64, error) {
+	var unread int64
+	if err := s.db.WithContext(ctx).Model(&models.Notification{}).Where("user_id = ? AND is_read = ?", userID, false).Count(&unread).Error; err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return unread, nil
+}
+
+// PruneNotifications 删除 before 之前创建的通知
+func (s *NotificationServiceImpl) PruneNotifications(ctx context.Context, before time.Time) (int64, error) {
+	result := s.db.WithContext(ctx).Where("created_at < ?", before).Delete(&models.Notification{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to prune notifications: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// NotificationPublisher 在推送事件之前把需要用户关注的事件保存到通知中心，推送的事件带上通知ID。
+// 广播事件和邮件状态、同步进度等事件只推送不保存
+type NotificationPublisher struct {
+	inner sse.EventPublisher
+	db    *gorm.DB
+}
+
+// NewNotificationPublisher 包装事件发布器，多实例部署时只有产生事件的实例保存通知
+func NewNotificationPublisher(db *gorm.DB, inner sse.EventPublisher) *NotificationPublisher {
+	return &NotificationPublisher{inner: inner, db: db}
+}
+
+// Publish 发布事件
+func (p *NotificationPublisher) Publish(ctx context.Context, event *sse.Event) error {
+	p.record(ctx, event)
+	return p.inner.Publish(ctx, event)
+}
+
+// PublishToUser 发布事件给指定用户
+func (p *NotificationPublisher) PublishToUser(ctx context.Context, userID uint, event *sse.Event) error {
+	if event != nil {
+		event.UserID = userID
+		p.record(ctx, event)
+	}
+	return p.inner.PublishToUser(ctx, userID, event)
+}
+
+// PublishToAccount 发布事件给指定账户的用户
+func (p *NotificationPublisher) PublishToAccount(ctx context.Context, accountID uint, event *sse.Event) error {
+	if event != nil {
+		var userIDs []uint
+		if err := p.db.WithContext(ctx).Model(&models.EmailAccount{}).Where("id = ?", accountID).Pluck("user_id", &userIDs).Error; err == nil && len(userIDs) == 1 {
+			event.UserID = userIDs[0]
+			event.AccountID = &accountID
+			p.record(ctx, event)
+		}
+	}
+	return p.inner.PublishToAccount(ctx, accountID, event)
+}
+
+// Broadcast 广播事件给所有连接的用户
+func (p *NotificationPublisher) Broadcast(ctx context.Context, event *sse.Event) error {
+	return p.inner.Broadcast(ctx, event)
+}
+
+// record 保存事件对应的通知，失败只记录日志，不影响推送
+func (p *NotificationPublisher) record(ctx context.Context, event *sse.Event) {
+	if event == nil || event.UserID == 0 {
+		return
+	}
+	notification := p.notificationFor(ctx, event)
+	if notification == nil {
+		return
+	}
+	if err := p.db.WithContext(ctx).Create(notification).Error; err != nil {
+		log.Printf("Warning: failed to save notification for %s event: %v", event.Type, err)
+		return
+	}
+	event.NotificationID = notification.ID
+}
+
+// notificationFor 按事件类型生成通知，不需要保存的事件返回nil
+func (p *NotificationPublisher) notificationFor(ctx context.Context, event *sse.Event) *models.Notification {
+	notification := &models.Notification{
+		UserID:    event.UserID,
+		AccountID: event.AccountID,
+		EventType: string(event.Type),
+		Level:     models.NotificationLevelInfo,
+	}
+	locale := func() string { return userLocale(ctx, p.db, event.UserID) }
+
+	switch data := event.Data.(type) {
+	case *sse.NewEmailEventData:
+		// 静默的新邮件不保存；VIP发件人的邮件由单独的 vip_email 事件保存
+		if event.Type == sse.EventNewEmail && (data.Silent || data.IsVIP) {
+			return nil
+		}
+		notification.AccountID = &data.AccountID
+		if event.Type == sse.EventVIPEmail {
+			notification.Title = i18n.T(locale(), "Email from VIP sender %s", data.From)
+		} else {
+			notification.Title = i18n.T(locale(), "New email from %s", data.From)
+		}
+		notification.Message = data.Subject
+		fields := map[string]interface{}{"email_id": data.EmailID}
+		if data.FolderID != nil {
+			fields["folder_id"] = *data.FolderID
+		}
+		notification.Data = notificationData(fields)
+	case *sse.VerificationCodeEventData:
+		notification.AccountID = &data.AccountID
+		notification.Title = i18n.T(locale(), "Verification code %s", data.Code)
+		notification.Message = data.Subject
+		notification.Data = notificationData(map[string]interface{}{"email_id": data.EmailID, "code": data.Code})
+	case *sse.EmailSendEventData:
+		if event.Type != sse.EventEmailSendFailed {
+			return nil
+		}
+		notification.Level = models.NotificationLevelError
+		notification.Title = i18n.T(locale(), "Failed to send email")
+		notification.Message = data.Error
+		notification.Data = notificationData(map[string]interface{}{"send_id": data.SendID, "email_id": data.EmailID})
+	case *sse.SyncEventData:
+		if event.Type != sse.EventSyncError {
+			return nil
+		}
+		notification.AccountID = &data.AccountID
+		notification.Level = models.NotificationLevelError
+		notification.Title = i18n.T(locale(), "Sync failed for %s", data.AccountName)
+		notification.Message = data.ErrorMessage
+	case *sse.SyncConflictEventData:
+		if data.Status != models.SyncConflictStatusPending {
+			return nil
+		}
+		notification.AccountID = &data.AccountID
+		notification.Level = models.NotificationLevelWarning
+		notification.Title = i18n.T(locale(), "Sync conflict needs your decision")
+		notification.Message = i18n.T(locale(), "The email was changed both here and on the server: %s", strings.Join(data.Fields, ", "))
+		notification.Data = notificationData(map[string]interface{}{"conflict_id": data.ConflictID, "email_id": data.EmailID})
+	case *sse.MigrationEventData:
+		switch event.Type {
+		case sse.EventMigrationCompleted:
+			notification.Level = models.NotificationLevelSuccess
+			notification.Title = i18n.T(locale(), "Mailbox migration completed")
+			notification.Message = i18n.T(locale(), "Copied %d emails, %d failed", data.CopiedEmails, data.FailedEmails)
+		case sse.EventMigrationFailed:
+			notification.Level = models.NotificationLevelError
+			notification.Title = i18n.T(locale(), "Mailbox migration failed")
+			notification.Message = data.ErrorMessage
+		default:
+			return nil
+		}
+		notification.AccountID = &data.TargetAccountID
+		notification.Data = notificationData(map[string]interface{}{"migration_id": data.MigrationID})
+	case *sse.AccountEventData:
+		if event.Type != sse.EventAccountError {
+			return nil
+		}
+		notification.AccountID = &data.AccountID
+		notification.Level = models.NotificationLevelError
+		notification.Title = i18n.T(locale(), "Account %s has a connection problem", data.AccountName)
+		notification.Message = data.ErrorMessage
+	case *sse.NotificationEventData:
+		if !data.Persistent {
+			return nil
+		}
+		notification.Title = data.Title
+		notification.Message = data.Message
+		switch data.Type {
+		case models.NotificationLevelSuccess, models.NotificationLevelWarning, models.NotificationLevelError:
+			notification.Level = data.Type
+		}
+	default:
+		return nil
+	}
+
+	notification.Title = truncateRunes(notification.Title, 255)
+	return notification
+}
+
+// notificationData 将通知附带的数据序列化为JSON
+func notificationData(fields map[string]interface{}) string {
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
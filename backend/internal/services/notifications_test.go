@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+	"firemail/internal/sse"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationPublisherPersistsNotableEvents(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.Notification{}))
+	ctx := context.Background()
+
+	inner := &recordingEventPublisher{}
+	publisher := NewNotificationPublisher(env.db, inner)
+	email := env.createEmail(t, env.inbox, 1, "季度报告", false, false)
+
+	newEmail := sse.NewNewEmailEvent(email, env.user.ID)
+	require.NoError(t, publisher.PublishToUser(ctx, env.user.ID, newEmail))
+	silent := sse.NewNewEmailEvent(email, env.user.ID)
+	silent.Data.(*sse.NewEmailEventData).Silent = true
+	require.NoError(t, publisher.PublishToUser(ctx, env.user.ID, silent))
+	// 操作结果提示只推送不保存
+	require.NoError(t, publisher.PublishToUser(ctx, env.user.ID, sse.NewNotificationEvent("Folder created", "done", "success", env.user.ID)))
+	require.NoError(t, publisher.PublishToUser(ctx, env.user.ID, sse.NewPersistentNotificationEvent("Reply later reminder", "季度报告", "info", env.user.ID)))
+	syncError := sse.NewSyncEvent(sse.EventSyncError, env.account.ID, env.account.Name, env.user.ID)
+	syncError.Data.(*sse.SyncEventData).ErrorMessage = "login failed"
+	require.NoError(t, publisher.PublishToAccount(ctx, env.account.ID, syncError))
+
+	// 所有事件照常推送，保存的事件带上通知ID
+	require.Len(t, inner.events, 5)
+	require.NotZero(t, newEmail.NotificationID)
+	require.Zero(t, silent.NotificationID)
+	require.NotZero(t, syncError.NotificationID)
+
+	var notifications []models.Notification
+	require.NoError(t, env.db.Order("id").Find(&notifications).Error)
+	require.Len(t, notifications, 3)
+	require.Equal(t, string(sse.EventNewEmail), notifications[0].EventType)
+	require.Equal(t, "季度报告", notifications[0].Message)
+	require.Equal(t, env.account.ID, *notifications[0].AccountID)
+	require.Equal(t, "Reply later reminder", notifications[1].Title)
+	require.Equal(t, models.NotificationLevelError, notifications[2].Level)
+	require.Equal(t, "login failed", notifications[2].Message)
+	require.Equal(t, env.user.ID, notifications[2].UserID)
+}
+
+func TestNotificationReadState(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.Notification{}))
+	ctx := context.Background()
+
+	for _, level := range []string{models.NotificationLevelInfo, models.NotificationLevelError, models.NotificationLevelError} {
+		require.NoError(t, env.db.Create(&models.Notification{UserID: env.user.ID, EventType: "sync_error", Level: level, Title: "t"}).Error)
+	}
+	publisher := &recordingEventPublisher{}
+	service := NewNotificationService(env.db, publisher)
+
+	counts, err := service.GetNotificationCounts(ctx, env.user.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), counts.Unread)
+	require.Equal(t, int64(2), counts.ByLevel[models.NotificationLevelError])
+
+	list, err := service.ListNotifications(ctx, env.user.ID, &ListNotificationsRequest{PageSize: 2})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), list.Total)
+	require.Equal(t, 2, list.TotalPages)
+	require.Len(t, list.Notifications, 2)
+
+	read := true
+	notification, err := service.UpdateNotification(ctx, env.user.ID, list.Notifications[0].ID, &UpdateNotificationRequest{IsRead: &read})
+	require.NoError(t, err)
+	require.True(t, notification.IsRead)
+	require.NotNil(t, notification.ReadAt)
+	require.Len(t, publisher.events, 1)
+	require.Equal(t, int64(2), publisher.events[0].Data.(*sse.NotificationReadEventData).UnreadCount)
+
+	_, err = service.UpdateNotification(ctx, env.user.ID+1, list.Notifications[0].ID, &UpdateNotificationRequest{IsRead: &read})
+	require.ErrorIs(t, err, ErrNotificationNotFound)
+
+	result, err := service.MarkNotifications(ctx, env.user.ID, &MarkNotificationsRequest{IsRead: &read})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), result.Updated)
+	require.Zero(t, result.UnreadCount)
+
+	unread, err := service.ListNotifications(ctx, env.user.ID, &ListNotificationsRequest{UnreadOnly: true})
+	require.NoError(t, err)
+	require.Empty(t, unread.Notifications)
+
+	pruned, err := service.PruneNotifications(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, int64(3), pruned)
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrVersionConflict 客户端提供的版本号已过期，记录在此期间被其他请求修改
+var ErrVersionConflict = errors.New("version conflict")
+
+// VersionConflictError 版本冲突，携带记录的最新状态，接口以409返回给客户端重新合并
+type VersionConflictError struct {
+	Current interface{}
+}
+
+func (e *VersionConflictError) Error() string {
+	return "the record was modified by another request, reload and retry"
+}
+
+func (e *VersionConflictError) Is(target error) bool {
+	return target == ErrVersionConflict
+}
+
+// expectedVersionKey 请求上下文中客户端读取到的版本号
+type expectedVersionKey struct{}
+
+// WithExpectedVersion 为请求附带客户端读取到的版本号（If-Match），更新时要求记录仍是该版本
+func WithExpectedVersion(ctx context.Context, version uint) context.Context {
+	return context.WithValue(ctx, expectedVersionKey{}, version)
+}
+
+// expectedVersion 请求附带的版本号，未附带时不做版本检查
+func expectedVersion(ctx context.Context) (uint, bool) {
+	version, ok := ctx.Value(expectedVersionKey{}).(uint)
+	return version, ok
+}
+
+// checkEmailVersion 在连接服务器修改之前检查邮件版本，避免用过期的状态覆盖其他客户端的修改
+func (s *EmailServiceImpl) checkEmailVersion(ctx context.Context, email *models.Email) error {
+	if version, ok := expectedVersion(ctx); ok && version != email.Version {
+		return s.emailVersionConflict(ctx, email.ID)
+	}
+	return nil
+}
+
+func (s *EmailServiceImpl) emailVersionConflict(ctx context.Context, emailID uint) error {
+	var current models.Email
+	if err := s.db.WithContext(ctx).First(&current, emailID).Error; err != nil {
+		return fmt.Errorf("failed to reload email: %w", err)
+	}
+	return &VersionConflictError{Current: &current}
+}
+
+// updateEmailColumns 只更新指定的列并递增版本号，整行保存会覆盖其他请求同时修改的字段。
+// 请求附带了版本号时以版本号为条件，版本已变化时返回 VersionConflictError
+func (s *EmailServiceImpl) updateEmailColumns(ctx context.Context, db *gorm.DB, email *models.Email, columns map[string]interface{}) error {
+	updates := make(map[string]interface{}, len(columns)+1)
+	for column, value := range columns {
+		updates[column] = value
+	}
+	updates["version"] = gorm.Expr("version + 1")
+
+	query := db.WithContext(ctx).Model(&models.Email{}).Where("id = ?", email.ID)
+	version, versioned := expectedVersion(ctx)
+	if versioned {
+		query = query.Where("version = ?", version)
+	}
+	result := query.Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 && versioned {
+		return s.emailVersionConflict(ctx, email.ID)
+	}
+
+	return db.WithContext(ctx).Model(&models.Email{}).Where("id = ?", email.ID).Pluck("version", &email.Version).Error
+}
+
+// syncedEmailStateColumns 同步时根据服务器更新的邮件列
+func syncedEmailStateColumns(email *models.Email) map[string]interface{} {
+	return map[string]interface{}{
+		"folder_id":              email.FolderID,
+		"uid":                    email.UID,
+		"message_id":             email.MessageID,
+		"is_read":                email.IsRead,
+		"is_starred":             email.IsStarred,
+		"is_draft":               email.IsDraft,
+		"is_deleted":             email.IsDeleted,
+		"trashed_at":             email.TrashedAt,
+		"labels":                 email.Labels,
+		"flag_color":             email.FlagColor,
+		"is_follow_up":           email.IsFollowUp,
+		"follow_up_at":           email.FollowUpAt,
+		"follow_up_completed_at": email.FollowUpCompletedAt,
+		"is_answered":            email.IsAnswered,
+		"is_forwarded":           email.IsForwarded,
+	}
+}
+
+// saveSyncedEmailState 只写入同步得到的状态列中发生变化的列并递增版本号，没有变化时不写入。
+// 整行保存会覆盖用户同时修改的其他字段，且不改变版本号，附带版本号的请求无法发现同步造成的修改
+func saveSyncedEmailState(db *gorm.DB, before, email *models.Email) error {
+	previous := syncedEmailStateColumns(before)
+	updates := make(map[string]interface{})
+	for column, value := range syncedEmailStateColumns(email) {
+		if !reflect.DeepEqual(value, previous[column]) {
+			updates[column] = value
+		}
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	updates["version"] = gorm.Expr("version + 1")
+
+	if err := db.Model(&models.Email{}).Where("id = ?", email.ID).Updates(updates).Error; err != nil {
+		return err
+	}
+	return db.Model(&models.Email{}).Where("id = ?", email.ID).Pluck("version", &email.Version).Error
+}
+
+// saveEmailAccount 保存账户的全部字段，以读取时的版本号为条件并递增版本号；
+// 请求附带的版本号或读取后账户被其他请求修改时返回 VersionConflictError
+func (s *EmailServiceImpl) saveEmailAccount(ctx context.Context, account *models.EmailAccount, expected *uint) error {
+	loaded := account.Version
+	if expected == nil {
+		if version, ok := expectedVersion(ctx); ok {
+			expected = &version
+		}
+	}
+	if expected != nil && *expected != loaded {
+		return s.accountVersionConflict(ctx, account.ID)
+	}
+
+	account.Version = loaded + 1
+	result := s.db.WithContext(ctx).Model(&models.EmailAccount{}).
+		Where("id = ? AND version = ?", account.ID, loaded).
+		Select("*").Omit("id", "created_at", clause.Associations).
+		Updates(account)
+	if result.Error != nil {
+		account.Version = loaded
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		account.Version = loaded
+		return s.accountVersionConflict(ctx, account.ID)
+	}
+	return nil
+}
+
+func (s *EmailServiceImpl) accountVersionConflict(ctx context.Context, accountID uint) error {
+	var current models.EmailAccount
+	if err := s.db.WithContext(ctx).First(&current, accountID).Error; err != nil {
+		return fmt.Errorf("failed to reload email account: %w", err)
+	}
+	return &VersionConflictError{Current: &current}
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailUpdateRejectsStaleVersion(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+
+	email := env.createEmail(t, env.inbox, 1, "versioned", false, false)
+	require.Equal(t, uint(1), email.Version)
+
+	// 未附带版本号时不检查，版本号照常递增
+	require.NoError(t, env.service.MarkEmailAsStarred(ctx, env.user.ID, email.ID))
+	var stored models.Email
+	require.NoError(t, env.db.First(&stored, email.ID).Error)
+	require.Equal(t, uint(2), stored.Version)
+
+	// 另一个客户端仍持有版本1
+	err := env.service.MarkEmailAsUnstarred(WithExpectedVersion(ctx, 1), env.user.ID, email.ID)
+	require.True(t, errors.Is(err, ErrVersionConflict))
+	var conflict *VersionConflictError
+	require.True(t, errors.As(err, &conflict))
+	current, ok := conflict.Current.(*models.Email)
+	require.True(t, ok)
+	require.Equal(t, uint(2), current.Version)
+	require.True(t, current.IsStarred)
+
+	require.NoError(t, env.db.First(&stored, email.ID).Error)
+	require.True(t, stored.IsStarred)
+	require.Equal(t, uint(2), stored.Version)
+
+	require.NoError(t, env.service.MarkEmailAsUnstarred(WithExpectedVersion(ctx, 2), env.user.ID, email.ID))
+	require.NoError(t, env.db.First(&stored, email.ID).Error)
+	require.False(t, stored.IsStarred)
+	require.Equal(t, uint(3), stored.Version)
+}
+
+func TestUpdateEmailAccountRejectsStaleVersion(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+
+	name := "改名后"
+	account, err := env.service.UpdateEmailAccount(ctx, env.user.ID, env.account.ID, &UpdateEmailAccountRequest{Name: &name})
+	require.NoError(t, err)
+	require.Equal(t, uint(2), account.Version)
+
+	stale := uint(1)
+	other := "过期的修改"
+	_, err = env.service.UpdateEmailAccount(ctx, env.user.ID, env.account.ID, &UpdateEmailAccountRequest{Name: &other, Version: &stale})
+	var conflict *VersionConflictError
+	require.True(t, errors.As(err, &conflict))
+	require.Equal(t, name, conflict.Current.(*models.EmailAccount).Name)
+
+	var stored models.EmailAccount
+	require.NoError(t, env.db.First(&stored, env.account.ID).Error)
+	require.Equal(t, name, stored.Name)
+	require.Equal(t, uint(2), stored.Version)
+}
@@ -0,0 +1,1061 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"firemail/internal/i18n"
+	"firemail/internal/models"
+	"firemail/internal/sse"
+
+	"gorm.io/gorm"
+)
+
+// 组织邀请的有效期
+const organizationInviteTTL = 7 * 24 * time.Hour
+
+var (
+	// ErrOrganizationNotFound 组织不存在或不是成员
+	ErrOrganizationNotFound = errors.New("organization not found")
+	// ErrOrganizationForbidden 组织角色不足
+	ErrOrganizationForbidden = errors.New("insufficient organization permissions")
+	// ErrInvalidOrganizationRequest 组织请求参数无效
+	ErrInvalidOrganizationRequest = errors.New("invalid organization request")
+	// ErrOrganizationConflict 成员、邀请或共享已存在，或邀请已失效
+	ErrOrganizationConflict = errors.New("organization state conflict")
+	// ErrOrganizationInviteNotFound 邀请不存在或不属于当前用户
+	ErrOrganizationInviteNotFound = errors.New("organization invite not found")
+	// ErrSharedMailboxNotFound 共享邮箱不存在或无权访问
+	ErrSharedMailboxNotFound = errors.New("shared mailbox not found")
+	// ErrSharedEmailNotFound 邮件不在共享邮箱中
+	ErrSharedEmailNotFound = errors.New("email not found")
+	// ErrEmailAlreadyAssigned 邮件已被其他成员认领
+	ErrEmailAlreadyAssigned = errors.New("email is already assigned")
+)
+
+// OrganizationService 组织与共享邮箱服务接口。
+// 共享邮箱仍属于共享者，成员通过授权访问：read_only 可以查看、认领和分配邮件，send_as 另外可以用该账户发信
+type OrganizationService interface {
+	// ListOrganizations 列出用户加入的组织
+	ListOrganizations(ctx context.Context, userID uint) ([]OrganizationSummary, error)
+
+	// CreateOrganization 创建组织，创建者成为所有者
+	CreateOrganization(ctx context.Context, userID uint, req *CreateOrganizationRequest) (*models.Organization, error)
+
+	// GetOrganization 获取组织的成员和共享邮箱，管理员还能看到待处理的邀请和授权
+	GetOrganization(ctx context.Context, userID, orgID uint) (*OrganizationDetail, error)
+
+	// DeleteOrganization 删除组织及其共享、授权和认领，仅所有者可以删除
+	DeleteOrganization(ctx context.Context, userID, orgID uint) error
+
+	// InviteMember 按用户名邀请成员
+	InviteMember(ctx context.Context, userID, orgID uint, req *InviteOrganizationMemberRequest) (*models.OrganizationInvite, error)
+
+	// RevokeInvite 撤销待处理的邀请
+	RevokeInvite(ctx context.Context, userID, orgID, inviteID uint) error
+
+	// ListInvites 列出用户收到的待处理邀请
+	ListInvites(ctx context.Context, userID uint) ([]models.OrganizationInvite, error)
+
+	// RespondToInvite 接受或拒绝邀请
+	RespondToInvite(ctx context.Context, userID, inviteID uint, accept bool) (*models.OrganizationInvite, error)
+
+	// UpdateMemberRole 修改成员角色，所有者的角色不能修改
+	UpdateMemberRole(ctx context.Context, userID, orgID, memberUserID uint, role string) (*models.OrganizationMember, error)
+
+	// RemoveMember 移除成员或退出组织，同时撤销其授权并释放其认领的邮件
+	RemoveMember(ctx context.Context, userID, orgID, memberUserID uint) error
+
+	// ShareMailbox 将自己的邮箱账户共享到组织，需要是组织管理员
+	ShareMailbox(ctx context.Context, userID, orgID, accountID uint) (*models.SharedMailbox, error)
+
+	// UnshareMailbox 取消共享，账户所有者或组织管理员可以操作
+	UnshareMailbox(ctx context.Context, userID, orgID, mailboxID uint) error
+
+	// SetMailboxGrant 授予或修改成员对共享邮箱的权限
+	SetMailboxGrant(ctx context.Context, userID, orgID, mailboxID uint, req *SharedMailboxGrantRequest) (*models.SharedMailboxGrant, error)
+
+	// RevokeMailboxGrant 撤销成员对共享邮箱的权限，并释放其认领的邮件
+	RevokeMailboxGrant(ctx context.Context, userID, orgID, mailboxID, memberUserID uint) error
+
+	// ListSharedMailboxes 列出用户可以访问的共享邮箱
+	ListSharedMailboxes(ctx context.Context, userID uint) ([]SharedMailboxInfo, error)
+
+	// ListSharedEmails 分页列出共享邮箱中的邮件及认领状态
+	ListSharedEmails(ctx context.Context, userID, mailboxID uint, req *ListSharedEmailsRequest) (*ListSharedEmailsResponse, error)
+
+	// GetSharedEmail 获取共享邮箱中的邮件，不改变所有者的已读状态
+	GetSharedEmail(ctx context.Context, userID, mailboxID, emailID uint) (*SharedMailboxEmail, error)
+
+	// ClaimEmail 认领邮件，已被他人认领时返回 ErrEmailAlreadyAssigned
+	ClaimEmail(ctx context.Context, userID, mailboxID, emailID uint) (*models.EmailAssignment, error)
+
+	// AssignEmail 将邮件分配给有权限的成员，需要是组织管理员
+	AssignEmail(ctx context.Context, userID, mailboxID, emailID, assigneeID uint) (*models.EmailAssignment, error)
+
+	// ReleaseEmail 释放认领，负责人或组织管理员可以操作
+	ReleaseEmail(ctx context.Context, userID, mailboxID, emailID uint) error
+
+	// CanSendAs 用户是否通过共享邮箱的 send_as 权限可以使用该账户发信
+	CanSendAs(ctx context.Context, userID, accountID uint) (bool, error)
+}
+
+// CreateOrganizationRequest 创建组织的请求
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// InviteOrganizationMemberRequest 邀请成员的请求
+type InviteOrganizationMemberRequest struct {
+	Username string `json:"username" binding:"required"`
+	Role     string `json:"role,omitempty"` // admin 或 member，默认 member
+}
+
+// UpdateOrganizationMemberRequest 修改成员角色的请求
+type UpdateOrganizationMemberRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// ShareMailboxRequest 共享邮箱账户的请求
+type ShareMailboxRequest struct {
+	AccountID uint `json:"account_id" binding:"required"`
+}
+
+// SharedMailboxGrantRequest 授予共享邮箱权限的请求
+type SharedMailboxGrantRequest struct {
+	UserID     uint   `json:"user_id" binding:"required"`
+	Permission string `json:"permission" binding:"required"` // read_only 或 send_as
+}
+
+// AssignEmailRequest 分配邮件的请求
+type AssignEmailRequest struct {
+	AssigneeID uint `json:"assignee_id" binding:"required"`
+}
+
+// ListSharedEmailsRequest 共享邮箱邮件列表的请求
+type ListSharedEmailsRequest struct {
+	FolderID   uint   `form:"folder_id"`
+	Assignment string `form:"assignment"` // unassigned、mine、assigned，为空时不过滤
+	Page       int    `form:"page"`
+	PageSize   int    `form:"page_size"`
+}
+
+// ListSharedEmailsResponse 共享邮箱邮件列表
+type ListSharedEmailsResponse struct {
+	Emails     []SharedMailboxEmail `json:"emails"`
+	Total      int64                `json:"total"`
+	Page       int                  `json:"page"`
+	PageSize   int                  `json:"page_size"`
+	TotalPages int                  `json:"total_pages"`
+}
+
+// SharedMailboxEmail 共享邮箱中的邮件及其认领
+type SharedMailboxEmail struct {
+	*models.Email
+	Assignment *models.EmailAssignment `json:"assignment,omitempty"`
+}
+
+// OrganizationSummary 用户加入的组织
+type OrganizationSummary struct {
+	models.Organization
+	Role        string `json:"role"`
+	MemberCount int64  `json:"member_count"`
+}
+
+// OrganizationDetail 组织详情
+type OrganizationDetail struct {
+	models.Organization
+	Role      string                      `json:"role"`
+	Members   []OrganizationMemberInfo    `json:"members"`
+	Mailboxes []SharedMailboxInfo         `json:"mailboxes"`
+	Invites   []models.OrganizationInvite `json:"invites,omitempty"` // 仅管理员可见
+}
+
+// OrganizationMemberInfo 组织成员
+type OrganizationMemberInfo struct {
+	UserID      uint      `json:"user_id"`
+	Username    string    `json:"username"`
+	DisplayName string    `json:"display_name"`
+	Role        string    `json:"role"`
+	JoinedAt    time.Time `json:"joined_at"`
+}
+
+// SharedMailboxInfo 共享邮箱，只包含账户的展示信息
+type SharedMailboxInfo struct {
+	ID             uint                        `json:"id"`
+	OrganizationID uint                        `json:"organization_id"`
+	AccountID      uint                        `json:"account_id"`
+	AccountName    string                      `json:"account_name"`
+	Email          string                      `json:"email"`
+	SharedBy       uint                        `json:"shared_by"`
+	Permission     string                      `json:"permission,omitempty"` // 当前用户的权限，账户所有者为 send_as
+	Grants         []models.SharedMailboxGrant `json:"grants,omitempty"`     // 仅管理员可见
+	CreatedAt      time.Time                   `json:"created_at"`
+}
+
+// OrganizationServiceImpl 组织服务实现
+type OrganizationServiceImpl struct {
+	db             *gorm.DB
+	eventPublisher sse.EventPublisher
+}
+
+// NewOrganizationService 创建组织服务
+func NewOrganizationService(db *gorm.DB, eventPublisher sse.EventPublisher) OrganizationService {
+	return &OrganizationServiceImpl{
+		db:             db,
+		eventPublisher: eventPublisher,
+	}
+}
+
+// ListOrganizations 列出用户加入的组织
+func (s *OrganizationServiceImpl) ListOrganizations(ctx context.Context, userID uint) ([]OrganizationSummary, error) {
+	var summaries []OrganizationSummary
+	if err := s.db.WithContext(ctx).Table("organizations").
+		Select("organizations.*, organization_members.role AS role, "+
+			"(SELECT COUNT(*) FROM organization_members m WHERE m.organization_id = organizations.id) AS member_count").
+		Joins("JOIN organization_members ON organization_members.organization_id = organizations.id").
+		Where("organization_members.user_id = ?", userID).
+		Order("organizations.name ASC").
+		Scan(&summaries).Error; err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	if summaries == nil {
+		summaries = []OrganizationSummary{}
+	}
+	return summaries, nil
+}
+
+// CreateOrganization 创建组织
+func (s *OrganizationServiceImpl) CreateOrganization(ctx context.Context, userID uint, req *CreateOrganizationRequest) (*models.Organization, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidOrganizationRequest)
+	}
+
+	org := &models.Organization{Name: name, CreatedBy: userID}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(org).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.OrganizationMember{
+			OrganizationID: org.ID,
+			UserID:         userID,
+			Role:           models.OrganizationRoleOwner,
+		}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+	return org, nil
+}
+
+// GetOrganization 获取组织详情
+func (s *OrganizationServiceImpl) GetOrganization(ctx context.Context, userID, orgID uint) (*OrganizationDetail, error) {
+	membership, err := s.membership(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var org models.Organization
+	if err := s.db.WithContext(ctx).First(&org, orgID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+	detail := &OrganizationDetail{Organization: org, Role: membership.Role}
+
+	if err := s.db.WithContext(ctx).Table("organization_members").
+		Select("organization_members.user_id, users.username, users.display_name, organization_members.role, organization_members.created_at AS joined_at").
+		Joins("JOIN users ON users.id = organization_members.user_id").
+		Where("organization_members.organization_id = ?", orgID).
+		Order("organization_members.created_at ASC").
+		Scan(&detail.Members).Error; err != nil {
+		return nil, fmt.Errorf("failed to list organization members: %w", err)
+	}
+
+	var mailboxes []models.SharedMailbox
+	query := s.db.WithContext(ctx).Preload("Account").Where("organization_id = ?", orgID).Order("created_at ASC")
+	if membership.IsAdmin() {
+		query = query.Preload("Grants")
+	}
+	if err := query.Find(&mailboxes).Error; err != nil {
+		return nil, fmt.Errorf("failed to list shared mailboxes: %w", err)
+	}
+	detail.Mailboxes = make([]SharedMailboxInfo, 0, len(mailboxes))
+	for i := range mailboxes {
+		detail.Mailboxes = append(detail.Mailboxes, sharedMailboxInfo(&mailboxes[i], ""))
+	}
+
+	if membership.IsAdmin() {
+		if err := s.db.WithContext(ctx).Preload("Invitee").
+			Where("organization_id = ? AND status = ? AND expires_at > ?", orgID, models.OrganizationInvitePending, time.Now()).
+			Order("created_at DESC").
+			Find(&detail.Invites).Error; err != nil {
+			return nil, fmt.Errorf("failed to list organization invites: %w", err)
+		}
+	}
+	if detail.Members == nil {
+		detail.Members = []OrganizationMemberInfo{}
+	}
+	return detail, nil
+}
+
+// DeleteOrganization 删除组织
+func (s *OrganizationServiceImpl) DeleteOrganization(ctx context.Context, userID, orgID uint) error {
+	membership, err := s.membership(ctx, orgID, userID)
+	if err != nil {
+		return err
+	}
+	if membership.Role != models.OrganizationRoleOwner {
+		return fmt.Errorf("%w: only the owner can delete the organization", ErrOrganizationForbidden)
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		mailboxIDs := tx.Model(&models.SharedMailbox{}).Select("id").Where("organization_id = ?", orgID)
+		if err := tx.Where("shared_mailbox_id IN (?)", mailboxIDs).Delete(&models.EmailAssignment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("shared_mailbox_id IN (?)", mailboxIDs).Delete(&models.SharedMailboxGrant{}).Error; err != nil {
+			return err
+		}
+		for _, model := range []interface{}{&models.SharedMailbox{}, &models.OrganizationInvite{}, &models.OrganizationMember{}} {
+			if err := tx.Where("organization_id = ?", orgID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&models.Organization{}, orgID).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete organization: %w", err)
+	}
+	return nil
+}
+
+// InviteMember 邀请成员
+func (s *OrganizationServiceImpl) InviteMember(ctx context.Context, userID, orgID uint, req *InviteOrganizationMemberRequest) (*models.OrganizationInvite, error) {
+	if _, err := s.requireAdmin(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	role := req.Role
+	if role == "" {
+		role = models.OrganizationRoleMember
+	}
+	if role != models.OrganizationRoleAdmin && role != models.OrganizationRoleMember {
+		return nil, fmt.Errorf("%w: role must be admin or member", ErrInvalidOrganizationRequest)
+	}
+
+	var invitee models.User
+	if err := s.db.WithContext(ctx).Where("username = ? AND is_active = ?", strings.TrimSpace(req.Username), true).First(&invitee).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: user %q not found", ErrInvalidOrganizationRequest, req.Username)
+		}
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.OrganizationMember{}).
+		Where("organization_id = ? AND user_id = ?", orgID, invitee.ID).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to check membership: %w", err)
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("%w: user is already a member", ErrOrganizationConflict)
+	}
+	if err := s.db.WithContext(ctx).Model(&models.OrganizationInvite{}).
+		Where("organization_id = ? AND invitee_id = ? AND status = ? AND expires_at > ?", orgID, invitee.ID, models.OrganizationInvitePending, time.Now()).
+		Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to check invites: %w", err)
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("%w: user already has a pending invite", ErrOrganizationConflict)
+	}
+
+	invite := &models.OrganizationInvite{
+		OrganizationID: orgID,
+		InviteeID:      invitee.ID,
+		InvitedBy:      userID,
+		Role:           role,
+		Status:         models.OrganizationInvitePending,
+		ExpiresAt:      time.Now().Add(organizationInviteTTL),
+	}
+	if err := s.db.WithContext(ctx).Create(invite).Error; err != nil {
+		return nil, fmt.Errorf("failed to create invite: %w", err)
+	}
+	invite.Invitee = &invitee
+
+	var org models.Organization
+	if err := s.db.WithContext(ctx).First(&org, orgID).Error; err == nil {
+		s.notify(ctx, invitee.ID, "Organization invite", "You have been invited to join organization \"%s\"", org.Name)
+	}
+	return invite, nil
+}
+
+// RevokeInvite 撤销邀请
+func (s *OrganizationServiceImpl) RevokeInvite(ctx context.Context, userID, orgID, inviteID uint) error {
+	if _, err := s.requireAdmin(ctx, orgID, userID); err != nil {
+		return err
+	}
+
+	result := s.db.WithContext(ctx).Model(&models.OrganizationInvite{}).
+		Where("id = ? AND organization_id = ? AND status = ?", inviteID, orgID, models.OrganizationInvitePending).
+		Updates(map[string]interface{}{"status": models.OrganizationInviteRevoked, "responded_at": time.Now()})
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke invite: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrOrganizationInviteNotFound
+	}
+	return nil
+}
+
+// ListInvites 列出待处理的邀请
+func (s *OrganizationServiceImpl) ListInvites(ctx context.Context, userID uint) ([]models.OrganizationInvite, error) {
+	var invites []models.OrganizationInvite
+	if err := s.db.WithContext(ctx).Preload("Organization").
+		Where("invitee_id = ? AND status = ? AND expires_at > ?", userID, models.OrganizationInvitePending, time.Now()).
+		Order("created_at DESC").
+		Find(&invites).Error; err != nil {
+		return nil, fmt.Errorf("failed to list invites: %w", err)
+	}
+	return invites, nil
+}
+
+// RespondToInvite 接受或拒绝邀请
+func (s *OrganizationServiceImpl) RespondToInvite(ctx context.Context, userID, inviteID uint, accept bool) (*models.OrganizationInvite, error) {
+	var invite models.OrganizationInvite
+	if err := s.db.WithContext(ctx).Preload("Organization").
+		Where("id = ? AND invitee_id = ?", inviteID, userID).First(&invite).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrganizationInviteNotFound
+		}
+		return nil, fmt.Errorf("failed to get invite: %w", err)
+	}
+	if invite.Status != models.OrganizationInvitePending {
+		return nil, fmt.Errorf("%w: invite is already %s", ErrOrganizationConflict, invite.Status)
+	}
+	if !invite.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: invite has expired", ErrOrganizationConflict)
+	}
+
+	now := time.Now()
+	invite.RespondedAt = &now
+	invite.Status = models.OrganizationInviteDeclined
+	if accept {
+		invite.Status = models.OrganizationInviteAccepted
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&invite).Updates(map[string]interface{}{"status": invite.Status, "responded_at": now}).Error; err != nil {
+			return err
+		}
+		if !accept {
+			return nil
+		}
+		err := tx.Create(&models.OrganizationMember{
+			OrganizationID: invite.OrganizationID,
+			UserID:         userID,
+			Role:           invite.Role,
+		}).Error
+		if isUniqueConstraintError(err) {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to respond to invite: %w", err)
+	}
+	return &invite, nil
+}
+
+// UpdateMemberRole 修改成员角色
+func (s *OrganizationServiceImpl) UpdateMemberRole(ctx context.Context, userID, orgID, memberUserID uint, role string) (*models.OrganizationMember, error) {
+	actor, err := s.requireAdmin(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role != models.OrganizationRoleAdmin && role != models.OrganizationRoleMember {
+		return nil, fmt.Errorf("%w: role must be admin or member", ErrInvalidOrganizationRequest)
+	}
+
+	member, err := s.membership(ctx, orgID, memberUserID)
+	if err != nil {
+		if errors.Is(err, ErrOrganizationNotFound) {
+			return nil, fmt.Errorf("%w: user is not a member", ErrInvalidOrganizationRequest)
+		}
+		return nil, err
+	}
+	if member.Role == models.OrganizationRoleOwner {
+		return nil, fmt.Errorf("%w: the owner's role cannot be changed", ErrOrganizationForbidden)
+	}
+	// 管理员之间的角色调整只能由所有者进行
+	if actor.Role != models.OrganizationRoleOwner && (member.Role == models.OrganizationRoleAdmin || role == models.OrganizationRoleAdmin) {
+		return nil, fmt.Errorf("%w: only the owner can manage admins", ErrOrganizationForbidden)
+	}
+
+	if err := s.db.WithContext(ctx).Model(member).Update("role", role).Error; err != nil {
+		return nil, fmt.Errorf("failed to update member role: %w", err)
+	}
+	member.Role = role
+	return member, nil
+}
+
+// RemoveMember 移除成员或退出组织
+func (s *OrganizationServiceImpl) RemoveMember(ctx context.Context, userID, orgID, memberUserID uint) error {
+	actor, err := s.membership(ctx, orgID, userID)
+	if err != nil {
+		return err
+	}
+
+	member := actor
+	if memberUserID != userID {
+		if !actor.IsAdmin() {
+			return ErrOrganizationForbidden
+		}
+		member, err = s.membership(ctx, orgID, memberUserID)
+		if err != nil {
+			if errors.Is(err, ErrOrganizationNotFound) {
+				return fmt.Errorf("%w: user is not a member", ErrInvalidOrganizationRequest)
+			}
+			return err
+		}
+		if member.Role == models.OrganizationRoleAdmin && actor.Role != models.OrganizationRoleOwner {
+			return fmt.Errorf("%w: only the owner can remove admins", ErrOrganizationForbidden)
+		}
+	}
+	if member.Role == models.OrganizationRoleOwner {
+		return fmt.Errorf("%w: the owner cannot leave the organization, delete it instead", ErrOrganizationForbidden)
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		mailboxIDs := tx.Model(&models.SharedMailbox{}).Select("id").Where("organization_id = ?", orgID)
+		if err := tx.Where("shared_mailbox_id IN (?) AND assignee_id = ?", mailboxIDs, memberUserID).Delete(&models.EmailAssignment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("shared_mailbox_id IN (?) AND user_id = ?", mailboxIDs, memberUserID).Delete(&models.SharedMailboxGrant{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(member).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+	return nil
+}
+
+// ShareMailbox 共享邮箱账户
+func (s *OrganizationServiceImpl) ShareMailbox(ctx context.Context, userID, orgID, accountID uint) (*models.SharedMailbox, error) {
+	if _, err := s.requireAdmin(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	var account models.EmailAccount
+	if err := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", accountID, userID).First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: account not found", ErrInvalidOrganizationRequest)
+		}
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+
+	mailbox := &models.SharedMailbox{OrganizationID: orgID, AccountID: account.ID, SharedBy: userID}
+	if err := s.db.WithContext(ctx).Create(mailbox).Error; err != nil {
+		if isUniqueConstraintError(err) {
+			return nil, fmt.Errorf("%w: account is already shared", ErrOrganizationConflict)
+		}
+		return nil, fmt.Errorf("failed to share mailbox: %w", err)
+	}
+	mailbox.Account = &account
+	return mailbox, nil
+}
+
+// UnshareMailbox 取消共享
+func (s *OrganizationServiceImpl) UnshareMailbox(ctx context.Context, userID, orgID, mailboxID uint) error {
+	membership, err := s.membership(ctx, orgID, userID)
+	if err != nil {
+		return err
+	}
+	mailbox, err := s.orgMailbox(ctx, orgID, mailboxID)
+	if err != nil {
+		return err
+	}
+	if !membership.IsAdmin() && mailbox.SharedBy != userID {
+		return ErrOrganizationForbidden
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("shared_mailbox_id = ?", mailbox.ID).Delete(&models.EmailAssignment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("shared_mailbox_id = ?", mailbox.ID).Delete(&models.SharedMailboxGrant{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(mailbox).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unshare mailbox: %w", err)
+	}
+	return nil
+}
+
+// SetMailboxGrant 授予或修改权限
+func (s *OrganizationServiceImpl) SetMailboxGrant(ctx context.Context, userID, orgID, mailboxID uint, req *SharedMailboxGrantRequest) (*models.SharedMailboxGrant, error) {
+	if _, err := s.requireAdmin(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	if req.Permission != models.SharedMailboxReadOnly && req.Permission != models.SharedMailboxSendAs {
+		return nil, fmt.Errorf("%w: permission must be read_only or send_as", ErrInvalidOrganizationRequest)
+	}
+	mailbox, err := s.orgMailbox(ctx, orgID, mailboxID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.membership(ctx, orgID, req.UserID); err != nil {
+		if errors.Is(err, ErrOrganizationNotFound) {
+			return nil, fmt.Errorf("%w: user is not a member", ErrInvalidOrganizationRequest)
+		}
+		return nil, err
+	}
+
+	var grant models.SharedMailboxGrant
+	err = s.db.WithContext(ctx).Where("shared_mailbox_id = ? AND user_id = ?", mailbox.ID, req.UserID).First(&grant).Error
+	switch {
+	case err == nil:
+		if err := s.db.WithContext(ctx).Model(&grant).Update("permission", req.Permission).Error; err != nil {
+			return nil, fmt.Errorf("failed to update grant: %w", err)
+		}
+		grant.Permission = req.Permission
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		grant = models.SharedMailboxGrant{SharedMailboxID: mailbox.ID, UserID: req.UserID, Permission: req.Permission}
+		if err := s.db.WithContext(ctx).Create(&grant).Error; err != nil {
+			return nil, fmt.Errorf("failed to create grant: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("failed to get grant: %w", err)
+	}
+	return &grant, nil
+}
+
+// RevokeMailboxGrant 撤销权限
+func (s *OrganizationServiceImpl) RevokeMailboxGrant(ctx context.Context, userID, orgID, mailboxID, memberUserID uint) error {
+	if _, err := s.requireAdmin(ctx, orgID, userID); err != nil {
+		return err
+	}
+	mailbox, err := s.orgMailbox(ctx, orgID, mailboxID)
+	if err != nil {
+		return err
+	}
+
+	var deleted int64
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("shared_mailbox_id = ? AND user_id = ?", mailbox.ID, memberUserID).Delete(&models.SharedMailboxGrant{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted = result.RowsAffected
+		// 账户所有者不需要授权也能访问，保留其认领
+		if memberUserID == mailbox.SharedBy {
+			return nil
+		}
+		return tx.Where("shared_mailbox_id = ? AND assignee_id = ?", mailbox.ID, memberUserID).Delete(&models.EmailAssignment{}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke grant: %w", err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("%w: grant not found", ErrInvalidOrganizationRequest)
+	}
+	return nil
+}
+
+// ListSharedMailboxes 列出可以访问的共享邮箱
+func (s *OrganizationServiceImpl) ListSharedMailboxes(ctx context.Context, userID uint) ([]SharedMailboxInfo, error) {
+	var mailboxes []models.SharedMailbox
+	if err := s.db.WithContext(ctx).Preload("Account").
+		Where("shared_by = ? OR id IN (?)", userID,
+			s.db.Model(&models.SharedMailboxGrant{}).Select("shared_mailbox_id").Where("user_id = ?", userID)).
+		Order("created_at ASC").
+		Find(&mailboxes).Error; err != nil {
+		return nil, fmt.Errorf("failed to list shared mailboxes: %w", err)
+	}
+
+	var grants []models.SharedMailboxGrant
+	if err := s.db.WithContext(ctx).Where("user_id = ?", userID).Find(&grants).Error; err != nil {
+		return nil, fmt.Errorf("failed to list grants: %w", err)
+	}
+	permissions := make(map[uint]string, len(grants))
+	for _, grant := range grants {
+		permissions[grant.SharedMailboxID] = grant.Permission
+	}
+
+	infos := make([]SharedMailboxInfo, 0, len(mailboxes))
+	for i := range mailboxes {
+		permission := permissions[mailboxes[i].ID]
+		if mailboxes[i].SharedBy == userID {
+			permission = models.SharedMailboxSendAs
+		}
+		infos = append(infos, sharedMailboxInfo(&mailboxes[i], permission))
+	}
+	return infos, nil
+}
+
+// ListSharedEmails 列出共享邮箱中的邮件
+func (s *OrganizationServiceImpl) ListSharedEmails(ctx context.Context, userID, mailboxID uint, req *ListSharedEmailsRequest) (*ListSharedEmailsResponse, error) {
+	mailbox, _, err := s.accessibleMailbox(ctx, userID, mailboxID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.Email{}).
+		Where("emails.account_id = ? AND emails.is_deleted = ?", mailbox.AccountID, false)
+	if req.FolderID != 0 {
+		query = query.Where("emails.folder_id = ?", req.FolderID)
+	}
+	assigned := s.db.Model(&models.EmailAssignment{}).Select("email_id").Where("shared_mailbox_id = ?", mailbox.ID)
+	switch req.Assignment {
+	case "":
+	case "unassigned":
+		query = query.Where("emails.id NOT IN (?)", assigned)
+	case "assigned":
+		query = query.Where("emails.id IN (?)", assigned)
+	case "mine":
+		query = query.Where("emails.id IN (?)", assigned.Where("assignee_id = ?", userID))
+	default:
+		return nil, fmt.Errorf("%w: assignment must be unassigned, assigned or mine", ErrInvalidOrganizationRequest)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count emails: %w", err)
+	}
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 50
+	}
+
+	var emails []*models.Email
+	if err := query.Order("emails.date DESC, emails.id DESC").
+		Limit(pageSize).
+		Offset((page - 1) * pageSize).
+		Find(&emails).Error; err != nil {
+		return nil, fmt.Errorf("failed to list emails: %w", err)
+	}
+
+	assignments, err := s.assignments(ctx, mailbox.ID, emails)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]SharedMailboxEmail, 0, len(emails))
+	for _, email := range emails {
+		items = append(items, SharedMailboxEmail{Email: email, Assignment: assignments[email.ID]})
+	}
+
+	return &ListSharedEmailsResponse{
+		Emails:     items,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
+	}, nil
+}
+
+// GetSharedEmail 获取共享邮箱中的邮件
+func (s *OrganizationServiceImpl) GetSharedEmail(ctx context.Context, userID, mailboxID, emailID uint) (*SharedMailboxEmail, error) {
+	mailbox, _, err := s.accessibleMailbox(ctx, userID, mailboxID)
+	if err != nil {
+		return nil, err
+	}
+
+	var email models.Email
+	if err := s.db.WithContext(ctx).Preload("Attachments").Preload("Folder").
+		Where("id = ? AND account_id = ? AND is_deleted = ?", emailID, mailbox.AccountID, false).
+		First(&email).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSharedEmailNotFound
+		}
+		return nil, fmt.Errorf("failed to get email: %w", err)
+	}
+
+	assignments, err := s.assignments(ctx, mailbox.ID, []*models.Email{&email})
+	if err != nil {
+		return nil, err
+	}
+	return &SharedMailboxEmail{Email: &email, Assignment: assignments[email.ID]}, nil
+}
+
+// ClaimEmail 认领邮件
+func (s *OrganizationServiceImpl) ClaimEmail(ctx context.Context, userID, mailboxID, emailID uint) (*models.EmailAssignment, error) {
+	mailbox, _, err := s.accessibleMailbox(ctx, userID, mailboxID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkMailboxEmail(ctx, mailbox, emailID); err != nil {
+		return nil, err
+	}
+
+	assignment := &models.EmailAssignment{
+		SharedMailboxID: mailbox.ID,
+		EmailID:         emailID,
+		AssigneeID:      userID,
+		AssignedBy:      userID,
+	}
+	// 依赖邮件的唯一索引，并发认领时只有一个成功
+	if err := s.db.WithContext(ctx).Create(assignment).Error; err != nil {
+		if !isUniqueConstraintError(err) {
+			return nil, fmt.Errorf("failed to claim email: %w", err)
+		}
+		var existing models.EmailAssignment
+		if err := s.db.WithContext(ctx).Where("email_id = ?", emailID).First(&existing).Error; err != nil {
+			return nil, fmt.Errorf("failed to get assignment: %w", err)
+		}
+		if existing.AssigneeID != userID {
+			return nil, ErrEmailAlreadyAssigned
+		}
+		return &existing, nil
+	}
+
+	s.publishAssignment(ctx, mailbox, emailID, &assignment.AssigneeID, userID)
+	return assignment, nil
+}
+
+// AssignEmail 分配邮件
+func (s *OrganizationServiceImpl) AssignEmail(ctx context.Context, userID, mailboxID, emailID, assigneeID uint) (*models.EmailAssignment, error) {
+	mailbox, _, err := s.accessibleMailbox(ctx, userID, mailboxID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.requireAdmin(ctx, mailbox.OrganizationID, userID); err != nil {
+		return nil, err
+	}
+	if _, _, err := s.accessibleMailbox(ctx, assigneeID, mailboxID); err != nil {
+		if errors.Is(err, ErrSharedMailboxNotFound) {
+			return nil, fmt.Errorf("%w: assignee has no access to the mailbox", ErrInvalidOrganizationRequest)
+		}
+		return nil, err
+	}
+	if err := s.checkMailboxEmail(ctx, mailbox, emailID); err != nil {
+		return nil, err
+	}
+
+	var assignment models.EmailAssignment
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("email_id = ?", emailID).First(&assignment).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			assignment = models.EmailAssignment{SharedMailboxID: mailbox.ID, EmailID: emailID, AssigneeID: assigneeID, AssignedBy: userID}
+			return tx.Create(&assignment).Error
+		}
+		if err != nil {
+			return err
+		}
+		assignment.AssigneeID = assigneeID
+		assignment.AssignedBy = userID
+		return tx.Model(&assignment).Updates(map[string]interface{}{"assignee_id": assigneeID, "assigned_by": userID}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign email: %w", err)
+	}
+
+	s.publishAssignment(ctx, mailbox, emailID, &assigneeID, userID)
+	return &assignment, nil
+}
+
+// ReleaseEmail 释放认领
+func (s *OrganizationServiceImpl) ReleaseEmail(ctx context.Context, userID, mailboxID, emailID uint) error {
+	mailbox, _, err := s.accessibleMailbox(ctx, userID, mailboxID)
+	if err != nil {
+		return err
+	}
+
+	var assignment models.EmailAssignment
+	if err := s.db.WithContext(ctx).Where("shared_mailbox_id = ? AND email_id = ?", mailbox.ID, emailID).First(&assignment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: email is not assigned", ErrInvalidOrganizationRequest)
+		}
+		return fmt.Errorf("failed to get assignment: %w", err)
+	}
+	if assignment.AssigneeID != userID {
+		if _, err := s.requireAdmin(ctx, mailbox.OrganizationID, userID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.db.WithContext(ctx).Delete(&assignment).Error; err != nil {
+		return fmt.Errorf("failed to release email: %w", err)
+	}
+	s.publishAssignment(ctx, mailbox, emailID, nil, userID)
+	return nil
+}
+
+// CanSendAs 检查共享邮箱的发信权限
+func (s *OrganizationServiceImpl) CanSendAs(ctx context.Context, userID, accountID uint) (bool, error) {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.SharedMailboxGrant{}).
+		Joins("JOIN shared_mailboxes ON shared_mailboxes.id = shared_mailbox_grants.shared_mailbox_id").
+		Where("shared_mailboxes.account_id = ? AND shared_mailbox_grants.user_id = ? AND shared_mailbox_grants.permission = ?",
+			accountID, userID, models.SharedMailboxSendAs).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check send-as permission: %w", err)
+	}
+	return count > 0, nil
+}
+
+// membership 获取用户在组织中的成员记录，不是成员时返回 ErrOrganizationNotFound
+func (s *OrganizationServiceImpl) membership(ctx context.Context, orgID, userID uint) (*models.OrganizationMember, error) {
+	var member models.OrganizationMember
+	if err := s.db.WithContext(ctx).Where("organization_id = ? AND user_id = ?", orgID, userID).First(&member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrganizationNotFound
+		}
+		return nil, fmt.Errorf("failed to get organization membership: %w", err)
+	}
+	return &member, nil
+}
+
+// requireAdmin 要求用户是组织的所有者或管理员
+func (s *OrganizationServiceImpl) requireAdmin(ctx context.Context, orgID, userID uint) (*models.OrganizationMember, error) {
+	member, err := s.membership(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !member.IsAdmin() {
+		return nil, ErrOrganizationForbidden
+	}
+	return member, nil
+}
+
+// orgMailbox 获取组织内的共享邮箱
+func (s *OrganizationServiceImpl) orgMailbox(ctx context.Context, orgID, mailboxID uint) (*models.SharedMailbox, error) {
+	var mailbox models.SharedMailbox
+	if err := s.db.WithContext(ctx).Where("id = ? AND organization_id = ?", mailboxID, orgID).First(&mailbox).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSharedMailboxNotFound
+		}
+		return nil, fmt.Errorf("failed to get shared mailbox: %w", err)
+	}
+	return &mailbox, nil
+}
+
+// accessibleMailbox 获取用户有权访问的共享邮箱及其权限，账户所有者无需授权
+func (s *OrganizationServiceImpl) accessibleMailbox(ctx context.Context, userID, mailboxID uint) (*models.SharedMailbox, string, error) {
+	var mailbox models.SharedMailbox
+	if err := s.db.WithContext(ctx).First(&mailbox, mailboxID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", ErrSharedMailboxNotFound
+		}
+		return nil, "", fmt.Errorf("failed to get shared mailbox: %w", err)
+	}
+	if mailbox.SharedBy == userID {
+		return &mailbox, models.SharedMailboxSendAs, nil
+	}
+
+	var grant models.SharedMailboxGrant
+	if err := s.db.WithContext(ctx).Where("shared_mailbox_id = ? AND user_id = ?", mailbox.ID, userID).First(&grant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", ErrSharedMailboxNotFound
+		}
+		return nil, "", fmt.Errorf("failed to get grant: %w", err)
+	}
+	return &mailbox, grant.Permission, nil
+}
+
+// checkMailboxEmail 检查邮件属于共享邮箱的账户
+func (s *OrganizationServiceImpl) checkMailboxEmail(ctx context.Context, mailbox *models.SharedMailbox, emailID uint) error {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.Email{}).
+		Where("id = ? AND account_id = ? AND is_deleted = ?", emailID, mailbox.AccountID, false).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to get email: %w", err)
+	}
+	if count == 0 {
+		return ErrSharedEmailNotFound
+	}
+	return nil
+}
+
+// assignments 加载邮件的认领记录
+func (s *OrganizationServiceImpl) assignments(ctx context.Context, mailboxID uint, emails []*models.Email) (map[uint]*models.EmailAssignment, error) {
+	result := make(map[uint]*models.EmailAssignment, len(emails))
+	if len(emails) == 0 {
+		return result, nil
+	}
+	emailIDs := make([]uint, len(emails))
+	for i, email := range emails {
+		emailIDs[i] = email.ID
+	}
+
+	var assignments []models.EmailAssignment
+	if err := s.db.WithContext(ctx).Where("shared_mailbox_id = ? AND email_id IN ?", mailboxID, emailIDs).Find(&assignments).Error; err != nil {
+		return nil, fmt.Errorf("failed to load assignments: %w", err)
+	}
+	for i := range assignments {
+		result[assignments[i].EmailID] = &assignments[i]
+	}
+	return result, nil
+}
+
+// publishAssignment 向共享邮箱的所有可访问用户推送认领变更
+func (s *OrganizationServiceImpl) publishAssignment(ctx context.Context, mailbox *models.SharedMailbox, emailID uint, assigneeID *uint, changedBy uint) {
+	if s.eventPublisher == nil {
+		return
+	}
+
+	var userIDs []uint
+	if err := s.db.WithContext(ctx).Model(&models.SharedMailboxGrant{}).
+		Where("shared_mailbox_id = ?", mailbox.ID).
+		Pluck("user_id", &userIDs).Error; err != nil {
+		log.Printf("Failed to load shared mailbox grants for event: %v", err)
+		return
+	}
+	recipients := map[uint]bool{mailbox.SharedBy: true}
+	for _, id := range userIDs {
+		recipients[id] = true
+	}
+
+	data := &sse.EmailAssignmentEventData{
+		SharedMailboxID: mailbox.ID,
+		AccountID:       mailbox.AccountID,
+		EmailID:         emailID,
+		AssigneeID:      assigneeID,
+		ChangedBy:       changedBy,
+	}
+	for recipient := range recipients {
+		if err := s.eventPublisher.PublishToUser(ctx, recipient, sse.NewEmailAssignmentEvent(data, recipient)); err != nil {
+			log.Printf("Failed to publish email assignment event: %v", err)
+		}
+	}
+}
+
+// notify 按接收者的语言推送通知并保存到通知中心，失败只记录日志
+func (s *OrganizationServiceImpl) notify(ctx context.Context, userID uint, title, message string, args ...interface{}) {
+	if s.eventPublisher == nil {
+		return
+	}
+	locale := userLocale(ctx, s.db, userID)
+	event := sse.NewPersistentNotificationEvent(i18n.T(locale, title), i18n.T(locale, message, args...), "info", userID)
+	if err := s.eventPublisher.PublishToUser(ctx, userID, event); err != nil {
+		log.Printf("Failed to publish organization notification: %v", err)
+	}
+}
+
+// sharedMailboxInfo 转换为共享邮箱的展示信息
+func sharedMailboxInfo(mailbox *models.SharedMailbox, permission string) SharedMailboxInfo {
+	info := SharedMailboxInfo{
+		ID:             mailbox.ID,
+		OrganizationID: mailbox.OrganizationID,
+		AccountID:      mailbox.AccountID,
+		SharedBy:       mailbox.SharedBy,
+		Permission:     permission,
+		Grants:         mailbox.Grants,
+		CreatedAt:      mailbox.CreatedAt,
+	}
+	if mailbox.Account != nil {
+		info.AccountName = mailbox.Account.Name
+		info.Email = mailbox.Account.Email
+	}
+	return info
+}
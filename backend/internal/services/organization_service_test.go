@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"firemail/internal/models"
+	"firemail/internal/sse"
+
+	"github.com/stretchr/testify/require"
+)
+
+type organizationTestEnv struct {
+	*emailStateServiceTestEnv
+	svc       *OrganizationServiceImpl
+	publisher *recordingEventPublisher
+	org       *models.Organization
+}
+
+func setupOrganizationTestEnv(t *testing.T) *organizationTestEnv {
+	t.Helper()
+
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(
+		&models.Organization{},
+		&models.OrganizationMember{},
+		&models.OrganizationInvite{},
+		&models.SharedMailbox{},
+		&models.SharedMailboxGrant{},
+		&models.EmailAssignment{},
+	))
+	publisher := &recordingEventPublisher{}
+	svc := NewOrganizationService(env.db, publisher).(*OrganizationServiceImpl)
+
+	org, err := svc.CreateOrganization(context.Background(), env.user.ID, &CreateOrganizationRequest{Name: " Support "})
+	require.NoError(t, err)
+	require.Equal(t, "Support", org.Name)
+	return &organizationTestEnv{emailStateServiceTestEnv: env, svc: svc, publisher: publisher, org: org}
+}
+
+// addMember 创建用户并通过邀请加入组织
+func (env *organizationTestEnv) addMember(t *testing.T, username, role string) *models.User {
+	t.Helper()
+	ctx := context.Background()
+
+	user := &models.User{Username: username, Password: "password123", Role: "user", IsActive: true}
+	require.NoError(t, env.db.Create(user).Error)
+	invite, err := env.svc.InviteMember(ctx, env.user.ID, env.org.ID, &InviteOrganizationMemberRequest{Username: username, Role: role})
+	require.NoError(t, err)
+	_, err = env.svc.RespondToInvite(ctx, user.ID, invite.ID, true)
+	require.NoError(t, err)
+	return user
+}
+
+func TestOrganizationMembersAndMailboxGrants(t *testing.T) {
+	env := setupOrganizationTestEnv(t)
+	ctx := context.Background()
+
+	alice := &models.User{Username: "alice", Password: "password123", Role: "user", IsActive: true}
+	require.NoError(t, env.db.Create(alice).Error)
+	invite, err := env.svc.InviteMember(ctx, env.user.ID, env.org.ID, &InviteOrganizationMemberRequest{Username: "alice"})
+	require.NoError(t, err)
+	require.Equal(t, models.OrganizationRoleMember, invite.Role)
+	_, err = env.svc.InviteMember(ctx, env.user.ID, env.org.ID, &InviteOrganizationMemberRequest{Username: "alice"})
+	require.ErrorIs(t, err, ErrOrganizationConflict)
+
+	invites, err := env.svc.ListInvites(ctx, alice.ID)
+	require.NoError(t, err)
+	require.Len(t, invites, 1)
+	require.Equal(t, "Support", invites[0].Organization.Name)
+	_, err = env.svc.RespondToInvite(ctx, alice.ID, invite.ID, true)
+	require.NoError(t, err)
+	_, err = env.svc.RespondToInvite(ctx, alice.ID, invite.ID, true)
+	require.ErrorIs(t, err, ErrOrganizationConflict)
+
+	// 普通成员不能管理组织
+	_, err = env.svc.InviteMember(ctx, alice.ID, env.org.ID, &InviteOrganizationMemberRequest{Username: "nobody"})
+	require.ErrorIs(t, err, ErrOrganizationForbidden)
+	require.ErrorIs(t, env.svc.RemoveMember(ctx, alice.ID, env.org.ID, env.user.ID), ErrOrganizationForbidden)
+
+	mailbox, err := env.svc.ShareMailbox(ctx, env.user.ID, env.org.ID, env.account.ID)
+	require.NoError(t, err)
+	_, err = env.svc.ShareMailbox(ctx, env.user.ID, env.org.ID, env.account.ID)
+	require.ErrorIs(t, err, ErrOrganizationConflict)
+
+	// 授权之前成员看不到共享邮箱
+	mailboxes, err := env.svc.ListSharedMailboxes(ctx, alice.ID)
+	require.NoError(t, err)
+	require.Empty(t, mailboxes)
+	_, err = env.svc.ListSharedEmails(ctx, alice.ID, mailbox.ID, &ListSharedEmailsRequest{})
+	require.ErrorIs(t, err, ErrSharedMailboxNotFound)
+
+	_, err = env.svc.SetMailboxGrant(ctx, env.user.ID, env.org.ID, mailbox.ID, &SharedMailboxGrantRequest{UserID: alice.ID, Permission: models.SharedMailboxReadOnly})
+	require.NoError(t, err)
+	mailboxes, err = env.svc.ListSharedMailboxes(ctx, alice.ID)
+	require.NoError(t, err)
+	require.Len(t, mailboxes, 1)
+	require.Equal(t, "tester@example.com", mailboxes[0].Email)
+	require.Equal(t, models.SharedMailboxReadOnly, mailboxes[0].Permission)
+	canSend, err := env.svc.CanSendAs(ctx, alice.ID, env.account.ID)
+	require.NoError(t, err)
+	require.False(t, canSend)
+
+	_, err = env.svc.SetMailboxGrant(ctx, env.user.ID, env.org.ID, mailbox.ID, &SharedMailboxGrantRequest{UserID: alice.ID, Permission: models.SharedMailboxSendAs})
+	require.NoError(t, err)
+	canSend, err = env.svc.CanSendAs(ctx, alice.ID, env.account.ID)
+	require.NoError(t, err)
+	require.True(t, canSend)
+
+	detail, err := env.svc.GetOrganization(ctx, alice.ID, env.org.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.OrganizationRoleMember, detail.Role)
+	require.Len(t, detail.Members, 2)
+	require.Len(t, detail.Mailboxes, 1)
+	require.Empty(t, detail.Mailboxes[0].Grants)
+
+	// 退出组织后撤销授权
+	require.NoError(t, env.svc.RemoveMember(ctx, alice.ID, env.org.ID, alice.ID))
+	canSend, err = env.svc.CanSendAs(ctx, alice.ID, env.account.ID)
+	require.NoError(t, err)
+	require.False(t, canSend)
+	_, err = env.svc.GetOrganization(ctx, alice.ID, env.org.ID)
+	require.ErrorIs(t, err, ErrOrganizationNotFound)
+
+	require.ErrorIs(t, env.svc.RemoveMember(ctx, env.user.ID, env.org.ID, env.user.ID), ErrOrganizationForbidden)
+	require.NoError(t, env.svc.DeleteOrganization(ctx, env.user.ID, env.org.ID))
+	var count int64
+	require.NoError(t, env.db.Model(&models.SharedMailbox{}).Count(&count).Error)
+	require.Zero(t, count)
+}
+
+func TestSharedMailboxClaimsAndAssignments(t *testing.T) {
+	env := setupOrganizationTestEnv(t)
+	ctx := context.Background()
+
+	alice := env.addMember(t, "alice", models.OrganizationRoleMember)
+	bob := env.addMember(t, "bob", models.OrganizationRoleMember)
+	outsider := env.addMember(t, "carol", models.OrganizationRoleMember)
+
+	mailbox, err := env.svc.ShareMailbox(ctx, env.user.ID, env.org.ID, env.account.ID)
+	require.NoError(t, err)
+	for _, user := range []*models.User{alice, bob} {
+		_, err := env.svc.SetMailboxGrant(ctx, env.user.ID, env.org.ID, mailbox.ID, &SharedMailboxGrantRequest{UserID: user.ID, Permission: models.SharedMailboxReadOnly})
+		require.NoError(t, err)
+	}
+
+	first := env.createEmail(t, env.inbox, 1, "Refund request", false, false)
+	second := env.createEmail(t, env.inbox, 2, "Login problem", false, false)
+	env.createEmail(t, env.inbox, 3, "Deleted", false, true)
+
+	assignment, err := env.svc.ClaimEmail(ctx, alice.ID, mailbox.ID, first.ID)
+	require.NoError(t, err)
+	require.Equal(t, alice.ID, assignment.AssigneeID)
+	_, err = env.svc.ClaimEmail(ctx, bob.ID, mailbox.ID, first.ID)
+	require.ErrorIs(t, err, ErrEmailAlreadyAssigned)
+	_, err = env.svc.ClaimEmail(ctx, outsider.ID, mailbox.ID, second.ID)
+	require.ErrorIs(t, err, ErrSharedMailboxNotFound)
+
+	// 认领变更推送给所有可以访问共享邮箱的用户
+	recipients := map[uint]bool{}
+	for _, event := range env.publisher.events {
+		if event.Type == sse.EventEmailAssignmentChanged {
+			recipients[event.UserID] = true
+		}
+	}
+	require.Equal(t, map[uint]bool{env.user.ID: true, alice.ID: true, bob.ID: true}, recipients)
+
+	unassigned, err := env.svc.ListSharedEmails(ctx, bob.ID, mailbox.ID, &ListSharedEmailsRequest{Assignment: "unassigned"})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), unassigned.Total)
+	require.Equal(t, second.ID, unassigned.Emails[0].ID)
+	mine, err := env.svc.ListSharedEmails(ctx, alice.ID, mailbox.ID, &ListSharedEmailsRequest{Assignment: "mine"})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), mine.Total)
+	require.Equal(t, alice.ID, mine.Emails[0].Assignment.AssigneeID)
+	all, err := env.svc.ListSharedEmails(ctx, bob.ID, mailbox.ID, &ListSharedEmailsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), all.Total)
+
+	// 成员只能释放自己的认领，管理员可以重新分配
+	require.ErrorIs(t, env.svc.ReleaseEmail(ctx, bob.ID, mailbox.ID, first.ID), ErrOrganizationForbidden)
+	_, err = env.svc.AssignEmail(ctx, alice.ID, mailbox.ID, first.ID, bob.ID)
+	require.ErrorIs(t, err, ErrOrganizationForbidden)
+	_, err = env.svc.AssignEmail(ctx, env.user.ID, mailbox.ID, first.ID, outsider.ID)
+	require.ErrorIs(t, err, ErrInvalidOrganizationRequest)
+	assignment, err = env.svc.AssignEmail(ctx, env.user.ID, mailbox.ID, first.ID, bob.ID)
+	require.NoError(t, err)
+	require.Equal(t, bob.ID, assignment.AssigneeID)
+	require.Equal(t, env.user.ID, assignment.AssignedBy)
+
+	email, err := env.svc.GetSharedEmail(ctx, bob.ID, mailbox.ID, first.ID)
+	require.NoError(t, err)
+	require.Equal(t, bob.ID, email.Assignment.AssigneeID)
+	require.False(t, email.IsRead)
+
+	require.NoError(t, env.svc.ReleaseEmail(ctx, bob.ID, mailbox.ID, first.ID))
+	_, err = env.svc.ClaimEmail(ctx, alice.ID, mailbox.ID, first.ID)
+	require.NoError(t, err)
+
+	// 撤销授权时释放该成员的认领
+	require.NoError(t, env.svc.RevokeMailboxGrant(ctx, env.user.ID, env.org.ID, mailbox.ID, alice.ID))
+	var count int64
+	require.NoError(t, env.db.Model(&models.EmailAssignment{}).Count(&count).Error)
+	require.Zero(t, count)
+}
@@ -0,0 +1,364 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"firemail/internal/models"
+)
+
+const (
+	// 发出邮件回复统计最多采样的邮件数
+	maxOutboundSamples = 2000
+	// 超过该时间才收到的回复不计入
+	outboundReplyWindow = 30 * 24 * time.Hour
+	// 按会话查询回复时每批的会话数，避免超出SQLite参数上限
+	outboundThreadBatch = 500
+)
+
+// OutboundReplyStats 发出邮件的回复率和回复用时
+type OutboundReplyStats struct {
+	Sent                   int     `json:"sent"`
+	Replied                int     `json:"replied"`
+	ReplyRate              float64 `json:"reply_rate"` // 0到1
+	AverageResponseSeconds int64   `json:"average_response_seconds"`
+	MedianResponseSeconds  int64   `json:"median_response_seconds"`
+}
+
+// AnalyticsOutboundMessage 一封发出邮件及其收到的首个回复
+type AnalyticsOutboundMessage struct {
+	EmailID         uint       `json:"email_id"`
+	AccountID       uint       `json:"account_id"`
+	Subject         string     `json:"subject"`
+	Recipients      []string   `json:"recipients"`
+	SentAt          time.Time  `json:"sent_at"`
+	ReplyEmailID    *uint      `json:"reply_email_id,omitempty"`
+	RepliedBy       string     `json:"replied_by,omitempty"`
+	RepliedAt       *time.Time `json:"replied_at,omitempty"`
+	ResponseSeconds *int64     `json:"response_seconds,omitempty"`
+}
+
+// AnalyticsOutboundAccount 账户维度的发出邮件回复统计
+type AnalyticsOutboundAccount struct {
+	AccountID uint   `json:"account_id"`
+	Email     string `json:"email"`
+	OutboundReplyStats
+}
+
+// AnalyticsOutboundContact 联系人维度的发出邮件回复统计，只计该联系人本人的回复
+type AnalyticsOutboundContact struct {
+	Address string `json:"address"`
+	Name    string `json:"name,omitempty"`
+	OutboundReplyStats
+	LastSentAt    time.Time  `json:"last_sent_at"`
+	LastRepliedAt *time.Time `json:"last_replied_at,omitempty"`
+}
+
+// outboundSent 一封发出邮件的回复匹配结果
+type outboundSent struct {
+	email      models.Email
+	recipients []*models.EmailAddress
+	reply      *models.Email
+	// 每个收件人各自的首个回复
+	repliesBy map[string]*models.Email
+}
+
+// GetOutboundMessages 发出邮件及其回复情况，按发送时间倒序，unreplied 为true时只返回未收到回复的邮件
+func (s *AnalyticsServiceImpl) GetOutboundMessages(ctx context.Context, userID uint, query *AnalyticsQuery, unreplied bool) ([]AnalyticsOutboundMessage, error) {
+	r, err := resolveAnalyticsQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	sent, err := s.outboundReplies(ctx, userID, query.AccountID, r)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]AnalyticsOutboundMessage, 0, r.limit)
+	for _, item := range sent {
+		if len(messages) >= r.limit {
+			break
+		}
+		if unreplied && item.reply != nil {
+			continue
+		}
+		message := AnalyticsOutboundMessage{
+			EmailID:    item.email.ID,
+			AccountID:  item.email.AccountID,
+			Subject:    item.email.Subject,
+			Recipients: make([]string, 0, len(item.recipients)),
+			SentAt:     item.email.Date,
+		}
+		for _, recipient := range item.recipients {
+			message.Recipients = append(message.Recipients, recipient.Address)
+		}
+		if item.reply != nil {
+			seconds := int64(item.reply.Date.Sub(item.email.Date).Seconds())
+			message.ReplyEmailID = &item.reply.ID
+			message.RepliedAt = &item.reply.Date
+			message.ResponseSeconds = &seconds
+			if from := parseEmailAddress(item.reply.From); from != nil {
+				message.RepliedBy = strings.ToLower(from.Address)
+			}
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// GetOutboundAccounts 按账户统计发出邮件的回复率和回复用时
+func (s *AnalyticsServiceImpl) GetOutboundAccounts(ctx context.Context, userID uint, query *AnalyticsQuery) ([]AnalyticsOutboundAccount, error) {
+	r, err := resolveAnalyticsQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	sent, err := s.outboundReplies(ctx, userID, query.AccountID, r)
+	if err != nil {
+		return nil, err
+	}
+
+	accountQuery := s.db.WithContext(ctx).Select("id, email").Where("user_id = ?", userID)
+	if query.AccountID != nil {
+		accountQuery = accountQuery.Where("id = ?", *query.AccountID)
+	}
+	var accounts []models.EmailAccount
+	if err := accountQuery.Order("id ASC").Find(&accounts).Error; err != nil {
+		return nil, fmt.Errorf("failed to load accounts: %w", err)
+	}
+
+	durations := make(map[uint][]time.Duration)
+	counts := make(map[uint]*OutboundReplyStats)
+	for _, item := range sent {
+		stats, ok := counts[item.email.AccountID]
+		if !ok {
+			stats = &OutboundReplyStats{}
+			counts[item.email.AccountID] = stats
+		}
+		stats.Sent++
+		if item.reply != nil {
+			stats.Replied++
+			durations[item.email.AccountID] = append(durations[item.email.AccountID], item.reply.Date.Sub(item.email.Date))
+		}
+	}
+
+	result := make([]AnalyticsOutboundAccount, 0, len(accounts))
+	for _, account := range accounts {
+		entry := AnalyticsOutboundAccount{AccountID: account.ID, Email: account.Email}
+		if stats, ok := counts[account.ID]; ok {
+			entry.OutboundReplyStats = *stats
+			entry.OutboundReplyStats.finish(durations[account.ID])
+		}
+		result = append(result, entry)
+	}
+	return result, nil
+}
+
+// GetOutboundContacts 按收件人（包括抄送）统计发出邮件的回复率和回复用时，发信最多的在前
+func (s *AnalyticsServiceImpl) GetOutboundContacts(ctx context.Context, userID uint, query *AnalyticsQuery) ([]AnalyticsOutboundContact, error) {
+	r, err := resolveAnalyticsQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	sent, err := s.outboundReplies(ctx, userID, query.AccountID, r)
+	if err != nil {
+		return nil, err
+	}
+
+	byAddress := make(map[string]*AnalyticsOutboundContact)
+	durations := make(map[string][]time.Duration)
+	for _, item := range sent {
+		for _, recipient := range item.recipients {
+			contact, ok := byAddress[recipient.Address]
+			if !ok {
+				contact = &AnalyticsOutboundContact{Address: recipient.Address}
+				byAddress[recipient.Address] = contact
+			}
+			contact.Sent++
+			if contact.Name == "" {
+				contact.Name = recipient.Name
+			}
+			if item.email.Date.After(contact.LastSentAt) {
+				contact.LastSentAt = item.email.Date
+			}
+
+			reply := item.repliesBy[recipient.Address]
+			if reply == nil {
+				continue
+			}
+			contact.Replied++
+			durations[recipient.Address] = append(durations[recipient.Address], reply.Date.Sub(item.email.Date))
+			if contact.LastRepliedAt == nil || reply.Date.After(*contact.LastRepliedAt) {
+				repliedAt := reply.Date
+				contact.LastRepliedAt = &repliedAt
+			}
+		}
+	}
+
+	contacts := make([]AnalyticsOutboundContact, 0, len(byAddress))
+	for address, contact := range byAddress {
+		contact.OutboundReplyStats.finish(durations[address])
+		contacts = append(contacts, *contact)
+	}
+	sort.Slice(contacts, func(i, j int) bool {
+		if contacts[i].Sent != contacts[j].Sent {
+			return contacts[i].Sent > contacts[j].Sent
+		}
+		return contacts[i].Address < contacts[j].Address
+	})
+	if len(contacts) > r.limit {
+		contacts = contacts[:r.limit]
+	}
+	return contacts, nil
+}
+
+// outboundReplies 按会话为时间范围内发出的邮件匹配回复，按发送时间倒序返回。
+// 回复为同一账户、同一会话中晚于该邮件且早于下一封发出邮件的来信，超过 outboundReplyWindow 不计入
+func (s *AnalyticsServiceImpl) outboundReplies(ctx context.Context, userID uint, accountID *uint, r *analyticsRange) ([]*outboundSent, error) {
+	var emails []models.Email
+	if err := s.analyticsEmails(ctx, userID, accountID, r).
+		Where(ownAddressCondition).
+		Select("emails.id, emails.account_id, emails.message_id, emails.thread_id, emails.subject, emails.to_addresses, emails.cc_addresses, emails.date").
+		Order("emails.date DESC").
+		Limit(maxOutboundSamples).
+		Find(&emails).Error; err != nil {
+		return nil, fmt.Errorf("failed to load sent emails: %w", err)
+	}
+	if len(emails) == 0 {
+		return nil, nil
+	}
+
+	var accounts []models.EmailAccount
+	if err := s.db.WithContext(ctx).Select("id, email").Where("user_id = ?", userID).Find(&accounts).Error; err != nil {
+		return nil, fmt.Errorf("failed to load accounts: %w", err)
+	}
+	accountEmails := make(map[uint]string, len(accounts))
+	for _, account := range accounts {
+		accountEmails[account.ID] = account.Email
+	}
+
+	sent := make([]*outboundSent, 0, len(emails))
+	sentIDs := make(map[uint]bool, len(emails))
+	keys := make([]string, 0, len(emails))
+	seenKeys := make(map[string]bool)
+	earliest := emails[len(emails)-1].Date
+	for i := range emails {
+		email := emails[i]
+		item := &outboundSent{email: email, repliesBy: make(map[string]*models.Email)}
+		seen := make(map[string]bool)
+		for _, list := range []string{email.To, email.CC} {
+			addresses, err := parseEmailAddressList(list)
+			if err != nil {
+				continue
+			}
+			for _, address := range addresses {
+				if address == nil || address.Address == "" {
+					continue
+				}
+				key := strings.ToLower(strings.TrimSpace(address.Address))
+				if seen[key] || isOwnEmailAddress(key, accountEmails[email.AccountID]) {
+					continue
+				}
+				seen[key] = true
+				item.recipients = append(item.recipients, &models.EmailAddress{Name: address.Name, Address: key})
+			}
+		}
+		sent = append(sent, item)
+		sentIDs[email.ID] = true
+
+		if key := emailThreadKey(&email); key != "" && !seenKeys[key] {
+			seenKeys[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	// 会话中的其他邮件：来信作为回复候选，其他发出的邮件（包括时间范围外的）作为回复窗口的边界
+	type threadEntry struct {
+		email *models.Email
+		own   bool
+	}
+	threads := make(map[string][]threadEntry)
+	threadKey := func(accountID uint, key string) string {
+		return fmt.Sprintf("%d:%s", accountID, key)
+	}
+	for start := 0; start < len(keys); start += outboundThreadBatch {
+		end := start + outboundThreadBatch
+		if end > len(keys) {
+			end = len(keys)
+		}
+		var related []models.Email
+		if err := s.db.WithContext(ctx).Model(&models.Email{}).
+			Select("id, account_id, thread_id, from_address, date").
+			Where("user_id = ? AND is_deleted = ? AND thread_id IN ? AND date > ?", userID, false, keys[start:end], earliest).
+			Find(&related).Error; err != nil {
+			return nil, fmt.Errorf("failed to load replies: %w", err)
+		}
+		for i := range related {
+			email := &related[i]
+			if sentIDs[email.ID] {
+				continue
+			}
+			key := threadKey(email.AccountID, email.ThreadID)
+			threads[key] = append(threads[key], threadEntry{email: email, own: isSentByAccount(email.From, accountEmails[email.AccountID])})
+		}
+	}
+	for _, item := range sent {
+		key := threadKey(item.email.AccountID, emailThreadKey(&item.email))
+		threads[key] = append(threads[key], threadEntry{email: &item.email, own: true})
+	}
+	for _, entries := range threads {
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].email.Date.Before(entries[j].email.Date) })
+	}
+
+	for _, item := range sent {
+		entries := threads[threadKey(item.email.AccountID, emailThreadKey(&item.email))]
+		deadline := item.email.Date.Add(outboundReplyWindow)
+		started := false
+		for _, entry := range entries {
+			if !started {
+				started = entry.email.ID == item.email.ID
+				continue
+			}
+			if entry.own || entry.email.Date.After(deadline) {
+				break
+			}
+			if item.reply == nil {
+				item.reply = entry.email
+			}
+			if from := parseEmailAddress(entry.email.From); from != nil {
+				address := strings.ToLower(strings.TrimSpace(from.Address))
+				if _, ok := item.repliesBy[address]; !ok {
+					item.repliesBy[address] = entry.email
+				}
+			}
+		}
+	}
+	return sent, nil
+}
+
+// finish 根据回复用时计算回复率、平均和中位用时
+func (stats *OutboundReplyStats) finish(durations []time.Duration) {
+	if stats.Sent > 0 {
+		stats.ReplyRate = float64(stats.Replied) / float64(stats.Sent)
+	}
+	stats.AverageResponseSeconds, stats.MedianResponseSeconds = durationSummary(durations)
+}
+
+// durationSummary 返回用时的平均值和中位数（秒），会对传入的切片排序
+func durationSummary(durations []time.Duration) (int64, int64) {
+	if len(durations) == 0 {
+		return 0, 0
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	var total time.Duration
+	for _, duration := range durations {
+		total += duration
+	}
+	median := durations[len(durations)/2]
+	if len(durations)%2 == 0 {
+		median = (durations[len(durations)/2-1] + durations[len(durations)/2]) / 2
+	}
+	return int64((total / time.Duration(len(durations))).Seconds()), int64(median.Seconds())
+}
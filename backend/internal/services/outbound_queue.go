@@ -0,0 +1,416 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"firemail/internal/models"
+	"firemail/internal/providers"
+	"firemail/internal/sse"
+
+	"gorm.io/gorm"
+)
+
+// 发送队列中立即发送邮件的状态，定时邮件另外使用 scheduled、processing、retry
+const (
+	outboundStatusPending     = "pending"      // MIME已保存，尚未投递
+	outboundStatusQueued      = "queued"       // MIME已保存，等待工作进程认领投递
+	outboundStatusSending     = "sending"      // 正在投递
+	outboundStatusSent        = "sent"         // 已投递
+	outboundStatusFailed      = "failed"       // 投递失败
+	outboundStatusRateLimited = "rate_limited" // 被限流，立即发送的邮件不再自动重试
+
+	// defaultStuckSendThreshold 超过该时长仍未完成的邮件视为卡住
+	defaultStuckSendThreshold = 15 * time.Minute
+
+	// queuedSendBatchSize 每次认领的最大邮件数
+	queuedSendBatchSize = 50
+)
+
+// sendQueueIDKey 上下文中关联的发送队列记录
+type sendQueueIDKey struct{}
+
+// withSendQueueID 让发送器把MIME原文写入已有的队列记录（如定时邮件），而不是新建记录
+func withSendQueueID(ctx context.Context, sendID string) context.Context {
+	return context.WithValue(ctx, sendQueueIDKey{}, sendID)
+}
+
+func sendQueueIDFromContext(ctx context.Context) string {
+	sendID, _ := ctx.Value(sendQueueIDKey{}).(string)
+	return sendID
+}
+
+// StuckSend 卡在发送队列中的邮件
+type StuckSend struct {
+	ID          uint       `json:"id"`
+	SendID      string     `json:"send_id"`
+	UserID      uint       `json:"user_id"`
+	AccountID   uint       `json:"account_id"`
+	Subject     string     `json:"subject"`
+	Recipients  []string   `json:"recipients"`
+	Status      string     `json:"status"`
+	Attempts    int        `json:"attempts"`
+	HasMIME     bool       `json:"has_mime"` // 是否已保存MIME原文，重启后可直接继续投递
+	LastError   string     `json:"last_error,omitempty"`
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+	LastAttempt *time.Time `json:"last_attempt,omitempty"`
+	NextAttempt *time.Time `json:"next_attempt,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// persistOutbound 在投递前生成MIME原文并写入发送队列，进程中途退出时邮件不会丢失
+func (s *StandardEmailSender) persistOutbound(ctx context.Context, email *ComposedEmail, account *models.EmailAccount, result *SendResult) (*models.SendQueue, error) {
+	if email.From == nil {
+		return nil, fmt.Errorf("sender address is required")
+	}
+	if len(result.Recipients) == 0 {
+		return nil, fmt.Errorf("at least one recipient is required")
+	}
+
+	outgoingMessage, err := s.buildOutgoingMessage(email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build outgoing message: %w", err)
+	}
+	providers.IdentityForAccount(account).Apply(outgoingMessage, email.ID)
+	raw, err := providers.BuildMessage(outgoingMessage)
+	closeOutgoingAttachments(outgoingMessage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build email data: %w", err)
+	}
+
+	// 只入队的进程写入 queued，由工作进程认领，避免与本进程的投递冲突
+	status := outboundStatusPending
+	if s.queueOnly {
+		status = outboundStatusQueued
+	}
+
+	envelopeFrom := envelopeSender(account, email)
+	fields := map[string]interface{}{
+		"raw_message":   raw,
+		"envelope_from": envelopeFrom,
+		"recipients":    strings.Join(result.Recipients, ","),
+		"subject":       email.Subject,
+		"status":        status,
+	}
+
+	// 定时邮件沿用自己的队列记录
+	if sendID := sendQueueIDFromContext(ctx); sendID != "" {
+		var queued models.SendQueue
+		if err := s.db.WithContext(ctx).Where("send_id = ?", sendID).First(&queued).Error; err != nil {
+			return nil, fmt.Errorf("failed to load send queue entry: %w", err)
+		}
+		if err := s.db.WithContext(ctx).Model(&queued).Updates(fields).Error; err != nil {
+			return nil, fmt.Errorf("failed to persist outbound message: %w", err)
+		}
+		return &queued, nil
+	}
+
+	queued := &models.SendQueue{
+		SendID:       result.SendID,
+		UserID:       account.UserID,
+		AccountID:    account.ID,
+		EmailData:    "{}",
+		RawMessage:   raw,
+		EnvelopeFrom: envelopeFrom,
+		Recipients:   strings.Join(result.Recipients, ","),
+		Subject:      email.Subject,
+		Status:       status,
+	}
+	if err := s.db.WithContext(ctx).Create(queued).Error; err != nil {
+		return nil, fmt.Errorf("failed to persist outbound message: %w", err)
+	}
+	return queued, nil
+}
+
+// markOutbound 更新发送队列记录的状态
+func (s *StandardEmailSender) markOutbound(ctx context.Context, queued *models.SendQueue, status string, sendErr error) {
+	updates := map[string]interface{}{"status": status}
+	switch status {
+	case outboundStatusSending:
+		updates["attempts"] = queued.Attempts + 1
+		updates["last_attempt"] = time.Now()
+		queued.Attempts++
+	case outboundStatusSent:
+		updates["last_error"] = ""
+	}
+	if sendErr != nil {
+		updates["last_error"] = sendErr.Error()
+	}
+
+	// 请求上下文可能已经结束，状态更新不随之取消
+	if err := s.db.WithContext(context.WithoutCancel(ctx)).Model(queued).Updates(updates).Error; err != nil {
+		log.Printf("Failed to update send queue entry %s: %v", queued.SendID, err)
+	}
+	queued.Status = status
+}
+
+// SetQueueOnly 设置为只入队模式：发送请求只把MIME原文写入发送队列，由工作进程认领投递。
+// 用于HTTP进程与工作进程分开部署，SMTP投递不占用接口进程
+func (s *StandardEmailSender) SetQueueOnly(queueOnly bool) {
+	s.queueOnly = queueOnly
+}
+
+// sendDeliverPayload 投递任务的参数
+type sendDeliverPayload struct {
+	SendID string `json:"send_id"`
+}
+
+// SetJobQueue 只入队模式写入的邮件通过任务队列投递，并注册投递任务的处理函数
+func (s *StandardEmailSender) SetJobQueue(queue JobQueueService) {
+	s.jobs = queue
+	queue.Register(JobTypeSendDeliver, func(ctx context.Context, job *models.Job) error {
+		var payload sendDeliverPayload
+		if err := DecodeJobPayload(job, &payload); err != nil {
+			return err
+		}
+		return s.DeliverQueuedSend(ctx, payload.SendID)
+	})
+}
+
+// enqueueDelivery 为写入发送队列的邮件创建投递任务，入队失败时由工作进程启动时的扫描补发
+func (s *StandardEmailSender) enqueueDelivery(ctx context.Context, queued *models.SendQueue) {
+	if s.jobs == nil {
+		return
+	}
+	if _, err := s.jobs.Enqueue(ctx, JobTypeSendDeliver, sendDeliverPayload{SendID: queued.SendID}, &JobOptions{
+		UserID:    queued.UserID,
+		UniqueKey: "send:" + queued.SendID,
+	}); err != nil {
+		log.Printf("Failed to enqueue delivery for send %s: %v", queued.SendID, err)
+	}
+}
+
+// DeliverQueuedSend 认领并投递发送队列中的一封邮件。上次投递失败的邮件同样可以认领，
+// 投递任务重试时重新投递；已被认领或已发出的邮件直接返回
+func (s *StandardEmailSender) DeliverQueuedSend(ctx context.Context, sendID string) error {
+	var entry models.SendQueue
+	if err := s.db.WithContext(ctx).Where("send_id = ? AND raw_message IS NOT NULL", sendID).First(&entry).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: send %s not found", ErrJobPermanent, sendID)
+		}
+		return fmt.Errorf("failed to load send queue entry: %w", err)
+	}
+
+	result := s.db.WithContext(ctx).Model(&models.SendQueue{}).
+		Where("id = ? AND status IN ?", entry.ID, []string{outboundStatusQueued, outboundStatusFailed, outboundStatusRateLimited}).
+		Update("status", outboundStatusSending)
+	if result.Error != nil {
+		return fmt.Errorf("failed to claim queued send: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+	entry.Status = outboundStatusSending
+
+	account, err := s.getEmailAccount(ctx, entry.AccountID)
+	if err != nil {
+		err = fmt.Errorf("failed to get email account: %w", err)
+		s.markOutbound(ctx, &entry, outboundStatusFailed, err)
+		return err
+	}
+
+	s.inFlight.Add(1)
+	defer s.inFlight.Done()
+	return s.resumeSend(ctx, account, &entry)
+}
+
+// ProcessQueuedSends 认领一批待投递的邮件并异步投递，返回认领的数量。工作进程启动时调用，
+// 补发没有投递任务的邮件（如入队失败）；认领通过条件更新状态完成，与投递任务同时运行也不会重复投递
+func (s *StandardEmailSender) ProcessQueuedSends(ctx context.Context) (int, error) {
+	var queued []models.SendQueue
+	if err := s.db.WithContext(ctx).
+		Where("raw_message IS NOT NULL AND status = ?", outboundStatusQueued).
+		Order("id ASC").
+		Limit(queuedSendBatchSize).
+		Find(&queued).Error; err != nil {
+		return 0, fmt.Errorf("failed to query queued sends: %w", err)
+	}
+
+	claimed := 0
+	for i := range queued {
+		entry := &queued[i]
+		result := s.db.WithContext(ctx).Model(&models.SendQueue{}).
+			Where("id = ? AND status = ?", entry.ID, outboundStatusQueued).
+			Update("status", outboundStatusSending)
+		if result.Error != nil {
+			return claimed, fmt.Errorf("failed to claim queued send: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		entry.Status = outboundStatusSending
+		claimed++
+
+		account, err := s.getEmailAccount(ctx, entry.AccountID)
+		if err != nil {
+			s.markOutbound(ctx, entry, outboundStatusFailed, fmt.Errorf("failed to get email account: %w", err))
+			continue
+		}
+		s.inFlight.Add(1)
+		go func() {
+			defer s.inFlight.Done()
+			if err := s.resumeSend(ctx, account, entry); err != nil {
+				log.Printf("Failed to deliver queued send %s: %v", entry.SendID, err)
+			}
+		}()
+	}
+	return claimed, nil
+}
+
+// ResumePendingSends 继续投递上次进程退出时未完成的邮件。
+// 正在投递中的邮件无法确认服务器是否已经收下，宁可重复投递也不丢失邮件。
+func (s *StandardEmailSender) ResumePendingSends(ctx context.Context) (int, error) {
+	var pending []models.SendQueue
+	if err := s.db.WithContext(ctx).
+		Where("raw_message IS NOT NULL AND status IN ?", []string{outboundStatusPending, outboundStatusSending}).
+		Order("id ASC").
+		Find(&pending).Error; err != nil {
+		return 0, fmt.Errorf("failed to query pending sends: %w", err)
+	}
+
+	for i := range pending {
+		queued := &pending[i]
+		account, err := s.getEmailAccount(ctx, queued.AccountID)
+		if err != nil {
+			s.markOutbound(ctx, queued, outboundStatusFailed, fmt.Errorf("failed to get email account: %w", err))
+			continue
+		}
+
+		log.Printf("Resuming interrupted send %s", queued.SendID)
+		s.inFlight.Add(1)
+		go func() {
+			defer s.inFlight.Done()
+			if err := s.resumeSend(ctx, account, queued); err != nil {
+				log.Printf("Failed to resume send %s: %v", queued.SendID, err)
+			}
+		}()
+	}
+	return len(pending), nil
+}
+
+// resumeSend 按保存的MIME原文重新投递
+func (s *StandardEmailSender) resumeSend(ctx context.Context, account *models.EmailAccount, queued *models.SendQueue) error {
+	provider, err := s.providerFactory.CreateProviderForAccount(account)
+	if err != nil {
+		err = fmt.Errorf("failed to create provider: %w", err)
+		s.markOutbound(ctx, queued, outboundStatusFailed, err)
+		return err
+	}
+
+	if err := s.deliverWithRetry(ctx, provider, account, queued); err != nil {
+		status := outboundStatusFailed
+		if _, ok := providers.RetryAfter(err); ok {
+			status = outboundStatusRateLimited
+		}
+		s.markOutbound(ctx, queued, status, err)
+		return err
+	}
+
+	s.markOutbound(ctx, queued, outboundStatusSent, nil)
+	s.recordQueuedSent(ctx, account, queued)
+	if s.eventPublisher != nil {
+		event := sse.NewEmailSendEvent("email_send_completed", queued.SendID, "", account.UserID)
+		s.eventPublisher.PublishToUser(ctx, account.UserID, event)
+	}
+	return nil
+}
+
+// recordQueuedSent 按发送队列记录补写已发送邮件记录
+func (s *StandardEmailSender) recordQueuedSent(ctx context.Context, account *models.EmailAccount, queued *models.SendQueue) {
+	if !s.config.SaveSentEmails {
+		return
+	}
+	sentEmail := &models.SentEmail{
+		SendID:     queued.SendID,
+		AccountID:  account.ID,
+		Subject:    queued.Subject,
+		Recipients: queued.Recipients,
+		SentAt:     time.Now(),
+		Status:     outboundStatusSent,
+		Size:       int64(len(queued.RawMessage)),
+	}
+	if err := s.db.WithContext(context.WithoutCancel(ctx)).Create(sentEmail).Error; err != nil {
+		log.Printf("Failed to save sent email %s: %v", queued.SendID, err)
+	}
+}
+
+// ListStuckSends 列出超过阈值仍未发出的邮件：等待投递、投递中、等待重试或早已到期的定时邮件
+func (s *StandardEmailSender) ListStuckSends(ctx context.Context, olderThan time.Duration) ([]StuckSend, error) {
+	if olderThan <= 0 {
+		olderThan = defaultStuckSendThreshold
+	}
+	cutoff := time.Now().Add(-olderThan)
+
+	var entries []models.SendQueue
+	if err := s.db.WithContext(ctx).
+		Where("(status IN ? AND updated_at <= ?) OR (status = ? AND next_attempt <= ?) OR (status = ? AND scheduled_at <= ?)",
+			[]string{outboundStatusPending, outboundStatusQueued, outboundStatusSending, "processing"}, cutoff,
+			"retry", cutoff,
+			"scheduled", cutoff).
+		Order("created_at ASC").
+		Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to query stuck sends: %w", err)
+	}
+
+	stuck := make([]StuckSend, 0, len(entries))
+	for _, entry := range entries {
+		var recipients []string
+		if entry.Recipients != "" {
+			recipients = strings.Split(entry.Recipients, ",")
+		}
+		stuck = append(stuck, StuckSend{
+			ID:          entry.ID,
+			SendID:      entry.SendID,
+			UserID:      entry.UserID,
+			AccountID:   entry.AccountID,
+			Subject:     entry.Subject,
+			Recipients:  recipients,
+			Status:      entry.Status,
+			Attempts:    entry.Attempts,
+			HasMIME:     len(entry.RawMessage) > 0,
+			LastError:   entry.LastError,
+			ScheduledAt: entry.ScheduledAt,
+			LastAttempt: entry.LastAttempt,
+			NextAttempt: entry.NextAttempt,
+			CreatedAt:   entry.CreatedAt,
+			UpdatedAt:   entry.UpdatedAt,
+		})
+	}
+	return stuck, nil
+}
+
+// loadSendStatusFromDB 从发送队列加载发送状态，用于进程重启后查询
+func (s *StandardEmailSender) loadSendStatusFromDB(ctx context.Context, sendID string) (*SendStatus, error) {
+	var queued models.SendQueue
+	if err := s.db.WithContext(ctx).Where("send_id = ?", sendID).First(&queued).Error; err != nil {
+		return nil, fmt.Errorf("send status not found: %s", sendID)
+	}
+
+	total := 0
+	if queued.Recipients != "" {
+		total = len(strings.Split(queued.Recipients, ","))
+	}
+	status := &SendStatus{
+		SendID:          queued.SendID,
+		Status:          queued.Status,
+		TotalRecipients: total,
+		StartTime:       queued.CreatedAt,
+		Error:           queued.LastError,
+	}
+	switch queued.Status {
+	case outboundStatusQueued:
+		// 等待工作进程认领，对调用方与等待投递相同
+		status.Status = outboundStatusPending
+	case outboundStatusSent:
+		status.Progress = 1.0
+		status.SentRecipients = total
+	case outboundStatusFailed, outboundStatusRateLimited:
+		status.FailedRecipients = total
+	}
+	return status, nil
+}
@@ -0,0 +1,311 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"firemail/internal/config"
+	"firemail/internal/models"
+	"firemail/internal/providers"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSMTPClient 记录投递的原始邮件
+type recordingSMTPClient struct {
+	mu        sync.Mutex
+	err       error
+	reject    string // 被拒绝的收件人
+	connected bool
+	sends     []recordedRawSend
+}
+
+type recordedRawSend struct {
+	from string
+	to   []string
+	data []byte
+}
+
+func (c *recordingSMTPClient) Connect(context.Context, providers.SMTPClientConfig) error { return nil }
+func (c *recordingSMTPClient) Disconnect() error                                         { return nil }
+func (c *recordingSMTPClient) IsConnected() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.connected
+}
+func (c *recordingSMTPClient) SendEmail(context.Context, *providers.OutgoingMessage) error {
+	return errors.New("unexpected structured send")
+}
+func (c *recordingSMTPClient) SendRawEmail(_ context.Context, from string, to []string, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	for _, recipient := range to {
+		if recipient == c.reject {
+			return errors.New("550 no such user " + recipient)
+		}
+	}
+	c.sends = append(c.sends, recordedRawSend{from: from, to: to, data: data})
+	return nil
+}
+
+// smtpTestProvider 在假提供商上挂接可记录的SMTP客户端
+type smtpTestProvider struct {
+	*fakeEmailProvider
+	smtp *recordingSMTPClient
+}
+
+func (p *smtpTestProvider) SMTPClient() providers.SMTPClient { return p.smtp }
+
+func (p *smtpTestProvider) Connect(ctx context.Context, account *models.EmailAccount) error {
+	if err := p.fakeEmailProvider.Connect(ctx, account); err != nil {
+		return err
+	}
+	p.smtp.mu.Lock()
+	p.smtp.connected = true
+	p.smtp.mu.Unlock()
+	return nil
+}
+
+func (p *smtpTestProvider) Disconnect() error {
+	p.smtp.mu.Lock()
+	p.smtp.connected = false
+	p.smtp.mu.Unlock()
+	return p.fakeEmailProvider.Disconnect()
+}
+
+func setupOutboundQueueTest(t *testing.T) (*emailStateServiceTestEnv, *StandardEmailSender, *recordingSMTPClient) {
+	t.Helper()
+
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.SendQueue{}, &models.SentEmail{}))
+
+	smtp := &recordingSMTPClient{}
+	factory := providers.NewProviderFactory()
+	factory.RegisterProvider("custom", func(*config.EmailProviderConfig) providers.EmailProvider {
+		return &smtpTestProvider{fakeEmailProvider: env.provider, smtp: smtp}
+	})
+
+	sender, ok := NewStandardEmailSender(env.db, factory, nil).(*StandardEmailSender)
+	require.True(t, ok)
+	return env, sender, smtp
+}
+
+func waitForSends(t *testing.T, sender *StandardEmailSender) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, sender.Wait(ctx))
+}
+
+func TestOutboundQueuePersistsMIMEBeforeDelivery(t *testing.T) {
+	env, sender, smtp := setupOutboundQueueTest(t)
+	ctx := context.Background()
+
+	result, err := sender.SendEmail(ctx, &ComposedEmail{
+		ID:       "composed-1",
+		From:     &models.EmailAddress{Address: "tester@example.com"},
+		To:       []*models.EmailAddress{{Address: "alice@example.org"}},
+		BCC:      []*models.EmailAddress{{Address: "audit@example.org"}},
+		Subject:  "Quarterly report",
+		TextBody: "See the numbers.",
+	}, env.account.ID)
+	require.NoError(t, err)
+	waitForSends(t, sender)
+
+	var queued models.SendQueue
+	require.NoError(t, env.db.Where("send_id = ?", result.SendID).First(&queued).Error)
+	require.Equal(t, outboundStatusSent, queued.Status)
+	require.Equal(t, 1, queued.Attempts)
+	require.Equal(t, "Quarterly report", queued.Subject)
+	require.Contains(t, string(queued.RawMessage), "Subject: Quarterly report")
+
+	require.Len(t, smtp.sends, 1)
+	require.Equal(t, "tester@example.com", smtp.sends[0].from)
+	require.Equal(t, []string{"alice@example.org", "audit@example.org"}, smtp.sends[0].to)
+	require.Equal(t, queued.RawMessage, smtp.sends[0].data)
+	require.NotContains(t, string(smtp.sends[0].data), "audit@example.org", "BCC recipients must stay out of the headers")
+
+	status, err := sender.GetSendStatus(ctx, result.SendID)
+	require.NoError(t, err)
+	require.Equal(t, "sent", status.Status)
+}
+
+func TestOutboundQueueResumesInterruptedSends(t *testing.T) {
+	env, sender, smtp := setupOutboundQueueTest(t)
+	ctx := context.Background()
+
+	raw := []byte("From: tester@example.com\r\nTo: bob@example.org\r\nSubject: Left behind\r\n\r\nhello\r\n")
+	newEntry := func(sendID, status string) *models.SendQueue {
+		entry := &models.SendQueue{
+			SendID:       sendID,
+			UserID:       env.user.ID,
+			AccountID:    env.account.ID,
+			EmailData:    "{}",
+			RawMessage:   raw,
+			EnvelopeFrom: "tester@example.com",
+			Recipients:   "bob@example.org",
+			Subject:      "Left behind",
+			Status:       status,
+		}
+		require.NoError(t, env.db.Create(entry).Error)
+		return entry
+	}
+	pending := newEntry("pending-1", outboundStatusPending)
+	sending := newEntry("sending-1", outboundStatusSending)
+	newEntry("sent-1", outboundStatusSent)
+
+	// 超过阈值仍未发出的邮件出现在卡住列表中
+	require.NoError(t, env.db.Model(pending).UpdateColumn("updated_at", time.Now().Add(-time.Hour)).Error)
+	stuck, err := sender.ListStuckSends(ctx, 30*time.Minute)
+	require.NoError(t, err)
+	require.Len(t, stuck, 1)
+	require.Equal(t, "pending-1", stuck[0].SendID)
+	require.True(t, stuck[0].HasMIME)
+	require.Equal(t, []string{"bob@example.org"}, stuck[0].Recipients)
+
+	resumed, err := sender.ResumePendingSends(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, resumed)
+	waitForSends(t, sender)
+
+	require.Len(t, smtp.sends, 2)
+	for _, send := range smtp.sends {
+		require.Equal(t, raw, send.data)
+	}
+	for _, entry := range []*models.SendQueue{pending, sending} {
+		require.NoError(t, env.db.First(entry, entry.ID).Error)
+		require.Equal(t, outboundStatusSent, entry.Status)
+	}
+
+	// 重启后仍可从队列查询发送状态
+	status, err := sender.GetSendStatus(ctx, "sending-1")
+	require.NoError(t, err)
+	require.Equal(t, "sent", status.Status)
+	require.Equal(t, 1, status.SentRecipients)
+}
+
+func TestOutboundQueueRecordsDeliveryFailure(t *testing.T) {
+	env, sender, smtp := setupOutboundQueueTest(t)
+	smtp.err = errors.New("550 mailbox unavailable")
+
+	result, err := sender.SendEmail(context.Background(), &ComposedEmail{
+		ID:       "composed-2",
+		From:     &models.EmailAddress{Address: "tester@example.com"},
+		To:       []*models.EmailAddress{{Address: "nobody@example.org"}},
+		Subject:  "Bounce",
+		TextBody: "hello",
+	}, env.account.ID)
+	require.NoError(t, err)
+	waitForSends(t, sender)
+
+	var queued models.SendQueue
+	require.NoError(t, env.db.Where("send_id = ?", result.SendID).First(&queued).Error)
+	require.Equal(t, outboundStatusFailed, queued.Status)
+	require.True(t, strings.Contains(queued.LastError, "mailbox unavailable"))
+
+	// 未能保存MIME的邮件不会进入投递
+	_, err = sender.SendEmail(context.Background(), &ComposedEmail{ID: "composed-3", Subject: "No sender"}, env.account.ID)
+	require.Error(t, err)
+}
+
+func TestBulkSendReusesOneSMTPSession(t *testing.T) {
+	env, sender, smtp := setupOutboundQueueTest(t)
+	smtp.reject = "gone@example.org"
+
+	var emails []*ComposedEmail
+	for _, to := range []string{"a@example.org", "gone@example.org", "b@example.org", "c@example.org"} {
+		emails = append(emails, &ComposedEmail{
+			ID:       "bulk-" + to,
+			From:     &models.EmailAddress{Address: "tester@example.com"},
+			To:       []*models.EmailAddress{{Address: to}},
+			Subject:  "Newsletter",
+			TextBody: "hello",
+		})
+	}
+	// 无法保存的邮件直接返回失败，不影响其余邮件
+	emails = append(emails, &ComposedEmail{ID: "bulk-invalid", Subject: "No sender"})
+
+	results, err := sender.SendBulkEmails(context.Background(), emails, env.account.ID)
+	require.NoError(t, err)
+	require.Len(t, results, 5)
+	require.Equal(t, "bulk-a@example.org", results[0].EmailID)
+	require.Equal(t, "failed", results[4].Status)
+	waitForSends(t, sender)
+
+	// 被拒绝的收件人不会中断会话，其余邮件都经由同一个会话发出
+	require.Len(t, smtp.sends, 3)
+	require.Equal(t, 1, env.provider.connectCalls)
+
+	statuses := make(map[string]string)
+	var queued []models.SendQueue
+	require.NoError(t, env.db.Find(&queued).Error)
+	for _, entry := range queued {
+		statuses[entry.Recipients] = entry.Status
+	}
+	require.Equal(t, map[string]string{
+		"a@example.org":    outboundStatusSent,
+		"gone@example.org": outboundStatusFailed,
+		"b@example.org":    outboundStatusSent,
+		"c@example.org":    outboundStatusSent,
+	}, statuses)
+	require.Equal(t, "failed", results[1].Status)
+}
+
+func TestOutboundQueueWorkerDeliversQueuedSends(t *testing.T) {
+	env, worker, smtp := setupOutboundQueueTest(t)
+	ctx := context.Background()
+
+	api, ok := NewStandardEmailSender(env.db, worker.providerFactory, nil).(*StandardEmailSender)
+	require.True(t, ok)
+	api.SetQueueOnly(true)
+
+	result, err := api.SendEmail(ctx, &ComposedEmail{
+		ID:       "composed-queued",
+		From:     &models.EmailAddress{Address: "tester@example.com"},
+		To:       []*models.EmailAddress{{Address: "alice@example.org"}},
+		Subject:  "Handed off",
+		TextBody: "Delivered by the worker.",
+	}, env.account.ID)
+	require.NoError(t, err)
+	waitForSends(t, api)
+	require.Empty(t, smtp.sends)
+
+	var queued models.SendQueue
+	require.NoError(t, env.db.Where("send_id = ?", result.SendID).First(&queued).Error)
+	require.Equal(t, outboundStatusQueued, queued.Status)
+	status, err := api.GetSendStatus(ctx, result.SendID)
+	require.NoError(t, err)
+	require.Equal(t, outboundStatusPending, status.Status)
+
+	// 中断恢复只处理本进程写入的邮件，不认领其他进程入队的邮件
+	resumed, err := worker.ResumePendingSends(ctx)
+	require.NoError(t, err)
+	require.Zero(t, resumed)
+
+	claimed, err := worker.ProcessQueuedSends(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, claimed)
+	waitForSends(t, worker)
+
+	claimed, err = worker.ProcessQueuedSends(ctx)
+	require.NoError(t, err)
+	require.Zero(t, claimed)
+
+	require.Len(t, smtp.sends, 1)
+	require.Equal(t, []string{"alice@example.org"}, smtp.sends[0].to)
+
+	status, err = api.GetSendStatus(ctx, result.SendID)
+	require.NoError(t, err)
+	require.Equal(t, "sent", status.Status)
+
+	var sent models.SentEmail
+	require.NoError(t, env.db.Where("send_id = ?", result.SendID).First(&sent).Error)
+	require.Equal(t, "Handed off", sent.Subject)
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"firemail/internal/config"
+	"firemail/internal/models"
+)
+
+// maxMailerLength 客户端名称的长度上限
+const maxMailerLength = 100
+
+// applyOutgoingIdentity 校验并设置账户的外发邮件标识，传nil的项保持不变，空字符串表示使用实例配置
+func applyOutgoingIdentity(account *models.EmailAccount, ehloName, messageIDDomain, mailer *string) error {
+	if ehloName != nil {
+		name := strings.TrimSpace(*ehloName)
+		if name != "" && !config.ValidEHLOName(name) {
+			return fmt.Errorf("invalid ehlo_name: %s", name)
+		}
+		account.EHLOName = name
+	}
+	if messageIDDomain != nil {
+		domain := strings.ToLower(strings.TrimSpace(*messageIDDomain))
+		if domain != "" && !config.ValidHostname(domain) {
+			return fmt.Errorf("invalid message_id_domain: %s", domain)
+		}
+		account.MessageIDDomain = domain
+	}
+	if mailer != nil {
+		name := strings.TrimSpace(*mailer)
+		if strings.ContainsAny(name, "\r\n") || len(name) > maxMailerLength {
+			return fmt.Errorf("invalid mailer: must be a single line of at most %d characters", maxMailerLength)
+		}
+		account.Mailer = name
+	}
+	return nil
+}
@@ -148,6 +148,8 @@ func (d *OutlookDeduplicator) checkConversationIDDuplicate(ctx context.Context,
 
 // HandleDuplicate Outlook特殊的重复处理逻辑
 func (d *OutlookDeduplicator) HandleDuplicate(ctx context.Context, existing *models.Email, new *providers.EmailMessage, folderID uint) error {
+	before := *existing
+
 	// 更新文件夹信息
 	if existing.FolderID == nil || *existing.FolderID != folderID {
 		existing.FolderID = &folderID
@@ -157,9 +159,7 @@ func (d *OutlookDeduplicator) HandleDuplicate(ctx context.Context, existing *mod
 	existing.UID = new.UID
 
 	// 更新邮件状态
-	existing.IsRead = d.isEmailRead(new.Flags)
-	existing.IsStarred = d.isEmailStarred(new.Flags)
-	existing.IsDraft = d.isEmailDraft(new.Flags)
+	d.applySyncedFlags(existing, new.Flags)
 
 	// 更新Outlook特有的属性
 	if err := d.updateOutlookProperties(ctx, existing, new); err != nil {
@@ -171,7 +171,7 @@ func (d *OutlookDeduplicator) HandleDuplicate(ctx context.Context, existing *mod
 		existing.MessageID = new.MessageID
 	}
 
-	return d.db.WithContext(ctx).Save(existing).Error
+	return saveSyncedEmailState(d.db.WithContext(ctx), &before, existing)
 }
 
 // normalizeOutlookMessageID 标准化Outlook MessageID
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// importanceThreshold 得分达到该值的邮件归入重要
+const importanceThreshold = 2
+
+// automatedSenderPrefixes 自动发送邮件常见的发件人前缀，这类邮件通常不需要优先处理
+var automatedSenderPrefixes = []string{
+	"noreply", "no-reply", "donotreply", "do-not-reply", "notification",
+	"newsletter", "mailer-daemon", "postmaster", "bounce", "marketing",
+}
+
+// senderAddressCondition 按发件人地址匹配邮件，兼容"Name <addr>"格式
+const senderAddressCondition = "(LOWER(emails.from_address) = ? OR LOWER(emails.from_address) LIKE ? ESCAPE '\\')"
+
+// senderImportanceAvailable 发件人重要性反馈表是否存在
+func senderImportanceAvailable(db *gorm.DB) bool {
+	return db.Migrator().HasTable(&models.SenderImportance{})
+}
+
+// classifyEmailImportance 根据规则、发件人历史和回复历史为新邮件打分并归类
+func classifyEmailImportance(db *gorm.DB, account *models.EmailAccount, email *models.Email) {
+	email.ImportanceScore = emailImportanceScore(db, account, email)
+	email.ImportanceBucket = models.ImportanceBucketOther
+	if email.ImportanceScore >= importanceThreshold {
+		email.ImportanceBucket = models.ImportanceBucketImportant
+	}
+}
+
+// emailImportanceScore 计算邮件的重要性得分，查询失败的信号忽略不计
+func emailImportanceScore(db *gorm.DB, account *models.EmailAccount, email *models.Email) int {
+	if isSentByAccount(email.From, account.Email) {
+		return 0
+	}
+
+	score := 0
+
+	// 规则：邮件优先级和是否直接发给自己
+	switch email.Priority {
+	case "high":
+		score++
+	case "low":
+		score--
+	}
+	if to, err := email.GetToAddresses(); err == nil {
+		for _, address := range to {
+			if isOwnEmailAddress(address.Address, account.Email) {
+				score++
+				break
+			}
+		}
+	}
+
+	sender := parseEmailAddress(email.From)
+	if sender == nil || sender.Address == "" {
+		return score
+	}
+	address := strings.ToLower(strings.TrimSpace(sender.Address))
+	localPart := address
+	if at := strings.Index(address, "@"); at >= 0 {
+		localPart = address[:at]
+	}
+	for _, prefix := range automatedSenderPrefixes {
+		if strings.HasPrefix(localPart, prefix) {
+			score -= 2
+			break
+		}
+	}
+
+	// 用户反馈：对该发件人的明确反馈权重最高
+	if senderImportanceAvailable(db) {
+		var feedback models.SenderImportance
+		err := db.Where("user_id = ? AND sender = ?", account.UserID, address).Take(&feedback).Error
+		if err == nil {
+			if feedback.ImportantCount > feedback.OtherCount {
+				score += 3
+			} else if feedback.OtherCount > feedback.ImportantCount {
+				score -= 3
+			}
+		} else if err != gorm.ErrRecordNotFound {
+			log.Printf("Warning: failed to load sender importance feedback: %v", err)
+		}
+	}
+
+	// 发件人历史：之前的邮件被加星标或标记为重要
+	var flagged int64
+	if err := db.Model(&models.Email{}).
+		Where("emails.user_id = ? AND (emails.is_starred = ? OR emails.is_important = ?)", account.UserID, true, true).
+		Where(senderAddressCondition, address, "%<"+escapeLike(address)+">").
+		Limit(1).Count(&flagged).Error; err != nil {
+		log.Printf("Warning: failed to check sender history: %v", err)
+	} else if flagged > 0 {
+		score++
+	}
+
+	// 回复历史：用户曾给该发件人发过邮件
+	var replied int64
+	if err := db.Model(&models.Email{}).
+		Joins("JOIN email_accounts ON emails.account_id = email_accounts.id").
+		Where("emails.user_id = ?", account.UserID).
+		Where(ownAddressCondition).
+		Where("LOWER(emails.to_addresses) LIKE ? ESCAPE '\\'", `%"`+escapeLike(address)+`"%`).
+		Limit(1).Count(&replied).Error; err != nil {
+		log.Printf("Warning: failed to check reply history: %v", err)
+	} else if replied > 0 {
+		score += 2
+	}
+
+	return score
+}
+
+// SetEmailImportance 用户将邮件标记为重要或非重要，同时记录对发件人的反馈用于后续分类
+func (s *EmailServiceImpl) SetEmailImportance(ctx context.Context, userID, emailID uint, bucket string) (*models.Email, error) {
+	if bucket != models.ImportanceBucketImportant && bucket != models.ImportanceBucketOther {
+		return nil, fmt.Errorf("invalid importance bucket: %s", bucket)
+	}
+
+	email, err := s.getEmailForUser(ctx, userID, emailID, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkEmailVersion(ctx, email); err != nil {
+		return nil, err
+	}
+	if email.ImportanceManual && email.ImportanceBucket == bucket {
+		return email, nil
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.updateEmailColumns(ctx, tx, email, map[string]interface{}{
+			"importance_bucket": bucket,
+			"importance_manual": true,
+		}); err != nil {
+			if errors.Is(err, ErrVersionConflict) {
+				return err
+			}
+			return fmt.Errorf("failed to update email importance: %w", err)
+		}
+
+		sender := parseEmailAddress(email.From)
+		if sender == nil || sender.Address == "" || !senderImportanceAvailable(tx) {
+			return nil
+		}
+
+		// 之前已反馈过的邮件改判时，撤销原来的计数
+		important, other := 0, 0
+		if bucket == models.ImportanceBucketImportant {
+			important++
+			if email.ImportanceManual {
+				other--
+			}
+		} else {
+			other++
+			if email.ImportanceManual {
+				important--
+			}
+		}
+
+		feedback := models.SenderImportance{
+			UserID:         userID,
+			Sender:         strings.ToLower(strings.TrimSpace(sender.Address)),
+			ImportantCount: max(important, 0),
+			OtherCount:     max(other, 0),
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}, {Name: "sender"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"important_count": gorm.Expr("MAX(important_count + ?, 0)", important),
+				"other_count":     gorm.Expr("MAX(other_count + ?, 0)", other),
+				"updated_at":      gorm.Expr("CURRENT_TIMESTAMP"),
+			}),
+		}).Create(&feedback).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	email.ImportanceBucket = bucket
+	email.ImportanceManual = true
+
+	s.invalidateEmailListCache(userID, email.AccountID, email.FolderID)
+	recordEmailChanges(ctx, s.changeLog, userID, email.AccountID, models.ChangeActionUpdated, email.ID)
+
+	return email, nil
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyEmailImportanceSignals(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.SenderImportance{}))
+
+	newsletter := &models.Email{From: "Shop <newsletter@shop.example.com>", Priority: "normal"}
+	classifyEmailImportance(env.db, env.account, newsletter)
+	require.Equal(t, models.ImportanceBucketOther, newsletter.ImportanceBucket)
+	require.Equal(t, -2, newsletter.ImportanceScore)
+
+	direct := &models.Email{From: "carol@example.com", Priority: "high", To: `[{"address":"Tester@example.com"}]`}
+	classifyEmailImportance(env.db, env.account, direct)
+	require.Equal(t, models.ImportanceBucketImportant, direct.ImportanceBucket)
+
+	// 回复历史：用户给bob发过邮件
+	unknown := &models.Email{From: "Bob <bob@example.com>"}
+	classifyEmailImportance(env.db, env.account, unknown)
+	require.Equal(t, models.ImportanceBucketOther, unknown.ImportanceBucket)
+
+	sent := env.createEmail(t, env.work, 1, "Hello Bob", true, false)
+	require.NoError(t, env.db.Model(sent).Updates(map[string]interface{}{
+		"from_address": "Me <tester@example.com>",
+		"to_addresses": `[{"name":"Bob","address":"Bob@example.com"}]`,
+	}).Error)
+	classifyEmailImportance(env.db, env.account, unknown)
+	require.Equal(t, models.ImportanceBucketImportant, unknown.ImportanceBucket)
+	require.Equal(t, 2, unknown.ImportanceScore)
+
+	// 自己发出的邮件不参与分类
+	own := &models.Email{From: "tester@example.com", Priority: "high"}
+	classifyEmailImportance(env.db, env.account, own)
+	require.Equal(t, models.ImportanceBucketOther, own.ImportanceBucket)
+}
+
+func TestSetEmailImportanceTrainsClassifier(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.SenderImportance{}))
+	ctx := context.Background()
+
+	email := env.createEmail(t, env.inbox, 1, "Status", false, false)
+	env.createEmail(t, env.inbox, 2, "Other", false, false)
+
+	updated, err := env.service.SetEmailImportance(ctx, env.user.ID, email.ID, models.ImportanceBucketImportant)
+	require.NoError(t, err)
+	require.Equal(t, models.ImportanceBucketImportant, updated.ImportanceBucket)
+	require.True(t, updated.ImportanceManual)
+
+	// 重复反馈不会重复计数
+	_, err = env.service.SetEmailImportance(ctx, env.user.ID, email.ID, models.ImportanceBucketImportant)
+	require.NoError(t, err)
+
+	var feedback models.SenderImportance
+	require.NoError(t, env.db.Where("user_id = ? AND sender = ?", env.user.ID, "sender@example.com").Take(&feedback).Error)
+	require.Equal(t, 1, feedback.ImportantCount)
+	require.Zero(t, feedback.OtherCount)
+
+	incoming := &models.Email{From: "Sender <SENDER@example.com>"}
+	classifyEmailImportance(env.db, env.account, incoming)
+	require.Equal(t, models.ImportanceBucketImportant, incoming.ImportanceBucket)
+
+	list, err := env.service.GetEmails(ctx, env.user.ID, &GetEmailsRequest{ImportanceBucket: models.ImportanceBucketImportant})
+	require.NoError(t, err)
+	require.Len(t, list.Emails, 1)
+	require.Equal(t, email.ID, list.Emails[0].ID)
+
+	// 改判时撤销原来的反馈
+	_, err = env.service.SetEmailImportance(ctx, env.user.ID, email.ID, models.ImportanceBucketOther)
+	require.NoError(t, err)
+	require.NoError(t, env.db.Where("user_id = ? AND sender = ?", env.user.ID, "sender@example.com").Take(&feedback).Error)
+	require.Zero(t, feedback.ImportantCount)
+	require.Equal(t, 1, feedback.OtherCount)
+
+	classifyEmailImportance(env.db, env.account, incoming)
+	require.Equal(t, models.ImportanceBucketOther, incoming.ImportanceBucket)
+
+	_, err = env.service.SetEmailImportance(ctx, env.user.ID, email.ID, "urgent")
+	require.EqualError(t, err, "invalid importance bucket: urgent")
+	_, err = env.service.SetEmailImportance(ctx, env.user.ID+1, email.ID, models.ImportanceBucketOther)
+	require.EqualError(t, err, "email not found")
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"sort"
+
+	"firemail/internal/config"
+	"firemail/internal/models"
+	"firemail/internal/providers"
+)
+
+// providerQuirks 提供商配置中的特殊行为，没有时返回nil（ProviderQuirks 的方法可以处理nil）
+func providerQuirks(name string) *config.ProviderQuirks {
+	if providerConfig := config.GetProviderByName(name); providerConfig != nil {
+		return providerConfig.Quirks
+	}
+	return nil
+}
+
+// sortFoldersByPriority 把提供商优先同步的文件夹排到前面，其余文件夹保持原有顺序
+func sortFoldersByPriority(folders []models.Folder, quirks *config.ProviderQuirks) {
+	if quirks == nil || len(quirks.PriorityFolders) == 0 {
+		return
+	}
+	sort.SliceStable(folders, func(i, j int) bool {
+		return quirks.PriorityRank(folders[i].Path) < quirks.PriorityRank(folders[j].Path)
+	})
+}
+
+// applyFolderQuirks 按提供商配置修正无法从名称识别的文件夹类型
+func applyFolderQuirks(folders []*providers.FolderInfo, quirks *config.ProviderQuirks) {
+	for _, folder := range folders {
+		if folderType := quirks.FolderType(folder.Name); folderType != "" {
+			folder.Type = folderType
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"strings"
+
+	"firemail/internal/models"
+)
+
+// formatMessageID 为Message-ID补上尖括号，空值返回空字符串
+func formatMessageID(id string) string {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return ""
+	}
+	if !strings.HasPrefix(id, "<") {
+		id = "<" + id
+	}
+	if !strings.HasSuffix(id, ">") {
+		id += ">"
+	}
+	return id
+}
+
+// replyHeaders 根据原邮件生成回复的In-Reply-To和References头。
+// 本地没有保存原邮件的References，使用会话首封邮件和原邮件的Message-ID，足以让收件方客户端归入同一会话
+func replyHeaders(original *models.Email) map[string]string {
+	messageID := formatMessageID(original.MessageID)
+	if messageID == "" {
+		return nil
+	}
+	return map[string]string{
+		"In-Reply-To": messageID,
+		"References":  threadReferences(original),
+	}
+}
+
+// forwardHeaders 转发邮件只设置References，保留与原会话的关联而不作为回复
+func forwardHeaders(original *models.Email) map[string]string {
+	if formatMessageID(original.MessageID) == "" {
+		return nil
+	}
+	return map[string]string{"References": threadReferences(original)}
+}
+
+// threadReferences 会话首封邮件与原邮件的Message-ID，以空格分隔
+func threadReferences(original *models.Email) string {
+	messageID := formatMessageID(original.MessageID)
+	root := formatMessageID(original.ThreadID)
+	if root == "" || root == messageID {
+		return messageID
+	}
+	return root + " " + messageID
+}
+
+// stripSubjectPrefixes 去掉主题开头重复的前缀，如 "Re: RE: 回复: "
+func stripSubjectPrefixes(subject string, prefixes []string) string {
+	subject = strings.TrimSpace(subject)
+	for {
+		lower := strings.ToLower(subject)
+		trimmed := false
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(lower, prefix) {
+				subject = strings.TrimSpace(subject[len(prefix):])
+				trimmed = true
+				break
+			}
+		}
+		if !trimmed {
+			return subject
+		}
+	}
+}
+
+// replySubject 回复邮件的主题，合并原主题中重复的回复前缀
+func replySubject(subject string) string {
+	return "Re: " + stripSubjectPrefixes(subject, replySubjectPrefixes)
+}
+
+// forwardSubject 转发邮件的主题，合并原主题中重复的转发前缀
+func forwardSubject(subject string) string {
+	return "Fwd: " + stripSubjectPrefixes(subject, forwardSubjectPrefixes)
+}
+
+// replyRecipient 回复的收件人：原邮件设置了Reply-To时回复到该地址，否则回复原发件人
+func replyRecipient(original *models.Email) *models.EmailAddress {
+	if address := parseEmailAddress(original.ReplyTo); address != nil && address.Address != "" {
+		return address
+	}
+	return parseEmailAddress(original.From)
+}
+
+// appendUniqueAddress 追加地址，跳过空地址、自己的地址和已存在的地址
+func appendUniqueAddress(addresses []*models.EmailAddress, address *models.EmailAddress, exclude ...string) []*models.EmailAddress {
+	if address == nil || strings.TrimSpace(address.Address) == "" {
+		return addresses
+	}
+	for _, excluded := range exclude {
+		if isOwnEmailAddress(address.Address, excluded) {
+			return addresses
+		}
+	}
+	if containsAddress(addresses, address.Address) {
+		return addresses
+	}
+	return append(addresses, address)
+}
+
+// containsAddress 地址列表中是否已有该地址，忽略大小写
+func containsAddress(addresses []*models.EmailAddress, address string) bool {
+	for _, existing := range addresses {
+		if isOwnEmailAddress(existing.Address, address) {
+			return true
+		}
+	}
+	return false
+}
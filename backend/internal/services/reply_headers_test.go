@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"firemail/internal/config"
+	"firemail/internal/models"
+	"firemail/internal/providers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplySubjectNormalization(t *testing.T) {
+	require.Equal(t, "Re: Plan", replySubject("Plan"))
+	require.Equal(t, "Re: Plan", replySubject("RE: Re: 回复：Plan"))
+	require.Equal(t, "Re: Fwd: Plan", replySubject("Re: Fwd: Plan"))
+	require.Equal(t, "Fwd: Plan", forwardSubject("FW: Fwd: Plan"))
+}
+
+func TestReplyHeadersReferenceThread(t *testing.T) {
+	original := &models.Email{MessageID: "parent@example.com", ThreadID: "<root@example.com>"}
+	require.Equal(t, map[string]string{
+		"In-Reply-To": "<parent@example.com>",
+		"References":  "<root@example.com> <parent@example.com>",
+	}, replyHeaders(original))
+	require.Equal(t, map[string]string{"References": "<root@example.com> <parent@example.com>"}, forwardHeaders(original))
+
+	// 会话首封邮件只引用自身
+	first := &models.Email{MessageID: "<root@example.com>", ThreadID: "<root@example.com>"}
+	require.Equal(t, "<root@example.com>", replyHeaders(first)["References"])
+	require.Nil(t, replyHeaders(&models.Email{}))
+}
+
+// capturingSMTPClient 记录结构化发送的邮件
+type capturingSMTPClient struct {
+	recordingSMTPClient
+	mu       sync.Mutex
+	messages []*providers.OutgoingMessage
+}
+
+func (c *capturingSMTPClient) SendEmail(_ context.Context, message *providers.OutgoingMessage) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = append(c.messages, message)
+	return nil
+}
+
+type capturingSMTPProvider struct {
+	*fakeEmailProvider
+	smtp *capturingSMTPClient
+}
+
+func (p *capturingSMTPProvider) SMTPClient() providers.SMTPClient { return p.smtp }
+
+func TestReplyAllHonorsReplyToAndThreads(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	smtp := &capturingSMTPClient{}
+	factory := providers.NewProviderFactory()
+	factory.RegisterProvider("custom", func(*config.EmailProviderConfig) providers.EmailProvider {
+		return &capturingSMTPProvider{fakeEmailProvider: env.provider, smtp: smtp}
+	})
+	service, ok := NewEmailService(env.db, factory, nil).(*EmailServiceImpl)
+	require.True(t, ok)
+
+	original := env.createEmail(t, env.inbox, 7, "Re: Launch", false, false)
+	require.NoError(t, env.db.Model(original).Updates(map[string]interface{}{
+		"reply_to":     "List <list@example.org>",
+		"thread_id":    "<launch@example.org>",
+		"to_addresses": `[{"address":"TESTER@example.com"},{"address":"bob@example.org"}]`,
+		"cc_addresses": `[{"address":"bob@example.org"},{"address":"carol@example.org"}]`,
+		"from_address": "Alice <alice@example.org>",
+	}).Error)
+
+	require.NoError(t, service.ReplyAllEmail(context.Background(), env.user.ID, original.ID, &ReplyEmailRequest{
+		AccountID: env.account.ID,
+		TextBody:  "Sounds good",
+	}))
+
+	require.Len(t, smtp.messages, 1)
+	message := smtp.messages[0]
+	require.Equal(t, "Re: Launch", message.Subject)
+	var to, cc []string
+	for _, addr := range message.To {
+		to = append(to, addr.Address)
+	}
+	for _, addr := range message.CC {
+		cc = append(cc, addr.Address)
+	}
+	require.Equal(t, []string{"list@example.org", "bob@example.org"}, to)
+	require.Equal(t, []string{"carol@example.org"}, cc)
+	require.Equal(t, original.MessageID, message.Headers["In-Reply-To"])
+	require.Equal(t, "<launch@example.org> "+original.MessageID, message.Headers["References"])
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"firemail/internal/i18n"
+	"firemail/internal/models"
+	"firemail/internal/sse"
+
+	"gorm.io/gorm"
+)
+
+// replyLaterCheckInterval 检查到期稍后回复的间隔
+const replyLaterCheckInterval = time.Minute
+
+// ErrInvalidReplyLater 稍后回复的提醒时间无效
+var ErrInvalidReplyLater = errors.New("invalid reply later")
+
+// ReplyLaterRequest 加入稍后回复的请求
+type ReplyLaterRequest struct {
+	RemindAt *time.Time `json:"remind_at,omitempty"` // 到期后邮件标记为未读并置顶，为空时只加入稍后回复列表
+}
+
+// ReplyLaterListRequest 稍后回复列表请求
+type ReplyLaterListRequest struct {
+	AccountID *uint `json:"account_id"`
+	Page      int   `json:"page"`
+	PageSize  int   `json:"page_size"`
+}
+
+// SetReplyLater 把邮件加入稍后回复，已在列表中时更新提醒时间
+func (s *EmailServiceImpl) SetReplyLater(ctx context.Context, userID, emailID uint, req *ReplyLaterRequest) (*models.Email, error) {
+	now := time.Now()
+	if req.RemindAt != nil && !req.RemindAt.After(now) {
+		return nil, fmt.Errorf("%w: remind_at must be in the future", ErrInvalidReplyLater)
+	}
+
+	email, err := s.getEmailForUser(ctx, userID, emailID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	replyLaterAt := email.ReplyLaterAt
+	if replyLaterAt == nil {
+		replyLaterAt = &now
+	}
+	if err := s.db.WithContext(ctx).Model(email).Updates(map[string]interface{}{
+		"reply_later_at":        replyLaterAt,
+		"reply_later_remind_at": req.RemindAt,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update reply later: %w", err)
+	}
+	added := email.ReplyLaterAt == nil
+	email.ReplyLaterAt = replyLaterAt
+	email.ReplyLaterRemindAt = req.RemindAt
+
+	s.invalidateEmailListCache(userID, email.AccountID, email.FolderID)
+	recordEmailChanges(ctx, s.changeLog, userID, email.AccountID, models.ChangeActionUpdated, email.ID)
+	if added {
+		recordEmailEvents(ctx, s.db, newEmailEvent(userID, email.AccountID, email.ID, models.EmailEventReplyLater, models.EmailEventSourceUser))
+	}
+	return email, nil
+}
+
+// ClearReplyLater 把邮件移出稍后回复
+func (s *EmailServiceImpl) ClearReplyLater(ctx context.Context, userID, emailID uint) (*models.Email, error) {
+	email, err := s.getEmailForUser(ctx, userID, emailID, false)
+	if err != nil {
+		return nil, err
+	}
+	if email.ReplyLaterAt == nil {
+		return email, nil
+	}
+
+	if err := s.db.WithContext(ctx).Model(email).Updates(map[string]interface{}{
+		"reply_later_at":        nil,
+		"reply_later_remind_at": nil,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to clear reply later: %w", err)
+	}
+	email.ReplyLaterAt = nil
+	email.ReplyLaterRemindAt = nil
+
+	s.invalidateEmailListCache(userID, email.AccountID, email.FolderID)
+	recordEmailChanges(ctx, s.changeLog, userID, email.AccountID, models.ChangeActionUpdated, email.ID)
+	return email, nil
+}
+
+// ListReplyLaterEmails 稍后回复列表，先加入的在前
+func (s *EmailServiceImpl) ListReplyLaterEmails(ctx context.Context, userID uint, req *ReplyLaterListRequest) (*GetEmailsResponse, error) {
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.Email{}).
+		Where("emails.user_id = ? AND emails.is_deleted = ? AND emails.reply_later_at IS NOT NULL", userID, false)
+	if req.AccountID != nil {
+		query = query.Where("emails.account_id = ?", *req.AccountID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count reply later emails: %w", err)
+	}
+
+	var emails []*models.Email
+	if err := query.Order("emails.reply_later_at ASC, emails.id ASC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize + 1).
+		Find(&emails).Error; err != nil {
+		return nil, fmt.Errorf("failed to list reply later emails: %w", err)
+	}
+	emails, hasMore, _ := trimEmailPage(emails, pageSize, false)
+
+	return &GetEmailsResponse{
+		Emails:     emails,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
+		HasMore:    hasMore,
+	}, nil
+}
+
+// ReturnDueReplyLater 处理到期的稍后回复：邮件标记为未读并置顶，回到收件箱顶部。
+// 邮件仍留在稍后回复列表中，直到用户回复或手动移出，返回处理的邮件数量
+func (s *EmailServiceImpl) ReturnDueReplyLater(ctx context.Context, now time.Time) (int, error) {
+	var due []*models.Email
+	if err := s.db.WithContext(ctx).
+		Where("reply_later_remind_at IS NOT NULL AND reply_later_remind_at <= ? AND is_deleted = ?", now, false).
+		Order("reply_later_remind_at ASC, id ASC").
+		Find(&due).Error; err != nil {
+		return 0, fmt.Errorf("failed to load due reply later emails: %w", err)
+	}
+
+	for _, email := range due {
+		// 到期提醒不受置顶数量上限限制
+		if err := s.db.WithContext(ctx).Model(email).Updates(map[string]interface{}{
+			"is_pinned":             true,
+			"pinned_at":             now,
+			"reply_later_remind_at": nil,
+		}).Error; err != nil {
+			return 0, fmt.Errorf("failed to return reply later email %d: %w", email.ID, err)
+		}
+		// 未读状态需要同步到服务器，失败时邮件仍然置顶
+		if email.IsRead {
+			if err := s.setEmailReadState(ctx, email.UserID, email.ID, false); err != nil {
+				log.Printf("Failed to mark reply later email %d as unread: %v", email.ID, err)
+			}
+		}
+
+		s.invalidateEmailListCache(email.UserID, email.AccountID, email.FolderID)
+		recordEmailChanges(ctx, s.changeLog, email.UserID, email.AccountID, models.ChangeActionUpdated, email.ID)
+		recordEmailEvents(ctx, s.db, newEmailEvent(email.UserID, email.AccountID, email.ID, models.EmailEventReplyLaterDue, models.EmailEventSourceUser))
+
+		if s.eventPublisher != nil {
+			locale := userLocale(ctx, s.db, email.UserID)
+			event := sse.NewPersistentNotificationEvent(
+				i18n.T(locale, "Reply later reminder"),
+				i18n.T(locale, "Time to reply: %s", email.Subject),
+				"info",
+				email.UserID,
+			)
+			if err := s.eventPublisher.PublishToUser(ctx, email.UserID, event); err != nil {
+				log.Printf("Failed to publish reply later event: %v", err)
+			}
+		}
+	}
+	return len(due), nil
+}
+
+// StartReplyLaterReminders 定期处理到期的稍后回复，ctx 取消后停止
+func (s *EmailServiceImpl) StartReplyLaterReminders(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(replyLaterCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if _, err := s.ReturnDueReplyLater(ctx, now); err != nil && ctx.Err() == nil {
+					log.Printf("Failed to process reply later reminders: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// clearReplyLaterThread 用户已回复会话时，把会话中在回复之前收到的邮件移出稍后回复
+func clearReplyLaterThread(db *gorm.DB, userID uint, threadKey string, repliedAt time.Time) int64 {
+	if threadKey == "" {
+		return 0
+	}
+	result := db.Model(&models.Email{}).
+		Where("user_id = ? AND reply_later_at IS NOT NULL AND (thread_id = ? OR message_id = ?) AND date <= ?",
+			userID, threadKey, threadKey, repliedAt).
+		Updates(map[string]interface{}{"reply_later_at": nil, "reply_later_remind_at": nil})
+	if result.Error != nil {
+		log.Printf("Warning: failed to clear reply later for thread %s: %v", threadKey, result.Error)
+		return 0
+	}
+	return result.RowsAffected
+}
+
+// clearReplyLaterOnReply 通过本应用回复邮件后清除该邮件所在会话的稍后回复
+func (s *EmailServiceImpl) clearReplyLaterOnReply(ctx context.Context, userID, emailID uint) {
+	email, err := s.getEmailForUser(ctx, userID, emailID, false)
+	if err != nil {
+		return
+	}
+	if clearReplyLaterThread(s.db.WithContext(ctx), userID, emailThreadKey(email), time.Now()) > 0 {
+		s.invalidateEmailListCache(userID, email.AccountID, email.FolderID)
+	}
+}
+
+// clearReplyLaterOnSyncedReply 同步到用户在其他客户端发出的回复时，按会话清除稍后回复
+func clearReplyLaterOnSyncedReply(tx *gorm.DB, userID, accountID uint, email *models.Email) {
+	if email.ThreadID == "" || email.ThreadID == email.MessageID {
+		return
+	}
+	var account models.EmailAccount
+	if err := tx.Select("email").First(&account, accountID).Error; err != nil {
+		return
+	}
+	from := parseEmailAddress(email.From)
+	if from == nil || !strings.EqualFold(from.Address, account.Email) {
+		return
+	}
+	clearReplyLaterThread(tx, userID, email.ThreadID, email.Date)
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplyLaterReturnsDueEmailsToTopOfInbox(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.EmailEvent{}))
+	ctx := context.Background()
+
+	email := env.createEmail(t, env.inbox, 1, "proposal", true, false)
+	other := env.createEmail(t, env.inbox, 2, "newsletter", false, false)
+
+	past := time.Now().Add(-time.Minute)
+	_, err := env.service.SetReplyLater(ctx, env.user.ID, email.ID, &ReplyLaterRequest{RemindAt: &past})
+	require.True(t, errors.Is(err, ErrInvalidReplyLater))
+
+	remindAt := time.Now().Add(time.Hour)
+	updated, err := env.service.SetReplyLater(ctx, env.user.ID, email.ID, &ReplyLaterRequest{RemindAt: &remindAt})
+	require.NoError(t, err)
+	require.NotNil(t, updated.ReplyLaterAt)
+	_, err = env.service.SetReplyLater(ctx, env.user.ID, other.ID, &ReplyLaterRequest{})
+	require.NoError(t, err)
+
+	list, err := env.service.ListReplyLaterEmails(ctx, env.user.ID, &ReplyLaterListRequest{})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, list.Total)
+	require.Equal(t, email.ID, list.Emails[0].ID)
+
+	// 未到期时不处理
+	returned, err := env.service.ReturnDueReplyLater(ctx, time.Now())
+	require.NoError(t, err)
+	require.Zero(t, returned)
+
+	returned, err = env.service.ReturnDueReplyLater(ctx, remindAt.Add(time.Second))
+	require.NoError(t, err)
+	require.Equal(t, 1, returned)
+
+	var stored models.Email
+	require.NoError(t, env.db.First(&stored, email.ID).Error)
+	require.True(t, stored.IsPinned)
+	require.False(t, stored.IsRead)
+	require.NotNil(t, stored.ReplyLaterAt)
+	require.Nil(t, stored.ReplyLaterRemindAt)
+	require.Equal(t, []uint32{email.UID}, flattenUIDCalls(env.provider.imap.markUnreadCalls))
+
+	cleared, err := env.service.ClearReplyLater(ctx, env.user.ID, other.ID)
+	require.NoError(t, err)
+	require.Nil(t, cleared.ReplyLaterAt)
+}
+
+func TestReplyLaterClearedByReplyInThread(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+
+	original := env.createEmail(t, env.inbox, 1, "question", false, false)
+	followUp := env.createEmail(t, env.inbox, 2, "follow-up", false, false)
+	require.NoError(t, env.db.Model(followUp).Update("thread_id", original.MessageID).Error)
+
+	for _, email := range []*models.Email{original, followUp} {
+		_, err := env.service.SetReplyLater(ctx, env.user.ID, email.ID, &ReplyLaterRequest{})
+		require.NoError(t, err)
+	}
+
+	env.service.clearReplyLaterOnReply(ctx, env.user.ID, followUp.ID)
+
+	list, err := env.service.ListReplyLaterEmails(ctx, env.user.ID, &ReplyLaterListRequest{})
+	require.NoError(t, err)
+	require.Zero(t, list.Total)
+
+	// 同步到用户从其他客户端发出的回复时同样清除
+	_, err = env.service.SetReplyLater(ctx, env.user.ID, original.ID, &ReplyLaterRequest{})
+	require.NoError(t, err)
+	sent := &models.Email{
+		MessageID: "<reply@example.com>",
+		ThreadID:  original.MessageID,
+		From:      "Tester <TESTER@example.com>",
+		Date:      time.Now().Add(time.Minute),
+	}
+	clearReplyLaterOnSyncedReply(env.db, env.user.ID, env.account.ID, sent)
+
+	require.NoError(t, env.db.First(original, original.ID).Error)
+	require.Nil(t, original.ReplyLaterAt)
+}
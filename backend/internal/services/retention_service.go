@@ -0,0 +1,542 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// 定时任务检查到期规则的间隔
+	retentionCheckInterval = time.Hour
+	// 同一规则两次定时执行的最小间隔
+	retentionRunInterval = 24 * time.Hour
+	// 单次执行最多处理的邮件数，剩余的邮件在下次执行时处理
+	maxRetentionEmailsPerRun = 1000
+	// 预览返回的样例邮件数
+	retentionPreviewSampleSize = 20
+	// 执行记录列表的默认和最大条数
+	defaultRetentionRunsLimit = 50
+	maxRetentionRunsLimit     = 200
+)
+
+var (
+	// ErrRetentionPolicyNotFound 保留规则不存在或无权访问
+	ErrRetentionPolicyNotFound = errors.New("retention policy not found")
+	// ErrInvalidRetentionPolicy 保留规则参数无效
+	ErrInvalidRetentionPolicy = errors.New("invalid retention policy")
+	// ErrRetentionPolicyRunning 保留规则正在执行
+	ErrRetentionPolicyRunning = errors.New("retention policy is already running")
+)
+
+// RetentionService 邮件保留规则服务接口
+type RetentionService interface {
+	// ListPolicies 列出保留规则
+	ListPolicies(ctx context.Context, userID uint) ([]models.RetentionPolicy, error)
+
+	// CreatePolicy 创建保留规则
+	CreatePolicy(ctx context.Context, userID uint, req *RetentionPolicyRequest) (*models.RetentionPolicy, error)
+
+	// UpdatePolicy 修改保留规则
+	UpdatePolicy(ctx context.Context, userID, policyID uint, req *RetentionPolicyRequest) (*models.RetentionPolicy, error)
+
+	// DeletePolicy 删除保留规则及其执行记录
+	DeletePolicy(ctx context.Context, userID, policyID uint) error
+
+	// PreviewPolicy 预览规则当前会处理的邮件，不做任何修改
+	PreviewPolicy(ctx context.Context, userID, policyID uint) (*RetentionPreview, error)
+
+	// RunPolicy 立即在后台执行规则，返回执行记录
+	RunPolicy(ctx context.Context, userID, policyID uint) (*models.RetentionRun, error)
+
+	// ListRuns 列出执行记录
+	ListRuns(ctx context.Context, userID uint, req *ListRetentionRunsRequest) ([]models.RetentionRun, error)
+
+	// Start 启动定时任务
+	Start(ctx context.Context) error
+
+	// Stop 停止定时任务并等待执行中的规则结束
+	Stop()
+}
+
+// RetentionPolicyRequest 创建或修改保留规则的请求
+type RetentionPolicyRequest struct {
+	Name          string `json:"name" binding:"required,max=100"`
+	AccountID     uint   `json:"account_id" binding:"required"`
+	FolderID      *uint  `json:"folder_id,omitempty"` // 为空时适用于账户的所有文件夹
+	Action        string `json:"action" binding:"required,oneof=delete archive"`
+	OlderThanDays int    `json:"older_than_days" binding:"required,min=1"`
+	UnreadOnly    bool   `json:"unread_only,omitempty"`
+	Newsletters   bool   `json:"newsletters,omitempty"`
+	IsEnabled     *bool  `json:"is_enabled,omitempty"` // 默认启用
+}
+
+// ListRetentionRunsRequest 执行记录查询参数
+type ListRetentionRunsRequest struct {
+	PolicyID *uint `form:"policy_id" json:"policy_id,omitempty"`
+	Limit    int   `form:"limit" json:"limit,omitempty"` // 默认50，最多200
+}
+
+// RetentionPreview 规则的试运行结果
+type RetentionPreview struct {
+	PolicyID     uint                  `json:"policy_id"`
+	Action       string                `json:"action"`
+	Cutoff       time.Time             `json:"cutoff"` // 早于该时间的邮件会被处理
+	Matched      int64                 `json:"matched"`
+	MatchedBytes int64                 `json:"matched_bytes"`
+	Samples      []StorageEmailSummary `json:"samples"` // 最早的若干封匹配邮件
+}
+
+// retentionEmailActions 执行规则所需的邮件操作，由邮件服务实现
+type retentionEmailActions interface {
+	DeleteEmail(ctx context.Context, userID, emailID uint) error
+	ArchiveEmail(ctx context.Context, userID, emailID uint) error
+}
+
+// RetentionServiceImpl 邮件保留规则服务实现
+type RetentionServiceImpl struct {
+	db      *gorm.DB
+	actions retentionEmailActions
+
+	baseCtx context.Context
+	cancel  context.CancelFunc
+	running map[uint]bool // 正在执行的规则
+	wg      sync.WaitGroup
+	mutex   sync.Mutex
+}
+
+// NewRetentionService 创建邮件保留规则服务，删除和归档复用邮件服务的逻辑
+func NewRetentionService(db *gorm.DB, emailService EmailService) RetentionService {
+	return &RetentionServiceImpl{
+		db:      db,
+		actions: emailService,
+		baseCtx: context.Background(),
+		running: make(map[uint]bool),
+	}
+}
+
+// ListPolicies 列出保留规则
+func (s *RetentionServiceImpl) ListPolicies(ctx context.Context, userID uint) ([]models.RetentionPolicy, error) {
+	policies := make([]models.RetentionPolicy, 0)
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("account_id ASC, id ASC").
+		Find(&policies).Error; err != nil {
+		return nil, fmt.Errorf("failed to list retention policies: %w", err)
+	}
+	return policies, nil
+}
+
+// getPolicy 获取属于用户的保留规则
+func (s *RetentionServiceImpl) getPolicy(ctx context.Context, userID, policyID uint) (*models.RetentionPolicy, error) {
+	var policy models.RetentionPolicy
+	if err := s.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", policyID, userID).
+		First(&policy).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRetentionPolicyNotFound
+		}
+		return nil, fmt.Errorf("failed to get retention policy: %w", err)
+	}
+	return &policy, nil
+}
+
+// validatePolicyTarget 校验账户和文件夹属于当前用户
+func (s *RetentionServiceImpl) validatePolicyTarget(ctx context.Context, userID uint, req *RetentionPolicyRequest) error {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.EmailAccount{}).
+		Where("id = ? AND user_id = ?", req.AccountID, userID).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check account: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("%w: account not found", ErrInvalidRetentionPolicy)
+	}
+
+	if req.FolderID != nil {
+		if err := s.db.WithContext(ctx).Model(&models.Folder{}).
+			Where("id = ? AND account_id = ?", *req.FolderID, req.AccountID).
+			Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to check folder: %w", err)
+		}
+		if count == 0 {
+			return fmt.Errorf("%w: folder not found", ErrInvalidRetentionPolicy)
+		}
+	}
+
+	if req.Action != models.RetentionActionDelete && req.Action != models.RetentionActionArchive {
+		return fmt.Errorf("%w: unknown action %s", ErrInvalidRetentionPolicy, req.Action)
+	}
+	if req.OlderThanDays <= 0 {
+		return fmt.Errorf("%w: older_than_days must be positive", ErrInvalidRetentionPolicy)
+	}
+	return nil
+}
+
+// applyPolicyRequest 将请求写入规则
+func applyPolicyRequest(policy *models.RetentionPolicy, req *RetentionPolicyRequest) {
+	policy.Name = strings.TrimSpace(req.Name)
+	policy.AccountID = req.AccountID
+	policy.FolderID = req.FolderID
+	policy.Action = req.Action
+	policy.OlderThanDays = req.OlderThanDays
+	policy.UnreadOnly = req.UnreadOnly
+	policy.Newsletters = req.Newsletters
+	if req.IsEnabled != nil {
+		policy.IsEnabled = *req.IsEnabled
+	}
+}
+
+// CreatePolicy 创建保留规则
+func (s *RetentionServiceImpl) CreatePolicy(ctx context.Context, userID uint, req *RetentionPolicyRequest) (*models.RetentionPolicy, error) {
+	if err := s.validatePolicyTarget(ctx, userID, req); err != nil {
+		return nil, err
+	}
+
+	policy := &models.RetentionPolicy{UserID: userID, IsEnabled: true}
+	applyPolicyRequest(policy, req)
+	if policy.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidRetentionPolicy)
+	}
+	enabled := policy.IsEnabled
+	if err := s.db.WithContext(ctx).Create(policy).Error; err != nil {
+		return nil, fmt.Errorf("failed to create retention policy: %w", err)
+	}
+	// 创建时false会被字段默认值覆盖，需单独写入
+	if !enabled {
+		if err := s.db.WithContext(ctx).Model(policy).Update("is_enabled", false).Error; err != nil {
+			return nil, fmt.Errorf("failed to create retention policy: %w", err)
+		}
+	}
+	return policy, nil
+}
+
+// UpdatePolicy 修改保留规则
+func (s *RetentionServiceImpl) UpdatePolicy(ctx context.Context, userID, policyID uint, req *RetentionPolicyRequest) (*models.RetentionPolicy, error) {
+	policy, err := s.getPolicy(ctx, userID, policyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validatePolicyTarget(ctx, userID, req); err != nil {
+		return nil, err
+	}
+
+	applyPolicyRequest(policy, req)
+	if policy.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidRetentionPolicy)
+	}
+	if err := s.db.WithContext(ctx).Save(policy).Error; err != nil {
+		return nil, fmt.Errorf("failed to update retention policy: %w", err)
+	}
+	return policy, nil
+}
+
+// DeletePolicy 删除保留规则及其执行记录，执行中的规则不能删除
+func (s *RetentionServiceImpl) DeletePolicy(ctx context.Context, userID, policyID uint) error {
+	policy, err := s.getPolicy(ctx, userID, policyID)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	running := s.running[policy.ID]
+	s.mutex.Unlock()
+	if running {
+		return ErrRetentionPolicyRunning
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("policy_id = ?", policy.ID).Delete(&models.RetentionRun{}).Error; err != nil {
+			return fmt.Errorf("failed to delete retention runs: %w", err)
+		}
+		if err := tx.Delete(policy).Error; err != nil {
+			return fmt.Errorf("failed to delete retention policy: %w", err)
+		}
+		return nil
+	})
+}
+
+// policyCutoff 规则的截止时间，早于该时间的邮件会被处理
+func policyCutoff(policy *models.RetentionPolicy, now time.Time) time.Time {
+	return now.AddDate(0, 0, -policy.OlderThanDays)
+}
+
+// policyQuery 构造规则的匹配条件；星标、置顶的邮件不处理，删除规则跳过受法律保全保护的邮件，归档规则跳过已在归档文件夹中的邮件
+func (s *RetentionServiceImpl) policyQuery(ctx context.Context, policy *models.RetentionPolicy, cutoff time.Time) (*gorm.DB, error) {
+	query := s.db.WithContext(ctx).Model(&models.Email{}).
+		Where("emails.account_id = ? AND emails.user_id = ? AND emails.is_deleted = ?", policy.AccountID, policy.UserID, false).
+		Where("emails.date < ?", cutoff).
+		Where("emails.is_starred = ? AND emails.is_pinned = ?", false, false)
+	if policy.FolderID != nil {
+		query = query.Where("emails.folder_id = ?", *policy.FolderID)
+	}
+	if policy.UnreadOnly {
+		query = query.Where("emails.is_read = ?", false)
+	}
+	if policy.Newsletters {
+		query = query.Where(bulkSenderCondition(s.db))
+	}
+	if policy.Action == models.RetentionActionArchive {
+		archiveFolders := s.db.Model(&models.Folder{}).Select("id").
+			Where("account_id = ? AND (type = ? OR name = ? OR name = ?)", policy.AccountID, "archive", "Archive", "已归档")
+		query = query.Where("(emails.folder_id IS NULL OR emails.folder_id NOT IN (?))", archiveFolders)
+		return query, nil
+	}
+	return excludeLegalHolds(query, &policy.UserID)
+}
+
+// PreviewPolicy 预览规则当前会处理的邮件，不做任何修改
+func (s *RetentionServiceImpl) PreviewPolicy(ctx context.Context, userID, policyID uint) (*RetentionPreview, error) {
+	policy, err := s.getPolicy(ctx, userID, policyID)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := policyCutoff(policy, time.Now())
+	preview := &RetentionPreview{
+		PolicyID: policy.ID,
+		Action:   policy.Action,
+		Cutoff:   cutoff,
+		Samples:  make([]StorageEmailSummary, 0),
+	}
+
+	var totals struct {
+		Count int64
+		Bytes int64
+	}
+	query, err := s.policyQuery(ctx, policy, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	if err := query.Session(&gorm.Session{}).
+		Select("COUNT(*) AS count, COALESCE(SUM(emails.size), 0) AS bytes").
+		Scan(&totals).Error; err != nil {
+		return nil, fmt.Errorf("failed to preview retention policy: %w", err)
+	}
+	preview.Matched = totals.Count
+	preview.MatchedBytes = totals.Bytes
+
+	if err := query.Session(&gorm.Session{}).
+		Select("emails.id, emails.folder_id, emails.subject, emails.from_address, emails.date, emails.size, emails.is_read, emails.has_attachment").
+		Order("emails.date ASC, emails.id ASC").
+		Limit(retentionPreviewSampleSize).
+		Scan(&preview.Samples).Error; err != nil {
+		return nil, fmt.Errorf("failed to preview retention policy: %w", err)
+	}
+	return preview, nil
+}
+
+// RunPolicy 立即在后台执行规则，返回执行记录
+func (s *RetentionServiceImpl) RunPolicy(ctx context.Context, userID, policyID uint) (*models.RetentionRun, error) {
+	policy, err := s.getPolicy(ctx, userID, policyID)
+	if err != nil {
+		return nil, err
+	}
+	return s.startRun(policy, models.RetentionTriggerManual)
+}
+
+// startRun 创建执行记录并在后台执行规则，同一规则同时只有一个执行
+func (s *RetentionServiceImpl) startRun(policy *models.RetentionPolicy, trigger string) (*models.RetentionRun, error) {
+	s.mutex.Lock()
+	if s.running[policy.ID] {
+		s.mutex.Unlock()
+		return nil, ErrRetentionPolicyRunning
+	}
+	s.running[policy.ID] = true
+	baseCtx := s.baseCtx
+	s.mutex.Unlock()
+
+	release := func() {
+		s.mutex.Lock()
+		delete(s.running, policy.ID)
+		s.mutex.Unlock()
+	}
+
+	now := time.Now()
+	run := &models.RetentionRun{
+		PolicyID:    policy.ID,
+		UserID:      policy.UserID,
+		TriggeredBy: trigger,
+		Status:      models.RetentionRunStatusRunning,
+		StartedAt:   now,
+	}
+	if err := s.db.WithContext(baseCtx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(run).Error; err != nil {
+			return err
+		}
+		return tx.Model(policy).Update("last_run_at", now).Error
+	}); err != nil {
+		release()
+		return nil, fmt.Errorf("failed to start retention policy: %w", err)
+	}
+
+	snapshot := *run
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer release()
+		s.executeRun(baseCtx, policy, run)
+	}()
+	return &snapshot, nil
+}
+
+// executeRun 逐封删除或归档匹配的邮件并记录结果
+func (s *RetentionServiceImpl) executeRun(ctx context.Context, policy *models.RetentionPolicy, run *models.RetentionRun) {
+	var matches []struct {
+		ID   uint
+		Size int64
+	}
+	query, err := s.policyQuery(ctx, policy, policyCutoff(policy, run.StartedAt))
+	if err == nil {
+		err = query.
+			Select("emails.id, emails.size").
+			Order("emails.date ASC, emails.id ASC").
+			Limit(maxRetentionEmailsPerRun).
+			Scan(&matches).Error
+	}
+	if err != nil {
+		run.LastError = fmt.Sprintf("failed to find emails: %v", err)
+	}
+	run.Matched = len(matches)
+
+	for _, match := range matches {
+		if ctx.Err() != nil {
+			break
+		}
+
+		var actionErr error
+		if policy.Action == models.RetentionActionArchive {
+			actionErr = s.actions.ArchiveEmail(ctx, policy.UserID, match.ID)
+		} else {
+			actionErr = s.actions.DeleteEmail(ctx, policy.UserID, match.ID)
+		}
+		if actionErr != nil {
+			run.Failed++
+			run.LastError = fmt.Sprintf("email %d: %v", match.ID, actionErr)
+			continue
+		}
+		run.Succeeded++
+		run.FreedBytes += match.Size
+	}
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	switch {
+	case ctx.Err() != nil:
+		run.Status = models.RetentionRunStatusCancelled
+	case err != nil, run.Matched > 0 && run.Failed == run.Matched:
+		run.Status = models.RetentionRunStatusFailed
+	default:
+		run.Status = models.RetentionRunStatusCompleted
+	}
+
+	// 服务停止时ctx已取消，结果仍需写入
+	if err := s.db.WithContext(context.WithoutCancel(ctx)).Save(run).Error; err != nil {
+		log.Printf("Failed to save retention run %d: %v", run.ID, err)
+	}
+	if run.Succeeded > 0 || run.Failed > 0 {
+		log.Printf("Retention policy %d (%s): %d processed, %d failed", policy.ID, policy.Action, run.Succeeded, run.Failed)
+	}
+}
+
+// ListRuns 列出执行记录，最新的在前
+func (s *RetentionServiceImpl) ListRuns(ctx context.Context, userID uint, req *ListRetentionRunsRequest) ([]models.RetentionRun, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultRetentionRunsLimit
+	}
+	if limit > maxRetentionRunsLimit {
+		limit = maxRetentionRunsLimit
+	}
+
+	query := s.db.WithContext(ctx).Where("user_id = ?", userID)
+	if req.PolicyID != nil {
+		if _, err := s.getPolicy(ctx, userID, *req.PolicyID); err != nil {
+			return nil, err
+		}
+		query = query.Where("policy_id = ?", *req.PolicyID)
+	}
+
+	runs := make([]models.RetentionRun, 0)
+	if err := query.Order("started_at DESC, id DESC").Limit(limit).Find(&runs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list retention runs: %w", err)
+	}
+	return runs, nil
+}
+
+// runDuePolicies 执行所有到期的启用规则
+func (s *RetentionServiceImpl) runDuePolicies(ctx context.Context) {
+	var policies []models.RetentionPolicy
+	if err := s.db.WithContext(ctx).
+		Where("is_enabled = ? AND (last_run_at IS NULL OR last_run_at < ?)", true, time.Now().Add(-retentionRunInterval)).
+		Order("id ASC").
+		Find(&policies).Error; err != nil {
+		log.Printf("Failed to load due retention policies: %v", err)
+		return
+	}
+
+	for i := range policies {
+		if ctx.Err() != nil {
+			return
+		}
+		if _, err := s.startRun(&policies[i], models.RetentionTriggerScheduled); err != nil && !errors.Is(err, ErrRetentionPolicyRunning) {
+			log.Printf("Failed to run retention policy %d: %v", policies[i].ID, err)
+		}
+	}
+}
+
+// Start 启动定时任务；上次关闭时未结束的执行标记为已取消，剩余邮件在下次执行时处理
+func (s *RetentionServiceImpl) Start(ctx context.Context) error {
+	if err := s.db.WithContext(ctx).Model(&models.RetentionRun{}).
+		Where("status = ?", models.RetentionRunStatusRunning).
+		Updates(map[string]interface{}{
+			"status":      models.RetentionRunStatusCancelled,
+			"finished_at": time.Now(),
+		}).Error; err != nil {
+		return fmt.Errorf("failed to reset interrupted retention runs: %w", err)
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	s.mutex.Lock()
+	s.baseCtx = runCtx
+	s.cancel = cancel
+	s.mutex.Unlock()
+
+	log.Println("Starting retention policy scheduler...")
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(retentionCheckInterval)
+		defer ticker.Stop()
+
+		s.runDuePolicies(runCtx)
+		for {
+			select {
+			case <-ticker.C:
+				s.runDuePolicies(runCtx)
+			case <-runCtx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop 停止定时任务并等待执行中的规则结束
+func (s *RetentionServiceImpl) Stop() {
+	s.mutex.Lock()
+	cancel := s.cancel
+	s.mutex.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	s.wg.Wait()
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setupRetentionTestEnv(t *testing.T) (*emailStateServiceTestEnv, *RetentionServiceImpl) {
+	t.Helper()
+
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.RetentionPolicy{}, &models.RetentionRun{}))
+	return env, NewRetentionService(env.db, env.service).(*RetentionServiceImpl)
+}
+
+func ageEmail(t *testing.T, env *emailStateServiceTestEnv, email *models.Email, days int) {
+	t.Helper()
+	require.NoError(t, env.db.Model(email).Update("date", time.Now().AddDate(0, 0, -days)).Error)
+}
+
+func TestRetentionPolicyPreviewAndRun(t *testing.T) {
+	env, svc := setupRetentionTestEnv(t)
+	ctx := context.Background()
+
+	expired := env.createEmail(t, env.inbox, 1, "Expired", true, false)
+	ageEmail(t, env, expired, 40)
+	starred := env.createEmail(t, env.inbox, 2, "Starred", true, false)
+	ageEmail(t, env, starred, 40)
+	require.NoError(t, env.db.Model(starred).Update("is_starred", true).Error)
+	otherFolder := env.createEmail(t, env.work, 3, "Other folder", true, false)
+	ageEmail(t, env, otherFolder, 40)
+	env.createEmail(t, env.inbox, 4, "Recent", true, false)
+
+	policy, err := svc.CreatePolicy(ctx, env.user.ID, &RetentionPolicyRequest{
+		Name:          "Inbox 30 days",
+		AccountID:     env.account.ID,
+		FolderID:      &env.inbox.ID,
+		Action:        models.RetentionActionDelete,
+		OlderThanDays: 30,
+	})
+	require.NoError(t, err)
+	require.True(t, policy.IsEnabled)
+
+	preview, err := svc.PreviewPolicy(ctx, env.user.ID, policy.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), preview.Matched)
+	require.Len(t, preview.Samples, 1)
+	require.Equal(t, expired.ID, preview.Samples[0].ID)
+
+	// 预览不修改邮件
+	var stored models.Email
+	require.NoError(t, env.db.First(&stored, expired.ID).Error)
+	require.False(t, stored.IsDeleted)
+
+	run, err := svc.RunPolicy(ctx, env.user.ID, policy.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.RetentionRunStatusRunning, run.Status)
+	require.Equal(t, models.RetentionTriggerManual, run.TriggeredBy)
+	svc.Stop()
+
+	for id, deleted := range map[uint]bool{expired.ID: true, starred.ID: false, otherFolder.ID: false} {
+		var email models.Email
+		require.NoError(t, env.db.First(&email, id).Error)
+		require.Equal(t, deleted, email.IsDeleted, "email %d", id)
+	}
+
+	runs, err := svc.ListRuns(ctx, env.user.ID, &ListRetentionRunsRequest{PolicyID: &policy.ID})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, models.RetentionRunStatusCompleted, runs[0].Status)
+	require.Equal(t, 1, runs[0].Matched)
+	require.Equal(t, 1, runs[0].Succeeded)
+	require.NotNil(t, runs[0].FinishedAt)
+
+	// 执行后记录了执行时间，定时任务在间隔内不会重复执行
+	svc.runDuePolicies(ctx)
+	svc.Stop()
+	runs, err = svc.ListRuns(ctx, env.user.ID, &ListRetentionRunsRequest{})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+}
+
+func TestRetentionPolicyValidation(t *testing.T) {
+	env, svc := setupRetentionTestEnv(t)
+	ctx := context.Background()
+
+	otherFolder := &models.Folder{AccountID: env.account.ID + 1, Name: "Other", Type: models.FolderTypeCustom, Path: "Other"}
+	require.NoError(t, env.db.Create(otherFolder).Error)
+
+	_, err := svc.CreatePolicy(ctx, env.user.ID, &RetentionPolicyRequest{
+		Name: "Bad folder", AccountID: env.account.ID, FolderID: &otherFolder.ID, Action: models.RetentionActionDelete, OlderThanDays: 30,
+	})
+	require.ErrorIs(t, err, ErrInvalidRetentionPolicy)
+
+	_, err = svc.CreatePolicy(ctx, env.user.ID+1, &RetentionPolicyRequest{
+		Name: "Other user", AccountID: env.account.ID, Action: models.RetentionActionDelete, OlderThanDays: 30,
+	})
+	require.ErrorIs(t, err, ErrInvalidRetentionPolicy)
+
+	disabled := false
+	policy, err := svc.CreatePolicy(ctx, env.user.ID, &RetentionPolicyRequest{
+		Name: "Newsletters", AccountID: env.account.ID, Action: models.RetentionActionArchive, OlderThanDays: 180, Newsletters: true, IsEnabled: &disabled,
+	})
+	require.NoError(t, err)
+
+	var stored models.RetentionPolicy
+	require.NoError(t, env.db.First(&stored, policy.ID).Error)
+	require.False(t, stored.IsEnabled)
+
+	// 停用的规则不会被定时执行
+	svc.runDuePolicies(ctx)
+	svc.Stop()
+	runs, err := svc.ListRuns(ctx, env.user.ID, &ListRetentionRunsRequest{})
+	require.NoError(t, err)
+	require.Empty(t, runs)
+
+	_, err = svc.PreviewPolicy(ctx, env.user.ID+1, policy.ID)
+	require.ErrorIs(t, err, ErrRetentionPolicyNotFound)
+
+	require.NoError(t, svc.DeletePolicy(ctx, env.user.ID, policy.ID))
+	_, err = svc.RunPolicy(ctx, env.user.ID, policy.ID)
+	require.ErrorIs(t, err, ErrRetentionPolicyNotFound)
+}
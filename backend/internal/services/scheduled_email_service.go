@@ -3,8 +3,10 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"firemail/internal/models"
@@ -21,8 +23,19 @@ type ScheduledEmailService interface {
 	
 	// ProcessScheduledEmails 处理到期的定时邮件
 	ProcessScheduledEmails(ctx context.Context) error
+
+	// Shutdown 暂停发送队列并等待正在发送的邮件完成
+	Shutdown(ctx context.Context) error
 }
 
+const (
+	// 等待单封定时邮件发送结果的超时时间与轮询间隔
+	scheduledSendResultTimeout = 5 * time.Minute
+	scheduledSendPollInterval  = 500 * time.Millisecond
+
+	scheduledInterruptedMessage = "sending interrupted by server shutdown"
+)
+
 // ScheduledEmailServiceImpl 定时邮件服务实现
 type ScheduledEmailServiceImpl struct {
 	db            *gorm.DB
@@ -30,7 +43,13 @@ type ScheduledEmailServiceImpl struct {
 	emailComposer EmailComposer
 	emailSender   EmailSender
 	stopChan      chan struct{}
+	stopOnce      sync.Once
 	ticker        *time.Ticker
+
+	// 暂停后不再领取新的邮件，processing 跟踪正在进行的处理
+	paused     bool
+	processing sync.WaitGroup
+	mutex      sync.Mutex
 }
 
 // NewScheduledEmailService 创建定时邮件服务
@@ -52,6 +71,17 @@ func NewScheduledEmailService(
 // StartScheduler 启动定时任务调度器
 func (s *ScheduledEmailServiceImpl) StartScheduler(ctx context.Context) error {
 	log.Println("Starting scheduled email service...")
+
+	// 上次关闭时仍在组装的邮件尚未交给发送器，放回队列重新发送；
+	// 已保存MIME原文的邮件由发送器在启动时继续投递
+	if err := s.db.WithContext(ctx).Model(&models.SendQueue{}).
+		Where("status = ?", "processing").
+		Updates(map[string]interface{}{
+			"status":     "scheduled",
+			"last_error": scheduledInterruptedMessage,
+		}).Error; err != nil {
+		return fmt.Errorf("failed to reset interrupted scheduled emails: %w", err)
+	}
 	
 	// 每分钟检查一次
 	s.ticker = time.NewTicker(1 * time.Minute)
@@ -78,20 +108,67 @@ func (s *ScheduledEmailServiceImpl) StartScheduler(ctx context.Context) error {
 
 // StopScheduler 停止定时任务调度器
 func (s *ScheduledEmailServiceImpl) StopScheduler() {
-	if s.ticker != nil {
-		s.ticker.Stop()
+	s.stopOnce.Do(func() {
+		if s.ticker != nil {
+			s.ticker.Stop()
+		}
+		close(s.stopChan)
+	})
+}
+
+// Shutdown 暂停发送队列并等待正在发送的邮件完成，未领取的邮件保持原状态，下次启动后继续发送
+func (s *ScheduledEmailServiceImpl) Shutdown(ctx context.Context) error {
+	s.mutex.Lock()
+	s.paused = true
+	s.mutex.Unlock()
+
+	s.StopScheduler()
+
+	done := make(chan struct{})
+	go func() {
+		s.processing.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for scheduled emails: %w", ctx.Err())
 	}
-	close(s.stopChan)
+}
+
+// beginProcessing 登记一次队列处理，已暂停时返回false
+func (s *ScheduledEmailServiceImpl) beginProcessing() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.paused {
+		return false
+	}
+	s.processing.Add(1)
+	return true
+}
+
+func (s *ScheduledEmailServiceImpl) isPaused() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.paused
 }
 
 // ProcessScheduledEmails 处理到期的定时邮件
 func (s *ScheduledEmailServiceImpl) ProcessScheduledEmails(ctx context.Context) error {
+	if !s.beginProcessing() {
+		return nil
+	}
+	defer s.processing.Done()
+
 	// 查找到期的定时邮件
 	var scheduledEmails []models.SendQueue
 	now := time.Now()
 	
 	err := s.db.WithContext(ctx).
-		Where("status = ? AND scheduled_at <= ?", "scheduled", now).
+		Where("(status = ? AND scheduled_at <= ?) OR (status = ? AND next_attempt <= ?)", "scheduled", now, "retry", now).
 		Find(&scheduledEmails).Error
 	if err != nil {
 		return fmt.Errorf("failed to query scheduled emails: %w", err)
@@ -104,6 +181,10 @@ func (s *ScheduledEmailServiceImpl) ProcessScheduledEmails(ctx context.Context)
 	log.Printf("Processing %d scheduled emails", len(scheduledEmails))
 	
 	for _, scheduledEmail := range scheduledEmails {
+		if s.isPaused() {
+			log.Println("Scheduled email queue paused, remaining emails will be sent after restart")
+			break
+		}
 		if err := s.processScheduledEmail(ctx, &scheduledEmail); err != nil {
 			log.Printf("Failed to process scheduled email %s: %v", scheduledEmail.SendID, err)
 			
@@ -133,6 +214,11 @@ func (s *ScheduledEmailServiceImpl) processScheduledEmail(ctx context.Context, s
 	if err := json.Unmarshal([]byte(scheduledEmail.EmailData), &composeRequest); err != nil {
 		return fmt.Errorf("failed to unmarshal email data: %w", err)
 	}
+	composeRequest.UserID = scheduledEmail.UserID
+	composeRequest.SizeLimit, err = AccountMessageSizeLimit(ctx, s.db, scheduledEmail.AccountID)
+	if err != nil {
+		return err
+	}
 	
 	// 组装邮件
 	composedEmail, err := s.emailComposer.ComposeEmail(ctx, &composeRequest)
@@ -140,19 +226,20 @@ func (s *ScheduledEmailServiceImpl) processScheduledEmail(ctx context.Context, s
 		return fmt.Errorf("failed to compose email: %w", err)
 	}
 	
-	// 发送邮件
-	_, err = s.emailSender.SendEmail(ctx, composedEmail, scheduledEmail.AccountID)
+	// 发送邮件并等待结果，以便限流时按建议时间重试
+	// 发送器把MIME原文写入本条队列记录，重启后可继续投递
+	sendResult, err := s.emailSender.SendEmail(withSendQueueID(ctx, scheduledEmail.SendID), composedEmail, scheduledEmail.AccountID)
 	if err != nil {
 		return fmt.Errorf("failed to send email: %w", err)
 	}
+	if err := awaitSendResult(ctx, s.emailSender, sendResult.SendID, scheduledSendResultTimeout, scheduledSendPollInterval); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
 	
-	// 更新状态为已发送
+	// 更新状态为已发送，投递次数由发送器记录
 	err = s.db.WithContext(ctx).
 		Model(scheduledEmail).
-		Updates(map[string]interface{}{
-			"status":    "sent",
-			"attempts":  gorm.Expr("attempts + 1"),
-		}).Error
+		Update("status", "sent").Error
 	if err != nil {
 		log.Printf("Failed to update sent status: %v", err)
 	}
@@ -163,11 +250,24 @@ func (s *ScheduledEmailServiceImpl) processScheduledEmail(ctx context.Context, s
 
 // updateScheduledEmailError 更新定时邮件错误信息
 func (s *ScheduledEmailServiceImpl) updateScheduledEmailError(ctx context.Context, scheduledEmail *models.SendQueue, sendErr error) {
-	scheduledEmail.Attempts++
 	scheduledEmail.LastError = sendErr.Error()
 	now := time.Now()
 	scheduledEmail.LastAttempt = &now
-	
+
+	// 被限流时按建议时间重试，不计入重试次数
+	var rateLimited *SendRateLimitedError
+	if errors.As(sendErr, &rateLimited) {
+		nextAttempt := rateLimited.RetryAt
+		scheduledEmail.NextAttempt = &nextAttempt
+		scheduledEmail.Status = "retry"
+		if err := s.db.WithContext(ctx).Save(scheduledEmail).Error; err != nil {
+			log.Printf("Failed to update scheduled email error: %v", err)
+		}
+		return
+	}
+
+	scheduledEmail.Attempts++
+
 	// 如果超过最大重试次数，标记为失败
 	if scheduledEmail.Attempts >= scheduledEmail.MaxAttempts {
 		scheduledEmail.Status = "failed"
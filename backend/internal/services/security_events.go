@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 安全事件列表默认与最大返回条数
+const (
+	defaultSecurityEventsLimit = 100
+	maxSecurityEventsLimit     = 1000
+)
+
+// SecurityEventService 安全事件审计日志
+type SecurityEventService interface {
+	Record(ctx context.Context, event *models.SecurityEvent) error
+	List(ctx context.Context, filter SecurityEventFilter) ([]models.SecurityEvent, error)
+}
+
+// SecurityEventFilter 安全事件查询条件，为空的条件不过滤
+type SecurityEventFilter struct {
+	EventType string
+	IP        string
+	Since     *time.Time
+	Limit     int
+}
+
+// SecurityEventServiceImpl 基于数据库的安全事件审计日志
+type SecurityEventServiceImpl struct {
+	db *gorm.DB
+}
+
+// NewSecurityEventService 创建安全事件服务
+func NewSecurityEventService(db *gorm.DB) SecurityEventService {
+	return &SecurityEventServiceImpl{db: db}
+}
+
+// Record 记录安全事件，过长的字段按列宽截断
+func (s *SecurityEventServiceImpl) Record(ctx context.Context, event *models.SecurityEvent) error {
+	event.Username = truncateRunes(event.Username, 100)
+	event.Path = truncateRunes(event.Path, 500)
+	event.Reason = truncateRunes(event.Reason, 255)
+	event.UserAgent = truncateRunes(event.UserAgent, 500)
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	if err := s.db.WithContext(ctx).Create(event).Error; err != nil {
+		return fmt.Errorf("failed to record security event: %w", err)
+	}
+	return nil
+}
+
+// List 按时间倒序列出安全事件
+func (s *SecurityEventServiceImpl) List(ctx context.Context, filter SecurityEventFilter) ([]models.SecurityEvent, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultSecurityEventsLimit
+	}
+	if limit > maxSecurityEventsLimit {
+		limit = maxSecurityEventsLimit
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.SecurityEvent{})
+	if filter.EventType != "" {
+		query = query.Where("event_type = ?", filter.EventType)
+	}
+	if filter.IP != "" {
+		query = query.Where("ip = ?", filter.IP)
+	}
+	if filter.Since != nil {
+		query = query.Where("created_at >= ?", *filter.Since)
+	}
+
+	events := []models.SecurityEvent{}
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to list security events: %w", err)
+	}
+	return events, nil
+}
+
+// truncateRunes 截断字符串到最多 n 个字符
+func truncateRunes(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n])
+	}
+	return s
+}
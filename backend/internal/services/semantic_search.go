@@ -0,0 +1,364 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EmbeddingIndexInterval 为缺少向量的邮件建立语义搜索索引的周期，账户同步完成后也会立即入队
+const EmbeddingIndexInterval = 5 * time.Minute
+
+const (
+	// SearchModeKeyword 关键词搜索（默认）
+	SearchModeKeyword = "keyword"
+	// SearchModeSemantic 语义搜索：查询和邮件由向量模型编码，按向量相似度与关键词命中综合排序
+	SearchModeSemantic = "semantic"
+
+	// 语义搜索打分权重，以及参与排序的结果上限
+	semanticVectorWeight  = 0.8
+	semanticKeywordWeight = 0.2
+	semanticMinScore      = 0.25
+	semanticResultLimit   = 200
+
+	// semanticScanBatchSize 每批读取的向量数，搜索时只读取向量和摘要列，不加载正文
+	semanticScanBatchSize = 1000
+
+	// embeddingIndexBatchSize 每次请求向量模型编码的邮件数
+	embeddingIndexBatchSize = 32
+
+	// embeddingMaxTextRunes 每封邮件送入向量模型的最大字符数
+	embeddingMaxTextRunes = 4000
+)
+
+// ErrSemanticSearchUnavailable 未配置向量模型
+var ErrSemanticSearchUnavailable = errors.New("semantic search is not configured")
+
+var (
+	embeddingHTMLTagPattern = regexp.MustCompile(`(?s)<[^>]*>`)
+	searchStopWords         = map[string]struct{}{
+		"the": {}, "a": {}, "an": {}, "and": {}, "or": {}, "of": {}, "to": {}, "in": {},
+		"on": {}, "for": {}, "from": {}, "with": {}, "about": {}, "at": {}, "by": {},
+		"is": {}, "are": {}, "was": {}, "be": {}, "this": {}, "that": {}, "it": {},
+		"re": {}, "fwd": {}, "fw": {},
+		"的": {}, "了": {}, "和": {}, "是": {}, "在": {},
+	}
+)
+
+// SetEmbeddingModel 设置语义搜索使用的向量模型
+func (s *EmailServiceImpl) SetEmbeddingModel(model EmbeddingModel) {
+	s.embeddingModel = model
+}
+
+// SetEmbeddingIndexQueue 账户同步完成后向该队列入队语义搜索索引任务
+func (s *SyncService) SetEmbeddingIndexQueue(jobs JobEnqueuer) {
+	s.embeddingJobs = jobs
+}
+
+// EnqueueEmbeddingIndex 入队语义搜索索引任务，已有未结束的索引任务时不重复入队
+func EnqueueEmbeddingIndex(ctx context.Context, jobs JobEnqueuer) error {
+	_, err := jobs.Enqueue(ctx, JobTypeEmbeddingIndex, nil, &JobOptions{UniqueKey: JobTypeEmbeddingIndex})
+	return err
+}
+
+// buildEmbeddingText 组合送入向量模型的邮件文本
+func buildEmbeddingText(email *models.Email) string {
+	body := email.TextBody
+	if strings.TrimSpace(body) == "" && email.HTMLBody != "" {
+		body = embeddingHTMLTagPattern.ReplaceAllString(email.HTMLBody, " ")
+	}
+	text := strings.Join([]string{"Subject: " + email.Subject, "From: " + email.From, strings.Join(strings.Fields(body), " ")}, "\n")
+	if runes := []rune(text); len(runes) > embeddingMaxTextRunes {
+		text = string(runes[:embeddingMaxTextRunes])
+	}
+	return text
+}
+
+// tokenizeForSearch 分词：拉丁文字按单词切分，中日韩文字按单字与双字切分
+func tokenizeForSearch(text string) []string {
+	text = strings.ToLower(text)
+	tokens := make([]string, 0, len(text)/5)
+
+	var word []rune
+	var han []rune
+	flushWord := func() {
+		if len(word) >= 2 {
+			token := stemToken(string(word))
+			if _, stop := searchStopWords[token]; !stop {
+				tokens = append(tokens, token)
+			}
+		}
+		word = word[:0]
+	}
+	flushHan := func() {
+		for i := range han {
+			if _, stop := searchStopWords[string(han[i])]; !stop {
+				tokens = append(tokens, string(han[i]))
+			}
+			if i+1 < len(han) {
+				tokens = append(tokens, string(han[i:i+2]))
+			}
+		}
+		han = han[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r) || unicode.In(r, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			flushWord()
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushHan()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushHan()
+		}
+	}
+	flushWord()
+	flushHan()
+
+	return tokens
+}
+
+// stemToken 简单的英文词干处理
+func stemToken(token string) string {
+	switch {
+	case len(token) > 5 && strings.HasSuffix(token, "ing"):
+		return token[:len(token)-3]
+	case len(token) > 4 && strings.HasSuffix(token, "ies"):
+		return token[:len(token)-3] + "y"
+	case len(token) > 4 && strings.HasSuffix(token, "ed"):
+		return token[:len(token)-2]
+	case len(token) > 3 && strings.HasSuffix(token, "s") && !strings.HasSuffix(token, "ss"):
+		return token[:len(token)-1]
+	}
+	return token
+}
+
+func normalizeVector(vector []float32) {
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		return
+	}
+	norm = math.Sqrt(norm)
+	for i := range vector {
+		vector[i] = float32(float64(vector[i]) / norm)
+	}
+}
+
+// cosineSimilarity 计算归一化向量的余弦相似度，维度不同（更换过模型）时为0
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return dot
+}
+
+// keywordMatchScore 计算查询词在文本中的命中比例
+func keywordMatchScore(queryTokens []string, text string) float64 {
+	if len(queryTokens) == 0 {
+		return 0
+	}
+
+	docTokens := make(map[string]struct{})
+	for _, token := range tokenizeForSearch(text) {
+		docTokens[token] = struct{}{}
+	}
+
+	matched := 0
+	for _, token := range queryTokens {
+		if _, ok := docTokens[token]; ok {
+			matched++
+		}
+	}
+	return float64(matched) / float64(len(queryTokens))
+}
+
+// semanticCandidate 语义搜索扫描的一行：向量和用于关键词打分的摘要列
+type semanticCandidate struct {
+	EmailID uint
+	Vector  []byte
+	Subject string
+	From    string `gorm:"column:from_address"`
+	Preview *string
+}
+
+// semanticSearch 用向量模型编码查询，与用户全部已索引邮件的向量比较，不限制邮件时间范围。
+// 扫描时只读取向量和摘要列，排序后只加载当前页的邮件
+func (s *EmailServiceImpl) semanticSearch(ctx context.Context, query *gorm.DB, req *SearchEmailsRequest, page, pageSize int) (*GetEmailsResponse, error) {
+	if s.embeddingModel == nil {
+		return nil, ErrSemanticSearchUnavailable
+	}
+
+	queryText := strings.TrimSpace(strings.Join([]string{req.Query, req.Subject, req.From, req.To, req.Body}, " "))
+	queryVectors, err := s.embeddingModel.Embed(ctx, []string{queryText})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed search query: %w", err)
+	}
+	queryVector := queryVectors[0]
+	queryTokens := tokenizeForSearch(queryText)
+
+	type scoredEmail struct {
+		id    uint
+		score float64
+	}
+	var scored []scoredEmail
+
+	base := query.Session(&gorm.Session{}).
+		Joins("JOIN email_embeddings ON email_embeddings.email_id = emails.id").
+		Where("email_embeddings.model = ? AND email_embeddings.deleted_at IS NULL", s.embeddingModel.Name())
+	var lastID uint
+	for {
+		var rows []semanticCandidate
+		if err := base.Where("emails.id > ?", lastID).
+			Select("emails.id AS email_id, email_embeddings.vector, emails.subject, emails.from_address, emails.preview").
+			Order("emails.id").
+			Limit(semanticScanBatchSize).
+			Scan(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to load email embeddings: %w", err)
+		}
+		if len(rows) == 0 {
+			break
+		}
+
+		for i := range rows {
+			embedding := models.EmailEmbedding{Vector: rows[i].Vector}
+			vector, err := embedding.GetVector()
+			if err != nil {
+				continue
+			}
+			text := rows[i].Subject + "\n" + rows[i].From
+			if rows[i].Preview != nil {
+				text += "\n" + *rows[i].Preview
+			}
+			score := semanticVectorWeight*cosineSimilarity(queryVector, vector) +
+				semanticKeywordWeight*keywordMatchScore(queryTokens, text)
+			if score >= semanticMinScore {
+				scored = append(scored, scoredEmail{id: rows[i].EmailID, score: score})
+			}
+		}
+		lastID = rows[len(rows)-1].EmailID
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].score > scored[j].score
+	})
+	if len(scored) > semanticResultLimit {
+		scored = scored[:semanticResultLimit]
+	}
+
+	total := int64(len(scored))
+	start := (page - 1) * pageSize
+	if start > len(scored) {
+		start = len(scored)
+	}
+	end := start + pageSize
+	if end > len(scored) {
+		end = len(scored)
+	}
+
+	emailIDs := make([]uint, 0, end-start)
+	for _, item := range scored[start:end] {
+		emailIDs = append(emailIDs, item.id)
+	}
+	emails := make([]*models.Email, 0, len(emailIDs))
+	if len(emailIDs) > 0 {
+		var found []*models.Email
+		if err := s.db.WithContext(ctx).Where("id IN ?", emailIDs).Find(&found).Error; err != nil {
+			return nil, fmt.Errorf("failed to load semantic search results: %w", err)
+		}
+		byID := make(map[uint]*models.Email, len(found))
+		for _, email := range found {
+			byID[email.ID] = email
+		}
+		for _, id := range emailIDs {
+			if email, ok := byID[id]; ok {
+				emails = append(emails, email)
+			}
+		}
+		fillMissingEmailSummaries(ctx, s.db, emails)
+	}
+
+	return &GetEmailsResponse{
+		Emails:     emails,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
+	}, nil
+}
+
+// IndexEmailEmbeddings 为尚未用当前向量模型建立索引的邮件分批生成向量，返回建立索引的邮件数。
+// 未配置向量模型时不做任何事
+func (s *EmailServiceImpl) IndexEmailEmbeddings(ctx context.Context) (int, error) {
+	if s.embeddingModel == nil {
+		return 0, nil
+	}
+
+	modelName := s.embeddingModel.Name()
+	indexed := 0
+	var lastID uint
+	for {
+		if err := ctx.Err(); err != nil {
+			return indexed, err
+		}
+
+		var emails []*models.Email
+		if err := s.db.WithContext(ctx).Preload("Body").
+			Where("emails.id > ? AND emails.is_deleted = ?", lastID, false).
+			Where("NOT EXISTS (SELECT 1 FROM email_embeddings WHERE email_embeddings.email_id = emails.id AND "+
+				"email_embeddings.model = ? AND email_embeddings.deleted_at IS NULL)", modelName).
+			Order("emails.id").
+			Limit(embeddingIndexBatchSize).
+			Find(&emails).Error; err != nil {
+			return indexed, fmt.Errorf("failed to find emails without embeddings: %w", err)
+		}
+		if len(emails) == 0 {
+			return indexed, nil
+		}
+
+		texts := make([]string, len(emails))
+		for i, email := range emails {
+			texts[i] = buildEmbeddingText(email)
+		}
+		vectors, err := s.embeddingModel.Embed(ctx, texts)
+		if err != nil {
+			return indexed, fmt.Errorf("failed to embed emails: %w", err)
+		}
+
+		embeddings := make([]*models.EmailEmbedding, len(emails))
+		for i, email := range emails {
+			embeddings[i] = &models.EmailEmbedding{EmailID: email.ID, AccountID: email.AccountID, Model: modelName}
+			embeddings[i].SetVector(vectors[i])
+		}
+		// 更换模型后按 email_id 覆盖旧向量
+		if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "email_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"account_id", "model", "dimension", "vector", "updated_at", "deleted_at"}),
+		}).Create(&embeddings).Error; err != nil {
+			return indexed, fmt.Errorf("failed to save email embeddings: %w", err)
+		}
+
+		indexed += len(emails)
+		lastID = emails[len(emails)-1].ID
+	}
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"firemail/internal/config"
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeEmbeddingServer 模拟 /embeddings 接口：按概念词表生成向量，同一概念的不同说法得到相近的向量
+func newFakeEmbeddingServer(t *testing.T) *httptest.Server {
+	concepts := [][]string{
+		{"landlord", "heating", "radiator", "apartment", "rent", "warm"},
+		{"lunch", "canteen", "noon", "meal"},
+		{"invoice", "bill", "costs"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+		var req embeddingRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, "test-embed", req.Model)
+
+		var resp embeddingResponse
+		for i, input := range req.Input {
+			vector := make([]float32, len(concepts)+1)
+			vector[len(concepts)] = 0.1
+			for _, word := range strings.Fields(strings.ToLower(input)) {
+				for dim, words := range concepts {
+					for _, w := range words {
+						if strings.Trim(word, ".,:<>") == w {
+							vector[dim]++
+						}
+					}
+				}
+			}
+			resp.Data = append(resp.Data, struct {
+				Index     int       `json:"index"`
+				Embedding []float32 `json:"embedding"`
+			}{Index: i, Embedding: vector})
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(resp))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSemanticSearchUsesEmbeddingModel(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.EmailEmbedding{}))
+	ctx := context.Background()
+
+	server := newFakeEmbeddingServer(t)
+	env.service.SetEmbeddingModel(NewHTTPEmbeddingModel(config.SemanticSearchConfig{
+		EmbeddingURL:   server.URL,
+		EmbeddingModel: "test-embed",
+		APIKey:         "test-key",
+		Timeout:        5 * time.Second,
+	}))
+
+	create := func(uid uint32, age time.Duration, subject, from, body string) *models.Email {
+		email := &models.Email{
+			AccountID: env.account.ID,
+			FolderID:  &env.inbox.ID,
+			MessageID: fmt.Sprintf("<semantic-%d@example.com>", uid),
+			UID:       uid,
+			Subject:   subject,
+			From:      from,
+			Date:      time.Now().UTC().Add(-age),
+			TextBody:  body,
+		}
+		require.NoError(t, env.db.Create(email).Error)
+		return email
+	}
+
+	// 目标邮件是两年前的，且不包含查询中的任何关键词
+	target := create(1, 2*365*24*time.Hour, "March statement", "Property office <office@rent.example.com>",
+		"The radiator repair and warm water costs for your apartment.")
+	create(2, time.Hour, "Team lunch on Friday", "colleague@example.com", "Let's meet at noon in the canteen.")
+	create(3, time.Minute, "Release notes", "ci@example.com", "Version 2.1 is out.")
+
+	search := func() *GetEmailsResponse {
+		resp, err := env.service.SearchEmails(ctx, env.user.ID, &SearchEmailsRequest{
+			Query:    "the landlord about heating",
+			Mode:     SearchModeSemantic,
+			Page:     1,
+			PageSize: 10,
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	// 尚未建立索引时没有结果，搜索本身不写入索引
+	require.Empty(t, search().Emails)
+	var count int64
+	require.NoError(t, env.db.Model(&models.EmailEmbedding{}).Count(&count).Error)
+	require.Zero(t, count)
+
+	indexed, err := env.service.IndexEmailEmbeddings(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 3, indexed)
+	indexed, err = env.service.IndexEmailEmbeddings(ctx)
+	require.NoError(t, err)
+	require.Zero(t, indexed)
+
+	resp := search()
+	require.Len(t, resp.Emails, 1)
+	require.Equal(t, target.ID, resp.Emails[0].ID)
+	require.Empty(t, resp.Emails[0].TextBody)
+
+	// 其他模型生成的向量不参与搜索，并由索引任务覆盖
+	require.NoError(t, env.db.Model(&models.EmailEmbedding{}).Where("1 = 1").Update("model", "old-model").Error)
+	require.Empty(t, search().Emails)
+	indexed, err = env.service.IndexEmailEmbeddings(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 3, indexed)
+	require.NoError(t, env.db.Model(&models.EmailEmbedding{}).Count(&count).Error)
+	require.Equal(t, int64(3), count)
+	require.Len(t, search().Emails, 1)
+}
+
+func TestSemanticSearchRequiresEmbeddingModel(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+
+	_, err := env.service.SearchEmails(context.Background(), env.user.ID, &SearchEmailsRequest{
+		Query: "heating",
+		Mode:  SearchModeSemantic,
+	})
+	require.ErrorIs(t, err, ErrSemanticSearchUnavailable)
+
+	indexed, err := env.service.IndexEmailEmbeddings(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, indexed)
+}
+
+func TestTokenizeForSearchHandlesCJK(t *testing.T) {
+	tokens := tokenizeForSearch("房东的暖气发票 Invoices")
+	require.Contains(t, tokens, "暖气")
+	require.Contains(t, tokens, "发票")
+	require.Contains(t, tokens, "invoice")
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"firemail/internal/models"
+)
+
+// maxSendAliases 每个账户最多配置的发件别名数量
+const maxSendAliases = 50
+
+// applySendAliases 校验并设置账户的发件别名：去除首尾空白、转小写、去重，忽略与账户邮箱相同的地址
+func applySendAliases(account *models.EmailAccount, aliases []string) error {
+	if len(aliases) > maxSendAliases {
+		return fmt.Errorf("too many send aliases: at most %d", maxSendAliases)
+	}
+
+	normalized := make([]string, 0, len(aliases))
+	seen := make(map[string]bool, len(aliases))
+	for _, alias := range aliases {
+		alias = strings.ToLower(strings.TrimSpace(alias))
+		if alias == "" {
+			continue
+		}
+		if parsed, err := mail.ParseAddress(alias); err != nil || parsed.Address != alias {
+			return fmt.Errorf("invalid send alias: %s", alias)
+		}
+		if seen[alias] || strings.EqualFold(alias, account.Email) {
+			continue
+		}
+		seen[alias] = true
+		normalized = append(normalized, alias)
+	}
+
+	return account.SetSendAliases(normalized)
+}
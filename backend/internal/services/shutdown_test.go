@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+	"firemail/internal/providers"
+
+	"github.com/stretchr/testify/require"
+)
+
+// blockingSyncProvider 连接时阻塞直到上下文取消，模拟进行中的同步
+type blockingSyncProvider struct {
+	*fakeEmailProvider
+	started chan struct{}
+}
+
+func (p *blockingSyncProvider) Connect(ctx context.Context, _ *models.EmailAccount) error {
+	close(p.started)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+type blockingSyncProviderFactory struct {
+	provider *blockingSyncProvider
+}
+
+func (f *blockingSyncProviderFactory) CreateProviderForAccount(*models.EmailAccount) (providers.EmailProvider, error) {
+	return f.provider, nil
+}
+
+func TestSyncServiceShutdownCancelsRunningSync(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+
+	provider := &blockingSyncProvider{fakeEmailProvider: env.provider, started: make(chan struct{})}
+	syncService := NewSyncService(env.db, &blockingSyncProviderFactory{provider: provider}, nil, nil, nil, nil)
+
+	result := make(chan error, 1)
+	go func() {
+		result <- syncService.SyncEmails(context.Background(), env.account.ID)
+	}()
+	<-provider.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, syncService.Shutdown(ctx))
+	require.Error(t, <-result)
+
+	// 被中断的账户恢复为待同步，而不是同步错误
+	var account models.EmailAccount
+	require.NoError(t, env.db.First(&account, env.account.ID).Error)
+	require.Equal(t, "pending", account.SyncStatus)
+	require.Equal(t, syncInterruptedMessage, account.ErrorMessage)
+
+	// 关闭后拒绝新的同步
+	require.ErrorIs(t, syncService.SyncEmails(context.Background(), env.account.ID), ErrSyncServiceShuttingDown)
+	require.ErrorIs(t, syncService.SyncFolder(context.Background(), env.account.ID, "INBOX"), ErrSyncServiceShuttingDown)
+}
+
+func TestScheduledEmailShutdownPausesQueue(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.SendQueue{}))
+	ctx := context.Background()
+
+	past := time.Now().Add(-time.Minute)
+	due := &models.SendQueue{SendID: "due", UserID: env.user.ID, AccountID: env.account.ID, EmailData: "{}", ScheduledAt: &past, Status: "scheduled"}
+	interrupted := &models.SendQueue{SendID: "interrupted", UserID: env.user.ID, AccountID: env.account.ID, EmailData: "{}", ScheduledAt: &past, Status: "processing"}
+	require.NoError(t, env.db.Create(due).Error)
+	require.NoError(t, env.db.Create(interrupted).Error)
+
+	// 启动时上次仍在组装、尚未交给发送器的邮件放回队列
+	service := NewScheduledEmailService(env.db, env.service, nil, nil)
+	require.NoError(t, service.StartScheduler(ctx))
+	require.NoError(t, env.db.First(interrupted, interrupted.ID).Error)
+	require.Equal(t, "scheduled", interrupted.Status)
+	require.Equal(t, scheduledInterruptedMessage, interrupted.LastError)
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	require.NoError(t, service.Shutdown(shutdownCtx))
+
+	// 暂停后不再领取到期邮件，保持原状态等待重启
+	require.NoError(t, service.ProcessScheduledEmails(ctx))
+	require.NoError(t, env.db.First(due, due.ID).Error)
+	require.Equal(t, "scheduled", due.Status)
+
+	// 重复关闭不会出错
+	require.NoError(t, service.Shutdown(shutdownCtx))
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"firemail/internal/smtpd"
+)
+
+// SMTPDeliveryBackend 内置SMTP服务器的投递后端：按收件地址找到入站端点，通过入站流程写入虚拟账户
+type SMTPDeliveryBackend struct {
+	ingest IngestService
+}
+
+// NewSMTPDeliveryBackend 创建SMTP投递后端
+func NewSMTPDeliveryBackend(ingest IngestService) *SMTPDeliveryBackend {
+	return &SMTPDeliveryBackend{ingest: ingest}
+}
+
+// ValidateRecipient 检查收件地址是否对应启用的入站端点
+func (b *SMTPDeliveryBackend) ValidateRecipient(ctx context.Context, address string) error {
+	_, err := b.ingest.FindEndpointByAddress(ctx, address)
+	if errors.Is(err, ErrIngestEndpointNotFound) {
+		return &smtpd.Error{Code: 550, EnhancedCode: "5.1.1", Message: "Mailbox unavailable"}
+	}
+	return err
+}
+
+// Deliver 投递到每个收件人对应的端点，多个收件人指向同一端点时只写入一次
+func (b *SMTPDeliveryBackend) Deliver(ctx context.Context, env *smtpd.Envelope, data []byte) error {
+	delivered := make(map[uint]bool, len(env.Recipients))
+	for _, recipient := range env.Recipients {
+		endpoint, err := b.ingest.FindEndpointByAddress(ctx, recipient)
+		if err != nil {
+			// 端点在RCPT之后被停用或删除，其余收件人照常投递
+			if errors.Is(err, ErrIngestEndpointNotFound) {
+				continue
+			}
+			return err
+		}
+		if delivered[endpoint.ID] {
+			continue
+		}
+
+		if _, err := b.ingest.IngestRaw(ctx, endpoint, data); err != nil {
+			if errors.Is(err, ErrInvalidIngestMessage) {
+				return &smtpd.Error{Code: 554, EnhancedCode: "5.6.0", Message: err.Error()}
+			}
+			return fmt.Errorf("failed to deliver %s to %s: %w", env.ID, recipient, err)
+		}
+		delivered[endpoint.ID] = true
+	}
+	return nil
+}
@@ -67,7 +67,6 @@ func (s *SoftDeleteServiceImpl) CleanupExpiredSoftDeletes(ctx context.Context, r
 		name  string
 		model interface{}
 	}{
-		{"emails", &models.Email{}},
 		{"email_accounts", &models.EmailAccount{}},
 		{"folders", &models.Folder{}},
 		{"attachments", &models.Attachment{}},
@@ -75,6 +74,18 @@ func (s *SoftDeleteServiceImpl) CleanupExpiredSoftDeletes(ctx context.Context, r
 	}
 
 	totalCleaned := 0
+
+	// 邮件按回收站语义清理，见 email_trash.go
+	purged, err := PurgeExpiredTrashedEmails(ctx, s.db, cutoffTime)
+	if err != nil {
+		log.Printf("Warning: failed to purge expired trashed emails: %v", err)
+	} else {
+		totalCleaned += int(purged)
+		if purged > 0 {
+			log.Printf("Purged %d emails from trash", purged)
+		}
+	}
+
 	for _, table := range tables {
 		count, err := s.cleanupTableSoftDeletes(ctx, table.name, table.model, cutoffTime)
 		if err != nil {
@@ -87,10 +98,34 @@ func (s *SoftDeleteServiceImpl) CleanupExpiredSoftDeletes(ctx context.Context, r
 		}
 	}
 
+	// 过期的草稿修订历史
+	count, err := s.cleanupExpiredDraftRevisions(ctx, cutoffTime)
+	if err != nil {
+		log.Printf("Warning: failed to cleanup draft revisions: %v", err)
+	} else {
+		totalCleaned += count
+		if count > 0 {
+			log.Printf("Cleaned up %d expired draft revisions", count)
+		}
+	}
+
 	log.Printf("Soft delete cleanup completed: %d total records permanently deleted", totalCleaned)
 	return nil
 }
 
+// cleanupExpiredDraftRevisions 清理过期的草稿修订以及已删除草稿的修订
+func (s *SoftDeleteServiceImpl) cleanupExpiredDraftRevisions(ctx context.Context, cutoffTime time.Time) (int, error) {
+	liveDrafts := s.db.Model(&models.Draft{}).Select("id")
+	result := s.db.WithContext(ctx).Unscoped().
+		Where("created_at < ? OR deleted_at IS NOT NULL OR draft_id NOT IN (?)", cutoffTime, liveDrafts).
+		Delete(&models.DraftRevision{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to cleanup draft_revisions: %w", result.Error)
+	}
+
+	return int(result.RowsAffected), nil
+}
+
 // cleanupTableSoftDeletes 清理指定表的软删除数据
 func (s *SoftDeleteServiceImpl) cleanupTableSoftDeletes(ctx context.Context, tableName string, model interface{}, cutoffTime time.Time) (int, error) {
 	// 使用Unscoped()来操作软删除的记录
@@ -108,7 +143,7 @@ func (s *SoftDeleteServiceImpl) RestoreSoftDeleted(ctx context.Context, tableNam
 	var model interface{}
 	switch tableName {
 	case "emails":
-		model = &models.Email{}
+		return s.restoreTrashedEmail(ctx, id)
 	case "email_accounts":
 		model = &models.EmailAccount{}
 	case "folders":
@@ -141,7 +176,7 @@ func (s *SoftDeleteServiceImpl) PermanentlyDelete(ctx context.Context, tableName
 	var model interface{}
 	switch tableName {
 	case "emails":
-		model = &models.Email{}
+		return s.purgeEmail(ctx, id)
 	case "email_accounts":
 		model = &models.EmailAccount{}
 	case "folders":
@@ -168,6 +203,41 @@ func (s *SoftDeleteServiceImpl) PermanentlyDelete(ctx context.Context, tableName
 	return nil
 }
 
+// restoreTrashedEmail 管理员恢复回收站中的邮件；计数会在下次同步时校正
+func (s *SoftDeleteServiceImpl) restoreTrashedEmail(ctx context.Context, id uint) error {
+	result := s.db.WithContext(ctx).Model(&models.Email{}).
+		Where("id = ? AND is_deleted = ?", id, true).
+		Updates(map[string]interface{}{"is_deleted": false, "trashed_at": nil})
+	if result.Error != nil {
+		return fmt.Errorf("failed to restore email: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("no trashed email found with id %d", id)
+	}
+
+	log.Printf("Restored trashed email: id=%d", id)
+	return nil
+}
+
+// purgeEmail 永久删除邮件及其附件
+func (s *SoftDeleteServiceImpl) purgeEmail(ctx context.Context, id uint) error {
+	var purged int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		count, err := purgeEmails(tx, "id = ?", id)
+		purged = count
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to permanently delete email: %w", err)
+	}
+	if purged == 0 {
+		return fmt.Errorf("no record found with id %d in table emails", id)
+	}
+
+	log.Printf("Permanently deleted record: table=emails, id=%d", id)
+	return nil
+}
+
 // GetSoftDeleteStats 获取软删除统计信息
 func (s *SoftDeleteServiceImpl) GetSoftDeleteStats(ctx context.Context) (*SoftDeleteStats, error) {
 	stats := &SoftDeleteStats{
@@ -180,13 +250,28 @@ func (s *SoftDeleteServiceImpl) GetSoftDeleteStats(ctx context.Context) (*SoftDe
 		name  string
 		model interface{}
 	}{
-		{"emails", &models.Email{}},
 		{"email_accounts", &models.EmailAccount{}},
 		{"folders", &models.Folder{}},
 		{"attachments", &models.Attachment{}},
 		{"users", &models.User{}},
 	}
 
+	// 邮件统计回收站中的数量
+	var trashed int64
+	if err := s.db.WithContext(ctx).Model(&models.Email{}).Where("is_deleted = ?", true).Count(&trashed).Error; err != nil {
+		log.Printf("Warning: failed to count trashed emails: %v", err)
+	} else {
+		stats.TotalSoftDeleted["emails"] = trashed
+		var oldest []time.Time
+		if trashed > 0 && s.db.WithContext(ctx).Model(&models.Email{}).
+			Where("is_deleted = ? AND trashed_at IS NOT NULL", true).
+			Order("trashed_at ASC").
+			Limit(1).
+			Pluck("trashed_at", &oldest).Error == nil && len(oldest) > 0 {
+			stats.OldestDeleted["emails"] = oldest[0]
+		}
+	}
+
 	for _, table := range tables {
 		// 统计软删除记录数量
 		var count int64
@@ -252,27 +337,26 @@ func (s *SoftDeleteServiceImpl) StopAutoCleanup() {
 	close(s.stopChan)
 }
 
-// ValidateSoftDeleteQueries 验证软删除查询的辅助函数
+// ValidateSoftDeleteQueries 检查邮件是否都符合回收站语义（见 email_trash.go）
 func ValidateSoftDeleteQueries(db *gorm.DB) error {
-	// 这个函数可以用来验证所有查询都正确处理了软删除
-	// 在开发和测试环境中使用
-	
 	log.Println("Validating soft delete query behavior...")
-	
-	// 测试基本的软删除行为
-	var count int64
-	
-	// 正常查询应该不包含软删除的记录
-	if err := db.Model(&models.Email{}).Count(&count).Error; err != nil {
-		return fmt.Errorf("failed to count emails: %w", err)
+
+	// 邮件不应使用gorm的deleted_at软删除
+	var legacyDeleted int64
+	if err := db.Unscoped().Model(&models.Email{}).Where("deleted_at IS NOT NULL").Count(&legacyDeleted).Error; err != nil {
+		return fmt.Errorf("failed to count legacy soft deleted emails: %w", err)
 	}
-	
-	// 使用Unscoped查询应该包含所有记录
-	var totalCount int64
-	if err := db.Unscoped().Model(&models.Email{}).Count(&totalCount).Error; err != nil {
-		return fmt.Errorf("failed to count all emails: %w", err)
+
+	// 回收站中的邮件需要删除时间，否则永远不会被清理
+	var untimedTrash int64
+	if err := db.Model(&models.Email{}).Where("is_deleted = ? AND trashed_at IS NULL", true).Count(&untimedTrash).Error; err != nil {
+		return fmt.Errorf("failed to count trashed emails without timestamp: %w", err)
+	}
+
+	if legacyDeleted > 0 || untimedTrash > 0 {
+		log.Printf("Warning: soft delete validation found %d emails with deleted_at and %d trashed emails without trashed_at", legacyDeleted, untimedTrash)
+	} else {
+		log.Println("Soft delete validation passed")
 	}
-	
-	log.Printf("Soft delete validation: normal count=%d, total count=%d", count, totalCount)
 	return nil
 }
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"firemail/internal/database/writequeue"
+	"firemail/internal/metrics"
+	"firemail/internal/models"
+	"firemail/internal/providers"
+
+	"gorm.io/gorm"
+)
+
+// syncWriteBatchSize 同步时每个写入事务包含的新邮件数，减少逐封提交带来的fsync开销
+const syncWriteBatchSize = 100
+
+// syncedEmailResult 批量保存中单封邮件的结果，created 和 conflict 的含义同 storeSyncedEmail
+type syncedEmailResult struct {
+	created  *models.Email
+	conflict *models.SyncConflict
+	err      error
+}
+
+// writeBatchLimit 每个写入事务包含的新邮件数上限
+func (s *SyncService) writeBatchLimit() int {
+	if s.writeBatchSize > 0 {
+		return s.writeBatchSize
+	}
+	return syncWriteBatchSize
+}
+
+// saveSyncedEmailBatch 保存一批同步到的邮件。先逐封检查重复，重复邮件按原有方式跳过或更新，
+// 新邮件在一个事务中写入；结果与 emailMsgs 一一对应。同一账户的批次依次写入
+func (s *SyncService) saveSyncedEmailBatch(ctx context.Context, account *models.EmailAccount, folderID uint, emailMsgs []*providers.EmailMessage) []syncedEmailResult {
+	writeLock := s.getAccountWriteLock(account.ID)
+	writeLock.Lock()
+	defer writeLock.Unlock()
+
+	results := make([]syncedEmailResult, len(emailMsgs))
+	deduplicator := s.deduplicatorFactory.CreateDeduplicator(account.Provider)
+
+	var fresh []int
+	seen := make(map[string]bool)
+	for i, emailMsg := range emailMsgs {
+		// 同一批中重复出现的邮件，逐封保存时第二封会因已存在而跳过
+		if emailMsg.MessageID != "" && seen[emailMsg.MessageID] {
+			log.Printf("Skipping duplicate email: %s (reason: repeated in sync batch)", emailMsg.MessageID)
+			continue
+		}
+
+		duplicateResult, err := deduplicator.CheckDuplicate(ctx, emailMsg, account.ID, folderID)
+		if err != nil {
+			results[i].err = fmt.Errorf("failed to check duplicate: %w", err)
+			continue
+		}
+		handled, conflict, err := s.handleSyncedDuplicate(ctx, account, deduplicator, duplicateResult, emailMsg, folderID, account.UserID)
+		if handled || err != nil {
+			results[i] = syncedEmailResult{conflict: conflict, err: err}
+			continue
+		}
+
+		if emailMsg.MessageID != "" {
+			seen[emailMsg.MessageID] = true
+		}
+		fresh = append(fresh, i)
+	}
+
+	s.insertSyncedBatch(ctx, account, folderID, emailMsgs, fresh, results)
+	return results
+}
+
+// insertSyncedBatch 在一个事务中写入一批新邮件。唯一约束冲突时整批回滚，拆成两半分别重试，
+// 只剩一封时回退到逐封保存，由其重新检查重复
+func (s *SyncService) insertSyncedBatch(ctx context.Context, account *models.EmailAccount, folderID uint, emailMsgs []*providers.EmailMessage, indexes []int, results []syncedEmailResult) {
+	if len(indexes) == 0 {
+		return
+	}
+
+	started := time.Now()
+	inserted := make([]*insertedSyncedEmail, 0, len(indexes))
+	err := writequeue.Transaction(ctx, s.db, func(tx *gorm.DB) error {
+		for _, i := range indexes {
+			email, err := s.insertSyncedEmail(ctx, tx, account, emailMsgs[i], folderID, account.UserID)
+			if err != nil {
+				return err
+			}
+			inserted = append(inserted, email)
+		}
+		return nil
+	})
+
+	switch {
+	case err == nil:
+		metrics.ObserveSyncWriteBatch(len(indexes), time.Since(started), metrics.BatchResultCommitted)
+	case isUniqueConstraintError(err):
+		metrics.ObserveSyncWriteBatch(len(indexes), time.Since(started), metrics.BatchResultSplit)
+		if len(indexes) == 1 {
+			i := indexes[0]
+			created, conflict, storeErr := s.storeSyncedEmail(ctx, emailMsgs[i], account.ID, folderID, account.UserID)
+			results[i] = syncedEmailResult{created: created, conflict: conflict, err: storeErr}
+			return
+		}
+		log.Printf("Unique constraint violation in sync batch of %d emails, retrying in halves", len(indexes))
+		half := len(indexes) / 2
+		s.insertSyncedBatch(ctx, account, folderID, emailMsgs, indexes[:half], results)
+		s.insertSyncedBatch(ctx, account, folderID, emailMsgs, indexes[half:], results)
+		return
+	default:
+		metrics.ObserveSyncWriteBatch(len(indexes), time.Since(started), metrics.BatchResultFailed)
+		for _, i := range indexes {
+			results[i].err = err
+		}
+		return
+	}
+
+	for k, i := range indexes {
+		results[i].created = inserted[k].email
+		s.afterSyncedEmailInserted(ctx, account, inserted[k], folderID, account.UserID)
+	}
+}
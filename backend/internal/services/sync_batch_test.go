@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+	"firemail/internal/providers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveSyncedEmailBatch(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+	require.NoError(t, env.db.AutoMigrate(&models.EmailEvent{}, &models.SyncConflict{}, &models.UserSetting{}))
+	require.NoError(t, env.db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_emails_account_folder_uid_unique ON emails(account_id, folder_id, uid)").Error)
+
+	existing := &models.Email{
+		AccountID: env.account.ID,
+		FolderID:  &env.work.ID,
+		MessageID: "<existing@example.com>",
+		UID:       1,
+		Subject:   "Existing",
+		Date:      time.Now(),
+	}
+	require.NoError(t, env.db.Create(existing).Error)
+
+	message := func(messageID string, uid uint32) *providers.EmailMessage {
+		return &providers.EmailMessage{MessageID: messageID, UID: uid, Subject: "Batch", Date: time.Now()}
+	}
+	batch := []*providers.EmailMessage{
+		message("<first@example.com>", 50),
+		message("<first@example.com>", 51),
+		message("<same-uid@example.com>", 50),
+		message("<second@example.com>", 52),
+		message("<existing@example.com>", 60),
+	}
+
+	syncService := NewSyncService(env.db, nil, env.publisher, NewDeduplicatorFactory(env.db), nil, nil)
+	results := syncService.saveSyncedEmailBatch(ctx, env.account, env.inbox.ID, batch)
+	require.Len(t, results, len(batch))
+	for _, result := range results {
+		require.NoError(t, result.err)
+	}
+
+	// 批内重复的Message-ID直接跳过；UID冲突导致整批回滚后拆分重试，其余邮件照常写入
+	require.NotNil(t, results[0].created)
+	require.Nil(t, results[1].created)
+	require.Nil(t, results[2].created)
+	require.NotNil(t, results[3].created)
+	require.Nil(t, results[4].created)
+
+	var count int64
+	require.NoError(t, env.db.Model(&models.Email{}).Where("account_id = ? AND folder_id = ?", env.account.ID, env.inbox.ID).Count(&count).Error)
+	require.EqualValues(t, 3, count)
+
+	// 其他文件夹中的已有邮件更新为服务器上的位置
+	require.NoError(t, env.db.First(existing, existing.ID).Error)
+	require.Equal(t, env.inbox.ID, *existing.FolderID)
+}
@@ -0,0 +1,472 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"firemail/internal/models"
+	"firemail/internal/providers"
+	"firemail/internal/sse"
+
+	"gorm.io/gorm"
+)
+
+// ErrSyncConflictResolved 同步冲突已处理
+var ErrSyncConflictResolved = errors.New("sync conflict already resolved")
+
+// ResolveSyncConflictRequest 处理同步冲突请求
+type ResolveSyncConflictRequest struct {
+	Resolution string `json:"resolution" binding:"required,oneof=server local"` // server 采用服务器状态，local 采用本地状态
+}
+
+// syncConflictEventTypes 本地修改各状态时记录的历史事件类型
+var syncConflictEventTypes = map[string][]string{
+	models.SyncConflictFieldRead:    {models.EmailEventRead, models.EmailEventUnread},
+	models.SyncConflictFieldStarred: {models.EmailEventStarred, models.EmailEventUnstarred},
+	models.SyncConflictFieldFolder:  {models.EmailEventMoved},
+	models.SyncConflictFieldDeleted: {models.EmailEventDeleted},
+}
+
+// syncConflictsAvailable 同步冲突表是否存在
+func syncConflictsAvailable(db *gorm.DB) bool {
+	return db.Migrator().HasTable(&models.SyncConflict{})
+}
+
+// syncConflictPolicy 用户的冲突处理策略，未设置或无效时以服务器为准
+func syncConflictPolicy(ctx context.Context, db *gorm.DB, userID uint) string {
+	policy := userSettingsOrDefault(ctx, db, userID).SyncConflictPolicy
+	if !models.IsSyncConflictPolicy(policy) {
+		return models.SyncConflictPolicyServerWins
+	}
+	return policy
+}
+
+// localConflictState 邮件在本地的状态
+func localConflictState(email *models.Email) *models.SyncConflictState {
+	return &models.SyncConflictState{
+		IsRead:    email.IsRead,
+		IsStarred: email.IsStarred,
+		IsDeleted: email.IsDeleted,
+		FolderID:  email.FolderID,
+	}
+}
+
+// remoteConflictState 邮件在服务器上的状态：位于正在同步的文件夹中，
+// 除非该文件夹是回收站，否则视为未删除
+func (s *SyncService) remoteConflictState(ctx context.Context, email *models.Email, emailMsg *providers.EmailMessage, folderID uint) *models.SyncConflictState {
+	remote := &models.SyncConflictState{
+		IsRead:    s.isEmailRead(emailMsg.Flags),
+		IsStarred: s.isEmailStarred(emailMsg.Flags),
+		IsDeleted: email.IsDeleted,
+		FolderID:  &folderID,
+	}
+	if email.IsDeleted {
+		var folder models.Folder
+		if err := s.db.WithContext(ctx).Select("id", "type").First(&folder, folderID).Error; err == nil && folder.Type != models.FolderTypeTrash {
+			remote.IsDeleted = false
+		}
+	}
+	return remote
+}
+
+// conflictFields 比较双方状态，返回两边都修改过的状态。
+// 只有自上次同步以来在本应用中修改过的状态才算冲突，其余差异是其他设备上的正常修改
+func conflictFields(ctx context.Context, db *gorm.DB, email *models.Email, local, remote *models.SyncConflictState, since time.Time) []string {
+	var differing []string
+	if local.IsRead != remote.IsRead {
+		differing = append(differing, models.SyncConflictFieldRead)
+	}
+	if local.IsStarred != remote.IsStarred {
+		differing = append(differing, models.SyncConflictFieldStarred)
+	}
+	if !sameFolderID(local.FolderID, remote.FolderID) {
+		differing = append(differing, models.SyncConflictFieldFolder)
+	}
+	if local.IsDeleted != remote.IsDeleted {
+		differing = append(differing, models.SyncConflictFieldDeleted)
+	}
+	if len(differing) == 0 {
+		return nil
+	}
+
+	var eventTypes []string
+	for _, field := range differing {
+		eventTypes = append(eventTypes, syncConflictEventTypes[field]...)
+	}
+	var changed []string
+	if err := db.WithContext(ctx).Model(&models.EmailEvent{}).
+		Where("email_id = ? AND source = ? AND type IN ? AND created_at > ?", email.ID, models.EmailEventSourceUser, eventTypes, since).
+		Distinct().Pluck("type", &changed).Error; err != nil {
+		log.Printf("Failed to check local changes for email %d: %v", email.ID, err)
+		return nil
+	}
+	changedTypes := make(map[string]bool, len(changed))
+	for _, eventType := range changed {
+		changedTypes[eventType] = true
+	}
+
+	var fields []string
+	for _, field := range differing {
+		for _, eventType := range syncConflictEventTypes[field] {
+			if changedTypes[eventType] {
+				fields = append(fields, field)
+				break
+			}
+		}
+	}
+	return fields
+}
+
+// detectSyncConflict 在用服务器状态更新已有邮件前检查冲突，没有冲突时返回nil。
+// 邮件已有待处理的冲突时只刷新服务器一侧的状态，本地状态保持不变
+func (s *SyncService) detectSyncConflict(ctx context.Context, account *models.EmailAccount, email *models.Email, emailMsg *providers.EmailMessage, folderID uint) *models.SyncConflict {
+	if account.LastSyncAt == nil || !syncConflictsAvailable(s.db) {
+		return nil
+	}
+
+	local := localConflictState(email)
+	remote := s.remoteConflictState(ctx, email, emailMsg, folderID)
+
+	var pending models.SyncConflict
+	err := s.db.WithContext(ctx).
+		Where("email_id = ? AND status = ?", email.ID, models.SyncConflictStatusPending).
+		Take(&pending).Error
+	if err == nil {
+		if err := s.db.WithContext(ctx).Model(&pending).Updates(map[string]interface{}{
+			"remote_state": encodeConflictState(remote),
+			"remote_uid":   emailMsg.UID,
+		}).Error; err != nil {
+			log.Printf("Failed to refresh sync conflict %d: %v", pending.ID, err)
+		}
+		pending.RemoteUID = emailMsg.UID
+		pending.RemoteState = encodeConflictState(remote)
+		return &pending
+	}
+	if err != gorm.ErrRecordNotFound {
+		log.Printf("Failed to find sync conflict for email %d: %v", email.ID, err)
+		return nil
+	}
+
+	fields := conflictFields(ctx, s.db, email, local, remote, *account.LastSyncAt)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	conflict := &models.SyncConflict{
+		UserID:      account.UserID,
+		AccountID:   account.ID,
+		EmailID:     email.ID,
+		Fields:      strings.Join(fields, ","),
+		LocalState:  encodeConflictState(local),
+		RemoteState: encodeConflictState(remote),
+		RemoteUID:   emailMsg.UID,
+		Policy:      syncConflictPolicy(ctx, s.db, account.UserID),
+		Status:      models.SyncConflictStatusPending,
+		FieldNames:  fields,
+		Local:       local,
+		Remote:      remote,
+	}
+	switch conflict.Policy {
+	case models.SyncConflictPolicyServerWins:
+		markConflictResolved(conflict, models.SyncConflictResolutionServer)
+	case models.SyncConflictPolicyLocalWins:
+		markConflictResolved(conflict, models.SyncConflictResolutionLocal)
+	}
+	if err := s.db.WithContext(ctx).Create(conflict).Error; err != nil {
+		log.Printf("Failed to record sync conflict for email %d: %v", email.ID, err)
+	}
+	log.Printf("Sync conflict on email %d (%s), policy %s", email.ID, conflict.Fields, conflict.Policy)
+
+	publishSyncConflictEvent(ctx, s.eventPublisher, conflict)
+	return conflict
+}
+
+// applyConflictState 将一方的状态写入邮件的冲突字段
+func applyConflictState(email *models.Email, state *models.SyncConflictState, fields []string) {
+	for _, field := range fields {
+		switch field {
+		case models.SyncConflictFieldRead:
+			email.IsRead = state.IsRead
+		case models.SyncConflictFieldStarred:
+			email.IsStarred = state.IsStarred
+		case models.SyncConflictFieldFolder:
+			email.FolderID = state.FolderID
+		case models.SyncConflictFieldDeleted:
+			email.IsDeleted = state.IsDeleted
+			if !email.IsDeleted {
+				email.TrashedAt = nil
+			}
+		}
+	}
+}
+
+// applyServerConflictState 以服务器为准时，去重器更新邮件后再写入服务器一侧的冲突字段
+func (s *SyncService) applyServerConflictState(ctx context.Context, email *models.Email, conflict *models.SyncConflict) error {
+	before := *email
+	applyConflictState(email, conflict.Remote, conflict.FieldList())
+	if err := saveSyncedEmailState(s.db.WithContext(ctx), &before, email); err != nil {
+		return fmt.Errorf("failed to apply server state: %w", err)
+	}
+	return nil
+}
+
+// pushLocalConflictState 将本地状态写回服务器。imapClient 需已连接，
+// 邮件位于服务器上冲突记录的文件夹中；星标只保存在本地，不写回
+func pushLocalConflictState(ctx context.Context, db *gorm.DB, imapClient providers.IMAPClient, conflict *models.SyncConflict) error {
+	if conflict.Remote == nil || conflict.Remote.FolderID == nil || conflict.RemoteUID == 0 {
+		return fmt.Errorf("sync conflict has no remote location")
+	}
+	var remoteFolder models.Folder
+	if err := db.WithContext(ctx).First(&remoteFolder, *conflict.Remote.FolderID).Error; err != nil {
+		return fmt.Errorf("failed to find remote folder: %w", err)
+	}
+	if _, err := imapClient.SelectFolder(ctx, remoteFolder.GetFullPath()); err != nil {
+		return fmt.Errorf("failed to select folder: %w", err)
+	}
+
+	uids := []uint32{conflict.RemoteUID}
+	if conflict.HasField(models.SyncConflictFieldRead) {
+		var err error
+		if conflict.Local.IsRead {
+			err = imapClient.MarkAsRead(ctx, uids)
+		} else {
+			err = imapClient.MarkAsUnread(ctx, uids)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update read state on server: %w", err)
+		}
+	}
+	if conflict.HasField(models.SyncConflictFieldDeleted) && conflict.Local.IsDeleted {
+		if err := imapClient.DeleteEmails(ctx, uids); err != nil {
+			return fmt.Errorf("failed to delete email on server: %w", err)
+		}
+		return nil
+	}
+	if conflict.HasField(models.SyncConflictFieldFolder) && conflict.Local.FolderID != nil {
+		var localFolder models.Folder
+		if err := db.WithContext(ctx).First(&localFolder, *conflict.Local.FolderID).Error; err != nil {
+			return fmt.Errorf("failed to find local folder: %w", err)
+		}
+		if err := imapClient.MoveEmails(ctx, uids, localFolder.GetFullPath()); err != nil {
+			return fmt.Errorf("failed to move email on server: %w", err)
+		}
+	}
+	return nil
+}
+
+// pushLocalConflictStates 文件夹同步完成后将以本地为准的冲突写回服务器，失败时只记录日志
+func (s *SyncService) pushLocalConflictStates(ctx context.Context, imapClient providers.IMAPClient, conflicts []*models.SyncConflict) {
+	for _, conflict := range conflicts {
+		if err := decodeSyncConflict(conflict); err != nil {
+			log.Printf("Failed to decode sync conflict %d: %v", conflict.ID, err)
+			continue
+		}
+		if err := pushLocalConflictState(ctx, s.db, imapClient, conflict); err != nil {
+			log.Printf("Failed to push local state of sync conflict %d: %v", conflict.ID, err)
+		}
+	}
+}
+
+// ListSyncConflicts 获取用户的同步冲突，status 为空时返回全部
+func (s *EmailServiceImpl) ListSyncConflicts(ctx context.Context, userID uint, status string) ([]models.SyncConflict, error) {
+	conflicts := make([]models.SyncConflict, 0)
+	if !syncConflictsAvailable(s.db) {
+		return conflicts, nil
+	}
+
+	query := s.db.WithContext(ctx).Where("user_id = ?", userID)
+	switch status {
+	case "":
+	case models.SyncConflictStatusPending, models.SyncConflictStatusResolved:
+		query = query.Where("status = ?", status)
+	default:
+		return nil, fmt.Errorf("invalid status: %s", status)
+	}
+	if err := query.Order("created_at DESC").Find(&conflicts).Error; err != nil {
+		return nil, fmt.Errorf("failed to get sync conflicts: %w", err)
+	}
+	for i := range conflicts {
+		if err := decodeSyncConflict(&conflicts[i]); err != nil {
+			log.Printf("Failed to decode sync conflict %d: %v", conflicts[i].ID, err)
+		}
+	}
+	return conflicts, nil
+}
+
+// ResolveSyncConflict 按用户的选择处理待处理的冲突：
+// server 将服务器状态写入本地，local 将本地状态写回服务器
+func (s *EmailServiceImpl) ResolveSyncConflict(ctx context.Context, userID, conflictID uint, resolution string) (*models.SyncConflict, error) {
+	if resolution != models.SyncConflictResolutionServer && resolution != models.SyncConflictResolutionLocal {
+		return nil, fmt.Errorf("invalid resolution: %s", resolution)
+	}
+	if !syncConflictsAvailable(s.db) {
+		return nil, fmt.Errorf("sync conflict not found")
+	}
+
+	var conflict models.SyncConflict
+	if err := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", conflictID, userID).Take(&conflict).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("sync conflict not found")
+		}
+		return nil, fmt.Errorf("failed to find sync conflict: %w", err)
+	}
+	if conflict.Status != models.SyncConflictStatusPending {
+		return nil, ErrSyncConflictResolved
+	}
+	if err := decodeSyncConflict(&conflict); err != nil {
+		return nil, err
+	}
+
+	var email models.Email
+	if err := s.db.WithContext(ctx).Preload("Account").First(&email, conflict.EmailID).Error; err != nil {
+		return nil, fmt.Errorf("email not found")
+	}
+
+	if resolution == models.SyncConflictResolutionServer {
+		if err := s.applyRemoteConflictState(ctx, userID, &email, &conflict); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := s.pushLocalConflictStateForAccount(ctx, &email.Account, &conflict); err != nil {
+			return nil, err
+		}
+	}
+
+	markConflictResolved(&conflict, resolution)
+	if err := s.db.WithContext(ctx).Model(&conflict).Updates(map[string]interface{}{
+		"status":      conflict.Status,
+		"resolution":  conflict.Resolution,
+		"resolved_at": conflict.ResolvedAt,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update sync conflict: %w", err)
+	}
+	publishSyncConflictEvent(ctx, s.eventPublisher, &conflict)
+
+	return &conflict, nil
+}
+
+// applyRemoteConflictState 将服务器状态写入本地邮件并维护计数
+func (s *EmailServiceImpl) applyRemoteConflictState(ctx context.Context, userID uint, email *models.Email, conflict *models.SyncConflict) error {
+	before := *email
+	fields := conflict.FieldList()
+	applyConflictState(email, conflict.Remote, fields)
+	if conflict.HasField(models.SyncConflictFieldFolder) && conflict.RemoteUID != 0 {
+		email.UID = conflict.RemoteUID
+	}
+	if err := saveSyncedEmailState(s.db.WithContext(ctx), &before, email); err != nil {
+		return fmt.Errorf("failed to update email: %w", err)
+	}
+
+	accountDelta, folderDeltas := emailCounterChanges(&before, email)
+	if err := s.adjustEmailCounters(ctx, userID, email.AccountID, accountDelta, folderDeltas); err != nil {
+		return err
+	}
+	recordEmailChanges(ctx, s.changeLog, userID, email.AccountID, models.ChangeActionUpdated, email.ID)
+	recordEmailEvents(ctx, s.db, emailStateEvents(userID, &before, email, models.EmailEventSourceSync)...)
+	return nil
+}
+
+// pushLocalConflictStateForAccount 连接账户的邮件服务器并写回本地状态
+func (s *EmailServiceImpl) pushLocalConflictStateForAccount(ctx context.Context, account *models.EmailAccount, conflict *models.SyncConflict) error {
+	provider, err := s.providerFactory.CreateProviderForAccount(account)
+	if err != nil {
+		return fmt.Errorf("failed to create provider: %w", err)
+	}
+	s.setupProviderTokenCallback(provider)
+
+	if err := provider.Connect(ctx, account); err != nil {
+		return fmt.Errorf("failed to connect to email server: %w", err)
+	}
+	defer provider.Disconnect()
+
+	imapClient := provider.IMAPClient()
+	if imapClient == nil {
+		return fmt.Errorf("IMAP client not available")
+	}
+	return pushLocalConflictState(ctx, s.db, imapClient, conflict)
+}
+
+// emailCounterChanges 邮件状态变化对账户和文件夹计数的影响，已删除的邮件不计数
+func emailCounterChanges(before, after *models.Email) (emailCounterDelta, map[uint]emailCounterDelta) {
+	counted := func(email *models.Email) emailCounterDelta {
+		if email.IsDeleted {
+			return emailCounterDelta{}
+		}
+		delta := emailCounterDelta{Total: 1}
+		if !email.IsRead {
+			delta.Unread = 1
+		}
+		return delta
+	}
+
+	old, current := counted(before), counted(after)
+	folders := make(map[uint]emailCounterDelta)
+	if before.FolderID != nil {
+		delta := folders[*before.FolderID]
+		delta.Total -= old.Total
+		delta.Unread -= old.Unread
+		folders[*before.FolderID] = delta
+	}
+	if after.FolderID != nil {
+		delta := folders[*after.FolderID]
+		delta.Total += current.Total
+		delta.Unread += current.Unread
+		folders[*after.FolderID] = delta
+	}
+	return emailCounterDelta{Total: current.Total - old.Total, Unread: current.Unread - old.Unread}, folders
+}
+
+// markConflictResolved 标记冲突已按指定结果处理
+func markConflictResolved(conflict *models.SyncConflict, resolution string) {
+	now := time.Now()
+	conflict.Status = models.SyncConflictStatusResolved
+	conflict.Resolution = resolution
+	conflict.ResolvedAt = &now
+}
+
+// encodeConflictState 序列化冲突一方的状态
+func encodeConflictState(state *models.SyncConflictState) string {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// decodeSyncConflict 解析冲突的状态列表和双方的状态
+func decodeSyncConflict(conflict *models.SyncConflict) error {
+	conflict.FieldNames = conflict.FieldList()
+	conflict.Local = &models.SyncConflictState{}
+	conflict.Remote = &models.SyncConflictState{}
+	if err := json.Unmarshal([]byte(conflict.LocalState), conflict.Local); err != nil {
+		return fmt.Errorf("invalid local state: %w", err)
+	}
+	if err := json.Unmarshal([]byte(conflict.RemoteState), conflict.Remote); err != nil {
+		return fmt.Errorf("invalid remote state: %w", err)
+	}
+	return nil
+}
+
+// publishSyncConflictEvent 推送同步冲突事件，发布失败只记录日志
+func publishSyncConflictEvent(ctx context.Context, publisher sse.EventPublisher, conflict *models.SyncConflict) {
+	if publisher == nil {
+		return
+	}
+	event := sse.NewSyncConflictEvent(&sse.SyncConflictEventData{
+		ConflictID: conflict.ID,
+		AccountID:  conflict.AccountID,
+		EmailID:    conflict.EmailID,
+		Fields:     conflict.FieldList(),
+		Policy:     conflict.Policy,
+		Status:     conflict.Status,
+		Resolution: conflict.Resolution,
+	}, conflict.UserID)
+	if err := publisher.PublishToUser(ctx, conflict.UserID, event); err != nil {
+		log.Printf("Failed to publish sync conflict event: %v", err)
+	}
+}
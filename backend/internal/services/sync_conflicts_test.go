@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+	"firemail/internal/providers"
+	"firemail/internal/sse"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncConflictPolicies(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+	require.NoError(t, env.db.AutoMigrate(&models.EmailEvent{}, &models.SyncConflict{}, &models.UserSetting{}))
+
+	lastSync := time.Now().Add(-time.Hour)
+	require.NoError(t, env.db.Model(env.account).Update("last_sync_at", &lastSync).Error)
+
+	archive := &models.Folder{
+		AccountID:    env.account.ID,
+		Name:         "Archive",
+		Type:         models.FolderTypeCustom,
+		Path:         "Archive",
+		IsSelectable: true,
+	}
+	require.NoError(t, env.db.Create(archive).Error)
+
+	newEmail := func(messageID string, uid uint32) *models.Email {
+		email := &models.Email{
+			AccountID: env.account.ID,
+			FolderID:  &env.inbox.ID,
+			MessageID: messageID,
+			UID:       uid,
+			Subject:   "Conflict",
+			Date:      time.Now(),
+		}
+		require.NoError(t, env.db.Create(email).Error)
+		return email
+	}
+	syncService := NewSyncService(env.db, nil, env.publisher, NewDeduplicatorFactory(env.db), nil, nil)
+	syncToArchive := func(messageID string, uid uint32) *models.SyncConflict {
+		_, conflict, err := syncService.storeSyncedEmail(ctx, &providers.EmailMessage{
+			MessageID: messageID,
+			UID:       uid,
+			Subject:   "Conflict",
+			Date:      time.Now(),
+		}, env.account.ID, archive.ID, env.user.ID)
+		require.NoError(t, err)
+		return conflict
+	}
+
+	// 默认以服务器为准：本地移到项目文件夹，其他设备移到归档，同步后位于归档
+	serverWins := newEmail("<server-wins@example.com>", 5)
+	require.NoError(t, env.service.MoveEmail(ctx, env.user.ID, serverWins.ID, env.work.ID))
+	conflict := syncToArchive("<server-wins@example.com>", 21)
+	require.NotNil(t, conflict)
+	require.Equal(t, models.SyncConflictPolicyServerWins, conflict.Policy)
+	require.Equal(t, models.SyncConflictResolutionServer, conflict.Resolution)
+	require.NoError(t, env.db.First(serverWins, serverWins.ID).Error)
+	require.Equal(t, archive.ID, *serverWins.FolderID)
+
+	// 只在其他设备上修改的邮件不是冲突
+	remoteOnly := newEmail("<remote-only@example.com>", 6)
+	require.Nil(t, syncToArchive("<remote-only@example.com>", 22))
+	require.NoError(t, env.db.First(remoteOnly, remoteOnly.ID).Error)
+	require.Equal(t, archive.ID, *remoteOnly.FolderID)
+
+	// 等待用户选择：保留本地状态并推送事件
+	policy := models.SyncConflictPolicyAsk
+	_, err := NewSettingsService(env.db).UpdateSettings(ctx, env.user.ID, &UpdateUserSettingsRequest{SyncConflictPolicy: &policy})
+	require.NoError(t, err)
+	ask := newEmail("<ask@example.com>", 7)
+	require.NoError(t, env.service.MoveEmail(ctx, env.user.ID, ask.ID, env.work.ID))
+	env.publisher.events = nil
+	conflict = syncToArchive("<ask@example.com>", 23)
+	require.NotNil(t, conflict)
+	require.Equal(t, models.SyncConflictStatusPending, conflict.Status)
+	require.NoError(t, env.db.First(ask, ask.ID).Error)
+	require.Equal(t, env.work.ID, *ask.FolderID)
+	require.Len(t, env.publisher.events, 1)
+	require.Equal(t, sse.EventSyncConflict, env.publisher.events[0].Type)
+
+	pending, err := env.service.ListSyncConflicts(ctx, env.user.ID, models.SyncConflictStatusPending)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, []string{models.SyncConflictFieldFolder}, pending[0].FieldNames)
+	require.Equal(t, archive.ID, *pending[0].Remote.FolderID)
+
+	// 选择本地状态：在服务器上把邮件移回项目文件夹
+	resolved, err := env.service.ResolveSyncConflict(ctx, env.user.ID, pending[0].ID, models.SyncConflictResolutionLocal)
+	require.NoError(t, err)
+	require.Equal(t, models.SyncConflictStatusResolved, resolved.Status)
+	lastMove := env.provider.imap.moveCalls[len(env.provider.imap.moveCalls)-1]
+	require.Equal(t, []uint32{23}, lastMove.UIDs)
+	require.Equal(t, "Projects", lastMove.TargetFolder)
+
+	_, err = env.service.ResolveSyncConflict(ctx, env.user.ID, pending[0].ID, models.SyncConflictResolutionServer)
+	require.ErrorIs(t, err, ErrSyncConflictResolved)
+}
+
+func TestSyncedDuplicateBumpsVersion(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+	require.NoError(t, env.db.AutoMigrate(&models.EmailEvent{}, &models.SyncConflict{}, &models.UserSetting{}))
+
+	email := env.createEmail(t, env.inbox, 1, "moved", false, false)
+	require.NoError(t, env.db.Model(email).Update("is_pinned", true).Error)
+	require.NoError(t, env.db.First(email, email.ID).Error)
+	version := email.Version
+
+	// 其他设备把邮件移到项目文件夹，同步后版本号递增，本地置顶状态保留
+	syncService := NewSyncService(env.db, nil, env.publisher, NewDeduplicatorFactory(env.db), nil, nil)
+	_, _, err := syncService.storeSyncedEmail(ctx, &providers.EmailMessage{
+		MessageID: email.MessageID,
+		UID:       31,
+		Subject:   email.Subject,
+		Date:      email.Date,
+	}, env.account.ID, env.work.ID, env.user.ID)
+	require.NoError(t, err)
+
+	var stored models.Email
+	require.NoError(t, env.db.First(&stored, email.ID).Error)
+	require.Equal(t, env.work.ID, *stored.FolderID)
+	require.True(t, stored.IsPinned)
+	require.Greater(t, stored.Version, version)
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"firemail/internal/models"
+	"firemail/internal/providers"
+)
+
+// SetFolderWorkers 设置文件夹并行同步的并发数：perAccount 为每个账户的工作协程数，
+// total 为所有账户合计的上限，0表示不限制
+func (s *SyncService) SetFolderWorkers(perAccount, total int) {
+	if perAccount < 1 {
+		perAccount = 1
+	}
+	s.folderWorkers = perAccount
+	s.folderSlots = nil
+	if total > 0 {
+		s.folderSlots = make(chan struct{}, total)
+	}
+}
+
+// folderWorkerCount 账户本次同步使用的工作协程数，不超过提供商允许的并发连接数和文件夹数
+func (s *SyncService) folderWorkerCount(provider providers.EmailProvider, folderCount int) int {
+	workers := s.folderWorkers
+	if limit := providers.GetGlobalRateLimiter().ConnectionLimit(provider.GetName()); limit > 0 && workers > limit {
+		workers = limit
+	}
+	if quirks := providerQuirks(provider.GetName()); quirks != nil && quirks.MaxFolderWorkers > 0 && workers > quirks.MaxFolderWorkers {
+		workers = quirks.MaxFolderWorkers
+	}
+	if workers > folderCount {
+		workers = folderCount
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	return workers
+}
+
+// syncFolders 用工作池并行同步账户的文件夹。第一个工作协程复用已建立的连接，
+// 其余各自建立独立的IMAP连接，连接失败时由其他工作协程继续处理剩余文件夹
+func (s *SyncService) syncFolders(ctx context.Context, provider providers.EmailProvider, account *models.EmailAccount, folders []models.Folder) []error {
+	jobs := make(chan *models.Folder)
+	go func() {
+		defer close(jobs)
+		for i := range folders {
+			select {
+			case jobs <- &folders[i]:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var (
+		wg         sync.WaitGroup
+		errorsMu   sync.Mutex
+		syncErrors []error
+	)
+	work := func(provider providers.EmailProvider, account *models.EmailAccount) {
+		for folder := range jobs {
+			if err := s.syncFolderWithSlot(ctx, provider, account, folder); err != nil {
+				log.Printf("Failed to sync folder %s: %v", folder.Name, err)
+				errorsMu.Lock()
+				syncErrors = append(syncErrors, err)
+				errorsMu.Unlock()
+			}
+		}
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		work(provider, account)
+	}()
+
+	for i := 1; i < s.folderWorkerCount(provider, len(folders)); i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			// 连接时可能刷新令牌等字段，每个工作协程使用账户的副本
+			workerAccount := *account
+			workerProvider, err := s.connectFolderWorker(ctx, &workerAccount)
+			if err != nil {
+				log.Printf("Folder sync worker %d for account %s not started: %v", worker, account.Email, err)
+				return
+			}
+			defer workerProvider.Disconnect()
+			work(workerProvider, &workerAccount)
+		}(i)
+	}
+
+	wg.Wait()
+	return syncErrors
+}
+
+// connectFolderWorker 为工作协程建立独立的连接
+func (s *SyncService) connectFolderWorker(ctx context.Context, account *models.EmailAccount) (providers.EmailProvider, error) {
+	provider, err := s.providerFactory.CreateProviderForAccount(account)
+	if err != nil {
+		return nil, err
+	}
+	if err := provider.Connect(ctx, account); err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+// syncFolderWithSlot 占用一个全局名额后同步文件夹
+func (s *SyncService) syncFolderWithSlot(ctx context.Context, provider providers.EmailProvider, account *models.EmailAccount, folder *models.Folder) error {
+	if s.folderSlots != nil {
+		select {
+		case s.folderSlots <- struct{}{}:
+			defer func() { <-s.folderSlots }()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return s.syncFolder(ctx, provider, account, folder)
+}
+
+// getAccountWriteLock 获取账户的写入锁。同一账户的文件夹并行同步时串行保存邮件，
+// 避免去重检查与插入之间的竞争，也减少SQLite写锁冲突
+func (s *SyncService) getAccountWriteLock(accountID uint) *sync.Mutex {
+	lock, _ := s.accountWriteLocks.LoadOrStore(accountID, &sync.Mutex{})
+	return lock.(*sync.Mutex)
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"firemail/internal/models"
+	"firemail/internal/providers"
+
+	"github.com/stretchr/testify/require"
+)
+
+// workerProviderFactory 为每个工作协程创建独立的提供商，记录建立的连接
+type workerProviderFactory struct {
+	mutex      sync.Mutex
+	created    []*fakeEmailProvider
+	connectErr error
+}
+
+func (f *workerProviderFactory) CreateProviderForAccount(*models.EmailAccount) (providers.EmailProvider, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	provider := &fakeEmailProvider{imap: &fakeIMAPClient{}, connectErr: f.connectErr}
+	f.created = append(f.created, provider)
+	return provider, nil
+}
+
+func TestSyncFoldersWorkerPool(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+
+	var folders []models.Folder
+	for i := 0; i < 6; i++ {
+		folders = append(folders, models.Folder{AccountID: env.account.ID, Name: fmt.Sprintf("Folder %d", i)})
+	}
+
+	// 每个账户最多3个工作协程：复用主连接，另外建立2个独立连接，结束后断开
+	factory := &workerProviderFactory{}
+	syncService := NewSyncService(env.db, factory, nil, NewDeduplicatorFactory(env.db), nil, nil)
+	syncService.SetFolderWorkers(3, 2)
+	require.Empty(t, syncService.syncFolders(ctx, env.provider, env.account, folders))
+	require.Len(t, factory.created, 2)
+	for _, provider := range factory.created {
+		require.Equal(t, 1, provider.connectCalls)
+		require.Equal(t, 1, provider.disconnects)
+	}
+	require.Equal(t, 0, env.provider.disconnects)
+
+	// 文件夹数少于并发数时只启动需要的工作协程
+	factory = &workerProviderFactory{}
+	syncService.providerFactory = factory
+	require.Empty(t, syncService.syncFolders(ctx, env.provider, env.account, folders[:2]))
+	require.Len(t, factory.created, 1)
+
+	// 额外连接失败时由主连接同步全部文件夹
+	factory = &workerProviderFactory{connectErr: errors.New("too many connections")}
+	syncService.providerFactory = factory
+	require.Empty(t, syncService.syncFolders(ctx, env.provider, env.account, folders))
+	require.Len(t, factory.created, 2)
+}
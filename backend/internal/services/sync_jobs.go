@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"firemail/internal/models"
+)
+
+// folderSyncJobTimeout 单个文件夹同步任务的超时时间
+const folderSyncJobTimeout = 10 * time.Minute
+
+// syncAccountPayload 账户同步任务的参数
+type syncAccountPayload struct {
+	AccountID uint `json:"account_id"`
+}
+
+// syncFolderPayload 文件夹同步任务的参数
+type syncFolderPayload struct {
+	UserID   uint `json:"user_id"`
+	FolderID uint `json:"folder_id"`
+}
+
+// EnqueueAccountSync 入队账户邮件同步任务，同一账户已有未结束的同步任务时返回该任务
+func EnqueueAccountSync(ctx context.Context, jobs JobEnqueuer, userID, accountID uint) (*models.Job, error) {
+	return jobs.Enqueue(ctx, JobTypeSyncAccount, syncAccountPayload{AccountID: accountID}, &JobOptions{
+		UserID:    userID,
+		UniqueKey: fmt.Sprintf("sync_account:%d", accountID),
+	})
+}
+
+// EnqueueFolderSync 入队文件夹同步任务，同一文件夹已有未结束的同步任务时返回该任务
+func EnqueueFolderSync(ctx context.Context, jobs JobEnqueuer, userID, folderID uint) (*models.Job, error) {
+	return jobs.Enqueue(ctx, JobTypeSyncFolder, syncFolderPayload{UserID: userID, FolderID: folderID}, &JobOptions{
+		UserID:    userID,
+		UniqueKey: fmt.Sprintf("sync_folder:%d", folderID),
+	})
+}
+
+// RegisterJobs 注册账户同步任务的处理函数
+func (s *SyncService) RegisterJobs(queue JobQueueService) {
+	queue.Register(JobTypeSyncAccount, s.runAccountSyncJob)
+}
+
+// runAccountSyncJob 同步账户邮件，账户已暂停同步时跳过
+func (s *SyncService) runAccountSyncJob(ctx context.Context, job *models.Job) error {
+	var payload syncAccountPayload
+	if err := DecodeJobPayload(job, &payload); err != nil {
+		return err
+	}
+	err := s.SyncEmails(ctx, payload.AccountID)
+	if errors.Is(err, ErrAccountSyncPaused) {
+		log.Printf("Skipping sync job %d: account %d sync is paused", job.ID, payload.AccountID)
+		return nil
+	}
+	// 为新同步的邮件建立语义搜索索引（入队失败时由周期任务补建）
+	if err == nil && s.embeddingJobs != nil {
+		if enqueueErr := EnqueueEmbeddingIndex(ctx, s.embeddingJobs); enqueueErr != nil {
+			log.Printf("Failed to enqueue embedding index job after syncing account %d: %v", payload.AccountID, enqueueErr)
+		}
+	}
+	return err
+}
+
+// SetJobQueue 新账户的文件夹同步和单个文件夹同步通过任务队列执行，并注册对应的处理函数
+func (s *EmailServiceImpl) SetJobQueue(queue JobQueueService) {
+	s.jobs = queue
+	queue.Register(JobTypeSyncAccountFolders, s.runAccountFoldersSyncJob)
+	queue.Register(JobTypeSyncFolder, s.runFolderSyncJob)
+}
+
+// enqueueAccountFoldersSync 入队新账户的文件夹同步任务
+func (s *EmailServiceImpl) enqueueAccountFoldersSync(ctx context.Context, account *models.EmailAccount) error {
+	_, err := s.jobs.Enqueue(ctx, JobTypeSyncAccountFolders, syncAccountPayload{AccountID: account.ID}, &JobOptions{
+		UserID:    account.UserID,
+		UniqueKey: fmt.Sprintf("sync_account_folders:%d", account.ID),
+	})
+	return err
+}
+
+// runAccountFoldersSyncJob 同步账户的文件夹列表，最后一次尝试仍失败时把账户标记为错误状态
+func (s *EmailServiceImpl) runAccountFoldersSyncJob(ctx context.Context, job *models.Job) error {
+	var payload syncAccountPayload
+	if err := DecodeJobPayload(job, &payload); err != nil {
+		return err
+	}
+	err := s.syncFoldersForAccount(ctx, payload.AccountID)
+	if err != nil && ctx.Err() == nil && job.Attempts >= job.MaxAttempts {
+		s.db.Model(&models.EmailAccount{}).Where("id = ?", payload.AccountID).Updates(map[string]interface{}{
+			"sync_status":   "error",
+			"error_message": fmt.Sprintf("Failed to sync folders: %v", err),
+		})
+	}
+	return err
+}
+
+// runFolderSyncJob 同步指定文件夹
+func (s *EmailServiceImpl) runFolderSyncJob(ctx context.Context, job *models.Job) error {
+	var payload syncFolderPayload
+	if err := DecodeJobPayload(job, &payload); err != nil {
+		return err
+	}
+	syncCtx, cancel := context.WithTimeout(ctx, folderSyncJobTimeout)
+	defer cancel()
+	return s.SyncSpecificFolder(syncCtx, payload.UserID, payload.FolderID)
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"firemail/internal/models"
+)
+
+// ErrAccountSyncPaused 账户同步已暂停
+var ErrAccountSyncPaused = errors.New("account sync is paused")
+
+// accountSyncTracker 记录账户进行中的同步，暂停时取消并等待其退出
+type accountSyncTracker struct {
+	mu      sync.Mutex
+	nextID  int
+	cancels map[int]context.CancelFunc
+	running sync.WaitGroup
+	pausing bool // 正在暂停或已暂停，拒绝新的同步
+}
+
+// accountTracker 获取账户的同步记录
+func (s *SyncService) accountTracker(accountID uint) *accountSyncTracker {
+	tracker, _ := s.accountSyncs.LoadOrStore(accountID, &accountSyncTracker{cancels: make(map[int]context.CancelFunc)})
+	return tracker.(*accountSyncTracker)
+}
+
+// beginAccountSync 登记账户的一个进行中同步，账户正在暂停时拒绝。
+// 返回的上下文在服务关闭或账户暂停时取消，结束后需调用done
+func (s *SyncService) beginAccountSync(ctx context.Context, accountID uint) (context.Context, func(), error) {
+	syncCtx, finish, err := s.beginSync(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tracker := s.accountTracker(accountID)
+	tracker.mu.Lock()
+	if tracker.pausing {
+		tracker.mu.Unlock()
+		finish()
+		return nil, nil, ErrAccountSyncPaused
+	}
+	syncCtx, cancel := context.WithCancel(syncCtx)
+	id := tracker.nextID
+	tracker.nextID++
+	tracker.cancels[id] = cancel
+	tracker.running.Add(1)
+	tracker.mu.Unlock()
+
+	return syncCtx, func() {
+		tracker.mu.Lock()
+		delete(tracker.cancels, id)
+		tracker.mu.Unlock()
+		cancel()
+		tracker.running.Done()
+		finish()
+	}, nil
+}
+
+// isAccountPausing 账户是否正在暂停或已暂停
+func (s *SyncService) isAccountPausing(accountID uint) bool {
+	tracker := s.accountTracker(accountID)
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	return tracker.pausing
+}
+
+// PauseAccountSync 暂停账户同步：拒绝新的同步，取消进行中的同步并等待其退出，然后记录暂停状态
+func (s *SyncService) PauseAccountSync(ctx context.Context, accountID uint) (*models.EmailAccount, error) {
+	var account models.EmailAccount
+	if err := s.db.WithContext(ctx).First(&account, accountID).Error; err != nil {
+		return nil, fmt.Errorf("account not found: %w", err)
+	}
+
+	tracker := s.accountTracker(accountID)
+	tracker.mu.Lock()
+	tracker.pausing = true
+	for _, cancel := range tracker.cancels {
+		cancel()
+	}
+	tracker.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		tracker.running.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timed out waiting for in-flight sync: %w", ctx.Err())
+	}
+
+	// 进行中的同步已退出，再写入暂停状态，避免被同步结束时保存的账户状态覆盖
+	now := time.Now()
+	if err := s.db.WithContext(ctx).Model(&account).Updates(map[string]interface{}{
+		"sync_paused":    true,
+		"sync_paused_at": &now,
+		"sync_status":    "paused",
+	}).Error; err != nil {
+		tracker.mu.Lock()
+		tracker.pausing = account.SyncPaused
+		tracker.mu.Unlock()
+		return nil, fmt.Errorf("failed to pause account sync: %w", err)
+	}
+	recordAccountChange(ctx, s.changeLog, account.UserID, account.ID, models.ChangeActionUpdated)
+
+	return s.reloadAccount(ctx, accountID)
+}
+
+// ResumeAccountSync 恢复账户同步，账户回到待同步状态
+func (s *SyncService) ResumeAccountSync(ctx context.Context, accountID uint) (*models.EmailAccount, error) {
+	var account models.EmailAccount
+	if err := s.db.WithContext(ctx).First(&account, accountID).Error; err != nil {
+		return nil, fmt.Errorf("account not found: %w", err)
+	}
+
+	if err := s.db.WithContext(ctx).Model(&account).Updates(map[string]interface{}{
+		"sync_paused":    false,
+		"sync_paused_at": nil,
+		"sync_status":    "pending",
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to resume account sync: %w", err)
+	}
+
+	tracker := s.accountTracker(accountID)
+	tracker.mu.Lock()
+	tracker.pausing = false
+	tracker.mu.Unlock()
+	recordAccountChange(ctx, s.changeLog, account.UserID, account.ID, models.ChangeActionUpdated)
+
+	return s.reloadAccount(ctx, accountID)
+}
+
+// reloadAccount 重新读取账户
+func (s *SyncService) reloadAccount(ctx context.Context, accountID uint) (*models.EmailAccount, error) {
+	var account models.EmailAccount
+	if err := s.db.WithContext(ctx).First(&account, accountID).Error; err != nil {
+		return nil, fmt.Errorf("account not found: %w", err)
+	}
+	return &account, nil
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPauseAccountSyncDrainsAndRejectsSync(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+
+	provider := &blockingSyncProvider{fakeEmailProvider: env.provider, started: make(chan struct{})}
+	syncService := NewSyncService(env.db, &blockingSyncProviderFactory{provider: provider}, nil, nil, nil, nil)
+
+	result := make(chan error, 1)
+	go func() {
+		result <- syncService.SyncEmails(context.Background(), env.account.ID)
+	}()
+	<-provider.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	account, err := syncService.PauseAccountSync(ctx, env.account.ID)
+	require.NoError(t, err)
+	require.True(t, account.SyncPaused)
+	require.NotNil(t, account.SyncPausedAt)
+	require.Equal(t, "paused", account.SyncStatus)
+
+	// 进行中的同步已被取消，暂停状态不会被同步结束时的保存覆盖
+	require.Error(t, <-result)
+	require.NoError(t, env.db.First(account, env.account.ID).Error)
+	require.True(t, account.SyncPaused)
+	require.Equal(t, "paused", account.SyncStatus)
+
+	require.ErrorIs(t, syncService.SyncEmails(context.Background(), env.account.ID), ErrAccountSyncPaused)
+	require.ErrorIs(t, syncService.SyncFolder(context.Background(), env.account.ID, "INBOX"), ErrAccountSyncPaused)
+	require.NoError(t, syncService.SyncEmailsForUser(context.Background(), env.user.ID))
+
+	// 服务重启后仍按账户记录的暂停状态拒绝同步
+	restarted := NewSyncService(env.db, &blockingSyncProviderFactory{provider: provider}, nil, nil, nil, nil)
+	require.ErrorIs(t, restarted.SyncEmails(context.Background(), env.account.ID), ErrAccountSyncPaused)
+
+	account, err = syncService.ResumeAccountSync(context.Background(), env.account.ID)
+	require.NoError(t, err)
+	require.False(t, account.SyncPaused)
+	require.Nil(t, account.SyncPausedAt)
+	require.Equal(t, "pending", account.SyncStatus)
+
+	// 恢复后可以再次同步
+	provider.started = make(chan struct{})
+	go func() {
+		result <- syncService.SyncEmails(context.Background(), env.account.ID)
+	}()
+	<-provider.started
+	require.NoError(t, syncService.Shutdown(ctx))
+	require.Error(t, <-result)
+}
@@ -5,13 +5,13 @@ import (
 	"context"
 	"errors"
 	"firemail/internal/cache"
+	"firemail/internal/database/writequeue"
 	"firemail/internal/encoding/transfer"
 	"firemail/internal/models"
 	"firemail/internal/providers"
 	"firemail/internal/sse"
 	"fmt"
 	"log"
-	"strings"
 	"sync"
 	"time"
 
@@ -27,11 +27,33 @@ type SyncService struct {
 	retryManager        *providers.RetryManager
 	attachmentStorage   AttachmentStorage   // 添加附件存储
 	cacheManager        *cache.CacheManager // 添加缓存管理器
+	embeddingJobs       JobEnqueuer         // 同步完成后入队语义搜索索引任务，为空时只依赖周期任务
+	changeLog           ChangeLogService    // 增量同步变更日志
+	forwarder           EmailForwarder      // 新邮件自动转发
 	accountLocks        sync.Map
+	accountSyncs        sync.Map // 各账户进行中的同步，用于暂停时取消
+	writeBatchSize      int      // 每个写入事务的邮件数，0表示使用默认值
+	accountWriteLocks   sync.Map // 各账户的邮件写入锁
+
+	// 文件夹并行同步：每个账户的工作协程数，以及所有账户共享的名额（nil表示不限制）
+	folderWorkers int
+	folderSlots   chan struct{}
+
+	// 服务关闭时取消进行中的同步并等待其退出
+	shutdownCtx    context.Context
+	shutdownCancel context.CancelFunc
+	running        sync.WaitGroup
+	lifecycleMutex sync.Mutex
 }
 
+// ErrSyncServiceShuttingDown 服务关闭期间拒绝新的同步
+var ErrSyncServiceShuttingDown = errors.New("sync service is shutting down")
+
+const syncInterruptedMessage = "sync interrupted by server shutdown"
+
 // NewSyncService 创建同步服务实例
 func NewSyncService(db *gorm.DB, providerFactory providers.ProviderFactoryInterface, eventPublisher sse.EventPublisher, deduplicatorFactory DeduplicatorFactory, attachmentStorage AttachmentStorage, cacheManager *cache.CacheManager) *SyncService {
+	shutdownCtx, shutdownCancel := context.WithCancel(context.Background())
 	return &SyncService{
 		db:                  db,
 		providerFactory:     providerFactory,
@@ -40,6 +62,58 @@ func NewSyncService(db *gorm.DB, providerFactory providers.ProviderFactoryInterf
 		retryManager:        providers.GetGlobalRetryManager(),
 		attachmentStorage:   attachmentStorage,
 		cacheManager:        cacheManager,
+		folderWorkers:       1,
+		shutdownCtx:         shutdownCtx,
+		shutdownCancel:      shutdownCancel,
+	}
+}
+
+// SetChangeLog 设置变更日志依赖
+func (s *SyncService) SetChangeLog(changeLog ChangeLogService) {
+	s.changeLog = changeLog
+}
+
+// SetForwarder 设置新邮件自动转发依赖
+func (s *SyncService) SetForwarder(forwarder EmailForwarder) {
+	s.forwarder = forwarder
+}
+
+// beginSync 登记一个进行中的同步，返回的上下文在服务关闭时取消，结束后需调用done
+func (s *SyncService) beginSync(ctx context.Context) (context.Context, func(), error) {
+	s.lifecycleMutex.Lock()
+	defer s.lifecycleMutex.Unlock()
+
+	if s.shutdownCtx.Err() != nil {
+		return nil, nil, ErrSyncServiceShuttingDown
+	}
+	s.running.Add(1)
+
+	syncCtx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(s.shutdownCtx, cancel)
+	return syncCtx, func() {
+		stop()
+		cancel()
+		s.running.Done()
+	}, nil
+}
+
+// Shutdown 取消进行中的同步并等待其退出，未提交的批次随事务回滚，下次同步时重新拉取
+func (s *SyncService) Shutdown(ctx context.Context) error {
+	s.lifecycleMutex.Lock()
+	s.shutdownCancel()
+	s.lifecycleMutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for sync workers: %w", ctx.Err())
 	}
 }
 
@@ -50,7 +124,13 @@ func (s *SyncService) SyncEmails(ctx context.Context, accountID uint) error {
 	if ctx != nil && ctx.Err() == nil {
 		baseCtx = ctx
 	}
-	syncCtx, cancel := context.WithTimeout(baseCtx, 10*time.Minute)
+	trackedCtx, done, err := s.beginAccountSync(baseCtx, accountID)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	syncCtx, cancel := context.WithTimeout(trackedCtx, 10*time.Minute)
 	defer cancel()
 
 	lock := s.getAccountLock(accountID)
@@ -66,10 +146,19 @@ func (s *SyncService) SyncEmails(ctx context.Context, accountID uint) error {
 	if !account.IsActive {
 		return fmt.Errorf("account is not active")
 	}
+	if account.SyncPaused {
+		return ErrAccountSyncPaused
+	}
+
+	// 入站虚拟账户的邮件由入站接口写入，没有服务器可同步
+	if account.Provider == providers.IngestProviderName {
+		return nil
+	}
 
 	// 更新同步状态
 	account.SyncStatus = "syncing"
 	s.db.WithContext(syncCtx).Save(&account)
+	recordAccountChange(syncCtx, s.changeLog, account.UserID, account.ID, models.ChangeActionUpdated)
 
 	// 发布同步开始事件
 	if s.eventPublisher != nil {
@@ -95,7 +184,7 @@ func (s *SyncService) SyncEmails(ctx context.Context, accountID uint) error {
 
 	// 获取账户的文件夹
 	var folders []models.Folder
-	if err := s.db.WithContext(syncCtx).Where("account_id = ? AND is_selectable = ?", accountID, true).
+	if err := s.db.WithContext(syncCtx).Where("account_id = ? AND is_selectable = ? AND label_mode <> ?", accountID, true, models.FolderLabelModeSkip).
 		Find(&folders).Error; err != nil {
 		s.updateSyncError(&account, fmt.Errorf("failed to get folders: %w", err))
 		return err
@@ -110,7 +199,7 @@ func (s *SyncService) SyncEmails(ctx context.Context, accountID uint) error {
 		}
 
 		// 重新查询文件夹
-		if err := s.db.WithContext(syncCtx).Where("account_id = ? AND is_selectable = ?", accountID, true).
+		if err := s.db.WithContext(syncCtx).Where("account_id = ? AND is_selectable = ? AND label_mode <> ?", accountID, true, models.FolderLabelModeSkip).
 			Find(&folders).Error; err != nil {
 			s.updateSyncError(&account, fmt.Errorf("failed to get folders after sync: %w", err))
 			return err
@@ -118,13 +207,20 @@ func (s *SyncService) SyncEmails(ctx context.Context, accountID uint) error {
 		fmt.Printf("📁 [SYNC] Folder sync completed, found %d selectable folders\n", len(folders))
 	}
 
-	// 同步每个文件夹
-	var syncErrors []error
-	for _, folder := range folders {
-		if err := s.syncFolder(syncCtx, provider, &account, &folder); err != nil {
-			log.Printf("Failed to sync folder %s: %v", folder.Name, err)
-			syncErrors = append(syncErrors, err)
-		}
+	// 提供商优先同步的文件夹（如验证码常见的文件夹）先进入工作队列
+	sortFoldersByPriority(folders, providerQuirks(provider.GetName()))
+
+	// 并行同步各文件夹
+	syncErrors := s.syncFolders(syncCtx, provider, &account, folders)
+
+	// 同步上下文已随服务关闭取消，不能再用它保存状态
+	if s.shutdownCtx.Err() != nil {
+		s.updateSyncError(&account, ErrSyncServiceShuttingDown)
+		return ErrSyncServiceShuttingDown
+	}
+	// 账户暂停时同步被取消，由暂停操作记录账户状态
+	if s.isAccountPausing(accountID) {
+		return ErrAccountSyncPaused
 	}
 
 	// 统计账户的总邮件数量（避免重复计算）
@@ -145,6 +241,7 @@ func (s *SyncService) SyncEmails(ctx context.Context, accountID uint) error {
 		account.SyncStatus = "error"
 		account.ErrorMessage = fmt.Sprintf("sync completed with %d errors", len(syncErrors))
 		s.db.WithContext(syncCtx).Save(&account)
+		recordAccountChange(syncCtx, s.changeLog, account.UserID, account.ID, models.ChangeActionUpdated)
 
 		// 发布同步错误事件
 		if s.eventPublisher != nil {
@@ -164,6 +261,7 @@ func (s *SyncService) SyncEmails(ctx context.Context, accountID uint) error {
 		account.SyncStatus = "success"
 		account.ErrorMessage = ""
 		s.db.WithContext(syncCtx).Save(&account)
+		recordAccountChange(syncCtx, s.changeLog, account.UserID, account.ID, models.ChangeActionUpdated)
 
 		// 发布同步完成事件
 		if s.eventPublisher != nil {
@@ -203,6 +301,7 @@ func (s *SyncService) syncFoldersForAccount(ctx context.Context, provider provid
 	}
 
 	fmt.Printf("📊 [FOLDER_SYNC] Found %d folders on server\n", len(folders))
+	applyFolderQuirks(folders, providerQuirks(provider.GetName()))
 
 	// 保存文件夹到数据库
 	for i, folderInfo := range folders {
@@ -240,7 +339,9 @@ func (s *SyncService) syncFoldersForAccount(ctx context.Context, provider provid
 		} else {
 			// 文件夹已存在，更新属性
 			existingFolder.DisplayName = folderInfo.DisplayName
-			existingFolder.Type = folderInfo.Type
+			if !existingFolder.TypeLocked {
+				existingFolder.Type = folderInfo.Type
+			}
 			existingFolder.IsSelectable = folderInfo.IsSelectable
 			existingFolder.IsSubscribed = folderInfo.IsSubscribed
 
@@ -264,7 +365,7 @@ func (s *SyncService) SyncAccount(ctx context.Context, accountID, userID uint) e
 // SyncEmailsForUser 同步用户的所有邮件账户
 func (s *SyncService) SyncEmailsForUser(ctx context.Context, userID uint) error {
 	var accounts []models.EmailAccount
-	if err := s.db.Where("user_id = ? AND is_active = ?", userID, true).
+	if err := s.db.Where("user_id = ? AND is_active = ? AND sync_paused = ?", userID, true, false).
 		Find(&accounts).Error; err != nil {
 		return fmt.Errorf("failed to get user accounts: %w", err)
 	}
@@ -286,10 +387,22 @@ func (s *SyncService) SyncEmailsForUser(ctx context.Context, userID uint) error
 
 // SyncFolder 同步指定文件夹
 func (s *SyncService) SyncFolder(ctx context.Context, accountID uint, folderName string) error {
+	ctx, done, err := s.beginAccountSync(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	defer done()
+
 	var account models.EmailAccount
 	if err := s.db.First(&account, accountID).Error; err != nil {
 		return fmt.Errorf("account not found: %w", err)
 	}
+	if account.SyncPaused {
+		return ErrAccountSyncPaused
+	}
+	if account.Provider == providers.IngestProviderName {
+		return nil
+	}
 
 	var folder models.Folder
 	if err := s.db.Where("account_id = ? AND (name = ? OR path = ?)",
@@ -341,6 +454,8 @@ func (s *SyncService) syncFolder(ctx context.Context, provider providers.EmailPr
 		log.Printf("Failed to perform incremental sync for folder %s: %v", folder.Name, err)
 		return fmt.Errorf("failed to perform incremental sync: %w", err)
 	}
+	// 附件溢出文件在保存后删除
+	defer providers.ReleaseEmailContents(newEmails)
 
 	fmt.Printf("📊 [FOLDER] Incremental sync completed for folder %s: %d new emails\n",
 		folder.Name, len(newEmails))
@@ -350,21 +465,53 @@ func (s *SyncService) syncFolder(ctx context.Context, provider providers.EmailPr
 	totalEmails := len(newEmails)
 	log.Printf("Retrieved %d new emails for folder %s", totalEmails, folder.Name)
 
-	for i, emailMsg := range newEmails {
-		if err := s.saveEmailToDatabase(ctx, emailMsg, account.ID, folder.ID, account.UserID); err != nil {
-			log.Printf("Failed to save email %s: %v", emailMsg.MessageID, err)
-		} else {
+	var mutedUIDs, trashedUIDs []uint32
+	movedUIDs := make(map[uint][]uint32)
+	var localWins []*models.SyncConflict
+	batchSize := s.writeBatchLimit()
+	for start := 0; start < totalEmails; start += batchSize {
+		end := start + batchSize
+		if end > totalEmails {
+			end = totalEmails
+		}
+		batch := newEmails[start:end]
+
+		// 保存前记录已读状态，静音会话中的新邮件保存时会补上\Seen标记
+		wasRead := make([]bool, len(batch))
+		for i, emailMsg := range batch {
+			wasRead[i] = s.isEmailRead(emailMsg.Flags)
+		}
+
+		for i, result := range s.saveSyncedEmailBatch(ctx, account, folder.ID, batch) {
+			emailMsg := batch[i]
+			if result.err != nil {
+				log.Printf("Failed to save email %s: %v", emailMsg.MessageID, result.err)
+				continue
+			}
 			newEmailCount++
+			if result.conflict != nil && result.conflict.Resolution == models.SyncConflictResolutionLocal {
+				localWins = append(localWins, result.conflict)
+			}
+			if !wasRead[i] && s.isEmailRead(emailMsg.Flags) {
+				mutedUIDs = append(mutedUIDs, emailMsg.UID)
+			}
+			// 屏蔽发件人的邮件
+			created := result.created
+			if created != nil && created.IsDeleted {
+				trashedUIDs = append(trashedUIDs, created.UID)
+			} else if created != nil && created.FolderID != nil && *created.FolderID != folder.ID {
+				movedUIDs[*created.FolderID] = append(movedUIDs[*created.FolderID], created.UID)
+			}
 		}
 
-		// 发布同步进度事件
-		if s.eventPublisher != nil && totalEmails > 0 {
-			progress := float64(i+1) / float64(totalEmails)
+		// 每批保存后发布同步进度事件
+		if s.eventPublisher != nil {
+			progress := float64(end) / float64(totalEmails)
 			syncProgressEvent := sse.NewSyncEvent(sse.EventSyncProgress, account.ID, account.Name, account.UserID)
 			if syncProgressEvent.Data != nil {
 				if syncData, ok := syncProgressEvent.Data.(*sse.SyncEventData); ok {
 					syncData.Progress = progress
-					syncData.ProcessedEmails = i + 1
+					syncData.ProcessedEmails = end
 					syncData.TotalEmails = totalEmails
 					syncData.FolderName = folder.Name
 				}
@@ -376,6 +523,12 @@ func (s *SyncService) syncFolder(ctx context.Context, provider providers.EmailPr
 	}
 
 	log.Printf("Synced %d new emails for folder %s", newEmailCount, folder.Name)
+	s.markMutedEmailsAsRead(ctx, imapClient, folder, mutedUIDs)
+	s.fileBlockedEmailsOnServer(ctx, imapClient, folder, trashedUIDs, movedUIDs)
+	s.pushLocalConflictStates(ctx, imapClient, localWins)
+	if newEmailCount > 0 {
+		recordFolderChanges(ctx, s.changeLog, account.UserID, account.ID, &folder.ID)
+	}
 
 	// 发布文件夹同步进度事件
 	if s.eventPublisher != nil && newEmailCount > 0 {
@@ -396,12 +549,65 @@ func (s *SyncService) syncFolder(ctx context.Context, provider providers.EmailPr
 	return nil
 }
 
+// markMutedEmailsAsRead 将静音会话中自动标记为已读的新邮件同步到服务器，失败时下次同步可能恢复为未读
+func (s *SyncService) markMutedEmailsAsRead(ctx context.Context, imapClient providers.IMAPClient, folder *models.Folder, uids []uint32) {
+	if len(uids) == 0 {
+		return
+	}
+	if _, err := imapClient.SelectFolder(ctx, folder.Path); err != nil {
+		log.Printf("Failed to select folder %s for muted emails: %v", folder.Name, err)
+		return
+	}
+	if err := imapClient.MarkAsRead(ctx, uids); err != nil {
+		log.Printf("Failed to mark muted emails as read in folder %s: %v", folder.Name, err)
+	}
+}
+
+// fileBlockedEmailsOnServer 在服务器上移走屏蔽发件人的新邮件，失败时只记录日志，本地状态保持不变
+func (s *SyncService) fileBlockedEmailsOnServer(ctx context.Context, imapClient providers.IMAPClient, folder *models.Folder, trashedUIDs []uint32, movedUIDs map[uint][]uint32) {
+	if len(trashedUIDs) == 0 && len(movedUIDs) == 0 {
+		return
+	}
+	if _, err := imapClient.SelectFolder(ctx, folder.Path); err != nil {
+		log.Printf("Failed to select folder %s for blocked emails: %v", folder.Name, err)
+		return
+	}
+
+	for targetID, uids := range movedUIDs {
+		var target models.Folder
+		if err := s.db.Select("id", "path").First(&target, targetID).Error; err != nil {
+			log.Printf("Failed to find target folder %d for blocked emails: %v", targetID, err)
+			continue
+		}
+		if err := imapClient.MoveEmails(ctx, uids, target.Path); err != nil {
+			log.Printf("Failed to move blocked emails from folder %s to %s: %v", folder.Name, target.Path, err)
+		}
+	}
+	if len(trashedUIDs) > 0 {
+		if err := imapClient.DeleteEmails(ctx, trashedUIDs); err != nil {
+			log.Printf("Failed to delete blocked emails in folder %s: %v", folder.Name, err)
+		}
+	}
+}
+
 // saveEmailToDatabase 保存邮件到数据库（使用去重功能）
 func (s *SyncService) saveEmailToDatabase(ctx context.Context, emailMsg *providers.EmailMessage, accountID, folderID, userID uint) error {
+	_, err := s.saveSyncedEmail(ctx, emailMsg, accountID, folderID, userID)
+	return err
+}
+
+// saveSyncedEmail 保存同步到的邮件，返回新创建的邮件；重复邮件更新或跳过时返回nil
+func (s *SyncService) saveSyncedEmail(ctx context.Context, emailMsg *providers.EmailMessage, accountID, folderID, userID uint) (*models.Email, error) {
+	created, _, err := s.storeSyncedEmail(ctx, emailMsg, accountID, folderID, userID)
+	return created, err
+}
+
+// storeSyncedEmail 保存同步到的邮件，同时返回更新已有邮件时发现的同步冲突
+func (s *SyncService) storeSyncedEmail(ctx context.Context, emailMsg *providers.EmailMessage, accountID, folderID, userID uint) (*models.Email, *models.SyncConflict, error) {
 	// 获取账户信息以确定提供商类型
 	var account models.EmailAccount
 	if err := s.db.First(&account, accountID).Error; err != nil {
-		return fmt.Errorf("failed to get account: %w", err)
+		return nil, nil, fmt.Errorf("failed to get account: %w", err)
 	}
 
 	// 创建对应的去重器
@@ -410,176 +616,256 @@ func (s *SyncService) saveEmailToDatabase(ctx context.Context, emailMsg *provide
 	// 检查邮件是否重复
 	duplicateResult, err := deduplicator.CheckDuplicate(ctx, emailMsg, accountID, folderID)
 	if err != nil {
-		return fmt.Errorf("failed to check duplicate: %w", err)
+		return nil, nil, fmt.Errorf("failed to check duplicate: %w", err)
+	}
+	if handled, conflict, err := s.handleSyncedDuplicate(ctx, &account, deduplicator, duplicateResult, emailMsg, folderID, userID); handled || err != nil {
+		return nil, conflict, err
 	}
 
-	// 处理重复邮件
-	if duplicateResult.IsDuplicate {
-		switch duplicateResult.Action {
-		case "skip":
-			log.Printf("Skipping duplicate email: %s (reason: %s)", emailMsg.MessageID, duplicateResult.Reason)
-			return nil
-		case "update", "create_label_reference":
-			if err := deduplicator.HandleDuplicate(ctx, duplicateResult.ExistingEmail, emailMsg, folderID); err != nil {
-				return fmt.Errorf("failed to handle duplicate: %w", err)
+	// 使用事务创建新邮件，确保数据一致性
+	var inserted *insertedSyncedEmail
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var insertErr error
+		inserted, insertErr = s.insertSyncedEmail(ctx, tx, &account, emailMsg, folderID, userID)
+		if insertErr != nil && isUniqueConstraintError(insertErr) {
+			log.Printf("Unique constraint violation for email %s, attempting to handle gracefully", emailMsg.MessageID)
+			// 重新检查重复并处理
+			duplicateResult, checkErr := deduplicator.CheckDuplicate(ctx, emailMsg, accountID, folderID)
+			if checkErr != nil {
+				return fmt.Errorf("failed to recheck duplicate after constraint violation: %w", checkErr)
+			}
+			if duplicateResult.IsDuplicate && duplicateResult.ExistingEmail != nil {
+				inserted = nil
+				return deduplicator.HandleDuplicate(ctx, duplicateResult.ExistingEmail, emailMsg, folderID)
 			}
-			log.Printf("Updated duplicate email: %s (action: %s)", emailMsg.MessageID, duplicateResult.Action)
-			return nil
-		default:
-			log.Printf("Unknown duplicate action: %s, creating new email", duplicateResult.Action)
 		}
+		return insertErr
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if inserted == nil {
+		return nil, nil, nil
 	}
 
-	// 使用事务创建新邮件，确保数据一致性
-	return s.db.Transaction(func(tx *gorm.DB) error {
-		// 创建新邮件
-		email := &models.Email{
-			AccountID:     accountID,
-			FolderID:      &folderID,
-			MessageID:     emailMsg.MessageID,
-			UID:           emailMsg.UID,
-			Subject:       emailMsg.Subject,
-			Date:          emailMsg.Date,
-			TextBody:      emailMsg.TextBody,
-			HTMLBody:      emailMsg.HTMLBody,
-			Size:          emailMsg.Size,
-			IsRead:        s.isEmailRead(emailMsg.Flags),
-			IsStarred:     s.isEmailStarred(emailMsg.Flags),
-			IsDraft:       s.isEmailDraft(emailMsg.Flags),
-			HasAttachment: len(emailMsg.Attachments) > 0,
-		}
+	s.afterSyncedEmailInserted(ctx, &account, inserted, folderID, userID)
+	return inserted.email, nil, nil
+}
 
-		// 设置发件人
-		if emailMsg.From != nil {
-			email.From = emailMsg.From.Address
-			if emailMsg.From.Name != "" {
-				email.From = fmt.Sprintf("%s <%s>", emailMsg.From.Name, emailMsg.From.Address)
+// handleSyncedDuplicate 处理重复的同步邮件（跳过或更新已有邮件），返回是否已处理；未处理时需要新建邮件
+func (s *SyncService) handleSyncedDuplicate(ctx context.Context, account *models.EmailAccount, deduplicator EmailDeduplicator, duplicateResult *DuplicateCheckResult, emailMsg *providers.EmailMessage, folderID, userID uint) (bool, *models.SyncConflict, error) {
+	if !duplicateResult.IsDuplicate {
+		return false, nil, nil
+	}
+
+	switch duplicateResult.Action {
+	case "skip":
+		log.Printf("Skipping duplicate email: %s (reason: %s)", emailMsg.MessageID, duplicateResult.Reason)
+		return true, nil, nil
+	case "update", "create_label_reference", "merge_label", "move_from_merged":
+		// 保存更新前的状态，用于记录其他设备上的操作
+		var before models.Email
+		var conflict *models.SyncConflict
+		if duplicateResult.ExistingEmail != nil {
+			before = *duplicateResult.ExistingEmail
+			// Gmail标签引用、合并标签是同一封邮件出现在多个文件夹中，不是冲突
+			if duplicateResult.Action == "update" {
+				conflict = s.detectSyncConflict(ctx, account, duplicateResult.ExistingEmail, emailMsg, folderID)
 			}
 		}
-
-		// 设置收件人
-		if err := email.SetToAddresses(convertEmailAddresses(emailMsg.To)); err != nil {
-			log.Printf("Failed to set To addresses: %v", err)
+		// 以本地为准或等待用户选择时保留本地状态
+		if conflict != nil && conflict.Resolution != models.SyncConflictResolutionServer {
+			log.Printf("Keeping local state of email %s (sync conflict %d)", emailMsg.MessageID, conflict.ID)
+			return true, conflict, nil
 		}
-
-		// 设置抄送
-		if err := email.SetCCAddresses(convertEmailAddresses(emailMsg.CC)); err != nil {
-			log.Printf("Failed to set CC addresses: %v", err)
+		if err := deduplicator.HandleDuplicate(ctx, duplicateResult.ExistingEmail, emailMsg, folderID); err != nil {
+			return true, nil, fmt.Errorf("failed to handle duplicate: %w", err)
 		}
-
-		// 设置密送
-		if err := email.SetBCCAddresses(convertEmailAddresses(emailMsg.BCC)); err != nil {
-			log.Printf("Failed to set BCC addresses: %v", err)
+		if conflict != nil {
+			if err := s.applyServerConflictState(ctx, duplicateResult.ExistingEmail, conflict); err != nil {
+				return true, nil, err
+			}
+		}
+		if duplicateResult.ExistingEmail != nil {
+			recordEmailChanges(ctx, s.changeLog, userID, account.ID, models.ChangeActionUpdated, duplicateResult.ExistingEmail.ID)
+			recordEmailEvents(ctx, s.db, emailStateEvents(userID, &before, duplicateResult.ExistingEmail, models.EmailEventSourceSync)...)
 		}
+		log.Printf("Updated duplicate email: %s (action: %s)", emailMsg.MessageID, duplicateResult.Action)
+		return true, conflict, nil
+	default:
+		log.Printf("Unknown duplicate action: %s, creating new email", duplicateResult.Action)
+	}
 
-		// 设置回复地址
-		if emailMsg.ReplyTo != nil {
-			email.ReplyTo = emailMsg.ReplyTo.Address
-			if emailMsg.ReplyTo.Name != "" {
-				email.ReplyTo = fmt.Sprintf("%s <%s>", emailMsg.ReplyTo.Name, emailMsg.ReplyTo.Address)
-			}
+	return false, nil, nil
+}
+
+// insertedSyncedEmail 事务中新建的同步邮件，事务提交后据此发送通知
+type insertedSyncedEmail struct {
+	email       *models.Email
+	threadMuted bool
+	blocked     bool
+}
+
+// insertSyncedEmail 在事务中创建同步到的新邮件及其附件、历史事件和收发统计
+func (s *SyncService) insertSyncedEmail(ctx context.Context, tx *gorm.DB, account *models.EmailAccount, emailMsg *providers.EmailMessage, folderID, userID uint) (*insertedSyncedEmail, error) {
+	// 创建新邮件
+	email := &models.Email{
+		AccountID:     account.ID,
+		FolderID:      &folderID,
+		MessageID:     emailMsg.MessageID,
+		UID:           emailMsg.UID,
+		Subject:       emailMsg.Subject,
+		Date:          emailMsg.Date,
+		TextBody:      emailMsg.TextBody,
+		HTMLBody:      emailMsg.HTMLBody,
+		Size:          emailMsg.Size,
+		IsRead:        s.isEmailRead(emailMsg.Flags),
+		IsStarred:     s.isEmailStarred(emailMsg.Flags),
+		IsDraft:       s.isEmailDraft(emailMsg.Flags),
+		HasAttachment: len(emailMsg.Attachments) > 0,
+
+		AttachmentCount: len(emailMsg.Attachments),
+	}
+	applyFlagKeywords(email, emailMsg.Flags)
+	applyReplyFlags(email, emailMsg.Flags)
+
+	// 设置发件人
+	if emailMsg.From != nil {
+		email.From = emailMsg.From.Address
+		if emailMsg.From.Name != "" {
+			email.From = fmt.Sprintf("%s <%s>", emailMsg.From.Name, emailMsg.From.Address)
 		}
+	}
 
-		// 保存邮件（在事务中）
-		if err := tx.Create(email).Error; err != nil {
-			// 检查是否是唯一约束冲突
-			if isUniqueConstraintError(err) {
-				log.Printf("Unique constraint violation for email %s, attempting to handle gracefully", emailMsg.MessageID)
-				// 重新检查重复并处理
-				deduplicator := s.deduplicatorFactory.CreateDeduplicator(account.Provider)
-				duplicateResult, checkErr := deduplicator.CheckDuplicate(ctx, emailMsg, accountID, folderID)
-				if checkErr != nil {
-					return fmt.Errorf("failed to recheck duplicate after constraint violation: %w", checkErr)
-				}
-				if duplicateResult.IsDuplicate && duplicateResult.ExistingEmail != nil {
-					return deduplicator.HandleDuplicate(ctx, duplicateResult.ExistingEmail, emailMsg, folderID)
-				}
-			}
-			return fmt.Errorf("failed to create email: %w", err)
+	// 设置收件人
+	if err := email.SetToAddresses(convertEmailAddresses(emailMsg.To)); err != nil {
+		log.Printf("Failed to set To addresses: %v", err)
+	}
+
+	// 设置抄送
+	if err := email.SetCCAddresses(convertEmailAddresses(emailMsg.CC)); err != nil {
+		log.Printf("Failed to set CC addresses: %v", err)
+	}
+
+	// 设置密送
+	if err := email.SetBCCAddresses(convertEmailAddresses(emailMsg.BCC)); err != nil {
+		log.Printf("Failed to set BCC addresses: %v", err)
+	}
+
+	// 设置回复地址
+	if emailMsg.ReplyTo != nil {
+		email.ReplyTo = emailMsg.ReplyTo.Address
+		if emailMsg.ReplyTo.Name != "" {
+			email.ReplyTo = fmt.Sprintf("%s <%s>", emailMsg.ReplyTo.Name, emailMsg.ReplyTo.Address)
 		}
+	}
 
-		// 保存附件（在事务中）
-		for _, attachmentInfo := range emailMsg.Attachments {
-			attachment := &models.Attachment{
-				EmailID:     &email.ID, // 使用指针类型
-				Filename:    attachmentInfo.Filename,
-				ContentType: attachmentInfo.ContentType,
-				Size:        attachmentInfo.Size,
-				ContentID:   attachmentInfo.ContentID,
-				Disposition: attachmentInfo.Disposition,
-				PartID:      attachmentInfo.PartID,
-				Encoding:    attachmentInfo.Encoding,
-			}
+	// 优先收件箱分类
+	classifyEmailImportance(tx, account, email)
+	email.IsVIP = isVIPSender(tx, userID, email.From)
 
-			if err := tx.Create(attachment).Error; err != nil {
-				log.Printf("Failed to save attachment %s: %v", attachmentInfo.Filename, err)
-				// 附件保存失败不应该回滚整个事务，只记录错误
-				continue
-			}
+	// 静音会话中的新邮件直接标记为已读，并补上\Seen标记以便同步回服务器
+	email.ThreadID = resolveEmailThreadID(tx, userID, emailMsg.MessageID, emailInReplyTo(emailMsg))
+	threadMuted := isThreadMuted(tx, userID, email.ThreadID)
+	if threadMuted && !email.IsRead {
+		email.IsRead = true
+		emailMsg.Flags = append(emailMsg.Flags, "\\Seen")
+	}
 
-			// 如果有附件内容，立即保存到本地存储
-			if len(attachmentInfo.Content) > 0 && s.attachmentStorage != nil {
-				if err := s.saveAttachmentContent(ctx, attachment, attachmentInfo.Content); err != nil {
-					log.Printf("Failed to save attachment content for %s: %v", attachmentInfo.Filename, err)
-					// 内容保存失败，更新数据库记录
-					tx.Model(attachment).Update("is_downloaded", false)
-				} else {
-					// 内容保存成功，标记为已下载
-					tx.Model(attachment).Updates(map[string]interface{}{
-						"is_downloaded": true,
-						"file_path":     s.attachmentStorage.GetStoragePath(attachment),
-					})
-					log.Printf("Successfully saved attachment content: %s (%d bytes)", attachmentInfo.Filename, len(attachmentInfo.Content))
-				}
-			}
+	// 屏蔽发件人的邮件移入垃圾邮件文件夹或回收站，服务器端在文件夹同步完成后处理
+	blocked := false
+	if rule := matchBlockedSender(tx, userID, email.From); rule != nil {
+		blocked = applyBlockedSender(tx, email, rule)
+	}
+
+	// 保存邮件（在事务中）
+	if err := tx.Create(email).Error; err != nil {
+		return nil, fmt.Errorf("failed to create email: %w", err)
+	}
+	clearReplyLaterOnSyncedReply(tx, userID, account.ID, email)
+	markAnsweredOnSyncedReply(tx, userID, account.ID, email, emailInReplyTo(emailMsg))
+
+	// 保存附件（在事务中）
+	for _, attachmentInfo := range emailMsg.Attachments {
+		attachment := &models.Attachment{
+			EmailID:     &email.ID, // 使用指针类型
+			Filename:    attachmentInfo.Filename,
+			ContentType: attachmentInfo.ContentType,
+			Size:        attachmentInfo.Size,
+			ContentID:   attachmentInfo.ContentID,
+			Disposition: attachmentInfo.Disposition,
+			PartID:      attachmentInfo.PartID,
+			Encoding:    attachmentInfo.Encoding,
 		}
+		applyAttachmentPolicy(attachment, attachmentInfoHead(attachmentInfo))
 
-		// 事务成功后发布新邮件事件
-		if s.eventPublisher != nil {
-			newEmailEvent := sse.NewNewEmailEvent(email, userID)
-			if err := s.eventPublisher.PublishToUser(ctx, userID, newEmailEvent); err != nil {
-				log.Printf("Failed to publish new email event: %v", err)
-				// 事件发布失败不应该回滚事务
-			}
+		if err := tx.Create(attachment).Error; err != nil {
+			log.Printf("Failed to save attachment %s: %v", attachmentInfo.Filename, err)
+			// 附件保存失败不应该回滚整个事务，只记录错误
+			continue
 		}
 
-		// 清除邮件列表缓存，确保前端能看到新邮件
-		if s.cacheManager != nil {
-			s.invalidateEmailListCache(userID)
+		// 如果有附件内容，立即保存到本地存储
+		if attachmentInfo.HasContent() && s.attachmentStorage != nil {
+			if err := s.saveAttachmentInfoContent(ctx, attachment, attachmentInfo); err != nil {
+				log.Printf("Failed to save attachment content for %s: %v", attachmentInfo.Filename, err)
+				// 内容保存失败，更新数据库记录
+				tx.Model(attachment).Update("is_downloaded", false)
+			} else {
+				// 内容保存成功，标记为已下载
+				tx.Model(attachment).Updates(map[string]interface{}{
+					"is_downloaded": true,
+					"file_path":     s.attachmentStorage.GetStoragePath(attachment),
+				})
+				log.Printf("Successfully saved attachment content: %s (%d bytes)", attachmentInfo.Filename, attachment.Size)
+			}
 		}
+	}
 
-		return nil
-	})
-}
+	syncedEvent := newEmailEvent(userID, account.ID, email.ID, models.EmailEventSynced, models.EmailEventSourceSync)
+	syncedEvent.ToFolderID = &folderID
+	recordEmailEvents(ctx, tx, syncedEvent)
 
-// updateExistingEmail 更新现有邮件
-func (s *SyncService) updateExistingEmail(email *models.Email, emailMsg *providers.EmailMessage, folderID uint) error {
-	// 更新可能变化的字段
-	email.FolderID = &folderID
-	email.IsRead = s.isEmailRead(emailMsg.Flags)
-	email.IsStarred = s.isEmailStarred(emailMsg.Flags)
-	email.IsDraft = s.isEmailDraft(emailMsg.Flags)
+	// 累加收发统计（失败不影响同步，可通过重建统计修复）
+	if err := recordEmailVolume(tx, account, email); err != nil {
+		log.Printf("Failed to record email volume for %s: %v", emailMsg.MessageID, err)
+	}
 
-	return s.db.Save(email).Error
+	return &insertedSyncedEmail{email: email, threadMuted: threadMuted, blocked: blocked}, nil
 }
 
-// invalidateEmailListCache 使邮件列表缓存失效
-func (s *SyncService) invalidateEmailListCache(userID uint) {
-	if s.cacheManager == nil {
-		return
+// afterSyncedEmailInserted 新邮件所在事务提交后发布通知、清除列表缓存并写入变更日志。
+// 变更日志在事务提交后写入，避免与邮件事务争用数据库锁；发布失败不影响已保存的邮件
+func (s *SyncService) afterSyncedEmailInserted(ctx context.Context, account *models.EmailAccount, inserted *insertedSyncedEmail, folderID, userID uint) {
+	email := inserted.email
+	if !inserted.blocked {
+		userMuted := userSettingsOrDefault(ctx, s.db, userID).NotificationsMuted
+		publishNewEmailNotification(ctx, s.db, s.eventPublisher, account, email, userID, inserted.threadMuted, userMuted)
+		publishVerificationCode(ctx, s.eventPublisher, email, userID)
+		if s.forwarder != nil {
+			s.forwarder.ForwardNewEmail(ctx, account, email)
+		}
 	}
 
-	// 获取所有缓存键
-	keys := s.cacheManager.EmailListCache().Keys()
+	// 清除邮件列表缓存，确保前端能看到新邮件
+	if s.cacheManager != nil {
+		s.invalidateEmailListCache(userID, account.ID, &folderID)
+		if inserted.blocked && *email.FolderID != folderID {
+			s.invalidateEmailListCache(userID, account.ID, email.FolderID)
+		}
+	}
 
-	// 删除与该用户相关的缓存
-	// 由于我们使用MD5哈希，这里简单地清除所有缓存
-	// 在实际应用中可以通过在缓存键中包含用户ID前缀来优化
-	for _, key := range keys {
-		s.cacheManager.EmailListCache().Delete(key)
+	recordEmailChanges(ctx, s.changeLog, userID, account.ID, models.ChangeActionCreated, email.ID)
+}
+
+// invalidateEmailListCache 使新邮件所在文件夹及汇总列表的缓存失效
+func (s *SyncService) invalidateEmailListCache(userID, accountID uint, folderID *uint) {
+	if s.cacheManager == nil {
+		return
 	}
 
-	log.Printf("Invalidated email list cache for user %d", userID)
+	removed := s.cacheManager.InvalidateEmailLists(userID, accountID, folderID)
+	log.Printf("Invalidated %d email list cache entries for user %d", removed, userID)
 }
 
 // 辅助函数
@@ -628,9 +914,16 @@ func convertEmailAddresses(addrs []*models.EmailAddress) []models.EmailAddress {
 
 // updateSyncError 更新同步错误状态
 func (s *SyncService) updateSyncError(account *models.EmailAccount, err error) {
-	account.SyncStatus = "error"
-	account.ErrorMessage = err.Error()
+	// 服务关闭导致的中断不算同步错误，恢复为待同步状态
+	if s.shutdownCtx.Err() != nil {
+		account.SyncStatus = "pending"
+		account.ErrorMessage = syncInterruptedMessage
+	} else {
+		account.SyncStatus = "error"
+		account.ErrorMessage = err.Error()
+	}
 	s.db.Save(account)
+	recordAccountChange(context.Background(), s.changeLog, account.UserID, account.ID, models.ChangeActionUpdated)
 }
 
 // performIncrementalSync 执行真正的增量同步
@@ -651,7 +944,7 @@ func (s *SyncService) performIncrementalSync(ctx context.Context, provider provi
 		fmt.Printf("❌ [INCREMENTAL] Failed to get folder status for %s: %v\n", folder.Name, err)
 
 		// 检查是否是文件夹不存在的错误
-		if s.isFolderNotExistError(err) {
+		if errors.Is(err, providers.ErrFolderMissing) {
 			fmt.Printf("⚠️ [INCREMENTAL] Folder %s does not exist on server, attempting recovery...\n", folder.Name)
 			return s.handleMissingFolder(ctx, imapClient, folder, account)
 		}
@@ -717,13 +1010,26 @@ func (s *SyncService) performFullSync(ctx context.Context, provider providers.Em
 
 	// 删除该文件夹的所有现有邮件（因为UIDVALIDITY变化，所有UID都无效了）
 	// 使用硬删除来避免UNIQUE约束冲突，同时先清理附件防止孤儿数据
-	if err := s.db.WithContext(ctx).
-		Where("email_id IN (?)", s.db.Model(&models.Email{}).Select("id").Where("account_id = ? AND folder_id = ?", account.ID, folder.ID)).
-		Delete(&models.Attachment{}).Error; err != nil {
-		log.Printf("Warning: failed to delete attachments for folder %s: %v", folder.Name, err)
+	var staleEmailIDs []uint
+	if s.changeLog != nil {
+		if err := s.db.WithContext(ctx).Model(&models.Email{}).Where("account_id = ? AND folder_id = ?", account.ID, folder.ID).Pluck("id", &staleEmailIDs).Error; err != nil {
+			log.Printf("Warning: failed to list existing emails for folder %s: %v", folder.Name, err)
+		}
 	}
-	if err := s.db.WithContext(ctx).Unscoped().Where("account_id = ? AND folder_id = ?", account.ID, folder.ID).Delete(&models.Email{}).Error; err != nil {
+	if err := writequeue.Transaction(ctx, s.db.WithContext(ctx), func(tx *gorm.DB) error {
+		_, err := purgeEmails(tx, "account_id = ? AND folder_id = ?", account.ID, folder.ID)
+		return err
+	}); err != nil {
 		log.Printf("Warning: failed to delete existing emails for folder %s: %v", folder.Name, err)
+	} else {
+		s.invalidateEmailListCache(account.UserID, account.ID, &folder.ID)
+		recordEmailChanges(ctx, s.changeLog, account.UserID, account.ID, models.ChangeActionDeleted, staleEmailIDs...)
+	}
+	if folder.LastMergedUID > 0 {
+		folder.LastMergedUID = 0
+		if err := s.db.WithContext(ctx).Model(folder).Update("last_merged_uid", 0).Error; err != nil {
+			log.Printf("Warning: failed to reset merged UID for folder %s: %v", folder.Name, err)
+		}
 	}
 
 	// 特殊处理：如果UIDNext=0，使用序列号范围而不是UID范围
@@ -761,6 +1067,10 @@ func (s *SyncService) performDeltaSync(ctx context.Context, provider providers.E
 		log.Printf("No previous emails found for folder %s, starting from UID 1", folder.Name)
 		lastUID = 0
 	}
+	// Gmail合并的标签中已有的邮件不保存在该文件夹，从已处理的UID继续
+	if folder.LastMergedUID > lastUID {
+		lastUID = folder.LastMergedUID
+	}
 
 	// 特殊处理：如果UIDNext和Total不匹配，可能存在UID不连续的情况
 	var gapEmails []*providers.EmailMessage
@@ -842,6 +1152,7 @@ func (s *SyncService) getEmailsInBatches(ctx context.Context, provider providers
 			return err
 		})
 		if err != nil {
+			providers.ReleaseEmailContents(allEmails)
 			return nil, fmt.Errorf("failed to get email batch %d-%d: %w", currentUID, batchEndUID, err)
 		}
 
@@ -859,35 +1170,6 @@ func (s *SyncService) getAccountLock(accountID uint) *sync.Mutex {
 	return lock.(*sync.Mutex)
 }
 
-// isConnectionError 检查是否是连接错误
-func (s *SyncService) isConnectionError(err error) bool {
-	if err == nil {
-		return false
-	}
-
-	errStr := strings.ToLower(err.Error())
-	connectionErrors := []string{
-		"connection closed",
-		"connection reset",
-		"connection refused",
-		"connection timeout",
-		"broken pipe",
-		"network is unreachable",
-		"no route to host",
-		"timeout",
-		"eof",
-		"i/o timeout",
-	}
-
-	for _, connErr := range connectionErrors {
-		if strings.Contains(errStr, connErr) {
-			return true
-		}
-	}
-
-	return false
-}
-
 // ensureConnection 确保IMAP连接有效，如果断开则重连
 func (s *SyncService) ensureConnection(ctx context.Context, provider providers.EmailProvider, account *models.EmailAccount) error {
 	// 检查provider是否连接
@@ -930,8 +1212,9 @@ func (s *SyncService) executeWithConnectionRetry(ctx context.Context, provider p
 		// 确保连接有效
 		if err := s.ensureConnection(ctx, provider, account); err != nil {
 			log.Printf("Failed to ensure connection for account %s (attempt %d): %v", account.Email, attempt+1, err)
-			if attempt == maxRetries-1 {
-				return fmt.Errorf("failed to establish connection after %d attempts: %w", maxRetries, err)
+			// 认证失败、被限流等错误重连无济于事
+			if !errors.Is(err, providers.ErrTransient) || attempt == maxRetries-1 {
+				return fmt.Errorf("failed to establish connection after %d attempts: %w", attempt+1, err)
 			}
 			time.Sleep(time.Duration(attempt+1) * 2 * time.Second)
 			continue
@@ -943,8 +1226,8 @@ func (s *SyncService) executeWithConnectionRetry(ctx context.Context, provider p
 			return nil
 		}
 
-		// 检查是否是连接错误
-		if s.isConnectionError(err) {
+		// 仅对连接中断、超时等临时错误重连重试
+		if errors.Is(err, providers.ErrTransient) {
 			log.Printf("Connection error detected for account %s (attempt %d): %v", account.Email, attempt+1, err)
 
 			// 断开连接，下次循环会重连
@@ -1003,19 +1286,6 @@ func (s *SyncService) getEmailsWithGapDetection(ctx context.Context, imapClient
 	return emails, nil
 }
 
-// isFolderNotExistError 检查是否是文件夹不存在的错误
-func (s *SyncService) isFolderNotExistError(err error) bool {
-	if err == nil {
-		return false
-	}
-
-	errStr := strings.ToLower(err.Error())
-	return strings.Contains(errStr, "folder not exist") ||
-		strings.Contains(errStr, "mailbox does not exist") ||
-		strings.Contains(errStr, "no such mailbox") ||
-		strings.Contains(errStr, "mailbox not found")
-}
-
 // handleMissingFolder 处理缺失的文件夹
 func (s *SyncService) handleMissingFolder(ctx context.Context, imapClient providers.IMAPClient, folder *models.Folder, account *models.EmailAccount) ([]*providers.EmailMessage, error) {
 	log.Printf("Handling missing folder %s (type: %s) for account %s", folder.Name, folder.Type, account.Email)
@@ -1099,6 +1369,27 @@ func (s *SyncService) getEmailsBySequenceRange(ctx context.Context, imapClient p
 	return emails, nil
 }
 
+// saveAttachmentInfoContent 保存同步时已获取的附件内容，溢出到临时文件的内容直接流式写入存储
+func (s *SyncService) saveAttachmentInfoContent(ctx context.Context, attachment *models.Attachment, info *providers.AttachmentInfo) error {
+	if info.ContentPath == "" {
+		return s.saveAttachmentContent(ctx, attachment, info.Content)
+	}
+	if s.attachmentStorage == nil {
+		return fmt.Errorf("attachment storage not configured")
+	}
+
+	content, err := info.OpenContent()
+	if err != nil {
+		return fmt.Errorf("failed to open attachment content: %w", err)
+	}
+	defer content.Close()
+
+	if err := s.attachmentStorage.Store(ctx, attachment, content); err != nil {
+		return fmt.Errorf("failed to store attachment content: %w", err)
+	}
+	return nil
+}
+
 // saveAttachmentContent 保存附件内容到本地存储
 func (s *SyncService) saveAttachmentContent(ctx context.Context, attachment *models.Attachment, rawContent []byte) error {
 	if s.attachmentStorage == nil {
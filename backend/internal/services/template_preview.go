@@ -0,0 +1,113 @@
+package services
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// 模板变量问题类型
+const (
+	// TemplateIssueMissing 必填变量未提供
+	TemplateIssueMissing = "missing"
+	// TemplateIssueInvalid 变量值与声明的类型不符
+	TemplateIssueInvalid = "invalid"
+	// TemplateIssueUndefined 模板引用了未声明也未提供的变量
+	TemplateIssueUndefined = "undefined"
+)
+
+// cidReferencePattern HTML正文中对内联图片的 cid: 引用
+var cidReferencePattern = regexp.MustCompile(`(?i)cid:([^"'\s)>]+)`)
+
+// TemplateVariableIssue 模板变量的一项校验问题
+type TemplateVariableIssue struct {
+	Variable string `json:"variable"`
+	Problem  string `json:"problem"` // missing, invalid, undefined
+	Message  string `json:"message,omitempty"`
+}
+
+// TemplateValidationError 模板变量校验失败，包含全部问题
+type TemplateValidationError struct {
+	Issues []TemplateVariableIssue
+}
+
+// Error 按问题类型汇总错误信息
+func (e *TemplateValidationError) Error() string {
+	var missing, undefined, parts []string
+	for _, issue := range e.Issues {
+		switch issue.Problem {
+		case TemplateIssueMissing:
+			missing = append(missing, issue.Variable)
+		case TemplateIssueUndefined:
+			undefined = append(undefined, issue.Variable)
+		default:
+			parts = append(parts, fmt.Sprintf("invalid value for template variable %s: %s", issue.Variable, issue.Message))
+		}
+	}
+	if len(missing) > 0 {
+		parts = append([]string{"missing required template variables: " + strings.Join(missing, ", ")}, parts...)
+	}
+	if len(undefined) > 0 {
+		parts = append(parts, "undefined template variables: "+strings.Join(undefined, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// TemplatePreviewRequest 模板预览请求
+type TemplatePreviewRequest struct {
+	Data              map[string]interface{} `json:"data"`
+	IncludeSignature  *bool                  `json:"include_signature,omitempty"`  // 是否追加用户签名，默认追加
+	InlineAttachments []*InlineAttachment    `json:"inline_attachments,omitempty"` // 正文通过 cid: 引用的内联图片
+}
+
+// TemplatePreview 模板预览结果，与发送时的最终内容一致
+type TemplatePreview struct {
+	ProcessedTemplate
+	PreviewHTML         string   `json:"preview_html"`          // cid: 引用替换为 data URI，可直接在浏览器中显示
+	InlineImages        []string `json:"inline_images"`         // 正文引用的内联图片 Content-ID
+	MissingInlineImages []string `json:"missing_inline_images"` // 正文引用但请求中未提供的内联图片
+}
+
+// includeSignature 是否追加签名
+func (r *TemplatePreviewRequest) includeSignature() bool {
+	return r.IncludeSignature == nil || *r.IncludeSignature
+}
+
+// resolveInlineImages 将HTML中的 cid: 引用替换为对应内联图片的 data URI，返回替换后的HTML、引用的和缺失的 Content-ID
+func resolveInlineImages(htmlBody string, attachments []*InlineAttachment) (string, []string, []string) {
+	images := make(map[string]string, len(attachments))
+	for _, attachment := range attachments {
+		if attachment == nil || len(attachment.Data) == 0 {
+			continue
+		}
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = http.DetectContentType(attachment.Data)
+		}
+		contentID := strings.ToLower(strings.Trim(attachment.ContentID, "<> "))
+		images[contentID] = "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(attachment.Data)
+	}
+
+	referenced := []string{}
+	missing := []string{}
+	seen := make(map[string]bool)
+	previewHTML := cidReferencePattern.ReplaceAllStringFunc(htmlBody, func(ref string) string {
+		contentID := ref[len("cid:"):]
+		key := strings.ToLower(contentID)
+		uri, ok := images[key]
+		if !seen[key] {
+			seen[key] = true
+			referenced = append(referenced, contentID)
+			if !ok {
+				missing = append(missing, contentID)
+			}
+		}
+		if !ok {
+			return ref
+		}
+		return uri
+	})
+	return previewHTML, referenced, missing
+}
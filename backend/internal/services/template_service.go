@@ -3,9 +3,6 @@ package services
 import (
 	"context"
 	"fmt"
-	"text/template"
-	"bytes"
-	htmlTemplate "html/template"
 
 	"firemail/internal/models"
 
@@ -30,7 +27,10 @@ type EmailTemplateService interface {
 	DeleteTemplate(ctx context.Context, userID, templateID uint) error
 
 	// ProcessTemplate 处理模板，替换变量
-	ProcessTemplate(ctx context.Context, templateID uint, data map[string]interface{}) (*ProcessedTemplate, error)
+	ProcessTemplate(ctx context.Context, userID, templateID uint, data map[string]interface{}) (*ProcessedTemplate, error)
+
+	// PreviewTemplate 预览模板渲染的最终内容，包括签名和内联图片（不发送、不计入使用次数）
+	PreviewTemplate(ctx context.Context, userID, templateID uint, req *TemplatePreviewRequest) (*TemplatePreview, error)
 
 	// GetBuiltInTemplates 获取内置模板
 	GetBuiltInTemplates(ctx context.Context) ([]*models.EmailTemplate, error)
@@ -70,6 +70,7 @@ type ListEmailTemplatesRequest struct {
 	IsActive      *bool  `form:"is_active"`
 	IsShared      *bool  `form:"is_shared"`
 	IncludeBuiltIn bool  `form:"include_built_in"`
+	Scope         string `form:"scope"` // all, private, shared
 	Search        string `form:"search"`
 	Page          int    `form:"page"`
 	PageSize      int    `form:"page_size"`
@@ -120,6 +121,14 @@ func (s *EmailTemplateServiceImpl) CreateTemplate(ctx context.Context, userID ui
 		return nil, fmt.Errorf("template body is required")
 	}
 	
+	if err := validateTemplateVariables(req.Variables); err != nil {
+		return nil, err
+	}
+	
+	if err := validateTemplateSyntax(req.Subject, req.TextBody, req.HTMLBody); err != nil {
+		return nil, err
+	}
+	
 	// 检查模板名称是否已存在
 	var existingTemplate models.EmailTemplate
 	err := s.db.WithContext(ctx).
@@ -249,6 +258,18 @@ func (s *EmailTemplateServiceImpl) UpdateTemplate(ctx context.Context, userID, t
 		}
 	}
 
+	// 校验更新后的模板内容
+	variables, err := template.GetVariables()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template variables: %w", err)
+	}
+	if err := validateTemplateVariables(variables); err != nil {
+		return nil, err
+	}
+	if err := validateTemplateSyntax(template.Subject, template.TextBody, template.HTMLBody); err != nil {
+		return nil, err
+	}
+
 	// 保存更新
 	if err := s.db.WithContext(ctx).Save(&template).Error; err != nil {
 		return nil, fmt.Errorf("failed to update template: %w", err)
@@ -313,8 +334,17 @@ func (s *EmailTemplateServiceImpl) ListTemplates(ctx context.Context, userID uin
 	query := s.db.WithContext(ctx).Model(&models.EmailTemplate{}).
 		Where("deleted_at IS NULL")
 
-	// 权限过滤：用户自己的模板 + 共享模板 + 内置模板
-	query = query.Where("user_id = ? OR is_shared = ? OR is_built_in = ?", userID, true, true)
+	// 权限过滤：按可见范围筛选，内置模板对所有用户可见
+	switch req.Scope {
+	case "", TemplateScopeAll:
+		query = query.Where("user_id = ? OR is_shared = ? OR is_built_in = ?", userID, true, true)
+	case TemplateScopePrivate:
+		query = query.Where("(user_id = ? AND is_shared = ?) OR is_built_in = ?", userID, false, true)
+	case TemplateScopeShared:
+		query = query.Where("is_shared = ? OR is_built_in = ?", true, true)
+	default:
+		return nil, fmt.Errorf("invalid template scope: %s", req.Scope)
+	}
 
 	// 应用过滤条件
 	if req.Category != "" {
@@ -394,98 +424,68 @@ func (s *EmailTemplateServiceImpl) ListTemplates(ctx context.Context, userID uin
 }
 
 // ProcessTemplate 处理模板，替换变量
-func (s *EmailTemplateServiceImpl) ProcessTemplate(ctx context.Context, templateID uint, data map[string]interface{}) (*ProcessedTemplate, error) {
-	// 获取模板
-	var tmpl models.EmailTemplate
-	err := s.db.WithContext(ctx).
-		Where("id = ? AND deleted_at IS NULL", templateID).
-		First(&tmpl).Error
-
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("template not found")
-		}
-		return nil, fmt.Errorf("failed to find template: %w", err)
-	}
-
-	// 处理主题
-	subject, err := s.processTemplateText(tmpl.Subject, data)
+func (s *EmailTemplateServiceImpl) ProcessTemplate(ctx context.Context, userID, templateID uint, data map[string]interface{}) (*ProcessedTemplate, error) {
+	tmpl, err := s.GetTemplate(ctx, userID, templateID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to process subject: %w", err)
+		return nil, err
 	}
 
-	// 处理文本正文
-	textBody, err := s.processTemplateText(tmpl.TextBody, data)
-	if err != nil {
-		return nil, fmt.Errorf("failed to process text body: %w", err)
+	if !tmpl.IsActive {
+		return nil, fmt.Errorf("template is not active")
 	}
 
-	// 处理HTML正文
-	htmlBody, err := s.processTemplateHTML(tmpl.HTMLBody, data)
+	processed, err := renderTemplate(tmpl, data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to process HTML body: %w", err)
+		return nil, err
 	}
 
 	// 增加使用次数
 	tmpl.IncrementUsage()
-	s.db.WithContext(ctx).Save(&tmpl)
+	s.db.WithContext(ctx).Model(tmpl).UpdateColumns(map[string]interface{}{
+		"usage_count":  tmpl.UsageCount,
+		"last_used_at": tmpl.LastUsedAt,
+	})
 
-	return &ProcessedTemplate{
-		Subject:  subject,
-		TextBody: textBody,
-		HTMLBody: htmlBody,
-	}, nil
+	return processed, nil
 }
 
-// GetBuiltInTemplates 获取内置模板
-func (s *EmailTemplateServiceImpl) GetBuiltInTemplates(ctx context.Context) ([]*models.EmailTemplate, error) {
-	var templates []*models.EmailTemplate
-	err := s.db.WithContext(ctx).
-		Where("is_built_in = ? AND deleted_at IS NULL", true).
-		Order("category ASC, name ASC").
-		Find(&templates).Error
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to get built-in templates: %w", err)
+// PreviewTemplate 预览模板渲染结果（不计入使用次数）
+func (s *EmailTemplateServiceImpl) PreviewTemplate(ctx context.Context, userID, templateID uint, req *TemplatePreviewRequest) (*TemplatePreview, error) {
+	if req == nil {
+		req = &TemplatePreviewRequest{}
 	}
 
-	return templates, nil
-}
-
-// processTemplateText 处理文本模板
-func (s *EmailTemplateServiceImpl) processTemplateText(templateText string, data map[string]interface{}) (string, error) {
-	if templateText == "" {
-		return "", nil
+	tmpl, err := s.GetTemplate(ctx, userID, templateID)
+	if err != nil {
+		return nil, err
 	}
 
-	tmpl, err := template.New("text").Parse(templateText)
+	processed, err := renderTemplate(tmpl, req.Data)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse text template: %w", err)
+		return nil, err
 	}
 
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", fmt.Errorf("failed to execute text template: %w", err)
+	if req.includeSignature() {
+		signature := userSettingsOrDefault(ctx, s.db, userID).Signature
+		processed.TextBody, processed.HTMLBody = appendSignature(processed.TextBody, processed.HTMLBody, signature)
 	}
 
-	return buf.String(), nil
+	preview := &TemplatePreview{ProcessedTemplate: *processed}
+	preview.PreviewHTML, preview.InlineImages, preview.MissingInlineImages = resolveInlineImages(processed.HTMLBody, req.InlineAttachments)
+	return preview, nil
 }
 
-// processTemplateHTML 处理HTML模板
-func (s *EmailTemplateServiceImpl) processTemplateHTML(templateHTML string, data map[string]interface{}) (string, error) {
-	if templateHTML == "" {
-		return "", nil
-	}
+// GetBuiltInTemplates 获取内置模板
+func (s *EmailTemplateServiceImpl) GetBuiltInTemplates(ctx context.Context) ([]*models.EmailTemplate, error) {
+	var templates []*models.EmailTemplate
+	err := s.db.WithContext(ctx).
+		Where("is_built_in = ? AND deleted_at IS NULL", true).
+		Order("category ASC, name ASC").
+		Find(&templates).Error
 
-	tmpl, err := htmlTemplate.New("html").Parse(templateHTML)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse HTML template: %w", err)
-	}
-
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", fmt.Errorf("failed to execute HTML template: %w", err)
+		return nil, fmt.Errorf("failed to get built-in templates: %w", err)
 	}
 
-	return buf.String(), nil
+	return templates, nil
 }
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"firemail/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setupTemplateServiceTest(t *testing.T) (*emailStateServiceTestEnv, *EmailTemplateServiceImpl) {
+	t.Helper()
+
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.EmailTemplate{}))
+
+	return env, NewEmailTemplateService(env.db).(*EmailTemplateServiceImpl)
+}
+
+func TestTemplateRenderingValidatesVariablesAndDefaults(t *testing.T) {
+	env, service := setupTemplateServiceTest(t)
+	ctx := context.Background()
+
+	tmpl, err := service.CreateTemplate(ctx, env.user.ID, &CreateEmailTemplateRequest{
+		Name:     "发票提醒",
+		Subject:  "发票 {{.Number}} 待支付",
+		TextBody: "{{.Name}}，您好：金额 {{.Amount}} 元，截止 {{.DueDate}}。{{if .Urgent}}请尽快处理。{{end}}",
+		HTMLBody: "<p>{{.Name}}</p>",
+		Variables: []models.TemplateVariable{
+			{Name: "Number", Type: TemplateVariableTypeString, Required: true},
+			{Name: "Name", Type: TemplateVariableTypeString, DefaultValue: "客户"},
+			{Name: "Amount", Type: TemplateVariableTypeNumber, Required: true},
+			{Name: "DueDate", Type: TemplateVariableTypeDate, Required: true},
+			{Name: "Urgent", Type: TemplateVariableTypeBoolean},
+		},
+	})
+	require.NoError(t, err)
+
+	preview, err := service.PreviewTemplate(ctx, env.user.ID, tmpl.ID, &TemplatePreviewRequest{Data: map[string]interface{}{
+		"Number":  "INV-7",
+		"Amount":  "128.5",
+		"DueDate": "2024-05-01",
+		"Urgent":  true,
+	}})
+	require.NoError(t, err)
+	require.Equal(t, "发票 INV-7 待支付", preview.Subject)
+	require.Equal(t, "客户，您好：金额 128.5 元，截止 2024-05-01。请尽快处理。", preview.TextBody)
+	require.Equal(t, "<p>客户</p>", preview.HTMLBody)
+
+	// HTML正文中的变量需要转义
+	preview, err = service.PreviewTemplate(ctx, env.user.ID, tmpl.ID, &TemplatePreviewRequest{Data: map[string]interface{}{
+		"Number": "INV-8", "Amount": 1, "DueDate": "2024-05-01", "Name": "<b>Bob</b>",
+	}})
+	require.NoError(t, err)
+	require.Equal(t, "<p>&lt;b&gt;Bob&lt;/b&gt;</p>", preview.HTMLBody)
+
+	_, err = service.PreviewTemplate(ctx, env.user.ID, tmpl.ID, &TemplatePreviewRequest{Data: map[string]interface{}{"Number": "INV-9"}})
+	require.EqualError(t, err, "missing required template variables: Amount, DueDate")
+
+	_, err = service.PreviewTemplate(ctx, env.user.ID, tmpl.ID, &TemplatePreviewRequest{Data: map[string]interface{}{
+		"Number": "INV-9", "Amount": "很多", "DueDate": "2024-05-01",
+	}})
+	require.ErrorContains(t, err, "invalid value for template variable Amount")
+
+	// 预览不计入使用次数，正式处理计入
+	_, err = service.ProcessTemplate(ctx, env.user.ID, tmpl.ID, map[string]interface{}{
+		"Number": "INV-10", "Amount": 3, "DueDate": "2024-05-01",
+	})
+	require.NoError(t, err)
+
+	var stored models.EmailTemplate
+	require.NoError(t, env.db.First(&stored, tmpl.ID).Error)
+	require.Equal(t, 1, stored.UsageCount)
+}
+
+func TestTemplateDefinitionValidation(t *testing.T) {
+	env, service := setupTemplateServiceTest(t)
+	ctx := context.Background()
+
+	_, err := service.CreateTemplate(ctx, env.user.ID, &CreateEmailTemplateRequest{
+		Name:     "坏语法",
+		Subject:  "{{.Name",
+		TextBody: "正文",
+	})
+	require.ErrorContains(t, err, "invalid subject template")
+
+	_, err = service.CreateTemplate(ctx, env.user.ID, &CreateEmailTemplateRequest{
+		Name:      "坏默认值",
+		Subject:   "主题",
+		TextBody:  "{{.Count}}",
+		Variables: []models.TemplateVariable{{Name: "Count", Type: TemplateVariableTypeNumber, DefaultValue: "abc"}},
+	})
+	require.ErrorContains(t, err, "invalid default value for template variable Count")
+
+	tmpl, err := service.CreateTemplate(ctx, env.user.ID, &CreateEmailTemplateRequest{
+		Name:     "未声明变量",
+		Subject:  "主题",
+		TextBody: "{{.Unknown}}",
+	})
+	require.NoError(t, err)
+
+	_, err = service.PreviewTemplate(ctx, env.user.ID, tmpl.ID, nil)
+	require.ErrorContains(t, err, "Unknown")
+}
+
+func TestTemplateScopes(t *testing.T) {
+	env, service := setupTemplateServiceTest(t)
+	ctx := context.Background()
+
+	other := &models.User{Username: fmt.Sprintf("template_other_%s", t.Name()), Password: "password123", Role: "user", IsActive: true}
+	require.NoError(t, env.db.Create(other).Error)
+
+	create := func(userID uint, name string, shared bool) *models.EmailTemplate {
+		tmpl, err := service.CreateTemplate(ctx, userID, &CreateEmailTemplateRequest{
+			Name: name, Subject: name, TextBody: "正文", IsShared: shared,
+		})
+		require.NoError(t, err)
+		return tmpl
+	}
+	create(env.user.ID, "mine-private", false)
+	create(env.user.ID, "mine-shared", true)
+	create(other.ID, "other-shared", true)
+	otherPrivate := create(other.ID, "other-private", false)
+
+	names := func(scope string) []string {
+		resp, err := service.ListTemplates(ctx, env.user.ID, &ListEmailTemplatesRequest{Scope: scope, SortBy: "name", SortOrder: "asc"})
+		require.NoError(t, err)
+		result := make([]string, 0, len(resp.Templates))
+		for _, tmpl := range resp.Templates {
+			result = append(result, tmpl.Name)
+		}
+		return result
+	}
+
+	require.Equal(t, []string{"mine-private", "mine-shared", "other-shared"}, names(TemplateScopeAll))
+	require.Equal(t, []string{"mine-private"}, names(TemplateScopePrivate))
+	require.Equal(t, []string{"mine-shared", "other-shared"}, names(TemplateScopeShared))
+
+	_, err := service.PreviewTemplate(ctx, env.user.ID, otherPrivate.ID, nil)
+	require.ErrorContains(t, err, "permission denied")
+}
+
+func TestTemplatePreviewRendersFinalContent(t *testing.T) {
+	env, service := setupTemplateServiceTest(t)
+	require.NoError(t, env.db.AutoMigrate(&models.UserSetting{}))
+	ctx := context.Background()
+
+	signature := "Alice\nACME"
+	_, err := NewSettingsService(env.db).UpdateSettings(ctx, env.user.ID, &UpdateUserSettingsRequest{Signature: &signature})
+	require.NoError(t, err)
+
+	tmpl, err := service.CreateTemplate(ctx, env.user.ID, &CreateEmailTemplateRequest{
+		Name:      "带图片",
+		Subject:   "Hi {{.Name}}",
+		TextBody:  "Hello {{.Name}}",
+		HTMLBody:  `<p>Hello {{.Name}}</p><img src="cid:logo@acme"><img src="cid:banner">`,
+		Variables: []models.TemplateVariable{{Name: "Name", Required: true}, {Name: "Count", Type: TemplateVariableTypeNumber}},
+	})
+	require.NoError(t, err)
+
+	png := []byte("\x89PNG\r\n\x1a\nrest")
+	preview, err := service.PreviewTemplate(ctx, env.user.ID, tmpl.ID, &TemplatePreviewRequest{
+		Data:              map[string]interface{}{"Name": "Bob"},
+		InlineAttachments: []*InlineAttachment{{ContentID: "<Logo@acme>", Filename: "logo.png", Data: png}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Hello Bob\n\n-- \nAlice\nACME", preview.TextBody)
+	require.Contains(t, preview.HTMLBody, `<img src="cid:logo@acme">`)
+	require.Contains(t, preview.HTMLBody, "-- <br>Alice<br>ACME</div>")
+	require.Contains(t, preview.PreviewHTML, `<img src="data:image/png;base64,iVBORw0KGgpyZXN0">`)
+	require.Contains(t, preview.PreviewHTML, `<img src="cid:banner">`)
+	require.Equal(t, []string{"logo@acme", "banner"}, preview.InlineImages)
+	require.Equal(t, []string{"banner"}, preview.MissingInlineImages)
+
+	noSignature := false
+	preview, err = service.PreviewTemplate(ctx, env.user.ID, tmpl.ID, &TemplatePreviewRequest{
+		Data: map[string]interface{}{"Name": "Bob"}, IncludeSignature: &noSignature,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Hello Bob", preview.TextBody)
+
+	// 一次返回全部变量问题
+	tmpl, err = service.CreateTemplate(ctx, env.user.ID, &CreateEmailTemplateRequest{
+		Name:      "多个问题",
+		Subject:   "{{.Name}} {{.Extra}}",
+		TextBody:  "{{.Count}} {{.Other}}",
+		Variables: []models.TemplateVariable{{Name: "Name", Required: true}, {Name: "Count", Type: TemplateVariableTypeNumber}},
+	})
+	require.NoError(t, err)
+	_, err = service.PreviewTemplate(ctx, env.user.ID, tmpl.ID, &TemplatePreviewRequest{Data: map[string]interface{}{"Count": "many"}})
+	var validationErr *TemplateValidationError
+	require.ErrorAs(t, err, &validationErr)
+	require.Equal(t, []TemplateVariableIssue{
+		{Variable: "Name", Problem: TemplateIssueMissing},
+		{Variable: "Count", Problem: TemplateIssueInvalid, Message: `expected number, got "many"`},
+		{Variable: "Extra", Problem: TemplateIssueUndefined},
+		{Variable: "Other", Problem: TemplateIssueUndefined},
+	}, validationErr.Issues)
+	require.EqualError(t, err, `missing required template variables: Name; invalid value for template variable Count: expected number, got "many"; undefined template variables: Extra, Other`)
+}
@@ -0,0 +1,273 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	htmlTemplate "html/template"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"firemail/internal/models"
+)
+
+// 模板变量类型
+const (
+	TemplateVariableTypeString  = "string"
+	TemplateVariableTypeNumber  = "number"
+	TemplateVariableTypeDate    = "date"
+	TemplateVariableTypeBoolean = "boolean"
+)
+
+// 模板可见范围
+const (
+	// TemplateScopeAll 自己的模板 + 共享模板（默认）
+	TemplateScopeAll = "all"
+	// TemplateScopePrivate 仅自己未共享的模板
+	TemplateScopePrivate = "private"
+	// TemplateScopeShared 所有共享模板（包括自己共享的）
+	TemplateScopeShared = "shared"
+)
+
+var (
+	templateVariableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	templateDateLayouts         = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"}
+	undefinedTemplateKeyPattern = regexp.MustCompile(`map has no entry for key "([^"]+)"`)
+)
+
+// validateTemplateVariables 校验模板变量定义（名称、类型、默认值）
+func validateTemplateVariables(variables []models.TemplateVariable) error {
+	seen := make(map[string]struct{}, len(variables))
+	for _, variable := range variables {
+		if !templateVariableNamePattern.MatchString(variable.Name) {
+			return fmt.Errorf("invalid template variable name: %q", variable.Name)
+		}
+		if _, exists := seen[variable.Name]; exists {
+			return fmt.Errorf("duplicate template variable: %s", variable.Name)
+		}
+		seen[variable.Name] = struct{}{}
+
+		switch variable.Type {
+		case "", TemplateVariableTypeString, TemplateVariableTypeNumber, TemplateVariableTypeDate, TemplateVariableTypeBoolean:
+		default:
+			return fmt.Errorf("unsupported type %q for template variable %s", variable.Type, variable.Name)
+		}
+
+		if variable.DefaultValue != nil {
+			if _, err := coerceTemplateValue(variable, variable.DefaultValue); err != nil {
+				return fmt.Errorf("invalid default value for template variable %s: %w", variable.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateTemplateSyntax 校验模板内容语法
+func validateTemplateSyntax(subject, textBody, htmlBody string) error {
+	if _, err := template.New("subject").Parse(subject); err != nil {
+		return fmt.Errorf("invalid subject template: %w", err)
+	}
+	if _, err := template.New("text").Parse(textBody); err != nil {
+		return fmt.Errorf("invalid text template: %w", err)
+	}
+	if _, err := htmlTemplate.New("html").Parse(htmlBody); err != nil {
+		return fmt.Errorf("invalid HTML template: %w", err)
+	}
+	return nil
+}
+
+// resolveTemplateData 按变量定义校验数据并填充默认值。
+// 缺失或无效的变量记为问题并以空值代替，以便继续检查模板中的其他变量
+func resolveTemplateData(variables []models.TemplateVariable, data map[string]interface{}) (map[string]interface{}, []TemplateVariableIssue) {
+	resolved := make(map[string]interface{}, len(data)+len(variables))
+	for key, value := range data {
+		resolved[key] = value
+	}
+
+	var issues []TemplateVariableIssue
+	for _, variable := range variables {
+		value, ok := data[variable.Name]
+		if !ok || value == nil || value == "" {
+			switch {
+			case variable.DefaultValue != nil:
+				value = variable.DefaultValue
+			case variable.Required:
+				issues = append(issues, TemplateVariableIssue{Variable: variable.Name, Problem: TemplateIssueMissing})
+				resolved[variable.Name] = ""
+				continue
+			default:
+				// 未提供的可选变量渲染为空
+				resolved[variable.Name] = ""
+				continue
+			}
+		}
+
+		coerced, err := coerceTemplateValue(variable, value)
+		if err != nil {
+			issues = append(issues, TemplateVariableIssue{Variable: variable.Name, Problem: TemplateIssueInvalid, Message: err.Error()})
+			resolved[variable.Name] = ""
+			continue
+		}
+		resolved[variable.Name] = coerced
+	}
+
+	return resolved, issues
+}
+
+// coerceTemplateValue 将变量值转换为声明的类型
+func coerceTemplateValue(variable models.TemplateVariable, value interface{}) (interface{}, error) {
+	switch variable.Type {
+	case TemplateVariableTypeNumber:
+		switch v := value.(type) {
+		case float64, float32, int, int32, int64, uint, uint32, uint64:
+			return v, nil
+		case json.Number:
+			return v.Float64()
+		case string:
+			number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("expected number, got %q", v)
+			}
+			return number, nil
+		}
+		return nil, fmt.Errorf("expected number, got %T", value)
+
+	case TemplateVariableTypeBoolean:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			boolean, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("expected boolean, got %q", v)
+			}
+			return boolean, nil
+		}
+		return nil, fmt.Errorf("expected boolean, got %T", value)
+
+	case TemplateVariableTypeDate:
+		switch v := value.(type) {
+		case time.Time:
+			return v, nil
+		case string:
+			for _, layout := range templateDateLayouts {
+				if _, err := time.Parse(layout, strings.TrimSpace(v)); err == nil {
+					return v, nil
+				}
+			}
+			return nil, fmt.Errorf("expected date, got %q", v)
+		}
+		return nil, fmt.Errorf("expected date, got %T", value)
+	}
+
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+// renderTemplate 渲染模板内容，变量缺失、无效或引用了未提供的变量时返回 *TemplateValidationError
+func renderTemplate(tmpl *models.EmailTemplate, data map[string]interface{}) (*ProcessedTemplate, error) {
+	variables, err := tmpl.GetVariables()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template variables: %w", err)
+	}
+
+	resolved, issues := resolveTemplateData(variables, data)
+	undefined := make(map[string]bool)
+
+	subject, err := executeCollectingUndefined(func(d map[string]interface{}) (string, error) {
+		return executeTextTemplate("subject", tmpl.Subject, d)
+	}, resolved, undefined)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process subject: %w", err)
+	}
+
+	textBody, err := executeCollectingUndefined(func(d map[string]interface{}) (string, error) {
+		return executeTextTemplate("text", tmpl.TextBody, d)
+	}, resolved, undefined)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process text body: %w", err)
+	}
+
+	htmlBody, err := executeCollectingUndefined(func(d map[string]interface{}) (string, error) {
+		return executeHTMLTemplate(tmpl.HTMLBody, d)
+	}, resolved, undefined)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process HTML body: %w", err)
+	}
+
+	names := make([]string, 0, len(undefined))
+	for name := range undefined {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		issues = append(issues, TemplateVariableIssue{Variable: name, Problem: TemplateIssueUndefined})
+	}
+	if len(issues) > 0 {
+		return nil, &TemplateValidationError{Issues: issues}
+	}
+
+	return &ProcessedTemplate{
+		Subject:  strings.TrimSpace(subject),
+		TextBody: textBody,
+		HTMLBody: htmlBody,
+	}, nil
+}
+
+// executeCollectingUndefined 执行模板，引用了数据中没有的变量时记录变量名并以空值重试
+func executeCollectingUndefined(execute func(map[string]interface{}) (string, error), data map[string]interface{}, undefined map[string]bool) (string, error) {
+	for {
+		out, err := execute(data)
+		if err == nil {
+			return out, nil
+		}
+		match := undefinedTemplateKeyPattern.FindStringSubmatch(err.Error())
+		if match == nil || undefined[match[1]] {
+			return "", err
+		}
+		undefined[match[1]] = true
+		data[match[1]] = ""
+	}
+}
+
+func executeTextTemplate(name, content string, data map[string]interface{}) (string, error) {
+	if content == "" {
+		return "", nil
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse text template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute text template: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+func executeHTMLTemplate(content string, data map[string]interface{}) (string, error) {
+	if content == "" {
+		return "", nil
+	}
+
+	tmpl, err := htmlTemplate.New("html").Option("missingkey=error").Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse HTML template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute HTML template: %w", err)
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"firemail/internal/config"
+	"firemail/internal/i18n"
+	"firemail/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 签名的最大长度（字符）
+const maxSignatureLength = 10000
+
+// ErrInvalidUserSettings 用户设置的值无效
+var ErrInvalidUserSettings = errors.New("invalid user settings")
+
+var (
+	userSettingDefaultsMu sync.RWMutex
+	userSettingDefaults   = UserSettings{
+		BlockRemoteImages:  true,
+		StripTrackers:      true,
+		Timezone:           "UTC",
+		EmailsPerPage:      20,
+		SyncConflictPolicy: models.SyncConflictPolicyServerWins,
+	}
+)
+
+// ConfigureUserSettingDefaults 设置用户设置的默认值，启动时调用一次
+func ConfigureUserSettingDefaults(cfg config.UserDefaultsConfig) {
+	userSettingDefaultsMu.Lock()
+	defer userSettingDefaultsMu.Unlock()
+	userSettingDefaults = UserSettings{
+		ReplyAll:           cfg.ReplyAll,
+		BlockRemoteImages:  cfg.BlockRemoteImages,
+		StripTrackers:      cfg.StripTrackers,
+		Timezone:           cfg.Timezone,
+		EmailsPerPage:      cfg.EmailsPerPage,
+		NotificationsMuted: cfg.NotificationsMuted,
+		Locale:             normalizedLocale(cfg.Locale),
+		SyncConflictPolicy: cfg.SyncConflictPolicy,
+	}
+}
+
+// UserSettings 用户设置，未设置的项为配置中的默认值
+type UserSettings struct {
+	ReplyAll           bool   `json:"reply_all"`
+	BlockRemoteImages  bool   `json:"block_remote_images"`
+	StripTrackers      bool   `json:"strip_trackers"` // 查看邮件时去掉追踪像素
+	Timezone           string `json:"timezone"`
+	Signature          string `json:"signature"`
+	EmailsPerPage      int    `json:"emails_per_page"`
+	NotificationsMuted bool   `json:"notifications_muted"`
+	Locale             string `json:"locale"`               // 为空表示按请求的Accept-Language选择
+	SyncConflictPolicy string `json:"sync_conflict_policy"` // server_wins、local_wins 或 ask
+}
+
+// UpdateUserSettingsRequest 修改用户设置的请求，只修改提供的字段
+type UpdateUserSettingsRequest struct {
+	ReplyAll           *bool   `json:"reply_all,omitempty"`
+	BlockRemoteImages  *bool   `json:"block_remote_images,omitempty"`
+	StripTrackers      *bool   `json:"strip_trackers,omitempty"`
+	Timezone           *string `json:"timezone,omitempty"`
+	Signature          *string `json:"signature,omitempty"`
+	EmailsPerPage      *int    `json:"emails_per_page,omitempty"`
+	NotificationsMuted *bool   `json:"notifications_muted,omitempty"`
+	Locale             *string `json:"locale,omitempty"` // 空字符串恢复为按请求选择
+	SyncConflictPolicy *string `json:"sync_conflict_policy,omitempty"`
+}
+
+// Location 用户时区，无效时使用UTC
+func (s *UserSettings) Location() *time.Location {
+	if loc, err := time.LoadLocation(s.Timezone); err == nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// fields 设置键对应的字段
+func (s *UserSettings) fields() map[string]interface{} {
+	return map[string]interface{}{
+		models.SettingReplyAll:           &s.ReplyAll,
+		models.SettingBlockRemoteImages:  &s.BlockRemoteImages,
+		models.SettingStripTrackers:      &s.StripTrackers,
+		models.SettingTimezone:           &s.Timezone,
+		models.SettingSignature:          &s.Signature,
+		models.SettingEmailsPerPage:      &s.EmailsPerPage,
+		models.SettingNotificationsMuted: &s.NotificationsMuted,
+		models.SettingLocale:             &s.Locale,
+		models.SettingSyncConflictPolicy: &s.SyncConflictPolicy,
+	}
+}
+
+// values 请求中提供的设置项
+func (r *UpdateUserSettingsRequest) values() map[string]interface{} {
+	values := make(map[string]interface{})
+	if r.ReplyAll != nil {
+		values[models.SettingReplyAll] = *r.ReplyAll
+	}
+	if r.BlockRemoteImages != nil {
+		values[models.SettingBlockRemoteImages] = *r.BlockRemoteImages
+	}
+	if r.StripTrackers != nil {
+		values[models.SettingStripTrackers] = *r.StripTrackers
+	}
+	if r.Timezone != nil {
+		values[models.SettingTimezone] = strings.TrimSpace(*r.Timezone)
+	}
+	if r.Signature != nil {
+		values[models.SettingSignature] = *r.Signature
+	}
+	if r.EmailsPerPage != nil {
+		values[models.SettingEmailsPerPage] = *r.EmailsPerPage
+	}
+	if r.NotificationsMuted != nil {
+		values[models.SettingNotificationsMuted] = *r.NotificationsMuted
+	}
+	if r.Locale != nil {
+		values[models.SettingLocale] = normalizedLocale(*r.Locale)
+	}
+	if r.SyncConflictPolicy != nil {
+		values[models.SettingSyncConflictPolicy] = *r.SyncConflictPolicy
+	}
+	return values
+}
+
+// validate 校验请求中的设置项
+func (r *UpdateUserSettingsRequest) validate() error {
+	if r.Timezone != nil {
+		name := strings.TrimSpace(*r.Timezone)
+		if _, err := time.LoadLocation(name); err != nil || name == "" {
+			return fmt.Errorf("%w: unknown time zone %q", ErrInvalidUserSettings, *r.Timezone)
+		}
+	}
+	if r.EmailsPerPage != nil && (*r.EmailsPerPage < 1 || *r.EmailsPerPage > 100) {
+		return fmt.Errorf("%w: emails_per_page must be between 1 and 100", ErrInvalidUserSettings)
+	}
+	if r.Signature != nil && utf8.RuneCountInString(*r.Signature) > maxSignatureLength {
+		return fmt.Errorf("%w: signature must not exceed %d characters", ErrInvalidUserSettings, maxSignatureLength)
+	}
+	if r.Locale != nil && strings.TrimSpace(*r.Locale) != "" {
+		if _, ok := i18n.Normalize(*r.Locale); !ok {
+			return fmt.Errorf("%w: unsupported locale %q, expected one of %s", ErrInvalidUserSettings, *r.Locale, strings.Join(i18n.Supported(), ", "))
+		}
+	}
+	if r.SyncConflictPolicy != nil && !models.IsSyncConflictPolicy(*r.SyncConflictPolicy) {
+		return fmt.Errorf("%w: unknown sync_conflict_policy %q, expected server_wins, local_wins or ask", ErrInvalidUserSettings, *r.SyncConflictPolicy)
+	}
+	return nil
+}
+
+// SettingsService 用户设置服务接口
+type SettingsService interface {
+	// GetSettings 获取用户设置，未设置的项为默认值
+	GetSettings(ctx context.Context, userID uint) (*UserSettings, error)
+
+	// UpdateSettings 修改用户设置，返回修改后的全部设置
+	UpdateSettings(ctx context.Context, userID uint, req *UpdateUserSettingsRequest) (*UserSettings, error)
+}
+
+// SettingsServiceImpl 用户设置服务实现
+type SettingsServiceImpl struct {
+	db *gorm.DB
+}
+
+// NewSettingsService 创建用户设置服务
+func NewSettingsService(db *gorm.DB) SettingsService {
+	return &SettingsServiceImpl{db: db}
+}
+
+// GetSettings 获取用户设置
+func (s *SettingsServiceImpl) GetSettings(ctx context.Context, userID uint) (*UserSettings, error) {
+	return loadUserSettings(ctx, s.db, userID)
+}
+
+// UpdateSettings 修改用户设置
+func (s *SettingsServiceImpl) UpdateSettings(ctx context.Context, userID uint, req *UpdateUserSettingsRequest) (*UserSettings, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
+	values := req.values()
+	if len(values) > 0 {
+		rows := make([]models.UserSetting, 0, len(values))
+		for key, value := range values {
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode setting %s: %w", key, err)
+			}
+			rows = append(rows, models.UserSetting{UserID: userID, Key: key, Value: string(encoded)})
+		}
+		if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "key"}},
+			DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+		}).Create(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to save settings: %w", err)
+		}
+	}
+
+	return loadUserSettings(ctx, s.db, userID)
+}
+
+// loadUserSettings 加载用户设置，无法解析的值记录日志并使用默认值
+func loadUserSettings(ctx context.Context, db *gorm.DB, userID uint) (*UserSettings, error) {
+	userSettingDefaultsMu.RLock()
+	settings := userSettingDefaults
+	userSettingDefaultsMu.RUnlock()
+
+	var rows []models.UserSetting
+	if err := db.WithContext(ctx).Where("user_id = ?", userID).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load settings: %w", err)
+	}
+
+	fields := settings.fields()
+	for _, row := range rows {
+		field, ok := fields[row.Key]
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal([]byte(row.Value), field); err != nil {
+			log.Printf("Ignoring invalid setting %s for user %d: %v", row.Key, userID, err)
+		}
+	}
+	return &settings, nil
+}
+
+// userSettingsOrDefault 加载用户设置，失败时记录日志并返回默认值，用于不应因设置读取失败而中断的流程
+func userSettingsOrDefault(ctx context.Context, db *gorm.DB, userID uint) *UserSettings {
+	settings, err := loadUserSettings(ctx, db, userID)
+	if err != nil {
+		log.Printf("Failed to load settings for user %d, using defaults: %v", userID, err)
+		userSettingDefaultsMu.RLock()
+		defaults := userSettingDefaults
+		userSettingDefaultsMu.RUnlock()
+		return &defaults
+	}
+	return settings
+}
+
+// normalizedLocale 规范化语言设置，为空或不支持时返回空字符串
+func normalizedLocale(tag string) string {
+	locale, _ := i18n.Normalize(tag)
+	return locale
+}
+
+// userLocale 用户的语言环境：优先使用用户设置，未设置时使用上下文中记录的请求语言
+func userLocale(ctx context.Context, db *gorm.DB, userID uint) string {
+	if locale := userSettingsOrDefault(ctx, db, userID).Locale; locale != "" {
+		return locale
+	}
+	return i18n.FromContext(ctx)
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"firemail/internal/config"
+	"firemail/internal/i18n"
+	"firemail/internal/models"
+	"firemail/internal/sse"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserSettingsDefaultsAndUpdates(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.UserSetting{}))
+	ctx := context.Background()
+
+	ConfigureUserSettingDefaults(config.UserDefaultsConfig{BlockRemoteImages: true, Timezone: "Asia/Shanghai", EmailsPerPage: 30})
+	t.Cleanup(func() {
+		ConfigureUserSettingDefaults(config.UserDefaultsConfig{BlockRemoteImages: true, Timezone: "UTC", EmailsPerPage: 20})
+	})
+	svc := NewSettingsService(env.db)
+
+	settings, err := svc.GetSettings(ctx, env.user.ID)
+	require.NoError(t, err)
+	require.Equal(t, UserSettings{BlockRemoteImages: true, Timezone: "Asia/Shanghai", EmailsPerPage: 30}, *settings)
+
+	replyAll, perPage, signature := true, 50, "Alice\nSupport"
+	settings, err = svc.UpdateSettings(ctx, env.user.ID, &UpdateUserSettingsRequest{
+		ReplyAll:      &replyAll,
+		EmailsPerPage: &perPage,
+		Signature:     &signature,
+	})
+	require.NoError(t, err)
+	require.True(t, settings.ReplyAll)
+	require.Equal(t, 50, settings.EmailsPerPage)
+	require.Equal(t, signature, settings.Signature)
+	require.Equal(t, "Asia/Shanghai", settings.Timezone)
+
+	// 再次修改时覆盖已有的值
+	perPage = 10
+	settings, err = svc.UpdateSettings(ctx, env.user.ID, &UpdateUserSettingsRequest{EmailsPerPage: &perPage})
+	require.NoError(t, err)
+	require.Equal(t, 10, settings.EmailsPerPage)
+	require.True(t, settings.ReplyAll)
+	var count int64
+	require.NoError(t, env.db.Model(&models.UserSetting{}).Where("user_id = ?", env.user.ID).Count(&count).Error)
+	require.Equal(t, int64(3), count)
+
+	badZone, badPerPage := "Mars/Olympus", 500
+	_, err = svc.UpdateSettings(ctx, env.user.ID, &UpdateUserSettingsRequest{Timezone: &badZone})
+	require.ErrorIs(t, err, ErrInvalidUserSettings)
+	_, err = svc.UpdateSettings(ctx, env.user.ID, &UpdateUserSettingsRequest{EmailsPerPage: &badPerPage})
+	require.ErrorIs(t, err, ErrInvalidUserSettings)
+
+	// 无法解析的值回退为默认值
+	require.NoError(t, env.db.Model(&models.UserSetting{}).
+		Where("user_id = ? AND key = ?", env.user.ID, models.SettingEmailsPerPage).
+		Update("value", `"many"`).Error)
+	settings, err = svc.GetSettings(ctx, env.user.ID)
+	require.NoError(t, err)
+	require.Equal(t, 30, settings.EmailsPerPage)
+}
+
+func TestUserLocaleSettingLocalizesNotifications(t *testing.T) {
+	env := setupOrganizationTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.UserSetting{}))
+	ctx := context.Background()
+	settingsSvc := NewSettingsService(env.db)
+
+	bad := "fr-FR"
+	_, err := settingsSvc.UpdateSettings(ctx, env.user.ID, &UpdateUserSettingsRequest{Locale: &bad})
+	require.ErrorIs(t, err, ErrInvalidUserSettings)
+
+	// 未设置时使用请求的语言，设置后以用户设置为准
+	require.Equal(t, i18n.ZhCN, userLocale(ctx, env.db, env.user.ID))
+	require.Equal(t, i18n.En, userLocale(i18n.WithLocale(ctx, i18n.En), env.db, env.user.ID))
+
+	alice := &models.User{Username: "alice", Password: "password123", Role: "user", IsActive: true}
+	require.NoError(t, env.db.Create(alice).Error)
+	english := "en_US"
+	settings, err := settingsSvc.UpdateSettings(ctx, alice.ID, &UpdateUserSettingsRequest{Locale: &english})
+	require.NoError(t, err)
+	require.Equal(t, i18n.En, settings.Locale)
+	require.Equal(t, i18n.En, userLocale(i18n.WithLocale(ctx, i18n.ZhCN), env.db, alice.ID))
+
+	_, err = env.svc.InviteMember(ctx, env.user.ID, env.org.ID, &InviteOrganizationMemberRequest{Username: "alice"})
+	require.NoError(t, err)
+	var notification *sse.NotificationEventData
+	for _, event := range env.publisher.events {
+		if event.Type == sse.EventNotification && event.UserID == alice.ID {
+			notification = event.Data.(*sse.NotificationEventData)
+		}
+	}
+	require.NotNil(t, notification)
+	require.Equal(t, "Organization invite", notification.Title)
+	require.Equal(t, `You have been invited to join organization "Support"`, notification.Message)
+}
+
+func TestQuotedContentUsesSignatureAndTimezone(t *testing.T) {
+	s := &EmailServiceImpl{}
+	original := &models.Email{
+		From:     "Bob <bob@example.com>",
+		Subject:  "Invoice",
+		Date:     time.Date(2026, 10, 1, 16, 30, 0, 0, time.UTC),
+		TextBody: "Please pay",
+		HTMLBody: "<p>Please pay</p>",
+	}
+	settings := &UserSettings{Timezone: "Asia/Shanghai", Signature: "Alice <Support>\nACME"}
+
+	quoted := s.buildQuotedContent(original, "Done", "<p>Done</p>", settings)
+	require.Contains(t, quoted.TextBody, "Done\n\n-- \nAlice <Support>\nACME\n\n--- Original Message ---")
+	require.Contains(t, quoted.TextBody, "Date: 2026-10-02 00:30:00")
+	require.Contains(t, quoted.HTMLBody, "-- <br>Alice &lt;Support&gt;<br>ACME</div>")
+
+	forwarded := s.buildForwardedContent(original, "FYI", "", &UserSettings{Timezone: "UTC"})
+	require.Contains(t, forwarded.TextBody, "FYI\n\n--- Forwarded Message ---")
+	require.Contains(t, forwarded.TextBody, "Date: 2026-10-01 16:30:00")
+}
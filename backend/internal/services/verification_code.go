@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"firemail/internal/models"
+	"firemail/internal/sanitize"
+	"firemail/internal/sse"
+)
+
+const (
+	// verificationCodeMaxAge 超过该时间的邮件不再发布验证码事件，避免首次同步时推送历史验证码
+	verificationCodeMaxAge = 30 * time.Minute
+	// verificationCodeDefaultMinutes 验证码列表默认查询的时间范围（分钟）
+	verificationCodeDefaultMinutes = 30
+	// verificationCodeMaxMinutes 验证码列表最大查询的时间范围（分钟）
+	verificationCodeMaxMinutes = 24 * 60
+	// verificationCodeScanLimit 验证码列表最多检查的邮件数量
+	verificationCodeScanLimit = 200
+	// verificationCodeBodyLimit 识别验证码时最多检查的正文长度
+	verificationCodeBodyLimit = 4000
+	// verificationCodeDistance 验证码与关键词之间允许的最大距离（字节）
+	verificationCodeDistance = 120
+)
+
+// verificationKeywords 验证码邮件的关键词，匹配时忽略大小写
+var verificationKeywords = []string{
+	"验证码", "校验码", "动态码", "动态密码", "确认码", "安全码", "验证代码",
+	"verification code", "security code", "confirmation code", "login code", "sign-in code",
+	"one-time", "passcode", "otp",
+}
+
+// verificationCodePattern 候选验证码：4到8位数字，或同时包含字母和数字的6到8位大写字母数字组合
+var verificationCodePattern = regexp.MustCompile(`\b([0-9]{4,8}|[A-Z0-9]{6,8})\b`)
+
+// yearPattern 年份形式的四位数字，作为验证码的可能性较低
+var yearPattern = regexp.MustCompile(`^(19|20)[0-9]{2}$`)
+
+// VerificationCodesRequest 最近验证码列表请求
+type VerificationCodesRequest struct {
+	AccountID *uint `json:"account_id"`
+	Minutes   int   `json:"minutes"`
+}
+
+// VerificationCode 邮件中识别出的验证码
+type VerificationCode struct {
+	EmailID   uint      `json:"email_id"`
+	AccountID uint      `json:"account_id"`
+	FolderID  *uint     `json:"folder_id,omitempty"`
+	Code      string    `json:"code"`
+	From      string    `json:"from"`
+	Subject   string    `json:"subject"`
+	Date      time.Time `json:"date"`
+}
+
+// detectVerificationCode 识别验证码：文本需包含验证码关键词，取距离关键词最近的候选，
+// 关键词之后的候选优先于之前的候选，没有识别出时返回空字符串
+func detectVerificationCode(subject, body string) string {
+	if len(body) > verificationCodeBodyLimit {
+		body = body[:verificationCodeBodyLimit]
+	}
+	text := subject + "\n" + body
+	lower := strings.ToLower(text)
+
+	type span struct{ start, end int }
+	var keywords []span
+	for _, keyword := range verificationKeywords {
+		for offset := 0; ; {
+			idx := strings.Index(lower[offset:], keyword)
+			if idx < 0 {
+				break
+			}
+			start := offset + idx
+			keywords = append(keywords, span{start, start + len(keyword)})
+			offset = start + len(keyword)
+		}
+	}
+	if len(keywords) == 0 {
+		return ""
+	}
+
+	best, bestScore := "", -1
+	for _, match := range verificationCodePattern.FindAllStringSubmatchIndex(text, -1) {
+		start, end := match[2], match[3]
+		candidate := text[start:end]
+		if !isVerificationCodeCandidate(candidate) {
+			continue
+		}
+		for _, keyword := range keywords {
+			score := -1
+			switch {
+			case start >= keyword.end && start-keyword.end <= verificationCodeDistance:
+				score = start - keyword.end
+			case end <= keyword.start && keyword.start-end <= verificationCodeDistance:
+				score = keyword.start - end + verificationCodeDistance
+			}
+			if score < 0 {
+				continue
+			}
+			if yearPattern.MatchString(candidate) {
+				score += 2 * verificationCodeDistance
+			}
+			if bestScore < 0 || score < bestScore {
+				best, bestScore = candidate, score
+			}
+		}
+	}
+	return best
+}
+
+// isVerificationCodeCandidate 候选必须包含数字，排除全是大写字母的单词
+func isVerificationCodeCandidate(candidate string) bool {
+	return strings.ContainsAny(candidate, "0123456789")
+}
+
+// emailVerificationCode 识别邮件中的验证码，没有纯文本正文时从HTML提取文本
+func emailVerificationCode(email *models.Email) string {
+	body := email.TextBody
+	if strings.TrimSpace(body) == "" && email.HTMLBody != "" {
+		body = sanitize.Text(email.HTMLBody)
+	}
+	return detectVerificationCode(email.Subject, body)
+}
+
+// publishVerificationCode 新邮件包含验证码时发布验证码事件；只处理最近收到的邮件
+func publishVerificationCode(ctx context.Context, publisher sse.EventPublisher, email *models.Email, userID uint) {
+	if publisher == nil || time.Since(email.Date) > verificationCodeMaxAge {
+		return
+	}
+	code := emailVerificationCode(email)
+	if code == "" {
+		return
+	}
+	if err := publisher.PublishToUser(ctx, userID, sse.NewVerificationCodeEvent(email, code, userID)); err != nil {
+		log.Printf("Failed to publish verification code event: %v", err)
+	}
+}
+
+// GetVerificationCodes 获取最近收到的邮件中的验证码，新邮件在前
+func (s *EmailServiceImpl) GetVerificationCodes(ctx context.Context, userID uint, req *VerificationCodesRequest) ([]VerificationCode, error) {
+	minutes := req.Minutes
+	if minutes <= 0 {
+		minutes = verificationCodeDefaultMinutes
+	}
+	if minutes > verificationCodeMaxMinutes {
+		minutes = verificationCodeMaxMinutes
+	}
+
+	query := s.db.WithContext(ctx).
+		Where("user_id = ? AND is_deleted = ? AND date >= ?", userID, false, time.Now().Add(-time.Duration(minutes)*time.Minute))
+	if req.AccountID != nil {
+		query = query.Where("account_id = ?", *req.AccountID)
+	}
+
+	var emails []*models.Email
+	if err := query.Preload("Body").Order("date DESC, id DESC").Limit(verificationCodeScanLimit).Find(&emails).Error; err != nil {
+		return nil, fmt.Errorf("failed to get recent emails: %w", err)
+	}
+
+	codes := make([]VerificationCode, 0)
+	for _, email := range emails {
+		code := emailVerificationCode(email)
+		if code == "" {
+			continue
+		}
+		codes = append(codes, VerificationCode{
+			EmailID:   email.ID,
+			AccountID: email.AccountID,
+			FolderID:  email.FolderID,
+			Code:      code,
+			From:      email.From,
+			Subject:   email.Subject,
+			Date:      email.Date,
+		})
+	}
+	return codes, nil
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"firemail/internal/models"
+	"firemail/internal/providers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectVerificationCode(t *testing.T) {
+	cases := []struct {
+		subject, body, want string
+	}{
+		{"【网易】验证码", "您的验证码为：382914，10分钟内有效。", "382914"},
+		{"Your sign-in code", "Use 5521 to sign in. This code expires in 2026.", "5521"},
+		{"428103 is your verification code", "", "428103"},
+		{"Confirm your account", "Your confirmation code: AB12CD. Order 20261017 shipped.", "AB12CD"},
+		{"会议纪要", "参会人数 12 人，会议室 3021。", ""},
+		{"Security code", "We noticed a new sign-in to your account.", ""},
+	}
+	for _, tc := range cases {
+		require.Equal(t, tc.want, detectVerificationCode(tc.subject, tc.body), tc.subject)
+	}
+}
+
+func TestGetVerificationCodes(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	ctx := context.Background()
+
+	code := env.createEmail(t, env.inbox, 1, "login", false, false)
+	require.NoError(t, env.db.Model(code).Update("user_id", env.user.ID).Error)
+	require.NoError(t, models.SaveEmailBody(env.db, code.ID, "您的登录验证码是 660218", "<p>hello</p>"))
+	plain := env.createEmail(t, env.inbox, 2, "hello", false, false)
+	require.NoError(t, env.db.Model(plain).Update("user_id", env.user.ID).Error)
+	old := env.createEmail(t, env.inbox, 3, "old", false, false)
+	require.NoError(t, env.db.Model(old).Updates(map[string]interface{}{
+		"user_id": env.user.ID,
+		"date":    time.Now().Add(-2 * time.Hour),
+	}).Error)
+	require.NoError(t, models.SaveEmailBody(env.db, old.ID, "验证码 111222", "<p>hello</p>"))
+
+	codes, err := env.service.GetVerificationCodes(ctx, env.user.ID, &VerificationCodesRequest{})
+	require.NoError(t, err)
+	require.Len(t, codes, 1)
+	require.Equal(t, code.ID, codes[0].EmailID)
+	require.Equal(t, "660218", codes[0].Code)
+
+	codes, err = env.service.GetVerificationCodes(ctx, env.user.ID, &VerificationCodesRequest{Minutes: 180})
+	require.NoError(t, err)
+	require.Len(t, codes, 2)
+}
+
+func TestNetEaseFolderQuirks(t *testing.T) {
+	quirks := providerQuirks("163")
+	require.NotNil(t, quirks)
+	require.Nil(t, providerQuirks("custom"))
+
+	folders := []models.Folder{{Path: "已发送"}, {Path: "订阅邮件"}, {Path: "草稿箱"}, {Path: "INBOX"}}
+	sortFoldersByPriority(folders, quirks)
+	require.Equal(t, []string{"INBOX", "订阅邮件", "已发送", "草稿箱"},
+		[]string{folders[0].Path, folders[1].Path, folders[2].Path, folders[3].Path})
+
+	infos := []*providers.FolderInfo{{Name: "病毒文件夹", Type: "custom"}, {Name: "订阅邮件", Type: "custom"}}
+	applyFolderQuirks(infos, quirks)
+	require.Equal(t, "spam", infos[0].Type)
+	require.Equal(t, "custom", infos[1].Type)
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/mail"
+	"strings"
+
+	"firemail/internal/models"
+	"firemail/internal/sse"
+
+	"gorm.io/gorm"
+)
+
+// AddVIPSenderRequest 添加VIP发件人请求
+type AddVIPSenderRequest struct {
+	Address string `json:"address" binding:"required"`
+	Name    string `json:"name" binding:"max=100"`
+}
+
+// vipSendersAvailable VIP发件人表是否存在
+func vipSendersAvailable(db *gorm.DB) bool {
+	return db.Migrator().HasTable(&models.VIPSender{})
+}
+
+// isVIPSender 发件人是否在用户的VIP列表中
+func isVIPSender(db *gorm.DB, userID uint, from string) bool {
+	sender := parseEmailAddress(from)
+	if sender == nil || sender.Address == "" || !vipSendersAvailable(db) {
+		return false
+	}
+
+	var count int64
+	if err := db.Model(&models.VIPSender{}).
+		Where("user_id = ? AND address = ?", userID, strings.ToLower(strings.TrimSpace(sender.Address))).
+		Count(&count).Error; err != nil {
+		log.Printf("Warning: failed to check VIP sender: %v", err)
+		return false
+	}
+	return count > 0
+}
+
+// ListVIPSenders 获取用户的VIP发件人列表
+func (s *EmailServiceImpl) ListVIPSenders(ctx context.Context, userID uint) ([]models.VIPSender, error) {
+	var senders []models.VIPSender
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("address ASC").
+		Find(&senders).Error; err != nil {
+		return nil, fmt.Errorf("failed to load VIP senders: %w", err)
+	}
+	return senders, nil
+}
+
+// AddVIPSender 添加VIP发件人，并标记该发件人已有的邮件
+func (s *EmailServiceImpl) AddVIPSender(ctx context.Context, userID uint, req *AddVIPSenderRequest) (*models.VIPSender, error) {
+	parsed, err := mail.ParseAddress(strings.TrimSpace(req.Address))
+	if err != nil {
+		return nil, fmt.Errorf("invalid email address")
+	}
+
+	sender := &models.VIPSender{
+		UserID:  userID,
+		Address: strings.ToLower(parsed.Address),
+		Name:    strings.TrimSpace(req.Name),
+	}
+	if sender.Name == "" {
+		sender.Name = parsed.Name
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&models.VIPSender{}).
+			Where("user_id = ? AND address = ?", userID, sender.Address).
+			Count(&existing).Error; err != nil {
+			return fmt.Errorf("failed to check VIP sender: %w", err)
+		}
+		if existing > 0 {
+			return fmt.Errorf("vip sender already exists")
+		}
+		if err := tx.Create(sender).Error; err != nil {
+			return fmt.Errorf("failed to create VIP sender: %w", err)
+		}
+		return s.setVIPEmails(tx, userID, sender.Address, true)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateEmailListCache(userID, 0, nil)
+	return sender, nil
+}
+
+// RemoveVIPSender 移除VIP发件人，并取消该发件人邮件的VIP标记
+func (s *EmailServiceImpl) RemoveVIPSender(ctx context.Context, userID, senderID uint) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var sender models.VIPSender
+		if err := tx.Where("id = ? AND user_id = ?", senderID, userID).First(&sender).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("vip sender not found")
+			}
+			return fmt.Errorf("failed to find VIP sender: %w", err)
+		}
+		if err := tx.Delete(&sender).Error; err != nil {
+			return fmt.Errorf("failed to delete VIP sender: %w", err)
+		}
+		return s.setVIPEmails(tx, userID, sender.Address, false)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateEmailListCache(userID, 0, nil)
+	return nil
+}
+
+// setVIPEmails 回填某发件人所有邮件的VIP标记
+func (s *EmailServiceImpl) setVIPEmails(tx *gorm.DB, userID uint, address string, isVIP bool) error {
+	if err := tx.Model(&models.Email{}).
+		Where("emails.user_id = ?", userID).
+		Where(senderAddressCondition, address, "%<"+escapeLike(address)+">").
+		Update("is_vip", isVIP).Error; err != nil {
+		return fmt.Errorf("failed to update VIP emails: %w", err)
+	}
+	return nil
+}
+
+// publishNewEmailNotification 发布新邮件事件；账户、会话或用户设置静音时事件标记为静默，
+// VIP发件人的邮件额外发布不受账户静音影响的高优先级通知，会话静音或用户静音全部通知时不发送
+func publishNewEmailNotification(ctx context.Context, db *gorm.DB, publisher sse.EventPublisher, account *models.EmailAccount, email *models.Email, userID uint, threadMuted, userMuted bool) {
+	if publisher == nil {
+		return
+	}
+
+	silent := account.NotificationsMuted || threadMuted || userMuted
+	vipAlert := email.IsVIP && !threadMuted && !userMuted
+
+	// 会弹出通知时附带快捷操作，db 为空或签发失败时只发布普通通知
+	var actions []sse.NotificationAction
+	if db != nil && (!silent || vipAlert) {
+		var err error
+		if actions, err = issueNotificationActions(ctx, db, userID, email.ID); err != nil {
+			log.Printf("Failed to issue notification actions: %v", err)
+		}
+	}
+
+	event := sse.NewNewEmailEvent(email, userID)
+	if data, ok := event.Data.(*sse.NewEmailEventData); ok {
+		data.Silent = silent
+		if !silent {
+			data.Actions = actions
+		}
+	}
+	if err := publisher.PublishToUser(ctx, userID, event); err != nil {
+		log.Printf("Failed to publish new email event: %v", err)
+	}
+
+	if vipAlert {
+		event := sse.NewVIPEmailEvent(email, userID)
+		if data, ok := event.Data.(*sse.NewEmailEventData); ok {
+			data.Actions = actions
+		}
+		if err := publisher.PublishToUser(ctx, userID, event); err != nil {
+			log.Printf("Failed to publish VIP email event: %v", err)
+		}
+	}
+}
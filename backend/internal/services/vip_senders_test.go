@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"firemail/internal/models"
+	"firemail/internal/sse"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVIPSendersMarkEmailsAndFilter(t *testing.T) {
+	env := setupEmailStateServiceTestEnv(t)
+	require.NoError(t, env.db.AutoMigrate(&models.VIPSender{}))
+	ctx := context.Background()
+
+	fromVIP := env.createEmail(t, env.inbox, 1, "Board meeting", false, false)
+	require.NoError(t, env.db.Model(fromVIP).Update("from_address", "The Boss <Boss@example.com>").Error)
+	env.createEmail(t, env.inbox, 2, "Newsletter", false, false)
+
+	sender, err := env.service.AddVIPSender(ctx, env.user.ID, &AddVIPSenderRequest{Address: "Boss <BOSS@example.com>"})
+	require.NoError(t, err)
+	require.Equal(t, "boss@example.com", sender.Address)
+	require.Equal(t, "Boss", sender.Name)
+
+	_, err = env.service.AddVIPSender(ctx, env.user.ID, &AddVIPSenderRequest{Address: "boss@example.com"})
+	require.EqualError(t, err, "vip sender already exists")
+	_, err = env.service.AddVIPSender(ctx, env.user.ID, &AddVIPSenderRequest{Address: "not an address"})
+	require.EqualError(t, err, "invalid email address")
+
+	isVIP := true
+	list, err := env.service.GetEmails(ctx, env.user.ID, &GetEmailsRequest{IsVIP: &isVIP})
+	require.NoError(t, err)
+	require.Len(t, list.Emails, 1)
+	require.Equal(t, fromVIP.ID, list.Emails[0].ID)
+
+	require.True(t, isVIPSender(env.db, env.user.ID, "boss@EXAMPLE.com"))
+	require.False(t, isVIPSender(env.db, env.user.ID+1, "boss@example.com"))
+
+	senders, err := env.service.ListVIPSenders(ctx, env.user.ID)
+	require.NoError(t, err)
+	require.Len(t, senders, 1)
+
+	require.EqualError(t, env.service.RemoveVIPSender(ctx, env.user.ID+1, sender.ID), "vip sender not found")
+	require.NoError(t, env.service.RemoveVIPSender(ctx, env.user.ID, sender.ID))
+
+	var reloaded models.Email
+	require.NoError(t, env.db.First(&reloaded, fromVIP.ID).Error)
+	require.False(t, reloaded.IsVIP)
+}
+
+func TestPublishNewEmailNotificationHonorsMuteExceptVIP(t *testing.T) {
+	ctx := context.Background()
+	publisher := &recordingEventPublisher{}
+	account := &models.EmailAccount{NotificationsMuted: true}
+
+	publishNewEmailNotification(ctx, nil, publisher, account, &models.Email{Subject: "regular"}, 1, false, false)
+	require.Len(t, publisher.events, 1)
+	require.Equal(t, sse.EventNewEmail, publisher.events[0].Type)
+	require.True(t, publisher.events[0].Data.(*sse.NewEmailEventData).Silent)
+
+	publisher.events = nil
+	publishNewEmailNotification(ctx, nil, publisher, account, &models.Email{Subject: "vip", IsVIP: true}, 1, false, false)
+	require.Len(t, publisher.events, 2)
+	vipEvent := publisher.events[1]
+	require.Equal(t, sse.EventVIPEmail, vipEvent.Type)
+	require.Equal(t, sse.PriorityUrgent, vipEvent.Priority)
+	data := vipEvent.Data.(*sse.NewEmailEventData)
+	require.True(t, data.IsVIP)
+	require.False(t, data.Silent)
+
+	// 用户设置静音全部通知时VIP邮件也不弹出
+	publisher.events = nil
+	publishNewEmailNotification(ctx, nil, publisher, &models.EmailAccount{}, &models.Email{Subject: "vip", IsVIP: true}, 1, false, true)
+	require.Len(t, publisher.events, 1)
+	require.True(t, publisher.events[0].Data.(*sse.NewEmailEventData).Silent)
+}
@@ -0,0 +1,72 @@
+package smtpd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+)
+
+// Hook 反垃圾邮件检查的扩展点，返回*Error时按其响应码拒绝，返回其他错误时临时拒绝
+type Hook interface {
+	// CheckConnection 客户端连接后、发送问候语前调用
+	CheckConnection(ctx context.Context, remoteIP net.IP) error
+
+	// CheckMessage 收到邮件内容后、投递前调用
+	CheckMessage(ctx context.Context, env *Envelope, data []byte) error
+}
+
+// Resolver DNS查询接口，便于测试替换
+type Resolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// DNSBLHook 连接时查询DNS黑名单，命中任一黑名单即拒绝连接
+type DNSBLHook struct {
+	Zones    []string
+	Resolver Resolver
+}
+
+// NewDNSBLHook 创建DNS黑名单检查，resolver为空时使用系统解析器
+func NewDNSBLHook(zones []string, resolver Resolver) *DNSBLHook {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	return &DNSBLHook{Zones: zones, Resolver: resolver}
+}
+
+// CheckConnection 查询客户端IPv4地址是否在黑名单中，回环和内网地址不检查
+func (h *DNSBLHook) CheckConnection(ctx context.Context, remoteIP net.IP) error {
+	ip := remoteIP.To4()
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() {
+		return nil
+	}
+
+	reversed := fmt.Sprintf("%d.%d.%d.%d", ip[3], ip[2], ip[1], ip[0])
+	for _, zone := range h.Zones {
+		zone = strings.Trim(strings.TrimSpace(zone), ".")
+		if zone == "" {
+			continue
+		}
+		addrs, err := h.Resolver.LookupHost(ctx, reversed+"."+zone)
+		if err != nil {
+			var dnsErr *net.DNSError
+			if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+				// 黑名单服务不可用时放行，避免因DNS故障拒收所有邮件
+				log.Printf("Warning: DNSBL lookup in %s failed for %s: %v", zone, remoteIP, err)
+			}
+			continue
+		}
+		if len(addrs) > 0 {
+			return &Error{Code: 554, EnhancedCode: "5.7.1", Message: fmt.Sprintf("Client host %s blocked using %s", remoteIP, zone)}
+		}
+	}
+	return nil
+}
+
+// CheckMessage DNS黑名单不检查邮件内容
+func (h *DNSBLHook) CheckMessage(ctx context.Context, env *Envelope, data []byte) error {
+	return nil
+}
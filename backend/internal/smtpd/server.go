@@ -0,0 +1,214 @@
+// Package smtpd 提供内置的SMTP收信服务器（MX模式）：只接收发往配置域名的邮件，不做中继，
+// 支持STARTTLS、大小和收件人数限制，以及连接和邮件内容检查的反垃圾邮件扩展点
+package smtpd
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceReserve 服务器在邮件开头追加的Return-Path和Received头的最大长度，投递方的大小上限需预留这部分
+const TraceReserve = 1024
+
+// ErrServerClosed 服务器已关闭
+var ErrServerClosed = errors.New("smtpd: server closed")
+
+// Envelope 一次投递的信封信息
+type Envelope struct {
+	ID         string   // 服务器分配的队列ID，出现在Received头和响应中
+	RemoteIP   net.IP   // 客户端IP
+	Helo       string   // 客户端在HELO/EHLO中声明的主机名
+	From       string   // MAIL FROM地址，退信为空
+	Recipients []string // RCPT TO地址，已转为小写
+	TLS        bool     // 是否通过STARTTLS加密
+}
+
+// Backend 邮件投递后端
+type Backend interface {
+	// ValidateRecipient 检查收件人是否存在，返回*Error时按其响应码拒绝
+	ValidateRecipient(ctx context.Context, address string) error
+
+	// Deliver 将邮件投递给信封中的全部收件人，data已包含服务器追加的跟踪头
+	Deliver(ctx context.Context, env *Envelope, data []byte) error
+}
+
+// Error 带SMTP响应码的错误
+type Error struct {
+	Code         int
+	EnhancedCode string
+	Message      string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s %s", e.Code, e.EnhancedCode, e.Message)
+}
+
+// Temporary 是否为4xx临时错误，客户端稍后会重试
+func (e *Error) Temporary() bool {
+	return e.Code >= 400 && e.Code < 500
+}
+
+// Config 服务器配置
+type Config struct {
+	Hostname        string        // 问候语和Received头中使用的主机名
+	Domains         []string      // 接收邮件的域名，其他域名的收件人一律拒绝
+	TLSConfig       *tls.Config   // 为空时不提供STARTTLS
+	RequireTLS      bool          // 要求先执行STARTTLS再投递
+	MaxMessageBytes int64         // 单封邮件的大小上限
+	MaxRecipients   int           // 单封邮件的收件人数上限
+	Timeout         time.Duration // 读取单条命令或邮件内容的超时时间
+	Hooks           []Hook        // 反垃圾邮件等检查，按顺序执行
+}
+
+// Server SMTP收信服务器
+type Server struct {
+	config  Config
+	backend Backend
+	domains map[string]bool
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	mutex     sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// NewServer 创建SMTP服务器
+func NewServer(config Config, backend Backend) *Server {
+	domains := make(map[string]bool, len(config.Domains))
+	for _, domain := range config.Domains {
+		domains[strings.ToLower(strings.TrimSpace(domain))] = true
+	}
+	if config.Hostname == "" {
+		config.Hostname = "localhost"
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Server{
+		config:    config,
+		backend:   backend,
+		domains:   domains,
+		ctx:       ctx,
+		cancel:    cancel,
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}
+}
+
+// Listen 在地址上监听并在后台接受连接，监听失败时立即返回错误
+func (s *Server) Listen(addr string) (net.Listener, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		if err := s.Serve(listener); err != nil && !errors.Is(err, ErrServerClosed) {
+			log.Printf("SMTP server on %s stopped: %v", addr, err)
+		}
+	}()
+	return listener, nil
+}
+
+// Serve 在监听器上接受连接，直到服务器关闭
+func (s *Server) Serve(listener net.Listener) error {
+	if !s.trackListener(listener, true) {
+		listener.Close()
+		return ErrServerClosed
+	}
+	defer s.trackListener(listener, false)
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if s.isClosed() {
+				return ErrServerClosed
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				time.Sleep(100 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+
+		if !s.trackConn(conn, true) {
+			conn.Close()
+			return ErrServerClosed
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer s.trackConn(conn, false)
+			newSession(s, conn).serve()
+		}()
+	}
+}
+
+// Close 关闭所有监听器和连接，并等待会话结束
+func (s *Server) Close() error {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.cancel()
+	for listener := range s.listeners {
+		listener.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mutex.Unlock()
+
+	s.wg.Wait()
+	return nil
+}
+
+// acceptsDomain 是否接收发往该域名的邮件
+func (s *Server) acceptsDomain(domain string) bool {
+	return s.domains[strings.ToLower(domain)]
+}
+
+func (s *Server) isClosed() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.closed
+}
+
+func (s *Server) trackListener(listener net.Listener, add bool) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if add {
+		if s.closed {
+			return false
+		}
+		s.listeners[listener] = struct{}{}
+	} else {
+		delete(s.listeners, listener)
+	}
+	return true
+}
+
+func (s *Server) trackConn(conn net.Conn, add bool) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if add {
+		if s.closed {
+			return false
+		}
+		s.conns[conn] = struct{}{}
+	} else {
+		delete(s.conns, conn)
+	}
+	return true
+}
@@ -0,0 +1,178 @@
+package smtpd
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testBackend struct {
+	mutex      sync.Mutex
+	mailboxes  map[string]bool
+	deliveries []testDelivery
+}
+
+type testDelivery struct {
+	env  *Envelope
+	data string
+}
+
+func (b *testBackend) ValidateRecipient(ctx context.Context, address string) error {
+	if !b.mailboxes[address] {
+		return &Error{Code: 550, EnhancedCode: "5.1.1", Message: "Mailbox unavailable"}
+	}
+	return nil
+}
+
+func (b *testBackend) Deliver(ctx context.Context, env *Envelope, data []byte) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.deliveries = append(b.deliveries, testDelivery{env: env, data: string(data)})
+	return nil
+}
+
+func startTestServer(t *testing.T, config Config) (string, *testBackend) {
+	t.Helper()
+
+	backend := &testBackend{mailboxes: map[string]bool{"support@example.com": true, "sales@example.com": true}}
+	config.Hostname = "mx.example.com"
+	config.Domains = []string{"Example.com"}
+	config.Timeout = 5 * time.Second
+	server := NewServer(config, backend)
+	listener, err := server.Listen("127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { server.Close() })
+	return listener.Addr().String(), backend
+}
+
+func requireSMTPCode(t *testing.T, err error, code int) {
+	t.Helper()
+	var protoErr *textproto.Error
+	require.ErrorAs(t, err, &protoErr)
+	require.Equal(t, code, protoErr.Code, protoErr.Msg)
+}
+
+func TestServerDeliversToLocalDomainOnly(t *testing.T) {
+	addr, backend := startTestServer(t, Config{MaxMessageBytes: 1024, MaxRecipients: 2})
+
+	client, err := smtp.Dial(addr)
+	require.NoError(t, err)
+	defer client.Close()
+	require.NoError(t, client.Hello("sender.test"))
+	ok, size := client.Extension("SIZE")
+	require.True(t, ok)
+	require.Equal(t, "1024", size)
+
+	require.NoError(t, client.Mail("alice@sender.test"))
+	requireSMTPCode(t, client.Rcpt("someone@elsewhere.test"), 550)
+	requireSMTPCode(t, client.Rcpt("nobody@example.com"), 550)
+	require.NoError(t, client.Rcpt("Support@Example.com"))
+	require.NoError(t, client.Rcpt("sales@example.com"))
+	requireSMTPCode(t, client.Rcpt("support@example.com"), 452)
+
+	w, err := client.Data()
+	require.NoError(t, err)
+	_, err = w.Write([]byte("From: alice@sender.test\r\nSubject: Hello\r\n\r\n.leading dot\r\nbody\r\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	// 超过大小上限的邮件被拒绝，连接仍可继续使用
+	require.NoError(t, client.Mail("alice@sender.test"))
+	require.NoError(t, client.Rcpt("support@example.com"))
+	w, err = client.Data()
+	require.NoError(t, err)
+	_, err = w.Write([]byte("Subject: Big\r\n\r\n" + strings.Repeat("x", 2048) + "\r\n"))
+	require.NoError(t, err)
+	requireSMTPCode(t, w.Close(), 552)
+	require.NoError(t, client.Quit())
+
+	backend.mutex.Lock()
+	defer backend.mutex.Unlock()
+	require.Len(t, backend.deliveries, 1)
+	delivery := backend.deliveries[0]
+	require.Equal(t, "alice@sender.test", delivery.env.From)
+	require.Equal(t, []string{"support@example.com", "sales@example.com"}, delivery.env.Recipients)
+	require.True(t, strings.HasPrefix(delivery.data, "Return-Path: <alice@sender.test>\nReceived: from sender.test (127.0.0.1)"))
+	require.Contains(t, delivery.data, "id "+delivery.env.ID)
+	require.Contains(t, delivery.data, "\n.leading dot\nbody\n")
+}
+
+func TestServerRequiresTLSWhenConfigured(t *testing.T) {
+	addr, backend := startTestServer(t, Config{TLSConfig: testTLSConfig(t), RequireTLS: true})
+
+	client, err := smtp.Dial(addr)
+	require.NoError(t, err)
+	defer client.Close()
+	require.NoError(t, client.Hello("sender.test"))
+	requireSMTPCode(t, client.Mail("alice@sender.test"), 530)
+
+	require.NoError(t, client.StartTLS(&tls.Config{InsecureSkipVerify: true}))
+	require.NoError(t, client.Mail("alice@sender.test"))
+	require.NoError(t, client.Rcpt("support@example.com"))
+	w, err := client.Data()
+	require.NoError(t, err)
+	_, err = w.Write([]byte("Subject: Secure\r\n\r\nhi\r\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, client.Quit())
+
+	backend.mutex.Lock()
+	defer backend.mutex.Unlock()
+	require.Len(t, backend.deliveries, 1)
+	require.True(t, backend.deliveries[0].env.TLS)
+	require.Contains(t, backend.deliveries[0].data, "with ESMTPS id")
+}
+
+type fakeResolver map[string][]string
+
+func (r fakeResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if addrs, ok := r[host]; ok {
+		return addrs, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func TestDNSBLHookRejectsListedClients(t *testing.T) {
+	hook := NewDNSBLHook([]string{"bl.test."}, fakeResolver{"4.3.2.1.bl.test": {"127.0.0.2"}})
+	ctx := context.Background()
+
+	err := hook.CheckConnection(ctx, net.ParseIP("1.2.3.4"))
+	var smtpErr *Error
+	require.ErrorAs(t, err, &smtpErr)
+	require.Equal(t, 554, smtpErr.Code)
+
+	require.NoError(t, hook.CheckConnection(ctx, net.ParseIP("5.6.7.8")))
+	require.NoError(t, hook.CheckConnection(ctx, net.ParseIP("127.0.0.1")))
+	require.NoError(t, hook.CheckConnection(ctx, net.ParseIP("2001:db8::1")))
+}
+
+func testTLSConfig(t *testing.T) *tls.Config {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "mx.example.com"},
+		DNSNames:     []string{"mx.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+}
@@ -0,0 +1,439 @@
+package smtpd
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxCommandLine 命令行的最大长度，RFC 5321要求至少512字节
+	maxCommandLine = 4096
+	// maxBadCommands 连续无效命令的上限，超过后断开连接
+	maxBadCommands = 10
+	// maxHeloLength HELO主机名和地址写入跟踪头时的最大长度
+	maxHeloLength = 255
+)
+
+var errLineTooLong = errors.New("smtpd: line too long")
+
+// errTemporary 后端返回非*Error错误时的响应
+var errTemporary = &Error{Code: 451, EnhancedCode: "4.3.0", Message: "Temporary failure, try again later"}
+
+// session 一个客户端连接的SMTP会话
+type session struct {
+	server   *Server
+	conn     net.Conn
+	reader   *bufio.Reader
+	writer   *bufio.Writer
+	remoteIP net.IP
+
+	helo        string
+	tls         bool
+	from        *string
+	recipients  []string
+	badCommands int
+}
+
+func newSession(server *Server, conn net.Conn) *session {
+	sess := &session{server: server}
+	if addr, ok := conn.RemoteAddr().(*net.TCPAddr); ok {
+		sess.remoteIP = addr.IP
+	}
+	sess.setConn(conn)
+	return sess
+}
+
+// setConn 设置会话的连接，STARTTLS后替换为加密连接
+func (sess *session) setConn(conn net.Conn) {
+	sess.conn = conn
+	sess.reader = bufio.NewReaderSize(&timeoutReader{conn: conn, timeout: sess.server.config.Timeout}, maxCommandLine)
+	sess.writer = bufio.NewWriter(conn)
+}
+
+func (sess *session) serve() {
+	defer func() { sess.conn.Close() }()
+
+	for _, hook := range sess.server.config.Hooks {
+		if err := hook.CheckConnection(sess.server.ctx, sess.remoteIP); err != nil {
+			sess.replyError(err)
+			return
+		}
+	}
+	sess.reply(220, "", sess.server.config.Hostname+" ESMTP FireMail")
+
+	for {
+		line, err := sess.readLine()
+		if err != nil {
+			if errors.Is(err, errLineTooLong) {
+				sess.reply(500, "5.5.6", "Line too long")
+			}
+			return
+		}
+
+		verb, arg := line, ""
+		if i := strings.IndexByte(line, ' '); i >= 0 {
+			verb, arg = line[:i], strings.TrimSpace(line[i+1:])
+		}
+		if !sess.handle(strings.ToUpper(verb), arg) {
+			return
+		}
+		if sess.badCommands >= maxBadCommands {
+			sess.reply(421, "4.7.0", "Too many errors, closing connection")
+			return
+		}
+	}
+}
+
+// handle 处理一条命令，返回false时关闭连接
+func (sess *session) handle(verb, arg string) bool {
+	switch verb {
+	case "HELO", "EHLO":
+		sess.handleHelo(verb, arg)
+	case "STARTTLS":
+		return sess.handleStartTLS()
+	case "MAIL":
+		sess.handleMail(arg)
+	case "RCPT":
+		sess.handleRcpt(arg)
+	case "DATA":
+		return sess.handleData()
+	case "RSET":
+		sess.reset()
+		sess.reply(250, "2.0.0", "OK")
+	case "NOOP":
+		sess.reply(250, "2.0.0", "OK")
+	case "VRFY":
+		sess.reply(252, "2.5.0", "Cannot VRFY user, but will accept message and attempt delivery")
+	case "QUIT":
+		sess.reply(221, "2.0.0", "Bye")
+		return false
+	default:
+		sess.badCommands++
+		sess.reply(500, "5.5.2", "Command not recognized")
+	}
+	return true
+}
+
+func (sess *session) handleHelo(verb, arg string) {
+	if arg == "" {
+		sess.badCommands++
+		sess.reply(501, "5.5.4", "Syntax: "+verb+" hostname")
+		return
+	}
+	sess.helo = truncate(arg, maxHeloLength)
+	sess.reset()
+
+	greeting := fmt.Sprintf("%s greets %s", sess.server.config.Hostname, sess.helo)
+	if verb == "HELO" {
+		sess.reply(250, "", greeting)
+		return
+	}
+
+	lines := []string{greeting, "PIPELINING", "8BITMIME", "ENHANCEDSTATUSCODES"}
+	if limit := sess.server.config.MaxMessageBytes; limit > 0 {
+		lines = append(lines, fmt.Sprintf("SIZE %d", limit))
+	}
+	if sess.server.config.TLSConfig != nil && !sess.tls {
+		lines = append(lines, "STARTTLS")
+	}
+	sess.replyLines(250, lines)
+}
+
+func (sess *session) handleStartTLS() bool {
+	if sess.server.config.TLSConfig == nil {
+		sess.badCommands++
+		sess.reply(502, "5.5.1", "STARTTLS not supported")
+		return true
+	}
+	if sess.tls {
+		sess.reply(503, "5.5.1", "Already running in TLS")
+		return true
+	}
+	if sess.reader.Buffered() > 0 {
+		// STARTTLS之后不允许流水线命令，防止明文注入到加密会话中
+		sess.reply(501, "5.5.4", "Pipelining after STARTTLS is not allowed")
+		return false
+	}
+
+	sess.reply(220, "2.0.0", "Ready to start TLS")
+	tlsConn := tls.Server(sess.conn, sess.server.config.TLSConfig)
+	if timeout := sess.server.config.Timeout; timeout > 0 {
+		tlsConn.SetDeadline(time.Now().Add(timeout))
+	}
+	if err := tlsConn.Handshake(); err != nil {
+		log.Printf("SMTP TLS handshake with %s failed: %v", sess.remoteIP, err)
+		return false
+	}
+	tlsConn.SetDeadline(time.Time{})
+
+	sess.setConn(tlsConn)
+	sess.tls = true
+	sess.helo = ""
+	sess.reset()
+	return true
+}
+
+func (sess *session) handleMail(arg string) {
+	if sess.helo == "" {
+		sess.reply(503, "5.5.1", "Send HELO/EHLO first")
+		return
+	}
+	if sess.server.config.RequireTLS && !sess.tls {
+		sess.reply(530, "5.7.0", "Must issue a STARTTLS command first")
+		return
+	}
+	if sess.from != nil {
+		sess.reply(503, "5.5.1", "Nested MAIL command")
+		return
+	}
+
+	address, params, ok := parsePath(arg, "FROM:")
+	if !ok {
+		sess.badCommands++
+		sess.reply(501, "5.5.4", "Syntax: MAIL FROM:<address>")
+		return
+	}
+	for _, param := range params {
+		key, value, _ := strings.Cut(param, "=")
+		if !strings.EqualFold(key, "SIZE") {
+			continue
+		}
+		size, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			sess.reply(501, "5.5.4", "Invalid SIZE parameter")
+			return
+		}
+		if limit := sess.server.config.MaxMessageBytes; limit > 0 && size > limit {
+			sess.reply(552, "5.3.4", "Message size exceeds fixed maximum message size")
+			return
+		}
+	}
+
+	sess.from = &address
+	sess.badCommands = 0
+	sess.reply(250, "2.1.0", "Sender OK")
+}
+
+func (sess *session) handleRcpt(arg string) {
+	if sess.from == nil {
+		sess.reply(503, "5.5.1", "Need MAIL command")
+		return
+	}
+
+	address, _, ok := parsePath(arg, "TO:")
+	at := strings.LastIndex(address, "@")
+	if !ok || at <= 0 || at == len(address)-1 {
+		sess.badCommands++
+		sess.reply(501, "5.1.3", "Bad recipient address syntax")
+		return
+	}
+	address = strings.ToLower(address)
+
+	if limit := sess.server.config.MaxRecipients; limit > 0 && len(sess.recipients) >= limit {
+		sess.reply(452, "4.5.3", "Too many recipients")
+		return
+	}
+	if !sess.server.acceptsDomain(address[at+1:]) {
+		sess.reply(550, "5.7.1", "Relaying denied")
+		return
+	}
+	if err := sess.server.backend.ValidateRecipient(sess.server.ctx, address); err != nil {
+		sess.replyError(err)
+		return
+	}
+
+	for _, existing := range sess.recipients {
+		if existing == address {
+			sess.reply(250, "2.1.5", "Recipient OK")
+			return
+		}
+	}
+	sess.recipients = append(sess.recipients, address)
+	sess.reply(250, "2.1.5", "Recipient OK")
+}
+
+func (sess *session) handleData() bool {
+	if len(sess.recipients) == 0 {
+		sess.reply(503, "5.5.1", "Need RCPT command")
+		return true
+	}
+	sess.reply(354, "", "End data with <CR><LF>.<CR><LF>")
+
+	dot := textproto.NewReader(sess.reader).DotReader()
+	var body io.Reader = dot
+	limit := sess.server.config.MaxMessageBytes
+	if limit > 0 {
+		body = io.LimitReader(dot, limit+1)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return false
+	}
+	if limit > 0 && int64(len(data)) > limit {
+		if _, err := io.Copy(io.Discard, dot); err != nil {
+			return false
+		}
+		sess.reset()
+		sess.reply(552, "5.3.4", "Message size exceeds fixed maximum message size")
+		return true
+	}
+
+	env := &Envelope{
+		ID:         newQueueID(),
+		RemoteIP:   sess.remoteIP,
+		Helo:       sess.helo,
+		From:       *sess.from,
+		Recipients: append([]string(nil), sess.recipients...),
+		TLS:        sess.tls,
+	}
+	sess.reset()
+
+	message := append([]byte(sess.traceHeaders(env)), data...)
+	if err := sess.deliver(env, message); err != nil {
+		sess.replyError(err)
+		return true
+	}
+	sess.reply(250, "2.0.0", "OK: queued as "+env.ID)
+	return true
+}
+
+// deliver 执行内容检查后投递邮件
+func (sess *session) deliver(env *Envelope, message []byte) error {
+	ctx := sess.server.ctx
+	for _, hook := range sess.server.config.Hooks {
+		if err := hook.CheckMessage(ctx, env, message); err != nil {
+			return err
+		}
+	}
+	return sess.server.backend.Deliver(ctx, env, message)
+}
+
+// traceHeaders 生成Return-Path和Received头，邮件内容的换行已由DotReader统一为LF
+func (sess *session) traceHeaders(env *Envelope) string {
+	protocol := "ESMTP"
+	if env.TLS {
+		protocol = "ESMTPS"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Return-Path: <%s>\n", truncate(env.From, maxHeloLength))
+	fmt.Fprintf(&b, "Received: from %s (%s)\n\tby %s (FireMail) with %s id %s",
+		env.Helo, env.RemoteIP, truncate(sess.server.config.Hostname, maxHeloLength), protocol, env.ID)
+	if len(env.Recipients) == 1 {
+		fmt.Fprintf(&b, "\n\tfor <%s>", truncate(env.Recipients[0], maxHeloLength))
+	}
+	fmt.Fprintf(&b, "; %s\n", time.Now().Format(time.RFC1123Z))
+	return b.String()
+}
+
+// reset 清空当前邮件事务
+func (sess *session) reset() {
+	sess.from = nil
+	sess.recipients = nil
+}
+
+// readLine 读取一行命令，超过长度上限时返回errLineTooLong
+func (sess *session) readLine() (string, error) {
+	line, err := sess.reader.ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) {
+		return "", errLineTooLong
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(line), "\r\n"), nil
+}
+
+// replyError 按错误回复，非*Error的错误记录日志并临时拒绝
+func (sess *session) replyError(err error) {
+	var smtpErr *Error
+	if !errors.As(err, &smtpErr) {
+		log.Printf("SMTP session with %s failed: %v", sess.remoteIP, err)
+		smtpErr = errTemporary
+	}
+	sess.reply(smtpErr.Code, smtpErr.EnhancedCode, smtpErr.Message)
+}
+
+func (sess *session) reply(code int, enhancedCode, message string) {
+	if enhancedCode != "" {
+		message = enhancedCode + " " + message
+	}
+	sess.replyLines(code, []string{message})
+}
+
+func (sess *session) replyLines(code int, lines []string) {
+	for i, line := range lines {
+		separator := "-"
+		if i == len(lines)-1 {
+			separator = " "
+		}
+		fmt.Fprintf(sess.writer, "%d%s%s\r\n", code, separator, line)
+	}
+	if timeout := sess.server.config.Timeout; timeout > 0 {
+		sess.conn.SetWriteDeadline(time.Now().Add(timeout))
+	}
+	sess.writer.Flush()
+}
+
+// parsePath 解析 FROM:<address> 或 TO:<address> 及其后的参数，忽略源路由
+func parsePath(arg, prefix string) (string, []string, bool) {
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return "", nil, false
+	}
+	arg = strings.TrimSpace(arg[len(prefix):])
+	if !strings.HasPrefix(arg, "<") {
+		return "", nil, false
+	}
+	end := strings.IndexByte(arg, '>')
+	if end < 0 {
+		return "", nil, false
+	}
+
+	address := arg[1:end]
+	if i := strings.IndexByte(address, ':'); i >= 0 && strings.HasPrefix(address, "@") {
+		address = address[i+1:]
+	}
+	if strings.ContainsAny(address, " \t<>") {
+		return "", nil, false
+	}
+	return address, strings.Fields(arg[end+1:]), true
+}
+
+// newQueueID 生成投递的队列ID
+func newQueueID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return strings.ToUpper(hex.EncodeToString(buf))
+}
+
+func truncate(value string, limit int) string {
+	if len(value) > limit {
+		return value[:limit]
+	}
+	return value
+}
+
+// timeoutReader 每次读取前刷新读超时，长邮件只要持续传输就不会超时
+type timeoutReader struct {
+	conn    net.Conn
+	timeout time.Duration
+}
+
+func (r *timeoutReader) Read(p []byte) (int, error) {
+	if r.timeout > 0 {
+		r.conn.SetReadDeadline(time.Now().Add(r.timeout))
+	}
+	return r.conn.Read(p)
+}
@@ -0,0 +1,160 @@
+package sse
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// EventBuffer 最近事件的环形缓冲区，供SSE重连补发和长轮询读取。
+// 序号从创建时的微秒时间戳开始递增，服务重启后旧游标一定早于新缓冲区中的事件，
+// 客户端据此得知中间可能有事件丢失
+type EventBuffer struct {
+	entries  []*Event
+	capacity int
+	next     int  // 下一个写入位置
+	full     bool // 缓冲区已写满，开始覆盖最旧的事件
+	head     uint64
+	notify   chan struct{} // 写入新事件时关闭并替换，唤醒等待中的长轮询
+	mutex    sync.Mutex
+}
+
+// EventBatch 游标之后的一批事件
+type EventBatch struct {
+	Events []*Event `json:"events"`
+	Cursor uint64   `json:"cursor"`          // 下次读取时传入的游标
+	Reset  bool     `json:"reset,omitempty"` // 游标早于缓冲区中最旧的事件或来自其他实例，之间的事件可能已丢失，客户端应重新加载数据
+}
+
+// NewEventBuffer 创建事件缓冲区，capacity 为保留的事件数
+func NewEventBuffer(capacity int) *EventBuffer {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &EventBuffer{
+		entries:  make([]*Event, capacity),
+		capacity: capacity,
+		head:     uint64(time.Now().UnixMicro()),
+		notify:   make(chan struct{}),
+	}
+}
+
+// Add 保存事件副本并为事件分配序号。心跳和服务关闭事件只对当前连接有意义，不保存
+func (b *EventBuffer) Add(event *Event) {
+	if event.Type == EventHeartbeat || event.Type == EventServerShutdown {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.head++
+	event.Seq = b.head
+	// 同一个事件对象可能被改写用户ID后再次发布，保存副本
+	stored := *event
+	b.entries[b.next] = &stored
+	b.next = (b.next + 1) % b.capacity
+	if b.next == 0 {
+		b.full = true
+	}
+
+	close(b.notify)
+	b.notify = make(chan struct{})
+}
+
+// Cursor 当前最新事件的序号
+func (b *EventBuffer) Cursor() uint64 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.head
+}
+
+// Since 读取游标之后发给用户且符合订阅条件的事件，最多 limit 条，limit 不大于0时不限制
+func (b *EventBuffer) Since(userID uint, since uint64, sub *Subscription, limit int) *EventBatch {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.collect(userID, since, sub, limit)
+}
+
+// Wait 读取游标之后的事件，没有时等待新事件，直到超时、上下文取消或 stop 关闭
+func (b *EventBuffer) Wait(ctx context.Context, userID uint, since uint64, sub *Subscription, limit int, timeout time.Duration, stop <-chan struct{}) *EventBatch {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		b.mutex.Lock()
+		batch := b.collect(userID, since, sub, limit)
+		notify := b.notify
+		b.mutex.Unlock()
+
+		if len(batch.Events) > 0 || batch.Reset {
+			return batch
+		}
+		// 新事件可能都不属于该用户，下一轮从已检查过的位置继续
+		since = batch.Cursor
+
+		select {
+		case <-notify:
+		case <-timer.C:
+			return batch
+		case <-ctx.Done():
+			return batch
+		case <-stop:
+			return batch
+		}
+	}
+}
+
+// collect 在持有锁时收集事件
+func (b *EventBuffer) collect(userID uint, since uint64, sub *Subscription, limit int) *EventBatch {
+	batch := &EventBatch{Events: make([]*Event, 0), Cursor: b.head}
+
+	count := b.next
+	start := 0
+	if b.full {
+		count = b.capacity
+		start = b.next
+	}
+	oldest := b.head - uint64(count) + 1
+
+	// 游标超出当前序号时来自其他实例或重启前，早于最旧事件时中间的事件已被覆盖
+	if since > b.head || since+1 < oldest {
+		batch.Reset = true
+		since = oldest - 1
+	}
+
+	for i := 0; i < count; i++ {
+		event := b.entries[(start+i)%b.capacity]
+		if event.Seq <= since {
+			continue
+		}
+		if event.UserID != 0 && event.UserID != userID {
+			continue
+		}
+		if !sub.Matches(event) {
+			continue
+		}
+		if limit > 0 && len(batch.Events) >= limit {
+			// 未返回的事件留到下次读取
+			batch.Cursor = batch.Events[len(batch.Events)-1].Seq
+			break
+		}
+		batch.Events = append(batch.Events, event)
+	}
+
+	return batch
+}
+
+// replayCursorKey 上下文中新连接需要补发的起始游标
+type replayCursorKey struct{}
+
+// WithReplayCursor 在建立连接的上下文中附带游标，HandleConnection 据此补发游标之后的事件
+func WithReplayCursor(ctx context.Context, since uint64) context.Context {
+	return context.WithValue(ctx, replayCursorKey{}, since)
+}
+
+// ReplayCursorFromContext 取出上下文中的补发游标
+func ReplayCursorFromContext(ctx context.Context) (uint64, bool) {
+	since, ok := ctx.Value(replayCursorKey{}).(uint64)
+	return since, ok
+}
@@ -0,0 +1,126 @@
+package sse
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventBufferSince(t *testing.T) {
+	buffer := NewEventBuffer(4)
+	start := buffer.Cursor()
+
+	buffer.Add(NewHeartbeatEvent(""))
+	assert.Equal(t, start, buffer.Cursor(), "心跳不进入缓冲区")
+
+	mine := NewSyncEvent(EventSyncStarted, 7, "work", 1)
+	buffer.Add(mine)
+	buffer.Add(NewNotificationEvent("Other", "other user", "info", 2))
+	buffer.Add(NewEmailSendEvent(EventEmailSendCompleted, "send-1", "email-1", 1))
+	assert.Equal(t, start+1, mine.Seq)
+
+	batch := buffer.Since(1, start, nil, 0)
+	require.Len(t, batch.Events, 2)
+	assert.Equal(t, EventSyncStarted, batch.Events[0].Type)
+	assert.Equal(t, EventEmailSendCompleted, batch.Events[1].Type)
+	assert.Equal(t, start+3, batch.Cursor)
+	assert.False(t, batch.Reset)
+
+	// 按订阅条件过滤，超过条数限制时游标停在最后返回的事件
+	sub, err := ParseSubscription("send", "")
+	require.NoError(t, err)
+	batch = buffer.Since(1, start, sub, 0)
+	require.Len(t, batch.Events, 1)
+	assert.Equal(t, EventEmailSendCompleted, batch.Events[0].Type)
+
+	batch = buffer.Since(1, start, nil, 1)
+	require.Len(t, batch.Events, 1)
+	assert.Equal(t, mine.Seq, batch.Cursor)
+
+	// 缓冲区写满后最旧的事件被覆盖，过旧的游标需要重置
+	buffer.Add(NewNotificationEvent("A", "a", "info", 1))
+	buffer.Add(NewNotificationEvent("B", "b", "info", 1))
+	batch = buffer.Since(1, start, nil, 0)
+	assert.True(t, batch.Reset)
+	require.Len(t, batch.Events, 3)
+	assert.Equal(t, EventEmailSendCompleted, batch.Events[0].Type)
+
+	// 来自其他实例或重启前的游标同样需要重置
+	batch = buffer.Since(1, buffer.Cursor()+100, nil, 0)
+	assert.True(t, batch.Reset)
+}
+
+func TestEventBufferWait(t *testing.T) {
+	buffer := NewEventBuffer(16)
+	cursor := buffer.Cursor()
+
+	// 没有事件时等到超时
+	started := time.Now()
+	batch := buffer.Wait(context.Background(), 1, cursor, nil, 0, 50*time.Millisecond, nil)
+	assert.Empty(t, batch.Events)
+	assert.Equal(t, cursor, batch.Cursor)
+	assert.GreaterOrEqual(t, time.Since(started), 50*time.Millisecond)
+
+	// 其他用户的事件不会唤醒，自己的事件到达后立即返回
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		buffer.Add(NewNotificationEvent("Other", "other user", "info", 2))
+		time.Sleep(20 * time.Millisecond)
+		buffer.Add(NewNotificationEvent("Mine", "mine", "info", 1))
+	}()
+	batch = buffer.Wait(context.Background(), 1, cursor, nil, 0, 5*time.Second, nil)
+	require.Len(t, batch.Events, 1)
+	assert.Equal(t, cursor+2, batch.Cursor)
+
+	// 服务停止时结束等待
+	stop := make(chan struct{})
+	close(stop)
+	batch = buffer.Wait(context.Background(), 1, batch.Cursor, nil, 0, 5*time.Second, stop)
+	assert.Empty(t, batch.Events)
+}
+
+func TestSSEServiceReplaysAndPollsBufferedEvents(t *testing.T) {
+	service := setupTestSSEService()
+	defer service.Stop()
+	ctx := context.Background()
+	const userID uint = 42
+
+	first := NewSyncEvent(EventSyncCompleted, 3, "work", userID)
+	require.NoError(t, service.PublishEvent(ctx, first))
+	second := NewNotificationEvent("Missed", "missed while offline", "info", userID)
+	require.NoError(t, service.PublishEvent(ctx, second))
+
+	// 长轮询立即返回游标之后已有的事件
+	since := first.Seq - 1
+	batch := service.PollEvents(ctx, userID, &since, nil, time.Second)
+	require.Len(t, batch.Events, 2)
+	assert.Equal(t, second.Seq, batch.Cursor)
+
+	// 不带游标时等待之后的新事件
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		service.PublishEvent(ctx, NewNotificationEvent("Later", "later", "info", userID))
+	}()
+	batch = service.PollEvents(ctx, userID, nil, nil, 5*time.Second)
+	require.Len(t, batch.Events, 1)
+	assert.Equal(t, "later", batch.Events[0].Data.(*NotificationEventData).Message)
+
+	// SSE重连时补发游标之后的事件，欢迎消息不进入缓冲区
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/sse?since=1", nil)
+	connCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	require.NoError(t, service.HandleConnection(WithReplayCursor(connCtx, first.Seq), userID, "replay", w, req.WithContext(connCtx)))
+
+	body := w.Body.String()
+	assert.Contains(t, body, "missed while offline")
+	assert.NotContains(t, body, "\"work\"")
+	assert.Contains(t, body, "later")
+	assert.Equal(t, 3, strings.Count(body, "event: notification"), "欢迎消息和补发的两条通知")
+	assert.Equal(t, second.Seq+1, service.buffer.Cursor(), "欢迎消息不进入缓冲区")
+}
@@ -17,6 +17,7 @@ type SSEConnection struct {
 	connectedAt  time.Time
 	lastActivity time.Time
 	closed       bool
+	subscription *Subscription // 为空时接收全部事件
 	mutex        sync.RWMutex
 }
 
@@ -86,6 +87,20 @@ func (c *SSEConnection) Send(data []byte) (err error) {
 	return nil
 }
 
+// SetSubscription 设置连接的订阅条件
+func (c *SSEConnection) SetSubscription(sub *Subscription) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.subscription = sub
+}
+
+// Accepts 事件是否符合连接的订阅条件
+func (c *SSEConnection) Accepts(event *Event) bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.subscription.Matches(event)
+}
+
 // Close 关闭连接
 func (c *SSEConnection) Close() error {
 	c.mutex.Lock()
@@ -285,6 +300,29 @@ func (cm *ConnectionManagerImpl) SendToUser(userID uint, data []byte) error {
 	return nil
 }
 
+// SendEventToUser 发送事件给指定用户订阅了该事件的连接
+func (cm *ConnectionManagerImpl) SendEventToUser(userID uint, event *Event, data []byte) error {
+	connections := cm.GetConnections(userID)
+
+	var errors []error
+	for _, conn := range connections {
+		if subscribed, ok := conn.(subscribedConnection); ok && !subscribed.Accepts(event) {
+			continue
+		}
+		if err := conn.Send(data); err != nil {
+			errors = append(errors, err)
+			// 发送失败时移除连接
+			cm.RemoveConnection(userID, conn.GetClientID())
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("failed to send to %d connections", len(errors))
+	}
+
+	return nil
+}
+
 // SendToConnection 发送消息给指定连接
 func (cm *ConnectionManagerImpl) SendToConnection(userID uint, clientID string, data []byte) error {
 	cm.mutex.RLock()
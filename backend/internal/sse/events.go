@@ -16,6 +16,8 @@ type EventType string
 const (
 	// 邮件相关事件
 	EventNewEmail                EventType = "new_email"
+	EventVIPEmail                EventType = "vip_email"
+	EventVerificationCode        EventType = "verification_code"
 	EventEmailRead               EventType = "email_read"
 	EventEmailUnread             EventType = "email_unread"
 	EventEmailDeleted            EventType = "email_deleted"
@@ -38,6 +40,12 @@ const (
 	EventSyncProgress  EventType = "sync_progress"
 	EventSyncCompleted EventType = "sync_completed"
 	EventSyncError     EventType = "sync_error"
+	EventSyncConflict  EventType = "sync_conflict"
+
+	// 邮箱迁移事件
+	EventMigrationProgress  EventType = "migration_progress"
+	EventMigrationCompleted EventType = "migration_completed"
+	EventMigrationFailed    EventType = "migration_failed"
 
 	// 账户相关事件
 	EventAccountConnected    EventType = "account_connected"
@@ -52,9 +60,14 @@ const (
 	EventGroupDefaultChanged EventType = "group_default_changed"
 	EventAccountGroupChanged EventType = "account_group_changed"
 
+	// 组织共享邮箱事件
+	EventEmailAssignmentChanged EventType = "email_assignment_changed"
+
 	// 系统事件
-	EventHeartbeat    EventType = "heartbeat"
-	EventNotification EventType = "notification"
+	EventHeartbeat        EventType = "heartbeat"
+	EventNotification     EventType = "notification"
+	EventNotificationRead EventType = "notification_read"
+	EventServerShutdown   EventType = "server_shutdown"
 )
 
 // EventPriority 事件优先级
@@ -77,19 +90,44 @@ type Event struct {
 	Priority  EventPriority `json:"priority"`
 	Timestamp time.Time     `json:"timestamp"`
 	Retry     *int          `json:"retry,omitempty"` // 重试间隔（毫秒）
+	Seq       uint64        `json:"seq,omitempty"`   // 事件缓冲区中的序号，作为重连补发和长轮询的游标
+
+	// 事件同时保存到通知中心时的通知ID，客户端可据此标记已读
+	NotificationID uint `json:"notification_id,omitempty"`
 }
 
 // NewEmailEventData 新邮件事件数据
 type NewEmailEventData struct {
-	EmailID       uint      `json:"email_id"`
-	AccountID     uint      `json:"account_id"`
-	FolderID      *uint     `json:"folder_id,omitempty"`
-	Subject       string    `json:"subject"`
-	From          string    `json:"from"`
-	Date          time.Time `json:"date"`
-	IsRead        bool      `json:"is_read"`
-	HasAttachment bool      `json:"has_attachment"`
-	Preview       string    `json:"preview,omitempty"` // 邮件预览文本
+	EmailID       uint                 `json:"email_id"`
+	AccountID     uint                 `json:"account_id"`
+	FolderID      *uint                `json:"folder_id,omitempty"`
+	Subject       string               `json:"subject"`
+	From          string               `json:"from"`
+	Date          time.Time            `json:"date"`
+	IsRead        bool                 `json:"is_read"`
+	HasAttachment bool                 `json:"has_attachment"`
+	Preview       string               `json:"preview,omitempty"` // 邮件预览文本
+	IsVIP         bool                 `json:"is_vip"`
+	Silent        bool                 `json:"silent,omitempty"`  // 账户已静音，客户端只刷新列表不弹出通知
+	Actions       []NotificationAction `json:"actions,omitempty"` // 通知上的快捷操作，静默事件不携带
+}
+
+// NotificationAction 通知快捷操作：POST 到 URL 即可执行，无需登录，令牌只能使用一次
+type NotificationAction struct {
+	Action    string    `json:"action"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// VerificationCodeEventData 新邮件中识别出的验证码
+type VerificationCodeEventData struct {
+	EmailID   uint      `json:"email_id"`
+	AccountID uint      `json:"account_id"`
+	FolderID  *uint     `json:"folder_id,omitempty"`
+	Code      string    `json:"code"`
+	From      string    `json:"from"`
+	Subject   string    `json:"subject"`
+	Date      time.Time `json:"date"`
 }
 
 // EmailStatusEventData 邮件状态变更事件数据
@@ -138,6 +176,31 @@ type SyncEventData struct {
 	ErrorMessage    string  `json:"error_message,omitempty"`
 }
 
+// SyncConflictEventData 同步冲突事件数据
+type SyncConflictEventData struct {
+	ConflictID uint     `json:"conflict_id"`
+	AccountID  uint     `json:"account_id"`
+	EmailID    uint     `json:"email_id"`
+	Fields     []string `json:"fields"`
+	Policy     string   `json:"policy"`
+	Status     string   `json:"status"`               // pending 表示等待用户选择
+	Resolution string   `json:"resolution,omitempty"` // 已按策略处理时为 server 或 local
+}
+
+// MigrationEventData 邮箱迁移事件数据
+type MigrationEventData struct {
+	MigrationID     uint    `json:"migration_id"`
+	SourceAccountID uint    `json:"source_account_id"`
+	TargetAccountID uint    `json:"target_account_id"`
+	Status          string  `json:"status"`
+	FolderPath      string  `json:"folder_path,omitempty"` // 正在复制的源文件夹
+	TotalEmails     int     `json:"total_emails"`
+	CopiedEmails    int     `json:"copied_emails"`
+	FailedEmails    int     `json:"failed_emails"`
+	Progress        float64 `json:"progress"` // 0.0-1.0
+	ErrorMessage    string  `json:"error_message,omitempty"`
+}
+
 // AccountEventData 账户事件数据
 type AccountEventData struct {
 	AccountID    uint   `json:"account_id"`
@@ -167,12 +230,29 @@ type AccountGroupEventData struct {
 	PreviousGroupID *uint  `json:"previous_group_id,omitempty"`
 }
 
+// EmailAssignmentEventData 共享邮箱邮件认领/分配变更事件数据
+type EmailAssignmentEventData struct {
+	SharedMailboxID uint  `json:"shared_mailbox_id"`
+	AccountID       uint  `json:"account_id"`
+	EmailID         uint  `json:"email_id"`
+	AssigneeID      *uint `json:"assignee_id,omitempty"` // 为空表示已释放
+	ChangedBy       uint  `json:"changed_by"`
+}
+
 // NotificationEventData 通知事件数据
 type NotificationEventData struct {
-	Title    string `json:"title"`
-	Message  string `json:"message"`
-	Type     string `json:"type"`               // info, success, warning, error
-	Duration *int   `json:"duration,omitempty"` // 显示时长（毫秒）
+	Title      string `json:"title"`
+	Message    string `json:"message"`
+	Type       string `json:"type"`                 // info, success, warning, error
+	Duration   *int   `json:"duration,omitempty"`   // 显示时长（毫秒）
+	Persistent bool   `json:"persistent,omitempty"` // 同时保存到通知中心，操作结果提示等临时通知不保存
+}
+
+// NotificationReadEventData 通知已读状态变更事件数据，其他客户端据此更新角标
+type NotificationReadEventData struct {
+	NotificationIDs []uint `json:"notification_ids,omitempty"` // 为空表示全部通知
+	IsRead          bool   `json:"is_read"`
+	UnreadCount     int64  `json:"unread_count"`
 }
 
 // HeartbeatEventData 心跳事件数据
@@ -241,6 +321,7 @@ func NewNewEmailEvent(email *models.Email, userID uint) *Event {
 		IsRead:        email.IsRead,
 		HasAttachment: email.HasAttachment,
 		Preview:       truncateText(email.TextBody, 100),
+		IsVIP:         email.IsVIP,
 	}
 
 	event := NewEvent(EventNewEmail, data, userID)
@@ -250,6 +331,34 @@ func NewNewEmailEvent(email *models.Email, userID uint) *Event {
 	return event
 }
 
+// NewVIPEmailEvent 创建VIP发件人新邮件通知事件，不受账户静音影响
+func NewVIPEmailEvent(email *models.Email, userID uint) *Event {
+	event := NewNewEmailEvent(email, userID)
+	event.Type = EventVIPEmail
+	event.Priority = PriorityUrgent
+
+	return event
+}
+
+// NewVerificationCodeEvent 创建验证码事件，客户端可直接展示或复制验证码
+func NewVerificationCodeEvent(email *models.Email, code string, userID uint) *Event {
+	data := &VerificationCodeEventData{
+		EmailID:   email.ID,
+		AccountID: email.AccountID,
+		FolderID:  email.FolderID,
+		Code:      code,
+		From:      email.From,
+		Subject:   email.Subject,
+		Date:      email.Date,
+	}
+
+	event := NewEvent(EventVerificationCode, data, userID)
+	event.AccountID = &email.AccountID
+	event.Priority = PriorityUrgent
+
+	return event
+}
+
 // NewEmailStatusEvent 创建邮件状态变更事件
 func NewEmailStatusEvent(emailID, accountID, userID uint, folderID *uint, isRead, isStarred, isImportant, isDeleted *bool, unreadDelta *int) *Event {
 	data := &EmailStatusEventData{
@@ -345,6 +454,32 @@ func NewSyncEvent(eventType EventType, accountID uint, accountName string, userI
 	return event
 }
 
+// NewSyncConflictEvent 创建同步冲突事件，需要用户选择时提高优先级
+func NewSyncConflictEvent(data *SyncConflictEventData, userID uint) *Event {
+	event := NewEvent(EventSyncConflict, data, userID)
+	event.AccountID = &data.AccountID
+	if data.Status == "pending" {
+		event.Priority = PriorityHigh
+	}
+	return event
+}
+
+// NewMigrationEvent 创建邮箱迁移事件
+func NewMigrationEvent(eventType EventType, data *MigrationEventData, userID uint) *Event {
+	event := NewEvent(eventType, data, userID)
+	if eventType != EventMigrationProgress {
+		event.Priority = PriorityHigh
+	}
+	return event
+}
+
+// NewEmailAssignmentEvent 创建共享邮箱邮件认领/分配变更事件
+func NewEmailAssignmentEvent(data *EmailAssignmentEventData, userID uint) *Event {
+	event := NewEvent(EventEmailAssignmentChanged, data, userID)
+	event.AccountID = &data.AccountID
+	return event
+}
+
 // NewNotificationEvent 创建通知事件
 func NewNotificationEvent(title, message, notificationType string, userID uint) *Event {
 	data := &NotificationEventData{
@@ -359,6 +494,18 @@ func NewNotificationEvent(title, message, notificationType string, userID uint)
 	return event
 }
 
+// NewPersistentNotificationEvent 创建同时保存到通知中心的通知事件，用户不在线时也能稍后查看
+func NewPersistentNotificationEvent(title, message, notificationType string, userID uint) *Event {
+	event := NewNotificationEvent(title, message, notificationType, userID)
+	event.Data.(*NotificationEventData).Persistent = true
+	return event
+}
+
+// NewNotificationReadEvent 创建通知已读状态变更事件
+func NewNotificationReadEvent(data *NotificationReadEventData, userID uint) *Event {
+	return NewEvent(EventNotificationRead, data, userID)
+}
+
 // NewHeartbeatEvent 创建心跳事件
 func NewHeartbeatEvent(clientID string) *Event {
 	data := &HeartbeatEventData{
@@ -377,6 +524,30 @@ func NewHeartbeatEvent(clientID string) *Event {
 	return event
 }
 
+// ServerShutdownEventData 服务关闭事件数据
+type ServerShutdownEventData struct {
+	Message        string `json:"message"`
+	ReconnectAfter int    `json:"reconnect_after"` // 建议的重连等待时间（毫秒）
+}
+
+// NewServerShutdownEvent 创建服务关闭事件，客户端应在 reconnectAfter 之后重连
+func NewServerShutdownEvent(reconnectAfter time.Duration) *Event {
+	retry := int(reconnectAfter / time.Millisecond)
+	data := &ServerShutdownEventData{
+		Message:        "服务器正在重启，稍后将自动重连",
+		ReconnectAfter: retry,
+	}
+
+	return &Event{
+		ID:        generateEventID(),
+		Type:      EventServerShutdown,
+		Data:      data,
+		Priority:  PriorityUrgent,
+		Timestamp: time.Now(),
+		Retry:     &retry,
+	}
+}
+
 // 辅助函数
 func generateEventID() string {
 	return fmt.Sprintf("%d_%d", time.Now().UnixNano(), rand.Intn(1000))
@@ -127,12 +127,21 @@ type SSEService interface {
 	
 	// Stop 停止SSE服务
 	Stop() error
+
+	// Shutdown 通知所有客户端服务即将关闭并停止服务
+	Shutdown() error
+
+	// Done 服务停止后关闭的通道，长连接据此结束
+	Done() <-chan struct{}
 	
 	// HandleConnection 处理新的SSE连接
 	HandleConnection(ctx context.Context, userID uint, clientID string, w http.ResponseWriter, r *http.Request) error
 	
 	// PublishEvent 发布事件
 	PublishEvent(ctx context.Context, event *Event) error
+
+	// PollEvents 长轮询读取游标之后的事件
+	PollEvents(ctx context.Context, userID uint, since *uint64, sub *Subscription, wait time.Duration) *EventBatch
 	
 	// GetStats 获取服务统计信息
 	GetStats() ServiceStats
@@ -15,6 +15,7 @@ type EventPublisherImpl struct {
 	connectionManager ConnectionManager
 	db                *gorm.DB
 	eventFilters      []EventFilter
+	buffer            *EventBuffer
 	stats             *PublisherStats
 	mutex             sync.RWMutex
 }
@@ -55,6 +56,11 @@ func (p *EventPublisherImpl) Publish(ctx context.Context, event *Event) error {
 		return nil
 	}
 
+	// 先写入缓冲区，推送的数据中带上序号
+	if p.buffer != nil {
+		p.buffer.Add(event)
+	}
+
 	// 转换为SSE格式
 	sseData, err := event.ToSSEFormat()
 	if err != nil {
@@ -64,10 +70,10 @@ func (p *EventPublisherImpl) Publish(ctx context.Context, event *Event) error {
 
 	// 发送给目标用户
 	if event.UserID > 0 {
-		err = p.connectionManager.SendToUser(event.UserID, sseData)
+		err = p.sendToUser(event.UserID, event, sseData)
 	} else {
 		// 如果没有指定用户，广播给所有用户
-		err = p.broadcastToAll(sseData)
+		err = p.broadcastToAll(event, sseData)
 	}
 
 	if err != nil {
@@ -99,7 +105,20 @@ func (p *EventPublisherImpl) PublishToAccount(ctx context.Context, accountID uin
 		return fmt.Errorf("event cannot be nil")
 	}
 
-	// 查询账户对应的用户ID
+	userID, err := p.accountUserID(accountID)
+	if err != nil {
+		return err
+	}
+
+	// 设置账户ID和用户ID
+	event.AccountID = &accountID
+	event.UserID = userID
+
+	return p.Publish(ctx, event)
+}
+
+// accountUserID 查询账户对应的用户ID
+func (p *EventPublisherImpl) accountUserID(accountID uint) (uint, error) {
 	var account struct {
 		UserID uint `gorm:"column:user_id"`
 	}
@@ -111,16 +130,12 @@ func (p *EventPublisherImpl) PublishToAccount(ctx context.Context, accountID uin
 
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return fmt.Errorf("account not found: %d", accountID)
+			return 0, fmt.Errorf("account not found: %d", accountID)
 		}
-		return fmt.Errorf("failed to query account: %w", err)
+		return 0, fmt.Errorf("failed to query account: %w", err)
 	}
 
-	// 设置账户ID和用户ID
-	event.AccountID = &accountID
-	event.UserID = account.UserID
-
-	return p.Publish(ctx, event)
+	return account.UserID, nil
 }
 
 // Broadcast 广播事件给所有连接的用户
@@ -135,6 +150,11 @@ func (p *EventPublisherImpl) Broadcast(ctx context.Context, event *Event) error
 	return p.Publish(ctx, event)
 }
 
+// SetEventBuffer 发布的事件同时写入缓冲区，供重连补发和长轮询读取
+func (p *EventPublisherImpl) SetEventBuffer(buffer *EventBuffer) {
+	p.buffer = buffer
+}
+
 // AddEventFilter 添加事件过滤器
 func (p *EventPublisherImpl) AddEventFilter(filter EventFilter) {
 	p.mutex.Lock()
@@ -200,13 +220,21 @@ func (p *EventPublisherImpl) shouldPublishEvent(event *Event) bool {
 	return true
 }
 
+// sendToUser 发送给用户的连接，连接管理器支持订阅过滤时跳过未订阅该事件的连接
+func (p *EventPublisherImpl) sendToUser(userID uint, event *Event, data []byte) error {
+	if sender, ok := p.connectionManager.(eventSender); ok {
+		return sender.SendEventToUser(userID, event, data)
+	}
+	return p.connectionManager.SendToUser(userID, data)
+}
+
 // broadcastToAll 广播给所有用户
-func (p *EventPublisherImpl) broadcastToAll(data []byte) error {
+func (p *EventPublisherImpl) broadcastToAll(event *Event, data []byte) error {
 	_, userConnections := p.connectionManager.GetConnectionCount()
 	
 	var errors []error
 	for userID := range userConnections {
-		if err := p.connectionManager.SendToUser(userID, data); err != nil {
+		if err := p.sendToUser(userID, event, data); err != nil {
 			errors = append(errors, fmt.Errorf("failed to send to user %d: %w", userID, err))
 		}
 	}
@@ -0,0 +1,153 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisEventMessage 通过Redis分发的事件消息
+type redisEventMessage struct {
+	Origin string `json:"origin"`
+	Event  *Event `json:"event"`
+}
+
+// RedisEventPublisher 通过Redis发布订阅在多个实例间分发事件。
+// 事件先推送给本实例的连接，再发布到Redis，由其他实例推送给各自的连接
+type RedisEventPublisher struct {
+	client     redis.UniversalClient
+	channel    string
+	local      *EventPublisherImpl
+	instanceID string
+
+	pubsub *redis.PubSub
+	done   chan struct{}
+	mutex  sync.Mutex
+}
+
+// NewRedisEventPublisher 创建基于Redis的事件发布器
+func NewRedisEventPublisher(client redis.UniversalClient, channel string, local *EventPublisherImpl) *RedisEventPublisher {
+	return &RedisEventPublisher{
+		client:     client,
+		channel:    channel,
+		local:      local,
+		instanceID: uuid.New().String(),
+	}
+}
+
+// Start 订阅Redis频道，将其他实例发布的事件推送给本实例的连接
+func (p *RedisEventPublisher) Start(ctx context.Context) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.pubsub != nil {
+		return nil
+	}
+
+	pubsub := p.client.Subscribe(ctx, p.channel)
+	// 等待订阅确认，确保启动后发布的事件不会丢失
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return fmt.Errorf("failed to subscribe to redis channel %s: %w", p.channel, err)
+	}
+
+	p.pubsub = pubsub
+	p.done = make(chan struct{})
+	go p.receive(pubsub.Channel(), p.done)
+
+	log.Printf("SSE redis fanout subscribed to %s", p.channel)
+	return nil
+}
+
+// Close 取消订阅
+func (p *RedisEventPublisher) Close() error {
+	p.mutex.Lock()
+	pubsub, done := p.pubsub, p.done
+	p.pubsub = nil
+	p.mutex.Unlock()
+
+	if pubsub == nil {
+		return nil
+	}
+	err := pubsub.Close()
+	<-done
+	return err
+}
+
+func (p *RedisEventPublisher) receive(messages <-chan *redis.Message, done chan struct{}) {
+	defer close(done)
+
+	for message := range messages {
+		var payload redisEventMessage
+		if err := json.Unmarshal([]byte(message.Payload), &payload); err != nil {
+			log.Printf("Failed to decode redis event message: %v", err)
+			continue
+		}
+		if payload.Origin == p.instanceID || payload.Event == nil {
+			continue
+		}
+		if err := p.local.Publish(context.Background(), payload.Event); err != nil {
+			log.Printf("Failed to deliver redis event %s: %v", payload.Event.ID, err)
+		}
+	}
+}
+
+// Publish 发布事件
+func (p *RedisEventPublisher) Publish(ctx context.Context, event *Event) error {
+	if event == nil {
+		return fmt.Errorf("event cannot be nil")
+	}
+
+	localErr := p.local.Publish(ctx, event)
+
+	data, err := json.Marshal(redisEventMessage{Origin: p.instanceID, Event: event})
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	if err := p.client.Publish(ctx, p.channel, data).Err(); err != nil {
+		return fmt.Errorf("failed to publish event to redis: %w", err)
+	}
+
+	return localErr
+}
+
+// PublishToUser 发布事件给指定用户
+func (p *RedisEventPublisher) PublishToUser(ctx context.Context, userID uint, event *Event) error {
+	if event == nil {
+		return fmt.Errorf("event cannot be nil")
+	}
+
+	event.UserID = userID
+	return p.Publish(ctx, event)
+}
+
+// PublishToAccount 发布事件给指定账户的用户
+func (p *RedisEventPublisher) PublishToAccount(ctx context.Context, accountID uint, event *Event) error {
+	if event == nil {
+		return fmt.Errorf("event cannot be nil")
+	}
+
+	userID, err := p.local.accountUserID(accountID)
+	if err != nil {
+		return err
+	}
+
+	event.AccountID = &accountID
+	event.UserID = userID
+	return p.Publish(ctx, event)
+}
+
+// Broadcast 广播事件给所有实例的所有连接
+func (p *RedisEventPublisher) Broadcast(ctx context.Context, event *Event) error {
+	if event == nil {
+		return fmt.Errorf("event cannot be nil")
+	}
+
+	event.UserID = 0
+	return p.Publish(ctx, event)
+}
@@ -0,0 +1,73 @@
+package sse
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncConnectionManager 供订阅协程并发写入的连接管理器
+type syncConnectionManager struct {
+	*MockConnectionManager
+	mutex sync.Mutex
+}
+
+func (m *syncConnectionManager) SendToUser(userID uint, data []byte) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.MockConnectionManager.SendToUser(userID, data)
+}
+
+func (m *syncConnectionManager) sentCount(userID uint) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return len(m.sentData[userID])
+}
+
+func newRedisTestPublisher(t *testing.T, server *miniredis.Miniredis) (*RedisEventPublisher, *syncConnectionManager) {
+	t.Helper()
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	manager := &syncConnectionManager{MockConnectionManager: NewMockConnectionManager()}
+	publisher := NewRedisEventPublisher(client, "firemail:events", NewEventPublisher(manager, nil))
+	require.NoError(t, publisher.Start(context.Background()))
+	t.Cleanup(func() { publisher.Close() })
+	return publisher, manager
+}
+
+func TestRedisEventPublisherFansOutAcrossInstances(t *testing.T) {
+	server := miniredis.RunT(t)
+	first, firstManager := newRedisTestPublisher(t, server)
+	_, secondManager := newRedisTestPublisher(t, server)
+
+	event := NewNotificationEvent("测试", "跨实例事件", "info", 1)
+	require.NoError(t, first.PublishToUser(context.Background(), 7, event))
+
+	// 本实例立即推送，其他实例通过订阅推送
+	assert.Equal(t, 1, firstManager.sentCount(7))
+	assert.Eventually(t, func() bool {
+		return secondManager.sentCount(7) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// 本实例不会重复处理自己发布的消息
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 1, firstManager.sentCount(7))
+}
+
+func TestRedisEventPublisherDeliversLocallyWhenRedisUnavailable(t *testing.T) {
+	server := miniredis.RunT(t)
+	publisher, manager := newRedisTestPublisher(t, server)
+	server.Close()
+
+	err := publisher.PublishToUser(context.Background(), 3, NewNotificationEvent("测试", "Redis不可用", "info", 3))
+	assert.Error(t, err)
+	assert.Equal(t, 1, manager.sentCount(3))
+}
@@ -8,8 +8,11 @@ import (
 	"sync"
 	"time"
 
+	"firemail/internal/i18n"
+
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
 
@@ -17,14 +20,24 @@ import (
 type SSEServiceImpl struct {
 	connectionManager ConnectionManager
 	eventPublisher    EventPublisher
+	localPublisher    *EventPublisherImpl  // 只推送给本实例的连接
+	fanout            *RedisEventPublisher // 启用Redis分发时不为空
+	buffer            *EventBuffer         // 本实例推送过的最近事件
 	db                *gorm.DB
 	config            *SSEConfig
 	stats             *ServiceStats
 	heartbeatTicker   *time.Ticker
 	stopChan          chan struct{}
+	stopped           bool
 	mutex             sync.RWMutex
 }
 
+// shutdownReconnectDelay 服务关闭时建议客户端的重连等待时间
+const shutdownReconnectDelay = 5 * time.Second
+
+// pollBatchLimit 一次长轮询最多返回的事件数
+const pollBatchLimit = 100
+
 // SSEConfig SSE配置
 type SSEConfig struct {
 	MaxConnectionsPerUser int           `json:"max_connections_per_user"`
@@ -60,10 +73,14 @@ func NewSSEService(db *gorm.DB, config *SSEConfig) *SSEServiceImpl {
 	)
 
 	eventPublisher := NewEventPublisher(connectionManager, db)
+	buffer := NewEventBuffer(config.BufferSize)
+	eventPublisher.SetEventBuffer(buffer)
 
 	return &SSEServiceImpl{
 		connectionManager: connectionManager,
 		eventPublisher:    eventPublisher,
+		localPublisher:    eventPublisher,
+		buffer:            buffer,
 		db:                db,
 		config:            config,
 		stats: &ServiceStats{
@@ -75,11 +92,26 @@ func NewSSEService(db *gorm.DB, config *SSEConfig) *SSEServiceImpl {
 	}
 }
 
+// EnableRedisFanout 通过Redis在多个实例间分发事件，需在获取事件发布器和Start之前调用
+func (s *SSEServiceImpl) EnableRedisFanout(client redis.UniversalClient, channel string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.fanout = NewRedisEventPublisher(client, channel, s.localPublisher)
+	s.eventPublisher = s.fanout
+}
+
 // Start 启动SSE服务
 func (s *SSEServiceImpl) Start(ctx context.Context) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
+	if s.fanout != nil {
+		if err := s.fanout.Start(ctx); err != nil {
+			return err
+		}
+	}
+
 	// 启动连接清理例程
 	if cm, ok := s.connectionManager.(*ConnectionManagerImpl); ok {
 		cm.StartCleanupRoutine()
@@ -99,6 +131,11 @@ func (s *SSEServiceImpl) Stop() error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
+	if s.stopped {
+		return nil
+	}
+	s.stopped = true
+
 	// 停止心跳
 	if s.heartbeatTicker != nil {
 		s.heartbeatTicker.Stop()
@@ -107,10 +144,33 @@ func (s *SSEServiceImpl) Stop() error {
 	// 发送停止信号
 	close(s.stopChan)
 
+	if s.fanout != nil {
+		if err := s.fanout.Close(); err != nil {
+			log.Printf("Failed to close SSE redis fanout: %v", err)
+		}
+	}
+
 	log.Println("SSE service stopped")
 	return nil
 }
 
+// Shutdown 通知本实例的所有连接服务即将关闭，然后停止服务，连接随之结束
+func (s *SSEServiceImpl) Shutdown() error {
+	event := NewServerShutdownEvent(shutdownReconnectDelay)
+	if err := s.localPublisher.Broadcast(context.Background(), event); err != nil {
+		log.Printf("Failed to notify SSE clients of shutdown: %v", err)
+	} else {
+		s.updateEventStats(event)
+	}
+
+	return s.Stop()
+}
+
+// Done 服务停止后关闭的通道
+func (s *SSEServiceImpl) Done() <-chan struct{} {
+	return s.stopChan
+}
+
 // HandleConnection 处理新的SSE连接
 func (s *SSEServiceImpl) HandleConnection(ctx context.Context, userID uint, clientID string, w http.ResponseWriter, r *http.Request) error {
 	// 创建SSE连接
@@ -118,6 +178,7 @@ func (s *SSEServiceImpl) HandleConnection(ctx context.Context, userID uint, clie
 	if err != nil {
 		return fmt.Errorf("failed to create SSE connection: %w", err)
 	}
+	conn.SetSubscription(SubscriptionFromContext(ctx))
 
 	// 添加到连接管理器
 	if err := s.connectionManager.AddConnection(userID, clientID, conn); err != nil {
@@ -128,15 +189,30 @@ func (s *SSEServiceImpl) HandleConnection(ctx context.Context, userID uint, clie
 	s.updateConnectionStats(userID, 1)
 
 	// 发送连接确认事件
+	locale := i18n.FromContext(ctx)
 	welcomeEvent := NewNotificationEvent(
-		"连接成功",
-		"SSE连接已建立，您将收到实时邮件通知",
+		i18n.T(locale, "Connected"),
+		i18n.T(locale, "SSE connection established, you will receive real-time email notifications"),
 		"success",
 		userID,
 	)
 
-	if err := s.PublishEvent(ctx, welcomeEvent); err != nil {
+	// 确认事件只发给新建立的连接，不进入缓冲区，也不需要跨实例分发
+	if err := s.sendToConnection(conn, welcomeEvent); err != nil {
 		log.Printf("Failed to send welcome event: %v", err)
+	} else {
+		s.updateEventStats(welcomeEvent)
+	}
+
+	// 补发游标之后的事件，与连接建立期间推送的事件可能重复，客户端按事件ID去重
+	if since, ok := ReplayCursorFromContext(ctx); ok {
+		batch := s.buffer.Since(userID, since, conn.subscription, 0)
+		for _, event := range batch.Events {
+			if err := s.sendToConnection(conn, event); err != nil {
+				log.Printf("Failed to replay event %s: %v", event.ID, err)
+				break
+			}
+		}
 	}
 
 	// 监听连接断开
@@ -157,6 +233,24 @@ func (s *SSEServiceImpl) PublishEvent(ctx context.Context, event *Event) error {
 	return nil
 }
 
+// PollEvents 长轮询：返回游标之后的事件，没有时最多等待 wait。since 为 nil 时从当前位置开始等待
+func (s *SSEServiceImpl) PollEvents(ctx context.Context, userID uint, since *uint64, sub *Subscription, wait time.Duration) *EventBatch {
+	cursor := s.buffer.Cursor()
+	if since != nil {
+		cursor = *since
+	}
+	return s.buffer.Wait(ctx, userID, cursor, sub, pollBatchLimit, wait, s.stopChan)
+}
+
+// sendToConnection 把事件直接发给单个连接
+func (s *SSEServiceImpl) sendToConnection(conn *SSEConnection, event *Event) error {
+	data, err := event.ToSSEFormat()
+	if err != nil {
+		return err
+	}
+	return conn.Send(data)
+}
+
 // GetStats 获取服务统计信息
 func (s *SSEServiceImpl) GetStats() ServiceStats {
 	s.mutex.RLock()
@@ -213,7 +307,7 @@ func (s *SSEServiceImpl) sendHeartbeat() {
 
 	for userID := range userConnections {
 		heartbeatEvent := NewHeartbeatEvent("")
-		if err := s.eventPublisher.PublishToUser(context.Background(), userID, heartbeatEvent); err != nil {
+		if err := s.localPublisher.PublishToUser(context.Background(), userID, heartbeatEvent); err != nil {
 			log.Printf("Failed to send heartbeat to user %d: %v", userID, err)
 		}
 	}
@@ -305,10 +399,12 @@ func SSEHandler(sseService SSEService) gin.HandlerFunc {
 			return
 		}
 
-		// 保持连接打开
+		// 保持连接打开，直到客户端断开或服务关闭
 		select {
 		case <-c.Request.Context().Done():
 			return
+		case <-sseService.Done():
+			return
 		}
 	}
 }
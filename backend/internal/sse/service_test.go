@@ -298,3 +298,45 @@ func TestSSEIntegration(t *testing.T) {
 		assert.Equal(t, 0, stats.TotalConnections)
 	})
 }
+
+func TestSSEServiceShutdown(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service := setupTestSSEService()
+	require.NoError(t, service.Start(context.Background()))
+
+	router := gin.New()
+	router.GET("/sse", func(c *gin.Context) {
+		c.Set("user_id", uint(42))
+		SSEHandler(service)(c)
+	})
+
+	req := httptest.NewRequest("GET", "/sse?client_id=shutdown-client", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	w := httptest.NewRecorder()
+
+	finished := make(chan struct{})
+	go func() {
+		router.ServeHTTP(w, req)
+		close(finished)
+	}()
+
+	require.Eventually(t, func() bool {
+		total, _ := service.connectionManager.GetConnectionCount()
+		return total == 1
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, service.Shutdown())
+
+	// 关闭后处理器返回，客户端收到关闭通知和重连间隔
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("SSE handler did not return after shutdown")
+	}
+	assert.Contains(t, w.Body.String(), "event: "+string(EventServerShutdown))
+	assert.Contains(t, w.Body.String(), "retry: 5000")
+
+	// 重复停止不会出错
+	assert.NoError(t, service.Stop())
+}
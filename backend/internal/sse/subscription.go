@@ -0,0 +1,169 @@
+package sse
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 事件类别，客户端按类别订阅，减少仪表盘类客户端收到的无关事件
+const (
+	CategoryNewEmail     = "new_email"    // 新邮件、VIP邮件和验证码
+	CategoryEmail        = "email"        // 邮件状态变更、移动和分配
+	CategorySync         = "sync"         // 同步开始、进度、完成、错误和冲突
+	CategorySend         = "send"         // 邮件发送状态
+	CategoryAccount      = "account"      // 账户连接状态和分组变化
+	CategoryMigration    = "migration"    // 邮箱迁移进度
+	CategoryNotification = "notification" // 通知
+)
+
+// eventCategories 各事件类型所属的类别
+var eventCategories = map[EventType]string{
+	EventNewEmail:         CategoryNewEmail,
+	EventVIPEmail:         CategoryNewEmail,
+	EventVerificationCode: CategoryNewEmail,
+
+	EventEmailRead:               CategoryEmail,
+	EventEmailUnread:             CategoryEmail,
+	EventEmailDeleted:            CategoryEmail,
+	EventEmailStarred:            CategoryEmail,
+	EventEmailUnstarred:          CategoryEmail,
+	EventEmailImportant:          CategoryEmail,
+	EventEmailUnimportant:        CategoryEmail,
+	EventEmailMoved:              CategoryEmail,
+	EventFolderReadStateChanged:  CategoryEmail,
+	EventAccountReadStateChanged: CategoryEmail,
+	EventEmailAssignmentChanged:  CategoryEmail,
+
+	EventSyncStarted:   CategorySync,
+	EventSyncProgress:  CategorySync,
+	EventSyncCompleted: CategorySync,
+	EventSyncError:     CategorySync,
+	EventSyncConflict:  CategorySync,
+
+	EventEmailSendStarted:   CategorySend,
+	EventEmailSendProgress:  CategorySend,
+	EventEmailSendCompleted: CategorySend,
+	EventEmailSendFailed:    CategorySend,
+
+	EventAccountConnected:    CategoryAccount,
+	EventAccountDisconnected: CategoryAccount,
+	EventAccountError:        CategoryAccount,
+	EventGroupCreated:        CategoryAccount,
+	EventGroupUpdated:        CategoryAccount,
+	EventGroupDeleted:        CategoryAccount,
+	EventGroupReordered:      CategoryAccount,
+	EventGroupDefaultChanged: CategoryAccount,
+	EventAccountGroupChanged: CategoryAccount,
+
+	EventMigrationProgress:  CategoryMigration,
+	EventMigrationCompleted: CategoryMigration,
+	EventMigrationFailed:    CategoryMigration,
+
+	EventNotification:     CategoryNotification,
+	EventNotificationRead: CategoryNotification,
+}
+
+// EventCategoryOf 事件类型所属的类别，未归类的类型返回空字符串
+func EventCategoryOf(eventType EventType) string {
+	return eventCategories[eventType]
+}
+
+// isKnownCategory 是否为已定义的类别
+func isKnownCategory(name string) bool {
+	for _, category := range eventCategories {
+		if category == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscription 连接的事件订阅条件，nil 表示接收全部事件
+type Subscription struct {
+	categories map[string]bool
+	types      map[EventType]bool
+	accountIDs map[uint]bool
+}
+
+// ParseSubscription 解析订阅参数。events 为逗号分隔的类别或事件类型，accountIDs 为逗号分隔的账户ID，
+// 两者都为空时返回 nil
+func ParseSubscription(events, accountIDs string) (*Subscription, error) {
+	sub := &Subscription{
+		categories: make(map[string]bool),
+		types:      make(map[EventType]bool),
+		accountIDs: make(map[uint]bool),
+	}
+
+	for _, name := range strings.Split(events, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch {
+		case name == "":
+		case isKnownCategory(name):
+			sub.categories[name] = true
+		case eventCategories[EventType(name)] != "":
+			sub.types[EventType(name)] = true
+		default:
+			return nil, fmt.Errorf("unknown event category or type %q", name)
+		}
+	}
+
+	for _, value := range strings.Split(accountIDs, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil || id == 0 {
+			return nil, fmt.Errorf("invalid account ID %q", value)
+		}
+		sub.accountIDs[uint(id)] = true
+	}
+
+	if len(sub.categories) == 0 && len(sub.types) == 0 && len(sub.accountIDs) == 0 {
+		return nil, nil
+	}
+	return sub, nil
+}
+
+// Matches 事件是否符合订阅条件。心跳和服务关闭事件维持连接本身，始终发送；
+// 账户过滤只作用于带账户ID的事件，发送状态、通知等不属于具体账户的事件不受影响
+func (s *Subscription) Matches(event *Event) bool {
+	if s == nil || event.Type == EventHeartbeat || event.Type == EventServerShutdown {
+		return true
+	}
+	if len(s.categories) > 0 || len(s.types) > 0 {
+		if !s.categories[EventCategoryOf(event.Type)] && !s.types[event.Type] {
+			return false
+		}
+	}
+	if len(s.accountIDs) > 0 && event.AccountID != nil && !s.accountIDs[*event.AccountID] {
+		return false
+	}
+	return true
+}
+
+// subscriptionKey 上下文中新连接的订阅条件
+type subscriptionKey struct{}
+
+// WithSubscription 在建立连接的上下文中附带订阅条件，HandleConnection 据此过滤该连接的事件
+func WithSubscription(ctx context.Context, sub *Subscription) context.Context {
+	return context.WithValue(ctx, subscriptionKey{}, sub)
+}
+
+// SubscriptionFromContext 取出上下文中的订阅条件，未设置时返回 nil
+func SubscriptionFromContext(ctx context.Context) *Subscription {
+	sub, _ := ctx.Value(subscriptionKey{}).(*Subscription)
+	return sub
+}
+
+// subscribedConnection 按订阅条件过滤事件的连接
+type subscribedConnection interface {
+	Accepts(event *Event) bool
+}
+
+// eventSender 按连接的订阅条件发送事件的连接管理器
+type eventSender interface {
+	SendEventToUser(userID uint, event *Event, data []byte) error
+}
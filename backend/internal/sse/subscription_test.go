@@ -0,0 +1,92 @@
+package sse
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// subscribedMockConnection 带订阅条件的模拟连接
+type subscribedMockConnection struct {
+	*MockClientConnection
+	subscription *Subscription
+}
+
+func (m *subscribedMockConnection) Accepts(event *Event) bool {
+	return m.subscription.Matches(event)
+}
+
+func TestParseSubscription(t *testing.T) {
+	sub, err := ParseSubscription("", "")
+	require.NoError(t, err)
+	assert.Nil(t, sub)
+
+	sub, err = ParseSubscription(" sync , email_send_failed,,", "3, 5")
+	require.NoError(t, err)
+	require.NotNil(t, sub)
+	assert.True(t, sub.categories[CategorySync])
+	assert.True(t, sub.types[EventEmailSendFailed])
+	assert.Equal(t, map[uint]bool{3: true, 5: true}, sub.accountIDs)
+
+	_, err = ParseSubscription("everything", "")
+	assert.Error(t, err)
+	_, err = ParseSubscription("", "abc")
+	assert.Error(t, err)
+	_, err = ParseSubscription("", "0")
+	assert.Error(t, err)
+}
+
+func TestSubscriptionMatches(t *testing.T) {
+	accountID := uint(3)
+	otherAccountID := uint(4)
+	syncEvent := NewSyncEvent(EventSyncCompleted, accountID, "Work", 1)
+	otherSyncEvent := NewSyncEvent(EventSyncCompleted, otherAccountID, "Home", 1)
+	sendEvent := NewEmailSendEvent(EventEmailSendCompleted, "send-1", "email-1", 1)
+	statusEvent := NewEmailStatusEvent(10, accountID, 1, nil, nil, nil, nil, nil, nil)
+
+	var all *Subscription
+	assert.True(t, all.Matches(statusEvent))
+
+	sub, err := ParseSubscription("sync,send", "3")
+	require.NoError(t, err)
+	assert.True(t, sub.Matches(syncEvent))
+	assert.False(t, sub.Matches(otherSyncEvent), "events of other accounts are filtered")
+	assert.True(t, sub.Matches(sendEvent), "events without an account are not filtered by account")
+	assert.False(t, sub.Matches(statusEvent), "unsubscribed categories are filtered")
+	assert.True(t, sub.Matches(NewHeartbeatEvent("")), "heartbeats are always delivered")
+	assert.True(t, sub.Matches(NewServerShutdownEvent(0)))
+
+	// 只按账户过滤时接收该账户的全部类别
+	sub, err = ParseSubscription("", "4")
+	require.NoError(t, err)
+	assert.True(t, sub.Matches(otherSyncEvent))
+	assert.False(t, sub.Matches(statusEvent))
+}
+
+func TestConnectionManagerSendsEventsBySubscription(t *testing.T) {
+	cm := NewConnectionManager(5, 0, 0)
+	syncOnly, err := ParseSubscription("sync", "")
+	require.NoError(t, err)
+
+	dashboard := &subscribedMockConnection{MockClientConnection: NewMockClientConnection("dashboard", 1), subscription: syncOnly}
+	full := NewMockClientConnection("full", 1)
+	require.NoError(t, cm.AddConnection(1, "dashboard", dashboard))
+	require.NoError(t, cm.AddConnection(1, "full", full))
+
+	publisher := NewEventPublisher(cm, nil)
+	require.NoError(t, publisher.Publish(context.Background(), NewSyncEvent(EventSyncStarted, 3, "Work", 1)))
+	require.NoError(t, publisher.Publish(context.Background(), NewEmailSendEvent(EventEmailSendCompleted, "send-1", "", 1)))
+
+	assert.Len(t, dashboard.GetSentData(), 1)
+	assert.Len(t, full.GetSentData(), 2)
+}
+
+func TestSubscriptionContext(t *testing.T) {
+	assert.Nil(t, SubscriptionFromContext(context.Background()))
+
+	sub, err := ParseSubscription("new_email", "")
+	require.NoError(t, err)
+	assert.Same(t, sub, SubscriptionFromContext(WithSubscription(context.Background(), sub)))
+}
@@ -0,0 +1,5825 @@
+// Code generated by firemail/cmd/openapi. DO NOT EDIT.
+
+// Package client 是根据OpenAPI文档生成的 FireMail API 客户端
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Client API客户端，Token为空时不发送Authorization头
+type Client struct {
+	BaseURL    string
+	Token      string
+	HTTPClient *http.Client
+}
+
+// New 创建客户端
+func New(baseURL, token string) *Client {
+	return &Client{BaseURL: strings.TrimRight(baseURL, "/"), Token: token, HTTPClient: http.DefaultClient}
+}
+
+// APIError 服务端返回的错误响应
+type APIError struct {
+	StatusCode int
+	Response   ErrorResponse
+}
+
+func (e *APIError) Error() string {
+	if e.Response.Message != "" {
+		return fmt.Sprintf("firemail: %d %s: %s", e.StatusCode, e.Response.Error, e.Response.Message)
+	}
+	return fmt.Sprintf("firemail: %d %s", e.StatusCode, e.Response.Error)
+}
+
+type requestBody struct {
+	reader      io.Reader
+	contentType string
+	err         error
+}
+
+func jsonBody(v interface{}) *requestBody {
+	data, err := json.Marshal(v)
+	return &requestBody{reader: bytes.NewReader(data), contentType: "application/json", err: err}
+}
+
+func multipartBody(form interface{}, filename string, file io.Reader) *requestBody {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	fields := map[string]interface{}{}
+	data, err := json.Marshal(form)
+	if err != nil {
+		return &requestBody{err: err}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		return &requestBody{err: err}
+	}
+	for name, value := range fields {
+		if err := writer.WriteField(name, fmt.Sprint(value)); err != nil {
+			return &requestBody{err: err}
+		}
+	}
+
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		return &requestBody{err: err}
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return &requestBody{err: err}
+	}
+	if err := writer.Close(); err != nil {
+		return &requestBody{err: err}
+	}
+	return &requestBody{reader: &buf, contentType: writer.FormDataContentType()}
+}
+
+func addQuery(query url.Values, name string, value interface{}) {
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			query.Add(name, fmt.Sprint(v.Index(i).Interface()))
+		}
+	default:
+		if t, ok := v.Interface().(time.Time); ok {
+			query.Set(name, t.Format(time.RFC3339))
+			return
+		}
+		query.Set(name, fmt.Sprint(v.Interface()))
+	}
+}
+
+func (c *Client) doRaw(ctx context.Context, method, path string, query url.Values, body *requestBody) (*http.Response, error) {
+	target := c.BaseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		if body.err != nil {
+			return nil, body.err
+		}
+		reader = body.reader
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", body.contentType)
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		if err := json.NewDecoder(resp.Body).Decode(&apiErr.Response); err != nil {
+			apiErr.Response.Error = http.StatusText(resp.StatusCode)
+		}
+		return nil, apiErr
+	}
+	return resp, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body *requestBody, out interface{}) error {
+	resp, err := c.doRaw(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Success bool            `json:"success"`
+		Data    json.RawMessage `json:"data"`
+		Message string          `json:"message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("firemail: decode response: %w", err)
+	}
+	if out == nil || len(envelope.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(envelope.Data, out)
+}
+
+// AccountChange 对应组件 AccountChange
+type AccountChange struct {
+	Deleted      bool       `json:"deleted,omitempty"`
+	ErrorMessage string     `json:"error_message,omitempty"`
+	ID           int64      `json:"id,omitempty"`
+	IsActive     bool       `json:"is_active,omitempty"`
+	LastSyncAt   *time.Time `json:"last_sync_at,omitempty"`
+	SyncStatus   string     `json:"sync_status,omitempty"`
+	UnreadEmails int64      `json:"unread_emails,omitempty"`
+}
+
+// AccountCountReport 对应组件 AccountCountReport
+type AccountCountReport struct {
+	AccountID    int64 `json:"account_id,omitempty"`
+	Fixed        bool  `json:"fixed,omitempty"`
+	StoredTotal  int64 `json:"stored_total,omitempty"`
+	StoredUnread int64 `json:"stored_unread,omitempty"`
+	Total        int64 `json:"total,omitempty"`
+	Unread       int64 `json:"unread,omitempty"`
+}
+
+// AccountSetupGuide 对应组件 AccountSetupGuide
+type AccountSetupGuide struct {
+	Code    string   `json:"code,omitempty"`
+	HelpURL string   `json:"help_url,omitempty"`
+	Message string   `json:"message,omitempty"`
+	Steps   []string `json:"steps,omitempty"`
+}
+
+// AddVIPSenderRequest 对应组件 AddVIPSenderRequest
+type AddVIPSenderRequest struct {
+	Address string `json:"address"`
+	Name    string `json:"name,omitempty"`
+}
+
+// AnalyticsBusiestHours 对应组件 AnalyticsBusiestHours
+type AnalyticsBusiestHours struct {
+	Hours    []*AnalyticsHourActivity    `json:"hours,omitempty"`
+	Tz       string                      `json:"tz,omitempty"`
+	Weekdays []*AnalyticsWeekdayActivity `json:"weekdays,omitempty"`
+}
+
+// AnalyticsHourActivity 对应组件 AnalyticsHourActivity
+type AnalyticsHourActivity struct {
+	Hour     int64 `json:"hour,omitempty"`
+	Received int64 `json:"received,omitempty"`
+	Sent     int64 `json:"sent,omitempty"`
+}
+
+// AnalyticsOutboundAccount 对应组件 AnalyticsOutboundAccount
+type AnalyticsOutboundAccount struct {
+	AccountID              int64   `json:"account_id,omitempty"`
+	AverageResponseSeconds int64   `json:"average_response_seconds,omitempty"`
+	Email                  string  `json:"email,omitempty"`
+	MedianResponseSeconds  int64   `json:"median_response_seconds,omitempty"`
+	Replied                int64   `json:"replied,omitempty"`
+	ReplyRate              float64 `json:"reply_rate,omitempty"`
+	Sent                   int64   `json:"sent,omitempty"`
+}
+
+// AnalyticsOutboundContact 对应组件 AnalyticsOutboundContact
+type AnalyticsOutboundContact struct {
+	Address                string     `json:"address,omitempty"`
+	AverageResponseSeconds int64      `json:"average_response_seconds,omitempty"`
+	LastRepliedAt          *time.Time `json:"last_replied_at,omitempty"`
+	LastSentAt             time.Time  `json:"last_sent_at,omitempty"`
+	MedianResponseSeconds  int64      `json:"median_response_seconds,omitempty"`
+	Name                   string     `json:"name,omitempty"`
+	Replied                int64      `json:"replied,omitempty"`
+	ReplyRate              float64    `json:"reply_rate,omitempty"`
+	Sent                   int64      `json:"sent,omitempty"`
+}
+
+// AnalyticsOutboundMessage 对应组件 AnalyticsOutboundMessage
+type AnalyticsOutboundMessage struct {
+	AccountID       int64      `json:"account_id,omitempty"`
+	EmailID         int64      `json:"email_id,omitempty"`
+	Recipients      []string   `json:"recipients,omitempty"`
+	RepliedAt       *time.Time `json:"replied_at,omitempty"`
+	RepliedBy       string     `json:"replied_by,omitempty"`
+	ReplyEmailID    *int64     `json:"reply_email_id,omitempty"`
+	ResponseSeconds *int64     `json:"response_seconds,omitempty"`
+	SentAt          time.Time  `json:"sent_at,omitempty"`
+	Subject         string     `json:"subject,omitempty"`
+}
+
+// AnalyticsRecipient 对应组件 AnalyticsRecipient
+type AnalyticsRecipient struct {
+	Address string    `json:"address,omitempty"`
+	Emails  int64     `json:"emails,omitempty"`
+	LastAt  time.Time `json:"last_at,omitempty"`
+	Name    string    `json:"name,omitempty"`
+}
+
+// AnalyticsResponseTimes 对应组件 AnalyticsResponseTimes
+type AnalyticsResponseTimes struct {
+	AverageSeconds int64 `json:"average_seconds,omitempty"`
+	MedianSeconds  int64 `json:"median_seconds,omitempty"`
+	Samples        int64 `json:"samples,omitempty"`
+	WithinDay      int64 `json:"within_day,omitempty"`
+	WithinHour     int64 `json:"within_hour,omitempty"`
+}
+
+// AnalyticsVolumePoint 对应组件 AnalyticsVolumePoint
+type AnalyticsVolumePoint struct {
+	Period   string `json:"period,omitempty"`
+	Received int64  `json:"received,omitempty"`
+	Sent     int64  `json:"sent,omitempty"`
+}
+
+// AnalyticsWeekdayActivity 对应组件 AnalyticsWeekdayActivity
+type AnalyticsWeekdayActivity struct {
+	Received int64 `json:"received,omitempty"`
+	Sent     int64 `json:"sent,omitempty"`
+	Weekday  int64 `json:"weekday,omitempty"`
+}
+
+// AppearanceRequest 对应组件 AppearanceRequest
+type AppearanceRequest struct {
+	Color     *string `json:"color,omitempty"`
+	Icon      *string `json:"icon,omitempty"`
+	SortOrder *int64  `json:"sort_order,omitempty"`
+}
+
+// AssignEmailRequest 对应组件 AssignEmailRequest
+type AssignEmailRequest struct {
+	AssigneeID int64 `json:"assignee_id"`
+}
+
+// Attachment 对应组件 Attachment
+type Attachment struct {
+	BlockReason  string     `json:"block_reason,omitempty"`
+	ContentID    string     `json:"content_id,omitempty"`
+	ContentType  string     `json:"content_type,omitempty"`
+	CreatedAt    time.Time  `json:"created_at,omitempty"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+	Disposition  string     `json:"disposition,omitempty"`
+	Email        *Email     `json:"email,omitempty"`
+	EmailID      *int64     `json:"email_id,omitempty"`
+	Encoding     string     `json:"encoding,omitempty"`
+	Filename     string     `json:"filename,omitempty"`
+	ID           int64      `json:"id,omitempty"`
+	IsBlocked    bool       `json:"is_blocked,omitempty"`
+	IsDownloaded bool       `json:"is_downloaded,omitempty"`
+	IsInline     bool       `json:"is_inline,omitempty"`
+	PartID       string     `json:"part_id,omitempty"`
+	Size         int64      `json:"size,omitempty"`
+	StoragePath  string     `json:"storage_path,omitempty"`
+	UpdatedAt    time.Time  `json:"updated_at,omitempty"`
+	UserID       *int64     `json:"user_id,omitempty"`
+}
+
+// AttachmentInfo 对应组件 AttachmentInfo
+type AttachmentInfo struct {
+	BlockReason  string `json:"block_reason,omitempty"`
+	ContentType  string `json:"content_type,omitempty"`
+	Disposition  string `json:"disposition,omitempty"`
+	DownloadURL  string `json:"download_url,omitempty"`
+	Filename     string `json:"filename,omitempty"`
+	ID           int64  `json:"id,omitempty"`
+	IsBlocked    bool   `json:"is_blocked,omitempty"`
+	IsDownloaded bool   `json:"is_downloaded,omitempty"`
+	IsInline     bool   `json:"is_inline,omitempty"`
+	Size         int64  `json:"size,omitempty"`
+}
+
+// AttachmentListItem 对应组件 AttachmentListItem
+type AttachmentListItem struct {
+	AccountID    int64     `json:"account_id,omitempty"`
+	ContentType  string    `json:"content_type,omitempty"`
+	Date         time.Time `json:"date,omitempty"`
+	EmailID      int64     `json:"email_id,omitempty"`
+	Filename     string    `json:"filename,omitempty"`
+	From         string    `json:"from,omitempty"`
+	ID           int64     `json:"id,omitempty"`
+	IsDownloaded bool      `json:"is_downloaded,omitempty"`
+	IsInline     bool      `json:"is_inline,omitempty"`
+	Size         int64     `json:"size,omitempty"`
+	Subject      string    `json:"subject,omitempty"`
+}
+
+// AttachmentListResponse 对应组件 AttachmentListResponse
+type AttachmentListResponse struct {
+	Attachments []*AttachmentListItem `json:"attachments,omitempty"`
+	Page        int64                 `json:"page,omitempty"`
+	PageSize    int64                 `json:"page_size,omitempty"`
+	Total       int64                 `json:"total,omitempty"`
+	TotalPages  int64                 `json:"total_pages,omitempty"`
+}
+
+// AttachmentPreview 对应组件 AttachmentPreview
+type AttachmentPreview struct {
+	AttachmentID int64  `json:"attachment_id,omitempty"`
+	Content      []byte `json:"content,omitempty"`
+	Error        string `json:"error,omitempty"`
+	Text         string `json:"text,omitempty"`
+	Thumbnail    []byte `json:"thumbnail,omitempty"`
+	Type         string `json:"type,omitempty"`
+}
+
+// AttachmentToken 对应组件 AttachmentToken
+type AttachmentToken struct {
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+	Token     string    `json:"token,omitempty"`
+	URL       string    `json:"url,omitempty"`
+}
+
+// AttachmentUploadResult 对应组件 AttachmentUploadResult
+type AttachmentUploadResult struct {
+	AttachmentID int64  `json:"attachment_id,omitempty"`
+	ContentType  string `json:"content_type,omitempty"`
+	Filename     string `json:"filename,omitempty"`
+	Size         int64  `json:"size,omitempty"`
+}
+
+// AuthFailureSummary 对应组件 AuthFailureSummary
+type AuthFailureSummary struct {
+	Failures   int64     `json:"failures,omitempty"`
+	FirstSeen  time.Time `json:"first_seen,omitempty"`
+	IP         string    `json:"ip,omitempty"`
+	LastSeen   time.Time `json:"last_seen,omitempty"`
+	SuggestBan bool      `json:"suggest_ban,omitempty"`
+	Usernames  []string  `json:"usernames,omitempty"`
+}
+
+// AuthFailuresResponse 对应组件 AuthFailuresResponse
+type AuthFailuresResponse struct {
+	Ips       []*AuthFailureSummary `json:"ips,omitempty"`
+	Threshold int64                 `json:"threshold,omitempty"`
+	Window    string                `json:"window,omitempty"`
+}
+
+// AuthenticationResult 对应组件 AuthenticationResult
+type AuthenticationResult struct {
+	Method     string            `json:"method,omitempty"`
+	Properties map[string]string `json:"properties,omitempty"`
+	Reason     string            `json:"reason,omitempty"`
+	Result     string            `json:"result,omitempty"`
+	Source     string            `json:"source,omitempty"`
+}
+
+// BatchAccountRequest 对应组件 BatchAccountRequest
+type BatchAccountRequest struct {
+	AccountIDs []int64 `json:"account_ids"`
+}
+
+// BatchEmailOperation 对应组件 BatchEmailOperation
+type BatchEmailOperation struct {
+	EmailIDs        []int64 `json:"email_ids"`
+	FolderID        *int64  `json:"folder_id,omitempty"`
+	Operation       string  `json:"operation"`
+	TargetAccountID *int64  `json:"target_account_id,omitempty"`
+	TargetFolderID  *int64  `json:"target_folder_id,omitempty"`
+}
+
+// BatchEmailResult 对应组件 BatchEmailResult
+type BatchEmailResult struct {
+	Errors       []string          `json:"errors,omitempty"`
+	Job          *EmailTransferJob `json:"job,omitempty"`
+	SuccessCount int64             `json:"success_count,omitempty"`
+	TotalCount   int64             `json:"total_count,omitempty"`
+}
+
+// BlockEmailSenderRequest 对应组件 BlockEmailSenderRequest
+type BlockEmailSenderRequest struct {
+	Action string `json:"action,omitempty"`
+	Domain bool   `json:"domain,omitempty"`
+}
+
+// BlockedSender 对应组件 BlockedSender
+type BlockedSender struct {
+	Action    string    `json:"action,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	ID        int64     `json:"id,omitempty"`
+	Pattern   string    `json:"pattern,omitempty"`
+}
+
+// BlockedSenderExport 对应组件 BlockedSenderExport
+type BlockedSenderExport struct {
+	Entries    []*BlockedSenderRequest `json:"entries,omitempty"`
+	ExportedAt time.Time               `json:"exported_at,omitempty"`
+}
+
+// BlockedSenderRequest 对应组件 BlockedSenderRequest
+type BlockedSenderRequest struct {
+	Action  string `json:"action,omitempty"`
+	Pattern string `json:"pattern"`
+}
+
+// CannedQuickItem 对应组件 CannedQuickItem
+type CannedQuickItem struct {
+	ID         int64  `json:"id,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Preview    string `json:"preview,omitempty"`
+	Shortcut   string `json:"shortcut,omitempty"`
+	Suggested  bool   `json:"suggested,omitempty"`
+	UsageCount int64  `json:"usage_count,omitempty"`
+}
+
+// CannedResponse 对应组件 CannedResponse
+type CannedResponse struct {
+	CreatedAt  time.Time  `json:"created_at,omitempty"`
+	FolderIDs  string     `json:"folder_ids,omitempty"`
+	HTMLBody   string     `json:"html_body,omitempty"`
+	ID         int64      `json:"id,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	Name       string     `json:"name,omitempty"`
+	Shortcut   string     `json:"shortcut,omitempty"`
+	TextBody   string     `json:"text_body,omitempty"`
+	UpdatedAt  time.Time  `json:"updated_at,omitempty"`
+	UsageCount int64      `json:"usage_count,omitempty"`
+}
+
+// CannedResponseRequest 对应组件 CannedResponseRequest
+type CannedResponseRequest struct {
+	FolderIDs []int64 `json:"folder_ids,omitempty"`
+	HTMLBody  string  `json:"html_body,omitempty"`
+	Name      string  `json:"name"`
+	Shortcut  string  `json:"shortcut,omitempty"`
+	TextBody  string  `json:"text_body"`
+}
+
+// ChangesResponse 对应组件 ChangesResponse
+type ChangesResponse struct {
+	Accounts []*AccountChange `json:"accounts,omitempty"`
+	Emails   *EmailChanges    `json:"emails,omitempty"`
+	Folders  []*FolderChange  `json:"folders,omitempty"`
+	HasMore  bool             `json:"has_more,omitempty"`
+	Reset    bool             `json:"reset,omitempty"`
+	Token    int64            `json:"token,omitempty"`
+}
+
+// ConfigView 对应组件 ConfigView
+type ConfigView struct {
+	ConfigFile string     `json:"config_file,omitempty"`
+	Settings   []*Setting `json:"settings,omitempty"`
+	Warnings   []string   `json:"warnings,omitempty"`
+}
+
+// CreateEmailAccountRequest 对应组件 CreateEmailAccountRequest
+type CreateEmailAccountRequest struct {
+	AuthMethod            string   `json:"auth_method"`
+	BounceAddress         string   `json:"bounce_address,omitempty"`
+	DnsResolver           string   `json:"dns_resolver,omitempty"`
+	EhloName              string   `json:"ehlo_name,omitempty"`
+	Email                 string   `json:"email"`
+	GroupID               *int64   `json:"group_id,omitempty"`
+	IMAPHost              string   `json:"imap_host,omitempty"`
+	IMAPPort              int64    `json:"imap_port,omitempty"`
+	IMAPSecurity          string   `json:"imap_security,omitempty"`
+	Mailer                string   `json:"mailer,omitempty"`
+	MessageIDDomain       string   `json:"message_id_domain,omitempty"`
+	Name                  string   `json:"name"`
+	Password              string   `json:"password,omitempty"`
+	Provider              string   `json:"provider,omitempty"`
+	SendAliases           []string `json:"send_aliases,omitempty"`
+	SMTPHost              string   `json:"smtp_host,omitempty"`
+	SMTPPort              int64    `json:"smtp_port,omitempty"`
+	SMTPSecurity          string   `json:"smtp_security,omitempty"`
+	TLSCaCert             *string  `json:"tls_ca_cert,omitempty"`
+	TLSInsecureSkipVerify *bool    `json:"tls_insecure_skip_verify,omitempty"`
+	TLSMinVersion         *string  `json:"tls_min_version,omitempty"`
+	TLSPinnedKeys         []string `json:"tls_pinned_keys,omitempty"`
+	Username              string   `json:"username,omitempty"`
+}
+
+// CreateEmailGroupRequest 对应组件 CreateEmailGroupRequest
+type CreateEmailGroupRequest struct {
+	Name string `json:"name"`
+}
+
+// CreateEmailNoteRequest 对应组件 CreateEmailNoteRequest
+type CreateEmailNoteRequest struct {
+	Content      string `json:"content"`
+	SyncToServer bool   `json:"sync_to_server,omitempty"`
+}
+
+// CreateEmailShareRequest 对应组件 CreateEmailShareRequest
+type CreateEmailShareRequest struct {
+	AllowAttachments bool  `json:"allow_attachments,omitempty"`
+	ExpiresInHours   int64 `json:"expires_in_hours,omitempty"`
+}
+
+// CreateEmailTemplateRequest 对应组件 CreateEmailTemplateRequest
+type CreateEmailTemplateRequest struct {
+	Category    string              `json:"category,omitempty"`
+	Description string              `json:"description,omitempty"`
+	HTMLBody    string              `json:"html_body,omitempty"`
+	IsShared    bool                `json:"is_shared,omitempty"`
+	Name        string              `json:"name"`
+	Subject     string              `json:"subject"`
+	Tags        []string            `json:"tags,omitempty"`
+	TextBody    string              `json:"text_body,omitempty"`
+	Variables   []*TemplateVariable `json:"variables,omitempty"`
+}
+
+// CreateFolderRequest 对应组件 CreateFolderRequest
+type CreateFolderRequest struct {
+	DisplayName string `json:"display_name,omitempty"`
+	Name        string `json:"name"`
+	ParentID    *int64 `json:"parent_id,omitempty"`
+}
+
+// CreateIngestEndpointRequest 对应组件 CreateIngestEndpointRequest
+type CreateIngestEndpointRequest struct {
+	AccountID *int64 `json:"account_id,omitempty"`
+	Address   string `json:"address,omitempty"`
+	FolderID  *int64 `json:"folder_id,omitempty"`
+	Name      string `json:"name"`
+}
+
+// CreateLegalHoldRequest 对应组件 CreateLegalHoldRequest
+type CreateLegalHoldRequest struct {
+	AccountIDs  []int64    `json:"account_ids,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Name        string     `json:"name"`
+	Senders     []string   `json:"senders,omitempty"`
+	Since       *time.Time `json:"since,omitempty"`
+	Until       *time.Time `json:"until,omitempty"`
+}
+
+// CreateMailboxMigrationRequest 对应组件 CreateMailboxMigrationRequest
+type CreateMailboxMigrationRequest struct {
+	Folders         []*MailboxMigrationFolderMapping `json:"folders,omitempty"`
+	SourceAccountID int64                            `json:"source_account_id"`
+	TargetAccountID int64                            `json:"target_account_id"`
+}
+
+// CreateManualOAuth2AccountRequest 对应组件 CreateManualOAuth2AccountRequest
+type CreateManualOAuth2AccountRequest struct {
+	AuthURL      string `json:"auth_url,omitempty"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret,omitempty"`
+	Email        string `json:"email"`
+	GroupID      *int64 `json:"group_id,omitempty"`
+	Name         string `json:"name"`
+	Provider     string `json:"provider"`
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope,omitempty"`
+	TokenURL     string `json:"token_url,omitempty"`
+}
+
+// CreateOAuth2AccountRequest 对应组件 CreateOAuth2AccountRequest
+type CreateOAuth2AccountRequest struct {
+	AccessToken  string `json:"access_token"`
+	ClientID     string `json:"client_id"`
+	Email        string `json:"email"`
+	ExpiresAt    int64  `json:"expires_at"`
+	GroupID      *int64 `json:"group_id,omitempty"`
+	Name         string `json:"name"`
+	Provider     string `json:"provider"`
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// CreateOrganizationRequest 对应组件 CreateOrganizationRequest
+type CreateOrganizationRequest struct {
+	Name string `json:"name"`
+}
+
+// DownloadAttachmentsZipRequest 对应组件 DownloadAttachmentsZipRequest
+type DownloadAttachmentsZipRequest struct {
+	AttachmentIDs []int64 `json:"attachment_ids"`
+}
+
+// DownloadProgress 对应组件 DownloadProgress
+type DownloadProgress struct {
+	AttachmentID int64      `json:"attachment_id,omitempty"`
+	BytesLoaded  int64      `json:"bytes_loaded,omitempty"`
+	BytesTotal   int64      `json:"bytes_total,omitempty"`
+	EndTime      *time.Time `json:"end_time,omitempty"`
+	Error        string     `json:"error,omitempty"`
+	Progress     float64    `json:"progress,omitempty"`
+	StartTime    time.Time  `json:"start_time,omitempty"`
+	Status       string     `json:"status,omitempty"`
+}
+
+// Draft 对应组件 Draft
+type Draft struct {
+	Account         *EmailAccount `json:"account,omitempty"`
+	AccountID       int64         `json:"account_id,omitempty"`
+	AttachmentIDs   string        `json:"attachment_ids,omitempty"`
+	BCC             string        `json:"bcc,omitempty"`
+	CC              string        `json:"cc,omitempty"`
+	CreatedAt       time.Time     `json:"created_at,omitempty"`
+	DeletedAt       *time.Time    `json:"deleted_at,omitempty"`
+	HTMLBody        string        `json:"html_body,omitempty"`
+	ID              int64         `json:"id,omitempty"`
+	IsTemplate      bool          `json:"is_template,omitempty"`
+	LastEditedAt    *time.Time    `json:"last_edited_at,omitempty"`
+	MarkdownBody    string        `json:"markdown_body,omitempty"`
+	Priority        string        `json:"priority,omitempty"`
+	RemoteFolder    string        `json:"remote_folder,omitempty"`
+	RemoteMessageID string        `json:"remote_message_id,omitempty"`
+	RemoteSyncedAt  *time.Time    `json:"remote_synced_at,omitempty"`
+	RemoteUID       int64         `json:"remote_uid,omitempty"`
+	Subject         string        `json:"subject,omitempty"`
+	TemplateName    string        `json:"template_name,omitempty"`
+	TextBody        string        `json:"text_body,omitempty"`
+	To              string        `json:"to,omitempty"`
+	UpdatedAt       time.Time     `json:"updated_at,omitempty"`
+	User            *User         `json:"user,omitempty"`
+	UserID          int64         `json:"user_id,omitempty"`
+}
+
+// DraftAutosaveResult 对应组件 DraftAutosaveResult
+type DraftAutosaveResult struct {
+	ID           int64      `json:"id,omitempty"`
+	LastEditedAt *time.Time `json:"last_edited_at,omitempty"`
+}
+
+// DraftRevision 对应组件 DraftRevision
+type DraftRevision struct {
+	AttachmentIDs string     `json:"attachment_ids,omitempty"`
+	BCC           string     `json:"bcc,omitempty"`
+	CC            string     `json:"cc,omitempty"`
+	CreatedAt     time.Time  `json:"created_at,omitempty"`
+	DeletedAt     *time.Time `json:"deleted_at,omitempty"`
+	DraftID       int64      `json:"draft_id,omitempty"`
+	HTMLBody      string     `json:"html_body,omitempty"`
+	ID            int64      `json:"id,omitempty"`
+	MarkdownBody  string     `json:"markdown_body,omitempty"`
+	Priority      string     `json:"priority,omitempty"`
+	Revision      int64      `json:"revision,omitempty"`
+	Source        string     `json:"source,omitempty"`
+	Subject       string     `json:"subject,omitempty"`
+	TextBody      string     `json:"text_body,omitempty"`
+	To            string     `json:"to,omitempty"`
+	UpdatedAt     time.Time  `json:"updated_at,omitempty"`
+	UserID        int64      `json:"user_id,omitempty"`
+}
+
+// DuplicateEmail 对应组件 DuplicateEmail
+type DuplicateEmail struct {
+	AccountID  int64     `json:"account_id,omitempty"`
+	Date       time.Time `json:"date,omitempty"`
+	FolderID   *int64    `json:"folder_id,omitempty"`
+	FolderName string    `json:"folder_name,omitempty"`
+	From       string    `json:"from,omitempty"`
+	ID         int64     `json:"id,omitempty"`
+	IsRead     bool      `json:"is_read,omitempty"`
+	IsStarred  bool      `json:"is_starred,omitempty"`
+	Size       int64     `json:"size,omitempty"`
+	Subject    string    `json:"subject,omitempty"`
+}
+
+// DuplicateEmailGroup 对应组件 DuplicateEmailGroup
+type DuplicateEmailGroup struct {
+	CanonicalID int64             `json:"canonical_id,omitempty"`
+	Emails      []*DuplicateEmail `json:"emails,omitempty"`
+	Fingerprint string            `json:"fingerprint,omitempty"`
+	MatchType   string            `json:"match_type,omitempty"`
+}
+
+// DuplicateResolution 对应组件 DuplicateResolution
+type DuplicateResolution struct {
+	CanonicalID int64  `json:"canonical_id,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+// DuplicateScanJob 对应组件 DuplicateScanJob
+type DuplicateScanJob struct {
+	DuplicateCount int64                  `json:"duplicate_count,omitempty"`
+	FinishedAt     *time.Time             `json:"finished_at,omitempty"`
+	GroupCount     int64                  `json:"group_count,omitempty"`
+	Groups         []*DuplicateEmailGroup `json:"groups,omitempty"`
+	ID             string                 `json:"id,omitempty"`
+	LastError      string                 `json:"last_error,omitempty"`
+	Scanned        int64                  `json:"scanned,omitempty"`
+	StartedAt      time.Time              `json:"started_at,omitempty"`
+	Status         string                 `json:"status,omitempty"`
+}
+
+// Email 对应组件 Email
+type Email struct {
+	Account             *EmailAccount `json:"account,omitempty"`
+	AccountID           int64         `json:"account_id,omitempty"`
+	AttachmentCount     int64         `json:"attachment_count,omitempty"`
+	Attachments         []*Attachment `json:"attachments,omitempty"`
+	BCC                 string        `json:"bcc,omitempty"`
+	CC                  string        `json:"cc,omitempty"`
+	CreatedAt           time.Time     `json:"created_at,omitempty"`
+	Date                time.Time     `json:"date,omitempty"`
+	DeletedAt           *time.Time    `json:"deleted_at,omitempty"`
+	FlagColor           string        `json:"flag_color,omitempty"`
+	Folder              *Folder       `json:"folder,omitempty"`
+	FolderID            *int64        `json:"folder_id,omitempty"`
+	FollowUpAt          *time.Time    `json:"follow_up_at,omitempty"`
+	FollowUpCompletedAt *time.Time    `json:"follow_up_completed_at,omitempty"`
+	From                string        `json:"from,omitempty"`
+	HasAttachment       bool          `json:"has_attachment,omitempty"`
+	HTMLBody            string        `json:"html_body,omitempty"`
+	ID                  int64         `json:"id,omitempty"`
+	ImportanceBucket    string        `json:"importance_bucket,omitempty"`
+	ImportanceManual    bool          `json:"importance_manual,omitempty"`
+	ImportanceScore     int64         `json:"importance_score,omitempty"`
+	IsAnswered          bool          `json:"is_answered,omitempty"`
+	IsDeleted           bool          `json:"is_deleted,omitempty"`
+	IsDraft             bool          `json:"is_draft,omitempty"`
+	IsFollowUp          bool          `json:"is_follow_up,omitempty"`
+	IsForwarded         bool          `json:"is_forwarded,omitempty"`
+	IsImportant         bool          `json:"is_important,omitempty"`
+	IsPinned            bool          `json:"is_pinned,omitempty"`
+	IsRead              bool          `json:"is_read,omitempty"`
+	IsSent              bool          `json:"is_sent,omitempty"`
+	IsStarred           bool          `json:"is_starred,omitempty"`
+	IsVip               bool          `json:"is_vip,omitempty"`
+	Labels              string        `json:"labels,omitempty"`
+	MessageID           string        `json:"message_id,omitempty"`
+	Notes               []*EmailNote  `json:"notes,omitempty"`
+	PinnedAt            *time.Time    `json:"pinned_at,omitempty"`
+	Preview             *string       `json:"preview,omitempty"`
+	Priority            string        `json:"priority,omitempty"`
+	ReplyLaterAt        *time.Time    `json:"reply_later_at,omitempty"`
+	ReplyLaterRemindAt  *time.Time    `json:"reply_later_remind_at,omitempty"`
+	ReplyTo             string        `json:"reply_to,omitempty"`
+	SenderAvatarHash    string        `json:"sender_avatar_hash,omitempty"`
+	SenderInitials      string        `json:"sender_initials,omitempty"`
+	Size                int64         `json:"size,omitempty"`
+	Subject             string        `json:"subject,omitempty"`
+	SyncedAt            *time.Time    `json:"synced_at,omitempty"`
+	TextBody            string        `json:"text_body,omitempty"`
+	ThreadID            string        `json:"thread_id,omitempty"`
+	To                  string        `json:"to,omitempty"`
+	TrackersRemoved     int64         `json:"trackers_removed,omitempty"`
+	TrashedAt           *time.Time    `json:"trashed_at,omitempty"`
+	UID                 int64         `json:"uid,omitempty"`
+	UpdatedAt           time.Time     `json:"updated_at,omitempty"`
+	Version             int64         `json:"version,omitempty"`
+}
+
+// EmailAccount 对应组件 EmailAccount
+type EmailAccount struct {
+	AuthMethod            string             `json:"auth_method,omitempty"`
+	AvatarEmoji           string             `json:"avatar_emoji,omitempty"`
+	AvatarInitials        string             `json:"avatar_initials,omitempty"`
+	BounceAddress         string             `json:"bounce_address,omitempty"`
+	Color                 string             `json:"color,omitempty"`
+	CreatedAt             time.Time          `json:"created_at,omitempty"`
+	DeletedAt             *time.Time         `json:"deleted_at,omitempty"`
+	DisplayOrder          int64              `json:"display_order,omitempty"`
+	DnsResolver           string             `json:"dns_resolver,omitempty"`
+	EhloName              string             `json:"ehlo_name,omitempty"`
+	Email                 string             `json:"email,omitempty"`
+	Emails                []*Email           `json:"emails,omitempty"`
+	ErrorMessage          string             `json:"error_message,omitempty"`
+	Folders               []*Folder          `json:"folders,omitempty"`
+	Group                 *EmailGroup        `json:"group,omitempty"`
+	GroupID               *int64             `json:"group_id,omitempty"`
+	ID                    int64              `json:"id,omitempty"`
+	IMAPHost              string             `json:"imap_host,omitempty"`
+	IMAPPort              int64              `json:"imap_port,omitempty"`
+	IMAPSecurity          string             `json:"imap_security,omitempty"`
+	IsActive              bool               `json:"is_active,omitempty"`
+	LastSyncAt            *time.Time         `json:"last_sync_at,omitempty"`
+	Mailer                string             `json:"mailer,omitempty"`
+	MaxPartSize           int64              `json:"max_part_size,omitempty"`
+	MessageIDDomain       string             `json:"message_id_domain,omitempty"`
+	Name                  string             `json:"name,omitempty"`
+	Nickname              string             `json:"nickname,omitempty"`
+	NotificationsMuted    bool               `json:"notifications_muted,omitempty"`
+	Provider              string             `json:"provider,omitempty"`
+	SendAliases           string             `json:"send_aliases,omitempty"`
+	SetupGuide            *AccountSetupGuide `json:"setup_guide,omitempty"`
+	SMTPHost              string             `json:"smtp_host,omitempty"`
+	SMTPPort              int64              `json:"smtp_port,omitempty"`
+	SMTPSecurity          string             `json:"smtp_security,omitempty"`
+	SyncPaused            bool               `json:"sync_paused,omitempty"`
+	SyncPausedAt          *time.Time         `json:"sync_paused_at,omitempty"`
+	SyncStatus            string             `json:"sync_status,omitempty"`
+	TLSCaCert             string             `json:"tls_ca_cert,omitempty"`
+	TLSInsecureSkipVerify bool               `json:"tls_insecure_skip_verify,omitempty"`
+	TLSMinVersion         string             `json:"tls_min_version,omitempty"`
+	TLSPinnedKeys         string             `json:"tls_pinned_keys,omitempty"`
+	TLSWarning            string             `json:"tls_warning,omitempty"`
+	TotalEmails           int64              `json:"total_emails,omitempty"`
+	UnreadEmails          int64              `json:"unread_emails,omitempty"`
+	UpdatedAt             time.Time          `json:"updated_at,omitempty"`
+	User                  *User              `json:"user,omitempty"`
+	UserID                int64              `json:"user_id,omitempty"`
+	Username              string             `json:"username,omitempty"`
+	Version               int64              `json:"version,omitempty"`
+}
+
+// EmailAddress 对应组件 EmailAddress
+type EmailAddress struct {
+	Address string `json:"address,omitempty"`
+	Name    string `json:"name,omitempty"`
+}
+
+// EmailAssignment 对应组件 EmailAssignment
+type EmailAssignment struct {
+	AssignedBy      int64     `json:"assigned_by,omitempty"`
+	AssigneeID      int64     `json:"assignee_id,omitempty"`
+	CreatedAt       time.Time `json:"created_at,omitempty"`
+	EmailID         int64     `json:"email_id,omitempty"`
+	ID              int64     `json:"id,omitempty"`
+	SharedMailboxID int64     `json:"shared_mailbox_id,omitempty"`
+	UpdatedAt       time.Time `json:"updated_at,omitempty"`
+}
+
+// EmailAttachment 对应组件 EmailAttachment
+type EmailAttachment struct {
+	ContentType string `json:"content_type,omitempty"`
+	Data        []byte `json:"data,omitempty"`
+	Encoding    string `json:"encoding,omitempty"`
+	Filename    string `json:"filename"`
+	Size        int64  `json:"size,omitempty"`
+}
+
+// EmailAvatar 对应组件 EmailAvatar
+type EmailAvatar struct {
+	GravatarHash string `json:"gravatar_hash,omitempty"`
+	Initials     string `json:"initials,omitempty"`
+}
+
+// EmailChanges 对应组件 EmailChanges
+type EmailChanges struct {
+	Created []int64 `json:"created,omitempty"`
+	Deleted []int64 `json:"deleted,omitempty"`
+	Updated []int64 `json:"updated,omitempty"`
+}
+
+// EmailGroup 对应组件 EmailGroup
+type EmailGroup struct {
+	AccountCount int64           `json:"account_count,omitempty"`
+	Accounts     []*EmailAccount `json:"accounts,omitempty"`
+	CreatedAt    time.Time       `json:"created_at,omitempty"`
+	DeletedAt    *time.Time      `json:"deleted_at,omitempty"`
+	ID           int64           `json:"id,omitempty"`
+	IsDefault    bool            `json:"is_default,omitempty"`
+	Name         string          `json:"name,omitempty"`
+	SortOrder    int64           `json:"sort_order,omitempty"`
+	SystemKey    *string         `json:"system_key,omitempty"`
+	UpdatedAt    time.Time       `json:"updated_at,omitempty"`
+	UserID       int64           `json:"user_id,omitempty"`
+}
+
+// EmailHeaderField 对应组件 EmailHeaderField
+type EmailHeaderField struct {
+	Name  string `json:"name,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+// EmailHistoryEntry 对应组件 EmailHistoryEntry
+type EmailHistoryEntry struct {
+	AccountID      int64     `json:"account_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at,omitempty"`
+	Details        string    `json:"details,omitempty"`
+	EmailID        int64     `json:"email_id,omitempty"`
+	FromFolder     string    `json:"from_folder,omitempty"`
+	FromFolderID   *int64    `json:"from_folder_id,omitempty"`
+	ID             int64     `json:"id,omitempty"`
+	RelatedEmailID *int64    `json:"related_email_id,omitempty"`
+	Source         string    `json:"source,omitempty"`
+	ToFolder       string    `json:"to_folder,omitempty"`
+	ToFolderID     *int64    `json:"to_folder_id,omitempty"`
+	Type           string    `json:"type,omitempty"`
+}
+
+// EmailListGroup 对应组件 EmailListGroup
+type EmailListGroup struct {
+	Count    int64   `json:"count,omitempty"`
+	EmailIDs []int64 `json:"email_ids,omitempty"`
+	Key      string  `json:"key,omitempty"`
+	Label    string  `json:"label,omitempty"`
+}
+
+// EmailNavigation 对应组件 EmailNavigation
+type EmailNavigation struct {
+	EmailID    int64  `json:"email_id,omitempty"`
+	NextID     *int64 `json:"next_id,omitempty"`
+	Position   int64  `json:"position,omitempty"`
+	PreviousID *int64 `json:"previous_id,omitempty"`
+	Total      int64  `json:"total,omitempty"`
+}
+
+// EmailNote 对应组件 EmailNote
+type EmailNote struct {
+	Content      string     `json:"content,omitempty"`
+	CreatedAt    time.Time  `json:"created_at,omitempty"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+	EmailID      int64      `json:"email_id,omitempty"`
+	ID           int64      `json:"id,omitempty"`
+	SyncToServer bool       `json:"sync_to_server,omitempty"`
+	SyncedAt     *time.Time `json:"synced_at,omitempty"`
+	UpdatedAt    time.Time  `json:"updated_at,omitempty"`
+}
+
+// EmailShareLink 对应组件 EmailShareLink
+type EmailShareLink struct {
+	AccessCount      int64      `json:"access_count,omitempty"`
+	Active           bool       `json:"active,omitempty"`
+	AllowAttachments bool       `json:"allow_attachments,omitempty"`
+	CreatedAt        time.Time  `json:"created_at,omitempty"`
+	DeletedAt        *time.Time `json:"deleted_at,omitempty"`
+	EmailID          int64      `json:"email_id,omitempty"`
+	ExpiresAt        time.Time  `json:"expires_at,omitempty"`
+	ID               int64      `json:"id,omitempty"`
+	LastAccessedAt   *time.Time `json:"last_accessed_at,omitempty"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+	UpdatedAt        time.Time  `json:"updated_at,omitempty"`
+	URL              string     `json:"url,omitempty"`
+}
+
+// EmailSource 对应组件 EmailSource
+type EmailSource struct {
+	EmailID    int64               `json:"email_id,omitempty"`
+	Headers    []*EmailHeaderField `json:"headers,omitempty"`
+	RawHeaders string              `json:"raw_headers,omitempty"`
+	Size       int64               `json:"size,omitempty"`
+	Structure  *MIMEPart           `json:"structure,omitempty"`
+}
+
+// EmailSummary 对应组件 EmailSummary
+type EmailSummary struct {
+	AccountID        int64        `json:"account_id,omitempty"`
+	AttachmentCount  int64        `json:"attachment_count,omitempty"`
+	Avatar           *EmailAvatar `json:"avatar,omitempty"`
+	Date             time.Time    `json:"date,omitempty"`
+	FlagColor        string       `json:"flag_color,omitempty"`
+	FolderID         *int64       `json:"folder_id,omitempty"`
+	FollowUpAt       *time.Time   `json:"follow_up_at,omitempty"`
+	From             string       `json:"from,omitempty"`
+	HasAttachment    bool         `json:"has_attachment,omitempty"`
+	ID               int64        `json:"id,omitempty"`
+	ImportanceBucket string       `json:"importance_bucket,omitempty"`
+	IsAnswered       bool         `json:"is_answered,omitempty"`
+	IsDraft          bool         `json:"is_draft,omitempty"`
+	IsFollowUp       bool         `json:"is_follow_up,omitempty"`
+	IsForwarded      bool         `json:"is_forwarded,omitempty"`
+	IsImportant      bool         `json:"is_important,omitempty"`
+	IsPinned         bool         `json:"is_pinned,omitempty"`
+	IsRead           bool         `json:"is_read,omitempty"`
+	IsSent           bool         `json:"is_sent,omitempty"`
+	IsStarred        bool         `json:"is_starred,omitempty"`
+	IsVip            bool         `json:"is_vip,omitempty"`
+	Preview          string       `json:"preview,omitempty"`
+	Priority         string       `json:"priority,omitempty"`
+	Size             int64        `json:"size,omitempty"`
+	Subject          string       `json:"subject,omitempty"`
+	ThreadID         string       `json:"thread_id,omitempty"`
+}
+
+// EmailTemplate 对应组件 EmailTemplate
+type EmailTemplate struct {
+	Category    string     `json:"category,omitempty"`
+	CreatedAt   time.Time  `json:"created_at,omitempty"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	Description string     `json:"description,omitempty"`
+	HTMLBody    string     `json:"html_body,omitempty"`
+	ID          int64      `json:"id,omitempty"`
+	IsActive    bool       `json:"is_active,omitempty"`
+	IsBuiltIn   bool       `json:"is_built_in,omitempty"`
+	IsDefault   bool       `json:"is_default,omitempty"`
+	IsShared    bool       `json:"is_shared,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	Name        string     `json:"name,omitempty"`
+	Subject     string     `json:"subject,omitempty"`
+	Tags        string     `json:"tags,omitempty"`
+	TextBody    string     `json:"text_body,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at,omitempty"`
+	UsageCount  int64      `json:"usage_count,omitempty"`
+	User        *User      `json:"user,omitempty"`
+	UserID      int64      `json:"user_id,omitempty"`
+	Variables   string     `json:"variables,omitempty"`
+}
+
+// EmailTransferJob 对应组件 EmailTransferJob
+type EmailTransferJob struct {
+	Copy            bool       `json:"copy,omitempty"`
+	Failed          int64      `json:"failed,omitempty"`
+	FinishedAt      *time.Time `json:"finished_at,omitempty"`
+	ID              string     `json:"id,omitempty"`
+	LastError       string     `json:"last_error,omitempty"`
+	Processed       int64      `json:"processed,omitempty"`
+	StartedAt       time.Time  `json:"started_at,omitempty"`
+	Status          string     `json:"status,omitempty"`
+	Succeeded       int64      `json:"succeeded,omitempty"`
+	TargetAccountID int64      `json:"target_account_id,omitempty"`
+	TargetFolderID  int64      `json:"target_folder_id,omitempty"`
+	Total           int64      `json:"total,omitempty"`
+}
+
+// Error 对应组件 Error
+type Error struct {
+	Locations []*Location   `json:"locations,omitempty"`
+	Message   string        `json:"message,omitempty"`
+	Path      []interface{} `json:"path,omitempty"`
+}
+
+// ErrorResponse 对应组件 ErrorResponse
+type ErrorResponse struct {
+	Code       string                `json:"code,omitempty"`
+	Current    interface{}           `json:"current,omitempty"`
+	Error      string                `json:"error,omitempty"`
+	Message    string                `json:"message,omitempty"`
+	SetupGuide *AccountSetupGuide    `json:"setup_guide,omitempty"`
+	SizeLimit  *MessageTooLargeError `json:"size_limit,omitempty"`
+}
+
+// Event 对应组件 Event
+type Event struct {
+	AccountID      *int64      `json:"account_id,omitempty"`
+	Data           interface{} `json:"data,omitempty"`
+	ID             string      `json:"id,omitempty"`
+	NotificationID int64       `json:"notification_id,omitempty"`
+	Priority       int64       `json:"priority,omitempty"`
+	Retry          *int64      `json:"retry,omitempty"`
+	Seq            int64       `json:"seq,omitempty"`
+	Timestamp      time.Time   `json:"timestamp,omitempty"`
+	Type           string      `json:"type,omitempty"`
+	UserID         int64       `json:"user_id,omitempty"`
+}
+
+// EventBatch 对应组件 EventBatch
+type EventBatch struct {
+	Cursor int64    `json:"cursor,omitempty"`
+	Events []*Event `json:"events,omitempty"`
+	Reset  bool     `json:"reset,omitempty"`
+}
+
+// Folder 对应组件 Folder
+type Folder struct {
+	Account      *EmailAccount `json:"account,omitempty"`
+	AccountID    int64         `json:"account_id,omitempty"`
+	Children     []*Folder     `json:"children,omitempty"`
+	Color        string        `json:"color,omitempty"`
+	CreatedAt    time.Time     `json:"created_at,omitempty"`
+	DeletedAt    *time.Time    `json:"deleted_at,omitempty"`
+	Delimiter    string        `json:"delimiter,omitempty"`
+	DisplayName  string        `json:"display_name,omitempty"`
+	Emails       []*Email      `json:"emails,omitempty"`
+	Icon         string        `json:"icon,omitempty"`
+	ID           int64         `json:"id,omitempty"`
+	IsSelectable bool          `json:"is_selectable,omitempty"`
+	IsSubscribed bool          `json:"is_subscribed,omitempty"`
+	LabelMode    string        `json:"label_mode,omitempty"`
+	Name         string        `json:"name,omitempty"`
+	Parent       *Folder       `json:"parent,omitempty"`
+	ParentID     *int64        `json:"parent_id,omitempty"`
+	Path         string        `json:"path,omitempty"`
+	SortOrder    int64         `json:"sort_order,omitempty"`
+	TotalEmails  int64         `json:"total_emails,omitempty"`
+	Type         string        `json:"type,omitempty"`
+	TypeLocked   bool          `json:"type_locked,omitempty"`
+	UIDNext      int64         `json:"uid_next,omitempty"`
+	UIDValidity  int64         `json:"uid_validity,omitempty"`
+	UnreadEmails int64         `json:"unread_emails,omitempty"`
+	UpdatedAt    time.Time     `json:"updated_at,omitempty"`
+}
+
+// FolderChange 对应组件 FolderChange
+type FolderChange struct {
+	AccountID    int64 `json:"account_id,omitempty"`
+	Deleted      bool  `json:"deleted,omitempty"`
+	ID           int64 `json:"id,omitempty"`
+	TotalEmails  int64 `json:"total_emails,omitempty"`
+	UnreadEmails int64 `json:"unread_emails,omitempty"`
+}
+
+// FolderCountReconciliation 对应组件 FolderCountReconciliation
+type FolderCountReconciliation struct {
+	Accounts        []*AccountCountReport `json:"accounts,omitempty"`
+	CheckedAccounts int64                 `json:"checked_accounts,omitempty"`
+	CheckedFolders  int64                 `json:"checked_folders,omitempty"`
+	DriftedFolders  int64                 `json:"drifted_folders,omitempty"`
+	Errors          []string              `json:"errors,omitempty"`
+	FixedFolders    int64                 `json:"fixed_folders,omitempty"`
+	Folders         []*FolderCountReport  `json:"folders,omitempty"`
+	ServerMismatch  int64                 `json:"server_mismatch,omitempty"`
+}
+
+// FolderCountReport 对应组件 FolderCountReport
+type FolderCountReport struct {
+	AccountID    int64  `json:"account_id,omitempty"`
+	Fixed        bool   `json:"fixed,omitempty"`
+	FolderID     int64  `json:"folder_id,omitempty"`
+	Path         string `json:"path,omitempty"`
+	ServerError  string `json:"server_error,omitempty"`
+	ServerTotal  *int64 `json:"server_total,omitempty"`
+	ServerUnread *int64 `json:"server_unread,omitempty"`
+	StoredTotal  int64  `json:"stored_total,omitempty"`
+	StoredUnread int64  `json:"stored_unread,omitempty"`
+	Total        int64  `json:"total,omitempty"`
+	Unread       int64  `json:"unread,omitempty"`
+}
+
+// ForwardEmailRequest 对应组件 ForwardEmailRequest
+type ForwardEmailRequest struct {
+	AccountID int64           `json:"account_id"`
+	BCC       []*EmailAddress `json:"bcc,omitempty"`
+	CC        []*EmailAddress `json:"cc,omitempty"`
+	HTMLBody  string          `json:"html_body,omitempty"`
+	Subject   string          `json:"subject,omitempty"`
+	TextBody  string          `json:"text_body,omitempty"`
+	To        []*EmailAddress `json:"to"`
+}
+
+// ForwardingRule 对应组件 ForwardingRule
+type ForwardingRule struct {
+	AccountID       int64      `json:"account_id,omitempty"`
+	CreatedAt       time.Time  `json:"created_at,omitempty"`
+	FailedCount     int64      `json:"failed_count,omitempty"`
+	ForwardTo       string     `json:"forward_to,omitempty"`
+	ForwardedCount  int64      `json:"forwarded_count,omitempty"`
+	FromContains    string     `json:"from_contains,omitempty"`
+	ID              int64      `json:"id,omitempty"`
+	IsEnabled       bool       `json:"is_enabled,omitempty"`
+	LastError       string     `json:"last_error,omitempty"`
+	LastForwardedAt *time.Time `json:"last_forwarded_at,omitempty"`
+	LoopCount       int64      `json:"loop_count,omitempty"`
+	Mode            string     `json:"mode,omitempty"`
+	Name            string     `json:"name,omitempty"`
+	SubjectContains string     `json:"subject_contains,omitempty"`
+	UpdatedAt       time.Time  `json:"updated_at,omitempty"`
+}
+
+// ForwardingRuleRequest 对应组件 ForwardingRuleRequest
+type ForwardingRuleRequest struct {
+	AccountID       int64  `json:"account_id"`
+	ForwardTo       string `json:"forward_to"`
+	FromContains    string `json:"from_contains,omitempty"`
+	IsEnabled       *bool  `json:"is_enabled,omitempty"`
+	Mode            string `json:"mode,omitempty"`
+	Name            string `json:"name"`
+	SubjectContains string `json:"subject_contains,omitempty"`
+}
+
+// GetEmailsResponse 对应组件 GetEmailsResponse
+type GetEmailsResponse struct {
+	Emails     []*Email          `json:"emails,omitempty"`
+	Groups     []*EmailListGroup `json:"groups,omitempty"`
+	HasMore    bool              `json:"has_more,omitempty"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+	Page       int64             `json:"page,omitempty"`
+	PageSize   int64             `json:"page_size,omitempty"`
+	Summaries  []*EmailSummary   `json:"summaries,omitempty"`
+	Total      int64             `json:"total,omitempty"`
+	TotalPages int64             `json:"total_pages,omitempty"`
+}
+
+// GmailLabelMapping 对应组件 GmailLabelMapping
+type GmailLabelMapping struct {
+	Configurable  bool   `json:"configurable,omitempty"`
+	DisplayName   string `json:"display_name,omitempty"`
+	EffectiveMode string `json:"effective_mode,omitempty"`
+	FolderID      int64  `json:"folder_id,omitempty"`
+	Label         string `json:"label,omitempty"`
+	Mode          string `json:"mode,omitempty"`
+	Path          string `json:"path,omitempty"`
+}
+
+// GmailLabelModeUpdate 对应组件 GmailLabelModeUpdate
+type GmailLabelModeUpdate struct {
+	FolderID int64  `json:"folder_id"`
+	Mode     string `json:"mode,omitempty"`
+}
+
+// HeaderAnalysis 对应组件 HeaderAnalysis
+type HeaderAnalysis struct {
+	Authentication    []*AuthenticationResult `json:"authentication,omitempty"`
+	Dkim              string                  `json:"dkim,omitempty"`
+	DkimDomains       []string                `json:"dkim_domains,omitempty"`
+	Dmarc             string                  `json:"dmarc,omitempty"`
+	EmailID           int64                   `json:"email_id,omitempty"`
+	Hops              []*ReceivedHop          `json:"hops,omitempty"`
+	OriginatingIP     *OriginatingIP          `json:"originating_ip,omitempty"`
+	Spf               string                  `json:"spf,omitempty"`
+	TotalDelaySeconds *float64                `json:"total_delay_seconds,omitempty"`
+	Warnings          []string                `json:"warnings,omitempty"`
+}
+
+// HealthResponse 对应组件 HealthResponse
+type HealthResponse struct {
+	Service string `json:"service,omitempty"`
+	Status  string `json:"status,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// IPGeoInfo 对应组件 IPGeoInfo
+type IPGeoInfo struct {
+	As          string  `json:"as,omitempty"`
+	City        string  `json:"city,omitempty"`
+	Country     string  `json:"country,omitempty"`
+	CountryCode string  `json:"country_code,omitempty"`
+	IP          string  `json:"ip,omitempty"`
+	Isp         string  `json:"isp,omitempty"`
+	Latitude    float64 `json:"latitude,omitempty"`
+	Longitude   float64 `json:"longitude,omitempty"`
+	Org         string  `json:"org,omitempty"`
+	Region      string  `json:"region,omitempty"`
+}
+
+// ImportBlockedSendersRequest 对应组件 ImportBlockedSendersRequest
+type ImportBlockedSendersRequest struct {
+	Entries []*BlockedSenderRequest `json:"entries"`
+	Replace bool                    `json:"replace,omitempty"`
+}
+
+// ImportBlockedSendersResult 对应组件 ImportBlockedSendersResult
+type ImportBlockedSendersResult struct {
+	Errors   []string `json:"errors,omitempty"`
+	Imported int64    `json:"imported,omitempty"`
+	Skipped  int64    `json:"skipped,omitempty"`
+}
+
+// ImportDraftsRequest 对应组件 ImportDraftsRequest
+type ImportDraftsRequest struct {
+	AccountID int64 `json:"account_id"`
+}
+
+// ImportDraftsResult 对应组件 ImportDraftsResult
+type ImportDraftsResult struct {
+	Imported int64 `json:"imported,omitempty"`
+}
+
+// IngestAttachment 对应组件 IngestAttachment
+type IngestAttachment struct {
+	ContentBase64 string `json:"content_base64"`
+	ContentType   string `json:"content_type,omitempty"`
+	Filename      string `json:"filename"`
+}
+
+// IngestEndpoint 对应组件 IngestEndpoint
+type IngestEndpoint struct {
+	AccountID     int64      `json:"account_id,omitempty"`
+	Address       *string    `json:"address,omitempty"`
+	CreatedAt     time.Time  `json:"created_at,omitempty"`
+	FolderID      int64      `json:"folder_id,omitempty"`
+	ID            int64      `json:"id,omitempty"`
+	IsEnabled     bool       `json:"is_enabled,omitempty"`
+	LastUsedAt    *time.Time `json:"last_used_at,omitempty"`
+	Name          string     `json:"name,omitempty"`
+	ReceivedCount int64      `json:"received_count,omitempty"`
+	TokenPrefix   string     `json:"token_prefix,omitempty"`
+	UpdatedAt     time.Time  `json:"updated_at,omitempty"`
+}
+
+// IngestEndpointWithToken 对应组件 IngestEndpointWithToken
+type IngestEndpointWithToken struct {
+	AccountID     int64      `json:"account_id,omitempty"`
+	Address       *string    `json:"address,omitempty"`
+	CreatedAt     time.Time  `json:"created_at,omitempty"`
+	FolderID      int64      `json:"folder_id,omitempty"`
+	ID            int64      `json:"id,omitempty"`
+	IsEnabled     bool       `json:"is_enabled,omitempty"`
+	LastUsedAt    *time.Time `json:"last_used_at,omitempty"`
+	Name          string     `json:"name,omitempty"`
+	ReceivedCount int64      `json:"received_count,omitempty"`
+	Token         string     `json:"token,omitempty"`
+	TokenPrefix   string     `json:"token_prefix,omitempty"`
+	UpdatedAt     time.Time  `json:"updated_at,omitempty"`
+}
+
+// IngestMessageRequest 对应组件 IngestMessageRequest
+type IngestMessageRequest struct {
+	Attachments   []*IngestAttachment `json:"attachments,omitempty"`
+	CC            []string            `json:"cc,omitempty"`
+	Date          *time.Time          `json:"date,omitempty"`
+	From          string              `json:"from,omitempty"`
+	HTML          string              `json:"html,omitempty"`
+	MessageID     string              `json:"message_id,omitempty"`
+	RawMime       string              `json:"raw_mime,omitempty"`
+	RawMimeBase64 string              `json:"raw_mime_base64,omitempty"`
+	ReplyTo       string              `json:"reply_to,omitempty"`
+	Subject       string              `json:"subject,omitempty"`
+	Text          string              `json:"text,omitempty"`
+	To            []string            `json:"to,omitempty"`
+}
+
+// IngestResult 对应组件 IngestResult
+type IngestResult struct {
+	Duplicate bool   `json:"duplicate,omitempty"`
+	EmailID   *int64 `json:"email_id,omitempty"`
+	MessageID string `json:"message_id,omitempty"`
+}
+
+// InlineAttachment 对应组件 InlineAttachment
+type InlineAttachment struct {
+	ContentID   string `json:"content_id"`
+	ContentType string `json:"content_type,omitempty"`
+	Data        []byte `json:"data,omitempty"`
+	Filename    string `json:"filename"`
+	Size        int64  `json:"size,omitempty"`
+}
+
+// InviteOrganizationMemberRequest 对应组件 InviteOrganizationMemberRequest
+type InviteOrganizationMemberRequest struct {
+	Role     string `json:"role,omitempty"`
+	Username string `json:"username"`
+}
+
+// Job 对应组件 Job
+type Job struct {
+	Attempts    int64      `json:"attempts,omitempty"`
+	CreatedAt   time.Time  `json:"created_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	ID          int64      `json:"id,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	LockedAt    *time.Time `json:"locked_at,omitempty"`
+	LockedBy    string     `json:"locked_by,omitempty"`
+	MaxAttempts int64      `json:"max_attempts,omitempty"`
+	Payload     string     `json:"payload,omitempty"`
+	RunAt       time.Time  `json:"run_at,omitempty"`
+	Status      string     `json:"status,omitempty"`
+	Type        string     `json:"type,omitempty"`
+	UniqueKey   string     `json:"unique_key,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at,omitempty"`
+	UserID      int64      `json:"user_id,omitempty"`
+}
+
+// JobList 对应组件 JobList
+type JobList struct {
+	Counts map[string]int64 `json:"counts,omitempty"`
+	Jobs   []*Job           `json:"jobs,omitempty"`
+}
+
+// Label 对应组件 Label
+type Label struct {
+	AccountID int64     `json:"account_id,omitempty"`
+	Color     string    `json:"color,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	Icon      string    `json:"icon,omitempty"`
+	ID        int64     `json:"id,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	SortOrder int64     `json:"sort_order,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// LegalHold 对应组件 LegalHold
+type LegalHold struct {
+	AccountIDs  string     `json:"account_ids,omitempty"`
+	CreatedAt   time.Time  `json:"created_at,omitempty"`
+	Description string     `json:"description,omitempty"`
+	ID          int64      `json:"id,omitempty"`
+	Name        string     `json:"name,omitempty"`
+	ReleasedAt  *time.Time `json:"released_at,omitempty"`
+	Senders     string     `json:"senders,omitempty"`
+	Since       *time.Time `json:"since,omitempty"`
+	Until       *time.Time `json:"until,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at,omitempty"`
+}
+
+// LegalHoldDetail 对应组件 LegalHoldDetail
+type LegalHoldDetail struct {
+	AccountIDs    string     `json:"account_ids,omitempty"`
+	CreatedAt     time.Time  `json:"created_at,omitempty"`
+	Description   string     `json:"description,omitempty"`
+	ID            int64      `json:"id,omitempty"`
+	MatchedBytes  int64      `json:"matched_bytes,omitempty"`
+	MatchedEmails int64      `json:"matched_emails,omitempty"`
+	Name          string     `json:"name,omitempty"`
+	ReleasedAt    *time.Time `json:"released_at,omitempty"`
+	Senders       string     `json:"senders,omitempty"`
+	Since         *time.Time `json:"since,omitempty"`
+	Until         *time.Time `json:"until,omitempty"`
+	UpdatedAt     time.Time  `json:"updated_at,omitempty"`
+}
+
+// LegalHoldExport 对应组件 LegalHoldExport
+type LegalHoldExport struct {
+	ArchiveSha256  string     `json:"archive_sha256,omitempty"`
+	ExportedEmails int64      `json:"exported_emails,omitempty"`
+	FileSize       int64      `json:"file_size,omitempty"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty"`
+	HoldID         int64      `json:"hold_id,omitempty"`
+	ID             int64      `json:"id,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	LocalEmails    int64      `json:"local_emails,omitempty"`
+	ManifestSha256 string     `json:"manifest_sha256,omitempty"`
+	Signature      string     `json:"signature,omitempty"`
+	StartedAt      time.Time  `json:"started_at,omitempty"`
+	Status         string     `json:"status,omitempty"`
+	TotalEmails    int64      `json:"total_emails,omitempty"`
+}
+
+// LegalHoldExportVerification 对应组件 LegalHoldExportVerification
+type LegalHoldExportVerification struct {
+	ArchiveMatches  bool     `json:"archive_matches,omitempty"`
+	ArchiveSha256   string   `json:"archive_sha256,omitempty"`
+	EntriesChecked  int64    `json:"entries_checked,omitempty"`
+	EntryMismatches []string `json:"entry_mismatches,omitempty"`
+	ExportID        int64    `json:"export_id,omitempty"`
+	SignatureValid  bool     `json:"signature_valid,omitempty"`
+	Valid           bool     `json:"valid,omitempty"`
+}
+
+// ListDraftsResponse 对应组件 ListDraftsResponse
+type ListDraftsResponse struct {
+	Drafts     []*Draft `json:"drafts,omitempty"`
+	Page       int64    `json:"page,omitempty"`
+	PageSize   int64    `json:"page_size,omitempty"`
+	Total      int64    `json:"total,omitempty"`
+	TotalPages int64    `json:"total_pages,omitempty"`
+}
+
+// ListEmailTemplatesResponse 对应组件 ListEmailTemplatesResponse
+type ListEmailTemplatesResponse struct {
+	Page       int64            `json:"page,omitempty"`
+	PageSize   int64            `json:"page_size,omitempty"`
+	Templates  []*EmailTemplate `json:"templates,omitempty"`
+	Total      int64            `json:"total,omitempty"`
+	TotalPages int64            `json:"total_pages,omitempty"`
+}
+
+// ListMailMergeRecipientsResponse 对应组件 ListMailMergeRecipientsResponse
+type ListMailMergeRecipientsResponse struct {
+	Page       int64                 `json:"page,omitempty"`
+	PageSize   int64                 `json:"page_size,omitempty"`
+	Recipients []*MailMergeRecipient `json:"recipients,omitempty"`
+	Total      int64                 `json:"total,omitempty"`
+	TotalPages int64                 `json:"total_pages,omitempty"`
+}
+
+// ListNotificationsResponse 对应组件 ListNotificationsResponse
+type ListNotificationsResponse struct {
+	Notifications []*Notification `json:"notifications,omitempty"`
+	Page          int64           `json:"page,omitempty"`
+	PageSize      int64           `json:"page_size,omitempty"`
+	Total         int64           `json:"total,omitempty"`
+	TotalPages    int64           `json:"total_pages,omitempty"`
+	UnreadCount   int64           `json:"unread_count,omitempty"`
+}
+
+// ListSharedEmailsResponse 对应组件 ListSharedEmailsResponse
+type ListSharedEmailsResponse struct {
+	Emails     []*SharedMailboxEmail `json:"emails,omitempty"`
+	Page       int64                 `json:"page,omitempty"`
+	PageSize   int64                 `json:"page_size,omitempty"`
+	Total      int64                 `json:"total,omitempty"`
+	TotalPages int64                 `json:"total_pages,omitempty"`
+}
+
+// Location 对应组件 Location
+type Location struct {
+	Column int64 `json:"column,omitempty"`
+	Line   int64 `json:"line,omitempty"`
+}
+
+// LoginRequest 对应组件 LoginRequest
+type LoginRequest struct {
+	Password string `json:"password"`
+	Username string `json:"username"`
+}
+
+// LoginResponse 对应组件 LoginResponse
+type LoginResponse struct {
+	CsrfToken string    `json:"csrf_token,omitempty"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+	Token     string    `json:"token,omitempty"`
+	User      *User     `json:"user,omitempty"`
+}
+
+// MIMEPart 对应组件 MIMEPart
+type MIMEPart struct {
+	Boundary    string      `json:"boundary,omitempty"`
+	Charset     string      `json:"charset,omitempty"`
+	Children    []*MIMEPart `json:"children,omitempty"`
+	ContentID   string      `json:"content_id,omitempty"`
+	ContentType string      `json:"content_type,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Disposition string      `json:"disposition,omitempty"`
+	Encoding    string      `json:"encoding,omitempty"`
+	Error       string      `json:"error,omitempty"`
+	Filename    string      `json:"filename,omitempty"`
+	Lines       int64       `json:"lines,omitempty"`
+	PartID      string      `json:"part_id,omitempty"`
+	Size        int64       `json:"size,omitempty"`
+}
+
+// MailFetchResult 对应组件 MailFetchResult
+type MailFetchResult struct {
+	Error     string `json:"error,omitempty"`
+	Failed    int64  `json:"failed,omitempty"`
+	Fetched   int64  `json:"fetched,omitempty"`
+	FetcherID int64  `json:"fetcher_id,omitempty"`
+	Remaining int64  `json:"remaining,omitempty"`
+}
+
+// MailFetcher 对应组件 MailFetcher
+type MailFetcher struct {
+	AccountID        int64      `json:"account_id,omitempty"`
+	CreatedAt        time.Time  `json:"created_at,omitempty"`
+	DeleteAfterFetch bool       `json:"delete_after_fetch,omitempty"`
+	FetchedCount     int64      `json:"fetched_count,omitempty"`
+	FolderID         int64      `json:"folder_id,omitempty"`
+	Host             string     `json:"host,omitempty"`
+	ID               int64      `json:"id,omitempty"`
+	IntervalMinutes  int64      `json:"interval_minutes,omitempty"`
+	IsEnabled        bool       `json:"is_enabled,omitempty"`
+	Label            string     `json:"label,omitempty"`
+	LastError        string     `json:"last_error,omitempty"`
+	LastRunAt        *time.Time `json:"last_run_at,omitempty"`
+	Name             string     `json:"name,omitempty"`
+	Port             int64      `json:"port,omitempty"`
+	Protocol         string     `json:"protocol,omitempty"`
+	Security         string     `json:"security,omitempty"`
+	SourceFolder     string     `json:"source_folder,omitempty"`
+	UpdatedAt        time.Time  `json:"updated_at,omitempty"`
+	Username         string     `json:"username,omitempty"`
+}
+
+// MailFetcherRequest 对应组件 MailFetcherRequest
+type MailFetcherRequest struct {
+	AccountID        int64  `json:"account_id"`
+	DeleteAfterFetch bool   `json:"delete_after_fetch,omitempty"`
+	FolderID         *int64 `json:"folder_id,omitempty"`
+	Host             string `json:"host"`
+	IntervalMinutes  int64  `json:"interval_minutes,omitempty"`
+	IsEnabled        *bool  `json:"is_enabled,omitempty"`
+	Label            string `json:"label,omitempty"`
+	Name             string `json:"name"`
+	Password         string `json:"password,omitempty"`
+	Port             int64  `json:"port"`
+	Protocol         string `json:"protocol"`
+	Security         string `json:"security,omitempty"`
+	SourceFolder     string `json:"source_folder,omitempty"`
+	Username         string `json:"username"`
+}
+
+// MailMergeCampaign 对应组件 MailMergeCampaign
+type MailMergeCampaign struct {
+	AccountID       int64      `json:"account_id,omitempty"`
+	ClickedCount    int64      `json:"clicked_count,omitempty"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at,omitempty"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
+	FailedCount     int64      `json:"failed_count,omitempty"`
+	ID              int64      `json:"id,omitempty"`
+	LastError       string     `json:"last_error,omitempty"`
+	Name            string     `json:"name,omitempty"`
+	OpenedCount     int64      `json:"opened_count,omitempty"`
+	RatePerMinute   int64      `json:"rate_per_minute,omitempty"`
+	SentCount       int64      `json:"sent_count,omitempty"`
+	SkippedCount    int64      `json:"skipped_count,omitempty"`
+	StartedAt       *time.Time `json:"started_at,omitempty"`
+	Status          string     `json:"status,omitempty"`
+	TemplateID      int64      `json:"template_id,omitempty"`
+	TotalRecipients int64      `json:"total_recipients,omitempty"`
+	TrackClicks     bool       `json:"track_clicks,omitempty"`
+	TrackOpens      bool       `json:"track_opens,omitempty"`
+	UpdatedAt       time.Time  `json:"updated_at,omitempty"`
+	UseVerp         bool       `json:"use_verp,omitempty"`
+	UserID          int64      `json:"user_id,omitempty"`
+}
+
+// MailMergeCampaignReport 对应组件 MailMergeCampaignReport
+type MailMergeCampaignReport struct {
+	Campaign      *MailMergeCampaign `json:"campaign,omitempty"`
+	ClickRate     float64            `json:"click_rate,omitempty"`
+	ClickedCount  int64              `json:"clicked_count,omitempty"`
+	ExcludedCount int64              `json:"excluded_count,omitempty"`
+	OpenRate      float64            `json:"open_rate,omitempty"`
+	OpenedCount   int64              `json:"opened_count,omitempty"`
+	TopLinks      []*MailMergeLink   `json:"top_links,omitempty"`
+	TrackedCount  int64              `json:"tracked_count,omitempty"`
+}
+
+// MailMergeLink 对应组件 MailMergeLink
+type MailMergeLink struct {
+	CampaignID int64     `json:"campaign_id,omitempty"`
+	ClickCount int64     `json:"click_count,omitempty"`
+	CreatedAt  time.Time `json:"created_at,omitempty"`
+	ID         int64     `json:"id,omitempty"`
+	URL        string    `json:"url,omitempty"`
+}
+
+// MailMergeRecipient 对应组件 MailMergeRecipient
+type MailMergeRecipient struct {
+	CampaignID       int64      `json:"campaign_id,omitempty"`
+	ClickCount       int64      `json:"click_count,omitempty"`
+	CreatedAt        time.Time  `json:"created_at,omitempty"`
+	DeletedAt        *time.Time `json:"deleted_at,omitempty"`
+	Email            string     `json:"email,omitempty"`
+	Error            string     `json:"error,omitempty"`
+	FirstClickedAt   *time.Time `json:"first_clicked_at,omitempty"`
+	FirstOpenedAt    *time.Time `json:"first_opened_at,omitempty"`
+	ID               int64      `json:"id,omitempty"`
+	LineNumber       int64      `json:"line_number,omitempty"`
+	Name             string     `json:"name,omitempty"`
+	OpenCount        int64      `json:"open_count,omitempty"`
+	SendID           string     `json:"send_id,omitempty"`
+	SentAt           *time.Time `json:"sent_at,omitempty"`
+	Status           string     `json:"status,omitempty"`
+	TrackingExcluded bool       `json:"tracking_excluded,omitempty"`
+	UpdatedAt        time.Time  `json:"updated_at,omitempty"`
+	Variables        string     `json:"variables,omitempty"`
+}
+
+// MailboxMigration 对应组件 MailboxMigration
+type MailboxMigration struct {
+	CompletedAt     *time.Time                `json:"completed_at,omitempty"`
+	CopiedEmails    int64                     `json:"copied_emails,omitempty"`
+	CreatedAt       time.Time                 `json:"created_at,omitempty"`
+	DeletedAt       *time.Time                `json:"deleted_at,omitempty"`
+	FailedEmails    int64                     `json:"failed_emails,omitempty"`
+	Folders         []*MailboxMigrationFolder `json:"folders,omitempty"`
+	ID              int64                     `json:"id,omitempty"`
+	LastError       string                    `json:"last_error,omitempty"`
+	SourceAccountID int64                     `json:"source_account_id,omitempty"`
+	StartedAt       *time.Time                `json:"started_at,omitempty"`
+	Status          string                    `json:"status,omitempty"`
+	TargetAccountID int64                     `json:"target_account_id,omitempty"`
+	TotalEmails     int64                     `json:"total_emails,omitempty"`
+	UpdatedAt       time.Time                 `json:"updated_at,omitempty"`
+	UserID          int64                     `json:"user_id,omitempty"`
+	VerifiedAt      *time.Time                `json:"verified_at,omitempty"`
+}
+
+// MailboxMigrationFolder 对应组件 MailboxMigrationFolder
+type MailboxMigrationFolder struct {
+	CopiedEmails int64      `json:"copied_emails,omitempty"`
+	CreatedAt    time.Time  `json:"created_at,omitempty"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+	FailedEmails int64      `json:"failed_emails,omitempty"`
+	ID           int64      `json:"id,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+	LastUID      int64      `json:"last_uid,omitempty"`
+	MigrationID  int64      `json:"migration_id,omitempty"`
+	SortOrder    int64      `json:"sort_order,omitempty"`
+	SourceCount  *int64     `json:"source_count,omitempty"`
+	SourcePath   string     `json:"source_path,omitempty"`
+	Status       string     `json:"status,omitempty"`
+	TargetCount  *int64     `json:"target_count,omitempty"`
+	TargetPath   string     `json:"target_path,omitempty"`
+	TotalEmails  int64      `json:"total_emails,omitempty"`
+	UIDValidity  int64      `json:"uid_validity,omitempty"`
+	UpdatedAt    time.Time  `json:"updated_at,omitempty"`
+}
+
+// MailboxMigrationFolderMapping 对应组件 MailboxMigrationFolderMapping
+type MailboxMigrationFolderMapping struct {
+	SourcePath string `json:"source_path,omitempty"`
+	TargetPath string `json:"target_path,omitempty"`
+}
+
+// MailboxMigrationFolderReport 对应组件 MailboxMigrationFolderReport
+type MailboxMigrationFolderReport struct {
+	CopiedEmails int64  `json:"copied_emails,omitempty"`
+	FailedEmails int64  `json:"failed_emails,omitempty"`
+	Matched      bool   `json:"matched,omitempty"`
+	SourceCount  *int64 `json:"source_count,omitempty"`
+	SourcePath   string `json:"source_path,omitempty"`
+	TargetCount  *int64 `json:"target_count,omitempty"`
+	TargetPath   string `json:"target_path,omitempty"`
+}
+
+// MailboxMigrationReport 对应组件 MailboxMigrationReport
+type MailboxMigrationReport struct {
+	CopiedEmails int64                           `json:"copied_emails,omitempty"`
+	FailedEmails int64                           `json:"failed_emails,omitempty"`
+	Folders      []*MailboxMigrationFolderReport `json:"folders,omitempty"`
+	Matched      bool                            `json:"matched,omitempty"`
+	MigrationID  int64                           `json:"migration_id,omitempty"`
+	Status       string                          `json:"status,omitempty"`
+	VerifiedAt   *time.Time                      `json:"verified_at,omitempty"`
+}
+
+// MailboxStorageReport 对应组件 MailboxStorageReport
+type MailboxStorageReport struct {
+	AccountID               int64                       `json:"account_id,omitempty"`
+	LargestAttachments      []*StorageAttachmentSummary `json:"largest_attachments,omitempty"`
+	LargestEmails           []*StorageEmailSummary      `json:"largest_emails,omitempty"`
+	OldestUnreadNewsletters []*StorageEmailSummary      `json:"oldest_unread_newsletters,omitempty"`
+	TopSenders              []*SenderVolume             `json:"top_senders,omitempty"`
+	TotalEmails             int64                       `json:"total_emails,omitempty"`
+	TotalSize               int64                       `json:"total_size,omitempty"`
+}
+
+// MarkNotificationsRequest 对应组件 MarkNotificationsRequest
+type MarkNotificationsRequest struct {
+	IDs    []int64 `json:"ids,omitempty"`
+	IsRead *bool   `json:"is_read"`
+}
+
+// MarkNotificationsResult 对应组件 MarkNotificationsResult
+type MarkNotificationsResult struct {
+	UnreadCount int64 `json:"unread_count,omitempty"`
+	Updated     int64 `json:"updated,omitempty"`
+}
+
+// MarkdownPreview 对应组件 MarkdownPreview
+type MarkdownPreview struct {
+	HTMLBody string `json:"html_body,omitempty"`
+	TextBody string `json:"text_body,omitempty"`
+}
+
+// MarkdownPreviewRequest 对应组件 MarkdownPreviewRequest
+type MarkdownPreviewRequest struct {
+	CodeHighlight *bool  `json:"code_highlight,omitempty"`
+	Markdown      string `json:"markdown,omitempty"`
+}
+
+// MessageTooLargeError 对应组件 MessageTooLargeError
+type MessageTooLargeError struct {
+	AttachmentsSize int64  `json:"attachments_size,omitempty"`
+	Limit           int64  `json:"limit,omitempty"`
+	Provider        string `json:"provider,omitempty"`
+	Size            int64  `json:"size,omitempty"`
+}
+
+// MoveEmailRequest 对应组件 MoveEmailRequest
+type MoveEmailRequest struct {
+	Copy            bool   `json:"copy,omitempty"`
+	TargetAccountID *int64 `json:"target_account_id,omitempty"`
+	TargetFolderID  int64  `json:"target_folder_id"`
+}
+
+// MutedThread 对应组件 MutedThread
+type MutedThread struct {
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	ID        int64     `json:"id,omitempty"`
+	Subject   string    `json:"subject,omitempty"`
+	ThreadID  string    `json:"thread_id,omitempty"`
+}
+
+// Notification 对应组件 Notification
+type Notification struct {
+	AccountID *int64     `json:"account_id,omitempty"`
+	CreatedAt time.Time  `json:"created_at,omitempty"`
+	Data      string     `json:"data,omitempty"`
+	EventType string     `json:"event_type,omitempty"`
+	ID        int64      `json:"id,omitempty"`
+	IsRead    bool       `json:"is_read,omitempty"`
+	Level     string     `json:"level,omitempty"`
+	Message   string     `json:"message,omitempty"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	Title     string     `json:"title,omitempty"`
+}
+
+// NotificationActionResult 对应组件 NotificationActionResult
+type NotificationActionResult struct {
+	Action  string `json:"action,omitempty"`
+	EmailID int64  `json:"email_id,omitempty"`
+}
+
+// NotificationCounts 对应组件 NotificationCounts
+type NotificationCounts struct {
+	ByEventType map[string]int64 `json:"by_event_type,omitempty"`
+	ByLevel     map[string]int64 `json:"by_level,omitempty"`
+	Unread      int64            `json:"unread,omitempty"`
+}
+
+// OAuthTokenResponse 对应组件 OAuthTokenResponse
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token,omitempty"`
+	ClientID     string `json:"client_id,omitempty"`
+	ExpiresIn    int64  `json:"expires_in,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	TokenType    string `json:"token_type,omitempty"`
+}
+
+// OAuthURLResponse 对应组件 OAuthURLResponse
+type OAuthURLResponse struct {
+	AuthURL string `json:"auth_url,omitempty"`
+	State   string `json:"state,omitempty"`
+}
+
+// Organization 对应组件 Organization
+type Organization struct {
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	CreatedBy int64     `json:"created_by,omitempty"`
+	ID        int64     `json:"id,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// OrganizationDetail 对应组件 OrganizationDetail
+type OrganizationDetail struct {
+	CreatedAt time.Time                 `json:"created_at,omitempty"`
+	CreatedBy int64                     `json:"created_by,omitempty"`
+	ID        int64                     `json:"id,omitempty"`
+	Invites   []*OrganizationInvite     `json:"invites,omitempty"`
+	Mailboxes []*SharedMailboxInfo      `json:"mailboxes,omitempty"`
+	Members   []*OrganizationMemberInfo `json:"members,omitempty"`
+	Name      string                    `json:"name,omitempty"`
+	Role      string                    `json:"role,omitempty"`
+	UpdatedAt time.Time                 `json:"updated_at,omitempty"`
+}
+
+// OrganizationInvite 对应组件 OrganizationInvite
+type OrganizationInvite struct {
+	CreatedAt      time.Time     `json:"created_at,omitempty"`
+	ExpiresAt      time.Time     `json:"expires_at,omitempty"`
+	ID             int64         `json:"id,omitempty"`
+	InvitedBy      int64         `json:"invited_by,omitempty"`
+	Invitee        *User         `json:"invitee,omitempty"`
+	InviteeID      int64         `json:"invitee_id,omitempty"`
+	Organization   *Organization `json:"organization,omitempty"`
+	OrganizationID int64         `json:"organization_id,omitempty"`
+	RespondedAt    *time.Time    `json:"responded_at,omitempty"`
+	Role           string        `json:"role,omitempty"`
+	Status         string        `json:"status,omitempty"`
+}
+
+// OrganizationMember 对应组件 OrganizationMember
+type OrganizationMember struct {
+	CreatedAt      time.Time `json:"created_at,omitempty"`
+	ID             int64     `json:"id,omitempty"`
+	OrganizationID int64     `json:"organization_id,omitempty"`
+	Role           string    `json:"role,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at,omitempty"`
+	UserID         int64     `json:"user_id,omitempty"`
+}
+
+// OrganizationMemberInfo 对应组件 OrganizationMemberInfo
+type OrganizationMemberInfo struct {
+	DisplayName string    `json:"display_name,omitempty"`
+	JoinedAt    time.Time `json:"joined_at,omitempty"`
+	Role        string    `json:"role,omitempty"`
+	UserID      int64     `json:"user_id,omitempty"`
+	Username    string    `json:"username,omitempty"`
+}
+
+// OrganizationSummary 对应组件 OrganizationSummary
+type OrganizationSummary struct {
+	CreatedAt   time.Time `json:"created_at,omitempty"`
+	CreatedBy   int64     `json:"created_by,omitempty"`
+	ID          int64     `json:"id,omitempty"`
+	MemberCount int64     `json:"member_count,omitempty"`
+	Name        string    `json:"name,omitempty"`
+	Role        string    `json:"role,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at,omitempty"`
+}
+
+// OriginatingIP 对应组件 OriginatingIP
+type OriginatingIP struct {
+	Geo      *IPGeoInfo `json:"geo,omitempty"`
+	GeoError string     `json:"geo_error,omitempty"`
+	IP       string     `json:"ip,omitempty"`
+	Private  bool       `json:"private,omitempty"`
+	Source   string     `json:"source,omitempty"`
+}
+
+// ProviderInfo 对应组件 ProviderInfo
+type ProviderInfo struct {
+	AuthMethods []string        `json:"auth_methods,omitempty"`
+	DisplayName string          `json:"display_name,omitempty"`
+	Domains     []string        `json:"domains,omitempty"`
+	IMAP        *ProviderServer `json:"imap,omitempty"`
+	Name        string          `json:"name,omitempty"`
+	SMTP        *ProviderServer `json:"smtp,omitempty"`
+}
+
+// ProviderServer 对应组件 ProviderServer
+type ProviderServer struct {
+	Host     string `json:"host,omitempty"`
+	Port     int64  `json:"port,omitempty"`
+	Security string `json:"security,omitempty"`
+}
+
+// RebuildVolumeStatsRequest 对应组件 RebuildVolumeStatsRequest
+type RebuildVolumeStatsRequest struct {
+	AccountID *int64 `json:"account_id,omitempty"`
+}
+
+// ReceivedHop 对应组件 ReceivedHop
+type ReceivedHop struct {
+	By           string     `json:"by,omitempty"`
+	DelaySeconds *float64   `json:"delay_seconds,omitempty"`
+	For          string     `json:"for,omitempty"`
+	From         string     `json:"from,omitempty"`
+	FromIP       string     `json:"from_ip,omitempty"`
+	ID           string     `json:"id,omitempty"`
+	Index        int64      `json:"index,omitempty"`
+	Raw          string     `json:"raw,omitempty"`
+	Timestamp    *time.Time `json:"timestamp,omitempty"`
+	With         string     `json:"with,omitempty"`
+}
+
+// ReconcileFolderCountsRequest 对应组件 ReconcileFolderCountsRequest
+type ReconcileFolderCountsRequest struct {
+	AccountID   *int64 `json:"account_id,omitempty"`
+	CheckServer bool   `json:"check_server,omitempty"`
+	DryRun      bool   `json:"dry_run,omitempty"`
+	FolderID    *int64 `json:"folder_id,omitempty"`
+}
+
+// RedecodeEmailRequest 对应组件 RedecodeEmailRequest
+type RedecodeEmailRequest struct {
+	Charset string `json:"charset"`
+}
+
+// RenderCannedResponseRequest 对应组件 RenderCannedResponseRequest
+type RenderCannedResponseRequest struct {
+	EmailID   *int64                 `json:"email_id,omitempty"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+// RenderedCannedResponse 对应组件 RenderedCannedResponse
+type RenderedCannedResponse struct {
+	HTMLBody         string   `json:"html_body,omitempty"`
+	MissingVariables []string `json:"missing_variables,omitempty"`
+	TextBody         string   `json:"text_body,omitempty"`
+}
+
+// ReorderEmailAccountsRequest 对应组件 ReorderEmailAccountsRequest
+type ReorderEmailAccountsRequest struct {
+	AccountIDs []int64 `json:"account_ids"`
+}
+
+// ReorderEmailGroupsRequest 对应组件 ReorderEmailGroupsRequest
+type ReorderEmailGroupsRequest struct {
+	GroupIDs []int64 `json:"group_ids"`
+}
+
+// ReparseJob 对应组件 ReparseJob
+type ReparseJob struct {
+	Failed     int64      `json:"failed,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	ID         string     `json:"id,omitempty"`
+	LastError  string     `json:"last_error,omitempty"`
+	Processed  int64      `json:"processed,omitempty"`
+	StartedAt  time.Time  `json:"started_at,omitempty"`
+	Status     string     `json:"status,omitempty"`
+	Succeeded  int64      `json:"succeeded,omitempty"`
+	Total      int64      `json:"total,omitempty"`
+}
+
+// ReplyEmailRequest 对应组件 ReplyEmailRequest
+type ReplyEmailRequest struct {
+	AccountID int64           `json:"account_id"`
+	BCC       []*EmailAddress `json:"bcc,omitempty"`
+	CC        []*EmailAddress `json:"cc,omitempty"`
+	HTMLBody  string          `json:"html_body,omitempty"`
+	Subject   string          `json:"subject,omitempty"`
+	TextBody  string          `json:"text_body,omitempty"`
+	To        []*EmailAddress `json:"to,omitempty"`
+}
+
+// ReplyLaterRequest 对应组件 ReplyLaterRequest
+type ReplyLaterRequest struct {
+	RemindAt *time.Time `json:"remind_at,omitempty"`
+}
+
+// Request 对应组件 Request
+type Request struct {
+	OperationName string                 `json:"operationName,omitempty"`
+	Query         string                 `json:"query,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// ResolveDuplicatesRequest 对应组件 ResolveDuplicatesRequest
+type ResolveDuplicatesRequest struct {
+	Action         string                 `json:"action"`
+	Groups         []*DuplicateResolution `json:"groups,omitempty"`
+	TargetFolderID *int64                 `json:"target_folder_id,omitempty"`
+}
+
+// ResolveDuplicatesResult 对应组件 ResolveDuplicatesResult
+type ResolveDuplicatesResult struct {
+	Errors    []string          `json:"errors,omitempty"`
+	Failed    int64             `json:"failed,omitempty"`
+	Groups    int64             `json:"groups,omitempty"`
+	Succeeded int64             `json:"succeeded,omitempty"`
+	Transfer  *EmailTransferJob `json:"transfer,omitempty"`
+}
+
+// ResolveSyncConflictRequest 对应组件 ResolveSyncConflictRequest
+type ResolveSyncConflictRequest struct {
+	Resolution string `json:"resolution"`
+}
+
+// Response 对应组件 Response
+type Response struct {
+	Data   interface{} `json:"data,omitempty"`
+	Errors []*Error    `json:"errors,omitempty"`
+}
+
+// RetentionPolicy 对应组件 RetentionPolicy
+type RetentionPolicy struct {
+	AccountID     int64      `json:"account_id,omitempty"`
+	Action        string     `json:"action,omitempty"`
+	CreatedAt     time.Time  `json:"created_at,omitempty"`
+	FolderID      *int64     `json:"folder_id,omitempty"`
+	ID            int64      `json:"id,omitempty"`
+	IsEnabled     bool       `json:"is_enabled,omitempty"`
+	LastRunAt     *time.Time `json:"last_run_at,omitempty"`
+	Name          string     `json:"name,omitempty"`
+	Newsletters   bool       `json:"newsletters,omitempty"`
+	OlderThanDays int64      `json:"older_than_days,omitempty"`
+	UnreadOnly    bool       `json:"unread_only,omitempty"`
+	UpdatedAt     time.Time  `json:"updated_at,omitempty"`
+}
+
+// RetentionPolicyRequest 对应组件 RetentionPolicyRequest
+type RetentionPolicyRequest struct {
+	AccountID     int64  `json:"account_id"`
+	Action        string `json:"action"`
+	FolderID      *int64 `json:"folder_id,omitempty"`
+	IsEnabled     *bool  `json:"is_enabled,omitempty"`
+	Name          string `json:"name"`
+	Newsletters   bool   `json:"newsletters,omitempty"`
+	OlderThanDays int64  `json:"older_than_days"`
+	UnreadOnly    bool   `json:"unread_only,omitempty"`
+}
+
+// RetentionPreview 对应组件 RetentionPreview
+type RetentionPreview struct {
+	Action       string                 `json:"action,omitempty"`
+	Cutoff       time.Time              `json:"cutoff,omitempty"`
+	Matched      int64                  `json:"matched,omitempty"`
+	MatchedBytes int64                  `json:"matched_bytes,omitempty"`
+	PolicyID     int64                  `json:"policy_id,omitempty"`
+	Samples      []*StorageEmailSummary `json:"samples,omitempty"`
+}
+
+// RetentionRun 对应组件 RetentionRun
+type RetentionRun struct {
+	Failed      int64      `json:"failed,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	FreedBytes  int64      `json:"freed_bytes,omitempty"`
+	ID          int64      `json:"id,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	Matched     int64      `json:"matched,omitempty"`
+	PolicyID    int64      `json:"policy_id,omitempty"`
+	StartedAt   time.Time  `json:"started_at,omitempty"`
+	Status      string     `json:"status,omitempty"`
+	Succeeded   int64      `json:"succeeded,omitempty"`
+	TriggeredBy string     `json:"triggered_by,omitempty"`
+}
+
+// SaveDraftRequest 对应组件 SaveDraftRequest
+type SaveDraftRequest struct {
+	AccountID              int64                  `json:"account_id"`
+	AttachmentIDs          []int64                `json:"attachment_ids,omitempty"`
+	Attachments            []*EmailAttachment     `json:"attachments,omitempty"`
+	BCC                    []*EmailAddress        `json:"bcc,omitempty"`
+	CC                     []*EmailAddress        `json:"cc,omitempty"`
+	CodeHighlight          *bool                  `json:"code_highlight,omitempty"`
+	DisableTextAlternative bool                   `json:"disable_text_alternative,omitempty"`
+	EnvelopeFrom           string                 `json:"envelope_from,omitempty"`
+	From                   *EmailAddress          `json:"from"`
+	Headers                map[string]string      `json:"headers,omitempty"`
+	HTMLBody               string                 `json:"html_body,omitempty"`
+	Importance             string                 `json:"importance,omitempty"`
+	InlineAttachments      []*InlineAttachment    `json:"inline_attachments,omitempty"`
+	MarkdownBody           string                 `json:"markdown_body,omitempty"`
+	Priority               string                 `json:"priority,omitempty"`
+	ReplyTo                *EmailAddress          `json:"reply_to,omitempty"`
+	RequestDeliveryReceipt bool                   `json:"request_delivery_receipt,omitempty"`
+	RequestReadReceipt     bool                   `json:"request_read_receipt,omitempty"`
+	ScheduledTime          *string                `json:"scheduled_time,omitempty"`
+	Subject                string                 `json:"subject"`
+	TemplateData           map[string]interface{} `json:"template_data,omitempty"`
+	TemplateID             *int64                 `json:"template_id,omitempty"`
+	TextBody               string                 `json:"text_body,omitempty"`
+	To                     []*EmailAddress        `json:"to"`
+}
+
+// SecurityEvent 对应组件 SecurityEvent
+type SecurityEvent struct {
+	CountryCode string    `json:"country_code,omitempty"`
+	CreatedAt   time.Time `json:"created_at,omitempty"`
+	EventType   string    `json:"event_type,omitempty"`
+	ID          int64     `json:"id,omitempty"`
+	IP          string    `json:"ip,omitempty"`
+	Method      string    `json:"method,omitempty"`
+	Path        string    `json:"path,omitempty"`
+	Reason      string    `json:"reason,omitempty"`
+	UserAgent   string    `json:"user_agent,omitempty"`
+	Username    string    `json:"username,omitempty"`
+}
+
+// SendEmailAttachment 对应组件 SendEmailAttachment
+type SendEmailAttachment struct {
+	Content     []byte `json:"content"`
+	ContentID   string `json:"content_id,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Disposition string `json:"disposition,omitempty"`
+	Filename    string `json:"filename"`
+	Size        int64  `json:"size,omitempty"`
+}
+
+// SendEmailRequest 对应组件 SendEmailRequest
+type SendEmailRequest struct {
+	AccountID               int64                  `json:"account_id"`
+	AttachmentIDs           []int64                `json:"attachment_ids,omitempty"`
+	Attachments             []*SendEmailAttachment `json:"attachments,omitempty"`
+	BCC                     []*EmailAddress        `json:"bcc,omitempty"`
+	CC                      []*EmailAddress        `json:"cc,omitempty"`
+	CodeHighlight           *bool                  `json:"code_highlight,omitempty"`
+	DraftID                 *int64                 `json:"draft_id,omitempty"`
+	EnvelopeFrom            string                 `json:"envelope_from,omitempty"`
+	HTMLBody                string                 `json:"html_body,omitempty"`
+	MarkdownBody            string                 `json:"markdown_body,omitempty"`
+	OffloadLargeAttachments bool                   `json:"offload_large_attachments,omitempty"`
+	Priority                string                 `json:"priority,omitempty"`
+	ReplyToID               *int64                 `json:"reply_to_id,omitempty"`
+	Subject                 string                 `json:"subject"`
+	TextBody                string                 `json:"text_body,omitempty"`
+	To                      []*EmailAddress        `json:"to"`
+}
+
+// SenderVolume 对应组件 SenderVolume
+type SenderVolume struct {
+	Emails     int64     `json:"emails,omitempty"`
+	NewestDate time.Time `json:"newest_date,omitempty"`
+	OldestDate time.Time `json:"oldest_date,omitempty"`
+	Sender     string    `json:"sender,omitempty"`
+	TotalSize  int64     `json:"total_size,omitempty"`
+	Unread     int64     `json:"unread,omitempty"`
+}
+
+// ServiceStats 对应组件 ServiceStats
+type ServiceStats struct {
+	ConnectionsByUser map[string]int64 `json:"connections_by_user,omitempty"`
+	EventsByType      map[string]int64 `json:"events_by_type,omitempty"`
+	EventsPublished   int64            `json:"events_published,omitempty"`
+	LastEventTime     *time.Time       `json:"last_event_time,omitempty"`
+	StartTime         time.Time        `json:"start_time,omitempty"`
+	TotalConnections  int64            `json:"total_connections,omitempty"`
+}
+
+// SetEmailFlagRequest 对应组件 SetEmailFlagRequest
+type SetEmailFlagRequest struct {
+	Color      *string    `json:"color,omitempty"`
+	Completed  bool       `json:"completed,omitempty"`
+	FollowUp   *bool      `json:"follow_up,omitempty"`
+	FollowUpAt *time.Time `json:"follow_up_at,omitempty"`
+}
+
+// Setting 对应组件 Setting
+type Setting struct {
+	Key    string `json:"key,omitempty"`
+	Path   string `json:"path,omitempty"`
+	Secret bool   `json:"secret,omitempty"`
+	Source string `json:"source,omitempty"`
+	Value  string `json:"value,omitempty"`
+}
+
+// ShareMailboxRequest 对应组件 ShareMailboxRequest
+type ShareMailboxRequest struct {
+	AccountID int64 `json:"account_id"`
+}
+
+// SharedMailbox 对应组件 SharedMailbox
+type SharedMailbox struct {
+	Account        *EmailAccount         `json:"account,omitempty"`
+	AccountID      int64                 `json:"account_id,omitempty"`
+	CreatedAt      time.Time             `json:"created_at,omitempty"`
+	Grants         []*SharedMailboxGrant `json:"grants,omitempty"`
+	ID             int64                 `json:"id,omitempty"`
+	OrganizationID int64                 `json:"organization_id,omitempty"`
+	SharedBy       int64                 `json:"shared_by,omitempty"`
+}
+
+// SharedMailboxEmail 对应组件 SharedMailboxEmail
+type SharedMailboxEmail struct {
+	Account             *EmailAccount    `json:"account,omitempty"`
+	AccountID           int64            `json:"account_id,omitempty"`
+	Assignment          *EmailAssignment `json:"assignment,omitempty"`
+	AttachmentCount     int64            `json:"attachment_count,omitempty"`
+	Attachments         []*Attachment    `json:"attachments,omitempty"`
+	BCC                 string           `json:"bcc,omitempty"`
+	CC                  string           `json:"cc,omitempty"`
+	CreatedAt           time.Time        `json:"created_at,omitempty"`
+	Date                time.Time        `json:"date,omitempty"`
+	DeletedAt           *time.Time       `json:"deleted_at,omitempty"`
+	FlagColor           string           `json:"flag_color,omitempty"`
+	Folder              *Folder          `json:"folder,omitempty"`
+	FolderID            *int64           `json:"folder_id,omitempty"`
+	FollowUpAt          *time.Time       `json:"follow_up_at,omitempty"`
+	FollowUpCompletedAt *time.Time       `json:"follow_up_completed_at,omitempty"`
+	From                string           `json:"from,omitempty"`
+	HasAttachment       bool             `json:"has_attachment,omitempty"`
+	HTMLBody            string           `json:"html_body,omitempty"`
+	ID                  int64            `json:"id,omitempty"`
+	ImportanceBucket    string           `json:"importance_bucket,omitempty"`
+	ImportanceManual    bool             `json:"importance_manual,omitempty"`
+	ImportanceScore     int64            `json:"importance_score,omitempty"`
+	IsAnswered          bool             `json:"is_answered,omitempty"`
+	IsDeleted           bool             `json:"is_deleted,omitempty"`
+	IsDraft             bool             `json:"is_draft,omitempty"`
+	IsFollowUp          bool             `json:"is_follow_up,omitempty"`
+	IsForwarded         bool             `json:"is_forwarded,omitempty"`
+	IsImportant         bool             `json:"is_important,omitempty"`
+	IsPinned            bool             `json:"is_pinned,omitempty"`
+	IsRead              bool             `json:"is_read,omitempty"`
+	IsSent              bool             `json:"is_sent,omitempty"`
+	IsStarred           bool             `json:"is_starred,omitempty"`
+	IsVip               bool             `json:"is_vip,omitempty"`
+	Labels              string           `json:"labels,omitempty"`
+	MessageID           string           `json:"message_id,omitempty"`
+	Notes               []*EmailNote     `json:"notes,omitempty"`
+	PinnedAt            *time.Time       `json:"pinned_at,omitempty"`
+	Preview             *string          `json:"preview,omitempty"`
+	Priority            string           `json:"priority,omitempty"`
+	ReplyLaterAt        *time.Time       `json:"reply_later_at,omitempty"`
+	ReplyLaterRemindAt  *time.Time       `json:"reply_later_remind_at,omitempty"`
+	ReplyTo             string           `json:"reply_to,omitempty"`
+	SenderAvatarHash    string           `json:"sender_avatar_hash,omitempty"`
+	SenderInitials      string           `json:"sender_initials,omitempty"`
+	Size                int64            `json:"size,omitempty"`
+	Subject             string           `json:"subject,omitempty"`
+	SyncedAt            *time.Time       `json:"synced_at,omitempty"`
+	TextBody            string           `json:"text_body,omitempty"`
+	ThreadID            string           `json:"thread_id,omitempty"`
+	To                  string           `json:"to,omitempty"`
+	TrackersRemoved     int64            `json:"trackers_removed,omitempty"`
+	TrashedAt           *time.Time       `json:"trashed_at,omitempty"`
+	UID                 int64            `json:"uid,omitempty"`
+	UpdatedAt           time.Time        `json:"updated_at,omitempty"`
+	Version             int64            `json:"version,omitempty"`
+}
+
+// SharedMailboxGrant 对应组件 SharedMailboxGrant
+type SharedMailboxGrant struct {
+	CreatedAt       time.Time `json:"created_at,omitempty"`
+	ID              int64     `json:"id,omitempty"`
+	Permission      string    `json:"permission,omitempty"`
+	SharedMailboxID int64     `json:"shared_mailbox_id,omitempty"`
+	UpdatedAt       time.Time `json:"updated_at,omitempty"`
+	UserID          int64     `json:"user_id,omitempty"`
+}
+
+// SharedMailboxGrantRequest 对应组件 SharedMailboxGrantRequest
+type SharedMailboxGrantRequest struct {
+	Permission string `json:"permission"`
+	UserID     int64  `json:"user_id"`
+}
+
+// SharedMailboxInfo 对应组件 SharedMailboxInfo
+type SharedMailboxInfo struct {
+	AccountID      int64                 `json:"account_id,omitempty"`
+	AccountName    string                `json:"account_name,omitempty"`
+	CreatedAt      time.Time             `json:"created_at,omitempty"`
+	Email          string                `json:"email,omitempty"`
+	Grants         []*SharedMailboxGrant `json:"grants,omitempty"`
+	ID             int64                 `json:"id,omitempty"`
+	OrganizationID int64                 `json:"organization_id,omitempty"`
+	Permission     string                `json:"permission,omitempty"`
+	SharedBy       int64                 `json:"shared_by,omitempty"`
+}
+
+// StartDuplicateScanRequest 对应组件 StartDuplicateScanRequest
+type StartDuplicateScanRequest struct {
+	AccountIDs []int64 `json:"account_ids,omitempty"`
+	FolderIDs  []int64 `json:"folder_ids,omitempty"`
+}
+
+// StartReparseJobRequest 对应组件 StartReparseJobRequest
+type StartReparseJobRequest struct {
+	AccountID *int64     `json:"account_id,omitempty"`
+	FolderID  *int64     `json:"folder_id,omitempty"`
+	Since     *time.Time `json:"since,omitempty"`
+}
+
+// StartStorageCleanupRequest 对应组件 StartStorageCleanupRequest
+type StartStorageCleanupRequest struct {
+	Action        string `json:"action"`
+	DryRun        bool   `json:"dry_run,omitempty"`
+	FolderID      *int64 `json:"folder_id,omitempty"`
+	MinSize       int64  `json:"min_size,omitempty"`
+	Newsletters   bool   `json:"newsletters,omitempty"`
+	OlderThanDays int64  `json:"older_than_days,omitempty"`
+	Sender        string `json:"sender,omitempty"`
+	UnreadOnly    bool   `json:"unread_only,omitempty"`
+}
+
+// StorageAttachmentSummary 对应组件 StorageAttachmentSummary
+type StorageAttachmentSummary struct {
+	ContentType string    `json:"content_type,omitempty"`
+	Date        time.Time `json:"date,omitempty"`
+	EmailID     int64     `json:"email_id,omitempty"`
+	Filename    string    `json:"filename,omitempty"`
+	ID          int64     `json:"id,omitempty"`
+	Size        int64     `json:"size,omitempty"`
+	Subject     string    `json:"subject,omitempty"`
+}
+
+// StorageCleanupJob 对应组件 StorageCleanupJob
+type StorageCleanupJob struct {
+	AccountID    int64      `json:"account_id,omitempty"`
+	Action       string     `json:"action,omitempty"`
+	DryRun       bool       `json:"dry_run,omitempty"`
+	Failed       int64      `json:"failed,omitempty"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+	FreedBytes   int64      `json:"freed_bytes,omitempty"`
+	ID           string     `json:"id,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+	MatchedBytes int64      `json:"matched_bytes,omitempty"`
+	Processed    int64      `json:"processed,omitempty"`
+	StartedAt    time.Time  `json:"started_at,omitempty"`
+	Status       string     `json:"status,omitempty"`
+	Succeeded    int64      `json:"succeeded,omitempty"`
+	Total        int64      `json:"total,omitempty"`
+}
+
+// StorageEmailSummary 对应组件 StorageEmailSummary
+type StorageEmailSummary struct {
+	Date          time.Time `json:"date,omitempty"`
+	FolderID      *int64    `json:"folder_id,omitempty"`
+	From          string    `json:"from,omitempty"`
+	HasAttachment bool      `json:"has_attachment,omitempty"`
+	ID            int64     `json:"id,omitempty"`
+	IsRead        bool      `json:"is_read,omitempty"`
+	Size          int64     `json:"size,omitempty"`
+	Subject       string    `json:"subject,omitempty"`
+}
+
+// StuckSend 对应组件 StuckSend
+type StuckSend struct {
+	AccountID   int64      `json:"account_id,omitempty"`
+	Attempts    int64      `json:"attempts,omitempty"`
+	CreatedAt   time.Time  `json:"created_at,omitempty"`
+	HasMime     bool       `json:"has_mime,omitempty"`
+	ID          int64      `json:"id,omitempty"`
+	LastAttempt *time.Time `json:"last_attempt,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	NextAttempt *time.Time `json:"next_attempt,omitempty"`
+	Recipients  []string   `json:"recipients,omitempty"`
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+	SendID      string     `json:"send_id,omitempty"`
+	Status      string     `json:"status,omitempty"`
+	Subject     string     `json:"subject,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at,omitempty"`
+	UserID      int64      `json:"user_id,omitempty"`
+}
+
+// SyncConflict 对应组件 SyncConflict
+type SyncConflict struct {
+	AccountID  int64              `json:"account_id,omitempty"`
+	CreatedAt  time.Time          `json:"created_at,omitempty"`
+	EmailID    int64              `json:"email_id,omitempty"`
+	Fields     []string           `json:"fields,omitempty"`
+	ID         int64              `json:"id,omitempty"`
+	Local      *SyncConflictState `json:"local,omitempty"`
+	Policy     string             `json:"policy,omitempty"`
+	Remote     *SyncConflictState `json:"remote,omitempty"`
+	RemoteUID  int64              `json:"remote_uid,omitempty"`
+	Resolution string             `json:"resolution,omitempty"`
+	ResolvedAt *time.Time         `json:"resolved_at,omitempty"`
+	Status     string             `json:"status,omitempty"`
+	UpdatedAt  time.Time          `json:"updated_at,omitempty"`
+}
+
+// SyncConflictState 对应组件 SyncConflictState
+type SyncConflictState struct {
+	FolderID  *int64 `json:"folder_id,omitempty"`
+	IsDeleted bool   `json:"is_deleted,omitempty"`
+	IsRead    bool   `json:"is_read,omitempty"`
+	IsStarred bool   `json:"is_starred,omitempty"`
+}
+
+// TemplatePreview 对应组件 TemplatePreview
+type TemplatePreview struct {
+	HTMLBody            string   `json:"html_body,omitempty"`
+	InlineImages        []string `json:"inline_images,omitempty"`
+	MissingInlineImages []string `json:"missing_inline_images,omitempty"`
+	PreviewHTML         string   `json:"preview_html,omitempty"`
+	Subject             string   `json:"subject,omitempty"`
+	TextBody            string   `json:"text_body,omitempty"`
+}
+
+// TemplatePreviewRequest 对应组件 TemplatePreviewRequest
+type TemplatePreviewRequest struct {
+	Data              map[string]interface{} `json:"data,omitempty"`
+	IncludeSignature  *bool                  `json:"include_signature,omitempty"`
+	InlineAttachments []*InlineAttachment    `json:"inline_attachments,omitempty"`
+}
+
+// TemplateVariable 对应组件 TemplateVariable
+type TemplateVariable struct {
+	DefaultValue interface{} `json:"default_value,omitempty"`
+	Description  string      `json:"description,omitempty"`
+	Name         string      `json:"name,omitempty"`
+	Required     bool        `json:"required,omitempty"`
+	Type         string      `json:"type,omitempty"`
+}
+
+// TestEventRequest 对应组件 TestEventRequest
+type TestEventRequest struct {
+	Data    interface{} `json:"data,omitempty"`
+	Message string      `json:"message"`
+	Type    string      `json:"type"`
+}
+
+// TestEventResult 对应组件 TestEventResult
+type TestEventResult struct {
+	EventID string `json:"event_id,omitempty"`
+	Message string `json:"message,omitempty"`
+	Type    string `json:"type,omitempty"`
+}
+
+// TrackingOptOut 对应组件 TrackingOptOut
+type TrackingOptOut struct {
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	ID        int64     `json:"id,omitempty"`
+	Pattern   string    `json:"pattern,omitempty"`
+}
+
+// TrackingOptOutRequest 对应组件 TrackingOptOutRequest
+type TrackingOptOutRequest struct {
+	Pattern string `json:"pattern"`
+}
+
+// TrashEmailsRequest 对应组件 TrashEmailsRequest
+type TrashEmailsRequest struct {
+	EmailIDs []int64 `json:"email_ids"`
+}
+
+// TrashOperationResponse 对应组件 TrashOperationResponse
+type TrashOperationResponse struct {
+	Affected int64 `json:"affected,omitempty"`
+}
+
+// UpdateBlockedSenderRequest 对应组件 UpdateBlockedSenderRequest
+type UpdateBlockedSenderRequest struct {
+	Action string `json:"action"`
+}
+
+// UpdateDraftRequest 对应组件 UpdateDraftRequest
+type UpdateDraftRequest struct {
+	AttachmentIDs []int64         `json:"attachment_ids,omitempty"`
+	BCC           []*EmailAddress `json:"bcc,omitempty"`
+	CC            []*EmailAddress `json:"cc,omitempty"`
+	HTMLBody      *string         `json:"html_body,omitempty"`
+	MarkdownBody  *string         `json:"markdown_body,omitempty"`
+	Priority      *string         `json:"priority,omitempty"`
+	Subject       *string         `json:"subject,omitempty"`
+	TextBody      *string         `json:"text_body,omitempty"`
+	To            []*EmailAddress `json:"to,omitempty"`
+}
+
+// UpdateEmailAccountRequest 对应组件 UpdateEmailAccountRequest
+type UpdateEmailAccountRequest struct {
+	AvatarEmoji           *string  `json:"avatar_emoji,omitempty"`
+	AvatarInitials        *string  `json:"avatar_initials,omitempty"`
+	BounceAddress         *string  `json:"bounce_address,omitempty"`
+	Color                 *string  `json:"color,omitempty"`
+	DisplayOrder          *int64   `json:"display_order,omitempty"`
+	DnsResolver           *string  `json:"dns_resolver,omitempty"`
+	EhloName              *string  `json:"ehlo_name,omitempty"`
+	GroupID               *int64   `json:"group_id,omitempty"`
+	IMAPHost              *string  `json:"imap_host,omitempty"`
+	IMAPPort              *int64   `json:"imap_port,omitempty"`
+	IMAPSecurity          *string  `json:"imap_security,omitempty"`
+	IsActive              *bool    `json:"is_active,omitempty"`
+	Mailer                *string  `json:"mailer,omitempty"`
+	MaxPartSize           *int64   `json:"max_part_size,omitempty"`
+	MessageIDDomain       *string  `json:"message_id_domain,omitempty"`
+	Name                  *string  `json:"name,omitempty"`
+	Nickname              *string  `json:"nickname,omitempty"`
+	NotificationsMuted    *bool    `json:"notifications_muted,omitempty"`
+	Password              *string  `json:"password,omitempty"`
+	SendAliases           []string `json:"send_aliases,omitempty"`
+	SMTPHost              *string  `json:"smtp_host,omitempty"`
+	SMTPPort              *int64   `json:"smtp_port,omitempty"`
+	SMTPSecurity          *string  `json:"smtp_security,omitempty"`
+	TLSCaCert             *string  `json:"tls_ca_cert,omitempty"`
+	TLSInsecureSkipVerify *bool    `json:"tls_insecure_skip_verify,omitempty"`
+	TLSMinVersion         *string  `json:"tls_min_version,omitempty"`
+	TLSPinnedKeys         []string `json:"tls_pinned_keys,omitempty"`
+	Version               *int64   `json:"version,omitempty"`
+}
+
+// UpdateEmailGroupRequest 对应组件 UpdateEmailGroupRequest
+type UpdateEmailGroupRequest struct {
+	Name *string `json:"name,omitempty"`
+}
+
+// UpdateEmailNoteRequest 对应组件 UpdateEmailNoteRequest
+type UpdateEmailNoteRequest struct {
+	Content      *string `json:"content,omitempty"`
+	SyncToServer *bool   `json:"sync_to_server,omitempty"`
+}
+
+// UpdateEmailRequest 对应组件 UpdateEmailRequest
+type UpdateEmailRequest struct {
+	FolderID    *int64 `json:"folder_id,omitempty"`
+	IsImportant *bool  `json:"is_important,omitempty"`
+	IsRead      *bool  `json:"is_read,omitempty"`
+	IsStarred   *bool  `json:"is_starred,omitempty"`
+	Version     *int64 `json:"version,omitempty"`
+}
+
+// UpdateEmailShareRequest 对应组件 UpdateEmailShareRequest
+type UpdateEmailShareRequest struct {
+	AllowAttachments *bool `json:"allow_attachments,omitempty"`
+}
+
+// UpdateEmailTemplateRequest 对应组件 UpdateEmailTemplateRequest
+type UpdateEmailTemplateRequest struct {
+	Category    *string             `json:"category,omitempty"`
+	Description *string             `json:"description,omitempty"`
+	HTMLBody    *string             `json:"html_body,omitempty"`
+	IsActive    *bool               `json:"is_active,omitempty"`
+	IsShared    *bool               `json:"is_shared,omitempty"`
+	Name        *string             `json:"name,omitempty"`
+	Subject     *string             `json:"subject,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	TextBody    *string             `json:"text_body,omitempty"`
+	Variables   []*TemplateVariable `json:"variables,omitempty"`
+}
+
+// UpdateFolderRequest 对应组件 UpdateFolderRequest
+type UpdateFolderRequest struct {
+	DisplayName *string `json:"display_name,omitempty"`
+	Name        *string `json:"name,omitempty"`
+	ParentID    *int64  `json:"parent_id,omitempty"`
+	Type        *string `json:"type,omitempty"`
+}
+
+// UpdateGmailLabelMappingsRequest 对应组件 UpdateGmailLabelMappingsRequest
+type UpdateGmailLabelMappingsRequest struct {
+	Mappings []*GmailLabelModeUpdate `json:"mappings"`
+}
+
+// UpdateIngestEndpointRequest 对应组件 UpdateIngestEndpointRequest
+type UpdateIngestEndpointRequest struct {
+	Address   *string `json:"address,omitempty"`
+	IsEnabled *bool   `json:"is_enabled,omitempty"`
+	Name      *string `json:"name,omitempty"`
+}
+
+// UpdateLabelAppearanceRequest 对应组件 UpdateLabelAppearanceRequest
+type UpdateLabelAppearanceRequest struct {
+	Color     *string `json:"color,omitempty"`
+	Icon      *string `json:"icon,omitempty"`
+	Name      string  `json:"name"`
+	SortOrder *int64  `json:"sort_order,omitempty"`
+}
+
+// UpdateNotificationRequest 对应组件 UpdateNotificationRequest
+type UpdateNotificationRequest struct {
+	IsRead *bool `json:"is_read"`
+}
+
+// UpdateOrganizationMemberRequest 对应组件 UpdateOrganizationMemberRequest
+type UpdateOrganizationMemberRequest struct {
+	Role string `json:"role"`
+}
+
+// UpdateUserSettingsRequest 对应组件 UpdateUserSettingsRequest
+type UpdateUserSettingsRequest struct {
+	BlockRemoteImages  *bool   `json:"block_remote_images,omitempty"`
+	EmailsPerPage      *int64  `json:"emails_per_page,omitempty"`
+	Locale             *string `json:"locale,omitempty"`
+	NotificationsMuted *bool   `json:"notifications_muted,omitempty"`
+	ReplyAll           *bool   `json:"reply_all,omitempty"`
+	Signature          *string `json:"signature,omitempty"`
+	StripTrackers      *bool   `json:"strip_trackers,omitempty"`
+	SyncConflictPolicy *string `json:"sync_conflict_policy,omitempty"`
+	Timezone           *string `json:"timezone,omitempty"`
+}
+
+// User 对应组件 User
+type User struct {
+	CreatedAt     time.Time       `json:"created_at,omitempty"`
+	DeletedAt     *time.Time      `json:"deleted_at,omitempty"`
+	DisplayName   string          `json:"display_name,omitempty"`
+	Email         string          `json:"email,omitempty"`
+	EmailAccounts []*EmailAccount `json:"email_accounts,omitempty"`
+	ID            int64           `json:"id,omitempty"`
+	IsActive      bool            `json:"is_active,omitempty"`
+	LastLoginAt   *time.Time      `json:"last_login_at,omitempty"`
+	LoginCount    int64           `json:"login_count,omitempty"`
+	Role          string          `json:"role,omitempty"`
+	UpdatedAt     time.Time       `json:"updated_at,omitempty"`
+	Username      string          `json:"username,omitempty"`
+}
+
+// UserSettings 对应组件 UserSettings
+type UserSettings struct {
+	BlockRemoteImages  bool   `json:"block_remote_images,omitempty"`
+	EmailsPerPage      int64  `json:"emails_per_page,omitempty"`
+	Locale             string `json:"locale,omitempty"`
+	NotificationsMuted bool   `json:"notifications_muted,omitempty"`
+	ReplyAll           bool   `json:"reply_all,omitempty"`
+	Signature          string `json:"signature,omitempty"`
+	StripTrackers      bool   `json:"strip_trackers,omitempty"`
+	SyncConflictPolicy string `json:"sync_conflict_policy,omitempty"`
+	Timezone           string `json:"timezone,omitempty"`
+}
+
+// VIPSender 对应组件 VIPSender
+type VIPSender struct {
+	Address   string    `json:"address,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	ID        int64     `json:"id,omitempty"`
+	Name      string    `json:"name,omitempty"`
+}
+
+// VerificationCode 对应组件 VerificationCode
+type VerificationCode struct {
+	AccountID int64     `json:"account_id,omitempty"`
+	Code      string    `json:"code,omitempty"`
+	Date      time.Time `json:"date,omitempty"`
+	EmailID   int64     `json:"email_id,omitempty"`
+	FolderID  *int64    `json:"folder_id,omitempty"`
+	From      string    `json:"from,omitempty"`
+	Subject   string    `json:"subject,omitempty"`
+}
+
+// GetMailboxStorageReportParams GetMailboxStorageReport 的查询参数
+type GetMailboxStorageReportParams struct {
+	Limit *int64
+}
+
+func (p *GetMailboxStorageReportParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "limit", p.Limit)
+	return query
+}
+
+// GetAuthFailuresParams GetAuthFailures 的查询参数
+type GetAuthFailuresParams struct {
+	// 统计窗口，如 30m、24h，默认使用 AUTH_FAILURE_BAN_WINDOW
+	Window *string
+}
+
+func (p *GetAuthFailuresParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "window", p.Window)
+	return query
+}
+
+// ListJobsParams ListJobs 的查询参数
+type ListJobsParams struct {
+	// 任务状态 pending/running/succeeded/dead/cancelled，为空时返回全部
+	Status *string
+	// 任务类型，如 sync.account、send.deliver
+	Type *string
+	// 返回条数，默认100，最多1000
+	Limit *int64
+}
+
+func (p *ListJobsParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "status", p.Status)
+	addQuery(query, "type", p.Type)
+	addQuery(query, "limit", p.Limit)
+	return query
+}
+
+// GetSecurityEventsParams GetSecurityEvents 的查询参数
+type GetSecurityEventsParams struct {
+	// 事件类型，如 ip_denied、auth_failed，为空时返回全部
+	Type *string
+	// 来源IP
+	IP *string
+	// 起始时间，RFC3339格式
+	Since *string
+	// 返回条数，默认100，最多1000
+	Limit *int64
+}
+
+func (p *GetSecurityEventsParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "type", p.Type)
+	addQuery(query, "ip", p.IP)
+	addQuery(query, "since", p.Since)
+	addQuery(query, "limit", p.Limit)
+	return query
+}
+
+// GetStuckSendsParams GetStuckSends 的查询参数
+type GetStuckSendsParams struct {
+	// 卡住判定时长，如 15m、2h，默认15m
+	OlderThan *string
+}
+
+func (p *GetStuckSendsParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "older_than", p.OlderThan)
+	return query
+}
+
+// GetBusiestHoursParams GetBusiestHours 的查询参数
+type GetBusiestHoursParams struct {
+	AccountID *int64
+	From      *time.Time
+	To        *time.Time
+	Interval  *string
+	Tz        *string
+	Limit     *int64
+}
+
+func (p *GetBusiestHoursParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "account_id", p.AccountID)
+	addQuery(query, "from", p.From)
+	addQuery(query, "to", p.To)
+	addQuery(query, "interval", p.Interval)
+	addQuery(query, "tz", p.Tz)
+	addQuery(query, "limit", p.Limit)
+	return query
+}
+
+// GetOutboundAccountsParams GetOutboundAccounts 的查询参数
+type GetOutboundAccountsParams struct {
+	AccountID *int64
+	From      *time.Time
+	To        *time.Time
+	Interval  *string
+	Tz        *string
+	Limit     *int64
+}
+
+func (p *GetOutboundAccountsParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "account_id", p.AccountID)
+	addQuery(query, "from", p.From)
+	addQuery(query, "to", p.To)
+	addQuery(query, "interval", p.Interval)
+	addQuery(query, "tz", p.Tz)
+	addQuery(query, "limit", p.Limit)
+	return query
+}
+
+// GetOutboundContactsParams GetOutboundContacts 的查询参数
+type GetOutboundContactsParams struct {
+	AccountID *int64
+	From      *time.Time
+	To        *time.Time
+	Interval  *string
+	Tz        *string
+	Limit     *int64
+}
+
+func (p *GetOutboundContactsParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "account_id", p.AccountID)
+	addQuery(query, "from", p.From)
+	addQuery(query, "to", p.To)
+	addQuery(query, "interval", p.Interval)
+	addQuery(query, "tz", p.Tz)
+	addQuery(query, "limit", p.Limit)
+	return query
+}
+
+// GetOutboundMessagesParams GetOutboundMessages 的查询参数
+type GetOutboundMessagesParams struct {
+	// 只返回未收到回复的邮件
+	Unreplied *bool
+	AccountID *int64
+	From      *time.Time
+	To        *time.Time
+	Interval  *string
+	Tz        *string
+	Limit     *int64
+}
+
+func (p *GetOutboundMessagesParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "unreplied", p.Unreplied)
+	addQuery(query, "account_id", p.AccountID)
+	addQuery(query, "from", p.From)
+	addQuery(query, "to", p.To)
+	addQuery(query, "interval", p.Interval)
+	addQuery(query, "tz", p.Tz)
+	addQuery(query, "limit", p.Limit)
+	return query
+}
+
+// GetResponseTimesParams GetResponseTimes 的查询参数
+type GetResponseTimesParams struct {
+	AccountID *int64
+	From      *time.Time
+	To        *time.Time
+	Interval  *string
+	Tz        *string
+	Limit     *int64
+}
+
+func (p *GetResponseTimesParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "account_id", p.AccountID)
+	addQuery(query, "from", p.From)
+	addQuery(query, "to", p.To)
+	addQuery(query, "interval", p.Interval)
+	addQuery(query, "tz", p.Tz)
+	addQuery(query, "limit", p.Limit)
+	return query
+}
+
+// GetTopRecipientsParams GetTopRecipients 的查询参数
+type GetTopRecipientsParams struct {
+	AccountID *int64
+	From      *time.Time
+	To        *time.Time
+	Interval  *string
+	Tz        *string
+	Limit     *int64
+}
+
+func (p *GetTopRecipientsParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "account_id", p.AccountID)
+	addQuery(query, "from", p.From)
+	addQuery(query, "to", p.To)
+	addQuery(query, "interval", p.Interval)
+	addQuery(query, "tz", p.Tz)
+	addQuery(query, "limit", p.Limit)
+	return query
+}
+
+// GetTopSendersParams GetTopSenders 的查询参数
+type GetTopSendersParams struct {
+	AccountID *int64
+	From      *time.Time
+	To        *time.Time
+	Interval  *string
+	Tz        *string
+	Limit     *int64
+}
+
+func (p *GetTopSendersParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "account_id", p.AccountID)
+	addQuery(query, "from", p.From)
+	addQuery(query, "to", p.To)
+	addQuery(query, "interval", p.Interval)
+	addQuery(query, "tz", p.Tz)
+	addQuery(query, "limit", p.Limit)
+	return query
+}
+
+// GetEmailVolumeParams GetEmailVolume 的查询参数
+type GetEmailVolumeParams struct {
+	AccountID *int64
+	From      *time.Time
+	To        *time.Time
+	Interval  *string
+	Tz        *string
+	Limit     *int64
+}
+
+func (p *GetEmailVolumeParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "account_id", p.AccountID)
+	addQuery(query, "from", p.From)
+	addQuery(query, "to", p.To)
+	addQuery(query, "interval", p.Interval)
+	addQuery(query, "tz", p.Tz)
+	addQuery(query, "limit", p.Limit)
+	return query
+}
+
+// ListAttachmentsParams ListAttachments 的查询参数
+type ListAttachmentsParams struct {
+	AccountID     *int64
+	Type          *string
+	Since         *time.Time
+	Until         *time.Time
+	MinSize       *int64
+	MaxSize       *int64
+	Filename      *string
+	IncludeInline *bool
+	Page          *int64
+	PageSize      *int64
+}
+
+func (p *ListAttachmentsParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "account_id", p.AccountID)
+	addQuery(query, "type", p.Type)
+	addQuery(query, "since", p.Since)
+	addQuery(query, "until", p.Until)
+	addQuery(query, "min_size", p.MinSize)
+	addQuery(query, "max_size", p.MaxSize)
+	addQuery(query, "filename", p.Filename)
+	addQuery(query, "include_inline", p.IncludeInline)
+	addQuery(query, "page", p.Page)
+	addQuery(query, "page_size", p.PageSize)
+	return query
+}
+
+// UploadAttachmentForm 对应组件 UploadAttachmentForm
+type UploadAttachmentForm struct {
+}
+
+// DownloadAttachmentWithTokenParams DownloadAttachmentWithToken 的查询参数
+type DownloadAttachmentWithTokenParams struct {
+	// 附件下载令牌
+	Token *string
+}
+
+func (p *DownloadAttachmentWithTokenParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "token", p.Token)
+	return query
+}
+
+// CreateMailMergeCampaignForm 对应组件 CreateMailMergeCampaignForm
+type CreateMailMergeCampaignForm struct {
+	AccountID     int64  `json:"account_id"`
+	Name          string `json:"name"`
+	RatePerMinute int64  `json:"rate_per_minute,omitempty"`
+	TemplateID    int64  `json:"template_id"`
+	TrackClicks   bool   `json:"track_clicks,omitempty"`
+	TrackOpens    bool   `json:"track_opens,omitempty"`
+	UseVerp       bool   `json:"use_verp,omitempty"`
+}
+
+// GetMailMergeRecipientsParams GetMailMergeRecipients 的查询参数
+type GetMailMergeRecipientsParams struct {
+	Status   *string
+	Page     *int64
+	PageSize *int64
+}
+
+func (p *GetMailMergeRecipientsParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "status", p.Status)
+	addQuery(query, "page", p.Page)
+	addQuery(query, "page_size", p.PageSize)
+	return query
+}
+
+// GetCannedQuickListParams GetCannedQuickList 的查询参数
+type GetCannedQuickListParams struct {
+	Q        *string
+	FolderID *int64
+	Limit    *int64
+}
+
+func (p *GetCannedQuickListParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "q", p.Q)
+	addQuery(query, "folder_id", p.FolderID)
+	addQuery(query, "limit", p.Limit)
+	return query
+}
+
+// GetChangesParams GetChanges 的查询参数
+type GetChangesParams struct {
+	// 上次获取的变更令牌
+	Since *int64
+	// 最多返回的变更数
+	Limit *int64
+}
+
+func (p *GetChangesParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "since", p.Since)
+	addQuery(query, "limit", p.Limit)
+	return query
+}
+
+// GetEmailsParams GetEmails 的查询参数
+type GetEmailsParams struct {
+	// 按账户过滤
+	AccountID *int64
+	// 按文件夹过滤
+	FolderID *int64
+	// 按已读状态过滤
+	IsRead *bool
+	// 按星标过滤
+	IsStarred *bool
+	// 按重要标记过滤
+	IsImportant *bool
+	// 按优先收件箱分类过滤：important 或 other
+	ImportanceBucket *string
+	// 只看或排除VIP发件人的邮件
+	IsVip *bool
+	// 按旗标颜色过滤：red、orange、yellow、green、blue、purple 或 gray
+	FlagColor *string
+	// 按后续跟进旗标过滤
+	IsFollowUp *bool
+	// 只返回跟进截止时间不晚于该时间的邮件（RFC3339）
+	FollowUpDue *time.Time
+	// 按已回复状态过滤
+	IsAnswered *bool
+	// 只返回等待我回复的邮件：尚未回复，且会话中之后没有已发送的邮件
+	AwaitingReply *bool
+	// 页码，从1开始
+	Page *int64
+	// 每页数量，1-100
+	PageSize *int64
+	// 排序字段，默认date
+	SortBy *string
+	// asc 或 desc，默认desc
+	SortOrder *string
+	// 关键词过滤
+	Search *string
+	// 上一页返回的next_cursor，提供时忽略page
+	Cursor *string
+	// 置顶邮件排在最前，不能与cursor同时使用
+	PinnedFirst *bool
+	// full（默认）返回不含正文的完整邮件；summary 只在 summaries 中返回预览、标记和头像等列表摘要
+	View *string
+	// date 按用户时区的今天、昨天、近一周、近一月和更早分组，要求按日期排序；sender 按发件人分组，不能与cursor同时使用
+	GroupBy *string
+}
+
+func (p *GetEmailsParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "account_id", p.AccountID)
+	addQuery(query, "folder_id", p.FolderID)
+	addQuery(query, "is_read", p.IsRead)
+	addQuery(query, "is_starred", p.IsStarred)
+	addQuery(query, "is_important", p.IsImportant)
+	addQuery(query, "importance_bucket", p.ImportanceBucket)
+	addQuery(query, "is_vip", p.IsVip)
+	addQuery(query, "flag_color", p.FlagColor)
+	addQuery(query, "is_follow_up", p.IsFollowUp)
+	addQuery(query, "follow_up_due", p.FollowUpDue)
+	addQuery(query, "is_answered", p.IsAnswered)
+	addQuery(query, "awaiting_reply", p.AwaitingReply)
+	addQuery(query, "page", p.Page)
+	addQuery(query, "page_size", p.PageSize)
+	addQuery(query, "sort_by", p.SortBy)
+	addQuery(query, "sort_order", p.SortOrder)
+	addQuery(query, "search", p.Search)
+	addQuery(query, "cursor", p.Cursor)
+	addQuery(query, "pinned_first", p.PinnedFirst)
+	addQuery(query, "view", p.View)
+	addQuery(query, "group_by", p.GroupBy)
+	return query
+}
+
+// ListDraftsParams ListDrafts 的查询参数
+type ListDraftsParams struct {
+	AccountID *int64
+	Page      *int64
+	PageSize  *int64
+	SortBy    *string
+	SortOrder *string
+}
+
+func (p *ListDraftsParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "account_id", p.AccountID)
+	addQuery(query, "page", p.Page)
+	addQuery(query, "page_size", p.PageSize)
+	addQuery(query, "sort_by", p.SortBy)
+	addQuery(query, "sort_order", p.SortOrder)
+	return query
+}
+
+// GetReplyLaterEmailsParams GetReplyLaterEmails 的查询参数
+type GetReplyLaterEmailsParams struct {
+	// 按账户过滤
+	AccountID *int64
+	// 页码，从1开始
+	Page *int64
+	// 每页数量，1-100
+	PageSize *int64
+}
+
+func (p *GetReplyLaterEmailsParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "account_id", p.AccountID)
+	addQuery(query, "page", p.Page)
+	addQuery(query, "page_size", p.PageSize)
+	return query
+}
+
+// SearchEmailsParams SearchEmails 的查询参数
+type SearchEmailsParams struct {
+	// 搜索关键词
+	Q *string
+	// 主题包含
+	Subject *string
+	// 发件人包含
+	From *string
+	// 收件人包含
+	To *string
+	// 正文包含
+	Body *string
+	// 私有笔记包含
+	Note *string
+	// 按账户过滤
+	AccountID *int64
+	// 按文件夹过滤
+	FolderID *int64
+	// 是否有附件
+	HasAttachment *bool
+	// 按已读状态过滤
+	IsRead *bool
+	// 按星标过滤
+	IsStarred *bool
+	// 起始时间（RFC3339）
+	Since *time.Time
+	// 截止时间（RFC3339）
+	Before *time.Time
+	// keyword（默认）或 semantic（按含义匹配，需配置向量模型，未配置时返回503，不支持游标）
+	Mode *string
+	// 页码，从1开始
+	Page *int64
+	// 每页数量，1-100
+	PageSize *int64
+	// 上一页返回的next_cursor，提供时忽略page
+	Cursor *string
+}
+
+func (p *SearchEmailsParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "q", p.Q)
+	addQuery(query, "subject", p.Subject)
+	addQuery(query, "from", p.From)
+	addQuery(query, "to", p.To)
+	addQuery(query, "body", p.Body)
+	addQuery(query, "note", p.Note)
+	addQuery(query, "account_id", p.AccountID)
+	addQuery(query, "folder_id", p.FolderID)
+	addQuery(query, "has_attachment", p.HasAttachment)
+	addQuery(query, "is_read", p.IsRead)
+	addQuery(query, "is_starred", p.IsStarred)
+	addQuery(query, "since", p.Since)
+	addQuery(query, "before", p.Before)
+	addQuery(query, "mode", p.Mode)
+	addQuery(query, "page", p.Page)
+	addQuery(query, "page_size", p.PageSize)
+	addQuery(query, "cursor", p.Cursor)
+	return query
+}
+
+// GetSyncConflictsParams GetSyncConflicts 的查询参数
+type GetSyncConflictsParams struct {
+	// pending 或 resolved，为空时返回全部
+	Status *string
+}
+
+func (p *GetSyncConflictsParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "status", p.Status)
+	return query
+}
+
+// ListTemplatesParams ListTemplates 的查询参数
+type ListTemplatesParams struct {
+	Category       *string
+	Tag            *string
+	IsActive       *bool
+	IsShared       *bool
+	IncludeBuiltIn *bool
+	Scope          *string
+	Search         *string
+	Page           *int64
+	PageSize       *int64
+	SortBy         *string
+	SortOrder      *string
+}
+
+func (p *ListTemplatesParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "category", p.Category)
+	addQuery(query, "tag", p.Tag)
+	addQuery(query, "is_active", p.IsActive)
+	addQuery(query, "is_shared", p.IsShared)
+	addQuery(query, "include_built_in", p.IncludeBuiltIn)
+	addQuery(query, "scope", p.Scope)
+	addQuery(query, "search", p.Search)
+	addQuery(query, "page", p.Page)
+	addQuery(query, "page_size", p.PageSize)
+	addQuery(query, "sort_by", p.SortBy)
+	addQuery(query, "sort_order", p.SortOrder)
+	return query
+}
+
+// GetVerificationCodesParams GetVerificationCodes 的查询参数
+type GetVerificationCodesParams struct {
+	// 按账户过滤
+	AccountID *int64
+	// 查询最近多少分钟的邮件，默认30，最大1440
+	Minutes *int64
+}
+
+func (p *GetVerificationCodesParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "account_id", p.AccountID)
+	addQuery(query, "minutes", p.Minutes)
+	return query
+}
+
+// GetEmailNavigationParams GetEmailNavigation 的查询参数
+type GetEmailNavigationParams struct {
+	// 按账户过滤
+	AccountID *int64
+	// 按文件夹过滤
+	FolderID *int64
+	// 按已读状态过滤
+	IsRead *bool
+	// 按星标过滤
+	IsStarred *bool
+	// 按重要标记过滤
+	IsImportant *bool
+	// 按优先收件箱分类过滤：important 或 other
+	ImportanceBucket *string
+	// 只看或排除VIP发件人的邮件
+	IsVip *bool
+	// 按旗标颜色过滤：red、orange、yellow、green、blue、purple 或 gray
+	FlagColor *string
+	// 按后续跟进旗标过滤
+	IsFollowUp *bool
+	// 只返回跟进截止时间不晚于该时间的邮件（RFC3339）
+	FollowUpDue *time.Time
+	// 按已回复状态过滤
+	IsAnswered *bool
+	// 只返回等待我回复的邮件：尚未回复，且会话中之后没有已发送的邮件
+	AwaitingReply *bool
+	// 排序字段，默认date
+	SortBy *string
+	// asc 或 desc，默认desc
+	SortOrder *string
+	// 关键词过滤
+	Search *string
+	// 置顶邮件排在最前
+	PinnedFirst *bool
+	// 与列表的分组方式一致，sender 时先按发件人排序
+	GroupBy *string
+}
+
+func (p *GetEmailNavigationParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "account_id", p.AccountID)
+	addQuery(query, "folder_id", p.FolderID)
+	addQuery(query, "is_read", p.IsRead)
+	addQuery(query, "is_starred", p.IsStarred)
+	addQuery(query, "is_important", p.IsImportant)
+	addQuery(query, "importance_bucket", p.ImportanceBucket)
+	addQuery(query, "is_vip", p.IsVip)
+	addQuery(query, "flag_color", p.FlagColor)
+	addQuery(query, "is_follow_up", p.IsFollowUp)
+	addQuery(query, "follow_up_due", p.FollowUpDue)
+	addQuery(query, "is_answered", p.IsAnswered)
+	addQuery(query, "awaiting_reply", p.AwaitingReply)
+	addQuery(query, "sort_by", p.SortBy)
+	addQuery(query, "sort_order", p.SortOrder)
+	addQuery(query, "search", p.Search)
+	addQuery(query, "pinned_first", p.PinnedFirst)
+	addQuery(query, "group_by", p.GroupBy)
+	return query
+}
+
+// ExportEmailPDFParams ExportEmailPDF 的查询参数
+type ExportEmailPDFParams struct {
+	Paper             *string
+	BundleAttachments *bool
+}
+
+func (p *ExportEmailPDFParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "paper", p.Paper)
+	addQuery(query, "bundle_attachments", p.BundleAttachments)
+	return query
+}
+
+// PollEventsParams PollEvents 的查询参数
+type PollEventsParams struct {
+	// 上次返回的游标，为空时从当前位置开始等待
+	Since *int64
+	// 没有事件时最多等待的秒数，默认25，最大60
+	Timeout *int64
+	// 逗号分隔的事件类别或事件类型，为空时接收全部事件
+	Events *string
+	// 逗号分隔的账户ID，只接收这些账户的事件
+	AccountIDs *string
+}
+
+func (p *PollEventsParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "since", p.Since)
+	addQuery(query, "timeout", p.Timeout)
+	addQuery(query, "events", p.Events)
+	addQuery(query, "account_ids", p.AccountIDs)
+	return query
+}
+
+// GetFoldersParams GetFolders 的查询参数
+type GetFoldersParams struct {
+	// 账户ID
+	AccountID int64
+}
+
+func (p *GetFoldersParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "account_id", p.AccountID)
+	return query
+}
+
+// CreateFolderParams CreateFolder 的查询参数
+type CreateFolderParams struct {
+	// 账户ID
+	AccountID int64
+}
+
+func (p *CreateFolderParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "account_id", p.AccountID)
+	return query
+}
+
+// GetLabelsParams GetLabels 的查询参数
+type GetLabelsParams struct {
+	// 账户ID
+	AccountID int64
+}
+
+func (p *GetLabelsParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "account_id", p.AccountID)
+	return query
+}
+
+// UpdateLabelAppearanceParams UpdateLabelAppearance 的查询参数
+type UpdateLabelAppearanceParams struct {
+	// 账户ID
+	AccountID int64
+}
+
+func (p *UpdateLabelAppearanceParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "account_id", p.AccountID)
+	return query
+}
+
+// IngestEmailParams IngestEmail 的查询参数
+type IngestEmailParams struct {
+	// 入站令牌，无法设置请求头时使用
+	Token *string
+}
+
+func (p *IngestEmailParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "token", p.Token)
+	return query
+}
+
+// PlanMailboxMigrationParams PlanMailboxMigration 的查询参数
+type PlanMailboxMigrationParams struct {
+	SourceAccountID int64
+	TargetAccountID int64
+}
+
+func (p *PlanMailboxMigrationParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "source_account_id", p.SourceAccountID)
+	addQuery(query, "target_account_id", p.TargetAccountID)
+	return query
+}
+
+// GetNotificationsParams GetNotifications 的查询参数
+type GetNotificationsParams struct {
+	UnreadOnly *bool
+	Level      *string
+	AccountID  *int64
+	Page       *int64
+	PageSize   *int64
+}
+
+func (p *GetNotificationsParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "unread_only", p.UnreadOnly)
+	addQuery(query, "level", p.Level)
+	addQuery(query, "account_id", p.AccountID)
+	addQuery(query, "page", p.Page)
+	addQuery(query, "page_size", p.PageSize)
+	return query
+}
+
+// InitGmailOAuthParams InitGmailOAuth 的查询参数
+type InitGmailOAuthParams struct {
+	// 授权完成后的前端回调地址
+	CallbackURL *string
+}
+
+func (p *InitGmailOAuthParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "callback_url", p.CallbackURL)
+	return query
+}
+
+// InitOutlookOAuthParams InitOutlookOAuth 的查询参数
+type InitOutlookOAuthParams struct {
+	// 授权完成后的前端回调地址
+	CallbackURL *string
+}
+
+func (p *InitOutlookOAuthParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "callback_url", p.CallbackURL)
+	return query
+}
+
+// HandleOAuth2CallbackParams HandleOAuth2Callback 的查询参数
+type HandleOAuth2CallbackParams struct {
+	// 授权码
+	Code *string
+	// 授权状态
+	State *string
+	// 提供商返回的错误
+	Error *string
+	// 错误描述
+	ErrorDescription *string
+}
+
+func (p *HandleOAuth2CallbackParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "code", p.Code)
+	addQuery(query, "state", p.State)
+	addQuery(query, "error", p.Error)
+	addQuery(query, "error_description", p.ErrorDescription)
+	return query
+}
+
+// DetectProviderParams DetectProvider 的查询参数
+type DetectProviderParams struct {
+	// 邮箱地址
+	Email string
+}
+
+func (p *DetectProviderParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "email", p.Email)
+	return query
+}
+
+// GetRetentionRunsParams GetRetentionRuns 的查询参数
+type GetRetentionRunsParams struct {
+	PolicyID *int64
+	Limit    *int64
+}
+
+func (p *GetRetentionRunsParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "policy_id", p.PolicyID)
+	addQuery(query, "limit", p.Limit)
+	return query
+}
+
+// GetSharedMailboxEmailsParams GetSharedMailboxEmails 的查询参数
+type GetSharedMailboxEmailsParams struct {
+	FolderID   *int64
+	Assignment *string
+	Page       *int64
+	PageSize   *int64
+}
+
+func (p *GetSharedMailboxEmailsParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "folder_id", p.FolderID)
+	addQuery(query, "assignment", p.Assignment)
+	addQuery(query, "page", p.Page)
+	addQuery(query, "page_size", p.PageSize)
+	return query
+}
+
+// HandleSSEParams HandleSSE 的查询参数
+type HandleSSEParams struct {
+	// 访问令牌，EventSource无法设置请求头时使用
+	Token *string
+	// 客户端标识
+	ClientID *string
+	// 逗号分隔的事件类别（new_email、email、sync、send、account、migration、notification）或事件类型，为空时接收全部事件
+	Events *string
+	// 逗号分隔的账户ID，只接收这些账户的事件；不属于具体账户的事件不受影响
+	AccountIDs *string
+	// 重连时传入最后收到事件的 seq，补发缓冲区中之后的事件
+	Since *int64
+}
+
+func (p *HandleSSEParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "token", p.Token)
+	addQuery(query, "client_id", p.ClientID)
+	addQuery(query, "events", p.Events)
+	addQuery(query, "account_ids", p.AccountIDs)
+	addQuery(query, "since", p.Since)
+	return query
+}
+
+// HandleSSEEventsParams HandleSSEEvents 的查询参数
+type HandleSSEEventsParams struct {
+	// 访问令牌，EventSource无法设置请求头时使用
+	Token *string
+	// 客户端标识
+	ClientID *string
+	// 逗号分隔的事件类别（new_email、email、sync、send、account、migration、notification）或事件类型，为空时接收全部事件
+	Events *string
+	// 逗号分隔的账户ID，只接收这些账户的事件；不属于具体账户的事件不受影响
+	AccountIDs *string
+	// 重连时传入最后收到事件的 seq，补发缓冲区中之后的事件
+	Since *int64
+}
+
+func (p *HandleSSEEventsParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "token", p.Token)
+	addQuery(query, "client_id", p.ClientID)
+	addQuery(query, "events", p.Events)
+	addQuery(query, "account_ids", p.AccountIDs)
+	addQuery(query, "since", p.Since)
+	return query
+}
+
+// GetTrashParams GetTrash 的查询参数
+type GetTrashParams struct {
+	// 按账户过滤
+	AccountID *int64
+	// 页码，从1开始
+	Page *int64
+	// 每页数量，1-100
+	PageSize *int64
+}
+
+func (p *GetTrashParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "account_id", p.AccountID)
+	addQuery(query, "page", p.Page)
+	addQuery(query, "page_size", p.PageSize)
+	return query
+}
+
+// EmptyTrashParams EmptyTrash 的查询参数
+type EmptyTrashParams struct {
+	// 只清空该账户
+	AccountID *int64
+}
+
+func (p *EmptyTrashParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	addQuery(query, "account_id", p.AccountID)
+	return query
+}
+
+// GraphQL 执行GraphQL查询
+func (c *Client) GraphQL(ctx context.Context, body *Request) (*http.Response, error) {
+	return c.doRaw(ctx, "POST", "/api/graphql", nil, jsonBody(body))
+}
+
+// GetGraphQLSchema 获取GraphQL类型定义（SDL）
+func (c *Client) GetGraphQLSchema(ctx context.Context) (*http.Response, error) {
+	return c.doRaw(ctx, "GET", "/api/graphql/schema", nil, nil)
+}
+
+// GetEmailAccounts 获取邮件账户列表
+func (c *Client) GetEmailAccounts(ctx context.Context) ([]*EmailAccount, error) {
+	var out []*EmailAccount
+	if err := c.do(ctx, "GET", "/api/v1/accounts", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateEmailAccount 创建邮件账户；连接失败且需要先在邮箱网页版完成设置（如QQ邮箱授权码）时返回setup_guide分步说明
+func (c *Client) CreateEmailAccount(ctx context.Context, body *CreateEmailAccountRequest) (*EmailAccount, error) {
+	var out EmailAccount
+	if err := c.do(ctx, "POST", "/api/v1/accounts", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// BatchDeleteEmailAccounts 批量删除账户
+func (c *Client) BatchDeleteEmailAccounts(ctx context.Context, body *BatchAccountRequest) error {
+	return c.do(ctx, "POST", "/api/v1/accounts/batch/delete", nil, jsonBody(body), nil)
+}
+
+// BatchMarkAccountsAsRead 批量标记账户为已读
+func (c *Client) BatchMarkAccountsAsRead(ctx context.Context, body *BatchAccountRequest) error {
+	return c.do(ctx, "POST", "/api/v1/accounts/batch/mark-read", nil, jsonBody(body), nil)
+}
+
+// BatchSyncEmailAccounts 批量同步账户
+func (c *Client) BatchSyncEmailAccounts(ctx context.Context, body *BatchAccountRequest) error {
+	return c.do(ctx, "POST", "/api/v1/accounts/batch/sync", nil, jsonBody(body), nil)
+}
+
+// CreateCustomEmailAccount 创建自定义服务器邮件账户
+func (c *Client) CreateCustomEmailAccount(ctx context.Context, body *CreateEmailAccountRequest) (*EmailAccount, error) {
+	var out EmailAccount
+	if err := c.do(ctx, "POST", "/api/v1/accounts/custom", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ReorderEmailAccounts 调整账户显示顺序，未列出的账户排在后面
+func (c *Client) ReorderEmailAccounts(ctx context.Context, body *ReorderEmailAccountsRequest) ([]*EmailAccount, error) {
+	var out []*EmailAccount
+	if err := c.do(ctx, "PUT", "/api/v1/accounts/reorder", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetEmailAccount 获取邮件账户
+func (c *Client) GetEmailAccount(ctx context.Context, id int64) (*EmailAccount, error) {
+	var out EmailAccount
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/accounts/%v", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateEmailAccount 更新邮件账户
+func (c *Client) UpdateEmailAccount(ctx context.Context, id int64, body *UpdateEmailAccountRequest) (*EmailAccount, error) {
+	var out EmailAccount
+	if err := c.do(ctx, "PUT", fmt.Sprintf("/api/v1/accounts/%v", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteEmailAccount 删除邮件账户
+func (c *Client) DeleteEmailAccount(ctx context.Context, id int64) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/accounts/%v", url.PathEscape(fmt.Sprint(id))), nil, nil, nil)
+}
+
+// GetGmailLabelMappings 获取Gmail标签映射方式
+func (c *Client) GetGmailLabelMappings(ctx context.Context, id int64) ([]*GmailLabelMapping, error) {
+	var out []*GmailLabelMapping
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/accounts/%v/gmail-labels", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateGmailLabelMappings 修改Gmail标签映射方式（作为文件夹、合并或跳过）
+func (c *Client) UpdateGmailLabelMappings(ctx context.Context, id int64, body *UpdateGmailLabelMappingsRequest) ([]*GmailLabelMapping, error) {
+	var out []*GmailLabelMapping
+	if err := c.do(ctx, "PUT", fmt.Sprintf("/api/v1/accounts/%v/gmail-labels", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MarkAccountAsRead 将账户邮件标记为已读
+func (c *Client) MarkAccountAsRead(ctx context.Context, id int64) error {
+	return c.do(ctx, "PUT", fmt.Sprintf("/api/v1/accounts/%v/mark-read", url.PathEscape(fmt.Sprint(id))), nil, nil, nil)
+}
+
+// PauseAccountSync 暂停账户同步，等待进行中的同步退出
+func (c *Client) PauseAccountSync(ctx context.Context, id int64) (*EmailAccount, error) {
+	var out EmailAccount
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/accounts/%v/pause", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ResumeAccountSync 恢复账户同步
+func (c *Client) ResumeAccountSync(ctx context.Context, id int64) (*EmailAccount, error) {
+	var out EmailAccount
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/accounts/%v/resume", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// StartStorageCleanup 按条件批量清理邮件，返回202和后台任务
+func (c *Client) StartStorageCleanup(ctx context.Context, id int64, body *StartStorageCleanupRequest) (*StorageCleanupJob, error) {
+	var out StorageCleanupJob
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/accounts/%v/storage/cleanup", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetStorageCleanup 获取邮箱清理任务状态
+func (c *Client) GetStorageCleanup(ctx context.Context, id int64, jobID string) (*StorageCleanupJob, error) {
+	var out StorageCleanupJob
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/accounts/%v/storage/cleanup/%v", url.PathEscape(fmt.Sprint(id)), url.PathEscape(fmt.Sprint(jobID))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CancelStorageCleanup 取消邮箱清理任务
+func (c *Client) CancelStorageCleanup(ctx context.Context, id int64, jobID string) (*StorageCleanupJob, error) {
+	var out StorageCleanupJob
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/accounts/%v/storage/cleanup/%v/cancel", url.PathEscape(fmt.Sprint(id)), url.PathEscape(fmt.Sprint(jobID))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetMailboxStorageReport 获取最大邮件和附件、最早未读订阅邮件及发件人占用统计
+func (c *Client) GetMailboxStorageReport(ctx context.Context, id int64, params *GetMailboxStorageReportParams) (*MailboxStorageReport, error) {
+	var out MailboxStorageReport
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/accounts/%v/storage/top", url.PathEscape(fmt.Sprint(id))), params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SyncEmailAccount 同步账户邮件
+func (c *Client) SyncEmailAccount(ctx context.Context, id int64) error {
+	return c.do(ctx, "POST", fmt.Sprintf("/api/v1/accounts/%v/sync", url.PathEscape(fmt.Sprint(id))), nil, nil, nil)
+}
+
+// TestEmailAccount 测试账户连接，需要先完成邮箱设置时错误响应附带setup_guide
+func (c *Client) TestEmailAccount(ctx context.Context, id int64) error {
+	return c.do(ctx, "POST", fmt.Sprintf("/api/v1/accounts/%v/test", url.PathEscape(fmt.Sprint(id))), nil, nil, nil)
+}
+
+// GetAuthFailures 按IP汇总最近的登录失败并给出封禁建议
+func (c *Client) GetAuthFailures(ctx context.Context, params *GetAuthFailuresParams) (*AuthFailuresResponse, error) {
+	var out AuthFailuresResponse
+	if err := c.do(ctx, "GET", "/api/v1/admin/auth-failures", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetConfig 查看生效配置（已脱敏）
+func (c *Client) GetConfig(ctx context.Context) (*ConfigView, error) {
+	var out ConfigView
+	if err := c.do(ctx, "GET", "/api/v1/admin/config", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListJobs 列出后台任务及各状态的任务数
+func (c *Client) ListJobs(ctx context.Context, params *ListJobsParams) (*JobList, error) {
+	var out JobList
+	if err := c.do(ctx, "GET", "/api/v1/admin/jobs", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetJob 获取后台任务详情
+func (c *Client) GetJob(ctx context.Context, id int64) (*Job, error) {
+	var out Job
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/admin/jobs/%v", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CancelJob 取消等待执行的任务
+func (c *Client) CancelJob(ctx context.Context, id int64) (*Job, error) {
+	var out Job
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/admin/jobs/%v/cancel", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RetryJob 重新执行死信或已取消的任务
+func (c *Client) RetryJob(ctx context.Context, id int64) (*Job, error) {
+	var out Job
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/admin/jobs/%v/retry", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// StartReparseJob 启动批量重新解析任务
+func (c *Client) StartReparseJob(ctx context.Context, body *StartReparseJobRequest) (*ReparseJob, error) {
+	var out ReparseJob
+	if err := c.do(ctx, "POST", "/api/v1/admin/reparse", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetReparseJob 获取重新解析任务状态
+func (c *Client) GetReparseJob(ctx context.Context, jobID string) (*ReparseJob, error) {
+	var out ReparseJob
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/admin/reparse/%v", url.PathEscape(fmt.Sprint(jobID))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CancelReparseJob 取消重新解析任务
+func (c *Client) CancelReparseJob(ctx context.Context, jobID string) (*ReparseJob, error) {
+	var out ReparseJob
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/admin/reparse/%v/cancel", url.PathEscape(fmt.Sprint(jobID))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetSecurityEvents 查看安全事件审计日志
+func (c *Client) GetSecurityEvents(ctx context.Context, params *GetSecurityEventsParams) ([]*SecurityEvent, error) {
+	var out []*SecurityEvent
+	if err := c.do(ctx, "GET", "/api/v1/admin/security-events", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetStuckSends 查看发送队列中超过阈值仍未发出的邮件
+func (c *Client) GetStuckSends(ctx context.Context, params *GetStuckSendsParams) ([]*StuckSend, error) {
+	var out []*StuckSend
+	if err := c.do(ctx, "GET", "/api/v1/admin/send-queue/stuck", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetBusiestHours 按小时和星期统计收发邮件数
+func (c *Client) GetBusiestHours(ctx context.Context, params *GetBusiestHoursParams) (*AnalyticsBusiestHours, error) {
+	var out AnalyticsBusiestHours
+	if err := c.do(ctx, "GET", "/api/v1/analytics/busiest-hours", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetOutboundAccounts 按账户统计发出邮件的回复率和回复用时
+func (c *Client) GetOutboundAccounts(ctx context.Context, params *GetOutboundAccountsParams) ([]*AnalyticsOutboundAccount, error) {
+	var out []*AnalyticsOutboundAccount
+	if err := c.do(ctx, "GET", "/api/v1/analytics/outbound/accounts", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetOutboundContacts 按联系人统计发出邮件的回复率和回复用时
+func (c *Client) GetOutboundContacts(ctx context.Context, params *GetOutboundContactsParams) ([]*AnalyticsOutboundContact, error) {
+	var out []*AnalyticsOutboundContact
+	if err := c.do(ctx, "GET", "/api/v1/analytics/outbound/contacts", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetOutboundMessages 发出的邮件及其回复情况
+func (c *Client) GetOutboundMessages(ctx context.Context, params *GetOutboundMessagesParams) ([]*AnalyticsOutboundMessage, error) {
+	var out []*AnalyticsOutboundMessage
+	if err := c.do(ctx, "GET", "/api/v1/analytics/outbound/messages", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RebuildAnalytics 根据现有邮件重建收发统计
+func (c *Client) RebuildAnalytics(ctx context.Context, body *RebuildVolumeStatsRequest) error {
+	return c.do(ctx, "POST", "/api/v1/analytics/rebuild", nil, jsonBody(body), nil)
+}
+
+// GetResponseTimes 回复邮件所用时间统计
+func (c *Client) GetResponseTimes(ctx context.Context, params *GetResponseTimesParams) (*AnalyticsResponseTimes, error) {
+	var out AnalyticsResponseTimes
+	if err := c.do(ctx, "GET", "/api/v1/analytics/response-times", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetTopRecipients 发信最多的收件人
+func (c *Client) GetTopRecipients(ctx context.Context, params *GetTopRecipientsParams) ([]*AnalyticsRecipient, error) {
+	var out []*AnalyticsRecipient
+	if err := c.do(ctx, "GET", "/api/v1/analytics/top-recipients", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetTopSenders 来信最多的发件人
+func (c *Client) GetTopSenders(ctx context.Context, params *GetTopSendersParams) ([]*SenderVolume, error) {
+	var out []*SenderVolume
+	if err := c.do(ctx, "GET", "/api/v1/analytics/top-senders", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetEmailVolume 按时间段统计收发邮件数
+func (c *Client) GetEmailVolume(ctx context.Context, params *GetEmailVolumeParams) ([]*AnalyticsVolumePoint, error) {
+	var out []*AnalyticsVolumePoint
+	if err := c.do(ctx, "GET", "/api/v1/analytics/volume", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DownloadAttachmentLink 通过邮件正文中的下载链接下载附件
+func (c *Client) DownloadAttachmentLink(ctx context.Context, token string) (*http.Response, error) {
+	return c.doRaw(ctx, "GET", fmt.Sprintf("/api/v1/attachment-links/%v", url.PathEscape(fmt.Sprint(token))), nil, nil)
+}
+
+// ListAttachments 跨邮件列出附件，可按账户、类型、时间和大小筛选
+func (c *Client) ListAttachments(ctx context.Context, params *ListAttachmentsParams) (*AttachmentListResponse, error) {
+	var out AttachmentListResponse
+	if err := c.do(ctx, "GET", "/api/v1/attachments", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DownloadAttachmentsZip 将选中的附件打包为ZIP下载
+func (c *Client) DownloadAttachmentsZip(ctx context.Context, body *DownloadAttachmentsZipRequest) (*http.Response, error) {
+	return c.doRaw(ctx, "POST", "/api/v1/attachments/download-zip", nil, jsonBody(body))
+}
+
+// UploadAttachment 上传附件
+func (c *Client) UploadAttachment(ctx context.Context, form *UploadAttachmentForm, filename string, file io.Reader) (*AttachmentUploadResult, error) {
+	var out AttachmentUploadResult
+	if err := c.do(ctx, "POST", "/api/v1/attachments/upload", nil, multipartBody(form, filename, file), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DownloadAttachment 下载附件
+func (c *Client) DownloadAttachment(ctx context.Context, id int64) (*http.Response, error) {
+	return c.doRaw(ctx, "GET", fmt.Sprintf("/api/v1/attachments/%v/download", url.PathEscape(fmt.Sprint(id))), nil, nil)
+}
+
+// ForceDownloadAttachment 从服务器重新下载附件
+func (c *Client) ForceDownloadAttachment(ctx context.Context, id int64) error {
+	return c.do(ctx, "POST", fmt.Sprintf("/api/v1/attachments/%v/download", url.PathEscape(fmt.Sprint(id))), nil, nil, nil)
+}
+
+// DownloadAttachmentWithToken 通过签名链接下载附件，无需登录令牌
+func (c *Client) DownloadAttachmentWithToken(ctx context.Context, id int64, params *DownloadAttachmentWithTokenParams) (*http.Response, error) {
+	return c.doRaw(ctx, "GET", fmt.Sprintf("/api/v1/attachments/%v/file", url.PathEscape(fmt.Sprint(id))), params.values(), nil)
+}
+
+// PreviewAttachment 预览附件
+func (c *Client) PreviewAttachment(ctx context.Context, id int64) (*AttachmentPreview, error) {
+	var out AttachmentPreview
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/attachments/%v/preview", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetDownloadProgress 获取附件下载进度
+func (c *Client) GetDownloadProgress(ctx context.Context, id int64) (*DownloadProgress, error) {
+	var out DownloadProgress
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/attachments/%v/progress", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateAttachmentToken 签发单个附件的短期下载链接
+func (c *Client) CreateAttachmentToken(ctx context.Context, id int64) (*AttachmentToken, error) {
+	var out AttachmentToken
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/attachments/%v/token", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Login 用户登录
+func (c *Client) Login(ctx context.Context, body *LoginRequest) (*LoginResponse, error) {
+	var out LoginResponse
+	if err := c.do(ctx, "POST", "/api/v1/auth/login", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Logout 用户登出
+func (c *Client) Logout(ctx context.Context) error {
+	return c.do(ctx, "POST", "/api/v1/auth/logout", nil, nil, nil)
+}
+
+// GetCurrentUser 获取当前用户信息
+func (c *Client) GetCurrentUser(ctx context.Context) (*User, error) {
+	var out User
+	if err := c.do(ctx, "GET", "/api/v1/auth/me", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetBlockedSenders 获取屏蔽发件人列表
+func (c *Client) GetBlockedSenders(ctx context.Context) ([]*BlockedSender, error) {
+	var out []*BlockedSender
+	if err := c.do(ctx, "GET", "/api/v1/blocked-senders", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AddBlockedSender 屏蔽邮箱地址或域名，之后同步到的邮件自动移入回收站或垃圾邮件
+func (c *Client) AddBlockedSender(ctx context.Context, body *BlockedSenderRequest) (*BlockedSender, error) {
+	var out BlockedSender
+	if err := c.do(ctx, "POST", "/api/v1/blocked-senders", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ExportBlockedSenders 导出屏蔽发件人列表
+func (c *Client) ExportBlockedSenders(ctx context.Context) (*BlockedSenderExport, error) {
+	var out BlockedSenderExport
+	if err := c.do(ctx, "GET", "/api/v1/blocked-senders/export", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ImportBlockedSenders 导入屏蔽发件人列表
+func (c *Client) ImportBlockedSenders(ctx context.Context, body *ImportBlockedSendersRequest) (*ImportBlockedSendersResult, error) {
+	var out ImportBlockedSendersResult
+	if err := c.do(ctx, "POST", "/api/v1/blocked-senders/import", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateBlockedSender 修改屏蔽发件人的处理方式
+func (c *Client) UpdateBlockedSender(ctx context.Context, id int64, body *UpdateBlockedSenderRequest) (*BlockedSender, error) {
+	var out BlockedSender
+	if err := c.do(ctx, "PUT", fmt.Sprintf("/api/v1/blocked-senders/%v", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteBlockedSender 取消屏蔽发件人
+func (c *Client) DeleteBlockedSender(ctx context.Context, id int64) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/blocked-senders/%v", url.PathEscape(fmt.Sprint(id))), nil, nil, nil)
+}
+
+// GetMailMergeCampaigns 获取邮件合并活动列表
+func (c *Client) GetMailMergeCampaigns(ctx context.Context) ([]*MailMergeCampaign, error) {
+	var out []*MailMergeCampaign
+	if err := c.do(ctx, "GET", "/api/v1/campaigns", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateMailMergeCampaign 上传CSV收件人创建活动
+func (c *Client) CreateMailMergeCampaign(ctx context.Context, form *CreateMailMergeCampaignForm, filename string, file io.Reader) (*MailMergeCampaign, error) {
+	var out MailMergeCampaign
+	if err := c.do(ctx, "POST", "/api/v1/campaigns", nil, multipartBody(form, filename, file), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetTrackingOptOuts 获取不跟踪名单
+func (c *Client) GetTrackingOptOuts(ctx context.Context) ([]*TrackingOptOut, error) {
+	var out []*TrackingOptOut
+	if err := c.do(ctx, "GET", "/api/v1/campaigns/opt-outs", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AddTrackingOptOut 添加不跟踪的地址或域名
+func (c *Client) AddTrackingOptOut(ctx context.Context, body *TrackingOptOutRequest) (*TrackingOptOut, error) {
+	var out TrackingOptOut
+	if err := c.do(ctx, "POST", "/api/v1/campaigns/opt-outs", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteTrackingOptOut 从不跟踪名单中移除
+func (c *Client) DeleteTrackingOptOut(ctx context.Context, id int64) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/campaigns/opt-outs/%v", url.PathEscape(fmt.Sprint(id))), nil, nil, nil)
+}
+
+// GetMailMergeCampaign 获取邮件合并活动
+func (c *Client) GetMailMergeCampaign(ctx context.Context, id int64) (*MailMergeCampaign, error) {
+	var out MailMergeCampaign
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/campaigns/%v", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CancelMailMergeCampaign 取消活动
+func (c *Client) CancelMailMergeCampaign(ctx context.Context, id int64) (*MailMergeCampaign, error) {
+	var out MailMergeCampaign
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/campaigns/%v/cancel", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PauseMailMergeCampaign 暂停发送
+func (c *Client) PauseMailMergeCampaign(ctx context.Context, id int64) (*MailMergeCampaign, error) {
+	var out MailMergeCampaign
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/campaigns/%v/pause", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetMailMergeRecipients 获取活动收件人
+func (c *Client) GetMailMergeRecipients(ctx context.Context, id int64, params *GetMailMergeRecipientsParams) (*ListMailMergeRecipientsResponse, error) {
+	var out ListMailMergeRecipientsResponse
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/campaigns/%v/recipients", url.PathEscape(fmt.Sprint(id))), params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetMailMergeCampaignReport 获取活动的打开和点击统计
+func (c *Client) GetMailMergeCampaignReport(ctx context.Context, id int64) (*MailMergeCampaignReport, error) {
+	var out MailMergeCampaignReport
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/campaigns/%v/report", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ResumeMailMergeCampaign 恢复发送
+func (c *Client) ResumeMailMergeCampaign(ctx context.Context, id int64) (*MailMergeCampaign, error) {
+	var out MailMergeCampaign
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/campaigns/%v/resume", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// StartMailMergeCampaign 开始发送
+func (c *Client) StartMailMergeCampaign(ctx context.Context, id int64) (*MailMergeCampaign, error) {
+	var out MailMergeCampaign
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/campaigns/%v/start", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetCannedResponses 获取快捷回复列表
+func (c *Client) GetCannedResponses(ctx context.Context) ([]*CannedResponse, error) {
+	var out []*CannedResponse
+	if err := c.do(ctx, "GET", "/api/v1/canned-responses", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateCannedResponse 创建快捷回复
+func (c *Client) CreateCannedResponse(ctx context.Context, body *CannedResponseRequest) (*CannedResponse, error) {
+	var out CannedResponse
+	if err := c.do(ctx, "POST", "/api/v1/canned-responses", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetCannedQuickList 撰写邮件时使用的快捷回复精简列表，关联当前文件夹的排在前面，其余按使用次数排序
+func (c *Client) GetCannedQuickList(ctx context.Context, params *GetCannedQuickListParams) ([]*CannedQuickItem, error) {
+	var out []*CannedQuickItem
+	if err := c.do(ctx, "GET", "/api/v1/canned-responses/quick", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateCannedResponse 修改快捷回复
+func (c *Client) UpdateCannedResponse(ctx context.Context, id int64, body *CannedResponseRequest) (*CannedResponse, error) {
+	var out CannedResponse
+	if err := c.do(ctx, "PUT", fmt.Sprintf("/api/v1/canned-responses/%v", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteCannedResponse 删除快捷回复
+func (c *Client) DeleteCannedResponse(ctx context.Context, id int64) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/canned-responses/%v", url.PathEscape(fmt.Sprint(id))), nil, nil, nil)
+}
+
+// RenderCannedResponse 代入变量渲染快捷回复并计入使用次数，提供email_id时可以使用发件人和主题变量
+func (c *Client) RenderCannedResponse(ctx context.Context, id int64, body *RenderCannedResponseRequest) (*RenderedCannedResponse, error) {
+	var out RenderedCannedResponse
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/canned-responses/%v/render", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetChanges 获取令牌之后的增量变更
+func (c *Client) GetChanges(ctx context.Context, params *GetChangesParams) (*ChangesResponse, error) {
+	var out ChangesResponse
+	if err := c.do(ctx, "GET", "/api/v1/changes", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetEmails 获取邮件列表
+func (c *Client) GetEmails(ctx context.Context, params *GetEmailsParams) (*GetEmailsResponse, error) {
+	var out GetEmailsResponse
+	if err := c.do(ctx, "GET", "/api/v1/emails", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// BatchEmailOperations 批量邮件操作，跨账户批量移动/复制较多邮件时返回202和后台任务
+func (c *Client) BatchEmailOperations(ctx context.Context, body *BatchEmailOperation) (*BatchEmailResult, error) {
+	var out BatchEmailResult
+	if err := c.do(ctx, "POST", "/api/v1/emails/batch", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SaveDraft 保存草稿
+func (c *Client) SaveDraft(ctx context.Context, body *SaveDraftRequest) (*Draft, error) {
+	var out Draft
+	if err := c.do(ctx, "POST", "/api/v1/emails/draft", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetDraft 获取草稿
+func (c *Client) GetDraft(ctx context.Context, id int64) (*Draft, error) {
+	var out Draft
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/emails/draft/%v", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateDraft 更新草稿
+func (c *Client) UpdateDraft(ctx context.Context, id int64, body *UpdateDraftRequest) (*Draft, error) {
+	var out Draft
+	if err := c.do(ctx, "PUT", fmt.Sprintf("/api/v1/emails/draft/%v", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteDraft 删除草稿
+func (c *Client) DeleteDraft(ctx context.Context, id int64) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/emails/draft/%v", url.PathEscape(fmt.Sprint(id))), nil, nil, nil)
+}
+
+// AutosaveDraft 自动保存草稿
+func (c *Client) AutosaveDraft(ctx context.Context, id int64, body *UpdateDraftRequest) (*DraftAutosaveResult, error) {
+	var out DraftAutosaveResult
+	if err := c.do(ctx, "PATCH", fmt.Sprintf("/api/v1/emails/draft/%v/autosave", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListDraftRevisions 获取草稿修订历史
+func (c *Client) ListDraftRevisions(ctx context.Context, id int64) ([]*DraftRevision, error) {
+	var out []*DraftRevision
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/emails/draft/%v/revisions", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RestoreDraftRevision 恢复草稿修订
+func (c *Client) RestoreDraftRevision(ctx context.Context, id int64, revisionID int64) (*Draft, error) {
+	var out Draft
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/emails/draft/%v/revisions/%v/restore", url.PathEscape(fmt.Sprint(id)), url.PathEscape(fmt.Sprint(revisionID))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListDrafts 获取草稿列表
+func (c *Client) ListDrafts(ctx context.Context, params *ListDraftsParams) (*ListDraftsResponse, error) {
+	var out ListDraftsResponse
+	if err := c.do(ctx, "GET", "/api/v1/emails/drafts", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ImportDrafts 导入服务器Drafts文件夹中的草稿
+func (c *Client) ImportDrafts(ctx context.Context, body *ImportDraftsRequest) (*ImportDraftsResult, error) {
+	var out ImportDraftsResult
+	if err := c.do(ctx, "POST", "/api/v1/emails/drafts/import", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// StartDuplicateScan 启动重复邮件扫描任务
+func (c *Client) StartDuplicateScan(ctx context.Context, body *StartDuplicateScanRequest) (*DuplicateScanJob, error) {
+	var out DuplicateScanJob
+	if err := c.do(ctx, "POST", "/api/v1/emails/duplicates/scans", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetDuplicateScan 获取重复邮件扫描状态及分组结果
+func (c *Client) GetDuplicateScan(ctx context.Context, jobID string) (*DuplicateScanJob, error) {
+	var out DuplicateScanJob
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/emails/duplicates/scans/%v", url.PathEscape(fmt.Sprint(jobID))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CancelDuplicateScan 取消重复邮件扫描任务
+func (c *Client) CancelDuplicateScan(ctx context.Context, jobID string) (*DuplicateScanJob, error) {
+	var out DuplicateScanJob
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/emails/duplicates/scans/%v/cancel", url.PathEscape(fmt.Sprint(jobID))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ResolveDuplicates 删除或移动重复邮件，每组保留一封
+func (c *Client) ResolveDuplicates(ctx context.Context, jobID string, body *ResolveDuplicatesRequest) (*ResolveDuplicatesResult, error) {
+	var out ResolveDuplicatesResult
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/emails/duplicates/scans/%v/resolve", url.PathEscape(fmt.Sprint(jobID))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PreviewMarkdown 预览Markdown正文渲染出的HTML和纯文本
+func (c *Client) PreviewMarkdown(ctx context.Context, body *MarkdownPreviewRequest) (*MarkdownPreview, error) {
+	var out MarkdownPreview
+	if err := c.do(ctx, "POST", "/api/v1/emails/markdown/preview", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetMutedThreads 获取已静音的会话
+func (c *Client) GetMutedThreads(ctx context.Context) ([]*MutedThread, error) {
+	var out []*MutedThread
+	if err := c.do(ctx, "GET", "/api/v1/emails/muted-threads", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeleteMutedThread 从静音列表中移除会话
+func (c *Client) DeleteMutedThread(ctx context.Context, id int64) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/emails/muted-threads/%v", url.PathEscape(fmt.Sprint(id))), nil, nil, nil)
+}
+
+// GetReplyLaterEmails 获取稍后回复列表，先加入的在前
+func (c *Client) GetReplyLaterEmails(ctx context.Context, params *GetReplyLaterEmailsParams) (*GetEmailsResponse, error) {
+	var out GetEmailsResponse
+	if err := c.do(ctx, "GET", "/api/v1/emails/reply-later", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SearchEmails 搜索邮件
+func (c *Client) SearchEmails(ctx context.Context, params *SearchEmailsParams) (*GetEmailsResponse, error) {
+	var out GetEmailsResponse
+	if err := c.do(ctx, "GET", "/api/v1/emails/search", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SendEmail 发送邮件；超过邮箱服务商大小上限时返回413，size_limit中给出邮件大小和上限，offload_large_attachments为true时把最大的附件改为正文中的下载链接
+func (c *Client) SendEmail(ctx context.Context, body *SendEmailRequest) error {
+	return c.do(ctx, "POST", "/api/v1/emails/send", nil, jsonBody(body), nil)
+}
+
+// GetSyncConflicts 获取本地与服务器同时修改同一封邮件产生的同步冲突
+func (c *Client) GetSyncConflicts(ctx context.Context, params *GetSyncConflictsParams) ([]*SyncConflict, error) {
+	var out []*SyncConflict
+	if err := c.do(ctx, "GET", "/api/v1/emails/sync-conflicts", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ResolveSyncConflict 选择以服务器或本地状态处理同步冲突
+func (c *Client) ResolveSyncConflict(ctx context.Context, id int64, body *ResolveSyncConflictRequest) (*SyncConflict, error) {
+	var out SyncConflict
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/emails/sync-conflicts/%v/resolve", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateTemplate 创建邮件模板
+func (c *Client) CreateTemplate(ctx context.Context, body *CreateEmailTemplateRequest) (*EmailTemplate, error) {
+	var out EmailTemplate
+	if err := c.do(ctx, "POST", "/api/v1/emails/template", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetTemplate 获取邮件模板
+func (c *Client) GetTemplate(ctx context.Context, id int64) (*EmailTemplate, error) {
+	var out EmailTemplate
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/emails/template/%v", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateTemplate 更新邮件模板
+func (c *Client) UpdateTemplate(ctx context.Context, id int64, body *UpdateEmailTemplateRequest) (*EmailTemplate, error) {
+	var out EmailTemplate
+	if err := c.do(ctx, "PUT", fmt.Sprintf("/api/v1/emails/template/%v", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteTemplate 删除邮件模板
+func (c *Client) DeleteTemplate(ctx context.Context, id int64) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/emails/template/%v", url.PathEscape(fmt.Sprint(id))), nil, nil, nil)
+}
+
+// PreviewTemplate 预览模板渲染结果
+func (c *Client) PreviewTemplate(ctx context.Context, id int64, body *TemplatePreviewRequest) (*TemplatePreview, error) {
+	var out TemplatePreview
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/emails/template/%v/preview", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListTemplates 获取邮件模板列表
+func (c *Client) ListTemplates(ctx context.Context, params *ListTemplatesParams) (*ListEmailTemplatesResponse, error) {
+	var out ListEmailTemplatesResponse
+	if err := c.do(ctx, "GET", "/api/v1/emails/templates", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetEmailTransferJob 获取跨账户移动/复制任务状态
+func (c *Client) GetEmailTransferJob(ctx context.Context, jobID string) (*EmailTransferJob, error) {
+	var out EmailTransferJob
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/emails/transfers/%v", url.PathEscape(fmt.Sprint(jobID))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CancelEmailTransferJob 取消跨账户移动/复制任务
+func (c *Client) CancelEmailTransferJob(ctx context.Context, jobID string) (*EmailTransferJob, error) {
+	var out EmailTransferJob
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/emails/transfers/%v/cancel", url.PathEscape(fmt.Sprint(jobID))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetVerificationCodes 获取最近收到的邮件中识别出的验证码，新邮件在前；新验证码同时通过verification_code事件推送
+func (c *Client) GetVerificationCodes(ctx context.Context, params *GetVerificationCodesParams) ([]*VerificationCode, error) {
+	var out []*VerificationCode
+	if err := c.do(ctx, "GET", "/api/v1/emails/verification-codes", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetEmail 获取邮件详情
+func (c *Client) GetEmail(ctx context.Context, id int64) (*Email, error) {
+	var out Email
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/emails/%v", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateEmail 更新邮件状态
+func (c *Client) UpdateEmail(ctx context.Context, id int64, body *UpdateEmailRequest) (*Email, error) {
+	var out Email
+	if err := c.do(ctx, "PATCH", fmt.Sprintf("/api/v1/emails/%v", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteEmail 删除邮件
+func (c *Client) DeleteEmail(ctx context.Context, id int64) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/emails/%v", url.PathEscape(fmt.Sprint(id))), nil, nil, nil)
+}
+
+// ArchiveEmail 归档邮件
+func (c *Client) ArchiveEmail(ctx context.Context, id int64) error {
+	return c.do(ctx, "PUT", fmt.Sprintf("/api/v1/emails/%v/archive", url.PathEscape(fmt.Sprint(id))), nil, nil, nil)
+}
+
+// GetEmailAttachments 获取邮件附件列表
+func (c *Client) GetEmailAttachments(ctx context.Context, id int64) ([]*AttachmentInfo, error) {
+	var out []*AttachmentInfo
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/emails/%v/attachments", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DownloadEmailAttachments 下载邮件的全部附件
+func (c *Client) DownloadEmailAttachments(ctx context.Context, id int64) error {
+	return c.do(ctx, "POST", fmt.Sprintf("/api/v1/emails/%v/attachments/download", url.PathEscape(fmt.Sprint(id))), nil, nil, nil)
+}
+
+// BlockEmailSender 屏蔽邮件的发件人或其域名，并将邮件移入垃圾邮件或回收站
+func (c *Client) BlockEmailSender(ctx context.Context, id int64, body *BlockEmailSenderRequest) (*BlockedSender, error) {
+	var out BlockedSender
+	if err := c.do(ctx, "PUT", fmt.Sprintf("/api/v1/emails/%v/block-sender", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SetEmailFlag 设置星标颜色和后续跟进旗标，尽量以IMAP关键字同步到服务器
+func (c *Client) SetEmailFlag(ctx context.Context, id int64, body *SetEmailFlagRequest) (*Email, error) {
+	var out Email
+	if err := c.do(ctx, "PUT", fmt.Sprintf("/api/v1/emails/%v/flag", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ForwardEmail 转发邮件
+func (c *Client) ForwardEmail(ctx context.Context, id int64, body *ForwardEmailRequest) error {
+	return c.do(ctx, "POST", fmt.Sprintf("/api/v1/emails/%v/forward", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), nil)
+}
+
+// AnalyzeEmailHeaders 分析邮件投递链延迟、SPF/DKIM结果和来源IP
+func (c *Client) AnalyzeEmailHeaders(ctx context.Context, id int64) (*HeaderAnalysis, error) {
+	var out HeaderAnalysis
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/emails/%v/headers/analysis", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetEmailHistory 获取邮件操作历史（同步、已读、移动、回复等）
+func (c *Client) GetEmailHistory(ctx context.Context, id int64) ([]*EmailHistoryEntry, error) {
+	var out []*EmailHistoryEntry
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/emails/%v/history", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MoveEmail 移动邮件，指定target_account_id或copy时跨账户移动/复制
+func (c *Client) MoveEmail(ctx context.Context, id int64, body *MoveEmailRequest) (*EmailTransferJob, error) {
+	var out EmailTransferJob
+	if err := c.do(ctx, "PUT", fmt.Sprintf("/api/v1/emails/%v/move", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// MuteThread 静音邮件所在会话，后续会话邮件自动已读且不通知
+func (c *Client) MuteThread(ctx context.Context, id int64) (*MutedThread, error) {
+	var out MutedThread
+	if err := c.do(ctx, "PUT", fmt.Sprintf("/api/v1/emails/%v/mute-thread", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetEmailNavigation 获取邮件在列表中的上一封和下一封
+func (c *Client) GetEmailNavigation(ctx context.Context, id int64, params *GetEmailNavigationParams) (*EmailNavigation, error) {
+	var out EmailNavigation
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/emails/%v/navigation", url.PathEscape(fmt.Sprint(id))), params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetEmailNotes 获取邮件的私有笔记
+func (c *Client) GetEmailNotes(ctx context.Context, id int64) ([]*EmailNote, error) {
+	var out []*EmailNote
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/emails/%v/notes", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateEmailNote 添加私有笔记，可选通过IMAP ANNOTATE同步到服务器
+func (c *Client) CreateEmailNote(ctx context.Context, id int64, body *CreateEmailNoteRequest) (*EmailNote, error) {
+	var out EmailNote
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/emails/%v/notes", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateEmailNote 更新私有笔记
+func (c *Client) UpdateEmailNote(ctx context.Context, id int64, noteID int64, body *UpdateEmailNoteRequest) (*EmailNote, error) {
+	var out EmailNote
+	if err := c.do(ctx, "PATCH", fmt.Sprintf("/api/v1/emails/%v/notes/%v", url.PathEscape(fmt.Sprint(id)), url.PathEscape(fmt.Sprint(noteID))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteEmailNote 删除私有笔记
+func (c *Client) DeleteEmailNote(ctx context.Context, id int64, noteID int64) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/emails/%v/notes/%v", url.PathEscape(fmt.Sprint(id)), url.PathEscape(fmt.Sprint(noteID))), nil, nil, nil)
+}
+
+// ExportEmailPDF 导出邮件PDF，可连同附件打包为zip
+func (c *Client) ExportEmailPDF(ctx context.Context, id int64, params *ExportEmailPDFParams) (*http.Response, error) {
+	return c.doRaw(ctx, "GET", fmt.Sprintf("/api/v1/emails/%v/pdf", url.PathEscape(fmt.Sprint(id))), params.values(), nil)
+}
+
+// ToggleEmailPin 切换置顶，文件夹内置顶数量已满时返回409
+func (c *Client) ToggleEmailPin(ctx context.Context, id int64) (*Email, error) {
+	var out Email
+	if err := c.do(ctx, "PUT", fmt.Sprintf("/api/v1/emails/%v/pin", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// MarkEmailPriorityImportant 反馈为重要邮件，用于训练优先收件箱分类
+func (c *Client) MarkEmailPriorityImportant(ctx context.Context, id int64) (*Email, error) {
+	var out Email
+	if err := c.do(ctx, "PUT", fmt.Sprintf("/api/v1/emails/%v/priority/important", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// MarkEmailPriorityOther 反馈为非重要邮件，用于训练优先收件箱分类
+func (c *Client) MarkEmailPriorityOther(ctx context.Context, id int64) (*Email, error) {
+	var out Email
+	if err := c.do(ctx, "PUT", fmt.Sprintf("/api/v1/emails/%v/priority/other", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// MarkEmailAsRead 标记为已读
+func (c *Client) MarkEmailAsRead(ctx context.Context, id int64) error {
+	return c.do(ctx, "PUT", fmt.Sprintf("/api/v1/emails/%v/read", url.PathEscape(fmt.Sprint(id))), nil, nil, nil)
+}
+
+// RedecodeEmail 使用指定字符集重新解码
+func (c *Client) RedecodeEmail(ctx context.Context, id int64, body *RedecodeEmailRequest) (*Email, error) {
+	var out Email
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/emails/%v/redecode", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ReparseEmail 从服务器源码重新解析
+func (c *Client) ReparseEmail(ctx context.Context, id int64) (*Email, error) {
+	var out Email
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/emails/%v/reparse", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ReplyEmail 回复邮件
+func (c *Client) ReplyEmail(ctx context.Context, id int64, body *ReplyEmailRequest) error {
+	return c.do(ctx, "POST", fmt.Sprintf("/api/v1/emails/%v/reply", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), nil)
+}
+
+// ReplyAllEmail 回复全部
+func (c *Client) ReplyAllEmail(ctx context.Context, id int64, body *ReplyEmailRequest) error {
+	return c.do(ctx, "POST", fmt.Sprintf("/api/v1/emails/%v/reply-all", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), nil)
+}
+
+// SetReplyLater 加入稍后回复，设置remind_at时到期后标记为未读并置顶，回复后自动移出
+func (c *Client) SetReplyLater(ctx context.Context, id int64, body *ReplyLaterRequest) (*Email, error) {
+	var out Email
+	if err := c.do(ctx, "PUT", fmt.Sprintf("/api/v1/emails/%v/reply-later", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ClearReplyLater 移出稍后回复
+func (c *Client) ClearReplyLater(ctx context.Context, id int64) (*Email, error) {
+	var out Email
+	if err := c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/emails/%v/reply-later", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateEmailShare 创建带有效期的公开分享链接
+func (c *Client) CreateEmailShare(ctx context.Context, id int64, body *CreateEmailShareRequest) (*EmailShareLink, error) {
+	var out EmailShareLink
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/emails/%v/share", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetEmailShares 获取邮件的分享链接
+func (c *Client) GetEmailShares(ctx context.Context, id int64) ([]*EmailShareLink, error) {
+	var out []*EmailShareLink
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/emails/%v/shares", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetEmailSource 查看邮件原始邮件头和MIME结构
+func (c *Client) GetEmailSource(ctx context.Context, id int64) (*EmailSource, error) {
+	var out EmailSource
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/emails/%v/source", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ToggleEmailStar 切换星标
+func (c *Client) ToggleEmailStar(ctx context.Context, id int64) error {
+	return c.do(ctx, "PUT", fmt.Sprintf("/api/v1/emails/%v/star", url.PathEscape(fmt.Sprint(id))), nil, nil, nil)
+}
+
+// UnmuteThread 取消静音邮件所在会话
+func (c *Client) UnmuteThread(ctx context.Context, id int64) error {
+	return c.do(ctx, "PUT", fmt.Sprintf("/api/v1/emails/%v/unmute-thread", url.PathEscape(fmt.Sprint(id))), nil, nil, nil)
+}
+
+// MarkEmailAsUnread 标记为未读
+func (c *Client) MarkEmailAsUnread(ctx context.Context, id int64) error {
+	return c.do(ctx, "PUT", fmt.Sprintf("/api/v1/emails/%v/unread", url.PathEscape(fmt.Sprint(id))), nil, nil, nil)
+}
+
+// PollEvents 长轮询获取事件（无法保持SSE连接时使用）
+func (c *Client) PollEvents(ctx context.Context, params *PollEventsParams) (*EventBatch, error) {
+	var out EventBatch
+	if err := c.do(ctx, "GET", "/api/v1/events/poll", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetFolders 获取文件夹列表
+func (c *Client) GetFolders(ctx context.Context, params *GetFoldersParams) ([]*Folder, error) {
+	var out []*Folder
+	if err := c.do(ctx, "GET", "/api/v1/folders", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateFolder 创建文件夹
+func (c *Client) CreateFolder(ctx context.Context, params *CreateFolderParams, body *CreateFolderRequest) (*Folder, error) {
+	var out Folder
+	if err := c.do(ctx, "POST", "/api/v1/folders", params.values(), jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetLabels 获取标签及显示属性
+func (c *Client) GetLabels(ctx context.Context, params *GetLabelsParams) ([]*Label, error) {
+	var out []*Label
+	if err := c.do(ctx, "GET", "/api/v1/folders/labels", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateLabelAppearance 设置标签显示属性
+func (c *Client) UpdateLabelAppearance(ctx context.Context, params *UpdateLabelAppearanceParams, body *UpdateLabelAppearanceRequest) (*Label, error) {
+	var out Label
+	if err := c.do(ctx, "PUT", "/api/v1/folders/labels", params.values(), jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteLabelAppearance 删除标签显示属性
+func (c *Client) DeleteLabelAppearance(ctx context.Context, id int64) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/folders/labels/%v", url.PathEscape(fmt.Sprint(id))), nil, nil, nil)
+}
+
+// ReconcileFolderCounts 重新统计文件夹邮件数和未读数
+func (c *Client) ReconcileFolderCounts(ctx context.Context, body *ReconcileFolderCountsRequest) (*FolderCountReconciliation, error) {
+	var out FolderCountReconciliation
+	if err := c.do(ctx, "POST", "/api/v1/folders/reconcile-counts", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetFolder 获取文件夹
+func (c *Client) GetFolder(ctx context.Context, id int64) (*Folder, error) {
+	var out Folder
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/folders/%v", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateFolder 更新文件夹
+func (c *Client) UpdateFolder(ctx context.Context, id int64, body *UpdateFolderRequest) (*Folder, error) {
+	var out Folder
+	if err := c.do(ctx, "PUT", fmt.Sprintf("/api/v1/folders/%v", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteFolder 删除文件夹
+func (c *Client) DeleteFolder(ctx context.Context, id int64) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/folders/%v", url.PathEscape(fmt.Sprint(id))), nil, nil, nil)
+}
+
+// UpdateFolderAppearance 更新文件夹显示属性
+func (c *Client) UpdateFolderAppearance(ctx context.Context, id int64, body *AppearanceRequest) (*Folder, error) {
+	var out Folder
+	if err := c.do(ctx, "PUT", fmt.Sprintf("/api/v1/folders/%v/appearance", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// MarkFolderAsRead 将文件夹标记为已读
+func (c *Client) MarkFolderAsRead(ctx context.Context, id int64) error {
+	return c.do(ctx, "PUT", fmt.Sprintf("/api/v1/folders/%v/mark-read", url.PathEscape(fmt.Sprint(id))), nil, nil, nil)
+}
+
+// SyncFolder 同步文件夹
+func (c *Client) SyncFolder(ctx context.Context, id int64) error {
+	return c.do(ctx, "PUT", fmt.Sprintf("/api/v1/folders/%v/sync", url.PathEscape(fmt.Sprint(id))), nil, nil, nil)
+}
+
+// GetForwardingRules 获取自动转发规则列表及转发统计
+func (c *Client) GetForwardingRules(ctx context.Context) ([]*ForwardingRule, error) {
+	var out []*ForwardingRule
+	if err := c.do(ctx, "GET", "/api/v1/forwarding-rules", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateForwardingRule 创建自动转发规则，收件箱中匹配的新邮件通过账户的SMTP转发到外部地址
+func (c *Client) CreateForwardingRule(ctx context.Context, body *ForwardingRuleRequest) (*ForwardingRule, error) {
+	var out ForwardingRule
+	if err := c.do(ctx, "POST", "/api/v1/forwarding-rules", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateForwardingRule 修改自动转发规则，包括启用和停用
+func (c *Client) UpdateForwardingRule(ctx context.Context, id int64, body *ForwardingRuleRequest) (*ForwardingRule, error) {
+	var out ForwardingRule
+	if err := c.do(ctx, "PUT", fmt.Sprintf("/api/v1/forwarding-rules/%v", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteForwardingRule 删除自动转发规则
+func (c *Client) DeleteForwardingRule(ctx context.Context, id int64) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/forwarding-rules/%v", url.PathEscape(fmt.Sprint(id))), nil, nil, nil)
+}
+
+// GetEmailGroups 获取邮箱分组
+func (c *Client) GetEmailGroups(ctx context.Context) ([]*EmailGroup, error) {
+	var out []*EmailGroup
+	if err := c.do(ctx, "GET", "/api/v1/groups", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateEmailGroup 创建邮箱分组
+func (c *Client) CreateEmailGroup(ctx context.Context, body *CreateEmailGroupRequest) (*EmailGroup, error) {
+	var out EmailGroup
+	if err := c.do(ctx, "POST", "/api/v1/groups", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ReorderEmailGroups 调整分组顺序
+func (c *Client) ReorderEmailGroups(ctx context.Context, body *ReorderEmailGroupsRequest) ([]*EmailGroup, error) {
+	var out []*EmailGroup
+	if err := c.do(ctx, "PUT", "/api/v1/groups/reorder", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateEmailGroup 更新邮箱分组
+func (c *Client) UpdateEmailGroup(ctx context.Context, id int64, body *UpdateEmailGroupRequest) (*EmailGroup, error) {
+	var out EmailGroup
+	if err := c.do(ctx, "PUT", fmt.Sprintf("/api/v1/groups/%v", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteEmailGroup 删除邮箱分组
+func (c *Client) DeleteEmailGroup(ctx context.Context, id int64) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/groups/%v", url.PathEscape(fmt.Sprint(id))), nil, nil, nil)
+}
+
+// SetDefaultEmailGroup 设为默认分组
+func (c *Client) SetDefaultEmailGroup(ctx context.Context, id int64) (*EmailGroup, error) {
+	var out EmailGroup
+	if err := c.do(ctx, "PUT", fmt.Sprintf("/api/v1/groups/%v/default", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// IngestEmail 外部服务推送邮件，凭Bearer或X-Ingest-Token令牌认证；也接受原始MIME和带body-mime字段的表单，重复投递返回200
+func (c *Client) IngestEmail(ctx context.Context, params *IngestEmailParams, body *IngestMessageRequest) (*IngestResult, error) {
+	var out IngestResult
+	if err := c.do(ctx, "POST", "/api/v1/ingest", params.values(), jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetIngestEndpoints 获取入站邮件端点列表
+func (c *Client) GetIngestEndpoints(ctx context.Context) ([]*IngestEndpoint, error) {
+	var out []*IngestEndpoint
+	if err := c.do(ctx, "GET", "/api/v1/ingest-endpoints", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateIngestEndpoint 创建入站邮件端点，未指定账户时创建虚拟账户，令牌只返回一次
+func (c *Client) CreateIngestEndpoint(ctx context.Context, body *CreateIngestEndpointRequest) (*IngestEndpointWithToken, error) {
+	var out IngestEndpointWithToken
+	if err := c.do(ctx, "POST", "/api/v1/ingest-endpoints", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateIngestEndpoint 修改入站端点的名称、SMTP收件地址或启用状态
+func (c *Client) UpdateIngestEndpoint(ctx context.Context, id int64, body *UpdateIngestEndpointRequest) (*IngestEndpoint, error) {
+	var out IngestEndpoint
+	if err := c.do(ctx, "PATCH", fmt.Sprintf("/api/v1/ingest-endpoints/%v", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteIngestEndpoint 删除入站端点，已接收的邮件保留
+func (c *Client) DeleteIngestEndpoint(ctx context.Context, id int64) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/ingest-endpoints/%v", url.PathEscape(fmt.Sprint(id))), nil, nil, nil)
+}
+
+// RotateIngestToken 重新生成入站令牌，旧令牌立即失效
+func (c *Client) RotateIngestToken(ctx context.Context, id int64) (*IngestEndpointWithToken, error) {
+	var out IngestEndpointWithToken
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/ingest-endpoints/%v/rotate-token", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetLegalHolds 获取法律保全列表
+func (c *Client) GetLegalHolds(ctx context.Context) ([]*LegalHold, error) {
+	var out []*LegalHold
+	if err := c.do(ctx, "GET", "/api/v1/legal-holds", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateLegalHold 创建法律保全，符合发件人和日期条件的邮件在解除前不能删除
+func (c *Client) CreateLegalHold(ctx context.Context, body *CreateLegalHoldRequest) (*LegalHold, error) {
+	var out LegalHold
+	if err := c.do(ctx, "POST", "/api/v1/legal-holds", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetLegalHold 获取法律保全及覆盖的邮件数
+func (c *Client) GetLegalHold(ctx context.Context, id int64) (*LegalHoldDetail, error) {
+	var out LegalHoldDetail
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/legal-holds/%v", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetLegalHoldExports 获取法律保全的导出列表
+func (c *Client) GetLegalHoldExports(ctx context.Context, id int64) ([]*LegalHoldExport, error) {
+	var out []*LegalHoldExport
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/legal-holds/%v/exports", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateLegalHoldExport 在后台导出保全覆盖的邮件原文和带哈希的签名清单，返回202
+func (c *Client) CreateLegalHoldExport(ctx context.Context, id int64) (*LegalHoldExport, error) {
+	var out LegalHoldExport
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/legal-holds/%v/exports", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetLegalHoldExport 获取导出进度和结果
+func (c *Client) GetLegalHoldExport(ctx context.Context, id int64, exportID int64) (*LegalHoldExport, error) {
+	var out LegalHoldExport
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/legal-holds/%v/exports/%v", url.PathEscape(fmt.Sprint(id)), url.PathEscape(fmt.Sprint(exportID))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DownloadLegalHoldExport 下载已完成的导出压缩包
+func (c *Client) DownloadLegalHoldExport(ctx context.Context, id int64, exportID int64) (*http.Response, error) {
+	return c.doRaw(ctx, "GET", fmt.Sprintf("/api/v1/legal-holds/%v/exports/%v/download", url.PathEscape(fmt.Sprint(id)), url.PathEscape(fmt.Sprint(exportID))), nil, nil)
+}
+
+// VerifyLegalHoldExport 重新计算导出文件的哈希并校验清单签名
+func (c *Client) VerifyLegalHoldExport(ctx context.Context, id int64, exportID int64) (*LegalHoldExportVerification, error) {
+	var out LegalHoldExportVerification
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/legal-holds/%v/exports/%v/verify", url.PathEscape(fmt.Sprint(id)), url.PathEscape(fmt.Sprint(exportID))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ReleaseLegalHold 解除法律保全
+func (c *Client) ReleaseLegalHold(ctx context.Context, id int64) (*LegalHold, error) {
+	var out LegalHold
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/legal-holds/%v/release", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetMailFetchers 获取代收外部邮箱列表
+func (c *Client) GetMailFetchers(ctx context.Context) ([]*MailFetcher, error) {
+	var out []*MailFetcher
+	if err := c.do(ctx, "GET", "/api/v1/mail-fetchers", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateMailFetcher 创建代收，定时从外部POP3/IMAP邮箱收取邮件到账户的文件夹
+func (c *Client) CreateMailFetcher(ctx context.Context, body *MailFetcherRequest) (*MailFetcher, error) {
+	var out MailFetcher
+	if err := c.do(ctx, "POST", "/api/v1/mail-fetchers", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateMailFetcher 修改代收，密码为空时保留原密码
+func (c *Client) UpdateMailFetcher(ctx context.Context, id int64, body *MailFetcherRequest) (*MailFetcher, error) {
+	var out MailFetcher
+	if err := c.do(ctx, "PUT", fmt.Sprintf("/api/v1/mail-fetchers/%v", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteMailFetcher 删除代收，已收取的邮件保留
+func (c *Client) DeleteMailFetcher(ctx context.Context, id int64) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/mail-fetchers/%v", url.PathEscape(fmt.Sprint(id))), nil, nil, nil)
+}
+
+// RunMailFetcher 立即执行一次代收并返回收取结果
+func (c *Client) RunMailFetcher(ctx context.Context, id int64) (*MailFetchResult, error) {
+	var out MailFetchResult
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/mail-fetchers/%v/run", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetMailboxMigrations 获取邮箱迁移列表
+func (c *Client) GetMailboxMigrations(ctx context.Context) ([]*MailboxMigration, error) {
+	var out []*MailboxMigration
+	if err := c.do(ctx, "GET", "/api/v1/migrations", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateMailboxMigration 创建邮箱迁移并开始复制
+func (c *Client) CreateMailboxMigration(ctx context.Context, body *CreateMailboxMigrationRequest) (*MailboxMigration, error) {
+	var out MailboxMigration
+	if err := c.do(ctx, "POST", "/api/v1/migrations", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PlanMailboxMigration 生成默认文件夹映射
+func (c *Client) PlanMailboxMigration(ctx context.Context, params *PlanMailboxMigrationParams) ([]*MailboxMigrationFolderMapping, error) {
+	var out []*MailboxMigrationFolderMapping
+	if err := c.do(ctx, "GET", "/api/v1/migrations/plan", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetMailboxMigration 获取邮箱迁移进度
+func (c *Client) GetMailboxMigration(ctx context.Context, id int64) (*MailboxMigration, error) {
+	var out MailboxMigration
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/migrations/%v", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CancelMailboxMigration 取消迁移
+func (c *Client) CancelMailboxMigration(ctx context.Context, id int64) (*MailboxMigration, error) {
+	var out MailboxMigration
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/migrations/%v/cancel", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PauseMailboxMigration 暂停迁移
+func (c *Client) PauseMailboxMigration(ctx context.Context, id int64) (*MailboxMigration, error) {
+	var out MailboxMigration
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/migrations/%v/pause", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetMailboxMigrationReport 获取迁移核对报告
+func (c *Client) GetMailboxMigrationReport(ctx context.Context, id int64) (*MailboxMigrationReport, error) {
+	var out MailboxMigrationReport
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/migrations/%v/report", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ResumeMailboxMigration 从断点继续迁移
+func (c *Client) ResumeMailboxMigration(ctx context.Context, id int64) (*MailboxMigration, error) {
+	var out MailboxMigration
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/migrations/%v/resume", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ExecuteNotificationAction 执行通知中的快捷操作（一次性令牌）
+func (c *Client) ExecuteNotificationAction(ctx context.Context, token string) (*NotificationActionResult, error) {
+	var out NotificationActionResult
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/notification-actions/%v", url.PathEscape(fmt.Sprint(token))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetNotifications 分页获取通知中心的通知，按时间倒序，同时返回全部未读数
+func (c *Client) GetNotifications(ctx context.Context, params *GetNotificationsParams) (*ListNotificationsResponse, error) {
+	var out ListNotificationsResponse
+	if err := c.do(ctx, "GET", "/api/v1/notifications", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// MarkNotifications 批量标记通知为已读或未读，未指定ids时修改全部通知
+func (c *Client) MarkNotifications(ctx context.Context, body *MarkNotificationsRequest) (*MarkNotificationsResult, error) {
+	var out MarkNotificationsResult
+	if err := c.do(ctx, "PATCH", "/api/v1/notifications", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetNotificationCounts 获取未读通知的角标计数，按级别和事件类型分组
+func (c *Client) GetNotificationCounts(ctx context.Context) (*NotificationCounts, error) {
+	var out NotificationCounts
+	if err := c.do(ctx, "GET", "/api/v1/notifications/counts", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateNotification 标记单条通知为已读或未读
+func (c *Client) UpdateNotification(ctx context.Context, id int64, body *UpdateNotificationRequest) (*Notification, error) {
+	var out Notification
+	if err := c.do(ctx, "PATCH", fmt.Sprintf("/api/v1/notifications/%v", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateOAuth2Account 使用OAuth2令牌创建邮件账户
+func (c *Client) CreateOAuth2Account(ctx context.Context, body *CreateOAuth2AccountRequest) (*EmailAccount, error) {
+	var out EmailAccount
+	if err := c.do(ctx, "POST", "/api/v1/oauth/create-account", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// InitGmailOAuth 获取Gmail授权地址
+func (c *Client) InitGmailOAuth(ctx context.Context, params *InitGmailOAuthParams) (*OAuthURLResponse, error) {
+	var out OAuthURLResponse
+	if err := c.do(ctx, "GET", "/api/v1/oauth/gmail", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateManualOAuth2Account 手动配置OAuth2邮件账户
+func (c *Client) CreateManualOAuth2Account(ctx context.Context, body *CreateManualOAuth2AccountRequest) (*EmailAccount, error) {
+	var out EmailAccount
+	if err := c.do(ctx, "POST", "/api/v1/oauth/manual-config", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// InitOutlookOAuth 获取Outlook授权地址
+func (c *Client) InitOutlookOAuth(ctx context.Context, params *InitOutlookOAuthParams) (*OAuthURLResponse, error) {
+	var out OAuthURLResponse
+	if err := c.do(ctx, "GET", "/api/v1/oauth/outlook", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// HandleOAuth2Callback 处理OAuth2回调
+func (c *Client) HandleOAuth2Callback(ctx context.Context, provider string, params *HandleOAuth2CallbackParams) (*OAuthTokenResponse, error) {
+	var out OAuthTokenResponse
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/oauth/%v/callback", url.PathEscape(fmt.Sprint(provider))), params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetOpenAPISpec 获取OpenAPI文档
+func (c *Client) GetOpenAPISpec(ctx context.Context) (*http.Response, error) {
+	return c.doRaw(ctx, "GET", "/api/v1/openapi.json", nil, nil)
+}
+
+// GetOrganizationInvites 获取当前用户收到的待处理邀请
+func (c *Client) GetOrganizationInvites(ctx context.Context) ([]*OrganizationInvite, error) {
+	var out []*OrganizationInvite
+	if err := c.do(ctx, "GET", "/api/v1/organization-invites", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AcceptOrganizationInvite 接受组织邀请
+func (c *Client) AcceptOrganizationInvite(ctx context.Context, id int64) (*OrganizationInvite, error) {
+	var out OrganizationInvite
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/organization-invites/%v/accept", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeclineOrganizationInvite 拒绝组织邀请
+func (c *Client) DeclineOrganizationInvite(ctx context.Context, id int64) (*OrganizationInvite, error) {
+	var out OrganizationInvite
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/organization-invites/%v/decline", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetOrganizations 获取当前用户加入的组织
+func (c *Client) GetOrganizations(ctx context.Context) ([]*OrganizationSummary, error) {
+	var out []*OrganizationSummary
+	if err := c.do(ctx, "GET", "/api/v1/organizations", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateOrganization 创建组织，创建者成为所有者
+func (c *Client) CreateOrganization(ctx context.Context, body *CreateOrganizationRequest) (*Organization, error) {
+	var out Organization
+	if err := c.do(ctx, "POST", "/api/v1/organizations", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetOrganization 获取组织成员和共享邮箱，管理员还返回待处理的邀请和授权
+func (c *Client) GetOrganization(ctx context.Context, id int64) (*OrganizationDetail, error) {
+	var out OrganizationDetail
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/organizations/%v", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteOrganization 删除组织及其共享、授权和认领，仅所有者可以操作
+func (c *Client) DeleteOrganization(ctx context.Context, id int64) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/organizations/%v", url.PathEscape(fmt.Sprint(id))), nil, nil, nil)
+}
+
+// InviteOrganizationMember 按用户名邀请成员，邀请7天内有效
+func (c *Client) InviteOrganizationMember(ctx context.Context, id int64, body *InviteOrganizationMemberRequest) (*OrganizationInvite, error) {
+	var out OrganizationInvite
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/organizations/%v/invites", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RevokeOrganizationInvite 撤销待处理的邀请
+func (c *Client) RevokeOrganizationInvite(ctx context.Context, id int64, inviteID int64) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/organizations/%v/invites/%v", url.PathEscape(fmt.Sprint(id)), url.PathEscape(fmt.Sprint(inviteID))), nil, nil, nil)
+}
+
+// ShareOrganizationMailbox 将自己的邮箱账户共享到组织，需要是组织管理员
+func (c *Client) ShareOrganizationMailbox(ctx context.Context, id int64, body *ShareMailboxRequest) (*SharedMailbox, error) {
+	var out SharedMailbox
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/organizations/%v/mailboxes", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UnshareOrganizationMailbox 取消共享邮箱
+func (c *Client) UnshareOrganizationMailbox(ctx context.Context, id int64, mailboxID int64) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/organizations/%v/mailboxes/%v", url.PathEscape(fmt.Sprint(id)), url.PathEscape(fmt.Sprint(mailboxID))), nil, nil, nil)
+}
+
+// SetSharedMailboxGrant 授予或修改成员的共享邮箱权限（read_only、send_as）
+func (c *Client) SetSharedMailboxGrant(ctx context.Context, id int64, mailboxID int64, body *SharedMailboxGrantRequest) (*SharedMailboxGrant, error) {
+	var out SharedMailboxGrant
+	if err := c.do(ctx, "PUT", fmt.Sprintf("/api/v1/organizations/%v/mailboxes/%v/grants", url.PathEscape(fmt.Sprint(id)), url.PathEscape(fmt.Sprint(mailboxID))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RevokeSharedMailboxGrant 撤销成员的共享邮箱权限并释放其认领的邮件
+func (c *Client) RevokeSharedMailboxGrant(ctx context.Context, id int64, mailboxID int64, userID int64) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/organizations/%v/mailboxes/%v/grants/%v", url.PathEscape(fmt.Sprint(id)), url.PathEscape(fmt.Sprint(mailboxID)), url.PathEscape(fmt.Sprint(userID))), nil, nil, nil)
+}
+
+// UpdateOrganizationMember 修改成员角色，管理员的任免只能由所有者进行
+func (c *Client) UpdateOrganizationMember(ctx context.Context, id int64, userID int64, body *UpdateOrganizationMemberRequest) (*OrganizationMember, error) {
+	var out OrganizationMember
+	if err := c.do(ctx, "PATCH", fmt.Sprintf("/api/v1/organizations/%v/members/%v", url.PathEscape(fmt.Sprint(id)), url.PathEscape(fmt.Sprint(userID))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RemoveOrganizationMember 移除成员或退出组织，同时撤销授权并释放认领的邮件
+func (c *Client) RemoveOrganizationMember(ctx context.Context, id int64, userID int64) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/organizations/%v/members/%v", url.PathEscape(fmt.Sprint(id)), url.PathEscape(fmt.Sprint(userID))), nil, nil, nil)
+}
+
+// GetProviders 获取支持的邮件提供商
+func (c *Client) GetProviders(ctx context.Context) ([]*ProviderInfo, error) {
+	var out []*ProviderInfo
+	if err := c.do(ctx, "GET", "/api/v1/providers", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DetectProvider 根据邮箱地址检测提供商
+func (c *Client) DetectProvider(ctx context.Context, params *DetectProviderParams) (*ProviderInfo, error) {
+	var out ProviderInfo
+	if err := c.do(ctx, "GET", "/api/v1/providers/detect", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetRetentionPolicies 获取邮件保留规则列表
+func (c *Client) GetRetentionPolicies(ctx context.Context) ([]*RetentionPolicy, error) {
+	var out []*RetentionPolicy
+	if err := c.do(ctx, "GET", "/api/v1/retention-policies", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateRetentionPolicy 创建保留规则，定时删除或归档超过指定天数的邮件
+func (c *Client) CreateRetentionPolicy(ctx context.Context, body *RetentionPolicyRequest) (*RetentionPolicy, error) {
+	var out RetentionPolicy
+	if err := c.do(ctx, "POST", "/api/v1/retention-policies", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetRetentionRuns 获取保留规则的执行记录
+func (c *Client) GetRetentionRuns(ctx context.Context, params *GetRetentionRunsParams) ([]*RetentionRun, error) {
+	var out []*RetentionRun
+	if err := c.do(ctx, "GET", "/api/v1/retention-policies/runs", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateRetentionPolicy 修改保留规则
+func (c *Client) UpdateRetentionPolicy(ctx context.Context, id int64, body *RetentionPolicyRequest) (*RetentionPolicy, error) {
+	var out RetentionPolicy
+	if err := c.do(ctx, "PUT", fmt.Sprintf("/api/v1/retention-policies/%v", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteRetentionPolicy 删除保留规则及其执行记录
+func (c *Client) DeleteRetentionPolicy(ctx context.Context, id int64) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/retention-policies/%v", url.PathEscape(fmt.Sprint(id))), nil, nil, nil)
+}
+
+// PreviewRetentionPolicy 试运行保留规则，返回会被处理的邮件数量和样例
+func (c *Client) PreviewRetentionPolicy(ctx context.Context, id int64) (*RetentionPreview, error) {
+	var out RetentionPreview
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/retention-policies/%v/preview", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RunRetentionPolicy 立即执行保留规则，返回202和执行记录
+func (c *Client) RunRetentionPolicy(ctx context.Context, id int64) (*RetentionRun, error) {
+	var out RetentionRun
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/retention-policies/%v/run", url.PathEscape(fmt.Sprint(id))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetSettings 获取当前用户的设置，未设置的项为服务器配置的默认值
+func (c *Client) GetSettings(ctx context.Context) (*UserSettings, error) {
+	var out UserSettings
+	if err := c.do(ctx, "GET", "/api/v1/settings", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateSettings 修改当前用户的设置，只修改提供的字段，返回全部设置
+func (c *Client) UpdateSettings(ctx context.Context, body *UpdateUserSettingsRequest) (*UserSettings, error) {
+	var out UserSettings
+	if err := c.do(ctx, "PATCH", "/api/v1/settings", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetSharedMailboxes 获取当前用户可以访问的共享邮箱及权限
+func (c *Client) GetSharedMailboxes(ctx context.Context) ([]*SharedMailboxInfo, error) {
+	var out []*SharedMailboxInfo
+	if err := c.do(ctx, "GET", "/api/v1/shared-mailboxes", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetSharedMailboxEmails 分页获取共享邮箱中的邮件及认领状态
+func (c *Client) GetSharedMailboxEmails(ctx context.Context, id int64, params *GetSharedMailboxEmailsParams) (*ListSharedEmailsResponse, error) {
+	var out ListSharedEmailsResponse
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/shared-mailboxes/%v/emails", url.PathEscape(fmt.Sprint(id))), params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetSharedMailboxEmail 获取共享邮箱中的邮件，不改变已读状态
+func (c *Client) GetSharedMailboxEmail(ctx context.Context, id int64, emailID int64) (*SharedMailboxEmail, error) {
+	var out SharedMailboxEmail
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/shared-mailboxes/%v/emails/%v", url.PathEscape(fmt.Sprint(id)), url.PathEscape(fmt.Sprint(emailID))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AssignSharedMailboxEmail 将邮件分配给有权限的成员，需要是组织管理员
+func (c *Client) AssignSharedMailboxEmail(ctx context.Context, id int64, emailID int64, body *AssignEmailRequest) (*EmailAssignment, error) {
+	var out EmailAssignment
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/shared-mailboxes/%v/emails/%v/assign", url.PathEscape(fmt.Sprint(id)), url.PathEscape(fmt.Sprint(emailID))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ReleaseSharedMailboxEmail 释放认领，负责人或组织管理员可以操作
+func (c *Client) ReleaseSharedMailboxEmail(ctx context.Context, id int64, emailID int64) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/shared-mailboxes/%v/emails/%v/assignment", url.PathEscape(fmt.Sprint(id)), url.PathEscape(fmt.Sprint(emailID))), nil, nil, nil)
+}
+
+// ClaimSharedMailboxEmail 认领邮件，已被其他成员认领时返回409
+func (c *Client) ClaimSharedMailboxEmail(ctx context.Context, id int64, emailID int64) (*EmailAssignment, error) {
+	var out EmailAssignment
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/shared-mailboxes/%v/emails/%v/claim", url.PathEscape(fmt.Sprint(id)), url.PathEscape(fmt.Sprint(emailID))), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ViewSharedEmail 查看分享的邮件（只读页面）
+func (c *Client) ViewSharedEmail(ctx context.Context, token string) (*http.Response, error) {
+	return c.doRaw(ctx, "GET", fmt.Sprintf("/api/v1/shared/%v", url.PathEscape(fmt.Sprint(token))), nil, nil)
+}
+
+// DownloadSharedAttachment 下载分享邮件的附件
+func (c *Client) DownloadSharedAttachment(ctx context.Context, token string, attachmentID int64) (*http.Response, error) {
+	return c.doRaw(ctx, "GET", fmt.Sprintf("/api/v1/shared/%v/attachments/%v", url.PathEscape(fmt.Sprint(token)), url.PathEscape(fmt.Sprint(attachmentID))), nil, nil)
+}
+
+// UpdateEmailShare 修改分享链接的附件访问权限
+func (c *Client) UpdateEmailShare(ctx context.Context, id int64, body *UpdateEmailShareRequest) (*EmailShareLink, error) {
+	var out EmailShareLink
+	if err := c.do(ctx, "PATCH", fmt.Sprintf("/api/v1/shares/%v", url.PathEscape(fmt.Sprint(id))), nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RevokeEmailShare 撤销分享链接
+func (c *Client) RevokeEmailShare(ctx context.Context, id int64) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/shares/%v", url.PathEscape(fmt.Sprint(id))), nil, nil, nil)
+}
+
+// HandleSSE 订阅实时事件流
+func (c *Client) HandleSSE(ctx context.Context, params *HandleSSEParams) (*http.Response, error) {
+	return c.doRaw(ctx, "GET", "/api/v1/sse", params.values(), nil)
+}
+
+// HandleSSEEvents 订阅实时事件流（兼容旧路径）
+func (c *Client) HandleSSEEvents(ctx context.Context, params *HandleSSEEventsParams) (*http.Response, error) {
+	return c.doRaw(ctx, "GET", "/api/v1/sse/events", params.values(), nil)
+}
+
+// GetSSEStats 获取SSE统计
+func (c *Client) GetSSEStats(ctx context.Context) (*ServiceStats, error) {
+	var out ServiceStats
+	if err := c.do(ctx, "GET", "/api/v1/sse/stats", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SendTestEvent 发送测试事件
+func (c *Client) SendTestEvent(ctx context.Context, body *TestEventRequest) (*TestEventResult, error) {
+	var out TestEventResult
+	if err := c.do(ctx, "POST", "/api/v1/sse/test", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// TrackMailMergeClick 记录点击并跳转到原始链接
+func (c *Client) TrackMailMergeClick(ctx context.Context, token string, linkID int64) error {
+	return c.do(ctx, "GET", fmt.Sprintf("/api/v1/t/c/%v/%v", url.PathEscape(fmt.Sprint(token)), url.PathEscape(fmt.Sprint(linkID))), nil, nil, nil)
+}
+
+// TrackMailMergeOpen 活动邮件的打开跟踪像素
+func (c *Client) TrackMailMergeOpen(ctx context.Context, token string) (*http.Response, error) {
+	return c.doRaw(ctx, "GET", fmt.Sprintf("/api/v1/t/o/%v", url.PathEscape(fmt.Sprint(token))), nil, nil)
+}
+
+// GetTrash 获取回收站中的邮件
+func (c *Client) GetTrash(ctx context.Context, params *GetTrashParams) (*GetEmailsResponse, error) {
+	var out GetEmailsResponse
+	if err := c.do(ctx, "GET", "/api/v1/trash", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// EmptyTrash 清空回收站
+func (c *Client) EmptyTrash(ctx context.Context, params *EmptyTrashParams) (*TrashOperationResponse, error) {
+	var out TrashOperationResponse
+	if err := c.do(ctx, "DELETE", "/api/v1/trash", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PurgeTrashedEmails 彻底删除回收站中的邮件
+func (c *Client) PurgeTrashedEmails(ctx context.Context, body *TrashEmailsRequest) (*TrashOperationResponse, error) {
+	var out TrashOperationResponse
+	if err := c.do(ctx, "POST", "/api/v1/trash/purge", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RestoreTrashedEmails 从回收站恢复邮件
+func (c *Client) RestoreTrashedEmails(ctx context.Context, body *TrashEmailsRequest) (*TrashOperationResponse, error) {
+	var out TrashOperationResponse
+	if err := c.do(ctx, "POST", "/api/v1/trash/restore", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetVIPSenders 获取VIP发件人列表
+func (c *Client) GetVIPSenders(ctx context.Context) ([]*VIPSender, error) {
+	var out []*VIPSender
+	if err := c.do(ctx, "GET", "/api/v1/vip-senders", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AddVIPSender 添加VIP发件人，并标记其已有邮件
+func (c *Client) AddVIPSender(ctx context.Context, body *AddVIPSenderRequest) (*VIPSender, error) {
+	var out VIPSender
+	if err := c.do(ctx, "POST", "/api/v1/vip-senders", nil, jsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RemoveVIPSender 移除VIP发件人
+func (c *Client) RemoveVIPSender(ctx context.Context, id int64) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/vip-senders/%v", url.PathEscape(fmt.Sprint(id))), nil, nil, nil)
+}
+
+// HealthCheck 健康检查
+func (c *Client) HealthCheck(ctx context.Context) (*http.Response, error) {
+	return c.doRaw(ctx, "GET", "/health", nil, nil)
+}
@@ -14,7 +14,7 @@ import {
   Flag,
 } from 'lucide-react';
 import { useContextMenuStore, useMailboxStore, useComposeStore } from '@/lib/store';
-import { Email } from '@/types/email';
+import { Email, hasEmailBody } from '@/types/email';
 import { apiClient } from '@/lib/api';
 import { toast } from 'sonner';
 
@@ -62,6 +62,13 @@ export function EmailContextMenu() {
 
     const email = target.data as Email;
 
+    // 列表中的邮件不含正文，转发和复制内容时先获取详情
+    const loadEmailWithBody = async (): Promise<Email> => {
+      if (hasEmailBody(email)) return email;
+      const response = await apiClient.getEmailDetail(email.id);
+      return response.success && response.data ? response.data : email;
+    };
+
     try {
       switch (action) {
         case 'reply':
@@ -75,7 +82,7 @@ export function EmailContextMenu() {
           break;
 
         case 'forward':
-          initializeForward(email);
+          initializeForward(await loadEmailWithBody());
           toast.success('已打开转发窗口');
           break;
 
@@ -148,7 +155,8 @@ export function EmailContextMenu() {
 
         case 'copy':
           // 复制邮件内容到剪贴板
-          const content = `主题: ${email.subject}\n发件人: ${email.from}\n时间: ${email.date}\n\n${email.text_body || email.html_body}`;
+          const fullEmail = await loadEmailWithBody();
+          const content = `主题: ${email.subject}\n发件人: ${email.from}\n时间: ${email.date}\n\n${fullEmail.text_body || fullEmail.html_body}`;
           await navigator.clipboard.writeText(content);
           toast.success('邮件内容已复制到剪贴板');
           break;
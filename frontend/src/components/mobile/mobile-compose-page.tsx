@@ -19,6 +19,7 @@ import {
   SelectValue,
 } from '@/components/ui/select';
 import { apiClient } from '@/lib/api';
+import { hasEmailBody } from '@/types/email';
 
 export function MobileComposePage() {
   const router = useRouter();
@@ -76,7 +77,8 @@ export function MobileComposePage() {
             emails.find((email) => email.id === emailId) ||
             (selectedEmail?.id === emailId ? selectedEmail : undefined);
 
-          if (!originalEmail) {
+          // 转发需要原邮件正文，列表中的邮件不含正文
+          if (!originalEmail || (forwardId && !hasEmailBody(originalEmail))) {
             const response = await apiClient.getEmailDetail(emailId);
             if (response.success && response.data) {
               originalEmail = response.data;
@@ -19,6 +19,7 @@ import { toast } from 'sonner';
 import { LanguageCode } from '@/lib/translate';
 import {
   formatEmailAddress,
+  hasEmailBody,
   parseEmailAddress,
   parseEmailAddresses,
   type Email,
@@ -46,15 +47,15 @@ export function MobileEmailDetailPage({ emailId }: MobileEmailDetailPageProps) {
     const loadEmailDetail = async () => {
       setIsLoading(true);
       try {
-        // 先尝试从本地状态获取
+        // 先显示本地列表中的邮件，列表数据不含正文
         const localEmail = emails.find((email) => email.id === emailId);
         if (localEmail) {
-          setEmailDetail(localEmail);
+          setEmailDetail((prev) => (prev?.id === emailId ? prev : localEmail));
           selectEmail(localEmail);
         }
 
-        // 如果本地没有找到，从API获取
-        if (!localEmail) {
+        // 正文需通过详情接口获取
+        if (!localEmail || !hasEmailBody(localEmail)) {
           const response = await apiClient.getEmailDetail(emailId);
           if (response.success && response.data) {
             setEmailDetail(response.data);
@@ -75,7 +76,17 @@ export function MobileEmailDetailPage({ emailId }: MobileEmailDetailPageProps) {
   useEffect(() => {
     const storeEmail = emails.find((email) => email.id === emailId);
     if (storeEmail) {
-      setEmailDetail((prev) => (prev ? { ...prev, ...storeEmail } : storeEmail));
+      // 列表刷新后的邮件不含正文，保留已加载的正文
+      setEmailDetail((prev) =>
+        prev
+          ? {
+              ...prev,
+              ...storeEmail,
+              text_body: storeEmail.text_body || prev.text_body,
+              html_body: storeEmail.html_body || prev.html_body,
+            }
+          : storeEmail
+      );
     }
   }, [emailId, emails]);
 
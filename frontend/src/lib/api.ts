@@ -88,14 +88,26 @@ class ApiClient {
     return null;
   }
 
+  // 后端启用会话Cookie认证时，修改数据的请求需要带上CSRF Cookie中的令牌
+  private getCSRFToken(): string | null {
+    if (typeof document === 'undefined') {
+      return null;
+    }
+    const match = document.cookie.match(/(?:^|;\s*)firemail_session_csrf=([^;]+)/);
+    return match ? decodeURIComponent(match[1]) : null;
+  }
+
   private async request<T>(endpoint: string, options: RequestInit = {}): Promise<ApiResponse<T>> {
     const token = this.getAuthToken();
+    const csrfToken = this.getCSRFToken();
     const url = `${API_BASE_URL}${endpoint}`;
 
     const config: RequestInit = {
+      credentials: 'include',
       headers: {
         'Content-Type': 'application/json',
         ...(token && { Authorization: `Bearer ${token}` }),
+        ...(csrfToken && { 'X-CSRF-Token': csrfToken }),
         ...options.headers,
       },
       ...options,
@@ -603,6 +615,22 @@ class ApiClient {
     return response.blob();
   }
 
+  // 签发附件的短期下载链接，可直接用于 <img> 标签和下载链接，无需携带登录令牌
+  async getAttachmentDownloadUrl(attachmentId: number): Promise<{ url: string; expires_at: string }> {
+    const response = await this.request<{ token: string; url: string; expires_at: string }>(
+      `/attachments/${attachmentId}/token`,
+      { method: 'POST' }
+    );
+    if (!response.success || !response.data) {
+      throw new Error(response.message || 'Failed to create attachment download link');
+    }
+
+    return {
+      url: `${API_BASE_URL}/attachments/${attachmentId}/file?token=${encodeURIComponent(response.data.token)}`,
+      expires_at: response.data.expires_at,
+    };
+  }
+
   async getEmail(id: number): Promise<ApiResponse<Email>> {
     return this.request(`/emails/${id}`);
   }
@@ -0,0 +1,4100 @@
+// Code generated by firemail/cmd/openapi. DO NOT EDIT.
+/* eslint-disable */
+
+export interface AccountChange {
+  deleted?: boolean;
+  error_message?: string;
+  id?: number;
+  is_active?: boolean;
+  last_sync_at?: string | null;
+  sync_status?: string;
+  unread_emails?: number;
+}
+
+export interface AccountCountReport {
+  account_id?: number;
+  fixed?: boolean;
+  stored_total?: number;
+  stored_unread?: number;
+  total?: number;
+  unread?: number;
+}
+
+export interface AccountSetupGuide {
+  code?: string;
+  help_url?: string;
+  message?: string;
+  steps?: string[];
+}
+
+export interface AddVIPSenderRequest {
+  address: string;
+  name?: string;
+}
+
+export interface AnalyticsBusiestHours {
+  hours?: AnalyticsHourActivity[];
+  tz?: string;
+  weekdays?: AnalyticsWeekdayActivity[];
+}
+
+export interface AnalyticsHourActivity {
+  hour?: number;
+  received?: number;
+  sent?: number;
+}
+
+export interface AnalyticsOutboundAccount {
+  account_id?: number;
+  average_response_seconds?: number;
+  email?: string;
+  median_response_seconds?: number;
+  replied?: number;
+  reply_rate?: number;
+  sent?: number;
+}
+
+export interface AnalyticsOutboundContact {
+  address?: string;
+  average_response_seconds?: number;
+  last_replied_at?: string | null;
+  last_sent_at?: string;
+  median_response_seconds?: number;
+  name?: string;
+  replied?: number;
+  reply_rate?: number;
+  sent?: number;
+}
+
+export interface AnalyticsOutboundMessage {
+  account_id?: number;
+  email_id?: number;
+  recipients?: string[];
+  replied_at?: string | null;
+  replied_by?: string;
+  reply_email_id?: number | null;
+  response_seconds?: number | null;
+  sent_at?: string;
+  subject?: string;
+}
+
+export interface AnalyticsRecipient {
+  address?: string;
+  emails?: number;
+  last_at?: string;
+  name?: string;
+}
+
+export interface AnalyticsResponseTimes {
+  average_seconds?: number;
+  median_seconds?: number;
+  samples?: number;
+  within_day?: number;
+  within_hour?: number;
+}
+
+export interface AnalyticsVolumePoint {
+  period?: string;
+  received?: number;
+  sent?: number;
+}
+
+export interface AnalyticsWeekdayActivity {
+  received?: number;
+  sent?: number;
+  weekday?: number;
+}
+
+export interface AppearanceRequest {
+  color?: string | null;
+  icon?: string | null;
+  sort_order?: number | null;
+}
+
+export interface AssignEmailRequest {
+  assignee_id: number;
+}
+
+export interface Attachment {
+  block_reason?: string;
+  content_id?: string;
+  content_type?: string;
+  created_at?: string;
+  deleted_at?: string | null;
+  disposition?: string;
+  email?: Email;
+  email_id?: number | null;
+  encoding?: string;
+  filename?: string;
+  id?: number;
+  is_blocked?: boolean;
+  is_downloaded?: boolean;
+  is_inline?: boolean;
+  part_id?: string;
+  size?: number;
+  storage_path?: string;
+  updated_at?: string;
+  user_id?: number | null;
+}
+
+export interface AttachmentInfo {
+  block_reason?: string;
+  content_type?: string;
+  disposition?: string;
+  download_url?: string;
+  filename?: string;
+  id?: number;
+  is_blocked?: boolean;
+  is_downloaded?: boolean;
+  is_inline?: boolean;
+  size?: number;
+}
+
+export interface AttachmentListItem {
+  account_id?: number;
+  content_type?: string;
+  date?: string;
+  email_id?: number;
+  filename?: string;
+  from?: string;
+  id?: number;
+  is_downloaded?: boolean;
+  is_inline?: boolean;
+  size?: number;
+  subject?: string;
+}
+
+export interface AttachmentListResponse {
+  attachments?: AttachmentListItem[];
+  page?: number;
+  page_size?: number;
+  total?: number;
+  total_pages?: number;
+}
+
+export interface AttachmentPreview {
+  attachment_id?: number;
+  content?: string;
+  error?: string;
+  text?: string;
+  thumbnail?: string;
+  type?: string;
+}
+
+export interface AttachmentToken {
+  expires_at?: string;
+  token?: string;
+  url?: string;
+}
+
+export interface AttachmentUploadResult {
+  attachment_id?: number;
+  content_type?: string;
+  filename?: string;
+  size?: number;
+}
+
+export interface AuthFailureSummary {
+  failures?: number;
+  first_seen?: string;
+  ip?: string;
+  last_seen?: string;
+  suggest_ban?: boolean;
+  usernames?: string[];
+}
+
+export interface AuthFailuresResponse {
+  ips?: AuthFailureSummary[];
+  threshold?: number;
+  window?: string;
+}
+
+export interface AuthenticationResult {
+  method?: string;
+  properties?: Record<string, string>;
+  reason?: string;
+  result?: string;
+  source?: string;
+}
+
+export interface BatchAccountRequest {
+  account_ids: number[];
+}
+
+export interface BatchEmailOperation {
+  email_ids: number[];
+  folder_id?: number | null;
+  operation: "read" | "unread" | "delete" | "star" | "unstar" | "move" | "copy";
+  target_account_id?: number | null;
+  target_folder_id?: number | null;
+}
+
+export interface BatchEmailResult {
+  errors?: string[];
+  job?: EmailTransferJob;
+  success_count?: number;
+  total_count?: number;
+}
+
+export interface BlockEmailSenderRequest {
+  action?: string;
+  domain?: boolean;
+}
+
+export interface BlockedSender {
+  action?: string;
+  created_at?: string;
+  id?: number;
+  pattern?: string;
+}
+
+export interface BlockedSenderExport {
+  entries?: BlockedSenderRequest[];
+  exported_at?: string;
+}
+
+export interface BlockedSenderRequest {
+  action?: string;
+  pattern: string;
+}
+
+export interface CannedQuickItem {
+  id?: number;
+  name?: string;
+  preview?: string;
+  shortcut?: string;
+  suggested?: boolean;
+  usage_count?: number;
+}
+
+export interface CannedResponse {
+  created_at?: string;
+  folder_ids?: string;
+  html_body?: string;
+  id?: number;
+  last_used_at?: string | null;
+  name?: string;
+  shortcut?: string;
+  text_body?: string;
+  updated_at?: string;
+  usage_count?: number;
+}
+
+export interface CannedResponseRequest {
+  folder_ids?: number[];
+  html_body?: string;
+  name: string;
+  shortcut?: string;
+  text_body: string;
+}
+
+export interface ChangesResponse {
+  accounts?: AccountChange[];
+  emails?: EmailChanges;
+  folders?: FolderChange[];
+  has_more?: boolean;
+  reset?: boolean;
+  token?: number;
+}
+
+export interface ConfigView {
+  config_file?: string;
+  settings?: Setting[];
+  warnings?: string[];
+}
+
+export interface CreateEmailAccountRequest {
+  auth_method: "password" | "oauth2";
+  bounce_address?: string;
+  dns_resolver?: string;
+  ehlo_name?: string;
+  email: string;
+  group_id?: number | null;
+  imap_host?: string;
+  imap_port?: number;
+  imap_security?: string;
+  mailer?: string;
+  message_id_domain?: string;
+  name: string;
+  password?: string;
+  provider?: string;
+  send_aliases?: string[];
+  smtp_host?: string;
+  smtp_port?: number;
+  smtp_security?: string;
+  tls_ca_cert?: string | null;
+  tls_insecure_skip_verify?: boolean | null;
+  tls_min_version?: string | null;
+  tls_pinned_keys?: string[] | null;
+  username?: string;
+}
+
+export interface CreateEmailGroupRequest {
+  name: string;
+}
+
+export interface CreateEmailNoteRequest {
+  content: string;
+  sync_to_server?: boolean;
+}
+
+export interface CreateEmailShareRequest {
+  allow_attachments?: boolean;
+  expires_in_hours?: number;
+}
+
+export interface CreateEmailTemplateRequest {
+  category?: string;
+  description?: string;
+  html_body?: string;
+  is_shared?: boolean;
+  name: string;
+  subject: string;
+  tags?: string[];
+  text_body?: string;
+  variables?: TemplateVariable[];
+}
+
+export interface CreateFolderRequest {
+  display_name?: string;
+  name: string;
+  parent_id?: number | null;
+}
+
+export interface CreateIngestEndpointRequest {
+  account_id?: number | null;
+  address?: string;
+  folder_id?: number | null;
+  name: string;
+}
+
+export interface CreateLegalHoldRequest {
+  account_ids?: number[];
+  description?: string;
+  name: string;
+  senders?: string[];
+  since?: string | null;
+  until?: string | null;
+}
+
+export interface CreateMailboxMigrationRequest {
+  folders?: MailboxMigrationFolderMapping[];
+  source_account_id: number;
+  target_account_id: number;
+}
+
+export interface CreateManualOAuth2AccountRequest {
+  auth_url?: string;
+  client_id: string;
+  client_secret?: string;
+  email: string;
+  group_id?: number | null;
+  name: string;
+  provider: "gmail" | "outlook";
+  refresh_token: string;
+  scope?: string;
+  token_url?: string;
+}
+
+export interface CreateOAuth2AccountRequest {
+  access_token: string;
+  client_id: string;
+  email: string;
+  expires_at: number;
+  group_id?: number | null;
+  name: string;
+  provider: "gmail" | "outlook";
+  refresh_token: string;
+  scope?: string;
+}
+
+export interface CreateOrganizationRequest {
+  name: string;
+}
+
+export interface DownloadAttachmentsZipRequest {
+  attachment_ids: number[];
+}
+
+export interface DownloadProgress {
+  attachment_id?: number;
+  bytes_loaded?: number;
+  bytes_total?: number;
+  end_time?: string | null;
+  error?: string;
+  progress?: number;
+  start_time?: string;
+  status?: string;
+}
+
+export interface Draft {
+  account?: EmailAccount;
+  account_id?: number;
+  attachment_ids?: string;
+  bcc?: string;
+  cc?: string;
+  created_at?: string;
+  deleted_at?: string | null;
+  html_body?: string;
+  id?: number;
+  is_template?: boolean;
+  last_edited_at?: string | null;
+  markdown_body?: string;
+  priority?: string;
+  remote_folder?: string;
+  remote_message_id?: string;
+  remote_synced_at?: string | null;
+  remote_uid?: number;
+  subject?: string;
+  template_name?: string;
+  text_body?: string;
+  to?: string;
+  updated_at?: string;
+  user?: User;
+  user_id?: number;
+}
+
+export interface DraftAutosaveResult {
+  id?: number;
+  last_edited_at?: string | null;
+}
+
+export interface DraftRevision {
+  attachment_ids?: string;
+  bcc?: string;
+  cc?: string;
+  created_at?: string;
+  deleted_at?: string | null;
+  draft_id?: number;
+  html_body?: string;
+  id?: number;
+  markdown_body?: string;
+  priority?: string;
+  revision?: number;
+  source?: string;
+  subject?: string;
+  text_body?: string;
+  to?: string;
+  updated_at?: string;
+  user_id?: number;
+}
+
+export interface DuplicateEmail {
+  account_id?: number;
+  date?: string;
+  folder_id?: number | null;
+  folder_name?: string;
+  from?: string;
+  id?: number;
+  is_read?: boolean;
+  is_starred?: boolean;
+  size?: number;
+  subject?: string;
+}
+
+export interface DuplicateEmailGroup {
+  canonical_id?: number;
+  emails?: DuplicateEmail[];
+  fingerprint?: string;
+  match_type?: string;
+}
+
+export interface DuplicateResolution {
+  canonical_id?: number;
+  fingerprint?: string;
+}
+
+export interface DuplicateScanJob {
+  duplicate_count?: number;
+  finished_at?: string | null;
+  group_count?: number;
+  groups?: DuplicateEmailGroup[];
+  id?: string;
+  last_error?: string;
+  scanned?: number;
+  started_at?: string;
+  status?: string;
+}
+
+export interface Email {
+  account?: EmailAccount;
+  account_id?: number;
+  attachment_count?: number;
+  attachments?: Attachment[];
+  bcc?: string;
+  cc?: string;
+  created_at?: string;
+  date?: string;
+  deleted_at?: string | null;
+  flag_color?: string;
+  folder?: Folder;
+  folder_id?: number | null;
+  follow_up_at?: string | null;
+  follow_up_completed_at?: string | null;
+  from?: string;
+  has_attachment?: boolean;
+  html_body?: string;
+  id?: number;
+  importance_bucket?: string;
+  importance_manual?: boolean;
+  importance_score?: number;
+  is_answered?: boolean;
+  is_deleted?: boolean;
+  is_draft?: boolean;
+  is_follow_up?: boolean;
+  is_forwarded?: boolean;
+  is_important?: boolean;
+  is_pinned?: boolean;
+  is_read?: boolean;
+  is_sent?: boolean;
+  is_starred?: boolean;
+  is_vip?: boolean;
+  labels?: string;
+  message_id?: string;
+  notes?: EmailNote[];
+  pinned_at?: string | null;
+  preview?: string | null;
+  priority?: string;
+  reply_later_at?: string | null;
+  reply_later_remind_at?: string | null;
+  reply_to?: string;
+  sender_avatar_hash?: string;
+  sender_initials?: string;
+  size?: number;
+  subject?: string;
+  synced_at?: string | null;
+  text_body?: string;
+  thread_id?: string;
+  to?: string;
+  trackers_removed?: number;
+  trashed_at?: string | null;
+  uid?: number;
+  updated_at?: string;
+  version?: number;
+}
+
+export interface EmailAccount {
+  auth_method?: string;
+  avatar_emoji?: string;
+  avatar_initials?: string;
+  bounce_address?: string;
+  color?: string;
+  created_at?: string;
+  deleted_at?: string | null;
+  display_order?: number;
+  dns_resolver?: string;
+  ehlo_name?: string;
+  email?: string;
+  emails?: Email[];
+  error_message?: string;
+  folders?: Folder[];
+  group?: EmailGroup;
+  group_id?: number | null;
+  id?: number;
+  imap_host?: string;
+  imap_port?: number;
+  imap_security?: string;
+  is_active?: boolean;
+  last_sync_at?: string | null;
+  mailer?: string;
+  max_part_size?: number;
+  message_id_domain?: string;
+  name?: string;
+  nickname?: string;
+  notifications_muted?: boolean;
+  provider?: string;
+  send_aliases?: string;
+  setup_guide?: AccountSetupGuide;
+  smtp_host?: string;
+  smtp_port?: number;
+  smtp_security?: string;
+  sync_paused?: boolean;
+  sync_paused_at?: string | null;
+  sync_status?: string;
+  tls_ca_cert?: string;
+  tls_insecure_skip_verify?: boolean;
+  tls_min_version?: string;
+  tls_pinned_keys?: string;
+  tls_warning?: string;
+  total_emails?: number;
+  unread_emails?: number;
+  updated_at?: string;
+  user?: User;
+  user_id?: number;
+  username?: string;
+  version?: number;
+}
+
+export interface EmailAddress {
+  address?: string;
+  name?: string;
+}
+
+export interface EmailAssignment {
+  assigned_by?: number;
+  assignee_id?: number;
+  created_at?: string;
+  email_id?: number;
+  id?: number;
+  shared_mailbox_id?: number;
+  updated_at?: string;
+}
+
+export interface EmailAttachment {
+  content_type?: string;
+  data?: string;
+  encoding?: string;
+  filename: string;
+  size?: number;
+}
+
+export interface EmailAvatar {
+  gravatar_hash?: string;
+  initials?: string;
+}
+
+export interface EmailChanges {
+  created?: number[];
+  deleted?: number[];
+  updated?: number[];
+}
+
+export interface EmailGroup {
+  account_count?: number;
+  accounts?: EmailAccount[];
+  created_at?: string;
+  deleted_at?: string | null;
+  id?: number;
+  is_default?: boolean;
+  name?: string;
+  sort_order?: number;
+  system_key?: string | null;
+  updated_at?: string;
+  user_id?: number;
+}
+
+export interface EmailHeaderField {
+  name?: string;
+  value?: string;
+}
+
+export interface EmailHistoryEntry {
+  account_id?: number;
+  created_at?: string;
+  details?: string;
+  email_id?: number;
+  from_folder?: string;
+  from_folder_id?: number | null;
+  id?: number;
+  related_email_id?: number | null;
+  source?: string;
+  to_folder?: string;
+  to_folder_id?: number | null;
+  type?: string;
+}
+
+export interface EmailListGroup {
+  count?: number;
+  email_ids?: number[];
+  key?: string;
+  label?: string;
+}
+
+export interface EmailNavigation {
+  email_id?: number;
+  next_id?: number | null;
+  position?: number;
+  previous_id?: number | null;
+  total?: number;
+}
+
+export interface EmailNote {
+  content?: string;
+  created_at?: string;
+  deleted_at?: string | null;
+  email_id?: number;
+  id?: number;
+  sync_to_server?: boolean;
+  synced_at?: string | null;
+  updated_at?: string;
+}
+
+export interface EmailShareLink {
+  access_count?: number;
+  active?: boolean;
+  allow_attachments?: boolean;
+  created_at?: string;
+  deleted_at?: string | null;
+  email_id?: number;
+  expires_at?: string;
+  id?: number;
+  last_accessed_at?: string | null;
+  revoked_at?: string | null;
+  updated_at?: string;
+  url?: string;
+}
+
+export interface EmailSource {
+  email_id?: number;
+  headers?: EmailHeaderField[];
+  raw_headers?: string;
+  size?: number;
+  structure?: MIMEPart;
+}
+
+export interface EmailSummary {
+  account_id?: number;
+  attachment_count?: number;
+  avatar?: EmailAvatar;
+  date?: string;
+  flag_color?: string;
+  folder_id?: number | null;
+  follow_up_at?: string | null;
+  from?: string;
+  has_attachment?: boolean;
+  id?: number;
+  importance_bucket?: string;
+  is_answered?: boolean;
+  is_draft?: boolean;
+  is_follow_up?: boolean;
+  is_forwarded?: boolean;
+  is_important?: boolean;
+  is_pinned?: boolean;
+  is_read?: boolean;
+  is_sent?: boolean;
+  is_starred?: boolean;
+  is_vip?: boolean;
+  preview?: string;
+  priority?: string;
+  size?: number;
+  subject?: string;
+  thread_id?: string;
+}
+
+export interface EmailTemplate {
+  category?: string;
+  created_at?: string;
+  deleted_at?: string | null;
+  description?: string;
+  html_body?: string;
+  id?: number;
+  is_active?: boolean;
+  is_built_in?: boolean;
+  is_default?: boolean;
+  is_shared?: boolean;
+  last_used_at?: string | null;
+  name?: string;
+  subject?: string;
+  tags?: string;
+  text_body?: string;
+  updated_at?: string;
+  usage_count?: number;
+  user?: User;
+  user_id?: number;
+  variables?: string;
+}
+
+export interface EmailTransferJob {
+  copy?: boolean;
+  failed?: number;
+  finished_at?: string | null;
+  id?: string;
+  last_error?: string;
+  processed?: number;
+  started_at?: string;
+  status?: string;
+  succeeded?: number;
+  target_account_id?: number;
+  target_folder_id?: number;
+  total?: number;
+}
+
+export interface Error {
+  locations?: Location[];
+  message?: string;
+  path?: unknown[];
+}
+
+export interface ErrorResponse {
+  code?: string;
+  current?: unknown;
+  error?: string;
+  message?: string;
+  setup_guide?: AccountSetupGuide;
+  size_limit?: MessageTooLargeError;
+}
+
+export interface Event {
+  account_id?: number | null;
+  data?: unknown;
+  id?: string;
+  notification_id?: number;
+  priority?: number;
+  retry?: number | null;
+  seq?: number;
+  timestamp?: string;
+  type?: string;
+  user_id?: number;
+}
+
+export interface EventBatch {
+  cursor?: number;
+  events?: Event[];
+  reset?: boolean;
+}
+
+export interface Folder {
+  account?: EmailAccount;
+  account_id?: number;
+  children?: Folder[];
+  color?: string;
+  created_at?: string;
+  deleted_at?: string | null;
+  delimiter?: string;
+  display_name?: string;
+  emails?: Email[];
+  icon?: string;
+  id?: number;
+  is_selectable?: boolean;
+  is_subscribed?: boolean;
+  label_mode?: string;
+  name?: string;
+  parent?: Folder;
+  parent_id?: number | null;
+  path?: string;
+  sort_order?: number;
+  total_emails?: number;
+  type?: string;
+  type_locked?: boolean;
+  uid_next?: number;
+  uid_validity?: number;
+  unread_emails?: number;
+  updated_at?: string;
+}
+
+export interface FolderChange {
+  account_id?: number;
+  deleted?: boolean;
+  id?: number;
+  total_emails?: number;
+  unread_emails?: number;
+}
+
+export interface FolderCountReconciliation {
+  accounts?: AccountCountReport[];
+  checked_accounts?: number;
+  checked_folders?: number;
+  drifted_folders?: number;
+  errors?: string[];
+  fixed_folders?: number;
+  folders?: FolderCountReport[];
+  server_mismatch?: number;
+}
+
+export interface FolderCountReport {
+  account_id?: number;
+  fixed?: boolean;
+  folder_id?: number;
+  path?: string;
+  server_error?: string;
+  server_total?: number | null;
+  server_unread?: number | null;
+  stored_total?: number;
+  stored_unread?: number;
+  total?: number;
+  unread?: number;
+}
+
+export interface ForwardEmailRequest {
+  account_id: number;
+  bcc?: EmailAddress[];
+  cc?: EmailAddress[];
+  html_body?: string;
+  subject?: string;
+  text_body?: string;
+  to: EmailAddress[];
+}
+
+export interface ForwardingRule {
+  account_id?: number;
+  created_at?: string;
+  failed_count?: number;
+  forward_to?: string;
+  forwarded_count?: number;
+  from_contains?: string;
+  id?: number;
+  is_enabled?: boolean;
+  last_error?: string;
+  last_forwarded_at?: string | null;
+  loop_count?: number;
+  mode?: string;
+  name?: string;
+  subject_contains?: string;
+  updated_at?: string;
+}
+
+export interface ForwardingRuleRequest {
+  account_id: number;
+  forward_to: string;
+  from_contains?: string;
+  is_enabled?: boolean | null;
+  mode?: "inline" | "attachment";
+  name: string;
+  subject_contains?: string;
+}
+
+export interface GetEmailsResponse {
+  emails?: Email[];
+  groups?: EmailListGroup[];
+  has_more?: boolean;
+  next_cursor?: string;
+  page?: number;
+  page_size?: number;
+  summaries?: EmailSummary[];
+  total?: number;
+  total_pages?: number;
+}
+
+export interface GmailLabelMapping {
+  configurable?: boolean;
+  display_name?: string;
+  effective_mode?: string;
+  folder_id?: number;
+  label?: string;
+  mode?: string;
+  path?: string;
+}
+
+export interface GmailLabelModeUpdate {
+  folder_id: number;
+  mode?: "folder" | "merge" | "skip";
+}
+
+export interface HeaderAnalysis {
+  authentication?: AuthenticationResult[];
+  dkim?: string;
+  dkim_domains?: string[];
+  dmarc?: string;
+  email_id?: number;
+  hops?: ReceivedHop[];
+  originating_ip?: OriginatingIP;
+  spf?: string;
+  total_delay_seconds?: number | null;
+  warnings?: string[];
+}
+
+export interface HealthResponse {
+  service?: string;
+  status?: string;
+  version?: string;
+}
+
+export interface IPGeoInfo {
+  as?: string;
+  city?: string;
+  country?: string;
+  country_code?: string;
+  ip?: string;
+  isp?: string;
+  latitude?: number;
+  longitude?: number;
+  org?: string;
+  region?: string;
+}
+
+export interface ImportBlockedSendersRequest {
+  entries: BlockedSenderRequest[];
+  replace?: boolean;
+}
+
+export interface ImportBlockedSendersResult {
+  errors?: string[];
+  imported?: number;
+  skipped?: number;
+}
+
+export interface ImportDraftsRequest {
+  account_id: number;
+}
+
+export interface ImportDraftsResult {
+  imported?: number;
+}
+
+export interface IngestAttachment {
+  content_base64: string;
+  content_type?: string;
+  filename: string;
+}
+
+export interface IngestEndpoint {
+  account_id?: number;
+  address?: string | null;
+  created_at?: string;
+  folder_id?: number;
+  id?: number;
+  is_enabled?: boolean;
+  last_used_at?: string | null;
+  name?: string;
+  received_count?: number;
+  token_prefix?: string;
+  updated_at?: string;
+}
+
+export interface IngestEndpointWithToken {
+  account_id?: number;
+  address?: string | null;
+  created_at?: string;
+  folder_id?: number;
+  id?: number;
+  is_enabled?: boolean;
+  last_used_at?: string | null;
+  name?: string;
+  received_count?: number;
+  token?: string;
+  token_prefix?: string;
+  updated_at?: string;
+}
+
+export interface IngestMessageRequest {
+  attachments?: IngestAttachment[];
+  cc?: string[];
+  date?: string | null;
+  from?: string;
+  html?: string;
+  message_id?: string;
+  raw_mime?: string;
+  raw_mime_base64?: string;
+  reply_to?: string;
+  subject?: string;
+  text?: string;
+  to?: string[];
+}
+
+export interface IngestResult {
+  duplicate?: boolean;
+  email_id?: number | null;
+  message_id?: string;
+}
+
+export interface InlineAttachment {
+  content_id: string;
+  content_type?: string;
+  data?: string;
+  filename: string;
+  size?: number;
+}
+
+export interface InviteOrganizationMemberRequest {
+  role?: string;
+  username: string;
+}
+
+export interface Job {
+  attempts?: number;
+  created_at?: string;
+  finished_at?: string | null;
+  id?: number;
+  last_error?: string;
+  locked_at?: string | null;
+  locked_by?: string;
+  max_attempts?: number;
+  payload?: string;
+  run_at?: string;
+  status?: string;
+  type?: string;
+  unique_key?: string;
+  updated_at?: string;
+  user_id?: number;
+}
+
+export interface JobList {
+  counts?: Record<string, number>;
+  jobs?: Job[];
+}
+
+export interface Label {
+  account_id?: number;
+  color?: string;
+  created_at?: string;
+  icon?: string;
+  id?: number;
+  name?: string;
+  sort_order?: number;
+  updated_at?: string;
+}
+
+export interface LegalHold {
+  account_ids?: string;
+  created_at?: string;
+  description?: string;
+  id?: number;
+  name?: string;
+  released_at?: string | null;
+  senders?: string;
+  since?: string | null;
+  until?: string | null;
+  updated_at?: string;
+}
+
+export interface LegalHoldDetail {
+  account_ids?: string;
+  created_at?: string;
+  description?: string;
+  id?: number;
+  matched_bytes?: number;
+  matched_emails?: number;
+  name?: string;
+  released_at?: string | null;
+  senders?: string;
+  since?: string | null;
+  until?: string | null;
+  updated_at?: string;
+}
+
+export interface LegalHoldExport {
+  archive_sha256?: string;
+  exported_emails?: number;
+  file_size?: number;
+  finished_at?: string | null;
+  hold_id?: number;
+  id?: number;
+  last_error?: string;
+  local_emails?: number;
+  manifest_sha256?: string;
+  signature?: string;
+  started_at?: string;
+  status?: string;
+  total_emails?: number;
+}
+
+export interface LegalHoldExportVerification {
+  archive_matches?: boolean;
+  archive_sha256?: string;
+  entries_checked?: number;
+  entry_mismatches?: string[];
+  export_id?: number;
+  signature_valid?: boolean;
+  valid?: boolean;
+}
+
+export interface ListDraftsResponse {
+  drafts?: Draft[];
+  page?: number;
+  page_size?: number;
+  total?: number;
+  total_pages?: number;
+}
+
+export interface ListEmailTemplatesResponse {
+  page?: number;
+  page_size?: number;
+  templates?: EmailTemplate[];
+  total?: number;
+  total_pages?: number;
+}
+
+export interface ListMailMergeRecipientsResponse {
+  page?: number;
+  page_size?: number;
+  recipients?: MailMergeRecipient[];
+  total?: number;
+  total_pages?: number;
+}
+
+export interface ListNotificationsResponse {
+  notifications?: Notification[];
+  page?: number;
+  page_size?: number;
+  total?: number;
+  total_pages?: number;
+  unread_count?: number;
+}
+
+export interface ListSharedEmailsResponse {
+  emails?: SharedMailboxEmail[];
+  page?: number;
+  page_size?: number;
+  total?: number;
+  total_pages?: number;
+}
+
+export interface Location {
+  column?: number;
+  line?: number;
+}
+
+export interface LoginRequest {
+  password: string;
+  username: string;
+}
+
+export interface LoginResponse {
+  csrf_token?: string;
+  expires_at?: string;
+  token?: string;
+  user?: User;
+}
+
+export interface MIMEPart {
+  boundary?: string;
+  charset?: string;
+  children?: MIMEPart[];
+  content_id?: string;
+  content_type?: string;
+  description?: string;
+  disposition?: string;
+  encoding?: string;
+  error?: string;
+  filename?: string;
+  lines?: number;
+  part_id?: string;
+  size?: number;
+}
+
+export interface MailFetchResult {
+  error?: string;
+  failed?: number;
+  fetched?: number;
+  fetcher_id?: number;
+  remaining?: number;
+}
+
+export interface MailFetcher {
+  account_id?: number;
+  created_at?: string;
+  delete_after_fetch?: boolean;
+  fetched_count?: number;
+  folder_id?: number;
+  host?: string;
+  id?: number;
+  interval_minutes?: number;
+  is_enabled?: boolean;
+  label?: string;
+  last_error?: string;
+  last_run_at?: string | null;
+  name?: string;
+  port?: number;
+  protocol?: string;
+  security?: string;
+  source_folder?: string;
+  updated_at?: string;
+  username?: string;
+}
+
+export interface MailFetcherRequest {
+  account_id: number;
+  delete_after_fetch?: boolean;
+  folder_id?: number | null;
+  host: string;
+  interval_minutes?: number;
+  is_enabled?: boolean | null;
+  label?: string;
+  name: string;
+  password?: string;
+  port: number;
+  protocol: "pop3" | "imap";
+  security?: "SSL" | "TLS" | "STARTTLS" | "NONE";
+  source_folder?: string;
+  username: string;
+}
+
+export interface MailMergeCampaign {
+  account_id?: number;
+  clicked_count?: number;
+  completed_at?: string | null;
+  created_at?: string;
+  deleted_at?: string | null;
+  failed_count?: number;
+  id?: number;
+  last_error?: string;
+  name?: string;
+  opened_count?: number;
+  rate_per_minute?: number;
+  sent_count?: number;
+  skipped_count?: number;
+  started_at?: string | null;
+  status?: string;
+  template_id?: number;
+  total_recipients?: number;
+  track_clicks?: boolean;
+  track_opens?: boolean;
+  updated_at?: string;
+  use_verp?: boolean;
+  user_id?: number;
+}
+
+export interface MailMergeCampaignReport {
+  campaign?: MailMergeCampaign;
+  click_rate?: number;
+  clicked_count?: number;
+  excluded_count?: number;
+  open_rate?: number;
+  opened_count?: number;
+  top_links?: MailMergeLink[];
+  tracked_count?: number;
+}
+
+export interface MailMergeLink {
+  campaign_id?: number;
+  click_count?: number;
+  created_at?: string;
+  id?: number;
+  url?: string;
+}
+
+export interface MailMergeRecipient {
+  campaign_id?: number;
+  click_count?: number;
+  created_at?: string;
+  deleted_at?: string | null;
+  email?: string;
+  error?: string;
+  first_clicked_at?: string | null;
+  first_opened_at?: string | null;
+  id?: number;
+  line_number?: number;
+  name?: string;
+  open_count?: number;
+  send_id?: string;
+  sent_at?: string | null;
+  status?: string;
+  tracking_excluded?: boolean;
+  updated_at?: string;
+  variables?: string;
+}
+
+export interface MailboxMigration {
+  completed_at?: string | null;
+  copied_emails?: number;
+  created_at?: string;
+  deleted_at?: string | null;
+  failed_emails?: number;
+  folders?: MailboxMigrationFolder[];
+  id?: number;
+  last_error?: string;
+  source_account_id?: number;
+  started_at?: string | null;
+  status?: string;
+  target_account_id?: number;
+  total_emails?: number;
+  updated_at?: string;
+  user_id?: number;
+  verified_at?: string | null;
+}
+
+export interface MailboxMigrationFolder {
+  copied_emails?: number;
+  created_at?: string;
+  deleted_at?: string | null;
+  failed_emails?: number;
+  id?: number;
+  last_error?: string;
+  last_uid?: number;
+  migration_id?: number;
+  sort_order?: number;
+  source_count?: number | null;
+  source_path?: string;
+  status?: string;
+  target_count?: number | null;
+  target_path?: string;
+  total_emails?: number;
+  uid_validity?: number;
+  updated_at?: string;
+}
+
+export interface MailboxMigrationFolderMapping {
+  source_path?: string;
+  target_path?: string;
+}
+
+export interface MailboxMigrationFolderReport {
+  copied_emails?: number;
+  failed_emails?: number;
+  matched?: boolean;
+  source_count?: number | null;
+  source_path?: string;
+  target_count?: number | null;
+  target_path?: string;
+}
+
+export interface MailboxMigrationReport {
+  copied_emails?: number;
+  failed_emails?: number;
+  folders?: MailboxMigrationFolderReport[];
+  matched?: boolean;
+  migration_id?: number;
+  status?: string;
+  verified_at?: string | null;
+}
+
+export interface MailboxStorageReport {
+  account_id?: number;
+  largest_attachments?: StorageAttachmentSummary[];
+  largest_emails?: StorageEmailSummary[];
+  oldest_unread_newsletters?: StorageEmailSummary[];
+  top_senders?: SenderVolume[];
+  total_emails?: number;
+  total_size?: number;
+}
+
+export interface MarkNotificationsRequest {
+  ids?: number[];
+  is_read: boolean | null;
+}
+
+export interface MarkNotificationsResult {
+  unread_count?: number;
+  updated?: number;
+}
+
+export interface MarkdownPreview {
+  html_body?: string;
+  text_body?: string;
+}
+
+export interface MarkdownPreviewRequest {
+  code_highlight?: boolean | null;
+  markdown?: string;
+}
+
+export interface MessageTooLargeError {
+  attachments_size?: number;
+  limit?: number;
+  provider?: string;
+  size?: number;
+}
+
+export interface MoveEmailRequest {
+  copy?: boolean;
+  target_account_id?: number | null;
+  target_folder_id: number;
+}
+
+export interface MutedThread {
+  created_at?: string;
+  id?: number;
+  subject?: string;
+  thread_id?: string;
+}
+
+export interface Notification {
+  account_id?: number | null;
+  created_at?: string;
+  data?: string;
+  event_type?: string;
+  id?: number;
+  is_read?: boolean;
+  level?: string;
+  message?: string;
+  read_at?: string | null;
+  title?: string;
+}
+
+export interface NotificationActionResult {
+  action?: string;
+  email_id?: number;
+}
+
+export interface NotificationCounts {
+  by_event_type?: Record<string, number>;
+  by_level?: Record<string, number>;
+  unread?: number;
+}
+
+export interface OAuthTokenResponse {
+  access_token?: string;
+  client_id?: string;
+  expires_in?: number;
+  refresh_token?: string;
+  scope?: string;
+  token_type?: string;
+}
+
+export interface OAuthURLResponse {
+  auth_url?: string;
+  state?: string;
+}
+
+export interface Organization {
+  created_at?: string;
+  created_by?: number;
+  id?: number;
+  name?: string;
+  updated_at?: string;
+}
+
+export interface OrganizationDetail {
+  created_at?: string;
+  created_by?: number;
+  id?: number;
+  invites?: OrganizationInvite[];
+  mailboxes?: SharedMailboxInfo[];
+  members?: OrganizationMemberInfo[];
+  name?: string;
+  role?: string;
+  updated_at?: string;
+}
+
+export interface OrganizationInvite {
+  created_at?: string;
+  expires_at?: string;
+  id?: number;
+  invited_by?: number;
+  invitee?: User;
+  invitee_id?: number;
+  organization?: Organization;
+  organization_id?: number;
+  responded_at?: string | null;
+  role?: string;
+  status?: string;
+}
+
+export interface OrganizationMember {
+  created_at?: string;
+  id?: number;
+  organization_id?: number;
+  role?: string;
+  updated_at?: string;
+  user_id?: number;
+}
+
+export interface OrganizationMemberInfo {
+  display_name?: string;
+  joined_at?: string;
+  role?: string;
+  user_id?: number;
+  username?: string;
+}
+
+export interface OrganizationSummary {
+  created_at?: string;
+  created_by?: number;
+  id?: number;
+  member_count?: number;
+  name?: string;
+  role?: string;
+  updated_at?: string;
+}
+
+export interface OriginatingIP {
+  geo?: IPGeoInfo;
+  geo_error?: string;
+  ip?: string;
+  private?: boolean;
+  source?: string;
+}
+
+export interface ProviderInfo {
+  auth_methods?: string[];
+  display_name?: string;
+  domains?: string[];
+  imap?: ProviderServer;
+  name?: string;
+  smtp?: ProviderServer;
+}
+
+export interface ProviderServer {
+  host?: string;
+  port?: number;
+  security?: string;
+}
+
+export interface RebuildVolumeStatsRequest {
+  account_id?: number | null;
+}
+
+export interface ReceivedHop {
+  by?: string;
+  delay_seconds?: number | null;
+  for?: string;
+  from?: string;
+  from_ip?: string;
+  id?: string;
+  index?: number;
+  raw?: string;
+  timestamp?: string | null;
+  with?: string;
+}
+
+export interface ReconcileFolderCountsRequest {
+  account_id?: number | null;
+  check_server?: boolean;
+  dry_run?: boolean;
+  folder_id?: number | null;
+}
+
+export interface RedecodeEmailRequest {
+  charset: string;
+}
+
+export interface RenderCannedResponseRequest {
+  email_id?: number | null;
+  variables?: Record<string, unknown>;
+}
+
+export interface RenderedCannedResponse {
+  html_body?: string;
+  missing_variables?: string[];
+  text_body?: string;
+}
+
+export interface ReorderEmailAccountsRequest {
+  account_ids: number[];
+}
+
+export interface ReorderEmailGroupsRequest {
+  group_ids: number[];
+}
+
+export interface ReparseJob {
+  failed?: number;
+  finished_at?: string | null;
+  id?: string;
+  last_error?: string;
+  processed?: number;
+  started_at?: string;
+  status?: string;
+  succeeded?: number;
+  total?: number;
+}
+
+export interface ReplyEmailRequest {
+  account_id: number;
+  bcc?: EmailAddress[];
+  cc?: EmailAddress[];
+  html_body?: string;
+  subject?: string;
+  text_body?: string;
+  to?: EmailAddress[];
+}
+
+export interface ReplyLaterRequest {
+  remind_at?: string | null;
+}
+
+export interface Request {
+  operationName?: string;
+  query?: string;
+  variables?: Record<string, unknown>;
+}
+
+export interface ResolveDuplicatesRequest {
+  action: "delete" | "move";
+  groups?: DuplicateResolution[];
+  target_folder_id?: number | null;
+}
+
+export interface ResolveDuplicatesResult {
+  errors?: string[];
+  failed?: number;
+  groups?: number;
+  succeeded?: number;
+  transfer?: EmailTransferJob;
+}
+
+export interface ResolveSyncConflictRequest {
+  resolution: "server" | "local";
+}
+
+export interface Response {
+  data?: unknown;
+  errors?: Error[];
+}
+
+export interface RetentionPolicy {
+  account_id?: number;
+  action?: string;
+  created_at?: string;
+  folder_id?: number | null;
+  id?: number;
+  is_enabled?: boolean;
+  last_run_at?: string | null;
+  name?: string;
+  newsletters?: boolean;
+  older_than_days?: number;
+  unread_only?: boolean;
+  updated_at?: string;
+}
+
+export interface RetentionPolicyRequest {
+  account_id: number;
+  action: "delete" | "archive";
+  folder_id?: number | null;
+  is_enabled?: boolean | null;
+  name: string;
+  newsletters?: boolean;
+  older_than_days: number;
+  unread_only?: boolean;
+}
+
+export interface RetentionPreview {
+  action?: string;
+  cutoff?: string;
+  matched?: number;
+  matched_bytes?: number;
+  policy_id?: number;
+  samples?: StorageEmailSummary[];
+}
+
+export interface RetentionRun {
+  failed?: number;
+  finished_at?: string | null;
+  freed_bytes?: number;
+  id?: number;
+  last_error?: string;
+  matched?: number;
+  policy_id?: number;
+  started_at?: string;
+  status?: string;
+  succeeded?: number;
+  triggered_by?: string;
+}
+
+export interface SaveDraftRequest {
+  account_id: number;
+  attachment_ids?: number[];
+  attachments?: EmailAttachment[];
+  bcc?: EmailAddress[];
+  cc?: EmailAddress[];
+  code_highlight?: boolean | null;
+  disable_text_alternative?: boolean;
+  envelope_from?: string;
+  from: EmailAddress;
+  headers?: Record<string, string>;
+  html_body?: string;
+  importance?: string;
+  inline_attachments?: InlineAttachment[];
+  markdown_body?: string;
+  priority?: string;
+  reply_to?: EmailAddress;
+  request_delivery_receipt?: boolean;
+  request_read_receipt?: boolean;
+  scheduled_time?: string | null;
+  subject: string;
+  template_data?: Record<string, unknown>;
+  template_id?: number | null;
+  text_body?: string;
+  to: EmailAddress[];
+}
+
+export interface SecurityEvent {
+  country_code?: string;
+  created_at?: string;
+  event_type?: string;
+  id?: number;
+  ip?: string;
+  method?: string;
+  path?: string;
+  reason?: string;
+  user_agent?: string;
+  username?: string;
+}
+
+export interface SendEmailAttachment {
+  content: string;
+  content_id?: string;
+  content_type?: string;
+  disposition?: string;
+  filename: string;
+  size?: number;
+}
+
+export interface SendEmailRequest {
+  account_id: number;
+  attachment_ids?: number[];
+  attachments?: SendEmailAttachment[];
+  bcc?: EmailAddress[];
+  cc?: EmailAddress[];
+  code_highlight?: boolean | null;
+  draft_id?: number | null;
+  envelope_from?: string;
+  html_body?: string;
+  markdown_body?: string;
+  offload_large_attachments?: boolean;
+  priority?: string;
+  reply_to_id?: number | null;
+  subject: string;
+  text_body?: string;
+  to: EmailAddress[];
+}
+
+export interface SenderVolume {
+  emails?: number;
+  newest_date?: string;
+  oldest_date?: string;
+  sender?: string;
+  total_size?: number;
+  unread?: number;
+}
+
+export interface ServiceStats {
+  connections_by_user?: Record<string, number>;
+  events_by_type?: Record<string, number>;
+  events_published?: number;
+  last_event_time?: string | null;
+  start_time?: string;
+  total_connections?: number;
+}
+
+export interface SetEmailFlagRequest {
+  color?: string | null;
+  completed?: boolean;
+  follow_up?: boolean | null;
+  follow_up_at?: string | null;
+}
+
+export interface Setting {
+  key?: string;
+  path?: string;
+  secret?: boolean;
+  source?: string;
+  value?: string;
+}
+
+export interface ShareMailboxRequest {
+  account_id: number;
+}
+
+export interface SharedMailbox {
+  account?: EmailAccount;
+  account_id?: number;
+  created_at?: string;
+  grants?: SharedMailboxGrant[];
+  id?: number;
+  organization_id?: number;
+  shared_by?: number;
+}
+
+export interface SharedMailboxEmail {
+  account?: EmailAccount;
+  account_id?: number;
+  assignment?: EmailAssignment;
+  attachment_count?: number;
+  attachments?: Attachment[];
+  bcc?: string;
+  cc?: string;
+  created_at?: string;
+  date?: string;
+  deleted_at?: string | null;
+  flag_color?: string;
+  folder?: Folder;
+  folder_id?: number | null;
+  follow_up_at?: string | null;
+  follow_up_completed_at?: string | null;
+  from?: string;
+  has_attachment?: boolean;
+  html_body?: string;
+  id?: number;
+  importance_bucket?: string;
+  importance_manual?: boolean;
+  importance_score?: number;
+  is_answered?: boolean;
+  is_deleted?: boolean;
+  is_draft?: boolean;
+  is_follow_up?: boolean;
+  is_forwarded?: boolean;
+  is_important?: boolean;
+  is_pinned?: boolean;
+  is_read?: boolean;
+  is_sent?: boolean;
+  is_starred?: boolean;
+  is_vip?: boolean;
+  labels?: string;
+  message_id?: string;
+  notes?: EmailNote[];
+  pinned_at?: string | null;
+  preview?: string | null;
+  priority?: string;
+  reply_later_at?: string | null;
+  reply_later_remind_at?: string | null;
+  reply_to?: string;
+  sender_avatar_hash?: string;
+  sender_initials?: string;
+  size?: number;
+  subject?: string;
+  synced_at?: string | null;
+  text_body?: string;
+  thread_id?: string;
+  to?: string;
+  trackers_removed?: number;
+  trashed_at?: string | null;
+  uid?: number;
+  updated_at?: string;
+  version?: number;
+}
+
+export interface SharedMailboxGrant {
+  created_at?: string;
+  id?: number;
+  permission?: string;
+  shared_mailbox_id?: number;
+  updated_at?: string;
+  user_id?: number;
+}
+
+export interface SharedMailboxGrantRequest {
+  permission: string;
+  user_id: number;
+}
+
+export interface SharedMailboxInfo {
+  account_id?: number;
+  account_name?: string;
+  created_at?: string;
+  email?: string;
+  grants?: SharedMailboxGrant[];
+  id?: number;
+  organization_id?: number;
+  permission?: string;
+  shared_by?: number;
+}
+
+export interface StartDuplicateScanRequest {
+  account_ids?: number[];
+  folder_ids?: number[];
+}
+
+export interface StartReparseJobRequest {
+  account_id?: number | null;
+  folder_id?: number | null;
+  since?: string | null;
+}
+
+export interface StartStorageCleanupRequest {
+  action: "delete" | "archive";
+  dry_run?: boolean;
+  folder_id?: number | null;
+  min_size?: number;
+  newsletters?: boolean;
+  older_than_days?: number;
+  sender?: string;
+  unread_only?: boolean;
+}
+
+export interface StorageAttachmentSummary {
+  content_type?: string;
+  date?: string;
+  email_id?: number;
+  filename?: string;
+  id?: number;
+  size?: number;
+  subject?: string;
+}
+
+export interface StorageCleanupJob {
+  account_id?: number;
+  action?: string;
+  dry_run?: boolean;
+  failed?: number;
+  finished_at?: string | null;
+  freed_bytes?: number;
+  id?: string;
+  last_error?: string;
+  matched_bytes?: number;
+  processed?: number;
+  started_at?: string;
+  status?: string;
+  succeeded?: number;
+  total?: number;
+}
+
+export interface StorageEmailSummary {
+  date?: string;
+  folder_id?: number | null;
+  from?: string;
+  has_attachment?: boolean;
+  id?: number;
+  is_read?: boolean;
+  size?: number;
+  subject?: string;
+}
+
+export interface StuckSend {
+  account_id?: number;
+  attempts?: number;
+  created_at?: string;
+  has_mime?: boolean;
+  id?: number;
+  last_attempt?: string | null;
+  last_error?: string;
+  next_attempt?: string | null;
+  recipients?: string[];
+  scheduled_at?: string | null;
+  send_id?: string;
+  status?: string;
+  subject?: string;
+  updated_at?: string;
+  user_id?: number;
+}
+
+export interface SyncConflict {
+  account_id?: number;
+  created_at?: string;
+  email_id?: number;
+  fields?: string[];
+  id?: number;
+  local?: SyncConflictState;
+  policy?: string;
+  remote?: SyncConflictState;
+  remote_uid?: number;
+  resolution?: string;
+  resolved_at?: string | null;
+  status?: string;
+  updated_at?: string;
+}
+
+export interface SyncConflictState {
+  folder_id?: number | null;
+  is_deleted?: boolean;
+  is_read?: boolean;
+  is_starred?: boolean;
+}
+
+export interface TemplatePreview {
+  html_body?: string;
+  inline_images?: string[];
+  missing_inline_images?: string[];
+  preview_html?: string;
+  subject?: string;
+  text_body?: string;
+}
+
+export interface TemplatePreviewRequest {
+  data?: Record<string, unknown>;
+  include_signature?: boolean | null;
+  inline_attachments?: InlineAttachment[];
+}
+
+export interface TemplateVariable {
+  default_value?: unknown;
+  description?: string;
+  name?: string;
+  required?: boolean;
+  type?: string;
+}
+
+export interface TestEventRequest {
+  data?: unknown;
+  message: string;
+  type: string;
+}
+
+export interface TestEventResult {
+  event_id?: string;
+  message?: string;
+  type?: string;
+}
+
+export interface TrackingOptOut {
+  created_at?: string;
+  id?: number;
+  pattern?: string;
+}
+
+export interface TrackingOptOutRequest {
+  pattern: string;
+}
+
+export interface TrashEmailsRequest {
+  email_ids: number[];
+}
+
+export interface TrashOperationResponse {
+  affected?: number;
+}
+
+export interface UpdateBlockedSenderRequest {
+  action: string;
+}
+
+export interface UpdateDraftRequest {
+  attachment_ids?: number[] | null;
+  bcc?: EmailAddress[] | null;
+  cc?: EmailAddress[] | null;
+  html_body?: string | null;
+  markdown_body?: string | null;
+  priority?: string | null;
+  subject?: string | null;
+  text_body?: string | null;
+  to?: EmailAddress[] | null;
+}
+
+export interface UpdateEmailAccountRequest {
+  avatar_emoji?: string | null;
+  avatar_initials?: string | null;
+  bounce_address?: string | null;
+  color?: string | null;
+  display_order?: number | null;
+  dns_resolver?: string | null;
+  ehlo_name?: string | null;
+  group_id?: number | null;
+  imap_host?: string | null;
+  imap_port?: number | null;
+  imap_security?: string | null;
+  is_active?: boolean | null;
+  mailer?: string | null;
+  max_part_size?: number | null;
+  message_id_domain?: string | null;
+  name?: string | null;
+  nickname?: string | null;
+  notifications_muted?: boolean | null;
+  password?: string | null;
+  send_aliases?: string[] | null;
+  smtp_host?: string | null;
+  smtp_port?: number | null;
+  smtp_security?: string | null;
+  tls_ca_cert?: string | null;
+  tls_insecure_skip_verify?: boolean | null;
+  tls_min_version?: string | null;
+  tls_pinned_keys?: string[] | null;
+  version?: number | null;
+}
+
+export interface UpdateEmailGroupRequest {
+  name?: string | null;
+}
+
+export interface UpdateEmailNoteRequest {
+  content?: string | null;
+  sync_to_server?: boolean | null;
+}
+
+export interface UpdateEmailRequest {
+  folder_id?: number | null;
+  is_important?: boolean | null;
+  is_read?: boolean | null;
+  is_starred?: boolean | null;
+  version?: number | null;
+}
+
+export interface UpdateEmailShareRequest {
+  allow_attachments?: boolean | null;
+}
+
+export interface UpdateEmailTemplateRequest {
+  category?: string | null;
+  description?: string | null;
+  html_body?: string | null;
+  is_active?: boolean | null;
+  is_shared?: boolean | null;
+  name?: string | null;
+  subject?: string | null;
+  tags?: string[];
+  text_body?: string | null;
+  variables?: TemplateVariable[];
+}
+
+export interface UpdateFolderRequest {
+  display_name?: string | null;
+  name?: string | null;
+  parent_id?: number | null;
+  type?: string | null;
+}
+
+export interface UpdateGmailLabelMappingsRequest {
+  mappings: GmailLabelModeUpdate[];
+}
+
+export interface UpdateIngestEndpointRequest {
+  address?: string | null;
+  is_enabled?: boolean | null;
+  name?: string | null;
+}
+
+export interface UpdateLabelAppearanceRequest {
+  color?: string | null;
+  icon?: string | null;
+  name: string;
+  sort_order?: number | null;
+}
+
+export interface UpdateNotificationRequest {
+  is_read: boolean | null;
+}
+
+export interface UpdateOrganizationMemberRequest {
+  role: string;
+}
+
+export interface UpdateUserSettingsRequest {
+  block_remote_images?: boolean | null;
+  emails_per_page?: number | null;
+  locale?: string | null;
+  notifications_muted?: boolean | null;
+  reply_all?: boolean | null;
+  signature?: string | null;
+  strip_trackers?: boolean | null;
+  sync_conflict_policy?: string | null;
+  timezone?: string | null;
+}
+
+export interface User {
+  created_at?: string;
+  deleted_at?: string | null;
+  display_name?: string;
+  email?: string;
+  email_accounts?: EmailAccount[];
+  id?: number;
+  is_active?: boolean;
+  last_login_at?: string | null;
+  login_count?: number;
+  role?: string;
+  updated_at?: string;
+  username?: string;
+}
+
+export interface UserSettings {
+  block_remote_images?: boolean;
+  emails_per_page?: number;
+  locale?: string;
+  notifications_muted?: boolean;
+  reply_all?: boolean;
+  signature?: string;
+  strip_trackers?: boolean;
+  sync_conflict_policy?: string;
+  timezone?: string;
+}
+
+export interface VIPSender {
+  address?: string;
+  created_at?: string;
+  id?: number;
+  name?: string;
+}
+
+export interface VerificationCode {
+  account_id?: number;
+  code?: string;
+  date?: string;
+  email_id?: number;
+  folder_id?: number | null;
+  from?: string;
+  subject?: string;
+}
+
+export interface GetMailboxStorageReportQuery {
+  limit?: number;
+}
+
+export interface GetAuthFailuresQuery {
+  /** 统计窗口，如 30m、24h，默认使用 AUTH_FAILURE_BAN_WINDOW */
+  window?: string;
+}
+
+export interface ListJobsQuery {
+  /** 任务状态 pending/running/succeeded/dead/cancelled，为空时返回全部 */
+  status?: string;
+  /** 任务类型，如 sync.account、send.deliver */
+  type?: string;
+  /** 返回条数，默认100，最多1000 */
+  limit?: number;
+}
+
+export interface GetSecurityEventsQuery {
+  /** 事件类型，如 ip_denied、auth_failed，为空时返回全部 */
+  type?: string;
+  /** 来源IP */
+  ip?: string;
+  /** 起始时间，RFC3339格式 */
+  since?: string;
+  /** 返回条数，默认100，最多1000 */
+  limit?: number;
+}
+
+export interface GetStuckSendsQuery {
+  /** 卡住判定时长，如 15m、2h，默认15m */
+  older_than?: string;
+}
+
+export interface GetBusiestHoursQuery {
+  account_id?: number | null;
+  from?: string | null;
+  to?: string | null;
+  interval?: string;
+  tz?: string;
+  limit?: number;
+}
+
+export interface GetOutboundAccountsQuery {
+  account_id?: number | null;
+  from?: string | null;
+  to?: string | null;
+  interval?: string;
+  tz?: string;
+  limit?: number;
+}
+
+export interface GetOutboundContactsQuery {
+  account_id?: number | null;
+  from?: string | null;
+  to?: string | null;
+  interval?: string;
+  tz?: string;
+  limit?: number;
+}
+
+export interface GetOutboundMessagesQuery {
+  /** 只返回未收到回复的邮件 */
+  unreplied?: boolean;
+  account_id?: number | null;
+  from?: string | null;
+  to?: string | null;
+  interval?: string;
+  tz?: string;
+  limit?: number;
+}
+
+export interface GetResponseTimesQuery {
+  account_id?: number | null;
+  from?: string | null;
+  to?: string | null;
+  interval?: string;
+  tz?: string;
+  limit?: number;
+}
+
+export interface GetTopRecipientsQuery {
+  account_id?: number | null;
+  from?: string | null;
+  to?: string | null;
+  interval?: string;
+  tz?: string;
+  limit?: number;
+}
+
+export interface GetTopSendersQuery {
+  account_id?: number | null;
+  from?: string | null;
+  to?: string | null;
+  interval?: string;
+  tz?: string;
+  limit?: number;
+}
+
+export interface GetEmailVolumeQuery {
+  account_id?: number | null;
+  from?: string | null;
+  to?: string | null;
+  interval?: string;
+  tz?: string;
+  limit?: number;
+}
+
+export interface ListAttachmentsQuery {
+  account_id?: number | null;
+  type?: string;
+  since?: string | null;
+  until?: string | null;
+  min_size?: number;
+  max_size?: number;
+  filename?: string;
+  include_inline?: boolean;
+  page?: number;
+  page_size?: number;
+}
+
+export interface DownloadAttachmentWithTokenQuery {
+  /** 附件下载令牌 */
+  token?: string;
+}
+
+export interface GetMailMergeRecipientsQuery {
+  status?: string;
+  page?: number;
+  page_size?: number;
+}
+
+export interface GetCannedQuickListQuery {
+  q?: string;
+  folder_id?: number;
+  limit?: number;
+}
+
+export interface GetChangesQuery {
+  /** 上次获取的变更令牌 */
+  since?: number;
+  /** 最多返回的变更数 */
+  limit?: number;
+}
+
+export interface GetEmailsQuery {
+  /** 按账户过滤 */
+  account_id?: number;
+  /** 按文件夹过滤 */
+  folder_id?: number;
+  /** 按已读状态过滤 */
+  is_read?: boolean;
+  /** 按星标过滤 */
+  is_starred?: boolean;
+  /** 按重要标记过滤 */
+  is_important?: boolean;
+  /** 按优先收件箱分类过滤：important 或 other */
+  importance_bucket?: string;
+  /** 只看或排除VIP发件人的邮件 */
+  is_vip?: boolean;
+  /** 按旗标颜色过滤：red、orange、yellow、green、blue、purple 或 gray */
+  flag_color?: string;
+  /** 按后续跟进旗标过滤 */
+  is_follow_up?: boolean;
+  /** 只返回跟进截止时间不晚于该时间的邮件（RFC3339） */
+  follow_up_due?: string;
+  /** 按已回复状态过滤 */
+  is_answered?: boolean;
+  /** 只返回等待我回复的邮件：尚未回复，且会话中之后没有已发送的邮件 */
+  awaiting_reply?: boolean;
+  /** 页码，从1开始 */
+  page?: number;
+  /** 每页数量，1-100 */
+  page_size?: number;
+  /** 排序字段，默认date */
+  sort_by?: string;
+  /** asc 或 desc，默认desc */
+  sort_order?: string;
+  /** 关键词过滤 */
+  search?: string;
+  /** 上一页返回的next_cursor，提供时忽略page */
+  cursor?: string;
+  /** 置顶邮件排在最前，不能与cursor同时使用 */
+  pinned_first?: boolean;
+  /** full（默认）返回不含正文的完整邮件；summary 只在 summaries 中返回预览、标记和头像等列表摘要 */
+  view?: string;
+  /** date 按用户时区的今天、昨天、近一周、近一月和更早分组，要求按日期排序；sender 按发件人分组，不能与cursor同时使用 */
+  group_by?: string;
+}
+
+export interface ListDraftsQuery {
+  account_id?: number | null;
+  page?: number;
+  page_size?: number;
+  sort_by?: string;
+  sort_order?: string;
+}
+
+export interface GetReplyLaterEmailsQuery {
+  /** 按账户过滤 */
+  account_id?: number;
+  /** 页码，从1开始 */
+  page?: number;
+  /** 每页数量，1-100 */
+  page_size?: number;
+}
+
+export interface SearchEmailsQuery {
+  /** 搜索关键词 */
+  q?: string;
+  /** 主题包含 */
+  subject?: string;
+  /** 发件人包含 */
+  from?: string;
+  /** 收件人包含 */
+  to?: string;
+  /** 正文包含 */
+  body?: string;
+  /** 私有笔记包含 */
+  note?: string;
+  /** 按账户过滤 */
+  account_id?: number;
+  /** 按文件夹过滤 */
+  folder_id?: number;
+  /** 是否有附件 */
+  has_attachment?: boolean;
+  /** 按已读状态过滤 */
+  is_read?: boolean;
+  /** 按星标过滤 */
+  is_starred?: boolean;
+  /** 起始时间（RFC3339） */
+  since?: string;
+  /** 截止时间（RFC3339） */
+  before?: string;
+  /** keyword（默认）或 semantic（按含义匹配，需配置向量模型，未配置时返回503，不支持游标） */
+  mode?: string;
+  /** 页码，从1开始 */
+  page?: number;
+  /** 每页数量，1-100 */
+  page_size?: number;
+  /** 上一页返回的next_cursor，提供时忽略page */
+  cursor?: string;
+}
+
+export interface GetSyncConflictsQuery {
+  /** pending 或 resolved，为空时返回全部 */
+  status?: string;
+}
+
+export interface ListTemplatesQuery {
+  category?: string;
+  tag?: string;
+  is_active?: boolean | null;
+  is_shared?: boolean | null;
+  include_built_in?: boolean;
+  scope?: string;
+  search?: string;
+  page?: number;
+  page_size?: number;
+  sort_by?: string;
+  sort_order?: string;
+}
+
+export interface GetVerificationCodesQuery {
+  /** 按账户过滤 */
+  account_id?: number;
+  /** 查询最近多少分钟的邮件，默认30，最大1440 */
+  minutes?: number;
+}
+
+export interface GetEmailNavigationQuery {
+  /** 按账户过滤 */
+  account_id?: number;
+  /** 按文件夹过滤 */
+  folder_id?: number;
+  /** 按已读状态过滤 */
+  is_read?: boolean;
+  /** 按星标过滤 */
+  is_starred?: boolean;
+  /** 按重要标记过滤 */
+  is_important?: boolean;
+  /** 按优先收件箱分类过滤：important 或 other */
+  importance_bucket?: string;
+  /** 只看或排除VIP发件人的邮件 */
+  is_vip?: boolean;
+  /** 按旗标颜色过滤：red、orange、yellow、green、blue、purple 或 gray */
+  flag_color?: string;
+  /** 按后续跟进旗标过滤 */
+  is_follow_up?: boolean;
+  /** 只返回跟进截止时间不晚于该时间的邮件（RFC3339） */
+  follow_up_due?: string;
+  /** 按已回复状态过滤 */
+  is_answered?: boolean;
+  /** 只返回等待我回复的邮件：尚未回复，且会话中之后没有已发送的邮件 */
+  awaiting_reply?: boolean;
+  /** 排序字段，默认date */
+  sort_by?: string;
+  /** asc 或 desc，默认desc */
+  sort_order?: string;
+  /** 关键词过滤 */
+  search?: string;
+  /** 置顶邮件排在最前 */
+  pinned_first?: boolean;
+  /** 与列表的分组方式一致，sender 时先按发件人排序 */
+  group_by?: string;
+}
+
+export interface ExportEmailPDFQuery {
+  paper?: string;
+  bundle_attachments?: boolean;
+}
+
+export interface PollEventsQuery {
+  /** 上次返回的游标，为空时从当前位置开始等待 */
+  since?: number;
+  /** 没有事件时最多等待的秒数，默认25，最大60 */
+  timeout?: number;
+  /** 逗号分隔的事件类别或事件类型，为空时接收全部事件 */
+  events?: string;
+  /** 逗号分隔的账户ID，只接收这些账户的事件 */
+  account_ids?: string;
+}
+
+export interface GetFoldersQuery {
+  /** 账户ID */
+  account_id: number;
+}
+
+export interface CreateFolderQuery {
+  /** 账户ID */
+  account_id: number;
+}
+
+export interface GetLabelsQuery {
+  /** 账户ID */
+  account_id: number;
+}
+
+export interface UpdateLabelAppearanceQuery {
+  /** 账户ID */
+  account_id: number;
+}
+
+export interface IngestEmailQuery {
+  /** 入站令牌，无法设置请求头时使用 */
+  token?: string;
+}
+
+export interface PlanMailboxMigrationQuery {
+  source_account_id: number;
+  target_account_id: number;
+}
+
+export interface GetNotificationsQuery {
+  unread_only?: boolean;
+  level?: string;
+  account_id?: number | null;
+  page?: number;
+  page_size?: number;
+}
+
+export interface InitGmailOAuthQuery {
+  /** 授权完成后的前端回调地址 */
+  callback_url?: string;
+}
+
+export interface InitOutlookOAuthQuery {
+  /** 授权完成后的前端回调地址 */
+  callback_url?: string;
+}
+
+export interface HandleOAuth2CallbackQuery {
+  /** 授权码 */
+  code?: string;
+  /** 授权状态 */
+  state?: string;
+  /** 提供商返回的错误 */
+  error?: string;
+  /** 错误描述 */
+  error_description?: string;
+}
+
+export interface DetectProviderQuery {
+  /** 邮箱地址 */
+  email: string;
+}
+
+export interface GetRetentionRunsQuery {
+  policy_id?: number | null;
+  limit?: number;
+}
+
+export interface GetSharedMailboxEmailsQuery {
+  folder_id?: number;
+  assignment?: string;
+  page?: number;
+  page_size?: number;
+}
+
+export interface HandleSSEQuery {
+  /** 访问令牌，EventSource无法设置请求头时使用 */
+  token?: string;
+  /** 客户端标识 */
+  client_id?: string;
+  /** 逗号分隔的事件类别（new_email、email、sync、send、account、migration、notification）或事件类型，为空时接收全部事件 */
+  events?: string;
+  /** 逗号分隔的账户ID，只接收这些账户的事件；不属于具体账户的事件不受影响 */
+  account_ids?: string;
+  /** 重连时传入最后收到事件的 seq，补发缓冲区中之后的事件 */
+  since?: number;
+}
+
+export interface HandleSSEEventsQuery {
+  /** 访问令牌，EventSource无法设置请求头时使用 */
+  token?: string;
+  /** 客户端标识 */
+  client_id?: string;
+  /** 逗号分隔的事件类别（new_email、email、sync、send、account、migration、notification）或事件类型，为空时接收全部事件 */
+  events?: string;
+  /** 逗号分隔的账户ID，只接收这些账户的事件；不属于具体账户的事件不受影响 */
+  account_ids?: string;
+  /** 重连时传入最后收到事件的 seq，补发缓冲区中之后的事件 */
+  since?: number;
+}
+
+export interface GetTrashQuery {
+  /** 按账户过滤 */
+  account_id?: number;
+  /** 页码，从1开始 */
+  page?: number;
+  /** 每页数量，1-100 */
+  page_size?: number;
+}
+
+export interface EmptyTrashQuery {
+  /** 只清空该账户 */
+  account_id?: number;
+}
+
+export type QueryValue = string | number | boolean | null | undefined | Array<string | number | boolean>;
+
+export interface FireMailClientOptions {
+  baseUrl: string;
+  token?: string | (() => string | null | undefined);
+  fetch?: typeof fetch;
+}
+
+export class FireMailApiError extends Error {
+  constructor(
+    public readonly status: number,
+    public readonly response: ErrorResponse
+  ) {
+    super(response.message || response.error);
+    this.name = 'FireMailApiError';
+  }
+}
+
+export class FireMailClient {
+  constructor(private readonly options: FireMailClientOptions) {}
+
+  /** 执行GraphQL查询 */
+  graphQL(body: Request): Promise<Response> {
+    return this.raw("POST", `/api/graphql`, undefined, body);
+  }
+
+  /** 获取GraphQL类型定义（SDL） */
+  getGraphQLSchema(): Promise<Response> {
+    return this.raw("GET", `/api/graphql/schema`, undefined);
+  }
+
+  /** 获取邮件账户列表 */
+  getEmailAccounts(): Promise<EmailAccount[]> {
+    return this.request<EmailAccount[]>("GET", `/api/v1/accounts`, undefined);
+  }
+
+  /** 创建邮件账户；连接失败且需要先在邮箱网页版完成设置（如QQ邮箱授权码）时返回setup_guide分步说明 */
+  createEmailAccount(body: CreateEmailAccountRequest): Promise<EmailAccount> {
+    return this.request<EmailAccount>("POST", `/api/v1/accounts`, undefined, body);
+  }
+
+  /** 批量删除账户 */
+  batchDeleteEmailAccounts(body: BatchAccountRequest): Promise<void> {
+    return this.request<void>("POST", `/api/v1/accounts/batch/delete`, undefined, body);
+  }
+
+  /** 批量标记账户为已读 */
+  batchMarkAccountsAsRead(body: BatchAccountRequest): Promise<void> {
+    return this.request<void>("POST", `/api/v1/accounts/batch/mark-read`, undefined, body);
+  }
+
+  /** 批量同步账户 */
+  batchSyncEmailAccounts(body: BatchAccountRequest): Promise<void> {
+    return this.request<void>("POST", `/api/v1/accounts/batch/sync`, undefined, body);
+  }
+
+  /** 创建自定义服务器邮件账户 */
+  createCustomEmailAccount(body: CreateEmailAccountRequest): Promise<EmailAccount> {
+    return this.request<EmailAccount>("POST", `/api/v1/accounts/custom`, undefined, body);
+  }
+
+  /** 调整账户显示顺序，未列出的账户排在后面 */
+  reorderEmailAccounts(body: ReorderEmailAccountsRequest): Promise<EmailAccount[]> {
+    return this.request<EmailAccount[]>("PUT", `/api/v1/accounts/reorder`, undefined, body);
+  }
+
+  /** 获取邮件账户 */
+  getEmailAccount(id: number): Promise<EmailAccount> {
+    return this.request<EmailAccount>("GET", `/api/v1/accounts/${encodeURIComponent(String(id))}`, undefined);
+  }
+
+  /** 更新邮件账户 */
+  updateEmailAccount(id: number, body: UpdateEmailAccountRequest): Promise<EmailAccount> {
+    return this.request<EmailAccount>("PUT", `/api/v1/accounts/${encodeURIComponent(String(id))}`, undefined, body);
+  }
+
+  /** 删除邮件账户 */
+  deleteEmailAccount(id: number): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/accounts/${encodeURIComponent(String(id))}`, undefined);
+  }
+
+  /** 获取Gmail标签映射方式 */
+  getGmailLabelMappings(id: number): Promise<GmailLabelMapping[]> {
+    return this.request<GmailLabelMapping[]>("GET", `/api/v1/accounts/${encodeURIComponent(String(id))}/gmail-labels`, undefined);
+  }
+
+  /** 修改Gmail标签映射方式（作为文件夹、合并或跳过） */
+  updateGmailLabelMappings(id: number, body: UpdateGmailLabelMappingsRequest): Promise<GmailLabelMapping[]> {
+    return this.request<GmailLabelMapping[]>("PUT", `/api/v1/accounts/${encodeURIComponent(String(id))}/gmail-labels`, undefined, body);
+  }
+
+  /** 将账户邮件标记为已读 */
+  markAccountAsRead(id: number): Promise<void> {
+    return this.request<void>("PUT", `/api/v1/accounts/${encodeURIComponent(String(id))}/mark-read`, undefined);
+  }
+
+  /** 暂停账户同步，等待进行中的同步退出 */
+  pauseAccountSync(id: number): Promise<EmailAccount> {
+    return this.request<EmailAccount>("POST", `/api/v1/accounts/${encodeURIComponent(String(id))}/pause`, undefined);
+  }
+
+  /** 恢复账户同步 */
+  resumeAccountSync(id: number): Promise<EmailAccount> {
+    return this.request<EmailAccount>("POST", `/api/v1/accounts/${encodeURIComponent(String(id))}/resume`, undefined);
+  }
+
+  /** 按条件批量清理邮件，返回202和后台任务 */
+  startStorageCleanup(id: number, body: StartStorageCleanupRequest): Promise<StorageCleanupJob> {
+    return this.request<StorageCleanupJob>("POST", `/api/v1/accounts/${encodeURIComponent(String(id))}/storage/cleanup`, undefined, body);
+  }
+
+  /** 获取邮箱清理任务状态 */
+  getStorageCleanup(id: number, jobID: string): Promise<StorageCleanupJob> {
+    return this.request<StorageCleanupJob>("GET", `/api/v1/accounts/${encodeURIComponent(String(id))}/storage/cleanup/${encodeURIComponent(String(jobID))}`, undefined);
+  }
+
+  /** 取消邮箱清理任务 */
+  cancelStorageCleanup(id: number, jobID: string): Promise<StorageCleanupJob> {
+    return this.request<StorageCleanupJob>("POST", `/api/v1/accounts/${encodeURIComponent(String(id))}/storage/cleanup/${encodeURIComponent(String(jobID))}/cancel`, undefined);
+  }
+
+  /** 获取最大邮件和附件、最早未读订阅邮件及发件人占用统计 */
+  getMailboxStorageReport(id: number, query?: GetMailboxStorageReportQuery): Promise<MailboxStorageReport> {
+    return this.request<MailboxStorageReport>("GET", `/api/v1/accounts/${encodeURIComponent(String(id))}/storage/top`, query);
+  }
+
+  /** 同步账户邮件 */
+  syncEmailAccount(id: number): Promise<void> {
+    return this.request<void>("POST", `/api/v1/accounts/${encodeURIComponent(String(id))}/sync`, undefined);
+  }
+
+  /** 测试账户连接，需要先完成邮箱设置时错误响应附带setup_guide */
+  testEmailAccount(id: number): Promise<void> {
+    return this.request<void>("POST", `/api/v1/accounts/${encodeURIComponent(String(id))}/test`, undefined);
+  }
+
+  /** 按IP汇总最近的登录失败并给出封禁建议 */
+  getAuthFailures(query?: GetAuthFailuresQuery): Promise<AuthFailuresResponse> {
+    return this.request<AuthFailuresResponse>("GET", `/api/v1/admin/auth-failures`, query);
+  }
+
+  /** 查看生效配置（已脱敏） */
+  getConfig(): Promise<ConfigView> {
+    return this.request<ConfigView>("GET", `/api/v1/admin/config`, undefined);
+  }
+
+  /** 列出后台任务及各状态的任务数 */
+  listJobs(query?: ListJobsQuery): Promise<JobList> {
+    return this.request<JobList>("GET", `/api/v1/admin/jobs`, query);
+  }
+
+  /** 获取后台任务详情 */
+  getJob(id: number): Promise<Job> {
+    return this.request<Job>("GET", `/api/v1/admin/jobs/${encodeURIComponent(String(id))}`, undefined);
+  }
+
+  /** 取消等待执行的任务 */
+  cancelJob(id: number): Promise<Job> {
+    return this.request<Job>("POST", `/api/v1/admin/jobs/${encodeURIComponent(String(id))}/cancel`, undefined);
+  }
+
+  /** 重新执行死信或已取消的任务 */
+  retryJob(id: number): Promise<Job> {
+    return this.request<Job>("POST", `/api/v1/admin/jobs/${encodeURIComponent(String(id))}/retry`, undefined);
+  }
+
+  /** 启动批量重新解析任务 */
+  startReparseJob(body: StartReparseJobRequest): Promise<ReparseJob> {
+    return this.request<ReparseJob>("POST", `/api/v1/admin/reparse`, undefined, body);
+  }
+
+  /** 获取重新解析任务状态 */
+  getReparseJob(jobID: string): Promise<ReparseJob> {
+    return this.request<ReparseJob>("GET", `/api/v1/admin/reparse/${encodeURIComponent(String(jobID))}`, undefined);
+  }
+
+  /** 取消重新解析任务 */
+  cancelReparseJob(jobID: string): Promise<ReparseJob> {
+    return this.request<ReparseJob>("POST", `/api/v1/admin/reparse/${encodeURIComponent(String(jobID))}/cancel`, undefined);
+  }
+
+  /** 查看安全事件审计日志 */
+  getSecurityEvents(query?: GetSecurityEventsQuery): Promise<SecurityEvent[]> {
+    return this.request<SecurityEvent[]>("GET", `/api/v1/admin/security-events`, query);
+  }
+
+  /** 查看发送队列中超过阈值仍未发出的邮件 */
+  getStuckSends(query?: GetStuckSendsQuery): Promise<StuckSend[]> {
+    return this.request<StuckSend[]>("GET", `/api/v1/admin/send-queue/stuck`, query);
+  }
+
+  /** 按小时和星期统计收发邮件数 */
+  getBusiestHours(query?: GetBusiestHoursQuery): Promise<AnalyticsBusiestHours> {
+    return this.request<AnalyticsBusiestHours>("GET", `/api/v1/analytics/busiest-hours`, query);
+  }
+
+  /** 按账户统计发出邮件的回复率和回复用时 */
+  getOutboundAccounts(query?: GetOutboundAccountsQuery): Promise<AnalyticsOutboundAccount[]> {
+    return this.request<AnalyticsOutboundAccount[]>("GET", `/api/v1/analytics/outbound/accounts`, query);
+  }
+
+  /** 按联系人统计发出邮件的回复率和回复用时 */
+  getOutboundContacts(query?: GetOutboundContactsQuery): Promise<AnalyticsOutboundContact[]> {
+    return this.request<AnalyticsOutboundContact[]>("GET", `/api/v1/analytics/outbound/contacts`, query);
+  }
+
+  /** 发出的邮件及其回复情况 */
+  getOutboundMessages(query?: GetOutboundMessagesQuery): Promise<AnalyticsOutboundMessage[]> {
+    return this.request<AnalyticsOutboundMessage[]>("GET", `/api/v1/analytics/outbound/messages`, query);
+  }
+
+  /** 根据现有邮件重建收发统计 */
+  rebuildAnalytics(body: RebuildVolumeStatsRequest): Promise<void> {
+    return this.request<void>("POST", `/api/v1/analytics/rebuild`, undefined, body);
+  }
+
+  /** 回复邮件所用时间统计 */
+  getResponseTimes(query?: GetResponseTimesQuery): Promise<AnalyticsResponseTimes> {
+    return this.request<AnalyticsResponseTimes>("GET", `/api/v1/analytics/response-times`, query);
+  }
+
+  /** 发信最多的收件人 */
+  getTopRecipients(query?: GetTopRecipientsQuery): Promise<AnalyticsRecipient[]> {
+    return this.request<AnalyticsRecipient[]>("GET", `/api/v1/analytics/top-recipients`, query);
+  }
+
+  /** 来信最多的发件人 */
+  getTopSenders(query?: GetTopSendersQuery): Promise<SenderVolume[]> {
+    return this.request<SenderVolume[]>("GET", `/api/v1/analytics/top-senders`, query);
+  }
+
+  /** 按时间段统计收发邮件数 */
+  getEmailVolume(query?: GetEmailVolumeQuery): Promise<AnalyticsVolumePoint[]> {
+    return this.request<AnalyticsVolumePoint[]>("GET", `/api/v1/analytics/volume`, query);
+  }
+
+  /** 通过邮件正文中的下载链接下载附件 */
+  downloadAttachmentLink(token: string): Promise<Response> {
+    return this.raw("GET", `/api/v1/attachment-links/${encodeURIComponent(String(token))}`, undefined);
+  }
+
+  /** 跨邮件列出附件，可按账户、类型、时间和大小筛选 */
+  listAttachments(query?: ListAttachmentsQuery): Promise<AttachmentListResponse> {
+    return this.request<AttachmentListResponse>("GET", `/api/v1/attachments`, query);
+  }
+
+  /** 将选中的附件打包为ZIP下载 */
+  downloadAttachmentsZip(body: DownloadAttachmentsZipRequest): Promise<Response> {
+    return this.raw("POST", `/api/v1/attachments/download-zip`, undefined, body);
+  }
+
+  /** 上传附件 */
+  uploadAttachment(form: {
+    file: Blob;
+  }): Promise<AttachmentUploadResult> {
+    return this.request<AttachmentUploadResult>("POST", `/api/v1/attachments/upload`, undefined, undefined, form);
+  }
+
+  /** 下载附件 */
+  downloadAttachment(id: number): Promise<Response> {
+    return this.raw("GET", `/api/v1/attachments/${encodeURIComponent(String(id))}/download`, undefined);
+  }
+
+  /** 从服务器重新下载附件 */
+  forceDownloadAttachment(id: number): Promise<void> {
+    return this.request<void>("POST", `/api/v1/attachments/${encodeURIComponent(String(id))}/download`, undefined);
+  }
+
+  /** 通过签名链接下载附件，无需登录令牌 */
+  downloadAttachmentWithToken(id: number, query?: DownloadAttachmentWithTokenQuery): Promise<Response> {
+    return this.raw("GET", `/api/v1/attachments/${encodeURIComponent(String(id))}/file`, query);
+  }
+
+  /** 预览附件 */
+  previewAttachment(id: number): Promise<AttachmentPreview> {
+    return this.request<AttachmentPreview>("GET", `/api/v1/attachments/${encodeURIComponent(String(id))}/preview`, undefined);
+  }
+
+  /** 获取附件下载进度 */
+  getDownloadProgress(id: number): Promise<DownloadProgress> {
+    return this.request<DownloadProgress>("GET", `/api/v1/attachments/${encodeURIComponent(String(id))}/progress`, undefined);
+  }
+
+  /** 签发单个附件的短期下载链接 */
+  createAttachmentToken(id: number): Promise<AttachmentToken> {
+    return this.request<AttachmentToken>("POST", `/api/v1/attachments/${encodeURIComponent(String(id))}/token`, undefined);
+  }
+
+  /** 用户登录 */
+  login(body: LoginRequest): Promise<LoginResponse> {
+    return this.request<LoginResponse>("POST", `/api/v1/auth/login`, undefined, body);
+  }
+
+  /** 用户登出 */
+  logout(): Promise<void> {
+    return this.request<void>("POST", `/api/v1/auth/logout`, undefined);
+  }
+
+  /** 获取当前用户信息 */
+  getCurrentUser(): Promise<User> {
+    return this.request<User>("GET", `/api/v1/auth/me`, undefined);
+  }
+
+  /** 获取屏蔽发件人列表 */
+  getBlockedSenders(): Promise<BlockedSender[]> {
+    return this.request<BlockedSender[]>("GET", `/api/v1/blocked-senders`, undefined);
+  }
+
+  /** 屏蔽邮箱地址或域名，之后同步到的邮件自动移入回收站或垃圾邮件 */
+  addBlockedSender(body: BlockedSenderRequest): Promise<BlockedSender> {
+    return this.request<BlockedSender>("POST", `/api/v1/blocked-senders`, undefined, body);
+  }
+
+  /** 导出屏蔽发件人列表 */
+  exportBlockedSenders(): Promise<BlockedSenderExport> {
+    return this.request<BlockedSenderExport>("GET", `/api/v1/blocked-senders/export`, undefined);
+  }
+
+  /** 导入屏蔽发件人列表 */
+  importBlockedSenders(body: ImportBlockedSendersRequest): Promise<ImportBlockedSendersResult> {
+    return this.request<ImportBlockedSendersResult>("POST", `/api/v1/blocked-senders/import`, undefined, body);
+  }
+
+  /** 修改屏蔽发件人的处理方式 */
+  updateBlockedSender(id: number, body: UpdateBlockedSenderRequest): Promise<BlockedSender> {
+    return this.request<BlockedSender>("PUT", `/api/v1/blocked-senders/${encodeURIComponent(String(id))}`, undefined, body);
+  }
+
+  /** 取消屏蔽发件人 */
+  deleteBlockedSender(id: number): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/blocked-senders/${encodeURIComponent(String(id))}`, undefined);
+  }
+
+  /** 获取邮件合并活动列表 */
+  getMailMergeCampaigns(): Promise<MailMergeCampaign[]> {
+    return this.request<MailMergeCampaign[]>("GET", `/api/v1/campaigns`, undefined);
+  }
+
+  /** 上传CSV收件人创建活动 */
+  createMailMergeCampaign(form: {
+    account_id: number;
+    file: Blob;
+    name: string;
+    rate_per_minute?: number;
+    template_id: number;
+    track_clicks?: boolean;
+    track_opens?: boolean;
+    use_verp?: boolean;
+  }): Promise<MailMergeCampaign> {
+    return this.request<MailMergeCampaign>("POST", `/api/v1/campaigns`, undefined, undefined, form);
+  }
+
+  /** 获取不跟踪名单 */
+  getTrackingOptOuts(): Promise<TrackingOptOut[]> {
+    return this.request<TrackingOptOut[]>("GET", `/api/v1/campaigns/opt-outs`, undefined);
+  }
+
+  /** 添加不跟踪的地址或域名 */
+  addTrackingOptOut(body: TrackingOptOutRequest): Promise<TrackingOptOut> {
+    return this.request<TrackingOptOut>("POST", `/api/v1/campaigns/opt-outs`, undefined, body);
+  }
+
+  /** 从不跟踪名单中移除 */
+  deleteTrackingOptOut(id: number): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/campaigns/opt-outs/${encodeURIComponent(String(id))}`, undefined);
+  }
+
+  /** 获取邮件合并活动 */
+  getMailMergeCampaign(id: number): Promise<MailMergeCampaign> {
+    return this.request<MailMergeCampaign>("GET", `/api/v1/campaigns/${encodeURIComponent(String(id))}`, undefined);
+  }
+
+  /** 取消活动 */
+  cancelMailMergeCampaign(id: number): Promise<MailMergeCampaign> {
+    return this.request<MailMergeCampaign>("POST", `/api/v1/campaigns/${encodeURIComponent(String(id))}/cancel`, undefined);
+  }
+
+  /** 暂停发送 */
+  pauseMailMergeCampaign(id: number): Promise<MailMergeCampaign> {
+    return this.request<MailMergeCampaign>("POST", `/api/v1/campaigns/${encodeURIComponent(String(id))}/pause`, undefined);
+  }
+
+  /** 获取活动收件人 */
+  getMailMergeRecipients(id: number, query?: GetMailMergeRecipientsQuery): Promise<ListMailMergeRecipientsResponse> {
+    return this.request<ListMailMergeRecipientsResponse>("GET", `/api/v1/campaigns/${encodeURIComponent(String(id))}/recipients`, query);
+  }
+
+  /** 获取活动的打开和点击统计 */
+  getMailMergeCampaignReport(id: number): Promise<MailMergeCampaignReport> {
+    return this.request<MailMergeCampaignReport>("GET", `/api/v1/campaigns/${encodeURIComponent(String(id))}/report`, undefined);
+  }
+
+  /** 恢复发送 */
+  resumeMailMergeCampaign(id: number): Promise<MailMergeCampaign> {
+    return this.request<MailMergeCampaign>("POST", `/api/v1/campaigns/${encodeURIComponent(String(id))}/resume`, undefined);
+  }
+
+  /** 开始发送 */
+  startMailMergeCampaign(id: number): Promise<MailMergeCampaign> {
+    return this.request<MailMergeCampaign>("POST", `/api/v1/campaigns/${encodeURIComponent(String(id))}/start`, undefined);
+  }
+
+  /** 获取快捷回复列表 */
+  getCannedResponses(): Promise<CannedResponse[]> {
+    return this.request<CannedResponse[]>("GET", `/api/v1/canned-responses`, undefined);
+  }
+
+  /** 创建快捷回复 */
+  createCannedResponse(body: CannedResponseRequest): Promise<CannedResponse> {
+    return this.request<CannedResponse>("POST", `/api/v1/canned-responses`, undefined, body);
+  }
+
+  /** 撰写邮件时使用的快捷回复精简列表，关联当前文件夹的排在前面，其余按使用次数排序 */
+  getCannedQuickList(query?: GetCannedQuickListQuery): Promise<CannedQuickItem[]> {
+    return this.request<CannedQuickItem[]>("GET", `/api/v1/canned-responses/quick`, query);
+  }
+
+  /** 修改快捷回复 */
+  updateCannedResponse(id: number, body: CannedResponseRequest): Promise<CannedResponse> {
+    return this.request<CannedResponse>("PUT", `/api/v1/canned-responses/${encodeURIComponent(String(id))}`, undefined, body);
+  }
+
+  /** 删除快捷回复 */
+  deleteCannedResponse(id: number): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/canned-responses/${encodeURIComponent(String(id))}`, undefined);
+  }
+
+  /** 代入变量渲染快捷回复并计入使用次数，提供email_id时可以使用发件人和主题变量 */
+  renderCannedResponse(id: number, body: RenderCannedResponseRequest): Promise<RenderedCannedResponse> {
+    return this.request<RenderedCannedResponse>("POST", `/api/v1/canned-responses/${encodeURIComponent(String(id))}/render`, undefined, body);
+  }
+
+  /** 获取令牌之后的增量变更 */
+  getChanges(query?: GetChangesQuery): Promise<ChangesResponse> {
+    return this.request<ChangesResponse>("GET", `/api/v1/changes`, query);
+  }
+
+  /** 获取邮件列表 */
+  getEmails(query?: GetEmailsQuery): Promise<GetEmailsResponse> {
+    return this.request<GetEmailsResponse>("GET", `/api/v1/emails`, query);
+  }
+
+  /** 批量邮件操作，跨账户批量移动/复制较多邮件时返回202和后台任务 */
+  batchEmailOperations(body: BatchEmailOperation): Promise<BatchEmailResult> {
+    return this.request<BatchEmailResult>("POST", `/api/v1/emails/batch`, undefined, body);
+  }
+
+  /** 保存草稿 */
+  saveDraft(body: SaveDraftRequest): Promise<Draft> {
+    return this.request<Draft>("POST", `/api/v1/emails/draft`, undefined, body);
+  }
+
+  /** 获取草稿 */
+  getDraft(id: number): Promise<Draft> {
+    return this.request<Draft>("GET", `/api/v1/emails/draft/${encodeURIComponent(String(id))}`, undefined);
+  }
+
+  /** 更新草稿 */
+  updateDraft(id: number, body: UpdateDraftRequest): Promise<Draft> {
+    return this.request<Draft>("PUT", `/api/v1/emails/draft/${encodeURIComponent(String(id))}`, undefined, body);
+  }
+
+  /** 删除草稿 */
+  deleteDraft(id: number): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/emails/draft/${encodeURIComponent(String(id))}`, undefined);
+  }
+
+  /** 自动保存草稿 */
+  autosaveDraft(id: number, body: UpdateDraftRequest): Promise<DraftAutosaveResult> {
+    return this.request<DraftAutosaveResult>("PATCH", `/api/v1/emails/draft/${encodeURIComponent(String(id))}/autosave`, undefined, body);
+  }
+
+  /** 获取草稿修订历史 */
+  listDraftRevisions(id: number): Promise<DraftRevision[]> {
+    return this.request<DraftRevision[]>("GET", `/api/v1/emails/draft/${encodeURIComponent(String(id))}/revisions`, undefined);
+  }
+
+  /** 恢复草稿修订 */
+  restoreDraftRevision(id: number, revisionID: number): Promise<Draft> {
+    return this.request<Draft>("POST", `/api/v1/emails/draft/${encodeURIComponent(String(id))}/revisions/${encodeURIComponent(String(revisionID))}/restore`, undefined);
+  }
+
+  /** 获取草稿列表 */
+  listDrafts(query?: ListDraftsQuery): Promise<ListDraftsResponse> {
+    return this.request<ListDraftsResponse>("GET", `/api/v1/emails/drafts`, query);
+  }
+
+  /** 导入服务器Drafts文件夹中的草稿 */
+  importDrafts(body: ImportDraftsRequest): Promise<ImportDraftsResult> {
+    return this.request<ImportDraftsResult>("POST", `/api/v1/emails/drafts/import`, undefined, body);
+  }
+
+  /** 启动重复邮件扫描任务 */
+  startDuplicateScan(body: StartDuplicateScanRequest): Promise<DuplicateScanJob> {
+    return this.request<DuplicateScanJob>("POST", `/api/v1/emails/duplicates/scans`, undefined, body);
+  }
+
+  /** 获取重复邮件扫描状态及分组结果 */
+  getDuplicateScan(jobID: string): Promise<DuplicateScanJob> {
+    return this.request<DuplicateScanJob>("GET", `/api/v1/emails/duplicates/scans/${encodeURIComponent(String(jobID))}`, undefined);
+  }
+
+  /** 取消重复邮件扫描任务 */
+  cancelDuplicateScan(jobID: string): Promise<DuplicateScanJob> {
+    return this.request<DuplicateScanJob>("POST", `/api/v1/emails/duplicates/scans/${encodeURIComponent(String(jobID))}/cancel`, undefined);
+  }
+
+  /** 删除或移动重复邮件，每组保留一封 */
+  resolveDuplicates(jobID: string, body: ResolveDuplicatesRequest): Promise<ResolveDuplicatesResult> {
+    return this.request<ResolveDuplicatesResult>("POST", `/api/v1/emails/duplicates/scans/${encodeURIComponent(String(jobID))}/resolve`, undefined, body);
+  }
+
+  /** 预览Markdown正文渲染出的HTML和纯文本 */
+  previewMarkdown(body: MarkdownPreviewRequest): Promise<MarkdownPreview> {
+    return this.request<MarkdownPreview>("POST", `/api/v1/emails/markdown/preview`, undefined, body);
+  }
+
+  /** 获取已静音的会话 */
+  getMutedThreads(): Promise<MutedThread[]> {
+    return this.request<MutedThread[]>("GET", `/api/v1/emails/muted-threads`, undefined);
+  }
+
+  /** 从静音列表中移除会话 */
+  deleteMutedThread(id: number): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/emails/muted-threads/${encodeURIComponent(String(id))}`, undefined);
+  }
+
+  /** 获取稍后回复列表，先加入的在前 */
+  getReplyLaterEmails(query?: GetReplyLaterEmailsQuery): Promise<GetEmailsResponse> {
+    return this.request<GetEmailsResponse>("GET", `/api/v1/emails/reply-later`, query);
+  }
+
+  /** 搜索邮件 */
+  searchEmails(query?: SearchEmailsQuery): Promise<GetEmailsResponse> {
+    return this.request<GetEmailsResponse>("GET", `/api/v1/emails/search`, query);
+  }
+
+  /** 发送邮件；超过邮箱服务商大小上限时返回413，size_limit中给出邮件大小和上限，offload_large_attachments为true时把最大的附件改为正文中的下载链接 */
+  sendEmail(body: SendEmailRequest): Promise<void> {
+    return this.request<void>("POST", `/api/v1/emails/send`, undefined, body);
+  }
+
+  /** 获取本地与服务器同时修改同一封邮件产生的同步冲突 */
+  getSyncConflicts(query?: GetSyncConflictsQuery): Promise<SyncConflict[]> {
+    return this.request<SyncConflict[]>("GET", `/api/v1/emails/sync-conflicts`, query);
+  }
+
+  /** 选择以服务器或本地状态处理同步冲突 */
+  resolveSyncConflict(id: number, body: ResolveSyncConflictRequest): Promise<SyncConflict> {
+    return this.request<SyncConflict>("POST", `/api/v1/emails/sync-conflicts/${encodeURIComponent(String(id))}/resolve`, undefined, body);
+  }
+
+  /** 创建邮件模板 */
+  createTemplate(body: CreateEmailTemplateRequest): Promise<EmailTemplate> {
+    return this.request<EmailTemplate>("POST", `/api/v1/emails/template`, undefined, body);
+  }
+
+  /** 获取邮件模板 */
+  getTemplate(id: number): Promise<EmailTemplate> {
+    return this.request<EmailTemplate>("GET", `/api/v1/emails/template/${encodeURIComponent(String(id))}`, undefined);
+  }
+
+  /** 更新邮件模板 */
+  updateTemplate(id: number, body: UpdateEmailTemplateRequest): Promise<EmailTemplate> {
+    return this.request<EmailTemplate>("PUT", `/api/v1/emails/template/${encodeURIComponent(String(id))}`, undefined, body);
+  }
+
+  /** 删除邮件模板 */
+  deleteTemplate(id: number): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/emails/template/${encodeURIComponent(String(id))}`, undefined);
+  }
+
+  /** 预览模板渲染结果 */
+  previewTemplate(id: number, body: TemplatePreviewRequest): Promise<TemplatePreview> {
+    return this.request<TemplatePreview>("POST", `/api/v1/emails/template/${encodeURIComponent(String(id))}/preview`, undefined, body);
+  }
+
+  /** 获取邮件模板列表 */
+  listTemplates(query?: ListTemplatesQuery): Promise<ListEmailTemplatesResponse> {
+    return this.request<ListEmailTemplatesResponse>("GET", `/api/v1/emails/templates`, query);
+  }
+
+  /** 获取跨账户移动/复制任务状态 */
+  getEmailTransferJob(jobID: string): Promise<EmailTransferJob> {
+    return this.request<EmailTransferJob>("GET", `/api/v1/emails/transfers/${encodeURIComponent(String(jobID))}`, undefined);
+  }
+
+  /** 取消跨账户移动/复制任务 */
+  cancelEmailTransferJob(jobID: string): Promise<EmailTransferJob> {
+    return this.request<EmailTransferJob>("POST", `/api/v1/emails/transfers/${encodeURIComponent(String(jobID))}/cancel`, undefined);
+  }
+
+  /** 获取最近收到的邮件中识别出的验证码，新邮件在前；新验证码同时通过verification_code事件推送 */
+  getVerificationCodes(query?: GetVerificationCodesQuery): Promise<VerificationCode[]> {
+    return this.request<VerificationCode[]>("GET", `/api/v1/emails/verification-codes`, query);
+  }
+
+  /** 获取邮件详情 */
+  getEmail(id: number): Promise<Email> {
+    return this.request<Email>("GET", `/api/v1/emails/${encodeURIComponent(String(id))}`, undefined);
+  }
+
+  /** 更新邮件状态 */
+  updateEmail(id: number, body: UpdateEmailRequest): Promise<Email> {
+    return this.request<Email>("PATCH", `/api/v1/emails/${encodeURIComponent(String(id))}`, undefined, body);
+  }
+
+  /** 删除邮件 */
+  deleteEmail(id: number): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/emails/${encodeURIComponent(String(id))}`, undefined);
+  }
+
+  /** 归档邮件 */
+  archiveEmail(id: number): Promise<void> {
+    return this.request<void>("PUT", `/api/v1/emails/${encodeURIComponent(String(id))}/archive`, undefined);
+  }
+
+  /** 获取邮件附件列表 */
+  getEmailAttachments(id: number): Promise<AttachmentInfo[]> {
+    return this.request<AttachmentInfo[]>("GET", `/api/v1/emails/${encodeURIComponent(String(id))}/attachments`, undefined);
+  }
+
+  /** 下载邮件的全部附件 */
+  downloadEmailAttachments(id: number): Promise<void> {
+    return this.request<void>("POST", `/api/v1/emails/${encodeURIComponent(String(id))}/attachments/download`, undefined);
+  }
+
+  /** 屏蔽邮件的发件人或其域名，并将邮件移入垃圾邮件或回收站 */
+  blockEmailSender(id: number, body: BlockEmailSenderRequest): Promise<BlockedSender> {
+    return this.request<BlockedSender>("PUT", `/api/v1/emails/${encodeURIComponent(String(id))}/block-sender`, undefined, body);
+  }
+
+  /** 设置星标颜色和后续跟进旗标，尽量以IMAP关键字同步到服务器 */
+  setEmailFlag(id: number, body: SetEmailFlagRequest): Promise<Email> {
+    return this.request<Email>("PUT", `/api/v1/emails/${encodeURIComponent(String(id))}/flag`, undefined, body);
+  }
+
+  /** 转发邮件 */
+  forwardEmail(id: number, body: ForwardEmailRequest): Promise<void> {
+    return this.request<void>("POST", `/api/v1/emails/${encodeURIComponent(String(id))}/forward`, undefined, body);
+  }
+
+  /** 分析邮件投递链延迟、SPF/DKIM结果和来源IP */
+  analyzeEmailHeaders(id: number): Promise<HeaderAnalysis> {
+    return this.request<HeaderAnalysis>("GET", `/api/v1/emails/${encodeURIComponent(String(id))}/headers/analysis`, undefined);
+  }
+
+  /** 获取邮件操作历史（同步、已读、移动、回复等） */
+  getEmailHistory(id: number): Promise<EmailHistoryEntry[]> {
+    return this.request<EmailHistoryEntry[]>("GET", `/api/v1/emails/${encodeURIComponent(String(id))}/history`, undefined);
+  }
+
+  /** 移动邮件，指定target_account_id或copy时跨账户移动/复制 */
+  moveEmail(id: number, body: MoveEmailRequest): Promise<EmailTransferJob> {
+    return this.request<EmailTransferJob>("PUT", `/api/v1/emails/${encodeURIComponent(String(id))}/move`, undefined, body);
+  }
+
+  /** 静音邮件所在会话，后续会话邮件自动已读且不通知 */
+  muteThread(id: number): Promise<MutedThread> {
+    return this.request<MutedThread>("PUT", `/api/v1/emails/${encodeURIComponent(String(id))}/mute-thread`, undefined);
+  }
+
+  /** 获取邮件在列表中的上一封和下一封 */
+  getEmailNavigation(id: number, query?: GetEmailNavigationQuery): Promise<EmailNavigation> {
+    return this.request<EmailNavigation>("GET", `/api/v1/emails/${encodeURIComponent(String(id))}/navigation`, query);
+  }
+
+  /** 获取邮件的私有笔记 */
+  getEmailNotes(id: number): Promise<EmailNote[]> {
+    return this.request<EmailNote[]>("GET", `/api/v1/emails/${encodeURIComponent(String(id))}/notes`, undefined);
+  }
+
+  /** 添加私有笔记，可选通过IMAP ANNOTATE同步到服务器 */
+  createEmailNote(id: number, body: CreateEmailNoteRequest): Promise<EmailNote> {
+    return this.request<EmailNote>("POST", `/api/v1/emails/${encodeURIComponent(String(id))}/notes`, undefined, body);
+  }
+
+  /** 更新私有笔记 */
+  updateEmailNote(id: number, noteID: number, body: UpdateEmailNoteRequest): Promise<EmailNote> {
+    return this.request<EmailNote>("PATCH", `/api/v1/emails/${encodeURIComponent(String(id))}/notes/${encodeURIComponent(String(noteID))}`, undefined, body);
+  }
+
+  /** 删除私有笔记 */
+  deleteEmailNote(id: number, noteID: number): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/emails/${encodeURIComponent(String(id))}/notes/${encodeURIComponent(String(noteID))}`, undefined);
+  }
+
+  /** 导出邮件PDF，可连同附件打包为zip */
+  exportEmailPDF(id: number, query?: ExportEmailPDFQuery): Promise<Response> {
+    return this.raw("GET", `/api/v1/emails/${encodeURIComponent(String(id))}/pdf`, query);
+  }
+
+  /** 切换置顶，文件夹内置顶数量已满时返回409 */
+  toggleEmailPin(id: number): Promise<Email> {
+    return this.request<Email>("PUT", `/api/v1/emails/${encodeURIComponent(String(id))}/pin`, undefined);
+  }
+
+  /** 反馈为重要邮件，用于训练优先收件箱分类 */
+  markEmailPriorityImportant(id: number): Promise<Email> {
+    return this.request<Email>("PUT", `/api/v1/emails/${encodeURIComponent(String(id))}/priority/important`, undefined);
+  }
+
+  /** 反馈为非重要邮件，用于训练优先收件箱分类 */
+  markEmailPriorityOther(id: number): Promise<Email> {
+    return this.request<Email>("PUT", `/api/v1/emails/${encodeURIComponent(String(id))}/priority/other`, undefined);
+  }
+
+  /** 标记为已读 */
+  markEmailAsRead(id: number): Promise<void> {
+    return this.request<void>("PUT", `/api/v1/emails/${encodeURIComponent(String(id))}/read`, undefined);
+  }
+
+  /** 使用指定字符集重新解码 */
+  redecodeEmail(id: number, body: RedecodeEmailRequest): Promise<Email> {
+    return this.request<Email>("POST", `/api/v1/emails/${encodeURIComponent(String(id))}/redecode`, undefined, body);
+  }
+
+  /** 从服务器源码重新解析 */
+  reparseEmail(id: number): Promise<Email> {
+    return this.request<Email>("POST", `/api/v1/emails/${encodeURIComponent(String(id))}/reparse`, undefined);
+  }
+
+  /** 回复邮件 */
+  replyEmail(id: number, body: ReplyEmailRequest): Promise<void> {
+    return this.request<void>("POST", `/api/v1/emails/${encodeURIComponent(String(id))}/reply`, undefined, body);
+  }
+
+  /** 回复全部 */
+  replyAllEmail(id: number, body: ReplyEmailRequest): Promise<void> {
+    return this.request<void>("POST", `/api/v1/emails/${encodeURIComponent(String(id))}/reply-all`, undefined, body);
+  }
+
+  /** 加入稍后回复，设置remind_at时到期后标记为未读并置顶，回复后自动移出 */
+  setReplyLater(id: number, body: ReplyLaterRequest): Promise<Email> {
+    return this.request<Email>("PUT", `/api/v1/emails/${encodeURIComponent(String(id))}/reply-later`, undefined, body);
+  }
+
+  /** 移出稍后回复 */
+  clearReplyLater(id: number): Promise<Email> {
+    return this.request<Email>("DELETE", `/api/v1/emails/${encodeURIComponent(String(id))}/reply-later`, undefined);
+  }
+
+  /** 创建带有效期的公开分享链接 */
+  createEmailShare(id: number, body: CreateEmailShareRequest): Promise<EmailShareLink> {
+    return this.request<EmailShareLink>("POST", `/api/v1/emails/${encodeURIComponent(String(id))}/share`, undefined, body);
+  }
+
+  /** 获取邮件的分享链接 */
+  getEmailShares(id: number): Promise<EmailShareLink[]> {
+    return this.request<EmailShareLink[]>("GET", `/api/v1/emails/${encodeURIComponent(String(id))}/shares`, undefined);
+  }
+
+  /** 查看邮件原始邮件头和MIME结构 */
+  getEmailSource(id: number): Promise<EmailSource> {
+    return this.request<EmailSource>("GET", `/api/v1/emails/${encodeURIComponent(String(id))}/source`, undefined);
+  }
+
+  /** 切换星标 */
+  toggleEmailStar(id: number): Promise<void> {
+    return this.request<void>("PUT", `/api/v1/emails/${encodeURIComponent(String(id))}/star`, undefined);
+  }
+
+  /** 取消静音邮件所在会话 */
+  unmuteThread(id: number): Promise<void> {
+    return this.request<void>("PUT", `/api/v1/emails/${encodeURIComponent(String(id))}/unmute-thread`, undefined);
+  }
+
+  /** 标记为未读 */
+  markEmailAsUnread(id: number): Promise<void> {
+    return this.request<void>("PUT", `/api/v1/emails/${encodeURIComponent(String(id))}/unread`, undefined);
+  }
+
+  /** 长轮询获取事件（无法保持SSE连接时使用） */
+  pollEvents(query?: PollEventsQuery): Promise<EventBatch> {
+    return this.request<EventBatch>("GET", `/api/v1/events/poll`, query);
+  }
+
+  /** 获取文件夹列表 */
+  getFolders(query: GetFoldersQuery): Promise<Folder[]> {
+    return this.request<Folder[]>("GET", `/api/v1/folders`, query);
+  }
+
+  /** 创建文件夹 */
+  createFolder(query: CreateFolderQuery, body: CreateFolderRequest): Promise<Folder> {
+    return this.request<Folder>("POST", `/api/v1/folders`, query, body);
+  }
+
+  /** 获取标签及显示属性 */
+  getLabels(query: GetLabelsQuery): Promise<Label[]> {
+    return this.request<Label[]>("GET", `/api/v1/folders/labels`, query);
+  }
+
+  /** 设置标签显示属性 */
+  updateLabelAppearance(query: UpdateLabelAppearanceQuery, body: UpdateLabelAppearanceRequest): Promise<Label> {
+    return this.request<Label>("PUT", `/api/v1/folders/labels`, query, body);
+  }
+
+  /** 删除标签显示属性 */
+  deleteLabelAppearance(id: number): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/folders/labels/${encodeURIComponent(String(id))}`, undefined);
+  }
+
+  /** 重新统计文件夹邮件数和未读数 */
+  reconcileFolderCounts(body: ReconcileFolderCountsRequest): Promise<FolderCountReconciliation> {
+    return this.request<FolderCountReconciliation>("POST", `/api/v1/folders/reconcile-counts`, undefined, body);
+  }
+
+  /** 获取文件夹 */
+  getFolder(id: number): Promise<Folder> {
+    return this.request<Folder>("GET", `/api/v1/folders/${encodeURIComponent(String(id))}`, undefined);
+  }
+
+  /** 更新文件夹 */
+  updateFolder(id: number, body: UpdateFolderRequest): Promise<Folder> {
+    return this.request<Folder>("PUT", `/api/v1/folders/${encodeURIComponent(String(id))}`, undefined, body);
+  }
+
+  /** 删除文件夹 */
+  deleteFolder(id: number): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/folders/${encodeURIComponent(String(id))}`, undefined);
+  }
+
+  /** 更新文件夹显示属性 */
+  updateFolderAppearance(id: number, body: AppearanceRequest): Promise<Folder> {
+    return this.request<Folder>("PUT", `/api/v1/folders/${encodeURIComponent(String(id))}/appearance`, undefined, body);
+  }
+
+  /** 将文件夹标记为已读 */
+  markFolderAsRead(id: number): Promise<void> {
+    return this.request<void>("PUT", `/api/v1/folders/${encodeURIComponent(String(id))}/mark-read`, undefined);
+  }
+
+  /** 同步文件夹 */
+  syncFolder(id: number): Promise<void> {
+    return this.request<void>("PUT", `/api/v1/folders/${encodeURIComponent(String(id))}/sync`, undefined);
+  }
+
+  /** 获取自动转发规则列表及转发统计 */
+  getForwardingRules(): Promise<ForwardingRule[]> {
+    return this.request<ForwardingRule[]>("GET", `/api/v1/forwarding-rules`, undefined);
+  }
+
+  /** 创建自动转发规则，收件箱中匹配的新邮件通过账户的SMTP转发到外部地址 */
+  createForwardingRule(body: ForwardingRuleRequest): Promise<ForwardingRule> {
+    return this.request<ForwardingRule>("POST", `/api/v1/forwarding-rules`, undefined, body);
+  }
+
+  /** 修改自动转发规则，包括启用和停用 */
+  updateForwardingRule(id: number, body: ForwardingRuleRequest): Promise<ForwardingRule> {
+    return this.request<ForwardingRule>("PUT", `/api/v1/forwarding-rules/${encodeURIComponent(String(id))}`, undefined, body);
+  }
+
+  /** 删除自动转发规则 */
+  deleteForwardingRule(id: number): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/forwarding-rules/${encodeURIComponent(String(id))}`, undefined);
+  }
+
+  /** 获取邮箱分组 */
+  getEmailGroups(): Promise<EmailGroup[]> {
+    return this.request<EmailGroup[]>("GET", `/api/v1/groups`, undefined);
+  }
+
+  /** 创建邮箱分组 */
+  createEmailGroup(body: CreateEmailGroupRequest): Promise<EmailGroup> {
+    return this.request<EmailGroup>("POST", `/api/v1/groups`, undefined, body);
+  }
+
+  /** 调整分组顺序 */
+  reorderEmailGroups(body: ReorderEmailGroupsRequest): Promise<EmailGroup[]> {
+    return this.request<EmailGroup[]>("PUT", `/api/v1/groups/reorder`, undefined, body);
+  }
+
+  /** 更新邮箱分组 */
+  updateEmailGroup(id: number, body: UpdateEmailGroupRequest): Promise<EmailGroup> {
+    return this.request<EmailGroup>("PUT", `/api/v1/groups/${encodeURIComponent(String(id))}`, undefined, body);
+  }
+
+  /** 删除邮箱分组 */
+  deleteEmailGroup(id: number): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/groups/${encodeURIComponent(String(id))}`, undefined);
+  }
+
+  /** 设为默认分组 */
+  setDefaultEmailGroup(id: number): Promise<EmailGroup> {
+    return this.request<EmailGroup>("PUT", `/api/v1/groups/${encodeURIComponent(String(id))}/default`, undefined);
+  }
+
+  /** 外部服务推送邮件，凭Bearer或X-Ingest-Token令牌认证；也接受原始MIME和带body-mime字段的表单，重复投递返回200 */
+  ingestEmail(query?: IngestEmailQuery, body: IngestMessageRequest): Promise<IngestResult> {
+    return this.request<IngestResult>("POST", `/api/v1/ingest`, query, body);
+  }
+
+  /** 获取入站邮件端点列表 */
+  getIngestEndpoints(): Promise<IngestEndpoint[]> {
+    return this.request<IngestEndpoint[]>("GET", `/api/v1/ingest-endpoints`, undefined);
+  }
+
+  /** 创建入站邮件端点，未指定账户时创建虚拟账户，令牌只返回一次 */
+  createIngestEndpoint(body: CreateIngestEndpointRequest): Promise<IngestEndpointWithToken> {
+    return this.request<IngestEndpointWithToken>("POST", `/api/v1/ingest-endpoints`, undefined, body);
+  }
+
+  /** 修改入站端点的名称、SMTP收件地址或启用状态 */
+  updateIngestEndpoint(id: number, body: UpdateIngestEndpointRequest): Promise<IngestEndpoint> {
+    return this.request<IngestEndpoint>("PATCH", `/api/v1/ingest-endpoints/${encodeURIComponent(String(id))}`, undefined, body);
+  }
+
+  /** 删除入站端点，已接收的邮件保留 */
+  deleteIngestEndpoint(id: number): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/ingest-endpoints/${encodeURIComponent(String(id))}`, undefined);
+  }
+
+  /** 重新生成入站令牌，旧令牌立即失效 */
+  rotateIngestToken(id: number): Promise<IngestEndpointWithToken> {
+    return this.request<IngestEndpointWithToken>("POST", `/api/v1/ingest-endpoints/${encodeURIComponent(String(id))}/rotate-token`, undefined);
+  }
+
+  /** 获取法律保全列表 */
+  getLegalHolds(): Promise<LegalHold[]> {
+    return this.request<LegalHold[]>("GET", `/api/v1/legal-holds`, undefined);
+  }
+
+  /** 创建法律保全，符合发件人和日期条件的邮件在解除前不能删除 */
+  createLegalHold(body: CreateLegalHoldRequest): Promise<LegalHold> {
+    return this.request<LegalHold>("POST", `/api/v1/legal-holds`, undefined, body);
+  }
+
+  /** 获取法律保全及覆盖的邮件数 */
+  getLegalHold(id: number): Promise<LegalHoldDetail> {
+    return this.request<LegalHoldDetail>("GET", `/api/v1/legal-holds/${encodeURIComponent(String(id))}`, undefined);
+  }
+
+  /** 获取法律保全的导出列表 */
+  getLegalHoldExports(id: number): Promise<LegalHoldExport[]> {
+    return this.request<LegalHoldExport[]>("GET", `/api/v1/legal-holds/${encodeURIComponent(String(id))}/exports`, undefined);
+  }
+
+  /** 在后台导出保全覆盖的邮件原文和带哈希的签名清单，返回202 */
+  createLegalHoldExport(id: number): Promise<LegalHoldExport> {
+    return this.request<LegalHoldExport>("POST", `/api/v1/legal-holds/${encodeURIComponent(String(id))}/exports`, undefined);
+  }
+
+  /** 获取导出进度和结果 */
+  getLegalHoldExport(id: number, exportID: number): Promise<LegalHoldExport> {
+    return this.request<LegalHoldExport>("GET", `/api/v1/legal-holds/${encodeURIComponent(String(id))}/exports/${encodeURIComponent(String(exportID))}`, undefined);
+  }
+
+  /** 下载已完成的导出压缩包 */
+  downloadLegalHoldExport(id: number, exportID: number): Promise<Response> {
+    return this.raw("GET", `/api/v1/legal-holds/${encodeURIComponent(String(id))}/exports/${encodeURIComponent(String(exportID))}/download`, undefined);
+  }
+
+  /** 重新计算导出文件的哈希并校验清单签名 */
+  verifyLegalHoldExport(id: number, exportID: number): Promise<LegalHoldExportVerification> {
+    return this.request<LegalHoldExportVerification>("POST", `/api/v1/legal-holds/${encodeURIComponent(String(id))}/exports/${encodeURIComponent(String(exportID))}/verify`, undefined);
+  }
+
+  /** 解除法律保全 */
+  releaseLegalHold(id: number): Promise<LegalHold> {
+    return this.request<LegalHold>("POST", `/api/v1/legal-holds/${encodeURIComponent(String(id))}/release`, undefined);
+  }
+
+  /** 获取代收外部邮箱列表 */
+  getMailFetchers(): Promise<MailFetcher[]> {
+    return this.request<MailFetcher[]>("GET", `/api/v1/mail-fetchers`, undefined);
+  }
+
+  /** 创建代收，定时从外部POP3/IMAP邮箱收取邮件到账户的文件夹 */
+  createMailFetcher(body: MailFetcherRequest): Promise<MailFetcher> {
+    return this.request<MailFetcher>("POST", `/api/v1/mail-fetchers`, undefined, body);
+  }
+
+  /** 修改代收，密码为空时保留原密码 */
+  updateMailFetcher(id: number, body: MailFetcherRequest): Promise<MailFetcher> {
+    return this.request<MailFetcher>("PUT", `/api/v1/mail-fetchers/${encodeURIComponent(String(id))}`, undefined, body);
+  }
+
+  /** 删除代收，已收取的邮件保留 */
+  deleteMailFetcher(id: number): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/mail-fetchers/${encodeURIComponent(String(id))}`, undefined);
+  }
+
+  /** 立即执行一次代收并返回收取结果 */
+  runMailFetcher(id: number): Promise<MailFetchResult> {
+    return this.request<MailFetchResult>("POST", `/api/v1/mail-fetchers/${encodeURIComponent(String(id))}/run`, undefined);
+  }
+
+  /** 获取邮箱迁移列表 */
+  getMailboxMigrations(): Promise<MailboxMigration[]> {
+    return this.request<MailboxMigration[]>("GET", `/api/v1/migrations`, undefined);
+  }
+
+  /** 创建邮箱迁移并开始复制 */
+  createMailboxMigration(body: CreateMailboxMigrationRequest): Promise<MailboxMigration> {
+    return this.request<MailboxMigration>("POST", `/api/v1/migrations`, undefined, body);
+  }
+
+  /** 生成默认文件夹映射 */
+  planMailboxMigration(query: PlanMailboxMigrationQuery): Promise<MailboxMigrationFolderMapping[]> {
+    return this.request<MailboxMigrationFolderMapping[]>("GET", `/api/v1/migrations/plan`, query);
+  }
+
+  /** 获取邮箱迁移进度 */
+  getMailboxMigration(id: number): Promise<MailboxMigration> {
+    return this.request<MailboxMigration>("GET", `/api/v1/migrations/${encodeURIComponent(String(id))}`, undefined);
+  }
+
+  /** 取消迁移 */
+  cancelMailboxMigration(id: number): Promise<MailboxMigration> {
+    return this.request<MailboxMigration>("POST", `/api/v1/migrations/${encodeURIComponent(String(id))}/cancel`, undefined);
+  }
+
+  /** 暂停迁移 */
+  pauseMailboxMigration(id: number): Promise<MailboxMigration> {
+    return this.request<MailboxMigration>("POST", `/api/v1/migrations/${encodeURIComponent(String(id))}/pause`, undefined);
+  }
+
+  /** 获取迁移核对报告 */
+  getMailboxMigrationReport(id: number): Promise<MailboxMigrationReport> {
+    return this.request<MailboxMigrationReport>("GET", `/api/v1/migrations/${encodeURIComponent(String(id))}/report`, undefined);
+  }
+
+  /** 从断点继续迁移 */
+  resumeMailboxMigration(id: number): Promise<MailboxMigration> {
+    return this.request<MailboxMigration>("POST", `/api/v1/migrations/${encodeURIComponent(String(id))}/resume`, undefined);
+  }
+
+  /** 执行通知中的快捷操作（一次性令牌） */
+  executeNotificationAction(token: string): Promise<NotificationActionResult> {
+    return this.request<NotificationActionResult>("POST", `/api/v1/notification-actions/${encodeURIComponent(String(token))}`, undefined);
+  }
+
+  /** 分页获取通知中心的通知，按时间倒序，同时返回全部未读数 */
+  getNotifications(query?: GetNotificationsQuery): Promise<ListNotificationsResponse> {
+    return this.request<ListNotificationsResponse>("GET", `/api/v1/notifications`, query);
+  }
+
+  /** 批量标记通知为已读或未读，未指定ids时修改全部通知 */
+  markNotifications(body: MarkNotificationsRequest): Promise<MarkNotificationsResult> {
+    return this.request<MarkNotificationsResult>("PATCH", `/api/v1/notifications`, undefined, body);
+  }
+
+  /** 获取未读通知的角标计数，按级别和事件类型分组 */
+  getNotificationCounts(): Promise<NotificationCounts> {
+    return this.request<NotificationCounts>("GET", `/api/v1/notifications/counts`, undefined);
+  }
+
+  /** 标记单条通知为已读或未读 */
+  updateNotification(id: number, body: UpdateNotificationRequest): Promise<Notification> {
+    return this.request<Notification>("PATCH", `/api/v1/notifications/${encodeURIComponent(String(id))}`, undefined, body);
+  }
+
+  /** 使用OAuth2令牌创建邮件账户 */
+  createOAuth2Account(body: CreateOAuth2AccountRequest): Promise<EmailAccount> {
+    return this.request<EmailAccount>("POST", `/api/v1/oauth/create-account`, undefined, body);
+  }
+
+  /** 获取Gmail授权地址 */
+  initGmailOAuth(query?: InitGmailOAuthQuery): Promise<OAuthURLResponse> {
+    return this.request<OAuthURLResponse>("GET", `/api/v1/oauth/gmail`, query);
+  }
+
+  /** 手动配置OAuth2邮件账户 */
+  createManualOAuth2Account(body: CreateManualOAuth2AccountRequest): Promise<EmailAccount> {
+    return this.request<EmailAccount>("POST", `/api/v1/oauth/manual-config`, undefined, body);
+  }
+
+  /** 获取Outlook授权地址 */
+  initOutlookOAuth(query?: InitOutlookOAuthQuery): Promise<OAuthURLResponse> {
+    return this.request<OAuthURLResponse>("GET", `/api/v1/oauth/outlook`, query);
+  }
+
+  /** 处理OAuth2回调 */
+  handleOAuth2Callback(provider: string, query?: HandleOAuth2CallbackQuery): Promise<OAuthTokenResponse> {
+    return this.request<OAuthTokenResponse>("GET", `/api/v1/oauth/${encodeURIComponent(String(provider))}/callback`, query);
+  }
+
+  /** 获取OpenAPI文档 */
+  getOpenAPISpec(): Promise<Response> {
+    return this.raw("GET", `/api/v1/openapi.json`, undefined);
+  }
+
+  /** 获取当前用户收到的待处理邀请 */
+  getOrganizationInvites(): Promise<OrganizationInvite[]> {
+    return this.request<OrganizationInvite[]>("GET", `/api/v1/organization-invites`, undefined);
+  }
+
+  /** 接受组织邀请 */
+  acceptOrganizationInvite(id: number): Promise<OrganizationInvite> {
+    return this.request<OrganizationInvite>("POST", `/api/v1/organization-invites/${encodeURIComponent(String(id))}/accept`, undefined);
+  }
+
+  /** 拒绝组织邀请 */
+  declineOrganizationInvite(id: number): Promise<OrganizationInvite> {
+    return this.request<OrganizationInvite>("POST", `/api/v1/organization-invites/${encodeURIComponent(String(id))}/decline`, undefined);
+  }
+
+  /** 获取当前用户加入的组织 */
+  getOrganizations(): Promise<OrganizationSummary[]> {
+    return this.request<OrganizationSummary[]>("GET", `/api/v1/organizations`, undefined);
+  }
+
+  /** 创建组织，创建者成为所有者 */
+  createOrganization(body: CreateOrganizationRequest): Promise<Organization> {
+    return this.request<Organization>("POST", `/api/v1/organizations`, undefined, body);
+  }
+
+  /** 获取组织成员和共享邮箱，管理员还返回待处理的邀请和授权 */
+  getOrganization(id: number): Promise<OrganizationDetail> {
+    return this.request<OrganizationDetail>("GET", `/api/v1/organizations/${encodeURIComponent(String(id))}`, undefined);
+  }
+
+  /** 删除组织及其共享、授权和认领，仅所有者可以操作 */
+  deleteOrganization(id: number): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/organizations/${encodeURIComponent(String(id))}`, undefined);
+  }
+
+  /** 按用户名邀请成员，邀请7天内有效 */
+  inviteOrganizationMember(id: number, body: InviteOrganizationMemberRequest): Promise<OrganizationInvite> {
+    return this.request<OrganizationInvite>("POST", `/api/v1/organizations/${encodeURIComponent(String(id))}/invites`, undefined, body);
+  }
+
+  /** 撤销待处理的邀请 */
+  revokeOrganizationInvite(id: number, inviteID: number): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/organizations/${encodeURIComponent(String(id))}/invites/${encodeURIComponent(String(inviteID))}`, undefined);
+  }
+
+  /** 将自己的邮箱账户共享到组织，需要是组织管理员 */
+  shareOrganizationMailbox(id: number, body: ShareMailboxRequest): Promise<SharedMailbox> {
+    return this.request<SharedMailbox>("POST", `/api/v1/organizations/${encodeURIComponent(String(id))}/mailboxes`, undefined, body);
+  }
+
+  /** 取消共享邮箱 */
+  unshareOrganizationMailbox(id: number, mailboxID: number): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/organizations/${encodeURIComponent(String(id))}/mailboxes/${encodeURIComponent(String(mailboxID))}`, undefined);
+  }
+
+  /** 授予或修改成员的共享邮箱权限（read_only、send_as） */
+  setSharedMailboxGrant(id: number, mailboxID: number, body: SharedMailboxGrantRequest): Promise<SharedMailboxGrant> {
+    return this.request<SharedMailboxGrant>("PUT", `/api/v1/organizations/${encodeURIComponent(String(id))}/mailboxes/${encodeURIComponent(String(mailboxID))}/grants`, undefined, body);
+  }
+
+  /** 撤销成员的共享邮箱权限并释放其认领的邮件 */
+  revokeSharedMailboxGrant(id: number, mailboxID: number, userID: number): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/organizations/${encodeURIComponent(String(id))}/mailboxes/${encodeURIComponent(String(mailboxID))}/grants/${encodeURIComponent(String(userID))}`, undefined);
+  }
+
+  /** 修改成员角色，管理员的任免只能由所有者进行 */
+  updateOrganizationMember(id: number, userID: number, body: UpdateOrganizationMemberRequest): Promise<OrganizationMember> {
+    return this.request<OrganizationMember>("PATCH", `/api/v1/organizations/${encodeURIComponent(String(id))}/members/${encodeURIComponent(String(userID))}`, undefined, body);
+  }
+
+  /** 移除成员或退出组织，同时撤销授权并释放认领的邮件 */
+  removeOrganizationMember(id: number, userID: number): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/organizations/${encodeURIComponent(String(id))}/members/${encodeURIComponent(String(userID))}`, undefined);
+  }
+
+  /** 获取支持的邮件提供商 */
+  getProviders(): Promise<ProviderInfo[]> {
+    return this.request<ProviderInfo[]>("GET", `/api/v1/providers`, undefined);
+  }
+
+  /** 根据邮箱地址检测提供商 */
+  detectProvider(query: DetectProviderQuery): Promise<ProviderInfo> {
+    return this.request<ProviderInfo>("GET", `/api/v1/providers/detect`, query);
+  }
+
+  /** 获取邮件保留规则列表 */
+  getRetentionPolicies(): Promise<RetentionPolicy[]> {
+    return this.request<RetentionPolicy[]>("GET", `/api/v1/retention-policies`, undefined);
+  }
+
+  /** 创建保留规则，定时删除或归档超过指定天数的邮件 */
+  createRetentionPolicy(body: RetentionPolicyRequest): Promise<RetentionPolicy> {
+    return this.request<RetentionPolicy>("POST", `/api/v1/retention-policies`, undefined, body);
+  }
+
+  /** 获取保留规则的执行记录 */
+  getRetentionRuns(query?: GetRetentionRunsQuery): Promise<RetentionRun[]> {
+    return this.request<RetentionRun[]>("GET", `/api/v1/retention-policies/runs`, query);
+  }
+
+  /** 修改保留规则 */
+  updateRetentionPolicy(id: number, body: RetentionPolicyRequest): Promise<RetentionPolicy> {
+    return this.request<RetentionPolicy>("PUT", `/api/v1/retention-policies/${encodeURIComponent(String(id))}`, undefined, body);
+  }
+
+  /** 删除保留规则及其执行记录 */
+  deleteRetentionPolicy(id: number): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/retention-policies/${encodeURIComponent(String(id))}`, undefined);
+  }
+
+  /** 试运行保留规则，返回会被处理的邮件数量和样例 */
+  previewRetentionPolicy(id: number): Promise<RetentionPreview> {
+    return this.request<RetentionPreview>("GET", `/api/v1/retention-policies/${encodeURIComponent(String(id))}/preview`, undefined);
+  }
+
+  /** 立即执行保留规则，返回202和执行记录 */
+  runRetentionPolicy(id: number): Promise<RetentionRun> {
+    return this.request<RetentionRun>("POST", `/api/v1/retention-policies/${encodeURIComponent(String(id))}/run`, undefined);
+  }
+
+  /** 获取当前用户的设置，未设置的项为服务器配置的默认值 */
+  getSettings(): Promise<UserSettings> {
+    return this.request<UserSettings>("GET", `/api/v1/settings`, undefined);
+  }
+
+  /** 修改当前用户的设置，只修改提供的字段，返回全部设置 */
+  updateSettings(body: UpdateUserSettingsRequest): Promise<UserSettings> {
+    return this.request<UserSettings>("PATCH", `/api/v1/settings`, undefined, body);
+  }
+
+  /** 获取当前用户可以访问的共享邮箱及权限 */
+  getSharedMailboxes(): Promise<SharedMailboxInfo[]> {
+    return this.request<SharedMailboxInfo[]>("GET", `/api/v1/shared-mailboxes`, undefined);
+  }
+
+  /** 分页获取共享邮箱中的邮件及认领状态 */
+  getSharedMailboxEmails(id: number, query?: GetSharedMailboxEmailsQuery): Promise<ListSharedEmailsResponse> {
+    return this.request<ListSharedEmailsResponse>("GET", `/api/v1/shared-mailboxes/${encodeURIComponent(String(id))}/emails`, query);
+  }
+
+  /** 获取共享邮箱中的邮件，不改变已读状态 */
+  getSharedMailboxEmail(id: number, emailID: number): Promise<SharedMailboxEmail> {
+    return this.request<SharedMailboxEmail>("GET", `/api/v1/shared-mailboxes/${encodeURIComponent(String(id))}/emails/${encodeURIComponent(String(emailID))}`, undefined);
+  }
+
+  /** 将邮件分配给有权限的成员，需要是组织管理员 */
+  assignSharedMailboxEmail(id: number, emailID: number, body: AssignEmailRequest): Promise<EmailAssignment> {
+    return this.request<EmailAssignment>("POST", `/api/v1/shared-mailboxes/${encodeURIComponent(String(id))}/emails/${encodeURIComponent(String(emailID))}/assign`, undefined, body);
+  }
+
+  /** 释放认领，负责人或组织管理员可以操作 */
+  releaseSharedMailboxEmail(id: number, emailID: number): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/shared-mailboxes/${encodeURIComponent(String(id))}/emails/${encodeURIComponent(String(emailID))}/assignment`, undefined);
+  }
+
+  /** 认领邮件，已被其他成员认领时返回409 */
+  claimSharedMailboxEmail(id: number, emailID: number): Promise<EmailAssignment> {
+    return this.request<EmailAssignment>("POST", `/api/v1/shared-mailboxes/${encodeURIComponent(String(id))}/emails/${encodeURIComponent(String(emailID))}/claim`, undefined);
+  }
+
+  /** 查看分享的邮件（只读页面） */
+  viewSharedEmail(token: string): Promise<Response> {
+    return this.raw("GET", `/api/v1/shared/${encodeURIComponent(String(token))}`, undefined);
+  }
+
+  /** 下载分享邮件的附件 */
+  downloadSharedAttachment(token: string, attachmentID: number): Promise<Response> {
+    return this.raw("GET", `/api/v1/shared/${encodeURIComponent(String(token))}/attachments/${encodeURIComponent(String(attachmentID))}`, undefined);
+  }
+
+  /** 修改分享链接的附件访问权限 */
+  updateEmailShare(id: number, body: UpdateEmailShareRequest): Promise<EmailShareLink> {
+    return this.request<EmailShareLink>("PATCH", `/api/v1/shares/${encodeURIComponent(String(id))}`, undefined, body);
+  }
+
+  /** 撤销分享链接 */
+  revokeEmailShare(id: number): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/shares/${encodeURIComponent(String(id))}`, undefined);
+  }
+
+  /** 订阅实时事件流 */
+  handleSSE(query?: HandleSSEQuery): Promise<Response> {
+    return this.raw("GET", `/api/v1/sse`, query);
+  }
+
+  /** 订阅实时事件流（兼容旧路径） */
+  handleSSEEvents(query?: HandleSSEEventsQuery): Promise<Response> {
+    return this.raw("GET", `/api/v1/sse/events`, query);
+  }
+
+  /** 获取SSE统计 */
+  getSSEStats(): Promise<ServiceStats> {
+    return this.request<ServiceStats>("GET", `/api/v1/sse/stats`, undefined);
+  }
+
+  /** 发送测试事件 */
+  sendTestEvent(body: TestEventRequest): Promise<TestEventResult> {
+    return this.request<TestEventResult>("POST", `/api/v1/sse/test`, undefined, body);
+  }
+
+  /** 记录点击并跳转到原始链接 */
+  trackMailMergeClick(token: string, linkID: number): Promise<void> {
+    return this.request<void>("GET", `/api/v1/t/c/${encodeURIComponent(String(token))}/${encodeURIComponent(String(linkID))}`, undefined);
+  }
+
+  /** 活动邮件的打开跟踪像素 */
+  trackMailMergeOpen(token: string): Promise<Response> {
+    return this.raw("GET", `/api/v1/t/o/${encodeURIComponent(String(token))}`, undefined);
+  }
+
+  /** 获取回收站中的邮件 */
+  getTrash(query?: GetTrashQuery): Promise<GetEmailsResponse> {
+    return this.request<GetEmailsResponse>("GET", `/api/v1/trash`, query);
+  }
+
+  /** 清空回收站 */
+  emptyTrash(query?: EmptyTrashQuery): Promise<TrashOperationResponse> {
+    return this.request<TrashOperationResponse>("DELETE", `/api/v1/trash`, query);
+  }
+
+  /** 彻底删除回收站中的邮件 */
+  purgeTrashedEmails(body: TrashEmailsRequest): Promise<TrashOperationResponse> {
+    return this.request<TrashOperationResponse>("POST", `/api/v1/trash/purge`, undefined, body);
+  }
+
+  /** 从回收站恢复邮件 */
+  restoreTrashedEmails(body: TrashEmailsRequest): Promise<TrashOperationResponse> {
+    return this.request<TrashOperationResponse>("POST", `/api/v1/trash/restore`, undefined, body);
+  }
+
+  /** 获取VIP发件人列表 */
+  getVIPSenders(): Promise<VIPSender[]> {
+    return this.request<VIPSender[]>("GET", `/api/v1/vip-senders`, undefined);
+  }
+
+  /** 添加VIP发件人，并标记其已有邮件 */
+  addVIPSender(body: AddVIPSenderRequest): Promise<VIPSender> {
+    return this.request<VIPSender>("POST", `/api/v1/vip-senders`, undefined, body);
+  }
+
+  /** 移除VIP发件人 */
+  removeVIPSender(id: number): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/vip-senders/${encodeURIComponent(String(id))}`, undefined);
+  }
+
+  /** 健康检查 */
+  healthCheck(): Promise<Response> {
+    return this.raw("GET", `/health`, undefined);
+  }
+
+  private async raw(
+    method: string,
+    path: string,
+    query?: object,
+    body?: unknown,
+    form?: object
+  ): Promise<Response> {
+    const params = new URLSearchParams();
+    for (const [key, value] of Object.entries((query ?? {}) as Record<string, QueryValue>)) {
+      if (value === undefined || value === null) continue;
+      for (const item of Array.isArray(value) ? value : [value]) {
+        params.append(key, String(item));
+      }
+    }
+    const search = params.toString();
+    const url = this.options.baseUrl.replace(/\/+$/, '') + path + (search ? '?' + search : '');
+
+    const headers: Record<string, string> = {};
+    const token = typeof this.options.token === 'function' ? this.options.token() : this.options.token;
+    if (token) headers.Authorization = `Bearer ${token}`;
+
+    let payload: BodyInit | undefined;
+    if (form) {
+      const data = new FormData();
+      for (const [key, value] of Object.entries(form)) {
+        if (value === undefined || value === null) continue;
+        data.append(key, value instanceof Blob ? value : String(value));
+      }
+      payload = data;
+    } else if (body !== undefined) {
+      headers['Content-Type'] = 'application/json';
+      payload = JSON.stringify(body);
+    }
+
+    const response = await (this.options.fetch ?? fetch)(url, { method, headers, body: payload });
+    if (!response.ok) {
+      const error = (await response.json().catch(() => ({ error: response.statusText }))) as ErrorResponse;
+      throw new FireMailApiError(response.status, error);
+    }
+    return response;
+  }
+
+  private async request<T>(
+    method: string,
+    path: string,
+    query?: object,
+    body?: unknown,
+    form?: object
+  ): Promise<T> {
+    const response = await this.raw(method, path, query, body, form);
+    const envelope = (await response.json()) as { success: boolean; data?: T; message?: string };
+    return envelope.data as T;
+  }
+}
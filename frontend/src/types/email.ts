@@ -346,9 +346,14 @@ export function formatEmailAddress(address: EmailAddress): string {
   return address.address;
 }
 
-// 工具函数：获取邮件预览文本
+// 工具函数：是否已加载正文（列表接口只返回 preview，正文需通过详情接口获取）
+export function hasEmailBody(email: Email): boolean {
+  return !!(email.text_body || email.html_body);
+}
+
+// 工具函数：获取邮件预览文本，优先使用服务端生成的 preview
 export function getEmailPreview(email: Email, maxLength: number = 100): string {
-  const content = email.text_body || email.html_body || '';
+  const content = email.preview || email.text_body || email.html_body || '';
   // 移除HTML标签
   const textContent = content.replace(/<[^>]*>/g, '');
   // 移除多余空白字符
@@ -19,6 +19,10 @@ export const ALL_SSE_EVENT_TYPES = [
   'sync_progress',
   'sync_completed',
   'sync_error',
+  'sync_conflict',
+  'migration_progress',
+  'migration_completed',
+  'migration_failed',
   'account_connected',
   'account_disconnected',
   'account_error',
@@ -28,6 +32,7 @@ export const ALL_SSE_EVENT_TYPES = [
   'group_reordered',
   'group_default_changed',
   'account_group_changed',
+  'email_assignment_changed',
   'notification',
   'heartbeat',
 ] as const;
@@ -108,6 +113,40 @@ export interface SyncEventData {
   error_message?: string;
 }
 
+// 同步冲突事件数据
+export interface SyncConflictEventData {
+  conflict_id: number;
+  account_id: number;
+  email_id: number;
+  fields: Array<'read' | 'starred' | 'folder' | 'deleted'>;
+  policy: 'server_wins' | 'local_wins' | 'ask';
+  status: 'pending' | 'resolved';
+  resolution?: 'server' | 'local';
+}
+
+// 邮箱迁移事件数据
+export interface MigrationEventData {
+  migration_id: number;
+  source_account_id: number;
+  target_account_id: number;
+  status: string;
+  folder_path?: string;
+  total_emails: number;
+  copied_emails: number;
+  failed_emails: number;
+  progress: number; // 0-1
+  error_message?: string;
+}
+
+// 共享邮箱邮件认领/分配变更事件数据
+export interface EmailAssignmentEventData {
+  shared_mailbox_id: number;
+  account_id: number;
+  email_id: number;
+  assignee_id?: number; // 为空表示已释放
+  changed_by: number;
+}
+
 // 账户事件数据
 export interface AccountEventData {
   account_id: number;
@@ -158,6 +197,9 @@ export type EmailMovedEvent = SSEEvent<EmailMovedEventData>;
 export type FolderReadStateEvent = SSEEvent<FolderReadStateEventData>;
 export type AccountReadStateEvent = SSEEvent<AccountReadStateEventData>;
 export type SyncEvent = SSEEvent<SyncEventData>;
+export type SyncConflictEvent = SSEEvent<SyncConflictEventData>;
+export type MigrationEvent = SSEEvent<MigrationEventData>;
+export type EmailAssignmentEvent = SSEEvent<EmailAssignmentEventData>;
 export type AccountEvent = SSEEvent<AccountEventData>;
 export type GroupEvent = SSEEvent<GroupEventData>;
 export type AccountGroupEvent = SSEEvent<AccountGroupEventData>;
@@ -172,9 +214,11 @@ export type AnySSEEvent =
   | FolderReadStateEvent
   | AccountReadStateEvent
   | SyncEvent
+  | SyncConflictEvent
   | AccountEvent
   | GroupEvent
   | AccountGroupEvent
+  | EmailAssignmentEvent
   | NotificationEvent
   | HeartbeatEvent;
 